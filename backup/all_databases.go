@@ -0,0 +1,148 @@
+package backup
+
+/*
+ * This file contains the --all-databases mode, in which every database in the
+ * cluster is backed up in turn, as pg_dumpall does.
+ */
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/iohelper"
+	"github.com/greenplum-db/gpbackup/backup_history"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+)
+
+// Databases that do not allow connections, such as template0, are left out
+func GetDatabaseNames(connectionPool *dbconn.DBConn) []string {
+	query := `
+	SELECT datname AS string
+	FROM pg_database
+	WHERE datallowconn
+	ORDER BY datname`
+	return dbconn.MustSelectStringSlice(connectionPool, query)
+}
+
+/*
+ * Each database is backed up to its own directory under --backup-dir, so that
+ * the backup sets can share a timestamp without their files colliding.  A
+ * database is restored by passing its directory to gprestore --backup-dir.
+ */
+func GetDatabaseBackupDir(backupDir string, dbName string) string {
+	return filepath.Join(backupDir, url.PathEscape(dbName))
+}
+
+func GetGlobalsFilePath(backupDir string, timestamp string) string {
+	return filepath.Join(backupDir, fmt.Sprintf("gpbackup_%s_globals.sql", timestamp))
+}
+
+/*
+ * Filters name objects in a single database and an incremental backup is
+ * based on an earlier backup of one database, so neither can apply to all of
+ * them.  A plugin or archive would receive files with the same names from
+ * every database.
+ */
+func ValidateAllDatabasesFlags(flags *pflag.FlagSet) {
+	if backupDir, _ := flags.GetString(utils.BACKUP_DIR); backupDir == "" {
+		gplog.Fatal(errors.Errorf("--%s requires --%s", utils.ALL_DATABASES, utils.BACKUP_DIR), "")
+	}
+	for _, flagName := range []string{utils.ARCHIVE_FILE, utils.DATA_ONLY, utils.EXCLUDE_RELATION, utils.EXCLUDE_RELATION_FILE,
+		utils.EXCLUDE_SCHEMA, utils.EXCLUDE_SCHEMA_FILE, utils.INCLUDE_RELATION, utils.INCLUDE_RELATION_FILE, utils.INCLUDE_SCHEMA,
+		utils.INCLUDE_SCHEMA_FILE, utils.INCREMENTAL, utils.PLUGIN_CONFIG} {
+		utils.CheckExclusiveFlags(flags, utils.ALL_DATABASES, flagName)
+	}
+}
+
+/*
+ * Backs up each database with the given flags and a shared timestamp, and
+ * returns the exit code of the last backup that failed, or of one that
+ * completed with errors if none failed.  The roles, resource
+ * queues and groups, and tablespaces are written to the global file by the
+ * first backup to get far enough to write them, and are left out of the
+ * metadata of every database.  A failed backup does not stop the backups of
+ * the remaining databases.
+ */
+func RunAllDatabasesBackup(flags *pflag.FlagSet) (exitCode int) {
+	defer func() {
+		if err := recover(); err != nil {
+			gplog.Error("%v", err)
+			exitCode = utils.EXIT_FATAL
+		}
+	}()
+	// Values from the configuration file are validated along with those from the command line
+	err := utils.SetFlagsFromConfiguration(flags, "GPBACKUP_")
+	gplog.FatalOnError(err)
+	ValidateAllDatabasesFlags(flags)
+	backupDir, _ := flags.GetString(utils.BACKUP_DIR)
+	maintenanceDB, _ := flags.GetString(utils.DBNAME)
+	if maintenanceDB == "" {
+		maintenanceDB = "template1"
+	}
+	conn := dbconn.NewDBConnFromEnvironment(maintenanceDB)
+	conn.MustConnect(1)
+	databases := GetDatabaseNames(conn)
+	conn.Close()
+
+	timestamp := backup_history.CurrentTimestamp()
+	globalsFile := GetGlobalsFilePath(backupDir, timestamp)
+	gplog.Info("Backing up %d databases with timestamp %s", len(databases), timestamp)
+	failedDatabases := make([]string, 0)
+	for _, dbName := range databases {
+		backup, err := backupDatabase(flags, dbName, GetDatabaseBackupDir(backupDir, dbName), timestamp, globalsFile)
+		if err != nil {
+			gplog.Error("Backup of database %s failed: %v", dbName, err)
+			failedDatabases = append(failedDatabases, dbName)
+			if utils.IsFailureExitCode(backup.ExitCode()) || exitCode == utils.EXIT_SUCCESS {
+				exitCode = backup.ExitCode()
+			}
+		}
+		if backup.wasTerminated {
+			gplog.Warn("Backup interrupted; the remaining databases were not backed up")
+			break
+		}
+	}
+
+	if len(failedDatabases) > 0 {
+		gplog.Error("Backups of %d of %d databases failed: %s", len(failedDatabases), len(databases), strings.Join(failedDatabases, ", "))
+	} else {
+		gplog.Info("Backed up %d databases with timestamp %s; global objects were written to %s", len(databases), timestamp, globalsFile)
+	}
+	return exitCode
+}
+
+/*
+ * The database name and backup directory are only set on the flags for the
+ * backup of the one database, and the caller's values are put back afterward.
+ */
+func backupDatabase(flags *pflag.FlagSet, dbName string, backupDir string, timestamp string, globalsFile string) (*Backup, error) {
+	defer setFlagForDatabase(flags, utils.DBNAME, dbName)()
+	defer setFlagForDatabase(flags, utils.BACKUP_DIR, backupDir)()
+
+	backup := NewBackup(flags, nil, nil)
+	backup.presetTimestamp = timestamp
+	backup.skipClusterGlobals = true
+	if !iohelper.FileExistsAndIsReadable(globalsFile) {
+		backup.clusterGlobalsFile = globalsFile
+	}
+	err := backup.DoBackup()
+	return backup, err
+}
+
+// Sets the flag and returns a function that puts back its previous value
+func setFlagForDatabase(flags *pflag.FlagSet, name string, value string) func() {
+	flag := flags.Lookup(name)
+	previousValue, previousChanged := flag.Value.String(), flag.Changed
+	err := flags.Set(name, value)
+	gplog.FatalOnError(err)
+	return func() {
+		_ = flag.Value.Set(previousValue)
+		flag.Changed = previousChanged
+	}
+}
@@ -0,0 +1,72 @@
+package backup_test
+
+import (
+	"os"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"github.com/greenplum-db/gpbackup/backup"
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("backup/all_databases tests", func() {
+	Describe("GetDatabaseNames", func() {
+		It("returns the databases that allow connections", func() {
+			mock.ExpectQuery("SELECT datname AS string").WillReturnRows(sqlmock.NewRows([]string{"string"}).AddRow("postgres").AddRow("template1").AddRow("test db"))
+
+			Expect(backup.GetDatabaseNames(connectionPool)).To(Equal([]string{"postgres", "template1", "test db"}))
+		})
+	})
+	Describe("GetDatabaseBackupDir", func() {
+		It("escapes database names that are not valid directory names", func() {
+			Expect(backup.GetDatabaseBackupDir("/backups", "testdb")).To(Equal("/backups/testdb"))
+			Expect(backup.GetDatabaseBackupDir("/backups", "a/b")).To(Equal("/backups/a%2Fb"))
+		})
+	})
+	Describe("GetGlobalsFilePath", func() {
+		It("places the global file in the backup directory", func() {
+			Expect(backup.GetGlobalsFilePath("/backups", "20240101010101")).To(Equal("/backups/gpbackup_20240101010101_globals.sql"))
+		})
+	})
+	Describe("ValidateAllDatabasesFlags", func() {
+		It("requires a backup directory", func() {
+			flags := backup.NewBackupFlagSet()
+			_ = flags.Set(utils.ALL_DATABASES, "true")
+
+			defer testhelper.ShouldPanicWithMessage("--all-databases requires --backup-dir")
+			backup.ValidateAllDatabasesFlags(flags)
+		})
+		It("does not allow filters", func() {
+			flags := backup.NewBackupFlagSet()
+			_ = flags.Set(utils.ALL_DATABASES, "true")
+			_ = flags.Set(utils.BACKUP_DIR, "/backups")
+			_ = flags.Set(utils.INCLUDE_SCHEMA, "public")
+
+			defer testhelper.ShouldPanicWithMessage("The following flags may not be specified together: all-databases, include-schema")
+			backup.ValidateAllDatabasesFlags(flags)
+		})
+	})
+	Describe("RunAllDatabasesBackup", func() {
+		It("returns an error code instead of exiting if the flags are invalid", func() {
+			flags := backup.NewBackupFlagSet()
+			_ = flags.Set(utils.ALL_DATABASES, "true")
+
+			Expect(backup.RunAllDatabasesBackup(flags)).To(Equal(utils.EXIT_FATAL))
+			Expect(logfile).To(Say("--all-databases requires --backup-dir"))
+		})
+		It("validates filters that are set in the environment", func() {
+			_ = os.Setenv("GPBACKUP_INCLUDE_SCHEMA", "public")
+			defer os.Unsetenv("GPBACKUP_INCLUDE_SCHEMA")
+			flags := backup.NewBackupFlagSet()
+			_ = flags.Set(utils.ALL_DATABASES, "true")
+			_ = flags.Set(utils.BACKUP_DIR, "/backups")
+
+			Expect(backup.RunAllDatabasesBackup(flags)).To(Equal(utils.EXIT_FATAL))
+			Expect(logfile).To(Say("The following flags may not be specified together: all-databases, include-schema"))
+		})
+	})
+})
@@ -0,0 +1,163 @@
+package backup
+
+/*
+ * This file contains the Backup type, which lets other Go programs take a
+ * backup without going through the gpbackup command line.
+ */
+
+import (
+	"context"
+	"sync"
+
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+/*
+ * Each Backup keeps its own state, so several can run at once in one process,
+ * with a few limits: the gplog logger and error code, the compression program,
+ * the metadata encryption key, and whether progress bars are shown belong to
+ * the process.  Backups that run at once share a log and error code and must
+ * be given the same compression and encryption flags.
+ *
+ * A termination signal is delivered to the whole process, so it terminates
+ * every backup that is running.
+ */
+var (
+	runningBackups      = make(map[*Backup]bool)
+	runningBackupsMutex = &sync.Mutex{}
+)
+
+type Backup struct {
+	/*
+	 * The flags are those gpbackup accepts on the command line, as returned
+	 * by NewBackupFlagSet.
+	 */
+	Flags *pflag.FlagSet
+	/*
+	 * If the connection pool is set before the backup runs, it must not yet
+	 * be connected, and it is used in place of one made from the environment
+	 * and --dbname.  Once the backup has run, it is the pool the backup used,
+	 * which is then closed.
+	 */
+	Connection *dbconn.DBConn
+	// If no logger is set, the backup logs to the current gplog logger
+	Logger *gplog.GpLogger
+	/*
+	 * Canceling the context cancels the queries the backup is running and
+	 * stops the backup.  If no context is set, the backup cannot be canceled.
+	 */
+	Context context.Context
+	/*
+	 * Applied, in order, to each metadata statement before it is written, after
+	 * any middleware the flags call for, such as --deterministic.
+	 */
+	StatementMiddleware []utils.StatementMiddleware
+
+	backupState
+	exitCode int
+
+	// Set by RunAllDatabasesBackup for the backup of each database
+	presetTimestamp    string
+	clusterGlobalsFile string
+	skipClusterGlobals bool
+}
+
+func NewBackupFlagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("gpbackup", pflag.ContinueOnError)
+	SetFlagDefaults(flags)
+	return flags
+}
+
+func NewBackup(flags *pflag.FlagSet, conn *dbconn.DBConn, logger *gplog.GpLogger) *Backup {
+	return &Backup{
+		Flags:       flags,
+		Connection:  conn,
+		Logger:      logger,
+		backupState: newBackupState(flags, conn, context.Background()),
+	}
+}
+
+/*
+ * Runs the backup as gpbackup would, but returns an error if it fails instead
+ * of exiting.  The gplog logger and error code are put back to their previous
+ * values before returning.
+ */
+func (b *Backup) DoBackup() (err error) {
+	previousLogger := gplog.GetLogger()
+	previousErrorCode := gplog.GetErrorCode()
+	if b.Logger != nil {
+		gplog.SetLogger(b.Logger)
+	}
+	gplog.SetErrorCode(0)
+	ctx := b.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	b.backupState = newBackupState(b.Flags, b.Connection, ctx)
+	runningBackupsMutex.Lock()
+	runningBackups[b] = true
+	runningBackupsMutex.Unlock()
+	defer func() {
+		b.exitCode = b.teardownBackup(recover())
+		if b.exitCode != 0 {
+			err = errors.Errorf("Backup failed with exit code %d: %s", b.exitCode, utils.ExitCodeDescription(b.exitCode))
+		}
+		b.Connection = b.connectionPool
+		runningBackupsMutex.Lock()
+		delete(runningBackups, b)
+		runningBackupsMutex.Unlock()
+		gplog.SetLogger(previousLogger)
+		gplog.SetErrorCode(previousErrorCode)
+	}()
+
+	b.validateFlags(b.Flags)
+	b.DoSetup()
+	b.doBackup()
+	return nil
+}
+
+func terminateRunningBackups(backupFailed bool) {
+	runningBackupsMutex.Lock()
+	defer runningBackupsMutex.Unlock()
+	for backup := range runningBackups {
+		backup.wasTerminated = true
+		backup.DoCleanup(backupFailed)
+	}
+}
+
+// Returns the timestamp of the backup once it has run
+func (b *Backup) Timestamp() string {
+	return b.globalFPInfo.Timestamp
+}
+
+func (b *Backup) Report() *utils.Report {
+	return b.backupReport
+}
+
+// Returns the exit code gpbackup would have exited with once the backup has run
+func (b *Backup) ExitCode() int {
+	return b.exitCode
+}
+
+/*
+ * Runs a backup with the flags of the given command and returns its exit code,
+ * so that the gpbackup command line can exit with it.
+ */
+func RunBackupCommand(cmd *cobra.Command) int {
+	// --all-databases may itself come from the configuration file or the environment
+	if err := utils.SetFlagsFromConfiguration(cmd.Flags(), "GPBACKUP_"); err != nil {
+		gplog.Error(err.Error())
+		return utils.EXIT_FATAL
+	}
+	if allDatabases, _ := cmd.Flags().GetBool(utils.ALL_DATABASES); allDatabases {
+		return RunAllDatabasesBackup(cmd.Flags())
+	}
+	backup := NewBackup(cmd.Flags(), nil, nil)
+	_ = backup.DoBackup()
+	return backup.ExitCode()
+}
@@ -0,0 +1,85 @@
+package backup_test
+
+import (
+	"context"
+	"sync"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpbackup/backup"
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("backup/api tests", func() {
+	Describe("NewBackupFlagSet", func() {
+		It("returns a flag set with gpbackup's defaults", func() {
+			flags := backup.NewBackupFlagSet()
+
+			Expect(utils.MustGetFlagInt(flags, utils.JOBS)).To(Equal(1))
+			Expect(utils.MustGetFlagString(flags, utils.DBNAME)).To(Equal(""))
+		})
+	})
+	Describe("Backup.DoBackup", func() {
+		It("returns an error instead of exiting if the backup fails", func() {
+			b := backup.NewBackup(backup.NewBackupFlagSet(), nil, nil)
+
+			err := b.DoBackup()
+
+			Expect(err).To(MatchError("Backup failed with exit code 1: fatal error"))
+			Expect(b.ExitCode()).To(Equal(utils.EXIT_FATAL))
+			Expect(logfile).To(Say("--dbname must be specified"))
+		})
+		It("stops if its context is canceled", func() {
+			flags := backup.NewBackupFlagSet()
+			_ = flags.Set(utils.DBNAME, "testdb")
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			b := backup.NewBackup(flags, nil, nil)
+			b.Context = ctx
+
+			err := b.DoBackup()
+
+			Expect(err).To(HaveOccurred())
+			Expect(logfile).To(Say("Backup canceled: context canceled"))
+		})
+		It("does not change the state of other backups", func() {
+			_ = cmdFlags.Set(utils.JOBS, "3")
+			testBackup.SetBackupPhase("predata")
+			b := backup.NewBackup(backup.NewBackupFlagSet(), nil, nil)
+
+			_ = b.DoBackup()
+
+			Expect(testBackup.MustGetFlagInt(utils.JOBS)).To(Equal(3))
+			Expect(testBackup.GetErrorContext()).To(Equal(backup.ErrorContext{Phase: "predata"}))
+		})
+		It("puts back the previous error code once it has run", func() {
+			b := backup.NewBackup(backup.NewBackupFlagSet(), nil, nil)
+
+			_ = b.DoBackup()
+
+			Expect(gplog.GetErrorCode()).To(Equal(0))
+		})
+		It("runs alongside other backups in the same process", func() {
+			backups := []*backup.Backup{
+				backup.NewBackup(backup.NewBackupFlagSet(), nil, nil),
+				backup.NewBackup(backup.NewBackupFlagSet(), nil, nil),
+			}
+			var wg sync.WaitGroup
+			for _, b := range backups {
+				wg.Add(1)
+				go func(b *backup.Backup) {
+					defer wg.Done()
+					_ = b.DoBackup()
+				}(b)
+			}
+			wg.Wait()
+
+			for _, b := range backups {
+				Expect(b.ExitCode()).To(Equal(utils.EXIT_FATAL))
+			}
+		})
+	})
+})
@@ -0,0 +1,168 @@
+package backup
+
+/*
+ * This file contains functions for writing a backup as a single tar archive,
+ * to a file or to stdout for piping into another program.  The data of each
+ * table is streamed from the segments into the archive as it is copied out,
+ * rather than being written to the segments' backup directories, so that the
+ * segments need no space for the backup.
+ */
+
+import (
+	"fmt"
+	"path"
+	"sync"
+
+	"github.com/greenplum-db/gp-common-go-libs/cluster"
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpbackup/backup_filepath"
+	"github.com/greenplum-db/gpbackup/backup_history"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/pkg/errors"
+)
+
+const archivePipeSuffix = "_archive_pipe"
+
+/*
+ * Each backup directory is archived under the name it would have in a backup
+ * taken with --backup-dir, so that the extracted archive can be restored with
+ * --backup-dir.
+ */
+func GetArchiveName(fpInfo backup_filepath.FilePathInfo, contentID int) string {
+	return path.Join(fmt.Sprintf("%s%d", fpInfo.UserSpecifiedSegPrefix, contentID), "backups", fpInfo.Timestamp[0:8], fpInfo.Timestamp)
+}
+
+// The backup markers only describe the backup directories, not the archive
+func GetCoordinatorArchiveSource(fpInfo backup_filepath.FilePathInfo) utils.ArchiveSource {
+	return utils.ArchiveSource{
+		Directory: fpInfo.GetDirForContent(-1),
+		Name:      GetArchiveName(fpInfo, -1),
+		Exclude:   []string{"*_" + backup_filepath.MARKER_IN_PROGRESS, "*_" + backup_filepath.MARKER_COMPLETE},
+	}
+}
+
+func (b *Backup) OpenBackupArchive(filename string) {
+	gplog.Info("Writing backup archive to %s", filename)
+	writer, err := utils.OpenArchiveFile(filename)
+	gplog.FatalOnError(err)
+	b.backupArchive = utils.NewArchiveWriter(writer)
+}
+
+/*
+ * Each segment's COPY writes the table's data to a named pipe in its backup
+ * directory, which is read over ssh and added to the archive under the name of
+ * the data file the segment would otherwise have written.  The data is only
+ * added once the COPY has succeeded, so a failed COPY leaves nothing partial in
+ * the archive.
+ */
+func (b *Backup) CopyTableOutToArchive(connectionPool *dbconn.DBConn, table Table, connNum int) (int64, error) {
+	extension := utils.GetPipeThroughProgram().Extension
+	pipePath := func(contentID int) string {
+		return b.globalFPInfo.GetTableBackupFilePath(contentID, table.Oid, extension, false) + archivePipeSuffix
+	}
+	remoteOutput := b.globalCluster.GenerateAndExecuteCommand(fmt.Sprintf("Creating archive pipes for table %s", table.FQN()), func(contentID int) string {
+		return fmt.Sprintf(`mkfifo -m 0600 "%s"`, pipePath(contentID))
+	}, cluster.ON_SEGMENTS)
+	b.globalCluster.CheckClusterError(remoteOutput, "Unable to create archive pipes", func(contentID int) string {
+		return fmt.Sprintf("Unable to create archive pipe %s on segment %d", pipePath(contentID), contentID)
+	})
+	defer b.removeArchivePipes(table, pipePath)
+
+	contentIDs := make([]int, 0)
+	for _, contentID := range b.globalCluster.ContentIDs {
+		if contentID != -1 {
+			contentIDs = append(contentIDs, contentID)
+		}
+	}
+	spools := make([]*utils.ArchiveSpool, len(contentIDs))
+	spoolErrs := make([]error, len(contentIDs))
+	var readers sync.WaitGroup
+	for i, contentID := range contentIDs {
+		readers.Add(1)
+		go func(i int, contentID int) {
+			defer readers.Done()
+			spools[i], spoolErrs[i] = utils.SpoolRemoteFile(b.globalCluster.GetHostForContent(contentID), pipePath(contentID))
+		}(i, contentID)
+	}
+	defer func() {
+		for _, spool := range spools {
+			if spool != nil {
+				spool.Remove()
+			}
+		}
+	}()
+
+	rowsCopied, err := b.CopyTableOut(connectionPool, table, b.globalFPInfo.GetTableBackupFilePathForCopyCommand(table.Oid, extension, false)+archivePipeSuffix, connNum)
+	if err != nil {
+		b.unblockArchivePipes(table, pipePath)
+		readers.Wait()
+		return 0, err
+	}
+	readers.Wait()
+	for i, contentID := range contentIDs {
+		if spoolErrs[i] != nil {
+			return 0, spoolErrs[i]
+		}
+		dataFile := path.Base(b.globalFPInfo.GetTableBackupFilePath(contentID, table.Oid, extension, false))
+		err = b.backupArchive.AddSpool(path.Join(GetArchiveName(b.globalFPInfo, contentID), dataFile), spools[i])
+		if err != nil {
+			return 0, errors.Wrap(err, "Unable to write backup archive")
+		}
+	}
+	return rowsCopied, nil
+}
+
+/*
+ * A segment whose COPY failed before it opened its pipe leaves the pipe's
+ * reader waiting for a writer, so each pipe is opened for writing, which
+ * fails at once if nothing is reading it, to give its reader an end of file.
+ */
+func (b *Backup) unblockArchivePipes(table Table, pipePath func(contentID int) string) {
+	_ = b.globalCluster.GenerateAndExecuteCommand(fmt.Sprintf("Closing archive pipes for table %s", table.FQN()), func(contentID int) string {
+		return fmt.Sprintf(`dd if=/dev/null of="%s" oflag=nonblock 2>/dev/null; true`, pipePath(contentID))
+	}, cluster.ON_SEGMENTS)
+}
+
+func (b *Backup) removeArchivePipes(table Table, pipePath func(contentID int) string) {
+	remoteOutput := b.globalCluster.GenerateAndExecuteCommand(fmt.Sprintf("Removing archive pipes for table %s", table.FQN()), func(contentID int) string {
+		return fmt.Sprintf(`rm -f "%s"`, pipePath(contentID))
+	}, cluster.ON_SEGMENTS)
+	b.globalCluster.CheckClusterError(remoteOutput, "Unable to remove archive pipes", func(contentID int) string {
+		return fmt.Sprintf("Unable to remove archive pipe %s on segment %d", pipePath(contentID), contentID)
+	}, true)
+}
+
+/*
+ * The coordinator's backup directory is added once the backup has written its
+ * metadata, table of contents, and configuration file to it, and before the
+ * backup is marked as complete and its report is written, so that a backup
+ * whose archive cannot be written is reported as failed.
+ */
+func (b *Backup) FinishBackupArchive(filename string) error {
+	if b.backupArchive == nil {
+		b.OpenBackupArchive(filename)
+	}
+	backup_history.WriteConfigFile(&b.backupReport.BackupConfig, b.globalFPInfo.GetConfigFilePath())
+	err := b.backupArchive.AddDirectory(GetCoordinatorArchiveSource(b.globalFPInfo))
+	if err != nil {
+		b.AbortBackupArchive()
+		return errors.Wrap(err, "Unable to write backup archive")
+	}
+	err = b.backupArchive.Close()
+	b.backupArchive = nil
+	if err != nil {
+		return errors.Wrap(err, "Unable to write backup archive")
+	}
+	gplog.Info("Backup archive complete")
+	return nil
+}
+
+func (b *Backup) AbortBackupArchive() {
+	if b.backupArchive == nil {
+		return
+	}
+	gplog.Warn("Backup archive %s is incomplete", b.MustGetFlagString(utils.ARCHIVE_FILE))
+	b.backupArchive.Abort()
+	b.backupArchive = nil
+}
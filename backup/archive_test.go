@@ -0,0 +1,37 @@
+package backup_test
+
+import (
+	"github.com/greenplum-db/gpbackup/backup"
+	"github.com/greenplum-db/gpbackup/backup_filepath"
+	"github.com/greenplum-db/gpbackup/testutils"
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backup/archive tests", func() {
+	testCluster := testutils.SetDefaultSegmentConfiguration()
+	fpInfo := backup_filepath.NewFilePathInfo(testCluster, "", "20170101010101", "gpseg")
+
+	Describe("GetArchiveName", func() {
+		It("names the backup directory of each content as it would be named under --backup-dir", func() {
+			Expect(backup.GetArchiveName(fpInfo, -1)).To(Equal("gpseg-1/backups/20170101/20170101010101"))
+			Expect(backup.GetArchiveName(fpInfo, 1)).To(Equal("gpseg1/backups/20170101/20170101010101"))
+		})
+		It("names directories the same way when the backup has a user-specified backup directory", func() {
+			userFPInfo := backup_filepath.NewFilePathInfo(testCluster, "/data/backups", "20170101010101", "gpseg")
+
+			Expect(backup.GetArchiveName(userFPInfo, 0)).To(Equal("gpseg0/backups/20170101/20170101010101"))
+		})
+	})
+	Describe("GetCoordinatorArchiveSource", func() {
+		It("archives the coordinator's backup directory without its backup markers", func() {
+			Expect(backup.GetCoordinatorArchiveSource(fpInfo)).To(Equal(utils.ArchiveSource{
+				Directory: "gpseg-1/backups/20170101/20170101010101",
+				Name:      "gpseg-1/backups/20170101/20170101010101",
+				Exclude:   []string{"*_in_progress", "*_complete"},
+			}))
+		})
+	})
+})
@@ -0,0 +1,67 @@
+package backup
+
+/*
+ * This file contains functions for detecting tables and schemas left in user
+ * databases by Greenplum itself or its utilities, such as legacy error tables
+ * and gpexpand's status schema, which are excluded from backups by default.
+ */
+
+import (
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpbackup/utils"
+)
+
+/*
+ * Error tables were created by single row error handling with LOG ERRORS INTO
+ * before GPDB 5, and are recreated automatically when an external table that
+ * logs errors into them is restored.  In GPDB 5, fmterrtbl refers to the
+ * external table itself when LOG ERRORS is used, so those are not error tables.
+ */
+func GetLegacyErrorTables(connectionPool *dbconn.DBConn) []string {
+	if connectionPool.Version.AtLeast("6") {
+		return []string{}
+	}
+	query := `
+	SELECT quote_ident(n.nspname) || '.' || quote_ident(c.relname) AS string
+	FROM pg_class c
+		JOIN pg_namespace n ON c.relnamespace = n.oid
+	WHERE c.oid IN (SELECT fmterrtbl FROM pg_exttable WHERE fmterrtbl IS NOT NULL AND fmterrtbl <> reloid)
+	ORDER BY n.nspname, c.relname`
+	return dbconn.MustSelectStringSlice(connectionPool, query)
+}
+
+/*
+ * gpexpand records the progress of an expansion in its own schema, which it
+ * drops once the expansion is cleaned up with gpexpand -c.
+ */
+func GetGpexpandSchema(connectionPool *dbconn.DBConn) []string {
+	query := `SELECT nspname AS string FROM pg_namespace WHERE nspname = 'gpexpand'`
+	return dbconn.MustSelectStringSlice(connectionPool, query)
+}
+
+/*
+ * Artifacts are only excluded when they would otherwise be backed up along
+ * with everything else; tables and schemas that the user included explicitly
+ * are always backed up.
+ */
+func (b *Backup) ProcessInternalArtifacts() {
+	if b.MustGetFlagBool(utils.INCLUDE_INTERNAL_ARTIFACTS) || len(b.MustGetFlagStringArray(utils.INCLUDE_RELATION)) > 0 {
+		return
+	}
+	for _, table := range GetLegacyErrorTables(b.connectionPool) {
+		gplog.Info("Excluding legacy error table %s. Use --%s to back it up.", table, utils.INCLUDE_INTERNAL_ARTIFACTS)
+		err := b.cmdFlags.Set(utils.EXCLUDE_RELATION, table)
+		gplog.FatalOnError(err)
+		b.excludedArtifacts = append(b.excludedArtifacts, table)
+	}
+	if len(b.MustGetFlagStringSlice(utils.INCLUDE_SCHEMA)) > 0 {
+		return
+	}
+	for _, schema := range GetGpexpandSchema(b.connectionPool) {
+		gplog.Info("Excluding schema %s created by gpexpand. Use --%s to back it up.", schema, utils.INCLUDE_INTERNAL_ARTIFACTS)
+		err := b.cmdFlags.Set(utils.EXCLUDE_SCHEMA, schema)
+		gplog.FatalOnError(err)
+		b.excludedArtifacts = append(b.excludedArtifacts, schema)
+	}
+}
@@ -0,0 +1,41 @@
+package backup_test
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/greenplum-db/gpbackup/backup"
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backup/artifacts tests", func() {
+	Describe("ProcessInternalArtifacts", func() {
+		It("excludes legacy error tables and the gpexpand schema", func() {
+			mock.ExpectQuery(`SELECT (.*) WHERE c.oid IN \(SELECT fmterrtbl FROM pg_exttable (.*)\)`).WillReturnRows(sqlmock.NewRows([]string{"string"}).AddRow("public.err_table"))
+			mock.ExpectQuery(`SELECT nspname AS string FROM pg_namespace WHERE nspname = 'gpexpand'`).WillReturnRows(sqlmock.NewRows([]string{"string"}).AddRow("gpexpand"))
+
+			testBackup.ProcessInternalArtifacts()
+
+			Expect(testBackup.MustGetFlagStringSlice(utils.EXCLUDE_RELATION)).To(Equal([]string{"public.err_table"}))
+			Expect(testBackup.MustGetFlagStringSlice(utils.EXCLUDE_SCHEMA)).To(Equal([]string{"gpexpand"}))
+		})
+		It("does not exclude the gpexpand schema when schemas are included explicitly", func() {
+			_ = cmdFlags.Set(utils.INCLUDE_SCHEMA, "gpexpand")
+			mock.ExpectQuery(`SELECT (.*) WHERE c.oid IN \(SELECT fmterrtbl FROM pg_exttable (.*)\)`).WillReturnRows(sqlmock.NewRows([]string{"string"}))
+
+			testBackup.ProcessInternalArtifacts()
+
+			Expect(testBackup.MustGetFlagStringSlice(utils.EXCLUDE_SCHEMA)).To(BeEmpty())
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+		It("does not exclude anything with --include-internal-artifacts", func() {
+			_ = cmdFlags.Set(utils.INCLUDE_INTERNAL_ARTIFACTS, "true")
+
+			testBackup.ProcessInternalArtifacts()
+
+			Expect(testBackup.MustGetFlagStringSlice(utils.EXCLUDE_RELATION)).To(BeEmpty())
+			Expect(testBackup.MustGetFlagStringSlice(utils.EXCLUDE_SCHEMA)).To(BeEmpty())
+		})
+	})
+})
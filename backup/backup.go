@@ -2,178 +2,324 @@ package backup
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"runtime/debug"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/greenplum-db/gp-common-go-libs/cluster"
 	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/iohelper"
 	"github.com/greenplum-db/gp-common-go-libs/operating"
 	"github.com/greenplum-db/gpbackup/backup_filepath"
 	"github.com/greenplum-db/gpbackup/backup_history"
 	"github.com/greenplum-db/gpbackup/options"
 	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
 
 /*
  * We define and initialize flags separately to avoid import conflicts in tests.
- * The flag variables, and setter functions for them, are in global_variables.go.
  */
 
-func initializeFlags(cmd *cobra.Command) {
-	SetFlagDefaults(cmd.Flags())
-
-	_ = cmd.MarkFlagRequired(utils.DBNAME)
-
-	cmdFlags = cmd.Flags()
-}
-
 func SetFlagDefaults(flagSet *pflag.FlagSet) {
+	flagSet.Bool(utils.ADAPTIVE_COMPRESSION, false, "Adjust the compression level of each table's data, starting from --compression-level, based on how well it compresses and whether CPU or I/O is the bottleneck.  Requires --single-data-file.")
+	flagSet.Bool(utils.ALL_DATABASES, false, "Back up every database that allows connections, each to its own directory under --backup-dir, with a shared timestamp.  Roles, resource queues and groups, and tablespaces are written once to a global file in --backup-dir, to be run with psql before the databases are restored.  If --dbname is given, it is the database connected to in order to list the others.")
+	flagSet.String(utils.ARCHIVE_FILE, "", "Write the backup as a single tar archive to this file, or to stdout if it is -.  Table data is streamed from the segments into the archive instead of being written to their backup directories, and the coordinator's files are added once the backup is complete.  The archive can be extracted into a directory to be restored with --backup-dir.")
 	flagSet.String(utils.BACKUP_DIR, "", "The absolute path of the directory to which all backup files will be written")
+	flagSet.StringArray(utils.BACKUP_GUC, []string{}, "A GUC to set on every database connection, in the format name=value, overriding gpbackup's default session settings.  The value is read as in postgresql.conf, so list parameters such as search_path take a comma-separated list. Can be specified multiple times.")
+	flagSet.Bool(utils.CHECK_CATALOG, false, "Before backing up, check the catalog for relations, types, and columns whose parent entries are missing.  The check scans pg_class, pg_type, and pg_attribute, so it can take a long time on databases with many objects.")
 	flagSet.Int(utils.COMPRESSION_LEVEL, 1, "Level of compression to use during data backup. Valid values are between 1 and 9.")
+	flagSet.Bool(utils.COMPRESS_METADATA, false, "Compress metadata, statistics, and table of contents files in the same way as data files")
+	flagSet.String(utils.CONFIG, "", "A YAML file of flag values to use, which are overridden by GPBACKUP_<FLAG_NAME> environment variables and by flags given on the command line")
+	flagSet.Int(utils.COPY_BUFFER_SIZE, 0, "The size in kilobytes of the buffers gpbackup_helper uses to stream table data with --single-data-file. 0 uses the default size.")
+	flagSet.String(utils.COPY_DELIMITER, "", "The single-character delimiter between columns in data files.  Defaults to a comma for csv and a tab for text.")
+	flagSet.String(utils.COPY_FORMAT, utils.COPY_FORMAT_CSV, "The format of data files, so that they can be loaded by other tools as well as gprestore. Valid values are \"csv\", \"text\", and \"binary\", which is fastest to restore but can only be restored to the same major version of GPDB.")
+	flagSet.Bool(utils.COPY_HEADER, false, "Begin each csv data file with a header line of column names")
+	flagSet.String(utils.COPY_NULL_STRING, "", "The string representing a null value in data files.  Defaults to an unquoted empty string for csv and \\N for text.")
+	flagSet.Int(utils.COPY_RETRIES, 0, "The number of times to retry backing up the data of a table whose COPY fails partway, overwriting its partial data file each time")
+	flagSet.String(utils.CONNECTION_OPTIONS, "", "Options to set on every database connection, in the format of PGOPTIONS, e.g. \"-c optimizer=off\"")
+	flagSet.String(utils.DATA_FILTER_FILE, "", "A file of lines in the format schema.table:condition.  Only the rows of each listed table matching its condition are backed up.")
 	flagSet.Bool(utils.DATA_ONLY, false, "Only back up data, do not back up metadata")
 	flagSet.String(utils.DBNAME, "", "The database to be backed up")
 	flagSet.Bool(utils.DEBUG, false, "Print verbose and debug log messages")
+	flagSet.Bool(utils.DETERMINISTIC, false, "Write metadata in name order with normalized spacing between statements and without sequence values, so that backups of identical schemas can be compared with diff")
+	flagSet.Bool(utils.DRY_RUN, false, "Print the objects that would be backed up and the estimated size of their data, without writing any backup files or locking any tables")
+	flagSet.Bool(utils.EXCLUDE_MONITORING_SCHEMAS, false, "Exclude schemas created by well-known monitoring tools, such as gpmetrics and pgwatch, from the backup")
+	flagSet.StringArray(utils.EXCLUDE_COLUMN, []string{}, "Back up the data of all columns except the specified column(s), in the format schema.table.column.  On restore, excluded columns are filled with their defaults.  --exclude-column can be specified multiple times.")
+	flagSet.Bool(utils.EXCLUDE_COLUMN_DDL, false, "Also omit columns excluded with --exclude-column from CREATE TABLE statements.  Indexes and constraints on excluded columns will fail to restore.")
+	flagSet.StringArray(utils.EXCLUDE_LEAF_PARTITION, []string{}, "Back up all data except the data of the specified leaf partition table(s), which are still created on restore. --exclude-leaf-partition can be specified multiple times.")
 	flagSet.StringSlice(utils.EXCLUDE_SCHEMA, []string{}, "Back up all metadata except objects in the specified schema(s). --exclude-schema can be specified multiple times.")
+	flagSet.String(utils.EXCLUDE_SCHEMA_FILE, "", "A file containing a list of schemas to be excluded from the backup")
 	flagSet.StringSlice(utils.EXCLUDE_RELATION, []string{}, "Back up all metadata except the specified table(s). --exclude-table can be specified multiple times.")
 	flagSet.String(utils.EXCLUDE_RELATION_FILE, "", "A file containing a list of fully-qualified tables to be excluded from the backup")
 	flagSet.String(utils.FROM_TIMESTAMP, "", "A timestamp to use to base the current incremental backup off")
 	flagSet.Bool("help", false, "Help for gpbackup")
+	flagSet.Bool(utils.INCLUDE_INTERNAL_ARTIFACTS, false, "Back up legacy error tables and the gpexpand schema, which are excluded by default")
+	flagSet.StringArray(utils.INCLUDE_LEAF_PARTITION, []string{}, "Back up the data of only the specified leaf partition table(s); all other leaf partitions are still created on restore, but are empty. --include-leaf-partition can be specified multiple times.")
 	flagSet.StringSlice(utils.INCLUDE_SCHEMA, []string{}, "Back up only the specified schema(s). --include-schema can be specified multiple times.")
+	flagSet.String(utils.INCLUDE_SCHEMA_FILE, "", "A file containing a list of schemas to be included in the backup")
 	flagSet.StringArray(utils.INCLUDE_RELATION, []string{}, "Back up only the specified table(s). --include-table can be specified multiple times.")
 	flagSet.String(utils.INCLUDE_RELATION_FILE, "", "A file containing a list of fully-qualified tables to be included in the backup")
 	flagSet.Bool(utils.INCREMENTAL, false, "Only back up data for AO tables that have been modified since the last backup")
 	flagSet.Int(utils.JOBS, 1, "The number of parallel connections to use when backing up data")
+	flagSet.Int(utils.LARGE_ROW_THRESHOLD, 0, "Warn about tables containing rows larger than this many megabytes before backing up their data. Checking requires scanning tables with large TOAST data. 0 disables the check.")
 	flagSet.Bool(utils.LEAF_PARTITION_DATA, false, "For partition tables, create one data file per leaf partition instead of one data file for the whole table")
+	flagSet.String(utils.LEAF_PARTITION_KEY_END, "", "Back up the data of only the range leaf partitions whose upper bound is at or below this partition key value; all other leaf partitions are still created on restore, but are empty")
+	flagSet.String(utils.LEAF_PARTITION_KEY_START, "", "Back up the data of only the range leaf partitions whose lower bound is at or above this partition key value; all other leaf partitions are still created on restore, but are empty")
+	flagSet.Bool(utils.LINK_UNCHANGED_DATA, false, "Link the data files of AO tables that are unchanged since the last matching backup instead of copying their data again")
+	flagSet.Bool(utils.LOCK_DATA_TABLES_ONLY, false, "Only lock tables whose data will be backed up.  Concurrent DDL on other tables may make their metadata inconsistent with the backup.")
+	flagSet.String(utils.MASKING_RULES_FILE, "", "A file of lines in the format schema.table.column:expression, e.g. public.users.email:md5(email).  The column's values are replaced with the result of the expression in the backed up data.")
+	flagSet.String(utils.METADATA_ENCRYPTION_KEY_FILE, "", "A file containing a passphrase with which to encrypt metadata, statistics, and table of contents files.  gprestore must be given the same file.")
 	flagSet.Bool(utils.METADATA_ONLY, false, "Only back up metadata, do not back up data")
+	flagSet.String(utils.METRICS_ADDRESS, "", "Publish Prometheus metrics on the progress of the backup at http://<address>/metrics while it runs, e.g. \":9187\"")
+	flagSet.String(utils.MONITORING_SCHEMA_FILE, "", "A file containing a list of additional schemas to treat as created by monitoring tools")
 	flagSet.Bool(utils.NO_COMPRESSION, false, "Disable compression of data files")
+	flagSet.Bool(utils.NO_MANIFEST_CHECKSUMS, false, "Do not read back every data file to record its size and checksum in the backup manifest.  gpbackup verify then only checks that the data files are present.")
+	flagSet.Bool(utils.NO_OWNER, false, "Do not back up ALTER ... OWNER TO statements, so that objects are owned by the restoring role")
+	flagSet.Bool(utils.NO_PRIVILEGES, false, "Do not back up GRANT and REVOKE statements for object privileges")
+	flagSet.Bool(utils.NO_TABLESPACES, false, "Do not back up CREATE TABLESPACE statements.  Tables and indexes still name their tablespaces, which must exist when they are restored or be mapped with gprestore --tablespace-map.")
+	flagSet.Bool(utils.PARQUET_EXPORT, false, "Write the data of each table as one Parquet file per segment, with column types derived from the catalog, so that it can be queried directly by external engines.  Data exported as Parquet cannot be restored by gprestore.")
 	flagSet.String(utils.PLUGIN_CONFIG, "", "The configuration file to use for a plugin")
+	flagSet.String(utils.PROFILE, "", "The profile in the --config file whose flag values override the values at the top level of the file")
+	flagSet.String(utils.PROGRESS_FILE, "", "The file to which progress events are appended with --progress-format json, instead of stdout")
+	flagSet.String(utils.PROGRESS_FORMAT, utils.PROGRESS_FORMAT_TEXT, "The format in which to report progress. Valid values are \"text\" for log messages and progress bars and \"json\" for newline-delimited JSON events.")
 	flagSet.Bool("version", false, "Print version number and exit")
+	flagSet.Int(utils.QUERY_TIMEOUT, 0, "Cancel any query, including the COPY of a table's data, that runs for longer than this many seconds. 0 disables the timeout.")
 	flagSet.Bool(utils.QUIET, false, "Suppress non-warning, non-error log messages")
+	flagSet.Int(utils.SAMPLE_PERCENT, 0, "Back up a random sample of approximately this percentage of the rows of each table, between 1 and 100.  0 backs up all rows.")
 	flagSet.Bool(utils.SINGLE_DATA_FILE, false, "Back up all data to a single file instead of one per table")
+	flagSet.Int(utils.SMALL_TABLE_BATCH_SIZE, 1, "The number of tables smaller than 1 MB to schedule together on one connection, each still copied with its own COPY statement")
+	flagSet.String(utils.SPLIT_METADATA, "", "Also write the metadata to one file per object type or per schema, for review or partial restore with psql. Valid values are \"object-type\" and \"schema\".")
+	flagSet.Bool(utils.STRICT, false, "Fail the backup if views to be backed up depend on tables excluded by filters, instead of excluding those views with a warning")
+	flagSet.String(utils.TARGET, TARGET_GREENPLUM, "The database to which the metadata will be restored. Valid values are \"greenplum\" and \"postgres\", which writes the metadata without distribution policies, append-optimized storage options, external tables, and other objects that only Greenplum supports.")
+	flagSet.Bool(utils.UTILITY_MODE, false, "Connect to the master in utility mode, without dispatching queries to the segments, to back up metadata while the segments are unavailable.  Implies --metadata-only, and before GPDB 7 omits the segment-specific locations of tablespaces.")
 	flagSet.Bool(utils.VERBOSE, false, "Print verbose log messages")
+	flagSet.String(utils.VERIFICATION_QUERIES, "", "A YAML file of named single-value SQL queries, such as row counts of critical tables, to run at the backup snapshot and record in the backup report")
+	flagSet.Int(utils.VERIFY_DATA_SAMPLE, 0, "After backing up data, check that this many randomly chosen rows of each table's data file on each segment can be loaded with the backup's COPY options.  0 disables the check.")
+	flagSet.Bool(utils.WITH_EXTERNAL_DATA, false, "Back up the rows of readable external tables, such as those reading from gpfdist, as if they were regular tables.  gprestore does not load this data into external tables, only into regular tables of the same name.")
+	flagSet.Bool(utils.WITH_LARGE_OBJECTS, false, "Back up large objects, along with their owners, privileges, and comments")
 	flagSet.Bool(utils.WITH_STATS, false, "Back up query plan statistics")
 }
 
 // This function handles setup that can be done before parsing flags.
 func DoInit(cmd *cobra.Command) {
-	CleanupGroup = &sync.WaitGroup{}
-	CleanupGroup.Add(1)
 	gplog.InitializeLogging("gpbackup", "")
-	initializeFlags(cmd)
-	utils.InitializeSignalHandler(DoCleanup, "backup process", &wasTerminated)
-	objectCounts = make(map[string]int)
+	SetFlagDefaults(cmd.Flags())
+	// Each running backup is marked as terminated by terminateRunningBackups
+	var terminated bool
+	utils.InitializeSignalHandler(terminateRunningBackups, "backup process", &terminated)
 }
 
-func DoFlagValidation(cmd *cobra.Command) {
-	ValidateFlagCombinations(cmd.Flags())
-	ValidateFlagValues()
+func (b *Backup) validateFlags(flags *pflag.FlagSet) {
+	err := utils.SetFlagsFromConfiguration(flags, "GPBACKUP_")
+	gplog.FatalOnError(err)
+	b.notifications, err = utils.ReadNotifications(b.MustGetFlagString(utils.CONFIG))
+	gplog.FatalOnError(err)
+	/*
+	 * The database name may come from a configuration file or the environment,
+	 * so it cannot be marked as required on the command line.
+	 */
+	if b.MustGetFlagString(utils.DBNAME) == "" {
+		gplog.Fatal(errors.Errorf("--%s must be specified on the command line, in the environment, or in the --%s file", utils.DBNAME, utils.CONFIG), "")
+	}
+	b.ValidateUtilityModeFlags(flags)
+	b.ValidateFlagCombinations(flags)
+	b.ValidateFlagValues()
 }
 
 // This function handles setup that must be done after parsing flags.
-func DoSetup() {
-	SetLoggerVerbosity()
+func (b *Backup) DoSetup() {
+	b.SetLoggerVerbosity()
 	gplog.Verbose("Backup Command: %s", os.Args)
+	if b.MustGetFlagString(utils.PROGRESS_FORMAT) == utils.PROGRESS_FORMAT_JSON {
+		b.InitializeProgressStream()
+	} else if b.MustGetFlagString(utils.ARCHIVE_FILE) == utils.ARCHIVE_TO_STDOUT {
+		err := utils.InitializeArchiveLogging("gpbackup")
+		gplog.FatalOnError(err)
+		utils.SetPrintProgressBars(false)
+	}
+	b.startBackupPhase("setup")
 
-	utils.CheckGpexpandRunning(utils.BackupPreventedByGpexpandMessage)
-	timestamp := backup_history.CurrentTimestamp()
-	CreateBackupLockFile(timestamp)
-	InitializeConnectionPool()
-
-	gplog.Info("Starting backup of database %s", MustGetFlagString(utils.DBNAME))
-	opts, err := options.NewOptions(cmdFlags)
+	if b.MustGetFlagBool(utils.UTILITY_MODE) {
+		gplog.Warn("Skipping the check for a running gpexpand, which requires a normal connection to the master, because --%s was specified", utils.UTILITY_MODE)
+	} else {
+		utils.CheckGpexpandRunning(utils.BackupPreventedByGpexpandMessage)
+	}
+	timestamp := b.presetTimestamp
+	if timestamp == "" {
+		timestamp = backup_history.CurrentTimestamp()
+	}
+	if !b.MustGetFlagBool(utils.DRY_RUN) {
+		b.CreateBackupLockFile(timestamp)
+	}
+	b.InitializeConnectionPool()
+
+	gplog.Info("Starting backup of database %s", b.MustGetFlagString(utils.DBNAME))
+	// todo remove these when EXCLUDE_RELATION* and *_SCHEMA_FILE flags are handled by options object
+	b.InitializeFilterLists()
+	b.ProcessMonitoringSchemas()
+	b.ProcessInternalArtifacts()
+	opts, err := options.NewOptions(b.cmdFlags)
 	gplog.FatalOnError(err)
 
-	DBValidate(connectionPool, opts.GetIncludedTables(), false)
-
-	// todo remove these when EXCLUDE_RELATION* flags are handled by options object
-	InitializeFilterLists()
-	validateFilterLists()
+	b.DBValidate(b.connectionPool, opts.GetIncludedTables(), false)
+	if dataFilterFile := b.MustGetFlagString(utils.DATA_FILTER_FILE); dataFilterFile != "" {
+		b.dataFilters = utils.ParseDataFilters(iohelper.MustReadLinesFromFile(dataFilterFile))
+	}
+	b.copyFormat = b.GetCopyFormatFromFlags()
+	if keyFile := b.MustGetFlagString(utils.METADATA_ENCRYPTION_KEY_FILE); keyFile != "" {
+		key, err := utils.ReadMetadataEncryptionKeyFile(keyFile)
+		gplog.FatalOnError(err)
+		utils.SetMetadataEncryptionKey(key)
+	}
+	b.excludedColumns = ParseExcludedColumns(b.MustGetFlagStringArray(utils.EXCLUDE_COLUMN))
+	if maskingRulesFile := b.MustGetFlagString(utils.MASKING_RULES_FILE); maskingRulesFile != "" {
+		b.maskingRules = ParseMaskingRules(iohelper.MustReadLinesFromFile(maskingRulesFile))
+	}
+	b.validateFilterLists()
+	if b.MustGetFlagBool(utils.WITH_LARGE_OBJECTS) && b.connectionPool.Version.Before("6") {
+		gplog.Fatal(errors.Errorf("--%s requires GPDB 6 or later", utils.WITH_LARGE_OBJECTS), "")
+	}
 
-	err = opts.ExpandIncludesForPartitions(connectionPool, cmdFlags)
+	err = opts.ExpandIncludesForPartitions(b.connectionPool, b.cmdFlags)
 	gplog.FatalOnError(err)
 
-	segConfig := cluster.MustGetSegmentConfiguration(connectionPool)
-	globalCluster = cluster.NewCluster(segConfig)
-	segPrefix := backup_filepath.GetSegPrefix(connectionPool)
-	globalFPInfo = backup_filepath.NewFilePathInfo(globalCluster, MustGetFlagString(utils.BACKUP_DIR), timestamp, segPrefix)
-	if MustGetFlagBool(utils.METADATA_ONLY) {
-		_, err = globalCluster.ExecuteLocalCommand(fmt.Sprintf("mkdir -p %s", globalFPInfo.GetDirForContent(-1)))
+	segConfig := cluster.MustGetSegmentConfiguration(b.connectionPool)
+	b.globalCluster = cluster.NewCluster(segConfig)
+	b.notifications.AddNotifier(utils.ReportEmailNotifier{Cluster: b.globalCluster}, utils.EVENT_BACKUP_SUCCESS, utils.EVENT_BACKUP_FAILURE)
+	segPrefix := backup_filepath.GetSegPrefix(b.connectionPool)
+	b.globalFPInfo = backup_filepath.NewFilePathInfo(b.globalCluster, b.MustGetFlagString(utils.BACKUP_DIR), timestamp, segPrefix)
+	if metricsAddress := b.MustGetFlagString(utils.METRICS_ADDRESS); metricsAddress != "" && !b.MustGetFlagBool(utils.DRY_RUN) {
+		b.backupMetrics = NewBackupMetrics(b.connectionPool.DBName, timestamp, operating.System.Now())
+		var address net.Addr
+		b.metricsServer, address, err = StartMetricsServer(metricsAddress, b.backupMetrics)
+		if err != nil {
+			gplog.Fatal(errors.Wrapf(err, "Unable to publish metrics at %s", metricsAddress), "")
+		}
+		gplog.Info("Publishing backup metrics at http://%s/metrics", address)
+	}
+	if b.MustGetFlagBool(utils.DRY_RUN) {
+		gplog.Verbose("Skipping creation of backup directories for dry run")
+	} else if b.MustGetFlagBool(utils.METADATA_ONLY) {
+		_, err = b.globalCluster.ExecuteLocalCommand(fmt.Sprintf("mkdir -p %s", b.globalFPInfo.GetDirForContent(-1)))
 		gplog.FatalOnError(err)
+		b.WriteInProgressMarkers(false)
 	} else {
-		CreateBackupDirectoriesOnAllHosts()
+		b.CreateBackupDirectoriesOnAllHosts()
+		b.WriteInProgressMarkers(true)
 	}
-	globalTOC = &utils.TOC{}
-	globalTOC.InitializeMetadataEntryMap()
-	utils.InitializePipeThroughParameters(!MustGetFlagBool(utils.NO_COMPRESSION), MustGetFlagInt(utils.COMPRESSION_LEVEL))
-	GetQuotedRoleNames(connectionPool)
+	b.RunPreflightChecks()
+	b.globalTOC = &utils.TOC{}
+	b.globalTOC.InitializeMetadataEntryMap()
+	utils.InitializePipeThroughParameters(!b.MustGetFlagBool(utils.NO_COMPRESSION), b.MustGetFlagInt(utils.COMPRESSION_LEVEL))
+	if b.MustGetFlagBool(utils.PARQUET_EXPORT) {
+		utils.InitializeParquetPipeThroughParameters(!b.MustGetFlagBool(utils.NO_COMPRESSION))
+	}
+	b.GetQuotedRoleNames(b.connectionPool)
 
-	pluginConfigFlag := MustGetFlagString(utils.PLUGIN_CONFIG)
+	pluginConfigFlag := b.MustGetFlagString(utils.PLUGIN_CONFIG)
 
 	if pluginConfigFlag != "" {
-		pluginConfig, err = utils.ReadPluginConfig(pluginConfigFlag)
+		b.pluginConfig, err = utils.ReadPluginConfig(pluginConfigFlag)
 		gplog.FatalOnError(err)
-		configFilename := filepath.Base(pluginConfig.ConfigPath)
-		configDirname := filepath.Dir(pluginConfig.ConfigPath)
-		pluginConfig.ConfigPath = filepath.Join(configDirname, timestamp+"_"+configFilename)
-		_ = cmdFlags.Set(utils.PLUGIN_CONFIG, pluginConfig.ConfigPath)
-		gplog.Info("Plugin config path: %s", pluginConfig.ConfigPath)
+		configFilename := filepath.Base(b.pluginConfig.ConfigPath)
+		configDirname := filepath.Dir(b.pluginConfig.ConfigPath)
+		b.pluginConfig.ConfigPath = filepath.Join(configDirname, timestamp+"_"+configFilename)
+		_ = b.cmdFlags.Set(utils.PLUGIN_CONFIG, b.pluginConfig.ConfigPath)
+		gplog.Info("Plugin config path: %s", b.pluginConfig.ConfigPath)
 	}
 
-	InitializeBackupReport(*opts)
+	b.InitializeBackupReport(*opts)
+	if verificationFile := b.MustGetFlagString(utils.VERIFICATION_QUERIES); verificationFile != "" {
+		b.backupReport.VerificationQueries, err = utils.ReadVerificationQueries(verificationFile)
+		gplog.FatalOnError(err)
+	}
+	if !b.MustGetFlagBool(utils.DRY_RUN) {
+		b.writeOptionsSnapshot(pluginConfigFlag)
+	}
 
-	if pluginConfigFlag != "" {
-		backupReport.PluginVersion = pluginConfig.CheckPluginExistsOnAllHosts(globalCluster)
-		pluginConfig.CopyPluginConfigToAllHosts(globalCluster)
-		pluginConfig.SetupPluginForBackup(globalCluster, globalFPInfo)
+	if pluginConfigFlag != "" && !b.MustGetFlagBool(utils.DRY_RUN) {
+		b.backupReport.PluginVersion = b.pluginConfig.CheckPluginExistsOnAllHosts(b.globalCluster)
+		b.pluginConfig.CopyPluginConfigToAllHosts(b.globalCluster)
+		b.pluginConfig.SetupPluginForBackup(b.globalCluster, b.globalFPInfo)
 	}
 }
 
-func DoBackup() {
-	gplog.Info("Backup Timestamp = %s", globalFPInfo.Timestamp)
-	gplog.Info("Backup Database = %s", connectionPool.DBName)
-	gplog.Verbose("Backup Parameters: {%s}", strings.ReplaceAll(backupReport.BackupParamsString, "\n", ", "))
+/*
+ * The plugin config flag has been changed to point to the per-backup copy of
+ * the plugin configuration by now, so the file the user passed is recorded.
+ */
+func (b *Backup) writeOptionsSnapshot(pluginConfigFile string) {
+	snapshot, err := utils.NewOptionsSnapshot(b.cmdFlags, "gpbackup", version, b.connectionPool.Version.VersionString, pluginConfigFile)
+	gplog.FatalOnError(err)
+	if pluginConfigFile != "" {
+		snapshot.Options[utils.PLUGIN_CONFIG] = pluginConfigFile
+	}
+	snapshot.WriteToFileAndMakeReadOnly(b.globalFPInfo.GetOptionsFilePath())
+}
+
+func (b *Backup) doBackup() {
+	b.catalogQueryCache = make(map[string]interface{})
+	gplog.Info("Backup Timestamp = %s", b.globalFPInfo.Timestamp)
+	gplog.Info("Backup Database = %s", b.connectionPool.DBName)
+	gplog.Verbose("Backup Parameters: {%s}", strings.ReplaceAll(b.backupReport.BackupParamsString, "\n", ", "))
+	if b.MustGetFlagBool(utils.DRY_RUN) {
+		b.DoDryRun()
+		return
+	}
 
-	pluginConfigFlag := MustGetFlagString(utils.PLUGIN_CONFIG)
+	pluginConfigFlag := b.MustGetFlagString(utils.PLUGIN_CONFIG)
 	targetBackupTimestamp := ""
 	var targetBackupFPInfo backup_filepath.FilePathInfo
-	if MustGetFlagBool(utils.INCREMENTAL) {
-		targetBackupTimestamp = GetTargetBackupTimestamp()
-		targetBackupFPInfo = backup_filepath.NewFilePathInfo(globalCluster, globalFPInfo.UserSpecifiedBackupDir,
-			targetBackupTimestamp, globalFPInfo.UserSpecifiedSegPrefix)
+	if b.MustGetFlagBool(utils.INCREMENTAL) {
+		targetBackupTimestamp = b.GetTargetBackupTimestamp()
+		targetBackupFPInfo = backup_filepath.NewFilePathInfo(b.globalCluster, b.globalFPInfo.UserSpecifiedBackupDir,
+			targetBackupTimestamp, b.globalFPInfo.UserSpecifiedSegPrefix)
 
 		if pluginConfigFlag != "" {
 			// These files need to be downloaded from the remote system into the local filesystem
-			pluginConfig.MustRestoreFile(targetBackupFPInfo.GetConfigFilePath())
-			pluginConfig.MustRestoreFile(targetBackupFPInfo.GetTOCFilePath())
-			pluginConfig.MustRestoreFile(targetBackupFPInfo.GetPluginConfigPath())
+			b.pluginConfig.MustRestoreFile(targetBackupFPInfo.GetConfigFilePath())
+			b.pluginConfig.MustRestoreFile(targetBackupFPInfo.GetTOCFilePath())
+			b.pluginConfig.MustRestoreFile(targetBackupFPInfo.GetPluginConfigPath())
 		}
 	}
 
 	gplog.Info("Gathering table state information")
-	metadataTables, dataTables := RetrieveAndProcessTables()
-	if !(MustGetFlagBool(utils.METADATA_ONLY) || MustGetFlagBool(utils.DATA_ONLY)) {
-		BackupIncrementalMetadata()
+	b.startBackupPhase("table state")
+	metadataTables, dataTables := b.RetrieveAndProcessTables()
+	tableSizes := b.AddTableClassificationToReport(metadataTables, dataTables)
+	RunVerificationQueries(b.connectionPool, b.backupReport.VerificationQueries)
+	if !(b.MustGetFlagBool(utils.METADATA_ONLY) || b.MustGetFlagBool(utils.DATA_ONLY)) {
+		b.BackupIncrementalMetadata()
 	}
-	CheckTablesContainData(dataTables)
-	metadataFilename := globalFPInfo.GetMetadataFilePath()
+	b.CheckTablesContainData(dataTables)
+	b.ValidateMaskingRules(dataTables)
+	metadataFilename := b.globalFPInfo.GetMetadataFilePath()
 	gplog.Info("Metadata will be written to %s", metadataFilename)
-	metadataFile := utils.NewFileWithByteCountFromFile(metadataFilename)
+	if b.MustGetFlagBool(utils.DETERMINISTIC) {
+		b.statementMiddleware = append(b.statementMiddleware, NormalizeWhitespace)
+	}
+	if b.MustGetFlagString(utils.TARGET) == TARGET_POSTGRES {
+		b.statementMiddleware = append(b.statementMiddleware, NewPostgresTargetMiddleware())
+	}
+	// The Backup's own middleware is given the statements as they will be restored
+	b.statementMiddleware = append(b.statementMiddleware, b.StatementMiddleware...)
+	metadataFile, metadataBuffer := b.newMetadataFile(metadataFilename)
 
-	BackupSessionGUCs(metadataFile)
-	if !MustGetFlagBool(utils.DATA_ONLY) {
+	b.BackupSessionGUCs(metadataFile)
+	if !b.MustGetFlagBool(utils.DATA_ONLY) {
 		tableOnlyBackup := true
-		if len(MustGetFlagStringArray(utils.INCLUDE_RELATION)) == 0 {
+		if len(b.MustGetFlagStringArray(utils.INCLUDE_RELATION)) == 0 {
 			tableOnlyBackup = false
-			backupGlobal(metadataFile)
+			b.backupGlobal(metadataFile)
 		}
-		backupPredata(metadataFile, metadataTables, tableOnlyBackup)
-		backupPostdata(metadataFile)
+		b.backupPredata(metadataFile, metadataTables, tableOnlyBackup)
+		b.backupPostdata(metadataFile)
 	}
 
 	/*
@@ -181,7 +327,7 @@ func DoBackup() {
 	 * perform a metadata only backup if the database contains no tables
 	 * or only external tables
 	 */
-	if !backupReport.MetadataOnly {
+	if !b.backupReport.MetadataOnly {
 		backupSetTables := dataTables
 
 		targetBackupRestorePlan := make([]backup_history.RestorePlanEntry, 0)
@@ -190,134 +336,198 @@ func DoBackup() {
 
 			targetBackupTOC := utils.NewTOC(targetBackupFPInfo.GetTOCFilePath())
 			targetBackupRestorePlan = backup_history.ReadConfigFile(targetBackupFPInfo.GetConfigFilePath()).RestorePlan
-			backupSetTables = FilterTablesForIncremental(targetBackupTOC, globalTOC, dataTables)
+			backupSetTables = FilterTablesForIncremental(targetBackupTOC, b.globalTOC, dataTables)
 		}
 
-		backupReport.RestorePlan = PopulateRestorePlan(backupSetTables, targetBackupRestorePlan, dataTables)
+		b.backupReport.RestorePlan = b.PopulateRestorePlan(backupSetTables, targetBackupRestorePlan, dataTables)
 
-		backupData(backupSetTables)
+		if b.MustGetFlagBool(utils.LINK_UNCHANGED_DATA) {
+			backupSetTables = b.LinkUnchangedTableData(backupSetTables)
+		}
+		b.backupData(backupSetTables, tableSizes)
+		b.CheckDataSamples(backupSetTables)
 	}
 
-	if MustGetFlagBool(utils.WITH_STATS) {
-		backupStatistics(metadataTables)
+	if b.MustGetFlagBool(utils.WITH_STATS) {
+		b.backupStatistics(metadataTables)
 	}
 
-	globalTOC.WriteToFileAndMakeReadOnly(globalFPInfo.GetTOCFilePath())
-	for connNum := 0; connNum < connectionPool.NumConns; connNum++ {
-		connectionPool.MustCommit(connNum)
+	if b.MustGetFlagBool(utils.WITH_LARGE_OBJECTS) {
+		b.backupLargeObjects()
 	}
+
+	b.startBackupPhase("finalization")
+	metadataFile = b.flushMetadataBuffer(metadataFilename, metadataFile, metadataBuffer, "global", "predata", "postdata")
 	metadataFile.Close()
+	b.globalTOC.MetadataChecksum = metadataFile.Checksum()
+	b.writeTOCFile(b.globalFPInfo.GetTOCFilePath())
+	for connNum := 0; connNum < b.connectionPool.NumConns; connNum++ {
+		b.connectionPool.MustCommit(connNum)
+	}
+	splitMetadataFilenames := make([]string, 0)
+	if splitBy := b.MustGetFlagString(utils.SPLIT_METADATA); splitBy != "" {
+		splitMetadataFilenames = b.writeSplitMetadataFiles(metadataFilename, splitBy)
+	}
 	if pluginConfigFlag != "" {
-		pluginConfig.MustBackupFile(metadataFilename)
-		pluginConfig.MustBackupFile(globalFPInfo.GetTOCFilePath())
-		if MustGetFlagBool(utils.WITH_STATS) {
-			pluginConfig.MustBackupFile(globalFPInfo.GetStatisticsFilePath())
+		b.pluginConfig.MustBackupFile(metadataFilename)
+		for _, splitMetadataFilename := range splitMetadataFilenames {
+			b.pluginConfig.MustBackupFile(splitMetadataFilename)
+		}
+		b.pluginConfig.MustBackupFile(b.globalFPInfo.GetTOCFilePath())
+		if !b.backupReport.MetadataOnly {
+			b.pluginConfig.MustBackupFile(b.globalFPInfo.GetManifestFilePath())
+		}
+		if b.MustGetFlagBool(utils.WITH_STATS) {
+			b.pluginConfig.MustBackupFile(b.globalFPInfo.GetStatisticsFilePath())
 		}
-		_ = utils.CopyFile(pluginConfigFlag, globalFPInfo.GetPluginConfigPath())
-		pluginConfig.MustBackupFile(globalFPInfo.GetPluginConfigPath())
+		if b.MustGetFlagBool(utils.WITH_LARGE_OBJECTS) {
+			b.pluginConfig.MustBackupFile(b.globalFPInfo.GetLargeObjectsFilePath())
+		}
+		b.pluginConfig.MustBackupFile(b.globalFPInfo.GetOptionsFilePath())
+		_ = utils.CopyFile(pluginConfigFlag, b.globalFPInfo.GetPluginConfigPath())
+		b.pluginConfig.MustBackupFile(b.globalFPInfo.GetPluginConfigPath())
 	}
 
-	err := backup_history.WriteBackupHistory(globalFPInfo.GetBackupHistoryFilePath(), &backupReport.BackupConfig)
+	err := backup_history.WriteBackupHistory(b.globalFPInfo.GetBackupHistoryFilePath(), &b.backupReport.BackupConfig)
 	gplog.FatalOnError(err)
 }
 
-func backupGlobal(metadataFile *utils.FileWithByteCount) {
+func (b *Backup) backupGlobal(metadataFile *utils.FileWithByteCount) {
 	gplog.Info("Writing global database metadata")
+	b.startBackupPhase("global metadata")
 
-	BackupResourceQueues(metadataFile)
-	if connectionPool.Version.AtLeast("5") {
-		BackupResourceGroups(metadataFile)
+	if b.clusterGlobalsFile != "" {
+		b.backupClusterGlobalsToFile(b.clusterGlobalsFile)
+	}
+	if !b.skipClusterGlobals {
+		b.backupClusterGlobals(metadataFile)
 	}
-	BackupRoles(metadataFile)
-	BackupRoleGrants(metadataFile)
-	BackupTablespaces(metadataFile)
-	BackupCreateDatabase(metadataFile)
-	BackupDatabaseGUCs(metadataFile)
-	BackupRoleGUCs(metadataFile)
+	b.BackupCreateDatabase(metadataFile)
+	b.BackupDatabaseGUCs(metadataFile)
+	b.BackupRoleGUCs(metadataFile)
 
-	if wasTerminated {
+	if b.wasTerminated {
 		gplog.Info("Global database metadata backup incomplete")
 	} else {
 		gplog.Info("Global database metadata backup complete")
 	}
 }
 
-func backupPredata(metadataFile *utils.FileWithByteCount, tables []Table, tableOnly bool) {
-	if wasTerminated {
+/*
+ * Tablespaces follow the roles that own them.  Role GUCs are not written with
+ * the other cluster-wide objects, as they can be set for a single database,
+ * which must exist first.
+ */
+func (b *Backup) backupClusterGlobals(metadataFile *utils.FileWithByteCount) {
+	b.BackupResourceQueues(metadataFile)
+	if b.connectionPool.Version.AtLeast("5") {
+		b.BackupResourceGroups(metadataFile)
+	}
+	b.BackupRoles(metadataFile)
+	b.BackupRoleGrants(metadataFile)
+	b.BackupTablespaces(metadataFile)
+}
+
+/*
+ * The global file of an --all-databases backup is run with psql rather than
+ * restored by gprestore, so its TOC entries are not kept.
+ */
+func (b *Backup) backupClusterGlobalsToFile(filename string) {
+	gplog.Info("Writing cluster-wide global metadata to %s", filename)
+	globalsFile := utils.NewFileWithByteCountFromFile(filename)
+	databaseTOC := b.globalTOC
+	b.globalTOC = &utils.TOC{}
+	b.globalTOC.InitializeMetadataEntryMap()
+	b.BackupSessionGUCs(globalsFile)
+	b.backupClusterGlobals(globalsFile)
+	globalsFile.Close()
+	b.globalTOC = databaseTOC
+}
+
+func (b *Backup) backupPredata(metadataFile *utils.FileWithByteCount, tables []Table, tableOnly bool) {
+	if b.wasTerminated {
 		return
 	}
 	gplog.Info("Writing pre-data metadata")
+	b.startBackupPhase("pre-data metadata")
 
 	sortables := make([]Sortable, 0)
 	metadataMap := make(MetadataMap)
 	sortables = append(sortables, convertToSortableSlice(tables)...)
-	relationMetadata := GetMetadataForObjectType(connectionPool, TYPE_RELATION)
+	relationMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_RELATION)
 	addToMetadataMap(relationMetadata, metadataMap)
 
 	var protocols []ExternalProtocol
-	funcInfoMap := GetFunctionOidToInfoMap(connectionPool)
+	funcInfoMap := b.GetFunctionOidToInfoMap(b.connectionPool)
 
 	if !tableOnly {
-		BackupSchemas(metadataFile)
-		if len(MustGetFlagStringSlice(utils.INCLUDE_SCHEMA)) == 0 && connectionPool.Version.AtLeast("5") {
-			BackupExtensions(metadataFile)
+		b.BackupSchemas(metadataFile)
+		if len(b.MustGetFlagStringSlice(utils.INCLUDE_SCHEMA)) == 0 && b.connectionPool.Version.AtLeast("5") {
+			b.BackupExtensions(metadataFile)
 		}
 
-		if connectionPool.Version.AtLeast("6") {
-			BackupCollations(metadataFile)
+		if b.connectionPool.Version.AtLeast("6") {
+			b.BackupCollations(metadataFile)
 		}
 
-		procLangs := GetProceduralLanguages(connectionPool)
-		langFuncs, functionMetadata := RetrieveFunctions(&sortables, metadataMap, procLangs)
+		procLangs := GetProceduralLanguages(b.connectionPool)
+		langFuncs, functionMetadata := b.RetrieveFunctions(&sortables, metadataMap, procLangs)
 
-		if len(MustGetFlagStringSlice(utils.INCLUDE_SCHEMA)) == 0 {
-			BackupProceduralLanguages(metadataFile, procLangs, langFuncs, functionMetadata, funcInfoMap)
+		if len(b.MustGetFlagStringSlice(utils.INCLUDE_SCHEMA)) == 0 {
+			b.BackupProceduralLanguages(metadataFile, procLangs, langFuncs, functionMetadata, funcInfoMap)
 		}
-		RetrieveAndBackupTypes(metadataFile, &sortables, metadataMap)
+		b.RetrieveAndBackupTypes(metadataFile, &sortables, metadataMap)
 
-		if len(MustGetFlagStringSlice(utils.INCLUDE_SCHEMA)) == 0 &&
-			connectionPool.Version.AtLeast("6") {
-			RetrieveForeignDataWrappers(&sortables, metadataMap)
-			RetrieveForeignServers(&sortables, metadataMap)
-			RetrieveUserMappings(&sortables)
+		if b.connectionPool.Version.AtLeast("6") {
+			servers := b.RetrieveForeignServers(&sortables, metadataMap, tables)
+			b.RetrieveForeignDataWrappers(&sortables, metadataMap, servers)
+			b.RetrieveUserMappings(&sortables, servers)
 		}
 
-		protocols = RetrieveProtocols(&sortables, metadataMap)
+		protocols = b.RetrieveProtocols(&sortables, metadataMap)
 
-		if connectionPool.Version.AtLeast("5") {
-			RetrieveTSParsers(&sortables, metadataMap)
-			RetrieveTSConfigurations(&sortables, metadataMap)
-			RetrieveTSTemplates(&sortables, metadataMap)
-			RetrieveTSDictionaries(&sortables, metadataMap)
+		if b.connectionPool.Version.AtLeast("5") {
+			b.RetrieveTSParsers(&sortables, metadataMap)
+			b.RetrieveTSConfigurations(&sortables, metadataMap)
+			b.RetrieveTSTemplates(&sortables, metadataMap)
+			b.RetrieveTSDictionaries(&sortables, metadataMap)
 
-			BackupOperatorFamilies(metadataFile)
+			b.BackupOperatorFamilies(metadataFile)
 		}
 
-		RetrieveOperators(&sortables, metadataMap)
-		RetrieveOperatorClasses(&sortables, metadataMap)
-		RetrieveAggregates(&sortables, metadataMap)
-		RetrieveCasts(&sortables, metadataMap)
+		b.RetrieveOperators(&sortables, metadataMap)
+		b.RetrieveOperatorClasses(&sortables, metadataMap)
+		b.RetrieveAggregates(&sortables, metadataMap)
+		b.RetrieveCasts(&sortables, metadataMap)
+		if b.connectionPool.Version.AtLeast("7") {
+			b.RetrieveTransforms(&sortables, metadataMap)
+		}
 	}
 
-	RetrieveViews(&sortables)
-	sequences, sequenceOwnerColumns := RetrieveSequences()
-	BackupCreateSequences(metadataFile, sequences, relationMetadata)
-	constraints, conMetadata := RetrieveConstraints()
+	b.RetrieveViews(&sortables)
+	sequences, sequenceOwnerColumns := b.RetrieveSequences()
+	b.BackupCreateSequences(metadataFile, sequences, relationMetadata)
+	constraints, conMetadata := b.RetrieveConstraints()
 
-	BackupDependentObjects(metadataFile, tables, protocols, metadataMap, constraints, sortables, funcInfoMap, tableOnly)
+	b.BackupDependentObjects(metadataFile, tables, protocols, metadataMap, constraints, sortables, funcInfoMap, tableOnly)
 
-	PrintAlterSequenceStatements(metadataFile, globalTOC, sequences, sequenceOwnerColumns)
+	b.PrintAlterSequenceStatements(metadataFile, b.globalTOC, sequences, sequenceOwnerColumns)
 
-	BackupConversions(metadataFile)
-	BackupConstraints(metadataFile, constraints, conMetadata)
-	if wasTerminated {
+	if !tableOnly && b.connectionPool.Version.AtLeast("5") {
+		b.BackupOperatorFamilyMembers(metadataFile)
+	}
+
+	b.BackupConversions(metadataFile)
+	b.BackupConstraints(metadataFile, constraints, conMetadata)
+	if b.wasTerminated {
 		gplog.Info("Pre-data metadata backup incomplete")
 	} else {
 		gplog.Info("Pre-data metadata backup complete")
 	}
 }
 
-func backupData(tables []Table) {
+func (b *Backup) backupData(tables []Table, tableSizes map[uint32]int64) {
+	b.startBackupPhase("data")
 	if len(tables) == 0 {
 		// No incremental data changes to backup
 		gplog.Info("No tables to backup")
@@ -325,107 +535,178 @@ func backupData(tables []Table) {
 		return
 	}
 
-	if MustGetFlagBool(utils.SINGLE_DATA_FILE) {
+	if b.MustGetFlagBool(utils.PARQUET_EXPORT) {
+		utils.VerifyHelperVersionOnSegments(version, b.globalCluster)
+	}
+	if b.MustGetFlagBool(utils.SINGLE_DATA_FILE) {
 		gplog.Verbose("Initializing pipes and gpbackup_helper on segments for single data file backup")
-		utils.VerifyHelperVersionOnSegments(version, globalCluster)
+		utils.VerifyHelperVersionOnSegments(version, b.globalCluster)
 		oidList := make([]string, 0, len(tables))
 		for _, table := range tables {
-			if !table.SkipDataBackup() {
+			if !b.SkipDataBackup(table) {
 				oidList = append(oidList, fmt.Sprintf("%d", table.Oid))
 			}
 		}
-		utils.WriteOidListToSegments(oidList, globalCluster, globalFPInfo)
-		utils.CreateFirstSegmentPipeOnAllHosts(oidList[0], globalCluster, globalFPInfo)
-		compressStr := fmt.Sprintf(" --compression-level %d", MustGetFlagInt(utils.COMPRESSION_LEVEL))
-		if MustGetFlagBool(utils.NO_COMPRESSION) {
+		utils.WriteOidListToSegments(oidList, b.globalCluster, b.globalFPInfo)
+		utils.CreateFirstSegmentPipeOnAllHosts(oidList[0], b.globalCluster, b.globalFPInfo)
+		compressStr := fmt.Sprintf(" --compression-level %d", b.MustGetFlagInt(utils.COMPRESSION_LEVEL))
+		if b.MustGetFlagBool(utils.NO_COMPRESSION) {
 			compressStr = " --compression-level 0"
 		}
+		if b.MustGetFlagBool(utils.ADAPTIVE_COMPRESSION) {
+			compressStr += " --adaptive-compression"
+		}
 		// Do not pass through the --on-error-continue flag because it does not apply to gpbackup
-		utils.StartGpbackupHelpers(globalCluster, globalFPInfo, "--backup-agent",
-			MustGetFlagString(utils.PLUGIN_CONFIG), compressStr, false)
+		utils.StartGpbackupHelpers(b.globalCluster, b.globalFPInfo, "--backup-agent",
+			b.MustGetFlagString(utils.PLUGIN_CONFIG), compressStr, false, b.MustGetFlagInt(utils.COPY_BUFFER_SIZE)*1024)
+	}
+	if archiveFile := b.MustGetFlagString(utils.ARCHIVE_FILE); archiveFile != "" {
+		b.OpenBackupArchive(archiveFile)
+	}
+	if largeRowThreshold := b.MustGetFlagInt(utils.LARGE_ROW_THRESHOLD); largeRowThreshold > 0 {
+		b.WarnForLargeRows(tables, int64(largeRowThreshold)*1024*1024)
 	}
 	gplog.Info("Writing data to file")
-	rowsCopiedMaps := BackupDataForAllTables(tables)
-	AddTableDataEntriesToTOC(tables, rowsCopiedMaps)
-	if MustGetFlagBool(utils.SINGLE_DATA_FILE) && MustGetFlagString(utils.PLUGIN_CONFIG) != "" {
-		pluginConfig.BackupSegmentTOCs(globalCluster, globalFPInfo)
+	rowsCopiedMaps := b.BackupDataForAllTables(tables, tableSizes)
+	b.AddTableDataEntriesToTOC(tables, rowsCopiedMaps)
+	if b.MustGetFlagBool(utils.SINGLE_DATA_FILE) && b.MustGetFlagString(utils.PLUGIN_CONFIG) != "" {
+		b.pluginConfig.BackupSegmentTOCs(b.globalCluster, b.globalFPInfo)
+	}
+	if !b.wasTerminated {
+		b.WriteBackupManifest()
 	}
-	if wasTerminated {
+	if b.wasTerminated {
 		gplog.Info("Data backup incomplete")
 	} else {
 		gplog.Info("Data backup complete")
 	}
 }
 
-func backupPostdata(metadataFile *utils.FileWithByteCount) {
-	if wasTerminated {
+func (b *Backup) backupPostdata(metadataFile *utils.FileWithByteCount) {
+	if b.wasTerminated {
 		return
 	}
 	gplog.Info("Writing post-data metadata")
-
-	BackupIndexes(metadataFile)
-	BackupRules(metadataFile)
-	BackupTriggers(metadataFile)
-	if connectionPool.Version.AtLeast("6") {
-		BackupDefaultPrivileges(metadataFile)
-		if len(MustGetFlagStringSlice(utils.INCLUDE_SCHEMA)) == 0 {
-			BackupEventTriggers(metadataFile)
+	b.startBackupPhase("post-data metadata")
+
+	b.BackupIndexes(metadataFile)
+	b.BackupRules(metadataFile)
+	b.BackupTriggers(metadataFile)
+	if b.connectionPool.Version.AtLeast("7") {
+		b.BackupRowLevelSecurity(metadataFile)
+		b.BackupExtendedStatistics(metadataFile)
+	}
+	if b.connectionPool.Version.AtLeast("6") {
+		if !b.MustGetFlagBool(utils.NO_PRIVILEGES) {
+			b.BackupDefaultPrivileges(metadataFile)
+		}
+		if len(b.MustGetFlagStringSlice(utils.INCLUDE_SCHEMA)) == 0 {
+			b.BackupEventTriggers(metadataFile)
 		}
 	}
-	if wasTerminated {
+	if b.wasTerminated {
 		gplog.Info("Post-data metadata backup incomplete")
 	} else {
 		gplog.Info("Post-data metadata backup complete")
 	}
 }
 
-func backupStatistics(tables []Table) {
-	if wasTerminated {
+func (b *Backup) backupStatistics(tables []Table) {
+	if b.wasTerminated {
 		return
 	}
-	statisticsFilename := globalFPInfo.GetStatisticsFilePath()
+	statisticsFilename := b.globalFPInfo.GetStatisticsFilePath()
 	gplog.Info("Writing query planner statistics to %s", statisticsFilename)
-	statisticsFile := utils.NewFileWithByteCountFromFile(statisticsFilename)
+	b.startBackupPhase("statistics")
+	statisticsFile, statisticsBuffer := b.newMetadataFile(statisticsFilename)
 	defer statisticsFile.Close()
-	BackupStatistics(statisticsFile, tables)
-	if wasTerminated {
+	b.BackupStatistics(statisticsFile, tables)
+	b.flushMetadataBuffer(statisticsFilename, statisticsFile, statisticsBuffer, "statistics")
+	if b.wasTerminated {
 		gplog.Info("Query planner statistics backup incomplete")
 	} else {
 		gplog.Info("Query planner statistics backup complete")
 	}
 }
 
-func DoTeardown() {
+func (b *Backup) backupLargeObjects() {
+	if b.wasTerminated {
+		return
+	}
+	largeObjectsFilename := b.globalFPInfo.GetLargeObjectsFilePath()
+	gplog.Info("Writing large objects to %s", largeObjectsFilename)
+	b.startBackupPhase("large objects")
+	largeObjectsFile, largeObjectsBuffer := b.newMetadataFile(largeObjectsFilename)
+	defer largeObjectsFile.Close()
+	b.BackupLargeObjects(largeObjectsFile)
+	b.flushMetadataBuffer(largeObjectsFilename, largeObjectsFile, largeObjectsBuffer, "largeobjects")
+	if b.wasTerminated {
+		gplog.Info("Large object backup incomplete")
+	} else {
+		gplog.Info("Large object backup complete")
+	}
+}
+
+/*
+ * Cleans up after a backup and writes its report, given the value recovered
+ * from any panic that ended it, and returns the backup's exit code.
+ */
+func (b *Backup) teardownBackup(err interface{}) (errorCode int) {
 	backupFailed := false
 	defer func() {
-		DoCleanup(backupFailed)
+		/*
+		 * A failure while writing the report files or completion markers must
+		 * not prevent cleanup or hide the exit code of the backup itself.
+		 */
+		if recover() != nil {
+			backupFailed = true
+		}
+		b.DoCleanup(backupFailed)
 
-		errorCode := gplog.GetErrorCode()
-		if errorCode == 0 {
+		errorCode = utils.ExitCodeFromErrorCode(gplog.GetErrorCode())
+		if errorCode == 0 && b.MustGetFlagBool(utils.DRY_RUN) {
+			gplog.Info("Dry run completed successfully")
+		} else if errorCode == 0 {
 			gplog.Info("Backup completed successfully")
 		}
-		os.Exit(errorCode)
+		gplog.Info("Backup exit code %d: %s", errorCode, utils.ExitCodeDescription(errorCode))
+		if b.progressStream != nil {
+			status := "Success"
+			if b.wasTerminated {
+				status = "Aborted"
+			} else if backupFailed {
+				status = "Failure"
+			}
+			b.progressStream.Finished(status, errorCode)
+			b.progressStream.Close()
+			utils.SetPrintProgressBars(true)
+		}
 	}()
 
 	errStr := ""
-	if err := recover(); err != nil {
+	if err != nil {
 		// gplog's Fatal will cause a panic with error code 2
 		if gplog.GetErrorCode() != 2 {
 			gplog.Error(fmt.Sprintf("%v: %s", err, debug.Stack()))
 			gplog.SetErrorCode(2)
 		} else {
 			errStr = fmt.Sprintf("%v", err)
+			// gplog keeps its own code for a fatal error that has no more specific cause
+			if exitCode := utils.ClassifyFatalError(errStr); exitCode != utils.EXIT_FATAL {
+				gplog.SetErrorCode(exitCode)
+			}
 		}
 		backupFailed = true
 	}
-	if wasTerminated {
+	if b.wasTerminated {
 		/*
 		 * Don't print an error or create a report file if the backup was canceled,
-		 * as the signal handler will take care of cleanup and return codes.  Just
+		 * as the signal handler will take care of cleanup, the aborted backup's
+		 * report file, and return codes.  Just
 		 * wait until the signal handler's DoCleanup completes so the main goroutine
 		 * doesn't exit while cleanup is still in progress.
 		 */
-		CleanupGroup.Wait()
+		b.cleanupGroup.Wait()
 		backupFailed = true
 		return
 	}
@@ -433,85 +714,192 @@ func DoTeardown() {
 		fmt.Println(errStr)
 	}
 	errMsg := utils.ParseErrorMessage(errStr)
+	if errorContext := b.GetErrorContext().String(); errMsg != "" && errorContext != "" {
+		gplog.Error("Backup %s", errorContext)
+		errMsg = fmt.Sprintf("%s (%s)", errMsg, errorContext)
+	}
 
-	/*
-	 * Only create a report file if we fail after the cluster is initialized
-	 * and a backup directory exists in which to create the report file.
-	 */
-	if globalFPInfo.Timestamp != "" {
-		_, statErr := os.Stat(globalFPInfo.GetDirForContent(-1))
-		if statErr != nil { // Even if this isn't os.IsNotExist, don't try to write a report file in case of further errors
-			return
-		}
-		reportFilename := globalFPInfo.GetBackupReportFilePath()
-		configFilename := globalFPInfo.GetConfigFilePath()
-
-		time.Sleep(time.Second) // We sleep for 1 second to ensure multiple backups do not start within the same second.
-
-		if backupReport != nil {
-			backupReport.ConstructBackupParamsString()
-			backup_history.WriteConfigFile(&backupReport.BackupConfig, configFilename)
-			endtime, _ := time.ParseInLocation("20060102150405", backupReport.BackupConfig.EndTime, operating.System.Local)
-			backupReport.WriteBackupReportFile(reportFilename, globalFPInfo.Timestamp, endtime, objectCounts, errMsg)
-			utils.EmailReport(globalCluster, globalFPInfo.Timestamp, reportFilename, "gpbackup")
-			if pluginConfig != nil {
-				err := pluginConfig.BackupFile(configFilename)
-				if err != nil {
-					gplog.Error(fmt.Sprintf("%v", err))
-					return
-				}
-				err = pluginConfig.BackupFile(reportFilename)
-				if err != nil {
-					gplog.Error(fmt.Sprintf("%v", err))
-					return
-				}
-			}
+	if archiveFile := b.MustGetFlagString(utils.ARCHIVE_FILE); archiveFile != "" && !backupFailed && !b.MustGetFlagBool(utils.DRY_RUN) {
+		if archiveErr := b.FinishBackupArchive(archiveFile); archiveErr != nil {
+			gplog.Error(archiveErr.Error())
+			gplog.SetErrorCode(2)
+			errMsg = archiveErr.Error()
+			backupFailed = true
+		}
+	}
+
+	reportFile := b.writeReportFiles(errMsg)
+	if !backupFailed && !b.MustGetFlagBool(utils.DRY_RUN) {
+		b.WriteCompletionMarkers(!b.MustGetFlagBool(utils.METADATA_ONLY))
+	}
+	b.notifyBackupCompletion(errMsg, reportFile, backupFailed, false)
+	return
+}
+
+/*
+ * A backup that ends in a panic without a fatal error has no error message,
+ * and one that logged errors but finished has a nonzero exit code, so the
+ * event is chosen by those rather than by the message.
+ */
+func (b *Backup) notifyBackupCompletion(errMsg string, reportFile string, failed bool, aborted bool) {
+	event, status := utils.EVENT_BACKUP_SUCCESS, "Success"
+	errorCode := utils.ExitCodeFromErrorCode(gplog.GetErrorCode())
+	if aborted {
+		event, status = utils.EVENT_BACKUP_FAILURE, "Aborted"
+	} else if failed || errorCode != 0 {
+		event, status = utils.EVENT_BACKUP_FAILURE, "Failure"
+		if errMsg == "" {
+			errMsg = fmt.Sprintf("Backup exit code %d: %s", errorCode, utils.ExitCodeDescription(errorCode))
 		}
-		if pluginConfig != nil {
-			pluginConfig.CleanupPluginForBackup(globalCluster, globalFPInfo)
-			pluginConfig.DeletePluginConfigWhenEncrypting(globalCluster)
+	}
+	b.notifications.Notify(event, utils.NotificationPayload{
+		Utility:    "gpbackup",
+		Timestamp:  b.globalFPInfo.Timestamp,
+		Database:   b.MustGetFlagString(utils.DBNAME),
+		Status:     status,
+		Message:    errMsg,
+		ReportFile: reportFile,
+	})
+}
+
+/*
+ * Only create a report file if we fail after the cluster is initialized
+ * and a backup directory exists in which to create the report file.  The
+ * report file is returned if it was written.
+ */
+func (b *Backup) writeReportFiles(errMsg string) string {
+	if b.globalFPInfo.Timestamp == "" {
+		return ""
+	}
+	_, statErr := os.Stat(b.globalFPInfo.GetDirForContent(-1))
+	if statErr != nil { // Even if this isn't os.IsNotExist, don't try to write a report file in case of further errors
+		return ""
+	}
+	reportFilename := b.globalFPInfo.GetBackupReportFilePath()
+	configFilename := b.globalFPInfo.GetConfigFilePath()
+
+	time.Sleep(time.Second) // We sleep for 1 second to ensure multiple backups do not start within the same second.
+
+	reportFile := ""
+	if b.backupReport != nil {
+		b.backupReport.ConstructBackupParamsString()
+		backup_history.WriteConfigFile(&b.backupReport.BackupConfig, configFilename)
+		endtime, _ := time.ParseInLocation("20060102150405", b.backupReport.BackupConfig.EndTime, operating.System.Local)
+		b.backupReport.WriteBackupReportFile(reportFilename, b.globalFPInfo.Timestamp, endtime, b.objectCounts, errMsg)
+		reportFile = reportFilename
+		if b.pluginConfig != nil {
+			err := b.pluginConfig.BackupFile(configFilename)
+			if err != nil {
+				gplog.Error(fmt.Sprintf("%v", err))
+				return reportFile
+			}
+			err = b.pluginConfig.BackupFile(reportFilename)
+			if err != nil {
+				gplog.Error(fmt.Sprintf("%v", err))
+				return reportFile
+			}
 		}
 	}
+	if b.pluginConfig != nil {
+		b.pluginConfig.CleanupPluginForBackup(b.globalCluster, b.globalFPInfo)
+		b.pluginConfig.DeletePluginConfigWhenEncrypting(b.globalCluster)
+	}
+	return reportFile
 }
 
-func DoCleanup(backupFailed bool) {
+/*
+ * A canceled context stops the backup at the start of its next phase, in case
+ * it was canceled while there was no query in progress to cancel.
+ */
+func (b *Backup) startBackupPhase(phase string) {
+	if err := b.backupContext.Err(); err != nil {
+		gplog.Fatal(errors.Wrap(err, "Backup canceled"), "")
+	}
+	b.SetBackupPhase(phase)
+	b.backupMetrics.SetPhase(phase)
+	b.progressStream.PhaseStarted(phase)
+}
+
+/*
+ * With no --progress-file, events are written to stdout, so log messages are
+ * written to stderr instead to leave stdout to the events alone.  The same is
+ * done when the backup archive is written to stdout.
+ */
+func (b *Backup) InitializeProgressStream() {
+	var err error
+	b.progressStream, err = utils.OpenProgressStream(b.MustGetFlagString(utils.PROGRESS_FILE))
+	gplog.FatalOnError(err)
+	logOutput := os.Stdout
+	if b.MustGetFlagString(utils.PROGRESS_FILE) == "" || b.MustGetFlagString(utils.ARCHIVE_FILE) == utils.ARCHIVE_TO_STDOUT {
+		logOutput = os.Stderr
+	}
+	err = utils.InitializeProgressLogging(b.progressStream, "gpbackup", logOutput)
+	gplog.FatalOnError(err)
+	utils.SetPrintProgressBars(false)
+}
+
+func (b *Backup) DoCleanup(backupFailed bool) {
 	defer func() {
 		if err := recover(); err != nil {
 			gplog.Warn("Encountered error during cleanup: %v", err)
 		}
 		gplog.Verbose("Cleanup complete")
-		CleanupGroup.Done()
+		b.cleanupGroup.Done()
 	}()
 
 	gplog.Verbose("Beginning cleanup")
-	if globalFPInfo.Timestamp != "" {
-		if MustGetFlagBool(utils.SINGLE_DATA_FILE) {
+	if b.globalFPInfo.Timestamp != "" {
+		if b.MustGetFlagBool(utils.SINGLE_DATA_FILE) {
 			if backupFailed {
 				// Cleanup only if terminated or fataled
-				utils.CleanUpSegmentHelperProcesses(globalCluster, globalFPInfo, "backup")
+				utils.CleanUpSegmentHelperProcesses(b.globalCluster, b.globalFPInfo, "backup")
 			}
-			if wasTerminated {
+			if b.wasTerminated {
 				// It is possible for the COPY command to become orphaned if an agent process is killed
-				utils.TerminateHangingCopySessions(connectionPool, globalFPInfo, "gpbackup")
+				utils.TerminateHangingCopySessions(b.connectionPool, b.globalFPInfo, "gpbackup")
 			}
-			utils.CleanUpHelperFilesOnAllHosts(globalCluster, globalFPInfo)
+			utils.CleanUpHelperFilesOnAllHosts(b.globalCluster, b.globalFPInfo)
 		}
 	}
-	err := backupLockFile.Unlock()
-	if err != nil && backupLockFile != "" {
-		gplog.Warn("Failed to remove lock file %s.", backupLockFile)
+	if b.metricsServer != nil {
+		_ = b.metricsServer.Close()
+		b.metricsServer = nil
+	}
+	b.AbortBackupArchive()
+	err := b.backupLockFile.Unlock()
+	if err != nil && b.backupLockFile != "" {
+		gplog.Warn("Failed to remove lock file %s.", b.backupLockFile)
 	}
-	if connectionPool != nil {
+	if b.connectionPool != nil {
+		// Queries still running when gpbackup is interrupted would otherwise keep the server working
+		if b.wasTerminated {
+			CancelBackendQueries(b.connectionPool, b.backendPids)
+		}
+		if b.connectionPoolClosed != nil {
+			close(b.connectionPoolClosed)
+			b.connectionPoolClosed = nil
+		}
 		// The connection pool might still have an ongoing transaction. Try
 		// to cancel it. We need to queue a ROLLBACK to ensure the transaction
 		// cancel actually happened because the Golang Context cancel function
 		// does not block... nor is there a cancel acknowledgement function.
-		if queryCancelFunc != nil {
-			queryCancelFunc()
-			connectionPool.MustExec("ROLLBACK")
+		if b.queryCancelFunc != nil {
+			b.queryCancelFunc()
+			b.connectionPool.MustExec("ROLLBACK")
 		}
 
-		connectionPool.Close()
+		b.connectionPool.Close()
+	}
+	if b.wasTerminated && b.backupReport != nil {
+		/*
+		 * Files written before the backup was canceled are left in place for
+		 * troubleshooting, and the report marks the backup as aborted so that
+		 * they are not mistaken for a complete backup.
+		 */
+		errMsg := "Backup was terminated before it completed; its files are incomplete"
+		b.backupReport.Aborted = true
+		reportFile := b.writeReportFiles(errMsg)
+		b.notifyBackupCompletion(errMsg, reportFile, true, true)
 	}
 }
 
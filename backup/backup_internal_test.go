@@ -1,7 +1,10 @@
 package backup
 
 import (
+	"context"
+
 	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"github.com/greenplum-db/gpbackup/utils"
 	"github.com/onsi/gomega/gbytes"
 
 	. "github.com/onsi/ginkgo"
@@ -18,8 +21,39 @@ var _ = Describe("backup internal tests", func() {
 		It("returns successfully immediately if there is no table data to backup", func() {
 			emptyTableSlice := make([]Table, 0)
 
-			backupData(emptyTableSlice)
+			NewBackup(NewBackupFlagSet(), nil, nil).backupData(emptyTableSlice, nil)
 			Expect(string(log.Contents())).To(ContainSubstring("Data backup complete"))
 		})
 	})
+	Describe("newBackupState", func() {
+		It("does not keep the statement middleware of an earlier run", func() {
+			b := NewBackup(NewBackupFlagSet(), nil, nil)
+			b.statementMiddleware = append(b.statementMiddleware, NormalizeWhitespace)
+
+			b.backupState = newBackupState(b.Flags, nil, context.Background())
+
+			Expect(b.statementMiddleware).To(BeEmpty())
+		})
+	})
+	Describe("setFlagForDatabase", func() {
+		It("puts back the caller's value of the flag", func() {
+			flags := NewBackupFlagSet()
+			_ = flags.Set(utils.DBNAME, "maintenance")
+
+			reset := setFlagForDatabase(flags, utils.DBNAME, "testdb")
+			Expect(flags.Lookup(utils.DBNAME).Value.String()).To(Equal("testdb"))
+			reset()
+
+			Expect(flags.Lookup(utils.DBNAME).Value.String()).To(Equal("maintenance"))
+			Expect(flags.Changed(utils.DBNAME)).To(BeTrue())
+		})
+		It("leaves a flag the caller did not set unchanged", func() {
+			flags := NewBackupFlagSet()
+
+			setFlagForDatabase(flags, utils.BACKUP_DIR, "/backups/testdb")()
+
+			Expect(flags.Lookup(utils.BACKUP_DIR).Value.String()).To(Equal(""))
+			Expect(flags.Changed(utils.BACKUP_DIR)).To(BeFalse())
+		})
+	})
 })
@@ -30,6 +30,7 @@ var (
 	buffer         = gbytes.NewBuffer()
 	toc            *utils.TOC
 	backupfile     *utils.FileWithByteCount
+	testBackup     *backup.Backup
 )
 
 func TestBackup(t *testing.T) {
@@ -44,12 +45,9 @@ var _ = BeforeEach(func() {
 
 	backup.SetFlagDefaults(cmdFlags)
 
-	backup.SetCmdFlags(cmdFlags)
-
 	utils.SetPipeThroughProgram(utils.PipeThroughProgram{})
 
 	connectionPool, mock, stdout, stderr, logfile = testutils.SetupTestEnvironment()
-	backup.SetConnection(connectionPool)
-	backup.InitializeMetadataParams(connectionPool)
+	testBackup = testutils.SetupTestBackup(cmdFlags, connectionPool)
 	buffer = gbytes.NewBuffer()
 })
@@ -0,0 +1,419 @@
+package backup
+
+/*
+ * This file contains the subcommands for managing existing backups: listing
+ * them from the backup history, verifying their files, and deleting them.
+ */
+
+import (
+	"fmt"
+	"hash/crc32"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/greenplum-db/gp-common-go-libs/cluster"
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/iohelper"
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/greenplum-db/gpbackup/backup_filepath"
+	"github.com/greenplum-db/gpbackup/backup_history"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func NewListCommand() *cobra.Command {
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the backups that have not been deleted",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			NewBackup(cmd.Flags(), nil, nil).DoListBackups(false)
+		}}
+	SetListFlagDefaults(listCmd.Flags())
+	return listCmd
+}
+
+func NewHistoryCommand() *cobra.Command {
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "List every backup in the backup history, including deleted backups",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			NewBackup(cmd.Flags(), nil, nil).DoListBackups(true)
+		}}
+	SetListFlagDefaults(historyCmd.Flags())
+	return historyCmd
+}
+
+func SetListFlagDefaults(flagSet *pflag.FlagSet) {
+	flagSet.String(utils.DBNAME, "", "Only list backups of the specified database")
+	flagSet.Bool(utils.DEBUG, false, "Print verbose and debug log messages")
+	flagSet.Bool(utils.QUIET, false, "Suppress non-warning, non-error log messages")
+	flagSet.Bool(utils.VERBOSE, false, "Print verbose log messages")
+}
+
+func NewVerifyCommand() *cobra.Command {
+	verifyCmd := &cobra.Command{
+		Use:   "verify <timestamp>",
+		Short: "Check a backup's data files against its manifest and its metadata statements for damage",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			NewBackup(cmd.Flags(), nil, nil).DoVerifyBackup(args[0])
+		}}
+	SetManageFlagDefaults(verifyCmd.Flags())
+	verifyCmd.Flags().String(utils.PLUGIN_CONFIG, "", "The configuration file of the plugin to which the backup was written")
+	return verifyCmd
+}
+
+func NewDeleteCommand() *cobra.Command {
+	deleteCmd := &cobra.Command{
+		Use:   "delete <timestamp>",
+		Short: "Remove a backup's files from the master and segments and mark it deleted in the backup history",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			NewBackup(cmd.Flags(), nil, nil).DoDeleteBackup(args[0])
+		}}
+	SetManageFlagDefaults(deleteCmd.Flags())
+	deleteCmd.Flags().String(utils.CONFIG, "", "A YAML configuration file whose notifiers are sent a backup_deleted event")
+	_ = deleteCmd.MarkFlagRequired(utils.DBNAME)
+	return deleteCmd
+}
+
+func SetManageFlagDefaults(flagSet *pflag.FlagSet) {
+	flagSet.String(utils.BACKUP_DIR, "", "The absolute path of the directory in which the backup is located, if not the segment data directories")
+	flagSet.String(utils.DBNAME, "", "The database used to find the cluster's segments")
+	flagSet.Bool(utils.DEBUG, false, "Print verbose and debug log messages")
+	flagSet.Bool(utils.QUIET, false, "Suppress non-warning, non-error log messages")
+	flagSet.Bool(utils.VERBOSE, false, "Print verbose log messages")
+}
+
+/*
+ * The backup history is read without connecting to the database, from the
+ * master data directory given in the environment.
+ */
+func (b *Backup) DoListBackups(includeDeleted bool) {
+	b.SetLoggerVerbosity()
+	masterDataDir := operating.System.Getenv("MASTER_DATA_DIRECTORY")
+	if masterDataDir == "" {
+		gplog.Fatal(errors.Errorf("MASTER_DATA_DIRECTORY must be set to find the backup history"), "")
+	}
+	historyFilePath := path.Join(masterDataDir, "gpbackup_history.yaml")
+	if !iohelper.FileExistsAndIsReadable(historyFilePath) {
+		gplog.Info("No backups found in %s", historyFilePath)
+		return
+	}
+	history, err := backup_history.NewHistory(historyFilePath)
+	gplog.FatalOnError(err)
+	configs := FilterBackupHistory(history.BackupConfigs, b.MustGetFlagString(utils.DBNAME), includeDeleted)
+	for _, line := range FormatBackupList(configs) {
+		gplog.Info(line)
+	}
+}
+
+func FilterBackupHistory(configs []backup_history.BackupConfig, dbName string, includeDeleted bool) []backup_history.BackupConfig {
+	filtered := make([]backup_history.BackupConfig, 0)
+	for _, config := range configs {
+		if (dbName == "" || utils.UnquoteIdent(config.DatabaseName) == dbName) && (includeDeleted || config.DateDeleted == "") {
+			filtered = append(filtered, config)
+		}
+	}
+	return filtered
+}
+
+func FormatBackupList(configs []backup_history.BackupConfig) []string {
+	lines := []string{fmt.Sprintf("%-14s  %-20s  %-13s  %s", "Timestamp", "Database", "Type", "Details")}
+	for _, config := range configs {
+		backupType := "full"
+		switch {
+		case config.Incremental:
+			backupType = "incremental"
+		case config.MetadataOnly:
+			backupType = "metadata-only"
+		case config.DataOnly:
+			backupType = "data-only"
+		}
+		details := make([]string, 0)
+		if config.Plugin != "" {
+			details = append(details, fmt.Sprintf("plugin %s", path.Base(config.Plugin)))
+		}
+		if config.IsFiltered() {
+			details = append(details, "filtered")
+		}
+		if config.DateDeleted != "" {
+			details = append(details, fmt.Sprintf("deleted %s", config.DateDeleted))
+		}
+		lines = append(lines, strings.TrimSpace(fmt.Sprintf("%-14s  %-20s  %-13s  %s", config.Timestamp, config.DatabaseName, backupType, strings.Join(details, ", "))))
+	}
+	return lines
+}
+
+func (b *Backup) connectForBackupManagement(timestamp string) backup_filepath.FilePathInfo {
+	b.SetLoggerVerbosity()
+	if !backup_filepath.IsValidTimestamp(timestamp) {
+		gplog.Fatal(errors.Errorf("Timestamp %s is invalid.  Timestamps must be in the format YYYYMMDDHHMMSS.", timestamp), "")
+	}
+	b.connectionPool = dbconn.NewDBConnFromEnvironment(b.MustGetFlagString(utils.DBNAME))
+	b.connectionPool.MustConnect(1)
+	utils.ValidateGPDBVersionCompatibility(b.connectionPool)
+	b.globalCluster = cluster.NewCluster(cluster.MustGetSegmentConfiguration(b.connectionPool))
+	return backup_filepath.NewFilePathInfo(b.globalCluster, b.MustGetFlagString(utils.BACKUP_DIR), timestamp, backup_filepath.GetSegPrefix(b.connectionPool))
+}
+
+/*
+ * Verification reads the backup's files and the segment configuration, but
+ * never the backed-up database itself, so it connects to template1 unless
+ * another database is given.
+ */
+func (b *Backup) DoVerifyBackup(timestamp string) {
+	if b.MustGetFlagString(utils.DBNAME) == "" {
+		_ = b.cmdFlags.Set(utils.DBNAME, "template1")
+	}
+	fpInfo := b.connectForBackupManagement(timestamp)
+	defer b.connectionPool.Close()
+	gplog.Info("Verifying backup %s", timestamp)
+
+	if pluginConfigFile := b.MustGetFlagString(utils.PLUGIN_CONFIG); pluginConfigFile != "" {
+		var err error
+		b.pluginConfig, err = utils.ReadPluginConfig(pluginConfigFile)
+		gplog.FatalOnError(err)
+		b.pluginConfig.CheckPluginExistsOnAllHosts(b.globalCluster)
+		b.pluginConfig.CopyPluginConfigToAllHosts(b.globalCluster)
+		b.pluginConfig.SetupPluginForRestore(b.globalCluster, fpInfo)
+		defer b.pluginConfig.CleanupPluginForRestore(b.globalCluster, fpInfo)
+		for _, filePath := range []string{fpInfo.GetConfigFilePath(), fpInfo.GetTOCFilePath(), fpInfo.GetMetadataFilePath()} {
+			if !iohelper.FileExistsAndIsReadable(filePath) {
+				b.pluginConfig.MustRestoreFile(filePath)
+			}
+		}
+	}
+
+	numProblems := 0
+	for _, filePath := range []string{fpInfo.GetConfigFilePath(), fpInfo.GetTOCFilePath(), fpInfo.GetMetadataFilePath()} {
+		if !iohelper.FileExistsAndIsReadable(filePath) {
+			gplog.Error("Cannot access %s", filePath)
+			numProblems++
+		}
+	}
+	if numProblems > 0 {
+		gplog.Fatal(errors.Errorf("Backup %s is missing files on the master", timestamp), "")
+	}
+
+	config := backup_history.ReadConfigFile(fpInfo.GetConfigFilePath())
+	if config.Plugin != "" && b.pluginConfig == nil {
+		gplog.Fatal(errors.Errorf("Backup %s was written to a plugin, so --plugin-config must be given to verify its data files", timestamp), "")
+	}
+	toc := utils.NewTOC(fpInfo.GetTOCFilePath())
+	toc.InitializeMetadataEntryMap()
+
+	for _, problem := range VerifyBackupMarkers(fpInfo) {
+		gplog.Error(problem)
+		numProblems++
+	}
+	for _, problem := range VerifyMetadataStatements(fpInfo, config, toc) {
+		gplog.Error(problem)
+		numProblems++
+	}
+	if config.MetadataOnly {
+		gplog.Info("Backup %s is metadata-only, so there are no data files to verify", timestamp)
+	} else {
+		for _, problem := range b.VerifyDataFiles(fpInfo, config, toc) {
+			gplog.Error(problem)
+			numProblems++
+		}
+	}
+	if numProblems > 0 {
+		gplog.Fatal(errors.Errorf("Found %d problems with backup %s", numProblems, timestamp), "")
+	}
+	gplog.Info("Backup %s is complete", timestamp)
+}
+
+/*
+ * Backups taken before completion markers were written have neither marker,
+ * so whether they completed can only be judged from their files.
+ */
+func VerifyBackupMarkers(fpInfo backup_filepath.FilePathInfo) []string {
+	completionMarkerPath := fpInfo.GetBackupMarkerFilePath(-1, backup_filepath.MARKER_COMPLETE)
+	if !iohelper.FileExistsAndIsReadable(completionMarkerPath) {
+		if iohelper.FileExistsAndIsReadable(fpInfo.GetBackupMarkerFilePath(-1, backup_filepath.MARKER_IN_PROGRESS)) {
+			return []string{fmt.Sprintf("Backup %s did not complete", fpInfo.Timestamp)}
+		}
+		gplog.Warn("Backup %s has no completion marker, so whether it completed cannot be verified", fpInfo.Timestamp)
+		return []string{}
+	}
+	marker := utils.ReadBackupMarker(completionMarkerPath)
+	if checksum := GetManifestChecksum(fpInfo.GetManifestFilePath()); checksum != marker.ManifestChecksum {
+		return []string{fmt.Sprintf("%s has checksum %s, but had checksum %s when the backup completed", fpInfo.GetManifestFilePath(), checksum, marker.ManifestChecksum)}
+	}
+	return []string{}
+}
+
+func VerifyMetadataStatements(fpInfo backup_filepath.FilePathInfo, config *backup_history.BackupConfig, toc *utils.TOC) []string {
+	gplog.Verbose("Checking metadata statements in %s", fpInfo.GetMetadataFilePath())
+	contents, err := utils.ReadFileDecompressingIfNeeded(fpInfo.GetMetadataFilePath())
+	gplog.FatalOnError(err)
+	problems := make([]string, 0)
+	if checksum := fmt.Sprintf("%08x", crc32.ChecksumIEEE(contents)); toc.MetadataChecksum != "" && checksum != toc.MetadataChecksum {
+		problems = append(problems, fmt.Sprintf("%s has checksum %s, but had checksum %s when backed up", fpInfo.GetMetadataFilePath(), checksum, toc.MetadataChecksum))
+	}
+	for _, section := range []string{"global", "predata", "postdata"} {
+		problems = append(problems, toc.FindInvalidStatements(section, contents)...)
+	}
+	if config.WithStatistics && iohelper.FileExistsAndIsReadable(fpInfo.GetStatisticsFilePath()) {
+		contents, err = utils.ReadFileDecompressingIfNeeded(fpInfo.GetStatisticsFilePath())
+		gplog.FatalOnError(err)
+		problems = append(problems, toc.FindInvalidStatements("statistics", contents)...)
+	}
+	if config.WithLargeObjects && iohelper.FileExistsAndIsReadable(fpInfo.GetLargeObjectsFilePath()) {
+		contents, err = utils.ReadFileDecompressingIfNeeded(fpInfo.GetLargeObjectsFilePath())
+		gplog.FatalOnError(err)
+		problems = append(problems, toc.FindInvalidStatements("largeobjects", contents)...)
+	}
+	return problems
+}
+
+/*
+ * The data files of a local backup are checksummed where they are, while
+ * those of a plugin backup are streamed back from the plugin storage to be
+ * checksummed, which checks that each file is there and can be read.  Plugin
+ * backups have no manifest to compare the checksums against.
+ */
+func (b *Backup) VerifyDataFiles(fpInfo backup_filepath.FilePathInfo, config *backup_history.BackupConfig, toc *utils.TOC) []string {
+	sources := GetDataFileSources(config, toc)
+	recorded := make(map[int][]utils.ManifestEntry)
+	timestamps := make([]string, 0, len(sources))
+	for timestamp := range sources {
+		timestamps = append(timestamps, timestamp)
+	}
+	sort.Strings(timestamps)
+	for _, timestamp := range timestamps {
+		sourceFPInfo := fpInfo
+		sourceFPInfo.Timestamp = timestamp
+		if !iohelper.FileExistsAndIsReadable(sourceFPInfo.GetManifestFilePath()) {
+			if config.Plugin == "" {
+				gplog.Warn("Backup %s has no manifest, so only the presence of its data files can be verified", timestamp)
+			}
+			continue
+		}
+		for contentID, entries := range utils.NewManifest(sourceFPInfo.GetManifestFilePath()).Segments {
+			recorded[contentID] = append(recorded[contentID], entries...)
+		}
+	}
+
+	remoteOutput := b.globalCluster.GenerateAndExecuteCommand("Computing checksums of data files", func(contentID int) string {
+		if config.Plugin != "" {
+			commands := []string{"set -o pipefail"}
+			for _, file := range GetExpectedDataFiles(fpInfo, contentID, config, sources) {
+				commands = append(commands, fmt.Sprintf(`if output=$(%s restore_data %s %s | cksum); then echo "$output %s"; fi`,
+					b.pluginConfig.ExecutablePath, b.pluginConfig.ConfigPath, file, file))
+			}
+			return strings.Join(commands, "; ")
+		}
+		dirs := make([]string, 0, len(timestamps))
+		for _, timestamp := range timestamps {
+			sourceFPInfo := fpInfo
+			sourceFPInfo.Timestamp = timestamp
+			dirs = append(dirs, sourceFPInfo.GetDirForContent(contentID))
+		}
+		return fmt.Sprintf("find %s -maxdepth 1 -type f -name 'gpbackup_*' -exec cksum {} + 2>/dev/null; true", strings.Join(dirs, " "))
+	}, cluster.ON_SEGMENTS)
+	b.globalCluster.CheckClusterError(remoteOutput, "Unable to compute checksums of data files", func(contentID int) string {
+		return fmt.Sprintf("Unable to compute checksums of data files for segment %d", contentID)
+	})
+
+	problems := make([]string, 0)
+	contentIDs := make([]int, 0, len(remoteOutput.Stdouts))
+	for contentID := range remoteOutput.Stdouts {
+		contentIDs = append(contentIDs, contentID)
+	}
+	sort.Ints(contentIDs)
+	for _, contentID := range contentIDs {
+		found, err := utils.ParseCksumOutput(remoteOutput.Stdouts[contentID])
+		gplog.FatalOnError(err)
+		for _, problem := range utils.CompareToManifest(GetExpectedDataFiles(fpInfo, contentID, config, sources), found, recorded[contentID]) {
+			problems = append(problems, fmt.Sprintf("Segment %d: %s", contentID, problem))
+		}
+	}
+	return problems
+}
+
+/*
+ * Incremental backups restore table data from the backups they were taken
+ * from, so a backup cannot be deleted while a later one depends on it.
+ */
+func (b *Backup) DoDeleteBackup(timestamp string) {
+	notifications, err := utils.ReadNotifications(b.MustGetFlagString(utils.CONFIG))
+	gplog.FatalOnError(err)
+	fpInfo := b.connectForBackupManagement(timestamp)
+	defer b.connectionPool.Close()
+
+	historyFilePath := fpInfo.GetBackupHistoryFilePath()
+	if !iohelper.FileExistsAndIsReadable(historyFilePath) {
+		gplog.Fatal(errors.Errorf("Backup history file %s not found", historyFilePath), "")
+	}
+	history, err := backup_history.NewHistory(historyFilePath)
+	gplog.FatalOnError(err)
+	/*
+	 * The backups of --all-databases share a timestamp, so the backup in the
+	 * given directories is told apart from the others by the database named
+	 * in its configuration file.
+	 */
+	dbName := ""
+	if configFilePath := fpInfo.GetConfigFilePath(); iohelper.FileExistsAndIsReadable(configFilePath) {
+		dbName = backup_history.ReadConfigFile(configFilePath).DatabaseName
+	} else if len(history.FindBackupConfigs(timestamp)) > 1 {
+		gplog.Fatal(errors.Errorf("Backups of several databases have timestamp %s, and the configuration file %s that names the database of this one was not found", timestamp, configFilePath), "")
+	}
+	config := history.FindBackupConfig(timestamp, dbName)
+	if config == nil {
+		gplog.Fatal(errors.Errorf("Backup %s not found in %s", timestamp, historyFilePath), "")
+	}
+	if config.DateDeleted != "" {
+		gplog.Fatal(errors.Errorf("Backup %s was already deleted on %s", timestamp, config.DateDeleted), "")
+	}
+	if dependents := GetDependentBackups(history.BackupConfigs, timestamp); len(dependents) > 0 {
+		gplog.Fatal(errors.Errorf("Backup %s cannot be deleted, as the following incremental backups depend on it: %s", timestamp, strings.Join(dependents, ", ")), "")
+	}
+
+	gplog.Info("Deleting backup %s", timestamp)
+	b.RemoveBackupSets([]OrphanedBackup{{Timestamp: timestamp, ContentIDs: b.globalCluster.ContentIDs}}, fpInfo.UserSpecifiedSegPrefix)
+	MarkBackupDeleted(history, timestamp, config.DatabaseName, backup_history.CurrentTimestamp())
+	err = history.RewriteHistoryFile(historyFilePath)
+	gplog.FatalOnError(err)
+	gplog.Info("Backup %s deleted", timestamp)
+	notifications.Notify(utils.EVENT_BACKUP_DELETED, utils.NotificationPayload{
+		Utility:   "gpbackup",
+		Timestamp: timestamp,
+		Database:  config.DatabaseName,
+		Status:    "Deleted",
+		Message:   "Deleted by gpbackup delete",
+	})
+}
+
+func GetDependentBackups(configs []backup_history.BackupConfig, timestamp string) []string {
+	dependents := make([]string, 0)
+	for _, config := range configs {
+		if config.Timestamp == timestamp || config.DateDeleted != "" {
+			continue
+		}
+		for _, entry := range config.RestorePlan {
+			if entry.Timestamp == timestamp {
+				dependents = append(dependents, config.Timestamp)
+				break
+			}
+		}
+	}
+	sort.Strings(dependents)
+	return dependents
+}
+
+func MarkBackupDeleted(history *backup_history.History, timestamp string, dbName string, dateDeleted string) {
+	for i := range history.BackupConfigs {
+		if history.BackupConfigs[i].Timestamp == timestamp && history.BackupConfigs[i].DatabaseName == dbName {
+			history.BackupConfigs[i].DateDeleted = dateDeleted
+		}
+	}
+}
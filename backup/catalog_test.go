@@ -0,0 +1,74 @@
+package backup_test
+
+import (
+	"github.com/greenplum-db/gpbackup/backup"
+	"github.com/greenplum-db/gpbackup/backup_history"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backup/catalog tests", func() {
+	configs := []backup_history.BackupConfig{
+		{Timestamp: "20190103000000", DatabaseName: "testdb", Incremental: true, RestorePlan: []backup_history.RestorePlanEntry{{Timestamp: "20190101000000"}, {Timestamp: "20190103000000"}}},
+		{Timestamp: "20190102000000", DatabaseName: `"Other DB"`, MetadataOnly: true, DateDeleted: "20190104000000"},
+		{Timestamp: "20190101000000", DatabaseName: "testdb", Plugin: "/usr/local/bin/gpbackup_s3_plugin", IncludeSchemas: []string{"public"}},
+	}
+	Describe("FilterBackupHistory", func() {
+		It("excludes deleted backups", func() {
+			filtered := backup.FilterBackupHistory(configs, "", false)
+
+			Expect(filtered).To(HaveLen(2))
+			Expect(filtered[0].Timestamp).To(Equal("20190103000000"))
+			Expect(filtered[1].Timestamp).To(Equal("20190101000000"))
+		})
+		It("includes deleted backups when requested", func() {
+			Expect(backup.FilterBackupHistory(configs, "", true)).To(HaveLen(3))
+		})
+		It("only includes backups of the given database", func() {
+			filtered := backup.FilterBackupHistory(configs, "Other DB", true)
+
+			Expect(filtered).To(HaveLen(1))
+			Expect(filtered[0].Timestamp).To(Equal("20190102000000"))
+		})
+	})
+	Describe("FormatBackupList", func() {
+		It("formats one line per backup after a header", func() {
+			Expect(backup.FormatBackupList(configs)).To(Equal([]string{
+				"Timestamp       Database              Type           Details",
+				"20190103000000  testdb                incremental",
+				`20190102000000  "Other DB"            metadata-only  deleted 20190104000000`,
+				"20190101000000  testdb                full           plugin gpbackup_s3_plugin, filtered",
+			}))
+		})
+	})
+	Describe("GetDependentBackups", func() {
+		It("returns the incremental backups that restore data from the given backup", func() {
+			Expect(backup.GetDependentBackups(configs, "20190101000000")).To(Equal([]string{"20190103000000"}))
+		})
+		It("does not consider a backup to depend on itself", func() {
+			Expect(backup.GetDependentBackups(configs, "20190103000000")).To(BeEmpty())
+		})
+	})
+	Describe("MarkBackupDeleted", func() {
+		It("sets the deletion date of the given backup", func() {
+			history := &backup_history.History{BackupConfigs: []backup_history.BackupConfig{{Timestamp: "20190101000000"}, {Timestamp: "20190102000000"}}}
+
+			backup.MarkBackupDeleted(history, "20190101000000", "", "20190105000000")
+
+			Expect(history.BackupConfigs[0].DateDeleted).To(Equal("20190105000000"))
+			Expect(history.BackupConfigs[1].DateDeleted).To(Equal(""))
+		})
+		It("marks only the backup of the given database among those with the same timestamp", func() {
+			history := &backup_history.History{BackupConfigs: []backup_history.BackupConfig{
+				{Timestamp: "20190101000000", DatabaseName: "db1"},
+				{Timestamp: "20190101000000", DatabaseName: "db2"},
+			}}
+
+			backup.MarkBackupDeleted(history, "20190101000000", "db2", "20190105000000")
+
+			Expect(history.BackupConfigs[0].DateDeleted).To(Equal(""))
+			Expect(history.BackupConfigs[1].DateDeleted).To(Equal("20190105000000"))
+		})
+	})
+})
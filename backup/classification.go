@@ -0,0 +1,169 @@
+package backup
+
+/*
+ * This file contains functions for classifying the tables in a backup by
+ * storage type and compression for the backup report.
+ */
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpbackup/utils"
+)
+
+const (
+	STORAGE_HEAP     = "heap"
+	STORAGE_AO       = "append-optimized"
+	STORAGE_AOCO     = "append-optimized column-oriented"
+	STORAGE_EXTERNAL = "external"
+	STORAGE_FOREIGN  = "foreign"
+)
+
+func GetTableStorageType(table Table) string {
+	switch {
+	case table.IsExternal:
+		return STORAGE_EXTERNAL
+	case (table.ForeignDef != ForeignTableDefinition{}):
+		return STORAGE_FOREIGN
+	}
+	// GPDB 7 records the storage of a table in its access method rather than its storage options
+	switch table.AccessMethodName {
+	case "ao_row":
+		return STORAGE_AO
+	case "ao_column":
+		return STORAGE_AOCO
+	}
+	options := parseStorageOptions(table.StorageOpts)
+	if options["appendonly"] != "true" && options["appendoptimized"] != "true" {
+		return STORAGE_HEAP
+	}
+	if options["orientation"] == "column" {
+		return STORAGE_AOCO
+	}
+	return STORAGE_AO
+}
+
+/*
+ * Append-optimized tables are compressed with zlib when only a compression
+ * level is given, and heap, external, and foreign tables are never
+ * compressed by the database.
+ */
+func GetTableCompression(table Table) string {
+	storageType := GetTableStorageType(table)
+	if storageType != STORAGE_AO && storageType != STORAGE_AOCO {
+		return "none"
+	}
+	options := parseStorageOptions(table.StorageOpts)
+	compressType, compressLevel := options["compresstype"], options["compresslevel"]
+	switch {
+	case compressType == "none" || (compressType == "" && (compressLevel == "" || compressLevel == "0")):
+		return "none"
+	case compressType == "":
+		compressType = "zlib"
+	}
+	if compressLevel == "" {
+		return compressType
+	}
+	return fmt.Sprintf("%s level %s", compressType, compressLevel)
+}
+
+func parseStorageOptions(storageOpts string) map[string]string {
+	options := make(map[string]string)
+	for _, option := range strings.Split(storageOpts, ",") {
+		keyValue := strings.SplitN(strings.TrimSpace(option), "=", 2)
+		if len(keyValue) == 2 {
+			options[strings.ToLower(keyValue[0])] = strings.ToLower(keyValue[1])
+		}
+	}
+	return options
+}
+
+/*
+ * Tables are grouped by storage type and compression.  Sizes are only shown
+ * when they were gathered, as they are not queried for metadata-only backups.
+ */
+func ClassifyTables(tables []Table, tableSizes map[uint32]int64) []utils.TableClassification {
+	type classKey struct {
+		storageType string
+		compression string
+	}
+	numTables := make(map[classKey]int)
+	sizes := make(map[classKey]int64)
+	for _, table := range tables {
+		key := classKey{GetTableStorageType(table), GetTableCompression(table)}
+		numTables[key]++
+		sizes[key] += tableSizes[table.Oid]
+	}
+
+	classifications := make([]utils.TableClassification, 0, len(numTables))
+	for key, count := range numTables {
+		classification := utils.TableClassification{StorageType: key.storageType, Compression: key.compression, NumTables: count}
+		if tableSizes != nil {
+			classification.Size = FormatByteSize(sizes[key])
+		}
+		classifications = append(classifications, classification)
+	}
+	sort.Slice(classifications, func(i, j int) bool {
+		if classifications[i].StorageType != classifications[j].StorageType {
+			return classifications[i].StorageType < classifications[j].StorageType
+		}
+		return classifications[i].Compression < classifications[j].Compression
+	})
+	return classifications
+}
+
+func CountPartitionTables(tables []Table) (int, int) {
+	numPartitioned, numLeaves := 0, 0
+	for _, table := range tables {
+		switch table.PartitionLevelInfo.Level {
+		case "p":
+			numPartitioned++
+		case "l":
+			numLeaves++
+		}
+	}
+	return numPartitioned, numLeaves
+}
+
+/*
+ * Leaf partitions are only in the backup set with --leaf-partition-data or
+ * --include-table, in which case their data is counted on its own rather than
+ * as part of their parent tables' sizes.  The sizes of all of the tables are
+ * returned so that the data backup can order its tables without querying them
+ * again; they are nil for a metadata-only backup.
+ */
+func (b *Backup) AddTableClassificationToReport(metadataTables []Table, dataTables []Table) map[uint32]int64 {
+	gplog.Verbose("Classifying tables by storage type")
+	tables := make([]Table, 0, len(metadataTables)+len(dataTables))
+	tableOids := make(map[uint32]bool)
+	for _, tableList := range [][]Table{metadataTables, dataTables} {
+		for _, table := range tableList {
+			if !tableOids[table.Oid] {
+				tableOids[table.Oid] = true
+				tables = append(tables, table)
+			}
+		}
+	}
+	numPartitioned, numLeaves := CountPartitionTables(tables)
+
+	var tableSizes, classifiedSizes map[uint32]int64
+	if !b.MustGetFlagBool(utils.METADATA_ONLY) {
+		tableSizes = b.GetTableDataSizes(b.connectionPool, tables)
+		classifiedSizes = make(map[uint32]int64, len(tableSizes))
+		for _, table := range tables {
+			if level := table.PartitionLevelInfo.Level; numLeaves > 0 && (level == "p" || level == "i") {
+				continue
+			}
+			if size, ok := tableSizes[table.Oid]; ok {
+				classifiedSizes[table.Oid] = size
+			}
+		}
+	}
+	b.backupReport.TableClassifications = ClassifyTables(tables, classifiedSizes)
+	b.backupReport.NumPartitionedTables = numPartitioned
+	b.backupReport.NumLeafPartitions = numLeaves
+	return tableSizes
+}
@@ -0,0 +1,87 @@
+package backup_test
+
+import (
+	"github.com/greenplum-db/gpbackup/backup"
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backup/classification tests", func() {
+	heapTable := backup.Table{Relation: backup.Relation{Oid: 1, Schema: "public", Name: "heap"}}
+	aoTable := backup.Table{Relation: backup.Relation{Oid: 2, Schema: "public", Name: "ao"},
+		TableDefinition: backup.TableDefinition{StorageOpts: "appendonly=true, compresslevel=5"}}
+	aocoTable := backup.Table{Relation: backup.Relation{Oid: 3, Schema: "public", Name: "aoco"},
+		TableDefinition: backup.TableDefinition{StorageOpts: "appendonly=true, orientation=column, compresstype=zstd, compresslevel=3"}}
+	externalTable := backup.Table{Relation: backup.Relation{Oid: 4, Schema: "public", Name: "ext"},
+		TableDefinition: backup.TableDefinition{IsExternal: true}}
+	foreignTable := backup.Table{Relation: backup.Relation{Oid: 5, Schema: "public", Name: "foreign"},
+		TableDefinition: backup.TableDefinition{ForeignDef: backup.ForeignTableDefinition{Oid: 5, Server: "server"}}}
+
+	Describe("GetTableStorageType", func() {
+		It("classifies each storage type", func() {
+			Expect(backup.GetTableStorageType(heapTable)).To(Equal(backup.STORAGE_HEAP))
+			Expect(backup.GetTableStorageType(aoTable)).To(Equal(backup.STORAGE_AO))
+			Expect(backup.GetTableStorageType(aocoTable)).To(Equal(backup.STORAGE_AOCO))
+			Expect(backup.GetTableStorageType(externalTable)).To(Equal(backup.STORAGE_EXTERNAL))
+			Expect(backup.GetTableStorageType(foreignTable)).To(Equal(backup.STORAGE_FOREIGN))
+		})
+		It("classifies tables by their access method in GPDB 7", func() {
+			aoRowTable := backup.Table{TableDefinition: backup.TableDefinition{AccessMethodName: "ao_row", StorageOpts: "compresstype=zstd"}}
+			aoColumnTable := backup.Table{TableDefinition: backup.TableDefinition{AccessMethodName: "ao_column"}}
+			gpdb7HeapTable := backup.Table{TableDefinition: backup.TableDefinition{AccessMethodName: "heap", StorageOpts: "fillfactor=50"}}
+
+			Expect(backup.GetTableStorageType(aoRowTable)).To(Equal(backup.STORAGE_AO))
+			Expect(backup.GetTableCompression(aoRowTable)).To(Equal("zstd"))
+			Expect(backup.GetTableStorageType(aoColumnTable)).To(Equal(backup.STORAGE_AOCO))
+			Expect(backup.GetTableStorageType(gpdb7HeapTable)).To(Equal(backup.STORAGE_HEAP))
+		})
+		It("classifies a heap table with storage options as heap", func() {
+			table := backup.Table{TableDefinition: backup.TableDefinition{StorageOpts: "fillfactor=50"}}
+			Expect(backup.GetTableStorageType(table)).To(Equal(backup.STORAGE_HEAP))
+		})
+	})
+	Describe("GetTableCompression", func() {
+		It("defaults to zlib when only a compression level is given", func() {
+			Expect(backup.GetTableCompression(aoTable)).To(Equal("zlib level 5"))
+		})
+		It("reports the compression type and level", func() {
+			Expect(backup.GetTableCompression(aocoTable)).To(Equal("zstd level 3"))
+		})
+		It("reports no compression for uncompressed and heap tables", func() {
+			uncompressed := backup.Table{TableDefinition: backup.TableDefinition{StorageOpts: "appendonly=true, compresstype=none"}}
+			Expect(backup.GetTableCompression(uncompressed)).To(Equal("none"))
+			Expect(backup.GetTableCompression(heapTable)).To(Equal("none"))
+		})
+	})
+	Describe("ClassifyTables", func() {
+		It("groups tables by storage type and compression with their total sizes", func() {
+			otherHeapTable := backup.Table{Relation: backup.Relation{Oid: 6, Schema: "public", Name: "heap2"}}
+			tables := []backup.Table{heapTable, aoTable, otherHeapTable, externalTable}
+			tableSizes := map[uint32]int64{1: 1024, 2: 2048, 6: 1024}
+
+			Expect(backup.ClassifyTables(tables, tableSizes)).To(Equal([]utils.TableClassification{
+				{StorageType: backup.STORAGE_AO, Compression: "zlib level 5", NumTables: 1, Size: "2.0 KB"},
+				{StorageType: backup.STORAGE_EXTERNAL, Compression: "none", NumTables: 1, Size: "0 bytes"},
+				{StorageType: backup.STORAGE_HEAP, Compression: "none", NumTables: 2, Size: "2.0 KB"},
+			}))
+		})
+		It("leaves out sizes when they were not gathered", func() {
+			Expect(backup.ClassifyTables([]backup.Table{heapTable}, nil)).To(Equal([]utils.TableClassification{
+				{StorageType: backup.STORAGE_HEAP, Compression: "none", NumTables: 1},
+			}))
+		})
+	})
+	Describe("CountPartitionTables", func() {
+		It("counts partitioned tables and leaf partitions", func() {
+			parent := backup.Table{TableDefinition: backup.TableDefinition{PartitionLevelInfo: backup.PartitionLevelInfo{Level: "p"}}}
+			leaf := backup.Table{TableDefinition: backup.TableDefinition{PartitionLevelInfo: backup.PartitionLevelInfo{Level: "l"}}}
+
+			numPartitioned, numLeaves := backup.CountPartitionTables([]backup.Table{parent, leaf, leaf, heapTable})
+
+			Expect(numPartitioned).To(Equal(1))
+			Expect(numLeaves).To(Equal(2))
+		})
+	})
+})
@@ -0,0 +1,291 @@
+package backup
+
+/*
+ * This file contains the "gpbackup cleanup" command, which finds backup sets
+ * left behind by failed or interrupted backups and gpbackup sessions that
+ * still hold table locks after their backup has exited, and optionally
+ * removes or terminates them.
+ */
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/greenplum-db/gp-common-go-libs/cluster"
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/greenplum-db/gpbackup/backup_filepath"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/nightlyone/lockfile"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type BackupSetState struct {
+	HasConfig    bool
+	ReportStatus string
+}
+
+type OrphanedBackup struct {
+	Timestamp  string
+	Reason     string
+	ContentIDs []int
+}
+
+type LeakedSession struct {
+	Pid      int
+	Database string
+	NumLocks int
+}
+
+func NewCleanupCommand() *cobra.Command {
+	cleanupCmd := &cobra.Command{
+		Use:   "cleanup",
+		Short: "Find and remove backup files and sessions left behind by failed backups",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			NewBackup(cmd.Flags(), nil, nil).DoCleanupArtifacts()
+		}}
+	SetCleanupFlagDefaults(cleanupCmd.Flags())
+	_ = cleanupCmd.MarkFlagRequired(utils.DBNAME)
+	return cleanupCmd
+}
+
+func SetCleanupFlagDefaults(flagSet *pflag.FlagSet) {
+	flagSet.String(utils.BACKUP_DIR, "", "The absolute path of the directory to which backups were written, if not the segment data directories")
+	flagSet.String(utils.CONFIG, "", "A YAML configuration file whose notifiers are sent a backup_deleted event for each orphaned backup removed")
+	flagSet.String(utils.DBNAME, "", "The database used to find the cluster's segments and sessions")
+	flagSet.Bool(utils.DEBUG, false, "Print verbose and debug log messages")
+	flagSet.Bool(utils.QUIET, false, "Suppress non-warning, non-error log messages")
+	flagSet.Bool(utils.REMOVE_ORPHANED_BACKUPS, false, "Remove the files of incomplete and failed backups from all segments")
+	flagSet.Bool(utils.TERMINATE_LEAKED_SESSIONS, false, "Terminate gpbackup sessions that still hold table locks when no backup is running")
+	flagSet.Bool(utils.VERBOSE, false, "Print verbose log messages")
+}
+
+func (b *Backup) DoCleanupArtifacts() {
+	b.SetLoggerVerbosity()
+	notifications, err := utils.ReadNotifications(b.MustGetFlagString(utils.CONFIG))
+	gplog.FatalOnError(err)
+	b.connectionPool = dbconn.NewDBConnFromEnvironment(b.MustGetFlagString(utils.DBNAME))
+	b.connectionPool.MustConnect(1)
+	defer b.connectionPool.Close()
+	utils.ValidateGPDBVersionCompatibility(b.connectionPool)
+	b.globalCluster = cluster.NewCluster(cluster.MustGetSegmentConfiguration(b.connectionPool))
+
+	segPrefix := backup_filepath.GetSegPrefix(b.connectionPool)
+
+	setContents := b.GetBackupSetTimestamps(segPrefix)
+	inProgress := GetBackupsInProgress(setContents)
+	masterStates := make(map[string]BackupSetState)
+	for timestamp, contentIDs := range setContents {
+		if contentIDs[0] == -1 && !inProgress[timestamp] {
+			masterStates[timestamp] = b.GetMasterBackupSetState(timestamp, segPrefix)
+		}
+	}
+	orphans := FindOrphanedBackups(setContents, masterStates, inProgress)
+	for _, orphan := range orphans {
+		gplog.Info("Orphaned backup set %s: %s", orphan.Timestamp, orphan.Reason)
+	}
+	gplog.Info("Found %d orphaned backup sets", len(orphans))
+	if len(orphans) > 0 && b.MustGetFlagBool(utils.REMOVE_ORPHANED_BACKUPS) {
+		b.RemoveBackupSets(orphans, segPrefix)
+		gplog.Info("Removed %d orphaned backup sets", len(orphans))
+		for _, orphan := range orphans {
+			notifications.Notify(utils.EVENT_BACKUP_DELETED, utils.NotificationPayload{
+				Utility:   "gpbackup",
+				Timestamp: orphan.Timestamp,
+				Database:  b.MustGetFlagString(utils.DBNAME),
+				Status:    "Deleted",
+				Message:   fmt.Sprintf("Orphaned backup set removed by gpbackup cleanup: %s", orphan.Reason),
+			})
+		}
+	}
+
+	if len(inProgress) > 0 {
+		gplog.Info("Not checking for leaked sessions, as backups are in progress")
+		return
+	}
+	sessions := GetLeakedSessions(b.connectionPool)
+	for _, session := range sessions {
+		gplog.Info("Leaked gpbackup session %d in database %s holds locks on %d tables", session.Pid, session.Database, session.NumLocks)
+	}
+	gplog.Info("Found %d leaked gpbackup sessions", len(sessions))
+	if len(sessions) > 0 && b.MustGetFlagBool(utils.TERMINATE_LEAKED_SESSIONS) {
+		TerminateSessions(b.connectionPool, sessions)
+		gplog.Info("Terminated %d leaked gpbackup sessions", len(sessions))
+	}
+}
+
+func (b *Backup) getBackupsRootForContent(contentID int, segPrefix string) string {
+	if backupDir := b.MustGetFlagString(utils.BACKUP_DIR); backupDir != "" {
+		return path.Join(backupDir, fmt.Sprintf("%s%d", segPrefix, contentID), "backups")
+	}
+	return path.Join(b.globalCluster.GetDirForContent(contentID), "backups")
+}
+
+func (b *Backup) getBackupSetDirForContent(contentID int, segPrefix string, timestamp string) string {
+	return path.Join(b.getBackupsRootForContent(contentID, segPrefix), timestamp[0:8], timestamp)
+}
+
+/*
+ * Returns the contents on which each backup timestamp has a directory, with
+ * the master, if present, listed first.
+ */
+func (b *Backup) GetBackupSetTimestamps(segPrefix string) map[string][]int {
+	remoteOutput := b.globalCluster.GenerateAndExecuteCommand("Listing backup directories", func(contentID int) string {
+		return fmt.Sprintf("ls -d %s/*/* 2>/dev/null; true", b.getBackupsRootForContent(contentID, segPrefix))
+	}, cluster.ON_SEGMENTS_AND_MASTER)
+	b.globalCluster.CheckClusterError(remoteOutput, "Unable to list backup directories", func(contentID int) string {
+		return fmt.Sprintf("Unable to list backup directories in %s", b.getBackupsRootForContent(contentID, segPrefix))
+	})
+	setContents := make(map[string][]int)
+	for _, contentID := range b.globalCluster.ContentIDs {
+		for _, timestamp := range ParseBackupTimestamps(remoteOutput.Stdouts[contentID]) {
+			setContents[timestamp] = append(setContents[timestamp], contentID)
+		}
+	}
+	return setContents
+}
+
+/*
+ * Only directories of the form <date>/<timestamp> are backup sets; anything
+ * else in a backups directory was not created by gpbackup and is left alone.
+ */
+func ParseBackupTimestamps(output string) []string {
+	timestamps := make([]string, 0)
+	for _, line := range strings.Split(output, "\n") {
+		dir, timestamp := path.Split(strings.TrimSpace(line))
+		if backup_filepath.IsValidTimestamp(timestamp) && path.Base(dir) == timestamp[0:8] {
+			timestamps = append(timestamps, timestamp)
+		}
+	}
+	return timestamps
+}
+
+/*
+ * A backup that is still running holds the lock file created by
+ * CreateBackupLockFile, which is released when its process exits.
+ */
+func GetBackupsInProgress(setContents map[string][]int) map[string]bool {
+	inProgress := make(map[string]bool)
+	for timestamp := range setContents {
+		lock, err := lockfile.New(fmt.Sprintf("/tmp/%s.lck", timestamp))
+		if err != nil {
+			continue
+		}
+		if _, err := lock.GetOwner(); err == nil {
+			inProgress[timestamp] = true
+		}
+	}
+	return inProgress
+}
+
+func (b *Backup) GetMasterBackupSetState(timestamp string, segPrefix string) BackupSetState {
+	fpInfo := backup_filepath.NewFilePathInfo(b.globalCluster, b.MustGetFlagString(utils.BACKUP_DIR), timestamp, segPrefix)
+	state := BackupSetState{}
+	if _, err := operating.System.Stat(fpInfo.GetConfigFilePath()); err == nil {
+		state.HasConfig = true
+	}
+	if contents, err := operating.System.ReadFile(fpInfo.GetBackupReportFilePath()); err == nil {
+		state.ReportStatus = GetReportStatus(string(contents))
+	}
+	return state
+}
+
+func GetReportStatus(reportContents string) string {
+	statusRegex := regexp.MustCompile(`(?m)^backup status:\s+(\S+)`)
+	if match := statusRegex.FindStringSubmatch(reportContents); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+/*
+ * Backup sets that are still in progress are never orphaned, even though
+ * their configuration file has not been written yet.
+ */
+func FindOrphanedBackups(setContents map[string][]int, masterStates map[string]BackupSetState, inProgress map[string]bool) []OrphanedBackup {
+	orphans := make([]OrphanedBackup, 0)
+	for timestamp, contentIDs := range setContents {
+		if inProgress[timestamp] {
+			continue
+		}
+		reason := ""
+		state, onMaster := masterStates[timestamp]
+		switch {
+		case !onMaster:
+			reason = "no backup files on the master"
+		case !state.HasConfig:
+			reason = "no configuration file"
+		case state.ReportStatus == "Failure" || state.ReportStatus == "Aborted":
+			reason = fmt.Sprintf("backup status is %s", state.ReportStatus)
+		default:
+			continue
+		}
+		orphans = append(orphans, OrphanedBackup{Timestamp: timestamp, Reason: reason, ContentIDs: contentIDs})
+	}
+	sort.Slice(orphans, func(i, j int) bool {
+		return orphans[i].Timestamp < orphans[j].Timestamp
+	})
+	return orphans
+}
+
+func (b *Backup) RemoveBackupSets(orphans []OrphanedBackup, segPrefix string) {
+	dirsByContent := make(map[int][]string)
+	for _, orphan := range orphans {
+		for _, contentID := range orphan.ContentIDs {
+			dirsByContent[contentID] = append(dirsByContent[contentID], b.getBackupSetDirForContent(contentID, segPrefix, orphan.Timestamp))
+		}
+	}
+	remoteOutput := b.globalCluster.GenerateAndExecuteCommand("Removing orphaned backup sets", func(contentID int) string {
+		if len(dirsByContent[contentID]) == 0 {
+			return "true"
+		}
+		return fmt.Sprintf("rm -rf %s", strings.Join(dirsByContent[contentID], " "))
+	}, cluster.ON_SEGMENTS_AND_MASTER)
+	b.globalCluster.CheckClusterError(remoteOutput, "Unable to remove orphaned backup sets", func(contentID int) string {
+		return fmt.Sprintf("Unable to remove %s", strings.Join(dirsByContent[contentID], ", "))
+	})
+}
+
+/*
+ * Every gpbackup connection sets its application name, so when no backup is
+ * running, any gpbackup session still holding ACCESS SHARE locks was left
+ * behind by a backup that exited without closing its connections.
+ */
+func GetLeakedSessions(connectionPool *dbconn.DBConn) []LeakedSession {
+	pidColumn := "pid"
+	if connectionPool.Version.Before("6") {
+		pidColumn = "procpid"
+	}
+	query := fmt.Sprintf(`
+	SELECT a.%[1]s AS pid,
+		a.datname AS database,
+		count(DISTINCT l.relation) AS numlocks
+	FROM pg_stat_activity a
+		JOIN pg_locks l ON l.pid = a.%[1]s
+	WHERE a.application_name = 'gpbackup'
+		AND l.mode = 'AccessShareLock'
+		AND l.granted
+		AND a.%[1]s <> pg_backend_pid()
+	GROUP BY a.%[1]s, a.datname
+	ORDER BY a.%[1]s`, pidColumn)
+	sessions := make([]LeakedSession, 0)
+	err := connectionPool.Select(&sessions, query)
+	gplog.FatalOnError(err)
+	return sessions
+}
+
+func TerminateSessions(connectionPool *dbconn.DBConn, sessions []LeakedSession) {
+	for _, session := range sessions {
+		// The session may have exited on its own since it was found
+		_, err := connectionPool.Exec(fmt.Sprintf("SELECT pg_terminate_backend(%d)", session.Pid))
+		if err != nil {
+			gplog.Warn("Unable to terminate session %d: %v", session.Pid, err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package backup_test
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/greenplum-db/gpbackup/backup"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backup/cleanup tests", func() {
+	Describe("ParseBackupTimestamps", func() {
+		It("returns the timestamps of backup set directories", func() {
+			output := "/data/gpseg0/backups/20190102/20190102030405\n/data/gpseg0/backups/20190103/20190103000000\n"
+
+			Expect(backup.ParseBackupTimestamps(output)).To(Equal([]string{"20190102030405", "20190103000000"}))
+		})
+		It("ignores directories not created by gpbackup", func() {
+			output := "/data/gpseg0/backups/20190102/foo\n/data/gpseg0/backups/foo/20190102030405\n/data/gpseg0/backups/20190101/20190102030405\n"
+
+			Expect(backup.ParseBackupTimestamps(output)).To(BeEmpty())
+		})
+		It("returns no timestamps when there are no backups", func() {
+			Expect(backup.ParseBackupTimestamps("")).To(BeEmpty())
+		})
+	})
+	Describe("GetReportStatus", func() {
+		It("returns the status from a backup report", func() {
+			contents := "Greenplum Database Backup Report\n\ntimestamp key:         20190102030405\nbackup status:         Failure\nbackup error:          oops\n"
+
+			Expect(backup.GetReportStatus(contents)).To(Equal("Failure"))
+		})
+		It("returns an empty string when the report has no status", func() {
+			Expect(backup.GetReportStatus("Greenplum Database Backup Report\n")).To(Equal(""))
+		})
+	})
+	Describe("FindOrphanedBackups", func() {
+		It("finds backup sets that are incomplete, failed, or missing from the master", func() {
+			setContents := map[string][]int{
+				"20190101000000": {-1, 0, 1},
+				"20190102000000": {-1, 0, 1},
+				"20190103000000": {-1, 0},
+				"20190104000000": {0, 1},
+				"20190105000000": {-1, 0, 1},
+			}
+			masterStates := map[string]backup.BackupSetState{
+				"20190101000000": {HasConfig: true, ReportStatus: "Success"},
+				"20190102000000": {HasConfig: true, ReportStatus: "Failure"},
+				"20190103000000": {HasConfig: false},
+				"20190105000000": {HasConfig: true, ReportStatus: "Aborted"},
+			}
+
+			orphans := backup.FindOrphanedBackups(setContents, masterStates, map[string]bool{})
+
+			Expect(orphans).To(Equal([]backup.OrphanedBackup{
+				{Timestamp: "20190102000000", Reason: "backup status is Failure", ContentIDs: []int{-1, 0, 1}},
+				{Timestamp: "20190103000000", Reason: "no configuration file", ContentIDs: []int{-1, 0}},
+				{Timestamp: "20190104000000", Reason: "no backup files on the master", ContentIDs: []int{0, 1}},
+				{Timestamp: "20190105000000", Reason: "backup status is Aborted", ContentIDs: []int{-1, 0, 1}},
+			}))
+		})
+		It("does not consider backups in progress to be orphaned", func() {
+			setContents := map[string][]int{"20190101000000": {-1, 0, 1}}
+
+			orphans := backup.FindOrphanedBackups(setContents, map[string]backup.BackupSetState{}, map[string]bool{"20190101000000": true})
+
+			Expect(orphans).To(BeEmpty())
+		})
+	})
+	Describe("GetLeakedSessions", func() {
+		It("returns gpbackup sessions holding ACCESS SHARE locks", func() {
+			rows := sqlmock.NewRows([]string{"pid", "database", "numlocks"}).AddRow(1234, "testdb", 3)
+			mock.ExpectQuery(`SELECT a.procpid AS pid, (.*) WHERE a.application_name = 'gpbackup' AND l.mode = 'AccessShareLock'`).WillReturnRows(rows)
+
+			sessions := backup.GetLeakedSessions(connectionPool)
+
+			Expect(sessions).To(Equal([]backup.LeakedSession{{Pid: 1234, Database: "testdb", NumLocks: 3}}))
+		})
+	})
+})
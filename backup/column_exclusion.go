@@ -0,0 +1,90 @@
+package backup
+
+/*
+ * This file contains functions for excluding individual columns of tables
+ * from the backed up data and, optionally, from their CREATE TABLE statements.
+ */
+
+import (
+	"strings"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/pkg/errors"
+)
+
+/*
+ * Parses --exclude-column values of the form schema.table.column into a map
+ * from each table's FQN to the set of its excluded columns.
+ */
+func ParseExcludedColumns(columns []string) map[string]map[string]bool {
+	excluded := make(map[string]map[string]bool)
+	for _, column := range columns {
+		columnIndex := strings.LastIndex(column, ".")
+		if columnIndex == -1 {
+			gplog.Fatal(errors.Errorf("Invalid excluded column %s.  Excluded columns must be in the format schema.table.column.", column), "")
+		}
+		tableFQN := column[:columnIndex]
+		utils.ValidateFQNs([]string{tableFQN})
+		if _, ok := excluded[tableFQN]; !ok {
+			excluded[tableFQN] = make(map[string]bool)
+		}
+		excluded[tableFQN][column[columnIndex+1:]] = true
+	}
+	return excluded
+}
+
+/*
+ * Returns copies of the tables without their excluded columns.  When only the
+ * data of a column is excluded, restoring its table fills the column with its
+ * default, which fails for a NOT NULL column without one.  When the column is
+ * also excluded from the DDL, it must not be part of the distribution key.
+ */
+func (b *Backup) ExcludeColumnsFromTables(tables []Table, excludeFromDDL bool) []Table {
+	filteredTables := make([]Table, 0, len(tables))
+	for _, table := range tables {
+		excluded, ok := b.excludedColumns[table.FQN()]
+		if !ok {
+			filteredTables = append(filteredTables, table)
+			continue
+		}
+		columnDefs := make([]ColumnDefinition, 0, len(table.ColumnDefs))
+		found := make(map[string]bool, len(excluded))
+		for _, col := range table.ColumnDefs {
+			if !excluded[col.Name] {
+				columnDefs = append(columnDefs, col)
+				continue
+			}
+			found[col.Name] = true
+			if excludeFromDDL && isDistributionKeyColumn(table.DistPolicy, col.Name) {
+				gplog.Fatal(errors.Errorf("Column %s of table %s is part of the distribution key and cannot be excluded from the table definition", col.Name, table.FQN()), "")
+			}
+			if !excludeFromDDL && col.NotNull && !col.HasDefault {
+				gplog.Warn("Column %s of table %s is NOT NULL without a default, so restoring the table's data without it will fail", col.Name, table.FQN())
+			}
+		}
+		for column := range excluded {
+			if !found[column] {
+				gplog.Fatal(errors.Errorf("Excluded column %s does not exist in table %s", column, table.FQN()), "")
+			}
+		}
+		table.ColumnDefs = columnDefs
+		filteredTables = append(filteredTables, table)
+	}
+	return filteredTables
+}
+
+func isDistributionKeyColumn(distPolicy string, column string) bool {
+	start := strings.Index(distPolicy, "(")
+	end := strings.LastIndex(distPolicy, ")")
+	if !strings.HasPrefix(distPolicy, "DISTRIBUTED BY") || start == -1 || end < start {
+		return false
+	}
+	// Key columns may be followed by an operator class
+	for _, keyColumn := range strings.Split(distPolicy[start+1:end], ",") {
+		if fields := strings.Fields(keyColumn); len(fields) > 0 && fields[0] == column {
+			return true
+		}
+	}
+	return false
+}
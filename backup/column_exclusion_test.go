@@ -0,0 +1,81 @@
+package backup_test
+
+import (
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"github.com/greenplum-db/gpbackup/backup"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("backup/column_exclusion tests", func() {
+	testTable := backup.Table{
+		Relation: backup.Relation{Schema: "public", Name: "docs"},
+		TableDefinition: backup.TableDefinition{
+			DistPolicy: "DISTRIBUTED BY (id)",
+			ColumnDefs: []backup.ColumnDefinition{{Name: "id", NotNull: true}, {Name: "title"}, {Name: "body", NotNull: true}},
+		},
+	}
+	otherTable := backup.Table{Relation: backup.Relation{Schema: "public", Name: "other"}}
+	AfterEach(func() {
+		testBackup.SetExcludedColumns(nil)
+	})
+	Describe("ParseExcludedColumns", func() {
+		It("parses columns into sets by table", func() {
+			excluded := backup.ParseExcludedColumns([]string{"public.docs.body", `public.docs."Title"`, "sales.orders.note"})
+
+			Expect(excluded).To(Equal(map[string]map[string]bool{
+				"public.docs":  {"body": true, `"Title"`: true},
+				"sales.orders": {"note": true},
+			}))
+		})
+		It("panics on a column without a table", func() {
+			defer testhelper.ShouldPanicWithMessage("Invalid excluded column body")
+			backup.ParseExcludedColumns([]string{"body"})
+		})
+		It("panics on a column of a table without a schema", func() {
+			defer testhelper.ShouldPanicWithMessage("Table docs is not correctly fully-qualified")
+			backup.ParseExcludedColumns([]string{"docs.body"})
+		})
+	})
+	Describe("ExcludeColumnsFromTables", func() {
+		It("removes excluded columns without modifying the original tables", func() {
+			testBackup.SetExcludedColumns(map[string]map[string]bool{"public.docs": {"title": true}})
+
+			tables := testBackup.ExcludeColumnsFromTables([]backup.Table{testTable, otherTable}, true)
+
+			Expect(tables).To(HaveLen(2))
+			Expect(tables[0].ColumnDefs).To(Equal([]backup.ColumnDefinition{{Name: "id", NotNull: true}, {Name: "body", NotNull: true}}))
+			Expect(tables[1]).To(Equal(otherTable))
+			Expect(testTable.ColumnDefs).To(HaveLen(3))
+		})
+		It("warns when the data of a NOT NULL column without a default is excluded", func() {
+			testBackup.SetExcludedColumns(map[string]map[string]bool{"public.docs": {"body": true}})
+
+			testBackup.ExcludeColumnsFromTables([]backup.Table{testTable}, false)
+
+			Expect(logfile).To(Say("Column body of table public.docs is NOT NULL without a default"))
+		})
+		It("panics when a distribution key column is excluded from the table definition", func() {
+			testBackup.SetExcludedColumns(map[string]map[string]bool{"public.docs": {"id": true}})
+
+			defer testhelper.ShouldPanicWithMessage("Column id of table public.docs is part of the distribution key")
+			testBackup.ExcludeColumnsFromTables([]backup.Table{testTable}, true)
+		})
+		It("panics on a column the table does not have", func() {
+			testBackup.SetExcludedColumns(map[string]map[string]bool{"public.docs": {"author": true}})
+
+			defer testhelper.ShouldPanicWithMessage("Excluded column author does not exist in table public.docs")
+			testBackup.ExcludeColumnsFromTables([]backup.Table{testTable}, false)
+		})
+	})
+	Describe("GetTableDataQuery", func() {
+		It("selects only the columns that are not excluded", func() {
+			testBackup.SetExcludedColumns(map[string]map[string]bool{"public.docs": {"body": true}})
+			tables := testBackup.ExcludeColumnsFromTables([]backup.Table{testTable}, true)
+
+			Expect(testBackup.GetTableDataQuery(tables[0])).To(Equal("SELECT id, title FROM public.docs"))
+		})
+	})
+})
@@ -5,21 +5,22 @@ package backup
  */
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/greenplum-db/gp-common-go-libs/cluster"
 	"github.com/greenplum-db/gp-common-go-libs/dbconn"
 	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/operating"
 	"github.com/greenplum-db/gpbackup/utils"
 	"gopkg.in/cheggaaa/pb.v1"
 )
 
-var (
-	tableDelim = ","
-)
-
 func ConstructTableAttributesList(columnDefs []ColumnDefinition) string {
 	names := make([]string, 0)
 	for _, col := range columnDefs {
@@ -31,9 +32,9 @@ func ConstructTableAttributesList(columnDefs []ColumnDefinition) string {
 	return ""
 }
 
-func AddTableDataEntriesToTOC(tables []Table, rowsCopiedMaps []map[uint32]int64) {
+func (b *Backup) AddTableDataEntriesToTOC(tables []Table, rowsCopiedMaps []map[uint32]int64) {
 	for _, table := range tables {
-		if !table.SkipDataBackup() {
+		if !b.SkipDataBackup(table) {
 			var rowsCopied int64
 			for _, rowsCopiedMap := range rowsCopiedMaps {
 				if val, ok := rowsCopiedMap[table.Oid]; ok {
@@ -42,7 +43,11 @@ func AddTableDataEntriesToTOC(tables []Table, rowsCopiedMaps []map[uint32]int64)
 				}
 			}
 			attributes := ConstructTableAttributesList(table.ColumnDefs)
-			globalTOC.AddMasterDataEntry(table.Schema, table.Name, table.Oid, attributes, rowsCopied, table.PartitionLevelInfo.RootName)
+			if table.IsExternal {
+				b.globalTOC.AddExternalMasterDataEntry(table.Schema, table.Name, table.Oid, attributes, rowsCopied, table.PartitionLevelInfo.RootName)
+			} else {
+				b.globalTOC.AddMasterDataEntry(table.Schema, table.Name, table.Oid, attributes, rowsCopied, table.PartitionLevelInfo.RootName)
+			}
 		}
 	}
 }
@@ -51,13 +56,101 @@ type BackupProgressCounters struct {
 	NumRegTables   int64
 	TotalRegTables int64
 	ProgressBar    utils.ProgressBar
+	TableSizes     map[uint32]int64
+	TableTimings   []utils.TableTiming
+	timingMutex    sync.Mutex
+}
+
+func (counters *BackupProgressCounters) addTableTiming(timing utils.TableTiming) {
+	counters.timingMutex.Lock()
+	defer counters.timingMutex.Unlock()
+	counters.TableTimings = append(counters.TableTimings, timing)
+}
+
+/*
+ * Returns the query selecting the rows of a table that are backed up when the
+ * table is filtered with --data-filter-file, sampled with --sample-percent,
+ * masked with --masking-rules-file, or has columns excluded with
+ * --exclude-column, or an empty string when the whole table is backed up as is.  GPDB versions before
+ * 7 have no TABLESAMPLE, so rows are sampled with random() instead.  COPY
+ * cannot read external tables directly, so their rows are always selected.
+ */
+func (b *Backup) GetTableDataQuery(table Table) string {
+	condition := b.dataFilters[table.FQN()]
+	samplePercent := b.MustGetFlagInt(utils.SAMPLE_PERCENT)
+	selectList := b.GetDataSelectList(table)
+	if condition == "" && samplePercent == 0 && selectList == "*" && !table.IsExternal {
+		return ""
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s", selectList, table.FQN())
+	conditions := make([]string, 0)
+	if samplePercent > 0 {
+		if b.connectionPool.Version.AtLeast("7") {
+			query += fmt.Sprintf(" TABLESAMPLE BERNOULLI (%d)", samplePercent)
+		} else {
+			conditions = append(conditions, fmt.Sprintf("random() < %.2f", float64(samplePercent)/100))
+		}
+	}
+	if condition != "" {
+		conditions = append(conditions, fmt.Sprintf("(%s)", condition))
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	return query
+}
+
+/*
+ * Returns the select list backing up a table's columns in the order of its
+ * attribute list, which omits excluded columns, with each masked column
+ * replaced by its expression.
+ */
+func (b *Backup) GetDataSelectList(table Table) string {
+	columnRules, masked := b.maskingRules[table.FQN()]
+	if _, excluded := b.excludedColumns[table.FQN()]; !masked && !excluded {
+		return "*"
+	}
+	selectList := make([]string, 0, len(table.ColumnDefs))
+	for _, col := range table.ColumnDefs {
+		if expression, ok := columnRules[col.Name]; ok {
+			selectList = append(selectList, fmt.Sprintf("%s AS %s", expression, col.Name))
+		} else {
+			selectList = append(selectList, col.Name)
+		}
+	}
+	return strings.Join(selectList, ", ")
+}
+
+/*
+ * Column names are given to the Parquet file without the quotes of quoted
+ * identifiers.
+ */
+func GetParquetColumns(table Table) []utils.ParquetColumn {
+	columns := make([]utils.ParquetColumn, 0, len(table.ColumnDefs))
+	for _, col := range table.ColumnDefs {
+		name := col.Name
+		if len(name) > 1 && strings.HasPrefix(name, `"`) && strings.HasSuffix(name, `"`) {
+			name = strings.Replace(name[1:len(name)-1], `""`, `"`, -1)
+		}
+		columns = append(columns, utils.ParquetColumn{Name: name, Type: col.Type})
+	}
+	return columns
+}
+
+func (b *Backup) GetParquetWriterCommand(table Table) string {
+	compressionLevel := b.MustGetFlagInt(utils.COMPRESSION_LEVEL)
+	if b.MustGetFlagBool(utils.NO_COMPRESSION) {
+		compressionLevel = 0
+	}
+	return fmt.Sprintf("%s/bin/gpbackup_helper --parquet-writer --content <SEGID> --parquet-schema %s --compression-level %d",
+		operating.System.Getenv("GPHOME"), utils.EncodeParquetSchema(GetParquetColumns(table)), compressionLevel)
 }
 
-func CopyTableOut(connectionPool *dbconn.DBConn, table Table, destinationToWrite string, connNum int) (int64, error) {
+func (b *Backup) CopyTableOut(connectionPool *dbconn.DBConn, table Table, destinationToWrite string, connNum int) (int64, error) {
 	checkPipeExistsCommand := ""
 	customPipeThroughCommand := utils.GetPipeThroughProgram().OutputCommand
 	sendToDestinationCommand := ">"
-	if MustGetFlagBool(utils.SINGLE_DATA_FILE) {
+	if b.MustGetFlagBool(utils.SINGLE_DATA_FILE) {
 		/*
 		 * The segment TOC files are always written to the segment data directory for
 		 * performance reasons, in case the user-specified directory is on a mounted
@@ -66,15 +159,27 @@ func CopyTableOut(connectionPool *dbconn.DBConn, table Table, destinationToWrite
 		 */
 		checkPipeExistsCommand = fmt.Sprintf("(test -p \"%s\" || (echo \"Pipe not found %s\">&2; exit 1)) && ", destinationToWrite, destinationToWrite)
 		customPipeThroughCommand = "cat -"
-	} else if MustGetFlagString(utils.PLUGIN_CONFIG) != "" {
-		sendToDestinationCommand = fmt.Sprintf("| %s backup_data %s", pluginConfig.ExecutablePath, pluginConfig.ConfigPath)
+	} else if b.MustGetFlagString(utils.PLUGIN_CONFIG) != "" {
+		sendToDestinationCommand = fmt.Sprintf("| %s backup_data %s", b.pluginConfig.ExecutablePath, b.pluginConfig.ConfigPath)
+	} else if b.MustGetFlagBool(utils.PARQUET_EXPORT) {
+		customPipeThroughCommand = b.GetParquetWriterCommand(table)
+		sendToDestinationCommand = "--data-file"
 	}
 
 	copyCommand := fmt.Sprintf("PROGRAM '%s%s %s %s'", checkPipeExistsCommand, customPipeThroughCommand, sendToDestinationCommand, destinationToWrite)
 
-	query := fmt.Sprintf("COPY %s TO %s WITH CSV DELIMITER '%s' ON SEGMENT IGNORE EXTERNAL PARTITIONS;", table.FQN(), copyCommand, tableDelim)
+	source := table.FQN()
+	ignoreExternalPartitions := " IGNORE EXTERNAL PARTITIONS"
+	if dataQuery := b.GetTableDataQuery(table); dataQuery != "" {
+		source = fmt.Sprintf("(%s)", dataQuery)
+		ignoreExternalPartitions = ""
+	}
+	if connectionPool.Version.AtLeast("7") {
+		ignoreExternalPartitions = ""
+	}
+	query := fmt.Sprintf("COPY %s TO %s WITH %s ON SEGMENT%s;", source, copyCommand, b.copyFormat.Options(), ignoreExternalPartitions)
 	gplog.Verbose(query)
-	result, err := connectionPool.Exec(query, connNum)
+	result, err := connectionPool.ExecContext(b.queryContext, query, connNum)
 	if err != nil {
 		return 0, err
 	}
@@ -82,8 +187,58 @@ func CopyTableOut(connectionPool *dbconn.DBConn, table Table, destinationToWrite
 	return numRows, nil
 }
 
-func BackupSingleTableData(table Table, rowsCopiedMap map[uint32]int64, counters *BackupProgressCounters, whichConn int) error {
-	if table.SkipDataBackup() {
+/*
+ * Each retry rolls the connection's transaction back to a savepoint taken
+ * before the failed COPY, so that the retry reads the table with the same
+ * snapshot as the rest of the backup, and the retried COPY overwrites the
+ * partial data file left by the failed one.  Returns the number of retries.
+ */
+func (b *Backup) CopyTableOutWithRetries(connectionPool *dbconn.DBConn, table Table, destinationToWrite string, connNum int, maxRetries int) (int64, int, error) {
+	if maxRetries == 0 {
+		rowsCopied, err := b.CopyTableOut(connectionPool, table, destinationToWrite, connNum)
+		return rowsCopied, 0, err
+	}
+	for retries := 0; ; retries++ {
+		_, err := connectionPool.Exec("SAVEPOINT gpbackup_copy", connNum)
+		if err != nil {
+			return 0, retries, err
+		}
+		rowsCopied, copyErr := b.CopyTableOut(connectionPool, table, destinationToWrite, connNum)
+		if copyErr == nil {
+			_, err = connectionPool.Exec("RELEASE SAVEPOINT gpbackup_copy", connNum)
+			return rowsCopied, retries, err
+		}
+		if retries == maxRetries || b.wasTerminated || b.queryContext.Err() != nil {
+			return 0, retries, copyErr
+		}
+		_, err = connectionPool.Exec("ROLLBACK TO SAVEPOINT gpbackup_copy", connNum)
+		if err != nil {
+			return 0, retries, copyErr
+		}
+		gplog.Warn("Backing up data for table %s failed, retrying (retry %d of %d): %v", table.FQN(), retries+1, maxRetries, copyErr)
+	}
+}
+
+/*
+ * A failed COPY can leave a truncated data file on each segment, which is
+ * removed so that it cannot be mistaken for a complete one.  Data sent to a
+ * plugin or a single data file is not written to its own file here.
+ */
+func (b *Backup) removePartialTableDataFiles(table Table) {
+	if b.MustGetFlagBool(utils.SINGLE_DATA_FILE) || b.MustGetFlagString(utils.PLUGIN_CONFIG) != "" {
+		return
+	}
+	extension := utils.GetPipeThroughProgram().Extension
+	remoteOutput := b.globalCluster.GenerateAndExecuteCommand(fmt.Sprintf("Removing partial data files for table %s", table.FQN()), func(contentID int) string {
+		return fmt.Sprintf("rm -f %s", b.globalFPInfo.GetTableBackupFilePath(contentID, table.Oid, extension, false))
+	}, cluster.ON_SEGMENTS)
+	b.globalCluster.CheckClusterError(remoteOutput, "Unable to remove partial data files", func(contentID int) string {
+		return fmt.Sprintf("Unable to remove partial data file for table %s on segment %d", table.FQN(), contentID)
+	}, true)
+}
+
+func (b *Backup) BackupSingleTableData(table Table, rowsCopiedMap map[uint32]int64, counters *BackupProgressCounters, whichConn int) error {
+	if b.SkipDataBackup(table) {
 		gplog.Verbose("Skipping data backup of table %s because it is either an external or foreign table.", table.FQN())
 	} else {
 
@@ -97,66 +252,125 @@ func BackupSingleTableData(table Table, rowsCopiedMap map[uint32]int64, counters
 		}
 
 		destinationToWrite := ""
-		if MustGetFlagBool(utils.SINGLE_DATA_FILE) {
-			destinationToWrite = fmt.Sprintf("%s_%d", globalFPInfo.GetSegmentPipePathForCopyCommand(), table.Oid)
+		if b.MustGetFlagBool(utils.SINGLE_DATA_FILE) {
+			destinationToWrite = fmt.Sprintf("%s_%d", b.globalFPInfo.GetSegmentPipePathForCopyCommand(), table.Oid)
 		} else {
-			destinationToWrite = globalFPInfo.GetTableBackupFilePathForCopyCommand(table.Oid, utils.GetPipeThroughProgram().Extension, false)
+			destinationToWrite = b.globalFPInfo.GetTableBackupFilePathForCopyCommand(table.Oid, utils.GetPipeThroughProgram().Extension, false)
+		}
+		startTime := operating.System.Now()
+		var rowsCopied int64
+		var retries int
+		var err error
+		if b.backupArchive != nil {
+			rowsCopied, err = b.CopyTableOutToArchive(b.connectionPool, table, whichConn)
+		} else {
+			rowsCopied, retries, err = b.CopyTableOutWithRetries(b.connectionPool, table, destinationToWrite, whichConn, b.MustGetFlagInt(utils.COPY_RETRIES))
+			if err != nil {
+				b.removePartialTableDataFiles(table)
+			}
 		}
-		rowsCopied, err := CopyTableOut(connectionPool, table, destinationToWrite, whichConn)
 		if err != nil {
 			return err
 		}
+		timing := utils.TableTiming{
+			Table:     table.FQN(),
+			StartTime: startTime,
+			Duration:  operating.System.Now().Sub(startTime),
+			Rows:      rowsCopied,
+			Bytes:     counters.TableSizes[table.Oid],
+			Retries:   retries,
+		}
+		gplog.Verbose("Backed up data for table %s in %s (started %s): %d rows, %.2f MB/s", timing.Table, timing.Duration.Round(time.Millisecond),
+			startTime.Format("15:04:05"), timing.Rows, timing.MBPerSecond())
+		counters.addTableTiming(timing)
 		rowsCopiedMap[table.Oid] = rowsCopied
 		counters.ProgressBar.Increment()
+		b.backupMetrics.CompleteTable(table.Oid)
+		b.progressStream.TableCompleted(table.FQN(), rowsCopied, counters.TableSizes[table.Oid])
 	}
 	return nil
 }
 
-func BackupDataForAllTables(tables []Table) []map[uint32]int64 {
+/*
+ * The sizes of the tables are queried here unless they were already gathered
+ * for the backup report, in which case tableSizes holds them.
+ */
+func (b *Backup) BackupDataForAllTables(tables []Table, tableSizes map[uint32]int64) []map[uint32]int64 {
 	var numExtOrForeignTables int64
 	for _, table := range tables {
-		if table.SkipDataBackup() {
+		if b.SkipDataBackup(table) {
 			numExtOrForeignTables++
 		}
 	}
 	counters := BackupProgressCounters{NumRegTables: 0, TotalRegTables: int64(len(tables)) - numExtOrForeignTables}
 	counters.ProgressBar = utils.NewProgressBar(int(counters.TotalRegTables), "Tables backed up: ", utils.PB_INFO)
 	counters.ProgressBar.Start()
-	rowsCopiedMaps := make([]map[uint32]int64, connectionPool.NumConns)
+	rowsCopiedMaps := make([]map[uint32]int64, b.connectionPool.NumConns)
 	/*
 	 * We break when an interrupt is received and rely on
 	 * TerminateHangingCopySessions to kill any COPY statements
 	 * in progress if they don't finish on their own.
 	 */
-	tasks := make(chan Table, len(tables))
+	orderedTables := tables
+	if tableSizes == nil {
+		tableSizes = b.GetTableDataSizes(b.connectionPool, tables)
+	}
+	counters.TableSizes = tableSizes
+	if b.connectionPool.NumConns > 1 {
+		orderedTables = b.OrderTablesBySize(tables, counters.TableSizes)
+	}
+	if b.backupMetrics != nil {
+		b.backupMetrics.StartData(counters.TotalRegTables, b.GetTableSegmentDataSizes(b.connectionPool, tables))
+	}
+	/*
+	 * All workers pull from a single shared queue, so a connection that finishes
+	 * its table early immediately takes the next one rather than waiting on a
+	 * fixed share of the tables, and starting with the largest tables keeps one
+	 * large table from being the only COPY still running at the end.
+	 */
+	batches := BatchSmallTables(orderedTables, counters.TableSizes, b.MustGetFlagInt(utils.SMALL_TABLE_BATCH_SIZE))
+	tasks := make(chan []Table, len(batches))
 	var workerPool sync.WaitGroup
 	var copyErr error
-	for connNum := 0; connNum < connectionPool.NumConns; connNum++ {
+	copyErrMutex := &sync.Mutex{}
+	// DoCleanup cancels any COPY still in progress when gpbackup is interrupted
+	b.queryContext, b.queryCancelFunc = context.WithCancel(b.backupContext)
+	for connNum := 0; connNum < b.connectionPool.NumConns; connNum++ {
 		rowsCopiedMaps[connNum] = make(map[uint32]int64)
 		workerPool.Add(1)
 		go func(whichConn int) {
 			defer workerPool.Done()
-			for table := range tasks {
-				if wasTerminated || copyErr != nil {
-					counters.ProgressBar.(*pb.ProgressBar).NotPrint = true
-					return
-				}
-				err := BackupSingleTableData(table, rowsCopiedMaps[whichConn], &counters, whichConn)
-				if err != nil {
-					copyErr = err
+			for batch := range tasks {
+				for _, table := range batch {
+					if b.wasTerminated || copyErr != nil {
+						counters.ProgressBar.(*pb.ProgressBar).NotPrint = true
+						return
+					}
+					err := b.BackupSingleTableData(table, rowsCopiedMaps[whichConn], &counters, whichConn)
+					if err != nil {
+						// Only the first failure is reported, so the table recorded is the one that caused it
+						copyErrMutex.Lock()
+						if copyErr == nil {
+							b.SetCurrentObject("table", table.FQN())
+							copyErr = err
+						}
+						copyErrMutex.Unlock()
+					}
 				}
 			}
 		}(connNum)
 	}
-	for _, table := range tables {
-		tasks <- table
+	for _, batch := range batches {
+		tasks <- batch
 	}
 	close(tasks)
 	workerPool.Wait()
+	b.queryCancelFunc = nil
+	b.queryContext = b.backupContext
 
 	var agentErr error
-	if MustGetFlagBool(utils.SINGLE_DATA_FILE) {
-		agentErr = utils.CheckAgentErrorsOnSegments(globalCluster, globalFPInfo)
+	if b.MustGetFlagBool(utils.SINGLE_DATA_FILE) {
+		agentErr = utils.CheckAgentErrorsOnSegments(b.globalCluster, b.globalFPInfo)
 	}
 
 	if copyErr != nil && agentErr != nil {
@@ -169,10 +383,103 @@ func BackupDataForAllTables(tables []Table) []map[uint32]int64 {
 	}
 
 	counters.ProgressBar.Finish()
+	b.backupReport.TableTimings = counters.TableTimings
 	printDataBackupWarnings(numExtOrForeignTables)
 	return rowsCopiedMaps
 }
 
+const SMALL_TABLE_SIZE = 1024 * 1024
+
+/*
+ * Groups consecutive tables smaller than SMALL_TABLE_SIZE into batches of up
+ * to batchSize tables, which are each backed up one after another on a single
+ * connection.  Every table in a batch still gets its own COPY statement and
+ * round trip, so batching only changes how tables are scheduled across the
+ * connections, not the cost of copying each table.  Larger tables are always
+ * backed up on their own, and the tables keep their order, which a single
+ * data file backup relies on.
+ */
+func BatchSmallTables(tables []Table, tableSizes map[uint32]int64, batchSize int) [][]Table {
+	batches := make([][]Table, 0, len(tables))
+	var smallBatch []Table
+	for _, table := range tables {
+		if batchSize <= 1 || tableSizes[table.Oid] >= SMALL_TABLE_SIZE {
+			if len(smallBatch) > 0 {
+				batches = append(batches, smallBatch)
+				smallBatch = nil
+			}
+			batches = append(batches, []Table{table})
+			continue
+		}
+		smallBatch = append(smallBatch, table)
+		if len(smallBatch) == batchSize {
+			batches = append(batches, smallBatch)
+			smallBatch = nil
+		}
+	}
+	if len(smallBatch) > 0 {
+		batches = append(batches, smallBatch)
+	}
+	return batches
+}
+
+/*
+ * Returns a copy of tables ordered from largest to smallest, keeping the
+ * original relative order for tables of the same size.
+ */
+func (b *Backup) OrderTablesBySize(tables []Table, tableSizes map[uint32]int64) []Table {
+	orderedTables := make([]Table, len(tables))
+	copy(orderedTables, tables)
+	sort.SliceStable(orderedTables, func(i, j int) bool {
+		return tableSizes[orderedTables[i].Oid] > tableSizes[orderedTables[j].Oid]
+	})
+	for _, table := range orderedTables {
+		if !b.SkipDataBackup(table) {
+			gplog.Verbose("Estimated size of table %s is %d bytes", table.FQN(), tableSizes[table.Oid])
+		}
+	}
+	return orderedTables
+}
+
+/*
+ * Returns the relations that must be locked for their data to be backed up
+ * when --lock-data-tables-only is passed.  The locks are taken before table
+ * definitions are gathered, so that concurrent DDL cannot change a table
+ * between its definition and its data being read, which means relations can
+ * only be left out by what is known from the relation list: every relation is
+ * left unlocked for a metadata-only backup, and excluded leaf partitions are
+ * left unlocked otherwise.  Relations whose data is not read are protected
+ * only by the backup transaction snapshot and not against concurrent DDL.
+ */
+func (b *Backup) GetRelationsToLockForData(relations []Relation, excludeOids []string) []Relation {
+	toLock := make([]Relation, 0)
+	if b.backupReport.MetadataOnly {
+		return toLock
+	}
+	excludeSet := utils.NewSet(excludeOids)
+	for _, relation := range relations {
+		if !excludeSet.MatchesFilter(fmt.Sprintf("%d", relation.Oid)) {
+			toLock = append(toLock, relation)
+		}
+	}
+	return toLock
+}
+
+/*
+ * COPY holds each row in memory in its entirety, so tables with rows larger
+ * than the threshold are reported before their data is backed up, as they
+ * can cause memory spikes on the segments.
+ */
+func (b *Backup) WarnForLargeRows(tables []Table, thresholdBytes int64) {
+	rowSizes := b.GetLargestRowSizes(b.connectionPool, tables, thresholdBytes)
+	for _, table := range tables {
+		if size, ok := rowSizes[table.Oid]; ok && size >= thresholdBytes {
+			gplog.Warn("Table %s contains a row of %s, which exceeds the large row threshold of %s.  Backing up this table may use a large amount of memory on the segments.",
+				table.FQN(), FormatByteSize(size), FormatByteSize(thresholdBytes))
+		}
+	}
+}
+
 func printDataBackupWarnings(numExtTables int64) {
 	if numExtTables > 0 {
 		gplog.Info("Skipped data backup of %d external/foreign table(s).", numExtTables)
@@ -180,14 +487,14 @@ func printDataBackupWarnings(numExtTables int64) {
 	}
 }
 
-func CheckTablesContainData(tables []Table) {
-	if !backupReport.MetadataOnly {
+func (b *Backup) CheckTablesContainData(tables []Table) {
+	if !b.backupReport.MetadataOnly {
 		for _, table := range tables {
-			if !table.SkipDataBackup() {
+			if !b.SkipDataBackup(table) {
 				return
 			}
 		}
 		gplog.Warn("No tables in backup set contain data. Performing metadata-only backup instead.")
-		backupReport.MetadataOnly = true
+		b.backupReport.MetadataOnly = true
 	}
 }
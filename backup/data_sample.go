@@ -0,0 +1,97 @@
+package backup
+
+/*
+ * This file contains functions for checking, once a backup's data has been
+ * written, that a random sample of the rows in its data files can be loaded
+ * with the COPY options of the backup.
+ */
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/pkg/errors"
+)
+
+func (b *Backup) GetDataSampleCommand(table Table, sampleRows int) string {
+	dataFile := b.globalFPInfo.GetTableBackupFilePathForCopyCommand(table.Oid, utils.GetPipeThroughProgram().Extension, false)
+	headerStr := ""
+	if b.copyFormat.Header {
+		headerStr = " --copy-header"
+	}
+	return fmt.Sprintf("cat %s | %s | %s/bin/gpbackup_helper --content <SEGID> --sample-rows %d --copy-format %s%s",
+		dataFile, utils.GetPipeThroughProgram().InputCommand, operating.System.Getenv("GPHOME"), sampleRows, b.copyFormat.Format, headerStr)
+}
+
+/*
+ * The sample is loaded into a randomly distributed temporary table with the
+ * backed up columns of the table, so that COPY checks the encoding of each row
+ * and its values against the columns' types without requiring the rows to be
+ * on the segments that the table's distribution policy would put them on.
+ * The table is dropped by rolling back to a savepoint, which leaves the
+ * backup's transaction usable.
+ */
+func (b *Backup) CheckTableDataSample(connectionPool *dbconn.DBConn, table Table, sampleRows int, connNum int) (int64, error) {
+	_, err := connectionPool.Exec("SAVEPOINT gpbackup_data_sample", connNum)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_, _ = connectionPool.Exec("ROLLBACK TO SAVEPOINT gpbackup_data_sample", connNum)
+		_, _ = connectionPool.Exec("RELEASE SAVEPOINT gpbackup_data_sample", connNum)
+	}()
+
+	attributes := ConstructTableAttributesList(table.ColumnDefs)
+	columns := strings.TrimSuffix(strings.TrimPrefix(attributes, "("), ")")
+	_, err = connectionPool.Exec(fmt.Sprintf("CREATE TEMP TABLE gpbackup_data_sample AS SELECT %s FROM %s LIMIT 0 DISTRIBUTED RANDOMLY;", columns, table.FQN()), connNum)
+	if err != nil {
+		return 0, errors.Wrap(err, "Unable to create table for sampled rows")
+	}
+	query := fmt.Sprintf("COPY gpbackup_data_sample%s FROM PROGRAM '%s' WITH %s ON SEGMENT;", attributes, b.GetDataSampleCommand(table, sampleRows), b.copyFormat.Options())
+	gplog.Verbose(query)
+	result, err := connectionPool.Exec(query, connNum)
+	if err != nil {
+		return 0, err
+	}
+	numRows, _ := result.RowsAffected()
+	return numRows, nil
+}
+
+/*
+ * Every table is checked and its result recorded in the report before the
+ * backup fails for any table whose sample could not be loaded.
+ */
+func (b *Backup) CheckDataSamples(tables []Table) {
+	sampleRows := b.MustGetFlagInt(utils.VERIFY_DATA_SAMPLE)
+	if sampleRows == 0 || b.wasTerminated {
+		return
+	}
+	gplog.Info("Checking a sample of %d rows of each table's data files on each segment", sampleRows)
+	b.startBackupPhase("data sample check")
+	utils.VerifyHelperVersionOnSegments(version, b.globalCluster)
+	numFailed := 0
+	for _, table := range tables {
+		if b.SkipDataBackup(table) {
+			continue
+		}
+		check := utils.DataSampleCheck{Table: table.FQN()}
+		numRows, err := b.CheckTableDataSample(b.connectionPool, table, sampleRows, 0)
+		if err != nil {
+			gplog.Error("Unable to load sampled rows of table %s: %v", table.FQN(), err)
+			check.Error = err.Error()
+			numFailed++
+		} else {
+			gplog.Verbose("Loaded %d sampled rows of table %s", numRows, table.FQN())
+			check.Rows = numRows
+		}
+		b.backupReport.DataSampleChecks = append(b.backupReport.DataSampleChecks, check)
+	}
+	if numFailed > 0 {
+		gplog.Fatal(errors.Errorf("Sampled rows of %d backed up tables could not be loaded", numFailed), "")
+	}
+	gplog.Info("Sampled rows of backed up tables loaded successfully")
+}
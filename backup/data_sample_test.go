@@ -0,0 +1,72 @@
+package backup_test
+
+import (
+	"regexp"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/greenplum-db/gpbackup/backup"
+	"github.com/greenplum-db/gpbackup/backup_filepath"
+	"github.com/greenplum-db/gpbackup/testutils"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/pkg/errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backup/data_sample tests", func() {
+	testTable := backup.Table{
+		Relation:        backup.Relation{SchemaOid: 2345, Oid: 3456, Schema: "public", Name: "foo"},
+		TableDefinition: backup.TableDefinition{ColumnDefs: []backup.ColumnDefinition{{Name: "i"}, {Name: "j"}}},
+	}
+	sampleCommand := "cat <SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456.gz | gzip -d -c | /usr/local/greenplum-db/bin/gpbackup_helper --content <SEGID> --sample-rows 100 --copy-format csv"
+	BeforeEach(func() {
+		testBackup.SetFPInfo(backup_filepath.NewFilePathInfo(testutils.SetDefaultSegmentConfiguration(), "", "20170101010101", "gpseg"))
+		utils.SetPipeThroughProgram(utils.PipeThroughProgram{Name: "gzip", OutputCommand: "gzip -c -1", InputCommand: "gzip -d -c", Extension: ".gz"})
+		operating.System.Getenv = func(key string) string {
+			return "/usr/local/greenplum-db"
+		}
+	})
+	AfterEach(func() {
+		operating.System = operating.InitializeSystemFunctions()
+	})
+	Describe("GetDataSampleCommand", func() {
+		It("samples the decompressed data file with gpbackup_helper", func() {
+			Expect(testBackup.GetDataSampleCommand(testTable, 100)).To(Equal(sampleCommand))
+		})
+		It("passes through whether the data files have a header", func() {
+			testBackup.SetCopyFormat(utils.NewCopyFormat(utils.COPY_FORMAT_CSV, "", nil, true))
+			defer testBackup.SetCopyFormat(utils.NewCopyFormat(utils.COPY_FORMAT_CSV, "", nil, false))
+
+			Expect(testBackup.GetDataSampleCommand(testTable, 100)).To(Equal(sampleCommand + " --copy-header"))
+		})
+	})
+	Describe("CheckTableDataSample", func() {
+		It("loads the sample into a temporary table and rolls it back", func() {
+			mock.ExpectExec("SAVEPOINT gpbackup_data_sample").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta("CREATE TEMP TABLE gpbackup_data_sample AS SELECT i,j FROM public.foo LIMIT 0 DISTRIBUTED RANDOMLY;")).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta("COPY gpbackup_data_sample(i,j) FROM PROGRAM '" + sampleCommand + "' WITH CSV DELIMITER ',' ON SEGMENT;")).WillReturnResult(sqlmock.NewResult(0, 300))
+			mock.ExpectExec("ROLLBACK TO SAVEPOINT gpbackup_data_sample").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("RELEASE SAVEPOINT gpbackup_data_sample").WillReturnResult(sqlmock.NewResult(0, 0))
+
+			numRows, err := testBackup.CheckTableDataSample(connectionPool, testTable, 100, 0)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(numRows).To(Equal(int64(300)))
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+		It("returns the error of a sample that cannot be loaded and rolls it back", func() {
+			mock.ExpectExec("SAVEPOINT gpbackup_data_sample").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("CREATE TEMP TABLE gpbackup_data_sample").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("COPY gpbackup_data_sample").WillReturnError(errors.New(`invalid input syntax for integer: "abc"`))
+			mock.ExpectExec("ROLLBACK TO SAVEPOINT gpbackup_data_sample").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("RELEASE SAVEPOINT gpbackup_data_sample").WillReturnResult(sqlmock.NewResult(0, 0))
+
+			_, err := testBackup.CheckTableDataSample(connectionPool, testTable, 100, 0)
+
+			Expect(err).To(MatchError(`invalid input syntax for integer: "abc"`))
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+})
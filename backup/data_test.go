@@ -5,13 +5,17 @@ import (
 	"regexp"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
 	"github.com/greenplum-db/gpbackup/backup"
 	"github.com/greenplum-db/gpbackup/backup_history"
 	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/pkg/errors"
 	"gopkg.in/cheggaaa/pb.v1"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
 )
 
 var _ = Describe("backup/data tests", func() {
@@ -40,7 +44,7 @@ var _ = Describe("backup/data tests", func() {
 		)
 		BeforeEach(func() {
 			toc = &utils.TOC{}
-			backup.SetTOC(toc)
+			testBackup.SetTOC(toc)
 			rowsCopiedMaps = make([]map[uint32]int64, connectionPool.NumConns)
 			columnDefs := []backup.ColumnDefinition{{Oid: 1, Name: "a"}}
 			table = backup.Table{
@@ -50,24 +54,92 @@ var _ = Describe("backup/data tests", func() {
 		})
 		It("adds an entry for a regular table to the TOC", func() {
 			tables := []backup.Table{table}
-			backup.AddTableDataEntriesToTOC(tables, rowsCopiedMaps)
+			testBackup.AddTableDataEntriesToTOC(tables, rowsCopiedMaps)
 			expectedDataEntries := []utils.MasterDataEntry{{Schema: "public", Name: "table", Oid: 1, AttributeString: "(a)"}}
 			Expect(toc.DataEntries).To(Equal(expectedDataEntries))
 		})
 		It("does not add an entry for an external table to the TOC", func() {
 			table.IsExternal = true
 			tables := []backup.Table{table}
-			backup.AddTableDataEntriesToTOC(tables, rowsCopiedMaps)
+			testBackup.AddTableDataEntriesToTOC(tables, rowsCopiedMaps)
+			Expect(toc.DataEntries).To(BeNil())
+		})
+		It("adds an entry for a readable external table to the TOC when --with-external-data is passed", func() {
+			_ = cmdFlags.Set(utils.WITH_EXTERNAL_DATA, "true")
+			table.IsExternal = true
+			tables := []backup.Table{table}
+			testBackup.AddTableDataEntriesToTOC(tables, rowsCopiedMaps)
+			expectedDataEntries := []utils.MasterDataEntry{{Schema: "public", Name: "table", Oid: 1, AttributeString: "(a)", IsExternal: true}}
+			Expect(toc.DataEntries).To(Equal(expectedDataEntries))
+		})
+		It("does not add an entry for a writable external table to the TOC", func() {
+			_ = cmdFlags.Set(utils.WITH_EXTERNAL_DATA, "true")
+			table.IsExternal = true
+			table.ExtTableDef.Writable = true
+			tables := []backup.Table{table}
+			testBackup.AddTableDataEntriesToTOC(tables, rowsCopiedMaps)
 			Expect(toc.DataEntries).To(BeNil())
 		})
 		It("does not add an entry for a foreign table to the TOC", func() {
 			foreignDef := backup.ForeignTableDefinition{Oid: 23, Options: "", Server: "fs"}
 			table.ForeignDef = foreignDef
 			tables := []backup.Table{table}
-			backup.AddTableDataEntriesToTOC(tables, rowsCopiedMaps)
+			testBackup.AddTableDataEntriesToTOC(tables, rowsCopiedMaps)
 			Expect(toc.DataEntries).To(BeNil())
 		})
 	})
+	Describe("OrderTablesBySize", func() {
+		tableOne := backup.Table{Relation: backup.Relation{Oid: 1, Schema: "public", Name: "table_one"}}
+		tableTwo := backup.Table{Relation: backup.Relation{Oid: 2, Schema: "public", Name: "table_two"}}
+		tableThree := backup.Table{Relation: backup.Relation{Oid: 3, Schema: "public", Name: "table_three"}}
+
+		It("orders tables from largest to smallest", func() {
+			tables := []backup.Table{tableOne, tableTwo, tableThree}
+			tableSizes := map[uint32]int64{1: 100, 2: 3000, 3: 20}
+
+			orderedTables := testBackup.OrderTablesBySize(tables, tableSizes)
+
+			Expect(orderedTables).To(Equal([]backup.Table{tableTwo, tableOne, tableThree}))
+			Expect(tables).To(Equal([]backup.Table{tableOne, tableTwo, tableThree}))
+		})
+		It("keeps the original order for tables of the same or unknown size", func() {
+			tables := []backup.Table{tableOne, tableTwo, tableThree}
+			tableSizes := map[uint32]int64{3: 20}
+
+			orderedTables := testBackup.OrderTablesBySize(tables, tableSizes)
+
+			Expect(orderedTables).To(Equal([]backup.Table{tableThree, tableOne, tableTwo}))
+		})
+	})
+	Describe("BatchSmallTables", func() {
+		tableOne := backup.Table{Relation: backup.Relation{Oid: 1, Schema: "public", Name: "table_one"}}
+		tableTwo := backup.Table{Relation: backup.Relation{Oid: 2, Schema: "public", Name: "table_two"}}
+		tableThree := backup.Table{Relation: backup.Relation{Oid: 3, Schema: "public", Name: "table_three"}}
+		tableFour := backup.Table{Relation: backup.Relation{Oid: 4, Schema: "public", Name: "table_four"}}
+		tables := []backup.Table{tableOne, tableTwo, tableThree, tableFour}
+
+		It("puts each table in its own batch when the batch size is 1", func() {
+			tableSizes := map[uint32]int64{1: 10, 2: 10, 3: 10, 4: 10}
+
+			batches := backup.BatchSmallTables(tables, tableSizes, 1)
+
+			Expect(batches).To(Equal([][]backup.Table{{tableOne}, {tableTwo}, {tableThree}, {tableFour}}))
+		})
+		It("groups small tables into batches of up to the batch size", func() {
+			tableSizes := map[uint32]int64{1: 10, 2: 10, 3: 10, 4: 10}
+
+			batches := backup.BatchSmallTables(tables, tableSizes, 3)
+
+			Expect(batches).To(Equal([][]backup.Table{{tableOne, tableTwo, tableThree}, {tableFour}}))
+		})
+		It("backs up large tables on their own and keeps the original table order", func() {
+			tableSizes := map[uint32]int64{1: 10, 2: backup.SMALL_TABLE_SIZE, 3: 10, 4: 10}
+
+			batches := backup.BatchSmallTables(tables, tableSizes, 3)
+
+			Expect(batches).To(Equal([][]backup.Table{{tableOne}, {tableTwo}, {tableThree, tableFour}}))
+		})
+	})
 	Describe("CopyTableOut", func() {
 		testTable := backup.Table{Relation: backup.Relation{SchemaOid: 2345, Oid: 3456, Schema: "public", Name: "foo"}}
 		It("will back up a table to its own file with compression", func() {
@@ -76,20 +148,32 @@ var _ = Describe("backup/data tests", func() {
 			mock.ExpectExec(execStr).WillReturnResult(sqlmock.NewResult(10, 0))
 			filename := "<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456.gz"
 
-			_, err := backup.CopyTableOut(connectionPool, testTable, filename, defaultConnNum)
+			_, err := testBackup.CopyTableOut(connectionPool, testTable, filename, defaultConnNum)
 
 			Expect(err).ShouldNot(HaveOccurred())
 		})
 		It("will back up a table to its own file with compression using a plugin", func() {
 			_ = cmdFlags.Set(utils.PLUGIN_CONFIG, "/tmp/plugin_config")
 			pluginConfig := utils.PluginConfig{ExecutablePath: "/tmp/fake-plugin.sh", ConfigPath: "/tmp/plugin_config"}
-			backup.SetPluginConfig(&pluginConfig)
+			testBackup.SetPluginConfig(&pluginConfig)
 			utils.SetPipeThroughProgram(utils.PipeThroughProgram{Name: "gzip", OutputCommand: "gzip -c -8", InputCommand: "gzip -d -c", Extension: ".gz"})
 			execStr := regexp.QuoteMeta("COPY public.foo TO PROGRAM 'gzip -c -8 | /tmp/fake-plugin.sh backup_data /tmp/plugin_config <SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456' WITH CSV DELIMITER ',' ON SEGMENT IGNORE EXTERNAL PARTITIONS;")
 			mock.ExpectExec(execStr).WillReturnResult(sqlmock.NewResult(10, 0))
 
 			filename := "<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456"
-			_, err := backup.CopyTableOut(connectionPool, testTable, filename, defaultConnNum)
+			_, err := testBackup.CopyTableOut(connectionPool, testTable, filename, defaultConnNum)
+
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+		It("will back up a table in the chosen format", func() {
+			testBackup.SetCopyFormat(utils.NewCopyFormat(utils.COPY_FORMAT_TEXT, "|", nil, false))
+			defer testBackup.SetCopyFormat(utils.NewCopyFormat(utils.COPY_FORMAT_CSV, "", nil, false))
+			utils.SetPipeThroughProgram(utils.PipeThroughProgram{Name: "cat", OutputCommand: "cat -", InputCommand: "cat -", Extension: ""})
+			execStr := regexp.QuoteMeta("COPY public.foo TO PROGRAM 'cat - > <SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456' WITH DELIMITER '|' ON SEGMENT IGNORE EXTERNAL PARTITIONS;")
+			mock.ExpectExec(execStr).WillReturnResult(sqlmock.NewResult(10, 0))
+			filename := "<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456"
+
+			_, err := testBackup.CopyTableOut(connectionPool, testTable, filename, defaultConnNum)
 
 			Expect(err).ShouldNot(HaveOccurred())
 		})
@@ -99,20 +183,20 @@ var _ = Describe("backup/data tests", func() {
 			mock.ExpectExec(execStr).WillReturnResult(sqlmock.NewResult(10, 0))
 			filename := "<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456"
 
-			_, err := backup.CopyTableOut(connectionPool, testTable, filename, defaultConnNum)
+			_, err := testBackup.CopyTableOut(connectionPool, testTable, filename, defaultConnNum)
 
 			Expect(err).ShouldNot(HaveOccurred())
 		})
 		It("will back up a table to its own file without compression using a plugin", func() {
 			_ = cmdFlags.Set(utils.PLUGIN_CONFIG, "/tmp/plugin_config")
 			pluginConfig := utils.PluginConfig{ExecutablePath: "/tmp/fake-plugin.sh", ConfigPath: "/tmp/plugin_config"}
-			backup.SetPluginConfig(&pluginConfig)
+			testBackup.SetPluginConfig(&pluginConfig)
 			utils.SetPipeThroughProgram(utils.PipeThroughProgram{Name: "cat", OutputCommand: "cat -", InputCommand: "cat -", Extension: ""})
 			execStr := regexp.QuoteMeta("COPY public.foo TO PROGRAM 'cat - | /tmp/fake-plugin.sh backup_data /tmp/plugin_config <SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456' WITH CSV DELIMITER ',' ON SEGMENT IGNORE EXTERNAL PARTITIONS;")
 			mock.ExpectExec(execStr).WillReturnResult(sqlmock.NewResult(10, 0))
 
 			filename := "<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456"
-			_, err := backup.CopyTableOut(connectionPool, testTable, filename, defaultConnNum)
+			_, err := testBackup.CopyTableOut(connectionPool, testTable, filename, defaultConnNum)
 
 			Expect(err).ShouldNot(HaveOccurred())
 		})
@@ -122,11 +206,114 @@ var _ = Describe("backup/data tests", func() {
 			mock.ExpectExec(execStr).WillReturnResult(sqlmock.NewResult(10, 0))
 			filename := "<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456"
 
-			_, err := backup.CopyTableOut(connectionPool, testTable, filename, defaultConnNum)
+			_, err := testBackup.CopyTableOut(connectionPool, testTable, filename, defaultConnNum)
 
 			Expect(err).ShouldNot(HaveOccurred())
 		})
 	})
+	Describe("GetTableDataQuery", func() {
+		testTable := backup.Table{Relation: backup.Relation{SchemaOid: 2345, Oid: 3456, Schema: "public", Name: "foo"}}
+		AfterEach(func() {
+			testBackup.SetDataFilters(nil)
+		})
+		It("returns an empty query when the table is neither filtered nor sampled", func() {
+			Expect(testBackup.GetTableDataQuery(testTable)).To(Equal(""))
+		})
+		It("selects the rows matching the table's filter condition", func() {
+			testBackup.SetDataFilters(map[string]string{"public.foo": "id < 100", "public.bar": "false"})
+
+			Expect(testBackup.GetTableDataQuery(testTable)).To(Equal("SELECT * FROM public.foo WHERE (id < 100)"))
+		})
+		It("selects all rows of an external table", func() {
+			externalTable := testTable
+			externalTable.IsExternal = true
+
+			Expect(testBackup.GetTableDataQuery(externalTable)).To(Equal("SELECT * FROM public.foo"))
+		})
+		It("samples rows with TABLESAMPLE on GPDB 7", func() {
+			testhelper.SetDBVersion(connectionPool, "7.0.0")
+			_ = cmdFlags.Set(utils.SAMPLE_PERCENT, "10")
+			testBackup.SetDataFilters(map[string]string{"public.foo": "id < 100"})
+
+			Expect(testBackup.GetTableDataQuery(testTable)).To(Equal("SELECT * FROM public.foo TABLESAMPLE BERNOULLI (10) WHERE (id < 100)"))
+		})
+		It("samples rows with random() before GPDB 7", func() {
+			testhelper.SetDBVersion(connectionPool, "6.0.0")
+			_ = cmdFlags.Set(utils.SAMPLE_PERCENT, "5")
+			testBackup.SetDataFilters(map[string]string{"public.foo": "id < 100"})
+
+			Expect(testBackup.GetTableDataQuery(testTable)).To(Equal("SELECT * FROM public.foo WHERE random() < 0.05 AND (id < 100)"))
+		})
+		It("backs up the filtered rows of a table with COPY (SELECT ...)", func() {
+			testhelper.SetDBVersion(connectionPool, "6.0.0")
+			testBackup.SetDataFilters(map[string]string{"public.foo": "id < 100"})
+			utils.SetPipeThroughProgram(utils.PipeThroughProgram{Name: "cat", OutputCommand: "cat -", InputCommand: "cat -", Extension: ""})
+			execStr := regexp.QuoteMeta("COPY (SELECT * FROM public.foo WHERE (id < 100)) TO PROGRAM 'cat - > <SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456' WITH CSV DELIMITER ',' ON SEGMENT;")
+			mock.ExpectExec(execStr).WillReturnResult(sqlmock.NewResult(10, 0))
+			filename := "<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456"
+
+			_, err := testBackup.CopyTableOut(connectionPool, testTable, filename, defaultConnNum)
+
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+	})
+	Describe("GetParquetColumns", func() {
+		It("removes the quotes of quoted column names", func() {
+			table := backup.Table{TableDefinition: backup.TableDefinition{ColumnDefs: []backup.ColumnDefinition{{Name: "id", Type: "integer"}, {Name: `"Say ""hi"""`, Type: "text"}}}}
+
+			Expect(backup.GetParquetColumns(table)).To(Equal([]utils.ParquetColumn{{Name: "id", Type: "integer"}, {Name: `Say "hi"`, Type: "text"}}))
+		})
+	})
+	Describe("CopyTableOut with --parquet-export", func() {
+		It("converts the table's data with gpbackup_helper", func() {
+			operating.System.Getenv = func(key string) string {
+				return "/usr/local/greenplum-db"
+			}
+			defer func() { operating.System = operating.InitializeSystemFunctions() }()
+			_ = cmdFlags.Set(utils.PARQUET_EXPORT, "true")
+			_ = cmdFlags.Set(utils.COMPRESSION_LEVEL, "3")
+			testTable := backup.Table{
+				Relation:        backup.Relation{SchemaOid: 2345, Oid: 3456, Schema: "public", Name: "foo"},
+				TableDefinition: backup.TableDefinition{ColumnDefs: []backup.ColumnDefinition{{Name: "i", Type: "integer"}}},
+			}
+			schema := utils.EncodeParquetSchema([]utils.ParquetColumn{{Name: "i", Type: "integer"}})
+			execStr := regexp.QuoteMeta(fmt.Sprintf("COPY public.foo TO PROGRAM '/usr/local/greenplum-db/bin/gpbackup_helper --parquet-writer --content <SEGID> --parquet-schema %s --compression-level 3 --data-file <SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456.parquet' WITH CSV DELIMITER ',' ON SEGMENT IGNORE EXTERNAL PARTITIONS;", schema))
+			mock.ExpectExec(execStr).WillReturnResult(sqlmock.NewResult(10, 0))
+			filename := "<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456.parquet"
+
+			_, err := testBackup.CopyTableOut(connectionPool, testTable, filename, defaultConnNum)
+
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+	})
+	Describe("CopyTableOutWithRetries", func() {
+		testTable := backup.Table{Relation: backup.Relation{SchemaOid: 2345, Oid: 3456, Schema: "public", Name: "foo"}}
+		filename := "<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456"
+		It("does not take a savepoint when retries are disabled", func() {
+			mock.ExpectExec("COPY public.foo").WillReturnResult(sqlmock.NewResult(0, 10))
+
+			rowsCopied, retries, err := testBackup.CopyTableOutWithRetries(connectionPool, testTable, filename, defaultConnNum, 0)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(rowsCopied).To(Equal(int64(10)))
+			Expect(retries).To(Equal(0))
+		})
+		It("returns the error once every retry has failed", func() {
+			for i := 0; i < 2; i++ {
+				mock.ExpectExec("SAVEPOINT gpbackup_copy").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("COPY public.foo").WillReturnError(errors.New("connection to segment lost"))
+				if i == 0 {
+					mock.ExpectExec("ROLLBACK TO SAVEPOINT gpbackup_copy").WillReturnResult(sqlmock.NewResult(0, 0))
+				}
+			}
+
+			_, retries, err := testBackup.CopyTableOutWithRetries(connectionPool, testTable, filename, defaultConnNum, 1)
+
+			Expect(err).To(MatchError("connection to segment lost"))
+			Expect(retries).To(Equal(1))
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+	})
 	Describe("BackupSingleTableData", func() {
 		var (
 			testTable     backup.Table
@@ -152,7 +339,7 @@ var _ = Describe("backup/data tests", func() {
 			backupFile := fmt.Sprintf("<SEG_DATA_DIR>/gpbackup_<SEGID>_20170101010101_pipe_(.*)_%d", testTable.Oid)
 			copyCmd := fmt.Sprintf(copyFmtStr, backupFile)
 			mock.ExpectExec(copyCmd).WillReturnResult(sqlmock.NewResult(0, 10))
-			err := backup.BackupSingleTableData(testTable, rowsCopiedMap, &counters, 0)
+			err := testBackup.BackupSingleTableData(testTable, rowsCopiedMap, &counters, 0)
 
 			Expect(err).ShouldNot(HaveOccurred())
 			Expect(rowsCopiedMap[0]).To(Equal(int64(10)))
@@ -164,16 +351,52 @@ var _ = Describe("backup/data tests", func() {
 			backupFile := fmt.Sprintf("<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_%d", testTable.Oid)
 			copyCmd := fmt.Sprintf(copyFmtStr, backupFile)
 			mock.ExpectExec(copyCmd).WillReturnResult(sqlmock.NewResult(0, 10))
-			err := backup.BackupSingleTableData(testTable, rowsCopiedMap, &counters, 0)
+			err := testBackup.BackupSingleTableData(testTable, rowsCopiedMap, &counters, 0)
 
 			Expect(err).ShouldNot(HaveOccurred())
 			Expect(rowsCopiedMap[0]).To(Equal(int64(10)))
 			Expect(counters.NumRegTables).To(Equal(int64(1)))
 		})
+		It("records how long the table took to back up", func() {
+			counters.TableSizes = map[uint32]int64{0: 2048}
+			backupFile := fmt.Sprintf("<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_%d", testTable.Oid)
+			mock.ExpectExec(fmt.Sprintf(copyFmtStr, backupFile)).WillReturnResult(sqlmock.NewResult(0, 10))
+			err := testBackup.BackupSingleTableData(testTable, rowsCopiedMap, &counters, 0)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(counters.TableTimings).To(HaveLen(1))
+			Expect(counters.TableTimings[0].Table).To(Equal("public.testtable"))
+			Expect(counters.TableTimings[0].Rows).To(Equal(int64(10)))
+			Expect(counters.TableTimings[0].Bytes).To(Equal(int64(2048)))
+		})
+		It("retries a table whose COPY fails and records the retry", func() {
+			_ = cmdFlags.Set(utils.COPY_RETRIES, "2")
+			backupFile := fmt.Sprintf("<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_%d", testTable.Oid)
+			mock.ExpectExec("SAVEPOINT gpbackup_copy").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(fmt.Sprintf(copyFmtStr, backupFile)).WillReturnError(errors.New("connection to segment lost"))
+			mock.ExpectExec("ROLLBACK TO SAVEPOINT gpbackup_copy").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("SAVEPOINT gpbackup_copy").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(fmt.Sprintf(copyFmtStr, backupFile)).WillReturnResult(sqlmock.NewResult(0, 10))
+			mock.ExpectExec("RELEASE SAVEPOINT gpbackup_copy").WillReturnResult(sqlmock.NewResult(0, 0))
+			err := testBackup.BackupSingleTableData(testTable, rowsCopiedMap, &counters, 0)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(rowsCopiedMap[0]).To(Equal(int64(10)))
+			Expect(counters.TableTimings).To(HaveLen(1))
+			Expect(counters.TableTimings[0].Retries).To(Equal(1))
+			Expect(logfile).To(Say(regexp.QuoteMeta("Backing up data for table public.testtable failed, retrying (retry 1 of 2): connection to segment lost")))
+		})
+		It("does not record timing for an external table", func() {
+			testTable.IsExternal = true
+			err := testBackup.BackupSingleTableData(testTable, rowsCopiedMap, &counters, 0)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(counters.TableTimings).To(BeEmpty())
+		})
 		It("backs up a single external table", func() {
 			_ = cmdFlags.Set(utils.LEAF_PARTITION_DATA, "false")
 			testTable.IsExternal = true
-			err := backup.BackupSingleTableData(testTable, rowsCopiedMap, &counters, 0)
+			err := testBackup.BackupSingleTableData(testTable, rowsCopiedMap, &counters, 0)
 
 			Expect(err).ShouldNot(HaveOccurred())
 			Expect(rowsCopiedMap).To(BeEmpty())
@@ -182,7 +405,7 @@ var _ = Describe("backup/data tests", func() {
 		It("backs up a single foreign table", func() {
 			_ = cmdFlags.Set(utils.LEAF_PARTITION_DATA, "false")
 			testTable.ForeignDef = backup.ForeignTableDefinition{Oid: 23, Options: "", Server: "fs"}
-			err := backup.BackupSingleTableData(testTable, rowsCopiedMap, &counters, 0)
+			err := testBackup.BackupSingleTableData(testTable, rowsCopiedMap, &counters, 0)
 
 			Expect(err).ShouldNot(HaveOccurred())
 			Expect(rowsCopiedMap).To(BeEmpty())
@@ -194,30 +417,72 @@ var _ = Describe("backup/data tests", func() {
 		var testTable backup.Table
 		BeforeEach(func() {
 			config.MetadataOnly = false
-			backup.SetReport(&utils.Report{BackupConfig: config})
+			testBackup.SetReport(&utils.Report{BackupConfig: config})
 			testTable = backup.Table{
 				Relation:        backup.Relation{Schema: "public", Name: "testtable"},
 				TableDefinition: backup.TableDefinition{},
 			}
 		})
 		It("changes backup type to metadata if no tables in DB", func() {
-			backup.CheckTablesContainData([]backup.Table{})
-			Expect(backup.GetReport().BackupConfig.MetadataOnly).To(BeTrue())
+			testBackup.CheckTablesContainData([]backup.Table{})
+			Expect(testBackup.Report().BackupConfig.MetadataOnly).To(BeTrue())
 		})
 		It("changes backup type to metadata if only external or foreign tables in database", func() {
 			testTable.IsExternal = true
-			backup.CheckTablesContainData([]backup.Table{testTable})
-			Expect(backup.GetReport().BackupConfig.MetadataOnly).To(BeTrue())
+			testBackup.CheckTablesContainData([]backup.Table{testTable})
+			Expect(testBackup.Report().BackupConfig.MetadataOnly).To(BeTrue())
 		})
 		It("does not change backup type if metadata-only backup", func() {
 			config.MetadataOnly = true
-			backup.SetReport(&utils.Report{BackupConfig: config})
-			backup.CheckTablesContainData([]backup.Table{})
-			Expect(backup.GetReport().BackupConfig.MetadataOnly).To(BeTrue())
+			testBackup.SetReport(&utils.Report{BackupConfig: config})
+			testBackup.CheckTablesContainData([]backup.Table{})
+			Expect(testBackup.Report().BackupConfig.MetadataOnly).To(BeTrue())
 		})
 		It("does not change backup type if tables present in database", func() {
-			backup.CheckTablesContainData([]backup.Table{testTable})
-			Expect(backup.GetReport().BackupConfig.MetadataOnly).To(BeFalse())
+			testBackup.CheckTablesContainData([]backup.Table{testTable})
+			Expect(testBackup.Report().BackupConfig.MetadataOnly).To(BeFalse())
+		})
+	})
+	Describe("WarnForLargeRows", func() {
+		largeTable := backup.Table{
+			Relation:        backup.Relation{Oid: 1, Schema: "public", Name: "large_table"},
+			TableDefinition: backup.TableDefinition{ColumnDefs: []backup.ColumnDefinition{{Name: "i"}, {Name: "j"}}},
+		}
+		smallTable := backup.Table{
+			Relation:        backup.Relation{Oid: 2, Schema: "public", Name: "small_table"},
+			TableDefinition: backup.TableDefinition{ColumnDefs: []backup.ColumnDefinition{{Name: "k"}}},
+		}
+		It("warns about tables with rows larger than the threshold", func() {
+			mock.ExpectQuery("SELECT c.oid::text AS string").WillReturnRows(sqlmock.NewRows([]string{"string"}).AddRow("1"))
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT coalesce(max(coalesce(pg_column_size(i), 0) + coalesce(pg_column_size(j), 0)), 0)::text AS string FROM public.large_table")).
+				WillReturnRows(sqlmock.NewRows([]string{"string"}).AddRow("209715200"))
+
+			testBackup.WarnForLargeRows([]backup.Table{largeTable, smallTable}, 104857600)
+
+			Expect(stdout).To(Say(`Table public.large_table contains a row of 200.0 MB, which exceeds the large row threshold of 100.0 MB`))
+			Expect(stdout).ToNot(Say("small_table"))
+		})
+		It("does not warn about tables with rows smaller than the threshold", func() {
+			mock.ExpectQuery("SELECT c.oid::text AS string").WillReturnRows(sqlmock.NewRows([]string{"string"}).AddRow("1"))
+			mock.ExpectQuery("SELECT coalesce").WillReturnRows(sqlmock.NewRows([]string{"string"}).AddRow("1024"))
+
+			testBackup.WarnForLargeRows([]backup.Table{largeTable}, 104857600)
+
+			Expect(stdout).ToNot(Say("large_table"))
+		})
+	})
+	Describe("GetRelationsToLockForData", func() {
+		regularTable := backup.Relation{Oid: 1, Schema: "public", Name: "regular_table"}
+		excludedLeaf := backup.Relation{Oid: 2, Schema: "public", Name: "part_1_prt_1"}
+		It("returns every relation except excluded leaf partitions", func() {
+			testBackup.SetReport(&utils.Report{})
+			relations := testBackup.GetRelationsToLockForData([]backup.Relation{regularTable, excludedLeaf}, []string{"2"})
+			Expect(relations).To(Equal([]backup.Relation{regularTable}))
+		})
+		It("returns no relations for a metadata-only backup", func() {
+			testBackup.SetReport(&utils.Report{BackupConfig: backup_history.BackupConfig{MetadataOnly: true}})
+			relations := testBackup.GetRelationsToLockForData([]backup.Relation{regularTable, excludedLeaf}, []string{})
+			Expect(relations).To(BeEmpty())
 		})
 	})
 })
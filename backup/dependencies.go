@@ -55,15 +55,19 @@ var (
 	PG_FOREIGN_SERVER_OID       uint32 = 1417
 	PG_INDEX_OID                uint32 = 2610
 	PG_LANGUAGE_OID             uint32 = 2612
+	PG_LARGEOBJECT_METADATA_OID uint32 = 2995
 	PG_NAMESPACE_OID            uint32 = 2615
 	PG_OPCLASS_OID              uint32 = 2616
 	PG_OPERATOR_OID             uint32 = 2617
 	PG_OPFAMILY_OID             uint32 = 2753
+	PG_POLICY_OID               uint32 = 3256
 	PG_PROC_OID                 uint32 = 1255
 	PG_RESGROUP_OID             uint32 = 6436
 	PG_RESQUEUE_OID             uint32 = 6026
 	PG_REWRITE_OID              uint32 = 2618
+	PG_STATISTIC_EXT_OID        uint32 = 3381
 	PG_TABLESPACE_OID           uint32 = 1213
+	PG_TRANSFORM_OID            uint32 = 3576
 	PG_TRIGGER_OID              uint32 = 2620
 	PG_TS_CONFIG_OID            uint32 = 3602
 	PG_TS_DICT_OID              uint32 = 3600
@@ -251,54 +255,59 @@ func breakCircularDependencies(depMap DependencyMap) {
 	}
 }
 
-func PrintDependentObjectStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, objects []Sortable, metadataMap MetadataMap, constraints []Constraint, funcInfoMap map[uint32]FunctionInfo) {
+func (b *Backup) PrintDependentObjectStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, objects []Sortable, metadataMap MetadataMap, constraints []Constraint, funcInfoMap map[uint32]FunctionInfo) {
 	conMap := make(map[string][]Constraint)
 	for _, constraint := range constraints {
 		conMap[constraint.OwningObject] = append(conMap[constraint.OwningObject], constraint)
 	}
 	for _, object := range objects {
+		if tocObject, ok := object.(utils.TOCObjectWithMetadata); ok {
+			b.SetCurrentTOCObject(tocObject)
+		}
 		objMetadata := metadataMap[object.GetUniqueID()]
 		switch obj := object.(type) {
 		case BaseType:
-			PrintCreateBaseTypeStatement(metadataFile, toc, obj, objMetadata)
+			b.PrintCreateBaseTypeStatement(metadataFile, toc, obj, objMetadata)
 		case CompositeType:
-			PrintCreateCompositeTypeStatement(metadataFile, toc, obj, objMetadata)
+			b.PrintCreateCompositeTypeStatement(metadataFile, toc, obj, objMetadata)
 		case Domain:
-			PrintCreateDomainStatement(metadataFile, toc, obj, objMetadata, conMap[obj.FQN()])
+			b.PrintCreateDomainStatement(metadataFile, toc, obj, objMetadata, conMap[obj.FQN()])
 		case RangeType:
-			PrintCreateRangeTypeStatement(metadataFile, toc, obj, objMetadata)
+			b.PrintCreateRangeTypeStatement(metadataFile, toc, obj, objMetadata)
 		case Function:
-			PrintCreateFunctionStatement(metadataFile, toc, obj, objMetadata)
+			b.PrintCreateFunctionStatement(metadataFile, toc, obj, objMetadata)
 		case Table:
-			PrintCreateTableStatement(metadataFile, toc, obj, objMetadata)
+			b.PrintCreateTableStatement(metadataFile, toc, obj, objMetadata)
 		case ExternalProtocol:
-			PrintCreateExternalProtocolStatement(metadataFile, toc, obj, funcInfoMap, objMetadata)
+			b.PrintCreateExternalProtocolStatement(metadataFile, toc, obj, funcInfoMap, objMetadata)
 		case View:
-			PrintCreateViewStatement(metadataFile, toc, obj, objMetadata)
+			b.PrintCreateViewStatement(metadataFile, toc, obj, objMetadata)
 		case TextSearchParser:
-			PrintCreateTextSearchParserStatement(metadataFile, toc, obj, objMetadata)
+			b.PrintCreateTextSearchParserStatement(metadataFile, toc, obj, objMetadata)
 		case TextSearchConfiguration:
-			PrintCreateTextSearchConfigurationStatement(metadataFile, toc, obj, objMetadata)
+			b.PrintCreateTextSearchConfigurationStatement(metadataFile, toc, obj, objMetadata)
 		case TextSearchTemplate:
-			PrintCreateTextSearchTemplateStatement(metadataFile, toc, obj, objMetadata)
+			b.PrintCreateTextSearchTemplateStatement(metadataFile, toc, obj, objMetadata)
 		case TextSearchDictionary:
-			PrintCreateTextSearchDictionaryStatement(metadataFile, toc, obj, objMetadata)
+			b.PrintCreateTextSearchDictionaryStatement(metadataFile, toc, obj, objMetadata)
 		case Operator:
-			PrintCreateOperatorStatement(metadataFile, toc, obj, objMetadata)
+			b.PrintCreateOperatorStatement(metadataFile, toc, obj, objMetadata)
 		case OperatorClass:
-			PrintCreateOperatorClassStatement(metadataFile, toc, obj, objMetadata)
+			b.PrintCreateOperatorClassStatement(metadataFile, toc, obj, objMetadata)
 		case Aggregate:
-			PrintCreateAggregateStatement(metadataFile, toc, obj, funcInfoMap, objMetadata)
+			b.PrintCreateAggregateStatement(metadataFile, toc, obj, funcInfoMap, objMetadata)
 		case Cast:
-			PrintCreateCastStatement(metadataFile, toc, obj, objMetadata)
+			b.PrintCreateCastStatement(metadataFile, toc, obj, objMetadata)
+		case Transform:
+			b.PrintCreateTransformStatement(metadataFile, toc, obj, objMetadata)
 		case ForeignDataWrapper:
-			PrintCreateForeignDataWrapperStatement(metadataFile, toc, obj, funcInfoMap, objMetadata)
+			b.PrintCreateForeignDataWrapperStatement(metadataFile, toc, obj, funcInfoMap, objMetadata)
 		case ForeignServer:
-			PrintCreateServerStatement(metadataFile, toc, obj, objMetadata)
+			b.PrintCreateServerStatement(metadataFile, toc, obj, objMetadata)
 		case UserMapping:
 			PrintCreateUserMappingStatement(metadataFile, toc, obj)
 		case MaterializedView:
-			PrintCreateMaterializedViewStatement(metadataFile, toc, obj, objMetadata)
+			b.PrintCreateMaterializedViewStatement(metadataFile, toc, obj, objMetadata)
 		}
 	}
 }
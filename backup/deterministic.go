@@ -0,0 +1,46 @@
+package backup
+
+/*
+ * This file contains functions for the --deterministic mode, in which the
+ * metadata for identical schemas is written identically regardless of object
+ * OIDs or sequence values, so that two backups can be compared with diff.
+ */
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/greenplum-db/gpbackup/utils"
+)
+
+/*
+ * Dependent objects are sorted by name before the topological sort, which
+ * otherwise receives them in OID order; objects with no dependency between
+ * them are then printed in name order.
+ */
+func SortObjectsByName(objects []Sortable) {
+	sort.SliceStable(objects, func(i int, j int) bool {
+		return objects[i].FQN() < objects[j].FQN()
+	})
+}
+
+/*
+ * This is registered as statement middleware, so it is applied to every
+ * statement.  Only the whitespace gpbackup writes around a statement is
+ * normalized: the whitespace before it is reduced to at most one blank line,
+ * and the whitespace after it is removed.  Whitespace within the statement,
+ * such as in function bodies and string literals, is part of the object's
+ * definition and is left as is.
+ */
+func NormalizeWhitespace(entry utils.MetadataEntry, statement string) string {
+	withoutLeading := strings.TrimLeftFunc(statement, unicode.IsSpace)
+	if withoutLeading == "" {
+		return statement
+	}
+	numNewlines := strings.Count(statement[:len(statement)-len(withoutLeading)], "\n")
+	if numNewlines > 2 {
+		numNewlines = 2
+	}
+	return strings.Repeat("\n", numNewlines) + strings.TrimRightFunc(withoutLeading, unicode.IsSpace)
+}
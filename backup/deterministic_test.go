@@ -0,0 +1,59 @@
+package backup_test
+
+import (
+	"github.com/greenplum-db/gpbackup/backup"
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backup/deterministic tests", func() {
+	Describe("SortObjectsByName", func() {
+		It("sorts objects by name rather than by OID", func() {
+			relation1 := backup.Relation{Schema: "public", Name: "zebra", Oid: 1}
+			relation2 := backup.Relation{Schema: "public", Name: "apple", Oid: 2}
+			relation3 := backup.Relation{Schema: "other", Name: "mango", Oid: 3}
+			objects := []backup.Sortable{relation1, relation2, relation3}
+
+			backup.SortObjectsByName(objects)
+
+			Expect(objects).To(Equal([]backup.Sortable{relation3, relation2, relation1}))
+		})
+		It("gives dependent objects the same order regardless of OIDs", func() {
+			relation1 := backup.Relation{Schema: "public", Name: "b", Oid: 1}
+			relation2 := backup.Relation{Schema: "public", Name: "a", Oid: 2}
+			relation3 := backup.Relation{Schema: "public", Name: "c", Oid: 3}
+			depMap := backup.DependencyMap{relation3.GetUniqueID(): {relation1.GetUniqueID(): true}}
+			objects := []backup.Sortable{relation1, relation2, relation3}
+
+			backup.SortObjectsByName(objects)
+			sorted := backup.TopologicalSort(objects, depMap)
+
+			Expect(sorted).To(Equal([]backup.Sortable{relation2, relation1, relation3}))
+		})
+	})
+	Describe("NormalizeWhitespace", func() {
+		It("removes whitespace after a statement and extra blank lines before it", func() {
+			statement := "\n \n\n\nCREATE TABLE public.foo (\n\ti integer\n) DISTRIBUTED RANDOMLY; \t\n"
+
+			result := backup.NormalizeWhitespace(utils.MetadataEntry{}, statement)
+
+			Expect(result).To(Equal("\n\nCREATE TABLE public.foo (\n\ti integer\n) DISTRIBUTED RANDOMLY;"))
+		})
+		It("keeps a single newline before a statement that continues the previous one", func() {
+			statement := "\nALTER INDEX public.foo_idx SET TABLESPACE ts;  "
+
+			result := backup.NormalizeWhitespace(utils.MetadataEntry{}, statement)
+
+			Expect(result).To(Equal("\nALTER INDEX public.foo_idx SET TABLESPACE ts;"))
+		})
+		It("does not change whitespace within a statement", func() {
+			statement := "\n\nCREATE FUNCTION public.f() RETURNS text AS $$SELECT 'a  \r\n\tb'  \n$$ LANGUAGE sql;"
+
+			result := backup.NormalizeWhitespace(utils.MetadataEntry{}, statement)
+
+			Expect(result).To(Equal(statement))
+		})
+	})
+})
@@ -0,0 +1,227 @@
+package backup
+
+/*
+ * This file contains the "gpbackup diff" command, which compares the metadata
+ * and table row counts of two backups, e.g. to audit schema drift between
+ * environments or between two points in time.
+ */
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/greenplum-db/gpbackup/backup_filepath"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	OBJECT_ADDED   = "added"
+	OBJECT_REMOVED = "removed"
+	OBJECT_CHANGED = "changed"
+)
+
+type ObjectDiff struct {
+	Change       string
+	ObjectType   string
+	Name         string
+	OldStatement string
+	NewStatement string
+}
+
+type RowCountDiff struct {
+	Table   string
+	OldRows int64
+	NewRows int64
+}
+
+func NewDiffCommand() *cobra.Command {
+	diffCmd := &cobra.Command{
+		Use:   "diff <old timestamp> <new timestamp>",
+		Short: "Compare the metadata and table row counts of two backups",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			NewBackup(cmd.Flags(), nil, nil).DoDiff(args[0], args[1])
+		}}
+	SetDiffFlagDefaults(diffCmd.Flags())
+	return diffCmd
+}
+
+func SetDiffFlagDefaults(flagSet *pflag.FlagSet) {
+	flagSet.String(utils.BACKUP_DIR, "", "The absolute path of the directory in which the backups to be compared are located")
+	flagSet.Bool(utils.DEBUG, false, "Print verbose and debug log messages")
+	flagSet.Bool(utils.QUIET, false, "Suppress non-warning, non-error log messages")
+	flagSet.Bool(utils.VERBOSE, false, "Print verbose log messages")
+}
+
+func (b *Backup) DoDiff(oldTimestamp string, newTimestamp string) {
+	b.SetLoggerVerbosity()
+	for _, timestamp := range []string{oldTimestamp, newTimestamp} {
+		if !backup_filepath.IsValidTimestamp(timestamp) {
+			gplog.Fatal(errors.Errorf("Timestamp %s is invalid.  Timestamps must be in the format YYYYMMDDHHMMSS.", timestamp), "")
+		}
+	}
+	gplog.Info("Comparing backup %s to backup %s", oldTimestamp, newTimestamp)
+	oldTOC, oldMetadata := b.readBackupForDiff(oldTimestamp)
+	newTOC, newMetadata := b.readBackupForDiff(newTimestamp)
+
+	objectDiffs := DiffMetadata(oldTOC, oldMetadata, newTOC, newMetadata)
+	rowCountDiffs := DiffRowCounts(oldTOC.DataEntries, newTOC.DataEntries)
+	PrintDiffReport(objectDiffs, rowCountDiffs)
+}
+
+/*
+ * Backups are located without connecting to the database, using either the
+ * backup directory or the master data directory from the environment.
+ */
+func (b *Backup) readBackupForDiff(timestamp string) (*utils.TOC, []byte) {
+	backupDir := b.MustGetFlagString(utils.BACKUP_DIR)
+	fpInfo := backup_filepath.FilePathInfo{
+		SegDirMap:              map[int]string{-1: operating.System.Getenv("MASTER_DATA_DIRECTORY")},
+		Timestamp:              timestamp,
+		UserSpecifiedBackupDir: backupDir,
+		UserSpecifiedSegPrefix: backup_filepath.ParseSegPrefix(backupDir, timestamp),
+	}
+	if backupDir == "" && fpInfo.SegDirMap[-1] == "" {
+		gplog.Fatal(errors.Errorf("MASTER_DATA_DIRECTORY must be set or --%s must be specified", utils.BACKUP_DIR), "")
+	}
+	toc := utils.NewTOC(fpInfo.GetTOCFilePath())
+	metadata, err := utils.ReadFileDecompressingIfNeeded(fpInfo.GetMetadataFilePath())
+	gplog.FatalOnError(err)
+	return toc, metadata
+}
+
+/*
+ * Objects are matched by type and name.  Several entries can share a type and
+ * name, such as overloaded functions, so their statements are compared
+ * together.
+ */
+func DiffMetadata(oldTOC *utils.TOC, oldMetadata []byte, newTOC *utils.TOC, newMetadata []byte) []ObjectDiff {
+	oldKeys, oldStatements := getStatementsByObject(oldTOC, oldMetadata)
+	newKeys, newStatements := getStatementsByObject(newTOC, newMetadata)
+
+	diffs := make([]ObjectDiff, 0)
+	for _, key := range oldKeys {
+		newStatement, ok := newStatements[key]
+		if !ok {
+			diffs = append(diffs, ObjectDiff{Change: OBJECT_REMOVED, ObjectType: key[0], Name: key[1], OldStatement: oldStatements[key]})
+		} else if newStatement != oldStatements[key] {
+			diffs = append(diffs, ObjectDiff{Change: OBJECT_CHANGED, ObjectType: key[0], Name: key[1], OldStatement: oldStatements[key], NewStatement: newStatement})
+		}
+	}
+	for _, key := range newKeys {
+		if _, ok := oldStatements[key]; !ok {
+			diffs = append(diffs, ObjectDiff{Change: OBJECT_ADDED, ObjectType: key[0], Name: key[1], NewStatement: newStatements[key]})
+		}
+	}
+	return diffs
+}
+
+func getStatementsByObject(toc *utils.TOC, metadata []byte) ([][2]string, map[[2]string]string) {
+	keys := make([][2]string, 0)
+	statements := make(map[[2]string]string)
+	for _, entries := range [][]utils.MetadataEntry{toc.GlobalEntries, toc.PredataEntries, toc.PostdataEntries} {
+		for _, entry := range entries {
+			if entry.ObjectType == "SESSION GUCS" {
+				continue
+			}
+			name := entry.Name
+			if entry.Schema != "" && entry.Schema != entry.Name {
+				name = utils.MakeFQN(entry.Schema, entry.Name)
+			}
+			if entry.ReferenceObject != "" {
+				name = fmt.Sprintf("%s on %s", name, entry.ReferenceObject)
+			}
+			key := [2]string{entry.ObjectType, name}
+			if _, ok := statements[key]; !ok {
+				keys = append(keys, key)
+			}
+			statements[key] += strings.TrimSpace(string(metadata[entry.StartByte:entry.EndByte])) + "\n"
+		}
+	}
+	return keys, statements
+}
+
+/*
+ * Row counts are only compared between tables present in both backups, as
+ * added and removed tables are reported with the rest of the metadata.
+ */
+func DiffRowCounts(oldEntries []utils.MasterDataEntry, newEntries []utils.MasterDataEntry) []RowCountDiff {
+	oldRows := make(map[string]int64, len(oldEntries))
+	for _, entry := range oldEntries {
+		oldRows[utils.MakeFQN(entry.Schema, entry.Name)] = entry.RowsCopied
+	}
+	diffs := make([]RowCountDiff, 0)
+	for _, entry := range newEntries {
+		table := utils.MakeFQN(entry.Schema, entry.Name)
+		if rows, ok := oldRows[table]; ok && rows != entry.RowsCopied {
+			diffs = append(diffs, RowCountDiff{Table: table, OldRows: rows, NewRows: entry.RowsCopied})
+		}
+	}
+	return diffs
+}
+
+/*
+ * Returns a line-by-line diff of two statements, in which lines only in the
+ * old statement are prefixed with "-" and lines only in the new one with "+".
+ */
+func DiffLines(oldText string, newText string) []string {
+	oldLines := strings.Split(strings.TrimSuffix(oldText, "\n"), "\n")
+	newLines := strings.Split(strings.TrimSuffix(newText, "\n"), "\n")
+	// common[i][j] is the length of the longest common subsequence of oldLines[i:] and newLines[j:]
+	common := make([][]int, len(oldLines)+1)
+	for i := range common {
+		common[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else if common[i+1][j] >= common[i][j+1] {
+				common[i][j] = common[i+1][j]
+			} else {
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+	diff := make([]string, 0)
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		if i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j] {
+			diff = append(diff, " "+oldLines[i])
+			i++
+			j++
+		} else if j == len(newLines) || (i < len(oldLines) && common[i+1][j] >= common[i][j+1]) {
+			diff = append(diff, "-"+oldLines[i])
+			i++
+		} else {
+			diff = append(diff, "+"+newLines[j])
+			j++
+		}
+	}
+	return diff
+}
+
+func PrintDiffReport(objectDiffs []ObjectDiff, rowCountDiffs []RowCountDiff) {
+	numChanges := map[string]int{}
+	for _, diff := range objectDiffs {
+		numChanges[diff.Change]++
+		gplog.Info("%s %s %s", strings.Title(diff.Change), diff.ObjectType, diff.Name)
+		if diff.Change == OBJECT_CHANGED {
+			for _, line := range DiffLines(diff.OldStatement, diff.NewStatement) {
+				gplog.Info("\t%s", line)
+			}
+		} else {
+			gplog.Verbose("\t%s", strings.Replace(strings.TrimSpace(diff.OldStatement+diff.NewStatement), "\n", "\n\t", -1))
+		}
+	}
+	for _, diff := range rowCountDiffs {
+		gplog.Info("Row count of table %s changed from %d to %d (%+d)", diff.Table, diff.OldRows, diff.NewRows, diff.NewRows-diff.OldRows)
+	}
+	gplog.Info("%d objects added, %d removed, %d changed; %d tables with different row counts",
+		numChanges[OBJECT_ADDED], numChanges[OBJECT_REMOVED], numChanges[OBJECT_CHANGED], len(rowCountDiffs))
+}
@@ -0,0 +1,95 @@
+package backup_test
+
+import (
+	"github.com/greenplum-db/gpbackup/backup"
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("backup/diff tests", func() {
+	Describe("DiffMetadata", func() {
+		It("reports added, removed, and changed objects", func() {
+			oldMetadata := []byte("CREATE TABLE public.foo (i int);CREATE TABLE public.bar (i int);CREATE SCHEMA s;")
+			oldTOC := &utils.TOC{PredataEntries: []utils.MetadataEntry{
+				{Schema: "public", Name: "foo", ObjectType: "TABLE", StartByte: 0, EndByte: 32},
+				{Schema: "public", Name: "bar", ObjectType: "TABLE", StartByte: 32, EndByte: 64},
+				{Schema: "s", Name: "s", ObjectType: "SCHEMA", StartByte: 64, EndByte: 80},
+			}}
+			newMetadata := []byte("CREATE TABLE public.foo (i int, j int);CREATE SCHEMA s;CREATE VIEW public.v AS SELECT 1;")
+			newTOC := &utils.TOC{PredataEntries: []utils.MetadataEntry{
+				{Schema: "public", Name: "foo", ObjectType: "TABLE", StartByte: 0, EndByte: 39},
+				{Schema: "s", Name: "s", ObjectType: "SCHEMA", StartByte: 39, EndByte: 55},
+				{Schema: "public", Name: "v", ObjectType: "VIEW", StartByte: 55, EndByte: 88},
+			}}
+
+			diffs := backup.DiffMetadata(oldTOC, oldMetadata, newTOC, newMetadata)
+
+			Expect(diffs).To(Equal([]backup.ObjectDiff{
+				{Change: backup.OBJECT_CHANGED, ObjectType: "TABLE", Name: "public.foo", OldStatement: "CREATE TABLE public.foo (i int);\n", NewStatement: "CREATE TABLE public.foo (i int, j int);\n"},
+				{Change: backup.OBJECT_REMOVED, ObjectType: "TABLE", Name: "public.bar", OldStatement: "CREATE TABLE public.bar (i int);\n"},
+				{Change: backup.OBJECT_ADDED, ObjectType: "VIEW", Name: "public.v", NewStatement: "CREATE VIEW public.v AS SELECT 1;\n"},
+			}))
+		})
+		It("ignores differences in session GUCs", func() {
+			oldMetadata := []byte("SET a = 1;COMMENT ON TABLE public.foo IS 'x';")
+			oldTOC := &utils.TOC{
+				GlobalEntries: []utils.MetadataEntry{{Name: "", ObjectType: "SESSION GUCS", StartByte: 0, EndByte: 10}},
+				PredataEntries: []utils.MetadataEntry{
+					{Schema: "public", Name: "foo", ObjectType: "COMMENT", ReferenceObject: "public.foo", StartByte: 10, EndByte: 45},
+				},
+			}
+			newMetadata := []byte("SET a = 2;COMMENT ON TABLE public.foo IS 'x';")
+
+			Expect(backup.DiffMetadata(oldTOC, oldMetadata, oldTOC, newMetadata)).To(BeEmpty())
+		})
+	})
+	Describe("DiffRowCounts", func() {
+		It("reports tables in both backups whose row counts differ", func() {
+			oldEntries := []utils.MasterDataEntry{
+				{Schema: "public", Name: "foo", RowsCopied: 10},
+				{Schema: "public", Name: "bar", RowsCopied: 5},
+				{Schema: "public", Name: "removed", RowsCopied: 1},
+			}
+			newEntries := []utils.MasterDataEntry{
+				{Schema: "public", Name: "foo", RowsCopied: 12},
+				{Schema: "public", Name: "bar", RowsCopied: 5},
+				{Schema: "public", Name: "added", RowsCopied: 1},
+			}
+
+			Expect(backup.DiffRowCounts(oldEntries, newEntries)).To(Equal([]backup.RowCountDiff{
+				{Table: "public.foo", OldRows: 10, NewRows: 12},
+			}))
+		})
+	})
+	Describe("DiffLines", func() {
+		It("marks removed and added lines", func() {
+			diff := backup.DiffLines("CREATE TABLE foo (\n\ti int,\n\tj int\n);\n", "CREATE TABLE foo (\n\ti int,\n\tk text\n);\n")
+
+			Expect(diff).To(Equal([]string{" CREATE TABLE foo (", " \ti int,", "-\tj int", "+\tk text", " );"}))
+		})
+		It("returns only unchanged lines for identical text", func() {
+			Expect(backup.DiffLines("a\nb", "a\nb")).To(Equal([]string{" a", " b"}))
+		})
+	})
+	Describe("PrintDiffReport", func() {
+		It("prints each difference and a summary", func() {
+			objectDiffs := []backup.ObjectDiff{
+				{Change: backup.OBJECT_CHANGED, ObjectType: "TABLE", Name: "public.foo", OldStatement: "a\n", NewStatement: "b\n"},
+				{Change: backup.OBJECT_ADDED, ObjectType: "VIEW", Name: "public.v", NewStatement: "c\n"},
+			}
+			rowCountDiffs := []backup.RowCountDiff{{Table: "public.foo", OldRows: 10, NewRows: 7}}
+
+			backup.PrintDiffReport(objectDiffs, rowCountDiffs)
+
+			Expect(stdout).To(Say("Changed TABLE public.foo"))
+			Expect(stdout).To(Say("-a"))
+			Expect(stdout).To(Say(`\+b`))
+			Expect(stdout).To(Say("Added VIEW public.v"))
+			Expect(stdout).To(Say(`Row count of table public.foo changed from 10 to 7 \(-3\)`))
+			Expect(stdout).To(Say("1 objects added, 0 removed, 1 changed; 1 tables with different row counts"))
+		})
+	})
+})
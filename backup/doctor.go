@@ -0,0 +1,180 @@
+package backup
+
+/*
+ * This file contains the "gpbackup doctor" command, which checks that the
+ * cluster and environment meet gpbackup's requirements without taking a
+ * backup, so that problems can be fixed before backups are scheduled.
+ */
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/greenplum-db/gp-common-go-libs/cluster"
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type DoctorResult struct {
+	Name   string
+	Passed bool
+	Detail string
+}
+
+/*
+ * Returns the catalog tables that must be readable by the backup user; failing
+ * to read any of these would cause a backup to fail partway through.  GPDB 7
+ * replaced pg_partition and pg_partition_rule with upstream partitioning.
+ */
+func GetDoctorCatalogTables(connectionPool *dbconn.DBConn) []string {
+	tables := []string{"pg_class", "pg_namespace", "pg_proc", "pg_type", "pg_authid", "pg_resqueue", "gp_segment_configuration"}
+	if connectionPool.Version.Before("7") {
+		return append(tables, "pg_partition", "pg_partition_rule")
+	}
+	return append(tables, "pg_partitioned_table")
+}
+
+func NewDoctorCommand() *cobra.Command {
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check that the cluster and environment are ready for gpbackup",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			NewBackup(cmd.Flags(), nil, nil).DoDoctor()
+		}}
+	SetDoctorFlagDefaults(doctorCmd.Flags())
+	_ = doctorCmd.MarkFlagRequired(utils.DBNAME)
+	return doctorCmd
+}
+
+func SetDoctorFlagDefaults(flagSet *pflag.FlagSet) {
+	flagSet.String(utils.BACKUP_DIR, "", "The absolute path of the directory to which backup files will be written, used to check free disk space")
+	flagSet.String(utils.DBNAME, "", "The database to be backed up")
+	flagSet.Bool(utils.DEBUG, false, "Print verbose and debug log messages")
+	flagSet.String(utils.PLUGIN_CONFIG, "", "The configuration file of the plugin to be checked")
+	flagSet.Bool(utils.QUIET, false, "Suppress non-warning, non-error log messages")
+	flagSet.Bool(utils.VERBOSE, false, "Print verbose log messages")
+}
+
+func (b *Backup) DoDoctor() {
+	b.SetLoggerVerbosity()
+	gplog.Info("Checking readiness of database %s for gpbackup", b.MustGetFlagString(utils.DBNAME))
+
+	results := []DoctorResult{RunDoctorCheck("Connectivity", b.checkConnectivity)}
+	if results[0].Passed {
+		results = append(results,
+			RunDoctorCheck("GPDB version", b.checkGPDBVersion),
+			RunDoctorCheck("Catalog access", b.checkCatalogAccess),
+			RunDoctorCheck("Segment configuration", b.checkSegmentConfiguration))
+	}
+	if b.globalCluster != nil {
+		results = append(results,
+			RunDoctorCheck("gpbackup_helper", b.checkHelperOnSegments),
+			RunDoctorCheck("Disk space", b.checkDiskSpace),
+			RunDoctorCheck("Plugin", b.checkPlugin))
+	}
+	if b.connectionPool != nil {
+		b.connectionPool.Close()
+	}
+
+	numFailed := PrintDoctorReport(results)
+	if numFailed > 0 {
+		gplog.Error("%d of %d checks failed; resolve the problems above before running gpbackup", numFailed, len(results))
+		os.Exit(1)
+	}
+	gplog.Info("All checks passed")
+}
+
+/*
+ * Checks report failure by calling gplog.Fatal, like the rest of gpbackup, so
+ * that the functions used during a backup can be reused here; the resulting
+ * panic is recovered and recorded as a failed check.
+ */
+func RunDoctorCheck(name string, check func() string) (result DoctorResult) {
+	defer func() {
+		if err := recover(); err != nil {
+			gplog.SetErrorCode(0)
+			result = DoctorResult{Name: name, Passed: false, Detail: utils.ParseErrorMessage(fmt.Sprintf("%v", err))}
+		}
+	}()
+	return DoctorResult{Name: name, Passed: true, Detail: check()}
+}
+
+func PrintDoctorReport(results []DoctorResult) int {
+	numFailed := 0
+	for _, result := range results {
+		status := "PASS"
+		if !result.Passed {
+			status = "FAIL"
+			numFailed++
+		}
+		gplog.Info("%-22s %s  %s", result.Name, status, result.Detail)
+	}
+	return numFailed
+}
+
+func (b *Backup) checkConnectivity() string {
+	b.connectionPool = dbconn.NewDBConnFromEnvironment(b.MustGetFlagString(utils.DBNAME))
+	b.connectionPool.MustConnect(1)
+	return fmt.Sprintf("Connected to database %s on %s:%d as %s", b.connectionPool.DBName, b.connectionPool.Host, b.connectionPool.Port, b.connectionPool.User)
+}
+
+func (b *Backup) checkGPDBVersion() string {
+	utils.ValidateGPDBVersionCompatibility(b.connectionPool)
+	return fmt.Sprintf("GPDB version %s is supported", b.connectionPool.Version.VersionString)
+}
+
+func (b *Backup) checkCatalogAccess() string {
+	catalogTables := GetDoctorCatalogTables(b.connectionPool)
+	unreadable := make([]string, 0)
+	for _, table := range catalogTables {
+		if _, err := dbconn.SelectString(b.connectionPool, fmt.Sprintf("SELECT count(*) AS string FROM pg_catalog.%s", table)); err != nil {
+			unreadable = append(unreadable, table)
+		}
+	}
+	if len(unreadable) > 0 {
+		gplog.Fatal(errors.Errorf("Unable to read catalog tables %s; gpbackup must be run as a superuser", strings.Join(unreadable, ", ")), "")
+	}
+	return fmt.Sprintf("All %d required catalog tables are readable", len(catalogTables))
+}
+
+func (b *Backup) checkSegmentConfiguration() string {
+	b.globalCluster = cluster.NewCluster(cluster.MustGetSegmentConfiguration(b.connectionPool))
+	return fmt.Sprintf("Found %d segments", len(b.globalCluster.ContentIDs)-1)
+}
+
+func (b *Backup) checkHelperOnSegments() string {
+	utils.VerifyHelperVersionOnSegments(version, b.globalCluster)
+	return fmt.Sprintf("gpbackup_helper version %s found on all hosts", version)
+}
+
+func (b *Backup) checkDiskSpace() string {
+	getDir := b.globalCluster.GetDirForContent
+	if backupDir := b.MustGetFlagString(utils.BACKUP_DIR); backupDir != "" {
+		getDir = func(contentID int) string {
+			return backupDir
+		}
+	}
+	availableSpace := b.GetAvailableDiskSpace(getDir)
+	requiredSpace := GetSegmentDatabaseSizes(b.connectionPool)
+	insufficientSegments := GetSegmentsWithInsufficientSpace(availableSpace, requiredSpace)
+	if len(insufficientSegments) > 0 {
+		gplog.Fatal(errors.Errorf("Less free space than the size of the database on segment(s) %s", strings.Trim(fmt.Sprint(insufficientSegments), "[]")), "")
+	}
+	return "Enough free space to back up the uncompressed database on every segment"
+}
+
+func (b *Backup) checkPlugin() string {
+	if b.MustGetFlagString(utils.PLUGIN_CONFIG) == "" {
+		return "No plugin configured"
+	}
+	plugin, err := utils.ReadPluginConfig(b.MustGetFlagString(utils.PLUGIN_CONFIG))
+	gplog.FatalOnError(err)
+	pluginVersion := plugin.CheckPluginExistsOnAllHosts(b.globalCluster)
+	return fmt.Sprintf("Plugin %s version %s found on all hosts", plugin.ExecutablePath, pluginVersion)
+}
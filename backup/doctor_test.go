@@ -0,0 +1,67 @@
+package backup_test
+
+import (
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"github.com/greenplum-db/gpbackup/backup"
+	"github.com/pkg/errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("backup/doctor tests", func() {
+	Describe("RunDoctorCheck", func() {
+		It("records a passing check with its detail", func() {
+			result := backup.RunDoctorCheck("Check", func() string {
+				return "everything is fine"
+			})
+
+			Expect(result).To(Equal(backup.DoctorResult{Name: "Check", Passed: true, Detail: "everything is fine"}))
+		})
+		It("records a failing check with the error message and resets the error code", func() {
+			result := backup.RunDoctorCheck("Check", func() string {
+				gplog.Fatal(errors.New("something is wrong"), "")
+				return ""
+			})
+
+			Expect(result.Name).To(Equal("Check"))
+			Expect(result.Passed).To(BeFalse())
+			Expect(result.Detail).To(ContainSubstring("something is wrong"))
+			Expect(gplog.GetErrorCode()).To(Equal(0))
+		})
+	})
+	Describe("GetDoctorCatalogTables", func() {
+		It("checks the partitioning catalog tables before GPDB 7", func() {
+			testhelper.SetDBVersion(connectionPool, "6.0.0")
+
+			tables := backup.GetDoctorCatalogTables(connectionPool)
+
+			Expect(tables).To(ContainElement("pg_partition"))
+			Expect(tables).To(ContainElement("pg_partition_rule"))
+			Expect(tables).ToNot(ContainElement("pg_partitioned_table"))
+		})
+		It("checks pg_partitioned_table instead of pg_partition on GPDB 7", func() {
+			testhelper.SetDBVersion(connectionPool, "7.0.0")
+
+			tables := backup.GetDoctorCatalogTables(connectionPool)
+
+			Expect(tables).To(ContainElement("pg_partitioned_table"))
+			Expect(tables).ToNot(ContainElement("pg_partition"))
+			Expect(tables).ToNot(ContainElement("pg_partition_rule"))
+		})
+	})
+	Describe("PrintDoctorReport", func() {
+		It("prints each result and returns the number of failed checks", func() {
+			results := []backup.DoctorResult{
+				{Name: "Connectivity", Passed: true, Detail: "Connected"},
+				{Name: "Disk space", Passed: false, Detail: "Not enough space"},
+			}
+
+			Expect(backup.PrintDoctorReport(results)).To(Equal(1))
+			Expect(stdout).To(Say("Connectivity           PASS  Connected"))
+			Expect(stdout).To(Say("Disk space             FAIL  Not enough space"))
+		})
+	})
+})
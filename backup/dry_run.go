@@ -0,0 +1,91 @@
+package backup
+
+/*
+ * This file contains functions related to performing a dry run of a backup,
+ * which reports what would be backed up without writing any backup files or
+ * locking any tables.
+ */
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpbackup/utils"
+)
+
+func (b *Backup) DoDryRun() {
+	gplog.Info("Performing a dry run; no backup files will be written and no tables will be locked")
+
+	metadataTables, dataTables := b.RetrieveAndProcessTables()
+	if !b.MustGetFlagBool(utils.DATA_ONLY) {
+		if len(b.MustGetFlagStringArray(utils.INCLUDE_RELATION)) == 0 {
+			schemaNames := make([]string, 0)
+			for _, schema := range b.GetAllUserSchemas(b.connectionPool) {
+				schemaNames = append(schemaNames, schema.Name)
+			}
+			printDryRunObjects("Schemas that would be backed up", schemaNames)
+		}
+		printDryRunObjects("Tables that would be backed up", tableFQNs(metadataTables))
+		sequenceNames := make([]string, 0)
+		for _, sequence := range b.GetAllSequenceRelations(b.connectionPool) {
+			sequenceNames = append(sequenceNames, sequence.FQN())
+		}
+		printDryRunObjects("Sequences that would be backed up", sequenceNames)
+		viewNames := make([]string, 0)
+		b.ExcludeViewsWithExcludedDependencies()
+		regularViews, materializedViews := b.GetAllViews(b.connectionPool)
+		for _, view := range regularViews {
+			viewNames = append(viewNames, view.FQN())
+		}
+		for _, view := range materializedViews {
+			viewNames = append(viewNames, view.FQN())
+		}
+		printDryRunObjects("Views that would be backed up", viewNames)
+	}
+
+	if b.MustGetFlagBool(utils.METADATA_ONLY) {
+		return
+	}
+	printDryRunObjects("Tables whose data would be backed up", tableFQNs(dataTables))
+	segmentSizes := b.GetSegmentDataSizes(b.connectionPool, dataTables)
+	contentIDs := make([]int, 0, len(segmentSizes))
+	var totalSize int64
+	for contentID, size := range segmentSizes {
+		contentIDs = append(contentIDs, contentID)
+		totalSize += size
+	}
+	sort.Ints(contentIDs)
+	gplog.Info("Estimated size of table data before compression: %s", FormatByteSize(totalSize))
+	for _, contentID := range contentIDs {
+		gplog.Info("  Segment %d: %s", contentID, FormatByteSize(segmentSizes[contentID]))
+	}
+}
+
+func tableFQNs(tables []Table) []string {
+	names := make([]string, 0, len(tables))
+	for _, table := range tables {
+		names = append(names, table.FQN())
+	}
+	return names
+}
+
+func printDryRunObjects(description string, names []string) {
+	gplog.Info("%s: %d", description, len(names))
+	for _, name := range names {
+		gplog.Info("  %s", name)
+	}
+}
+
+func FormatByteSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d bytes", size)
+	}
+	divisor, exponent := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		divisor *= unit
+		exponent++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(divisor), "KMGTPE"[exponent])
+}
@@ -0,0 +1,22 @@
+package backup_test
+
+import (
+	"github.com/greenplum-db/gpbackup/backup"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backup/dry_run tests", func() {
+	Describe("FormatByteSize", func() {
+		It("formats sizes below one kilobyte in bytes", func() {
+			Expect(backup.FormatByteSize(0)).To(Equal("0 bytes"))
+			Expect(backup.FormatByteSize(1023)).To(Equal("1023 bytes"))
+		})
+		It("formats larger sizes in the largest whole unit", func() {
+			Expect(backup.FormatByteSize(1024)).To(Equal("1.0 KB"))
+			Expect(backup.FormatByteSize(1536 * 1024)).To(Equal("1.5 MB"))
+			Expect(backup.FormatByteSize(3 * 1024 * 1024 * 1024 * 1024)).To(Equal("3.0 TB"))
+		})
+	})
+})
@@ -0,0 +1,62 @@
+package backup
+
+/*
+ * This file contains functions for tracking the phase of the backup and the
+ * object being backed up, so that a failure is reported along with the object
+ * that caused it rather than as a bare SQL error.
+ */
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/greenplum-db/gpbackup/utils"
+)
+
+type ErrorContext struct {
+	Phase      string
+	ObjectType string
+	ObjectName string
+}
+
+/*
+ * Starting a new phase clears the current object, as any object recorded in
+ * an earlier phase was backed up successfully.
+ */
+func (b *Backup) SetBackupPhase(phase string) {
+	b.errorContextMux.Lock()
+	defer b.errorContextMux.Unlock()
+	b.currentErrorContext = ErrorContext{Phase: phase}
+}
+
+func (b *Backup) SetCurrentObject(objectType string, objectName string) {
+	b.errorContextMux.Lock()
+	defer b.errorContextMux.Unlock()
+	b.currentErrorContext.ObjectType = objectType
+	b.currentErrorContext.ObjectName = objectName
+}
+
+// The object type is taken from the object's TOC entry, e.g. "text search parser"
+func (b *Backup) SetCurrentTOCObject(object utils.TOCObjectWithMetadata) {
+	_, entry := object.GetMetadataEntry()
+	b.SetCurrentObject(strings.ToLower(entry.ObjectType), object.FQN())
+}
+
+func (b *Backup) GetErrorContext() ErrorContext {
+	b.errorContextMux.Lock()
+	defer b.errorContextMux.Unlock()
+	return b.currentErrorContext
+}
+
+func (context ErrorContext) String() string {
+	if context.Phase == "" {
+		return ""
+	}
+	if context.ObjectName != "" {
+		return fmt.Sprintf("failed while dumping %s %s during %s phase", context.ObjectType, context.ObjectName, context.Phase)
+	}
+	if context.ObjectType != "" {
+		return fmt.Sprintf("failed while dumping %s during %s phase", context.ObjectType, context.Phase)
+	}
+	return fmt.Sprintf("failed during %s phase", context.Phase)
+}
@@ -0,0 +1,43 @@
+package backup_test
+
+import (
+	"github.com/greenplum-db/gpbackup/backup"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backup/error_context tests", func() {
+	Describe("ErrorContext.String", func() {
+		It("describes the object, its type, and the phase", func() {
+			context := backup.ErrorContext{Phase: "data", ObjectType: "table", ObjectName: "public.foo"}
+			Expect(context.String()).To(Equal("failed while dumping table public.foo during data phase"))
+		})
+		It("describes the object type and the phase when no object name is known", func() {
+			context := backup.ErrorContext{Phase: "pre-data metadata", ObjectType: "operator classes"}
+			Expect(context.String()).To(Equal("failed while dumping operator classes during pre-data metadata phase"))
+		})
+		It("describes only the phase when no object is known", func() {
+			context := backup.ErrorContext{Phase: "setup"}
+			Expect(context.String()).To(Equal("failed during setup phase"))
+		})
+		It("returns an empty string when no phase has started", func() {
+			Expect(backup.ErrorContext{}.String()).To(Equal(""))
+		})
+	})
+	Describe("SetCurrentTOCObject", func() {
+		It("records the object with the type from its TOC entry", func() {
+			testBackup.SetBackupPhase("pre-data metadata")
+			testBackup.SetCurrentTOCObject(backup.Table{Relation: backup.Relation{Schema: "public", Name: "foo"}})
+			Expect(testBackup.GetErrorContext().String()).To(Equal("failed while dumping table public.foo during pre-data metadata phase"))
+		})
+	})
+	Describe("SetBackupPhase", func() {
+		It("clears the object recorded in the previous phase", func() {
+			testBackup.SetBackupPhase("pre-data metadata")
+			testBackup.SetCurrentObject("schemas", "")
+			testBackup.SetBackupPhase("data")
+			Expect(testBackup.GetErrorContext()).To(Equal(backup.ErrorContext{Phase: "data"}))
+		})
+	})
+})
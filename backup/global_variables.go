@@ -2,6 +2,7 @@ package backup
 
 import (
 	"context"
+	"net/http"
 	"sync"
 
 	"github.com/greenplum-db/gp-common-go-libs/cluster"
@@ -13,16 +14,25 @@ import (
 )
 
 /*
- * This file contains global variables and setter functions for those variables
- * used in testing.
+ * This file contains the state of a single backup, kept in its Backup, and
+ * setter functions for that state used in testing.
  */
 
+// Set at build time with -ldflags
+var version string
+
 /*
- * Non-flag variables
+ * The values that belong to a single backup.  They are reset each time the
+ * backup runs, so that nothing carries over from an earlier run, such as that
+ * of another database backed up with --all-databases.
  */
-var (
+type backupState struct {
+	cmdFlags             *pflag.FlagSet
 	backupReport         *utils.Report
 	connectionPool       *dbconn.DBConn
+	backupContext        context.Context
+	backendPids          []string
+	connectionPoolClosed chan struct{}
 	queryContext         context.Context
 	queryCancelFunc      context.CancelFunc
 	globalCluster        *cluster.Cluster
@@ -30,90 +40,129 @@ var (
 	globalTOC            *utils.TOC
 	objectCounts         map[string]int
 	pluginConfig         *utils.PluginConfig
-	version              string
 	wasTerminated        bool
 	backupLockFile       lockfile.Lockfile
 	filterRelationClause string
+	dataFilters          map[string]string
+	maskingRules         map[string]map[string]string
+	excludedColumns      map[string]map[string]bool
+	copyFormat           utils.CopyFormat
 	quotedRoleNames      map[string]string
+	catalogQueryCache    map[string]interface{}
+	excludedArtifacts    []string
+	notifications        *utils.Notifications
+	backupMetrics        *BackupMetrics
+	metricsServer        *http.Server
+	progressStream       *utils.ProgressStream
+	backupArchive        *utils.ArchiveWriter
+	statementMiddleware  []utils.StatementMiddleware
 	/*
-	 * Used for synchronizing DoCleanup.  In DoInit() we increment the group
-	 * and then wait for at least one DoCleanup to finish, either in DoTeardown
-	 * or the signal handler.
+	 * Used for synchronizing DoCleanup.  The group is incremented when the
+	 * backup starts, and the backup then waits for at least one DoCleanup to
+	 * finish, either in teardownBackup or the signal handler.
 	 */
-	CleanupGroup *sync.WaitGroup
-)
-
-/*
- * Command-line flags
- */
-var cmdFlags *pflag.FlagSet
+	cleanupGroup        *sync.WaitGroup
+	errorContextMux     *sync.Mutex
+	currentErrorContext ErrorContext
+}
+
+func newBackupState(flags *pflag.FlagSet, conn *dbconn.DBConn, ctx context.Context) backupState {
+	cleanupGroup := &sync.WaitGroup{}
+	cleanupGroup.Add(1)
+	return backupState{
+		cmdFlags:        flags,
+		connectionPool:  conn,
+		backupContext:   ctx,
+		queryContext:    ctx,
+		objectCounts:    make(map[string]int),
+		copyFormat:      utils.NewCopyFormat(utils.COPY_FORMAT_CSV, "", nil, false),
+		cleanupGroup:    cleanupGroup,
+		errorContextMux: &sync.Mutex{},
+	}
+}
 
 /*
  * Setter functions
  */
 
-func SetCmdFlags(flagSet *pflag.FlagSet) {
-	cmdFlags = flagSet
-}
-
-func SetConnection(conn *dbconn.DBConn) {
-	connectionPool = conn
+func (b *Backup) SetCmdFlags(flagSet *pflag.FlagSet) {
+	b.cmdFlags = flagSet
 }
 
-func SetCluster(cluster *cluster.Cluster) {
-	globalCluster = cluster
+func (b *Backup) SetConnection(conn *dbconn.DBConn) {
+	b.connectionPool = conn
 }
 
-func SetFPInfo(fpInfo backup_filepath.FilePathInfo) {
-	globalFPInfo = fpInfo
+func (b *Backup) SetCluster(cluster *cluster.Cluster) {
+	b.globalCluster = cluster
 }
 
-func SetPluginConfig(config *utils.PluginConfig) {
-	pluginConfig = config
+func (b *Backup) SetFPInfo(fpInfo backup_filepath.FilePathInfo) {
+	b.globalFPInfo = fpInfo
 }
 
-func SetReport(report *utils.Report) {
-	backupReport = report
+func (b *Backup) SetPluginConfig(config *utils.PluginConfig) {
+	b.pluginConfig = config
 }
 
-func GetReport() *utils.Report {
-	return backupReport
+func (b *Backup) SetReport(report *utils.Report) {
+	b.backupReport = report
 }
 
-func SetTOC(toc *utils.TOC) {
-	globalTOC = toc
+func (b *Backup) SetTOC(toc *utils.TOC) {
+	b.globalTOC = toc
 }
 
 func SetVersion(v string) {
 	version = v
 }
 
-func SetFilterRelationClause(filterClause string) {
-	filterRelationClause = filterClause
+func (b *Backup) SetFilterRelationClause(filterClause string) {
+	b.filterRelationClause = filterClause
+}
+
+func (b *Backup) SetDataFilters(filters map[string]string) {
+	b.dataFilters = filters
+}
+
+func (b *Backup) SetMaskingRules(rules map[string]map[string]string) {
+	b.maskingRules = rules
+}
+
+func (b *Backup) SetExcludedColumns(columns map[string]map[string]bool) {
+	b.excludedColumns = columns
+}
+
+func (b *Backup) SetCopyFormat(format utils.CopyFormat) {
+	b.copyFormat = format
+}
+
+func (b *Backup) SetQuotedRoleNames(quotedRoles map[string]string) {
+	b.quotedRoleNames = quotedRoles
 }
 
-func SetQuotedRoleNames(quotedRoles map[string]string) {
-	quotedRoleNames = quotedRoles
+func (b *Backup) SetCatalogQueryCache(cache map[string]interface{}) {
+	b.catalogQueryCache = cache
 }
 
 // Util functions to enable ease of access to global flag values
 
-func MustGetFlagString(flagName string) string {
-	return utils.MustGetFlagString(cmdFlags, flagName)
+func (b *Backup) MustGetFlagString(flagName string) string {
+	return utils.MustGetFlagString(b.cmdFlags, flagName)
 }
 
-func MustGetFlagInt(flagName string) int {
-	return utils.MustGetFlagInt(cmdFlags, flagName)
+func (b *Backup) MustGetFlagInt(flagName string) int {
+	return utils.MustGetFlagInt(b.cmdFlags, flagName)
 }
 
-func MustGetFlagBool(flagName string) bool {
-	return utils.MustGetFlagBool(cmdFlags, flagName)
+func (b *Backup) MustGetFlagBool(flagName string) bool {
+	return utils.MustGetFlagBool(b.cmdFlags, flagName)
 }
 
-func MustGetFlagStringSlice(flagName string) []string {
-	return utils.MustGetFlagStringSlice(cmdFlags, flagName)
+func (b *Backup) MustGetFlagStringSlice(flagName string) []string {
+	return utils.MustGetFlagStringSlice(b.cmdFlags, flagName)
 }
 
-func MustGetFlagStringArray(flagName string) []string {
-	return utils.MustGetFlagStringArray(cmdFlags, flagName)
+func (b *Backup) MustGetFlagStringArray(flagName string) []string {
+	return utils.MustGetFlagStringArray(b.cmdFlags, flagName)
 }
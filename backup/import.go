@@ -0,0 +1,492 @@
+package backup
+
+/*
+ * This file contains the "gpbackup import-pgdump" command, which converts a
+ * plain-format pg_dump or pg_dumpall file into a backup that gprestore can
+ * restore, to ease migrating a database from PostgreSQL to Greenplum.
+ */
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/iohelper"
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/greenplum-db/gpbackup/backup_filepath"
+	"github.com/greenplum-db/gpbackup/backup_history"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+/*
+ * An imported backup is restored as though it were taken on GPDB 6, so that
+ * it can be restored to GPDB 6 or later without the legacy hash operators.
+ * Tables are given oids from the first oid that PostgreSQL assigns to user
+ * objects, as their data files are named by oid.
+ */
+const (
+	IMPORT_DATABASE_VERSION = "6.0.0"
+	IMPORT_SEG_PREFIX       = "gpseg"
+	IMPORT_FIRST_OID        = 16384
+)
+
+const identPattern = `(?:"(?:[^"]|"")*"|[^\s."(),;]+)`
+
+var (
+	qualifiedNamePattern    = fmt.Sprintf(`(%s(?:\.%s)?)`, identPattern, identPattern)
+	importDatabaseRegex     = regexp.MustCompile(fmt.Sprintf(`(?s)^(?:(CREATE)|ALTER|COMMENT ON|(?:GRANT|REVOKE) .* ON) DATABASE (%s)`, identPattern))
+	importRoleRegex         = regexp.MustCompile(fmt.Sprintf(`^(?:CREATE|ALTER) ROLE (%s)`, identPattern))
+	importTablespaceRegex   = regexp.MustCompile(fmt.Sprintf(`^(?:CREATE|ALTER|COMMENT ON) TABLESPACE (%s)`, identPattern))
+	importAlterTableRegex   = regexp.MustCompile(fmt.Sprintf(`^ALTER TABLE (?:ONLY )?%s`, qualifiedNamePattern))
+	importOnTableRegex      = regexp.MustCompile(fmt.Sprintf(`(?s)\sON (?:ONLY )?%s`, qualifiedNamePattern))
+	importRuleTableRegex    = regexp.MustCompile(fmt.Sprintf(`(?s)\sAS\s+ON \w+ TO %s`, qualifiedNamePattern))
+	importCreateTableRegex  = regexp.MustCompile(`^CREATE (?:UNLOGGED )?TABLE `)
+	importDistributedRegex  = regexp.MustCompile(`\sDISTRIBUTED (?:BY|RANDOMLY|REPLICATED)`)
+	importUniqueKeyRegex    = regexp.MustCompile(fmt.Sprintf(`(?s)^ALTER TABLE (?:ONLY )?%s\s+ADD CONSTRAINT %s (PRIMARY KEY|UNIQUE) \(([^)]*)\)`, qualifiedNamePattern, identPattern))
+	importPostdataTypeNames = map[string]string{
+		"CHECK CONSTRAINT": "CONSTRAINT",
+		"CONSTRAINT":       "CONSTRAINT",
+		"DEFAULT ACL":      "DEFAULT PRIVILEGES",
+		"EVENT TRIGGER":    "EVENT TRIGGER",
+		"FK CONSTRAINT":    "CONSTRAINT",
+		"INDEX":            "INDEX",
+		"INDEX ATTACH":     "INDEX",
+		"POLICY":           "POLICY",
+		"ROW SECURITY":     "ROW LEVEL SECURITY",
+		"RULE":             "RULE",
+		"STATISTICS":       "STATISTICS",
+		"TRIGGER":          "TRIGGER",
+	}
+)
+
+type ImportedStatement struct {
+	Section   string
+	Entry     utils.MetadataEntry
+	Statement string
+}
+
+type PgDumpImport struct {
+	DatabaseName     string
+	FPInfo           backup_filepath.FilePathInfo
+	SegmentCount     int
+	CompressionLevel int
+	DumpVersion      string
+	Statements       []ImportedStatement
+	TOC              *utils.TOC
+	nextOid          uint32
+}
+
+func NewImportCommand() *cobra.Command {
+	importCmd := &cobra.Command{
+		Use:   "import-pgdump <dump file>",
+		Short: "Convert a plain-format pg_dump or pg_dumpall file into a backup",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			NewBackup(cmd.Flags(), nil, nil).DoImportPgDump(args[0])
+		}}
+	SetImportFlagDefaults(importCmd.Flags())
+	return importCmd
+}
+
+func SetImportFlagDefaults(flagSet *pflag.FlagSet) {
+	flagSet.String(utils.BACKUP_DIR, "", "The absolute path of the directory in which to write the backup")
+	flagSet.Int(utils.COMPRESSION_LEVEL, 1, "Level of compression to use for data files. Valid values are between 1 and 9.")
+	flagSet.String(utils.DBNAME, "", "The database to import.  For a pg_dumpall file, only the objects of this database and the global objects are imported.")
+	flagSet.Bool(utils.DEBUG, false, "Print verbose and debug log messages")
+	flagSet.Bool(utils.NO_COMPRESSION, false, "Disable compression of data files")
+	flagSet.Bool(utils.QUIET, false, "Suppress non-warning, non-error log messages")
+	flagSet.Int(utils.SEGMENT_COUNT, 0, "The number of primary segments of the cluster to which the backup will be restored")
+	flagSet.Bool(utils.VERBOSE, false, "Print verbose log messages")
+}
+
+func (b *Backup) DoImportPgDump(dumpFilename string) {
+	b.SetLoggerVerbosity()
+	b.validateImportFlags()
+	compressionLevel := b.MustGetFlagInt(utils.COMPRESSION_LEVEL)
+	if b.MustGetFlagBool(utils.NO_COMPRESSION) {
+		compressionLevel = 0
+	}
+	fpInfo := backup_filepath.FilePathInfo{
+		Timestamp:              backup_history.CurrentTimestamp(),
+		UserSpecifiedBackupDir: b.MustGetFlagString(utils.BACKUP_DIR),
+		UserSpecifiedSegPrefix: IMPORT_SEG_PREFIX,
+	}
+	importer := NewPgDumpImport(b.MustGetFlagString(utils.DBNAME), fpInfo, b.MustGetFlagInt(utils.SEGMENT_COUNT), compressionLevel)
+	for contentID := -1; contentID < importer.SegmentCount; contentID++ {
+		err := operating.System.MkdirAll(fpInfo.GetDirForContent(contentID), 0755)
+		gplog.FatalOnError(err)
+	}
+
+	gplog.Info("Importing database %s from %s to backup %s", importer.DatabaseName, dumpFilename, fpInfo.Timestamp)
+	dumpFile := iohelper.MustOpenFileForReading(dumpFilename)
+	err := importer.ReadDump(utils.NewPgDumpReader(dumpFile))
+	_ = dumpFile.Close()
+	gplog.FatalOnError(err)
+	importer.WriteBackup(version)
+	gplog.Info("Imported %d tables to backup %s; restore it with gprestore --timestamp %s --backup-dir %s", len(importer.TOC.DataEntries), fpInfo.Timestamp, fpInfo.Timestamp, fpInfo.UserSpecifiedBackupDir)
+}
+
+func (b *Backup) validateImportFlags() {
+	if b.MustGetFlagString(utils.BACKUP_DIR) == "" {
+		gplog.Fatal(errors.Errorf("--%s must be specified", utils.BACKUP_DIR), "")
+	}
+	gplog.FatalOnError(utils.ValidateFullPath(b.MustGetFlagString(utils.BACKUP_DIR)))
+	if b.MustGetFlagString(utils.DBNAME) == "" {
+		gplog.Fatal(errors.Errorf("--%s must be specified", utils.DBNAME), "")
+	}
+	if b.MustGetFlagInt(utils.SEGMENT_COUNT) < 1 {
+		gplog.Fatal(errors.Errorf("--%s must be specified as the number of primary segments of the target cluster", utils.SEGMENT_COUNT), "")
+	}
+	if level := b.MustGetFlagInt(utils.COMPRESSION_LEVEL); level < 1 || level > 9 {
+		gplog.Fatal(errors.Errorf("Compression level must be between 1 and 9"), "")
+	}
+}
+
+func NewPgDumpImport(dbName string, fpInfo backup_filepath.FilePathInfo, segmentCount int, compressionLevel int) *PgDumpImport {
+	toc := &utils.TOC{}
+	toc.InitializeMetadataEntryMap()
+	return &PgDumpImport{
+		DatabaseName:     dbName,
+		FPInfo:           fpInfo,
+		SegmentCount:     segmentCount,
+		CompressionLevel: compressionLevel,
+		Statements:       make([]ImportedStatement, 0),
+		TOC:              toc,
+		nextOid:          IMPORT_FIRST_OID,
+	}
+}
+
+/*
+ * Metadata statements are kept in memory until the whole dump has been read,
+ * as the distribution of a table is inferred from constraints that pg_dump
+ * writes after the table's data.  Table data is written to the data files as
+ * it is read.
+ */
+func (importer *PgDumpImport) ReadDump(dumpReader *utils.PgDumpReader) error {
+	for {
+		statement, err := dumpReader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if statement.CopyTable != "" {
+			if statement.Database == "" || statement.Database == importer.DatabaseName {
+				if err = importer.importTableData(dumpReader, statement); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if imported, ok := ClassifyDumpStatement(statement, importer.DatabaseName); ok {
+			importer.Statements = append(importer.Statements, imported)
+		}
+	}
+	importer.DumpVersion = dumpReader.DatabaseVersion
+	return nil
+}
+
+/*
+ * Rows are spread across the segments' data files in turn.  The tables are
+ * created randomly distributed, so the rows may be loaded on any segment, and
+ * are redistributed by their inferred distribution keys after the data is
+ * restored.
+ */
+func (importer *PgDumpImport) importTableData(dumpReader *utils.PgDumpReader, statement utils.PgDumpStatement) error {
+	schema, name := splitImportedTableName(statement.CopyTable)
+	oid := importer.nextOid
+	importer.nextOid++
+	extension := ""
+	if importer.CompressionLevel > 0 {
+		extension = ".gz"
+	}
+	files := make([]*utils.FileWithByteCount, importer.SegmentCount)
+	for contentID := range files {
+		filename := importer.FPInfo.GetTableBackupFilePath(contentID, oid, extension, false)
+		if importer.CompressionLevel > 0 {
+			files[contentID] = utils.NewCompressedFileWithByteCountFromFile(filename, importer.CompressionLevel)
+		} else {
+			files[contentID] = utils.NewFileWithByteCountFromFile(filename)
+		}
+	}
+	var rows int64
+	err := dumpReader.ReadCopyData(func(row string) error {
+		files[rows%int64(importer.SegmentCount)].MustPrint(row)
+		rows++
+		return nil
+	})
+	for _, file := range files {
+		file.Close()
+	}
+	if err != nil {
+		return errors.Wrapf(err, "Unable to import data of table %s", statement.CopyTable)
+	}
+	attributeString := ""
+	if statement.CopyColumns != "" {
+		attributeString = fmt.Sprintf("(%s)", strings.Join(utils.SplitQuotedList(statement.CopyColumns, ','), ","))
+	}
+	importer.TOC.AddMasterDataEntry(schema, name, oid, attributeString, rows, "")
+	gplog.Verbose("Imported %d rows of table %s", rows, statement.CopyTable)
+	return nil
+}
+
+func splitImportedTableName(fqn string) (string, string) {
+	parts := utils.SplitQuotedList(fqn, '.')
+	if len(parts) == 1 {
+		return "public", parts[0]
+	}
+	return parts[0], parts[1]
+}
+
+/*
+ * Determines the section and table of contents entry of a statement from the
+ * dump, or returns false for statements that are not imported: the settings
+ * that pg_dump writes for the restoring session, which gprestore sets itself,
+ * and the objects of other databases in a pg_dumpall file.  Roles and
+ * tablespaces in a pg_dumpall file are not described by headers, so they are
+ * recognized by their statements.
+ */
+func ClassifyDumpStatement(statement utils.PgDumpStatement, dbName string) (ImportedStatement, bool) {
+	text := strings.TrimSpace(statement.Statement)
+	if strings.HasPrefix(text, "SET ") || strings.HasPrefix(text, "RESET ") || strings.HasPrefix(text, "SELECT pg_catalog.set_config(") {
+		return ImportedStatement{}, false
+	}
+	imported := ImportedStatement{Statement: text}
+
+	if matches := importDatabaseRegex.FindStringSubmatch(text); matches != nil {
+		if utils.UnquoteIdent(matches[2]) != dbName {
+			return ImportedStatement{}, false
+		}
+		imported.Section = "global"
+		imported.Entry = utils.MetadataEntry{Name: matches[2], ObjectType: "DATABASE METADATA"}
+		if matches[1] != "" {
+			imported.Entry.ObjectType = "DATABASE"
+		}
+		return imported, true
+	}
+	if statement.Database != "" && statement.Database != dbName {
+		return ImportedStatement{}, false
+	}
+
+	if statement.ObjectType == "" {
+		if matches := importRoleRegex.FindStringSubmatch(text); matches != nil {
+			imported.Section = "global"
+			imported.Entry = utils.MetadataEntry{Name: utils.UnquoteIdent(matches[1]), ObjectType: "ROLE"}
+			return imported, true
+		} else if matches := importTablespaceRegex.FindStringSubmatch(text); matches != nil {
+			imported.Section = "global"
+			imported.Entry = utils.MetadataEntry{Name: matches[1], ObjectType: "TABLESPACE"}
+			return imported, true
+		} else if strings.HasPrefix(text, "GRANT ") && !strings.Contains(text, " ON ") {
+			imported.Section = "global"
+			imported.Entry = utils.MetadataEntry{ObjectType: "ROLE GRANT"}
+			return imported, true
+		}
+	}
+
+	schema := ""
+	if statement.Schema != "" {
+		schema = utils.QuoteIdentIfNeeded(statement.Schema)
+	}
+	if objectType, ok := importPostdataTypeNames[statement.ObjectType]; ok {
+		imported.Section = "postdata"
+		imported.Entry = utils.MetadataEntry{Schema: schema, Name: statement.Name, ObjectType: objectType, ReferenceObject: GetImportReferenceObject(objectType, text)}
+		return imported, true
+	}
+	if isImportedPostdataMetadata(statement) {
+		imported.Section = "postdata"
+		imported.Entry = utils.MetadataEntry{Schema: schema, Name: statement.Name, ObjectType: statement.ObjectType}
+		return imported, true
+	}
+
+	imported.Section = "predata"
+	imported.Entry = utils.MetadataEntry{Schema: schema, Name: statement.Name, ObjectType: statement.ObjectType}
+	if statement.ObjectType == "TABLE" || statement.ObjectType == "SCHEMA" {
+		imported.Entry.Name = utils.QuoteIdentIfNeeded(statement.Name)
+	}
+	return imported, true
+}
+
+/*
+ * Materialized views are refreshed once their tables' data is restored, and
+ * comments and privileges on post-data objects must follow the objects.
+ */
+func isImportedPostdataMetadata(statement utils.PgDumpStatement) bool {
+	if statement.ObjectType == "MATERIALIZED VIEW DATA" {
+		return true
+	}
+	if statement.ObjectType != "COMMENT" && statement.ObjectType != "ACL" {
+		return false
+	}
+	for _, prefix := range []string{"CONSTRAINT ", "EVENT TRIGGER ", "INDEX ", "POLICY ", "RULE ", "TRIGGER "} {
+		if strings.HasPrefix(statement.Name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * Returns the table to which a post-data statement belongs, so that the
+ * statements for a table are restored in order, or an empty string for
+ * statements that belong to no one table.
+ */
+func GetImportReferenceObject(objectType string, statement string) string {
+	var matches []string
+	switch objectType {
+	case "CONSTRAINT", "ROW LEVEL SECURITY":
+		matches = importAlterTableRegex.FindStringSubmatch(statement)
+	case "INDEX", "POLICY", "TRIGGER":
+		if strings.HasPrefix(statement, "CREATE ") {
+			matches = importOnTableRegex.FindStringSubmatch(statement)
+		}
+	case "RULE":
+		matches = importRuleTableRegex.FindStringSubmatch(statement)
+	}
+	if matches == nil {
+		return ""
+	}
+	return matches[1]
+}
+
+/*
+ * Tables that do not specify a distribution are created randomly distributed,
+ * so that their data can be loaded on any segment.  Partitions are created
+ * with the distribution of their parent.
+ */
+func AddDefaultDistribution(statement string) string {
+	if !importCreateTableRegex.MatchString(statement) || strings.Contains(statement, " PARTITION OF ") || importDistributedRegex.MatchString(statement) {
+		return statement
+	}
+	if index := strings.LastIndex(statement, "\nPARTITION BY "); index != -1 {
+		return statement[:index] + "\nDISTRIBUTED RANDOMLY" + statement[index:]
+	}
+	return strings.TrimSuffix(statement, ";") + " DISTRIBUTED RANDOMLY;"
+}
+
+/*
+ * A table is distributed by its primary key, or by its first unique
+ * constraint if it has no primary key, as Greenplum requires the distribution
+ * key of a table to be part of every such constraint.  Returns the column list
+ * of each table's distribution key by table.
+ */
+func InferDistributionKeys(statements []ImportedStatement) map[string]string {
+	keys := make(map[string]string)
+	primaryKeys := make(map[string]bool)
+	for _, statement := range statements {
+		if statement.Entry.ObjectType != "CONSTRAINT" {
+			continue
+		}
+		matches := importUniqueKeyRegex.FindStringSubmatch(statement.Statement)
+		if matches == nil {
+			continue
+		}
+		table, constraintType, columns := matches[1], matches[2], strings.Join(utils.SplitQuotedList(matches[3], ','), ", ")
+		if constraintType == "PRIMARY KEY" {
+			keys[table] = columns
+			primaryKeys[table] = true
+		} else if _, ok := keys[table]; !ok && !primaryKeys[table] {
+			keys[table] = columns
+		}
+	}
+	return keys
+}
+
+/*
+ * Adds the default distribution to the imported tables, and distributes each
+ * table with an inferred distribution key by that key before any other
+ * post-data statement for the table is run, as a randomly distributed table
+ * cannot have a primary key or unique constraint.
+ */
+func PrepareImportedStatements(statements []ImportedStatement) []ImportedStatement {
+	keys := InferDistributionKeys(statements)
+	prepared := make([]ImportedStatement, 0, len(statements)+len(keys))
+	postdata := make([]ImportedStatement, 0)
+	distributed := make(map[string]bool)
+	for _, statement := range statements {
+		if statement.Section != "postdata" {
+			if statement.Entry.ObjectType == "TABLE" {
+				statement.Statement = AddDefaultDistribution(statement.Statement)
+			}
+			prepared = append(prepared, statement)
+			continue
+		}
+		if table := statement.Entry.ReferenceObject; keys[table] != "" && !distributed[table] {
+			distributed[table] = true
+			schema, name := splitImportedTableName(table)
+			prepared = append(prepared, ImportedStatement{
+				Section:   "postdata",
+				Entry:     utils.MetadataEntry{Schema: schema, Name: name, ObjectType: "DISTRIBUTION POLICY", ReferenceObject: table},
+				Statement: fmt.Sprintf("ALTER TABLE %s SET DISTRIBUTED BY (%s);", table, keys[table]),
+			})
+		}
+		postdata = append(postdata, statement)
+	}
+	return append(prepared, postdata...)
+}
+
+/*
+ * Writes the metadata file, table of contents, and config file of the backup
+ * and marks it as complete, so that gprestore finds it as it would a backup
+ * taken with --backup-dir.
+ */
+func (importer *PgDumpImport) WriteBackup(backupVersion string) {
+	fpInfo := importer.FPInfo
+	metadataFile := utils.NewFileWithByteCountFromFile(fpInfo.GetMetadataFilePath())
+	statements := PrepareImportedStatements(importer.Statements)
+	for _, section := range []string{"global", "predata", "postdata"} {
+		for _, statement := range statements {
+			if statement.Section == section {
+				metadataFile.MustPrintEntry(importer.TOC, section, statement.Entry, "\n\n%s\n", statement.Statement)
+			}
+		}
+	}
+	metadataFile.Close()
+	importer.TOC.MetadataChecksum = metadataFile.Checksum()
+	importer.TOC.WriteToFileAndMakeReadOnly(fpInfo.GetTOCFilePath())
+
+	tableFQNs := make([]string, 0, len(importer.TOC.DataEntries))
+	for _, entry := range importer.TOC.DataEntries {
+		tableFQNs = append(tableFQNs, utils.MakeFQN(entry.Schema, entry.Name))
+	}
+	copyFormat := utils.NewCopyFormat(utils.COPY_FORMAT_TEXT, "", nil, false)
+	config := backup_history.BackupConfig{
+		BackupDir:       fpInfo.UserSpecifiedBackupDir,
+		BackupVersion:   backupVersion,
+		Compressed:      importer.CompressionLevel > 0,
+		CopyDelimiter:   copyFormat.Delimiter,
+		CopyFormat:      copyFormat.Format,
+		CopyNullString:  copyFormat.NullString,
+		DatabaseName:    utils.QuoteIdentIfNeeded(importer.DatabaseName),
+		DatabaseVersion: importer.GetDatabaseVersion(),
+		RestorePlan:     []backup_history.RestorePlanEntry{{Timestamp: fpInfo.Timestamp, TableFQNs: tableFQNs}},
+		SegmentCount:    importer.SegmentCount,
+		Timestamp:       fpInfo.Timestamp,
+		EndTime:         backup_history.CurrentTimestamp(),
+	}
+	backup_history.WriteConfigFile(&config, fpInfo.GetConfigFilePath())
+
+	marker := utils.BackupMarker{Timestamp: fpInfo.Timestamp, EndTime: config.EndTime}
+	for contentID := 0; contentID < importer.SegmentCount; contentID++ {
+		writeImportMarker(fpInfo, contentID, marker)
+	}
+	writeImportMarker(fpInfo, -1, marker)
+}
+
+func (importer *PgDumpImport) GetDatabaseVersion() string {
+	if importer.DumpVersion == "" {
+		return fmt.Sprintf("%s (imported from pg_dump)", IMPORT_DATABASE_VERSION)
+	}
+	return fmt.Sprintf("%s (imported from pg_dump of PostgreSQL %s)", IMPORT_DATABASE_VERSION, importer.DumpVersion)
+}
+
+func writeImportMarker(fpInfo backup_filepath.FilePathInfo, contentID int, marker utils.BackupMarker) {
+	markerFile := utils.NewFileWithByteCountFromFile(fpInfo.GetBackupMarkerFilePath(contentID, backup_filepath.MARKER_COMPLETE))
+	markerFile.MustPrint(marker.Contents())
+	markerFile.Close()
+}
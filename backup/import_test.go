@@ -0,0 +1,113 @@
+package backup_test
+
+import (
+	"github.com/greenplum-db/gpbackup/backup"
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backup/import tests", func() {
+	Describe("ClassifyDumpStatement", func() {
+		It("skips session settings", func() {
+			for _, statement := range []string{"SET statement_timeout = 0;", "SELECT pg_catalog.set_config('search_path', '', false);"} {
+				_, ok := backup.ClassifyDumpStatement(utils.PgDumpStatement{Statement: statement}, "db")
+				Expect(ok).To(BeFalse())
+			}
+		})
+		It("imports only the objects of the given database", func() {
+			_, ok := backup.ClassifyDumpStatement(utils.PgDumpStatement{Statement: "CREATE SCHEMA s;", ObjectType: "SCHEMA", Name: "s", Database: "other"}, "db")
+			Expect(ok).To(BeFalse())
+			_, ok = backup.ClassifyDumpStatement(utils.PgDumpStatement{Statement: "CREATE DATABASE other;", ObjectType: "DATABASE", Name: "other", Database: "template1"}, "db")
+			Expect(ok).To(BeFalse())
+
+			imported, ok := backup.ClassifyDumpStatement(utils.PgDumpStatement{Statement: "CREATE DATABASE db WITH TEMPLATE = template0;", ObjectType: "DATABASE", Name: "db", Database: "template1"}, "db")
+			Expect(ok).To(BeTrue())
+			Expect(imported.Section).To(Equal("global"))
+			Expect(imported.Entry).To(Equal(utils.MetadataEntry{Name: "db", ObjectType: "DATABASE"}))
+			imported, ok = backup.ClassifyDumpStatement(utils.PgDumpStatement{Statement: "ALTER DATABASE db OWNER TO gpadmin;", ObjectType: "DATABASE", Name: "db", Database: "template1"}, "db")
+			Expect(ok).To(BeTrue())
+			Expect(imported.Entry).To(Equal(utils.MetadataEntry{Name: "db", ObjectType: "DATABASE METADATA"}))
+		})
+		It("recognizes the global objects of a pg_dumpall file", func() {
+			imported, _ := backup.ClassifyDumpStatement(utils.PgDumpStatement{Statement: `CREATE ROLE "Admin";`}, "db")
+			Expect(imported).To(Equal(backup.ImportedStatement{Section: "global", Entry: utils.MetadataEntry{Name: "Admin", ObjectType: "ROLE"}, Statement: `CREATE ROLE "Admin";`}))
+			imported, _ = backup.ClassifyDumpStatement(utils.PgDumpStatement{Statement: "GRANT admin TO gpadmin GRANTED BY gpadmin;"}, "db")
+			Expect(imported.Entry.ObjectType).To(Equal("ROLE GRANT"))
+			imported, _ = backup.ClassifyDumpStatement(utils.PgDumpStatement{Statement: "CREATE TABLESPACE ts OWNER gpadmin LOCATION '/data/ts';"}, "db")
+			Expect(imported.Entry).To(Equal(utils.MetadataEntry{Name: "ts", ObjectType: "TABLESPACE"}))
+		})
+		It("places objects in the pre-data or post-data section by type", func() {
+			imported, _ := backup.ClassifyDumpStatement(utils.PgDumpStatement{Statement: "CREATE TABLE public.\"Foo\" (\n    i integer\n);", ObjectType: "TABLE", Schema: "public", Name: "Foo"}, "db")
+			Expect(imported.Section).To(Equal("predata"))
+			Expect(imported.Entry).To(Equal(utils.MetadataEntry{Schema: "public", Name: `"Foo"`, ObjectType: "TABLE"}))
+
+			imported, _ = backup.ClassifyDumpStatement(utils.PgDumpStatement{Statement: "ALTER TABLE ONLY public.foo\n    ADD CONSTRAINT foo_fkey FOREIGN KEY (i) REFERENCES public.bar(i);", ObjectType: "FK CONSTRAINT", Schema: "public", Name: "foo foo_fkey"}, "db")
+			Expect(imported.Section).To(Equal("postdata"))
+			Expect(imported.Entry).To(Equal(utils.MetadataEntry{Schema: "public", Name: "foo foo_fkey", ObjectType: "CONSTRAINT", ReferenceObject: "public.foo"}))
+
+			imported, _ = backup.ClassifyDumpStatement(utils.PgDumpStatement{Statement: "COMMENT ON INDEX public.foo_idx IS 'x';", ObjectType: "COMMENT", Schema: "public", Name: "INDEX foo_idx"}, "db")
+			Expect(imported.Section).To(Equal("postdata"))
+			Expect(imported.Entry.ReferenceObject).To(Equal(""))
+
+			imported, _ = backup.ClassifyDumpStatement(utils.PgDumpStatement{Statement: "COMMENT ON TABLE public.foo IS 'x';", ObjectType: "COMMENT", Schema: "public", Name: "TABLE foo"}, "db")
+			Expect(imported.Section).To(Equal("predata"))
+		})
+	})
+	Describe("GetImportReferenceObject", func() {
+		It("finds the table of post-data statements", func() {
+			Expect(backup.GetImportReferenceObject("CONSTRAINT", "ALTER TABLE ONLY public.\"Foo\"\n    ADD CONSTRAINT foo_pkey PRIMARY KEY (i);")).To(Equal(`public."Foo"`))
+			Expect(backup.GetImportReferenceObject("INDEX", "CREATE INDEX foo_idx ON public.foo USING btree (i);")).To(Equal("public.foo"))
+			Expect(backup.GetImportReferenceObject("INDEX", "ALTER INDEX public.p_pkey ATTACH PARTITION public.c_pkey;")).To(Equal(""))
+			Expect(backup.GetImportReferenceObject("TRIGGER", "CREATE TRIGGER t BEFORE INSERT ON public.foo FOR EACH ROW EXECUTE FUNCTION public.f();")).To(Equal("public.foo"))
+			Expect(backup.GetImportReferenceObject("RULE", "CREATE RULE r AS\n    ON INSERT TO public.foo DO INSTEAD NOTHING;")).To(Equal("public.foo"))
+			Expect(backup.GetImportReferenceObject("EVENT TRIGGER", "CREATE EVENT TRIGGER e ON ddl_command_start\n   EXECUTE FUNCTION public.f();")).To(Equal(""))
+		})
+	})
+	Describe("AddDefaultDistribution", func() {
+		It("distributes tables randomly unless they specify a distribution", func() {
+			Expect(backup.AddDefaultDistribution("CREATE TABLE public.foo (\n    i integer\n);")).To(Equal("CREATE TABLE public.foo (\n    i integer\n) DISTRIBUTED RANDOMLY;"))
+			Expect(backup.AddDefaultDistribution("CREATE UNLOGGED TABLE public.foo (\n    i integer\n);")).To(Equal("CREATE UNLOGGED TABLE public.foo (\n    i integer\n) DISTRIBUTED RANDOMLY;"))
+			Expect(backup.AddDefaultDistribution("CREATE TABLE public.p (\n    i integer\n)\nPARTITION BY RANGE (i);")).To(Equal("CREATE TABLE public.p (\n    i integer\n)\nDISTRIBUTED RANDOMLY\nPARTITION BY RANGE (i);"))
+			for _, statement := range []string{
+				"CREATE TABLE public.foo (\n    i integer\n) DISTRIBUTED BY (i);",
+				"CREATE TABLE public.c PARTITION OF public.p\nFOR VALUES FROM (1) TO (2);",
+				"ALTER TABLE public.foo OWNER TO gpadmin;",
+			} {
+				Expect(backup.AddDefaultDistribution(statement)).To(Equal(statement))
+			}
+		})
+	})
+	Describe("PrepareImportedStatements", func() {
+		constraint := func(table string, statement string) backup.ImportedStatement {
+			return backup.ImportedStatement{Section: "postdata", Entry: utils.MetadataEntry{ObjectType: "CONSTRAINT", ReferenceObject: table}, Statement: statement}
+		}
+		It("infers distribution keys from primary keys and then unique constraints", func() {
+			keys := backup.InferDistributionKeys([]backup.ImportedStatement{
+				constraint("public.foo", "ALTER TABLE ONLY public.foo\n    ADD CONSTRAINT foo_u UNIQUE (j);"),
+				constraint("public.foo", "ALTER TABLE ONLY public.foo\n    ADD CONSTRAINT foo_pkey PRIMARY KEY (i,\"K\");"),
+				constraint("public.bar", "ALTER TABLE ONLY public.bar\n    ADD CONSTRAINT bar_u1 UNIQUE (a);"),
+				constraint("public.bar", "ALTER TABLE ONLY public.bar\n    ADD CONSTRAINT bar_u2 UNIQUE (b);"),
+				constraint("public.baz", "ALTER TABLE public.baz\n    ADD CONSTRAINT baz_check CHECK ((i > 0));"),
+			})
+			Expect(keys).To(Equal(map[string]string{"public.foo": `i, "K"`, "public.bar": "a"}))
+		})
+		It("distributes tables by their keys before their other post-data statements", func() {
+			statements := backup.PrepareImportedStatements([]backup.ImportedStatement{
+				{Section: "predata", Entry: utils.MetadataEntry{Schema: "public", Name: "foo", ObjectType: "TABLE"}, Statement: "CREATE TABLE public.foo (\n    i integer\n);"},
+				{Section: "postdata", Entry: utils.MetadataEntry{ObjectType: "INDEX", ReferenceObject: "public.foo"}, Statement: "CREATE INDEX foo_idx ON public.foo USING btree (i);"},
+				constraint("public.foo", "ALTER TABLE ONLY public.foo\n    ADD CONSTRAINT foo_pkey PRIMARY KEY (i);"),
+			})
+			Expect(statements).To(HaveLen(4))
+			Expect(statements[0].Statement).To(Equal("CREATE TABLE public.foo (\n    i integer\n) DISTRIBUTED RANDOMLY;"))
+			Expect(statements[1]).To(Equal(backup.ImportedStatement{
+				Section:   "postdata",
+				Entry:     utils.MetadataEntry{Schema: "public", Name: "foo", ObjectType: "DISTRIBUTION POLICY", ReferenceObject: "public.foo"},
+				Statement: "ALTER TABLE public.foo SET DISTRIBUTED BY (i);",
+			}))
+			Expect(statements[2].Entry.ObjectType).To(Equal("INDEX"))
+			Expect(statements[3].Entry.ObjectType).To(Equal("CONSTRAINT"))
+		})
+	})
+})
@@ -26,25 +26,80 @@ func FilterTablesForIncremental(lastBackupTOC, currentTOC *utils.TOC, tables []T
 	return filteredTables
 }
 
-func GetTargetBackupTimestamp() string {
+/*
+ * A LinkedTable is a table whose data file is linked from the backup with
+ * timestamp SourceTimestamp instead of being copied out of the database again.
+ */
+type LinkedTable struct {
+	Table
+	SourceTimestamp string
+}
+
+/*
+ * Splits tables into those whose data must be copied and those whose AO
+ * modcount and last DDL timestamp are unchanged since the last backup, along
+ * with the backup that holds the data for each unchanged table according to
+ * the last backup's restore plan.
+ */
+func FilterTablesForLinking(lastBackupTOC, currentTOC *utils.TOC, lastRestorePlan []backup_history.RestorePlanEntry, tables []Table) ([]Table, []LinkedTable) {
+	sourceTimestamps := make(map[string]string)
+	for _, restorePlanEntry := range lastRestorePlan {
+		for _, tableFQN := range restorePlanEntry.TableFQNs {
+			sourceTimestamps[tableFQN] = restorePlanEntry.Timestamp
+		}
+	}
+
+	tablesToCopy := make([]Table, 0)
+	tablesToLink := make([]LinkedTable, 0)
+	for _, table := range tables {
+		currentAOEntry, isAOTable := currentTOC.IncrementalMetadata.AO[table.FQN()]
+		previousAOEntry, wasAOTable := lastBackupTOC.IncrementalMetadata.AO[table.FQN()]
+		sourceTimestamp, hasData := sourceTimestamps[table.FQN()]
+		if isAOTable && wasAOTable && hasData && previousAOEntry == currentAOEntry {
+			tablesToLink = append(tablesToLink, LinkedTable{Table: table, SourceTimestamp: sourceTimestamp})
+		} else {
+			tablesToCopy = append(tablesToCopy, table)
+		}
+	}
+	return tablesToCopy, tablesToLink
+}
+
+/*
+ * Unlike for an incremental backup, a metadata-only, data-only, or deleted
+ * backup cannot be used as the source of data files to link.
+ */
+func (b *Backup) GetLatestBackupConfigForLinking(history *backup_history.History, currentBackupConfig *backup_history.BackupConfig) *backup_history.BackupConfig {
+	for _, backupConfig := range history.BackupConfigs {
+		if backupConfig.MetadataOnly || backupConfig.DataOnly || backupConfig.DateDeleted != "" {
+			continue
+		}
+		if b.MatchesIncrementalFlags(&backupConfig, currentBackupConfig) {
+			return &backupConfig
+		}
+	}
+
+	return nil
+}
+
+func (b *Backup) GetTargetBackupTimestamp() string {
 	targetTimestamp := ""
-	if fromTimestamp := MustGetFlagString(utils.FROM_TIMESTAMP); fromTimestamp != "" {
-		ValidateFromTimestamp(fromTimestamp)
+	if fromTimestamp := b.MustGetFlagString(utils.FROM_TIMESTAMP); fromTimestamp != "" {
+		b.ValidateFromTimestamp(fromTimestamp)
 		targetTimestamp = fromTimestamp
 	} else {
-		targetTimestamp = GetLatestMatchingBackupTimestamp()
+		targetTimestamp = b.GetLatestMatchingBackupTimestamp()
 	}
 	return targetTimestamp
 }
 
-func GetLatestMatchingBackupTimestamp() string {
+func (b *Backup) GetLatestMatchingBackupTimestamp() string {
 	var history *backup_history.History
 	var latestMatchingBackupHistoryEntry *backup_history.BackupConfig
 	var err error
-	if iohelper.FileExistsAndIsReadable(globalFPInfo.GetBackupHistoryFilePath()) {
-		history, err = backup_history.NewHistory(globalFPInfo.GetBackupHistoryFilePath())
+	if iohelper.FileExistsAndIsReadable(b.globalFPInfo.GetBackupHistoryFilePath()) {
+		history, err = backup_history.NewHistory(b.globalFPInfo.GetBackupHistoryFilePath())
 		gplog.FatalOnError(err)
-		latestMatchingBackupHistoryEntry = GetLatestMatchingBackupConfig(history, &backupReport.BackupConfig)
+		latestMatchingBackupHistoryEntry = b.GetLatestMatchingBackupConfig(history, &b.backupReport.BackupConfig)
 	}
 
 	if latestMatchingBackupHistoryEntry == nil {
@@ -55,9 +110,9 @@ func GetLatestMatchingBackupTimestamp() string {
 	return latestMatchingBackupHistoryEntry.Timestamp
 }
 
-func GetLatestMatchingBackupConfig(history *backup_history.History, currentBackupConfig *backup_history.BackupConfig) *backup_history.BackupConfig {
+func (b *Backup) GetLatestMatchingBackupConfig(history *backup_history.History, currentBackupConfig *backup_history.BackupConfig) *backup_history.BackupConfig {
 	for _, backupConfig := range history.BackupConfigs {
-		if MatchesIncrementalFlags(&backupConfig, currentBackupConfig) {
+		if b.MatchesIncrementalFlags(&backupConfig, currentBackupConfig) {
 			return &backupConfig
 		}
 	}
@@ -65,24 +120,31 @@ func GetLatestMatchingBackupConfig(history *backup_history.History, currentBacku
 	return nil
 }
 
-func MatchesIncrementalFlags(backupConfig *backup_history.BackupConfig, currentBackupConfig *backup_history.BackupConfig) bool {
-	return backupConfig.BackupDir == MustGetFlagString(utils.BACKUP_DIR) &&
+func (b *Backup) MatchesIncrementalFlags(backupConfig *backup_history.BackupConfig, currentBackupConfig *backup_history.BackupConfig) bool {
+	// A backup of only some of the rows or columns of its tables, or of masked values, cannot be the base of an incremental backup
+	return !backupConfig.DataFiltered && !backupConfig.DataMasked && len(backupConfig.ExcludeColumns) == 0 && !backupConfig.ParquetExport &&
+		backupConfig.BackupDir == b.MustGetFlagString(utils.BACKUP_DIR) &&
 		backupConfig.DatabaseName == currentBackupConfig.DatabaseName &&
-		backupConfig.LeafPartitionData == MustGetFlagBool(utils.LEAF_PARTITION_DATA) &&
+		backupConfig.LeafPartitionData == b.MustGetFlagBool(utils.LEAF_PARTITION_DATA) &&
 		backupConfig.Plugin == currentBackupConfig.Plugin &&
-		backupConfig.SingleDataFile == MustGetFlagBool(utils.SINGLE_DATA_FILE) &&
+		backupConfig.SingleDataFile == b.MustGetFlagBool(utils.SINGLE_DATA_FILE) &&
 		backupConfig.Compressed == currentBackupConfig.Compressed &&
+		utils.GetCopyFormat(backupConfig) == utils.GetCopyFormat(currentBackupConfig) &&
 		// Expanding of the include list happens before this now so we must compare again current backup config
 		utils.NewIncludeSet(backupConfig.IncludeRelations).Equals(utils.NewIncludeSet(currentBackupConfig.IncludeRelations)) &&
-		utils.NewIncludeSet(backupConfig.IncludeSchemas).Equals(utils.NewIncludeSet(MustGetFlagStringSlice(utils.INCLUDE_SCHEMA))) &&
-		utils.NewIncludeSet(backupConfig.ExcludeRelations).Equals(utils.NewIncludeSet(MustGetFlagStringSlice(utils.EXCLUDE_RELATION))) &&
-		utils.NewIncludeSet(backupConfig.ExcludeSchemas).Equals(utils.NewIncludeSet(MustGetFlagStringSlice(utils.EXCLUDE_SCHEMA)))
+		utils.NewIncludeSet(backupConfig.IncludeSchemas).Equals(utils.NewIncludeSet(b.MustGetFlagStringSlice(utils.INCLUDE_SCHEMA))) &&
+		utils.NewIncludeSet(backupConfig.ExcludeRelations).Equals(utils.NewIncludeSet(b.MustGetFlagStringSlice(utils.EXCLUDE_RELATION))) &&
+		utils.NewIncludeSet(backupConfig.ExcludeLeafPartitions).Equals(utils.NewIncludeSet(b.MustGetFlagStringArray(utils.EXCLUDE_LEAF_PARTITION))) &&
+		utils.NewIncludeSet(backupConfig.IncludeLeafPartitions).Equals(utils.NewIncludeSet(b.MustGetFlagStringArray(utils.INCLUDE_LEAF_PARTITION))) &&
+		backupConfig.LeafPartitionKeyStart == b.MustGetFlagString(utils.LEAF_PARTITION_KEY_START) &&
+		backupConfig.LeafPartitionKeyEnd == b.MustGetFlagString(utils.LEAF_PARTITION_KEY_END) &&
+		utils.NewIncludeSet(backupConfig.ExcludeSchemas).Equals(utils.NewIncludeSet(b.MustGetFlagStringSlice(utils.EXCLUDE_SCHEMA)))
 }
 
-func PopulateRestorePlan(changedTables []Table,
+func (b *Backup) PopulateRestorePlan(changedTables []Table,
 	restorePlan []backup_history.RestorePlanEntry, allTables []Table) []backup_history.RestorePlanEntry {
 	currBackupRestorePlanEntry := backup_history.RestorePlanEntry{
-		Timestamp: globalFPInfo.Timestamp,
+		Timestamp: b.globalFPInfo.Timestamp,
 		TableFQNs: make([]string, 0, len(changedTables)),
 	}
 
@@ -87,32 +87,114 @@ var _ = Describe("backup/incremental tests", func() {
 		It("Should return the latest backup's timestamp with matching Dbname", func() {
 			currentBackupConfig := backup_history.BackupConfig{DatabaseName: "test1"}
 
-			latestBackupHistoryEntry := backup.GetLatestMatchingBackupConfig(&history, &currentBackupConfig)
+			latestBackupHistoryEntry := testBackup.GetLatestMatchingBackupConfig(&history, &currentBackupConfig)
 
 			structmatcher.ExpectStructsToMatch(history.BackupConfigs[1], latestBackupHistoryEntry)
 		})
 		It("should return nil with no matching Dbname", func() {
 			currentBackupConfig := backup_history.BackupConfig{DatabaseName: "test3"}
 
-			latestBackupHistoryEntry := backup.GetLatestMatchingBackupConfig(&history, &currentBackupConfig)
+			latestBackupHistoryEntry := testBackup.GetLatestMatchingBackupConfig(&history, &currentBackupConfig)
 
 			Expect(latestBackupHistoryEntry).To(BeNil())
 		})
+		It("skips backups of only some of the rows of their tables", func() {
+			filteredHistory := backup_history.History{BackupConfigs: []backup_history.BackupConfig{
+				{DatabaseName: "test1", Timestamp: "timestamp2", DataFiltered: true},
+				{DatabaseName: "test1", Timestamp: "timestamp1"},
+			}}
+			currentBackupConfig := backup_history.BackupConfig{DatabaseName: "test1"}
+
+			latestBackupHistoryEntry := testBackup.GetLatestMatchingBackupConfig(&filteredHistory, &currentBackupConfig)
+
+			structmatcher.ExpectStructsToMatch(filteredHistory.BackupConfigs[1], latestBackupHistoryEntry)
+		})
 		It("should return nil with an empty history", func() {
 			currentBackupConfig := backup_history.BackupConfig{}
 
-			latestBackupHistoryEntry := backup.
+			latestBackupHistoryEntry := testBackup.
 				GetLatestMatchingBackupConfig(&backup_history.History{BackupConfigs: []backup_history.BackupConfig{}}, &currentBackupConfig)
 
 			Expect(latestBackupHistoryEntry).To(BeNil())
 		})
 	})
 
+	Describe("FilterTablesForLinking", func() {
+		defaultEntry := utils.AOEntry{
+			Modcount:         0,
+			LastDDLTimestamp: "00000",
+		}
+		prevTOC := utils.TOC{
+			IncrementalMetadata: utils.IncrementalEntries{
+				AO: map[string]utils.AOEntry{
+					"public.ao_changed_modcount": defaultEntry,
+					"public.ao_unchanged":        defaultEntry,
+					"public.ao_unchanged_old":    defaultEntry,
+					"public.ao_no_data":          defaultEntry,
+				},
+			},
+		}
+		currTOC := utils.TOC{
+			IncrementalMetadata: utils.IncrementalEntries{
+				AO: map[string]utils.AOEntry{
+					"public.ao_changed_modcount": {
+						Modcount:         2,
+						LastDDLTimestamp: "00000",
+					},
+					"public.ao_unchanged":     defaultEntry,
+					"public.ao_unchanged_old": defaultEntry,
+					"public.ao_no_data":       defaultEntry,
+					"public.ao_new":           defaultEntry,
+				},
+			},
+		}
+		restorePlan := []backup_history.RestorePlanEntry{
+			{Timestamp: "ts1", TableFQNs: []string{"public.ao_unchanged_old"}},
+			{Timestamp: "ts2", TableFQNs: []string{"public.heap", "public.ao_changed_modcount", "public.ao_unchanged"}},
+		}
+
+		tblHeap := backup.Table{Relation: backup.Relation{Schema: "public", Name: "heap"}}
+		tblAOChangedModcount := backup.Table{Relation: backup.Relation{Schema: "public", Name: "ao_changed_modcount"}}
+		tblAOUnchanged := backup.Table{Relation: backup.Relation{Schema: "public", Name: "ao_unchanged"}}
+		tblAOUnchangedOld := backup.Table{Relation: backup.Relation{Schema: "public", Name: "ao_unchanged_old"}}
+		tblAONoData := backup.Table{Relation: backup.Relation{Schema: "public", Name: "ao_no_data"}}
+		tblAONew := backup.Table{Relation: backup.Relation{Schema: "public", Name: "ao_new"}}
+		tables := []backup.Table{tblHeap, tblAOChangedModcount, tblAOUnchanged, tblAOUnchangedOld, tblAONoData, tblAONew}
+
+		tablesToCopy, tablesToLink := backup.FilterTablesForLinking(&prevTOC, &currTOC, restorePlan, tables)
+
+		It("copies heap tables, changed AO tables, and AO tables without data in a previous backup", func() {
+			Expect(tablesToCopy).To(Equal([]backup.Table{tblHeap, tblAOChangedModcount, tblAONoData, tblAONew}))
+		})
+		It("links unchanged AO tables from the backup holding their data", func() {
+			Expect(tablesToLink).To(Equal([]backup.LinkedTable{
+				{Table: tblAOUnchanged, SourceTimestamp: "ts2"},
+				{Table: tblAOUnchangedOld, SourceTimestamp: "ts1"},
+			}))
+		})
+	})
+
+	Describe("GetLatestBackupConfigForLinking", func() {
+		It("skips metadata-only, data-only, and deleted backups", func() {
+			history := backup_history.History{BackupConfigs: []backup_history.BackupConfig{
+				{DatabaseName: "test1", Timestamp: "timestamp4", MetadataOnly: true},
+				{DatabaseName: "test1", Timestamp: "timestamp3", DataOnly: true},
+				{DatabaseName: "test1", Timestamp: "timestamp2", DateDeleted: "20200101000000"},
+				{DatabaseName: "test1", Timestamp: "timestamp1"},
+			}}
+			currentBackupConfig := backup_history.BackupConfig{DatabaseName: "test1"}
+
+			latestBackupHistoryEntry := testBackup.GetLatestBackupConfigForLinking(&history, &currentBackupConfig)
+
+			structmatcher.ExpectStructsToMatch(history.BackupConfigs[3], latestBackupHistoryEntry)
+		})
+	})
+
 	Describe("PopulateRestorePlan", func() {
 		testCluster := testutils.SetDefaultSegmentConfiguration()
 		testFPInfo := backup_filepath.NewFilePathInfo(testCluster, "", "ts0",
 			"gpseg")
-		backup.SetFPInfo(testFPInfo)
+		testBackup.SetFPInfo(testFPInfo)
 
 		Context("Full backup", func() {
 			restorePlan := make([]backup_history.RestorePlanEntry, 0)
@@ -122,7 +204,7 @@ var _ = Describe("backup/incremental tests", func() {
 			}
 			allTables := backupSetTables
 
-			restorePlan = backup.PopulateRestorePlan(backupSetTables, restorePlan, allTables)
+			restorePlan = testBackup.PopulateRestorePlan(backupSetTables, restorePlan, allTables)
 
 			It("Should populate a restore plan with a single entry", func() {
 				Expect(restorePlan).To(HaveLen(1))
@@ -152,7 +234,7 @@ var _ = Describe("backup/incremental tests", func() {
 			Context("Incremental backup with no table drops in between", func() {
 				allTables := changedTables
 
-				restorePlan := backup.PopulateRestorePlan(changedTables, previousRestorePlan, allTables)
+				restorePlan := testBackup.PopulateRestorePlan(changedTables, previousRestorePlan, allTables)
 
 				It("should append 1 more entry to the previous restore plan", func() {
 					Expect(restorePlan[0:2]).To(Equal(previousRestorePlan[0:2]))
@@ -181,7 +263,7 @@ var _ = Describe("backup/incremental tests", func() {
 				allTables := changedTables[0:1] // exclude "heap1"
 				excludedTableFQN := "public.heap1"
 
-				restorePlan := backup.PopulateRestorePlan(changedTables[0:1], previousRestorePlan, allTables)
+				restorePlan := testBackup.PopulateRestorePlan(changedTables[0:1], previousRestorePlan, allTables)
 
 				Specify("That the added entry should NOT have the dropped table FQN", func() {
 					Expect(restorePlan[2].TableFQNs).To(Not(ContainElement(excludedTableFQN)))
@@ -205,10 +287,10 @@ var _ = Describe("backup/incremental tests", func() {
 			operating.InitializeSystemFunctions()
 		})
 		It("fatals when trying to take an incremental backup without a full backup", func() {
-			backup.SetFPInfo(backup_filepath.FilePathInfo{UserSpecifiedBackupDir: "/tmp", UserSpecifiedSegPrefix: "/test-prefix"})
-			backup.SetReport(&utils.Report{})
+			testBackup.SetFPInfo(backup_filepath.FilePathInfo{UserSpecifiedBackupDir: "/tmp", UserSpecifiedSegPrefix: "/test-prefix"})
+			testBackup.SetReport(&utils.Report{})
 
-			Expect(func() { backup.GetLatestMatchingBackupTimestamp() }).Should(Panic())
+			Expect(func() { testBackup.GetLatestMatchingBackupTimestamp() }).Should(Panic())
 			Expect(log.Contents()).To(ContainSubstring("There was no matching previous backup found with the flags provided. Please take a full backup."))
 
 		})
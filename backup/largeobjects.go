@@ -0,0 +1,30 @@
+package backup
+
+/*
+ * This file contains structs and functions related to backing up large
+ * objects, which are restored after all other data is restored.
+ */
+
+import (
+	"github.com/greenplum-db/gpbackup/utils"
+)
+
+/*
+ * The large object is created with its original OID, as tables in the
+ * database refer to large objects by OID.
+ */
+func (b *Backup) PrintCreateLargeObjectStatement(largeObjectsFile *utils.FileWithByteCount, toc *utils.TOC, largeObject LargeObject, largeObjectMetadata ObjectMetadata) {
+	section, entry := largeObject.GetMetadataEntry()
+	largeObjectsFile.MustPrintEntry(toc, section, entry, "\n\nSELECT pg_catalog.lo_create(%d);\n", largeObject.Oid)
+	b.PrintObjectMetadata(largeObjectsFile, toc, largeObjectMetadata, largeObject, "")
+}
+
+/*
+ * The data is hex-encoded and decoded on restore, so that the statement does
+ * not depend on the standard_conforming_strings or bytea_output settings.
+ */
+func PrintLargeObjectDataStatement(largeObjectsFile *utils.FileWithByteCount, toc *utils.TOC, largeObject LargeObject, offset int64, data []byte) {
+	section, entry := largeObject.GetMetadataEntry()
+	entry.ObjectType = "LARGE OBJECT DATA"
+	largeObjectsFile.MustPrintEntry(toc, section, entry, "\n\nSELECT pg_catalog.lo_put(%d, %d, pg_catalog.decode('%x', 'hex'));\n", largeObject.Oid, offset, data)
+}
@@ -0,0 +1,39 @@
+package backup_test
+
+import (
+	"github.com/greenplum-db/gpbackup/backup"
+	"github.com/greenplum-db/gpbackup/testutils"
+
+	. "github.com/onsi/ginkgo"
+)
+
+var _ = Describe("backup/largeobjects tests", func() {
+	largeObject := backup.LargeObject{Oid: 16384}
+	BeforeEach(func() {
+		toc, backupfile = testutils.InitializeTestTOC(buffer, "largeobjects")
+	})
+	Describe("PrintCreateLargeObjectStatement", func() {
+		It("prints a create statement for a large object without metadata", func() {
+			testBackup.PrintCreateLargeObjectStatement(backupfile, toc, largeObject, backup.ObjectMetadata{})
+			testutils.ExpectEntry(toc.LargeObjectEntries, 0, "", "", "16384", "LARGE OBJECT")
+			testutils.AssertBufferContents(toc.LargeObjectEntries, buffer, "SELECT pg_catalog.lo_create(16384);")
+		})
+		It("prints a create statement for a large object with privileges, an owner, and a comment", func() {
+			largeObjectMetadata := testutils.DefaultMetadata("LARGE OBJECT", true, true, true, false)
+			testBackup.PrintCreateLargeObjectStatement(backupfile, toc, largeObject, largeObjectMetadata)
+			testutils.AssertBufferContents(toc.LargeObjectEntries, buffer, "SELECT pg_catalog.lo_create(16384);",
+				"COMMENT ON LARGE OBJECT 16384 IS 'This is a large object comment.';",
+				"ALTER LARGE OBJECT 16384 OWNER TO testrole;",
+				`REVOKE ALL ON LARGE OBJECT 16384 FROM PUBLIC;
+REVOKE ALL ON LARGE OBJECT 16384 FROM testrole;
+GRANT ALL ON LARGE OBJECT 16384 TO testrole;`)
+		})
+	})
+	Describe("PrintLargeObjectDataStatement", func() {
+		It("prints hex-encoded data at the given offset", func() {
+			backup.PrintLargeObjectDataStatement(backupfile, toc, largeObject, 1048576, []byte("lo\x00data'"))
+			testutils.ExpectEntry(toc.LargeObjectEntries, 0, "", "", "16384", "LARGE OBJECT DATA")
+			testutils.AssertBufferContents(toc.LargeObjectEntries, buffer, "SELECT pg_catalog.lo_put(16384, 1048576, pg_catalog.decode('6c6f006461746127', 'hex'));")
+		})
+	})
+})
@@ -0,0 +1,111 @@
+package backup
+
+/*
+ * This file contains functions for recording the sizes and checksums of a
+ * backup's data files in its manifest, and for finding which data files each
+ * segment should have so that "gpbackup verify" can check them.
+ */
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/greenplum-db/gp-common-go-libs/cluster"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpbackup/backup_filepath"
+	"github.com/greenplum-db/gpbackup/backup_history"
+	"github.com/greenplum-db/gpbackup/utils"
+)
+
+/*
+ * The data files of a plugin or archive backup are streamed to the plugin or
+ * archive and never written to the segments, so the manifest of such a backup
+ * has only the row counts of its tables, as does that of a backup taken with
+ * --no-manifest-checksums, as computing the checksums reads every data file
+ * again.  With a single data file, gpbackup_helper writes the segment table of
+ * contents once it has finished writing the data file, so we wait for it
+ * before computing checksums.
+ */
+func (b *Backup) WriteBackupManifest() {
+	manifest := utils.Manifest{Segments: make(map[int][]utils.ManifestEntry), RowCounts: utils.GetRowCountsFromTOC(b.globalTOC)}
+	if b.pluginConfig != nil || b.backupArchive != nil || b.MustGetFlagBool(utils.NO_MANIFEST_CHECKSUMS) {
+		manifest.WriteToFileAndMakeReadOnly(b.globalFPInfo.GetManifestFilePath())
+		return
+	}
+	if b.MustGetFlagBool(utils.SINGLE_DATA_FILE) {
+		remoteOutput := b.globalCluster.GenerateAndExecuteCommand("Waiting for gpbackup_helper to finish writing data files", func(contentID int) string {
+			tocFile := b.globalFPInfo.GetSegmentTOCFilePath(contentID)
+			errorFile := fmt.Sprintf("%s_error", b.globalFPInfo.GetSegmentPipeFilePath(contentID))
+			return fmt.Sprintf(`while [[ ! -f "%s" && ! -f "%s" ]]; do sleep 1; done; ls "%s"`, tocFile, errorFile, tocFile)
+		}, cluster.ON_SEGMENTS)
+		b.globalCluster.CheckClusterError(remoteOutput, "Error occurred in gpbackup_helper", func(contentID int) string {
+			return "See gpAdminLog for gpbackup_helper on segment host for details: Error occurred while writing data file"
+		})
+	}
+
+	gplog.Verbose("Computing checksums of data files for the backup manifest")
+	remoteOutput := b.globalCluster.GenerateAndExecuteCommand("Computing checksums of data files", func(contentID int) string {
+		return fmt.Sprintf("find %s -maxdepth 1 -type f -name 'gpbackup_*' ! -name '*_%s' -exec cksum {} +", b.globalFPInfo.GetDirForContent(contentID), backup_filepath.MARKER_IN_PROGRESS)
+	}, cluster.ON_SEGMENTS)
+	b.globalCluster.CheckClusterError(remoteOutput, "Unable to compute checksums of data files", func(contentID int) string {
+		return fmt.Sprintf("Unable to compute checksums of data files in %s", b.globalFPInfo.GetDirForContent(contentID))
+	})
+
+	for contentID, output := range remoteOutput.Stdouts {
+		entries, err := utils.ParseCksumOutput(output)
+		gplog.FatalOnError(err)
+		manifest.Segments[contentID] = entries
+	}
+	manifest.WriteToFileAndMakeReadOnly(b.globalFPInfo.GetManifestFilePath())
+}
+
+/*
+ * Returns the oids of the tables whose data is in each backup's data files,
+ * by backup timestamp.  An incremental backup reads the data of tables that
+ * have not changed from the earlier backups in its restore plan.
+ */
+func GetDataFileSources(config *backup_history.BackupConfig, toc *utils.TOC) map[string][]uint32 {
+	timestampForTable := make(map[string]string)
+	for _, entry := range config.RestorePlan {
+		for _, tableFQN := range entry.TableFQNs {
+			timestampForTable[tableFQN] = entry.Timestamp
+		}
+	}
+	sources := make(map[string][]uint32)
+	for _, entry := range toc.DataEntries {
+		timestamp, ok := timestampForTable[utils.MakeFQN(entry.Schema, entry.Name)]
+		if !ok {
+			timestamp = config.Timestamp
+		}
+		sources[timestamp] = append(sources[timestamp], entry.Oid)
+	}
+	return sources
+}
+
+/*
+ * Returns the paths of the data files that a segment should have for the
+ * given sources: a data file and a table of contents file per backup when
+ * using a single data file, or one data file per table otherwise.
+ */
+func GetExpectedDataFiles(fpInfo backup_filepath.FilePathInfo, contentID int, config *backup_history.BackupConfig, sources map[string][]uint32) []string {
+	extension := ""
+	if config.ParquetExport {
+		extension = ".parquet"
+	} else if config.Compressed {
+		extension = ".gz"
+	}
+	files := make([]string, 0)
+	for timestamp, oids := range sources {
+		sourceFPInfo := fpInfo
+		sourceFPInfo.Timestamp = timestamp
+		if config.SingleDataFile {
+			files = append(files, sourceFPInfo.GetTableBackupFilePath(contentID, 0, extension, true), sourceFPInfo.GetSegmentTOCFilePath(contentID))
+			continue
+		}
+		for _, oid := range oids {
+			files = append(files, sourceFPInfo.GetTableBackupFilePath(contentID, oid, extension, false))
+		}
+	}
+	sort.Strings(files)
+	return files
+}
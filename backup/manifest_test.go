@@ -0,0 +1,92 @@
+package backup_test
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/greenplum-db/gpbackup/backup"
+	"github.com/greenplum-db/gpbackup/backup_filepath"
+	"github.com/greenplum-db/gpbackup/backup_history"
+	"github.com/greenplum-db/gpbackup/testutils"
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backup/manifest tests", func() {
+	toc := &utils.TOC{DataEntries: []utils.MasterDataEntry{
+		{Schema: "public", Name: "changed", Oid: 16384},
+		{Schema: "public", Name: "unchanged", Oid: 16390},
+		{Schema: "public", Name: "new", Oid: 16400},
+	}}
+	config := &backup_history.BackupConfig{
+		Timestamp:  "20190102000000",
+		Compressed: true,
+		RestorePlan: []backup_history.RestorePlanEntry{
+			{Timestamp: "20190101000000", TableFQNs: []string{"public.unchanged"}},
+			{Timestamp: "20190102000000", TableFQNs: []string{"public.changed"}},
+		},
+	}
+	fpInfo := backup_filepath.NewFilePathInfo(testutils.SetDefaultSegmentConfiguration(), "", "20190102000000", "gpseg")
+	Describe("WriteBackupManifest", func() {
+		It("records only row counts with --no-manifest-checksums", func() {
+			backupDir, err := ioutil.TempDir("", "manifest")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(backupDir)
+			tempFPInfo := backup_filepath.NewFilePathInfo(testutils.SetDefaultSegmentConfiguration(), backupDir, "20190102000000", "gpseg")
+			Expect(os.MkdirAll(tempFPInfo.GetDirForContent(-1), 0755)).To(Succeed())
+			testBackup.SetFPInfo(tempFPInfo)
+			testBackup.SetTOC(&utils.TOC{DataEntries: []utils.MasterDataEntry{{Schema: "public", Name: "changed", Oid: 16384, RowsCopied: 10}}})
+			testBackup.SetPluginConfig(nil)
+			_ = cmdFlags.Set(utils.NO_MANIFEST_CHECKSUMS, "true")
+
+			testBackup.WriteBackupManifest()
+
+			manifest := utils.NewManifest(tempFPInfo.GetManifestFilePath())
+			Expect(manifest.Segments).To(BeEmpty())
+			Expect(manifest.RowCounts).To(Equal(map[string]int64{"public.changed": 10}))
+		})
+	})
+	Describe("GetDataFileSources", func() {
+		It("groups tables by the backup that holds their data", func() {
+			Expect(backup.GetDataFileSources(config, toc)).To(Equal(map[string][]uint32{
+				"20190101000000": {16390},
+				"20190102000000": {16384, 16400},
+			}))
+		})
+	})
+	Describe("GetExpectedDataFiles", func() {
+		sources := map[string][]uint32{
+			"20190101000000": {16390},
+			"20190102000000": {16384},
+		}
+		It("expects one data file per table", func() {
+			Expect(backup.GetExpectedDataFiles(fpInfo, 0, config, sources)).To(Equal([]string{
+				"gpseg0/backups/20190101/20190101000000/gpbackup_0_20190101000000_16390.gz",
+				"gpseg0/backups/20190102/20190102000000/gpbackup_0_20190102000000_16384.gz",
+			}))
+		})
+		It("expects one Parquet file per table with a Parquet export", func() {
+			parquetConfig := *config
+			parquetConfig.ParquetExport = true
+
+			Expect(backup.GetExpectedDataFiles(fpInfo, 0, &parquetConfig, sources)).To(Equal([]string{
+				"gpseg0/backups/20190101/20190101000000/gpbackup_0_20190101000000_16390.parquet",
+				"gpseg0/backups/20190102/20190102000000/gpbackup_0_20190102000000_16384.parquet",
+			}))
+		})
+		It("expects a data file and a table of contents per backup with a single data file", func() {
+			singleFileConfig := *config
+			singleFileConfig.SingleDataFile = true
+			singleFileConfig.Compressed = false
+
+			Expect(backup.GetExpectedDataFiles(fpInfo, 1, &singleFileConfig, sources)).To(Equal([]string{
+				"gpseg1/backups/20190101/20190101000000/gpbackup_1_20190101000000",
+				"gpseg1/backups/20190101/20190101000000/gpbackup_1_20190101000000_toc.yaml",
+				"gpseg1/backups/20190102/20190102000000/gpbackup_1_20190102000000",
+				"gpseg1/backups/20190102/20190102000000/gpbackup_1_20190102000000_toc.yaml",
+			}))
+		})
+	})
+})
@@ -0,0 +1,78 @@
+package backup
+
+/*
+ * This file contains functions for writing the markers that show whether a
+ * backup is in progress or complete to each of its backup directories.
+ */
+
+import (
+	"fmt"
+	"hash/crc32"
+
+	"github.com/greenplum-db/gp-common-go-libs/cluster"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/iohelper"
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/greenplum-db/gpbackup/backup_filepath"
+	"github.com/greenplum-db/gpbackup/utils"
+)
+
+func (b *Backup) WriteInProgressMarkers(onSegments bool) {
+	marker := utils.BackupMarker{Timestamp: b.globalFPInfo.Timestamp}
+	b.writeMarkers(onSegments, func(contentID int) string {
+		return GetInProgressMarkerCommand(b.globalFPInfo, contentID, marker)
+	})
+}
+
+/*
+ * The segments' markers are written before the master's, so a completion
+ * marker on the master means that the backup has completed everywhere.
+ */
+func (b *Backup) WriteCompletionMarkers(onSegments bool) {
+	marker := utils.BackupMarker{
+		Timestamp:        b.globalFPInfo.Timestamp,
+		EndTime:          b.backupReport.BackupConfig.EndTime,
+		ManifestChecksum: GetManifestChecksum(b.globalFPInfo.GetManifestFilePath()),
+	}
+	b.writeMarkers(onSegments, func(contentID int) string {
+		return GetCompletionMarkerCommand(b.globalFPInfo, contentID, marker)
+	})
+	gplog.Verbose("Marked backup %s as complete", b.globalFPInfo.Timestamp)
+}
+
+func (b *Backup) writeMarkers(onSegments bool, generateCommand func(contentID int) string) {
+	if onSegments {
+		remoteOutput := b.globalCluster.GenerateAndExecuteCommand("Writing backup markers", generateCommand, cluster.ON_SEGMENTS)
+		b.globalCluster.CheckClusterError(remoteOutput, "Unable to write backup markers", func(contentID int) string {
+			return fmt.Sprintf("Unable to write backup marker in %s", b.globalFPInfo.GetDirForContent(contentID))
+		})
+	}
+	_, err := b.globalCluster.ExecuteLocalCommand(generateCommand(-1))
+	gplog.FatalOnError(err, fmt.Sprintf("Unable to write backup marker in %s", b.globalFPInfo.GetDirForContent(-1)))
+}
+
+func GetInProgressMarkerCommand(fpInfo backup_filepath.FilePathInfo, contentID int, marker utils.BackupMarker) string {
+	return fmt.Sprintf("printf '%%s' '%s' > %s", marker.Contents(), fpInfo.GetBackupMarkerFilePath(contentID, backup_filepath.MARKER_IN_PROGRESS))
+}
+
+/*
+ * The completion marker is written under a temporary name and renamed into
+ * place, so that it is never seen partially written, before the in-progress
+ * marker is removed.
+ */
+func GetCompletionMarkerCommand(fpInfo backup_filepath.FilePathInfo, contentID int, marker utils.BackupMarker) string {
+	markerPath := fpInfo.GetBackupMarkerFilePath(contentID, backup_filepath.MARKER_COMPLETE)
+	partialPath := utils.GetPartialFilename(markerPath)
+	return fmt.Sprintf("printf '%%s' '%s' > %s && mv %s %s && rm -f %s", marker.Contents(), partialPath, partialPath, markerPath,
+		fpInfo.GetBackupMarkerFilePath(contentID, backup_filepath.MARKER_IN_PROGRESS))
+}
+
+// Returns an empty string for backups without a manifest
+func GetManifestChecksum(manifestFilename string) string {
+	if !iohelper.FileExistsAndIsReadable(manifestFilename) {
+		return ""
+	}
+	contents, err := operating.System.ReadFile(manifestFilename)
+	gplog.FatalOnError(err)
+	return fmt.Sprintf("%08x", crc32.ChecksumIEEE(contents))
+}
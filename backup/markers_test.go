@@ -0,0 +1,34 @@
+package backup_test
+
+import (
+	"github.com/greenplum-db/gpbackup/backup"
+	"github.com/greenplum-db/gpbackup/backup_filepath"
+	"github.com/greenplum-db/gpbackup/testutils"
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backup/markers tests", func() {
+	fpInfo := backup_filepath.NewFilePathInfo(testutils.SetDefaultSegmentConfiguration(), "", "20190101000000", "gpseg")
+	Describe("GetInProgressMarkerCommand", func() {
+		It("writes the in-progress marker for the segment", func() {
+			marker := utils.BackupMarker{Timestamp: "20190101000000"}
+
+			Expect(backup.GetInProgressMarkerCommand(fpInfo, 0, marker)).To(Equal(`printf '%s' 'timestamp: "20190101000000"
+' > gpseg0/backups/20190101/20190101000000/gpbackup_20190101000000_in_progress`))
+		})
+	})
+	Describe("GetCompletionMarkerCommand", func() {
+		It("renames the completion marker into place before removing the in-progress marker", func() {
+			marker := utils.BackupMarker{Timestamp: "20190101000000", EndTime: "20190101000500"}
+			dir := "gpseg-1/backups/20190101/20190101000000"
+
+			Expect(backup.GetCompletionMarkerCommand(fpInfo, -1, marker)).To(Equal(`printf '%s' 'timestamp: "20190101000000"
+endtime: "20190101000500"
+' > ` + dir + `/gpbackup_20190101000000_complete.partial && mv ` + dir + `/gpbackup_20190101000000_complete.partial ` +
+				dir + `/gpbackup_20190101000000_complete && rm -f ` + dir + `/gpbackup_20190101000000_in_progress`))
+		})
+	})
+})
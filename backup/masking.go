@@ -0,0 +1,66 @@
+package backup
+
+/*
+ * This file contains functions for masking the values of sensitive columns
+ * as their tables' data is backed up, so that backups can be restored in
+ * lower environments without exposing production data.
+ */
+
+import (
+	"strings"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/pkg/errors"
+)
+
+/*
+ * Parses --masking-rules-file lines of the form schema.table.column:expression
+ * into a map from each table's FQN to a map from its masked columns to their
+ * expressions.  As with data filters, only the first colon separates the
+ * column from the expression, and identifiers must be quoted as they would be
+ * in SQL.
+ */
+func ParseMaskingRules(lines []string) map[string]map[string]string {
+	rules := make(map[string]map[string]string)
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		columnIndex := strings.LastIndex(parts[0], ".")
+		if len(parts) != 2 || strings.TrimSpace(parts[1]) == "" || columnIndex == -1 {
+			gplog.Fatal(errors.Errorf("Invalid masking rule %s.  Masking rules must be in the format schema.table.column:expression.", line), "")
+		}
+		tableFQN := strings.TrimSpace(parts[0][:columnIndex])
+		utils.ValidateFQNs([]string{tableFQN})
+		if _, ok := rules[tableFQN]; !ok {
+			rules[tableFQN] = make(map[string]string)
+		}
+		rules[tableFQN][strings.TrimSpace(parts[0][columnIndex+1:])] = strings.TrimSpace(parts[1])
+	}
+	return rules
+}
+
+/*
+ * A rule for a column that does not exist would otherwise back up the column's
+ * real values without any indication, so every rule for a table being backed
+ * up must match one of its columns.
+ */
+func (b *Backup) ValidateMaskingRules(tables []Table) {
+	for _, table := range tables {
+		columnRules, ok := b.maskingRules[table.FQN()]
+		if !ok {
+			continue
+		}
+		columnNames := make(map[string]bool, len(table.ColumnDefs))
+		for _, col := range table.ColumnDefs {
+			columnNames[col.Name] = true
+		}
+		for column := range columnRules {
+			if !columnNames[column] {
+				gplog.Fatal(errors.Errorf("Masking rule column %s does not exist in table %s", column, table.FQN()), "")
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package backup_test
+
+import (
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"github.com/greenplum-db/gpbackup/backup"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backup/masking tests", func() {
+	testTable := backup.Table{
+		Relation:        backup.Relation{Schema: "public", Name: "users"},
+		TableDefinition: backup.TableDefinition{ColumnDefs: []backup.ColumnDefinition{{Name: "id"}, {Name: "email"}, {Name: `"Phone"`}}},
+	}
+	AfterEach(func() {
+		testBackup.SetMaskingRules(nil)
+	})
+	Describe("ParseMaskingRules", func() {
+		It("parses rules into expressions by table and column", func() {
+			rules := backup.ParseMaskingRules([]string{"public.users.email:md5(email)", "", `public.users."Phone": '555-0100'`, "public.orders.note:NULL"})
+
+			Expect(rules).To(Equal(map[string]map[string]string{
+				"public.users":  {"email": "md5(email)", `"Phone"`: "'555-0100'"},
+				"public.orders": {"note": "NULL"},
+			}))
+		})
+		It("keeps colons in expressions", func() {
+			rules := backup.ParseMaskingRules([]string{"public.users.email:'x'::text"})
+
+			Expect(rules["public.users"]["email"]).To(Equal("'x'::text"))
+		})
+		It("panics on a rule without an expression", func() {
+			defer testhelper.ShouldPanicWithMessage("Invalid masking rule public.users.email")
+			backup.ParseMaskingRules([]string{"public.users.email"})
+		})
+		It("panics on a rule without a column", func() {
+			defer testhelper.ShouldPanicWithMessage("Invalid masking rule users:NULL")
+			backup.ParseMaskingRules([]string{"users:NULL"})
+		})
+	})
+	Describe("ValidateMaskingRules", func() {
+		It("accepts rules for existing columns", func() {
+			testBackup.SetMaskingRules(map[string]map[string]string{"public.users": {"email": "NULL"}})
+
+			testBackup.ValidateMaskingRules([]backup.Table{testTable})
+		})
+		It("panics on a rule for a column the table does not have", func() {
+			testBackup.SetMaskingRules(map[string]map[string]string{"public.users": {"mail": "NULL"}})
+
+			defer testhelper.ShouldPanicWithMessage("Masking rule column mail does not exist in table public.users")
+			testBackup.ValidateMaskingRules([]backup.Table{testTable})
+		})
+	})
+	Describe("GetDataSelectList", func() {
+		It("selects all columns of a table without rules", func() {
+			Expect(testBackup.GetDataSelectList(testTable)).To(Equal("*"))
+		})
+		It("replaces masked columns with their expressions in column order", func() {
+			testBackup.SetMaskingRules(map[string]map[string]string{"public.users": {"email": "md5(email)", `"Phone"`: "NULL"}})
+
+			Expect(testBackup.GetDataSelectList(testTable)).To(Equal(`id, md5(email) AS email, NULL AS "Phone"`))
+		})
+	})
+	Describe("GetTableDataQuery", func() {
+		It("selects masked values of a table with rules", func() {
+			testBackup.SetMaskingRules(map[string]map[string]string{"public.users": {"email": "md5(email)"}})
+
+			Expect(testBackup.GetTableDataQuery(testTable)).To(Equal(`SELECT id, md5(email) AS email, "Phone" FROM public.users`))
+		})
+	})
+})
@@ -25,7 +25,7 @@ SET client_encoding = '%s';
 	toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
 }
 
-func PrintCreateDatabaseStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, defaultDB Database, db Database, dbMetadata MetadataMap) {
+func (b *Backup) PrintCreateDatabaseStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, defaultDB Database, db Database, dbMetadata MetadataMap) {
 	start := metadataFile.ByteCount
 	metadataFile.MustPrintf("\n\nCREATE DATABASE %s TEMPLATE template0", db.Name)
 	if db.Tablespace != "pg_default" {
@@ -44,7 +44,7 @@ func PrintCreateDatabaseStatement(metadataFile *utils.FileWithByteCount, toc *ut
 
 	entry := utils.MetadataEntry{Name: db.Name, ObjectType: "DATABASE"}
 	toc.AddMetadataEntry("global", entry, start, metadataFile.ByteCount)
-	PrintObjectMetadata(metadataFile, toc, dbMetadata[db.GetUniqueID()], db, "")
+	b.PrintObjectMetadata(metadataFile, toc, dbMetadata[db.GetUniqueID()], db, "")
 }
 
 func PrintDatabaseGUCs(metadataFile *utils.FileWithByteCount, toc *utils.TOC, gucs []string, dbname string) {
@@ -57,8 +57,9 @@ func PrintDatabaseGUCs(metadataFile *utils.FileWithByteCount, toc *utils.TOC, gu
 	}
 }
 
-func PrintCreateResourceQueueStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, resQueues []ResourceQueue, resQueueMetadata MetadataMap) {
+func (b *Backup) PrintCreateResourceQueueStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, resQueues []ResourceQueue, resQueueMetadata MetadataMap) {
 	for _, resQueue := range resQueues {
+		b.SetCurrentTOCObject(resQueue)
 		start := metadataFile.ByteCount
 		attributes := make([]string, 0)
 		if resQueue.ActiveStatements != -1 {
@@ -91,7 +92,7 @@ func PrintCreateResourceQueueStatements(metadataFile *utils.FileWithByteCount, t
 
 		section, entry := resQueue.GetMetadataEntry()
 		toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-		PrintObjectMetadata(metadataFile, toc, resQueueMetadata[resQueue.GetUniqueID()], resQueue, "")
+		b.PrintObjectMetadata(metadataFile, toc, resQueueMetadata[resQueue.GetUniqueID()], resQueue, "")
 	}
 }
 
@@ -124,13 +125,17 @@ func PrintResetResourceGroupStatements(metadataFile *utils.FileWithByteCount, to
 	}
 }
 
-func PrintCreateResourceGroupStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, resGroups []ResourceGroup, resGroupMetadata MetadataMap) {
+func (b *Backup) PrintCreateResourceGroupStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, resGroups []ResourceGroup, resGroupMetadata MetadataMap) {
+	if b.connectionPool.Version.AtLeast("7") {
+		b.printCreateResourceGroupStatementsAtLeast7(metadataFile, toc, resGroups, resGroupMetadata)
+		return
+	}
 	for _, resGroup := range resGroups {
 
 		// temporarily special case for 5x resource groups #temp5xResGroup
 		memorySpillRatio := resGroup.MemorySpillRatio
 
-		if connectionPool.Version.Is("5") {
+		if b.connectionPool.Version.Is("5") {
 			/*
 			 * memory_spill_ratio can be set in absolute value format since 5.20,
 			 * such as '1 MB', it has to be set as a quoted string, otherwise set
@@ -173,7 +178,7 @@ func PrintCreateResourceGroupStatements(metadataFile *utils.FileWithByteCount, t
 			}
 
 			toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-			PrintObjectMetadata(metadataFile, toc, resGroupMetadata[resGroup.GetUniqueID()], resGroup, "")
+			b.PrintObjectMetadata(metadataFile, toc, resGroupMetadata[resGroup.GetUniqueID()], resGroup, "")
 		} else {
 			start = metadataFile.ByteCount
 			attributes := make([]string, 0)
@@ -207,12 +212,59 @@ func PrintCreateResourceGroupStatements(metadataFile *utils.FileWithByteCount, t
 			metadataFile.MustPrintf("\n\nCREATE RESOURCE GROUP %s WITH (%s);", resGroup.Name, strings.Join(attributes, ", "))
 
 			toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-			PrintObjectMetadata(metadataFile, toc, resGroupMetadata[resGroup.GetUniqueID()], resGroup, "")
+			b.PrintObjectMetadata(metadataFile, toc, resGroupMetadata[resGroup.GetUniqueID()], resGroup, "")
+		}
+	}
+}
+
+/*
+ * GPDB 7 does not limit the total CPU_MAX_PERCENT of all resource groups, so
+ * the built-in groups can be altered directly without resetting them first.
+ * A MEMORY_LIMIT of -1 means the group's memory is not limited, which is the
+ * default, so it is omitted.
+ */
+func (b *Backup) printCreateResourceGroupStatementsAtLeast7(metadataFile *utils.FileWithByteCount, toc *utils.TOC, resGroups []ResourceGroup, resGroupMetadata MetadataMap) {
+	type resGroupSetting struct {
+		setting string
+		value   string
+	}
+	for _, resGroup := range resGroups {
+		settings := make([]resGroupSetting, 0)
+		settings = append(settings, resGroupSetting{"CONCURRENCY", resGroup.Concurrency})
+		if !strings.HasPrefix(resGroup.CPURateLimit, "-") {
+			settings = append(settings, resGroupSetting{"CPU_MAX_PERCENT", resGroup.CPURateLimit})
+		} else {
+			settings = append(settings, resGroupSetting{"CPUSET", fmt.Sprintf("'%s'", resGroup.Cpuset)})
+		}
+		settings = append(settings, resGroupSetting{"CPU_WEIGHT", resGroup.CPUWeight})
+		if resGroup.MemoryLimit != "" && resGroup.MemoryLimit != "-1" {
+			settings = append(settings, resGroupSetting{"MEMORY_LIMIT", resGroup.MemoryLimit})
+		}
+		if resGroup.MinCost != "" {
+			settings = append(settings, resGroupSetting{"MIN_COST", resGroup.MinCost})
+		}
+
+		section, entry := resGroup.GetMetadataEntry()
+		if resGroup.Name == "default_group" || resGroup.Name == "admin_group" || resGroup.Name == "system_group" {
+			for _, property := range settings {
+				start := metadataFile.ByteCount
+				metadataFile.MustPrintf("\n\nALTER RESOURCE GROUP %s SET %s %s;", resGroup.Name, property.setting, property.value)
+				toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
+			}
+		} else {
+			attributes := make([]string, 0)
+			for _, property := range settings {
+				attributes = append(attributes, fmt.Sprintf("%s=%s", property.setting, property.value))
+			}
+			start := metadataFile.ByteCount
+			metadataFile.MustPrintf("\n\nCREATE RESOURCE GROUP %s WITH (%s);", resGroup.Name, strings.Join(attributes, ", "))
+			toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
 		}
+		b.PrintObjectMetadata(metadataFile, toc, resGroupMetadata[resGroup.GetUniqueID()], resGroup, "")
 	}
 }
 
-func PrintCreateRoleStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, roles []Role, roleMetadata MetadataMap) {
+func (b *Backup) PrintCreateRoleStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, roles []Role, roleMetadata MetadataMap) {
 	for _, role := range roles {
 		start := metadataFile.ByteCount
 		attrs := make([]string, 0)
@@ -269,7 +321,7 @@ func PrintCreateRoleStatements(metadataFile *utils.FileWithByteCount, toc *utils
 
 		attrs = append(attrs, fmt.Sprintf("RESOURCE QUEUE %s", role.ResQueue))
 
-		if connectionPool.Version.AtLeast("5") {
+		if b.connectionPool.Version.AtLeast("5") {
 			attrs = append(attrs, fmt.Sprintf("RESOURCE GROUP %s", role.ResGroup))
 		}
 
@@ -303,12 +355,10 @@ ALTER ROLE %s WITH %s;`, role.Name, role.Name, strings.Join(attrs, " "))
 
 		if len(role.TimeConstraints) != 0 {
 			for _, timeConstraint := range role.TimeConstraints {
-				start := metadataFile.ByteCount
-				metadataFile.MustPrintf("\nALTER ROLE %s DENY BETWEEN DAY %d TIME '%s' AND DAY %d TIME '%s';", role.Name, timeConstraint.StartDay, timeConstraint.StartTime, timeConstraint.EndDay, timeConstraint.EndTime)
-				toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
+				metadataFile.MustPrintEntry(toc, section, entry, "\nALTER ROLE %s DENY BETWEEN DAY %d TIME '%s' AND DAY %d TIME '%s';", role.Name, timeConstraint.StartDay, timeConstraint.StartTime, timeConstraint.EndDay, timeConstraint.EndTime)
 			}
 		}
-		PrintObjectMetadata(metadataFile, toc, roleMetadata[role.GetUniqueID()], role, "")
+		b.PrintObjectMetadata(metadataFile, toc, roleMetadata[role.GetUniqueID()], role, "")
 	}
 }
 
@@ -346,7 +396,7 @@ func PrintRoleMembershipStatements(metadataFile *utils.FileWithByteCount, toc *u
 	}
 }
 
-func PrintCreateTablespaceStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, tablespaces []Tablespace, tablespaceMetadata MetadataMap) {
+func (b *Backup) PrintCreateTablespaceStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, tablespaces []Tablespace, tablespaceMetadata MetadataMap) {
 	for _, tablespace := range tablespaces {
 		start := metadataFile.ByteCount
 		locationStr := ""
@@ -367,6 +417,6 @@ func PrintCreateTablespaceStatements(metadataFile *utils.FileWithByteCount, toc
 			metadataFile.MustPrintf("\n\nALTER TABLESPACE %s SET (%s);\n", tablespace.Tablespace, tablespace.Options)
 			toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
 		}
-		PrintObjectMetadata(metadataFile, toc, tablespaceMetadata[tablespace.GetUniqueID()], tablespace, "")
+		b.PrintObjectMetadata(metadataFile, toc, tablespaceMetadata[tablespace.GetUniqueID()], tablespace, "")
 	}
 }
@@ -4,8 +4,10 @@ import (
 	"github.com/greenplum-db/gp-common-go-libs/testhelper"
 	"github.com/greenplum-db/gpbackup/backup"
 	"github.com/greenplum-db/gpbackup/testutils"
+	"github.com/greenplum-db/gpbackup/utils"
 
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
 )
 
 var _ = Describe("backup/metadata_globals tests", func() {
@@ -27,14 +29,14 @@ var _ = Describe("backup/metadata_globals tests", func() {
 		It("prints a basic CREATE DATABASE statement", func() {
 			db := backup.Database{Oid: 1, Name: "testdb", Tablespace: "pg_default"}
 			emptyMetadataMap := backup.MetadataMap{}
-			backup.PrintCreateDatabaseStatement(backupfile, toc, emptyDB, db, emptyMetadataMap)
+			testBackup.PrintCreateDatabaseStatement(backupfile, toc, emptyDB, db, emptyMetadataMap)
 			testutils.ExpectEntry(toc.GlobalEntries, 0, "", "", "testdb", "DATABASE")
 			testutils.AssertBufferContents(toc.GlobalEntries, buffer, `CREATE DATABASE testdb TEMPLATE template0;`)
 		})
 		It("prints a CREATE DATABASE statement for a reserved keyword named database", func() {
 			db := backup.Database{Oid: 1, Name: `"table"`, Tablespace: "pg_default"}
 			emptyMetadataMap := backup.MetadataMap{}
-			backup.PrintCreateDatabaseStatement(backupfile, toc, emptyDB, db, emptyMetadataMap)
+			testBackup.PrintCreateDatabaseStatement(backupfile, toc, emptyDB, db, emptyMetadataMap)
 			testutils.ExpectEntry(toc.GlobalEntries, 0, "", "", `"table"`, "DATABASE")
 			testutils.AssertBufferContents(toc.GlobalEntries, buffer, `CREATE DATABASE "table" TEMPLATE template0;`)
 		})
@@ -44,7 +46,7 @@ var _ = Describe("backup/metadata_globals tests", func() {
 			dbMetadata := dbMetadataMap[db.GetUniqueID()]
 			dbMetadata.Privileges[0].Create = false
 			dbMetadataMap[db.GetUniqueID()] = dbMetadata
-			backup.PrintCreateDatabaseStatement(backupfile, toc, emptyDB, db, dbMetadataMap)
+			testBackup.PrintCreateDatabaseStatement(backupfile, toc, emptyDB, db, dbMetadataMap)
 			expectedStatements := []string{
 				`CREATE DATABASE testdb TEMPLATE template0;`,
 				`COMMENT ON DATABASE testdb IS 'This is a database comment.';`,
@@ -58,14 +60,14 @@ GRANT TEMPORARY,CONNECT ON DATABASE testdb TO testrole;`,
 		It("prints a CREATE DATABASE statement with all modifiers", func() {
 			db := backup.Database{Oid: 1, Name: "testdb", Tablespace: "test_tablespace", Encoding: "UTF8", Collate: "en_US.utf-8", CType: "en_US.utf-8"}
 			emptyMetadataMap := backup.MetadataMap{}
-			backup.PrintCreateDatabaseStatement(backupfile, toc, emptyDB, db, emptyMetadataMap)
+			testBackup.PrintCreateDatabaseStatement(backupfile, toc, emptyDB, db, emptyMetadataMap)
 			testutils.AssertBufferContents(toc.GlobalEntries, buffer, `CREATE DATABASE testdb TEMPLATE template0 TABLESPACE test_tablespace ENCODING 'UTF8' LC_COLLATE 'en_US.utf-8' LC_CTYPE 'en_US.utf-8';`)
 		})
 		It("does not print encoding information if it is the same as defaults", func() {
 			defaultDB := backup.Database{Oid: 0, Name: "", Tablespace: "", Encoding: "UTF8", Collate: "en_US.utf-8", CType: "en_US.utf-8"}
 			db := backup.Database{Oid: 1, Name: "testdb", Tablespace: "test_tablespace", Encoding: "UTF8", Collate: "en_US.utf-8", CType: "en_US.utf-8"}
 			emptyMetadataMap := backup.MetadataMap{}
-			backup.PrintCreateDatabaseStatement(backupfile, toc, defaultDB, db, emptyMetadataMap)
+			testBackup.PrintCreateDatabaseStatement(backupfile, toc, defaultDB, db, emptyMetadataMap)
 			testutils.AssertBufferContents(toc.GlobalEntries, buffer, `CREATE DATABASE testdb TEMPLATE template0 TABLESPACE test_tablespace;`)
 		})
 	})
@@ -91,6 +93,13 @@ GRANT TEMPORARY,CONNECT ON DATABASE testdb TO testrole;`,
 				`ALTER DATABASE testdb SET search_path TO pg_catalog, public;`,
 				`ALTER DATABASE testdb SET gp_default_storage_options TO 'appendonly=true,blocksize=32768';`)
 		})
+		It("prints database GUCs for a database name that requires quoting", func() {
+			gucs := []string{defaultOidGUC}
+
+			backup.PrintDatabaseGUCs(backupfile, toc, gucs, `"test-db"`)
+			testutils.ExpectEntry(toc.GlobalEntries, 0, "", "", `"test-db"`, "DATABASE GUC")
+			testutils.AssertBufferContents(toc.GlobalEntries, buffer, `ALTER DATABASE "test-db" SET default_with_oids TO 'true';`)
+		})
 	})
 	Describe("PrintCreateResourceQueueStatements", func() {
 		var emptyResQueueMetadata = backup.MetadataMap{}
@@ -99,7 +108,7 @@ GRANT TEMPORARY,CONNECT ON DATABASE testdb TO testrole;`,
 			maxCostQueue := backup.ResourceQueue{Oid: 1, Name: `"someMaxCostQueue"`, ActiveStatements: -1, MaxCost: "99.9", CostOvercommit: true, MinCost: "0.00", Priority: "medium", MemoryLimit: "-1"}
 			resQueues := []backup.ResourceQueue{someQueue, maxCostQueue}
 
-			backup.PrintCreateResourceQueueStatements(backupfile, toc, resQueues, emptyResQueueMetadata)
+			testBackup.PrintCreateResourceQueueStatements(backupfile, toc, resQueues, emptyResQueueMetadata)
 			testutils.ExpectEntry(toc.GlobalEntries, 0, "", "", "some_queue", "RESOURCE QUEUE")
 			testutils.AssertBufferContents(toc.GlobalEntries, buffer,
 				`CREATE RESOURCE QUEUE some_queue WITH (ACTIVE_STATEMENTS=1);`,
@@ -109,14 +118,14 @@ GRANT TEMPORARY,CONNECT ON DATABASE testdb TO testrole;`,
 			someActiveMaxCostQueue := backup.ResourceQueue{Oid: 1, Name: `"someActiveMaxCostQueue"`, ActiveStatements: 5, MaxCost: "62.03", CostOvercommit: false, MinCost: "0.00", Priority: "medium", MemoryLimit: "-1"}
 			resQueues := []backup.ResourceQueue{someActiveMaxCostQueue}
 
-			backup.PrintCreateResourceQueueStatements(backupfile, toc, resQueues, emptyResQueueMetadata)
+			testBackup.PrintCreateResourceQueueStatements(backupfile, toc, resQueues, emptyResQueueMetadata)
 			testutils.AssertBufferContents(toc.GlobalEntries, buffer, `CREATE RESOURCE QUEUE "someActiveMaxCostQueue" WITH (ACTIVE_STATEMENTS=5, MAX_COST=62.03);`)
 		})
 		It("prints a resource queue with all properties", func() {
 			everythingQueue := backup.ResourceQueue{Oid: 1, Name: `"everythingQueue"`, ActiveStatements: 7, MaxCost: "32.80", CostOvercommit: true, MinCost: "1.34", Priority: "low", MemoryLimit: "2GB"}
 			resQueues := []backup.ResourceQueue{everythingQueue}
 
-			backup.PrintCreateResourceQueueStatements(backupfile, toc, resQueues, emptyResQueueMetadata)
+			testBackup.PrintCreateResourceQueueStatements(backupfile, toc, resQueues, emptyResQueueMetadata)
 			testutils.AssertBufferContents(toc.GlobalEntries, buffer, `CREATE RESOURCE QUEUE "everythingQueue" WITH (ACTIVE_STATEMENTS=7, MAX_COST=32.80, COST_OVERCOMMIT=TRUE, MIN_COST=1.34, PRIORITY=LOW, MEMORY_LIMIT='2GB');`)
 		})
 		It("prints a resource queue with a comment", func() {
@@ -124,7 +133,7 @@ GRANT TEMPORARY,CONNECT ON DATABASE testdb TO testrole;`,
 			resQueues := []backup.ResourceQueue{commentQueue}
 			resQueueMetadata := testutils.DefaultMetadataMap("RESOURCE QUEUE", false, false, true, false)
 
-			backup.PrintCreateResourceQueueStatements(backupfile, toc, resQueues, resQueueMetadata)
+			testBackup.PrintCreateResourceQueueStatements(backupfile, toc, resQueues, resQueueMetadata)
 			testutils.AssertBufferContents(toc.GlobalEntries, buffer, `CREATE RESOURCE QUEUE "commentQueue" WITH (ACTIVE_STATEMENTS=1);`,
 				`COMMENT ON RESOURCE QUEUE "commentQueue" IS 'This is a resource queue comment.';`)
 		})
@@ -132,7 +141,7 @@ GRANT TEMPORARY,CONNECT ON DATABASE testdb TO testrole;`,
 			pgDefault := backup.ResourceQueue{Oid: 1, Name: "pg_default", ActiveStatements: 1, MaxCost: "-1.00", CostOvercommit: false, MinCost: "0.00", Priority: "medium", MemoryLimit: "-1"}
 			resQueues := []backup.ResourceQueue{pgDefault}
 
-			backup.PrintCreateResourceQueueStatements(backupfile, toc, resQueues, emptyResQueueMetadata)
+			testBackup.PrintCreateResourceQueueStatements(backupfile, toc, resQueues, emptyResQueueMetadata)
 			testutils.AssertBufferContents(toc.GlobalEntries, buffer, `ALTER RESOURCE QUEUE pg_default WITH (ACTIVE_STATEMENTS=1);`)
 		})
 	})
@@ -143,7 +152,7 @@ GRANT TEMPORARY,CONNECT ON DATABASE testdb TO testrole;`,
 			someGroup2 := backup.ResourceGroup{Oid: 2, Name: "some_group2", CPURateLimit: "20", MemoryLimit: "30", Concurrency: "25", MemorySharedQuota: "35", MemorySpillRatio: "10"}
 			resGroups := []backup.ResourceGroup{someGroup, someGroup2}
 
-			backup.PrintCreateResourceGroupStatements(backupfile, toc, resGroups, emptyResGroupMetadata)
+			testBackup.PrintCreateResourceGroupStatements(backupfile, toc, resGroups, emptyResGroupMetadata)
 			testutils.ExpectEntry(toc.GlobalEntries, 0, "", "", "some_group", "RESOURCE GROUP")
 			testutils.AssertBufferContents(toc.GlobalEntries, buffer,
 				`CREATE RESOURCE GROUP some_group WITH (CPU_RATE_LIMIT=10, MEMORY_AUDITOR=vmtracker, MEMORY_LIMIT=20, MEMORY_SHARED_QUOTA=25, MEMORY_SPILL_RATIO=30, CONCURRENCY=15);`,
@@ -153,7 +162,7 @@ GRANT TEMPORARY,CONNECT ON DATABASE testdb TO testrole;`,
 			defaultGroup := backup.ResourceGroup{Oid: 1, Name: "default_group", CPURateLimit: "10", MemoryLimit: "20", Concurrency: "15", MemorySharedQuota: "25", MemorySpillRatio: "30"}
 			resGroups := []backup.ResourceGroup{defaultGroup}
 
-			backup.PrintCreateResourceGroupStatements(backupfile, toc, resGroups, emptyResGroupMetadata)
+			testBackup.PrintCreateResourceGroupStatements(backupfile, toc, resGroups, emptyResGroupMetadata)
 			testutils.ExpectEntry(toc.GlobalEntries, 0, "", "", "default_group", "RESOURCE GROUP")
 			testutils.AssertBufferContents(toc.GlobalEntries, buffer,
 				`ALTER RESOURCE GROUP default_group SET MEMORY_LIMIT 20;`,
@@ -168,7 +177,7 @@ GRANT TEMPORARY,CONNECT ON DATABASE testdb TO testrole;`,
 			someGroup3 := backup.ResourceGroup{Oid: 3, Name: "some_group3", CPURateLimit: "10", MemoryLimit: "30", Concurrency: "25", MemorySharedQuota: "35", MemorySpillRatio: "10", MemoryAuditor: "0"}
 			resGroups := []backup.ResourceGroup{someGroup, someGroup2, someGroup3}
 
-			backup.PrintCreateResourceGroupStatements(backupfile, toc, resGroups, emptyResGroupMetadata)
+			testBackup.PrintCreateResourceGroupStatements(backupfile, toc, resGroups, emptyResGroupMetadata)
 			testutils.ExpectEntry(toc.GlobalEntries, 0, "", "", "some_group", "RESOURCE GROUP")
 			testutils.AssertBufferContents(toc.GlobalEntries, buffer,
 				`CREATE RESOURCE GROUP some_group WITH (CPU_RATE_LIMIT=10, MEMORY_AUDITOR=vmtracker, MEMORY_LIMIT=20, MEMORY_SHARED_QUOTA=25, MEMORY_SPILL_RATIO=30, CONCURRENCY=15);`,
@@ -180,7 +189,7 @@ GRANT TEMPORARY,CONNECT ON DATABASE testdb TO testrole;`,
 			someGroup2 := backup.ResourceGroup{Oid: 2, Name: "some_group2", CPURateLimit: "-1", Cpuset: "0-3", MemoryLimit: "30", Concurrency: "25", MemorySharedQuota: "35", MemorySpillRatio: "10"}
 			resGroups := []backup.ResourceGroup{someGroup, someGroup2}
 
-			backup.PrintCreateResourceGroupStatements(backupfile, toc, resGroups, emptyResGroupMetadata)
+			testBackup.PrintCreateResourceGroupStatements(backupfile, toc, resGroups, emptyResGroupMetadata)
 			testutils.ExpectEntry(toc.GlobalEntries, 0, "", "", "some_group", "RESOURCE GROUP")
 			testutils.AssertBufferContents(toc.GlobalEntries, buffer,
 				`CREATE RESOURCE GROUP some_group WITH (CPU_RATE_LIMIT=10, MEMORY_AUDITOR=vmtracker, MEMORY_LIMIT=20, MEMORY_SHARED_QUOTA=25, MEMORY_SPILL_RATIO=30, CONCURRENCY=15);`,
@@ -196,7 +205,7 @@ GRANT TEMPORARY,CONNECT ON DATABASE testdb TO testrole;`,
 			someGroup2 := backup.ResourceGroup{Oid: 4, Name: "some_group2", CPURateLimit: "20", MemoryLimit: "30", Concurrency: "25", MemorySharedQuota: "35", MemorySpillRatio: "40"}
 			resGroups := []backup.ResourceGroup{defaultGroup, adminGroup, someGroup, someGroup2}
 
-			backup.PrintCreateResourceGroupStatements(backupfile, toc, resGroups, emptyResGroupMetadata)
+			testBackup.PrintCreateResourceGroupStatements(backupfile, toc, resGroups, emptyResGroupMetadata)
 			testutils.ExpectEntry(toc.GlobalEntries, 0, "", "", "default_group", "RESOURCE GROUP")
 			testutils.AssertBufferContents(toc.GlobalEntries, buffer,
 				`ALTER RESOURCE GROUP default_group SET MEMORY_LIMIT 20;`,
@@ -212,6 +221,24 @@ GRANT TEMPORARY,CONNECT ON DATABASE testdb TO testrole;`,
 				`CREATE RESOURCE GROUP some_group WITH (CPU_RATE_LIMIT=20, MEMORY_AUDITOR=vmtracker, MEMORY_LIMIT=30, MEMORY_SHARED_QUOTA=35, MEMORY_SPILL_RATIO='40 MB', CONCURRENCY=25);`,
 				`CREATE RESOURCE GROUP some_group2 WITH (CPU_RATE_LIMIT=20, MEMORY_AUDITOR=vmtracker, MEMORY_LIMIT=30, MEMORY_SHARED_QUOTA=35, MEMORY_SPILL_RATIO=40, CONCURRENCY=25);`)
 		})
+		It("prints GPDB 7 resource groups", func() {
+			testhelper.SetDBVersion(connectionPool, "7.0.0")
+
+			defaultGroup := backup.ResourceGroup{Oid: 1, Name: "default_group", Concurrency: "20", CPURateLimit: "20", CPUWeight: "100", MemoryLimit: "-1", MinCost: "0"}
+			someGroup := backup.ResourceGroup{Oid: 2, Name: "some_group", Concurrency: "15", CPURateLimit: "10", CPUWeight: "200", MemoryLimit: "1024", MinCost: "500"}
+			someGroup2 := backup.ResourceGroup{Oid: 3, Name: "some_group2", Concurrency: "25", CPURateLimit: "-1", Cpuset: "0-3", CPUWeight: "100", MemoryLimit: "-1", MinCost: "0"}
+			resGroups := []backup.ResourceGroup{defaultGroup, someGroup, someGroup2}
+
+			testBackup.PrintCreateResourceGroupStatements(backupfile, toc, resGroups, emptyResGroupMetadata)
+			testutils.ExpectEntry(toc.GlobalEntries, 0, "", "", "default_group", "RESOURCE GROUP")
+			testutils.AssertBufferContents(toc.GlobalEntries, buffer,
+				`ALTER RESOURCE GROUP default_group SET CONCURRENCY 20;`,
+				`ALTER RESOURCE GROUP default_group SET CPU_MAX_PERCENT 20;`,
+				`ALTER RESOURCE GROUP default_group SET CPU_WEIGHT 100;`,
+				`ALTER RESOURCE GROUP default_group SET MIN_COST 0;`,
+				`CREATE RESOURCE GROUP some_group WITH (CONCURRENCY=15, CPU_MAX_PERCENT=10, CPU_WEIGHT=200, MEMORY_LIMIT=1024, MIN_COST=500);`,
+				`CREATE RESOURCE GROUP some_group2 WITH (CONCURRENCY=25, CPUSET='0-3', CPU_WEIGHT=100, MIN_COST=0);`)
+		})
 	})
 	Describe("PrintResetResourceGroupStatements", func() {
 		It("prints prepare resource groups", func() {
@@ -282,7 +309,7 @@ GRANT TEMPORARY,CONNECT ON DATABASE testdb TO testrole;`,
 		}
 		It("prints basic role", func() {
 			roleMetadataMap := testutils.DefaultMetadataMap("ROLE", false, false, true, false)
-			backup.PrintCreateRoleStatements(backupfile, toc, []backup.Role{testrole1}, roleMetadataMap)
+			testBackup.PrintCreateRoleStatements(backupfile, toc, []backup.Role{testrole1}, roleMetadataMap)
 
 			testutils.ExpectEntry(toc.GlobalEntries, 0, "", "", "testrole1", "ROLE")
 			testutils.AssertBufferContents(toc.GlobalEntries, buffer, `CREATE ROLE testrole1;
@@ -291,7 +318,7 @@ ALTER ROLE testrole1 WITH NOSUPERUSER NOINHERIT NOCREATEROLE NOCREATEDB NOLOGIN
 		})
 		It("prints roles with non-defaults and security label", func() {
 			roleMetadataMap := testutils.DefaultMetadataMap("ROLE", false, false, true, true)
-			backup.PrintCreateRoleStatements(backupfile, toc, []backup.Role{testrole2}, roleMetadataMap)
+			testBackup.PrintCreateRoleStatements(backupfile, toc, []backup.Role{testrole2}, roleMetadataMap)
 
 			expectedStatements := []string{
 				`CREATE ROLE "testRole2";
@@ -305,7 +332,7 @@ ALTER ROLE "testRole2" WITH SUPERUSER INHERIT CREATEROLE CREATEDB LOGIN REPLICAT
 		})
 		It("prints multiple roles", func() {
 			emptyMetadataMap := backup.MetadataMap{}
-			backup.PrintCreateRoleStatements(backupfile, toc, []backup.Role{testrole1, testrole2}, emptyMetadataMap)
+			testBackup.PrintCreateRoleStatements(backupfile, toc, []backup.Role{testrole1, testrole2}, emptyMetadataMap)
 
 			expectedStatements := []string{
 				`CREATE ROLE testrole1;
@@ -357,17 +384,29 @@ ALTER ROLE "testRole2" WITH SUPERUSER INHERIT CREATEROLE CREATEDB LOGIN REPLICAT
 				`ALTER ROLE testrole1 SET gp_default_storage_options TO 'appendonly=true, compresslevel=6, orientation=row, compresstype=none';`)
 		})
 	})
+	Describe("BackupTablespaces", func() {
+		It("leaves tablespaces out of the global section with --no-tablespaces", func() {
+			_ = cmdFlags.Set(utils.NO_TABLESPACES, "true")
+			testBackup.SetTOC(toc)
+
+			testBackup.BackupTablespaces(backupfile)
+
+			Expect(toc.GlobalEntries).To(BeEmpty())
+			Expect(buffer.Contents()).To(BeEmpty())
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+	})
 	Describe("PrintCreateTablespaceStatements", func() {
 		expectedTablespace := backup.Tablespace{Oid: 1, Tablespace: "test_tablespace", FileLocation: "test_filespace"}
 		It("prints a basic tablespace with a filespace", func() {
 			emptyMetadataMap := backup.MetadataMap{}
-			backup.PrintCreateTablespaceStatements(backupfile, toc, []backup.Tablespace{expectedTablespace}, emptyMetadataMap)
+			testBackup.PrintCreateTablespaceStatements(backupfile, toc, []backup.Tablespace{expectedTablespace}, emptyMetadataMap)
 			testutils.ExpectEntry(toc.GlobalEntries, 0, "", "", "test_tablespace", "TABLESPACE")
 			testutils.AssertBufferContents(toc.GlobalEntries, buffer, `CREATE TABLESPACE test_tablespace FILESPACE test_filespace;`)
 		})
 		It("prints a tablespace with privileges, an owner, security label, and a comment", func() {
 			tablespaceMetadataMap := testutils.DefaultMetadataMap("TABLESPACE", true, true, true, true)
-			backup.PrintCreateTablespaceStatements(backupfile, toc, []backup.Tablespace{expectedTablespace}, tablespaceMetadataMap)
+			testBackup.PrintCreateTablespaceStatements(backupfile, toc, []backup.Tablespace{expectedTablespace}, tablespaceMetadataMap)
 			expectedStatements := []string{
 				`CREATE TABLESPACE test_tablespace FILESPACE test_filespace;`,
 				`COMMENT ON TABLESPACE test_tablespace IS 'This is a tablespace comment.';`,
@@ -385,7 +424,7 @@ GRANT ALL ON TABLESPACE test_tablespace TO testrole;`,
 				SegmentLocations: []string{},
 			}
 			emptyMetadataMap := backup.MetadataMap{}
-			backup.PrintCreateTablespaceStatements(backupfile, toc, []backup.Tablespace{expectedTablespace}, emptyMetadataMap)
+			testBackup.PrintCreateTablespaceStatements(backupfile, toc, []backup.Tablespace{expectedTablespace}, emptyMetadataMap)
 			testutils.ExpectEntry(toc.GlobalEntries, 0, "", "", "test_tablespace", "TABLESPACE")
 			testutils.AssertBufferContents(toc.GlobalEntries, buffer, `CREATE TABLESPACE test_tablespace LOCATION '/data/dir';`)
 		})
@@ -395,7 +434,7 @@ GRANT ALL ON TABLESPACE test_tablespace TO testrole;`,
 				SegmentLocations: []string{"content1='/data/dir1'", "content2='/data/dir2'", "content3='/data/dir3'"},
 			}
 			emptyMetadataMap := backup.MetadataMap{}
-			backup.PrintCreateTablespaceStatements(backupfile, toc, []backup.Tablespace{expectedTablespace}, emptyMetadataMap)
+			testBackup.PrintCreateTablespaceStatements(backupfile, toc, []backup.Tablespace{expectedTablespace}, emptyMetadataMap)
 			testutils.ExpectEntry(toc.GlobalEntries, 0, "", "", "test_tablespace", "TABLESPACE")
 			testutils.AssertBufferContents(toc.GlobalEntries, buffer, `CREATE TABLESPACE test_tablespace LOCATION '/data/dir'
 	WITH (content1='/data/dir1', content2='/data/dir2', content3='/data/dir3');`)
@@ -407,7 +446,7 @@ GRANT ALL ON TABLESPACE test_tablespace TO testrole;`,
 				Options:          "param1=val1, param2=val2",
 			}
 			emptyMetadataMap := backup.MetadataMap{}
-			backup.PrintCreateTablespaceStatements(backupfile, toc, []backup.Tablespace{expectedTablespace}, emptyMetadataMap)
+			testBackup.PrintCreateTablespaceStatements(backupfile, toc, []backup.Tablespace{expectedTablespace}, emptyMetadataMap)
 			testutils.ExpectEntry(toc.GlobalEntries, 0, "", "", "test_tablespace", "TABLESPACE")
 			testutils.ExpectEntry(toc.GlobalEntries, 1, "", "", "test_tablespace", "TABLESPACE")
 			expectedStatements := []string{`CREATE TABLESPACE test_tablespace LOCATION '/data/dir';`,
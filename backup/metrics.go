@@ -0,0 +1,178 @@
+package backup
+
+/*
+ * This file contains the metrics that gpbackup publishes over HTTP in the
+ * Prometheus text format while a backup runs, when --metrics-address is
+ * given, so that monitoring systems can follow a long backup's progress
+ * without scraping its log file.
+ */
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+)
+
+/*
+ * Data sizes are the on-disk sizes of the tables, not the bytes written to
+ * the backup files, as the COPY commands on the segments do not report how
+ * much they have written until they finish.  They are counted when each
+ * table's data has been backed up.
+ */
+type BackupMetrics struct {
+	mutex                 sync.Mutex
+	database              string
+	timestamp             string
+	startTime             time.Time
+	phase                 string
+	lockStartTime         time.Time
+	lockWait              time.Duration
+	tablesTotal           int64
+	tablesCompleted       int64
+	bytesTotal            int64
+	bytesCompleted        int64
+	segmentBytesTotal     map[int]int64
+	segmentBytesCompleted map[int]int64
+	tableSegmentSizes     map[uint32]map[int]int64
+}
+
+func NewBackupMetrics(database string, timestamp string, startTime time.Time) *BackupMetrics {
+	return &BackupMetrics{
+		database:              database,
+		timestamp:             timestamp,
+		startTime:             startTime,
+		segmentBytesTotal:     make(map[int]int64),
+		segmentBytesCompleted: make(map[int]int64),
+		tableSegmentSizes:     make(map[uint32]map[int]int64),
+	}
+}
+
+/*
+ * The methods below do nothing on a nil BackupMetrics, so that they can be
+ * called whether or not metrics are being published.
+ */
+
+func (metrics *BackupMetrics) SetPhase(phase string) {
+	if metrics == nil {
+		return
+	}
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+	metrics.phase = phase
+}
+
+func (metrics *BackupMetrics) StartLockWait(startTime time.Time) {
+	if metrics == nil {
+		return
+	}
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+	metrics.lockStartTime = startTime
+}
+
+func (metrics *BackupMetrics) FinishLockWait(duration time.Duration) {
+	if metrics == nil {
+		return
+	}
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+	metrics.lockStartTime = time.Time{}
+	metrics.lockWait = duration
+}
+
+func (metrics *BackupMetrics) StartData(numTables int64, tableSegmentSizes map[uint32]map[int]int64) {
+	if metrics == nil {
+		return
+	}
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+	metrics.tablesTotal = numTables
+	metrics.tableSegmentSizes = tableSegmentSizes
+	for _, segmentSizes := range tableSegmentSizes {
+		for contentID, size := range segmentSizes {
+			metrics.segmentBytesTotal[contentID] += size
+			metrics.bytesTotal += size
+		}
+	}
+}
+
+func (metrics *BackupMetrics) CompleteTable(oid uint32) {
+	if metrics == nil {
+		return
+	}
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+	metrics.tablesCompleted++
+	for contentID, size := range metrics.tableSegmentSizes[oid] {
+		metrics.segmentBytesCompleted[contentID] += size
+		metrics.bytesCompleted += size
+	}
+}
+
+func (metrics *BackupMetrics) WriteMetrics(writer io.Writer, now time.Time) {
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+	lockWait := metrics.lockWait
+	if !metrics.lockStartTime.IsZero() {
+		lockWait = now.Sub(metrics.lockStartTime)
+	}
+	writeMetric(writer, "gpbackup_info", "gauge", "Information about the backup in progress",
+		fmt.Sprintf(`{database="%s",timestamp="%s",phase="%s"}`, labelEscaper.Replace(metrics.database), metrics.timestamp, metrics.phase), 1)
+	writeMetric(writer, "gpbackup_start_time_seconds", "gauge", "Time at which the backup started, in seconds since the epoch", "", float64(metrics.startTime.Unix()))
+	writeMetric(writer, "gpbackup_lock_wait_seconds", "gauge", "Time spent acquiring table locks", "", lockWait.Seconds())
+	writeMetric(writer, "gpbackup_tables", "gauge", "Number of tables whose data is being backed up", "", float64(metrics.tablesTotal))
+	writeMetric(writer, "gpbackup_tables_completed_total", "counter", "Number of tables whose data has been backed up", "", float64(metrics.tablesCompleted))
+	writeMetric(writer, "gpbackup_data_bytes", "gauge", "On-disk size of the tables whose data is being backed up", "", float64(metrics.bytesTotal))
+	writeMetric(writer, "gpbackup_data_bytes_completed_total", "counter", "On-disk size of the tables whose data has been backed up", "", float64(metrics.bytesCompleted))
+
+	contentIDs := make([]int, 0, len(metrics.segmentBytesTotal))
+	for contentID := range metrics.segmentBytesTotal {
+		contentIDs = append(contentIDs, contentID)
+	}
+	sort.Ints(contentIDs)
+	fmt.Fprintf(writer, "# HELP gpbackup_segment_data_bytes On-disk size on each segment of the tables whose data is being backed up\n# TYPE gpbackup_segment_data_bytes gauge\n")
+	for _, contentID := range contentIDs {
+		fmt.Fprintf(writer, "gpbackup_segment_data_bytes{content=\"%d\"} %d\n", contentID, metrics.segmentBytesTotal[contentID])
+	}
+	fmt.Fprintf(writer, "# HELP gpbackup_segment_data_bytes_completed_total On-disk size on each segment of the tables whose data has been backed up\n# TYPE gpbackup_segment_data_bytes_completed_total counter\n")
+	for _, contentID := range contentIDs {
+		fmt.Fprintf(writer, "gpbackup_segment_data_bytes_completed_total{content=\"%d\"} %d\n", contentID, metrics.segmentBytesCompleted[contentID])
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func writeMetric(writer io.Writer, name string, metricType string, help string, labels string, value float64) {
+	fmt.Fprintf(writer, "# HELP %s %s\n# TYPE %s %s\n%s%s %s\n", name, help, name, metricType, name, labels, strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+func (metrics *BackupMetrics) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	metrics.WriteMetrics(writer, operating.System.Now())
+}
+
+/*
+ * The server is started before the backup so that an address that is already
+ * in use is reported immediately, and it stops when the backup is cleaned up.
+ */
+func StartMetricsServer(address string, metrics *BackupMetrics) (*http.Server, net.Addr, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+	server := &http.Server{Handler: mux}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	return server, listener.Addr(), nil
+}
@@ -0,0 +1,74 @@
+package backup_test
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/greenplum-db/gpbackup/backup"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backup/metrics tests", func() {
+	startTime := time.Date(2019, time.October, 10, 12, 0, 0, 0, time.UTC)
+	Describe("WriteMetrics", func() {
+		It("writes the progress of the backup's data", func() {
+			metrics := backup.NewBackupMetrics("testdb", "20191010120000", startTime)
+			metrics.SetPhase("data")
+			metrics.FinishLockWait(90 * time.Second)
+			metrics.StartData(2, map[uint32]map[int]int64{
+				1: {0: 100, 1: 200},
+				2: {0: 1000, 1: 2000},
+			})
+			metrics.CompleteTable(2)
+			buffer := bytes.NewBuffer(nil)
+
+			metrics.WriteMetrics(buffer, startTime.Add(time.Hour))
+
+			output := buffer.String()
+			Expect(output).To(ContainSubstring(`gpbackup_info{database="testdb",timestamp="20191010120000",phase="data"} 1
+`))
+			Expect(output).To(ContainSubstring("gpbackup_start_time_seconds 1570708800\n"))
+			Expect(output).To(ContainSubstring("gpbackup_lock_wait_seconds 90\n"))
+			Expect(output).To(ContainSubstring("gpbackup_tables 2\n"))
+			Expect(output).To(ContainSubstring("gpbackup_tables_completed_total 1\n"))
+			Expect(output).To(ContainSubstring("gpbackup_data_bytes 3300\n"))
+			Expect(output).To(ContainSubstring("gpbackup_data_bytes_completed_total 3000\n"))
+			Expect(output).To(ContainSubstring(`gpbackup_segment_data_bytes{content="0"} 1100
+gpbackup_segment_data_bytes{content="1"} 2200
+`))
+			Expect(output).To(ContainSubstring(`gpbackup_segment_data_bytes_completed_total{content="0"} 1000
+gpbackup_segment_data_bytes_completed_total{content="1"} 2000
+`))
+		})
+		It("reports the time spent waiting for locks that are still being acquired", func() {
+			metrics := backup.NewBackupMetrics("testdb", "20191010120000", startTime)
+			metrics.StartLockWait(startTime.Add(time.Minute))
+			buffer := bytes.NewBuffer(nil)
+
+			metrics.WriteMetrics(buffer, startTime.Add(3*time.Minute))
+
+			Expect(buffer.String()).To(ContainSubstring("gpbackup_lock_wait_seconds 120\n"))
+		})
+		It("escapes the database name", func() {
+			metrics := backup.NewBackupMetrics(`test"db`, "20191010120000", startTime)
+			buffer := bytes.NewBuffer(nil)
+
+			metrics.WriteMetrics(buffer, startTime)
+
+			Expect(buffer.String()).To(ContainSubstring(`database="test\"db"`))
+		})
+	})
+	Describe("nil BackupMetrics", func() {
+		It("ignores progress when metrics are not being published", func() {
+			var metrics *backup.BackupMetrics
+
+			metrics.SetPhase("data")
+			metrics.StartLockWait(startTime)
+			metrics.FinishLockWait(time.Second)
+			metrics.StartData(1, map[uint32]map[int]int64{1: {0: 100}})
+			metrics.CompleteTable(1)
+		})
+	})
+})
@@ -0,0 +1,79 @@
+package backup
+
+/*
+ * This file contains functions for detecting schemas created in user
+ * databases by monitoring tools, whose objects are usually recreated by the
+ * tool itself and should not be restored along with user data.
+ */
+
+import (
+	"fmt"
+
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/iohelper"
+	"github.com/greenplum-db/gpbackup/utils"
+)
+
+var defaultMonitoringSchemas = []string{
+	"gpcc_schema", // Greenplum Command Center (older versions)
+	"gpmetrics",   // Greenplum Command Center
+	"pgagent",     // pgAgent job scheduler
+	"pganalyze",   // pganalyze collector
+	"pgwatch",     // pgwatch
+	"pgwatch2",    // pgwatch2
+}
+
+/*
+ * The built-in list can be extended with a file containing one schema name
+ * per line, so that sites can add schemas used by their own tooling.
+ */
+func (b *Backup) GetMonitoringSchemaNames() []string {
+	schemaNames := append([]string{}, defaultMonitoringSchemas...)
+	if b.MustGetFlagString(utils.MONITORING_SCHEMA_FILE) != "" {
+		schemaNames = append(schemaNames, iohelper.MustReadLinesFromFile(b.MustGetFlagString(utils.MONITORING_SCHEMA_FILE))...)
+	}
+	return schemaNames
+}
+
+func GetMonitoringSchemasInDatabase(connectionPool *dbconn.DBConn, schemaNames []string) []string {
+	query := fmt.Sprintf(`
+	SELECT nspname AS string
+	FROM pg_namespace
+	WHERE nspname IN (%s)
+	ORDER BY nspname`, utils.SliceToQuotedString(schemaNames))
+	return dbconn.MustSelectStringSlice(connectionPool, query)
+}
+
+/*
+ * Returns those monitoring schemas that the include and exclude schema filters
+ * would still back up.
+ */
+func GetMonitoringSchemasToBackUp(monitoringSchemas []string, includeSchemas []string, excludeSchemas []string) []string {
+	includeSet := utils.NewIncludeSet(includeSchemas)
+	excludeSet := utils.NewSet(excludeSchemas)
+	schemasToBackUp := make([]string, 0)
+	for _, schema := range monitoringSchemas {
+		if includeSet.MatchesFilter(schema) && !excludeSet.MatchesFilter(schema) {
+			schemasToBackUp = append(schemasToBackUp, schema)
+		}
+	}
+	return schemasToBackUp
+}
+
+func (b *Backup) ProcessMonitoringSchemas() {
+	if len(b.MustGetFlagStringArray(utils.INCLUDE_RELATION)) > 0 {
+		return
+	}
+	monitoringSchemas := GetMonitoringSchemasInDatabase(b.connectionPool, b.GetMonitoringSchemaNames())
+	schemasToBackUp := GetMonitoringSchemasToBackUp(monitoringSchemas, b.MustGetFlagStringSlice(utils.INCLUDE_SCHEMA), b.MustGetFlagStringSlice(utils.EXCLUDE_SCHEMA))
+	for _, schema := range schemasToBackUp {
+		if b.MustGetFlagBool(utils.EXCLUDE_MONITORING_SCHEMAS) {
+			gplog.Info("Excluding schema %s created by a monitoring tool", schema)
+			err := b.cmdFlags.Set(utils.EXCLUDE_SCHEMA, schema)
+			gplog.FatalOnError(err)
+		} else {
+			gplog.Warn("Schema %s appears to have been created by a monitoring tool and will be backed up. Use --%s to exclude it.", schema, utils.EXCLUDE_MONITORING_SCHEMAS)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package backup_test
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/greenplum-db/gpbackup/backup"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backup/monitoring tests", func() {
+	Describe("GetMonitoringSchemasInDatabase", func() {
+		It("returns the monitoring schemas present in the database", func() {
+			mock.ExpectQuery(`SELECT (.*) WHERE nspname IN \('gpmetrics','pgwatch2'\)`).WillReturnRows(sqlmock.NewRows([]string{"string"}).AddRow("gpmetrics"))
+
+			Expect(backup.GetMonitoringSchemasInDatabase(connectionPool, []string{"gpmetrics", "pgwatch2"})).To(Equal([]string{"gpmetrics"}))
+		})
+	})
+	Describe("GetMonitoringSchemasToBackUp", func() {
+		monitoringSchemas := []string{"gpmetrics", "pgwatch2"}
+		It("returns all monitoring schemas if no schema filters are set", func() {
+			Expect(backup.GetMonitoringSchemasToBackUp(monitoringSchemas, []string{}, []string{})).To(Equal([]string{"gpmetrics", "pgwatch2"}))
+		})
+		It("returns only included monitoring schemas", func() {
+			Expect(backup.GetMonitoringSchemasToBackUp(monitoringSchemas, []string{"public", "pgwatch2"}, []string{})).To(Equal([]string{"pgwatch2"}))
+		})
+		It("does not return excluded monitoring schemas", func() {
+			Expect(backup.GetMonitoringSchemasToBackUp(monitoringSchemas, []string{}, []string{"gpmetrics"})).To(Equal([]string{"pgwatch2"}))
+		})
+	})
+})
@@ -0,0 +1,191 @@
+package backup
+
+/*
+ * This file contains the "gpbackup plugin-test" command, which runs a storage
+ * plugin on this host through the calls gpbackup and gprestore make of it, so
+ * that a plugin can be checked before it is used for a real backup.
+ */
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpbackup/backup_history"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func NewPluginTestCommand() *cobra.Command {
+	pluginTestCmd := &cobra.Command{
+		Use:   "plugin-test",
+		Short: "Check that a storage plugin on this host works with gpbackup and gprestore",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			NewBackup(cmd.Flags(), nil, nil).DoPluginTest()
+		}}
+	SetPluginTestFlagDefaults(pluginTestCmd.Flags())
+	_ = pluginTestCmd.MarkFlagRequired(utils.PLUGIN_CONFIG)
+	return pluginTestCmd
+}
+
+func SetPluginTestFlagDefaults(flagSet *pflag.FlagSet) {
+	flagSet.Bool(utils.DEBUG, false, "Print verbose and debug log messages")
+	flagSet.String(utils.PLUGIN_CONFIG, "", "The configuration file of the plugin to be tested")
+	flagSet.Bool(utils.QUIET, false, "Suppress non-warning, non-error log messages")
+	flagSet.Bool(utils.VERBOSE, false, "Print verbose log messages")
+}
+
+func (b *Backup) DoPluginTest() {
+	b.SetLoggerVerbosity()
+	configFile, err := filepath.Abs(b.MustGetFlagString(utils.PLUGIN_CONFIG))
+	gplog.FatalOnError(err)
+	plugin, err := utils.ReadPluginConfig(configFile)
+	gplog.FatalOnError(err)
+	// The configuration is not copied to /tmp as it is for a backup, so the plugin reads it in place
+	plugin.ConfigPath = configFile
+
+	timestamp := backup_history.CurrentTimestamp()
+	testDir := filepath.Join(os.TempDir(), "gpbackup_plugin_test", "backups", timestamp[0:8], timestamp)
+	gplog.Info("Testing plugin %s with files in %s", plugin.ExecutablePath, testDir)
+	results := RunPluginTests(plugin, testDir)
+	_ = os.RemoveAll(filepath.Join(os.TempDir(), "gpbackup_plugin_test"))
+
+	numFailed := PrintDoctorReport(results)
+	if numFailed > 0 {
+		gplog.Error("Plugin %s did not pass all tests; tests after the first failure were not run", plugin.ExecutablePath)
+		os.Exit(1)
+	}
+	gplog.Info("All plugin tests passed")
+}
+
+/*
+ * Each test depends on those before it, so testing stops at the first failure.
+ * The tests use the same scope and arguments as the master's calls during a
+ * backup and restore, with the test directory standing in for the backup
+ * directory.
+ */
+func RunPluginTests(plugin *utils.PluginConfig, testDir string) []DoctorResult {
+	testFile := filepath.Join(testDir, "gpbackup_plugin_test_file.txt")
+	testData := filepath.Join(testDir, "gpbackup_plugin_test_data")
+	fileContents := "gpbackup plugin test file\n"
+	dataContents := strings.Repeat("gpbackup plugin test data\n", 1000)
+	// Hooks are passed the content ID in quotes, as gpbackup passes it
+	masterContentID := `"-1"`
+
+	tests := []struct {
+		name  string
+		check func() string
+	}{
+		{"API version", func() string {
+			return checkPluginAPIVersionOutput(mustRunPlugin(plugin, "", "plugin_api_version"))
+		}},
+		{"Native version", func() string {
+			return checkPluginNativeVersionOutput(mustRunPlugin(plugin, "", "--version"))
+		}},
+		{"Setup for backup", func() string {
+			gplog.FatalOnError(os.MkdirAll(testDir, 0700))
+			mustRunPlugin(plugin, "", "setup_plugin_for_backup", plugin.ConfigPath, testDir, string(utils.MASTER), masterContentID)
+			return "setup_plugin_for_backup succeeded"
+		}},
+		{"Backup file", func() string {
+			gplog.FatalOnError(ioutil.WriteFile(testFile, []byte(fileContents), 0600))
+			mustRunPlugin(plugin, "", "backup_file", plugin.ConfigPath, testFile)
+			if _, err := os.Stat(testFile); err != nil {
+				gplog.Fatal(errors.Errorf("Plugin removed the local copy of %s", testFile), "")
+			}
+			return fmt.Sprintf("Backed up %s", testFile)
+		}},
+		{"Setup for restore", func() string {
+			mustRunPlugin(plugin, "", "setup_plugin_for_restore", plugin.ConfigPath, testDir, string(utils.MASTER), masterContentID)
+			return "setup_plugin_for_restore succeeded"
+		}},
+		{"Restore file", func() string {
+			gplog.FatalOnError(os.Remove(testFile))
+			mustRunPlugin(plugin, "", "restore_file", plugin.ConfigPath, testFile)
+			contents, err := ioutil.ReadFile(testFile)
+			gplog.FatalOnError(err)
+			if string(contents) != fileContents {
+				gplog.Fatal(errors.Errorf("Restored file %s does not match the file backed up", testFile), "")
+			}
+			return fmt.Sprintf("Restored %s", testFile)
+		}},
+		{"Restore missing file", func() string {
+			missingFile := filepath.Join(testDir, "gpbackup_plugin_test_missing_file.txt")
+			if _, err := runPlugin(plugin, "", "restore_file", plugin.ConfigPath, missingFile); err == nil {
+				gplog.Fatal(errors.Errorf("Plugin did not fail when restoring %s, which was never backed up", missingFile), "")
+			}
+			return "Restoring a file that was never backed up failed as expected"
+		}},
+		{"Backup data", func() string {
+			mustRunPlugin(plugin, dataContents, "backup_data", plugin.ConfigPath, testData)
+			return fmt.Sprintf("Backed up %d bytes of data", len(dataContents))
+		}},
+		{"Restore data", func() string {
+			if mustRunPlugin(plugin, "", "restore_data", plugin.ConfigPath, testData) != dataContents {
+				gplog.Fatal(errors.Errorf("Restored data for %s does not match the data backed up", testData), "")
+			}
+			return fmt.Sprintf("Restored %d bytes of data", len(dataContents))
+		}},
+		{"Cleanup", func() string {
+			mustRunPlugin(plugin, "", "cleanup_plugin_for_backup", plugin.ConfigPath, testDir, string(utils.MASTER), masterContentID)
+			mustRunPlugin(plugin, "", "cleanup_plugin_for_restore", plugin.ConfigPath, testDir, string(utils.MASTER), masterContentID)
+			return "cleanup_plugin_for_backup and cleanup_plugin_for_restore succeeded"
+		}},
+	}
+
+	results := make([]DoctorResult, 0)
+	for _, test := range tests {
+		result := RunDoctorCheck(test.name, test.check)
+		results = append(results, result)
+		if !result.Passed {
+			break
+		}
+	}
+	return results
+}
+
+func runPlugin(plugin *utils.PluginConfig, input string, args ...string) (string, error) {
+	gplog.Verbose("Running %s %s", plugin.ExecutablePath, strings.Join(args, " "))
+	command := exec.Command(plugin.ExecutablePath, args...)
+	command.Stdin = strings.NewReader(input)
+	output, err := command.Output()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return string(output), errors.Errorf("%s %s failed: %s", plugin.ExecutablePath, args[0], strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return string(output), err
+}
+
+func mustRunPlugin(plugin *utils.PluginConfig, input string, args ...string) string {
+	output, err := runPlugin(plugin, input, args...)
+	gplog.FatalOnError(err)
+	return output
+}
+
+func checkPluginAPIVersionOutput(output string) string {
+	apiVersion := strings.TrimSpace(output)
+	version, err := semver.Make(apiVersion)
+	if err != nil {
+		gplog.Fatal(errors.Errorf("Unable to parse plugin API version %s: %v", apiVersion, err), "")
+	}
+	if !version.GE(semver.MustParse(utils.RequiredPluginVersion)) {
+		gplog.Fatal(errors.Errorf("Plugin API version %s is less than the minimum supported version %s", apiVersion, utils.RequiredPluginVersion), "")
+	}
+	return fmt.Sprintf("Plugin API version %s is supported", apiVersion)
+}
+
+func checkPluginNativeVersionOutput(output string) string {
+	nativeVersion := strings.TrimSpace(output)
+	parts := strings.Split(nativeVersion, " ")
+	if len(parts) < 3 || parts[1] != "version" {
+		gplog.Fatal(errors.Errorf("Plugin --version response '%s' is not in the format <plugin name> version <version>", nativeVersion), "")
+	}
+	return fmt.Sprintf("Plugin %s version %s", parts[0], parts[2])
+}
@@ -0,0 +1,58 @@
+package backup_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/greenplum-db/gpbackup/backup"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/spf13/cobra"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backup/plugin_testing tests", func() {
+	Describe("NewPluginTestCommand", func() {
+		It("requires --plugin-config", func() {
+			pluginTestCmd := backup.NewPluginTestCommand()
+			Expect(pluginTestCmd.Use).To(Equal("plugin-test"))
+			Expect(pluginTestCmd.Flags().Lookup(utils.PLUGIN_CONFIG).Annotations).To(HaveKey(cobra.BashCompOneRequiredFlag))
+		})
+	})
+	Describe("RunPluginTests", func() {
+		var tempDir string
+		BeforeEach(func() {
+			var err error
+			tempDir, err = ioutil.TempDir("", "plugin_testing")
+			Expect(err).ToNot(HaveOccurred())
+		})
+		AfterEach(func() {
+			_ = os.RemoveAll(tempDir)
+			_ = os.RemoveAll("/tmp/plugin_dest/20190102/20190102030405")
+		})
+		It("passes every test for a working plugin", func() {
+			executablePath, _ := filepath.Abs("../plugins/example_plugin.bash")
+			plugin := &utils.PluginConfig{ExecutablePath: executablePath, ConfigPath: "/tmp/example_plugin_config.yaml"}
+
+			results := backup.RunPluginTests(plugin, filepath.Join(tempDir, "backups", "20190102", "20190102030405"))
+
+			Expect(results).To(HaveLen(10))
+			for _, result := range results {
+				Expect(result.Passed).To(BeTrue(), result.Name+": "+result.Detail)
+			}
+		})
+		It("stops at the first test that fails", func() {
+			executablePath := filepath.Join(tempDir, "bad_plugin.bash")
+			Expect(ioutil.WriteFile(executablePath, []byte("#!/bin/bash\necho 0.1.0\n"), 0700)).To(Succeed())
+			plugin := &utils.PluginConfig{ExecutablePath: executablePath, ConfigPath: "/tmp/bad_plugin_config.yaml"}
+
+			results := backup.RunPluginTests(plugin, filepath.Join(tempDir, "backups", "20190102", "20190102030405"))
+
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].Passed).To(BeFalse())
+			Expect(results[0].Detail).To(ContainSubstring("Plugin API version 0.1.0 is less than the minimum supported version 0.3.0"))
+		})
+	})
+})
@@ -7,11 +7,14 @@ package backup
  */
 
 import (
+	"fmt"
+
 	"github.com/greenplum-db/gpbackup/utils"
 )
 
-func PrintCreateIndexStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, indexes []IndexDefinition, indexMetadata MetadataMap) {
+func (b *Backup) PrintCreateIndexStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, indexes []IndexDefinition, indexMetadata MetadataMap) {
 	for _, index := range indexes {
+		b.SetCurrentTOCObject(index)
 		start := metadataFile.ByteCount
 		if !index.SupportsConstraint {
 			section, entry := index.GetMetadataEntry()
@@ -21,52 +24,43 @@ func PrintCreateIndexStatements(metadataFile *utils.FileWithByteCount, toc *util
 
 			indexFQN := utils.MakeFQN(index.OwningSchema, index.Name)
 			if index.Tablespace != "" {
-				start := metadataFile.ByteCount
-				metadataFile.MustPrintf("\nALTER INDEX %s SET TABLESPACE %s;", indexFQN, index.Tablespace)
-				toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
+				metadataFile.MustPrintEntry(toc, section, entry, "\nALTER INDEX %s SET TABLESPACE %s;", indexFQN, index.Tablespace)
 			}
 			tableFQN := utils.MakeFQN(index.OwningSchema, index.OwningTable)
 			if index.IsClustered {
-				start := metadataFile.ByteCount
-				metadataFile.MustPrintf("\nALTER TABLE %s CLUSTER ON %s;", tableFQN, index.Name)
-				toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
+				metadataFile.MustPrintEntry(toc, section, entry, "\nALTER TABLE %s CLUSTER ON %s;", tableFQN, index.Name)
 			}
 			if index.IsReplicaIdentity {
-				start := metadataFile.ByteCount
-				metadataFile.MustPrintf("\nALTER TABLE %s REPLICA IDENTITY USING INDEX %s;", tableFQN, index.Name)
-				toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
+				metadataFile.MustPrintEntry(toc, section, entry, "\nALTER TABLE %s REPLICA IDENTITY USING INDEX %s;", tableFQN, index.Name)
 			}
 		}
-		PrintObjectMetadata(metadataFile, toc, indexMetadata[index.GetUniqueID()], index, "")
+		b.PrintObjectMetadata(metadataFile, toc, indexMetadata[index.GetUniqueID()], index, "")
 	}
 }
 
-func PrintCreateRuleStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, rules []RuleDefinition, ruleMetadata MetadataMap) {
+func (b *Backup) PrintCreateRuleStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, rules []RuleDefinition, ruleMetadata MetadataMap) {
 	for _, rule := range rules {
-		start := metadataFile.ByteCount
-		metadataFile.MustPrintf("\n\n%s", rule.Def)
-
+		b.SetCurrentTOCObject(rule)
 		section, entry := rule.GetMetadataEntry()
-		toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
+		metadataFile.MustPrintEntry(toc, section, entry, "\n\n%s", rule.Def)
 		tableFQN := utils.MakeFQN(rule.OwningSchema, rule.OwningTable)
-		PrintObjectMetadata(metadataFile, toc, ruleMetadata[rule.GetUniqueID()], rule, tableFQN)
+		b.PrintObjectMetadata(metadataFile, toc, ruleMetadata[rule.GetUniqueID()], rule, tableFQN)
 	}
 }
 
-func PrintCreateTriggerStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, triggers []TriggerDefinition, triggerMetadata MetadataMap) {
+func (b *Backup) PrintCreateTriggerStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, triggers []TriggerDefinition, triggerMetadata MetadataMap) {
 	for _, trigger := range triggers {
-		start := metadataFile.ByteCount
-		metadataFile.MustPrintf("\n\n%s;", trigger.Def)
-
+		b.SetCurrentTOCObject(trigger)
 		section, entry := trigger.GetMetadataEntry()
-		toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
+		metadataFile.MustPrintEntry(toc, section, entry, "\n\n%s;", trigger.Def)
 		tableFQN := utils.MakeFQN(trigger.OwningSchema, trigger.OwningTable)
-		PrintObjectMetadata(metadataFile, toc, triggerMetadata[trigger.GetUniqueID()], trigger, tableFQN)
+		b.PrintObjectMetadata(metadataFile, toc, triggerMetadata[trigger.GetUniqueID()], trigger, tableFQN)
 	}
 }
 
-func PrintCreateEventTriggerStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, eventTriggers []EventTrigger, eventTriggerMetadata MetadataMap) {
+func (b *Backup) PrintCreateEventTriggerStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, eventTriggers []EventTrigger, eventTriggerMetadata MetadataMap) {
 	for _, eventTrigger := range eventTriggers {
+		b.SetCurrentTOCObject(eventTrigger)
 		start := metadataFile.ByteCount
 		section, entry := eventTrigger.GetMetadataEntry()
 
@@ -89,10 +83,50 @@ func PrintCreateEventTriggerStatements(metadataFile *utils.FileWithByteCount, to
 			default:
 				enableOption = "ENABLE"
 			}
-			start := metadataFile.ByteCount
-			metadataFile.MustPrintf("\nALTER EVENT TRIGGER %s %s;", eventTrigger.Name, enableOption)
-			toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
+			metadataFile.MustPrintEntry(toc, section, entry, "\nALTER EVENT TRIGGER %s %s;", eventTrigger.Name, enableOption)
+		}
+		b.PrintObjectMetadata(metadataFile, toc, eventTriggerMetadata[eventTrigger.GetUniqueID()], eventTrigger, "")
+	}
+}
+
+func PrintRowLevelSecurityStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, tables []RowLevelSecurity) {
+	for _, table := range tables {
+		section, entry := table.GetMetadataEntry()
+		metadataFile.MustPrintEntry(toc, section, entry, "\n\nALTER TABLE %s ENABLE ROW LEVEL SECURITY;", table.FQN())
+		if table.Force {
+			metadataFile.MustPrintEntry(toc, section, entry, "\nALTER TABLE %s FORCE ROW LEVEL SECURITY;", table.FQN())
+		}
+	}
+}
+
+var policyCommands = map[string]string{"*": "ALL", "r": "SELECT", "a": "INSERT", "w": "UPDATE", "d": "DELETE"}
+
+func (b *Backup) PrintCreatePolicyStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, policies []RowLevelSecurityPolicy, policyMetadata MetadataMap) {
+	for _, policy := range policies {
+		b.SetCurrentTOCObject(policy)
+		tableFQN := utils.MakeFQN(policy.OwningSchema, policy.OwningTable)
+		statement := fmt.Sprintf("\n\nCREATE POLICY %s ON %s", policy.Name, tableFQN)
+		if !policy.Permissive {
+			statement += " AS RESTRICTIVE"
 		}
-		PrintObjectMetadata(metadataFile, toc, eventTriggerMetadata[eventTrigger.GetUniqueID()], eventTrigger, "")
+		statement += fmt.Sprintf(" FOR %s TO %s", policyCommands[policy.Command], policy.Roles)
+		if policy.Using != "" {
+			statement += fmt.Sprintf(" USING (%s)", policy.Using)
+		}
+		if policy.WithCheck != "" {
+			statement += fmt.Sprintf(" WITH CHECK (%s)", policy.WithCheck)
+		}
+		section, entry := policy.GetMetadataEntry()
+		metadataFile.MustPrintEntry(toc, section, entry, "%s;", statement)
+		b.PrintObjectMetadata(metadataFile, toc, policyMetadata[policy.GetUniqueID()], policy, tableFQN)
+	}
+}
+
+func (b *Backup) PrintCreateExtendedStatisticsStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, statistics []ExtendedStatistic, statisticsMetadata MetadataMap) {
+	for _, statistic := range statistics {
+		b.SetCurrentTOCObject(statistic)
+		section, entry := statistic.GetMetadataEntry()
+		metadataFile.MustPrintEntry(toc, section, entry, "\n\n%s;", statistic.Def)
+		b.PrintObjectMetadata(metadataFile, toc, statisticsMetadata[statistic.GetUniqueID()], statistic, "")
 	}
 }
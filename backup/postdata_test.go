@@ -19,14 +19,14 @@ var _ = Describe("backup/postdata tests", func() {
 		})
 		It("can print a basic index", func() {
 			indexes := []backup.IndexDefinition{index}
-			backup.PrintCreateIndexStatements(backupfile, toc, indexes, emptyMetadataMap)
+			testBackup.PrintCreateIndexStatements(backupfile, toc, indexes, emptyMetadataMap)
 			testutils.ExpectEntry(toc.PostdataEntries, 0, "public", "public.testtable", "testindex", "INDEX")
 			testutils.AssertBufferContents(toc.PostdataEntries, buffer, `CREATE INDEX testindex ON public.testtable USING btree(i);`)
 		})
 		It("can print an index used for clustering", func() {
 			index.IsClustered = true
 			indexes := []backup.IndexDefinition{index}
-			backup.PrintCreateIndexStatements(backupfile, toc, indexes, emptyMetadataMap)
+			testBackup.PrintCreateIndexStatements(backupfile, toc, indexes, emptyMetadataMap)
 			testutils.ExpectEntry(toc.PostdataEntries, 0, "public", "public.testtable", "testindex", "INDEX")
 			testutils.AssertBufferContents(toc.PostdataEntries, buffer, "CREATE INDEX testindex ON public.testtable USING btree(i);",
 				"ALTER TABLE public.testtable CLUSTER ON testindex;")
@@ -34,21 +34,21 @@ var _ = Describe("backup/postdata tests", func() {
 		It("can print an index with a tablespace", func() {
 			index.Tablespace = "test_tablespace"
 			indexes := []backup.IndexDefinition{index}
-			backup.PrintCreateIndexStatements(backupfile, toc, indexes, emptyMetadataMap)
+			testBackup.PrintCreateIndexStatements(backupfile, toc, indexes, emptyMetadataMap)
 			testutils.AssertBufferContents(toc.PostdataEntries, buffer, "CREATE INDEX testindex ON public.testtable USING btree(i);",
 				"ALTER INDEX public.testindex SET TABLESPACE test_tablespace;")
 		})
 		It("can print an index with a comment", func() {
 			indexes := []backup.IndexDefinition{index}
 			indexMetadataMap := testutils.DefaultMetadataMap("INDEX", false, false, true, false)
-			backup.PrintCreateIndexStatements(backupfile, toc, indexes, indexMetadataMap)
+			testBackup.PrintCreateIndexStatements(backupfile, toc, indexes, indexMetadataMap)
 			testutils.AssertBufferContents(toc.PostdataEntries, buffer, "CREATE INDEX testindex ON public.testtable USING btree(i);",
 				"COMMENT ON INDEX public.testindex IS 'This is an index comment.';")
 		})
 		It("can print an index that is a replica identity", func() {
 			index.IsReplicaIdentity = true
 			indexes := []backup.IndexDefinition{index}
-			backup.PrintCreateIndexStatements(backupfile, toc, indexes, emptyMetadataMap)
+			testBackup.PrintCreateIndexStatements(backupfile, toc, indexes, emptyMetadataMap)
 			testutils.ExpectEntry(toc.PostdataEntries, 0, "public", "public.testtable", "testindex", "INDEX")
 			testutils.AssertBufferContents(toc.PostdataEntries, buffer,
 				"CREATE INDEX testindex ON public.testtable USING btree(i);",
@@ -61,14 +61,14 @@ var _ = Describe("backup/postdata tests", func() {
 		It("can print a basic rule", func() {
 			rules := []backup.RuleDefinition{rule}
 			emptyMetadataMap := backup.MetadataMap{}
-			backup.PrintCreateRuleStatements(backupfile, toc, rules, emptyMetadataMap)
+			testBackup.PrintCreateRuleStatements(backupfile, toc, rules, emptyMetadataMap)
 			testutils.ExpectEntry(toc.PostdataEntries, 0, "public", "public.testtable", "testrule", "RULE")
 			testutils.AssertBufferContents(toc.PostdataEntries, buffer, "CREATE RULE update_notify AS ON UPDATE TO testtable DO NOTIFY testtable;")
 		})
 		It("can print a rule with a comment", func() {
 			rules := []backup.RuleDefinition{rule}
 			ruleMetadataMap := testutils.DefaultMetadataMap("RULE", false, false, true, false)
-			backup.PrintCreateRuleStatements(backupfile, toc, rules, ruleMetadataMap)
+			testBackup.PrintCreateRuleStatements(backupfile, toc, rules, ruleMetadataMap)
 			testutils.AssertBufferContents(toc.PostdataEntries, buffer, "CREATE RULE update_notify AS ON UPDATE TO testtable DO NOTIFY testtable;",
 				"COMMENT ON RULE testrule ON public.testtable IS 'This is a rule comment.';")
 		})
@@ -78,14 +78,14 @@ var _ = Describe("backup/postdata tests", func() {
 		It("can print a basic trigger", func() {
 			triggers := []backup.TriggerDefinition{trigger}
 			emptyMetadataMap := backup.MetadataMap{}
-			backup.PrintCreateTriggerStatements(backupfile, toc, triggers, emptyMetadataMap)
+			testBackup.PrintCreateTriggerStatements(backupfile, toc, triggers, emptyMetadataMap)
 			testutils.ExpectEntry(toc.PostdataEntries, 0, "public", "public.testtable", "testtrigger", "TRIGGER")
 			testutils.AssertBufferContents(toc.PostdataEntries, buffer, "CREATE TRIGGER sync_testtable AFTER INSERT OR DELETE OR UPDATE ON testtable FOR EACH STATEMENT EXECUTE PROCEDURE flatfile_update_trigger();")
 		})
 		It("can print a trigger with a comment", func() {
 			triggers := []backup.TriggerDefinition{trigger}
 			triggerMetadataMap := testutils.DefaultMetadataMap("TRIGGER", false, false, true, false)
-			backup.PrintCreateTriggerStatements(backupfile, toc, triggers, triggerMetadataMap)
+			testBackup.PrintCreateTriggerStatements(backupfile, toc, triggers, triggerMetadataMap)
 			testutils.AssertBufferContents(toc.PostdataEntries, buffer, "CREATE TRIGGER sync_testtable AFTER INSERT OR DELETE OR UPDATE ON testtable FOR EACH STATEMENT EXECUTE PROCEDURE flatfile_update_trigger();",
 				"COMMENT ON TRIGGER testtrigger ON public.testtable IS 'This is a trigger comment.';")
 		})
@@ -95,7 +95,7 @@ var _ = Describe("backup/postdata tests", func() {
 			eventTrigger := backup.EventTrigger{Oid: 1, Name: "testeventtrigger", Event: "ddl_command_start", FunctionName: "abort_any_command", Enabled: "O"}
 			eventTriggers := []backup.EventTrigger{eventTrigger}
 			emptyMetadataMap := backup.MetadataMap{}
-			backup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, emptyMetadataMap)
+			testBackup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, emptyMetadataMap)
 			testutils.ExpectEntry(toc.PostdataEntries, 0, "", "", "testeventtrigger", "EVENT TRIGGER")
 			testutils.AssertBufferContents(toc.PostdataEntries, buffer, `CREATE EVENT TRIGGER testeventtrigger
 ON ddl_command_start
@@ -105,7 +105,7 @@ EXECUTE PROCEDURE abort_any_command();`)
 			eventTrigger := backup.EventTrigger{Oid: 1, Name: "testeventtrigger", Event: "ddl_command_start", FunctionName: "abort_any_command", Enabled: "O"}
 			eventTriggers := []backup.EventTrigger{eventTrigger}
 			eventTriggerMetadataMap := testutils.DefaultMetadataMap("EVENT TRIGGER", false, false, true, false)
-			backup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, eventTriggerMetadataMap)
+			testBackup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, eventTriggerMetadataMap)
 			testutils.ExpectEntry(toc.PostdataEntries, 0, "", "", "testeventtrigger", "EVENT TRIGGER")
 			testutils.AssertBufferContents(toc.PostdataEntries, buffer, `CREATE EVENT TRIGGER testeventtrigger
 ON ddl_command_start
@@ -115,7 +115,7 @@ EXECUTE PROCEDURE abort_any_command();`, `COMMENT ON EVENT TRIGGER testeventtrig
 			eventTrigger := backup.EventTrigger{Oid: 1, Name: "testeventtrigger", Event: "ddl_command_start", FunctionName: "abort_any_command", Enabled: "O"}
 			eventTriggers := []backup.EventTrigger{eventTrigger}
 			eventTriggerMetadataMap := testutils.DefaultMetadataMap("EVENT TRIGGER", false, true, false, false)
-			backup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, eventTriggerMetadataMap)
+			testBackup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, eventTriggerMetadataMap)
 			testutils.ExpectEntry(toc.PostdataEntries, 0, "", "", "testeventtrigger", "EVENT TRIGGER")
 			testutils.AssertBufferContents(toc.PostdataEntries, buffer, `CREATE EVENT TRIGGER testeventtrigger
 ON ddl_command_start
@@ -125,7 +125,7 @@ EXECUTE PROCEDURE abort_any_command();`, `ALTER EVENT TRIGGER testeventtrigger O
 			eventTrigger := backup.EventTrigger{Oid: 1, Name: "testeventtrigger", Event: "ddl_command_start", FunctionName: "abort_any_command", Enabled: "O"}
 			eventTriggers := []backup.EventTrigger{eventTrigger}
 			eventTriggerMetadataMap := testutils.DefaultMetadataMap("EVENT TRIGGER", false, false, false, true)
-			backup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, eventTriggerMetadataMap)
+			testBackup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, eventTriggerMetadataMap)
 			testutils.ExpectEntry(toc.PostdataEntries, 0, "", "", "testeventtrigger", "EVENT TRIGGER")
 			testutils.AssertBufferContents(toc.PostdataEntries, buffer, `CREATE EVENT TRIGGER testeventtrigger
 ON ddl_command_start
@@ -135,7 +135,7 @@ EXECUTE PROCEDURE abort_any_command();`, `SECURITY LABEL FOR dummy ON EVENT TRIG
 			eventTrigger := backup.EventTrigger{Oid: 1, Name: "testeventtrigger", Event: "ddl_command_start", FunctionName: "abort_any_command", Enabled: "O", EventTags: `'DROP FUNCTION','DROP TABLE'`}
 			eventTriggers := []backup.EventTrigger{eventTrigger}
 			emptyMetadataMap := backup.MetadataMap{}
-			backup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, emptyMetadataMap)
+			testBackup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, emptyMetadataMap)
 			testutils.ExpectEntry(toc.PostdataEntries, 0, "", "", "testeventtrigger", "EVENT TRIGGER")
 			testutils.AssertBufferContents(toc.PostdataEntries, buffer, `CREATE EVENT TRIGGER testeventtrigger
 ON ddl_command_start
@@ -146,7 +146,7 @@ EXECUTE PROCEDURE abort_any_command();`)
 			eventTrigger := backup.EventTrigger{Oid: 1, Name: "testeventtrigger", Event: "ddl_command_start", FunctionName: "abort_any_command", Enabled: "D"}
 			eventTriggers := []backup.EventTrigger{eventTrigger}
 			emptyMetadataMap := backup.MetadataMap{}
-			backup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, emptyMetadataMap)
+			testBackup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, emptyMetadataMap)
 			testutils.ExpectEntry(toc.PostdataEntries, 0, "", "", "testeventtrigger", "EVENT TRIGGER")
 			testutils.AssertBufferContents(toc.PostdataEntries, buffer, `CREATE EVENT TRIGGER testeventtrigger
 ON ddl_command_start
@@ -156,7 +156,7 @@ EXECUTE PROCEDURE abort_any_command();`, `ALTER EVENT TRIGGER testeventtrigger D
 			eventTrigger := backup.EventTrigger{Oid: 1, Name: "testeventtrigger", Event: "ddl_command_start", FunctionName: "abort_any_command", Enabled: ""}
 			eventTriggers := []backup.EventTrigger{eventTrigger}
 			emptyMetadataMap := backup.MetadataMap{}
-			backup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, emptyMetadataMap)
+			testBackup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, emptyMetadataMap)
 			testutils.ExpectEntry(toc.PostdataEntries, 0, "", "", "testeventtrigger", "EVENT TRIGGER")
 			testutils.AssertBufferContents(toc.PostdataEntries, buffer, `CREATE EVENT TRIGGER testeventtrigger
 ON ddl_command_start
@@ -166,7 +166,7 @@ EXECUTE PROCEDURE abort_any_command();`, `ALTER EVENT TRIGGER testeventtrigger E
 			eventTrigger := backup.EventTrigger{Oid: 1, Name: "testeventtrigger", Event: "ddl_command_start", FunctionName: "abort_any_command", Enabled: "R"}
 			eventTriggers := []backup.EventTrigger{eventTrigger}
 			emptyMetadataMap := backup.MetadataMap{}
-			backup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, emptyMetadataMap)
+			testBackup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, emptyMetadataMap)
 			testutils.ExpectEntry(toc.PostdataEntries, 0, "", "", "testeventtrigger", "EVENT TRIGGER")
 			testutils.AssertBufferContents(toc.PostdataEntries, buffer, `CREATE EVENT TRIGGER testeventtrigger
 ON ddl_command_start
@@ -176,11 +176,58 @@ EXECUTE PROCEDURE abort_any_command();`, `ALTER EVENT TRIGGER testeventtrigger E
 			eventTrigger := backup.EventTrigger{Oid: 1, Name: "testeventtrigger", Event: "ddl_command_start", FunctionName: "abort_any_command", Enabled: "A"}
 			eventTriggers := []backup.EventTrigger{eventTrigger}
 			emptyMetadataMap := backup.MetadataMap{}
-			backup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, emptyMetadataMap)
+			testBackup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, emptyMetadataMap)
 			testutils.ExpectEntry(toc.PostdataEntries, 0, "", "", "testeventtrigger", "EVENT TRIGGER")
 			testutils.AssertBufferContents(toc.PostdataEntries, buffer, `CREATE EVENT TRIGGER testeventtrigger
 ON ddl_command_start
 EXECUTE PROCEDURE abort_any_command();`, `ALTER EVENT TRIGGER testeventtrigger ENABLE ALWAYS;`)
 		})
 	})
+	Context("PrintRowLevelSecurityStatements", func() {
+		It("can print a table with row level security enabled", func() {
+			tables := []backup.RowLevelSecurity{{Oid: 1, Schema: "public", Name: "testtable"}}
+			backup.PrintRowLevelSecurityStatements(backupfile, toc, tables)
+			testutils.ExpectEntry(toc.PostdataEntries, 0, "public", "public.testtable", "testtable", "ROW LEVEL SECURITY")
+			testutils.AssertBufferContents(toc.PostdataEntries, buffer, "ALTER TABLE public.testtable ENABLE ROW LEVEL SECURITY;")
+		})
+		It("can print a table with row level security forced", func() {
+			tables := []backup.RowLevelSecurity{{Oid: 1, Schema: "public", Name: "testtable", Force: true}}
+			backup.PrintRowLevelSecurityStatements(backupfile, toc, tables)
+			testutils.AssertBufferContents(toc.PostdataEntries, buffer, "ALTER TABLE public.testtable ENABLE ROW LEVEL SECURITY;", "ALTER TABLE public.testtable FORCE ROW LEVEL SECURITY;")
+		})
+	})
+	Context("PrintCreatePolicyStatements", func() {
+		policy := backup.RowLevelSecurityPolicy{Oid: 1, Name: "testpolicy", OwningSchema: "public", OwningTable: "testtable", Command: "*", Permissive: true, Roles: "PUBLIC", Using: "(owner = CURRENT_USER)"}
+		It("can print a basic policy", func() {
+			testBackup.PrintCreatePolicyStatements(backupfile, toc, []backup.RowLevelSecurityPolicy{policy}, emptyMetadataMap)
+			testutils.ExpectEntry(toc.PostdataEntries, 0, "public", "public.testtable", "testpolicy", "POLICY")
+			testutils.AssertBufferContents(toc.PostdataEntries, buffer, "CREATE POLICY testpolicy ON public.testtable FOR ALL TO PUBLIC USING ((owner = CURRENT_USER));")
+		})
+		It("can print a restrictive policy for a command with a check expression", func() {
+			restrictivePolicy := backup.RowLevelSecurityPolicy{Oid: 1, Name: "testpolicy", OwningSchema: "public", OwningTable: "testtable", Command: "a", Permissive: false, Roles: "role1, role2", WithCheck: "(i > 0)"}
+			testBackup.PrintCreatePolicyStatements(backupfile, toc, []backup.RowLevelSecurityPolicy{restrictivePolicy}, emptyMetadataMap)
+			testutils.AssertBufferContents(toc.PostdataEntries, buffer, "CREATE POLICY testpolicy ON public.testtable AS RESTRICTIVE FOR INSERT TO role1, role2 WITH CHECK ((i > 0));")
+		})
+		It("can print a policy with a comment", func() {
+			policyMetadataMap := testutils.DefaultMetadataMap("POLICY", false, false, true, false)
+			testBackup.PrintCreatePolicyStatements(backupfile, toc, []backup.RowLevelSecurityPolicy{policy}, policyMetadataMap)
+			testutils.AssertBufferContents(toc.PostdataEntries, buffer, "CREATE POLICY testpolicy ON public.testtable FOR ALL TO PUBLIC USING ((owner = CURRENT_USER));",
+				"COMMENT ON POLICY testpolicy ON public.testtable IS 'This is a policy comment.';")
+		})
+	})
+	Context("PrintCreateExtendedStatisticsStatements", func() {
+		statistic := backup.ExtendedStatistic{Oid: 1, Schema: "public", Name: "teststats", OwningSchema: "public", OwningTable: "testtable", Def: "CREATE STATISTICS public.teststats (ndistinct, dependencies) ON i, j FROM public.testtable"}
+		It("can print a basic extended statistics object", func() {
+			testBackup.PrintCreateExtendedStatisticsStatements(backupfile, toc, []backup.ExtendedStatistic{statistic}, emptyMetadataMap)
+			testutils.ExpectEntry(toc.PostdataEntries, 0, "public", "public.testtable", "teststats", "STATISTICS")
+			testutils.AssertBufferContents(toc.PostdataEntries, buffer, "CREATE STATISTICS public.teststats (ndistinct, dependencies) ON i, j FROM public.testtable;")
+		})
+		It("can print an extended statistics object with an owner and a comment", func() {
+			statisticsMetadataMap := testutils.DefaultMetadataMap("STATISTICS", false, true, true, false)
+			testBackup.PrintCreateExtendedStatisticsStatements(backupfile, toc, []backup.ExtendedStatistic{statistic}, statisticsMetadataMap)
+			testutils.AssertBufferContents(toc.PostdataEntries, buffer, "CREATE STATISTICS public.teststats (ndistinct, dependencies) ON i, j FROM public.testtable;",
+				"COMMENT ON STATISTICS public.teststats IS 'This is a statistics comment.';",
+				"ALTER STATISTICS public.teststats OWNER TO testrole;")
+		})
+	})
 })
@@ -0,0 +1,109 @@
+package backup
+
+/*
+ * This file contains the --target postgres mode, in which the metadata is
+ * written without the clauses and objects that only Greenplum understands,
+ * so that it can be run on community PostgreSQL.
+ */
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpbackup/utils"
+)
+
+const (
+	TARGET_GREENPLUM = "greenplum"
+	TARGET_POSTGRES  = "postgres"
+)
+
+var (
+	postgresCreateTablePattern    = regexp.MustCompile(`^\s*CREATE (?:UNLOGGED )?(?:TABLE|MATERIALIZED VIEW) `)
+	postgresExternalTablePattern  = regexp.MustCompile(`^\s*CREATE (?:READABLE |WRITABLE )?EXTERNAL (?:WEB )?TABLE `)
+	postgresDistributedPattern    = regexp.MustCompile(`\s*DISTRIBUTED (?:BY \([^)]*\)|RANDOMLY|REPLICATED)`)
+	postgresColumnEncodingRegex   = regexp.MustCompile(`(?m)^(\t.*) ENCODING \([^)]*\)(,?)$`)
+	postgresStorageOptionsRegex   = regexp.MustCompile(`(?m)^(\) .*?)WITH \(([^)]*)\) ?`)
+	postgresRoleAttributePattern  = regexp.MustCompile(` (?:RESOURCE QUEUE \S+|RESOURCE GROUP \S+|(?:NO)?CREATEEXTTABLE \([^)]*\))`)
+	postgresEmptyAlterRolePattern = regexp.MustCompile(`\nALTER ROLE \S+ WITH;`)
+	postgresBitmapIndexPattern    = regexp.MustCompile(` USING bitmap `)
+
+	// Append-optimized storage options, which PostgreSQL rejects
+	postgresAOStorageOptions = []string{"appendonly", "appendoptimized", "blocksize", "checksum", "compresslevel", "compresstype", "orientation"}
+
+	// Objects that exist only in Greenplum, and are not written at all
+	postgresSkippedObjectTypes = []string{"EXCHANGE PARTITION", "PROTOCOL", "RESOURCE GROUP", "RESOURCE QUEUE"}
+)
+
+/*
+ * Returns statement middleware that converts statements for PostgreSQL.
+ * External tables cannot be converted, so they are left out along with the
+ * statements that follow them for the same table, such as their owners and
+ * comments, which middleware receives in the order they were written.
+ * Greenplum partition definitions are left unchanged.
+ */
+func NewPostgresTargetMiddleware() utils.StatementMiddleware {
+	externalTables := make(map[string]bool)
+	return func(entry utils.MetadataEntry, statement string) string {
+		if utils.Exists(postgresSkippedObjectTypes, entry.ObjectType) {
+			return ""
+		}
+		switch entry.ObjectType {
+		case "TABLE":
+			fqn := utils.MakeFQN(entry.Schema, entry.Name)
+			if postgresExternalTablePattern.MatchString(statement) {
+				gplog.Warn("External table %s cannot be converted for PostgreSQL and will not be written to the metadata file", fqn)
+				externalTables[fqn] = true
+				return ""
+			} else if externalTables[fqn] {
+				return ""
+			}
+			return ConvertTableForPostgres(statement)
+		case "MATERIALIZED VIEW":
+			return ConvertTableForPostgres(statement)
+		case "ROLE":
+			return ConvertRoleForPostgres(statement)
+		case "INDEX":
+			return postgresBitmapIndexPattern.ReplaceAllString(statement, " USING btree ")
+		}
+		return statement
+	}
+}
+
+/*
+ * Removes the distribution policy, column encodings, and append-optimized
+ * storage options from a CREATE TABLE statement, which makes append-optimized
+ * tables into heap tables.
+ */
+func ConvertTableForPostgres(statement string) string {
+	if !postgresCreateTablePattern.MatchString(statement) {
+		return statement
+	}
+	statement = postgresColumnEncodingRegex.ReplaceAllString(statement, "$1$2")
+	statement = postgresStorageOptionsRegex.ReplaceAllStringFunc(statement, func(clause string) string {
+		matches := postgresStorageOptionsRegex.FindStringSubmatch(clause)
+		options := make([]string, 0)
+		for _, option := range strings.Split(matches[2], ",") {
+			option = strings.TrimSpace(option)
+			name := strings.ToLower(strings.TrimSpace(strings.SplitN(option, "=", 2)[0]))
+			if !utils.Exists(postgresAOStorageOptions, name) {
+				options = append(options, option)
+			}
+		}
+		if len(options) == 0 {
+			return matches[1]
+		}
+		return matches[1] + "WITH (" + strings.Join(options, ", ") + ") "
+	})
+	return postgresDistributedPattern.ReplaceAllString(statement, "")
+}
+
+// Removes the resource management and external table attributes of a role
+func ConvertRoleForPostgres(statement string) string {
+	if trimmed := strings.TrimSpace(statement); strings.HasPrefix(trimmed, "ALTER ROLE ") && strings.Contains(trimmed, " DENY BETWEEN ") {
+		return ""
+	}
+	statement = postgresRoleAttributePattern.ReplaceAllString(statement, "")
+	return postgresEmptyAlterRolePattern.ReplaceAllString(statement, "")
+}
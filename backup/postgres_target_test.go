@@ -0,0 +1,60 @@
+package backup_test
+
+import (
+	"github.com/greenplum-db/gpbackup/backup"
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backup/postgres_target tests", func() {
+	Describe("ConvertTableForPostgres", func() {
+		It("removes the distribution policy", func() {
+			Expect(backup.ConvertTableForPostgres("\n\nCREATE TABLE public.foo (\n\ti integer\n) DISTRIBUTED BY (i);\n")).To(Equal("\n\nCREATE TABLE public.foo (\n\ti integer\n);\n"))
+			Expect(backup.ConvertTableForPostgres("\n\nCREATE TABLE public.foo (\n\ti integer\n) TABLESPACE ts DISTRIBUTED RANDOMLY;\n")).To(Equal("\n\nCREATE TABLE public.foo (\n\ti integer\n) TABLESPACE ts;\n"))
+			Expect(backup.ConvertTableForPostgres("\n\nCREATE TABLE public.foo (\n\ti integer\n) DISTRIBUTED REPLICATED;\n")).To(Equal("\n\nCREATE TABLE public.foo (\n\ti integer\n);\n"))
+		})
+		It("removes column encodings and append-optimized storage options", func() {
+			statement := "\n\nCREATE TABLE public.foo (\n\ti integer ENCODING (compresstype=zlib,blocksize=32768),\n\tj text ENCODING (compresstype=none)\n) WITH (appendonly=true, orientation=column, fillfactor=70) DISTRIBUTED BY (i);\n"
+			Expect(backup.ConvertTableForPostgres(statement)).To(Equal("\n\nCREATE TABLE public.foo (\n\ti integer,\n\tj text\n) WITH (fillfactor=70);\n"))
+
+			statement = "\n\nCREATE TABLE public.foo (\n\ti integer\n) WITH (appendonly=true, compresstype=zlib) TABLESPACE ts DISTRIBUTED BY (i);\n"
+			Expect(backup.ConvertTableForPostgres(statement)).To(Equal("\n\nCREATE TABLE public.foo (\n\ti integer\n) TABLESPACE ts;\n"))
+		})
+		It("does not change other statements", func() {
+			statement := "\n\nCOMMENT ON TABLE public.foo IS 'DISTRIBUTED BY (i)';"
+			Expect(backup.ConvertTableForPostgres(statement)).To(Equal(statement))
+		})
+	})
+	Describe("ConvertRoleForPostgres", func() {
+		It("removes resource management and external table attributes", func() {
+			statement := "\n\nCREATE ROLE testrole;\nALTER ROLE testrole WITH NOSUPERUSER INHERIT LOGIN RESOURCE QUEUE pg_default RESOURCE GROUP default_group CREATEEXTTABLE (protocol='gpfdist', type='readable');"
+			Expect(backup.ConvertRoleForPostgres(statement)).To(Equal("\n\nCREATE ROLE testrole;\nALTER ROLE testrole WITH NOSUPERUSER INHERIT LOGIN;"))
+		})
+		It("removes time constraints", func() {
+			Expect(backup.ConvertRoleForPostgres("\nALTER ROLE testrole DENY BETWEEN DAY 0 TIME '00:00:00' AND DAY 1 TIME '00:00:00';")).To(Equal(""))
+		})
+	})
+	Describe("NewPostgresTargetMiddleware", func() {
+		It("leaves out Greenplum-only objects", func() {
+			middleware := backup.NewPostgresTargetMiddleware()
+			Expect(middleware(utils.MetadataEntry{Name: "q", ObjectType: "RESOURCE QUEUE"}, "\n\nCREATE RESOURCE QUEUE q WITH (ACTIVE_STATEMENTS=5);")).To(Equal(""))
+			Expect(middleware(utils.MetadataEntry{Name: "p", ObjectType: "PROTOCOL"}, "\n\nCREATE TRUSTED PROTOCOL p (readfunc = public.f);")).To(Equal(""))
+		})
+		It("leaves out external tables and the statements that follow them", func() {
+			middleware := backup.NewPostgresTargetMiddleware()
+			entry := utils.MetadataEntry{Schema: "public", Name: "ext", ObjectType: "TABLE"}
+			Expect(middleware(entry, "\n\nCREATE READABLE EXTERNAL TABLE public.ext (\n\ti integer\n) LOCATION (\n\t'gpfdist://host:8080/file'\n) FORMAT 'text';")).To(Equal(""))
+			Expect(middleware(entry, "\n\nALTER TABLE public.ext OWNER TO testrole;")).To(Equal(""))
+
+			other := utils.MetadataEntry{Schema: "public", Name: "foo", ObjectType: "TABLE"}
+			Expect(middleware(other, "\n\nALTER TABLE public.foo OWNER TO testrole;")).To(Equal("\n\nALTER TABLE public.foo OWNER TO testrole;"))
+		})
+		It("replaces bitmap indexes with btree indexes", func() {
+			middleware := backup.NewPostgresTargetMiddleware()
+			statement := "\n\nCREATE INDEX foo_idx ON public.foo USING bitmap (i);"
+			Expect(middleware(utils.MetadataEntry{ObjectType: "INDEX"}, statement)).To(Equal("\n\nCREATE INDEX foo_idx ON public.foo USING btree (i);"))
+		})
+	})
+})
@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
 	"github.com/greenplum-db/gpbackup/utils"
 )
 
@@ -55,14 +56,12 @@ type MetadataMap map[UniqueID]ObjectMetadata
 
 func PrintStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, obj utils.TOCObject, statements []string) {
 	for _, statement := range statements {
-		start := metadataFile.ByteCount
-		metadataFile.MustPrintf("\n\n%s\n", statement)
 		section, entry := obj.GetMetadataEntry()
-		toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
+		metadataFile.MustPrintEntry(toc, section, entry, "\n\n%s\n", statement)
 	}
 }
 
-func PrintObjectMetadata(file *utils.FileWithByteCount, toc *utils.TOC, metadata ObjectMetadata, obj utils.TOCObjectWithMetadata, owningTable string) {
+func (b *Backup) PrintObjectMetadata(file *utils.FileWithByteCount, toc *utils.TOC, metadata ObjectMetadata, obj utils.TOCObjectWithMetadata, owningTable string) {
 	_, entry := obj.GetMetadataEntry()
 	if entry.ObjectType == "DATABASE METADATA" {
 		entry.ObjectType = "DATABASE"
@@ -71,13 +70,13 @@ func PrintObjectMetadata(file *utils.FileWithByteCount, toc *utils.TOC, metadata
 	if comment := metadata.GetCommentStatement(obj.FQN(), entry.ObjectType, owningTable); comment != "" {
 		statements = append(statements, strings.TrimSpace(comment))
 	}
-	if owner := metadata.GetOwnerStatement(obj.FQN(), entry.ObjectType); owner != "" {
-		if !(connectionPool.Version.Before("5") && entry.ObjectType == "LANGUAGE") {
+	if owner := metadata.GetOwnerStatement(b.connectionPool, obj.FQN(), entry.ObjectType); owner != "" && !b.MustGetFlagBool(utils.NO_OWNER) {
+		if !(b.connectionPool.Version.Before("5") && entry.ObjectType == "LANGUAGE") {
 			// Languages have implicit owners in 4.3, but do not support ALTER OWNER
 			statements = append(statements, strings.TrimSpace(owner))
 		}
 	}
-	if privileges := metadata.GetPrivilegesStatements(obj.FQN(), entry.ObjectType); privileges != "" {
+	if privileges := metadata.GetPrivilegesStatements(obj.FQN(), entry.ObjectType); privileges != "" && !b.MustGetFlagBool(utils.NO_PRIVILEGES) {
 		statements = append(statements, strings.TrimSpace(privileges))
 	}
 	if securityLabel := metadata.GetSecurityLabelStatement(obj.FQN(), entry.ObjectType); securityLabel != "" {
@@ -86,11 +85,19 @@ func PrintObjectMetadata(file *utils.FileWithByteCount, toc *utils.TOC, metadata
 	PrintStatements(file, toc, obj, statements)
 }
 
-func ConstructMetadataMap(results []MetadataQueryStruct) MetadataMap {
-	metadataMap := make(MetadataMap)
+func (b *Backup) ConstructMetadataMap(results []MetadataQueryStruct) MetadataMap {
 	if len(results) == 0 {
 		return MetadataMap{}
 	}
+	// Results are ordered by object, so the number of objects sizes the map without it growing
+	numObjects := 1
+	for i := 1; i < len(results); i++ {
+		if results[i].UniqueID != results[i-1].UniqueID {
+			numObjects++
+		}
+	}
+	metadataMap := make(MetadataMap, numObjects)
+	interner := utils.NewStringInterner()
 	var metadata ObjectMetadata
 	currentUniqueID := UniqueID{}
 	// Collect all entries for the same object into one ObjectMetadata
@@ -109,14 +116,15 @@ func ConstructMetadataMap(results []MetadataQueryStruct) MetadataMap {
 			currentUniqueID = result.UniqueID
 			metadata = ObjectMetadata{}
 			metadata.Privileges = make([]ACL, 0)
-			metadata.Owner = result.Owner
+			metadata.Owner = interner.Intern(result.Owner)
 			metadata.Comment = result.Comment
-			metadata.SecurityLabelProvider = result.SecurityLabelProvider
+			metadata.SecurityLabelProvider = interner.Intern(result.SecurityLabelProvider)
 			metadata.SecurityLabel = result.SecurityLabel
 		}
 
-		privileges := ParseACL(privilegesStr)
+		privileges := b.ParseACL(privilegesStr)
 		if privileges != nil {
+			privileges.Grantee = interner.Intern(privileges.Grantee)
 			metadata.Privileges = append(metadata.Privileges, *privileges)
 		}
 	}
@@ -125,7 +133,7 @@ func ConstructMetadataMap(results []MetadataQueryStruct) MetadataMap {
 	return metadataMap
 }
 
-func getColumnACL(privileges sql.NullString, kind string) []ACL {
+func (b *Backup) getColumnACL(privileges sql.NullString, kind string) []ACL {
 	privilegesStr := ""
 	if kind == "Empty" {
 		privilegesStr = "GRANTEE=/GRANTOR"
@@ -133,14 +141,14 @@ func getColumnACL(privileges sql.NullString, kind string) []ACL {
 		privilegesStr = privileges.String
 	}
 	columnMetadata := make([]ACL, 0)
-	acl := ParseACL(privilegesStr)
+	acl := b.ParseACL(privilegesStr)
 	if acl != nil {
 		columnMetadata = append(columnMetadata, *acl)
 	}
 	return columnMetadata
 }
 
-func ParseACL(aclStr string) *ACL {
+func (b *Backup) ParseACL(aclStr string) *ACL {
 	grantee := ""
 	acl := ACL{}
 	if matches := ACLRegex.FindStringSubmatch(aclStr); len(matches) != 0 {
@@ -219,7 +227,7 @@ func ParseACL(aclStr string) *ACL {
 			}
 			lastChar = char
 		}
-		if quotedRoleName, ok := quotedRoleNames[grantee]; ok {
+		if quotedRoleName, ok := b.quotedRoleNames[grantee]; ok {
 			acl.Grantee = quotedRoleName
 		} else {
 			acl.Grantee = grantee
@@ -296,9 +304,12 @@ func createPrivilegeStrings(acl ACL, objectType string) (string, string) {
 		hasAllPrivileges = acl.Select && acl.Insert && acl.Update && acl.Delete && acl.References && acl.Trigger
 		hasAllPrivilegesWithGrant = acl.SelectWithGrant && acl.InsertWithGrant && acl.UpdateWithGrant && acl.DeleteWithGrant &&
 			acl.ReferencesWithGrant && acl.TriggerWithGrant
-	case "FUNCTION":
+	case "FUNCTION", "PROCEDURE":
 		hasAllPrivileges = acl.Execute
 		hasAllPrivilegesWithGrant = acl.ExecuteWithGrant
+	case "LARGE OBJECT":
+		hasAllPrivileges = acl.Select && acl.Update
+		hasAllPrivilegesWithGrant = acl.SelectWithGrant && acl.UpdateWithGrant
 	case "LANGUAGE":
 		hasAllPrivileges = acl.Usage
 		hasAllPrivilegesWithGrant = acl.UsageWithGrant
@@ -409,7 +420,7 @@ func createPrivilegeStrings(acl ACL, objectType string) (string, string) {
 	return privStr, privWithGrantStr
 
 }
-func (obj ObjectMetadata) GetOwnerStatement(objectName string, objectType string) string {
+func (obj ObjectMetadata) GetOwnerStatement(connectionPool *dbconn.DBConn, objectName string, objectType string) string {
 	typeStr := objectType
 	if connectionPool.Version.Before("6") && (objectType == "SEQUENCE" || objectType == "VIEW") {
 		typeStr = "TABLE"
@@ -495,7 +506,7 @@ func PrintDefaultPrivilegesStatements(metadataFile *utils.FileWithByteCount, toc
 	}
 }
 
-func ConstructDefaultPrivileges(results []DefaultPrivilegesQueryStruct) []DefaultPrivileges {
+func (b *Backup) ConstructDefaultPrivileges(results []DefaultPrivilegesQueryStruct) []DefaultPrivileges {
 	if len(results) == 0 {
 		return []DefaultPrivileges{}
 	}
@@ -522,7 +533,7 @@ func ConstructDefaultPrivileges(results []DefaultPrivilegesQueryStruct) []Defaul
 			priv.ObjectType = result.ObjectType
 		}
 
-		privileges := ParseACL(privilegesStr)
+		privileges := b.ParseACL(privilegesStr)
 		if privileges != nil {
 			priv.Privileges = append(priv.Privileges, *privileges)
 		}
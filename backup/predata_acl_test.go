@@ -2,12 +2,16 @@ package backup_test
 
 import (
 	"database/sql"
+	"fmt"
+	"runtime"
+	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/greenplum-db/gp-common-go-libs/structmatcher"
 	"github.com/greenplum-db/gp-common-go-libs/testhelper"
 	"github.com/greenplum-db/gpbackup/backup"
 	"github.com/greenplum-db/gpbackup/testutils"
+	"github.com/greenplum-db/gpbackup/utils"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -31,28 +35,48 @@ var _ = Describe("backup/predata_acl tests", func() {
 		privilegesWithGrant := []backup.ACL{hasAllPrivilegesWithGrant, hasMostPrivilegesWithGrant, hasSinglePrivilegeWithGrant}
 		It("prints a block with a table comment", func() {
 			tableMetadata := backup.ObjectMetadata{Comment: "This is a table comment."}
-			backup.PrintObjectMetadata(backupfile, toc, tableMetadata, table, "")
+			testBackup.PrintObjectMetadata(backupfile, toc, tableMetadata, table, "")
 			testhelper.ExpectRegexp(buffer, `
 
 COMMENT ON TABLE public.tablename IS 'This is a table comment.';`)
 		})
 		It("prints a block with a table comment with special characters", func() {
 			tableMetadata := backup.ObjectMetadata{Comment: `This is a ta'ble 1+=;,./\>,<@\\n^comment.`}
-			backup.PrintObjectMetadata(backupfile, toc, tableMetadata, table, "")
+			testBackup.PrintObjectMetadata(backupfile, toc, tableMetadata, table, "")
 			testhelper.ExpectRegexp(buffer, `
 
 COMMENT ON TABLE public.tablename IS 'This is a ta''ble 1+=;,./\>,<@\\n^comment.';`)
 		})
 		It("prints an ALTER TABLE ... OWNER TO statement to set the table owner", func() {
 			tableMetadata := backup.ObjectMetadata{Owner: "testrole"}
-			backup.PrintObjectMetadata(backupfile, toc, tableMetadata, table, "")
+			testBackup.PrintObjectMetadata(backupfile, toc, tableMetadata, table, "")
 			testhelper.ExpectRegexp(buffer, `
 
 ALTER TABLE public.tablename OWNER TO testrole;`)
 		})
+		It("does not print an owner statement with --no-owner", func() {
+			_ = cmdFlags.Set(utils.NO_OWNER, "true")
+			defer cmdFlags.Set(utils.NO_OWNER, "false")
+			tableMetadata := backup.ObjectMetadata{Owner: "testrole", Comment: "This is a table comment."}
+			testBackup.PrintObjectMetadata(backupfile, toc, tableMetadata, table, "")
+			testhelper.ExpectRegexp(buffer, `
+
+COMMENT ON TABLE public.tablename IS 'This is a table comment.';`)
+			testhelper.NotExpectRegexp(buffer, `OWNER TO`)
+		})
+		It("does not print REVOKE and GRANT statements with --no-privileges", func() {
+			_ = cmdFlags.Set(utils.NO_PRIVILEGES, "true")
+			defer cmdFlags.Set(utils.NO_PRIVILEGES, "false")
+			tableMetadata := backup.ObjectMetadata{Privileges: privileges, Owner: "testrole"}
+			testBackup.PrintObjectMetadata(backupfile, toc, tableMetadata, table, "")
+			testhelper.ExpectRegexp(buffer, `
+
+ALTER TABLE public.tablename OWNER TO testrole;`)
+			testhelper.NotExpectRegexp(buffer, `GRANT|REVOKE`)
+		})
 		It("prints a block of REVOKE and GRANT statements", func() {
 			tableMetadata := backup.ObjectMetadata{Privileges: privileges}
-			backup.PrintObjectMetadata(backupfile, toc, tableMetadata, table, "")
+			testBackup.PrintObjectMetadata(backupfile, toc, tableMetadata, table, "")
 			testhelper.ExpectRegexp(buffer, `
 
 REVOKE ALL ON TABLE public.tablename FROM PUBLIC;
@@ -62,7 +86,7 @@ GRANT TRIGGER ON TABLE public.tablename TO PUBLIC;`)
 		})
 		It("prints a block of REVOKE and GRANT statements WITH GRANT OPTION", func() {
 			tableMetadata := backup.ObjectMetadata{Privileges: privilegesWithGrant}
-			backup.PrintObjectMetadata(backupfile, toc, tableMetadata, table, "")
+			testBackup.PrintObjectMetadata(backupfile, toc, tableMetadata, table, "")
 			testhelper.ExpectRegexp(buffer, `
 
 REVOKE ALL ON TABLE public.tablename FROM PUBLIC;
@@ -72,7 +96,7 @@ GRANT TRIGGER ON TABLE public.tablename TO PUBLIC WITH GRANT OPTION;`)
 		})
 		It("prints a block of REVOKE and GRANT statements, some with WITH GRANT OPTION, some without", func() {
 			tableMetadata := backup.ObjectMetadata{Privileges: []backup.ACL{hasAllPrivileges, hasMostPrivilegesWithGrant}}
-			backup.PrintObjectMetadata(backupfile, toc, tableMetadata, table, "")
+			testBackup.PrintObjectMetadata(backupfile, toc, tableMetadata, table, "")
 			testhelper.ExpectRegexp(buffer, `
 
 REVOKE ALL ON TABLE public.tablename FROM PUBLIC;
@@ -81,7 +105,7 @@ GRANT SELECT,INSERT,UPDATE,DELETE,TRUNCATE,REFERENCES ON TABLE public.tablename
 		})
 		It("prints both an ALTER TABLE ... OWNER TO statement and a table comment", func() {
 			tableMetadata := backup.ObjectMetadata{Comment: "This is a table comment.", Owner: "testrole"}
-			backup.PrintObjectMetadata(backupfile, toc, tableMetadata, table, "")
+			testBackup.PrintObjectMetadata(backupfile, toc, tableMetadata, table, "")
 			testhelper.ExpectRegexp(buffer, `
 
 COMMENT ON TABLE public.tablename IS 'This is a table comment.';
@@ -91,7 +115,7 @@ ALTER TABLE public.tablename OWNER TO testrole;`)
 		})
 		It("prints both a block of REVOKE and GRANT statements and an ALTER TABLE ... OWNER TO statement", func() {
 			tableMetadata := backup.ObjectMetadata{Privileges: privileges, Owner: "testrole"}
-			backup.PrintObjectMetadata(backupfile, toc, tableMetadata, table, "")
+			testBackup.PrintObjectMetadata(backupfile, toc, tableMetadata, table, "")
 			testhelper.ExpectRegexp(buffer, `
 
 ALTER TABLE public.tablename OWNER TO testrole;
@@ -105,7 +129,7 @@ GRANT TRIGGER ON TABLE public.tablename TO PUBLIC;`)
 		})
 		It("prints both a block of REVOKE and GRANT statements and a table comment", func() {
 			tableMetadata := backup.ObjectMetadata{Privileges: privileges, Comment: "This is a table comment."}
-			backup.PrintObjectMetadata(backupfile, toc, tableMetadata, table, "")
+			testBackup.PrintObjectMetadata(backupfile, toc, tableMetadata, table, "")
 			testhelper.ExpectRegexp(buffer, `
 
 COMMENT ON TABLE public.tablename IS 'This is a table comment.';
@@ -118,7 +142,7 @@ GRANT TRIGGER ON TABLE public.tablename TO PUBLIC;`)
 		})
 		It("prints REVOKE and GRANT statements, an ALTER TABLE ... OWNER TO statement, and comments", func() {
 			tableMetadata := backup.ObjectMetadata{Privileges: privileges, Owner: "testrole", Comment: "This is a table comment."}
-			backup.PrintObjectMetadata(backupfile, toc, tableMetadata, table, "")
+			testBackup.PrintObjectMetadata(backupfile, toc, tableMetadata, table, "")
 			testhelper.ExpectRegexp(buffer, `
 
 COMMENT ON TABLE public.tablename IS 'This is a table comment.';
@@ -137,7 +161,7 @@ GRANT TRIGGER ON TABLE public.tablename TO PUBLIC;`)
 			server := backup.ForeignServer{Name: "foreignserver"}
 			serverPrivileges := testutils.DefaultACLForType("testrole", "FOREIGN SERVER")
 			serverMetadata := backup.ObjectMetadata{Privileges: []backup.ACL{serverPrivileges}, Owner: "testrole"}
-			backup.PrintObjectMetadata(backupfile, toc, serverMetadata, server, "")
+			testBackup.PrintObjectMetadata(backupfile, toc, serverMetadata, server, "")
 			testhelper.ExpectRegexp(buffer, `
 
 ALTER SERVER foreignserver OWNER TO testrole;
@@ -156,28 +180,28 @@ GRANT ALL ON FOREIGN SERVER foreignserver TO testrole;`)
 			})
 			It("prints an ALTER TABLE ... OWNER TO statement to set the owner for a sequence if version < 6", func() {
 				testhelper.SetDBVersion(connectionPool, "5.0.0")
-				backup.PrintObjectMetadata(backupfile, toc, objectMetadata, sequence, "public.sequencename")
+				testBackup.PrintObjectMetadata(backupfile, toc, objectMetadata, sequence, "public.sequencename")
 				testhelper.ExpectRegexp(buffer, `
 
 ALTER TABLE public.sequencename OWNER TO testrole;`)
 			})
 			It("prints an ALTER TABLE ... OWNER TO statement to set the owner for a view if version < 6", func() {
 				testhelper.SetDBVersion(connectionPool, "5.0.0")
-				backup.PrintObjectMetadata(backupfile, toc, objectMetadata, view, "public.viewname")
+				testBackup.PrintObjectMetadata(backupfile, toc, objectMetadata, view, "public.viewname")
 				testhelper.ExpectRegexp(buffer, `
 
 ALTER TABLE public.viewname OWNER TO testrole;`)
 			})
 			It("prints an ALTER SEQUENCE ... OWNER TO statement to set the owner for a sequence if version >= 6", func() {
 				testhelper.SetDBVersion(connectionPool, "6.0.0")
-				backup.PrintObjectMetadata(backupfile, toc, objectMetadata, sequence, "public.sequencename")
+				testBackup.PrintObjectMetadata(backupfile, toc, objectMetadata, sequence, "public.sequencename")
 				testhelper.ExpectRegexp(buffer, `
 
 ALTER SEQUENCE public.sequencename OWNER TO testrole;`)
 			})
 			It("prints an ALTER VIEW ... OWNER TO statement to set the owner for a view if version >= 6", func() {
 				testhelper.SetDBVersion(connectionPool, "6.0.0")
-				backup.PrintObjectMetadata(backupfile, toc, objectMetadata, view, "public.viewname")
+				testBackup.PrintObjectMetadata(backupfile, toc, objectMetadata, view, "public.viewname")
 				testhelper.ExpectRegexp(buffer, `
 
 ALTER VIEW public.viewname OWNER TO testrole;`)
@@ -279,12 +303,12 @@ ALTER DEFAULT PRIVILEGES FOR ROLE testrole GRANT USAGE ON TABLES TO somerole WIT
 			metadataList = []backup.MetadataQueryStruct{}
 		})
 		It("No objects", func() {
-			metadataMap := backup.ConstructMetadataMap(metadataList)
+			metadataMap := testBackup.ConstructMetadataMap(metadataList)
 			Expect(metadataMap).To(BeEmpty())
 		})
 		It("One object", func() {
 			metadataList = []backup.MetadataQueryStruct{object2}
-			metadataMap := backup.ConstructMetadataMap(metadataList)
+			metadataMap := testBackup.ConstructMetadataMap(metadataList)
 			expectedObjectMetadata := backup.ObjectMetadata{Privileges: []backup.ACL{{Grantee: "testrole", Select: true}}, Owner: "testrole", Comment: "this is a comment", SecurityLabelProvider: "some_provider", SecurityLabel: "some_label"}
 			Expect(metadataMap).To(HaveLen(1))
 			Expect(metadataMap[backup.UniqueID{Oid: 2}]).To(Equal(expectedObjectMetadata))
@@ -292,14 +316,14 @@ ALTER DEFAULT PRIVILEGES FOR ROLE testrole GRANT USAGE ON TABLES TO somerole WIT
 		})
 		It("One object with two ACL entries", func() {
 			metadataList = []backup.MetadataQueryStruct{object1A, object1B}
-			metadataMap := backup.ConstructMetadataMap(metadataList)
+			metadataMap := testBackup.ConstructMetadataMap(metadataList)
 			expectedObjectMetadata := backup.ObjectMetadata{Privileges: []backup.ACL{{Grantee: "gpadmin", Select: true}, {Grantee: "testrole", Select: true}}, Owner: "testrole"}
 			Expect(metadataMap).To(HaveLen(1))
 			Expect(metadataMap[backup.UniqueID{Oid: 1}]).To(Equal(expectedObjectMetadata))
 		})
 		It("Multiple objects", func() {
 			metadataList = []backup.MetadataQueryStruct{object1A, object1B, object2}
-			metadataMap := backup.ConstructMetadataMap(metadataList)
+			metadataMap := testBackup.ConstructMetadataMap(metadataList)
 			expectedObjectMetadataOne := backup.ObjectMetadata{Privileges: []backup.ACL{{Grantee: "gpadmin", Select: true}, {Grantee: "testrole", Select: true}}, Owner: "testrole"}
 			expectedObjectMetadataTwo := backup.ObjectMetadata{Privileges: []backup.ACL{{Grantee: "testrole", Select: true}}, Owner: "testrole", Comment: "this is a comment", SecurityLabelProvider: "some_provider", SecurityLabel: "some_label"}
 			Expect(metadataMap).To(HaveLen(2))
@@ -308,14 +332,14 @@ ALTER DEFAULT PRIVILEGES FOR ROLE testrole GRANT USAGE ON TABLES TO somerole WIT
 		})
 		It("Default Kind", func() {
 			metadataList = []backup.MetadataQueryStruct{objectDefaultKind}
-			metadataMap := backup.ConstructMetadataMap(metadataList)
+			metadataMap := testBackup.ConstructMetadataMap(metadataList)
 			expectedObjectMetadata := backup.ObjectMetadata{Privileges: []backup.ACL{}, Owner: "testrole"}
 			Expect(metadataMap).To(HaveLen(1))
 			Expect(metadataMap[backup.UniqueID{Oid: 3}]).To(Equal(expectedObjectMetadata))
 		})
 		It("'Empty' Kind", func() {
 			metadataList = []backup.MetadataQueryStruct{objectEmptyKind}
-			metadataMap := backup.ConstructMetadataMap(metadataList)
+			metadataMap := testBackup.ConstructMetadataMap(metadataList)
 			expectedObjectMetadata := backup.ObjectMetadata{Privileges: []backup.ACL{{Grantee: "GRANTEE"}}, Owner: "testrole"}
 			Expect(metadataMap).To(HaveLen(1))
 			Expect(metadataMap[backup.UniqueID{Oid: 4}]).To(Equal(expectedObjectMetadata))
@@ -328,55 +352,55 @@ ALTER DEFAULT PRIVILEGES FOR ROLE testrole GRANT USAGE ON TABLES TO somerole WIT
 				"testrole":  "testrole",
 				"Test|role": `"Test|role"`,
 			}
-			backup.SetQuotedRoleNames(quotedRoleNames)
+			testBackup.SetQuotedRoleNames(quotedRoleNames)
 		})
 		It("parses an ACL string representing default privileges", func() {
 			aclStr := ""
-			result := backup.ParseACL(aclStr)
+			result := testBackup.ParseACL(aclStr)
 			Expect(result).To(BeNil())
 		})
 		It("parses an ACL string representing no privileges", func() {
 			aclStr := "GRANTEE=/GRANTOR"
 			expected := backup.ACL{Grantee: "GRANTEE"}
-			result := backup.ParseACL(aclStr)
+			result := testBackup.ParseACL(aclStr)
 			structmatcher.ExpectStructsToMatch(&expected, result)
 		})
 		It("parses an ACL string containing a role with multiple privileges", func() {
 			aclStr := "testrole=arwdDxt/gpadmin"
 			expected := testutils.DefaultACLForType("testrole", "TABLE")
-			result := backup.ParseACL(aclStr)
+			result := testBackup.ParseACL(aclStr)
 			structmatcher.ExpectStructsToMatch(&expected, result)
 		})
 		It("parses an ACL string containing a role with one privilege", func() {
 			aclStr := "testrole=a/gpadmin"
 			expected := backup.ACL{Grantee: "testrole", Insert: true}
-			result := backup.ParseACL(aclStr)
+			result := testBackup.ParseACL(aclStr)
 			structmatcher.ExpectStructsToMatch(&expected, result)
 		})
 		It("parses an ACL string containing a role name with special characters", func() {
 			aclStr := `Test|role=a/gpadmin`
 			expected := backup.ACL{Grantee: `"Test|role"`, Insert: true}
-			result := backup.ParseACL(aclStr)
+			result := testBackup.ParseACL(aclStr)
 			structmatcher.ExpectStructsToMatch(&expected, result)
 		})
 		It("parses an ACL string containing a role with some privileges with GRANT and some without including GRANT", func() {
 			aclStr := "testrole=ar*w*d*tXUCTc/gpadmin"
 			expected := backup.ACL{Grantee: "testrole", Insert: true, SelectWithGrant: true, UpdateWithGrant: true,
 				DeleteWithGrant: true, Trigger: true, Execute: true, Usage: true, Create: true, Temporary: true, Connect: true}
-			result := backup.ParseACL(aclStr)
+			result := testBackup.ParseACL(aclStr)
 			structmatcher.ExpectStructsToMatch(&expected, result)
 		})
 		It("parses an ACL string containing a role with all privileges including GRANT", func() {
 			aclStr := "testrole=a*D*x*t*X*U*C*T*c*/gpadmin"
 			expected := backup.ACL{Grantee: "testrole", InsertWithGrant: true, TruncateWithGrant: true, ReferencesWithGrant: true,
 				TriggerWithGrant: true, ExecuteWithGrant: true, UsageWithGrant: true, CreateWithGrant: true, TemporaryWithGrant: true, ConnectWithGrant: true}
-			result := backup.ParseACL(aclStr)
+			result := testBackup.ParseACL(aclStr)
 			structmatcher.ExpectStructsToMatch(&expected, result)
 		})
 		It("parses an ACL string granting privileges to PUBLIC", func() {
 			aclStr := "=a/gpadmin"
 			expected := backup.ACL{Grantee: "", Insert: true}
-			result := backup.ParseACL(aclStr)
+			result := testBackup.ParseACL(aclStr)
 			structmatcher.ExpectStructsToMatch(&expected, result)
 		})
 	})
@@ -396,26 +420,26 @@ ALTER DEFAULT PRIVILEGES FOR ROLE testrole GRANT USAGE ON TABLES TO somerole WIT
 			privilegesQuerylist = []backup.DefaultPrivilegesQueryStruct{}
 		})
 		It("returns no privileges when no default privileges exist", func() {
-			privilegesList := backup.ConstructDefaultPrivileges(privilegesQuerylist)
+			privilegesList := testBackup.ConstructDefaultPrivileges(privilegesQuerylist)
 			Expect(privilegesList).To(BeEmpty())
 		})
 		It("constructs a single sequence default privilege in a specific schema", func() {
 			privilegesQuerylist = []backup.DefaultPrivilegesQueryStruct{object2}
-			privilegesList := backup.ConstructDefaultPrivileges(privilegesQuerylist)
+			privilegesList := testBackup.ConstructDefaultPrivileges(privilegesQuerylist)
 			expectedDefaultPrivileges := backup.DefaultPrivileges{Privileges: []backup.ACL{{Grantee: "testrole", Select: true}}, Owner: "testrole", Schema: "myschema", ObjectType: "S"}
 			Expect(privilegesList).To(HaveLen(1))
 			Expect(privilegesList[0]).To(Equal(expectedDefaultPrivileges))
 		})
 		It("constructs multiple default privileges on a single relation in a specific schema", func() {
 			privilegesQuerylist = []backup.DefaultPrivilegesQueryStruct{object1A, object1B}
-			privilegesList := backup.ConstructDefaultPrivileges(privilegesQuerylist)
+			privilegesList := testBackup.ConstructDefaultPrivileges(privilegesQuerylist)
 			expectedObjectMetadata := backup.DefaultPrivileges{Privileges: []backup.ACL{{Grantee: "gpadmin", Select: true}, {Grantee: "testrole", Select: true}}, Owner: "testrole", Schema: "myschema", ObjectType: "r"}
 			Expect(privilegesList).To(HaveLen(1))
 			Expect(privilegesList[0]).To(Equal(expectedObjectMetadata))
 		})
 		It("constructs multiple default privileges on multiple objects in a specific schema", func() {
 			privilegesQuerylist = []backup.DefaultPrivilegesQueryStruct{object1A, object1B, object2}
-			privilegesList := backup.ConstructDefaultPrivileges(privilegesQuerylist)
+			privilegesList := testBackup.ConstructDefaultPrivileges(privilegesQuerylist)
 			expectedObjectMetadataOne := backup.DefaultPrivileges{Privileges: []backup.ACL{{Grantee: "gpadmin", Select: true}, {Grantee: "testrole", Select: true}}, Owner: "testrole", Schema: "myschema", ObjectType: "r"}
 			expectedObjectMetadataTwo := backup.DefaultPrivileges{Privileges: []backup.ACL{{Grantee: "testrole", Select: true}}, Owner: "testrole", Schema: "myschema", ObjectType: "S"}
 			Expect(privilegesList).To(HaveLen(2))
@@ -424,17 +448,81 @@ ALTER DEFAULT PRIVILEGES FOR ROLE testrole GRANT USAGE ON TABLES TO somerole WIT
 		})
 		It("constructs a default privilege for a type with a 'Default' kind", func() {
 			privilegesQuerylist = []backup.DefaultPrivilegesQueryStruct{objectDefaultKind}
-			privilegesList := backup.ConstructDefaultPrivileges(privilegesQuerylist)
+			privilegesList := testBackup.ConstructDefaultPrivileges(privilegesQuerylist)
 			expectedObjectMetadata := backup.DefaultPrivileges{Privileges: []backup.ACL{}, Owner: "testrole", Schema: "", ObjectType: "T"}
 			Expect(privilegesList).To(HaveLen(1))
 			Expect(privilegesList[0]).To(Equal(expectedObjectMetadata))
 		})
 		It("constructs a default privilege for a function with an 'Empty' kind", func() {
 			privilegesQuerylist = []backup.DefaultPrivilegesQueryStruct{objectEmptyKind}
-			privilegesList := backup.ConstructDefaultPrivileges(privilegesQuerylist)
+			privilegesList := testBackup.ConstructDefaultPrivileges(privilegesQuerylist)
 			expectedObjectMetadata := backup.DefaultPrivileges{Privileges: []backup.ACL{{Grantee: "GRANTEE"}}, Owner: "testrole", Schema: "", ObjectType: "f"}
 			Expect(privilegesList).To(HaveLen(1))
 			Expect(privilegesList[0]).To(Equal(expectedObjectMetadata))
 		})
 	})
 })
+
+/*
+ * Each row of a metadata query is scanned into its own strings, so the rows
+ * are built with separate copies of the role names as they would be.  The
+ * heap retained by the map is reported per object, both for the map built as
+ * it was before strings were shared across objects and the map was presized,
+ * as a baseline, and for ConstructMetadataMap.
+ */
+func BenchmarkConstructMetadataMap(b *testing.B) {
+	const numObjects = 100000
+	results := make([]backup.MetadataQueryStruct, 0, numObjects*2)
+	for i := 0; i < numObjects; i++ {
+		uniqueID := backup.UniqueID{ClassID: backup.PG_CLASS_OID, Oid: uint32(i + 1)}
+		owner := fmt.Sprintf("role%d", i%10)
+		for _, grantee := range []string{fmt.Sprintf("role%d", i%10), fmt.Sprintf("role%d", i%7)} {
+			results = append(results, backup.MetadataQueryStruct{
+				UniqueID:   uniqueID,
+				Privileges: sql.NullString{String: fmt.Sprintf("%s=arwdDxt/%s", grantee, owner), Valid: true},
+				Owner:      fmt.Sprintf("role%d", i%10),
+			})
+		}
+	}
+	b.Run("baseline", func(b *testing.B) {
+		benchmarkRetainedMetadata(b, numObjects, func() interface{} { return constructUnsharedMetadataMap(results) })
+	})
+	b.Run("ConstructMetadataMap", func(b *testing.B) {
+		benchmarkRetainedMetadata(b, numObjects, func() interface{} { return testBackup.ConstructMetadataMap(results) })
+	})
+}
+
+func benchmarkRetainedMetadata(b *testing.B, numObjects int, construct func() interface{}) {
+	b.ReportAllocs()
+	var retainedBytes uint64
+	for n := 0; n < b.N; n++ {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		metadataMap := construct()
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		runtime.KeepAlive(metadataMap)
+		if after.HeapAlloc > before.HeapAlloc {
+			retainedBytes += after.HeapAlloc - before.HeapAlloc
+		}
+	}
+	b.ReportMetric(float64(retainedBytes)/float64(b.N)/float64(numObjects), "retained-B/object")
+}
+
+// Privileges are left unsorted, as sorting does not change the memory they use
+func constructUnsharedMetadataMap(results []backup.MetadataQueryStruct) backup.MetadataMap {
+	metadataMap := make(backup.MetadataMap)
+	for _, result := range results {
+		metadata, ok := metadataMap[result.UniqueID]
+		if !ok {
+			metadata = backup.ObjectMetadata{Privileges: make([]backup.ACL, 0), Owner: result.Owner, Comment: result.Comment,
+				SecurityLabelProvider: result.SecurityLabelProvider, SecurityLabel: result.SecurityLabel}
+		}
+		if privileges := testBackup.ParseACL(result.Privileges.String); privileges != nil {
+			metadata.Privileges = append(metadata.Privileges, *privileges)
+		}
+		metadataMap[result.UniqueID] = metadata
+	}
+	return metadataMap
+}
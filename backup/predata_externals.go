@@ -304,7 +304,7 @@ func PrintExternalTableStatements(metadataFile *utils.FileWithByteCount, tableNa
 	}
 }
 
-func PrintCreateExternalProtocolStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, protocol ExternalProtocol, funcInfoMap map[uint32]FunctionInfo, protoMetadata ObjectMetadata) {
+func (b *Backup) PrintCreateExternalProtocolStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, protocol ExternalProtocol, funcInfoMap map[uint32]FunctionInfo, protoMetadata ObjectMetadata) {
 	start := metadataFile.ByteCount
 	funcOidList := []uint32{protocol.ReadFunction, protocol.WriteFunction, protocol.Validator}
 	hasUserDefinedFunc := false
@@ -336,7 +336,7 @@ func PrintCreateExternalProtocolStatement(metadataFile *utils.FileWithByteCount,
 
 	section, entry := protocol.GetMetadataEntry()
 	toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-	PrintObjectMetadata(metadataFile, toc, protoMetadata, protocol, "")
+	b.PrintObjectMetadata(metadataFile, toc, protoMetadata, protocol, "")
 }
 
 func PrintExchangeExternalPartitionStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, extPartitions []PartitionInfo, partInfoMap map[uint32]PartitionInfo, tables []Table) {
@@ -449,26 +449,26 @@ ENCODING 'UTF-8'`)
 		}
 
 		It("prints untrusted protocol with read and write function", func() {
-			backup.PrintCreateExternalProtocolStatement(backupfile, toc, protocolUntrustedReadWrite, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateExternalProtocolStatement(backupfile, toc, protocolUntrustedReadWrite, funcInfoMap, emptyMetadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "", "", "s3", "PROTOCOL")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE PROTOCOL s3 (readfunc = public.read_fn_s3, writefunc = public.write_fn_s3);`)
 		})
 		It("prints untrusted protocol with read and validator", func() {
-			backup.PrintCreateExternalProtocolStatement(backupfile, toc, protocolUntrustedReadValidator, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateExternalProtocolStatement(backupfile, toc, protocolUntrustedReadValidator, funcInfoMap, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE PROTOCOL s3 (readfunc = public.read_fn_s3, validatorfunc = public.validator);`)
 		})
 		It("prints untrusted protocol with write function only", func() {
-			backup.PrintCreateExternalProtocolStatement(backupfile, toc, protocolUntrustedWriteOnly, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateExternalProtocolStatement(backupfile, toc, protocolUntrustedWriteOnly, funcInfoMap, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE PROTOCOL s3 (writefunc = public.write_fn_s3);`)
 		})
 		It("prints trusted protocol with read, write, and validator", func() {
-			backup.PrintCreateExternalProtocolStatement(backupfile, toc, protocolTrustedReadWriteValidator, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateExternalProtocolStatement(backupfile, toc, protocolTrustedReadWriteValidator, funcInfoMap, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TRUSTED PROTOCOL s3 (readfunc = public.read_fn_s3, writefunc = public.write_fn_s3, validatorfunc = public.validator);`)
 		})
 		It("prints a protocol with privileges and an owner", func() {
 			protoMetadata := backup.ObjectMetadata{Privileges: []backup.ACL{{Grantee: "testrole", Select: true, Insert: true}}, Owner: "testrole"}
 
-			backup.PrintCreateExternalProtocolStatement(backupfile, toc, protocolUntrustedReadWrite, funcInfoMap, protoMetadata)
+			testBackup.PrintCreateExternalProtocolStatement(backupfile, toc, protocolUntrustedReadWrite, funcInfoMap, protoMetadata)
 			expectedStatements := []string{
 				"CREATE PROTOCOL s3 (readfunc = public.read_fn_s3, writefunc = public.write_fn_s3);",
 				"ALTER PROTOCOL s3 OWNER TO testrole;",
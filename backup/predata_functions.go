@@ -8,23 +8,31 @@ package backup
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/greenplum-db/gpbackup/utils"
 )
 
-func PrintCreateFunctionStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, funcDef Function, funcMetadata ObjectMetadata) {
+func (b *Backup) PrintCreateFunctionStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, funcDef Function, funcMetadata ObjectMetadata) {
 	start := metadataFile.ByteCount
 	funcFQN := utils.MakeFQN(funcDef.Schema, funcDef.Name)
-	metadataFile.MustPrintf("\n\nCREATE FUNCTION %s(%s) RETURNS ", funcFQN, funcDef.Arguments)
-	metadataFile.MustPrintf("%s AS", funcDef.ResultType)
-	PrintFunctionBodyOrPath(metadataFile, funcDef)
-	metadataFile.MustPrintf("LANGUAGE %s", funcDef.Language)
-	PrintFunctionModifiers(metadataFile, funcDef)
+	if funcDef.Kind == "p" {
+		metadataFile.MustPrintf("\n\nCREATE PROCEDURE %s(%s) AS", funcFQN, funcDef.Arguments)
+		PrintFunctionBodyOrPath(metadataFile, funcDef)
+		metadataFile.MustPrintf("LANGUAGE %s", funcDef.Language)
+		PrintProcedureModifiers(metadataFile, funcDef)
+	} else {
+		metadataFile.MustPrintf("\n\nCREATE FUNCTION %s(%s) RETURNS ", funcFQN, funcDef.Arguments)
+		metadataFile.MustPrintf("%s AS", funcDef.ResultType)
+		PrintFunctionBodyOrPath(metadataFile, funcDef)
+		metadataFile.MustPrintf("LANGUAGE %s", funcDef.Language)
+		PrintFunctionModifiers(metadataFile, funcDef)
+	}
 	metadataFile.MustPrintln(";")
 
 	section, entry := funcDef.GetMetadataEntry()
 	toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-	PrintObjectMetadata(metadataFile, toc, funcMetadata, funcDef, "")
+	b.PrintObjectMetadata(metadataFile, toc, funcMetadata, funcDef, "")
 }
 
 /*
@@ -45,6 +53,9 @@ func PrintFunctionBodyOrPath(metadataFile *utils.FileWithByteCount, funcDef Func
 }
 
 func PrintFunctionModifiers(metadataFile *utils.FileWithByteCount, funcDef Function) {
+	if funcDef.TransformTypes != "" {
+		metadataFile.MustPrintf(" TRANSFORM %s", funcDef.TransformTypes)
+	}
 	switch funcDef.DataAccess {
 	case "c":
 		metadataFile.MustPrintf(" CONTAINS SQL")
@@ -94,7 +105,23 @@ func PrintFunctionModifiers(metadataFile *utils.FileWithByteCount, funcDef Funct
 	}
 }
 
-func PrintCreateAggregateStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, aggDef Aggregate, funcInfoMap map[uint32]FunctionInfo, aggMetadata ObjectMetadata) {
+/*
+ * Procedures do not return values and are not called from queries, so only
+ * the transform, security, and configuration clauses apply to them.
+ */
+func PrintProcedureModifiers(metadataFile *utils.FileWithByteCount, funcDef Function) {
+	if funcDef.TransformTypes != "" {
+		metadataFile.MustPrintf(" TRANSFORM %s", funcDef.TransformTypes)
+	}
+	if funcDef.IsSecurityDefiner {
+		metadataFile.MustPrintf(" SECURITY DEFINER")
+	}
+	if funcDef.Config != "" {
+		metadataFile.MustPrintf("\n%s", funcDef.Config)
+	}
+}
+
+func (b *Backup) PrintCreateAggregateStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, aggDef Aggregate, funcInfoMap map[uint32]FunctionInfo, aggMetadata ObjectMetadata) {
 	start := metadataFile.ByteCount
 	orderedStr := ""
 	if aggDef.IsOrdered {
@@ -164,10 +191,10 @@ func PrintCreateAggregateStatement(metadataFile *utils.FileWithByteCount, toc *u
 
 	section, entry := aggDef.GetMetadataEntry()
 	toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-	PrintObjectMetadata(metadataFile, toc, aggMetadata, aggDef, "")
+	b.PrintObjectMetadata(metadataFile, toc, aggMetadata, aggDef, "")
 }
 
-func PrintCreateCastStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, castDef Cast, castMetadata ObjectMetadata) {
+func (b *Backup) PrintCreateCastStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, castDef Cast, castMetadata ObjectMetadata) {
 	start := metadataFile.ByteCount
 	metadataFile.MustPrintf("\n\nCREATE CAST %s\n", castDef.FQN())
 	switch castDef.CastMethod {
@@ -190,17 +217,34 @@ func PrintCreateCastStatement(metadataFile *utils.FileWithByteCount, toc *utils.
 
 	section, entry := castDef.GetMetadataEntry()
 	toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-	PrintObjectMetadata(metadataFile, toc, castMetadata, castDef, "")
+	b.PrintObjectMetadata(metadataFile, toc, castMetadata, castDef, "")
 }
 
-func PrintCreateExtensionStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, extensionDefs []Extension, extensionMetadata MetadataMap) {
-	for _, extensionDef := range extensionDefs {
-		start := metadataFile.ByteCount
-		metadataFile.MustPrintf("\n\nSET search_path=%s,pg_catalog;\nCREATE EXTENSION IF NOT EXISTS %s WITH SCHEMA %s;\nSET search_path=pg_catalog;", extensionDef.Schema, extensionDef.Name, extensionDef.Schema)
+func (b *Backup) PrintCreateTransformStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, transformDef Transform, transformMetadata ObjectMetadata) {
+	start := metadataFile.ByteCount
+	functions := make([]string, 0)
+	if transformDef.FromSQLFunc != "" {
+		functions = append(functions, fmt.Sprintf("FROM SQL WITH FUNCTION %s", transformDef.FromSQLFunc))
+	}
+	if transformDef.ToSQLFunc != "" {
+		functions = append(functions, fmt.Sprintf("TO SQL WITH FUNCTION %s", transformDef.ToSQLFunc))
+	}
+	metadataFile.MustPrintf("\n\nCREATE TRANSFORM %s (\n\t%s\n);", transformDef.FQN(), strings.Join(functions, ",\n\t"))
 
+	section, entry := transformDef.GetMetadataEntry()
+	toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
+	b.PrintObjectMetadata(metadataFile, toc, transformMetadata, transformDef, "")
+}
+
+func (b *Backup) PrintCreateExtensionStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, extensionDefs []Extension, extensionMetadata MetadataMap) {
+	for _, extensionDef := range extensionDefs {
 		section, entry := extensionDef.GetMetadataEntry()
-		toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-		PrintObjectMetadata(metadataFile, toc, extensionMetadata[extensionDef.GetUniqueID()], extensionDef, "")
+		versionStr := ""
+		if extensionDef.Version != "" {
+			versionStr = fmt.Sprintf(" VERSION '%s'", utils.EscapeSingleQuotes(extensionDef.Version))
+		}
+		metadataFile.MustPrintEntry(toc, section, entry, "\n\nSET search_path=%s,pg_catalog;\nCREATE EXTENSION IF NOT EXISTS %s WITH SCHEMA %s%s;\nSET search_path=pg_catalog;", extensionDef.Schema, extensionDef.Name, extensionDef.Schema, versionStr)
+		b.PrintObjectMetadata(metadataFile, toc, extensionMetadata[extensionDef.GetUniqueID()], extensionDef, "")
 	}
 }
 
@@ -232,7 +276,7 @@ func ExtractLanguageFunctions(funcDefs []Function, procLangs []ProceduralLanguag
 	return langFuncs, otherFuncs
 }
 
-func PrintCreateLanguageStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, procLangs []ProceduralLanguage,
+func (b *Backup) PrintCreateLanguageStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, procLangs []ProceduralLanguage,
 	funcInfoMap map[uint32]FunctionInfo, procLangMetadata MetadataMap) {
 	for _, procLang := range procLangs {
 		start := metadataFile.ByteCount
@@ -271,15 +315,17 @@ func PrintCreateLanguageStatements(metadataFile *utils.FileWithByteCount, toc *u
 		section, entry := procLang.GetMetadataEntry()
 		toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
 
-		start = metadataFile.ByteCount
-		metadataFile.MustPrint(alterStr)
-		toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
+		if !b.MustGetFlagBool(utils.NO_OWNER) {
+			start = metadataFile.ByteCount
+			metadataFile.MustPrint(alterStr)
+			toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
+		}
 
-		PrintObjectMetadata(metadataFile, toc, procLangMetadata[procLang.GetUniqueID()], procLang, "")
+		b.PrintObjectMetadata(metadataFile, toc, procLangMetadata[procLang.GetUniqueID()], procLang, "")
 	}
 }
 
-func PrintCreateConversionStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, conversions []Conversion, conversionMetadata MetadataMap) {
+func (b *Backup) PrintCreateConversionStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, conversions []Conversion, conversionMetadata MetadataMap) {
 	for _, conversion := range conversions {
 		start := metadataFile.ByteCount
 		convFQN := utils.MakeFQN(conversion.Schema, conversion.Name)
@@ -292,11 +338,11 @@ func PrintCreateConversionStatements(metadataFile *utils.FileWithByteCount, toc
 
 		section, entry := conversion.GetMetadataEntry()
 		toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-		PrintObjectMetadata(metadataFile, toc, conversionMetadata[conversion.GetUniqueID()], conversion, "")
+		b.PrintObjectMetadata(metadataFile, toc, conversionMetadata[conversion.GetUniqueID()], conversion, "")
 	}
 }
 
-func PrintCreateForeignDataWrapperStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC,
+func (b *Backup) PrintCreateForeignDataWrapperStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC,
 	fdw ForeignDataWrapper, funcInfoMap map[uint32]FunctionInfo, fdwMetadata ObjectMetadata) {
 	start := metadataFile.ByteCount
 	metadataFile.MustPrintf("\n\nCREATE FOREIGN DATA WRAPPER %s", fdw.Name)
@@ -314,10 +360,10 @@ func PrintCreateForeignDataWrapperStatement(metadataFile *utils.FileWithByteCoun
 
 	section, entry := fdw.GetMetadataEntry()
 	toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-	PrintObjectMetadata(metadataFile, toc, fdwMetadata, fdw, "")
+	b.PrintObjectMetadata(metadataFile, toc, fdwMetadata, fdw, "")
 }
 
-func PrintCreateServerStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, server ForeignServer, serverMetadata ObjectMetadata) {
+func (b *Backup) PrintCreateServerStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, server ForeignServer, serverMetadata ObjectMetadata) {
 	start := metadataFile.ByteCount
 	metadataFile.MustPrintf("\n\nCREATE SERVER %s", server.Name)
 	if server.Type != "" {
@@ -335,7 +381,7 @@ func PrintCreateServerStatement(metadataFile *utils.FileWithByteCount, toc *util
 	//NOTE: We must specify SERVER when creating and dropping, but FOREIGN SERVER when granting and revoking
 	section, entry := server.GetMetadataEntry()
 	toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-	PrintObjectMetadata(metadataFile, toc, serverMetadata, server, "")
+	b.PrintObjectMetadata(metadataFile, toc, serverMetadata, server, "")
 }
 
 func PrintCreateUserMappingStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, mapping UserMapping) {
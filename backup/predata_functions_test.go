@@ -28,7 +28,7 @@ var _ = Describe("backup/predata_functions tests", func() {
 				funcMetadata = backup.ObjectMetadata{}
 			})
 			It("prints a function definition for an internal function without a binary path", func() {
-				backup.PrintCreateFunctionStatement(backupfile, toc, funcDef, funcMetadata)
+				testBackup.PrintCreateFunctionStatement(backupfile, toc, funcDef, funcMetadata)
 				testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "func_name(integer, integer)", "FUNCTION")
 				testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE FUNCTION public.func_name(integer, integer) RETURNS integer AS
 $$add_two_ints$$
@@ -37,14 +37,14 @@ LANGUAGE internal;`)
 			It("prints a function definition for a function that returns a set", func() {
 				funcDef.ReturnsSet = true
 				funcDef.ResultType = "SETOF integer"
-				backup.PrintCreateFunctionStatement(backupfile, toc, funcDef, funcMetadata)
+				testBackup.PrintCreateFunctionStatement(backupfile, toc, funcDef, funcMetadata)
 				testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE FUNCTION public.func_name(integer, integer) RETURNS SETOF integer AS
 $$add_two_ints$$
 LANGUAGE internal;`)
 			})
 			It("prints a function definition for a function with permissions, an owner, security label, and a comment", func() {
 				funcMetadata := testutils.DefaultMetadata("FUNCTION", true, true, true, true)
-				backup.PrintCreateFunctionStatement(backupfile, toc, funcDef, funcMetadata)
+				testBackup.PrintCreateFunctionStatement(backupfile, toc, funcDef, funcMetadata)
 				expectedStatements := []string{`CREATE FUNCTION public.func_name(integer, integer) RETURNS integer AS
 $$add_two_ints$$
 LANGUAGE internal;`,
@@ -57,6 +57,46 @@ GRANT ALL ON FUNCTION public.func_name(integer, integer) TO testrole;`,
 				testutils.AssertBufferContents(toc.PredataEntries, buffer, expectedStatements...)
 
 			})
+			It("prints a function definition for a function with transforms", func() {
+				funcDef.Language = "plperl"
+				funcDef.TransformTypes = "FOR TYPE hstore, FOR TYPE integer"
+				testBackup.PrintCreateFunctionStatement(backupfile, toc, funcDef, funcMetadata)
+				testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE FUNCTION public.func_name(integer, integer) RETURNS integer AS
+$$add_two_ints$$
+LANGUAGE plperl TRANSFORM FOR TYPE hstore, FOR TYPE integer
+COST 1;`)
+			})
+			It("prints a procedure definition without function-only modifiers", func() {
+				funcDef.Kind = "p"
+				funcDef.ResultType = ""
+				funcDef.Language = "sql"
+				funcDef.FunctionBody = "INSERT INTO foo VALUES ($1)"
+				funcDef.Volatility = "s"
+				funcDef.IsStrict = true
+				funcDef.IsSecurityDefiner = true
+				funcDef.Config = "SET search_path TO 'pg_temp'"
+				testBackup.PrintCreateFunctionStatement(backupfile, toc, funcDef, funcMetadata)
+				testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "func_name(integer, integer)", "PROCEDURE")
+				testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE PROCEDURE public.func_name(integer, integer) AS
+$_$INSERT INTO foo VALUES ($1)$_$
+LANGUAGE sql SECURITY DEFINER
+SET search_path TO 'pg_temp';`)
+			})
+			It("prints a procedure definition with permissions, an owner, and a comment", func() {
+				funcDef.Kind = "p"
+				funcDef.ResultType = ""
+				funcMetadata := testutils.DefaultMetadata("PROCEDURE", true, true, true, false)
+				testBackup.PrintCreateFunctionStatement(backupfile, toc, funcDef, funcMetadata)
+				expectedStatements := []string{`CREATE PROCEDURE public.func_name(integer, integer) AS
+$$add_two_ints$$
+LANGUAGE internal;`,
+					"COMMENT ON PROCEDURE public.func_name(integer, integer) IS 'This is a procedure comment.';",
+					"ALTER PROCEDURE public.func_name(integer, integer) OWNER TO testrole;",
+					`REVOKE ALL ON PROCEDURE public.func_name(integer, integer) FROM PUBLIC;
+REVOKE ALL ON PROCEDURE public.func_name(integer, integer) FROM testrole;
+GRANT ALL ON PROCEDURE public.func_name(integer, integer) TO testrole;`}
+				testutils.AssertBufferContents(toc.PredataEntries, buffer, expectedStatements...)
+			})
 		})
 		Describe("PrintFunctionBodyOrPath", func() {
 			It("prints a function definition for an internal function with 'NULL' binary path using '-'", func() {
@@ -278,7 +318,7 @@ $_$`)
 		})
 
 		It("prints an aggregate definition for an unordered aggregate with no optional specifications", func() {
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "agg_name(integer, integer)", "AGGREGATE")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE AGGREGATE public.agg_name(integer, integer) (
 	SFUNC = public.mysfunc,
@@ -287,7 +327,7 @@ $_$`)
 		})
 		It("prints an aggregate definition for an ordered aggregate with no optional specifications", func() {
 			aggDefinition.IsOrdered = true
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE ORDERED AGGREGATE public.agg_name(integer, integer) (
 	SFUNC = public.mysfunc,
 	STYPE = integer
@@ -296,7 +336,7 @@ $_$`)
 		It("prints an aggregate definition for an unordered aggregate with no arguments", func() {
 			aggDefinition.Arguments = ""
 			aggDefinition.IdentArgs = ""
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE AGGREGATE public.agg_name(*) (
 	SFUNC = public.mysfunc,
 	STYPE = integer
@@ -304,7 +344,7 @@ $_$`)
 		})
 		It("prints an aggregate with a preliminary function", func() {
 			aggDefinition.PreliminaryFunction = 2
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE AGGREGATE public.agg_name(integer, integer) (
 	SFUNC = public.mysfunc,
 	STYPE = integer,
@@ -313,7 +353,7 @@ $_$`)
 		})
 		It("prints an aggregate with a combine function", func() {
 			aggDefinition.CombineFunction = 2
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE AGGREGATE public.agg_name(integer, integer) (
 	SFUNC = public.mysfunc,
 	STYPE = integer,
@@ -322,7 +362,7 @@ $_$`)
 		})
 		It("prints an aggregate with a serial function", func() {
 			aggDefinition.SerialFunction = 2
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE AGGREGATE public.agg_name(integer, integer) (
 	SFUNC = public.mysfunc,
 	STYPE = integer,
@@ -331,7 +371,7 @@ $_$`)
 		})
 		It("prints an aggregate with a deserial function", func() {
 			aggDefinition.DeserialFunction = 2
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE AGGREGATE public.agg_name(integer, integer) (
 	SFUNC = public.mysfunc,
 	STYPE = integer,
@@ -340,7 +380,7 @@ $_$`)
 		})
 		It("prints an aggregate with a final function", func() {
 			aggDefinition.FinalFunction = 3
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE AGGREGATE public.agg_name(integer, integer) (
 	SFUNC = public.mysfunc,
 	STYPE = integer,
@@ -349,7 +389,7 @@ $_$`)
 		})
 		It("prints an aggregate with a final function extra attribute", func() {
 			aggDefinition.FinalFuncExtra = true
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE AGGREGATE public.agg_name(integer, integer) (
 	SFUNC = public.mysfunc,
 	STYPE = integer,
@@ -359,7 +399,7 @@ $_$`)
 		It("prints an aggregate with an initial condition", func() {
 			aggDefinition.InitialValue = "0"
 			aggDefinition.InitValIsNull = false
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE AGGREGATE public.agg_name(integer, integer) (
 	SFUNC = public.mysfunc,
 	STYPE = integer,
@@ -369,7 +409,7 @@ $_$`)
 		It("prints an aggregate with a sort operator", func() {
 			aggDefinition.SortOperator = "+"
 			aggDefinition.SortOperatorSchema = "myschema"
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE AGGREGATE public.agg_name(integer, integer) (
 	SFUNC = public.mysfunc,
 	STYPE = integer,
@@ -378,7 +418,7 @@ $_$`)
 		})
 		It("prints an aggregate with a specified transition data size", func() {
 			aggDefinition.TransitionDataSize = 1000
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "agg_name(integer, integer)", "AGGREGATE")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE AGGREGATE public.agg_name(integer, integer) (
 	SFUNC = public.mysfunc,
@@ -388,7 +428,7 @@ $_$`)
 		})
 		It("prints an aggregate with a specified moving transition function", func() {
 			aggDefinition.MTransitionFunction = 1
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "agg_name(integer, integer)", "AGGREGATE")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE AGGREGATE public.agg_name(integer, integer) (
 	SFUNC = public.mysfunc,
@@ -398,7 +438,7 @@ $_$`)
 		})
 		It("prints an aggregate with a specified moving inverse transition function", func() {
 			aggDefinition.MInverseTransitionFunction = 1
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "agg_name(integer, integer)", "AGGREGATE")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE AGGREGATE public.agg_name(integer, integer) (
 	SFUNC = public.mysfunc,
@@ -408,7 +448,7 @@ $_$`)
 		})
 		It("prints an aggregate with a specified moving state type", func() {
 			aggDefinition.MTransitionDataType = "numeric"
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "agg_name(integer, integer)", "AGGREGATE")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE AGGREGATE public.agg_name(integer, integer) (
 	SFUNC = public.mysfunc,
@@ -418,7 +458,7 @@ $_$`)
 		})
 		It("prints an aggregate with a specified moving transition size", func() {
 			aggDefinition.MTransitionDataSize = 100
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "agg_name(integer, integer)", "AGGREGATE")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE AGGREGATE public.agg_name(integer, integer) (
 	SFUNC = public.mysfunc,
@@ -428,7 +468,7 @@ $_$`)
 		})
 		It("prints an aggregate with a specified moving final function", func() {
 			aggDefinition.MFinalFunction = 3
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "agg_name(integer, integer)", "AGGREGATE")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE AGGREGATE public.agg_name(integer, integer) (
 	SFUNC = public.mysfunc,
@@ -438,7 +478,7 @@ $_$`)
 		})
 		It("prints an aggregate with a moving final function extra attribute", func() {
 			aggDefinition.MFinalFuncExtra = true
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "agg_name(integer, integer)", "AGGREGATE")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE AGGREGATE public.agg_name(integer, integer) (
 	SFUNC = public.mysfunc,
@@ -449,7 +489,7 @@ $_$`)
 		It("prints an aggregate with a moving initial condition", func() {
 			aggDefinition.MInitialValue = "0"
 			aggDefinition.MInitValIsNull = false
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE AGGREGATE public.agg_name(integer, integer) (
 	SFUNC = public.mysfunc,
 	STYPE = integer,
@@ -460,7 +500,7 @@ $_$`)
 			aggDefinition.FinalFunction = 3
 			aggDefinition.SortOperator = "~>~"
 			aggDefinition.SortOperatorSchema = "myschema"
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE AGGREGATE public.agg_name(integer, integer) (
 	SFUNC = public.mysfunc,
 	STYPE = integer,
@@ -475,7 +515,7 @@ $_$`)
 				TransitionDataType: "internal", InitValIsNull: true, MInitValIsNull: true, FinalFuncExtra: true, Hypothetical: true,
 			}
 			aggDefinition = complexAggDefinition
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE AGGREGATE public.agg_hypo_ord(VARIADIC "any" ORDER BY VARIADIC "any") (
 	SFUNC = pg_catalog.ordered_set_transition_multi,
 	STYPE = internal,
@@ -485,7 +525,7 @@ $_$`)
 );`)
 		})
 		It("prints an aggregate with owner, security label and comment", func() {
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, aggMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, aggMetadata)
 			expectedStatements := []string{
 				`CREATE AGGREGATE public.agg_name(integer, integer) (
 	SFUNC = public.mysfunc,
@@ -498,7 +538,7 @@ $_$`)
 		It("prints an aggregate with owner, comment, and no arguments", func() {
 			aggDefinition.Arguments = ""
 			aggDefinition.IdentArgs = ""
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, aggMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggDefinition, funcInfoMap, aggMetadata)
 			expectedStatements := []string{
 				`CREATE AGGREGATE public.agg_name(*) (
 	SFUNC = public.mysfunc,
@@ -514,64 +554,99 @@ $_$`)
 		emptyMetadata := backup.ObjectMetadata{}
 		It("prints an explicit cast with a function", func() {
 			castDef := backup.Cast{Oid: 1, SourceTypeFQN: "src", TargetTypeFQN: "dst", FunctionSchema: "public", FunctionName: "cast_func", FunctionArgs: "integer, integer", CastContext: "e", CastMethod: "f"}
-			backup.PrintCreateCastStatement(backupfile, toc, castDef, emptyMetadata)
+			testBackup.PrintCreateCastStatement(backupfile, toc, castDef, emptyMetadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "(src AS dst)", "CAST")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE CAST (src AS dst)
 	WITH FUNCTION public.cast_func(integer, integer);`)
 		})
 		It("prints an implicit cast with a function", func() {
 			castDef := backup.Cast{Oid: 1, SourceTypeFQN: "src", TargetTypeFQN: "dst", FunctionSchema: "public", FunctionName: "cast_func", FunctionArgs: "integer, integer", CastContext: "i", CastMethod: "f"}
-			backup.PrintCreateCastStatement(backupfile, toc, castDef, emptyMetadata)
+			testBackup.PrintCreateCastStatement(backupfile, toc, castDef, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE CAST (src AS dst)
 	WITH FUNCTION public.cast_func(integer, integer)
 AS IMPLICIT;`)
 		})
 		It("prints an assignment cast with a function", func() {
 			castDef := backup.Cast{Oid: 1, SourceTypeFQN: "src", TargetTypeFQN: "dst", FunctionSchema: "public", FunctionName: "cast_func", FunctionArgs: "integer, integer", CastContext: "a", CastMethod: "f"}
-			backup.PrintCreateCastStatement(backupfile, toc, castDef, emptyMetadata)
+			testBackup.PrintCreateCastStatement(backupfile, toc, castDef, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE CAST (src AS dst)
 	WITH FUNCTION public.cast_func(integer, integer)
 AS ASSIGNMENT;`)
 		})
 		It("prints an explicit cast without a function", func() {
 			castDef := backup.Cast{Oid: 1, SourceTypeFQN: "src", TargetTypeFQN: "dst", FunctionSchema: "", FunctionName: "", FunctionArgs: "", CastContext: "e", CastMethod: "b"}
-			backup.PrintCreateCastStatement(backupfile, toc, castDef, emptyMetadata)
+			testBackup.PrintCreateCastStatement(backupfile, toc, castDef, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE CAST (src AS dst)
 	WITHOUT FUNCTION;`)
 		})
 		It("prints an implicit cast without a function", func() {
 			castDef := backup.Cast{Oid: 1, SourceTypeFQN: "src", TargetTypeFQN: "dst", FunctionSchema: "", FunctionName: "", FunctionArgs: "", CastContext: "i", CastMethod: "b"}
-			backup.PrintCreateCastStatement(backupfile, toc, castDef, emptyMetadata)
+			testBackup.PrintCreateCastStatement(backupfile, toc, castDef, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE CAST (src AS dst)
 	WITHOUT FUNCTION
 AS IMPLICIT;`)
 		})
 		It("prints an assignment cast without a function", func() {
 			castDef := backup.Cast{Oid: 1, SourceTypeFQN: "src", TargetTypeFQN: "dst", FunctionSchema: "", FunctionName: "", FunctionArgs: "", CastContext: "a", CastMethod: "b"}
-			backup.PrintCreateCastStatement(backupfile, toc, castDef, emptyMetadata)
+			testBackup.PrintCreateCastStatement(backupfile, toc, castDef, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE CAST (src AS dst)
 	WITHOUT FUNCTION
 AS ASSIGNMENT;`)
 		})
 		It("prints an inout cast", func() {
 			castDef := backup.Cast{Oid: 1, SourceTypeFQN: "src", TargetTypeFQN: "dst", FunctionSchema: "", FunctionName: "", FunctionArgs: "", CastContext: "e", CastMethod: "i"}
-			backup.PrintCreateCastStatement(backupfile, toc, castDef, emptyMetadata)
+			testBackup.PrintCreateCastStatement(backupfile, toc, castDef, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE CAST (src AS dst)
 	WITH INOUT;`)
 		})
 		It("prints a cast with a comment", func() {
 			castDef := backup.Cast{Oid: 1, SourceTypeFQN: "src", TargetTypeFQN: "dst", FunctionSchema: "", FunctionName: "", FunctionArgs: "", CastContext: "e", CastMethod: "b"}
 			castMetadata := testutils.DefaultMetadata("CAST", false, false, true, false)
-			backup.PrintCreateCastStatement(backupfile, toc, castDef, castMetadata)
+			testBackup.PrintCreateCastStatement(backupfile, toc, castDef, castMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE CAST (src AS dst)
 	WITHOUT FUNCTION;`, "COMMENT ON CAST (src AS dst) IS 'This is a cast comment.';")
 		})
 	})
+	Describe("PrintCreateTransformStatement", func() {
+		emptyMetadata := backup.ObjectMetadata{}
+		transformDef := backup.Transform{Oid: 1, TypeFQN: "public.hstore", Language: "plperl", FromSQLFunc: "public.hstore_to_plperl(internal)", ToSQLFunc: "public.plperl_to_hstore(internal)"}
+		It("prints a transform with both functions", func() {
+			testBackup.PrintCreateTransformStatement(backupfile, toc, transformDef, emptyMetadata)
+			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "FOR public.hstore LANGUAGE plperl", "TRANSFORM")
+			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TRANSFORM FOR public.hstore LANGUAGE plperl (
+	FROM SQL WITH FUNCTION public.hstore_to_plperl(internal),
+	TO SQL WITH FUNCTION public.plperl_to_hstore(internal)
+);`)
+		})
+		It("prints a transform with only a from SQL function", func() {
+			fromOnly := transformDef
+			fromOnly.ToSQLFunc = ""
+			testBackup.PrintCreateTransformStatement(backupfile, toc, fromOnly, emptyMetadata)
+			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TRANSFORM FOR public.hstore LANGUAGE plperl (
+	FROM SQL WITH FUNCTION public.hstore_to_plperl(internal)
+);`)
+		})
+		It("prints a transform with a comment", func() {
+			transformMetadata := testutils.DefaultMetadata("TRANSFORM", false, false, true, false)
+			testBackup.PrintCreateTransformStatement(backupfile, toc, transformDef, transformMetadata)
+			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TRANSFORM FOR public.hstore LANGUAGE plperl (
+	FROM SQL WITH FUNCTION public.hstore_to_plperl(internal),
+	TO SQL WITH FUNCTION public.plperl_to_hstore(internal)
+);`, "COMMENT ON TRANSFORM FOR public.hstore LANGUAGE plperl IS 'This is a transform comment.';")
+		})
+	})
 	Describe("PrintCreateExtensionStatement", func() {
 		emptyMetadataMap := backup.MetadataMap{}
+		It("prints a create extension statement with the installed version", func() {
+			extensionDef := backup.Extension{Oid: 1, Name: "extension1", Schema: "schema1", Version: "1.2"}
+			testBackup.PrintCreateExtensionStatements(backupfile, toc, []backup.Extension{extensionDef}, emptyMetadataMap)
+			testutils.AssertBufferContents(toc.PredataEntries, buffer, `SET search_path=schema1,pg_catalog;
+CREATE EXTENSION IF NOT EXISTS extension1 WITH SCHEMA schema1 VERSION '1.2';
+SET search_path=pg_catalog;`)
+		})
 		It("prints a create extension statement", func() {
 			extensionDef := backup.Extension{Oid: 1, Name: "extension1", Schema: "schema1"}
-			backup.PrintCreateExtensionStatements(backupfile, toc, []backup.Extension{extensionDef}, emptyMetadataMap)
+			testBackup.PrintCreateExtensionStatements(backupfile, toc, []backup.Extension{extensionDef}, emptyMetadataMap)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `SET search_path=schema1,pg_catalog;
 CREATE EXTENSION IF NOT EXISTS extension1 WITH SCHEMA schema1;
 SET search_path=pg_catalog;`)
@@ -579,7 +654,7 @@ SET search_path=pg_catalog;`)
 		It("prints a create extension statement with a comment", func() {
 			extensionDef := backup.Extension{Oid: 1, Name: "extension1", Schema: "schema1"}
 			extensionMetadataMap := testutils.DefaultMetadataMap("EXTENSION", false, false, true, false)
-			backup.PrintCreateExtensionStatements(backupfile, toc, []backup.Extension{extensionDef}, extensionMetadataMap)
+			testBackup.PrintCreateExtensionStatements(backupfile, toc, []backup.Extension{extensionDef}, extensionMetadataMap)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `SET search_path=schema1,pg_catalog;
 CREATE EXTENSION IF NOT EXISTS extension1 WITH SCHEMA schema1;
 SET search_path=pg_catalog;`, "COMMENT ON EXTENSION extension1 IS 'This is an extension comment.';")
@@ -629,14 +704,14 @@ SET search_path=pg_catalog;`, "COMMENT ON EXTENSION extension1 IS 'This is an ex
 		It("prints untrusted language with a handler only", func() {
 			langs := []backup.ProceduralLanguage{plUntrustedHandlerOnly}
 
-			backup.PrintCreateLanguageStatements(backupfile, toc, langs, funcInfoMap, emptyMetadataMap)
+			testBackup.PrintCreateLanguageStatements(backupfile, toc, langs, funcInfoMap, emptyMetadataMap)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "", "", "plpythonu", "LANGUAGE")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, "CREATE PROCEDURAL LANGUAGE plpythonu HANDLER pg_catalog.plpython_call_handler;", "ALTER FUNCTION pg_catalog.plpython_call_handler() OWNER TO testrole;")
 		})
 		It("prints trusted language with handler, inline, and validator", func() {
 			langs := []backup.ProceduralLanguage{plAllFields}
 
-			backup.PrintCreateLanguageStatements(backupfile, toc, langs, funcInfoMap, emptyMetadataMap)
+			testBackup.PrintCreateLanguageStatements(backupfile, toc, langs, funcInfoMap, emptyMetadataMap)
 			expectedStatements := []string{
 				"CREATE TRUSTED PROCEDURAL LANGUAGE plperl HANDLER pg_catalog.plperl_call_handler INLINE pg_catalog.plperl_inline_handler VALIDATOR pg_catalog.plperl_validator;",
 				`ALTER FUNCTION pg_catalog.plperl_call_handler() OWNER TO testrole;
@@ -648,7 +723,7 @@ ALTER FUNCTION pg_catalog.plperl_validator(oid) OWNER TO testrole;`,
 		It("prints multiple create language statements", func() {
 			langs := []backup.ProceduralLanguage{plUntrustedHandlerOnly, plAllFields}
 
-			backup.PrintCreateLanguageStatements(backupfile, toc, langs, funcInfoMap, emptyMetadataMap)
+			testBackup.PrintCreateLanguageStatements(backupfile, toc, langs, funcInfoMap, emptyMetadataMap)
 			expectedStatements := []string{
 				"CREATE PROCEDURAL LANGUAGE plpythonu HANDLER pg_catalog.plpython_call_handler;",
 				"ALTER FUNCTION pg_catalog.plpython_call_handler() OWNER TO testrole;",
@@ -663,7 +738,7 @@ ALTER FUNCTION pg_catalog.plperl_validator(oid) OWNER TO testrole;`,
 			langs := []backup.ProceduralLanguage{plComment}
 			langMetadataMap := testutils.DefaultMetadataMap("LANGUAGE", true, true, true, true)
 
-			backup.PrintCreateLanguageStatements(backupfile, toc, langs, funcInfoMap, langMetadataMap)
+			testBackup.PrintCreateLanguageStatements(backupfile, toc, langs, funcInfoMap, langMetadataMap)
 			expectedStatements := []string{
 				"CREATE PROCEDURAL LANGUAGE plpythonu HANDLER pg_catalog.plpython_call_handler;",
 				"ALTER FUNCTION pg_catalog.plpython_call_handler() OWNER TO testrole;",
@@ -682,7 +757,7 @@ GRANT ALL ON LANGUAGE plpythonu TO testrole;`,
 
 			langMetadataMap := testutils.DefaultMetadataMap("LANGUAGE", true, true, true, true)
 
-			backup.PrintCreateLanguageStatements(backupfile, toc, langs, funcInfoMap, langMetadataMap)
+			testBackup.PrintCreateLanguageStatements(backupfile, toc, langs, funcInfoMap, langMetadataMap)
 			expectedStatements := []string{
 				"CREATE TRUSTED PROCEDURAL LANGUAGE plperl HANDLER pg_catalog.plperl_call_handler INLINE pg_catalog.plperl_inline_handler VALIDATOR pg_catalog.plperl_validator;",
 				"ALTER FUNCTION pg_catalog.plperl_call_handler() OWNER TO owner%percentage;\nALTER FUNCTION pg_catalog.plperl_inline_handler(internal) OWNER TO owner%percentage;\nALTER FUNCTION pg_catalog.plperl_validator(oid) OWNER TO owner%percentage;",
@@ -710,18 +785,18 @@ GRANT ALL ON LANGUAGE plperl TO testrole;`,
 
 		It("prints a non-default conversion", func() {
 			conversions := []backup.Conversion{convOne}
-			backup.PrintCreateConversionStatements(backupfile, toc, conversions, metadataMap)
+			testBackup.PrintCreateConversionStatements(backupfile, toc, conversions, metadataMap)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "conv_one", "CONVERSION")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE CONVERSION public.conv_one FOR 'UTF8' TO 'LATIN1' FROM public.converter;`)
 		})
 		It("prints a default conversion", func() {
 			conversions := []backup.Conversion{convTwo}
-			backup.PrintCreateConversionStatements(backupfile, toc, conversions, metadataMap)
+			testBackup.PrintCreateConversionStatements(backupfile, toc, conversions, metadataMap)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE DEFAULT CONVERSION public.conv_two FOR 'UTF8' TO 'LATIN1' FROM public.converter;`)
 		})
 		It("prints multiple create conversion statements", func() {
 			conversions := []backup.Conversion{convOne, convTwo}
-			backup.PrintCreateConversionStatements(backupfile, toc, conversions, metadataMap)
+			testBackup.PrintCreateConversionStatements(backupfile, toc, conversions, metadataMap)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer,
 				`CREATE CONVERSION public.conv_one FOR 'UTF8' TO 'LATIN1' FROM public.converter;`,
 				`CREATE DEFAULT CONVERSION public.conv_two FOR 'UTF8' TO 'LATIN1' FROM public.converter;`)
@@ -729,7 +804,7 @@ GRANT ALL ON LANGUAGE plperl TO testrole;`,
 		It("prints a conversion with an owner and a comment", func() {
 			conversions := []backup.Conversion{convOne}
 			metadataMap = testutils.DefaultMetadataMap("CONVERSION", false, true, true, false)
-			backup.PrintCreateConversionStatements(backupfile, toc, conversions, metadataMap)
+			testBackup.PrintCreateConversionStatements(backupfile, toc, conversions, metadataMap)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, "CREATE CONVERSION public.conv_one FOR 'UTF8' TO 'LATIN1' FROM public.converter;",
 				"COMMENT ON CONVERSION public.conv_one IS 'This is a conversion comment.';",
 				"ALTER CONVERSION public.conv_one OWNER TO testrole;")
@@ -742,34 +817,34 @@ GRANT ALL ON LANGUAGE plperl TO testrole;`,
 		}
 		It("prints a basic foreign data wrapper", func() {
 			foreignDataWrapper := backup.ForeignDataWrapper{Oid: 1, Name: "foreigndata"}
-			backup.PrintCreateForeignDataWrapperStatement(backupfile, toc, foreignDataWrapper, funcInfoMap, backup.ObjectMetadata{})
+			testBackup.PrintCreateForeignDataWrapperStatement(backupfile, toc, foreignDataWrapper, funcInfoMap, backup.ObjectMetadata{})
 			testutils.ExpectEntry(toc.PredataEntries, 0, "", "", "foreigndata", "FOREIGN DATA WRAPPER")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE FOREIGN DATA WRAPPER foreigndata;`)
 		})
 		It("prints a foreign data wrapper with a handler", func() {
 			foreignDataWrapper := backup.ForeignDataWrapper{Name: "foreigndata", Handler: 1}
-			backup.PrintCreateForeignDataWrapperStatement(backupfile, toc, foreignDataWrapper, funcInfoMap, backup.ObjectMetadata{})
+			testBackup.PrintCreateForeignDataWrapperStatement(backupfile, toc, foreignDataWrapper, funcInfoMap, backup.ObjectMetadata{})
 			testutils.ExpectEntry(toc.PredataEntries, 0, "", "", "foreigndata", "FOREIGN DATA WRAPPER")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE FOREIGN DATA WRAPPER foreigndata
 	HANDLER pg_catalog.postgresql_fdw_handler;`)
 		})
 		It("prints a foreign data wrapper with a validator", func() {
 			foreignDataWrapper := backup.ForeignDataWrapper{Name: "foreigndata", Validator: 2}
-			backup.PrintCreateForeignDataWrapperStatement(backupfile, toc, foreignDataWrapper, funcInfoMap, backup.ObjectMetadata{})
+			testBackup.PrintCreateForeignDataWrapperStatement(backupfile, toc, foreignDataWrapper, funcInfoMap, backup.ObjectMetadata{})
 			testutils.ExpectEntry(toc.PredataEntries, 0, "", "", "foreigndata", "FOREIGN DATA WRAPPER")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE FOREIGN DATA WRAPPER foreigndata
 	VALIDATOR pg_catalog.postgresql_fdw_validator;`)
 		})
 		It("prints a foreign data wrapper with one option", func() {
 			foreignDataWrapper := backup.ForeignDataWrapper{Name: "foreigndata", Options: "debug 'true'"}
-			backup.PrintCreateForeignDataWrapperStatement(backupfile, toc, foreignDataWrapper, funcInfoMap, backup.ObjectMetadata{})
+			testBackup.PrintCreateForeignDataWrapperStatement(backupfile, toc, foreignDataWrapper, funcInfoMap, backup.ObjectMetadata{})
 			testutils.ExpectEntry(toc.PredataEntries, 0, "", "", "foreigndata", "FOREIGN DATA WRAPPER")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE FOREIGN DATA WRAPPER foreigndata
 	OPTIONS (debug 'true');`)
 		})
 		It("prints a foreign data wrapper with two options", func() {
 			foreignDataWrapper := backup.ForeignDataWrapper{Name: "foreigndata", Options: "debug 'true', host 'localhost'"}
-			backup.PrintCreateForeignDataWrapperStatement(backupfile, toc, foreignDataWrapper, funcInfoMap, backup.ObjectMetadata{})
+			testBackup.PrintCreateForeignDataWrapperStatement(backupfile, toc, foreignDataWrapper, funcInfoMap, backup.ObjectMetadata{})
 			testutils.ExpectEntry(toc.PredataEntries, 0, "", "", "foreigndata", "FOREIGN DATA WRAPPER")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE FOREIGN DATA WRAPPER foreigndata
 	OPTIONS (debug 'true', host 'localhost');`)
@@ -778,14 +853,14 @@ GRANT ALL ON LANGUAGE plperl TO testrole;`,
 	Describe("PrintCreateServerStatement", func() {
 		It("prints a basic foreign server", func() {
 			foreignServer := backup.ForeignServer{Oid: 1, Name: "foreignserver", ForeignDataWrapper: "foreignwrapper"}
-			backup.PrintCreateServerStatement(backupfile, toc, foreignServer, backup.ObjectMetadata{})
+			testBackup.PrintCreateServerStatement(backupfile, toc, foreignServer, backup.ObjectMetadata{})
 			testutils.ExpectEntry(toc.PredataEntries, 0, "", "", "foreignserver", "FOREIGN SERVER")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE SERVER foreignserver
 	FOREIGN DATA WRAPPER foreignwrapper;`)
 		})
 		It("prints a foreign server with one option", func() {
 			foreignServer := backup.ForeignServer{Oid: 1, Name: "foreignserver", ForeignDataWrapper: "foreignwrapper", Options: "host 'localhost'"}
-			backup.PrintCreateServerStatement(backupfile, toc, foreignServer, backup.ObjectMetadata{})
+			testBackup.PrintCreateServerStatement(backupfile, toc, foreignServer, backup.ObjectMetadata{})
 			testutils.ExpectEntry(toc.PredataEntries, 0, "", "", "foreignserver", "FOREIGN SERVER")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE SERVER foreignserver
 	FOREIGN DATA WRAPPER foreignwrapper
@@ -793,7 +868,7 @@ GRANT ALL ON LANGUAGE plperl TO testrole;`,
 		})
 		It("prints a foreign server with two options", func() {
 			foreignServer := backup.ForeignServer{Oid: 1, Name: "foreignserver", ForeignDataWrapper: "foreignwrapper", Options: "host 'localhost', dbname 'testdb'"}
-			backup.PrintCreateServerStatement(backupfile, toc, foreignServer, backup.ObjectMetadata{})
+			testBackup.PrintCreateServerStatement(backupfile, toc, foreignServer, backup.ObjectMetadata{})
 			testutils.ExpectEntry(toc.PredataEntries, 0, "", "", "foreignserver", "FOREIGN SERVER")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE SERVER foreignserver
 	FOREIGN DATA WRAPPER foreignwrapper
@@ -801,7 +876,7 @@ GRANT ALL ON LANGUAGE plperl TO testrole;`,
 		})
 		It("prints a foreign server with type and version", func() {
 			foreignServer := backup.ForeignServer{Oid: 1, Name: "foreignserver", Type: "server type", Version: "server version", ForeignDataWrapper: "foreignwrapper"}
-			backup.PrintCreateServerStatement(backupfile, toc, foreignServer, backup.ObjectMetadata{})
+			testBackup.PrintCreateServerStatement(backupfile, toc, foreignServer, backup.ObjectMetadata{})
 			testutils.ExpectEntry(toc.PredataEntries, 0, "", "", "foreignserver", "FOREIGN SERVER")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE SERVER foreignserver
 	TYPE 'server type'
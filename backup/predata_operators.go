@@ -14,7 +14,7 @@ import (
 	"github.com/greenplum-db/gpbackup/utils"
 )
 
-func PrintCreateOperatorStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, operator Operator, operatorMetadata ObjectMetadata) {
+func (b *Backup) PrintCreateOperatorStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, operator Operator, operatorMetadata ObjectMetadata) {
 	start := metadataFile.ByteCount
 	optionalFields := make([]string, 0)
 	var leftArg string
@@ -54,25 +54,54 @@ CREATE OPERATOR %s.%s (
 
 	section, entry := operator.GetMetadataEntry()
 	toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-	PrintObjectMetadata(metadataFile, toc, operatorMetadata, operator, "")
+	b.PrintObjectMetadata(metadataFile, toc, operatorMetadata, operator, "")
 }
 
 /*
  * Operator families are not supported in GPDB 4.3, so this function
  * is not used in a 4.3 backup.
  */
-func PrintCreateOperatorFamilyStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, operatorFamilies []OperatorFamily, operatorFamilyMetadata MetadataMap) {
+func (b *Backup) PrintCreateOperatorFamilyStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, operatorFamilies []OperatorFamily, operatorFamilyMetadata MetadataMap) {
 	for _, operatorFamily := range operatorFamilies {
+		section, entry := operatorFamily.GetMetadataEntry()
+		metadataFile.MustPrintEntry(toc, section, entry, "\n\nCREATE OPERATOR FAMILY %s;", operatorFamily.FQN())
+		b.PrintObjectMetadata(metadataFile, toc, operatorFamilyMetadata[operatorFamily.GetUniqueID()], operatorFamily, "")
+	}
+}
+
+/*
+ * Operator family members are grouped into one ALTER OPERATOR FAMILY statement
+ * per family.  This function expects members of the same family to be adjacent
+ * in the slice, as GetOperatorFamilyMembers returns them.
+ */
+func PrintAlterOperatorFamilyStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, members []OperatorFamilyMember) {
+	for i := 0; i < len(members); {
+		family := members[i]
+		memberClauses := make([]string, 0)
+		for ; i < len(members) && members[i].FamilyFQN() == family.FamilyFQN(); i++ {
+			member := members[i]
+			if member.MemberType == "OPERATOR" {
+				opStr := fmt.Sprintf("OPERATOR %d %s", member.Number, member.Member)
+				if member.Recheck {
+					opStr += " RECHECK"
+				}
+				if member.OrderByFamily != "" {
+					opStr += fmt.Sprintf(" FOR ORDER BY %s", member.OrderByFamily)
+				}
+				memberClauses = append(memberClauses, opStr)
+			} else {
+				memberClauses = append(memberClauses, fmt.Sprintf("FUNCTION %d (%s, %s) %s", member.Number, member.LeftType, member.RightType, member.Member))
+			}
+		}
 		start := metadataFile.ByteCount
-		metadataFile.MustPrintf("\n\nCREATE OPERATOR FAMILY %s;", operatorFamily.FQN())
+		metadataFile.MustPrintf("\n\nALTER OPERATOR FAMILY %s ADD\n\t%s;", family.FamilyFQN(), strings.Join(memberClauses, ",\n\t"))
 
-		section, entry := operatorFamily.GetMetadataEntry()
-		toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-		PrintObjectMetadata(metadataFile, toc, operatorFamilyMetadata[operatorFamily.GetUniqueID()], operatorFamily, "")
+		entry := utils.MetadataEntry{Schema: family.FamilySchema, Name: family.FamilyName, ObjectType: "OPERATOR FAMILY MEMBERS"}
+		toc.AddMetadataEntry("predata", entry, start, metadataFile.ByteCount)
 	}
 }
 
-func PrintCreateOperatorClassStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, operatorClass OperatorClass, operatorClassMetadata ObjectMetadata) {
+func (b *Backup) PrintCreateOperatorClassStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, operatorClass OperatorClass, operatorClassMetadata ObjectMetadata) {
 	start := metadataFile.ByteCount
 	metadataFile.MustPrintf("\n\nCREATE OPERATOR CLASS %s.%s", operatorClass.Schema, operatorClass.Name)
 	forTypeStr := ""
@@ -118,5 +147,5 @@ func PrintCreateOperatorClassStatement(metadataFile *utils.FileWithByteCount, to
 
 	section, entry := operatorClass.GetMetadataEntry()
 	toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-	PrintObjectMetadata(metadataFile, toc, operatorClassMetadata, operatorClass, "")
+	b.PrintObjectMetadata(metadataFile, toc, operatorClassMetadata, operatorClass, "")
 }
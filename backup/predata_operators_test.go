@@ -22,7 +22,7 @@ var _ = Describe("backup/predata_operators tests", func() {
 			operator = backup.Operator{Oid: 0, Schema: "public", Name: "##", Procedure: "public.path_inter", LeftArgType: "public.path", RightArgType: `public."PATH"`, CommutatorOp: "0", NegatorOp: "0", RestrictFunction: "-", JoinFunction: "-", CanHash: false, CanMerge: false}
 		})
 		It("prints a basic operator", func() {
-			backup.PrintCreateOperatorStatement(backupfile, toc, operator, emptyMetadata)
+			testBackup.PrintCreateOperatorStatement(backupfile, toc, operator, emptyMetadata)
 
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "##", "OPERATOR")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE OPERATOR public.## (
@@ -34,7 +34,7 @@ var _ = Describe("backup/predata_operators tests", func() {
 		It("prints a full-featured operator with a comment and owner", func() {
 			complexOperator := backup.Operator{Oid: 1, Schema: "testschema", Name: "##", Procedure: "public.path_inter", LeftArgType: "public.path", RightArgType: "public.path", CommutatorOp: "testschema.##", NegatorOp: "testschema.###", RestrictFunction: "eqsel(internal,oid,internal,integer)", JoinFunction: "eqjoinsel(internal,oid,internal,smallint)", CanHash: true, CanMerge: true}
 
-			backup.PrintCreateOperatorStatement(backupfile, toc, complexOperator, operatorMetadata)
+			testBackup.PrintCreateOperatorStatement(backupfile, toc, complexOperator, operatorMetadata)
 
 			expectedStatements := []string{
 				`CREATE OPERATOR testschema.## (
@@ -55,7 +55,7 @@ var _ = Describe("backup/predata_operators tests", func() {
 		It("prints an operator with only a left argument", func() {
 			operator.RightArgType = "-"
 
-			backup.PrintCreateOperatorStatement(backupfile, toc, operator, emptyMetadata)
+			testBackup.PrintCreateOperatorStatement(backupfile, toc, operator, emptyMetadata)
 
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE OPERATOR public.## (
 	PROCEDURE = public.path_inter,
@@ -65,7 +65,7 @@ var _ = Describe("backup/predata_operators tests", func() {
 		It("prints an operator with only a right argument", func() {
 			operator.LeftArgType = "-"
 
-			backup.PrintCreateOperatorStatement(backupfile, toc, operator, emptyMetadata)
+			testBackup.PrintCreateOperatorStatement(backupfile, toc, operator, emptyMetadata)
 
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE OPERATOR public.## (
 	PROCEDURE = public.path_inter,
@@ -77,7 +77,7 @@ var _ = Describe("backup/predata_operators tests", func() {
 		It("prints a basic operator family", func() {
 			operatorFamily := backup.OperatorFamily{Oid: 0, Schema: "public", Name: "testfam", IndexMethod: "hash"}
 
-			backup.PrintCreateOperatorFamilyStatements(backupfile, toc, []backup.OperatorFamily{operatorFamily}, backup.MetadataMap{})
+			testBackup.PrintCreateOperatorFamilyStatements(backupfile, toc, []backup.OperatorFamily{operatorFamily}, backup.MetadataMap{})
 
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "testfam", "OPERATOR FAMILY")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE OPERATOR FAMILY public.testfam USING hash;`)
@@ -87,7 +87,7 @@ var _ = Describe("backup/predata_operators tests", func() {
 
 			metadataMap := testutils.DefaultMetadataMap("OPERATOR FAMILY", false, true, true, false)
 
-			backup.PrintCreateOperatorFamilyStatements(backupfile, toc, []backup.OperatorFamily{operatorFamily}, metadataMap)
+			testBackup.PrintCreateOperatorFamilyStatements(backupfile, toc, []backup.OperatorFamily{operatorFamily}, metadataMap)
 
 			expectedStatements := []string{"CREATE OPERATOR FAMILY public.testfam USING hash;",
 				"COMMENT ON OPERATOR FAMILY public.testfam USING hash IS 'This is an operator family comment.';",
@@ -95,6 +95,35 @@ var _ = Describe("backup/predata_operators tests", func() {
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, expectedStatements...)
 		})
 	})
+	Describe("PrintAlterOperatorFamilyStatements", func() {
+		It("prints a single ALTER statement for the members of an operator family", func() {
+			members := []backup.OperatorFamilyMember{
+				{FamilySchema: "public", FamilyName: "testfam", IndexMethod: "btree", MemberType: "OPERATOR", Number: 1, Member: "<(integer,bigint)", LeftType: "integer", RightType: "bigint"},
+				{FamilySchema: "public", FamilyName: "testfam", IndexMethod: "btree", MemberType: "FUNCTION", Number: 1, Member: "btint48cmp(integer,bigint)", LeftType: "integer", RightType: "bigint"},
+			}
+
+			backup.PrintAlterOperatorFamilyStatements(backupfile, toc, members)
+
+			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "testfam", "OPERATOR FAMILY MEMBERS")
+			testutils.AssertBufferContents(toc.PredataEntries, buffer, `ALTER OPERATOR FAMILY public.testfam USING btree ADD
+	OPERATOR 1 <(integer,bigint),
+	FUNCTION 1 (integer, bigint) btint48cmp(integer,bigint);`)
+		})
+		It("prints a separate ALTER statement for each operator family", func() {
+			members := []backup.OperatorFamilyMember{
+				{FamilySchema: "public", FamilyName: "testfam", IndexMethod: "btree", MemberType: "OPERATOR", Number: 1, Member: "<(integer,bigint)", Recheck: true},
+				{FamilySchema: "public", FamilyName: "testfam", IndexMethod: "gist", MemberType: "OPERATOR", Number: 15, Member: "<->(point,point)", OrderByFamily: "pg_catalog.float_ops"},
+			}
+
+			backup.PrintAlterOperatorFamilyStatements(backupfile, toc, members)
+
+			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "testfam", "OPERATOR FAMILY MEMBERS")
+			testutils.ExpectEntry(toc.PredataEntries, 1, "public", "", "testfam", "OPERATOR FAMILY MEMBERS")
+			testutils.AssertBufferContents(toc.PredataEntries, buffer, `ALTER OPERATOR FAMILY public.testfam USING btree ADD
+	OPERATOR 1 <(integer,bigint) RECHECK;`, `ALTER OPERATOR FAMILY public.testfam USING gist ADD
+	OPERATOR 15 <->(point,point) FOR ORDER BY pg_catalog.float_ops;`)
+		})
+	})
 	Describe("PrintCreateOperatorClassStatement", func() {
 		var (
 			operatorClass backup.OperatorClass
@@ -108,7 +137,7 @@ var _ = Describe("backup/predata_operators tests", func() {
 			function1 = backup.OperatorClassFunction{ClassOid: 0, SupportNumber: 1, FunctionName: "abs(integer)"}
 		})
 		It("prints a basic operator class", func() {
-			backup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, emptyMetadata)
+			testBackup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, emptyMetadata)
 
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "testclass", "OPERATOR CLASS")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE OPERATOR CLASS public.testclass
@@ -119,7 +148,7 @@ var _ = Describe("backup/predata_operators tests", func() {
 			operatorClass.FamilyName = "testfam"
 			operatorClass.Default = true
 
-			backup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, emptyMetadata)
+			testBackup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, emptyMetadata)
 
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE OPERATOR CLASS public.testclass
 	DEFAULT FOR TYPE uuid USING hash FAMILY public.testfam AS
@@ -130,7 +159,7 @@ var _ = Describe("backup/predata_operators tests", func() {
 			operatorClass.FamilySchema = `"Schema2"`
 			operatorClass.FamilyName = "testfam"
 
-			backup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, emptyMetadata)
+			testBackup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, emptyMetadata)
 
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE OPERATOR CLASS schema1.testclass
 	FOR TYPE uuid USING hash FAMILY "Schema2".testfam AS
@@ -139,7 +168,7 @@ var _ = Describe("backup/predata_operators tests", func() {
 		It("prints an operator class with an operator", func() {
 			operatorClass.Operators = []backup.OperatorClassOperator{operator1}
 
-			backup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, emptyMetadata)
+			testBackup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, emptyMetadata)
 
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE OPERATOR CLASS public.testclass
 	FOR TYPE uuid USING hash AS
@@ -149,7 +178,7 @@ var _ = Describe("backup/predata_operators tests", func() {
 			operator2 := backup.OperatorClassOperator{ClassOid: 0, StrategyNumber: 2, Operator: ">(uuid,uuid)", Recheck: true}
 			operatorClass.Operators = []backup.OperatorClassOperator{operator1, operator2}
 
-			backup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, emptyMetadata)
+			testBackup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, emptyMetadata)
 
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE OPERATOR CLASS public.testclass
 	FOR TYPE uuid USING hash AS
@@ -160,7 +189,7 @@ var _ = Describe("backup/predata_operators tests", func() {
 			operator1.OrderByFamily = "schema.family_name"
 			operatorClass.Operators = []backup.OperatorClassOperator{operator1}
 
-			backup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, emptyMetadata)
+			testBackup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, emptyMetadata)
 
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE OPERATOR CLASS public.testclass
 	FOR TYPE uuid USING hash AS
@@ -169,7 +198,7 @@ var _ = Describe("backup/predata_operators tests", func() {
 		It("prints an operator class with a function", func() {
 			operatorClass.Functions = []backup.OperatorClassFunction{function1}
 
-			backup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, emptyMetadata)
+			testBackup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, emptyMetadata)
 
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE OPERATOR CLASS public.testclass
 	FOR TYPE uuid USING hash AS
@@ -180,7 +209,7 @@ var _ = Describe("backup/predata_operators tests", func() {
 			function1.RightType = "text"
 			operatorClass.Functions = []backup.OperatorClassFunction{function1}
 
-			backup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, emptyMetadata)
+			testBackup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, emptyMetadata)
 
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE OPERATOR CLASS public.testclass
 	FOR TYPE uuid USING hash AS
@@ -190,7 +219,7 @@ var _ = Describe("backup/predata_operators tests", func() {
 			operatorClass.Functions = []backup.OperatorClassFunction{function1}
 
 			objMetadata := testutils.DefaultMetadata("OPERATOR CLASS", false, true, true, false)
-			backup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, objMetadata)
+			testBackup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, objMetadata)
 
 			expectedStatements := []string{
 				`CREATE OPERATOR CLASS public.testclass
@@ -14,6 +14,61 @@ import (
 	"github.com/greenplum-db/gpbackup/utils"
 )
 
+/*
+ * Leaf partitions excluded with --exclude-leaf-partition, or not selected with
+ * --include-leaf-partition or a partition key range, are only removed from the
+ * set of tables whose data is backed up.  They are still created as part of
+ * their root partition table's definition, and attached or exchanged into it
+ * as usual, so they are empty after a restore.
+ */
+func FilterExcludedLeafPartitions(dataTables []Table, excludeOids []string) []Table {
+	excludeSet := utils.NewSet(excludeOids)
+	filteredTables := make([]Table, 0, len(dataTables))
+	for _, table := range dataTables {
+		if excludeSet.MatchesFilter(fmt.Sprintf("%d", table.Oid)) {
+			gplog.Verbose("Skipping data backup of excluded leaf partition %s", table.FQN())
+			continue
+		}
+		filteredTables = append(filteredTables, table)
+	}
+	return filteredTables
+}
+
+// Returns the oids of the leaf partitions in relations that are not in selectedOids
+func GetUnselectedLeafPartitionOids(relations []Relation, partTableMap map[uint32]PartitionLevelInfo, selectedOids []string) []string {
+	selectedSet := utils.NewSet(selectedOids)
+	unselectedOids := make([]string, 0)
+	for _, relation := range relations {
+		oid := fmt.Sprintf("%d", relation.Oid)
+		if partTableMap[relation.Oid].Level == "l" && !selectedSet.MatchesFilter(oid) {
+			unselectedOids = append(unselectedOids, oid)
+		}
+	}
+	return unselectedOids
+}
+
+/*
+ * Returns the views that depend, directly or through other views, on
+ * relations that are not in the backup set, mapped to the name of the
+ * relation not in the backup set that each directly depends on.
+ */
+func FindViewsWithExcludedDependencies(dependencies []ViewDependency) map[uint32]string {
+	excludedViews := make(map[uint32]string)
+	for changed := true; changed; {
+		changed = false
+		for _, dep := range dependencies {
+			if _, ok := excludedViews[dep.ViewOid]; ok {
+				continue
+			}
+			if _, referenceExcluded := excludedViews[dep.ReferenceOid]; !dep.ReferenceInBackup || referenceExcluded {
+				excludedViews[dep.ViewOid] = dep.ReferenceName
+				changed = true
+			}
+		}
+	}
+	return excludedViews
+}
+
 /*
  * When leafPartitionData is set, for partition tables we want to print metadata
  * for the parent tables and data for the leaf tables, so we split them into
@@ -22,11 +77,29 @@ import (
  *
  * When the flag is not set, we want to back up both metadata and data for all
  * tables, so both returned arrays contain all tables.
+ *
+ * In GPDB 7, every partition is created with its own CREATE TABLE statement, so
+ * metadata is backed up for all tables, and partitioned tables hold no data of
+ * their own, so data is always backed up from the leaf partitions.
  */
-func SplitTablesByPartitionType(tables []Table, includeList []string) ([]Table, []Table) {
+func (b *Backup) SplitTablesByPartitionType(tables []Table, includeList []string) ([]Table, []Table) {
 	metadataTables := make([]Table, 0)
 	dataTables := make([]Table, 0)
-	if MustGetFlagBool(utils.LEAF_PARTITION_DATA) || len(includeList) > 0 {
+	if b.connectionPool.Version.AtLeast("7") {
+		includeSet := utils.NewSet(includeList)
+		for _, table := range tables {
+			metadataTables = append(metadataTables, table)
+			partType := table.PartitionLevelInfo.Level
+			if partType == "p" || partType == "i" {
+				continue
+			}
+			if len(includeList) == 0 || includeSet.MatchesFilter(table.FQN()) {
+				dataTables = append(dataTables, table)
+			}
+		}
+		return metadataTables, dataTables
+	}
+	if b.MustGetFlagBool(utils.LEAF_PARTITION_DATA) || len(includeList) > 0 {
 		includeSet := utils.NewSet(includeList)
 		for _, table := range tables {
 			if table.IsExternal && table.PartitionLevelInfo.Level == "l" {
@@ -37,7 +110,7 @@ func SplitTablesByPartitionType(tables []Table, includeList []string) ([]Table,
 			if partType != "l" && partType != "i" {
 				metadataTables = append(metadataTables, table)
 			}
-			if MustGetFlagBool(utils.LEAF_PARTITION_DATA) {
+			if b.MustGetFlagBool(utils.LEAF_PARTITION_DATA) {
 				if partType != "p" && partType != "i" {
 					dataTables = append(dataTables, table)
 				}
@@ -80,7 +153,7 @@ func AppendExtPartSuffix(name string) string {
  * the search_path; this will aid in later filtering to include or exclude certain tables during the
  * backup process, and allows customers to copy just the CREATE TABLE block in order to use it directly.
  */
-func PrintCreateTableStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, table Table, tableMetadata ObjectMetadata) {
+func (b *Backup) PrintCreateTableStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, table Table, tableMetadata ObjectMetadata) {
 	start := metadataFile.ByteCount
 	// We use an empty TOC below to keep count of the bytes for testing purposes.
 	if table.IsExternal && table.PartitionLevelInfo.Level != "p" {
@@ -90,7 +163,7 @@ func PrintCreateTableStatement(metadataFile *utils.FileWithByteCount, toc *utils
 	}
 	section, entry := table.GetMetadataEntry()
 	toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-	PrintPostCreateTableStatements(metadataFile, toc, table, tableMetadata)
+	b.PrintPostCreateTableStatements(metadataFile, toc, table, tableMetadata)
 }
 
 func PrintRegularTableCreateStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, table Table) {
@@ -116,6 +189,9 @@ func PrintRegularTableCreateStatement(metadataFile *utils.FileWithByteCount, toc
 		dependencyList := strings.Join(table.Inherits, ", ")
 		metadataFile.MustPrintf("INHERITS (%s) ", dependencyList)
 	}
+	if table.PartitionKeyDef != "" {
+		metadataFile.MustPrintf("PARTITION BY %s ", table.PartitionKeyDef)
+	}
 	if table.ForeignDef != (ForeignTableDefinition{}) {
 		metadataFile.MustPrintf("SERVER %s ", table.ForeignDef.Server)
 		if table.ForeignDef.Options != "" {
@@ -190,16 +266,19 @@ func printAlterColumnStatements(metadataFile *utils.FileWithByteCount, table Tab
  * This function prints additional statements that come after the CREATE TABLE
  * statement for both regular and external tables.
  */
-func PrintPostCreateTableStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, table Table, tableMetadata ObjectMetadata) {
-	PrintObjectMetadata(metadataFile, toc, tableMetadata, table, "")
+func (b *Backup) PrintPostCreateTableStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, table Table, tableMetadata ObjectMetadata) {
+	b.PrintObjectMetadata(metadataFile, toc, tableMetadata, table, "")
 	statements := make([]string, 0)
+	if table.AttachPartition != (AttachPartitionInfo{}) {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE ONLY %s ATTACH PARTITION %s %s;", table.AttachPartition.Parent, table.FQN(), table.AttachPartition.Bound))
+	}
 	for _, att := range table.ColumnDefs {
 		if att.Comment != "" {
 			escapedComment := utils.EscapeSingleQuotes(att.Comment)
 			statements = append(statements, fmt.Sprintf("COMMENT ON COLUMN %s.%s IS '%s';", table.FQN(), att.Name, escapedComment))
 		}
-		if att.Privileges.Valid {
-			columnMetadata := ObjectMetadata{Privileges: getColumnACL(att.Privileges, att.Kind), Owner: tableMetadata.Owner}
+		if att.Privileges.Valid && !b.MustGetFlagBool(utils.NO_PRIVILEGES) {
+			columnMetadata := ObjectMetadata{Privileges: b.getColumnACL(att.Privileges, att.Kind), Owner: tableMetadata.Owner}
 			columnPrivileges := columnMetadata.GetPrivilegesStatements(table.FQN(), "COLUMN", att.Name)
 			statements = append(statements, strings.TrimSpace(columnPrivileges))
 		}
@@ -229,49 +308,78 @@ func PrintPostCreateTableStatements(metadataFile *utils.FileWithByteCount, toc *
  * This function is largely derived from the dumpSequence() function in pg_dump.c.  The values of
  * minVal and maxVal come from SEQ_MINVALUE and SEQ_MAXVALUE, defined in include/commands/sequence.h.
  */
-func PrintCreateSequenceStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, sequences []Sequence, sequenceMetadata MetadataMap) {
-	maxVal := int64(math.MaxInt64)
-	minVal := int64(math.MinInt64)
+func (b *Backup) PrintCreateSequenceStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, sequences []Sequence, sequenceMetadata MetadataMap) {
 	for _, sequence := range sequences {
+		// Identity column sequences are created along with their identity in PrintAlterSequenceStatements
+		if sequence.IdentityType != "" {
+			continue
+		}
 		start := metadataFile.ByteCount
 		metadataFile.MustPrintln("\n\nCREATE SEQUENCE", sequence.FQN())
-		if connectionPool.Version.AtLeast("6") {
-			metadataFile.MustPrintln("\tSTART WITH", sequence.StartVal)
-		} else if !sequence.IsCalled {
-			metadataFile.MustPrintln("\tSTART WITH", sequence.LastVal)
-		}
-		metadataFile.MustPrintln("\tINCREMENT BY", sequence.Increment)
-
-		if !((sequence.MaxVal == maxVal && sequence.Increment > 0) || (sequence.MaxVal == -1 && sequence.Increment < 0)) {
-			metadataFile.MustPrintln("\tMAXVALUE", sequence.MaxVal)
-		} else {
-			metadataFile.MustPrintln("\tNO MAXVALUE")
-		}
-		if !((sequence.MinVal == minVal && sequence.Increment < 0) || (sequence.MinVal == 1 && sequence.Increment > 0)) {
-			metadataFile.MustPrintln("\tMINVALUE", sequence.MinVal)
-		} else {
-			metadataFile.MustPrintln("\tNO MINVALUE")
-		}
-		cycleStr := ""
-		if sequence.IsCycled {
-			cycleStr = "\n\tCYCLE"
-		}
-		metadataFile.MustPrintf("\tCACHE %d%s;", sequence.CacheVal, cycleStr)
-
-		metadataFile.MustPrintf("\n\nSELECT pg_catalog.setval('%s', %d, %v);\n", utils.EscapeSingleQuotes(sequence.FQN()), sequence.LastVal, sequence.IsCalled)
+		b.PrintSequenceOptions(metadataFile, sequence)
+		metadataFile.MustPrintf(";")
+		b.PrintSequenceSetval(metadataFile, sequence)
 
 		section, entry := sequence.GetMetadataEntry()
 		toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-		PrintObjectMetadata(metadataFile, toc, sequenceMetadata[sequence.Relation.GetUniqueID()], sequence, "")
+		b.PrintObjectMetadata(metadataFile, toc, sequenceMetadata[sequence.Relation.GetUniqueID()], sequence, "")
+	}
+}
+
+/*
+ * Prints every sequence option after the sequence name, one per line, without
+ * a trailing semicolon, as they are used in both CREATE SEQUENCE statements
+ * and identity column definitions.
+ */
+func (b *Backup) PrintSequenceOptions(metadataFile *utils.FileWithByteCount, sequence Sequence) {
+	maxVal := int64(math.MaxInt64)
+	minVal := int64(math.MinInt64)
+	if sequence.DataType != "" && sequence.DataType != "bigint" {
+		metadataFile.MustPrintln("\tAS", sequence.DataType)
+	}
+	if b.connectionPool.Version.AtLeast("6") {
+		metadataFile.MustPrintln("\tSTART WITH", sequence.StartVal)
+	} else if !sequence.IsCalled {
+		metadataFile.MustPrintln("\tSTART WITH", sequence.LastVal)
+	}
+	metadataFile.MustPrintln("\tINCREMENT BY", sequence.Increment)
+
+	if !((sequence.MaxVal == maxVal && sequence.Increment > 0) || (sequence.MaxVal == -1 && sequence.Increment < 0)) {
+		metadataFile.MustPrintln("\tMAXVALUE", sequence.MaxVal)
+	} else {
+		metadataFile.MustPrintln("\tNO MAXVALUE")
+	}
+	if !((sequence.MinVal == minVal && sequence.Increment < 0) || (sequence.MinVal == 1 && sequence.Increment > 0)) {
+		metadataFile.MustPrintln("\tMINVALUE", sequence.MinVal)
+	} else {
+		metadataFile.MustPrintln("\tNO MINVALUE")
+	}
+	cycleStr := ""
+	if sequence.IsCycled {
+		cycleStr = "\n\tCYCLE"
+	}
+	metadataFile.MustPrintf("\tCACHE %d%s", sequence.CacheVal, cycleStr)
+}
+
+func (b *Backup) PrintSequenceSetval(metadataFile *utils.FileWithByteCount, sequence Sequence) {
+	if !b.MustGetFlagBool(utils.DETERMINISTIC) {
+		metadataFile.MustPrintf("\n\nSELECT pg_catalog.setval('%s', %d, %v);\n", utils.EscapeSingleQuotes(sequence.FQN()), sequence.LastVal, sequence.IsCalled)
 	}
 }
 
-func PrintAlterSequenceStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, sequences []Sequence, sequenceColumnOwners map[string]string) {
+/*
+ * Sequences owned by a column are attached to it with OWNED BY, while the
+ * sequence of an identity column is created by adding the identity to the
+ * column, which is why both happen after all tables are created.
+ */
+func (b *Backup) PrintAlterSequenceStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, sequences []Sequence, sequenceColumnOwners map[string]string) {
 	gplog.Verbose("Writing ALTER SEQUENCE statements to metadata file")
 	for _, sequence := range sequences {
 		seqFQN := sequence.FQN()
 		// owningColumn is quoted when the map is constructed in GetSequenceColumnOwnerMap() and doesn't need to be quoted again
-		if owningColumn, hasColumnOwner := sequenceColumnOwners[seqFQN]; hasColumnOwner {
+		if owningColumn, hasColumnOwner := sequenceColumnOwners[seqFQN]; hasColumnOwner && sequence.IdentityType != "" {
+			b.printIdentityColumnStatement(metadataFile, toc, sequence, owningColumn)
+		} else if hasColumnOwner {
 			start := metadataFile.ByteCount
 			metadataFile.MustPrintf("\n\nALTER SEQUENCE %s OWNED BY %s;\n", seqFQN, owningColumn)
 			//TODO: see if the SEQUENCE OWNER type is being utilized in restore or if it could be SEQUENCE. I think we should be using it for filtering, but aren't
@@ -281,19 +389,36 @@ func PrintAlterSequenceStatements(metadataFile *utils.FileWithByteCount, toc *ut
 	}
 }
 
+func (b *Backup) printIdentityColumnStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, sequence Sequence, owningColumn string) {
+	start := metadataFile.ByteCount
+	columnName := strings.TrimPrefix(owningColumn, sequence.OwningTable+".")
+	generatedStr := "BY DEFAULT"
+	if sequence.IdentityType == "a" {
+		generatedStr = "ALWAYS"
+	}
+	metadataFile.MustPrintf("\n\nALTER TABLE %s ALTER COLUMN %s ADD GENERATED %s AS IDENTITY (\n", sequence.OwningTable, columnName, generatedStr)
+	metadataFile.MustPrintln("\tSEQUENCE NAME", sequence.FQN())
+	b.PrintSequenceOptions(metadataFile, sequence)
+	metadataFile.MustPrintf("\n);")
+	b.PrintSequenceSetval(metadataFile, sequence)
+	tableSchema := strings.SplitN(sequence.OwningTable, ".", 2)[0]
+	entry := utils.MetadataEntry{Schema: tableSchema, Name: sequence.Relation.Name, ObjectType: "IDENTITY", ReferenceObject: sequence.OwningTable}
+	toc.AddMetadataEntry("predata", entry, start, metadataFile.ByteCount)
+}
+
 // A view's column names are automatically factored into it's definition.
-func PrintCreateViewStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, view View, viewMetadata ObjectMetadata) {
+func (b *Backup) PrintCreateViewStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, view View, viewMetadata ObjectMetadata) {
 	start := metadataFile.ByteCount
 	// Option's keyword WITH is expected to be prepended to its options in the SQL statement
 	metadataFile.MustPrintf("\n\nCREATE VIEW %s%s AS %s\n", view.FQN(), view.Options, view.Definition)
 
 	section, entry := view.GetMetadataEntry()
 	toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-	PrintObjectMetadata(metadataFile, toc, viewMetadata, view, "")
+	b.PrintObjectMetadata(metadataFile, toc, viewMetadata, view, "")
 }
 
 // A materialized view's column names are automatically factored into it's definition.
-func PrintCreateMaterializedViewStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, mview MaterializedView, mviewMetadata ObjectMetadata) {
+func (b *Backup) PrintCreateMaterializedViewStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, mview MaterializedView, mviewMetadata ObjectMetadata) {
 	start := metadataFile.ByteCount
 	var tablespaceClause string
 	if mview.Tablespace != "" {
@@ -305,5 +430,5 @@ func PrintCreateMaterializedViewStatement(metadataFile *utils.FileWithByteCount,
 
 	section, entry := mview.GetMetadataEntry()
 	toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-	PrintObjectMetadata(metadataFile, toc, mviewMetadata, mview, "")
+	b.PrintObjectMetadata(metadataFile, toc, mviewMetadata, mview, "")
 }
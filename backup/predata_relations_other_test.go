@@ -31,7 +31,7 @@ var _ = Describe("backup/predata_relations tests", func() {
 
 		It("can print a sequence with all default options", func() {
 			sequences := []backup.Sequence{seqDefault}
-			backup.PrintCreateSequenceStatements(backupfile, toc, sequences, emptySequenceMetadataMap)
+			testBackup.PrintCreateSequenceStatements(backupfile, toc, sequences, emptySequenceMetadataMap)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "seq_name", "SEQUENCE")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE SEQUENCE public.seq_name
 	INCREMENT BY 1
@@ -40,10 +40,28 @@ var _ = Describe("backup/predata_relations tests", func() {
 	CACHE 5;
 
 SELECT pg_catalog.setval('public.seq_name', 7, true);`)
+		})
+		It("does not print a sequence backing an identity column", func() {
+			seqIdentity := seqDefault
+			seqIdentity.IdentityType = "a"
+			testBackup.PrintCreateSequenceStatements(backupfile, toc, []backup.Sequence{seqIdentity}, emptySequenceMetadataMap)
+			Expect(toc.PredataEntries).To(BeEmpty())
+			testhelper.NotExpectRegexp(buffer, `CREATE SEQUENCE`)
+		})
+		It("does not print the sequence value with --deterministic", func() {
+			_ = cmdFlags.Set(utils.DETERMINISTIC, "true")
+			defer cmdFlags.Set(utils.DETERMINISTIC, "false")
+			sequences := []backup.Sequence{seqDefault}
+			testBackup.PrintCreateSequenceStatements(backupfile, toc, sequences, emptySequenceMetadataMap)
+			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE SEQUENCE public.seq_name
+	INCREMENT BY 1
+	NO MAXVALUE
+	NO MINVALUE
+	CACHE 5;`)
 		})
 		It("can print a decreasing sequence", func() {
 			sequences := []backup.Sequence{seqNegIncr}
-			backup.PrintCreateSequenceStatements(backupfile, toc, sequences, emptySequenceMetadataMap)
+			testBackup.PrintCreateSequenceStatements(backupfile, toc, sequences, emptySequenceMetadataMap)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE SEQUENCE public.seq_name
 	INCREMENT BY -1
 	NO MAXVALUE
@@ -54,7 +72,7 @@ SELECT pg_catalog.setval('public.seq_name', 7, true);`)
 		})
 		It("can print an increasing sequence with a maximum value", func() {
 			sequences := []backup.Sequence{seqMaxPos}
-			backup.PrintCreateSequenceStatements(backupfile, toc, sequences, emptySequenceMetadataMap)
+			testBackup.PrintCreateSequenceStatements(backupfile, toc, sequences, emptySequenceMetadataMap)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE SEQUENCE public.seq_name
 	INCREMENT BY 1
 	MAXVALUE 100
@@ -65,7 +83,7 @@ SELECT pg_catalog.setval('public.seq_name', 7, true);`)
 		})
 		It("can print an increasing sequence with a minimum value", func() {
 			sequences := []backup.Sequence{seqMinPos}
-			backup.PrintCreateSequenceStatements(backupfile, toc, sequences, emptySequenceMetadataMap)
+			testBackup.PrintCreateSequenceStatements(backupfile, toc, sequences, emptySequenceMetadataMap)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE SEQUENCE public.seq_name
 	INCREMENT BY 1
 	NO MAXVALUE
@@ -76,7 +94,7 @@ SELECT pg_catalog.setval('public.seq_name', 7, true);`)
 		})
 		It("can print a decreasing sequence with a maximum value", func() {
 			sequences := []backup.Sequence{seqMaxNeg}
-			backup.PrintCreateSequenceStatements(backupfile, toc, sequences, emptySequenceMetadataMap)
+			testBackup.PrintCreateSequenceStatements(backupfile, toc, sequences, emptySequenceMetadataMap)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE SEQUENCE public.seq_name
 	INCREMENT BY -1
 	MAXVALUE -10
@@ -87,7 +105,7 @@ SELECT pg_catalog.setval('public.seq_name', 7, true);`)
 		})
 		It("can print a decreasing sequence with a minimum value", func() {
 			sequences := []backup.Sequence{seqMinNeg}
-			backup.PrintCreateSequenceStatements(backupfile, toc, sequences, emptySequenceMetadataMap)
+			testBackup.PrintCreateSequenceStatements(backupfile, toc, sequences, emptySequenceMetadataMap)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE SEQUENCE public.seq_name
 	INCREMENT BY -1
 	NO MAXVALUE
@@ -98,7 +116,7 @@ SELECT pg_catalog.setval('public.seq_name', 7, true);`)
 		})
 		It("can print a sequence that cycles", func() {
 			sequences := []backup.Sequence{seqCycle}
-			backup.PrintCreateSequenceStatements(backupfile, toc, sequences, emptySequenceMetadataMap)
+			testBackup.PrintCreateSequenceStatements(backupfile, toc, sequences, emptySequenceMetadataMap)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE SEQUENCE public.seq_name
 	INCREMENT BY 1
 	NO MAXVALUE
@@ -110,7 +128,7 @@ SELECT pg_catalog.setval('public.seq_name', 7, true);`)
 		})
 		It("can print a sequence with a start value", func() {
 			sequences := []backup.Sequence{seqStart}
-			backup.PrintCreateSequenceStatements(backupfile, toc, sequences, emptySequenceMetadataMap)
+			testBackup.PrintCreateSequenceStatements(backupfile, toc, sequences, emptySequenceMetadataMap)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE SEQUENCE public.seq_name
 	START WITH 7
 	INCREMENT BY 1
@@ -124,7 +142,7 @@ SELECT pg_catalog.setval('public.seq_name', 7, false);`)
 			baseSequenceWithQuote := backup.Relation{SchemaOid: 0, Oid: 1, Schema: "public", Name: "seq_'name"}
 			seqWithQuote := backup.Sequence{Relation: baseSequenceWithQuote, SequenceDefinition: backup.SequenceDefinition{LastVal: 7, Increment: 1, MaxVal: math.MaxInt64, MinVal: 1, CacheVal: 5, LogCnt: 42, IsCycled: false, IsCalled: true}}
 			sequences := []backup.Sequence{seqWithQuote}
-			backup.PrintCreateSequenceStatements(backupfile, toc, sequences, emptySequenceMetadataMap)
+			testBackup.PrintCreateSequenceStatements(backupfile, toc, sequences, emptySequenceMetadataMap)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE SEQUENCE public.seq_'name
 	INCREMENT BY 1
 	NO MAXVALUE
@@ -140,7 +158,7 @@ SELECT pg_catalog.setval('public.seq_''name', 7, true);`)
 			sequenceMetadata.Privileges[0].Update = false
 			sequenceMetadataMap[seqDefault.GetUniqueID()] = sequenceMetadata
 			sequences := []backup.Sequence{seqDefault}
-			backup.PrintCreateSequenceStatements(backupfile, toc, sequences, sequenceMetadataMap)
+			testBackup.PrintCreateSequenceStatements(backupfile, toc, sequences, sequenceMetadataMap)
 			expectedEntries := []string{`CREATE SEQUENCE public.seq_name
 	INCREMENT BY 1
 	NO MAXVALUE
@@ -163,7 +181,7 @@ GRANT SELECT,USAGE ON SEQUENCE public.seq_name TO testrole;`}
 			sequenceMetadata.Privileges[0].Update = false
 			sequenceMetadataMap[seqDefault.GetUniqueID()] = sequenceMetadata
 			sequences := []backup.Sequence{seqDefault}
-			backup.PrintCreateSequenceStatements(backupfile, toc, sequences, sequenceMetadataMap)
+			testBackup.PrintCreateSequenceStatements(backupfile, toc, sequences, sequenceMetadataMap)
 			expectedEntries := []string{`CREATE SEQUENCE public.seq_name
 	START WITH 0
 	INCREMENT BY 1
@@ -185,7 +203,7 @@ GRANT SELECT,USAGE ON SEQUENCE public.seq_name TO testrole;`,
 			sequenceMetadata := backup.ObjectMetadata{Privileges: []backup.ACL{testutils.DefaultACLWithGrantWithout("testrole", "SEQUENCE", "UPDATE")}}
 			sequenceMetadataMap := backup.MetadataMap{seqDefault.GetUniqueID(): sequenceMetadata}
 			sequences := []backup.Sequence{seqDefault}
-			backup.PrintCreateSequenceStatements(backupfile, toc, sequences, sequenceMetadataMap)
+			testBackup.PrintCreateSequenceStatements(backupfile, toc, sequences, sequenceMetadataMap)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE SEQUENCE public.seq_name
 	INCREMENT BY 1
 	NO MAXVALUE
@@ -207,7 +225,7 @@ GRANT SELECT,USAGE ON SEQUENCE public.seq_name TO testrole WITH GRANT OPTION;`)
 			emptyMetadata = backup.ObjectMetadata{}
 		})
 		It("can print a basic view", func() {
-			backup.PrintCreateViewStatement(backupfile, toc, view, emptyMetadata)
+			testBackup.PrintCreateViewStatement(backupfile, toc, view, emptyMetadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "shamwow", "", "shazam", "VIEW")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer,
 				`CREATE VIEW shamwow.shazam AS SELECT count(*) FROM pg_tables;`)
@@ -217,7 +235,7 @@ GRANT SELECT,USAGE ON SEQUENCE public.seq_name TO testrole WITH GRANT OPTION;`)
 			defer testhelper.SetDBVersion(connectionPool, "5.1.0")
 
 			viewMetadata := testutils.DefaultMetadata("VIEW", true, true, true, false)
-			backup.PrintCreateViewStatement(backupfile, toc, view, viewMetadata)
+			testBackup.PrintCreateViewStatement(backupfile, toc, view, viewMetadata)
 			expectedEntries := []string{"CREATE VIEW shamwow.shazam AS SELECT count(*) FROM pg_tables;",
 				"COMMENT ON VIEW shamwow.shazam IS 'This is a view comment.';",
 				"ALTER TABLE shamwow.shazam OWNER TO testrole;",
@@ -231,7 +249,7 @@ GRANT ALL ON shamwow.shazam TO testrole;`}
 			defer testhelper.SetDBVersion(connectionPool, "5.1.0")
 
 			viewMetadata := testutils.DefaultMetadata("VIEW", true, true, true, true)
-			backup.PrintCreateViewStatement(backupfile, toc, view, viewMetadata)
+			testBackup.PrintCreateViewStatement(backupfile, toc, view, viewMetadata)
 			expectedEntries := []string{"CREATE VIEW shamwow.shazam AS SELECT count(*) FROM pg_tables;",
 				"COMMENT ON VIEW shamwow.shazam IS 'This is a view comment.';",
 				"ALTER VIEW shamwow.shazam OWNER TO testrole;",
@@ -243,7 +261,7 @@ GRANT ALL ON shamwow.shazam TO testrole;`,
 		})
 		It("can print a view with options", func() {
 			view.Options = " WITH (security_barrier=true)"
-			backup.PrintCreateViewStatement(backupfile, toc, view, emptyMetadata)
+			testBackup.PrintCreateViewStatement(backupfile, toc, view, emptyMetadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "shamwow", "", "shazam", "VIEW")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer,
 				`CREATE VIEW shamwow.shazam WITH (security_barrier=true) AS SELECT count(*) FROM pg_tables;`)
@@ -255,24 +273,128 @@ GRANT ALL ON shamwow.shazam TO testrole;`,
 		emptyColumnOwnerMap := make(map[string]string)
 		It("prints nothing for a sequence without an owning column", func() {
 			sequences := []backup.Sequence{seqDefault}
-			backup.PrintAlterSequenceStatements(backupfile, toc, sequences, emptyColumnOwnerMap)
+			testBackup.PrintAlterSequenceStatements(backupfile, toc, sequences, emptyColumnOwnerMap)
 			Expect(toc.PredataEntries).To(BeEmpty())
 			testhelper.NotExpectRegexp(buffer, `ALTER SEQUENCE`)
 		})
 		It("does not write an alter sequence statement for a sequence that is not in the backup", func() {
 			columnOwnerMap := map[string]string{"public.seq_name2": "public.tablename.col_one"}
 			sequences := []backup.Sequence{seqDefault}
-			backup.PrintAlterSequenceStatements(backupfile, toc, sequences, columnOwnerMap)
+			testBackup.PrintAlterSequenceStatements(backupfile, toc, sequences, columnOwnerMap)
 			Expect(toc.PredataEntries).To(BeEmpty())
 			testhelper.NotExpectRegexp(buffer, `ALTER SEQUENCE`)
 		})
 		It("can print an ALTER SEQUENCE statement for a sequence with an owning column", func() {
 			columnOwnerMap := map[string]string{"public.seq_name": "public.tablename.col_one"}
 			sequences := []backup.Sequence{seqDefault}
-			backup.PrintAlterSequenceStatements(backupfile, toc, sequences, columnOwnerMap)
+			testBackup.PrintAlterSequenceStatements(backupfile, toc, sequences, columnOwnerMap)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "seq_name", "SEQUENCE OWNER")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `ALTER SEQUENCE public.seq_name OWNED BY public.tablename.col_one;`)
 		})
+		It("can print an identity column definition for a sequence backing a GENERATED ALWAYS identity column", func() {
+			columnOwnerMap := map[string]string{"public.seq_name": "public.tablename.col_one"}
+			seqIdentity := seqDefault
+			seqIdentity.OwningTable = "public.tablename"
+			seqIdentity.IdentityType = "a"
+			seqIdentity.DataType = "integer"
+			testBackup.PrintAlterSequenceStatements(backupfile, toc, []backup.Sequence{seqIdentity}, columnOwnerMap)
+			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "seq_name", "IDENTITY")
+			testutils.AssertBufferContents(toc.PredataEntries, buffer, `ALTER TABLE public.tablename ALTER COLUMN col_one ADD GENERATED ALWAYS AS IDENTITY (
+	SEQUENCE NAME public.seq_name
+	AS integer
+	INCREMENT BY 1
+	NO MAXVALUE
+	NO MINVALUE
+	CACHE 5
+);
+
+SELECT pg_catalog.setval('public.seq_name', 7, true);`)
+		})
+		It("can print an identity column definition for a sequence backing a GENERATED BY DEFAULT identity column", func() {
+			_ = cmdFlags.Set(utils.DETERMINISTIC, "true")
+			defer cmdFlags.Set(utils.DETERMINISTIC, "false")
+			columnOwnerMap := map[string]string{"public.seq_name": "other.tablename.col_one"}
+			seqIdentity := seqDefault
+			seqIdentity.OwningTable = "other.tablename"
+			seqIdentity.IdentityType = "d"
+			testBackup.PrintAlterSequenceStatements(backupfile, toc, []backup.Sequence{seqIdentity}, columnOwnerMap)
+			testutils.ExpectEntry(toc.PredataEntries, 0, "other", "", "seq_name", "IDENTITY")
+			testutils.AssertBufferContents(toc.PredataEntries, buffer, `ALTER TABLE other.tablename ALTER COLUMN col_one ADD GENERATED BY DEFAULT AS IDENTITY (
+	SEQUENCE NAME public.seq_name
+	INCREMENT BY 1
+	NO MAXVALUE
+	NO MINVALUE
+	CACHE 5
+);`)
+		})
+	})
+	Describe("FilterExcludedLeafPartitions", func() {
+		leafOne := backup.Table{
+			Relation:        backup.Relation{Oid: 3, Schema: "public", Name: "part_parent1_leaf1"},
+			TableDefinition: backup.TableDefinition{PartitionLevelInfo: backup.PartitionLevelInfo{Level: "l"}},
+		}
+		leafTwo := backup.Table{
+			Relation:        backup.Relation{Oid: 4, Schema: "public", Name: "part_parent1_leaf2"},
+			TableDefinition: backup.TableDefinition{PartitionLevelInfo: backup.PartitionLevelInfo{Level: "l"}},
+		}
+		regularTable := backup.Table{
+			Relation:        backup.Relation{Oid: 8, Schema: "public", Name: "test_table"},
+			TableDefinition: backup.TableDefinition{PartitionLevelInfo: backup.PartitionLevelInfo{Level: "n"}},
+		}
+		It("removes excluded leaf partitions from the data tables", func() {
+			dataTables := backup.FilterExcludedLeafPartitions([]backup.Table{leafOne, leafTwo, regularTable}, []string{"4"})
+			Expect(dataTables).To(Equal([]backup.Table{leafOne, regularTable}))
+		})
+		It("returns all data tables if no leaf partitions are excluded", func() {
+			dataTables := backup.FilterExcludedLeafPartitions([]backup.Table{leafOne, leafTwo, regularTable}, []string{})
+			Expect(dataTables).To(Equal([]backup.Table{leafOne, leafTwo, regularTable}))
+		})
+	})
+	Describe("GetUnselectedLeafPartitionOids", func() {
+		relations := []backup.Relation{
+			{Oid: 2, Schema: "public", Name: "part_parent1"},
+			{Oid: 3, Schema: "public", Name: "part_parent1_leaf1"},
+			{Oid: 4, Schema: "public", Name: "part_parent1_leaf2"},
+			{Oid: 8, Schema: "public", Name: "test_table"},
+		}
+		partTableMap := map[uint32]backup.PartitionLevelInfo{
+			2: {Oid: 2, Level: "p"},
+			3: {Oid: 3, Level: "l", RootName: "part_parent1"},
+			4: {Oid: 4, Level: "l", RootName: "part_parent1"},
+		}
+		It("returns the leaf partitions that are not selected", func() {
+			unselectedOids := backup.GetUnselectedLeafPartitionOids(relations, partTableMap, []string{"3"})
+			Expect(unselectedOids).To(Equal([]string{"4"}))
+		})
+		It("returns all leaf partitions if none are selected", func() {
+			unselectedOids := backup.GetUnselectedLeafPartitionOids(relations, partTableMap, []string{})
+			Expect(unselectedOids).To(Equal([]string{"3", "4"}))
+		})
+	})
+	Describe("FindViewsWithExcludedDependencies", func() {
+		It("returns no views if every relation they depend on is in the backup", func() {
+			excludedViews := backup.FindViewsWithExcludedDependencies([]backup.ViewDependency{
+				{ViewOid: 1, ViewName: "public.view1", ReferenceOid: 10, ReferenceName: "public.table1", ReferenceInBackup: true},
+				{ViewOid: 2, ViewName: "public.view2", ReferenceOid: 1, ReferenceName: "public.view1", ReferenceInBackup: true},
+			})
+			Expect(excludedViews).To(BeEmpty())
+		})
+		It("returns views that depend on relations not in the backup", func() {
+			excludedViews := backup.FindViewsWithExcludedDependencies([]backup.ViewDependency{
+				{ViewOid: 1, ViewName: "public.view1", ReferenceOid: 10, ReferenceName: "public.table1", ReferenceInBackup: true},
+				{ViewOid: 1, ViewName: "public.view1", ReferenceOid: 11, ReferenceName: "excluded.table2", ReferenceInBackup: false},
+				{ViewOid: 3, ViewName: "public.view3", ReferenceOid: 10, ReferenceName: "public.table1", ReferenceInBackup: true},
+			})
+			Expect(excludedViews).To(Equal(map[uint32]string{1: "excluded.table2"}))
+		})
+		It("returns views that depend on other excluded views", func() {
+			excludedViews := backup.FindViewsWithExcludedDependencies([]backup.ViewDependency{
+				{ViewOid: 3, ViewName: "public.view3", ReferenceOid: 2, ReferenceName: "public.view2", ReferenceInBackup: true},
+				{ViewOid: 2, ViewName: "public.view2", ReferenceOid: 1, ReferenceName: "public.view1", ReferenceInBackup: true},
+				{ViewOid: 1, ViewName: "public.view1", ReferenceOid: 11, ReferenceName: "excluded.table2", ReferenceInBackup: false},
+			})
+			Expect(excludedViews).To(Equal(map[uint32]string{1: "excluded.table2", 2: "public.view1", 3: "public.view2"}))
+		})
 	})
 	Describe("SplitTablesByPartitionType", func() {
 		var tables []backup.Table
@@ -332,7 +454,7 @@ GRANT ALL ON shamwow.shazam TO testrole;`,
 				includeList = []string{"public.part_parent1", "public.part_parent2_child1", "public.part_parent2_child2", "public.test_table"}
 				_ = cmdFlags.Set(utils.LEAF_PARTITION_DATA, "true")
 
-				metadataTables, dataTables := backup.SplitTablesByPartitionType(tables, includeList)
+				metadataTables, dataTables := testBackup.SplitTablesByPartitionType(tables, includeList)
 
 				Expect(metadataTables).To(Equal(expectedMetadataTables))
 
@@ -351,7 +473,7 @@ GRANT ALL ON shamwow.shazam TO testrole;`,
 			It("gets only parent partitions for metadata and only child partitions in data", func() {
 				_ = cmdFlags.Set(utils.LEAF_PARTITION_DATA, "true")
 				includeList = []string{}
-				metadataTables, dataTables := backup.SplitTablesByPartitionType(tables, includeList)
+				metadataTables, dataTables := testBackup.SplitTablesByPartitionType(tables, includeList)
 
 				Expect(metadataTables).To(Equal(expectedMetadataTables))
 
@@ -370,7 +492,7 @@ GRANT ALL ON shamwow.shazam TO testrole;`,
 			It("gets only parent partitions of included tables for metadata and only included tables for data", func() {
 				_ = cmdFlags.Set(utils.LEAF_PARTITION_DATA, "false")
 				includeList = []string{"public.part_parent1", "public.part_parent2_child1", "public.part_parent2_child2", "public.test_table"}
-				metadataTables, dataTables := backup.SplitTablesByPartitionType(tables, includeList)
+				metadataTables, dataTables := testBackup.SplitTablesByPartitionType(tables, includeList)
 
 				Expect(metadataTables).To(Equal(expectedMetadataTables))
 
@@ -404,7 +526,7 @@ GRANT ALL ON shamwow.shazam TO testrole;`,
 				}
 				_ = cmdFlags.Set(utils.LEAF_PARTITION_DATA, "false")
 				_ = cmdFlags.Set(utils.INCLUDE_RELATION, "")
-				metadataTables, dataTables := backup.SplitTablesByPartitionType(tables, includeList)
+				metadataTables, dataTables := testBackup.SplitTablesByPartitionType(tables, includeList)
 
 				Expect(metadataTables).To(Equal(expectedMetadataTables))
 
@@ -432,7 +554,7 @@ GRANT ALL ON shamwow.shazam TO testrole;`,
 				}
 				_ = cmdFlags.Set(utils.LEAF_PARTITION_DATA, "false")
 				_ = cmdFlags.Set(utils.INCLUDE_RELATION, "")
-				metadataTables, _ := backup.SplitTablesByPartitionType(tables, includeList)
+				metadataTables, _ := testBackup.SplitTablesByPartitionType(tables, includeList)
 
 				expectedTables := []backup.Table{
 					{
@@ -449,6 +571,35 @@ GRANT ALL ON shamwow.shazam TO testrole;`,
 				structmatcher.ExpectStructsToMatch(&expectedTables[1], &metadataTables[1])
 			})
 		})
+		Context("GPDB 7", func() {
+			BeforeEach(func() {
+				testhelper.SetDBVersion(connectionPool, "7.0.0")
+			})
+			It("gets all tables for metadata and only leaf partitions and regular tables for data", func() {
+				includeList = []string{}
+
+				metadataTables, dataTables := testBackup.SplitTablesByPartitionType(tables, includeList)
+
+				Expect(metadataTables).To(Equal(tables))
+				dataTableNames := make([]string, 0)
+				for _, table := range dataTables {
+					dataTableNames = append(dataTableNames, table.FQN())
+				}
+				sort.Strings(dataTableNames)
+				Expect(dataTableNames).To(Equal([]string{"public.part_parent1_child1", "public.part_parent1_child2", "public.part_parent2_child1", "public.part_parent2_child2", "public.test_table"}))
+			})
+			It("gets only included leaf partitions and regular tables for data", func() {
+				includeList = []string{"public.part_parent1", "public.part_parent1_inter1", "public.part_parent1_child1", "public.test_table"}
+
+				_, dataTables := testBackup.SplitTablesByPartitionType(tables, includeList)
+
+				dataTableNames := make([]string, 0)
+				for _, table := range dataTables {
+					dataTableNames = append(dataTableNames, table.FQN())
+				}
+				Expect(dataTableNames).To(Equal([]string{"public.part_parent1_child1", "public.test_table"}))
+			})
+		})
 	})
 	Describe("AppendExtPartSuffix", func() {
 		It("adds a suffix to an unquoted external partition table", func() {
@@ -488,7 +639,7 @@ GRANT ALL ON shamwow.shazam TO testrole;`,
 			emptyMetadata = backup.ObjectMetadata{}
 		})
 		It("can print a basic materialized view", func() {
-			backup.PrintCreateMaterializedViewStatement(backupfile, toc, mview, emptyMetadata)
+			testBackup.PrintCreateMaterializedViewStatement(backupfile, toc, mview, emptyMetadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "schema1", "", "mview1", "MATERIALIZED VIEW")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer,
 				`CREATE MATERIALIZED VIEW schema1.mview1 AS SELECT count(*) FROM pg_tables
@@ -496,7 +647,7 @@ WITH NO DATA;`)
 		})
 		It("can print a view with privileges, an owner, and a comment", func() {
 			mviewMetadata := testutils.DefaultMetadata("MATERIALIZED VIEW", true, true, true, false)
-			backup.PrintCreateMaterializedViewStatement(backupfile, toc, mview, mviewMetadata)
+			testBackup.PrintCreateMaterializedViewStatement(backupfile, toc, mview, mviewMetadata)
 			expectedEntries := []string{`CREATE MATERIALIZED VIEW schema1.mview1 AS SELECT count(*) FROM pg_tables
 WITH NO DATA;`,
 				"COMMENT ON MATERIALIZED VIEW schema1.mview1 IS 'This is a materialized view comment.';",
@@ -509,7 +660,7 @@ GRANT ALL ON schema1.mview1 TO testrole;`}
 		It("can print a materialized view with options and a tablespace", func() {
 			mview.Options = " WITH (security_barrier=true)"
 			mview.Tablespace = "myTablespace"
-			backup.PrintCreateMaterializedViewStatement(backupfile, toc, mview, emptyMetadata)
+			testBackup.PrintCreateMaterializedViewStatement(backupfile, toc, mview, emptyMetadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "schema1", "", "mview1", "MATERIALIZED VIEW")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer,
 				`CREATE MATERIALIZED VIEW schema1.mview1 WITH (security_barrier=true) TABLESPACE myTablespace AS SELECT count(*) FROM pg_tables
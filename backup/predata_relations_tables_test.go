@@ -30,14 +30,14 @@ var _ = Describe("backup/predata_relations tests", func() {
 			tableMetadata := backup.ObjectMetadata{Owner: "testrole"}
 
 			testTable.IsExternal = false
-			backup.PrintCreateTableStatement(backupfile, toc, testTable, tableMetadata)
+			testBackup.PrintCreateTableStatement(backupfile, toc, testTable, tableMetadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "tablename", "TABLE")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TABLE public.tablename (
 ) DISTRIBUTED RANDOMLY;`, "ALTER TABLE public.tablename OWNER TO testrole;")
 		})
 		It("calls PrintExternalTableCreateStatement for an external table", func() {
 			testTable.IsExternal = true
-			backup.PrintCreateTableStatement(backupfile, toc, testTable, noMetadata)
+			testBackup.PrintCreateTableStatement(backupfile, toc, testTable, noMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE READABLE EXTERNAL WEB TABLE public.tablename (
 ) 
 FORMAT 'TEXT'
@@ -451,6 +451,17 @@ SET SUBPARTITION TEMPLATE
 ) INHERITS (public.parent_one, public.parent_two) DISTRIBUTED RANDOMLY;`)
 			})
 		})
+		Context("GPDB 7 Partitioning", func() {
+			It("prints a CREATE TABLE block with a PARTITION BY clause", func() {
+				col := []backup.ColumnDefinition{rowOne}
+				testTable.ColumnDefs = col
+				testTable.PartitionKeyDef = "RANGE (i)"
+				backup.PrintRegularTableCreateStatement(backupfile, toc, testTable)
+				testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TABLE public.tablename (
+	i integer
+) PARTITION BY RANGE (i) DISTRIBUTED RANDOMLY;`)
+			})
+		})
 		Context("Foreign Table", func() {
 			BeforeEach(func() {
 				testTable.DistPolicy = ""
@@ -497,34 +508,39 @@ SET SUBPARTITION TEMPLATE
 
 		It("does not print default replica identity statement", func() {
 			testTable.ReplicaIdentity = "d"
-			backup.PrintPostCreateTableStatements(backupfile, toc, testTable, noMetadata)
+			testBackup.PrintPostCreateTableStatements(backupfile, toc, testTable, noMetadata)
 			testhelper.NotExpectRegexp(buffer, `REPLICA IDENTITY`)
 		})
 		It("does not print index replica identity statement", func() {
 			testTable.ReplicaIdentity = "i"
-			backup.PrintPostCreateTableStatements(backupfile, toc, testTable, noMetadata)
+			testBackup.PrintPostCreateTableStatements(backupfile, toc, testTable, noMetadata)
 			testhelper.NotExpectRegexp(buffer, `REPLICA IDENTITY`)
 		})
 		It("does not print null replica identity statement", func() {
 			testTable.ReplicaIdentity = ""
-			backup.PrintPostCreateTableStatements(backupfile, toc, testTable, noMetadata)
+			testBackup.PrintPostCreateTableStatements(backupfile, toc, testTable, noMetadata)
 			testhelper.NotExpectRegexp(buffer, `REPLICA IDENTITY`)
 		})
 		It("prints replica identity full", func() {
 			testTable.ReplicaIdentity = "f"
-			backup.PrintPostCreateTableStatements(backupfile, toc, testTable, noMetadata)
+			testBackup.PrintPostCreateTableStatements(backupfile, toc, testTable, noMetadata)
 			testhelper.ExpectRegexp(buffer, `ALTER TABLE public.tablename REPLICA IDENTITY FULL;`)
 		})
+		It("prints an ATTACH PARTITION statement for a GPDB 7 partition", func() {
+			testTable.AttachPartition = backup.AttachPartitionInfo{Oid: 1, Parent: "public.parent", Bound: "FOR VALUES FROM (1) TO (10)"}
+			testBackup.PrintPostCreateTableStatements(backupfile, toc, testTable, noMetadata)
+			testhelper.ExpectRegexp(buffer, `ALTER TABLE ONLY public.parent ATTACH PARTITION public.tablename FOR VALUES FROM (1) TO (10);`)
+		})
 		It("prints replica identity nothing", func() {
 			testTable.ReplicaIdentity = "n"
-			backup.PrintPostCreateTableStatements(backupfile, toc, testTable, noMetadata)
+			testBackup.PrintPostCreateTableStatements(backupfile, toc, testTable, noMetadata)
 			testhelper.ExpectRegexp(buffer, `ALTER TABLE public.tablename REPLICA IDENTITY NOTHING;`)
 		})
 		It("prints a block with a table comment", func() {
 			col := []backup.ColumnDefinition{rowOne}
 			testTable.ColumnDefs = col
 			tableMetadata := testutils.DefaultMetadata("TABLE", false, false, true, false)
-			backup.PrintPostCreateTableStatements(backupfile, toc, testTable, tableMetadata)
+			testBackup.PrintPostCreateTableStatements(backupfile, toc, testTable, tableMetadata)
 			testhelper.ExpectRegexp(buffer, `
 
 COMMENT ON TABLE public.tablename IS 'This is a table comment.';`)
@@ -532,7 +548,7 @@ COMMENT ON TABLE public.tablename IS 'This is a table comment.';`)
 		It("prints a block with a single column comment", func() {
 			col := []backup.ColumnDefinition{rowCommentOne}
 			testTable.ColumnDefs = col
-			backup.PrintPostCreateTableStatements(backupfile, toc, testTable, noMetadata)
+			testBackup.PrintPostCreateTableStatements(backupfile, toc, testTable, noMetadata)
 			testhelper.ExpectRegexp(buffer, `
 
 COMMENT ON COLUMN public.tablename.i IS 'This is a column comment.';`)
@@ -542,7 +558,7 @@ COMMENT ON COLUMN public.tablename.i IS 'This is a column comment.';`)
 
 			col := []backup.ColumnDefinition{rowCommentSpecialCharacters}
 			testTable.ColumnDefs = col
-			backup.PrintPostCreateTableStatements(backupfile, toc, testTable, noMetadata)
+			testBackup.PrintPostCreateTableStatements(backupfile, toc, testTable, noMetadata)
 			testhelper.ExpectRegexp(buffer, `
 
 COMMENT ON COLUMN public.tablename.i IS 'This is a ta''ble 1+=;,./\>,<@\\n^comment.';`)
@@ -550,7 +566,7 @@ COMMENT ON COLUMN public.tablename.i IS 'This is a ta''ble 1+=;,./\>,<@\\n^comme
 		It("prints a block with multiple column comments", func() {
 			col := []backup.ColumnDefinition{rowCommentOne, rowCommentTwo}
 			testTable.ColumnDefs = col
-			backup.PrintPostCreateTableStatements(backupfile, toc, testTable, noMetadata)
+			testBackup.PrintPostCreateTableStatements(backupfile, toc, testTable, noMetadata)
 			testhelper.ExpectRegexp(buffer, `
 
 COMMENT ON COLUMN public.tablename.i IS 'This is a column comment.';
@@ -562,7 +578,7 @@ COMMENT ON COLUMN public.tablename.j IS 'This is another column comment.';`)
 			col := []backup.ColumnDefinition{rowOne}
 			testTable.ColumnDefs = col
 			tableMetadata := testutils.DefaultMetadata("TABLE", false, true, false, false)
-			backup.PrintPostCreateTableStatements(backupfile, toc, testTable, tableMetadata)
+			testBackup.PrintPostCreateTableStatements(backupfile, toc, testTable, tableMetadata)
 			testhelper.ExpectRegexp(buffer, `
 
 ALTER TABLE public.tablename OWNER TO testrole;`)
@@ -571,7 +587,7 @@ ALTER TABLE public.tablename OWNER TO testrole;`)
 			col := []backup.ColumnDefinition{rowOne}
 			testTable.ColumnDefs = col
 			tableMetadata := testutils.DefaultMetadata("TABLE", false, false, false, true)
-			backup.PrintPostCreateTableStatements(backupfile, toc, testTable, tableMetadata)
+			testBackup.PrintPostCreateTableStatements(backupfile, toc, testTable, tableMetadata)
 			testhelper.ExpectRegexp(buffer, `
 
 SECURITY LABEL FOR dummy ON TABLE public.tablename IS 'unclassified';`)
@@ -580,7 +596,7 @@ SECURITY LABEL FOR dummy ON TABLE public.tablename IS 'unclassified';`)
 			testTable.ForeignDef = backup.ForeignTableDefinition{Options: "", Server: "fs"}
 			testTable.ReplicaIdentity = "n"
 			tableMetadata := testutils.DefaultMetadata("FOREIGN TABLE", true, true, true, true)
-			backup.PrintPostCreateTableStatements(backupfile, toc, testTable, tableMetadata)
+			testBackup.PrintPostCreateTableStatements(backupfile, toc, testTable, tableMetadata)
 			testhelper.NotExpectRegexp(buffer, `REPLICA IDENTITY`)
 		})
 		It("prints owner, comment, security label, and ACL statements for foreign table", func() {
@@ -588,7 +604,7 @@ SECURITY LABEL FOR dummy ON TABLE public.tablename IS 'unclassified';`)
 			testTable.ColumnDefs = col
 			testTable.ForeignDef = backup.ForeignTableDefinition{Options: "", Server: "fs"}
 			tableMetadata := testutils.DefaultMetadata("FOREIGN TABLE", true, true, true, true)
-			backup.PrintPostCreateTableStatements(backupfile, toc, testTable, tableMetadata)
+			testBackup.PrintPostCreateTableStatements(backupfile, toc, testTable, tableMetadata)
 			testhelper.ExpectRegexp(buffer, `
 
 COMMENT ON FOREIGN TABLE public.tablename IS 'This is a foreign table comment.';
@@ -608,7 +624,7 @@ SECURITY LABEL FOR dummy ON FOREIGN TABLE public.tablename IS 'unclassified';`)
 			col := []backup.ColumnDefinition{rowCommentOne, rowCommentTwo}
 			testTable.ColumnDefs = col
 			tableMetadata := backup.ObjectMetadata{Owner: "testrole", Comment: "This is a table comment."}
-			backup.PrintPostCreateTableStatements(backupfile, toc, testTable, tableMetadata)
+			testBackup.PrintPostCreateTableStatements(backupfile, toc, testTable, tableMetadata)
 			testhelper.ExpectRegexp(buffer, `
 
 COMMENT ON TABLE public.tablename IS 'This is a table comment.';
@@ -628,7 +644,7 @@ COMMENT ON COLUMN public.tablename.j IS 'This is another column comment.';`)
 			col := []backup.ColumnDefinition{privilegesColumnOne, privilegesColumnTwo}
 			testTable.ColumnDefs = col
 			tableMetadata := backup.ObjectMetadata{Owner: "testrole"}
-			backup.PrintPostCreateTableStatements(backupfile, toc, testTable, tableMetadata)
+			testBackup.PrintPostCreateTableStatements(backupfile, toc, testTable, tableMetadata)
 			testhelper.ExpectRegexp(buffer, `
 
 ALTER TABLE public.tablename OWNER TO testrole;
@@ -648,7 +664,7 @@ GRANT ALL (j) ON TABLE public.tablename TO testrole2;`)
 			privilegesColumnTwo := backup.ColumnDefinition{Oid: 1, Num: 2, Name: "j", Type: "character varying(20)", StatTarget: -1, SecurityLabelProvider: "dummy", SecurityLabel: "unclassified"}
 			col := []backup.ColumnDefinition{privilegesColumnOne, privilegesColumnTwo}
 			testTable.ColumnDefs = col
-			backup.PrintPostCreateTableStatements(backupfile, toc, testTable, backup.ObjectMetadata{})
+			testBackup.PrintPostCreateTableStatements(backupfile, toc, testTable, backup.ObjectMetadata{})
 			testhelper.ExpectRegexp(buffer, `
 
 SECURITY LABEL FOR dummy ON COLUMN public.tablename.i IS 'unclassified';
@@ -14,7 +14,7 @@ import (
  * There's no built-in function to generate constraint definitions like there is for other types of
  * metadata, so this function constructs them.
  */
-func PrintConstraintStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, constraints []Constraint, conMetadata MetadataMap) {
+func (b *Backup) PrintConstraintStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, constraints []Constraint, conMetadata MetadataMap) {
 	allConstraints := make([]Constraint, 0)
 	allFkConstraints := make([]Constraint, 0)
 	/*
@@ -46,11 +46,11 @@ func PrintConstraintStatements(metadataFile *utils.FileWithByteCount, toc *utils
 
 		section, entry := constraint.GetMetadataEntry()
 		toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-		PrintObjectMetadata(metadataFile, toc, conMetadata[constraint.GetUniqueID()], constraint, constraint.OwningObject)
+		b.PrintObjectMetadata(metadataFile, toc, conMetadata[constraint.GetUniqueID()], constraint, constraint.OwningObject)
 	}
 }
 
-func PrintCreateSchemaStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, schemas []Schema, schemaMetadata MetadataMap) {
+func (b *Backup) PrintCreateSchemaStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, schemas []Schema, schemaMetadata MetadataMap) {
 	for _, schema := range schemas {
 		start := metadataFile.ByteCount
 		metadataFile.MustPrintln()
@@ -59,6 +59,6 @@ func PrintCreateSchemaStatements(metadataFile *utils.FileWithByteCount, toc *uti
 		}
 		section, entry := schema.GetMetadataEntry()
 		toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-		PrintObjectMetadata(metadataFile, toc, schemaMetadata[schema.GetUniqueID()], schema, "")
+		b.PrintObjectMetadata(metadataFile, toc, schemaMetadata[schema.GetUniqueID()], schema, "")
 	}
 }
@@ -35,7 +35,7 @@ var _ = Describe("backup/predata_shared tests", func() {
 		Context("No constraints", func() {
 			It("doesn't print anything", func() {
 				constraints := make([]backup.Constraint, 0)
-				backup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
+				testBackup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
 				testhelper.NotExpectRegexp(buffer, `CONSTRAINT`)
 			})
 		})
@@ -43,61 +43,61 @@ var _ = Describe("backup/predata_shared tests", func() {
 			It("prints an ADD CONSTRAINT statement for one UNIQUE constraint with a comment", func() {
 				constraints := []backup.Constraint{uniqueOne}
 				constraintMetadataMap := testutils.DefaultMetadataMap("CONSTRAINT", false, false, true, false)
-				backup.PrintConstraintStatements(backupfile, toc, constraints, constraintMetadataMap)
+				testBackup.PrintConstraintStatements(backupfile, toc, constraints, constraintMetadataMap)
 				testutils.ExpectEntry(toc.PostdataEntries, 0, "", "public.tablename", "tablename_i_key", "CONSTRAINT")
 				testutils.AssertBufferContents(toc.PostdataEntries, buffer, "ALTER TABLE ONLY public.tablename ADD CONSTRAINT tablename_i_key UNIQUE (i);", "COMMENT ON CONSTRAINT tablename_i_key ON public.tablename IS 'This is a constraint comment.';")
 			})
 			It("prints an ADD CONSTRAINT statement for one UNIQUE constraint", func() {
 				constraints := []backup.Constraint{uniqueOne}
-				backup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
+				testBackup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
 				testutils.AssertBufferContents(toc.PostdataEntries, buffer, `ALTER TABLE ONLY public.tablename ADD CONSTRAINT tablename_i_key UNIQUE (i);`)
 			})
 			It("prints ADD CONSTRAINT statements for two UNIQUE constraints", func() {
 				constraints := []backup.Constraint{uniqueOne, uniqueTwo}
-				backup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
+				testBackup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
 				testutils.AssertBufferContents(toc.PostdataEntries, buffer,
 					`ALTER TABLE ONLY public.tablename ADD CONSTRAINT tablename_i_key UNIQUE (i);`,
 					`ALTER TABLE ONLY public.tablename ADD CONSTRAINT tablename_j_key UNIQUE (j);`)
 			})
 			It("prints an ADD CONSTRAINT statement for one PRIMARY KEY constraint on one column", func() {
 				constraints := []backup.Constraint{primarySingle}
-				backup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
+				testBackup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
 				testutils.AssertBufferContents(toc.PostdataEntries, buffer, `ALTER TABLE ONLY public.tablename ADD CONSTRAINT tablename_pkey PRIMARY KEY (i);`)
 			})
 			It("prints an ADD CONSTRAINT statement for one composite PRIMARY KEY constraint on two columns", func() {
 				constraints := []backup.Constraint{primaryComposite}
-				backup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
+				testBackup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
 				testutils.AssertBufferContents(toc.PostdataEntries, buffer, `ALTER TABLE ONLY public.tablename ADD CONSTRAINT tablename_pkey PRIMARY KEY (i, j);`)
 			})
 			It("prints an ADD CONSTRAINT statement for one FOREIGN KEY constraint", func() {
 				constraints := []backup.Constraint{foreignOne}
-				backup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
+				testBackup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
 				testutils.AssertBufferContents(toc.PostdataEntries, buffer, `ALTER TABLE ONLY public.tablename ADD CONSTRAINT tablename_i_fkey FOREIGN KEY (i) REFERENCES other_tablename(a);`)
 			})
 			It("prints ADD CONSTRAINT statements for two FOREIGN KEY constraints", func() {
 				constraints := []backup.Constraint{foreignOne, foreignTwo}
-				backup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
+				testBackup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
 				testutils.AssertBufferContents(toc.PostdataEntries, buffer,
 					`ALTER TABLE ONLY public.tablename ADD CONSTRAINT tablename_i_fkey FOREIGN KEY (i) REFERENCES other_tablename(a);`,
 					`ALTER TABLE ONLY public.tablename ADD CONSTRAINT tablename_j_fkey FOREIGN KEY (j) REFERENCES other_tablename(b);`)
 			})
 			It("prints ADD CONSTRAINT statements for one UNIQUE constraint and one FOREIGN KEY constraint", func() {
 				constraints := []backup.Constraint{foreignTwo, uniqueOne}
-				backup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
+				testBackup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
 				testutils.AssertBufferContents(toc.PostdataEntries, buffer,
 					`ALTER TABLE ONLY public.tablename ADD CONSTRAINT tablename_i_key UNIQUE (i);`,
 					`ALTER TABLE ONLY public.tablename ADD CONSTRAINT tablename_j_fkey FOREIGN KEY (j) REFERENCES other_tablename(b);`)
 			})
 			It("prints ADD CONSTRAINT statements for one PRIMARY KEY constraint and one FOREIGN KEY constraint", func() {
 				constraints := []backup.Constraint{foreignTwo, primarySingle}
-				backup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
+				testBackup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
 				testutils.AssertBufferContents(toc.PostdataEntries, buffer,
 					`ALTER TABLE ONLY public.tablename ADD CONSTRAINT tablename_pkey PRIMARY KEY (i);`,
 					`ALTER TABLE ONLY public.tablename ADD CONSTRAINT tablename_j_fkey FOREIGN KEY (j) REFERENCES other_tablename(b);`)
 			})
 			It("prints ADD CONSTRAINT statements for one two-column composite PRIMARY KEY constraint and one FOREIGN KEY constraint", func() {
 				constraints := []backup.Constraint{foreignTwo, primaryComposite}
-				backup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
+				testBackup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
 				testutils.AssertBufferContents(toc.PostdataEntries, buffer,
 					`ALTER TABLE ONLY public.tablename ADD CONSTRAINT tablename_pkey PRIMARY KEY (i, j);`,
 					`ALTER TABLE ONLY public.tablename ADD CONSTRAINT tablename_j_fkey FOREIGN KEY (j) REFERENCES other_tablename(b);`)
@@ -106,21 +106,21 @@ var _ = Describe("backup/predata_shared tests", func() {
 		Context("Constraints involving the same column", func() {
 			It("prints ADD CONSTRAINT statements for one UNIQUE constraint and one FOREIGN KEY constraint", func() {
 				constraints := []backup.Constraint{foreignOne, uniqueOne}
-				backup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
+				testBackup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
 				testutils.AssertBufferContents(toc.PostdataEntries, buffer,
 					`ALTER TABLE ONLY public.tablename ADD CONSTRAINT tablename_i_key UNIQUE (i);`,
 					`ALTER TABLE ONLY public.tablename ADD CONSTRAINT tablename_i_fkey FOREIGN KEY (i) REFERENCES other_tablename(a);`)
 			})
 			It("prints ADD CONSTRAINT statements for one PRIMARY KEY constraint and one FOREIGN KEY constraint", func() {
 				constraints := []backup.Constraint{foreignOne, primarySingle}
-				backup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
+				testBackup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
 				testutils.AssertBufferContents(toc.PostdataEntries, buffer,
 					`ALTER TABLE ONLY public.tablename ADD CONSTRAINT tablename_pkey PRIMARY KEY (i);`,
 					`ALTER TABLE ONLY public.tablename ADD CONSTRAINT tablename_i_fkey FOREIGN KEY (i) REFERENCES other_tablename(a);`)
 			})
 			It("prints ADD CONSTRAINT statements for a two-column composite PRIMARY KEY constraint and one FOREIGN KEY constraint", func() {
 				constraints := []backup.Constraint{foreignOne, primaryComposite}
-				backup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
+				testBackup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
 				testutils.AssertBufferContents(toc.PostdataEntries, buffer,
 					`ALTER TABLE ONLY public.tablename ADD CONSTRAINT tablename_pkey PRIMARY KEY (i, j);`,
 					`ALTER TABLE ONLY public.tablename ADD CONSTRAINT tablename_i_fkey FOREIGN KEY (i) REFERENCES other_tablename(a);`)
@@ -128,19 +128,19 @@ var _ = Describe("backup/predata_shared tests", func() {
 			It("doesn't print an ADD CONSTRAINT statement for domain check constraint", func() {
 				domainCheckConstraint := backup.Constraint{Oid: 0, Name: "check1", ConType: "c", ConDef: "CHECK (VALUE <> 42::numeric)", OwningObject: "public.domain1", IsDomainConstraint: true, IsPartitionParent: false}
 				constraints := []backup.Constraint{domainCheckConstraint}
-				backup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
+				testBackup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
 				testhelper.NotExpectRegexp(buffer, `ALTER DOMAIN`)
 			})
 			It("prints an ADD CONSTRAINT statement for a parent partition table", func() {
 				uniqueOne.IsPartitionParent = true
 				constraints := []backup.Constraint{uniqueOne}
-				backup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
+				testBackup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
 				testutils.AssertBufferContents(toc.PostdataEntries, buffer, `ALTER TABLE public.tablename ADD CONSTRAINT tablename_i_key UNIQUE (i);`)
 			})
 			It("prints an ADD CONSTRAINT [name] CHECK statement without keyword ONLY for a table with descendants (another table inherits it)", func() {
 				checkConstraint := backup.Constraint{Oid: 0, Name: "check1", ConType: "c", ConDef: "CHECK (VALUE <> 42::numeric)", OwningObject: "public.tablename", IsDomainConstraint: false, IsPartitionParent: false, ConIsLocal: true}
 				constraints := []backup.Constraint{checkConstraint}
-				backup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
+				testBackup.PrintConstraintStatements(backupfile, toc, constraints, emptyMetadataMap)
 				testutils.AssertBufferContents(toc.PostdataEntries, buffer, `ALTER TABLE public.tablename ADD CONSTRAINT check1 CHECK (VALUE <> 42::numeric);`)
 			})
 		})
@@ -150,7 +150,7 @@ var _ = Describe("backup/predata_shared tests", func() {
 			schemas := []backup.Schema{{Oid: 0, Name: "schemaname"}}
 			emptyMetadataMap := backup.MetadataMap{}
 
-			backup.PrintCreateSchemaStatements(backupfile, toc, schemas, emptyMetadataMap)
+			testBackup.PrintCreateSchemaStatements(backupfile, toc, schemas, emptyMetadataMap)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "schemaname", "", "schemaname", "SCHEMA")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, "CREATE SCHEMA schemaname;")
 		})
@@ -158,7 +158,7 @@ var _ = Describe("backup/predata_shared tests", func() {
 			schemas := []backup.Schema{{Oid: 1, Name: "schemaname"}}
 			schemaMetadataMap := testutils.DefaultMetadataMap("SCHEMA", true, true, true, true)
 
-			backup.PrintCreateSchemaStatements(backupfile, toc, schemas, schemaMetadataMap)
+			testBackup.PrintCreateSchemaStatements(backupfile, toc, schemas, schemaMetadataMap)
 			expectedStatements := []string{"CREATE SCHEMA schemaname;",
 				"COMMENT ON SCHEMA schemaname IS 'This is a schema comment.';",
 				"ALTER SCHEMA schemaname OWNER TO testrole;",
@@ -16,7 +16,7 @@ import (
 	"github.com/greenplum-db/gpbackup/utils"
 )
 
-func PrintCreateTextSearchParserStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, parser TextSearchParser, parserMetadata ObjectMetadata) {
+func (b *Backup) PrintCreateTextSearchParserStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, parser TextSearchParser, parserMetadata ObjectMetadata) {
 	start := metadataFile.ByteCount
 	metadataFile.MustPrintf("\n\nCREATE TEXT SEARCH PARSER %s (", parser.FQN())
 	metadataFile.MustPrintf("\n\tSTART = %s,", parser.StartFunc)
@@ -30,10 +30,10 @@ func PrintCreateTextSearchParserStatement(metadataFile *utils.FileWithByteCount,
 
 	section, entry := parser.GetMetadataEntry()
 	toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-	PrintObjectMetadata(metadataFile, toc, parserMetadata, parser, "")
+	b.PrintObjectMetadata(metadataFile, toc, parserMetadata, parser, "")
 }
 
-func PrintCreateTextSearchTemplateStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, template TextSearchTemplate, templateMetadata ObjectMetadata) {
+func (b *Backup) PrintCreateTextSearchTemplateStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, template TextSearchTemplate, templateMetadata ObjectMetadata) {
 	start := metadataFile.ByteCount
 	metadataFile.MustPrintf("\n\nCREATE TEXT SEARCH TEMPLATE %s (", template.FQN())
 	if template.InitFunc != "" {
@@ -44,10 +44,10 @@ func PrintCreateTextSearchTemplateStatement(metadataFile *utils.FileWithByteCoun
 
 	section, entry := template.GetMetadataEntry()
 	toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-	PrintObjectMetadata(metadataFile, toc, templateMetadata, template, "")
+	b.PrintObjectMetadata(metadataFile, toc, templateMetadata, template, "")
 }
 
-func PrintCreateTextSearchDictionaryStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, dictionary TextSearchDictionary, dictionaryMetadata ObjectMetadata) {
+func (b *Backup) PrintCreateTextSearchDictionaryStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, dictionary TextSearchDictionary, dictionaryMetadata ObjectMetadata) {
 	start := metadataFile.ByteCount
 	metadataFile.MustPrintf("\n\nCREATE TEXT SEARCH DICTIONARY %s (", dictionary.FQN())
 	metadataFile.MustPrintf("\n\tTEMPLATE = %s", dictionary.Template)
@@ -58,10 +58,10 @@ func PrintCreateTextSearchDictionaryStatement(metadataFile *utils.FileWithByteCo
 
 	section, entry := dictionary.GetMetadataEntry()
 	toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-	PrintObjectMetadata(metadataFile, toc, dictionaryMetadata, dictionary, "")
+	b.PrintObjectMetadata(metadataFile, toc, dictionaryMetadata, dictionary, "")
 }
 
-func PrintCreateTextSearchConfigurationStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, configuration TextSearchConfiguration, configurationMetadata ObjectMetadata) {
+func (b *Backup) PrintCreateTextSearchConfigurationStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, configuration TextSearchConfiguration, configurationMetadata ObjectMetadata) {
 	start := metadataFile.ByteCount
 	metadataFile.MustPrintf("\n\nCREATE TEXT SEARCH CONFIGURATION %s (", configuration.FQN())
 	metadataFile.MustPrintf("\n\tPARSER = %s", configuration.Parser)
@@ -82,5 +82,5 @@ func PrintCreateTextSearchConfigurationStatement(metadataFile *utils.FileWithByt
 		metadataFile.MustPrintf("\n\tADD MAPPING FOR \"%s\" WITH %s;", token, strings.Join(dicts, ", "))
 		toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
 	}
-	PrintObjectMetadata(metadataFile, toc, configurationMetadata, configuration, "")
+	b.PrintObjectMetadata(metadataFile, toc, configurationMetadata, configuration, "")
 }
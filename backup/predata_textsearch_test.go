@@ -14,7 +14,7 @@ var _ = Describe("backup/predata_textsearch tests", func() {
 	Describe("PrintCreateTextSearchParserStatements", func() {
 		It("prints a basic text search parser", func() {
 			parser := backup.TextSearchParser{Oid: 0, Schema: "public", Name: "testparser", StartFunc: "start_func", TokenFunc: "token_func", EndFunc: "end_func", LexTypesFunc: "lextypes_func"}
-			backup.PrintCreateTextSearchParserStatement(backupfile, toc, parser, backup.ObjectMetadata{})
+			testBackup.PrintCreateTextSearchParserStatement(backupfile, toc, parser, backup.ObjectMetadata{})
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "testparser", "TEXT SEARCH PARSER")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TEXT SEARCH PARSER public.testparser (
 	START = start_func,
@@ -26,7 +26,7 @@ var _ = Describe("backup/predata_textsearch tests", func() {
 		It("prints a text search parser with a headline and comment", func() {
 			parser := backup.TextSearchParser{Oid: 1, Schema: "public", Name: "testparser", StartFunc: "start_func", TokenFunc: "token_func", EndFunc: "end_func", LexTypesFunc: "lextypes_func", HeadlineFunc: "headline_func"}
 			metadata := testutils.DefaultMetadata("TEXT SEARCH PARSER", false, false, true, false)
-			backup.PrintCreateTextSearchParserStatement(backupfile, toc, parser, metadata)
+			testBackup.PrintCreateTextSearchParserStatement(backupfile, toc, parser, metadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TEXT SEARCH PARSER public.testparser (
 	START = start_func,
 	GETTOKEN = token_func,
@@ -40,7 +40,7 @@ var _ = Describe("backup/predata_textsearch tests", func() {
 		It("prints a basic text search template with comment", func() {
 			template := backup.TextSearchTemplate{Oid: 1, Schema: "public", Name: "testtemplate", InitFunc: "dsimple_init", LexizeFunc: "dsimple_lexize"}
 			metadata := testutils.DefaultMetadata("TEXT SEARCH TEMPLATE", false, false, true, false)
-			backup.PrintCreateTextSearchTemplateStatement(backupfile, toc, template, metadata)
+			testBackup.PrintCreateTextSearchTemplateStatement(backupfile, toc, template, metadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "testtemplate", "TEXT SEARCH TEMPLATE")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TEXT SEARCH TEMPLATE public.testtemplate (
 	INIT = dsimple_init,
@@ -52,7 +52,7 @@ var _ = Describe("backup/predata_textsearch tests", func() {
 		It("prints a basic text search dictionary with comment", func() {
 			dictionary := backup.TextSearchDictionary{Oid: 1, Schema: "public", Name: "testdictionary", Template: "testschema.snowball", InitOption: "language = 'russian', stopwords = 'russian'"}
 			metadata := testutils.DefaultMetadata("TEXT SEARCH DICTIONARY", false, true, true, false)
-			backup.PrintCreateTextSearchDictionaryStatement(backupfile, toc, dictionary, metadata)
+			testBackup.PrintCreateTextSearchDictionaryStatement(backupfile, toc, dictionary, metadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "testdictionary", "TEXT SEARCH DICTIONARY")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TEXT SEARCH DICTIONARY public.testdictionary (
 	TEMPLATE = testschema.snowball,
@@ -63,7 +63,7 @@ var _ = Describe("backup/predata_textsearch tests", func() {
 	Describe("PrintCreateTextSearchConfigurationStatement", func() {
 		It("prints a basic text search configuration", func() {
 			configurations := backup.TextSearchConfiguration{Oid: 0, Schema: "public", Name: "testconfiguration", Parser: `pg_catalog."default"`, TokenToDicts: map[string][]string{}}
-			backup.PrintCreateTextSearchConfigurationStatement(backupfile, toc, configurations, backup.ObjectMetadata{})
+			testBackup.PrintCreateTextSearchConfigurationStatement(backupfile, toc, configurations, backup.ObjectMetadata{})
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TEXT SEARCH CONFIGURATION public.testconfiguration (
 	PARSER = pg_catalog."default"
 );`)
@@ -72,7 +72,7 @@ var _ = Describe("backup/predata_textsearch tests", func() {
 			tokenToDicts := map[string][]string{"int": {"simple", "english_stem"}, "asciiword": {"english_stem"}}
 			configurations := backup.TextSearchConfiguration{Oid: 1, Schema: "public", Name: "testconfiguration", Parser: `pg_catalog."default"`, TokenToDicts: tokenToDicts}
 			metadata := testutils.DefaultMetadata("TEXT SEARCH CONFIGURATION", false, true, true, false)
-			backup.PrintCreateTextSearchConfigurationStatement(backupfile, toc, configurations, metadata)
+			testBackup.PrintCreateTextSearchConfigurationStatement(backupfile, toc, configurations, metadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "testconfiguration", "TEXT SEARCH CONFIGURATION")
 			expectedStatements := []string{`CREATE TEXT SEARCH CONFIGURATION public.testconfiguration (
 	PARSER = pg_catalog."default"
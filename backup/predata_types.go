@@ -31,15 +31,12 @@ func PrintCreateShellTypeStatements(metadataFile *utils.FileWithByteCount, toc *
 	}
 
 	for _, typ := range types {
-		start := metadataFile.ByteCount
-		metadataFile.MustPrintf("CREATE TYPE %s;\n", typ.FQN())
-
 		section, entry := typ.GetMetadataEntry()
-		toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
+		metadataFile.MustPrintEntry(toc, section, entry, "CREATE TYPE %s;\n", typ.FQN())
 	}
 }
 
-func PrintCreateDomainStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, domain Domain, typeMetadata ObjectMetadata, constraints []Constraint) {
+func (b *Backup) PrintCreateDomainStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, domain Domain, typeMetadata ObjectMetadata, constraints []Constraint) {
 	start := metadataFile.ByteCount
 	metadataFile.MustPrintf("\nCREATE DOMAIN %s AS %s", domain.FQN(), domain.BaseType)
 	if domain.DefaultVal != "" {
@@ -58,10 +55,10 @@ func PrintCreateDomainStatement(metadataFile *utils.FileWithByteCount, toc *util
 
 	section, entry := domain.GetMetadataEntry()
 	toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-	PrintObjectMetadata(metadataFile, toc, typeMetadata, domain, "")
+	b.PrintObjectMetadata(metadataFile, toc, typeMetadata, domain, "")
 }
 
-func PrintCreateBaseTypeStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, base BaseType, typeMetadata ObjectMetadata) {
+func (b *Backup) PrintCreateBaseTypeStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, base BaseType, typeMetadata ObjectMetadata) {
 	start := metadataFile.ByteCount
 	metadataFile.MustPrintf("\n\nCREATE TYPE %s (\n", base.FQN())
 
@@ -73,7 +70,7 @@ func PrintCreateBaseTypeStatement(metadataFile *utils.FileWithByteCount, toc *ut
 	if base.Send != "" {
 		metadataFile.MustPrintf(",\n\tSEND = %s", base.Send)
 	}
-	if connectionPool.Version.AtLeast("5") {
+	if b.connectionPool.Version.AtLeast("5") {
 		if base.ModIn != "" {
 			metadataFile.MustPrintf(",\n\tTYPMOD_IN = %s", base.ModIn)
 		}
@@ -133,10 +130,10 @@ func PrintCreateBaseTypeStatement(metadataFile *utils.FileWithByteCount, toc *ut
 	}
 	section, entry := base.GetMetadataEntry()
 	toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-	PrintObjectMetadata(metadataFile, toc, typeMetadata, base, "")
+	b.PrintObjectMetadata(metadataFile, toc, typeMetadata, base, "")
 }
 
-func PrintCreateCompositeTypeStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, composite CompositeType, typeMetadata ObjectMetadata) {
+func (b *Backup) PrintCreateCompositeTypeStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, composite CompositeType, typeMetadata ObjectMetadata) {
 	var attributeList []string
 	for _, att := range composite.Attributes {
 		collationStr := ""
@@ -153,11 +150,11 @@ func PrintCreateCompositeTypeStatement(metadataFile *utils.FileWithByteCount, to
 
 	section, entry := composite.GetMetadataEntry()
 	toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-	PrintPostCreateCompositeTypeStatement(metadataFile, toc, composite, typeMetadata)
+	b.PrintPostCreateCompositeTypeStatement(metadataFile, toc, composite, typeMetadata)
 }
 
-func PrintPostCreateCompositeTypeStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, composite CompositeType, typeMetadata ObjectMetadata) {
-	PrintObjectMetadata(metadataFile, toc, typeMetadata, composite, "")
+func (b *Backup) PrintPostCreateCompositeTypeStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, composite CompositeType, typeMetadata ObjectMetadata) {
+	b.PrintObjectMetadata(metadataFile, toc, typeMetadata, composite, "")
 	statements := make([]string, 0)
 	for _, att := range composite.Attributes {
 		if att.Comment != "" {
@@ -167,18 +164,15 @@ func PrintPostCreateCompositeTypeStatement(metadataFile *utils.FileWithByteCount
 	PrintStatements(metadataFile, toc, composite, statements)
 }
 
-func PrintCreateEnumTypeStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, enums []EnumType, typeMetadata MetadataMap) {
+func (b *Backup) PrintCreateEnumTypeStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, enums []EnumType, typeMetadata MetadataMap) {
 	for _, enum := range enums {
-		start := metadataFile.ByteCount
-		metadataFile.MustPrintf("\n\nCREATE TYPE %s AS ENUM (\n\t%s\n);\n", enum.FQN(), enum.EnumLabels)
-
 		section, entry := enum.GetMetadataEntry()
-		toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-		PrintObjectMetadata(metadataFile, toc, typeMetadata[enum.GetUniqueID()], enum, "")
+		metadataFile.MustPrintEntry(toc, section, entry, "\n\nCREATE TYPE %s AS ENUM (\n\t%s\n);\n", enum.FQN(), enum.EnumLabels)
+		b.PrintObjectMetadata(metadataFile, toc, typeMetadata[enum.GetUniqueID()], enum, "")
 	}
 }
 
-func PrintCreateRangeTypeStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, rangeType RangeType, typeMetadata ObjectMetadata) {
+func (b *Backup) PrintCreateRangeTypeStatement(metadataFile *utils.FileWithByteCount, toc *utils.TOC, rangeType RangeType, typeMetadata ObjectMetadata) {
 	start := metadataFile.ByteCount
 	metadataFile.MustPrintf("\n\nCREATE TYPE %s AS RANGE (\n\tSUBTYPE = %s", rangeType.FQN(), rangeType.SubType)
 
@@ -198,16 +192,22 @@ func PrintCreateRangeTypeStatement(metadataFile *utils.FileWithByteCount, toc *u
 
 	section, entry := rangeType.GetMetadataEntry()
 	toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-	PrintObjectMetadata(metadataFile, toc, typeMetadata, rangeType, "")
+	b.PrintObjectMetadata(metadataFile, toc, typeMetadata, rangeType, "")
 }
 
-func PrintCreateCollationStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, collations []Collation, collationMetadata MetadataMap) {
+func (b *Backup) PrintCreateCollationStatements(metadataFile *utils.FileWithByteCount, toc *utils.TOC, collations []Collation, collationMetadata MetadataMap) {
 	for _, collation := range collations {
-		start := metadataFile.ByteCount
-		metadataFile.MustPrintf("\nCREATE COLLATION %s (LC_COLLATE = '%s', LC_CTYPE = '%s');", collation.FQN(), collation.Collate, collation.Ctype)
-
 		section, entry := collation.GetMetadataEntry()
-		toc.AddMetadataEntry(section, entry, start, metadataFile.ByteCount)
-		PrintObjectMetadata(metadataFile, toc, collationMetadata[collation.GetUniqueID()], collation, "")
+		providerStr := ""
+		if collation.Provider != "" {
+			providerStr = fmt.Sprintf("PROVIDER = %s, ", collation.Provider)
+		}
+		deterministicStr := ""
+		if !collation.IsDeterministic {
+			deterministicStr = ", DETERMINISTIC = false"
+		}
+		metadataFile.MustPrintEntry(toc, section, entry, "\nCREATE COLLATION %s (%sLC_COLLATE = '%s', LC_CTYPE = '%s'%s);", collation.FQN(), providerStr,
+			utils.EscapeSingleQuotes(collation.Collate), utils.EscapeSingleQuotes(collation.Ctype), deterministicStr)
+		b.PrintObjectMetadata(metadataFile, toc, collationMetadata[collation.GetUniqueID()], collation, "")
 	}
 }
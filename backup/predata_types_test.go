@@ -21,7 +21,7 @@ var _ = Describe("backup/predata_types tests", func() {
 		enumTwo := backup.EnumType{Oid: 1, Schema: "public", Name: "enum_type", EnumLabels: "'bar',\n\t'baz',\n\t'foo'"}
 
 		It("prints an enum type with multiple attributes", func() {
-			backup.PrintCreateEnumTypeStatements(backupfile, toc, []backup.EnumType{enumOne}, emptyMetadataMap)
+			testBackup.PrintCreateEnumTypeStatements(backupfile, toc, []backup.EnumType{enumOne}, emptyMetadataMap)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "enum_type", "TYPE")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TYPE public.enum_type AS ENUM (
 	'bar',
@@ -30,7 +30,7 @@ var _ = Describe("backup/predata_types tests", func() {
 );`)
 		})
 		It("prints an enum type with comment, security label, and owner", func() {
-			backup.PrintCreateEnumTypeStatements(backupfile, toc, []backup.EnumType{enumTwo}, typeMetadataMap)
+			testBackup.PrintCreateEnumTypeStatements(backupfile, toc, []backup.EnumType{enumTwo}, typeMetadataMap)
 			expectedStatements := []string{`CREATE TYPE public.enum_type AS ENUM (
 	'bar',
 	'baz',
@@ -55,7 +55,7 @@ var _ = Describe("backup/predata_types tests", func() {
 
 		It("prints a composite type with one attribute", func() {
 			compType.Attributes = oneAtt
-			backup.PrintCreateCompositeTypeStatement(backupfile, toc, compType, emptyMetadata)
+			testBackup.PrintCreateCompositeTypeStatement(backupfile, toc, compType, emptyMetadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "composite_type", "TYPE")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TYPE public.composite_type AS (
 	foo integer
@@ -63,7 +63,7 @@ var _ = Describe("backup/predata_types tests", func() {
 		})
 		It("prints a composite type with one attribute with a collation", func() {
 			compType.Attributes = oneAttWithCollation
-			backup.PrintCreateCompositeTypeStatement(backupfile, toc, compType, emptyMetadata)
+			testBackup.PrintCreateCompositeTypeStatement(backupfile, toc, compType, emptyMetadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "composite_type", "TYPE")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TYPE public.composite_type AS (
 	foo integer COLLATE public.some_coll
@@ -71,7 +71,7 @@ var _ = Describe("backup/predata_types tests", func() {
 		})
 		It("prints a composite type with multiple attributes", func() {
 			compType.Attributes = twoAtts
-			backup.PrintCreateCompositeTypeStatement(backupfile, toc, compType, emptyMetadata)
+			testBackup.PrintCreateCompositeTypeStatement(backupfile, toc, compType, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TYPE public.composite_type AS (
 	foo integer,
 	bar text
@@ -79,7 +79,7 @@ var _ = Describe("backup/predata_types tests", func() {
 		})
 		It("prints a composite type with comment, security label, and owner", func() {
 			compType.Attributes = twoAtts
-			backup.PrintCreateCompositeTypeStatement(backupfile, toc, compType, typeMetadata)
+			testBackup.PrintCreateCompositeTypeStatement(backupfile, toc, compType, typeMetadata)
 			expectedEntries := []string{`CREATE TYPE public.composite_type AS (
 	foo integer,
 	bar text
@@ -91,7 +91,7 @@ var _ = Describe("backup/predata_types tests", func() {
 		})
 		It("prints a composite type with attribute comment", func() {
 			compType.Attributes = attWithComment
-			backup.PrintCreateCompositeTypeStatement(backupfile, toc, compType, emptyMetadata)
+			testBackup.PrintCreateCompositeTypeStatement(backupfile, toc, compType, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TYPE public.composite_type AS (
 	foo integer
 );`, "COMMENT ON COLUMN public.composite_type.foo IS 'attribute comment';")
@@ -105,7 +105,7 @@ var _ = Describe("backup/predata_types tests", func() {
 		basePermTwo := backup.BaseType{Oid: 1, Schema: "public", Name: "base_type", Input: "input_fn", Output: "output_fn", Receive: "", Send: "", ModIn: "", ModOut: "", InternalLength: -1, IsPassedByValue: false, Alignment: "i", Storage: "x", DefaultVal: "", Element: "", Category: "U", Delimiter: ""}
 
 		It("prints a base type with no optional arguments", func() {
-			backup.PrintCreateBaseTypeStatement(backupfile, toc, baseSimple, emptyMetadata)
+			testBackup.PrintCreateBaseTypeStatement(backupfile, toc, baseSimple, emptyMetadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "base_type", "TYPE")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TYPE public.base_type (
 	INPUT = input_fn,
@@ -113,7 +113,7 @@ var _ = Describe("backup/predata_types tests", func() {
 );`)
 		})
 		It("prints a base type where all optional arguments have default values where possible", func() {
-			backup.PrintCreateBaseTypeStatement(backupfile, toc, basePartial, emptyMetadata)
+			testBackup.PrintCreateBaseTypeStatement(backupfile, toc, basePartial, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TYPE public.base_type (
 	INPUT = input_fn,
 	OUTPUT = output_fn,
@@ -127,7 +127,7 @@ var _ = Describe("backup/predata_types tests", func() {
 );`)
 		})
 		It("prints a base type with all optional arguments provided", func() {
-			backup.PrintCreateBaseTypeStatement(backupfile, toc, baseFull, emptyMetadata)
+			testBackup.PrintCreateBaseTypeStatement(backupfile, toc, baseFull, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TYPE public.base_type (
 	INPUT = input_fn,
 	OUTPUT = output_fn,
@@ -151,7 +151,7 @@ ALTER TYPE public.base_type
 	SET DEFAULT ENCODING (compresstype=zlib, compresslevel=1, blocksize=32768);`)
 		})
 		It("prints a base type with double alignment and main storage", func() {
-			backup.PrintCreateBaseTypeStatement(backupfile, toc, basePermOne, emptyMetadata)
+			testBackup.PrintCreateBaseTypeStatement(backupfile, toc, basePermOne, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TYPE public.base_type (
 	INPUT = input_fn,
 	OUTPUT = output_fn,
@@ -160,7 +160,7 @@ ALTER TYPE public.base_type
 );`)
 		})
 		It("prints a base type with int4 alignment and external storage", func() {
-			backup.PrintCreateBaseTypeStatement(backupfile, toc, basePermTwo, emptyMetadata)
+			testBackup.PrintCreateBaseTypeStatement(backupfile, toc, basePermTwo, emptyMetadata)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TYPE public.base_type (
 	INPUT = input_fn,
 	OUTPUT = output_fn,
@@ -169,7 +169,7 @@ ALTER TYPE public.base_type
 );`)
 		})
 		It("prints a base type with comment, security label, and owner", func() {
-			backup.PrintCreateBaseTypeStatement(backupfile, toc, baseSimple, typeMetadata)
+			testBackup.PrintCreateBaseTypeStatement(backupfile, toc, baseSimple, typeMetadata)
 			expectedEntries := []string{`CREATE TYPE public.base_type (
 	INPUT = input_fn,
 	OUTPUT = output_fn
@@ -208,18 +208,18 @@ ALTER TYPE public.base_type
 		domainOne := backup.Domain{Oid: 1, Schema: "public", Name: "domain1", DefaultVal: "4", BaseType: "numeric", NotNull: true, Collation: "public.mycollation"}
 		domainTwo := backup.Domain{Oid: 1, Schema: "public", Name: "domain2", DefaultVal: "", BaseType: "varchar", NotNull: false, Collation: ""}
 		It("prints a domain with a constraint", func() {
-			backup.PrintCreateDomainStatement(backupfile, toc, domainOne, emptyMetadata, checkConstraint)
+			testBackup.PrintCreateDomainStatement(backupfile, toc, domainOne, emptyMetadata, checkConstraint)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "domain1", "DOMAIN")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE DOMAIN public.domain1 AS numeric DEFAULT 4 COLLATE public.mycollation NOT NULL
 	CONSTRAINT domain1_check CHECK (VALUE > 2);`)
 		})
 		It("prints a domain without constraint", func() {
-			backup.PrintCreateDomainStatement(backupfile, toc, domainOne, emptyMetadata, emptyConstraint)
+			testBackup.PrintCreateDomainStatement(backupfile, toc, domainOne, emptyMetadata, emptyConstraint)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE DOMAIN public.domain1 AS numeric DEFAULT 4 COLLATE public.mycollation NOT NULL;`)
 		})
 		It("prints a domain without constraint with comment, security label, and owner", func() {
 			domainMetadata := testutils.DefaultMetadata("DOMAIN", false, true, true, true)
-			backup.PrintCreateDomainStatement(backupfile, toc, domainTwo, domainMetadata, emptyConstraint)
+			testBackup.PrintCreateDomainStatement(backupfile, toc, domainTwo, domainMetadata, emptyConstraint)
 			expectedEntries := []string{"CREATE DOMAIN public.domain2 AS varchar;",
 				"COMMENT ON DOMAIN public.domain2 IS 'This is a domain comment.';",
 				"ALTER DOMAIN public.domain2 OWNER TO testrole;",
@@ -242,14 +242,14 @@ ALTER TYPE public.base_type
 			SubTypeDiff:    "diff_schema.test_diff",
 		}
 		It("prints a basic range type", func() {
-			backup.PrintCreateRangeTypeStatement(backupfile, toc, basicRangeType, emptyMetadata)
+			testBackup.PrintCreateRangeTypeStatement(backupfile, toc, basicRangeType, emptyMetadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "rangetype", "TYPE")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TYPE public.rangetype AS RANGE (
 	SUBTYPE = test_subtype_schema.test_subtype
 );`)
 		})
 		It("prints a complex range type", func() {
-			backup.PrintCreateRangeTypeStatement(backupfile, toc, complexRangeType, emptyMetadata)
+			testBackup.PrintCreateRangeTypeStatement(backupfile, toc, complexRangeType, emptyMetadata)
 			testutils.ExpectEntry(toc.PredataEntries, 0, "public", "", "rangetype", "TYPE")
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE TYPE public.rangetype AS RANGE (
 	SUBTYPE = test_subtype_schema.test_subtype,
@@ -260,7 +260,7 @@ ALTER TYPE public.base_type
 );`)
 		})
 		It("prints a range type with an owner, security label, and a comment", func() {
-			backup.PrintCreateRangeTypeStatement(backupfile, toc, basicRangeType, typeMetadata)
+			testBackup.PrintCreateRangeTypeStatement(backupfile, toc, basicRangeType, typeMetadata)
 			expectedStatements := []string{`CREATE TYPE public.rangetype AS RANGE (
 	SUBTYPE = test_subtype_schema.test_subtype
 );`,
@@ -272,19 +272,24 @@ ALTER TYPE public.base_type
 	})
 	Describe("PrintCreateCollationStatement", func() {
 		It("prints a create collation statement", func() {
-			collation := backup.Collation{Oid: 1, Name: "collation1", Collate: "collate1", Ctype: "ctype1", Schema: "schema1"}
-			backup.PrintCreateCollationStatements(backupfile, toc, []backup.Collation{collation}, emptyMetadataMap)
+			collation := backup.Collation{Oid: 1, Name: "collation1", Collate: "collate1", Ctype: "ctype1", Schema: "schema1", IsDeterministic: true}
+			testBackup.PrintCreateCollationStatements(backupfile, toc, []backup.Collation{collation}, emptyMetadataMap)
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE COLLATION schema1.collation1 (LC_COLLATE = 'collate1', LC_CTYPE = 'ctype1');`)
 		})
 		It("prints a create collation statement with owner and comment", func() {
-			collation := backup.Collation{Oid: 1, Name: "collation1", Collate: "collate1", Ctype: "ctype1", Schema: "schema1"}
+			collation := backup.Collation{Oid: 1, Name: "collation1", Collate: "collate1", Ctype: "ctype1", Schema: "schema1", IsDeterministic: true}
 			collationMetadataMap := testutils.DefaultMetadataMap("COLLATION", false, true, true, false)
-			backup.PrintCreateCollationStatements(backupfile, toc, []backup.Collation{collation}, collationMetadataMap)
+			testBackup.PrintCreateCollationStatements(backupfile, toc, []backup.Collation{collation}, collationMetadataMap)
 			expectedStatements := []string{
 				"CREATE COLLATION schema1.collation1 (LC_COLLATE = 'collate1', LC_CTYPE = 'ctype1');",
 				"COMMENT ON COLLATION schema1.collation1 IS 'This is a collation comment.';",
 				"ALTER COLLATION schema1.collation1 OWNER TO testrole;"}
 			testutils.AssertBufferContents(toc.PredataEntries, buffer, expectedStatements...)
 		})
+		It("prints a create collation statement with a provider and nondeterministic comparison", func() {
+			collation := backup.Collation{Oid: 1, Name: "collation1", Collate: "und-u-ks-level2", Ctype: "und-u-ks-level2", Schema: "schema1", Provider: "icu", IsDeterministic: false}
+			testBackup.PrintCreateCollationStatements(backupfile, toc, []backup.Collation{collation}, emptyMetadataMap)
+			testutils.AssertBufferContents(toc.PredataEntries, buffer, `CREATE COLLATION schema1.collation1 (PROVIDER = icu, LC_COLLATE = 'und-u-ks-level2', LC_CTYPE = 'und-u-ks-level2', DETERMINISTIC = false);`)
+		})
 	})
 })
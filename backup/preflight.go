@@ -0,0 +1,185 @@
+package backup
+
+/*
+ * This file contains the checks that are run before a backup starts, so that
+ * problems with the cluster are reported before any tables are locked or any
+ * backup files are written.
+ */
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/greenplum-db/gp-common-go-libs/cluster"
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/pkg/errors"
+)
+
+func (b *Backup) RunPreflightChecks() {
+	gplog.Verbose("Running pre-flight checks")
+	CheckNoConflictingBackups(b.connectionPool)
+	if b.MustGetFlagBool(utils.CHECK_CATALOG) {
+		CheckCatalogConsistency(b.connectionPool)
+	}
+	if b.MustGetFlagBool(utils.DRY_RUN) {
+		return
+	}
+	b.CheckBackupDirectoriesWritable()
+	// The data of an archive backup is streamed from the segments rather than written to them
+	if !b.MustGetFlagBool(utils.METADATA_ONLY) && b.MustGetFlagString(utils.ARCHIVE_FILE) == "" {
+		b.CheckFreeDiskSpace()
+	}
+}
+
+func CheckNoConflictingBackups(connectionPool *dbconn.DBConn) {
+	pidColumn := "procpid"
+	if connectionPool.Version.AtLeast("6") {
+		pidColumn = "pid"
+	}
+	ownPids := make([]string, 0, connectionPool.NumConns)
+	for connNum := 0; connNum < connectionPool.NumConns; connNum++ {
+		ownPids = append(ownPids, dbconn.MustSelectString(connectionPool, "SELECT pg_backend_pid() AS string", connNum))
+	}
+	query := fmt.Sprintf(`
+	SELECT count(DISTINCT sess_id) AS string
+	FROM pg_stat_activity
+	WHERE application_name = 'gpbackup'
+		AND datname = current_database()
+		AND %s NOT IN (%s)`, pidColumn, strings.Join(ownPids, ", "))
+	numSessions := dbconn.MustSelectString(connectionPool, query)
+	if numSessions != "0" {
+		gplog.Fatal(errors.Errorf("Another gpbackup is already running against database %s. Wait for it to finish before starting a new backup.", connectionPool.DBName), "")
+	}
+}
+
+/*
+ * These queries look for catalog entries whose parent entries are missing,
+ * which indicates catalog corruption that would cause the backup to fail or
+ * to produce metadata that cannot be restored.
+ */
+var catalogConsistencyQueries = []struct {
+	description string
+	query       string
+}{
+	{"relations without a schema", `
+	SELECT count(*) AS string
+	FROM pg_class c
+		LEFT JOIN pg_namespace n ON c.relnamespace = n.oid
+	WHERE n.oid IS NULL`},
+	{"types without a schema", `
+	SELECT count(*) AS string
+	FROM pg_type t
+		LEFT JOIN pg_namespace n ON t.typnamespace = n.oid
+	WHERE n.oid IS NULL`},
+	{"columns without a relation", `
+	SELECT count(*) AS string
+	FROM pg_attribute a
+		LEFT JOIN pg_class c ON a.attrelid = c.oid
+	WHERE c.oid IS NULL`},
+}
+
+func CheckCatalogConsistency(connectionPool *dbconn.DBConn) {
+	problems := make([]string, 0)
+	for _, check := range catalogConsistencyQueries {
+		count := dbconn.MustSelectString(connectionPool, check.query)
+		if count != "0" {
+			problems = append(problems, fmt.Sprintf("%s %s", count, check.description))
+		}
+	}
+	if len(problems) > 0 {
+		gplog.Fatal(errors.Errorf("Catalog corruption detected in database %s: found %s. Run gpcheckcat to diagnose and repair the catalog before backing up.",
+			connectionPool.DBName, strings.Join(problems, ", ")), "")
+	}
+}
+
+func (b *Backup) CheckBackupDirectoriesWritable() {
+	scope := cluster.ON_SEGMENTS_AND_MASTER
+	if b.MustGetFlagBool(utils.METADATA_ONLY) {
+		scope = cluster.ON_MASTER
+	}
+	remoteOutput := b.globalCluster.GenerateAndExecuteCommand("Checking that backup directories are writable", func(contentID int) string {
+		return fmt.Sprintf("test -w %s", b.globalFPInfo.GetDirForContent(contentID))
+	}, scope)
+	b.globalCluster.CheckClusterError(remoteOutput, "Backup directories are not writable", func(contentID int) string {
+		return fmt.Sprintf("Backup directory %s on host %s is not writable by the current user", b.globalFPInfo.GetDirForContent(contentID), b.globalCluster.GetHostForContent(contentID))
+	})
+}
+
+/*
+ * The size of the database is only an estimate of the space the backup will
+ * need, as data is usually compressed, so insufficient space is reported as a
+ * warning unless compression is disabled.
+ */
+func (b *Backup) CheckFreeDiskSpace() {
+	availableSpace := b.GetAvailableDiskSpace(b.globalFPInfo.GetDirForContent)
+	requiredSpace := GetSegmentDatabaseSizes(b.connectionPool)
+	insufficientSegments := GetSegmentsWithInsufficientSpace(availableSpace, requiredSpace)
+	if len(insufficientSegments) == 0 {
+		return
+	}
+	for _, contentID := range insufficientSegments {
+		gplog.Warn("Backup directory %s on host %s has %s free, but the database is %s on segment %d",
+			b.globalFPInfo.GetDirForContent(contentID), b.globalCluster.GetHostForContent(contentID),
+			FormatByteSize(availableSpace[contentID]), FormatByteSize(requiredSpace[contentID]), contentID)
+	}
+	if b.MustGetFlagBool(utils.NO_COMPRESSION) {
+		gplog.Fatal(errors.Errorf("Insufficient disk space for an uncompressed backup on %d segment(s). Free up space or use a different --backup-dir.", len(insufficientSegments)), "")
+	}
+}
+
+/*
+ * Returns the free space in bytes of the filesystem containing the directory
+ * returned by getDir for each segment.
+ */
+func (b *Backup) GetAvailableDiskSpace(getDir func(contentID int) string) map[int]int64 {
+	remoteOutput := b.globalCluster.GenerateAndExecuteCommand("Checking free disk space", func(contentID int) string {
+		return fmt.Sprintf("df -Pk %s | tail -1 | awk '{print $4}'", getDir(contentID))
+	}, cluster.ON_SEGMENTS)
+	b.globalCluster.CheckClusterError(remoteOutput, "Unable to check free disk space", func(contentID int) string {
+		return fmt.Sprintf("Unable to check free disk space for directory %s", getDir(contentID))
+	})
+	availableSpace := make(map[int]int64, len(remoteOutput.Stdouts))
+	for contentID, stdout := range remoteOutput.Stdouts {
+		kilobytes, err := strconv.ParseInt(strings.TrimSpace(stdout), 10, 64)
+		if err != nil {
+			gplog.Warn("Unable to parse free disk space for directory %s: %s", getDir(contentID), strings.TrimSpace(stdout))
+			continue
+		}
+		availableSpace[contentID] = kilobytes * 1024
+	}
+	return availableSpace
+}
+
+func GetSegmentsWithInsufficientSpace(availableSpace map[int]int64, requiredSpace map[int]int64) []int {
+	contentIDs := make([]int, 0)
+	for contentID, available := range availableSpace {
+		if available < requiredSpace[contentID] {
+			contentIDs = append(contentIDs, contentID)
+		}
+	}
+	sort.Ints(contentIDs)
+	return contentIDs
+}
+
+func GetSegmentDatabaseSizes(connectionPool *dbconn.DBConn) map[int]int64 {
+	query := `
+	SELECT gp_segment_id AS contentid,
+		pg_database_size(current_database())::bigint AS size
+	FROM gp_dist_random('gp_id')`
+
+	var results []struct {
+		ContentID int
+		Size      int64
+	}
+	err := connectionPool.Select(&results, query)
+	gplog.FatalOnError(err)
+	sizeMap := make(map[int]int64, len(results))
+	for _, result := range results {
+		sizeMap[result.ContentID] = result.Size
+	}
+	return sizeMap
+}
@@ -0,0 +1,55 @@
+package backup_test
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"github.com/greenplum-db/gpbackup/backup"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backup/preflight tests", func() {
+	Describe("CheckNoConflictingBackups", func() {
+		It("passes if no other gpbackup sessions are connected to the database", func() {
+			mock.ExpectQuery("SELECT pg_backend_pid()").WillReturnRows(sqlmock.NewRows([]string{"string"}).AddRow("1234"))
+			mock.ExpectQuery("SELECT (.*)").WillReturnRows(sqlmock.NewRows([]string{"string"}).AddRow("0"))
+			backup.CheckNoConflictingBackups(connectionPool)
+		})
+		It("panics if another gpbackup session is connected to the database", func() {
+			mock.ExpectQuery("SELECT pg_backend_pid()").WillReturnRows(sqlmock.NewRows([]string{"string"}).AddRow("1234"))
+			mock.ExpectQuery("SELECT (.*)").WillReturnRows(sqlmock.NewRows([]string{"string"}).AddRow("1"))
+			defer testhelper.ShouldPanicWithMessage("Another gpbackup is already running against database testdb")
+			backup.CheckNoConflictingBackups(connectionPool)
+		})
+	})
+	Describe("CheckCatalogConsistency", func() {
+		It("passes if no orphaned catalog entries are found", func() {
+			for i := 0; i < 3; i++ {
+				mock.ExpectQuery("SELECT (.*)").WillReturnRows(sqlmock.NewRows([]string{"string"}).AddRow("0"))
+			}
+			backup.CheckCatalogConsistency(connectionPool)
+		})
+		It("panics listing each kind of orphaned catalog entry found", func() {
+			mock.ExpectQuery("SELECT (.*)").WillReturnRows(sqlmock.NewRows([]string{"string"}).AddRow("2"))
+			mock.ExpectQuery("SELECT (.*)").WillReturnRows(sqlmock.NewRows([]string{"string"}).AddRow("0"))
+			mock.ExpectQuery("SELECT (.*)").WillReturnRows(sqlmock.NewRows([]string{"string"}).AddRow("5"))
+			defer testhelper.ShouldPanicWithMessage("found 2 relations without a schema, 5 columns without a relation")
+			backup.CheckCatalogConsistency(connectionPool)
+		})
+	})
+	Describe("GetSegmentsWithInsufficientSpace", func() {
+		It("returns the sorted content ids of segments with less space available than required", func() {
+			availableSpace := map[int]int64{0: 100, 1: 50, 2: 300, 3: 10}
+			requiredSpace := map[int]int64{0: 100, 1: 200, 2: 200, 3: 20}
+
+			Expect(backup.GetSegmentsWithInsufficientSpace(availableSpace, requiredSpace)).To(Equal([]int{1, 3}))
+		})
+		It("returns an empty list if all segments have enough space", func() {
+			availableSpace := map[int]int64{0: 100, 1: 200}
+			requiredSpace := map[int]int64{0: 10, 1: 20}
+
+			Expect(backup.GetSegmentsWithInsufficientSpace(availableSpace, requiredSpace)).To(BeEmpty())
+		})
+	})
+})
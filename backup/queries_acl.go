@@ -9,6 +9,7 @@ import (
 	"database/sql"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/greenplum-db/gp-common-go-libs/dbconn"
 	"github.com/greenplum-db/gp-common-go-libs/gplog"
@@ -32,78 +33,42 @@ type MetadataQueryParams struct {
 }
 
 var (
-	TYPE_AGGREGATE          MetadataQueryParams
-	TYPE_CAST               MetadataQueryParams
-	TYPE_COLLATION          MetadataQueryParams
-	TYPE_CONSTRAINT         MetadataQueryParams
-	TYPE_CONVERSION         MetadataQueryParams
-	TYPE_DATABASE           MetadataQueryParams
-	TYPE_EVENTTRIGGER       MetadataQueryParams
-	TYPE_EXTENSION          MetadataQueryParams
-	TYPE_FOREIGNDATAWRAPPER MetadataQueryParams
-	TYPE_FOREIGNSERVER      MetadataQueryParams
-	TYPE_FUNCTION           MetadataQueryParams
-	TYPE_INDEX              MetadataQueryParams
-	TYPE_PROCLANGUAGE       MetadataQueryParams
-	TYPE_OPERATOR           MetadataQueryParams
-	TYPE_OPERATORCLASS      MetadataQueryParams
-	TYPE_OPERATORFAMILY     MetadataQueryParams
-	TYPE_PROTOCOL           MetadataQueryParams
-	TYPE_RELATION           MetadataQueryParams
-	TYPE_RESOURCEGROUP      MetadataQueryParams
-	TYPE_RESOURCEQUEUE      MetadataQueryParams
-	TYPE_ROLE               MetadataQueryParams
-	TYPE_RULE               MetadataQueryParams
-	TYPE_SCHEMA             MetadataQueryParams
-	TYPE_TABLESPACE         MetadataQueryParams
-	TYPE_TSCONFIGURATION    MetadataQueryParams
-	TYPE_TSDICTIONARY       MetadataQueryParams
-	TYPE_TSPARSER           MetadataQueryParams
-	TYPE_TSTEMPLATE         MetadataQueryParams
-	TYPE_TRIGGER            MetadataQueryParams
-	TYPE_TYPE               MetadataQueryParams
-)
-
-func InitializeMetadataParams(connectionPool *dbconn.DBConn) {
-	TYPE_AGGREGATE = MetadataQueryParams{NameField: "proname", SchemaField: "pronamespace", ACLField: "proacl", OwnerField: "proowner", CatalogTable: "pg_proc"}
-	TYPE_CAST = MetadataQueryParams{NameField: "typname", OidField: "oid", OidTable: "pg_type", CatalogTable: "pg_cast"}
-	TYPE_COLLATION = MetadataQueryParams{NameField: "collname", OidField: "oid", SchemaField: "collnamespace", OwnerField: "collowner", CatalogTable: "pg_collation"}
-	TYPE_CONSTRAINT = MetadataQueryParams{NameField: "conname", SchemaField: "connamespace", OidField: "oid", CatalogTable: "pg_constraint"}
-	TYPE_CONVERSION = MetadataQueryParams{NameField: "conname", OidField: "oid", SchemaField: "connamespace", OwnerField: "conowner", CatalogTable: "pg_conversion"}
-	TYPE_DATABASE = MetadataQueryParams{NameField: "datname", ACLField: "datacl", OwnerField: "datdba", CatalogTable: "pg_database", Shared: true}
-	TYPE_EVENTTRIGGER = MetadataQueryParams{NameField: "evtname", OidField: "oid", OwnerField: "evtowner", CatalogTable: "pg_event_trigger"}
-	TYPE_EXTENSION = MetadataQueryParams{NameField: "extname", OidField: "oid", CatalogTable: "pg_extension"}
+	TYPE_AGGREGATE          = MetadataQueryParams{NameField: "proname", SchemaField: "pronamespace", ACLField: "proacl", OwnerField: "proowner", CatalogTable: "pg_proc"}
+	TYPE_CAST               = MetadataQueryParams{NameField: "typname", OidField: "oid", OidTable: "pg_type", CatalogTable: "pg_cast"}
+	TYPE_COLLATION          = MetadataQueryParams{NameField: "collname", OidField: "oid", SchemaField: "collnamespace", OwnerField: "collowner", CatalogTable: "pg_collation"}
+	TYPE_CONSTRAINT         = MetadataQueryParams{NameField: "conname", SchemaField: "connamespace", OidField: "oid", CatalogTable: "pg_constraint"}
+	TYPE_CONVERSION         = MetadataQueryParams{NameField: "conname", OidField: "oid", SchemaField: "connamespace", OwnerField: "conowner", CatalogTable: "pg_conversion"}
+	TYPE_DATABASE           = MetadataQueryParams{NameField: "datname", ACLField: "datacl", OwnerField: "datdba", CatalogTable: "pg_database", Shared: true}
+	TYPE_EVENTTRIGGER       = MetadataQueryParams{NameField: "evtname", OidField: "oid", OwnerField: "evtowner", CatalogTable: "pg_event_trigger"}
+	TYPE_EXTENSION          = MetadataQueryParams{NameField: "extname", OidField: "oid", CatalogTable: "pg_extension"}
 	TYPE_FOREIGNDATAWRAPPER = MetadataQueryParams{NameField: "fdwname", ACLField: "fdwacl", OwnerField: "fdwowner", CatalogTable: "pg_foreign_data_wrapper"}
-	TYPE_FOREIGNSERVER = MetadataQueryParams{NameField: "srvname", ACLField: "srvacl", OwnerField: "srvowner", CatalogTable: "pg_foreign_server"}
-	TYPE_FUNCTION = TYPE_AGGREGATE // Aggregates are functions. So the metadata call to get them are the same.
-	TYPE_INDEX = MetadataQueryParams{NameField: "relname", OidField: "indexrelid", OidTable: "pg_class", CommentTable: "pg_class", CatalogTable: "pg_index"}
-	TYPE_PROCLANGUAGE = MetadataQueryParams{NameField: "lanname", ACLField: "lanacl", CatalogTable: "pg_language"}
-	if connectionPool.Version.Before("5") {
-		TYPE_PROCLANGUAGE.OwnerField = "10" // In GPDB 4.3, there is no lanowner field in pg_language, but languages have an implicit owner
-	} else {
-		TYPE_PROCLANGUAGE.OwnerField = "lanowner"
-	}
-	TYPE_OPERATOR = MetadataQueryParams{NameField: "oprname", SchemaField: "oprnamespace", OidField: "oid", OwnerField: "oprowner", CatalogTable: "pg_operator"}
-	TYPE_OPERATORCLASS = MetadataQueryParams{NameField: "opcname", SchemaField: "opcnamespace", OidField: "oid", OwnerField: "opcowner", CatalogTable: "pg_opclass"}
-	TYPE_OPERATORFAMILY = MetadataQueryParams{NameField: "opfname", SchemaField: "opfnamespace", OidField: "oid", OwnerField: "opfowner", CatalogTable: "pg_opfamily"}
-	TYPE_PROTOCOL = MetadataQueryParams{NameField: "ptcname", ACLField: "ptcacl", OwnerField: "ptcowner", CatalogTable: "pg_extprotocol"}
-	TYPE_RELATION = MetadataQueryParams{NameField: "relname", SchemaField: "relnamespace", ACLField: "relacl", OwnerField: "relowner", CatalogTable: "pg_class"}
-	TYPE_RESOURCEGROUP = MetadataQueryParams{NameField: "rsgname", OidField: "oid", CatalogTable: "pg_resgroup", Shared: true}
-	TYPE_RESOURCEQUEUE = MetadataQueryParams{NameField: "rsqname", OidField: "oid", CatalogTable: "pg_resqueue", Shared: true}
-	TYPE_ROLE = MetadataQueryParams{NameField: "rolname", OidField: "oid", CatalogTable: "pg_authid", Shared: true}
-	TYPE_RULE = MetadataQueryParams{NameField: "rulename", OidField: "oid", CatalogTable: "pg_rewrite"}
-	TYPE_SCHEMA = MetadataQueryParams{NameField: "nspname", ACLField: "nspacl", OwnerField: "nspowner", CatalogTable: "pg_namespace"}
-	TYPE_TABLESPACE = MetadataQueryParams{NameField: "spcname", ACLField: "spcacl", OwnerField: "spcowner", CatalogTable: "pg_tablespace", Shared: true}
+	TYPE_FOREIGNSERVER      = MetadataQueryParams{NameField: "srvname", ACLField: "srvacl", OwnerField: "srvowner", CatalogTable: "pg_foreign_server"}
+	TYPE_FUNCTION           = TYPE_AGGREGATE // Aggregates are functions. So the metadata call to get them are the same.
+	TYPE_INDEX              = MetadataQueryParams{NameField: "relname", OidField: "indexrelid", OidTable: "pg_class", CommentTable: "pg_class", CatalogTable: "pg_index"}
+	// Comments and security labels on large objects refer to pg_largeobject rather than pg_largeobject_metadata
+	TYPE_LARGEOBJECT     = MetadataQueryParams{NameField: "oid", ACLField: "lomacl", OwnerField: "lomowner", CommentTable: "pg_largeobject", CatalogTable: "pg_largeobject_metadata"}
+	TYPE_PROCLANGUAGE    = MetadataQueryParams{NameField: "lanname", ACLField: "lanacl", OwnerField: "lanowner", CatalogTable: "pg_language"}
+	TYPE_OPERATOR        = MetadataQueryParams{NameField: "oprname", SchemaField: "oprnamespace", OidField: "oid", OwnerField: "oprowner", CatalogTable: "pg_operator"}
+	TYPE_OPERATORCLASS   = MetadataQueryParams{NameField: "opcname", SchemaField: "opcnamespace", OidField: "oid", OwnerField: "opcowner", CatalogTable: "pg_opclass"}
+	TYPE_OPERATORFAMILY  = MetadataQueryParams{NameField: "opfname", SchemaField: "opfnamespace", OidField: "oid", OwnerField: "opfowner", CatalogTable: "pg_opfamily"}
+	TYPE_POLICY          = MetadataQueryParams{NameField: "polname", OidField: "oid", CatalogTable: "pg_policy"}
+	TYPE_PROTOCOL        = MetadataQueryParams{NameField: "ptcname", ACLField: "ptcacl", OwnerField: "ptcowner", CatalogTable: "pg_extprotocol"}
+	TYPE_RELATION        = MetadataQueryParams{NameField: "relname", SchemaField: "relnamespace", ACLField: "relacl", OwnerField: "relowner", CatalogTable: "pg_class"}
+	TYPE_RESOURCEGROUP   = MetadataQueryParams{NameField: "rsgname", OidField: "oid", CatalogTable: "pg_resgroup", Shared: true}
+	TYPE_RESOURCEQUEUE   = MetadataQueryParams{NameField: "rsqname", OidField: "oid", CatalogTable: "pg_resqueue", Shared: true}
+	TYPE_ROLE            = MetadataQueryParams{NameField: "rolname", OidField: "oid", CatalogTable: "pg_authid", Shared: true}
+	TYPE_RULE            = MetadataQueryParams{NameField: "rulename", OidField: "oid", CatalogTable: "pg_rewrite"}
+	TYPE_SCHEMA          = MetadataQueryParams{NameField: "nspname", ACLField: "nspacl", OwnerField: "nspowner", CatalogTable: "pg_namespace"}
+	TYPE_STATISTIC_EXT   = MetadataQueryParams{NameField: "stxname", OidField: "oid", SchemaField: "stxnamespace", OwnerField: "stxowner", CatalogTable: "pg_statistic_ext"}
+	TYPE_TABLESPACE      = MetadataQueryParams{NameField: "spcname", ACLField: "spcacl", OwnerField: "spcowner", CatalogTable: "pg_tablespace", Shared: true}
+	TYPE_TRANSFORM       = MetadataQueryParams{NameField: "oid", OidField: "oid", CatalogTable: "pg_transform"}
 	TYPE_TSCONFIGURATION = MetadataQueryParams{NameField: "cfgname", OidField: "oid", SchemaField: "cfgnamespace", OwnerField: "cfgowner", CatalogTable: "pg_ts_config"}
-	TYPE_TSDICTIONARY = MetadataQueryParams{NameField: "dictname", OidField: "oid", SchemaField: "dictnamespace", OwnerField: "dictowner", CatalogTable: "pg_ts_dict"}
-	TYPE_TSPARSER = MetadataQueryParams{NameField: "prsname", OidField: "oid", SchemaField: "prsnamespace", CatalogTable: "pg_ts_parser"}
-	TYPE_TSTEMPLATE = MetadataQueryParams{NameField: "tmplname", OidField: "oid", SchemaField: "tmplnamespace", CatalogTable: "pg_ts_template"}
-	TYPE_TRIGGER = MetadataQueryParams{NameField: "tgname", OidField: "oid", CatalogTable: "pg_trigger"}
-	TYPE_TYPE = MetadataQueryParams{NameField: "typname", SchemaField: "typnamespace", OwnerField: "typowner", CatalogTable: "pg_type"}
-	if connectionPool.Version.AtLeast("6") {
-		TYPE_TYPE.ACLField = "typacl"
-	}
-}
+	TYPE_TSDICTIONARY    = MetadataQueryParams{NameField: "dictname", OidField: "oid", SchemaField: "dictnamespace", OwnerField: "dictowner", CatalogTable: "pg_ts_dict"}
+	TYPE_TSPARSER        = MetadataQueryParams{NameField: "prsname", OidField: "oid", SchemaField: "prsnamespace", CatalogTable: "pg_ts_parser"}
+	TYPE_TSTEMPLATE      = MetadataQueryParams{NameField: "tmplname", OidField: "oid", SchemaField: "tmplnamespace", CatalogTable: "pg_ts_template"}
+	TYPE_TRIGGER         = MetadataQueryParams{NameField: "tgname", OidField: "oid", CatalogTable: "pg_trigger"}
+	TYPE_TYPE            = MetadataQueryParams{NameField: "typname", SchemaField: "typnamespace", ACLField: "typacl", OwnerField: "typowner", CatalogTable: "pg_type"}
+)
 
 type MetadataQueryStruct struct {
 	UniqueID
@@ -115,9 +80,31 @@ type MetadataQueryStruct struct {
 	SecurityLabelProvider string
 }
 
-func GetMetadataForObjectType(connectionPool *dbconn.DBConn, params MetadataQueryParams) MetadataMap {
+/*
+ * Every kind of object has its owner, privileges, comment, and security label
+ * gathered by this one query, driven by the catalog columns in its
+ * MetadataQueryParams, so supporting a new kind of object only requires
+ * defining its parameters above.  Kinds of objects with neither an owner nor
+ * privileges only have an entry in the map if they have a comment or label.
+ */
+func (b *Backup) GetMetadataForObjectType(connectionPool *dbconn.DBConn, params MetadataQueryParams) MetadataMap {
 	gplog.Verbose("Getting object type metadata from " + params.CatalogTable)
 
+	if params.CatalogTable == "pg_language" && connectionPool.Version.Before("5") {
+		params.OwnerField = "10" // In GPDB 4.3, there is no lanowner field in pg_language, but languages have an implicit owner
+	}
+	if params.CatalogTable == "pg_type" && connectionPool.Version.Before("6") {
+		params.ACLField = "" // Types have no privileges before GPDB 6
+	}
+
+	oidField := "oid"
+	if params.OidField != "" {
+		oidField = params.OidField
+	}
+	commentTable := params.CatalogTable
+	if params.CommentTable != "" {
+		commentTable = params.CommentTable
+	}
 	aclStr := "''"
 	kindStr := "''"
 	if params.ACLField != "" {
@@ -131,9 +118,10 @@ func GetMetadataForObjectType(connectionPool *dbconn.DBConn, params MetadataQuer
 		ELSE '' END`, params.ACLField)
 	}
 	schemaStr := ""
+	conditions := make([]string, 0)
 	if params.SchemaField != "" {
-		schemaStr = fmt.Sprintf(`JOIN pg_namespace n ON o.%s = n.oid
-	WHERE %s`, params.SchemaField, SchemaFilterClause("n"))
+		schemaStr = fmt.Sprintf("JOIN pg_namespace n ON o.%s = n.oid", params.SchemaField)
+		conditions = append(conditions, b.SchemaFilterClause("n"))
 	}
 	descFunc := "pg_description"
 	subidStr := " AND d.objsubid = 0"
@@ -147,6 +135,7 @@ func GetMetadataForObjectType(connectionPool *dbconn.DBConn, params MetadataQuer
 	}
 	secCols := ""
 	secStr := ""
+	hasMetadataStr := "d.description IS NOT NULL"
 	if connectionPool.Version.AtLeast("6") {
 		secCols = "coalesce(sec.label,'') AS securitylabel, coalesce(sec.provider, '') AS securitylabelprovider,"
 		secTable := "pg_seclabel"
@@ -155,30 +144,36 @@ func GetMetadataForObjectType(connectionPool *dbconn.DBConn, params MetadataQuer
 			secTable = "pg_shseclabel"
 			secSubidStr = ""
 		}
-		secStr = fmt.Sprintf("LEFT JOIN %s sec ON (sec.objoid = o.oid AND sec.classoid = '%s'::regclass%s)", secTable, params.CatalogTable, secSubidStr)
+		secStr = fmt.Sprintf("LEFT JOIN %s sec ON (sec.objoid = o.%s AND sec.classoid = '%s'::regclass%s)", secTable, oidField, commentTable, secSubidStr)
+		hasMetadataStr = "(d.description IS NOT NULL OR sec.label IS NOT NULL)"
+	}
+	if params.OwnerField == "" && params.ACLField == "" {
+		conditions = append(conditions, hasMetadataStr)
 	}
+	conditions = append(conditions, fmt.Sprintf("o.%s NOT IN (SELECT objid FROM pg_depend WHERE deptype='e')", oidField))
 
 	query := fmt.Sprintf(`
 	SELECT
 		'%s'::regclass::oid AS classid,
-		o.oid,
+		o.%s AS oid,
 		%s AS privileges,
 		%s AS kind,
 		%s AS owner,
 		%s
 		coalesce(description,'') AS comment
-	FROM %s o LEFT JOIN %s d ON (d.objoid = o.oid AND d.classoid = '%s'::regclass%s)
+	FROM %s o LEFT JOIN %s d ON (d.objoid = o.%s AND d.classoid = '%s'::regclass%s)
 		%s
 		%s
-		AND o.oid NOT IN (SELECT objid FROM pg_depend WHERE deptype='e')
-	ORDER BY o.oid`, params.CatalogTable, aclStr, kindStr, ownerStr, secCols,
-	params.CatalogTable, descFunc, params.CatalogTable, subidStr, secStr, schemaStr)
+	WHERE %s
+	ORDER BY o.%s`, params.CatalogTable, oidField, aclStr, kindStr, ownerStr, secCols,
+		params.CatalogTable, descFunc, oidField, commentTable, subidStr, secStr, schemaStr,
+		strings.Join(conditions, "\n\tAND "), oidField)
 
 	results := make([]MetadataQueryStruct, 0)
 	err := connectionPool.Select(&results, query)
 	gplog.FatalOnError(err)
 
-	return ConstructMetadataMap(results)
+	return b.ConstructMetadataMap(results)
 }
 
 func sortACLs(privileges []ACL) []ACL {
@@ -188,47 +183,6 @@ func sortACLs(privileges []ACL) []ACL {
 	return privileges
 }
 
-func GetCommentsForObjectType(connectionPool *dbconn.DBConn, params MetadataQueryParams) MetadataMap {
-	joinStr := ""
-	if params.SchemaField != "" {
-		joinStr = fmt.Sprintf(`JOIN pg_namespace n ON o.%s = n.oid
-	 WHERE %s`, params.SchemaField, SchemaFilterClause("n"))
-	}
-	descTable := "pg_description"
-	subidStr := " AND d.objsubid = 0"
-	if params.Shared {
-		descTable = "pg_shdescription"
-		subidStr = ""
-	}
-	commentTable := params.CatalogTable
-	if params.CommentTable != "" {
-		commentTable = params.CommentTable
-	}
-	query := fmt.Sprintf(`
-	SELECT '%s'::regclass::oid AS classid,
-		o.%s AS oid,
-		coalesce(description,'') AS comment
-	FROM %s o
-		JOIN %s d ON (d.objoid = %s AND d.classoid = '%s'::regclass%s)
-		%s`, params.CatalogTable, params.OidField, params.CatalogTable, descTable,
-		params.OidField, commentTable, subidStr, joinStr)
-
-	results := make([]struct {
-		UniqueID
-		Comment string
-	}, 0)
-	err := connectionPool.Select(&results, query)
-	gplog.FatalOnError(err)
-
-	metadataMap := make(MetadataMap)
-	if len(results) > 0 {
-		for _, result := range results {
-			metadataMap[result.UniqueID] = ObjectMetadata{[]ACL{}, "", result.Comment, "", ""}
-		}
-	}
-	return metadataMap
-}
-
 type DefaultPrivilegesQueryStruct struct {
 	Oid        uint32
 	Owner      string
@@ -257,8 +211,12 @@ func (dp DefaultPrivileges) GetMetadataEntry() (string, utils.MetadataEntry) {
 		}
 }
 
-func GetDefaultPrivileges(connectionPool *dbconn.DBConn) []DefaultPrivileges {
-	query := `
+/*
+ * Default privileges set IN SCHEMA are only backed up along with their schema,
+ * while those set for the whole database are always backed up.
+ */
+func (b *Backup) GetDefaultPrivileges(connectionPool *dbconn.DBConn) []DefaultPrivileges {
+	query := fmt.Sprintf(`
 	SELECT a.oid,
 		quote_ident(r.rolname) AS owner,
 		coalesce(quote_ident(n.nspname),'') AS schema,
@@ -276,15 +234,16 @@ func GetDefaultPrivileges(connectionPool *dbconn.DBConn) []DefaultPrivileges {
 	FROM pg_default_acl a
 		JOIN pg_roles r ON r.oid = a.defaclrole
 		LEFT JOIN pg_namespace n ON n.oid = a.defaclnamespace
-	ORDER BY n.nspname, a.defaclobjtype, r.rolname`
+	WHERE a.defaclnamespace = 0 OR (%s)
+	ORDER BY n.nspname, a.defaclobjtype, r.rolname`, b.SchemaFilterClause("n"))
 	results := make([]DefaultPrivilegesQueryStruct, 0)
 	err := connectionPool.Select(&results, query)
 	gplog.FatalOnError(err)
 
-	return ConstructDefaultPrivileges(results)
+	return b.ConstructDefaultPrivileges(results)
 }
 
-func GetQuotedRoleNames(connectionPool *dbconn.DBConn) map[string]string {
+func (b *Backup) GetQuotedRoleNames(connectionPool *dbconn.DBConn) map[string]string {
 	results := make([]struct {
 		RoleName       string
 		QuotedRoleName string
@@ -292,9 +251,9 @@ func GetQuotedRoleNames(connectionPool *dbconn.DBConn) map[string]string {
 	query := `SELECT rolname AS rolename, quote_ident(rolname) AS quotedrolename FROM pg_authid`
 	err := connectionPool.Select(&results, query)
 	gplog.FatalOnError(err)
-	quotedRoleNames = make(map[string]string)
+	b.quotedRoleNames = make(map[string]string)
 	for _, result := range results {
-		quotedRoleNames[result.RoleName] = result.QuotedRoleName
+		b.quotedRoleNames[result.RoleName] = result.QuotedRoleName
 	}
-	return quotedRoleNames
+	return b.quotedRoleNames
 }
@@ -24,20 +24,20 @@ var _ = Describe("backup/queries_acl tests", func() {
 		It("queries metadata for an object with default params", func() {
 			mock.ExpectQuery(regexp.QuoteMeta(`
 	SELECT 'table'::regclass::oid AS classid,
-		o.oid,
+		o.oid AS oid,
 		'' AS privileges,
 		'' AS kind,
 		quote_ident(pg_get_userbyid(owner)) AS owner,
 		coalesce(description,'') AS comment
 	FROM table o LEFT JOIN pg_description d ON (d.objoid = o.oid AND d.classoid = 'table'::regclass AND d.objsubid = 0)
-	AND o.oid NOT IN (SELECT objid FROM pg_depend WHERE deptype='e')
+	WHERE o.oid NOT IN (SELECT objid FROM pg_depend WHERE deptype='e')
 	ORDER BY o.oid`)).WillReturnRows(emptyRows)
-			backup.GetMetadataForObjectType(connectionPool, params)
+			testBackup.GetMetadataForObjectType(connectionPool, params)
 		})
 		It("queries metadata for an object with a schema field", func() {
 			mock.ExpectQuery(regexp.QuoteMeta(`
 	SELECT 'table'::regclass::oid AS classid,
-		o.oid,
+		o.oid AS oid,
 		'' AS privileges,
 		'' AS kind,
 		quote_ident(pg_get_userbyid(owner)) AS owner,
@@ -48,12 +48,12 @@ var _ = Describe("backup/queries_acl tests", func() {
 	AND o.oid NOT IN (SELECT objid FROM pg_depend WHERE deptype='e')
 	ORDER BY o.oid`)).WillReturnRows(emptyRows)
 			params.SchemaField = "schema"
-			backup.GetMetadataForObjectType(connectionPool, params)
+			testBackup.GetMetadataForObjectType(connectionPool, params)
 		})
 		It("queries metadata for an object with an ACL field", func() {
 			mock.ExpectQuery(regexp.QuoteMeta(`
 	SELECT 'table'::regclass::oid AS classid,
-		o.oid,
+		o.oid AS oid,
 		CASE
 			WHEN acl IS NULL THEN NULL
 			WHEN array_upper(acl, 1) = 0 THEN acl[0]
@@ -66,24 +66,56 @@ var _ = Describe("backup/queries_acl tests", func() {
 		quote_ident(pg_get_userbyid(owner)) AS owner,
 		coalesce(description,'') AS comment
 	FROM table o LEFT JOIN pg_description d ON (d.objoid = o.oid AND d.classoid = 'table'::regclass AND d.objsubid = 0)
-	AND o.oid NOT IN (SELECT objid FROM pg_depend WHERE deptype='e')
+	WHERE o.oid NOT IN (SELECT objid FROM pg_depend WHERE deptype='e')
 	ORDER BY o.oid`)).WillReturnRows(emptyRows)
 			params.ACLField = "acl"
-			backup.GetMetadataForObjectType(connectionPool, params)
+			testBackup.GetMetadataForObjectType(connectionPool, params)
 		})
 		It("queries metadata for a shared object", func() {
 			mock.ExpectQuery(regexp.QuoteMeta(`
 	SELECT 'table'::regclass::oid AS classid,
-		o.oid,
+		o.oid AS oid,
 		'' AS privileges,
 		'' AS kind,
 		quote_ident(pg_get_userbyid(owner)) AS owner,
 		coalesce(description,'') AS comment
 	FROM table o LEFT JOIN pg_shdescription d ON (d.objoid = o.oid AND d.classoid = 'table'::regclass)
-	AND o.oid NOT IN (SELECT objid FROM pg_depend WHERE deptype='e')
+	WHERE o.oid NOT IN (SELECT objid FROM pg_depend WHERE deptype='e')
 	ORDER BY o.oid`)).WillReturnRows(emptyRows)
 			params.Shared = true
-			backup.GetMetadataForObjectType(connectionPool, params)
+			testBackup.GetMetadataForObjectType(connectionPool, params)
+		})
+		It("queries only commented objects for an object without an owner or privileges", func() {
+			mock.ExpectQuery(regexp.QuoteMeta(`
+	SELECT 'table'::regclass::oid AS classid,
+		o.oid AS oid,
+		'' AS privileges,
+		'' AS kind,
+		'' AS owner,
+		coalesce(description,'') AS comment
+	FROM table o LEFT JOIN pg_description d ON (d.objoid = o.oid AND d.classoid = 'table'::regclass AND d.objsubid = 0)
+	WHERE d.description IS NOT NULL
+	AND o.oid NOT IN (SELECT objid FROM pg_depend WHERE deptype='e')
+	ORDER BY o.oid`)).WillReturnRows(emptyRows)
+			params.OwnerField = ""
+			testBackup.GetMetadataForObjectType(connectionPool, params)
+		})
+		It("queries metadata for an object with a different oid field and comment table", func() {
+			mock.ExpectQuery(regexp.QuoteMeta(`
+	SELECT 'table'::regclass::oid AS classid,
+		o.indexrelid AS oid,
+		'' AS privileges,
+		'' AS kind,
+		'' AS owner,
+		coalesce(description,'') AS comment
+	FROM table o LEFT JOIN pg_description d ON (d.objoid = o.indexrelid AND d.classoid = 'comment_table'::regclass AND d.objsubid = 0)
+	WHERE d.description IS NOT NULL
+	AND o.indexrelid NOT IN (SELECT objid FROM pg_depend WHERE deptype='e')
+	ORDER BY o.indexrelid`)).WillReturnRows(emptyRows)
+			params.OwnerField = ""
+			params.OidField = "indexrelid"
+			params.CommentTable = "comment_table"
+			testBackup.GetMetadataForObjectType(connectionPool, params)
 		})
 		It("returns metadata for multiple objects", func() {
 			aclRowOne := []driver.Value{"1", "gpadmin=a/gpadmin", "testrole", ""}
@@ -97,7 +129,7 @@ var _ = Describe("backup/queries_acl tests", func() {
 			mock.ExpectQuery("SELECT rolname (.*)").
 				WillReturnRows(rolnames)
 			params.ACLField = "acl"
-			resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, params)
+			resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, params)
 
 			expectedOne := backup.ObjectMetadata{Privileges: []backup.ACL{
 				{Grantee: "gpadmin", Insert: true},
@@ -110,47 +142,13 @@ var _ = Describe("backup/queries_acl tests", func() {
 			structmatcher.ExpectStructsToMatch(&expectedOne, &resultOne)
 			structmatcher.ExpectStructsToMatch(&expectedTwo, &resultTwo)
 		})
-	})
-	Describe("GetCommentsForObjectType", func() {
-		var params backup.MetadataQueryParams
-		header := []string{"oid", "comment"}
-		emptyRows := sqlmock.NewRows(header)
-
-		BeforeEach(func() {
-			params = backup.MetadataQueryParams{NameField: "name", OidField: "oid", CatalogTable: "table"}
-		})
-		It("returns comment for object with default params", func() {
-			mock.ExpectQuery(regexp.QuoteMeta(`
-	SELECT 'table'::regclass::oid AS classid,
-		o.oid AS oid,
-		coalesce(description,'') AS comment
-	FROM table o JOIN pg_description d ON (d.objoid = oid AND d.classoid = 'table'::regclass AND d.objsubid = 0)`)).WillReturnRows(emptyRows)
-			backup.GetCommentsForObjectType(connectionPool, params)
-		})
-		It("returns comment for object with different comment table", func() {
-			params.CommentTable = "comment_table"
-			mock.ExpectQuery(regexp.QuoteMeta(`
-	SELECT 'table'::regclass::oid AS classid,
-		o.oid AS oid,
-		coalesce(description,'') AS comment
-	FROM table o JOIN pg_description d ON (d.objoid = oid AND d.classoid = 'comment_table'::regclass AND d.objsubid = 0)`)).WillReturnRows(emptyRows)
-			backup.GetCommentsForObjectType(connectionPool, params)
-		})
-		It("returns comment for a shared object", func() {
-			params.Shared = true
-			mock.ExpectQuery(regexp.QuoteMeta(`
-	SELECT 'table'::regclass::oid AS classid,
-		o.oid AS oid,
-		coalesce(description,'') AS comment
-	FROM table o JOIN pg_shdescription d ON (d.objoid = oid AND d.classoid = 'table'::regclass)`)).WillReturnRows(emptyRows)
-			backup.GetCommentsForObjectType(connectionPool, params)
-		})
-		It("returns comments for multiple objects", func() {
-			rowOne := []driver.Value{"1", "This is a metadata comment."}
-			rowTwo := []driver.Value{"2", "This is also a metadata comment."}
+		It("returns comments for multiple objects without an owner or privileges", func() {
+			rowOne := []driver.Value{"1", "", "", "This is a metadata comment."}
+			rowTwo := []driver.Value{"2", "", "", "This is also a metadata comment."}
 			fakeRows := sqlmock.NewRows(header).AddRow(rowOne...).AddRow(rowTwo...)
 			mock.ExpectQuery(`SELECT (.*)`).WillReturnRows(fakeRows)
-			resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, params)
+			params.OwnerField = ""
+			resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, params)
 
 			expectedOne := backup.ObjectMetadata{Privileges: []backup.ACL{}, Comment: "This is a metadata comment."}
 			expectedTwo := backup.ObjectMetadata{Privileges: []backup.ACL{}, Comment: "This is also a metadata comment."}
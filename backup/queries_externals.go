@@ -102,7 +102,7 @@ func (p ExternalProtocol) FQN() string {
 
 func GetExternalProtocols(connectionPool *dbconn.DBConn) []ExternalProtocol {
 	results := make([]ExternalProtocol, 0)
-	query := `
+	query := fmt.Sprintf(`
 	SELECT p.oid,
 		quote_ident(p.ptcname) AS name,
 		pg_get_userbyid(p.ptcowner) AS owner,
@@ -110,7 +110,8 @@ func GetExternalProtocols(connectionPool *dbconn.DBConn) []ExternalProtocol {
 		p.ptcreadfn,
 		p.ptcwritefn,
 		p.ptcvalidatorfn
-	FROM pg_extprotocol p`
+	FROM pg_extprotocol p
+	WHERE %s`, ExtensionFilterClause("p"))
 	err := connectionPool.Select(&results, query)
 	gplog.FatalOnError(err)
 	return results
@@ -142,6 +143,10 @@ func (pi PartitionInfo) GetMetadataEntry() (string, utils.MetadataEntry) {
 
 func GetExternalPartitionInfo(connectionPool *dbconn.DBConn) ([]PartitionInfo, map[uint32]PartitionInfo) {
 	results := make([]PartitionInfo, 0)
+	if connectionPool.Version.AtLeast("7") {
+		// GPDB 7 partitions are attached with ATTACH PARTITION rather than exchanged
+		return results, make(map[uint32]PartitionInfo)
+	}
 	query := `
 	SELECT pr1.oid AS partitionruleoid,
 		pr1.parparentrule AS partitionparentruleoid,
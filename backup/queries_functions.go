@@ -36,15 +36,21 @@ type Function struct {
 	Language          string
 	IsWindow          bool   `db:"proiswindow"`
 	ExecLocation      string `db:"proexeclocation"`
+	Kind              string `db:"prokind"`
+	TransformTypes    string
 }
 
 func (f Function) GetMetadataEntry() (string, utils.MetadataEntry) {
 	nameWithArgs := fmt.Sprintf("%s(%s)", f.Name, f.IdentArgs)
+	objectType := "FUNCTION"
+	if f.Kind == "p" {
+		objectType = "PROCEDURE"
+	}
 	return "predata",
 		utils.MetadataEntry{
 			Schema:          f.Schema,
 			Name:            nameWithArgs,
-			ObjectType:      "FUNCTION",
+			ObjectType:      objectType,
 			ReferenceObject: "",
 			StartByte:       0,
 			EndByte:         0,
@@ -69,12 +75,12 @@ func (f Function) FQN() string {
  * 5 or later but in GPDB 4.3 we must query pg_proc directly and construct
  * those values here.
  */
-func GetFunctionsAllVersions(connectionPool *dbconn.DBConn) []Function {
+func (b *Backup) GetFunctionsAllVersions(connectionPool *dbconn.DBConn) []Function {
 	var functions []Function
 	if connectionPool.Version.Before("5") {
-		functions = GetFunctions4(connectionPool)
-		arguments, tableArguments := GetFunctionArgsAndIdentArgs(connectionPool)
-		returns := GetFunctionReturnTypes(connectionPool)
+		functions = b.GetFunctions4(connectionPool)
+		arguments, tableArguments := b.GetFunctionArgsAndIdentArgs(connectionPool)
+		returns := b.GetFunctionReturnTypes(connectionPool)
 		for i := range functions {
 			oid := functions[i].Oid
 			functions[i].Arguments = arguments[oid]
@@ -87,14 +93,15 @@ func GetFunctionsAllVersions(connectionPool *dbconn.DBConn) []Function {
 			}
 		}
 	} else {
-		functions = GetFunctions(connectionPool)
+		functions = b.GetFunctions(connectionPool)
 	}
 	return functions
 }
 
-func GetFunctions(connectionPool *dbconn.DBConn) []Function {
+func (b *Backup) GetFunctions(connectionPool *dbconn.DBConn) []Function {
 	excludeImplicitFunctionsClause := ""
 	masterAtts := "'a' AS proexeclocation,"
+	kindClause := "proisagg = 'f'"
 	if connectionPool.Version.AtLeast("6") {
 		masterAtts = "proiswindow,proexeclocation,proleakproof,"
 		// This excludes implicitly created functions. Currently this is only range type functions
@@ -104,6 +111,16 @@ func GetFunctions(connectionPool *dbconn.DBConn) []Function {
 		WHERE classid = 'pg_proc'::regclass::oid
 			AND objid = p.oid AND deptype = 'i')`
 	}
+	if connectionPool.Version.AtLeast("7") {
+		/*
+		 * GPDB 7 replaces proisagg and proiswindow with prokind, which also
+		 * distinguishes procedures from functions.
+		 */
+		masterAtts = `prokind = 'w' AS proiswindow, prokind, proexeclocation, proleakproof,
+		coalesce(array_to_string(ARRAY(SELECT 'FOR TYPE ' || pg_catalog.format_type(t, NULL)
+			FROM unnest(protrftypes) t), ', '), '') AS transformtypes,`
+		kindClause = "prokind <> 'a'"
+	}
 	query := fmt.Sprintf(`
 	SELECT p.oid,
 		quote_ident(nspname) AS schema,
@@ -113,7 +130,7 @@ func GetFunctions(connectionPool *dbconn.DBConn) []Function {
 		coalesce(probin, '') AS binarypath,
 		pg_catalog.pg_get_function_arguments(p.oid) AS arguments,
 		pg_catalog.pg_get_function_identity_arguments(p.oid) AS identargs,
-		pg_catalog.pg_get_function_result(p.oid) AS resulttype,
+		coalesce(pg_catalog.pg_get_function_result(p.oid), '') AS resulttype,
 		provolatile,
 		proisstrict,
 		prosecdef,
@@ -127,9 +144,9 @@ func GetFunctions(connectionPool *dbconn.DBConn) []Function {
 	FROM pg_proc p
 		LEFT JOIN pg_namespace n ON p.pronamespace = n.oid
 	WHERE %s
-		AND proisagg = 'f'
+		AND %s
 		AND %s%s
-	ORDER BY nspname, proname, identargs`, masterAtts, SchemaFilterClause("n"), ExtensionFilterClause("p"), excludeImplicitFunctionsClause)
+	ORDER BY nspname, proname, identargs`, masterAtts, b.SchemaFilterClause("n"), kindClause, ExtensionFilterClause("p"), excludeImplicitFunctionsClause)
 
 	results := make([]Function, 0)
 	err := connectionPool.Select(&results, query)
@@ -204,7 +221,7 @@ func UnescapeDoubleQuote(value string) string {
  * In addition to lacking the pg_get_function_* functions, GPDB 4.3 lacks
  * several columns in pg_proc compared to GPDB 5, so we don't retrieve those.
  */
-func GetFunctions4(connectionPool *dbconn.DBConn) []Function {
+func (b *Backup) GetFunctions4(connectionPool *dbconn.DBConn) []Function {
 	query := fmt.Sprintf(`
 	SELECT p.oid,
 		quote_ident(nspname) AS schema,
@@ -221,7 +238,7 @@ func GetFunctions4(connectionPool *dbconn.DBConn) []Function {
 		LEFT JOIN pg_namespace n ON p.pronamespace = n.oid
 	WHERE %s
 		AND proisagg = 'f'
-	ORDER BY nspname, proname`, SchemaFilterClause("n"))
+	ORDER BY nspname, proname`, b.SchemaFilterClause("n"))
 
 	results := make([]Function, 0)
 	err := connectionPool.Select(&results, query)
@@ -234,7 +251,15 @@ func GetFunctions4(connectionPool *dbconn.DBConn) []Function {
  * difference between a function's "arguments" and "identity arguments" and
  * we can use the same map for both fields.
  */
-func GetFunctionArgsAndIdentArgs(connectionPool *dbconn.DBConn) (map[uint32]string, map[uint32]string) {
+func (b *Backup) GetFunctionArgsAndIdentArgs(connectionPool *dbconn.DBConn) (map[uint32]string, map[uint32]string) {
+	result := b.memoizeCatalogQuery("function arguments", func() interface{} {
+		argMap, tableArgMap := getFunctionArgsAndIdentArgs(connectionPool)
+		return [2]map[uint32]string{argMap, tableArgMap}
+	}).([2]map[uint32]string)
+	return result[0], result[1]
+}
+
+func getFunctionArgsAndIdentArgs(connectionPool *dbconn.DBConn) (map[uint32]string, map[uint32]string) {
 	query := `
 	SELECT p.oid,
 		CASE WHEN proallargtypes IS NOT NULL THEN format_type(unnest(proallargtypes), NULL)
@@ -293,7 +318,7 @@ func GetFunctionArgsAndIdentArgs(connectionPool *dbconn.DBConn) (map[uint32]stri
 	return argMap, tableArgMap
 }
 
-func GetFunctionReturnTypes(connectionPool *dbconn.DBConn) map[uint32]Function {
+func (b *Backup) GetFunctionReturnTypes(connectionPool *dbconn.DBConn) map[uint32]Function {
 	query := fmt.Sprintf(`
 	SELECT p.oid,
 		proretset,
@@ -301,7 +326,7 @@ func GetFunctionReturnTypes(connectionPool *dbconn.DBConn) map[uint32]Function {
 			ELSE format_type(prorettype, NULL) END AS resulttype
 	FROM pg_proc p
 		JOIN pg_namespace n ON p.pronamespace = n.oid
-	WHERE %s`, SchemaFilterClause("n"))
+	WHERE %s`, b.SchemaFilterClause("n"))
 
 	results := make([]Function, 0)
 	err := connectionPool.Select(&results, query)
@@ -374,7 +399,7 @@ func (a Aggregate) FQN() string {
 	return fmt.Sprintf("%s.%s(%s)", a.Schema, a.Name, identArgumentsStr)
 }
 
-func GetAggregates(connectionPool *dbconn.DBConn) []Aggregate {
+func (b *Backup) GetAggregates(connectionPool *dbconn.DBConn) []Aggregate {
 	version4query := fmt.Sprintf(`
 	SELECT p.oid,
 		quote_ident(n.nspname) AS schema,
@@ -396,7 +421,7 @@ func GetAggregates(connectionPool *dbconn.DBConn) []Aggregate {
 		LEFT JOIN pg_namespace n ON p.pronamespace = n.oid
 		LEFT JOIN pg_operator o ON a.aggsortop = o.oid
 		LEFT JOIN pg_namespace opn ON o.oprnamespace = opn.oid
-	WHERE %s`, SchemaFilterClause("n"))
+	WHERE %s`, b.SchemaFilterClause("n"))
 
 	version5query := fmt.Sprintf(`
 	SELECT p.oid,
@@ -421,7 +446,7 @@ func GetAggregates(connectionPool *dbconn.DBConn) []Aggregate {
 		LEFT JOIN pg_namespace opn ON o.oprnamespace = opn.oid
 	WHERE %s
 		AND %s`,
-		SchemaFilterClause("n"), ExtensionFilterClause("p"))
+		b.SchemaFilterClause("n"), ExtensionFilterClause("p"))
 
 	masterQuery := fmt.Sprintf(`
 	SELECT p.oid,
@@ -457,7 +482,7 @@ func GetAggregates(connectionPool *dbconn.DBConn) []Aggregate {
 		LEFT JOIN pg_namespace opn ON o.oprnamespace = opn.oid
 	WHERE %s
 		AND %s`,
-		SchemaFilterClause("n"), ExtensionFilterClause("p"))
+		b.SchemaFilterClause("n"), ExtensionFilterClause("p"))
 
 	aggregates := make([]Aggregate, 0)
 	query := ""
@@ -476,7 +501,7 @@ func GetAggregates(connectionPool *dbconn.DBConn) []Aggregate {
 		}
 	}
 	if connectionPool.Version.Before("5") {
-		arguments, _ := GetFunctionArgsAndIdentArgs(connectionPool)
+		arguments, _ := b.GetFunctionArgsAndIdentArgs(connectionPool)
 		for i := range aggregates {
 			oid := aggregates[i].Oid
 			aggregates[i].Arguments = arguments[oid]
@@ -492,7 +517,7 @@ type FunctionInfo struct {
 	IsInternal    bool
 }
 
-func GetFunctionOidToInfoMap(connectionPool *dbconn.DBConn) map[uint32]FunctionInfo {
+func (b *Backup) GetFunctionOidToInfoMap(connectionPool *dbconn.DBConn) map[uint32]FunctionInfo {
 	version4query := `
 	SELECT p.oid,
 		quote_ident(n.nspname) AS schema,
@@ -517,7 +542,7 @@ func GetFunctionOidToInfoMap(connectionPool *dbconn.DBConn) map[uint32]FunctionI
 	var err error
 	if connectionPool.Version.Before("5") {
 		err = connectionPool.Select(&results, version4query)
-		arguments, _ := GetFunctionArgsAndIdentArgs(connectionPool)
+		arguments, _ := b.GetFunctionArgsAndIdentArgs(connectionPool)
 		for i := range results {
 			results[i].Arguments = arguments[results[i].Oid]
 		}
@@ -575,7 +600,7 @@ func (c Cast) FQN() string {
 	return fmt.Sprintf("(%s AS %s)", c.SourceTypeFQN, c.TargetTypeFQN)
 }
 
-func GetCasts(connectionPool *dbconn.DBConn) []Cast {
+func (b *Backup) GetCasts(connectionPool *dbconn.DBConn) []Cast {
 	/* This query retrieves all casts where either the source type, the target
 	 * type, or the cast function is user-defined.
 	 */
@@ -612,14 +637,14 @@ func GetCasts(connectionPool *dbconn.DBConn) []Cast {
 	WHERE ((%s) OR (%s) OR (%s))
 		AND %s
 	ORDER BY 1, 2`, argStr, methodStr,
-		SchemaFilterClause("sn"), SchemaFilterClause("tn"),
-		SchemaFilterClause("n"), ExtensionFilterClause("c"))
+		b.SchemaFilterClause("sn"), b.SchemaFilterClause("tn"),
+		b.SchemaFilterClause("n"), ExtensionFilterClause("c"))
 
 	casts := make([]Cast, 0)
 	err := connectionPool.Select(&casts, query)
 	gplog.FatalOnError(err)
 	if connectionPool.Version.Before("5") {
-		arguments, _ := GetFunctionArgsAndIdentArgs(connectionPool)
+		arguments, _ := b.GetFunctionArgsAndIdentArgs(connectionPool)
 		for i := range casts {
 			oid := casts[i].FunctionOid
 			casts[i].FunctionArgs = arguments[oid]
@@ -628,10 +653,82 @@ func GetCasts(connectionPool *dbconn.DBConn) []Cast {
 	return casts
 }
 
+type Transform struct {
+	Oid         uint32
+	TypeFQN     string
+	Language    string
+	FromSQLFunc string
+	ToSQLFunc   string
+}
+
+func (t Transform) GetMetadataEntry() (string, utils.MetadataEntry) {
+	return "predata",
+		utils.MetadataEntry{
+			Schema:          t.Schema(),
+			Name:            fmt.Sprintf("FOR %s LANGUAGE %s", t.TypeFQN, t.Language),
+			ObjectType:      "TRANSFORM",
+			ReferenceObject: "",
+			StartByte:       0,
+			EndByte:         0,
+		}
+}
+
+/*
+ * Transforms do not belong to a schema, so the schema of the transformed type
+ * is used to allow restore filtering.
+ */
+func (t Transform) Schema() string {
+	return strings.SplitN(t.TypeFQN, ".", 2)[0]
+}
+
+func (t Transform) GetUniqueID() UniqueID {
+	return UniqueID{ClassID: PG_TRANSFORM_OID, Oid: t.Oid}
+}
+
+func (t Transform) FQN() string {
+	return fmt.Sprintf("FOR %s LANGUAGE %s", t.TypeFQN, t.Language)
+}
+
+func (b *Backup) GetTransforms(connectionPool *dbconn.DBConn) []Transform {
+	/*
+	 * This query retrieves all transforms where either the type or one of the
+	 * transform functions is user-defined.
+	 */
+	query := fmt.Sprintf(`
+	SELECT
+		tr.oid,
+		quote_ident(tn.nspname) || '.' || quote_ident(t.typname) AS typefqn,
+		quote_ident(l.lanname) AS language,
+		coalesce(quote_ident(fn.nspname) || '.' || quote_ident(f.proname) || '(' || pg_get_function_identity_arguments(f.oid) || ')', '') AS fromsqlfunc,
+		coalesce(quote_ident(gn.nspname) || '.' || quote_ident(g.proname) || '(' || pg_get_function_identity_arguments(g.oid) || ')', '') AS tosqlfunc
+	FROM pg_transform tr
+		JOIN pg_type t ON tr.trftype = t.oid
+		JOIN pg_namespace tn ON t.typnamespace = tn.oid
+		JOIN pg_language l ON tr.trflang = l.oid
+		LEFT JOIN pg_proc f ON tr.trffromsql = f.oid
+		LEFT JOIN pg_namespace fn ON f.pronamespace = fn.oid
+		LEFT JOIN pg_proc g ON tr.trftosql = g.oid
+		LEFT JOIN pg_namespace gn ON g.pronamespace = gn.oid
+	WHERE ((%s) OR (%s) OR (%s))
+		AND %s
+	ORDER BY 2, 3`, b.SchemaFilterClause("tn"), b.SchemaFilterClause("fn"),
+		b.SchemaFilterClause("gn"), ExtensionFilterClause("tr"))
+
+	transforms := make([]Transform, 0)
+	err := connectionPool.Select(&transforms, query)
+	gplog.FatalOnError(err)
+	return transforms
+}
+
+/*
+ * Version is the installed version of the extension, which is restored so
+ * that the objects it creates match those the backed up data depends on.
+ */
 type Extension struct {
-	Oid    uint32
-	Name   string
-	Schema string
+	Oid     uint32
+	Name    string
+	Schema  string
+	Version string
 }
 
 func (e Extension) GetMetadataEntry() (string, utils.MetadataEntry) {
@@ -660,7 +757,8 @@ func GetExtensions(connectionPool *dbconn.DBConn) []Extension {
 	query := `
 	SELECT e.oid,
 		quote_ident(extname) AS name,
-		quote_ident(n.nspname) AS schema
+		quote_ident(n.nspname) AS schema,
+		e.extversion AS version
 	FROM pg_extension e
 		JOIN pg_namespace n ON e.extnamespace = n.oid`
 	err := connectionPool.Select(&results, query)
@@ -767,7 +865,7 @@ func (c Conversion) FQN() string {
 	return utils.MakeFQN(c.Schema, c.Name)
 }
 
-func GetConversions(connectionPool *dbconn.DBConn) []Conversion {
+func (b *Backup) GetConversions(connectionPool *dbconn.DBConn) []Conversion {
 	results := make([]Conversion, 0)
 	query := fmt.Sprintf(`
 	SELECT c.oid,
@@ -783,7 +881,7 @@ func GetConversions(connectionPool *dbconn.DBConn) []Conversion {
 		JOIN pg_namespace fn ON p.pronamespace = fn.oid
 	WHERE %s
 		AND %s
-	ORDER BY n.nspname, c.conname`, SchemaFilterClause("n"), ExtensionFilterClause("c"))
+	ORDER BY n.nspname, c.conname`, b.SchemaFilterClause("n"), ExtensionFilterClause("c"))
 
 	err := connectionPool.Select(&results, query)
 	gplog.FatalOnError(err)
@@ -836,6 +934,20 @@ func GetForeignDataWrappers(connectionPool *dbconn.DBConn) []ForeignDataWrapper
 	return results
 }
 
+func FilterForeignDataWrappersForServers(wrappers []ForeignDataWrapper, servers []ForeignServer) []ForeignDataWrapper {
+	usedWrappers := make(map[string]bool)
+	for _, server := range servers {
+		usedWrappers[server.ForeignDataWrapper] = true
+	}
+	filteredWrappers := make([]ForeignDataWrapper, 0)
+	for _, wrapper := range wrappers {
+		if usedWrappers[wrapper.Name] {
+			filteredWrappers = append(filteredWrappers, wrapper)
+		}
+	}
+	return filteredWrappers
+}
+
 type ForeignServer struct {
 	Oid                uint32
 	Name               string
@@ -885,6 +997,22 @@ func GetForeignServers(connectionPool *dbconn.DBConn) []ForeignServer {
 	return results
 }
 
+func FilterForeignServersForTables(servers []ForeignServer, tables []Table) []ForeignServer {
+	usedServers := make(map[string]bool)
+	for _, table := range tables {
+		if table.ForeignDef != (ForeignTableDefinition{}) {
+			usedServers[table.ForeignDef.Server] = true
+		}
+	}
+	filteredServers := make([]ForeignServer, 0)
+	for _, server := range servers {
+		if usedServers[server.Name] {
+			filteredServers = append(filteredServers, server)
+		}
+	}
+	return filteredServers
+}
+
 type UserMapping struct {
 	Oid     uint32
 	User    string
@@ -930,3 +1058,17 @@ func GetUserMappings(connectionPool *dbconn.DBConn) []UserMapping {
 	gplog.FatalOnError(err)
 	return results
 }
+
+func FilterUserMappingsForServers(mappings []UserMapping, servers []ForeignServer) []UserMapping {
+	usedServers := make(map[string]bool)
+	for _, server := range servers {
+		usedServers[server.Name] = true
+	}
+	filteredMappings := make([]UserMapping, 0)
+	for _, mapping := range mappings {
+		if usedServers[mapping.Server] {
+			filteredMappings = append(filteredMappings, mapping)
+		}
+	}
+	return filteredMappings
+}
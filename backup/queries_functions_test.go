@@ -1,6 +1,7 @@
 package backup_test
 
 import (
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/greenplum-db/gpbackup/backup"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -38,6 +39,27 @@ SET BAZ TO abc`},
 SET baz to abc`))
 		})
 	})
+	Describe("GetFunctionArgsAndIdentArgs", func() {
+		AfterEach(func() {
+			testBackup.SetCatalogQueryCache(nil)
+		})
+		It("only queries the catalog once when the catalog query cache is enabled", func() {
+			testBackup.SetCatalogQueryCache(make(map[string]interface{}))
+			argRows := sqlmock.NewRows([]string{"oid", "type", "name", "mode"}).
+				AddRow(1, "integer", "a", "").
+				AddRow(1, "text", "b", "t")
+			mock.ExpectQuery(`SELECT (.*)`).WillReturnRows(argRows)
+
+			arguments, tableArguments := testBackup.GetFunctionArgsAndIdentArgs(connectionPool)
+			cachedArguments, cachedTableArguments := testBackup.GetFunctionArgsAndIdentArgs(connectionPool)
+
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+			Expect(arguments).To(Equal(map[uint32]string{1: "a integer"}))
+			Expect(tableArguments).To(Equal(map[uint32]string{1: "b text"}))
+			Expect(cachedArguments).To(Equal(arguments))
+			Expect(cachedTableArguments).To(Equal(tableArguments))
+		})
+	})
 	Describe("QuoteGUCValue", func() {
 		It("returns correct value for a name/value pair", func() {
 			result := backup.QuoteGUCValue("foo", `bar`)
@@ -70,4 +92,25 @@ SET baz to abc`))
 			Expect(result).To(Equal(`"foo"`))
 		})
 	})
+	Describe("Foreign object filtering", func() {
+		servers := []backup.ForeignServer{
+			{Oid: 1, Name: "used_server", ForeignDataWrapper: "used_fdw"},
+			{Oid: 2, Name: "unused_server", ForeignDataWrapper: "unused_fdw"},
+		}
+		It("keeps only the servers used by foreign tables", func() {
+			tables := []backup.Table{
+				{Relation: backup.Relation{Oid: 3, Schema: "public", Name: "foreign_table"}, TableDefinition: backup.TableDefinition{ForeignDef: backup.ForeignTableDefinition{Oid: 3, Server: "used_server"}}},
+				{Relation: backup.Relation{Oid: 4, Schema: "public", Name: "regular_table"}},
+			}
+			Expect(backup.FilterForeignServersForTables(servers, tables)).To(Equal(servers[:1]))
+		})
+		It("keeps only the foreign data wrappers used by servers", func() {
+			wrappers := []backup.ForeignDataWrapper{{Oid: 5, Name: "used_fdw"}, {Oid: 6, Name: "unused_fdw"}}
+			Expect(backup.FilterForeignDataWrappersForServers(wrappers, servers[:1])).To(Equal(wrappers[:1]))
+		})
+		It("keeps only the user mappings for servers", func() {
+			mappings := []backup.UserMapping{{Oid: 7, User: "testrole", Server: "used_server"}, {Oid: 8, User: "testrole", Server: "unused_server"}}
+			Expect(backup.FilterUserMappingsForServers(mappings, servers[:1])).To(Equal(mappings[:1]))
+		})
+	})
 })
@@ -29,7 +29,13 @@ func (sg SessionGUCs) GetMetadataEntry() (string, utils.MetadataEntry) {
 		}
 }
 
-func GetSessionGUCs(connectionPool *dbconn.DBConn) SessionGUCs {
+func (b *Backup) GetSessionGUCs(connectionPool *dbconn.DBConn) SessionGUCs {
+	return b.memoizeCatalogQuery("session GUCs", func() interface{} {
+		return getSessionGUCs(connectionPool)
+	}).(SessionGUCs)
+}
+
+func getSessionGUCs(connectionPool *dbconn.DBConn) SessionGUCs {
 	result := SessionGUCs{}
 	query := "SHOW client_encoding;"
 	err := connectionPool.Get(&result, query)
@@ -181,6 +187,11 @@ func GetResourceQueues(connectionPool *dbconn.DBConn) []ResourceQueue {
 	return results
 }
 
+/*
+ * In GPDB 7, resource groups no longer manage shared memory or spill ratios,
+ * and CPURateLimit holds the group's CPU_MAX_PERCENT, which is -1 when it
+ * uses a CPUSET instead.  CPUWeight and MinCost are only set in GPDB 7.
+ */
 type ResourceGroup struct {
 	Oid               uint32
 	Name              string
@@ -191,6 +202,8 @@ type ResourceGroup struct {
 	MemorySpillRatio  string
 	MemoryAuditor     string
 	Cpuset            string
+	CPUWeight         string
+	MinCost           string
 }
 
 func (rg ResourceGroup) GetMetadataEntry() (string, utils.MetadataEntry) {
@@ -214,6 +227,9 @@ func (rg ResourceGroup) FQN() string {
 }
 
 func GetResourceGroups(connectionPool *dbconn.DBConn) []ResourceGroup {
+	if connectionPool.Version.AtLeast("7") {
+		return getResourceGroupsAtLeast7(connectionPool)
+	}
 	selectClause := ""
 	// This is when pg_dumpall was changed to use the actual values
 	if connectionPool.Version.AtLeast("5.2.0") {
@@ -275,6 +291,30 @@ func GetResourceGroups(connectionPool *dbconn.DBConn) []ResourceGroup {
 	return results
 }
 
+func getResourceGroupsAtLeast7(connectionPool *dbconn.DBConn) []ResourceGroup {
+	query := `
+	SELECT g.oid,
+		quote_ident(g.rsgname) AS name,
+		t1.value AS concurrency,
+		t2.value AS cpuratelimit,
+		t3.value AS cpuweight,
+		coalesce(t4.value, '') AS cpuset,
+		coalesce(t5.value, '') AS memorylimit,
+		coalesce(t6.value, '') AS mincost
+	FROM pg_resgroup g
+		JOIN pg_resgroupcapability t1 ON t1.resgroupid = g.oid AND t1.reslimittype = 1
+		JOIN pg_resgroupcapability t2 ON t2.resgroupid = g.oid AND t2.reslimittype = 2
+		JOIN pg_resgroupcapability t3 ON t3.resgroupid = g.oid AND t3.reslimittype = 3
+		LEFT JOIN pg_resgroupcapability t4 ON t4.resgroupid = g.oid AND t4.reslimittype = 4
+		LEFT JOIN pg_resgroupcapability t5 ON t5.resgroupid = g.oid AND t5.reslimittype = 5
+		LEFT JOIN pg_resgroupcapability t6 ON t6.resgroupid = g.oid AND t6.reslimittype = 6`
+
+	results := make([]ResourceGroup, 0)
+	err := connectionPool.Select(&results, query)
+	gplog.FatalOnError(err)
+	return results
+}
+
 type TimeConstraint struct {
 	Oid       uint32
 	StartDay  int
@@ -526,7 +566,7 @@ func (t Tablespace) FQN() string {
 	return t.Tablespace
 }
 
-func GetTablespaces(connectionPool *dbconn.DBConn) []Tablespace {
+func (b *Backup) GetTablespaces(connectionPool *dbconn.DBConn) []Tablespace {
 	before6query := `
 	SELECT t.oid,
 		quote_ident(t.spcname) AS tablespace,
@@ -535,11 +575,12 @@ func GetTablespaces(connectionPool *dbconn.DBConn) []Tablespace {
 		JOIN pg_filespace f ON t.spcfsoid = f.oid
 	WHERE spcname != 'pg_default'
 		AND spcname != 'pg_global'`
+	// The contentN options give the locations on individual segments, which are dumped with the location
 	query := `
 	SELECT oid,
 		quote_ident(spcname) AS tablespace,
 		'''' || pg_catalog.pg_tablespace_location(oid) || '''' AS filelocation,
-		coalesce(array_to_string(spcoptions, ', '), '') AS options
+		coalesce(array_to_string(ARRAY(SELECT o FROM unnest(spcoptions) o WHERE o !~ '^content[0-9]+='), ', '), '') AS options
 	FROM pg_tablespace
 	WHERE spcname != 'pg_default'
 		AND spcname != 'pg_global'`
@@ -550,15 +591,39 @@ func GetTablespaces(connectionPool *dbconn.DBConn) []Tablespace {
 		err = connectionPool.Select(&results, before6query)
 	} else {
 		err = connectionPool.Select(&results, query)
-		for i := 0; i < len(results); i++ {
-			results[i].SegmentLocations = GetSegmentTablespaces(connectionPool, results[i].Oid)
+		/*
+		 * Before GPDB 7 the segments' locations can only be read by dispatching
+		 * to the segments, which a utility mode connection cannot do.
+		 */
+		if b.MustGetFlagBool(utils.UTILITY_MODE) && connectionPool.Version.Before("7") {
+			if len(results) > 0 {
+				gplog.Warn("Segment-specific tablespace locations cannot be read in utility mode, so tablespaces will be backed up with only their master locations")
+			}
+		} else {
+			for i := 0; i < len(results); i++ {
+				results[i].SegmentLocations = GetSegmentTablespaces(connectionPool, results[i].Oid)
+			}
 		}
 	}
 	gplog.FatalOnError(err)
 	return results
 }
 
+/*
+ * GPDB 7 records the locations that differ from the master's only in the
+ * tablespace's contentN options, so they are read from there rather than from
+ * the segments.
+ */
 func GetSegmentTablespaces(connectionPool *dbconn.DBConn, Oid uint32) []string {
+	if connectionPool.Version.AtLeast("7") {
+		query := fmt.Sprintf(`
+	SELECT split_part(o, '=', 1) || '=' || quote_literal(substring(o from position('=' in o) + 1)) AS string
+	FROM (SELECT unnest(spcoptions) AS o FROM pg_tablespace WHERE oid = %d) opts
+	WHERE o ~ '^content[0-9]+='
+	ORDER BY substring(o from '^content([0-9]+)')::int;`, Oid)
+
+		return dbconn.MustSelectStringSlice(connectionPool, query)
+	}
 	query := fmt.Sprintf(`
 	SELECT 'content' || gp_segment_id || '=''' || tblspc_loc || '''' AS string
 	FROM gp_tablespace_segment_location(%d)
@@ -568,7 +633,7 @@ func GetSegmentTablespaces(connectionPool *dbconn.DBConn, Oid uint32) []string {
 	return dbconn.MustSelectStringSlice(connectionPool, query)
 }
 
-//Potentially expensive query
+// Potentially expensive query
 func GetDBSize(connectionPool *dbconn.DBConn) string {
 	size := struct{ DBSize string }{}
 	sizeQuery := fmt.Sprintf("SELECT pg_size_pretty(pg_database_size('%s')) as dbsize",
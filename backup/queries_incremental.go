@@ -8,11 +8,11 @@ import (
 	"github.com/greenplum-db/gpbackup/utils"
 )
 
-func GetAOIncrementalMetadata(connectionPool *dbconn.DBConn) map[string]utils.AOEntry {
+func (b *Backup) GetAOIncrementalMetadata(connectionPool *dbconn.DBConn) map[string]utils.AOEntry {
 	gplog.Verbose("Querying table row mod counts")
-	var modCounts = getAllModCounts(connectionPool)
+	var modCounts = b.getAllModCounts(connectionPool)
 	gplog.Verbose("Querying last DDL modification timestamp for tables")
-	var lastDDLTimestamps = getLastDDLTimestamps(connectionPool)
+	var lastDDLTimestamps = b.getLastDDLTimestamps(connectionPool)
 	aoTableEntries := make(map[string]utils.AOEntry)
 	for aoTableFQN := range modCounts {
 		aoTableEntries[aoTableFQN] = utils.AOEntry{
@@ -24,8 +24,8 @@ func GetAOIncrementalMetadata(connectionPool *dbconn.DBConn) map[string]utils.AO
 	return aoTableEntries
 }
 
-func getAllModCounts(connectionPool *dbconn.DBConn) map[string]int64 {
-	var segTableFQNs = getAOSegTableFQNs(connectionPool)
+func (b *Backup) getAllModCounts(connectionPool *dbconn.DBConn) map[string]int64 {
+	var segTableFQNs = b.getAOSegTableFQNs(connectionPool)
 	modCounts := make(map[string]int64)
 	for aoTableFQN, segTableFQN := range segTableFQNs {
 		modCounts[aoTableFQN] = getModCount(connectionPool, segTableFQN)
@@ -33,7 +33,7 @@ func getAllModCounts(connectionPool *dbconn.DBConn) map[string]int64 {
 	return modCounts
 }
 
-func getAOSegTableFQNs(connectionPool *dbconn.DBConn) map[string]string {
+func (b *Backup) getAOSegTableFQNs(connectionPool *dbconn.DBConn) map[string]string {
 	query := fmt.Sprintf(`
 	SELECT seg.aotablefqn,
 		'pg_aoseg.' || quote_ident(aoseg_c.relname) AS aosegtablefqn
@@ -49,7 +49,7 @@ func getAOSegTableFQNs(connectionPool *dbconn.DBConn) map[string]string {
 					WHERE relstorage IN ( 'ao', 'co' )
 						AND %s
 				) aotables ON pg_ao.relid = aotables.oid
-		) seg ON aoseg_c.oid = seg.segrelid`, relationAndSchemaFilterClause())
+		) seg ON aoseg_c.oid = seg.segrelid`, b.relationAndSchemaFilterClause())
 	results := make([]struct {
 		AOTableFQN    string
 		AOSegTableFQN string
@@ -75,7 +75,7 @@ func getModCount(connectionPool *dbconn.DBConn, aosegtablefqn string) int64 {
 	return results[0].Modcount
 }
 
-func getLastDDLTimestamps(connectionPool *dbconn.DBConn) map[string]string {
+func (b *Backup) getLastDDLTimestamps(connectionPool *dbconn.DBConn) map[string]string {
 	query := fmt.Sprintf(`
 	SELECT quote_ident(aoschema) || '.' || quote_ident(aorelname) as aotablefqn,
 		lastddltimestamp
@@ -93,7 +93,7 @@ func getLastDDLTimestamps(connectionPool *dbconn.DBConn) map[string]string {
 			WHERE lo.staactionname IN ('CREATE', 'ALTER', 'TRUNCATE')
 			GROUP BY lo.objid
 		) lastop
-	ON aotables.aooid = lastop.objid`, relationAndSchemaFilterClause())
+	ON aotables.aooid = lastop.objid`, b.relationAndSchemaFilterClause())
 
 	var results []struct {
 		AOTableFQN       string
@@ -0,0 +1,79 @@
+package backup
+
+/*
+ * This file contains structs and functions related to executing specific
+ * queries to gather metadata and data for large objects.
+ */
+
+import (
+	"fmt"
+
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpbackup/utils"
+)
+
+/*
+ * Large object data is read and written in chunks of this many bytes, which
+ * keeps both the memory used by gpbackup and the size of each restore
+ * statement bounded regardless of the size of the large object.
+ */
+const LARGE_OBJECT_CHUNK_SIZE = 1024 * 1024
+
+type LargeObject struct {
+	Oid uint32
+}
+
+/*
+ * Large objects do not belong to a schema, so they are not affected by schema
+ * or table filtering on backup or restore.
+ */
+func (lo LargeObject) GetMetadataEntry() (string, utils.MetadataEntry) {
+	return "largeobjects",
+		utils.MetadataEntry{
+			Schema:          "",
+			Name:            lo.FQN(),
+			ObjectType:      "LARGE OBJECT",
+			ReferenceObject: "",
+			StartByte:       0,
+			EndByte:         0,
+		}
+}
+
+func (lo LargeObject) GetUniqueID() UniqueID {
+	return UniqueID{ClassID: PG_LARGEOBJECT_METADATA_OID, Oid: lo.Oid}
+}
+
+func (lo LargeObject) FQN() string {
+	return fmt.Sprintf("%d", lo.Oid)
+}
+
+func GetLargeObjects(connectionPool *dbconn.DBConn) []LargeObject {
+	query := `
+	SELECT oid
+	FROM pg_largeobject_metadata
+	ORDER BY oid`
+
+	results := make([]LargeObject, 0)
+	err := connectionPool.Select(&results, query)
+	gplog.FatalOnError(err)
+	return results
+}
+
+/*
+ * Returns at most LARGE_OBJECT_CHUNK_SIZE bytes of the large object starting
+ * at offset, so a chunk shorter than that is the last one.
+ */
+func GetLargeObjectChunk(connectionPool *dbconn.DBConn, oid uint32, offset int64) []byte {
+	query := fmt.Sprintf(`SELECT pg_catalog.lo_get(%d, %d, %d) AS data`, oid, offset, LARGE_OBJECT_CHUNK_SIZE)
+
+	results := make([]struct {
+		Data []byte
+	}, 0)
+	err := connectionPool.Select(&results, query)
+	gplog.FatalOnError(err)
+	if len(results) == 0 {
+		return []byte{}
+	}
+	return results[0].Data
+}
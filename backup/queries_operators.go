@@ -56,7 +56,7 @@ func (o Operator) FQN() string {
 	return fmt.Sprintf("%s.%s (%s, %s)", o.Schema, o.Name, leftArg, rightArg)
 }
 
-func GetOperators(connectionPool *dbconn.DBConn) []Operator {
+func (b *Backup) GetOperators(connectionPool *dbconn.DBConn) []Operator {
 	results := make([]Operator, 0)
 	version4query := fmt.Sprintf(`
 	SELECT o.oid AS oid,
@@ -72,7 +72,7 @@ func GetOperators(connectionPool *dbconn.DBConn) []Operator {
 		oprcanhash AS canhash
 	FROM pg_operator o
 		JOIN pg_namespace n on n.oid = o.oprnamespace
-	WHERE %s AND oprcode != 0`, SchemaFilterClause("n"))
+	WHERE %s AND oprcode != 0`, b.SchemaFilterClause("n"))
 
 	masterQuery := fmt.Sprintf(`
 	SELECT o.oid AS oid,
@@ -90,7 +90,7 @@ func GetOperators(connectionPool *dbconn.DBConn) []Operator {
 	FROM pg_operator o
 		JOIN pg_namespace n on n.oid = o.oprnamespace
 	WHERE %s AND oprcode != 0
-		AND %s`, SchemaFilterClause("n"), ExtensionFilterClause("o"))
+		AND %s`, b.SchemaFilterClause("n"), ExtensionFilterClause("o"))
 
 	var err error
 	if connectionPool.Version.Before("5") {
@@ -134,7 +134,7 @@ func (opf OperatorFamily) FQN() string {
 	return fmt.Sprintf("%s USING %s", utils.MakeFQN(opf.Schema, opf.Name), opf.IndexMethod)
 }
 
-func GetOperatorFamilies(connectionPool *dbconn.DBConn) []OperatorFamily {
+func (b *Backup) GetOperatorFamilies(connectionPool *dbconn.DBConn) []OperatorFamily {
 	results := make([]OperatorFamily, 0)
 	query := fmt.Sprintf(`
 	SELECT o.oid AS oid,
@@ -145,7 +145,88 @@ func GetOperatorFamilies(connectionPool *dbconn.DBConn) []OperatorFamily {
 		JOIN pg_namespace n on n.oid = o.opfnamespace
 	WHERE %s
 		AND %s`,
-		SchemaFilterClause("n"), ExtensionFilterClause("o"))
+		b.SchemaFilterClause("n"), ExtensionFilterClause("o"))
+	err := connectionPool.Select(&results, query)
+	gplog.FatalOnError(err)
+	return results
+}
+
+/*
+ * Operators and support functions can be added to an operator family with
+ * ALTER OPERATOR FAMILY ... ADD, in which case they depend on the family
+ * rather than on any operator class and would otherwise be lost.
+ */
+type OperatorFamilyMember struct {
+	FamilySchema  string
+	FamilyName    string
+	IndexMethod   string
+	MemberType    string
+	Number        int
+	Member        string
+	LeftType      string
+	RightType     string
+	Recheck       bool
+	OrderByFamily string
+}
+
+func (opfm OperatorFamilyMember) FamilyFQN() string {
+	return fmt.Sprintf("%s USING %s", utils.MakeFQN(opfm.FamilySchema, opfm.FamilyName), opfm.IndexMethod)
+}
+
+func (b *Backup) GetOperatorFamilyMembers(connectionPool *dbconn.DBConn) []OperatorFamilyMember {
+	results := make([]OperatorFamilyMember, 0)
+	recheckStr := "ao.amopreqcheck"
+	orderByFamilyStr := "''"
+	orderByFamilyJoinStr := ""
+	if connectionPool.Version.AtLeast("6") {
+		recheckStr = "false"
+		orderByFamilyStr = "coalesce(quote_ident(sort_ns.nspname) || '.' || quote_ident(sort_opf.opfname), '')"
+		orderByFamilyJoinStr = `
+		LEFT JOIN pg_catalog.pg_opfamily sort_opf ON sort_opf.oid = ao.amopsortfamily
+		LEFT JOIN pg_catalog.pg_namespace sort_ns ON sort_ns.oid = sort_opf.opfnamespace`
+	}
+	query := fmt.Sprintf(`
+	SELECT quote_ident(n.nspname) AS familyschema,
+		quote_ident(opf.opfname) AS familyname,
+		(SELECT quote_ident(amname) FROM pg_catalog.pg_am WHERE oid = opf.opfmethod) AS indexmethod,
+		'OPERATOR' AS membertype,
+		ao.amopstrategy AS number,
+		ao.amopopr::pg_catalog.regoperator::text AS member,
+		ao.amoplefttype::pg_catalog.regtype::text AS lefttype,
+		ao.amoprighttype::pg_catalog.regtype::text AS righttype,
+		%s AS recheck,
+		%s AS orderbyfamily
+	FROM pg_catalog.pg_amop ao
+		JOIN pg_catalog.pg_depend d ON d.objid = ao.oid
+		JOIN pg_catalog.pg_opfamily opf ON opf.oid = d.refobjid
+		JOIN pg_catalog.pg_namespace n ON n.oid = opf.opfnamespace%s
+	WHERE d.classid = 'pg_catalog.pg_amop'::pg_catalog.regclass
+		AND d.refclassid = 'pg_catalog.pg_opfamily'::pg_catalog.regclass
+		AND %s
+		AND %s
+	UNION ALL
+	SELECT quote_ident(n.nspname) AS familyschema,
+		quote_ident(opf.opfname) AS familyname,
+		(SELECT quote_ident(amname) FROM pg_catalog.pg_am WHERE oid = opf.opfmethod) AS indexmethod,
+		'FUNCTION' AS membertype,
+		ap.amprocnum AS number,
+		ap.amproc::pg_catalog.regprocedure::text AS member,
+		ap.amproclefttype::pg_catalog.regtype::text AS lefttype,
+		ap.amprocrighttype::pg_catalog.regtype::text AS righttype,
+		false AS recheck,
+		'' AS orderbyfamily
+	FROM pg_catalog.pg_amproc ap
+		JOIN pg_catalog.pg_depend d ON d.objid = ap.oid
+		JOIN pg_catalog.pg_opfamily opf ON opf.oid = d.refobjid
+		JOIN pg_catalog.pg_namespace n ON n.oid = opf.opfnamespace
+	WHERE d.classid = 'pg_catalog.pg_amproc'::pg_catalog.regclass
+		AND d.refclassid = 'pg_catalog.pg_opfamily'::pg_catalog.regclass
+		AND %s
+		AND %s
+	ORDER BY familyschema, familyname, indexmethod, membertype DESC, number`,
+		recheckStr, orderByFamilyStr, orderByFamilyJoinStr,
+		b.SchemaFilterClause("n"), ExtensionFilterClause("opf"),
+		b.SchemaFilterClause("n"), ExtensionFilterClause("opf"))
 	err := connectionPool.Select(&results, query)
 	gplog.FatalOnError(err)
 	return results
@@ -185,7 +266,7 @@ func (opc OperatorClass) FQN() string {
 	return fmt.Sprintf("%s USING %s", utils.MakeFQN(opc.Schema, opc.Name), opc.IndexMethod)
 }
 
-func GetOperatorClasses(connectionPool *dbconn.DBConn) []OperatorClass {
+func (b *Backup) GetOperatorClasses(connectionPool *dbconn.DBConn) []OperatorClass {
 	results := make([]OperatorClass, 0)
 	/*
 	 * In the GPDB 4.3 query, we assign the class schema and name to both the
@@ -205,7 +286,7 @@ func GetOperatorClasses(connectionPool *dbconn.DBConn) []OperatorClass {
 		opckeytype::pg_catalog.regtype AS storagetype
 	FROM pg_catalog.pg_opclass c
 		JOIN pg_catalog.pg_namespace cls_ns ON cls_ns.oid = opcnamespace
-	WHERE %s`, SchemaFilterClause("cls_ns"))
+	WHERE %s`, b.SchemaFilterClause("cls_ns"))
 
 	masterQuery := fmt.Sprintf(`
 	SELECT c.oid AS oid,
@@ -223,7 +304,7 @@ func GetOperatorClasses(connectionPool *dbconn.DBConn) []OperatorClass {
 		JOIN pg_catalog.pg_namespace fam_ns ON fam_ns.oid = opfnamespace
 	WHERE %s
 		AND %s`,
-		SchemaFilterClause("cls_ns"), ExtensionFilterClause("c"))
+		b.SchemaFilterClause("cls_ns"), ExtensionFilterClause("c"))
 
 	var err error
 	if connectionPool.Version.Before("5") {
@@ -77,7 +77,7 @@ func (i IndexDefinition) FQN() string {
  * implicitly created indexes could still have metadata to be backed up.
  * e.g. comments on implicitly created indexes
  */
-func GetIndexes(connectionPool *dbconn.DBConn) []IndexDefinition {
+func (b *Backup) GetIndexes(connectionPool *dbconn.DBConn) []IndexDefinition {
 	resultIndexes := make([]IndexDefinition, 0)
 	if connectionPool.Version.Before("6") {
 		indexNameSet := ConstructImplicitIndexNames(connectionPool)
@@ -107,18 +107,30 @@ func GetIndexes(connectionPool *dbconn.DBConn) []IndexDefinition {
 		AND NOT EXISTS (SELECT 1 FROM pg_partition_rule r WHERE r.parchildrelid = c.oid)
 		AND %s
 	ORDER BY name`,
-	implicitIndexStr, relationAndSchemaFilterClause(), ExtensionFilterClause("c"))
+			implicitIndexStr, b.relationAndSchemaFilterClause(), ExtensionFilterClause("c"))
 
 		err := connectionPool.Select(&resultIndexes, query)
 		gplog.FatalOnError(err)
 	} else {
+		indexDef := "pg_get_indexdef(i.indexrelid)"
+		childPartitionFilter := "AND NOT EXISTS (SELECT 1 FROM pg_partition_rule r WHERE r.parchildrelid = c.oid)"
+		if connectionPool.Version.AtLeast("7") {
+			/*
+			 * The index of a GPDB 7 partitioned table is defined ON ONLY that
+			 * table, but it is restored without ONLY so that it is also
+			 * created on each partition, as the partitions' own indexes are
+			 * not backed up.
+			 */
+			indexDef = "CASE WHEN ic.relkind = 'I' THEN replace(pg_get_indexdef(i.indexrelid), ' ON ONLY ', ' ON ') ELSE pg_get_indexdef(i.indexrelid) END"
+			childPartitionFilter = "AND NOT EXISTS (SELECT 1 FROM pg_inherits inh WHERE inh.inhrelid = i.indexrelid)"
+		}
 		query := fmt.Sprintf(`
 	SELECT DISTINCT i.indexrelid AS oid,
 		quote_ident(ic.relname) AS name,
 		quote_ident(n.nspname) AS owningschema,
 		quote_ident(c.relname) AS owningtable,
 		coalesce(quote_ident(s.spcname), '') AS tablespace,
-		pg_get_indexdef(i.indexrelid) AS def,
+		%s AS def,
 		i.indisclustered AS isclustered,
 		i.indisreplident AS isreplicaidentity,
 		CASE
@@ -135,10 +147,10 @@ func GetIndexes(connectionPool *dbconn.DBConn) []IndexDefinition {
 		AND i.indisvalid
 		AND i.indisready
 		AND i.indisprimary = 'f'
-		AND NOT EXISTS (SELECT 1 FROM pg_partition_rule r WHERE r.parchildrelid = c.oid)
+		%s
 		AND %s
 	ORDER BY name`,
-	relationAndSchemaFilterClause(), ExtensionFilterClause("c")) // The index itself does not have a dependency on the extension, but the index's table does
+			indexDef, b.relationAndSchemaFilterClause(), childPartitionFilter, ExtensionFilterClause("c")) // The index itself does not have a dependency on the extension, but the index's table does
 		err := connectionPool.Select(&resultIndexes, query)
 		gplog.FatalOnError(err)
 	}
@@ -175,11 +187,16 @@ func (r RuleDefinition) FQN() string {
 }
 
 /*
- * Rules named "_RETURN", "pg_settings_n", and "pg_settings_u" are
- * built-in rules and we don't want to back them up. We use two `%` to
- * prevent Go from interpolating the % symbol.
+ * A view's ON SELECT rule, always named "_RETURN", is backed up as part of its
+ * CREATE VIEW statement, so it is excluded by event type rather than by name
+ * to ensure it is never emitted twice without also skipping user rules whose
+ * names end in RETURN.  All other rules are printed in postdata, after every
+ * view and table they could reference, so they restore in the right order
+ * even when a rule and a view reference each other's relations.  Rules named
+ * "pg_settings_n" and "pg_settings_u" are built-in rules and we don't want to
+ * back them up.
  */
-func GetRules(connectionPool *dbconn.DBConn) []RuleDefinition {
+func (b *Backup) GetRules(connectionPool *dbconn.DBConn) []RuleDefinition {
 	query := fmt.Sprintf(`
 	SELECT r.oid AS oid,
 		quote_ident(r.rulename) AS name,
@@ -190,11 +207,11 @@ func GetRules(connectionPool *dbconn.DBConn) []RuleDefinition {
 		JOIN pg_class c ON c.oid = r.ev_class
 		JOIN pg_namespace n ON c.relnamespace = n.oid
 	WHERE %s
-		AND rulename NOT LIKE '%%RETURN'
-		AND rulename NOT LIKE 'pg_%%'
+		AND r.ev_type != '1'
+		AND r.rulename NOT IN ('pg_settings_n', 'pg_settings_u')
 		AND %s
 	ORDER BY rulename`,
-	relationAndSchemaFilterClause(), ExtensionFilterClause("c"))
+		b.relationAndSchemaFilterClause(), ExtensionFilterClause("c"))
 
 	results := make([]RuleDefinition, 0)
 	err := connectionPool.Select(&results, query)
@@ -225,7 +242,7 @@ func (t TriggerDefinition) FQN() string {
 	return t.Name
 }
 
-func GetTriggers(connectionPool *dbconn.DBConn) []TriggerDefinition {
+func (b *Backup) GetTriggers(connectionPool *dbconn.DBConn) []TriggerDefinition {
 	constraintClause := "NOT tgisinternal"
 	if connectionPool.Version.Before("6") {
 		constraintClause = "tgisconstraint = 'f'"
@@ -244,7 +261,7 @@ func GetTriggers(connectionPool *dbconn.DBConn) []TriggerDefinition {
 		AND %s
 		AND %s
 	ORDER BY tgname`,
-	relationAndSchemaFilterClause(), constraintClause, ExtensionFilterClause("c"))
+		b.relationAndSchemaFilterClause(), constraintClause, ExtensionFilterClause("c"))
 
 	results := make([]TriggerDefinition, 0)
 	err := connectionPool.Select(&results, query)
@@ -298,3 +315,176 @@ func GetEventTriggers(connectionPool *dbconn.DBConn) []EventTrigger {
 	gplog.FatalOnError(err)
 	return results
 }
+
+type RowLevelSecurity struct {
+	Oid    uint32
+	Schema string
+	Name   string
+	Force  bool
+}
+
+func (r RowLevelSecurity) GetMetadataEntry() (string, utils.MetadataEntry) {
+	tableFQN := utils.MakeFQN(r.Schema, r.Name)
+	return "postdata",
+		utils.MetadataEntry{
+			Schema:          r.Schema,
+			Name:            r.Name,
+			ObjectType:      "ROW LEVEL SECURITY",
+			ReferenceObject: tableFQN,
+			StartByte:       0,
+			EndByte:         0,
+		}
+}
+
+func (r RowLevelSecurity) GetUniqueID() UniqueID {
+	return UniqueID{ClassID: PG_CLASS_OID, Oid: r.Oid}
+}
+
+func (r RowLevelSecurity) FQN() string {
+	return utils.MakeFQN(r.Schema, r.Name)
+}
+
+/*
+ * Row level security is enabled in postdata rather than with the table's
+ * CREATE TABLE statement so that the policies cannot interfere with the
+ * data restore.
+ */
+func (b *Backup) GetRowLevelSecurityTables(connectionPool *dbconn.DBConn) []RowLevelSecurity {
+	query := fmt.Sprintf(`
+	SELECT c.oid,
+		quote_ident(n.nspname) AS schema,
+		quote_ident(c.relname) AS name,
+		c.relforcerowsecurity AS force
+	FROM pg_class c
+		JOIN pg_namespace n ON c.relnamespace = n.oid
+	WHERE %s
+		AND c.relrowsecurity
+		AND %s
+	ORDER BY c.oid`,
+		b.relationAndSchemaFilterClause(), ExtensionFilterClause("c"))
+
+	results := make([]RowLevelSecurity, 0)
+	err := connectionPool.Select(&results, query)
+	gplog.FatalOnError(err)
+	return results
+}
+
+type RowLevelSecurityPolicy struct {
+	Oid          uint32
+	Name         string
+	OwningSchema string
+	OwningTable  string
+	Command      string
+	Permissive   bool
+	Roles        string
+	Using        string `db:"usingexpr"`
+	WithCheck    string `db:"withcheckexpr"`
+}
+
+func (p RowLevelSecurityPolicy) GetMetadataEntry() (string, utils.MetadataEntry) {
+	tableFQN := utils.MakeFQN(p.OwningSchema, p.OwningTable)
+	return "postdata",
+		utils.MetadataEntry{
+			Schema:          p.OwningSchema,
+			Name:            p.Name,
+			ObjectType:      "POLICY",
+			ReferenceObject: tableFQN,
+			StartByte:       0,
+			EndByte:         0,
+		}
+}
+
+func (p RowLevelSecurityPolicy) GetUniqueID() UniqueID {
+	return UniqueID{ClassID: PG_POLICY_OID, Oid: p.Oid}
+}
+
+func (p RowLevelSecurityPolicy) FQN() string {
+	return p.Name
+}
+
+func (b *Backup) GetRowLevelSecurityPolicies(connectionPool *dbconn.DBConn) []RowLevelSecurityPolicy {
+	query := fmt.Sprintf(`
+	SELECT p.oid,
+		quote_ident(p.polname) AS name,
+		quote_ident(n.nspname) AS owningschema,
+		quote_ident(c.relname) AS owningtable,
+		p.polcmd AS command,
+		p.polpermissive AS permissive,
+		CASE
+			WHEN p.polroles = '{0}' THEN 'PUBLIC'
+			ELSE array_to_string(ARRAY(SELECT quote_ident(rolname) FROM pg_roles WHERE oid = ANY(p.polroles) ORDER BY rolname), ', ')
+		END AS roles,
+		coalesce(pg_get_expr(p.polqual, p.polrelid), '') AS usingexpr,
+		coalesce(pg_get_expr(p.polwithcheck, p.polrelid), '') AS withcheckexpr
+	FROM pg_policy p
+		JOIN pg_class c ON c.oid = p.polrelid
+		JOIN pg_namespace n ON c.relnamespace = n.oid
+	WHERE %s
+		AND %s
+	ORDER BY name`,
+		b.relationAndSchemaFilterClause(), ExtensionFilterClause("c"))
+
+	results := make([]RowLevelSecurityPolicy, 0)
+	err := connectionPool.Select(&results, query)
+	gplog.FatalOnError(err)
+	return results
+}
+
+type ExtendedStatistic struct {
+	Oid          uint32
+	Schema       string
+	Name         string
+	OwningSchema string
+	OwningTable  string
+	Def          string
+}
+
+func (s ExtendedStatistic) GetMetadataEntry() (string, utils.MetadataEntry) {
+	tableFQN := utils.MakeFQN(s.OwningSchema, s.OwningTable)
+	return "postdata",
+		utils.MetadataEntry{
+			Schema:          s.Schema,
+			Name:            s.Name,
+			ObjectType:      "STATISTICS",
+			ReferenceObject: tableFQN,
+			StartByte:       0,
+			EndByte:         0,
+		}
+}
+
+func (s ExtendedStatistic) GetUniqueID() UniqueID {
+	return UniqueID{ClassID: PG_STATISTIC_EXT_OID, Oid: s.Oid}
+}
+
+func (s ExtendedStatistic) FQN() string {
+	return utils.MakeFQN(s.Schema, s.Name)
+}
+
+/*
+ * Extended statistics objects are filtered by the table they are defined on,
+ * as that is the table whose columns they describe, rather than by their own
+ * schema.
+ */
+func (b *Backup) GetExtendedStatistics(connectionPool *dbconn.DBConn) []ExtendedStatistic {
+	query := fmt.Sprintf(`
+	SELECT s.oid,
+		quote_ident(sn.nspname) AS schema,
+		quote_ident(s.stxname) AS name,
+		quote_ident(n.nspname) AS owningschema,
+		quote_ident(c.relname) AS owningtable,
+		pg_get_statisticsobjdef(s.oid) AS def
+	FROM pg_statistic_ext s
+		JOIN pg_namespace sn ON s.stxnamespace = sn.oid
+		JOIN pg_class c ON c.oid = s.stxrelid
+		JOIN pg_namespace n ON c.relnamespace = n.oid
+	WHERE %s
+		AND %s
+		AND %s
+	ORDER BY schema, name`,
+		b.relationAndSchemaFilterClause(), ExtensionFilterClause("c"), ExtensionFilterClause("s"))
+
+	results := make([]ExtendedStatistic, 0)
+	err := connectionPool.Select(&results, query)
+	gplog.FatalOnError(err)
+	return results
+}
@@ -9,32 +9,34 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/greenplum-db/gp-common-go-libs/dbconn"
 	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/operating"
 	"github.com/greenplum-db/gpbackup/options"
 	"github.com/greenplum-db/gpbackup/utils"
 )
 
-func relationAndSchemaFilterClause() string {
-	if filterRelationClause != "" {
-		return filterRelationClause
+func (b *Backup) relationAndSchemaFilterClause() string {
+	if b.filterRelationClause != "" {
+		return b.filterRelationClause
 	}
-	filterRelationClause = SchemaFilterClause("n")
-	if len(MustGetFlagStringSlice(utils.EXCLUDE_RELATION)) > 0 {
-		excludeOids := GetOidsFromRelationList(connectionPool, MustGetFlagStringSlice(utils.EXCLUDE_RELATION))
+	b.filterRelationClause = b.SchemaFilterClause("n")
+	if len(b.MustGetFlagStringSlice(utils.EXCLUDE_RELATION)) > 0 {
+		excludeOids := GetOidsFromRelationList(b.connectionPool, b.MustGetFlagStringSlice(utils.EXCLUDE_RELATION))
 		if len(excludeOids) > 0 {
-			filterRelationClause += fmt.Sprintf("\nAND c.oid NOT IN (%s)", strings.Join(excludeOids, ", "))
+			b.filterRelationClause += fmt.Sprintf("\nAND c.oid NOT IN (%s)", strings.Join(excludeOids, ", "))
 		}
 	}
-	if len(MustGetFlagStringArray(utils.INCLUDE_RELATION)) > 0 {
-		quotedIncludeRelations, err := options.QuoteTableNames(connectionPool, MustGetFlagStringArray(utils.INCLUDE_RELATION))
+	if len(b.MustGetFlagStringArray(utils.INCLUDE_RELATION)) > 0 {
+		quotedIncludeRelations, err := options.QuoteTableNames(b.connectionPool, b.MustGetFlagStringArray(utils.INCLUDE_RELATION))
 		gplog.FatalOnError(err)
 
-		includeOids := GetOidsFromRelationList(connectionPool, quotedIncludeRelations)
-		filterRelationClause += fmt.Sprintf("\nAND c.oid IN (%s)", strings.Join(includeOids, ", "))
+		includeOids := GetOidsFromRelationList(b.connectionPool, quotedIncludeRelations)
+		b.filterRelationClause += fmt.Sprintf("\nAND c.oid IN (%s)", strings.Join(includeOids, ", "))
 	}
-	return filterRelationClause
+	return b.filterRelationClause
 }
 
 func GetOidsFromRelationList(connectionPool *dbconn.DBConn, quotedIncludeRelations []string) []string {
@@ -47,11 +49,11 @@ func GetOidsFromRelationList(connectionPool *dbconn.DBConn, quotedIncludeRelatio
 	return dbconn.MustSelectStringSlice(connectionPool, query)
 }
 
-func GetIncludedUserTableRelations(connectionPool *dbconn.DBConn, includedRelationsQuoted []string) []Relation {
-	if len(MustGetFlagStringArray(utils.INCLUDE_RELATION)) > 0 {
+func (b *Backup) GetIncludedUserTableRelations(connectionPool *dbconn.DBConn, includedRelationsQuoted []string) []Relation {
+	if len(b.MustGetFlagStringArray(utils.INCLUDE_RELATION)) > 0 {
 		return GetUserTableRelationsWithIncludeFiltering(connectionPool, includedRelationsQuoted)
 	}
-	return GetUserTableRelations(connectionPool)
+	return b.GetUserTableRelations(connectionPool)
 }
 
 type Relation struct {
@@ -69,13 +71,32 @@ func (r Relation) GetUniqueID() UniqueID {
 	return UniqueID{ClassID: PG_CLASS_OID, Oid: r.Oid}
 }
 
+// Relations in the same schema share a single copy of the schema name
+func internSchemaNames(relations []Relation) {
+	interner := utils.NewStringInterner()
+	for i := range relations {
+		relations[i].Schema = interner.Intern(relations[i].Schema)
+	}
+}
+
+// Partitioned tables in GPDB 7 have their own relkind, as they hold no data themselves
+func userTableRelkindClause(connectionPool *dbconn.DBConn) string {
+	if connectionPool.Version.AtLeast("7") {
+		return "relkind IN ('r', 'p')"
+	}
+	return "relkind = 'r'"
+}
+
 /*
  * This function also handles exclude table filtering since the way we do
  * it is currently much simpler than the include case.
+ *
+ * In GPDB 7, each partition is a table of its own that is created and then
+ * attached to its parent, so partitions are never filtered out.
  */
-func GetUserTableRelations(connectionPool *dbconn.DBConn) []Relation {
+func (b *Backup) GetUserTableRelations(connectionPool *dbconn.DBConn) []Relation {
 	childPartitionFilter := ""
-	if !MustGetFlagBool(utils.LEAF_PARTITION_DATA) {
+	if connectionPool.Version.Before("7") && !b.MustGetFlagBool(utils.LEAF_PARTITION_DATA) {
 		//Filter out non-external child partitions
 		childPartitionFilter = `
 	AND c.oid NOT IN (
@@ -94,14 +115,15 @@ func GetUserTableRelations(connectionPool *dbconn.DBConn) []Relation {
 		JOIN pg_namespace n ON c.relnamespace = n.oid
 	WHERE %s
 		%s
-		AND relkind = 'r'
+		AND %s
 		AND %s
 		ORDER BY c.oid`,
-		relationAndSchemaFilterClause(), childPartitionFilter, ExtensionFilterClause("c"))
+		b.relationAndSchemaFilterClause(), childPartitionFilter, userTableRelkindClause(connectionPool), ExtensionFilterClause("c"))
 
 	results := make([]Relation, 0)
 	err := connectionPool.Select(&results, query)
 	gplog.FatalOnError(err)
+	internSchemaNames(results)
 
 	return results
 }
@@ -118,16 +140,17 @@ func GetUserTableRelationsWithIncludeFiltering(connectionPool *dbconn.DBConn, in
 	FROM pg_class c
 		JOIN pg_namespace n ON c.relnamespace = n.oid
 	WHERE c.oid IN (%s)
-		AND (relkind = 'r')
-	ORDER BY c.oid`, oidStr)
+		AND %s
+	ORDER BY c.oid`, oidStr, userTableRelkindClause(connectionPool))
 
 	results := make([]Relation, 0)
 	err := connectionPool.Select(&results, query)
 	gplog.FatalOnError(err)
+	internSchemaNames(results)
 	return results
 }
 
-func GetForeignTableRelations(connectionPool *dbconn.DBConn) []Relation {
+func (b *Backup) GetForeignTableRelations(connectionPool *dbconn.DBConn) []Relation {
 	query := fmt.Sprintf(`
 	SELECT n.oid AS schemaoid,
 		c.oid AS oid,
@@ -139,11 +162,12 @@ func GetForeignTableRelations(connectionPool *dbconn.DBConn) []Relation {
 		AND relkind = 'f'
 		AND %s
 	ORDER BY c.oid`,
-		relationAndSchemaFilterClause(), ExtensionFilterClause("c"))
+		b.relationAndSchemaFilterClause(), ExtensionFilterClause("c"))
 
 	results := make([]Relation, 0)
 	err := connectionPool.Select(&results, query)
 	gplog.FatalOnError(err)
+	internSchemaNames(results)
 	return results
 }
 
@@ -165,20 +189,22 @@ func (s Sequence) GetMetadataEntry() (string, utils.MetadataEntry) {
 }
 
 type SequenceDefinition struct {
-	LastVal     int64
-	StartVal    int64
-	Increment   int64
-	MaxVal      int64
-	MinVal      int64
-	CacheVal    int64
-	LogCnt      int64
-	IsCycled    bool
-	IsCalled    bool
-	OwningTable string
-}
-
-func GetAllSequences(connectionPool *dbconn.DBConn, sequenceOwnerTables map[string]string) []Sequence {
-	sequenceRelations := GetAllSequenceRelations(connectionPool)
+	LastVal      int64
+	StartVal     int64
+	Increment    int64
+	MaxVal       int64
+	MinVal       int64
+	CacheVal     int64
+	LogCnt       int64
+	IsCycled     bool
+	IsCalled     bool
+	OwningTable  string
+	DataType     string
+	IdentityType string
+}
+
+func (b *Backup) GetAllSequences(connectionPool *dbconn.DBConn, sequenceOwnerTables map[string]string) []Sequence {
+	sequenceRelations := b.GetAllSequenceRelations(connectionPool)
 	sequences := make([]Sequence, 0)
 	for _, seqRelation := range sequenceRelations {
 		seqDef := GetSequenceDefinition(connectionPool, seqRelation.FQN())
@@ -189,7 +215,7 @@ func GetAllSequences(connectionPool *dbconn.DBConn, sequenceOwnerTables map[stri
 	return sequences
 }
 
-func GetAllSequenceRelations(connectionPool *dbconn.DBConn) []Relation {
+func (b *Backup) GetAllSequenceRelations(connectionPool *dbconn.DBConn) []Relation {
 	query := fmt.Sprintf(`
 	SELECT n.oid AS schemaoid,
 		c.oid AS oid,
@@ -202,16 +228,24 @@ func GetAllSequenceRelations(connectionPool *dbconn.DBConn) []Relation {
 		AND %s
 		AND %s
 	ORDER BY n.nspname, c.relname`,
-		relationAndSchemaFilterClause(), ExtensionFilterClause("c"))
+		b.relationAndSchemaFilterClause(), ExtensionFilterClause("c"))
 
 	results := make([]Relation, 0)
 	err := connectionPool.Select(&results, query)
 	gplog.FatalOnError(err)
+	internSchemaNames(results)
 
 	return results
 }
 
+/*
+ * In GPDB 7, the sequence relation only holds the sequence's current state,
+ * and its parameters are stored in pg_sequence instead.
+ */
 func GetSequenceDefinition(connectionPool *dbconn.DBConn, seqName string) SequenceDefinition {
+	if connectionPool.Version.AtLeast("7") {
+		return getSequenceDefinitionAtLeast7(connectionPool, seqName)
+	}
 	startValQuery := ""
 	if connectionPool.Version.AtLeast("6") {
 		startValQuery = "start_value AS startval,"
@@ -233,10 +267,47 @@ func GetSequenceDefinition(connectionPool *dbconn.DBConn, seqName string) Sequen
 	return result
 }
 
-func GetSequenceColumnOwnerMap(connectionPool *dbconn.DBConn) (map[string]string, map[string]string) {
+/*
+ * The identity type is "a" for GENERATED ALWAYS and "d" for GENERATED BY
+ * DEFAULT identity columns, and empty for all other sequences.
+ */
+func getSequenceDefinitionAtLeast7(connectionPool *dbconn.DBConn, seqName string) SequenceDefinition {
+	query := fmt.Sprintf(`
+	SELECT s.last_value AS lastval,
+		p.seqstart AS startval,
+		p.seqincrement AS increment,
+		p.seqmax AS maxval,
+		p.seqmin AS minval,
+		p.seqcache AS cacheval,
+		s.log_cnt AS logcnt,
+		p.seqcycle AS iscycled,
+		s.is_called AS iscalled,
+		pg_catalog.format_type(p.seqtypid, NULL) AS datatype,
+		coalesce((SELECT a.attidentity::text
+			FROM pg_depend d
+				JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+			WHERE d.classid = 'pg_class'::regclass
+				AND d.objid = p.seqrelid
+				AND d.refclassid = 'pg_class'::regclass
+				AND d.deptype = 'i'), '') AS identitytype
+	FROM %s s, pg_sequence p
+	WHERE p.seqrelid = '%s'::regclass`, seqName, utils.EscapeSingleQuotes(seqName))
+	result := SequenceDefinition{}
+	err := connectionPool.Get(&result, query)
+	gplog.FatalOnError(err)
+	return result
+}
+
+/*
+ * Sequences are owned by a column through an automatic dependency, or through
+ * an internal dependency for the sequence of a GPDB 7 identity column.  The
+ * owning table may be in a different schema than the sequence.
+ */
+func (b *Backup) GetSequenceColumnOwnerMap(connectionPool *dbconn.DBConn) (map[string]string, map[string]string) {
 	query := fmt.Sprintf(`
 	SELECT quote_ident(n.nspname) AS schema,
 		quote_ident(s.relname) AS name,
+		quote_ident(tn.nspname) AS tableschema,
 		quote_ident(c.relname) AS tablename,
 		quote_ident(a.attname) AS columnname
 	FROM pg_depend d
@@ -244,14 +315,19 @@ func GetSequenceColumnOwnerMap(connectionPool *dbconn.DBConn) (map[string]string
 		JOIN pg_class s ON s.oid = d.objid
 		JOIN pg_class c ON c.oid = d.refobjid
 		JOIN pg_namespace n ON n.oid = s.relnamespace
+		JOIN pg_namespace tn ON tn.oid = c.relnamespace
 	WHERE s.relkind = 'S'
-		AND %s`, relationAndSchemaFilterClause())
+		AND d.classid = 'pg_class'::regclass
+		AND d.refclassid = 'pg_class'::regclass
+		AND d.deptype IN ('a', 'i')
+		AND %s`, b.relationAndSchemaFilterClause())
 
 	results := make([]struct {
-		Schema     string
-		Name       string
-		TableName  string
-		ColumnName string
+		Schema      string
+		Name        string
+		TableSchema string
+		TableName   string
+		ColumnName  string
 	}, 0)
 	sequenceOwnerTables := make(map[string]string)
 	sequenceOwnerColumns := make(map[string]string)
@@ -259,8 +335,8 @@ func GetSequenceColumnOwnerMap(connectionPool *dbconn.DBConn) (map[string]string
 	gplog.FatalOnError(err, fmt.Sprintf("Failed on query: %s", query))
 	for _, seqOwner := range results {
 		seqFQN := utils.MakeFQN(seqOwner.Schema, seqOwner.Name)
-		tableFQN := fmt.Sprintf("%s.%s", seqOwner.Schema, seqOwner.TableName)
-		columnFQN := fmt.Sprintf("%s.%s.%s", seqOwner.Schema, seqOwner.TableName, seqOwner.ColumnName)
+		tableFQN := fmt.Sprintf("%s.%s", seqOwner.TableSchema, seqOwner.TableName)
+		columnFQN := fmt.Sprintf("%s.%s", tableFQN, seqOwner.ColumnName)
 		sequenceOwnerTables[seqFQN] = tableFQN
 		sequenceOwnerColumns[seqFQN] = columnFQN
 	}
@@ -299,7 +375,7 @@ func (v View) FQN() string {
 
 // This function retrieves both regular views and materialized views.
 // Materialized views were introduced in GPDB 7.
-func GetAllViews(connectionPool *dbconn.DBConn) (regularViews []View, materializedViews []MaterializedView) {
+func (b *Backup) GetAllViews(connectionPool *dbconn.DBConn) (regularViews []View, materializedViews []MaterializedView) {
 	selectClause := `
 	SELECT
 		c.oid AS oid,
@@ -334,7 +410,7 @@ func GetAllViews(connectionPool *dbconn.DBConn) (regularViews []View, materializ
 	}
 	whereClause += fmt.Sprintf(`
 		AND %s
-		AND %s`, relationAndSchemaFilterClause(), ExtensionFilterClause("c"))
+		AND %s`, b.relationAndSchemaFilterClause(), ExtensionFilterClause("c"))
 
 	results := make([]View, 0)
 	query := selectClause + fromClause + whereClause
@@ -354,6 +430,50 @@ func GetAllViews(connectionPool *dbconn.DBConn) (regularViews []View, materializ
 	return regularViews, materializedViews
 }
 
+type ViewDependency struct {
+	ViewOid           uint32
+	ViewName          string
+	ReferenceOid      uint32
+	ReferenceName     string
+	ReferenceInBackup bool
+}
+
+/*
+ * Returns the user relations that views in the backup set reference, and
+ * whether each of them is itself in the backup set.
+ */
+func (b *Backup) GetViewDependencies(connectionPool *dbconn.DBConn) []ViewDependency {
+	query := fmt.Sprintf(`
+	SELECT DISTINCT r.ev_class AS viewoid,
+		quote_ident(vn.nspname) || '.' || quote_ident(v.relname) AS viewname,
+		d.refobjid AS referenceoid,
+		quote_ident(rn.nspname) || '.' || quote_ident(rc.relname) AS referencename,
+		d.refobjid IN (SELECT c.oid FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace WHERE %[1]s) AS referenceinbackup
+	FROM pg_rewrite r
+		JOIN pg_class v ON v.oid = r.ev_class
+		JOIN pg_namespace vn ON vn.oid = v.relnamespace
+		JOIN pg_depend d ON d.classid = 'pg_rewrite'::regclass AND d.objid = r.oid AND d.refclassid = 'pg_class'::regclass
+		JOIN pg_class rc ON rc.oid = d.refobjid
+		JOIN pg_namespace rn ON rn.oid = rc.relnamespace
+	WHERE r.ev_class IN (
+			SELECT c.oid
+			FROM pg_class c
+				JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE c.relkind IN ('m', 'v')
+				AND %[1]s
+				AND %[2]s)
+		AND d.refobjid != r.ev_class
+		AND rc.relkind IN ('f', 'm', 'p', 'r', 'S', 'v')
+		AND rn.nspname NOT LIKE 'pg_temp_%%'
+		AND rn.nspname NOT IN ('gp_toolkit', 'information_schema', 'pg_aoseg', 'pg_bitmapindex', 'pg_catalog')
+		AND %[3]s`, b.relationAndSchemaFilterClause(), ExtensionFilterClause("c"), ExtensionFilterClause("rc"))
+
+	results := make([]ViewDependency, 0)
+	err := connectionPool.Select(&results, query)
+	gplog.FatalOnError(err, fmt.Sprintf("Failed on query: %s", query))
+	return results
+}
+
 type MaterializedView struct {
 	Oid        uint32
 	Schema     string
@@ -394,7 +514,14 @@ func makeMaterializedView(view View) MaterializedView {
 	}
 }
 
-func LockTables(connectionPool *dbconn.DBConn, tables []Relation) {
+/*
+ * On databases with a very large number of tables, acquiring locks can take
+ * long enough that the progress bar alone is not sufficient, so the lock rate
+ * is also logged every lockProgressInterval.
+ */
+var lockProgressInterval = 30 * time.Second
+
+func (b *Backup) LockTables(connectionPool *dbconn.DBConn, tables []Relation) time.Duration {
 	gplog.Info("Acquiring ACCESS SHARE locks on tables")
 
 	progressBar := utils.NewProgressBar(len(tables), "Locks acquired: ", utils.PB_VERBOSE)
@@ -409,25 +536,52 @@ func LockTables(connectionPool *dbconn.DBConn, tables []Relation) {
 	// holding an AccessExclusiveLock on the table. If gpbackup
 	// is interrupted and exits, the SQL session will leak if
 	// we don't cancel the query.
-	queryContext, queryCancelFunc = context.WithCancel(context.Background())
+	b.queryContext, b.queryCancelFunc = context.WithCancel(b.backupContext)
 
+	startTime := operating.System.Now()
+	b.backupMetrics.StartLockWait(startTime)
+	lastLogTime := startTime
+	numLocked := 0
 	for i, currentBatch := range tableBatches {
-		connectionPool.MustExecContext(queryContext,
+		if b.wasTerminated {
+			break
+		}
+		_, err := connectionPool.ExecContext(b.queryContext,
 			fmt.Sprintf("LOCK TABLE %s IN ACCESS SHARE MODE", currentBatch))
+		if err != nil {
+			// DoCleanup cancels the in-flight batch when gpbackup is interrupted
+			if b.wasTerminated {
+				break
+			}
+			gplog.FatalOnError(err)
+		}
 
 		if i == len(tableBatches)-1 && lastBatchSize > 0 {
 			currentBatchSize = lastBatchSize
 		}
 
 		progressBar.Add(currentBatchSize)
+		numLocked += currentBatchSize
+		if now := operating.System.Now(); now.Sub(lastLogTime) >= lockProgressInterval {
+			gplog.Info("Acquired %d of %d table locks (%.0f locks/sec)", numLocked, len(tables), float64(numLocked)/now.Sub(startTime).Seconds())
+			lastLogTime = now
+		}
 	}
+	lockDuration := operating.System.Now().Sub(startTime)
+	b.backupMetrics.FinishLockWait(lockDuration)
 
 	// We're done grabbing table locks. Unset the Context globals
 	// so we don't use them during DoCleanup.
-	queryContext = context.TODO()
-	queryCancelFunc = nil
+	b.queryContext = b.backupContext
+	b.queryCancelFunc = nil
 
 	progressBar.Finish()
+	if b.wasTerminated {
+		gplog.Info("Table lock acquisition interrupted after %d of %d tables", numLocked, len(tables))
+	} else {
+		gplog.Verbose("Acquired %d table locks in %s", numLocked, lockDuration)
+	}
+	return lockDuration
 }
 
 // generateTableBatches batches tables to reduce network congestion and
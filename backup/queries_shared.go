@@ -51,7 +51,7 @@ func (s Schema) FQN() string {
 	return s.Name
 }
 
-func GetAllUserSchemas(connectionPool *dbconn.DBConn) []Schema {
+func (b *Backup) GetAllUserSchemas(connectionPool *dbconn.DBConn) []Schema {
 	/*
 	 * This query is constructed from scratch, but the list of schemas to exclude
 	 * is copied from gpcrondump so that gpbackup exhibits similar behavior regarding
@@ -60,7 +60,7 @@ func GetAllUserSchemas(connectionPool *dbconn.DBConn) []Schema {
 	query := fmt.Sprintf(`
 	SELECT oid, quote_ident(nspname) AS name FROM pg_namespace n
 		WHERE %s AND %s ORDER BY name`,
-		SchemaFilterClause("n"), ExtensionFilterClause(""))
+		b.SchemaFilterClause("n"), ExtensionFilterClause(""))
 	results := make([]Schema, 0)
 
 	err := connectionPool.Select(&results, query)
@@ -104,7 +104,7 @@ func (c Constraint) FQN() string {
 	return c.Name
 }
 
-func GetConstraints(connectionPool *dbconn.DBConn, includeTables ...Relation) []Constraint {
+func (b *Backup) GetConstraints(connectionPool *dbconn.DBConn, includeTables ...Relation) []Constraint {
 	// ConIsLocal should always return true from GetConstraints because we filter out constraints that are inherited using the INHERITS clause, or inherited from a parent partition table. This field only accurately reflects constraints in GPDB6+ because check constraints on parent tables must propogate to children. For GPDB versions 5 or lower, this field will default to false.
 	var selectConIsLocal string
 	var groupByConIsLocal string
@@ -112,6 +112,21 @@ func GetConstraints(connectionPool *dbconn.DBConn, includeTables ...Relation) []
 		selectConIsLocal = `conislocal,`
 		groupByConIsLocal = `con.conislocal,`
 	}
+	isPartitionParent := `CASE
+			WHEN pt.parrelid IS NULL THEN 'f'
+			ELSE 't'
+		END`
+	partitionJoin := `
+		LEFT JOIN pg_partition pt ON con.conrelid = pt.parrelid`
+	childPartitionFilter := `conrelid NOT IN (SELECT parchildrelid FROM pg_partition_rule)`
+	groupByPartition := `pt.parrelid`
+	if connectionPool.Version.AtLeast("7") {
+		// Constraints cloned onto GPDB 7 partitions from their parent are recreated by the parent's constraint
+		isPartitionParent = `CASE WHEN c.relkind = 'p' THEN 't' ELSE 'f' END`
+		partitionJoin = ""
+		childPartitionFilter = `con.conparentid = 0`
+		groupByPartition = `c.relkind`
+	}
 	// This query is adapted from the queries underlying \d in psql.
 	tableQuery := fmt.Sprintf(`
 	SELECT con.oid,
@@ -122,20 +137,16 @@ func GetConstraints(connectionPool *dbconn.DBConn, includeTables ...Relation) []
 		pg_get_constraintdef(con.oid, TRUE) AS condef,
 		quote_ident(n.nspname) || '.' || quote_ident(c.relname) AS owningobject,
 		'f' AS isdomainconstraint,
-		CASE
-			WHEN pt.parrelid IS NULL THEN 'f'
-			ELSE 't'
-		END AS ispartitionparent
+		%s AS ispartitionparent
 	FROM pg_constraint con
-		LEFT JOIN pg_class c ON con.conrelid = c.oid
-		LEFT JOIN pg_partition pt ON con.conrelid = pt.parrelid
+		LEFT JOIN pg_class c ON con.conrelid = c.oid%s
 		JOIN pg_namespace n ON n.oid = con.connamespace
 	WHERE %s
 		AND %s
 		AND c.relname IS NOT NULL
-		AND conrelid NOT IN (SELECT parchildrelid FROM pg_partition_rule)
+		AND %s
 		AND (conrelid, conname) NOT IN (SELECT i.inhrelid, con.conname FROM pg_inherits i JOIN pg_constraint con ON i.inhrelid = con.conrelid JOIN pg_constraint p ON i.inhparent = p.conrelid WHERE con.conname = p.conname)
-	GROUP BY con.oid, conname, contype, c.relname, n.nspname, %s pt.parrelid`, selectConIsLocal, "%s", ExtensionFilterClause("c"), groupByConIsLocal)
+	GROUP BY con.oid, conname, contype, c.relname, n.nspname, %s %s`, selectConIsLocal, isPartitionParent, partitionJoin, "%s", ExtensionFilterClause("c"), childPartitionFilter, groupByConIsLocal, groupByPartition)
 
 	nonTableQuery := fmt.Sprintf(`
 	SELECT con.oid,
@@ -154,7 +165,7 @@ func GetConstraints(connectionPool *dbconn.DBConn, includeTables ...Relation) []
 		AND %s
 		AND t.typname IS NOT NULL
 	GROUP BY con.oid, conname, contype, n.nspname, %s t.typname
-	ORDER BY name`, selectConIsLocal, SchemaFilterClause("n"), ExtensionFilterClause("con"), groupByConIsLocal)
+	ORDER BY name`, selectConIsLocal, b.SchemaFilterClause("n"), ExtensionFilterClause("con"), groupByConIsLocal)
 
 	query := ""
 	if len(includeTables) > 0 {
@@ -162,10 +173,10 @@ func GetConstraints(connectionPool *dbconn.DBConn, includeTables ...Relation) []
 		for _, table := range includeTables {
 			oidList = append(oidList, fmt.Sprintf("%d", table.Oid))
 		}
-		filterClause := fmt.Sprintf("%s\nAND c.oid IN (%s)", SchemaFilterClause("n"), strings.Join(oidList, ","))
+		filterClause := fmt.Sprintf("%s\nAND c.oid IN (%s)", b.SchemaFilterClause("n"), strings.Join(oidList, ","))
 		query = fmt.Sprintf(tableQuery, filterClause)
 	} else {
-		tableQuery = fmt.Sprintf(tableQuery, relationAndSchemaFilterClause())
+		tableQuery = fmt.Sprintf(tableQuery, b.relationAndSchemaFilterClause())
 		query = fmt.Sprintf("%s\nUNION\n%s", tableQuery, nonTableQuery)
 	}
 	results := make([]Constraint, 0)
@@ -175,13 +186,13 @@ func GetConstraints(connectionPool *dbconn.DBConn, includeTables ...Relation) []
 }
 
 // A list of schemas we don't want to back up, formatted for use in a WHERE clause
-func SchemaFilterClause(namespace string) string {
+func (b *Backup) SchemaFilterClause(namespace string) string {
 	schemaFilterClauseStr := ""
-	if len(MustGetFlagStringSlice(utils.INCLUDE_SCHEMA)) > 0 {
-		schemaFilterClauseStr = fmt.Sprintf("\nAND %s.nspname IN (%s)", namespace, utils.SliceToQuotedString(MustGetFlagStringSlice(utils.INCLUDE_SCHEMA)))
+	if len(b.MustGetFlagStringSlice(utils.INCLUDE_SCHEMA)) > 0 {
+		schemaFilterClauseStr = fmt.Sprintf("\nAND %s.nspname IN (%s)", namespace, utils.SliceToQuotedString(b.MustGetFlagStringSlice(utils.INCLUDE_SCHEMA)))
 	}
-	if len(MustGetFlagStringSlice(utils.EXCLUDE_SCHEMA)) > 0 {
-		schemaFilterClauseStr = fmt.Sprintf("\nAND %s.nspname NOT IN (%s)", namespace, utils.SliceToQuotedString(MustGetFlagStringSlice(utils.EXCLUDE_SCHEMA)))
+	if len(b.MustGetFlagStringSlice(utils.EXCLUDE_SCHEMA)) > 0 {
+		schemaFilterClauseStr = fmt.Sprintf("\nAND %s.nspname NOT IN (%s)", namespace, utils.SliceToQuotedString(b.MustGetFlagStringSlice(utils.EXCLUDE_SCHEMA)))
 	}
 	return fmt.Sprintf(`%s.nspname NOT LIKE 'pg_temp_%%' AND %s.nspname NOT LIKE 'pg_toast%%' AND %s.nspname NOT IN ('gp_toolkit', 'information_schema', 'pg_aoseg', 'pg_bitmapindex', 'pg_catalog') %s`, namespace, namespace, namespace, schemaFilterClauseStr)
 }
@@ -194,3 +205,22 @@ func ExtensionFilterClause(namespace string) string {
 
 	return fmt.Sprintf("%s NOT IN (select objid from pg_depend where deptype = 'e')", oidStr)
 }
+
+/*
+ * Some catalog queries are needed by several metadata retrieval functions over
+ * the course of a backup.  Metadata is read inside the backup transaction, so
+ * their results cannot change during the run, and they are only executed once
+ * and cached by name here.  Caching is disabled while catalogQueryCache is nil.
+ */
+func (b *Backup) memoizeCatalogQuery(cacheKey string, runQuery func() interface{}) interface{} {
+	if b.catalogQueryCache == nil {
+		return runQuery()
+	}
+	if result, ok := b.catalogQueryCache[cacheKey]; ok {
+		gplog.Debug("Using cached results of %s query", cacheKey)
+		return result
+	}
+	result := runQuery()
+	b.catalogQueryCache[cacheKey] = result
+	return result
+}
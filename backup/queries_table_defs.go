@@ -8,6 +8,7 @@ package backup
 import (
 	"database/sql"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/greenplum-db/gp-common-go-libs/dbconn"
@@ -20,9 +21,16 @@ type Table struct {
 	TableDefinition
 }
 
-func (t Table) SkipDataBackup() bool {
-	def := t.TableDefinition
-	return def.IsExternal || (def.ForeignDef != ForeignTableDefinition{})
+/*
+ * The data of readable external tables is only backed up when
+ * --with-external-data is passed, and writable external tables cannot be read.
+ */
+func (b *Backup) SkipDataBackup(table Table) bool {
+	def := table.TableDefinition
+	if def.IsExternal {
+		return def.ExtTableDef.Writable || !b.MustGetFlagBool(utils.WITH_EXTERNAL_DATA)
+	}
+	return def.ForeignDef != ForeignTableDefinition{}
 }
 
 func (t Table) GetMetadataEntry() (string, utils.MetadataEntry) {
@@ -56,52 +64,89 @@ type TableDefinition struct {
 	ForeignDef         ForeignTableDefinition
 	Inherits           []string
 	ReplicaIdentity    string
+	PartitionKeyDef    string
+	AttachPartition    AttachPartitionInfo
+	AccessMethodName   string
 }
 
 /*
  * This function calls all the functions needed to gather the metadata for a
  * single table and assembles the metadata into ColumnDef and TableDef structs
  * for more convenient handling in the PrintCreateTableStatement() function.
+ *
+ * Each of those functions returns a map by oid covering every relation in the
+ * database, so rather than holding all of them until the tables are built,
+ * each map is copied into the tables by position as soon as it is read and can
+ * then be collected.  Only the positions of the tables are kept throughout.
  */
-func ConstructDefinitionsForTables(connectionPool *dbconn.DBConn, tableRelations []Relation) []Table {
-	tables := make([]Table, 0)
+func (b *Backup) ConstructDefinitionsForTables(connectionPool *dbconn.DBConn, tableRelations []Relation) []Table {
+	tables := make([]Table, len(tableRelations))
+	positions := make(map[uint32]int, len(tableRelations))
+	for i, tableRel := range tableRelations {
+		tables[i] = Table{Relation: tableRel, TableDefinition: TableDefinition{Inherits: []string{}}}
+		positions[tableRel.Oid] = i
+	}
+	setDefinition := func(oid uint32, set func(tableDef *TableDefinition)) {
+		if i, ok := positions[oid]; ok {
+			set(&tables[i].TableDefinition)
+		}
+	}
 
 	gplog.Info("Gathering additional table metadata")
-	columnDefs := GetColumnDefinitions(connectionPool)
-	distributionPolicies := GetDistributionPolicies(connectionPool)
-	partitionDefs, partTemplateDefs := GetPartitionDetails(connectionPool)
-	tablespaceNames, storageOptions := GetTableStorage(connectionPool)
-	extTableDefs := GetExternalTableDefinitions(connectionPool)
-	partTableMap := GetPartitionTableMap(connectionPool)
-	tableTypeMap := GetTableType(connectionPool)
-	unloggedTableMap := GetUnloggedTables(connectionPool)
-	foreignTableDefs := GetForeignTableDefinitions(connectionPool)
-	inheritanceMap := GetTableInheritance(connectionPool, tableRelations)
-	replicaIdentityMap := GetTableReplicaIdentity(connectionPool)
-
-	gplog.Verbose("Constructing table definition map")
-	for _, tableRel := range tableRelations {
-		oid := tableRel.Oid
-		tableDef := TableDefinition{
-			DistPolicy:         distributionPolicies[oid],
-			PartDef:            partitionDefs[oid],
-			PartTemplateDef:    partTemplateDefs[oid],
-			StorageOpts:        storageOptions[oid],
-			TablespaceName:     tablespaceNames[oid],
-			ColumnDefs:         columnDefs[oid],
-			IsExternal:         extTableDefs[oid].Oid != 0,
-			ExtTableDef:        extTableDefs[oid],
-			PartitionLevelInfo: partTableMap[oid],
-			TableType:          tableTypeMap[oid],
-			IsUnlogged:         unloggedTableMap[oid],
-			ForeignDef:         foreignTableDefs[oid],
-			Inherits:           inheritanceMap[oid],
-			ReplicaIdentity:    replicaIdentityMap[oid],
-		}
-		if tableDef.Inherits == nil {
-			tableDef.Inherits = []string{}
+	for oid, columnDefs := range b.GetColumnDefinitions(connectionPool) {
+		setDefinition(oid, func(tableDef *TableDefinition) { tableDef.ColumnDefs = columnDefs })
+	}
+	for oid, distPolicy := range GetDistributionPolicies(connectionPool) {
+		setDefinition(oid, func(tableDef *TableDefinition) { tableDef.DistPolicy = distPolicy })
+	}
+	partitionDefs, partTemplateDefs := b.GetPartitionDetails(connectionPool)
+	for oid, partDef := range partitionDefs {
+		setDefinition(oid, func(tableDef *TableDefinition) { tableDef.PartDef = partDef })
+	}
+	for oid, partTemplateDef := range partTemplateDefs {
+		setDefinition(oid, func(tableDef *TableDefinition) { tableDef.PartTemplateDef = partTemplateDef })
+	}
+	tablespaceNames, storageOptions := b.GetTableStorage(connectionPool)
+	for oid, tablespaceName := range tablespaceNames {
+		setDefinition(oid, func(tableDef *TableDefinition) { tableDef.TablespaceName = tablespaceName })
+	}
+	for oid, storageOpts := range storageOptions {
+		setDefinition(oid, func(tableDef *TableDefinition) { tableDef.StorageOpts = storageOpts })
+	}
+	for oid, extTableDef := range GetExternalTableDefinitions(connectionPool) {
+		setDefinition(oid, func(tableDef *TableDefinition) {
+			tableDef.IsExternal = extTableDef.Oid != 0
+			tableDef.ExtTableDef = extTableDef
+		})
+	}
+	for oid, partitionLevelInfo := range b.GetPartitionTableMap(connectionPool) {
+		setDefinition(oid, func(tableDef *TableDefinition) { tableDef.PartitionLevelInfo = partitionLevelInfo })
+	}
+	for oid, tableType := range GetTableType(connectionPool) {
+		setDefinition(oid, func(tableDef *TableDefinition) { tableDef.TableType = tableType })
+	}
+	for oid, isUnlogged := range GetUnloggedTables(connectionPool) {
+		setDefinition(oid, func(tableDef *TableDefinition) { tableDef.IsUnlogged = isUnlogged })
+	}
+	for oid, foreignDef := range GetForeignTableDefinitions(connectionPool) {
+		setDefinition(oid, func(tableDef *TableDefinition) { tableDef.ForeignDef = foreignDef })
+	}
+	for oid, inherits := range b.GetTableInheritance(connectionPool, tableRelations) {
+		if inherits != nil {
+			setDefinition(oid, func(tableDef *TableDefinition) { tableDef.Inherits = inherits })
 		}
-		tables = append(tables, Table{tableRel, tableDef})
+	}
+	for oid, replicaIdentity := range GetTableReplicaIdentity(connectionPool) {
+		setDefinition(oid, func(tableDef *TableDefinition) { tableDef.ReplicaIdentity = replicaIdentity })
+	}
+	for oid, partitionKeyDef := range GetPartitionKeyDefs(connectionPool) {
+		setDefinition(oid, func(tableDef *TableDefinition) { tableDef.PartitionKeyDef = partitionKeyDef })
+	}
+	for oid, attachPartition := range GetAttachPartitionInfo(connectionPool) {
+		setDefinition(oid, func(tableDef *TableDefinition) { tableDef.AttachPartition = attachPartition })
+	}
+	for oid, accessMethodName := range GetTableAccessMethods(connectionPool) {
+		setDefinition(oid, func(tableDef *TableDefinition) { tableDef.AccessMethodName = accessMethodName })
 	}
 	return tables
 }
@@ -118,8 +163,16 @@ type PartitionLevelInfo struct {
 	RootName string
 }
 
-func GetPartitionTableMap(connectionPool *dbconn.DBConn) map[uint32]PartitionLevelInfo {
-	query := `
+func (b *Backup) GetPartitionTableMap(connectionPool *dbconn.DBConn) map[uint32]PartitionLevelInfo {
+	return b.memoizeCatalogQuery("partition table map", func() interface{} {
+		return getPartitionTableMap(connectionPool)
+	}).(map[uint32]PartitionLevelInfo)
+}
+
+func getPartitionTableMap(connectionPool *dbconn.DBConn) map[uint32]PartitionLevelInfo {
+	query := ""
+	if connectionPool.Version.Before("7") {
+		query = `
 	SELECT pc.oid AS oid,
 		'p' AS level,
 		'' AS rootname
@@ -135,6 +188,18 @@ func GetPartitionTableMap(connectionPool *dbconn.DBConn) map[uint32]PartitionLev
 		JOIN (SELECT parrelid AS relid, max(parlevel) AS pl
 			FROM pg_partition GROUP BY parrelid) AS levels ON p.parrelid = levels.relid
 	WHERE r.parchildrelid != 0`
+	} else {
+		query = `
+	SELECT c.oid,
+		CASE WHEN c.relkind = 'p' AND NOT c.relispartition THEN 'p'
+			WHEN c.relkind = 'p' THEN 'i'
+			ELSE 'l'
+		END AS level,
+		CASE WHEN c.relispartition THEN quote_ident(rc.relname) ELSE '' END AS rootname
+	FROM pg_class c
+		LEFT JOIN pg_class rc ON rc.oid = pg_partition_root(c.oid)
+	WHERE c.relkind = 'p' OR c.relispartition`
+	}
 
 	results := make([]PartitionLevelInfo, 0)
 	err := connectionPool.Select(&results, query)
@@ -148,6 +213,117 @@ func GetPartitionTableMap(connectionPool *dbconn.DBConn) map[uint32]PartitionLev
 	return resultMap
 }
 
+/*
+ * RangeStart and RangeEnd are the bounds of a range leaf partition as SQL
+ * expressions, so that they can be compared with partition key values in the
+ * database.  In GPDB 7, the bounds are untyped literals and are cast to the
+ * type of the partition key, KeyType, before they are compared.
+ */
+type LeafPartitionBound struct {
+	Oid          uint32
+	RangeStart   string
+	RangeEnd     string
+	EndInclusive bool
+	KeyType      string
+}
+
+func GetLeafPartitionRangeBounds(connectionPool *dbconn.DBConn) []LeafPartitionBound {
+	query := ""
+	if connectionPool.Version.Before("7") {
+		query = `
+	SELECT r.parchildrelid AS oid,
+		coalesce(pg_get_expr(r.parrangestart, r.parchildrelid), '') AS rangestart,
+		coalesce(pg_get_expr(r.parrangeend, r.parchildrelid), '') AS rangeend,
+		r.parrangeendincl AS endinclusive,
+		'' AS keytype
+	FROM pg_partition p
+		JOIN pg_partition_rule r ON p.oid = r.paroid
+		JOIN (SELECT parrelid AS relid, max(parlevel) AS pl
+			FROM pg_partition GROUP BY parrelid) AS levels ON p.parrelid = levels.relid
+	WHERE r.parchildrelid != 0
+		AND p.parlevel = levels.pl
+		AND p.parkind = 'r'
+		AND NOT p.paristemplate
+		AND NOT r.parisdefault`
+	} else {
+		query = `
+	SELECT c.oid,
+		coalesce(substring(pg_get_expr(c.relpartbound, c.oid) from 'FROM \((.*)\) TO'), '') AS rangestart,
+		coalesce(substring(pg_get_expr(c.relpartbound, c.oid) from 'TO \((.*)\)$'), '') AS rangeend,
+		false AS endinclusive,
+		format_type(a.atttypid, a.atttypmod) AS keytype
+	FROM pg_class c
+		JOIN pg_inherits i ON c.oid = i.inhrelid
+		JOIN pg_partitioned_table pt ON i.inhparent = pt.partrelid
+		JOIN pg_attribute a ON pt.partrelid = a.attrelid AND a.attnum = pt.partattrs[0]
+	WHERE c.relispartition
+		AND c.relkind != 'p'
+		AND pt.partstrat = 'r'
+		AND pt.partnatts = 1`
+	}
+
+	results := make([]LeafPartitionBound, 0)
+	err := connectionPool.Select(&results, query)
+	gplog.FatalOnError(err)
+	return results
+}
+
+/*
+ * Returns the oids of the range leaf partitions whose bounds lie within
+ * keyStart and keyEnd, either of which may be empty to leave that side of
+ * the range open.  A partition with an open bound on a side of the range that
+ * is given, such as one starting at MINVALUE, is not within the range.
+ */
+func GetLeafPartitionOidsInKeyRange(connectionPool *dbconn.DBConn, bounds []LeafPartitionBound, keyStart string, keyEnd string) []string {
+	if len(bounds) == 0 {
+		return []string{}
+	}
+	rows := make([]string, 0, len(bounds))
+	for _, bound := range bounds {
+		rows = append(rows, fmt.Sprintf("('%d', %s)", bound.Oid, leafPartitionKeyRangeCondition(bound, keyStart, keyEnd)))
+	}
+	query := fmt.Sprintf(`
+	SELECT r.leafoid AS string
+	FROM (VALUES %s) AS r(leafoid, inrange)
+	WHERE r.inrange IS TRUE`, strings.Join(rows, ",\n\t\t"))
+	return dbconn.MustSelectStringSlice(connectionPool, query)
+}
+
+func leafPartitionKeyRangeCondition(bound LeafPartitionBound, keyStart string, keyEnd string) string {
+	conditions := make([]string, 0)
+	if keyStart != "" {
+		if isOpenPartitionBound(bound.RangeStart) {
+			return "false"
+		}
+		conditions = append(conditions, fmt.Sprintf("%s >= '%s'", castPartitionBound(bound.RangeStart, bound.KeyType), utils.EscapeSingleQuotes(keyStart)))
+	}
+	if keyEnd != "" {
+		if isOpenPartitionBound(bound.RangeEnd) {
+			return "false"
+		}
+		operator := "<="
+		if bound.EndInclusive {
+			operator = "<"
+		}
+		conditions = append(conditions, fmt.Sprintf("%s %s '%s'", castPartitionBound(bound.RangeEnd, bound.KeyType), operator, utils.EscapeSingleQuotes(keyEnd)))
+	}
+	if len(conditions) == 0 {
+		return "true"
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+func isOpenPartitionBound(bound string) bool {
+	return bound == "" || bound == "MINVALUE" || bound == "MAXVALUE"
+}
+
+func castPartitionBound(bound string, keyType string) string {
+	if keyType == "" {
+		return fmt.Sprintf("(%s)", bound)
+	}
+	return fmt.Sprintf("(%s)::%s", bound, keyType)
+}
+
 type ColumnDefinition struct {
 	Oid                   uint32 `db:"attrelid"`
 	Num                   int    `db:"attnum"`
@@ -176,7 +352,7 @@ var storageTypeCodes = map[string]string{
 	"x": "EXTENDED",
 }
 
-func GetColumnDefinitions(connectionPool *dbconn.DBConn) map[uint32][]ColumnDefinition {
+func (b *Backup) GetColumnDefinitions(connectionPool *dbconn.DBConn) map[uint32][]ColumnDefinition {
 	// This query is adapted from the getTableAttrs() function in pg_dump.c.
 	// Optimize Get column definitions to avoid child partitions
 	// Include child partitions that are also external tables
@@ -202,11 +378,16 @@ func GetColumnDefinitions(connectionPool *dbconn.DBConn) map[uint32][]ColumnDefi
 		LEFT JOIN pg_catalog.pg_type t ON a.atttypid = t.oid
 		LEFT JOIN pg_catalog.pg_attribute_encoding e ON e.attrelid = a.attrelid AND e.attnum = a.attnum
 		LEFT JOIN pg_description d ON d.objoid = a.attrelid AND d.classoid = 'pg_class'::regclass AND d.objsubid = a.attnum`
-	whereClause := `
-	WHERE ` + relationAndSchemaFilterClause() + `
+	childPartitionFilter := `
 		AND NOT EXISTS (SELECT 1 FROM 
 			(SELECT parchildrelid FROM pg_partition_rule EXCEPT SELECT reloid FROM pg_exttable)
-			par WHERE par.parchildrelid = c.oid)
+			par WHERE par.parchildrelid = c.oid)`
+	if connectionPool.Version.AtLeast("7") {
+		// Each partition in GPDB 7 is created with its own column definitions
+		childPartitionFilter = ""
+	}
+	whereClause := `
+	WHERE ` + b.relationAndSchemaFilterClause() + childPartitionFilter + `
 		AND c.reltype <> 0
 		AND a.attnum > 0::pg_catalog.int2
 		AND a.attisdropped = 'f'
@@ -279,6 +460,18 @@ func GetTableType(connectionPool *dbconn.DBConn) map[uint32]string {
 	return selectAsOidToStringMap(connectionPool, query)
 }
 
+/*
+ * In GPDB 7, append-optimized tables are created with the ao_row or ao_column
+ * access method instead of the appendonly storage option.
+ */
+func GetTableAccessMethods(connectionPool *dbconn.DBConn) map[uint32]string {
+	if connectionPool.Version.Before("7") {
+		return map[uint32]string{}
+	}
+	query := `SELECT c.oid, a.amname AS value FROM pg_class c JOIN pg_am a ON c.relam = a.oid`
+	return selectAsOidToStringMap(connectionPool, query)
+}
+
 func GetTableReplicaIdentity(connectionPool *dbconn.DBConn) map[uint32]string {
 	if connectionPool.Version.Before("6") {
 		return map[uint32]string{}
@@ -287,7 +480,16 @@ func GetTableReplicaIdentity(connectionPool *dbconn.DBConn) map[uint32]string {
 	return selectAsOidToStringMap(connectionPool, query)
 }
 
-func GetPartitionDetails(connectionPool *dbconn.DBConn) (map[uint32]string, map[uint32]string) {
+/*
+ * Partitioned tables in GPDB 7 have no partition definition or template, and
+ * are instead created with a partition key and their partitions attached to
+ * them, so their partition definitions come from GetPartitionKeyDefs and
+ * GetAttachPartitionInfo.
+ */
+func (b *Backup) GetPartitionDetails(connectionPool *dbconn.DBConn) (map[uint32]string, map[uint32]string) {
+	if connectionPool.Version.AtLeast("7") {
+		return map[uint32]string{}, map[uint32]string{}
+	}
 	gplog.Info("Getting partition definitions")
 	query := fmt.Sprintf(`
 	SELECT p.parrelid AS oid,
@@ -296,7 +498,7 @@ func GetPartitionDetails(connectionPool *dbconn.DBConn) (map[uint32]string, map[
 	FROM pg_partition p
 		JOIN pg_class c ON p.parrelid = c.oid
 		JOIN pg_namespace n ON c.relnamespace = n.oid
-	WHERE %s`, relationAndSchemaFilterClause())
+	WHERE %s`, b.relationAndSchemaFilterClause())
 	var results []struct {
 		Oid        uint32
 		Definition string
@@ -315,7 +517,49 @@ func GetPartitionDetails(connectionPool *dbconn.DBConn) (map[uint32]string, map[
 	return partitionDef, partitionTemp
 }
 
-func GetTableStorage(connectionPool *dbconn.DBConn) (map[uint32]string, map[uint32]string) {
+func GetPartitionKeyDefs(connectionPool *dbconn.DBConn) map[uint32]string {
+	if connectionPool.Version.Before("7") {
+		return map[uint32]string{}
+	}
+	query := `SELECT partrelid AS oid, pg_get_partkeydef(partrelid) AS value FROM pg_partitioned_table`
+	return selectAsOidToStringMap(connectionPool, query)
+}
+
+/*
+ * The parent of a partition and the bound it is attached with, such as
+ * "FOR VALUES FROM (1) TO (10)" or "DEFAULT".
+ */
+type AttachPartitionInfo struct {
+	Oid    uint32
+	Parent string
+	Bound  string
+}
+
+func GetAttachPartitionInfo(connectionPool *dbconn.DBConn) map[uint32]AttachPartitionInfo {
+	resultMap := make(map[uint32]AttachPartitionInfo)
+	if connectionPool.Version.Before("7") {
+		return resultMap
+	}
+	query := `
+	SELECT c.oid,
+		quote_ident(pn.nspname) || '.' || quote_ident(pc.relname) AS parent,
+		pg_get_expr(c.relpartbound, c.oid) AS bound
+	FROM pg_class c
+		JOIN pg_inherits i ON c.oid = i.inhrelid
+		JOIN pg_class pc ON i.inhparent = pc.oid
+		JOIN pg_namespace pn ON pc.relnamespace = pn.oid
+	WHERE c.relispartition`
+
+	results := make([]AttachPartitionInfo, 0)
+	err := connectionPool.Select(&results, query)
+	gplog.FatalOnError(err)
+	for _, result := range results {
+		resultMap[result.Oid] = result
+	}
+	return resultMap
+}
+
+func (b *Backup) GetTableStorage(connectionPool *dbconn.DBConn) (map[uint32]string, map[uint32]string) {
 	gplog.Info("Getting storage information")
 	query := fmt.Sprintf(`
 	SELECT c.oid,
@@ -326,7 +570,7 @@ func GetTableStorage(connectionPool *dbconn.DBConn) (map[uint32]string, map[uint
 		LEFT JOIN pg_tablespace t ON t.oid = c.reltablespace
 	WHERE %s
 		AND (t.spcname IS NOT NULL OR reloptions IS NOT NULL)`,
-		relationAndSchemaFilterClause())
+		b.relationAndSchemaFilterClause())
 	var results []struct {
 		Oid        uint32
 		Tablespace sql.NullString
@@ -375,7 +619,7 @@ func GetForeignTableDefinitions(connectionPool *dbconn.DBConn) map[uint32]Foreig
 		return map[uint32]ForeignTableDefinition{}
 	}
 	query := `
-	SELECT ftrelid, fs.srvname AS ftserver,
+	SELECT ftrelid, quote_ident(fs.srvname) AS ftserver,
 		pg_catalog.array_to_string(array(
 			SELECT pg_catalog.quote_ident(option_name) || ' ' || pg_catalog.quote_literal(option_value)
 			FROM pg_catalog.pg_options_to_table(ftoptions) ORDER BY option_name
@@ -397,9 +641,9 @@ type Dependency struct {
 	ReferencedObject string
 }
 
-func GetTableInheritance(connectionPool *dbconn.DBConn, tables []Relation) map[uint32][]string {
+func (b *Backup) GetTableInheritance(connectionPool *dbconn.DBConn, tables []Relation) map[uint32][]string {
 	tableFilterStr := ""
-	if len(MustGetFlagStringArray(utils.INCLUDE_RELATION)) > 0 {
+	if len(b.MustGetFlagStringArray(utils.INCLUDE_RELATION)) > 0 {
 		tableOidList := make([]string, len(tables))
 		for i, table := range tables {
 			tableOidList[i] = fmt.Sprintf("%d", table.Oid)
@@ -410,6 +654,11 @@ func GetTableInheritance(connectionPool *dbconn.DBConn, tables []Relation) map[u
 		}
 	}
 
+	// Partitions in GPDB 7 are attached to their parents rather than inheriting from them
+	if connectionPool.Version.AtLeast("7") {
+		tableFilterStr += "\nAND i.inhrelid NOT IN (SELECT oid FROM pg_class WHERE relispartition)"
+	}
+
 	query := fmt.Sprintf(`
 	SELECT i.inhrelid AS oid,
 		quote_ident(n.nspname) || '.' || quote_ident(p.relname) AS referencedobject
@@ -418,7 +667,7 @@ func GetTableInheritance(connectionPool *dbconn.DBConn, tables []Relation) map[u
 		JOIN pg_namespace n ON p.relnamespace = n.oid
 	WHERE %s%s
 	ORDER BY i.inhrelid, i.inhseqno`,
-	ExtensionFilterClause("p"), tableFilterStr)
+		ExtensionFilterClause("p"), tableFilterStr)
 
 	results := make([]Dependency, 0)
 	resultMap := make(map[uint32][]string)
@@ -443,3 +692,207 @@ func selectAsOidToStringMap(connectionPool *dbconn.DBConn, query string) map[uin
 	}
 	return resultMap
 }
+
+/*
+ * Returns the on-disk size of each table across all segments, used to order
+ * the data backup so that the largest tables are started first.  The size of
+ * a partition root includes all of its partitions, since COPY on the root
+ * table copies the data of every leaf partition.
+ */
+func (b *Backup) GetTableDataSizes(connectionPool *dbconn.DBConn, tables []Table) map[uint32]int64 {
+	sizeMap := make(map[uint32]int64)
+	oidList := make([]string, 0, len(tables))
+	for _, table := range tables {
+		if !b.SkipDataBackup(table) {
+			oidList = append(oidList, fmt.Sprintf("%d", table.Oid))
+		}
+	}
+	if len(oidList) == 0 {
+		return sizeMap
+	}
+	query := fmt.Sprintf(`
+	SELECT c.oid,
+		pg_relation_size(c.oid) + coalesce(p.partitionsize, 0) AS size
+	FROM pg_class c
+		LEFT JOIN (
+			SELECT parentoid, sum(pg_relation_size(childoid)) AS partitionsize
+			FROM (%s) pc
+			GROUP BY parentoid
+		) p ON c.oid = p.parentoid
+	WHERE c.oid IN (%s)`, partitionChildrenQuery(connectionPool), strings.Join(oidList, ", "))
+
+	var results []struct {
+		Oid  uint32
+		Size int64
+	}
+	err := connectionPool.Select(&results, query)
+	gplog.FatalOnError(err)
+	for _, result := range results {
+		sizeMap[result.Oid] = result.Size
+	}
+	return sizeMap
+}
+
+/*
+ * Returns a query for the oid of each partition of a partition table and the
+ * oid of the root partition table it belongs to.
+ */
+func partitionChildrenQuery(connectionPool *dbconn.DBConn) string {
+	if connectionPool.Version.Before("7") {
+		return `
+	SELECT pp.parrelid AS parentoid,
+		pr.parchildrelid AS childoid
+	FROM pg_partition pp
+		JOIN pg_partition_rule pr ON pp.oid = pr.paroid
+	WHERE pp.paristemplate = false
+		AND pr.parchildrelid != 0`
+	}
+	return `
+	SELECT pg_partition_root(c.oid) AS parentoid,
+		c.oid AS childoid
+	FROM pg_class c
+	WHERE c.relispartition`
+}
+
+/*
+ * Returns the size of the largest row of each table whose TOAST data is at
+ * least minToastSize bytes, as a table with less TOAST data than that cannot
+ * contain a row that large.  Column sizes are measured as stored, so that
+ * values are not decompressed, but each such table must still be scanned.
+ */
+func (b *Backup) GetLargestRowSizes(connectionPool *dbconn.DBConn, tables []Table, minToastSize int64) map[uint32]int64 {
+	sizeMap := make(map[uint32]int64)
+	tableMap := make(map[string]Table)
+	oidList := make([]string, 0, len(tables))
+	for _, table := range tables {
+		if !b.SkipDataBackup(table) && len(table.ColumnDefs) > 0 {
+			oid := fmt.Sprintf("%d", table.Oid)
+			tableMap[oid] = table
+			oidList = append(oidList, oid)
+		}
+	}
+	if len(oidList) == 0 {
+		return sizeMap
+	}
+	query := fmt.Sprintf(`
+	SELECT c.oid::text AS string
+	FROM pg_class c
+	WHERE c.oid IN (%s)
+		AND c.reltoastrelid != 0
+		AND pg_relation_size(c.reltoastrelid) >= %d`, strings.Join(oidList, ", "), minToastSize)
+	candidateOids := dbconn.MustSelectStringSlice(connectionPool, query)
+
+	for _, oid := range candidateOids {
+		table := tableMap[oid]
+		columnSizes := make([]string, 0, len(table.ColumnDefs))
+		for _, column := range table.ColumnDefs {
+			columnSizes = append(columnSizes, fmt.Sprintf("coalesce(pg_column_size(%s), 0)", column.Name))
+		}
+		gplog.Verbose("Checking row sizes of table %s", table.FQN())
+		b.SetCurrentObject("table", table.FQN())
+		rowSizeQuery := fmt.Sprintf("SELECT coalesce(max(%s), 0)::text AS string FROM %s", strings.Join(columnSizes, " + "), table.FQN())
+		size, err := strconv.ParseInt(dbconn.MustSelectString(connectionPool, rowSizeQuery), 10, 64)
+		gplog.FatalOnError(err)
+		sizeMap[table.Oid] = size
+	}
+	return sizeMap
+}
+
+/*
+ * Returns the total on-disk size, including indexes and TOAST data, of the
+ * given tables on each segment, keyed by content ID.  The partitions of any
+ * partition root are included, as their data is backed up with the root.
+ */
+func (b *Backup) GetSegmentDataSizes(connectionPool *dbconn.DBConn, tables []Table) map[int]int64 {
+	sizeMap := make(map[int]int64)
+	oidList := make([]string, 0, len(tables))
+	for _, table := range tables {
+		if !b.SkipDataBackup(table) {
+			oidList = append(oidList, fmt.Sprintf("%d", table.Oid))
+		}
+	}
+	if len(oidList) == 0 {
+		return sizeMap
+	}
+	partitionQuery := fmt.Sprintf(`
+	SELECT childoid AS string
+	FROM (%s) pc
+	WHERE parentoid IN (%s)`, partitionChildrenQuery(connectionPool), strings.Join(oidList, ", "))
+	oidList = append(oidList, dbconn.MustSelectStringSlice(connectionPool, partitionQuery)...)
+
+	query := fmt.Sprintf(`
+	SELECT gp_segment_id AS contentid,
+		sum(pg_total_relation_size(oid))::bigint AS size
+	FROM gp_dist_random('pg_class')
+	WHERE oid IN (%s)
+	GROUP BY gp_segment_id`, strings.Join(oidList, ", "))
+
+	var results []struct {
+		ContentID int
+		Size      int64
+	}
+	err := connectionPool.Select(&results, query)
+	gplog.FatalOnError(err)
+	for _, result := range results {
+		sizeMap[result.ContentID] = result.Size
+	}
+	return sizeMap
+}
+
+/*
+ * Returns the size of each table on each segment, with the sizes of the leaf
+ * partitions of a partition table counted toward the table itself.
+ */
+func (b *Backup) GetTableSegmentDataSizes(connectionPool *dbconn.DBConn, tables []Table) map[uint32]map[int]int64 {
+	sizeMap := make(map[uint32]map[int]int64)
+	oidList := make([]string, 0, len(tables))
+	for _, table := range tables {
+		if !b.SkipDataBackup(table) {
+			oidList = append(oidList, fmt.Sprintf("%d", table.Oid))
+		}
+	}
+	if len(oidList) == 0 {
+		return sizeMap
+	}
+	partitionQuery := fmt.Sprintf(`
+	SELECT parentoid, childoid
+	FROM (%s) pc
+	WHERE parentoid IN (%s)`, partitionChildrenQuery(connectionPool), strings.Join(oidList, ", "))
+	var partitions []struct {
+		ParentOid uint32
+		ChildOid  uint32
+	}
+	err := connectionPool.Select(&partitions, partitionQuery)
+	gplog.FatalOnError(err)
+	parentOids := make(map[uint32]uint32, len(partitions))
+	for _, partition := range partitions {
+		parentOids[partition.ChildOid] = partition.ParentOid
+		oidList = append(oidList, fmt.Sprintf("%d", partition.ChildOid))
+	}
+
+	query := fmt.Sprintf(`
+	SELECT oid,
+		gp_segment_id AS contentid,
+		pg_relation_size(oid) AS size
+	FROM gp_dist_random('pg_class')
+	WHERE oid IN (%s)`, strings.Join(oidList, ", "))
+
+	var results []struct {
+		Oid       uint32
+		ContentID int
+		Size      int64
+	}
+	err = connectionPool.Select(&results, query)
+	gplog.FatalOnError(err)
+	for _, result := range results {
+		oid := result.Oid
+		if parentOid, ok := parentOids[oid]; ok {
+			oid = parentOid
+		}
+		if _, ok := sizeMap[oid]; !ok {
+			sizeMap[oid] = make(map[int]int64)
+		}
+		sizeMap[oid][result.ContentID] += result.Size
+	}
+	return sizeMap
+}
@@ -0,0 +1,71 @@
+package backup_test
+
+import (
+	"regexp"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"github.com/greenplum-db/gpbackup/backup"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backup/queries_table_defs tests", func() {
+	Describe("GetTableAccessMethods", func() {
+		It("returns the access method of each table in GPDB 7", func() {
+			testhelper.SetDBVersion(connectionPool, "7.0.0")
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT c.oid, a.amname AS value FROM pg_class c JOIN pg_am a ON c.relam = a.oid")).
+				WillReturnRows(sqlmock.NewRows([]string{"oid", "value"}).AddRow(1, "heap").AddRow(2, "ao_column"))
+
+			Expect(backup.GetTableAccessMethods(connectionPool)).To(Equal(map[uint32]string{1: "heap", 2: "ao_column"}))
+		})
+		It("does not query access methods before GPDB 7", func() {
+			testhelper.SetDBVersion(connectionPool, "6.0.0")
+
+			Expect(backup.GetTableAccessMethods(connectionPool)).To(BeEmpty())
+		})
+	})
+	Describe("GetLeafPartitionOidsInKeyRange", func() {
+		gpdb6Bound := backup.LeafPartitionBound{Oid: 1, RangeStart: "'2023-01-01'::date", RangeEnd: "'2023-02-01'::date"}
+		gpdb7Bound := backup.LeafPartitionBound{Oid: 2, RangeStart: "'2023-02-01'", RangeEnd: "'2023-03-01'", KeyType: "date"}
+		inclusiveBound := backup.LeafPartitionBound{Oid: 3, RangeStart: "'2023-03-01'::date", RangeEnd: "'2023-12-31'::date", EndInclusive: true}
+		openBound := backup.LeafPartitionBound{Oid: 4, RangeStart: "MINVALUE", RangeEnd: "'2023-01-01'", KeyType: "date"}
+
+		It("compares the bounds of each leaf partition with both ends of the range", func() {
+			mock.ExpectQuery(regexp.QuoteMeta(`
+	SELECT r.leafoid AS string
+	FROM (VALUES ('1', ('2023-01-01'::date) >= '2023-01-01' AND ('2023-02-01'::date) <= '2024-01-01'),
+		('2', ('2023-02-01')::date >= '2023-01-01' AND ('2023-03-01')::date <= '2024-01-01'),
+		('3', ('2023-03-01'::date) >= '2023-01-01' AND ('2023-12-31'::date) < '2024-01-01'),
+		('4', false)) AS r(leafoid, inrange)
+	WHERE r.inrange IS TRUE`)).WillReturnRows(sqlmock.NewRows([]string{"string"}).AddRow("1").AddRow("2").AddRow("3"))
+
+			oids := backup.GetLeafPartitionOidsInKeyRange(connectionPool, []backup.LeafPartitionBound{gpdb6Bound, gpdb7Bound, inclusiveBound, openBound}, "2023-01-01", "2024-01-01")
+			Expect(oids).To(Equal([]string{"1", "2", "3"}))
+		})
+		It("leaves a side of the range open if it is not given", func() {
+			mock.ExpectQuery(regexp.QuoteMeta(`
+	SELECT r.leafoid AS string
+	FROM (VALUES ('4', ('2023-01-01')::date <= '2023-06-01')) AS r(leafoid, inrange)
+	WHERE r.inrange IS TRUE`)).WillReturnRows(sqlmock.NewRows([]string{"string"}).AddRow("4"))
+
+			oids := backup.GetLeafPartitionOidsInKeyRange(connectionPool, []backup.LeafPartitionBound{openBound}, "", "2023-06-01")
+			Expect(oids).To(Equal([]string{"4"}))
+		})
+		It("escapes quotes in partition key values", func() {
+			textBound := backup.LeafPartitionBound{Oid: 5, RangeStart: "'a'", RangeEnd: "'m'", KeyType: "text"}
+			mock.ExpectQuery(regexp.QuoteMeta(`
+	SELECT r.leafoid AS string
+	FROM (VALUES ('5', ('a')::text >= 'it''s')) AS r(leafoid, inrange)
+	WHERE r.inrange IS TRUE`)).WillReturnRows(sqlmock.NewRows([]string{"string"}))
+
+			oids := backup.GetLeafPartitionOidsInKeyRange(connectionPool, []backup.LeafPartitionBound{textBound}, "it's", "")
+			Expect(oids).To(BeEmpty())
+		})
+		It("does not query the database if there are no range leaf partitions", func() {
+			oids := backup.GetLeafPartitionOidsInKeyRange(connectionPool, []backup.LeafPartitionBound{}, "2023-01-01", "2024-01-01")
+			Expect(oids).To(BeEmpty())
+		})
+	})
+})
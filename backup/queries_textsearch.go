@@ -47,7 +47,7 @@ func (tsp TextSearchParser) FQN() string {
 	return utils.MakeFQN(tsp.Schema, tsp.Name)
 }
 
-func GetTextSearchParsers(connectionPool *dbconn.DBConn) []TextSearchParser {
+func (b *Backup) GetTextSearchParsers(connectionPool *dbconn.DBConn) []TextSearchParser {
 	query := fmt.Sprintf(`
 	SELECT p.oid,
 		quote_ident(nspname) AS schema,
@@ -64,7 +64,7 @@ func GetTextSearchParsers(connectionPool *dbconn.DBConn) []TextSearchParser {
 		JOIN pg_namespace n ON n.oid = p.prsnamespace
 	WHERE %s
 		AND %s
-	ORDER BY prsname`, SchemaFilterClause("n"), ExtensionFilterClause("p"))
+	ORDER BY prsname`, b.SchemaFilterClause("n"), ExtensionFilterClause("p"))
 
 	results := make([]TextSearchParser, 0)
 	err := connectionPool.Select(&results, query)
@@ -100,7 +100,7 @@ func (tst TextSearchTemplate) FQN() string {
 	return utils.MakeFQN(tst.Schema, tst.Name)
 }
 
-func GetTextSearchTemplates(connectionPool *dbconn.DBConn) []TextSearchTemplate {
+func (b *Backup) GetTextSearchTemplates(connectionPool *dbconn.DBConn) []TextSearchTemplate {
 	query := fmt.Sprintf(`
 	SELECT p.oid,
 		quote_ident(nspname) as schema,
@@ -115,7 +115,7 @@ func GetTextSearchTemplates(connectionPool *dbconn.DBConn) []TextSearchTemplate
 	WHERE %s
 		AND %s
 	ORDER BY tmplname`,
-	SchemaFilterClause("n"), ExtensionFilterClause("p"))
+		b.SchemaFilterClause("n"), ExtensionFilterClause("p"))
 
 	results := make([]TextSearchTemplate, 0)
 	err := connectionPool.Select(&results, query)
@@ -151,7 +151,7 @@ func (tsd TextSearchDictionary) FQN() string {
 	return utils.MakeFQN(tsd.Schema, tsd.Name)
 }
 
-func GetTextSearchDictionaries(connectionPool *dbconn.DBConn) []TextSearchDictionary {
+func (b *Backup) GetTextSearchDictionaries(connectionPool *dbconn.DBConn) []TextSearchDictionary {
 	query := fmt.Sprintf(`
 	SELECT d.oid,
 		quote_ident(dict_ns.nspname) as schema,
@@ -165,7 +165,7 @@ func GetTextSearchDictionaries(connectionPool *dbconn.DBConn) []TextSearchDictio
 	WHERE %s
 		AND %s
 	ORDER BY dictname`,
-	SchemaFilterClause("dict_ns"), ExtensionFilterClause("d"))
+		b.SchemaFilterClause("dict_ns"), ExtensionFilterClause("d"))
 
 	results := make([]TextSearchDictionary, 0)
 	err := connectionPool.Select(&results, query)
@@ -201,7 +201,7 @@ func (tsc TextSearchConfiguration) FQN() string {
 	return utils.MakeFQN(tsc.Schema, tsc.Name)
 }
 
-func GetTextSearchConfigurations(connectionPool *dbconn.DBConn) []TextSearchConfiguration {
+func (b *Backup) GetTextSearchConfigurations(connectionPool *dbconn.DBConn) []TextSearchConfiguration {
 	query := fmt.Sprintf(`
 	SELECT c.oid AS configoid,
 		quote_ident(cfg_ns.nspname) AS schema,
@@ -215,7 +215,7 @@ func GetTextSearchConfigurations(connectionPool *dbconn.DBConn) []TextSearchConf
 	WHERE %s
 		AND %s
 	ORDER BY cfgname`,
-	SchemaFilterClause("cfg_ns"), ExtensionFilterClause("c"))
+		b.SchemaFilterClause("cfg_ns"), ExtensionFilterClause("c"))
 
 	results := make([]struct {
 		Schema    string
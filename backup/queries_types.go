@@ -61,7 +61,7 @@ func (t BaseType) FQN() string {
 	return utils.MakeFQN(t.Schema, t.Name)
 }
 
-func GetBaseTypes(connectionPool *dbconn.DBConn) []BaseType {
+func (b *Backup) GetBaseTypes(connectionPool *dbconn.DBConn) []BaseType {
 	gplog.Verbose("Getting base types")
 	version4query := fmt.Sprintf(`
 	SELECT t.oid,
@@ -96,7 +96,7 @@ func GetBaseTypes(connectionPool *dbconn.DBConn) []BaseType {
 	WHERE %s
 		AND t.typtype = 'b'
 		AND ut.oid IS NULL
-		AND %s`, SchemaFilterClause("n"), ExtensionFilterClause("t"))
+		AND %s`, b.SchemaFilterClause("n"), ExtensionFilterClause("t"))
 
 	version5query := fmt.Sprintf(`
 	SELECT t.oid,
@@ -128,7 +128,7 @@ func GetBaseTypes(connectionPool *dbconn.DBConn) []BaseType {
 	WHERE %s
 		AND t.typtype = 'b'
 		AND ut.oid IS NULL
-		AND %s`, SchemaFilterClause("n"), ExtensionFilterClause("t"))
+		AND %s`, b.SchemaFilterClause("n"), ExtensionFilterClause("t"))
 
 	masterQuery := fmt.Sprintf(`
 	SELECT t.oid,
@@ -162,7 +162,7 @@ func GetBaseTypes(connectionPool *dbconn.DBConn) []BaseType {
 	WHERE %s
 		AND t.typtype = 'b'
 		AND ut.oid IS NULL
-		AND %s`, SchemaFilterClause("n"), ExtensionFilterClause("t"))
+		AND %s`, b.SchemaFilterClause("n"), ExtensionFilterClause("t"))
 
 	results := make([]BaseType, 0)
 	var err error
@@ -211,7 +211,7 @@ func (t CompositeType) FQN() string {
 	return utils.MakeFQN(t.Schema, t.Name)
 }
 
-func GetCompositeTypes(connectionPool *dbconn.DBConn) []CompositeType {
+func (b *Backup) GetCompositeTypes(connectionPool *dbconn.DBConn) []CompositeType {
 	gplog.Verbose("Getting composite types")
 	query := fmt.Sprintf(`
 	SELECT t.oid,
@@ -227,7 +227,7 @@ func GetCompositeTypes(connectionPool *dbconn.DBConn) []CompositeType {
 	WHERE %s
 		AND t.typtype = 'c'
 		AND c.relkind = 'c'
-		AND %s`, SchemaFilterClause("n"), ExtensionFilterClause("t"))
+		AND %s`, b.SchemaFilterClause("n"), ExtensionFilterClause("t"))
 
 	compTypes := make([]CompositeType, 0)
 	err := connectionPool.Select(&compTypes, query)
@@ -329,7 +329,7 @@ func (t Domain) FQN() string {
 	return utils.MakeFQN(t.Schema, t.Name)
 }
 
-func GetDomainTypes(connectionPool *dbconn.DBConn) []Domain {
+func (b *Backup) GetDomainTypes(connectionPool *dbconn.DBConn) []Domain {
 	gplog.Verbose("Getting domain types")
 	results := make([]Domain, 0)
 	before6query := fmt.Sprintf(`
@@ -344,7 +344,7 @@ func GetDomainTypes(connectionPool *dbconn.DBConn) []Domain {
 	WHERE %s
 		AND t.typtype = 'd'
 		AND %s
-	ORDER BY n.nspname, t.typname`, SchemaFilterClause("n"), ExtensionFilterClause("t"))
+	ORDER BY n.nspname, t.typname`, b.SchemaFilterClause("n"), ExtensionFilterClause("t"))
 
 	masterQuery := fmt.Sprintf(`
 	SELECT t.oid,
@@ -366,7 +366,7 @@ func GetDomainTypes(connectionPool *dbconn.DBConn) []Domain {
 	WHERE %s
 		AND t.typtype = 'd'
 		AND %s
-	ORDER BY n.nspname, t.typname`, SchemaFilterClause("n"), ExtensionFilterClause("t"))
+	ORDER BY n.nspname, t.typname`, b.SchemaFilterClause("n"), ExtensionFilterClause("t"))
 	var err error
 
 	if connectionPool.Version.Before("6") {
@@ -398,7 +398,7 @@ func (t EnumType) FQN() string {
 	return utils.MakeFQN(t.Schema, t.Name)
 }
 
-func GetEnumTypes(connectionPool *dbconn.DBConn) []EnumType {
+func (b *Backup) GetEnumTypes(connectionPool *dbconn.DBConn) []EnumType {
 	enumSortClause := "ORDER BY e.enumsortorder"
 	if connectionPool.Version.Is("5") {
 		enumSortClause = "ORDER BY e.oid"
@@ -415,7 +415,7 @@ func GetEnumTypes(connectionPool *dbconn.DBConn) []EnumType {
 	WHERE %s
 		AND t.typtype = 'e'
 		AND %s
-	ORDER BY n.nspname, t.typname`, enumSortClause, SchemaFilterClause("n"), ExtensionFilterClause("t"))
+	ORDER BY n.nspname, t.typname`, enumSortClause, b.SchemaFilterClause("n"), ExtensionFilterClause("t"))
 
 	results := make([]EnumType, 0)
 	err := connectionPool.Select(&results, query)
@@ -446,7 +446,7 @@ func (t RangeType) FQN() string {
 	return utils.MakeFQN(t.Schema, t.Name)
 }
 
-func GetRangeTypes(connectionPool *dbconn.DBConn) []RangeType {
+func (b *Backup) GetRangeTypes(connectionPool *dbconn.DBConn) []RangeType {
 	gplog.Verbose("Retrieving range types")
 	query := fmt.Sprintf(`
 	SELECT t.oid,
@@ -479,7 +479,7 @@ func GetRangeTypes(connectionPool *dbconn.DBConn) []RangeType {
 		LEFT JOIN pg_namespace nopc ON nopc.oid = opc.opcnamespace
 	WHERE %s
 		AND t.typtype = 'r'
-		AND %s`, SchemaFilterClause("n"), ExtensionFilterClause("t"))
+		AND %s`, b.SchemaFilterClause("n"), ExtensionFilterClause("t"))
 
 	results := make([]RangeType, 0)
 	err := connectionPool.Select(&results, query)
@@ -505,7 +505,7 @@ func (t ShellType) FQN() string {
 	return utils.MakeFQN(t.Schema, t.Name)
 }
 
-func GetShellTypes(connectionPool *dbconn.DBConn) []ShellType {
+func (b *Backup) GetShellTypes(connectionPool *dbconn.DBConn) []ShellType {
 	gplog.Verbose("Getting shell types")
 	query := fmt.Sprintf(`
 	SELECT t.oid,
@@ -516,7 +516,7 @@ func GetShellTypes(connectionPool *dbconn.DBConn) []ShellType {
 	WHERE %s
 		AND t.typtype = 'p'
 		AND %s
-	ORDER BY n.nspname, t.typname`, SchemaFilterClause("n"), ExtensionFilterClause("t"))
+	ORDER BY n.nspname, t.typname`, b.SchemaFilterClause("n"), ExtensionFilterClause("t"))
 
 	results := make([]ShellType, 0)
 	err := connectionPool.Select(&results, query)
@@ -524,12 +524,19 @@ func GetShellTypes(connectionPool *dbconn.DBConn) []ShellType {
 	return results
 }
 
+/*
+ * Provider and IsDeterministic are only set in GPDB 7 and later, where
+ * collations can use ICU and compare strings that differ only in ways the
+ * collation ignores as equal.
+ */
 type Collation struct {
-	Oid     uint32
-	Schema  string
-	Name    string
-	Collate string
-	Ctype   string
+	Oid             uint32
+	Schema          string
+	Name            string
+	Collate         string
+	Ctype           string
+	Provider        string
+	IsDeterministic bool
 }
 
 func (c Collation) GetMetadataEntry() (string, utils.MetadataEntry) {
@@ -552,16 +559,28 @@ func (c Collation) FQN() string {
 	return utils.MakeFQN(c.Schema, c.Name)
 }
 
-func GetCollations(connectionPool *dbconn.DBConn) []Collation {
+func (b *Backup) GetCollations(connectionPool *dbconn.DBConn) []Collation {
+	providerCols := `'' AS provider,
+		't' AS isdeterministic`
+	if connectionPool.Version.AtLeast("7") {
+		providerCols = `CASE c.collprovider
+			WHEN 'i' THEN 'icu'
+			WHEN 'c' THEN 'libc'
+			ELSE ''
+		END AS provider,
+		c.collisdeterministic AS isdeterministic`
+	}
 	query := fmt.Sprintf(`
 	SELECT c.oid,
 		quote_ident(n.nspname) AS schema,
 		quote_ident(c.collname) AS name,
 		c.collcollate AS collate,
-		c.collctype AS ctype
+		c.collctype AS ctype,
+		%s
 	FROM pg_collation c
 		JOIN pg_namespace n ON c.collnamespace = n.oid
-	WHERE %s`, SchemaFilterClause("n"))
+	WHERE %s
+		AND %s`, providerCols, b.SchemaFilterClause("n"), ExtensionFilterClause("c"))
 
 	results := make([]Collation, 0)
 	err := connectionPool.Select(&results, query)
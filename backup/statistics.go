@@ -13,40 +13,64 @@ import (
 	"github.com/lib/pq"
 )
 
-func PrintStatisticsStatements(statisticsFile *utils.FileWithByteCount, toc *utils.TOC, tables []Table, attStats map[uint32][]AttributeStatistic, tupleStats map[uint32]TupleStatistic) {
+func (b *Backup) PrintStatisticsStatements(statisticsFile *utils.FileWithByteCount, toc *utils.TOC, tables []Table, attStats map[uint32][]AttributeStatistic, tupleStats map[uint32]TupleStatistic) {
 	for _, table := range tables {
-		PrintStatisticsStatementsForTable(statisticsFile, toc, table, attStats[table.Oid], tupleStats[table.Oid])
+		b.SetCurrentObject("statistics for table", table.FQN())
+		b.PrintStatisticsStatementsForTable(statisticsFile, toc, table, attStats[table.Oid], tupleStats[table.Oid])
 	}
 }
 
-func PrintStatisticsStatementsForTable(statisticsFile *utils.FileWithByteCount, toc *utils.TOC, table Table, attStats []AttributeStatistic, tupleStat TupleStatistic) {
+/*
+ * The server does not accept input for the types in which extended statistics
+ * data is stored, so that data is rebuilt by analyzing each table with
+ * extended statistics objects instead.  These statements are printed before
+ * the statistics statements for any table, so that the statistics backed up
+ * for the table are restored over those computed by ANALYZE.
+ */
+func PrintExtendedStatisticsAnalyzeStatements(statisticsFile *utils.FileWithByteCount, toc *utils.TOC, statistics []ExtendedStatistic) {
+	analyzed := make(map[string]bool)
+	for _, statistic := range statistics {
+		tableFQN := utils.MakeFQN(statistic.OwningSchema, statistic.OwningTable)
+		if analyzed[tableFQN] {
+			continue
+		}
+		analyzed[tableFQN] = true
+		entry := utils.MetadataEntry{Schema: statistic.OwningSchema, Name: statistic.OwningTable, ObjectType: "EXTENDED STATISTICS", ReferenceObject: tableFQN}
+		statisticsFile.MustPrintEntry(toc, "statistics", entry, "\n\nANALYZE %s;\n", tableFQN)
+	}
+}
+
+func (b *Backup) PrintStatisticsStatementsForTable(statisticsFile *utils.FileWithByteCount, toc *utils.TOC, table Table, attStats []AttributeStatistic, tupleStat TupleStatistic) {
 	start := statisticsFile.ByteCount
 	tupleQuery := GenerateTupleStatisticsQuery(table, tupleStat)
 	statisticsFile.MustPrintf("\n\n%s\n", tupleQuery)
 	for _, attStat := range attStats {
-		attributeQuery := GenerateAttributeStatisticsQuery(table, attStat)
+		attributeQuery := b.GenerateAttributeStatisticsQuery(table, attStat)
 		statisticsFile.MustPrintf("\n\n%s\n", attributeQuery)
 	}
 	entry := utils.MetadataEntry{Schema: table.Schema, Name: table.Name, ObjectType: "STATISTICS"}
 	toc.AddMetadataEntry("statistics", entry, start, statisticsFile.ByteCount)
 }
 
+/*
+ * As with the attribute statistics below, the table is looked up by name
+ * rather than by its OID in the source database, which will not match the
+ * OID in the restore database.
+ */
 func GenerateTupleStatisticsQuery(table Table, tupleStat TupleStatistic) string {
 	tupleQuery := `UPDATE pg_class
 SET
 	relpages = %d::int,
 	reltuples = %f::real
-WHERE relname = '%s'
-AND relnamespace = %d;`
+WHERE oid = '%s'::regclass::oid;`
 	return fmt.Sprintf(
 		tupleQuery,
 		tupleStat.RelPages,
 		tupleStat.RelTuples,
-		utils.EscapeSingleQuotes(tupleStat.Table),
-		table.SchemaOid)
+		utils.EscapeSingleQuotes(table.FQN()))
 }
 
-func GenerateAttributeStatisticsQuery(table Table, attStat AttributeStatistic) string {
+func (b *Backup) GenerateAttributeStatisticsQuery(table Table, attStat AttributeStatistic) string {
 	/*
 	 * When restoring statistics to a new database, we cannot determine what the
 	 * new OID for a given object will be, so we need to perform an explicit cast
@@ -57,7 +81,7 @@ func GenerateAttributeStatisticsQuery(table Table, attStat AttributeStatistic) s
 	// The entry may or may not already exist, so we can't either just UPDATE or just INSERT without a DELETE.
 	inheritStr := ""
 	attributeSlotsQueryStr := ""
-	if connectionPool.Version.AtLeast("6") {
+	if b.connectionPool.Version.AtLeast("6") {
 		inheritStr = fmt.Sprintf("\n\t%t::boolean,", attStat.Inherit)
 		attributeSlotsQueryStr = generateAttributeSlotsQueryMaster(attStat)
 	} else {
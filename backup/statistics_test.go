@@ -27,14 +27,13 @@ var _ = Describe("backup/statistics tests", func() {
 		It("prints tuple and attribute stats for single table with no stats", func() {
 			tupleStats = backup.TupleStatistic{Schema: "testschema", Table: "testtable"}
 			attStats = []backup.AttributeStatistic{}
-			backup.PrintStatisticsStatementsForTable(backupfile, toc, tableTestTable, attStats, tupleStats)
+			testBackup.PrintStatisticsStatementsForTable(backupfile, toc, tableTestTable, attStats, tupleStats)
 			testutils.ExpectEntry(toc.StatisticsEntries, 0, "testschema", "", "testtable", "STATISTICS")
 			testutils.AssertBufferContents(toc.StatisticsEntries, buffer, `UPDATE pg_class
 SET
 	relpages = 0::int,
 	reltuples = 0.000000::real
-WHERE relname = 'testtable'
-AND relnamespace = 0;`)
+WHERE oid = 'testschema.testtable'::regclass::oid;`)
 		})
 		It("prints tuple and attribute stats for single table with stats", func() {
 			tupleStats = backup.TupleStatistic{Schema: "testschema", Table: "testtable"}
@@ -43,14 +42,13 @@ AND relnamespace = 0;`)
 				{Schema: "testschema", Table: "testtable", AttName: "testatt", Type: "_array", Relid: 2, AttNumber: 3, NullFraction: .4,
 					Width: 10, Distinct: .5, Kind1: 20, Operator1: 10, Numbers1: []string{"1", "2", "3"}, Values1: []string{"4", "5", "6"}},
 			}
-			backup.PrintStatisticsStatementsForTable(backupfile, toc, tableTestTable, attStats, tupleStats)
+			testBackup.PrintStatisticsStatementsForTable(backupfile, toc, tableTestTable, attStats, tupleStats)
 			testutils.ExpectEntry(toc.StatisticsEntries, 0, "testschema", "", "testtable", "STATISTICS")
 			testutils.AssertBufferContents(toc.StatisticsEntries, buffer, `UPDATE pg_class
 SET
 	relpages = 0::int,
 	reltuples = 0.000000::real
-WHERE relname = 'testtable'
-AND relnamespace = 0;
+WHERE oid = 'testschema.testtable'::regclass::oid;
 
 
 DELETE FROM pg_statistic WHERE starelid = 'testschema.testtable'::regclass::oid AND staattnum = 0;
@@ -107,6 +105,22 @@ INSERT INTO pg_statistic VALUES (
 );`)
 		})
 	})
+	Describe("PrintExtendedStatisticsAnalyzeStatements", func() {
+		BeforeEach(func() {
+			toc, backupfile = testutils.InitializeTestTOC(buffer, "statistics")
+		})
+		It("prints one ANALYZE statement per table with extended statistics objects", func() {
+			statistics := []backup.ExtendedStatistic{
+				{Oid: 1, Schema: "public", Name: "stats1", OwningSchema: "testschema", OwningTable: "testtable"},
+				{Oid: 2, Schema: "public", Name: "stats2", OwningSchema: "testschema", OwningTable: "testtable"},
+				{Oid: 3, Schema: "public", Name: "stats3", OwningSchema: "testschema", OwningTable: "othertable"},
+			}
+			backup.PrintExtendedStatisticsAnalyzeStatements(backupfile, toc, statistics)
+			testutils.ExpectEntry(toc.StatisticsEntries, 0, "testschema", "testschema.testtable", "testtable", "EXTENDED STATISTICS")
+			testutils.ExpectEntry(toc.StatisticsEntries, 1, "testschema", "testschema.othertable", "othertable", "EXTENDED STATISTICS")
+			testutils.AssertBufferContents(toc.StatisticsEntries, buffer, "ANALYZE testschema.testtable;", "ANALYZE testschema.othertable;")
+		})
+	})
 	Describe("GenerateTupleStatisticsQuery", func() {
 		It("generates tuple statistics query with a single quote in the table name", func() {
 			tableTestTable := backup.Table{Relation: backup.Relation{Schema: "testschema", Name: `"test'table"`}}
@@ -116,8 +130,7 @@ INSERT INTO pg_statistic VALUES (
 SET
 	relpages = 0::int,
 	reltuples = 0.000000::real
-WHERE relname = '"test''table"'
-AND relnamespace = 0;`))
+WHERE oid = 'testschema."test''table"'::regclass::oid;`))
 		})
 
 	})
@@ -130,7 +143,7 @@ AND relnamespace = 0;`))
 			It("generates attribute statistics query for array type for GPDB master", func() {
 				testhelper.SetDBVersion(connectionPool, "6.0.0")
 				attStats.Type = "_array"
-				attStatsQuery := backup.GenerateAttributeStatisticsQuery(tableTestTable, attStats)
+				attStatsQuery := testBackup.GenerateAttributeStatisticsQuery(tableTestTable, attStats)
 				Expect(attStatsQuery).To(Equal(`DELETE FROM pg_statistic WHERE starelid = 'testschema."test''table"'::regclass::oid AND staattnum = 3;
 
 INSERT INTO pg_statistic VALUES (
@@ -165,7 +178,7 @@ INSERT INTO pg_statistic VALUES (
 			It("generates attribute statistics query for non-array type for GPDB master", func() {
 				testhelper.SetDBVersion(connectionPool, "6.0.0")
 				attStats.Type = "testtype"
-				attStatsQuery := backup.GenerateAttributeStatisticsQuery(tableTestTable, attStats)
+				attStatsQuery := testBackup.GenerateAttributeStatisticsQuery(tableTestTable, attStats)
 				Expect(attStatsQuery).To(Equal(`DELETE FROM pg_statistic WHERE starelid = 'testschema."test''table"'::regclass::oid AND staattnum = 3;
 
 INSERT INTO pg_statistic VALUES (
@@ -206,7 +219,7 @@ INSERT INTO pg_statistic VALUES (
 			It("generates attribute statistics query for array type for GPDB4/5", func() {
 				testhelper.SetDBVersion(connectionPool, "5.1.0")
 				attStats.Type = "_array"
-				attStatsQuery := backup.GenerateAttributeStatisticsQuery(tableTestTable, attStats)
+				attStatsQuery := testBackup.GenerateAttributeStatisticsQuery(tableTestTable, attStats)
 				Expect(attStatsQuery).To(Equal(`DELETE FROM pg_statistic WHERE starelid = 'testschema."test''table"'::regclass::oid AND staattnum = 3;
 
 INSERT INTO pg_statistic VALUES (
@@ -236,7 +249,7 @@ INSERT INTO pg_statistic VALUES (
 			It("generates attribute statistics query for non-array type for GPDB4/5", func() {
 				testhelper.SetDBVersion(connectionPool, "5.1.0")
 				attStats.Type = "testtype"
-				attStatsQuery := backup.GenerateAttributeStatisticsQuery(tableTestTable, attStats)
+				attStatsQuery := testBackup.GenerateAttributeStatisticsQuery(tableTestTable, attStats)
 				Expect(attStatsQuery).To(Equal(`DELETE FROM pg_statistic WHERE starelid = 'testschema."test''table"'::regclass::oid AND staattnum = 3;
 
 INSERT INTO pg_statistic VALUES (
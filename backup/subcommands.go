@@ -0,0 +1,84 @@
+package backup
+
+/*
+ * This file contains the subcommands that give gpbackup's operations their
+ * own verbs, so that "gpbackup backup" and "gpbackup restore" can be used
+ * alongside the subcommands that manage existing backups.
+ */
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+/*
+ * Running gpbackup with no subcommand still takes a backup, so this command
+ * only gives that default an explicit name.
+ */
+func NewBackupCommand() *cobra.Command {
+	backupCmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Back up a database, as gpbackup does when run without a subcommand",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			os.Exit(RunBackupCommand(cmd))
+		}}
+	SetFlagDefaults(backupCmd.Flags())
+	return backupCmd
+}
+
+/*
+ * gprestore keeps its own flags and global state, so rather than linking it
+ * into gpbackup, this command runs the gprestore installed alongside gpbackup
+ * with the arguments it was given.
+ */
+func NewRestoreCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:                "restore [gprestore flags]",
+		Short:              "Restore a backup, by running gprestore with the given flags",
+		DisableFlagParsing: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			os.Exit(RunGprestore(args))
+		}}
+}
+
+func RunGprestore(args []string) int {
+	executable, err := os.Executable()
+	gplog.FatalOnError(err)
+	gprestorePath := filepath.Join(filepath.Dir(executable), "gprestore")
+	command := exec.Command(gprestorePath, args...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	err = command.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode()
+	}
+	gplog.FatalOnError(err)
+	return 0
+}
+
+func NewCompletionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:       "completion <bash|zsh>",
+		Short:     "Print a bash or zsh completion script for gpbackup",
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"bash", "zsh"},
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			switch args[0] {
+			case "bash":
+				err = cmd.Root().GenBashCompletion(os.Stdout)
+			case "zsh":
+				err = cmd.Root().GenZshCompletion(os.Stdout)
+			default:
+				err = errors.Errorf("Unsupported shell %s.  Completion scripts can be generated for bash and zsh.", args[0])
+			}
+			gplog.FatalOnError(err)
+		}}
+}
@@ -0,0 +1,32 @@
+package backup_test
+
+import (
+	"github.com/greenplum-db/gpbackup/backup"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/spf13/pflag"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backup/subcommands tests", func() {
+	Describe("NewBackupCommand", func() {
+		It("accepts the same flags as gpbackup without a subcommand", func() {
+			backupCmd := backup.NewBackupCommand()
+			defaultFlags := backup.NewBackupFlagSet()
+
+			numFlags := 0
+			defaultFlags.VisitAll(func(flag *pflag.Flag) {
+				numFlags++
+				Expect(backupCmd.Flags().Lookup(flag.Name)).ToNot(BeNil())
+			})
+			Expect(numFlags).To(BeNumerically(">", 0))
+			Expect(utils.MustGetFlagInt(backupCmd.Flags(), utils.JOBS)).To(Equal(1))
+		})
+	})
+	Describe("NewRestoreCommand", func() {
+		It("passes its flags through to gprestore unparsed", func() {
+			Expect(backup.NewRestoreCommand().DisableFlagParsing).To(BeTrue())
+		})
+	})
+})
@@ -2,6 +2,7 @@ package backup
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/greenplum-db/gp-common-go-libs/dbconn"
 	"github.com/greenplum-db/gp-common-go-libs/gplog"
@@ -17,11 +18,13 @@ import (
  * This file contains functions related to validating user input.
  */
 
-func validateFilterLists() {
+func (b *Backup) validateFilterLists() {
 	gplog.Verbose("Validating filters")
-	ValidateFilterSchemas(connectionPool, MustGetFlagStringSlice(utils.INCLUDE_SCHEMA), false)
-	ValidateFilterSchemas(connectionPool, MustGetFlagStringSlice(utils.EXCLUDE_SCHEMA), true)
-	ValidateFilterTables(connectionPool, MustGetFlagStringSlice(utils.EXCLUDE_RELATION), true)
+	ValidateFilterSchemas(b.connectionPool, b.MustGetFlagStringSlice(utils.INCLUDE_SCHEMA), false)
+	ValidateFilterSchemas(b.connectionPool, b.MustGetFlagStringSlice(utils.EXCLUDE_SCHEMA), true)
+	b.ValidateFilterTables(b.connectionPool, b.MustGetFlagStringSlice(utils.EXCLUDE_RELATION), true)
+	b.ValidateLeafPartitionFilter(b.connectionPool, b.MustGetFlagStringArray(utils.EXCLUDE_LEAF_PARTITION), utils.EXCLUDE_LEAF_PARTITION)
+	b.ValidateLeafPartitionFilter(b.connectionPool, b.MustGetFlagStringArray(utils.INCLUDE_LEAF_PARTITION), utils.INCLUDE_LEAF_PARTITION)
 }
 
 func ValidateFilterSchemas(connectionPool *dbconn.DBConn, schemaList []string, excludeSet bool) {
@@ -33,32 +36,64 @@ func ValidateFilterSchemas(connectionPool *dbconn.DBConn, schemaList []string, e
 	resultSchemas := dbconn.MustSelectStringSlice(connectionPool, query)
 	if len(resultSchemas) < len(schemaList) {
 		schemaSet := utils.NewSet(resultSchemas)
+		missingSchemas := make([]string, 0)
 		for _, schema := range schemaList {
 			if !schemaSet.MatchesFilter(schema) {
 				if excludeSet {
 					gplog.Warn(`Excluded schema %s does not exist`, schema)
 				} else {
-					gplog.Fatal(nil, "Schema %s does not exist", schema)
+					missingSchemas = append(missingSchemas, schema)
 				}
 			}
 		}
+		if len(missingSchemas) == 1 {
+			gplog.Fatal(nil, "Schema %s does not exist", missingSchemas[0])
+		} else if len(missingSchemas) > 1 {
+			gplog.Fatal(nil, "Schemas %s do not exist", strings.Join(missingSchemas, ", "))
+		}
 	}
 }
 
-func ValidateFilterTables(conn *dbconn.DBConn, tableList []string, excludeSet bool) {
+func (b *Backup) ValidateFilterTables(conn *dbconn.DBConn, tableList []string, excludeSet bool) {
 	if len(tableList) == 0 {
 		return
 	}
 	utils.ValidateFQNs(tableList)
-	DBValidate(conn, tableList, excludeSet)
+	b.DBValidate(conn, tableList, excludeSet)
 }
 
-func DBValidate(conn *dbconn.DBConn, tableList []string, excludeSet bool) {
+func (b *Backup) DBValidate(conn *dbconn.DBConn, tableList []string, excludeSet bool) {
 	if len(tableList) == 0 {
 		return
 	}
 	gplog.Verbose("Validating tables")
-	quotedIncludeRelations, err := options.QuoteTableNames(connectionPool, tableList)
+	tableMap := b.getTableOidMap(conn, tableList)
+
+	partTableMap := b.GetPartitionTableMap(conn)
+	missingTables := make([]string, 0)
+	for _, table := range tableList {
+		tableOid := tableMap[table]
+		if tableOid == 0 {
+			if excludeSet {
+				gplog.Warn("Excluded table %s does not exist", table)
+			} else {
+				missingTables = append(missingTables, table)
+			}
+			continue
+		}
+		if partTableMap[tableOid].Level == "i" {
+			gplog.Fatal(nil, "Cannot filter on %s, as it is an intermediate partition table.  Only parent partition tables and leaf partition tables may be specified.", table)
+		}
+	}
+	if len(missingTables) == 1 {
+		gplog.Fatal(nil, "Table %s does not exist", missingTables[0])
+	} else if len(missingTables) > 1 {
+		gplog.Fatal(nil, "Tables %s do not exist", strings.Join(missingTables, ", "))
+	}
+}
+
+func (b *Backup) getTableOidMap(conn *dbconn.DBConn, tableList []string) map[string]uint32 {
+	quotedIncludeRelations, err := options.QuoteTableNames(b.connectionPool, tableList)
 	gplog.FatalOnError(err)
 	// todo perhaps store quoted list in options??
 
@@ -80,51 +115,164 @@ func DBValidate(conn *dbconn.DBConn, tableList []string, excludeSet bool) {
 	for _, table := range resultTables {
 		tableMap[table.Name] = table.Oid
 	}
+	return tableMap
+}
 
-	partTableMap := GetPartitionTableMap(conn)
+func (b *Backup) ValidateLeafPartitionFilter(conn *dbconn.DBConn, tableList []string, flagName string) {
+	if len(tableList) == 0 {
+		return
+	}
+	utils.ValidateFQNs(tableList)
+	tableMap := b.getTableOidMap(conn, tableList)
+	partTableMap := b.GetPartitionTableMap(conn)
 	for _, table := range tableList {
 		tableOid := tableMap[table]
 		if tableOid == 0 {
-			if excludeSet {
-				gplog.Warn("Excluded table %s does not exist", table)
-			} else {
-				gplog.Fatal(nil, "Table %s does not exist", table)
-			}
+			gplog.Fatal(nil, "Table %s does not exist", table)
 		}
-		if partTableMap[tableOid].Level == "i" {
-			gplog.Fatal(nil, "Cannot filter on %s, as it is an intermediate partition table.  Only parent partition tables and leaf partition tables may be specified.", table)
+		if partTableMap[tableOid].Level != "l" {
+			gplog.Fatal(nil, "Cannot specify %s with --%s, as it is not a leaf partition table.", table, flagName)
 		}
 	}
 }
 
-func ValidateFlagCombinations(flags *pflag.FlagSet) {
+func (b *Backup) ValidateFlagCombinations(flags *pflag.FlagSet) {
 	utils.CheckExclusiveFlags(flags, utils.DEBUG, utils.QUIET, utils.VERBOSE)
 	utils.CheckExclusiveFlags(flags, utils.DATA_ONLY, utils.METADATA_ONLY, utils.INCREMENTAL)
-	utils.CheckExclusiveFlags(flags, utils.INCLUDE_SCHEMA, utils.INCLUDE_RELATION, utils.INCLUDE_RELATION_FILE)
-	utils.CheckExclusiveFlags(flags, utils.EXCLUDE_SCHEMA, utils.INCLUDE_SCHEMA)
-	utils.CheckExclusiveFlags(flags, utils.EXCLUDE_SCHEMA, utils.EXCLUDE_RELATION, utils.INCLUDE_RELATION, utils.EXCLUDE_RELATION_FILE, utils.INCLUDE_RELATION_FILE)
+	utils.CheckExclusiveFlags(flags, utils.INCLUDE_SCHEMA, utils.INCLUDE_SCHEMA_FILE, utils.INCLUDE_RELATION, utils.INCLUDE_RELATION_FILE)
+	utils.CheckExclusiveFlags(flags, utils.EXCLUDE_SCHEMA, utils.EXCLUDE_SCHEMA_FILE, utils.INCLUDE_SCHEMA, utils.INCLUDE_SCHEMA_FILE)
+	utils.CheckExclusiveFlags(flags, utils.EXCLUDE_SCHEMA, utils.EXCLUDE_SCHEMA_FILE, utils.EXCLUDE_RELATION, utils.INCLUDE_RELATION, utils.EXCLUDE_RELATION_FILE, utils.INCLUDE_RELATION_FILE)
+	utils.CheckExclusiveFlags(flags, utils.EXCLUDE_MONITORING_SCHEMAS, utils.INCLUDE_SCHEMA, utils.INCLUDE_SCHEMA_FILE)
+	utils.CheckExclusiveFlags(flags, utils.EXCLUDE_MONITORING_SCHEMAS, utils.EXCLUDE_RELATION, utils.INCLUDE_RELATION, utils.EXCLUDE_RELATION_FILE, utils.INCLUDE_RELATION_FILE)
 	utils.CheckExclusiveFlags(flags, utils.EXCLUDE_RELATION, utils.EXCLUDE_RELATION_FILE, utils.LEAF_PARTITION_DATA)
 	utils.CheckExclusiveFlags(flags, utils.JOBS, utils.METADATA_ONLY, utils.SINGLE_DATA_FILE)
 	utils.CheckExclusiveFlags(flags, utils.METADATA_ONLY, utils.LEAF_PARTITION_DATA)
+	utils.CheckExclusiveFlags(flags, utils.DATA_ONLY, utils.SPLIT_METADATA)
+	utils.CheckExclusiveFlags(flags, utils.METADATA_ENCRYPTION_KEY_FILE, utils.SPLIT_METADATA, utils.ALL_DATABASES)
 	utils.CheckExclusiveFlags(flags, utils.NO_COMPRESSION, utils.COMPRESSION_LEVEL)
+	utils.CheckExclusiveFlags(flags, utils.NO_COMPRESSION, utils.COMPRESS_METADATA)
+	utils.CheckExclusiveFlags(flags, utils.NO_COMPRESSION, utils.ADAPTIVE_COMPRESSION)
 	utils.CheckExclusiveFlags(flags, utils.PLUGIN_CONFIG, utils.BACKUP_DIR)
-	if MustGetFlagString(utils.FROM_TIMESTAMP) != "" && !MustGetFlagBool(utils.INCREMENTAL) {
+	utils.CheckExclusiveFlags(flags, utils.LINK_UNCHANGED_DATA, utils.INCREMENTAL, utils.METADATA_ONLY, utils.DATA_ONLY, utils.SINGLE_DATA_FILE, utils.PLUGIN_CONFIG)
+	utils.CheckExclusiveFlags(flags, utils.COPY_RETRIES, utils.METADATA_ONLY, utils.SINGLE_DATA_FILE)
+	utils.CheckExclusiveFlags(flags, utils.WITH_LARGE_OBJECTS, utils.METADATA_ONLY)
+	utils.CheckExclusiveFlags(flags, utils.WITH_EXTERNAL_DATA, utils.METADATA_ONLY)
+	utils.CheckExclusiveFlags(flags, utils.DATA_FILTER_FILE, utils.METADATA_ONLY, utils.INCREMENTAL, utils.LINK_UNCHANGED_DATA)
+	utils.CheckExclusiveFlags(flags, utils.SAMPLE_PERCENT, utils.METADATA_ONLY, utils.INCREMENTAL, utils.LINK_UNCHANGED_DATA)
+	utils.CheckExclusiveFlags(flags, utils.MASKING_RULES_FILE, utils.METADATA_ONLY, utils.INCREMENTAL, utils.LINK_UNCHANGED_DATA)
+	utils.CheckExclusiveFlags(flags, utils.PARQUET_EXPORT, utils.METADATA_ONLY, utils.INCREMENTAL, utils.LINK_UNCHANGED_DATA, utils.SINGLE_DATA_FILE, utils.PLUGIN_CONFIG, utils.ADAPTIVE_COMPRESSION, utils.MASKING_RULES_FILE)
+	utils.CheckExclusiveFlags(flags, utils.PARQUET_EXPORT, utils.COPY_FORMAT, utils.COPY_DELIMITER, utils.COPY_HEADER, utils.COPY_NULL_STRING)
+	utils.CheckExclusiveFlags(flags, utils.ARCHIVE_FILE, utils.PLUGIN_CONFIG, utils.DRY_RUN)
+	utils.CheckExclusiveFlags(flags, utils.ARCHIVE_FILE, utils.SINGLE_DATA_FILE, utils.PARQUET_EXPORT, utils.INCREMENTAL, utils.LINK_UNCHANGED_DATA, utils.COPY_RETRIES, utils.VERIFY_DATA_SAMPLE)
+	utils.CheckExclusiveFlags(flags, utils.VERIFY_DATA_SAMPLE, utils.METADATA_ONLY, utils.SINGLE_DATA_FILE, utils.PLUGIN_CONFIG, utils.PARQUET_EXPORT)
+	utils.CheckExclusiveFlags(flags, utils.EXCLUDE_COLUMN, utils.INCREMENTAL, utils.LINK_UNCHANGED_DATA, utils.LEAF_PARTITION_DATA)
+	utils.CheckExclusiveFlags(flags, utils.EXCLUDE_LEAF_PARTITION, utils.INCLUDE_LEAF_PARTITION)
+	if b.MustGetFlagString(utils.FROM_TIMESTAMP) != "" && !b.MustGetFlagBool(utils.INCREMENTAL) {
 		gplog.Fatal(errors.Errorf("--from-timestamp must be specified with --incremental"), "")
 	}
-	if MustGetFlagBool(utils.INCREMENTAL) && !MustGetFlagBool(utils.LEAF_PARTITION_DATA) {
+	if b.MustGetFlagBool(utils.INCREMENTAL) && !b.MustGetFlagBool(utils.LEAF_PARTITION_DATA) {
 		gplog.Fatal(errors.Errorf("--leaf-partition-data must be specified with --incremental"), "")
 	}
+	for _, leafPartitionFlag := range []string{utils.EXCLUDE_LEAF_PARTITION, utils.INCLUDE_LEAF_PARTITION, utils.LEAF_PARTITION_KEY_START, utils.LEAF_PARTITION_KEY_END} {
+		if flags.Changed(leafPartitionFlag) && !b.MustGetFlagBool(utils.LEAF_PARTITION_DATA) {
+			gplog.Fatal(errors.Errorf("--leaf-partition-data must be specified with --%s", leafPartitionFlag), "")
+		}
+	}
+	if b.MustGetFlagBool(utils.EXCLUDE_COLUMN_DDL) && len(b.MustGetFlagStringArray(utils.EXCLUDE_COLUMN)) == 0 {
+		gplog.Fatal(errors.Errorf("--exclude-column must be specified with --exclude-column-ddl"), "")
+	}
+	if b.MustGetFlagBool(utils.LINK_UNCHANGED_DATA) && !b.MustGetFlagBool(utils.LEAF_PARTITION_DATA) {
+		gplog.Fatal(errors.Errorf("--leaf-partition-data must be specified with --link-unchanged-data"), "")
+	}
+	if flags.Changed(utils.COPY_BUFFER_SIZE) && !b.MustGetFlagBool(utils.SINGLE_DATA_FILE) {
+		gplog.Fatal(errors.Errorf("--single-data-file must be specified with --copy-buffer-size"), "")
+	}
+	if b.MustGetFlagBool(utils.ADAPTIVE_COMPRESSION) && !b.MustGetFlagBool(utils.SINGLE_DATA_FILE) {
+		gplog.Fatal(errors.Errorf("--single-data-file must be specified with --adaptive-compression"), "")
+	}
+	if b.MustGetFlagString(utils.PROGRESS_FILE) != "" && b.MustGetFlagString(utils.PROGRESS_FORMAT) != utils.PROGRESS_FORMAT_JSON {
+		gplog.Fatal(errors.Errorf("--progress-format json must be specified with --progress-file"), "")
+	}
+	if b.MustGetFlagString(utils.ARCHIVE_FILE) == utils.ARCHIVE_TO_STDOUT && b.MustGetFlagString(utils.PROGRESS_FORMAT) == utils.PROGRESS_FORMAT_JSON && b.MustGetFlagString(utils.PROGRESS_FILE) == "" {
+		gplog.Fatal(errors.Errorf("--progress-file must be specified with --progress-format json when the archive is written to stdout"), "")
+	}
 }
 
-func ValidateFlagValues() {
-	err := utils.ValidateFullPath(MustGetFlagString(utils.BACKUP_DIR))
+/*
+ * Table data is written by the segments, so a utility mode backup can only
+ * contain metadata.
+ */
+func (b *Backup) ValidateUtilityModeFlags(flags *pflag.FlagSet) {
+	if !b.MustGetFlagBool(utils.UTILITY_MODE) {
+		return
+	}
+	utils.CheckExclusiveFlags(flags, utils.UTILITY_MODE, utils.DATA_ONLY, utils.INCREMENTAL, utils.SINGLE_DATA_FILE, utils.PLUGIN_CONFIG)
+	if !b.MustGetFlagBool(utils.METADATA_ONLY) {
+		gplog.Warn("Table data cannot be backed up in utility mode, as it must be dispatched to the segments; only metadata will be backed up")
+		err := flags.Set(utils.METADATA_ONLY, "true")
+		gplog.FatalOnError(err)
+	}
+}
+
+func (b *Backup) ValidateFlagValues() {
+	err := utils.ValidateFullPath(b.MustGetFlagString(utils.BACKUP_DIR))
+	gplog.FatalOnError(err)
+	err = utils.ValidateFullPath(b.MustGetFlagString(utils.PLUGIN_CONFIG))
+	gplog.FatalOnError(err)
+	err = utils.ValidateFullPath(b.MustGetFlagString(utils.DATA_FILTER_FILE))
+	gplog.FatalOnError(err)
+	err = utils.ValidateFullPath(b.MustGetFlagString(utils.MASKING_RULES_FILE))
+	gplog.FatalOnError(err)
+	if archiveFile := b.MustGetFlagString(utils.ARCHIVE_FILE); archiveFile != utils.ARCHIVE_TO_STDOUT {
+		err = utils.ValidateFullPath(archiveFile)
+		gplog.FatalOnError(err)
+	}
+	if samplePercent := b.MustGetFlagInt(utils.SAMPLE_PERCENT); samplePercent < 0 || samplePercent > 100 {
+		gplog.Fatal(errors.Errorf("--sample-percent must be between 0 and 100"), "")
+	}
+	ValidateCompressionLevel(b.MustGetFlagInt(utils.COMPRESSION_LEVEL))
+	if b.MustGetFlagInt(utils.COPY_BUFFER_SIZE) < 0 {
+		gplog.Fatal(errors.Errorf("--copy-buffer-size must not be negative"), "")
+	}
+	if b.MustGetFlagInt(utils.SMALL_TABLE_BATCH_SIZE) < 1 {
+		gplog.Fatal(errors.Errorf("--small-table-batch-size must be at least 1"), "")
+	}
+	if b.MustGetFlagInt(utils.COPY_RETRIES) < 0 {
+		gplog.Fatal(errors.Errorf("--copy-retries must not be negative"), "")
+	}
+	if b.MustGetFlagInt(utils.LARGE_ROW_THRESHOLD) < 0 {
+		gplog.Fatal(errors.Errorf("--large-row-threshold must not be negative"), "")
+	}
+	if b.MustGetFlagInt(utils.VERIFY_DATA_SAMPLE) < 0 {
+		gplog.Fatal(errors.Errorf("--verify-data-sample must not be negative"), "")
+	}
+	if b.MustGetFlagInt(utils.QUERY_TIMEOUT) < 0 {
+		gplog.Fatal(errors.Errorf("--query-timeout must not be negative"), "")
+	}
+	if format := b.MustGetFlagString(utils.PROGRESS_FORMAT); format != utils.PROGRESS_FORMAT_TEXT && format != utils.PROGRESS_FORMAT_JSON {
+		gplog.Fatal(errors.Errorf("--progress-format must be one of %s or %s", utils.PROGRESS_FORMAT_TEXT, utils.PROGRESS_FORMAT_JSON), "")
+	}
+	if target := b.MustGetFlagString(utils.TARGET); target != TARGET_GREENPLUM && target != TARGET_POSTGRES {
+		gplog.Fatal(errors.Errorf("--target must be one of %s or %s", TARGET_GREENPLUM, TARGET_POSTGRES), "")
+	}
+	if splitBy := b.MustGetFlagString(utils.SPLIT_METADATA); splitBy != "" && splitBy != utils.SPLIT_BY_OBJECT_TYPE && splitBy != utils.SPLIT_BY_SCHEMA {
+		gplog.Fatal(errors.Errorf("--split-metadata must be one of %s or %s", utils.SPLIT_BY_OBJECT_TYPE, utils.SPLIT_BY_SCHEMA), "")
+	}
+	err = b.GetCopyFormatFromFlags().Validate()
+	gplog.FatalOnError(err)
+	if b.MustGetFlagString(utils.COPY_FORMAT) == utils.COPY_FORMAT_BINARY && b.MustGetFlagString(utils.MASKING_RULES_FILE) != "" {
+		gplog.Fatal(errors.Errorf("--masking-rules-file cannot be used with --copy-format binary, because masked values may not have the binary representation of their columns' types"), "")
+	}
+	if b.MustGetFlagString(utils.COPY_FORMAT) == utils.COPY_FORMAT_BINARY && b.MustGetFlagInt(utils.VERIFY_DATA_SAMPLE) > 0 {
+		gplog.Fatal(errors.Errorf("--verify-data-sample cannot be used with --copy-format binary, because binary data files cannot be split into rows without their columns' types"), "")
+	}
+	_, err = utils.ParseConnectionOptions(b.MustGetFlagString(utils.CONNECTION_OPTIONS))
 	gplog.FatalOnError(err)
-	err = utils.ValidateFullPath(MustGetFlagString(utils.PLUGIN_CONFIG))
+	_, err = utils.ParseBackupGUCs(b.MustGetFlagStringArray(utils.BACKUP_GUC))
 	gplog.FatalOnError(err)
-	ValidateCompressionLevel(MustGetFlagInt(utils.COMPRESSION_LEVEL))
-	if MustGetFlagString(utils.FROM_TIMESTAMP) != "" && !backup_filepath.IsValidTimestamp(MustGetFlagString(utils.FROM_TIMESTAMP)) {
+	if b.MustGetFlagString(utils.FROM_TIMESTAMP) != "" && !backup_filepath.IsValidTimestamp(b.MustGetFlagString(utils.FROM_TIMESTAMP)) {
 		gplog.Fatal(errors.Errorf("Timestamp %s is invalid.  Timestamps must be in the format YYYYMMDDHHMMSS.",
-			MustGetFlagString(utils.FROM_TIMESTAMP)), "")
+			b.MustGetFlagString(utils.FROM_TIMESTAMP)), "")
 	}
 }
 
@@ -134,16 +282,16 @@ func ValidateCompressionLevel(compressionLevel int) {
 	}
 }
 
-func ValidateFromTimestamp(fromTimestamp string) {
-	fromTimestampFPInfo := backup_filepath.NewFilePathInfo(globalCluster, globalFPInfo.UserSpecifiedBackupDir,
-		fromTimestamp, globalFPInfo.UserSpecifiedSegPrefix)
-	if MustGetFlagString(utils.PLUGIN_CONFIG) != "" {
+func (b *Backup) ValidateFromTimestamp(fromTimestamp string) {
+	fromTimestampFPInfo := backup_filepath.NewFilePathInfo(b.globalCluster, b.globalFPInfo.UserSpecifiedBackupDir,
+		fromTimestamp, b.globalFPInfo.UserSpecifiedSegPrefix)
+	if b.MustGetFlagString(utils.PLUGIN_CONFIG) != "" {
 		// The config file needs to be downloaded from the remote system into the local filesystem
-		pluginConfig.MustRestoreFile(fromTimestampFPInfo.GetConfigFilePath())
+		b.pluginConfig.MustRestoreFile(fromTimestampFPInfo.GetConfigFilePath())
 	}
 	fromBackupConfig := backup_history.ReadConfigFile(fromTimestampFPInfo.GetConfigFilePath())
 
-	if !MatchesIncrementalFlags(fromBackupConfig, &backupReport.BackupConfig) {
+	if !b.MatchesIncrementalFlags(fromBackupConfig, &b.backupReport.BackupConfig) {
 		gplog.Fatal(errors.Errorf("The flags of the backup with timestamp = %s does not match "+
 			"that of the current one. Please refer to the report to view the flags supplied for the"+
 			"previous backup.", fromTimestampFPInfo.Timestamp), "")
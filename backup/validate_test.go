@@ -7,6 +7,7 @@ import (
 	"github.com/greenplum-db/gpbackup/utils"
 
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
 )
 
 var _ = Describe("backup/validate tests", func() {
@@ -44,7 +45,7 @@ var _ = Describe("backup/validate tests", func() {
 			twoSchemaRows := sqlmock.NewRows([]string{"string"})
 			mock.ExpectQuery("SELECT (.*)").WillReturnRows(twoSchemaRows)
 			filterList = []string{"schema2", "schema3"}
-			defer testhelper.ShouldPanicWithMessage("Schema schema2 does not exist")
+			defer testhelper.ShouldPanicWithMessage("Schemas schema2, schema3 do not exist")
 			backup.ValidateFilterSchemas(connectionPool, filterList, false)
 		})
 		It("does not panic if schema is not present in database and noFatal is true", func() {
@@ -57,6 +58,39 @@ var _ = Describe("backup/validate tests", func() {
 			testhelper.ExpectRegexp(logfile, "[WARNING]:-Excluded schema schema2 does not exist")
 		})
 	})
+	Describe("ValidateLeafPartitionFilter", func() {
+		var tableRows, partitionTables, schemaAndTable *sqlmock.Rows
+		BeforeEach(func() {
+			tableRows = sqlmock.NewRows([]string{"oid", "name"})
+			schemaAndTable = sqlmock.NewRows([]string{"schemaname", "tablename"})
+			partitionTables = sqlmock.NewRows([]string{"oid", "level", "rootname"})
+		})
+		It("passes if given a leaf partition table", func() {
+			// Added to handle call to `quote_ident`
+			schemaAndTable.AddRow("public", "table1")
+			mock.ExpectQuery("SELECT (.*)").WillReturnRows(schemaAndTable)
+			//
+			tableRows.AddRow("1", "public.table1")
+			mock.ExpectQuery("SELECT (.*)").WillReturnRows(tableRows)
+			partitionTables.AddRow("1", "l", "root")
+			mock.ExpectQuery("SELECT (.*)").WillReturnRows(partitionTables)
+			filterList = []string{"public.table1"}
+			testBackup.ValidateLeafPartitionFilter(connectionPool, filterList, "exclude-leaf-partition")
+		})
+		It("panics if given a table that is not a leaf partition", func() {
+			// Added to handle call to `quote_ident`
+			schemaAndTable.AddRow("public", "table1")
+			mock.ExpectQuery("SELECT (.*)").WillReturnRows(schemaAndTable)
+			//
+			tableRows.AddRow("1", "public.table1")
+			mock.ExpectQuery("SELECT (.*)").WillReturnRows(tableRows)
+			partitionTables.AddRow("1", "p", "")
+			mock.ExpectQuery("SELECT (.*)").WillReturnRows(partitionTables)
+			filterList = []string{"public.table1"}
+			defer testhelper.ShouldPanicWithMessage("Cannot specify public.table1 with --exclude-leaf-partition, as it is not a leaf partition table.")
+			testBackup.ValidateLeafPartitionFilter(connectionPool, filterList, "exclude-leaf-partition")
+		})
+	})
 	Describe("ValidateFilterTables", func() {
 		var tableRows, partitionTables, schemaAndTable, schemaAndTable2 *sqlmock.Rows
 		BeforeEach(func() {
@@ -67,7 +101,7 @@ var _ = Describe("backup/validate tests", func() {
 		})
 		Context("Non-partition tables", func() {
 			It("passes if there are no filter tables", func() {
-				backup.ValidateFilterTables(connectionPool, filterList, false)
+				testBackup.ValidateFilterTables(connectionPool, filterList, false)
 			})
 			It("passes if single table is present in database", func() {
 				// Added to handle call to `quote_ident`
@@ -78,7 +112,7 @@ var _ = Describe("backup/validate tests", func() {
 				mock.ExpectQuery("SELECT (.*)").WillReturnRows(tableRows)
 				mock.ExpectQuery("SELECT (.*)").WillReturnRows(partitionTables)
 				filterList = []string{"public.table1"}
-				backup.ValidateFilterTables(connectionPool, filterList, false)
+				testBackup.ValidateFilterTables(connectionPool, filterList, false)
 			})
 			It("passes if multiple tables are present in database", func() {
 				// Added to handle call to `quote_ident`
@@ -91,7 +125,7 @@ var _ = Describe("backup/validate tests", func() {
 				mock.ExpectQuery("SELECT (.*)").WillReturnRows(tableRows)
 				mock.ExpectQuery("SELECT (.*)").WillReturnRows(partitionTables)
 				filterList = []string{"public.table1", "public.table2"}
-				backup.ValidateFilterTables(connectionPool, filterList, false)
+				testBackup.ValidateFilterTables(connectionPool, filterList, false)
 			})
 			It("panics if table is not present in database", func() {
 				// Added to handle call to `quote_ident`
@@ -105,7 +139,20 @@ var _ = Describe("backup/validate tests", func() {
 				mock.ExpectQuery("SELECT (.*)").WillReturnRows(partitionTables)
 				filterList = []string{"public.table1", "public.table2"}
 				defer testhelper.ShouldPanicWithMessage("Table public.table2 does not exist")
-				backup.ValidateFilterTables(connectionPool, filterList, false)
+				testBackup.ValidateFilterTables(connectionPool, filterList, false)
+			})
+			It("panics listing every table that is not present in database", func() {
+				// Added to handle call to `quote_ident`
+				schemaAndTable.AddRow("public", "table1")
+				schemaAndTable2.AddRow("public", "table2")
+				mock.ExpectQuery("SELECT (.*)").WillReturnRows(schemaAndTable)
+				mock.ExpectQuery("SELECT (.*)").WillReturnRows(schemaAndTable2)
+				//
+				mock.ExpectQuery("SELECT (.*)").WillReturnRows(tableRows)
+				mock.ExpectQuery("SELECT (.*)").WillReturnRows(partitionTables)
+				filterList = []string{"public.table1", "public.table2"}
+				defer testhelper.ShouldPanicWithMessage("Tables public.table1, public.table2 do not exist")
+				testBackup.ValidateFilterTables(connectionPool, filterList, false)
 			})
 			It("does not panic if table is not present in database and noFatal is true", func() {
 				// Added to handle call to `quote_ident`
@@ -119,7 +166,7 @@ var _ = Describe("backup/validate tests", func() {
 				mock.ExpectQuery("SELECT (.*)").WillReturnRows(tableRows)
 				mock.ExpectQuery("SELECT (.*)").WillReturnRows(partitionTables)
 				filterList = []string{"public.table1", "public.table2"}
-				backup.ValidateFilterTables(connectionPool, filterList, true)
+				testBackup.ValidateFilterTables(connectionPool, filterList, true)
 				testhelper.ExpectRegexp(logfile, "[WARNING]:-Excluded table public.table2 does not exist")
 			})
 		})
@@ -134,7 +181,7 @@ var _ = Describe("backup/validate tests", func() {
 				partitionTables.AddRow("1", "p", "")
 				mock.ExpectQuery("SELECT (.*)").WillReturnRows(partitionTables)
 				filterList = []string{"public.table1"}
-				backup.ValidateFilterTables(connectionPool, filterList, false)
+				testBackup.ValidateFilterTables(connectionPool, filterList, false)
 			})
 			It("passes if given a leaf partition table", func() {
 				// Added to handle call to `quote_ident`
@@ -146,7 +193,7 @@ var _ = Describe("backup/validate tests", func() {
 				partitionTables.AddRow("1", "l", "root")
 				mock.ExpectQuery("SELECT (.*)").WillReturnRows(partitionTables)
 				filterList = []string{"public.table1"}
-				backup.ValidateFilterTables(connectionPool, filterList, false)
+				testBackup.ValidateFilterTables(connectionPool, filterList, false)
 			})
 			It("panics if given an intermediate partition table and --leaf-partition-data is set", func() {
 				// Added to handle call to `quote_ident`
@@ -160,7 +207,7 @@ var _ = Describe("backup/validate tests", func() {
 				mock.ExpectQuery("SELECT (.*)").WillReturnRows(partitionTables)
 				filterList = []string{"public.table1"}
 				defer testhelper.ShouldPanicWithMessage("Cannot filter on public.table1, as it is an intermediate partition table.  Only parent partition tables and leaf partition tables may be specified.")
-				backup.ValidateFilterTables(connectionPool, filterList, false)
+				testBackup.ValidateFilterTables(connectionPool, filterList, false)
 			})
 			It("panics if given an intermediate partition table and --leaf-partition-data is not set", func() {
 				// Added to handle call to `quote_ident`
@@ -173,7 +220,7 @@ var _ = Describe("backup/validate tests", func() {
 				mock.ExpectQuery("SELECT (.*)").WillReturnRows(partitionTables)
 				filterList = []string{"public.table1"}
 				defer testhelper.ShouldPanicWithMessage("Cannot filter on public.table1, as it is an intermediate partition table.  Only parent partition tables and leaf partition tables may be specified.")
-				backup.ValidateFilterTables(connectionPool, filterList, false)
+				testBackup.ValidateFilterTables(connectionPool, filterList, false)
 			})
 		})
 	})
@@ -193,4 +240,34 @@ var _ = Describe("backup/validate tests", func() {
 			backup.ValidateCompressionLevel(compressLevel)
 		})
 	})
+	Describe("ValidateUtilityModeFlags", func() {
+		It("does nothing if --utility-mode is not set", func() {
+			testBackup.ValidateUtilityModeFlags(cmdFlags)
+			Expect(cmdFlags.Changed(utils.METADATA_ONLY)).To(BeFalse())
+		})
+		It("sets --metadata-only with a warning if --utility-mode is set", func() {
+			_ = cmdFlags.Set(utils.UTILITY_MODE, "true")
+			testBackup.ValidateUtilityModeFlags(cmdFlags)
+			Expect(testBackup.MustGetFlagBool(utils.METADATA_ONLY)).To(BeTrue())
+			Expect(string(logfile.Contents())).To(ContainSubstring("Table data cannot be backed up in utility mode"))
+		})
+		It("does not warn if --metadata-only is already set", func() {
+			_ = cmdFlags.Set(utils.UTILITY_MODE, "true")
+			_ = cmdFlags.Set(utils.METADATA_ONLY, "true")
+			testBackup.ValidateUtilityModeFlags(cmdFlags)
+			Expect(string(logfile.Contents())).ToNot(ContainSubstring("Table data cannot be backed up in utility mode"))
+		})
+		It("panics if --utility-mode is set with --data-only", func() {
+			_ = cmdFlags.Set(utils.UTILITY_MODE, "true")
+			_ = cmdFlags.Set(utils.DATA_ONLY, "true")
+			defer testhelper.ShouldPanicWithMessage("The following flags may not be specified together: utility-mode, data-only, incremental, single-data-file, plugin-config")
+			testBackup.ValidateUtilityModeFlags(cmdFlags)
+		})
+		It("panics if --utility-mode is set with --plugin-config", func() {
+			_ = cmdFlags.Set(utils.UTILITY_MODE, "true")
+			_ = cmdFlags.Set(utils.PLUGIN_CONFIG, "/tmp/plugin_config.yaml")
+			defer testhelper.ShouldPanicWithMessage("The following flags may not be specified together: utility-mode, data-only, incremental, single-data-file, plugin-config")
+			testBackup.ValidateUtilityModeFlags(cmdFlags)
+		})
+	})
 })
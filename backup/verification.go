@@ -0,0 +1,40 @@
+package backup
+
+/*
+ * This file contains functions for running the user's verification queries
+ * at the backup snapshot.
+ */
+
+import (
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpbackup/utils"
+)
+
+/*
+ * The queries run in the backup transaction on the first connection, after
+ * the tables to back up have been locked, so that their results describe
+ * the same data as the backup.  A failed query is recorded in the report
+ * instead of failing the backup, and is rolled back to a savepoint so that
+ * the transaction remains usable.
+ */
+func RunVerificationQueries(connectionPool *dbconn.DBConn, queries []utils.VerificationQuery) {
+	if len(queries) == 0 {
+		return
+	}
+	gplog.Info("Running verification queries")
+	for i := range queries {
+		query := &queries[i]
+		connectionPool.MustExec("SAVEPOINT gpbackup_verification")
+		result, err := dbconn.SelectString(connectionPool, query.Query)
+		if err != nil {
+			connectionPool.MustExec("ROLLBACK TO SAVEPOINT gpbackup_verification")
+			gplog.Warn("Verification query %s failed: %v", query.Name, err)
+			query.Error = err.Error()
+			continue
+		}
+		connectionPool.MustExec("RELEASE SAVEPOINT gpbackup_verification")
+		gplog.Verbose("Verification query %s returned %s", query.Name, result)
+		query.Result = result
+	}
+}
@@ -0,0 +1,48 @@
+package backup_test
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/greenplum-db/gpbackup/backup"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/pkg/errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("backup/verification tests", func() {
+	Describe("RunVerificationQueries", func() {
+		It("records the result of each query", func() {
+			queries := []utils.VerificationQuery{
+				{Name: "orders", Query: "SELECT count(*) FROM sales.orders"},
+				{Name: "customers", Query: "SELECT count(*) FROM sales.customers"},
+			}
+			mock.ExpectExec("SAVEPOINT gpbackup_verification").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("SELECT count").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow("10"))
+			mock.ExpectExec("RELEASE SAVEPOINT gpbackup_verification").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("SAVEPOINT gpbackup_verification").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("SELECT count").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow("3"))
+			mock.ExpectExec("RELEASE SAVEPOINT gpbackup_verification").WillReturnResult(sqlmock.NewResult(0, 0))
+
+			backup.RunVerificationQueries(connectionPool, queries)
+
+			Expect(queries[0].Result).To(Equal("10"))
+			Expect(queries[1].Result).To(Equal("3"))
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+		It("records the error of a failed query and rolls back to the savepoint", func() {
+			queries := []utils.VerificationQuery{{Name: "orders", Query: "SELECT count(*) FROM sales.orders"}}
+			mock.ExpectExec("SAVEPOINT gpbackup_verification").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("SELECT count").WillReturnError(errors.New(`relation "sales.orders" does not exist`))
+			mock.ExpectExec("ROLLBACK TO SAVEPOINT gpbackup_verification").WillReturnResult(sqlmock.NewResult(0, 0))
+
+			backup.RunVerificationQueries(connectionPool, queries)
+
+			Expect(queries[0].Result).To(Equal(""))
+			Expect(queries[0].Error).To(Equal(`relation "sales.orders" does not exist`))
+			Expect(logfile).To(Say(`Verification query orders failed: relation "sales.orders" does not exist`))
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+})
@@ -1,14 +1,20 @@
 package backup
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"path"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/greenplum-db/gp-common-go-libs/cluster"
 	"github.com/greenplum-db/gp-common-go-libs/dbconn"
 	"github.com/greenplum-db/gp-common-go-libs/gplog"
 	"github.com/greenplum-db/gp-common-go-libs/iohelper"
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/greenplum-db/gpbackup/backup_filepath"
 	"github.com/greenplum-db/gpbackup/backup_history"
 	"github.com/greenplum-db/gpbackup/options"
 	"github.com/greenplum-db/gpbackup/utils"
@@ -27,35 +33,88 @@ import (
  * Setup and validation wrapper functions
  */
 
-func SetLoggerVerbosity() {
-	if MustGetFlagBool(utils.QUIET) {
+func (b *Backup) SetLoggerVerbosity() {
+	if b.MustGetFlagBool(utils.QUIET) {
 		gplog.SetVerbosity(gplog.LOGERROR)
-	} else if MustGetFlagBool(utils.DEBUG) {
+	} else if b.MustGetFlagBool(utils.DEBUG) {
 		gplog.SetVerbosity(gplog.LOGDEBUG)
-	} else if MustGetFlagBool(utils.VERBOSE) {
+	} else if b.MustGetFlagBool(utils.VERBOSE) {
 		gplog.SetVerbosity(gplog.LOGVERBOSE)
 	}
 }
 
-func InitializeConnectionPool() {
-	connectionPool = dbconn.NewDBConnFromEnvironment(MustGetFlagString(utils.DBNAME))
-	connectionPool.MustConnect(MustGetFlagInt(utils.JOBS))
-	utils.ValidateGPDBVersionCompatibility(connectionPool)
-	InitializeMetadataParams(connectionPool)
-	for connNum := 0; connNum < connectionPool.NumConns; connNum++ {
-		connectionPool.MustExec("SET application_name TO 'gpbackup'", connNum)
-		connectionPool.MustBegin(connNum)
-		SetSessionGUCs(connNum)
+func (b *Backup) InitializeConnectionPool() {
+	// A Backup may have been given a connection pool that has not yet connected
+	if b.connectionPool == nil {
+		b.connectionPool = dbconn.NewDBConnFromEnvironment(b.MustGetFlagString(utils.DBNAME))
+	}
+	if b.MustGetFlagBool(utils.UTILITY_MODE) {
+		installedVersion, err := utils.GetInstalledGPDBVersion()
+		gplog.FatalOnError(err)
+		b.connectionPool.Driver = utils.UtilityModeDriver{Version: installedVersion}
+	}
+	b.connectionPool.MustConnect(b.MustGetFlagInt(utils.JOBS))
+	utils.ValidateGPDBVersionCompatibility(b.connectionPool)
+	b.backendPids = make([]string, b.connectionPool.NumConns)
+	for connNum := 0; connNum < b.connectionPool.NumConns; connNum++ {
+		b.connectionPool.MustExec("SET application_name TO 'gpbackup'", connNum)
+		b.backendPids[connNum] = dbconn.MustSelectString(b.connectionPool, "SELECT pg_backend_pid()", connNum)
+		b.connectionPool.MustBegin(connNum)
+		b.SetSessionGUCs(connNum)
+	}
+	b.cancelQueriesOnContextDone(b.backupContext, b.connectionPool, b.backendPids)
+}
+
+/*
+ * Catalog queries are not given a context, so if the backup's context is
+ * canceled while one is running, the query is canceled on the server instead.
+ * The watch ends when DoCleanup closes the connection pool.
+ */
+func (b *Backup) cancelQueriesOnContextDone(ctx context.Context, conn *dbconn.DBConn, pids []string) {
+	if ctx.Done() == nil {
+		return
+	}
+	poolClosed := make(chan struct{})
+	b.connectionPoolClosed = poolClosed
+	go func() {
+		select {
+		case <-ctx.Done():
+			gplog.Warn("Backup canceled, canceling queries in progress")
+			CancelBackendQueries(conn, pids)
+		case <-poolClosed:
+		}
+	}()
+}
+
+/*
+ * The connections in the pool may all be busy, so the queries are canceled
+ * from a new connection.
+ */
+func CancelBackendQueries(conn *dbconn.DBConn, pids []string) {
+	if len(pids) == 0 {
+		return
+	}
+	cancelConn := &dbconn.DBConn{Driver: conn.Driver, User: conn.User, DBName: conn.DBName, Host: conn.Host, Port: conn.Port}
+	err := cancelConn.Connect(1)
+	if err != nil {
+		gplog.Warn("Unable to connect to cancel queries in progress: %v", err)
+		return
+	}
+	defer cancelConn.Close()
+	for _, pid := range pids {
+		// We don't check the error as the query may have finished or the session may have ended
+		_, _ = cancelConn.Exec(fmt.Sprintf("SELECT pg_cancel_backend(%s)", pid))
 	}
 }
 
-func SetSessionGUCs(connNum int) {
+func (b *Backup) SetSessionGUCs(connNum int) {
 	// These GUCs ensure the dumps portability accross systems
-	connectionPool.MustExec("SET search_path TO pg_catalog", connNum)
-	connectionPool.MustExec("SET statement_timeout = 0", connNum)
-	connectionPool.MustExec("SET DATESTYLE = ISO", connNum)
-	connectionPool.MustExec("SET standard_conforming_strings = 1", connNum) // Needed for 4.3, default on in 5+
-	connectionPool.MustExec("SET enable_mergejoin TO off", connNum)
+	b.connectionPool.MustExec("SET search_path TO pg_catalog", connNum)
+	// The timeout is enforced by the server, as most catalog queries cannot be given a context
+	b.connectionPool.MustExec(fmt.Sprintf("SET statement_timeout = %d", b.MustGetFlagInt(utils.QUERY_TIMEOUT)*1000), connNum)
+	b.connectionPool.MustExec("SET DATESTYLE = ISO", connNum)
+	b.connectionPool.MustExec("SET standard_conforming_strings = 1", connNum) // Needed for 4.3, default on in 5+
+	b.connectionPool.MustExec("SET enable_mergejoin TO off", connNum)
 
 	// The fix to raise the max of extra_float_digits GUC is going out with
 	// GPDB 4.3.33.1. This means if we set the GUC using 'SET
@@ -63,96 +122,236 @@ func SetSessionGUCs(connNum int) {
 	// our Semver package only allows up to 3 digits. To avoid any complicated
 	// version diffs of setting this GUC, we use set_config() with a subquery
 	// getting the max value of the GUC.
-	connectionPool.MustExec("SELECT set_config('extra_float_digits', (SELECT max_val FROM pg_settings WHERE name = 'extra_float_digits'), false)")
+	b.connectionPool.MustExec("SELECT set_config('extra_float_digits', (SELECT max_val FROM pg_settings WHERE name = 'extra_float_digits'), false)")
 
-	if connectionPool.Version.AtLeast("5") {
-		connectionPool.MustExec("SET synchronize_seqscans TO off", connNum)
+	if b.connectionPool.Version.AtLeast("5") {
+		b.connectionPool.MustExec("SET synchronize_seqscans TO off", connNum)
+	}
+	if b.connectionPool.Version.AtLeast("6") {
+		b.connectionPool.MustExec("SET INTERVALSTYLE = POSTGRES", connNum)
+		b.connectionPool.MustExec("SET lock_timeout = 0", connNum)
 	}
-	if connectionPool.Version.AtLeast("6") {
-		connectionPool.MustExec("SET INTERVALSTYLE = POSTGRES", connNum)
-		connectionPool.MustExec("SET lock_timeout = 0", connNum)
+
+	// User-specified settings are set last so that they can override the defaults above
+	connectionOptions, err := utils.ParseConnectionOptions(b.MustGetFlagString(utils.CONNECTION_OPTIONS))
+	gplog.FatalOnError(err)
+	backupGUCs, err := utils.ParseBackupGUCs(b.MustGetFlagStringArray(utils.BACKUP_GUC))
+	gplog.FatalOnError(err)
+	for _, option := range append(connectionOptions, backupGUCs...) {
+		b.connectionPool.MustExec(utils.ConnectionOptionStatement(option), connNum)
 	}
 }
 
-func NewBackupConfig(dbName string, dbVersion string, backupVersion string, plugin string, timestamp string, opts options.Options) *backup_history.BackupConfig {
+/*
+ * The null string is only passed on when given, because the default differs
+ * between formats and an empty string is a valid null string.
+ */
+func (b *Backup) GetCopyFormatFromFlags() utils.CopyFormat {
+	var nullString *string
+	if b.cmdFlags.Changed(utils.COPY_NULL_STRING) {
+		flagValue := b.MustGetFlagString(utils.COPY_NULL_STRING)
+		nullString = &flagValue
+	}
+	return utils.NewCopyFormat(b.MustGetFlagString(utils.COPY_FORMAT), b.MustGetFlagString(utils.COPY_DELIMITER), nullString, b.MustGetFlagBool(utils.COPY_HEADER))
+}
+
+func (b *Backup) NewBackupConfig(dbName string, dbVersion string, backupVersion string, plugin string, timestamp string, opts options.Options) *backup_history.BackupConfig {
 	backupConfig := backup_history.BackupConfig{
-		BackupDir:             MustGetFlagString(utils.BACKUP_DIR),
+		BackupDir:             b.MustGetFlagString(utils.BACKUP_DIR),
 		BackupVersion:         backupVersion,
-		Compressed:            !MustGetFlagBool(utils.NO_COMPRESSION),
+		Compressed:            !b.MustGetFlagBool(utils.NO_COMPRESSION),
+		CopyDelimiter:         b.copyFormat.Delimiter,
+		CopyFormat:            b.copyFormat.Format,
+		CopyHeader:            b.copyFormat.Header,
+		CopyNullString:        b.copyFormat.NullString,
 		DatabaseName:          dbName,
 		DatabaseVersion:       dbVersion,
-		DataOnly:              MustGetFlagBool(utils.DATA_ONLY),
-		ExcludeRelations:      MustGetFlagStringSlice(utils.EXCLUDE_RELATION),
-		ExcludeSchemaFiltered: len(MustGetFlagStringSlice(utils.EXCLUDE_SCHEMA)) > 0,
-		ExcludeSchemas:        MustGetFlagStringSlice(utils.EXCLUDE_SCHEMA),
-		ExcludeTableFiltered:  len(MustGetFlagStringSlice(utils.EXCLUDE_RELATION)) > 0,
+		DataFiltered:          b.MustGetFlagString(utils.DATA_FILTER_FILE) != "" || b.MustGetFlagInt(utils.SAMPLE_PERCENT) > 0,
+		DataMasked:            b.MustGetFlagString(utils.MASKING_RULES_FILE) != "",
+		DataOnly:              b.MustGetFlagBool(utils.DATA_ONLY),
+		ExcludeColumns:        b.MustGetFlagStringArray(utils.EXCLUDE_COLUMN),
+		ExcludedArtifacts:     b.excludedArtifacts,
+		ExcludeLeafPartitions: b.MustGetFlagStringArray(utils.EXCLUDE_LEAF_PARTITION),
+		ExcludeRelations:      b.MustGetFlagStringSlice(utils.EXCLUDE_RELATION),
+		ExcludeSchemaFiltered: len(b.MustGetFlagStringSlice(utils.EXCLUDE_SCHEMA)) > 0,
+		ExcludeSchemas:        b.MustGetFlagStringSlice(utils.EXCLUDE_SCHEMA),
+		ExcludeTableFiltered:  len(b.MustGetFlagStringSlice(utils.EXCLUDE_RELATION)) > 0,
+		IncludeLeafPartitions: b.MustGetFlagStringArray(utils.INCLUDE_LEAF_PARTITION),
 		IncludeRelations:      opts.GetOriginalIncludedTables(),
-		IncludeSchemaFiltered: len(MustGetFlagStringSlice(utils.INCLUDE_SCHEMA)) > 0,
-		IncludeSchemas:        MustGetFlagStringSlice(utils.INCLUDE_SCHEMA),
-		IncludeTableFiltered:  len(MustGetFlagStringArray(utils.INCLUDE_RELATION)) > 0,
-		Incremental:           MustGetFlagBool(utils.INCREMENTAL),
-		LeafPartitionData:     MustGetFlagBool(utils.LEAF_PARTITION_DATA),
-		MetadataOnly:          MustGetFlagBool(utils.METADATA_ONLY),
+		IncludeSchemaFiltered: len(b.MustGetFlagStringSlice(utils.INCLUDE_SCHEMA)) > 0,
+		IncludeSchemas:        b.MustGetFlagStringSlice(utils.INCLUDE_SCHEMA),
+		IncludeTableFiltered:  len(b.MustGetFlagStringArray(utils.INCLUDE_RELATION)) > 0,
+		Incremental:           b.MustGetFlagBool(utils.INCREMENTAL),
+		MetadataCompressed:    b.MustGetFlagBool(utils.COMPRESS_METADATA),
+		MetadataEncrypted:     b.MustGetFlagString(utils.METADATA_ENCRYPTION_KEY_FILE) != "",
+		LeafPartitionData:     b.MustGetFlagBool(utils.LEAF_PARTITION_DATA),
+		LeafPartitionKeyEnd:   b.MustGetFlagString(utils.LEAF_PARTITION_KEY_END),
+		LeafPartitionKeyStart: b.MustGetFlagString(utils.LEAF_PARTITION_KEY_START),
+		MetadataOnly:          b.MustGetFlagBool(utils.METADATA_ONLY),
+		ParquetExport:         b.MustGetFlagBool(utils.PARQUET_EXPORT),
 		Plugin:                plugin,
-		SingleDataFile:        MustGetFlagBool(utils.SINGLE_DATA_FILE),
+		SingleDataFile:        b.MustGetFlagBool(utils.SINGLE_DATA_FILE),
+		Target:                b.MustGetFlagString(utils.TARGET),
 		Timestamp:             timestamp,
-		WithStatistics:        MustGetFlagBool(utils.WITH_STATS),
+		WithExternalData:      b.MustGetFlagBool(utils.WITH_EXTERNAL_DATA),
+		WithLargeObjects:      b.MustGetFlagBool(utils.WITH_LARGE_OBJECTS),
+		WithStatistics:        b.MustGetFlagBool(utils.WITH_STATS),
 	}
 
 	return &backupConfig
 }
 
-func InitializeBackupReport(opts options.Options) {
-	escapedDBName := dbconn.MustSelectString(connectionPool, fmt.Sprintf("select quote_ident(datname) AS string FROM pg_database where datname='%s'", utils.EscapeSingleQuotes(connectionPool.DBName)))
+func (b *Backup) InitializeBackupReport(opts options.Options) {
+	escapedDBName := dbconn.MustSelectString(b.connectionPool, fmt.Sprintf("select quote_ident(datname) AS string FROM pg_database where datname='%s'", utils.EscapeSingleQuotes(b.connectionPool.DBName)))
 	plugin := ""
-	if pluginConfig != nil {
-		plugin = pluginConfig.ExecutablePath
+	if b.pluginConfig != nil {
+		plugin = b.pluginConfig.ExecutablePath
 	}
-	config := NewBackupConfig(escapedDBName, connectionPool.Version.VersionString, version,
-		plugin, globalFPInfo.Timestamp, opts)
+	config := b.NewBackupConfig(escapedDBName, b.connectionPool.Version.VersionString, version,
+		plugin, b.globalFPInfo.Timestamp, opts)
+	config.SegmentCount = len(b.globalCluster.ContentIDs) - 1
 
 	isFilteredBackup := config.IncludeTableFiltered || config.IncludeSchemaFiltered ||
 		config.ExcludeTableFiltered || config.ExcludeSchemaFiltered
 	dbSize := ""
-	if !MustGetFlagBool(utils.METADATA_ONLY) && !isFilteredBackup {
+	if !b.MustGetFlagBool(utils.METADATA_ONLY) && !isFilteredBackup {
 		gplog.Verbose("Getting database size")
 		//Potentially expensive query
-		dbSize = GetDBSize(connectionPool)
+		dbSize = GetDBSize(b.connectionPool)
 	}
 
-	backupReport = &utils.Report{
+	b.backupReport = &utils.Report{
 		DatabaseSize: dbSize,
 		BackupConfig: *config,
 	}
-	backupReport.ConstructBackupParamsString()
+	b.backupReport.ConstructBackupParamsString()
 }
 
-func InitializeFilterLists() {
-	if MustGetFlagString(utils.EXCLUDE_RELATION_FILE) != "" {
-		excludeRelations := iohelper.MustReadLinesFromFile(MustGetFlagString(utils.EXCLUDE_RELATION_FILE))
-		err := cmdFlags.Set(utils.EXCLUDE_RELATION, strings.Join(excludeRelations, ","))
+func (b *Backup) InitializeFilterLists() {
+	if b.MustGetFlagString(utils.INCLUDE_SCHEMA_FILE) != "" {
+		includeSchemas := iohelper.MustReadLinesFromFile(b.MustGetFlagString(utils.INCLUDE_SCHEMA_FILE))
+		err := b.cmdFlags.Set(utils.INCLUDE_SCHEMA, strings.Join(includeSchemas, ","))
+		gplog.FatalOnError(err)
+	}
+	if b.MustGetFlagString(utils.EXCLUDE_SCHEMA_FILE) != "" {
+		excludeSchemas := iohelper.MustReadLinesFromFile(b.MustGetFlagString(utils.EXCLUDE_SCHEMA_FILE))
+		err := b.cmdFlags.Set(utils.EXCLUDE_SCHEMA, strings.Join(excludeSchemas, ","))
+		gplog.FatalOnError(err)
+	}
+	if b.MustGetFlagString(utils.EXCLUDE_RELATION_FILE) != "" {
+		excludeRelations := iohelper.MustReadLinesFromFile(b.MustGetFlagString(utils.EXCLUDE_RELATION_FILE))
+		err := b.cmdFlags.Set(utils.EXCLUDE_RELATION, strings.Join(excludeRelations, ","))
 		gplog.FatalOnError(err)
 	}
 }
 
-func CreateBackupLockFile(timestamp string) {
+func (b *Backup) CreateBackupLockFile(timestamp string) {
 	var err error
 	timestampLockFile := fmt.Sprintf("/tmp/%s.lck", timestamp)
-	backupLockFile, err = lockfile.New(timestampLockFile)
+	b.backupLockFile, err = lockfile.New(timestampLockFile)
 	gplog.FatalOnError(err)
-	err = backupLockFile.TryLock()
+	err = b.backupLockFile.TryLock()
 	if err != nil {
 		gplog.Error(err.Error())
 		gplog.Fatal(errors.Errorf("A backup with timestamp %s is already in progress. Wait 1 second and try the backup again.", timestamp), "")
 	}
 }
 
-func CreateBackupDirectoriesOnAllHosts() {
-	remoteOutput := globalCluster.GenerateAndExecuteCommand("Creating backup directories", func(contentID int) string {
-		return fmt.Sprintf("mkdir -p %s", globalFPInfo.GetDirForContent(contentID))
+func (b *Backup) openMetadataFileForWriting(filename string) *utils.FileWithByteCount {
+	if key := utils.GetMetadataEncryptionKey(); key != nil {
+		return utils.NewEncryptedFileWithByteCountFromFile(filename, b.getMetadataCompressionLevel(), key)
+	}
+	if b.MustGetFlagBool(utils.COMPRESS_METADATA) {
+		return utils.NewCompressedFileWithByteCountFromFile(filename, b.MustGetFlagInt(utils.COMPRESSION_LEVEL))
+	}
+	return utils.NewFileWithByteCountFromFile(filename)
+}
+
+/*
+ * If the backup has any statement middleware, metadata is collected in memory
+ * so that each statement can be rewritten once its TOC entry is known, and is
+ * only written to disk by flushMetadataBuffer.
+ */
+func (b *Backup) newMetadataFile(filename string) (*utils.FileWithByteCount, *bytes.Buffer) {
+	if len(b.statementMiddleware) > 0 {
+		buffer := &bytes.Buffer{}
+		return utils.NewFileWithByteCount(buffer), buffer
+	}
+	return b.openMetadataFileForWriting(filename), nil
+}
+
+/*
+ * Returns the file to which the buffer was written, or the given file if
+ * its contents were written directly to disk rather than buffered.
+ */
+func (b *Backup) flushMetadataBuffer(filename string, file *utils.FileWithByteCount, buffer *bytes.Buffer, sections ...string) *utils.FileWithByteCount {
+	if buffer == nil {
+		return file
+	}
+	metadataFile := b.openMetadataFileForWriting(filename)
+	b.globalTOC.RewriteStatementsWithMiddleware(b.statementMiddleware, buffer.Bytes(), metadataFile, sections...)
+	metadataFile.Close()
+	return metadataFile
+}
+
+/*
+ * Statements are read back from the finished metadata file, so that the split
+ * files contain exactly what gprestore would run.  The session GUCs are
+ * written at the top of every file, so that each file can be run on its own.
+ */
+func (b *Backup) writeSplitMetadataFiles(metadataFilename string, splitBy string) []string {
+	splitDir := b.globalFPInfo.GetSplitMetadataDirPath()
+	gplog.Info("Writing metadata split by %s to %s", splitBy, splitDir)
+	contents, err := utils.ReadFileDecompressingIfNeeded(metadataFilename)
+	gplog.FatalOnError(err)
+	err = operating.System.MkdirAll(splitDir, 0755)
+	gplog.FatalOnError(err)
+
+	gucs := ""
+	for _, entry := range b.globalTOC.GlobalEntries {
+		if entry.ObjectType == "SESSION GUCS" {
+			gucs = string(contents[entry.StartByte:entry.EndByte])
+		}
+	}
+	filenames, entriesByFile := b.globalTOC.GetSplitMetadataEntries(splitBy)
+	splitFilenames := make([]string, 0, len(filenames))
+	for _, filename := range filenames {
+		splitFilename := path.Join(splitDir, filename)
+		splitFile := utils.NewFileWithByteCountFromFile(splitFilename)
+		splitFile.MustPrint(gucs)
+		for _, entry := range entriesByFile[filename] {
+			splitFile.MustPrint(string(contents[entry.StartByte:entry.EndByte]))
+		}
+		splitFile.Close()
+		splitFilenames = append(splitFilenames, splitFilename)
+	}
+	return splitFilenames
+}
+
+// Returns 0 if metadata is not to be compressed
+func (b *Backup) getMetadataCompressionLevel() int {
+	if !b.MustGetFlagBool(utils.COMPRESS_METADATA) {
+		return 0
+	}
+	return b.MustGetFlagInt(utils.COMPRESSION_LEVEL)
+}
+
+func (b *Backup) writeTOCFile(filename string) {
+	if key := utils.GetMetadataEncryptionKey(); key != nil {
+		b.globalTOC.WriteToEncryptedFileAndMakeReadOnly(filename, b.getMetadataCompressionLevel(), key)
+	} else if b.MustGetFlagBool(utils.COMPRESS_METADATA) {
+		b.globalTOC.WriteToCompressedFileAndMakeReadOnly(filename, b.MustGetFlagInt(utils.COMPRESSION_LEVEL))
+	} else {
+		b.globalTOC.WriteToFileAndMakeReadOnly(filename)
+	}
+}
+
+func (b *Backup) CreateBackupDirectoriesOnAllHosts() {
+	remoteOutput := b.globalCluster.GenerateAndExecuteCommand("Creating backup directories", func(contentID int) string {
+		return fmt.Sprintf("mkdir -p %s", b.globalFPInfo.GetDirForContent(contentID))
 	}, cluster.ON_SEGMENTS_AND_MASTER)
-	globalCluster.CheckClusterError(remoteOutput, "Unable to create backup directories", func(contentID int) string {
-		return fmt.Sprintf("Unable to create backup directory %s", globalFPInfo.GetDirForContent(contentID))
+	b.globalCluster.CheckClusterError(remoteOutput, "Unable to create backup directories", func(contentID int) string {
+		return fmt.Sprintf("Unable to create backup directory %s", b.globalFPInfo.GetDirForContent(contentID))
 	})
 }
 
@@ -160,30 +359,72 @@ func CreateBackupDirectoriesOnAllHosts() {
  * Metadata retrieval wrapper functions
  */
 
-func RetrieveAndProcessTables() ([]Table, []Table) {
-	quotedIncludeRelations, err := options.QuoteTableNames(connectionPool, MustGetFlagStringArray(utils.INCLUDE_RELATION))
+func (b *Backup) RetrieveAndProcessTables() ([]Table, []Table) {
+	b.SetCurrentObject("tables", "")
+	quotedIncludeRelations, err := options.QuoteTableNames(b.connectionPool, b.MustGetFlagStringArray(utils.INCLUDE_RELATION))
 	gplog.FatalOnError(err)
 
-	tableRelations := GetIncludedUserTableRelations(connectionPool, quotedIncludeRelations)
-	LockTables(connectionPool, tableRelations)
-
-	if connectionPool.Version.AtLeast("6") {
-		tableRelations = append(tableRelations, GetForeignTableRelations(connectionPool)...)
+	tableRelations := b.GetIncludedUserTableRelations(b.connectionPool, quotedIncludeRelations)
+	excludeLeafPartitionOids := b.getExcludedLeafPartitionOids(tableRelations)
+	if !b.MustGetFlagBool(utils.DRY_RUN) {
+		if b.MustGetFlagBool(utils.LOCK_DATA_TABLES_ONLY) {
+			b.backupReport.LockDuration = b.LockTables(b.connectionPool, b.GetRelationsToLockForData(tableRelations, excludeLeafPartitionOids))
+		} else {
+			b.backupReport.LockDuration = b.LockTables(b.connectionPool, tableRelations)
+		}
 	}
 
-	tables := ConstructDefinitionsForTables(connectionPool, tableRelations)
+	if b.connectionPool.Version.AtLeast("6") {
+		tableRelations = append(tableRelations, b.GetForeignTableRelations(b.connectionPool)...)
+	}
 
-	metadataTables, dataTables := SplitTablesByPartitionType(tables, quotedIncludeRelations)
-	objectCounts["Tables"] = len(metadataTables)
+	tables := b.ConstructDefinitionsForTables(b.connectionPool, tableRelations)
 
+	metadataTables, dataTables := b.SplitTablesByPartitionType(tables, quotedIncludeRelations)
+	b.objectCounts["Tables"] = len(metadataTables)
+	if len(excludeLeafPartitionOids) > 0 {
+		dataTables = FilterExcludedLeafPartitions(dataTables, excludeLeafPartitionOids)
+	}
+	if len(b.excludedColumns) > 0 {
+		dataTables = b.ExcludeColumnsFromTables(dataTables, b.MustGetFlagBool(utils.EXCLUDE_COLUMN_DDL))
+		if b.MustGetFlagBool(utils.EXCLUDE_COLUMN_DDL) {
+			metadataTables = b.ExcludeColumnsFromTables(metadataTables, true)
+		}
+	}
 	return metadataTables, dataTables
 }
 
-func RetrieveFunctions(sortables *[]Sortable, metadataMap MetadataMap, procLangs []ProceduralLanguage) ([]Function, MetadataMap) {
+/*
+ * Leaf partitions can be excluded by name, or all leaf partitions other than
+ * those selected by name or by partition key range can be excluded.
+ */
+func (b *Backup) getExcludedLeafPartitionOids(tableRelations []Relation) []string {
+	excludeOids := make([]string, 0)
+	if excludeLeafPartitions := b.MustGetFlagStringArray(utils.EXCLUDE_LEAF_PARTITION); len(excludeLeafPartitions) > 0 {
+		quotedExcludeLeafPartitions, err := options.QuoteTableNames(b.connectionPool, excludeLeafPartitions)
+		gplog.FatalOnError(err)
+		excludeOids = GetOidsFromRelationList(b.connectionPool, quotedExcludeLeafPartitions)
+	}
+	if includeLeafPartitions := b.MustGetFlagStringArray(utils.INCLUDE_LEAF_PARTITION); len(includeLeafPartitions) > 0 {
+		quotedIncludeLeafPartitions, err := options.QuoteTableNames(b.connectionPool, includeLeafPartitions)
+		gplog.FatalOnError(err)
+		includeOids := GetOidsFromRelationList(b.connectionPool, quotedIncludeLeafPartitions)
+		excludeOids = append(excludeOids, GetUnselectedLeafPartitionOids(tableRelations, b.GetPartitionTableMap(b.connectionPool), includeOids)...)
+	}
+	keyStart, keyEnd := b.MustGetFlagString(utils.LEAF_PARTITION_KEY_START), b.MustGetFlagString(utils.LEAF_PARTITION_KEY_END)
+	if keyStart != "" || keyEnd != "" {
+		inRangeOids := GetLeafPartitionOidsInKeyRange(b.connectionPool, GetLeafPartitionRangeBounds(b.connectionPool), keyStart, keyEnd)
+		excludeOids = append(excludeOids, GetUnselectedLeafPartitionOids(tableRelations, b.GetPartitionTableMap(b.connectionPool), inRangeOids)...)
+	}
+	return excludeOids
+}
+
+func (b *Backup) RetrieveFunctions(sortables *[]Sortable, metadataMap MetadataMap, procLangs []ProceduralLanguage) ([]Function, MetadataMap) {
 	gplog.Verbose("Retrieving function information")
-	functions := GetFunctionsAllVersions(connectionPool)
-	objectCounts["Functions"] = len(functions)
-	functionMetadata := GetMetadataForObjectType(connectionPool, TYPE_FUNCTION)
+	b.SetCurrentObject("functions", "")
+	functions := b.GetFunctionsAllVersions(b.connectionPool)
+	b.objectCounts["Functions"] = len(functions)
+	functionMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_FUNCTION)
 	langFuncs, otherFuncs := ExtractLanguageFunctions(functions, procLangs)
 
 	*sortables = append(*sortables, convertToSortableSlice(otherFuncs)...)
@@ -192,28 +433,29 @@ func RetrieveFunctions(sortables *[]Sortable, metadataMap MetadataMap, procLangs
 	return langFuncs, functionMetadata
 }
 
-func RetrieveAndBackupTypes(metadataFile *utils.FileWithByteCount, sortables *[]Sortable, metadataMap MetadataMap) {
+func (b *Backup) RetrieveAndBackupTypes(metadataFile *utils.FileWithByteCount, sortables *[]Sortable, metadataMap MetadataMap) {
 	gplog.Verbose("Retrieving type information")
-	shells := GetShellTypes(connectionPool)
-	bases := GetBaseTypes(connectionPool)
-	composites := GetCompositeTypes(connectionPool)
-	domains := GetDomainTypes(connectionPool)
+	b.SetCurrentObject("types", "")
+	shells := b.GetShellTypes(b.connectionPool)
+	bases := b.GetBaseTypes(b.connectionPool)
+	composites := b.GetCompositeTypes(b.connectionPool)
+	domains := b.GetDomainTypes(b.connectionPool)
 	rangeTypes := make([]RangeType, 0)
-	if connectionPool.Version.AtLeast("6") {
-		rangeTypes = GetRangeTypes(connectionPool)
+	if b.connectionPool.Version.AtLeast("6") {
+		rangeTypes = b.GetRangeTypes(b.connectionPool)
 	}
-	typeMetadata := GetMetadataForObjectType(connectionPool, TYPE_TYPE)
+	typeMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_TYPE)
 
-	BackupShellTypes(metadataFile, shells, bases, rangeTypes)
-	if connectionPool.Version.AtLeast("5") {
-		BackupEnumTypes(metadataFile, typeMetadata)
+	b.BackupShellTypes(metadataFile, shells, bases, rangeTypes)
+	if b.connectionPool.Version.AtLeast("5") {
+		b.BackupEnumTypes(metadataFile, typeMetadata)
 	}
 
-	objectCounts["Types"] += len(shells)
-	objectCounts["Types"] += len(bases)
-	objectCounts["Types"] += len(composites)
-	objectCounts["Types"] += len(domains)
-	objectCounts["Types"] += len(rangeTypes)
+	b.objectCounts["Types"] += len(shells)
+	b.objectCounts["Types"] += len(bases)
+	b.objectCounts["Types"] += len(composites)
+	b.objectCounts["Types"] += len(domains)
+	b.objectCounts["Types"] += len(rangeTypes)
 	*sortables = append(*sortables, convertToSortableSlice(bases)...)
 	*sortables = append(*sortables, convertToSortableSlice(composites)...)
 	*sortables = append(*sortables, convertToSortableSlice(domains)...)
@@ -221,25 +463,28 @@ func RetrieveAndBackupTypes(metadataFile *utils.FileWithByteCount, sortables *[]
 	addToMetadataMap(typeMetadata, metadataMap)
 }
 
-func RetrieveConstraints(tables ...Relation) ([]Constraint, MetadataMap) {
+func (b *Backup) RetrieveConstraints(tables ...Relation) ([]Constraint, MetadataMap) {
 	gplog.Verbose("Retrieving constraints")
-	constraints := GetConstraints(connectionPool, tables...)
-	conMetadata := GetCommentsForObjectType(connectionPool, TYPE_CONSTRAINT)
+	b.SetCurrentObject("constraints", "")
+	constraints := b.GetConstraints(b.connectionPool, tables...)
+	conMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_CONSTRAINT)
 	return constraints, conMetadata
 }
 
-func RetrieveSequences() ([]Sequence, map[string]string) {
+func (b *Backup) RetrieveSequences() ([]Sequence, map[string]string) {
 	gplog.Verbose("Retrieving sequences")
-	sequenceOwnerTables, sequenceOwnerColumns := GetSequenceColumnOwnerMap(connectionPool)
-	sequences := GetAllSequences(connectionPool, sequenceOwnerTables)
+	b.SetCurrentObject("sequences", "")
+	sequenceOwnerTables, sequenceOwnerColumns := b.GetSequenceColumnOwnerMap(b.connectionPool)
+	sequences := b.GetAllSequences(b.connectionPool, sequenceOwnerTables)
 	return sequences, sequenceOwnerColumns
 }
 
-func RetrieveProtocols(sortables *[]Sortable, metadataMap MetadataMap) []ExternalProtocol {
+func (b *Backup) RetrieveProtocols(sortables *[]Sortable, metadataMap MetadataMap) []ExternalProtocol {
 	gplog.Verbose("Retrieving protocols")
-	protocols := GetExternalProtocols(connectionPool)
-	objectCounts["Protocols"] = len(protocols)
-	protoMetadata := GetMetadataForObjectType(connectionPool, TYPE_PROTOCOL)
+	b.SetCurrentObject("protocols", "")
+	protocols := GetExternalProtocols(b.connectionPool)
+	b.objectCounts["Protocols"] = len(protocols)
+	protoMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_PROTOCOL)
 
 	*sortables = append(*sortables, convertToSortableSlice(protocols)...)
 	addToMetadataMap(protoMetadata, metadataMap)
@@ -247,237 +492,333 @@ func RetrieveProtocols(sortables *[]Sortable, metadataMap MetadataMap) []Externa
 	return protocols
 }
 
-func RetrieveViews(sortables *[]Sortable) {
+/*
+ * Views that depend on relations excluded by filters could not be restored,
+ * so they are excluded as well, or the backup fails under --strict.  They are
+ * added to the relation filter so that their rules, indexes, and other
+ * dependent objects are excluded along with them.
+ */
+func (b *Backup) ExcludeViewsWithExcludedDependencies() {
+	dependencies := b.GetViewDependencies(b.connectionPool)
+	excludedViews := FindViewsWithExcludedDependencies(dependencies)
+	if len(excludedViews) == 0 {
+		return
+	}
+	viewNames := make(map[uint32]string, len(excludedViews))
+	for _, dep := range dependencies {
+		viewNames[dep.ViewOid] = dep.ViewName
+	}
+	messages := make([]string, 0, len(excludedViews))
+	viewOids := make([]string, 0, len(excludedViews))
+	for viewOid, referenceName := range excludedViews {
+		messages = append(messages, fmt.Sprintf("View %s depends on %s, which is not in the backup", viewNames[viewOid], referenceName))
+		viewOids = append(viewOids, fmt.Sprintf("%d", viewOid))
+	}
+	sort.Strings(messages)
+	if b.MustGetFlagBool(utils.STRICT) {
+		gplog.Fatal(errors.Errorf("%s.  Include the relations these views depend on, exclude the views, or remove --strict to exclude such views automatically.", strings.Join(messages, ".  ")), "")
+	}
+	for _, message := range messages {
+		gplog.Warn("%s; excluding it from the backup", message)
+	}
+	b.filterRelationClause = b.relationAndSchemaFilterClause() + fmt.Sprintf("\nAND c.oid NOT IN (%s)", strings.Join(viewOids, ", "))
+}
+
+func (b *Backup) RetrieveViews(sortables *[]Sortable) {
 	gplog.Verbose("Retrieving views")
-	views, materializedViews := GetAllViews(connectionPool)
-	objectCounts["Views"] = len(views)
+	b.SetCurrentObject("views", "")
+	b.ExcludeViewsWithExcludedDependencies()
+	views, materializedViews := b.GetAllViews(b.connectionPool)
+	b.objectCounts["Views"] = len(views)
 
 	*sortables = append(*sortables, convertToSortableSlice(views)...)
 	*sortables = append(*sortables, convertToSortableSlice(materializedViews)...)
 }
 
-func RetrieveTSParsers(sortables *[]Sortable, metadataMap MetadataMap) {
+func (b *Backup) RetrieveTSParsers(sortables *[]Sortable, metadataMap MetadataMap) {
 	gplog.Verbose("Retrieving Text Search Parsers")
-	parsers := GetTextSearchParsers(connectionPool)
-	objectCounts["Text Search Parsers"] = len(parsers)
-	parserMetadata := GetCommentsForObjectType(connectionPool, TYPE_TSPARSER)
+	b.SetCurrentObject("text search parsers", "")
+	parsers := b.GetTextSearchParsers(b.connectionPool)
+	b.objectCounts["Text Search Parsers"] = len(parsers)
+	parserMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_TSPARSER)
 
 	*sortables = append(*sortables, convertToSortableSlice(parsers)...)
 	addToMetadataMap(parserMetadata, metadataMap)
 }
 
-func RetrieveTSTemplates(sortables *[]Sortable, metadataMap MetadataMap) {
+func (b *Backup) RetrieveTSTemplates(sortables *[]Sortable, metadataMap MetadataMap) {
 	gplog.Verbose("Retrieving TEXT SEARCH TEMPLATE information")
-	templates := GetTextSearchTemplates(connectionPool)
-	objectCounts["Text Search Templates"] = len(templates)
-	templateMetadata := GetCommentsForObjectType(connectionPool, TYPE_TSTEMPLATE)
+	b.SetCurrentObject("text search templates", "")
+	templates := b.GetTextSearchTemplates(b.connectionPool)
+	b.objectCounts["Text Search Templates"] = len(templates)
+	templateMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_TSTEMPLATE)
 
 	*sortables = append(*sortables, convertToSortableSlice(templates)...)
 	addToMetadataMap(templateMetadata, metadataMap)
 }
 
-func RetrieveTSDictionaries(sortables *[]Sortable, metadataMap MetadataMap) {
+func (b *Backup) RetrieveTSDictionaries(sortables *[]Sortable, metadataMap MetadataMap) {
 	gplog.Verbose("Retrieving TEXT SEARCH DICTIONARY information")
-	dictionaries := GetTextSearchDictionaries(connectionPool)
-	objectCounts["Text Search Dictionaries"] = len(dictionaries)
-	dictionaryMetadata := GetMetadataForObjectType(connectionPool, TYPE_TSDICTIONARY)
+	b.SetCurrentObject("text search dictionaries", "")
+	dictionaries := b.GetTextSearchDictionaries(b.connectionPool)
+	b.objectCounts["Text Search Dictionaries"] = len(dictionaries)
+	dictionaryMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_TSDICTIONARY)
 
 	*sortables = append(*sortables, convertToSortableSlice(dictionaries)...)
 	addToMetadataMap(dictionaryMetadata, metadataMap)
 }
 
-func RetrieveTSConfigurations(sortables *[]Sortable, metadataMap MetadataMap) {
+func (b *Backup) RetrieveTSConfigurations(sortables *[]Sortable, metadataMap MetadataMap) {
 	gplog.Verbose("Retrieving TEXT SEARCH CONFIGURATION information")
-	configurations := GetTextSearchConfigurations(connectionPool)
-	objectCounts["Text Search Configurations"] = len(configurations)
-	configurationMetadata := GetMetadataForObjectType(connectionPool, TYPE_TSCONFIGURATION)
+	b.SetCurrentObject("text search configurations", "")
+	configurations := b.GetTextSearchConfigurations(b.connectionPool)
+	b.objectCounts["Text Search Configurations"] = len(configurations)
+	configurationMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_TSCONFIGURATION)
 
 	*sortables = append(*sortables, convertToSortableSlice(configurations)...)
 	addToMetadataMap(configurationMetadata, metadataMap)
 }
 
-func RetrieveOperators(sortables *[]Sortable, metadataMap MetadataMap) {
+func (b *Backup) RetrieveOperators(sortables *[]Sortable, metadataMap MetadataMap) {
 	gplog.Verbose("Retrieving OPERATOR information")
-	operators := GetOperators(connectionPool)
-	objectCounts["Operators"] = len(operators)
-	operatorMetadata := GetMetadataForObjectType(connectionPool, TYPE_OPERATOR)
+	b.SetCurrentObject("operators", "")
+	operators := b.GetOperators(b.connectionPool)
+	b.objectCounts["Operators"] = len(operators)
+	operatorMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_OPERATOR)
 
 	*sortables = append(*sortables, convertToSortableSlice(operators)...)
 	addToMetadataMap(operatorMetadata, metadataMap)
 }
 
-func RetrieveOperatorClasses(sortables *[]Sortable, metadataMap MetadataMap) {
+func (b *Backup) RetrieveOperatorClasses(sortables *[]Sortable, metadataMap MetadataMap) {
 	gplog.Verbose("Retrieving OPERATOR CLASS information")
-	operatorClasses := GetOperatorClasses(connectionPool)
-	objectCounts["Operator Classes"] = len(operatorClasses)
-	operatorClassMetadata := GetMetadataForObjectType(connectionPool, TYPE_OPERATORCLASS)
+	b.SetCurrentObject("operator classes", "")
+	operatorClasses := b.GetOperatorClasses(b.connectionPool)
+	b.objectCounts["Operator Classes"] = len(operatorClasses)
+	operatorClassMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_OPERATORCLASS)
 
 	*sortables = append(*sortables, convertToSortableSlice(operatorClasses)...)
 	addToMetadataMap(operatorClassMetadata, metadataMap)
 }
 
-func RetrieveAggregates(sortables *[]Sortable, metadataMap MetadataMap) {
+func (b *Backup) RetrieveAggregates(sortables *[]Sortable, metadataMap MetadataMap) {
 	gplog.Verbose("Retrieving AGGREGATE information")
-	aggregates := GetAggregates(connectionPool)
-	objectCounts["Aggregates"] = len(aggregates)
+	b.SetCurrentObject("aggregates", "")
+	aggregates := b.GetAggregates(b.connectionPool)
+	b.objectCounts["Aggregates"] = len(aggregates)
 	/* This call to get Metadata for Aggregates, although redundant, is preserved for
 	 * consistency with other, similar methods.  The metadata for aggregate
 	 * are located on the same catalog table as functions (pg_proc). This means that when we
 	 * get function metadata we also get aggregate metadata at the same time.
 	 */
-	aggMetadata := GetMetadataForObjectType(connectionPool, TYPE_AGGREGATE)
+	aggMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_AGGREGATE)
 
 	*sortables = append(*sortables, convertToSortableSlice(aggregates)...)
 	addToMetadataMap(aggMetadata, metadataMap)
 }
 
-func RetrieveCasts(sortables *[]Sortable, metadataMap MetadataMap) {
+func (b *Backup) RetrieveCasts(sortables *[]Sortable, metadataMap MetadataMap) {
 	gplog.Verbose("Retrieving CAST information")
-	casts := GetCasts(connectionPool)
-	objectCounts["Casts"] = len(casts)
-	castMetadata := GetCommentsForObjectType(connectionPool, TYPE_CAST)
+	b.SetCurrentObject("casts", "")
+	casts := b.GetCasts(b.connectionPool)
+	b.objectCounts["Casts"] = len(casts)
+	castMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_CAST)
 
 	*sortables = append(*sortables, convertToSortableSlice(casts)...)
 	addToMetadataMap(castMetadata, metadataMap)
 }
 
-func RetrieveForeignDataWrappers(sortables *[]Sortable, metadataMap MetadataMap) {
+func (b *Backup) RetrieveTransforms(sortables *[]Sortable, metadataMap MetadataMap) {
+	gplog.Verbose("Retrieving TRANSFORM information")
+	b.SetCurrentObject("transforms", "")
+	transforms := b.GetTransforms(b.connectionPool)
+	b.objectCounts["Transforms"] = len(transforms)
+	transformMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_TRANSFORM)
+
+	*sortables = append(*sortables, convertToSortableSlice(transforms)...)
+	addToMetadataMap(transformMetadata, metadataMap)
+}
+
+/*
+ * Foreign data wrappers, servers, and user mappings do not belong to a schema,
+ * so when schemas are filtered only those used by the foreign tables in the
+ * backup are backed up.
+ */
+func (b *Backup) RetrieveForeignDataWrappers(sortables *[]Sortable, metadataMap MetadataMap, servers []ForeignServer) {
 	gplog.Verbose("Writing CREATE FOREIGN DATA WRAPPER statements to metadata file")
-	wrappers := GetForeignDataWrappers(connectionPool)
-	objectCounts["Foreign Data Wrappers"] = len(wrappers)
-	fdwMetadata := GetMetadataForObjectType(connectionPool, TYPE_FOREIGNDATAWRAPPER)
+	b.SetCurrentObject("foreign data wrappers", "")
+	wrappers := GetForeignDataWrappers(b.connectionPool)
+	if len(b.MustGetFlagStringSlice(utils.INCLUDE_SCHEMA)) > 0 {
+		wrappers = FilterForeignDataWrappersForServers(wrappers, servers)
+	}
+	b.objectCounts["Foreign Data Wrappers"] = len(wrappers)
+	fdwMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_FOREIGNDATAWRAPPER)
 
 	*sortables = append(*sortables, convertToSortableSlice(wrappers)...)
 	addToMetadataMap(fdwMetadata, metadataMap)
 }
 
-func RetrieveForeignServers(sortables *[]Sortable, metadataMap MetadataMap) {
+func (b *Backup) RetrieveForeignServers(sortables *[]Sortable, metadataMap MetadataMap, tables []Table) []ForeignServer {
 	gplog.Verbose("Writing CREATE SERVER statements to metadata file")
-	servers := GetForeignServers(connectionPool)
-	objectCounts["Foreign Servers"] = len(servers)
-	serverMetadata := GetMetadataForObjectType(connectionPool, TYPE_FOREIGNSERVER)
+	b.SetCurrentObject("foreign servers", "")
+	servers := GetForeignServers(b.connectionPool)
+	if len(b.MustGetFlagStringSlice(utils.INCLUDE_SCHEMA)) > 0 {
+		servers = FilterForeignServersForTables(servers, tables)
+	}
+	b.objectCounts["Foreign Servers"] = len(servers)
+	serverMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_FOREIGNSERVER)
 
 	*sortables = append(*sortables, convertToSortableSlice(servers)...)
 	addToMetadataMap(serverMetadata, metadataMap)
+	return servers
 }
 
-func RetrieveUserMappings(sortables *[]Sortable) {
+func (b *Backup) RetrieveUserMappings(sortables *[]Sortable, servers []ForeignServer) {
 	gplog.Verbose("Writing CREATE USER MAPPING statements to metadata file")
-	mappings := GetUserMappings(connectionPool)
-	objectCounts["User Mappings"] = len(mappings)
+	b.SetCurrentObject("user mappings", "")
+	mappings := GetUserMappings(b.connectionPool)
+	if len(b.MustGetFlagStringSlice(utils.INCLUDE_SCHEMA)) > 0 {
+		mappings = FilterUserMappingsForServers(mappings, servers)
+	}
+	b.objectCounts["User Mappings"] = len(mappings)
 	// No comments, owners, or ACLs on UserMappings so no need to get metadata
 
 	*sortables = append(*sortables, convertToSortableSlice(mappings)...)
 }
 
-func BackupSessionGUCs(metadataFile *utils.FileWithByteCount) {
+func (b *Backup) BackupSessionGUCs(metadataFile *utils.FileWithByteCount) {
 	gplog.Verbose("Writing Session Configuration Parameters to metadata file")
-	gucs := GetSessionGUCs(connectionPool)
-	PrintSessionGUCs(metadataFile, globalTOC, gucs)
+	b.SetCurrentObject("session configuration parameters", "")
+	gucs := b.GetSessionGUCs(b.connectionPool)
+	PrintSessionGUCs(metadataFile, b.globalTOC, gucs)
 }
 
 /*
  * Global metadata wrapper functions
  */
 
-func BackupTablespaces(metadataFile *utils.FileWithByteCount) {
+func (b *Backup) BackupTablespaces(metadataFile *utils.FileWithByteCount) {
+	if b.MustGetFlagBool(utils.NO_TABLESPACES) {
+		gplog.Verbose("Skipping CREATE TABLESPACE statements because --%s was specified", utils.NO_TABLESPACES)
+		return
+	}
 	gplog.Verbose("Writing CREATE TABLESPACE statements to metadata file")
-	tablespaces := GetTablespaces(connectionPool)
-	objectCounts["Tablespaces"] = len(tablespaces)
-	tablespaceMetadata := GetMetadataForObjectType(connectionPool, TYPE_TABLESPACE)
-	PrintCreateTablespaceStatements(metadataFile, globalTOC, tablespaces, tablespaceMetadata)
+	b.SetCurrentObject("tablespaces", "")
+	tablespaces := b.GetTablespaces(b.connectionPool)
+	b.objectCounts["Tablespaces"] = len(tablespaces)
+	tablespaceMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_TABLESPACE)
+	b.PrintCreateTablespaceStatements(metadataFile, b.globalTOC, tablespaces, tablespaceMetadata)
 }
 
-func BackupCreateDatabase(metadataFile *utils.FileWithByteCount) {
+func (b *Backup) BackupCreateDatabase(metadataFile *utils.FileWithByteCount) {
 	gplog.Verbose("Writing CREATE DATABASE statement to metadata file")
-	defaultDB := GetDefaultDatabaseEncodingInfo(connectionPool)
-	db := GetDatabaseInfo(connectionPool)
-	dbMetadata := GetMetadataForObjectType(connectionPool, TYPE_DATABASE)
-	PrintCreateDatabaseStatement(metadataFile, globalTOC, defaultDB, db, dbMetadata)
+	b.SetCurrentObject("database", "")
+	defaultDB := GetDefaultDatabaseEncodingInfo(b.connectionPool)
+	db := GetDatabaseInfo(b.connectionPool)
+	dbMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_DATABASE)
+	b.PrintCreateDatabaseStatement(metadataFile, b.globalTOC, defaultDB, db, dbMetadata)
 }
 
-func BackupDatabaseGUCs(metadataFile *utils.FileWithByteCount) {
+func (b *Backup) BackupDatabaseGUCs(metadataFile *utils.FileWithByteCount) {
 	gplog.Verbose("Writing Database Configuration Parameters to metadata file")
-	databaseGucs := GetDatabaseGUCs(connectionPool)
-	objectCounts["Database GUCs"] = len(databaseGucs)
-	PrintDatabaseGUCs(metadataFile, globalTOC, databaseGucs, connectionPool.DBName)
+	b.SetCurrentObject("database configuration parameters", "")
+	databaseGucs := GetDatabaseGUCs(b.connectionPool)
+	b.objectCounts["Database GUCs"] = len(databaseGucs)
+	/*
+	 * The database name stored in the backup report has already been passed
+	 * through quote_ident, which is required both for the ALTER DATABASE
+	 * statements to be valid and for --redirect-db to find the name on restore.
+	 */
+	PrintDatabaseGUCs(metadataFile, b.globalTOC, databaseGucs, b.backupReport.DatabaseName)
 }
 
-func BackupResourceQueues(metadataFile *utils.FileWithByteCount) {
+func (b *Backup) BackupResourceQueues(metadataFile *utils.FileWithByteCount) {
 	gplog.Verbose("Writing CREATE RESOURCE QUEUE statements to metadata file")
-	resQueues := GetResourceQueues(connectionPool)
-	objectCounts["Resource Queues"] = len(resQueues)
-	resQueueMetadata := GetCommentsForObjectType(connectionPool, TYPE_RESOURCEQUEUE)
-	PrintCreateResourceQueueStatements(metadataFile, globalTOC, resQueues, resQueueMetadata)
+	b.SetCurrentObject("resource queues", "")
+	resQueues := GetResourceQueues(b.connectionPool)
+	b.objectCounts["Resource Queues"] = len(resQueues)
+	resQueueMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_RESOURCEQUEUE)
+	b.PrintCreateResourceQueueStatements(metadataFile, b.globalTOC, resQueues, resQueueMetadata)
 }
 
-func BackupResourceGroups(metadataFile *utils.FileWithByteCount) {
+func (b *Backup) BackupResourceGroups(metadataFile *utils.FileWithByteCount) {
 	gplog.Verbose("Writing CREATE RESOURCE GROUP statements to metadata file")
-	resGroups := GetResourceGroups(connectionPool)
-	objectCounts["Resource Groups"] = len(resGroups)
-	resGroupMetadata := GetCommentsForObjectType(connectionPool, TYPE_RESOURCEGROUP)
-	PrintResetResourceGroupStatements(metadataFile, globalTOC)
-	PrintCreateResourceGroupStatements(metadataFile, globalTOC, resGroups, resGroupMetadata)
+	b.SetCurrentObject("resource groups", "")
+	resGroups := GetResourceGroups(b.connectionPool)
+	b.objectCounts["Resource Groups"] = len(resGroups)
+	resGroupMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_RESOURCEGROUP)
+	if b.connectionPool.Version.Before("7") {
+		PrintResetResourceGroupStatements(metadataFile, b.globalTOC)
+	}
+	b.PrintCreateResourceGroupStatements(metadataFile, b.globalTOC, resGroups, resGroupMetadata)
 }
 
-func BackupRoles(metadataFile *utils.FileWithByteCount) {
+func (b *Backup) BackupRoles(metadataFile *utils.FileWithByteCount) {
 	gplog.Verbose("Writing CREATE ROLE statements to metadata file")
-	roles := GetRoles(connectionPool)
-	objectCounts["Roles"] = len(roles)
-	roleMetadata := GetMetadataForObjectType(connectionPool, TYPE_ROLE)
-	PrintCreateRoleStatements(metadataFile, globalTOC, roles, roleMetadata)
+	b.SetCurrentObject("roles", "")
+	roles := GetRoles(b.connectionPool)
+	b.objectCounts["Roles"] = len(roles)
+	roleMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_ROLE)
+	b.PrintCreateRoleStatements(metadataFile, b.globalTOC, roles, roleMetadata)
 }
 
-func BackupRoleGUCs(metadataFile *utils.FileWithByteCount) {
+func (b *Backup) BackupRoleGUCs(metadataFile *utils.FileWithByteCount) {
 	gplog.Verbose("Writing ROLE Configuration Parameter to meadata file")
-	roleGUCs := GetRoleGUCs(connectionPool)
-	PrintRoleGUCStatements(metadataFile, globalTOC, roleGUCs)
+	b.SetCurrentObject("role configuration parameters", "")
+	roleGUCs := GetRoleGUCs(b.connectionPool)
+	PrintRoleGUCStatements(metadataFile, b.globalTOC, roleGUCs)
 }
 
-func BackupRoleGrants(metadataFile *utils.FileWithByteCount) {
+func (b *Backup) BackupRoleGrants(metadataFile *utils.FileWithByteCount) {
 	gplog.Verbose("Writing GRANT ROLE statements to metadata file")
-	roleMembers := GetRoleMembers(connectionPool)
-	PrintRoleMembershipStatements(metadataFile, globalTOC, roleMembers)
+	b.SetCurrentObject("role grants", "")
+	roleMembers := GetRoleMembers(b.connectionPool)
+	PrintRoleMembershipStatements(metadataFile, b.globalTOC, roleMembers)
 }
 
 /*
  * Predata wrapper functions
  */
 
-func BackupSchemas(metadataFile *utils.FileWithByteCount) {
+func (b *Backup) BackupSchemas(metadataFile *utils.FileWithByteCount) {
 	gplog.Verbose("Writing CREATE SCHEMA statements to metadata file")
-	schemas := GetAllUserSchemas(connectionPool)
-	objectCounts["Schemas"] = len(schemas)
-	schemaMetadata := GetMetadataForObjectType(connectionPool, TYPE_SCHEMA)
-	PrintCreateSchemaStatements(metadataFile, globalTOC, schemas, schemaMetadata)
+	b.SetCurrentObject("schemas", "")
+	schemas := b.GetAllUserSchemas(b.connectionPool)
+	b.objectCounts["Schemas"] = len(schemas)
+	schemaMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_SCHEMA)
+	b.PrintCreateSchemaStatements(metadataFile, b.globalTOC, schemas, schemaMetadata)
 }
 
-func BackupProceduralLanguages(metadataFile *utils.FileWithByteCount, procLangs []ProceduralLanguage, langFuncs []Function, functionMetadata MetadataMap, funcInfoMap map[uint32]FunctionInfo) {
+func (b *Backup) BackupProceduralLanguages(metadataFile *utils.FileWithByteCount, procLangs []ProceduralLanguage, langFuncs []Function, functionMetadata MetadataMap, funcInfoMap map[uint32]FunctionInfo) {
 	gplog.Verbose("Writing CREATE PROCEDURAL LANGUAGE statements to metadata file")
-	objectCounts["Procedural Languages"] = len(procLangs)
+	b.SetCurrentObject("procedural languages", "")
+	b.objectCounts["Procedural Languages"] = len(procLangs)
 	for _, langFunc := range langFuncs {
-		PrintCreateFunctionStatement(metadataFile, globalTOC, langFunc, functionMetadata[langFunc.GetUniqueID()])
+		b.PrintCreateFunctionStatement(metadataFile, b.globalTOC, langFunc, functionMetadata[langFunc.GetUniqueID()])
 	}
-	procLangMetadata := GetMetadataForObjectType(connectionPool, TYPE_PROCLANGUAGE)
-	PrintCreateLanguageStatements(metadataFile, globalTOC, procLangs, funcInfoMap, procLangMetadata)
+	procLangMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_PROCLANGUAGE)
+	b.PrintCreateLanguageStatements(metadataFile, b.globalTOC, procLangs, funcInfoMap, procLangMetadata)
 }
 
-func BackupShellTypes(metadataFile *utils.FileWithByteCount, shellTypes []ShellType, baseTypes []BaseType, rangeTypes []RangeType) {
+func (b *Backup) BackupShellTypes(metadataFile *utils.FileWithByteCount, shellTypes []ShellType, baseTypes []BaseType, rangeTypes []RangeType) {
 	gplog.Verbose("Writing CREATE TYPE statements for shell types to metadata file")
-	PrintCreateShellTypeStatements(metadataFile, globalTOC, shellTypes, baseTypes, rangeTypes)
+	b.SetCurrentObject("shell types", "")
+	PrintCreateShellTypeStatements(metadataFile, b.globalTOC, shellTypes, baseTypes, rangeTypes)
 }
 
-func BackupEnumTypes(metadataFile *utils.FileWithByteCount, typeMetadata MetadataMap) {
+func (b *Backup) BackupEnumTypes(metadataFile *utils.FileWithByteCount, typeMetadata MetadataMap) {
 	gplog.Verbose("Writing CREATE TYPE statements for enum types to metadata file")
-	enums := GetEnumTypes(connectionPool)
-	objectCounts["Types"] += len(enums)
-	PrintCreateEnumTypeStatements(metadataFile, globalTOC, enums, typeMetadata)
+	b.SetCurrentObject("enum types", "")
+	enums := b.GetEnumTypes(b.connectionPool)
+	b.objectCounts["Types"] += len(enums)
+	b.PrintCreateEnumTypeStatements(metadataFile, b.globalTOC, enums, typeMetadata)
 }
 
-func BackupCreateSequences(metadataFile *utils.FileWithByteCount, sequences []Sequence, relationMetadata MetadataMap) {
+func (b *Backup) BackupCreateSequences(metadataFile *utils.FileWithByteCount, sequences []Sequence, relationMetadata MetadataMap) {
 	gplog.Verbose("Writing CREATE SEQUENCE statements to metadata file")
-	objectCounts["Sequences"] = len(sequences)
-	PrintCreateSequenceStatements(metadataFile, globalTOC, sequences, relationMetadata)
+	b.SetCurrentObject("sequences", "")
+	b.objectCounts["Sequences"] = len(sequences)
+	b.PrintCreateSequenceStatements(metadataFile, b.globalTOC, sequences, relationMetadata)
 }
 
 func createBackupSet(objSlice []Sortable) (backupSet map[UniqueID]bool) {
@@ -514,122 +855,258 @@ func addToMetadataMap(newMetadata MetadataMap, metadataMap MetadataMap) {
 }
 
 // This function is fairly unwieldy, but there's not really a good way to break it down
-func BackupDependentObjects(metadataFile *utils.FileWithByteCount, tables []Table,
+func (b *Backup) BackupDependentObjects(metadataFile *utils.FileWithByteCount, tables []Table,
 	protocols []ExternalProtocol, filteredMetadata MetadataMap,
 	constraints []Constraint, sortables []Sortable, funcInfoMap map[uint32]FunctionInfo,
 	tableOnly bool) {
 
 	gplog.Verbose("Writing CREATE statements for dependent objects to metadata file")
+	b.SetCurrentObject("dependent objects", "")
 
 	backupSet := createBackupSet(sortables)
-	relevantDeps := GetDependencies(connectionPool, backupSet)
-	if connectionPool.Version.Is("4") && !tableOnly {
+	relevantDeps := GetDependencies(b.connectionPool, backupSet)
+	if b.connectionPool.Version.Is("4") && !tableOnly {
 		AddProtocolDependenciesForGPDB4(relevantDeps, tables, protocols)
 	}
+	if b.MustGetFlagBool(utils.DETERMINISTIC) {
+		SortObjectsByName(sortables)
+	}
 	sortedSlice := TopologicalSort(sortables, relevantDeps)
 
-	PrintDependentObjectStatements(metadataFile, globalTOC, sortedSlice, filteredMetadata, constraints, funcInfoMap)
-	extPartInfo, partInfoMap := GetExternalPartitionInfo(connectionPool)
+	b.PrintDependentObjectStatements(metadataFile, b.globalTOC, sortedSlice, filteredMetadata, constraints, funcInfoMap)
+	extPartInfo, partInfoMap := GetExternalPartitionInfo(b.connectionPool)
 	if len(extPartInfo) > 0 {
 		gplog.Verbose("Writing EXCHANGE PARTITION statements to metadata file")
-		PrintExchangeExternalPartitionStatements(metadataFile, globalTOC, extPartInfo, partInfoMap, tables)
+		PrintExchangeExternalPartitionStatements(metadataFile, b.globalTOC, extPartInfo, partInfoMap, tables)
 	}
 }
 
-func BackupConversions(metadataFile *utils.FileWithByteCount) {
+func (b *Backup) BackupConversions(metadataFile *utils.FileWithByteCount) {
 	gplog.Verbose("Writing CREATE CONVERSION statements to metadata file")
-	conversions := GetConversions(connectionPool)
-	objectCounts["Conversions"] = len(conversions)
-	convMetadata := GetMetadataForObjectType(connectionPool, TYPE_CONVERSION)
-	PrintCreateConversionStatements(metadataFile, globalTOC, conversions, convMetadata)
+	b.SetCurrentObject("conversions", "")
+	conversions := b.GetConversions(b.connectionPool)
+	b.objectCounts["Conversions"] = len(conversions)
+	convMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_CONVERSION)
+	b.PrintCreateConversionStatements(metadataFile, b.globalTOC, conversions, convMetadata)
 }
 
-func BackupOperatorFamilies(metadataFile *utils.FileWithByteCount) {
+func (b *Backup) BackupOperatorFamilies(metadataFile *utils.FileWithByteCount) {
 	gplog.Verbose("Writing CREATE OPERATOR FAMILY statements to metadata file")
-	operatorFamilies := GetOperatorFamilies(connectionPool)
-	objectCounts["Operator Families"] = len(operatorFamilies)
-	operatorFamilyMetadata := GetMetadataForObjectType(connectionPool, TYPE_OPERATORFAMILY)
-	PrintCreateOperatorFamilyStatements(metadataFile, globalTOC, operatorFamilies, operatorFamilyMetadata)
+	b.SetCurrentObject("operator families", "")
+	operatorFamilies := b.GetOperatorFamilies(b.connectionPool)
+	b.objectCounts["Operator Families"] = len(operatorFamilies)
+	operatorFamilyMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_OPERATORFAMILY)
+	b.PrintCreateOperatorFamilyStatements(metadataFile, b.globalTOC, operatorFamilies, operatorFamilyMetadata)
+}
+
+func (b *Backup) BackupOperatorFamilyMembers(metadataFile *utils.FileWithByteCount) {
+	gplog.Verbose("Writing ALTER OPERATOR FAMILY statements to metadata file")
+	b.SetCurrentObject("operator family members", "")
+	members := b.GetOperatorFamilyMembers(b.connectionPool)
+	PrintAlterOperatorFamilyStatements(metadataFile, b.globalTOC, members)
 }
 
-func BackupCollations(metadataFile *utils.FileWithByteCount) {
+func (b *Backup) BackupCollations(metadataFile *utils.FileWithByteCount) {
 	gplog.Verbose("Writing CREATE COLLATION statements to metadata file")
-	collations := GetCollations(connectionPool)
-	objectCounts["Collations"] = len(collations)
-	collationMetadata := GetMetadataForObjectType(connectionPool, TYPE_COLLATION)
-	PrintCreateCollationStatements(metadataFile, globalTOC, collations, collationMetadata)
+	b.SetCurrentObject("collations", "")
+	collations := b.GetCollations(b.connectionPool)
+	b.objectCounts["Collations"] = len(collations)
+	collationMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_COLLATION)
+	b.PrintCreateCollationStatements(metadataFile, b.globalTOC, collations, collationMetadata)
 }
 
-func BackupExtensions(metadataFile *utils.FileWithByteCount) {
+func (b *Backup) BackupExtensions(metadataFile *utils.FileWithByteCount) {
 	gplog.Verbose("Writing CREATE EXTENSION statements to metadata file")
-	extensions := GetExtensions(connectionPool)
-	objectCounts["Extensions"] = len(extensions)
-	extensionMetadata := GetCommentsForObjectType(connectionPool, TYPE_EXTENSION)
-	PrintCreateExtensionStatements(metadataFile, globalTOC, extensions, extensionMetadata)
+	b.SetCurrentObject("extensions", "")
+	extensions := GetExtensions(b.connectionPool)
+	b.objectCounts["Extensions"] = len(extensions)
+	extensionMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_EXTENSION)
+	b.PrintCreateExtensionStatements(metadataFile, b.globalTOC, extensions, extensionMetadata)
 }
 
-func BackupConstraints(metadataFile *utils.FileWithByteCount, constraints []Constraint, conMetadata MetadataMap) {
+func (b *Backup) BackupConstraints(metadataFile *utils.FileWithByteCount, constraints []Constraint, conMetadata MetadataMap) {
 	gplog.Verbose("Writing ADD CONSTRAINT statements to metadata file")
-	objectCounts["Constraints"] = len(constraints)
-	PrintConstraintStatements(metadataFile, globalTOC, constraints, conMetadata)
+	b.SetCurrentObject("constraints", "")
+	b.objectCounts["Constraints"] = len(constraints)
+	b.PrintConstraintStatements(metadataFile, b.globalTOC, constraints, conMetadata)
 }
 
 /*
  * Postdata wrapper functions
  */
 
-func BackupIndexes(metadataFile *utils.FileWithByteCount) {
+func (b *Backup) BackupIndexes(metadataFile *utils.FileWithByteCount) {
 	gplog.Verbose("Writing CREATE INDEX statements to metadata file")
-	indexes := GetIndexes(connectionPool)
-	objectCounts["Indexes"] = len(indexes)
-	indexMetadata := GetCommentsForObjectType(connectionPool, TYPE_INDEX)
-	PrintCreateIndexStatements(metadataFile, globalTOC, indexes, indexMetadata)
+	b.SetCurrentObject("indexes", "")
+	indexes := b.GetIndexes(b.connectionPool)
+	b.objectCounts["Indexes"] = len(indexes)
+	indexMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_INDEX)
+	b.PrintCreateIndexStatements(metadataFile, b.globalTOC, indexes, indexMetadata)
 }
 
-func BackupRules(metadataFile *utils.FileWithByteCount) {
+func (b *Backup) BackupRules(metadataFile *utils.FileWithByteCount) {
 	gplog.Verbose("Writing CREATE RULE statements to metadata file")
-	rules := GetRules(connectionPool)
-	objectCounts["Rules"] = len(rules)
-	ruleMetadata := GetCommentsForObjectType(connectionPool, TYPE_RULE)
-	PrintCreateRuleStatements(metadataFile, globalTOC, rules, ruleMetadata)
+	b.SetCurrentObject("rules", "")
+	rules := b.GetRules(b.connectionPool)
+	b.objectCounts["Rules"] = len(rules)
+	ruleMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_RULE)
+	b.PrintCreateRuleStatements(metadataFile, b.globalTOC, rules, ruleMetadata)
 }
 
-func BackupTriggers(metadataFile *utils.FileWithByteCount) {
+func (b *Backup) BackupTriggers(metadataFile *utils.FileWithByteCount) {
 	gplog.Verbose("Writing CREATE TRIGGER statements to metadata file")
-	triggers := GetTriggers(connectionPool)
-	objectCounts["Triggers"] = len(triggers)
-	triggerMetadata := GetCommentsForObjectType(connectionPool, TYPE_TRIGGER)
-	PrintCreateTriggerStatements(metadataFile, globalTOC, triggers, triggerMetadata)
+	b.SetCurrentObject("triggers", "")
+	triggers := b.GetTriggers(b.connectionPool)
+	b.objectCounts["Triggers"] = len(triggers)
+	triggerMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_TRIGGER)
+	b.PrintCreateTriggerStatements(metadataFile, b.globalTOC, triggers, triggerMetadata)
+}
+
+func (b *Backup) BackupRowLevelSecurity(metadataFile *utils.FileWithByteCount) {
+	gplog.Verbose("Writing ROW LEVEL SECURITY and CREATE POLICY statements to metadata file")
+	b.SetCurrentObject("row level security policies", "")
+	rlsTables := b.GetRowLevelSecurityTables(b.connectionPool)
+	PrintRowLevelSecurityStatements(metadataFile, b.globalTOC, rlsTables)
+	policies := b.GetRowLevelSecurityPolicies(b.connectionPool)
+	b.objectCounts["Policies"] = len(policies)
+	policyMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_POLICY)
+	b.PrintCreatePolicyStatements(metadataFile, b.globalTOC, policies, policyMetadata)
 }
 
-func BackupEventTriggers(metadataFile *utils.FileWithByteCount) {
+func (b *Backup) BackupExtendedStatistics(metadataFile *utils.FileWithByteCount) {
+	gplog.Verbose("Writing CREATE STATISTICS statements to metadata file")
+	b.SetCurrentObject("extended statistics", "")
+	statistics := b.GetExtendedStatistics(b.connectionPool)
+	b.objectCounts["Extended Statistics"] = len(statistics)
+	statisticsMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_STATISTIC_EXT)
+	b.PrintCreateExtendedStatisticsStatements(metadataFile, b.globalTOC, statistics, statisticsMetadata)
+}
+
+func (b *Backup) BackupEventTriggers(metadataFile *utils.FileWithByteCount) {
 	gplog.Verbose("Writing CREATE EVENT TRIGGER statements to metadata file")
-	eventTriggers := GetEventTriggers(connectionPool)
-	objectCounts["Event Triggers"] = len(eventTriggers)
-	eventTriggerMetadata := GetMetadataForObjectType(connectionPool, TYPE_EVENTTRIGGER)
-	PrintCreateEventTriggerStatements(metadataFile, globalTOC, eventTriggers, eventTriggerMetadata)
+	b.SetCurrentObject("event triggers", "")
+	eventTriggers := GetEventTriggers(b.connectionPool)
+	b.objectCounts["Event Triggers"] = len(eventTriggers)
+	eventTriggerMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_EVENTTRIGGER)
+	b.PrintCreateEventTriggerStatements(metadataFile, b.globalTOC, eventTriggers, eventTriggerMetadata)
 }
 
-func BackupDefaultPrivileges(metadataFile *utils.FileWithByteCount) {
+func (b *Backup) BackupDefaultPrivileges(metadataFile *utils.FileWithByteCount) {
 	gplog.Verbose("Writing ALTER DEFAULT PRIVILEGES statements to metadata file")
-	defaultPrivileges := GetDefaultPrivileges(connectionPool)
-	objectCounts["DEFAULT PRIVILEGES"] = len(defaultPrivileges)
-	PrintDefaultPrivilegesStatements(metadataFile, globalTOC, defaultPrivileges)
+	b.SetCurrentObject("default privileges", "")
+	defaultPrivileges := b.GetDefaultPrivileges(b.connectionPool)
+	b.objectCounts["DEFAULT PRIVILEGES"] = len(defaultPrivileges)
+	PrintDefaultPrivilegesStatements(metadataFile, b.globalTOC, defaultPrivileges)
 }
 
 /*
  * Data wrapper functions
  */
 
-func BackupStatistics(statisticsFile *utils.FileWithByteCount, tables []Table) {
-	attStats := GetAttributeStatistics(connectionPool, tables)
-	tupleStats := GetTupleStatistics(connectionPool, tables)
+func (b *Backup) BackupStatistics(statisticsFile *utils.FileWithByteCount, tables []Table) {
+	b.SetCurrentObject("statistics", "")
+	attStats := b.GetAttributeStatistics(b.connectionPool, tables)
+	tupleStats := b.GetTupleStatistics(b.connectionPool, tables)
+
+	b.BackupSessionGUCs(statisticsFile)
+	if b.connectionPool.Version.AtLeast("7") {
+		PrintExtendedStatisticsAnalyzeStatements(statisticsFile, b.globalTOC, b.GetExtendedStatistics(b.connectionPool))
+	}
+	b.PrintStatisticsStatements(statisticsFile, b.globalTOC, tables, attStats, tupleStats)
+}
 
-	BackupSessionGUCs(statisticsFile)
-	PrintStatisticsStatements(statisticsFile, globalTOC, tables, attStats, tupleStats)
+/*
+ * Large objects are written in chunks so that no more than one chunk of a
+ * large object is held in memory at a time.
+ */
+func (b *Backup) BackupLargeObjects(largeObjectsFile *utils.FileWithByteCount) {
+	b.SetCurrentObject("large objects", "")
+	largeObjects := GetLargeObjects(b.connectionPool)
+	b.objectCounts["Large Objects"] = len(largeObjects)
+	largeObjectMetadata := b.GetMetadataForObjectType(b.connectionPool, TYPE_LARGEOBJECT)
+
+	for _, largeObject := range largeObjects {
+		if b.wasTerminated {
+			return
+		}
+		b.PrintCreateLargeObjectStatement(largeObjectsFile, b.globalTOC, largeObject, largeObjectMetadata[largeObject.GetUniqueID()])
+		for offset := int64(0); ; offset += LARGE_OBJECT_CHUNK_SIZE {
+			data := GetLargeObjectChunk(b.connectionPool, largeObject.Oid, offset)
+			if len(data) > 0 {
+				PrintLargeObjectDataStatement(largeObjectsFile, b.globalTOC, largeObject, offset, data)
+			}
+			if len(data) < LARGE_OBJECT_CHUNK_SIZE {
+				break
+			}
+		}
+	}
 }
 
-func BackupIncrementalMetadata() {
-	aoTableEntries := GetAOIncrementalMetadata(connectionPool)
-	globalTOC.IncrementalMetadata.AO = aoTableEntries
+func (b *Backup) BackupIncrementalMetadata() {
+	b.SetCurrentObject("incremental metadata", "")
+	aoTableEntries := b.GetAOIncrementalMetadata(b.connectionPool)
+	b.globalTOC.IncrementalMetadata.AO = aoTableEntries
+}
+
+/*
+ * Links the data files of AO tables that are unchanged since the last matching
+ * backup into this backup, using a reflink where the filesystem supports it and
+ * a hard link otherwise, and returns the tables whose data must still be copied.
+ */
+func (b *Backup) LinkUnchangedTableData(dataTables []Table) []Table {
+	var lastBackupConfig *backup_history.BackupConfig
+	if iohelper.FileExistsAndIsReadable(b.globalFPInfo.GetBackupHistoryFilePath()) {
+		history, err := backup_history.NewHistory(b.globalFPInfo.GetBackupHistoryFilePath())
+		gplog.FatalOnError(err)
+		lastBackupConfig = b.GetLatestBackupConfigForLinking(history, &b.backupReport.BackupConfig)
+	}
+	if lastBackupConfig == nil {
+		gplog.Info("No matching previous backup found to link unchanged table data from")
+		return dataTables
+	}
+	gplog.Info("Linking unchanged table data from backup with timestamp = %s", lastBackupConfig.Timestamp)
+
+	lastBackupFPInfo := backup_filepath.NewFilePathInfo(b.globalCluster, b.globalFPInfo.UserSpecifiedBackupDir,
+		lastBackupConfig.Timestamp, b.globalFPInfo.UserSpecifiedSegPrefix)
+	lastBackupTOC := utils.NewTOC(lastBackupFPInfo.GetTOCFilePath())
+	tablesToCopy, tablesToLink := FilterTablesForLinking(lastBackupTOC, b.globalTOC, lastBackupConfig.RestorePlan, dataTables)
+	if len(tablesToLink) == 0 {
+		return tablesToCopy
+	}
+
+	sourceFPInfos := make(map[string]backup_filepath.FilePathInfo)
+	rowsCopiedMap := make(map[uint32]int64)
+	for _, table := range tablesToLink {
+		if _, ok := sourceFPInfos[table.SourceTimestamp]; ok {
+			continue
+		}
+		sourceFPInfo := backup_filepath.NewFilePathInfo(b.globalCluster, b.globalFPInfo.UserSpecifiedBackupDir,
+			table.SourceTimestamp, b.globalFPInfo.UserSpecifiedSegPrefix)
+		sourceFPInfos[table.SourceTimestamp] = sourceFPInfo
+		for _, entry := range utils.NewTOC(sourceFPInfo.GetTOCFilePath()).DataEntries {
+			rowsCopiedMap[entry.Oid] = entry.RowsCopied
+		}
+	}
+
+	extension := utils.GetPipeThroughProgram().Extension
+	remoteOutput := b.globalCluster.GenerateAndExecuteCommand("Linking unchanged table data files", func(contentID int) string {
+		linkCommands := make([]string, 0, len(tablesToLink))
+		for _, table := range tablesToLink {
+			sourceFPInfo := sourceFPInfos[table.SourceTimestamp]
+			source := sourceFPInfo.GetTableBackupFilePath(contentID, table.Oid, extension, false)
+			destination := b.globalFPInfo.GetTableBackupFilePath(contentID, table.Oid, extension, false)
+			linkCommands = append(linkCommands, fmt.Sprintf("(cp --reflink=always %s %s 2>/dev/null || ln %s %s)", source, destination, source, destination))
+		}
+		return strings.Join(linkCommands, " && ")
+	}, cluster.ON_SEGMENTS)
+	b.globalCluster.CheckClusterError(remoteOutput, "Unable to link unchanged table data files", func(contentID int) string {
+		return "Unable to link unchanged table data files"
+	})
+
+	for _, table := range tablesToLink {
+		attributes := ConstructTableAttributesList(table.ColumnDefs)
+		b.globalTOC.AddMasterDataEntry(table.Schema, table.Name, table.Oid, attributes, rowsCopiedMap[table.Oid], table.PartitionLevelInfo.RootName)
+	}
+	gplog.Info("Linked data for %d unchanged table(s), backing up data for %d table(s)", len(tablesToLink), len(tablesToCopy))
+	return tablesToCopy
 }
@@ -100,11 +100,16 @@ var metadataFilenameMap = map[string]string{
 	"config":                "config.yaml",
 	"metadata":              "metadata.sql",
 	"statistics":            "statistics.sql",
+	"large_objects":         "large_objects.sql",
 	"table of contents":     "toc.yaml",
 	"report":                "report",
 	"plugin_config":         "plugin_config.yaml",
 	"error_tables_metadata": "error_tables_metadata",
 	"error_tables_data":     "error_tables_data",
+	"manifest":              "manifest.yaml",
+	"options":               "options.yaml",
+	"restore_state":         "restore_state",
+	"split_metadata":        "metadata",
 }
 
 func (backupFPInfo *FilePathInfo) GetBackupFilePath(filetype string) string {
@@ -120,14 +125,57 @@ func (backupFPInfo *FilePathInfo) GetMetadataFilePath() string {
 	return backupFPInfo.GetBackupFilePath("metadata")
 }
 
+/*
+ * The directory holding the metadata split by object type or schema, which
+ * is written in addition to the metadata file used by gprestore.
+ */
+func (backupFPInfo *FilePathInfo) GetSplitMetadataDirPath() string {
+	return backupFPInfo.GetBackupFilePath("split_metadata")
+}
+
 func (backupFPInfo *FilePathInfo) GetStatisticsFilePath() string {
 	return backupFPInfo.GetBackupFilePath("statistics")
 }
 
+func (backupFPInfo *FilePathInfo) GetLargeObjectsFilePath() string {
+	return backupFPInfo.GetBackupFilePath("large_objects")
+}
+
 func (backupFPInfo *FilePathInfo) GetTOCFilePath() string {
 	return backupFPInfo.GetBackupFilePath("table of contents")
 }
 
+/*
+ * The manifest records the size and checksum of each data file written to the
+ * segments, so that "gpbackup verify" can detect files that have changed.
+ */
+func (backupFPInfo *FilePathInfo) GetManifestFilePath() string {
+	return backupFPInfo.GetBackupFilePath("manifest")
+}
+
+/*
+ * The options file records the effective value of every flag the backup was
+ * taken with, along with the versions of gpbackup and the database.
+ */
+func (backupFPInfo *FilePathInfo) GetOptionsFilePath() string {
+	return backupFPInfo.GetBackupFilePath("options")
+}
+
+const (
+	MARKER_IN_PROGRESS = "in_progress"
+	MARKER_COMPLETE    = "complete"
+)
+
+/*
+ * Every backup directory holds an in-progress marker while the backup is
+ * being taken, which is replaced with a completion marker once the whole
+ * backup has succeeded, so a directory without a completion marker holds
+ * the files of a backup that failed or is still running.
+ */
+func (backupFPInfo *FilePathInfo) GetBackupMarkerFilePath(contentID int, marker string) string {
+	return path.Join(backupFPInfo.GetDirForContent(contentID), fmt.Sprintf("gpbackup_%s_%s", backupFPInfo.Timestamp, marker))
+}
+
 func (backupFPInfo *FilePathInfo) GetBackupReportFilePath() string {
 	return backupFPInfo.GetBackupFilePath("report")
 }
@@ -148,6 +196,14 @@ func (backupFPInfo *FilePathInfo) GetErrorTablesDataFilePath(restoreTimestamp st
 	return backupFPInfo.GetRestoreFilePath(restoreTimestamp, "error_tables_data")
 }
 
+/*
+ * Unlike other restore files, the restore state file is not specific to one
+ * restore, so that a later restore of the same backup can resume from it.
+ */
+func (backupFPInfo *FilePathInfo) GetRestoreStateFilePath() string {
+	return path.Join(backupFPInfo.GetDirForContent(-1), fmt.Sprintf("gprestore_%s_%s", backupFPInfo.Timestamp, metadataFilenameMap["restore_state"]))
+}
+
 func (backupFPInfo *FilePathInfo) GetConfigFilePath() string {
 	return backupFPInfo.GetBackupFilePath("config")
 }
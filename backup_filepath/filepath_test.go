@@ -92,6 +92,46 @@ var _ = Describe("filepath tests", func() {
 			Expect(fpInfo.GetBackupReportFilePath()).To(Equal("/foo/bar/gpseg-1/backups/20170101/20170101010101/gpbackup_20170101010101_report"))
 		})
 	})
+	Describe("GetRestoreStateFilePath", func() {
+		It("returns restore state file path", func() {
+			fpInfo := backup_filepath.NewFilePathInfo(c, "", "20170101010101", "gpseg")
+			Expect(fpInfo.GetRestoreStateFilePath()).To(Equal("/data/gpseg-1/backups/20170101/20170101010101/gprestore_20170101010101_restore_state"))
+		})
+	})
+	Describe("GetSplitMetadataDirPath", func() {
+		It("returns split metadata directory path", func() {
+			fpInfo := backup_filepath.NewFilePathInfo(c, "", "20170101010101", "gpseg")
+			Expect(fpInfo.GetSplitMetadataDirPath()).To(Equal("/data/gpseg-1/backups/20170101/20170101010101/gpbackup_20170101010101_metadata"))
+		})
+	})
+	Describe("GetManifestFilePath", func() {
+		It("returns manifest file path", func() {
+			fpInfo := backup_filepath.NewFilePathInfo(c, "", "20170101010101", "gpseg")
+			Expect(fpInfo.GetManifestFilePath()).To(Equal("/data/gpseg-1/backups/20170101/20170101010101/gpbackup_20170101010101_manifest.yaml"))
+		})
+	})
+	Describe("GetLargeObjectsFilePath", func() {
+		It("returns large objects file path", func() {
+			fpInfo := backup_filepath.NewFilePathInfo(c, "", "20170101010101", "gpseg")
+			Expect(fpInfo.GetLargeObjectsFilePath()).To(Equal("/data/gpseg-1/backups/20170101/20170101010101/gpbackup_20170101010101_large_objects.sql"))
+		})
+	})
+	Describe("GetOptionsFilePath", func() {
+		It("returns options file path", func() {
+			fpInfo := backup_filepath.NewFilePathInfo(c, "", "20170101010101", "gpseg")
+			Expect(fpInfo.GetOptionsFilePath()).To(Equal("/data/gpseg-1/backups/20170101/20170101010101/gpbackup_20170101010101_options.yaml"))
+		})
+	})
+	Describe("GetBackupMarkerFilePath", func() {
+		It("returns marker file path for the master", func() {
+			fpInfo := backup_filepath.NewFilePathInfo(c, "", "20170101010101", "gpseg")
+			Expect(fpInfo.GetBackupMarkerFilePath(-1, backup_filepath.MARKER_COMPLETE)).To(Equal("/data/gpseg-1/backups/20170101/20170101010101/gpbackup_20170101010101_complete"))
+		})
+		It("returns marker file path based on user specified path", func() {
+			fpInfo := backup_filepath.NewFilePathInfo(c, "/foo/bar", "20170101010101", "gpseg")
+			Expect(fpInfo.GetBackupMarkerFilePath(-1, backup_filepath.MARKER_IN_PROGRESS)).To(Equal("/foo/bar/gpseg-1/backups/20170101/20170101010101/gpbackup_20170101010101_in_progress"))
+		})
+	})
 	Describe("GetTableBackupFilePath", func() {
 		It("returns table file path", func() {
 			fpInfo := backup_filepath.NewFilePathInfo(c, "", "20170101010101", "gpseg")
@@ -3,6 +3,7 @@ package backup_history
 //TODO: change package name to conform to Go standards
 
 import (
+	"fmt"
 	"sort"
 	"time"
 
@@ -22,30 +23,86 @@ type BackupConfig struct {
 	BackupDir             string
 	BackupVersion         string
 	Compressed            bool
+	CopyDelimiter         string
+	CopyFormat            string
+	CopyHeader            bool
+	CopyNullString        string
 	DatabaseName          string
 	DatabaseVersion       string
+	DataFiltered          bool
+	DataMasked            bool
 	DataOnly              bool
 	DateDeleted           string
+	ExcludeColumns        []string
+	ExcludedArtifacts     []string
+	ExcludeLeafPartitions []string
 	ExcludeRelations      []string
 	ExcludeSchemaFiltered bool
 	ExcludeSchemas        []string
 	ExcludeTableFiltered  bool
+	IncludeLeafPartitions []string
 	IncludeRelations      []string
 	IncludeSchemaFiltered bool
 	IncludeSchemas        []string
 	IncludeTableFiltered  bool
 	Incremental           bool
+	MetadataCompressed    bool
+	MetadataEncrypted     bool
 	LeafPartitionData     bool
+	LeafPartitionKeyEnd   string
+	LeafPartitionKeyStart string
 	MetadataOnly          bool
+	ParquetExport         bool
 	Plugin                string
 	PluginVersion         string
 	RestorePlan           []RestorePlanEntry
+	SegmentCount          int
 	SingleDataFile        bool
+	Target                string
 	Timestamp             string
 	EndTime               string
+	WithExternalData      bool
+	WithLargeObjects      bool
 	WithStatistics        bool
 }
 
+/*
+ * Returns the filters the backup was taken with, in the form of the flags
+ * that set them.  Internal artifacts excluded by default are not filters, as
+ * a backup without them still contains the whole database.
+ */
+func (config *BackupConfig) GetFilters() []string {
+	artifacts := make(map[string]bool, len(config.ExcludedArtifacts))
+	for _, artifact := range config.ExcludedArtifacts {
+		artifacts[artifact] = true
+	}
+	filters := make([]string, 0)
+	addFilters := func(flagName string, values []string) {
+		for _, value := range values {
+			if !artifacts[value] {
+				filters = append(filters, fmt.Sprintf("--%s %s", flagName, value))
+			}
+		}
+	}
+	addFilters("include-schema", config.IncludeSchemas)
+	addFilters("include-table", config.IncludeRelations)
+	addFilters("exclude-schema", config.ExcludeSchemas)
+	addFilters("exclude-table", config.ExcludeRelations)
+	addFilters("exclude-leaf-partition", config.ExcludeLeafPartitions)
+	addFilters("include-leaf-partition", config.IncludeLeafPartitions)
+	if config.LeafPartitionKeyStart != "" {
+		filters = append(filters, fmt.Sprintf("--leaf-partition-key-start %s", config.LeafPartitionKeyStart))
+	}
+	if config.LeafPartitionKeyEnd != "" {
+		filters = append(filters, fmt.Sprintf("--leaf-partition-key-end %s", config.LeafPartitionKeyEnd))
+	}
+	return filters
+}
+
+func (config *BackupConfig) IsFiltered() bool {
+	return len(config.GetFilters()) > 0
+}
+
 func ReadConfigFile(filename string) *BackupConfig {
 	config := &BackupConfig{}
 	contents, err := operating.System.ReadFile(filename)
@@ -173,11 +230,27 @@ func (history *History) WriteToFileAndMakeReadOnly(filename string) error {
 	return nil
 }
 
-func (history *History) FindBackupConfig(timestamp string) *BackupConfig {
+/*
+ * The backups of the databases of an --all-databases backup share a timestamp,
+ * so a backup is found by its timestamp and the name of its database, as
+ * recorded in its configuration.  An empty name matches a backup of any
+ * database.
+ */
+func (history *History) FindBackupConfig(timestamp string, dbName string) *BackupConfig {
 	for _, backupConfig := range history.BackupConfigs {
-		if backupConfig.Timestamp == timestamp {
+		if backupConfig.Timestamp == timestamp && (dbName == "" || backupConfig.DatabaseName == dbName) {
 			return &backupConfig
 		}
 	}
 	return nil
 }
+
+func (history *History) FindBackupConfigs(timestamp string) []BackupConfig {
+	backupConfigs := make([]BackupConfig, 0)
+	for _, backupConfig := range history.BackupConfigs {
+		if backupConfig.Timestamp == timestamp {
+			backupConfigs = append(backupConfigs, backupConfig)
+		}
+	}
+	return backupConfigs
+}
@@ -9,10 +9,7 @@ import (
 	"github.com/greenplum-db/gp-common-go-libs/iohelper"
 	"github.com/greenplum-db/gp-common-go-libs/operating"
 	"github.com/greenplum-db/gp-common-go-libs/structmatcher"
-	"github.com/greenplum-db/gpbackup/backup"
-	"github.com/greenplum-db/gpbackup/backup_filepath"
 	"github.com/greenplum-db/gpbackup/backup_history"
-	"github.com/greenplum-db/gpbackup/utils"
 	"github.com/onsi/gomega/gbytes"
 	"gopkg.in/yaml.v2"
 
@@ -58,6 +55,37 @@ var _ = Describe("backup/history tests", func() {
 	AfterEach(func() {
 		_ = os.Remove(historyFilePath)
 	})
+	Describe("GetFilters", func() {
+		It("returns the filters the backup was taken with", func() {
+			config := backup_history.BackupConfig{
+				IncludeSchemas:        []string{"public"},
+				ExcludeRelations:      []string{"public.foo"},
+				ExcludeLeafPartitions: []string{"public.sales_1_prt_jan"},
+			}
+
+			Expect(config.GetFilters()).To(Equal([]string{"--include-schema public", "--exclude-table public.foo", "--exclude-leaf-partition public.sales_1_prt_jan"}))
+			Expect(config.IsFiltered()).To(BeTrue())
+		})
+		It("returns the leaf partitions selected by name and by partition key range", func() {
+			config := backup_history.BackupConfig{
+				IncludeLeafPartitions: []string{"public.sales_1_prt_jan"},
+				LeafPartitionKeyStart: "2023-01-01",
+				LeafPartitionKeyEnd:   "2024-01-01",
+			}
+
+			Expect(config.GetFilters()).To(Equal([]string{"--include-leaf-partition public.sales_1_prt_jan", "--leaf-partition-key-start 2023-01-01", "--leaf-partition-key-end 2024-01-01"}))
+		})
+		It("does not treat excluded internal artifacts as filters", func() {
+			config := backup_history.BackupConfig{
+				ExcludeSchemas:    []string{"gpexpand"},
+				ExcludeRelations:  []string{"public.err_table"},
+				ExcludedArtifacts: []string{"gpexpand", "public.err_table"},
+			}
+
+			Expect(config.GetFilters()).To(BeEmpty())
+			Expect(config.IsFiltered()).To(BeFalse())
+		})
+	})
 	Describe("CurrentTimestamp", func() {
 		It("returns the current timestamp", func() {
 			operating.System.Now = func() time.Time { return time.Date(2017, time.January, 1, 1, 1, 1, 1, time.Local) }
@@ -153,8 +181,6 @@ var _ = Describe("backup/history tests", func() {
 				Expect(err.Error()).To(ContainSubstring("not yaml"))
 			})
 			It("NewHistory returns an empty History", func() {
-				backup.SetFPInfo(backup_filepath.FilePathInfo{UserSpecifiedBackupDir: "/tmp", UserSpecifiedSegPrefix: "/test-prefix"})
-				backup.SetReport(&utils.Report{})
 				operating.System.ReadFile = func(string) ([]byte, error) { return []byte(""), nil }
 
 				history, err := backup_history.NewHistory("/tempfile")
@@ -235,12 +261,33 @@ var _ = Describe("backup/history tests", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 		It("finds a backup config for the given timestamp", func() {
-			foundConfig := resultHistory.FindBackupConfig("timestamp2")
+			foundConfig := resultHistory.FindBackupConfig("timestamp2", "")
 			Expect(foundConfig).To(Equal(&testConfig2))
 		})
 		It("returns nil when timestamp not found", func() {
-			foundConfig := resultHistory.FindBackupConfig("foo")
+			foundConfig := resultHistory.FindBackupConfig("foo", "")
 			Expect(foundConfig).To(BeNil())
 		})
+		It("finds the backup config of the given database among those with the same timestamp", func() {
+			history := backup_history.History{BackupConfigs: []backup_history.BackupConfig{
+				{Timestamp: "20190101000000", DatabaseName: "db1"},
+				{Timestamp: "20190101000000", DatabaseName: "db2"},
+			}}
+
+			Expect(history.FindBackupConfig("20190101000000", "db2")).To(Equal(&history.BackupConfigs[1]))
+			Expect(history.FindBackupConfig("20190101000000", "db3")).To(BeNil())
+		})
+	})
+	Describe("FindBackupConfigs", func() {
+		It("finds the backup configs of every database with the given timestamp", func() {
+			history := backup_history.History{BackupConfigs: []backup_history.BackupConfig{
+				{Timestamp: "20190101000000", DatabaseName: "db1"},
+				{Timestamp: "20190102000000", DatabaseName: "db1"},
+				{Timestamp: "20190101000000", DatabaseName: "db2"},
+			}}
+
+			Expect(history.FindBackupConfigs("20190101000000")).To(Equal([]backup_history.BackupConfig{history.BackupConfigs[0], history.BackupConfigs[2]}))
+			Expect(history.FindBackupConfigs("20190103000000")).To(BeEmpty())
+		})
 	})
 })
@@ -366,16 +366,27 @@ var _ = Describe("backup end to end integration tests", func() {
 		Describe("Backup include filtering", func() {
 			It("runs gpbackup and gprestore with include-schema backup flag and compression level", func() {
 				timestamp := gpbackup(gpbackupPath, backupHelperPath, "--include-schema", "public", "--compression-level", "2")
-				gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb")
+				gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb", "--allow-filtered-restore")
 
 				assertRelationsCreated(restoreConn, 20)
 				assertDataRestored(restoreConn, publicSchemaTupleCounts)
 				assertArtifactsCleaned(restoreConn, timestamp)
 			})
+			It("runs gpbackup and gprestore with include-schema-file backup flag", func() {
+				includeFile := iohelper.MustOpenFileForWriting("/tmp/include-schemas.txt")
+				utils.MustPrintln(includeFile, "public")
+				timestamp := gpbackup(gpbackupPath, backupHelperPath, "--include-schema-file", "/tmp/include-schemas.txt")
+				gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb", "--allow-filtered-restore")
+
+				assertRelationsCreated(restoreConn, 20)
+				assertDataRestored(restoreConn, publicSchemaTupleCounts)
+
+				_ = os.Remove("/tmp/include-schemas.txt")
+			})
 			It("runs gpbackup and gprestore with include-table backup flag", func() {
 				skipIfOldBackupVersionBefore("1.4.0")
 				timestamp := gpbackup(gpbackupPath, backupHelperPath, "--include-table", "public.foo", "--include-table", "public.sales", "--include-table", "public.myseq1", "--include-table", "public.myview1")
-				gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb")
+				gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb", "--allow-filtered-restore")
 
 				assertRelationsCreated(restoreConn, 16)
 				assertDataRestored(restoreConn, map[string]int{"public.foo": 40000})
@@ -387,7 +398,7 @@ var _ = Describe("backup end to end integration tests", func() {
 				includeFile := iohelper.MustOpenFileForWriting("/tmp/include-tables.txt")
 				utils.MustPrintln(includeFile, "public.sales\npublic.foo\npublic.myseq1\npublic.myview1")
 				timestamp := gpbackup(gpbackupPath, backupHelperPath, "--include-table-file", "/tmp/include-tables.txt")
-				gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb")
+				gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb", "--allow-filtered-restore")
 
 				assertRelationsCreated(restoreConn, 16)
 				assertDataRestored(restoreConn, map[string]int{"public.sales": 13, "public.foo": 40000})
@@ -405,6 +416,17 @@ var _ = Describe("backup end to end integration tests", func() {
 				assertDataRestored(restoreConn, schema2TupleCounts)
 
 			})
+			It("runs gpbackup and gprestore with include-schema-file restore flag", func() {
+				includeFile := iohelper.MustOpenFileForWriting("/tmp/include-schemas.txt")
+				utils.MustPrintln(includeFile, "schema2")
+				timestamp := gpbackup(gpbackupPath, backupHelperPath, "--backup-dir", backupDir)
+				gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb", "--backup-dir", backupDir, "--include-schema-file", "/tmp/include-schemas.txt")
+
+				assertRelationsCreated(restoreConn, 17)
+				assertDataRestored(restoreConn, schema2TupleCounts)
+
+				_ = os.Remove("/tmp/include-schemas.txt")
+			})
 			It("runs gpbackup and gprestore with include-table restore flag", func() {
 				timestamp := gpbackup(gpbackupPath, backupHelperPath)
 				gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb", "--include-table", "public.foo", "--include-table", "public.sales", "--include-table", "public.myseq1", "--include-table", "public.myview1")
@@ -441,15 +463,26 @@ var _ = Describe("backup end to end integration tests", func() {
 		Describe("Backup exclude filtering", func() {
 			It("runs gpbackup and gprestore with exclude-schema backup flag", func() {
 				timestamp := gpbackup(gpbackupPath, backupHelperPath, "--exclude-schema", "public")
-				gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb")
+				gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb", "--allow-filtered-restore")
 
 				assertRelationsCreated(restoreConn, 17)
 				assertDataRestored(restoreConn, schema2TupleCounts)
 			})
+			It("runs gpbackup and gprestore with exclude-schema-file backup flag", func() {
+				excludeFile := iohelper.MustOpenFileForWriting("/tmp/exclude-schemas.txt")
+				utils.MustPrintln(excludeFile, "public")
+				timestamp := gpbackup(gpbackupPath, backupHelperPath, "--exclude-schema-file", "/tmp/exclude-schemas.txt")
+				gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb", "--allow-filtered-restore")
+
+				assertRelationsCreated(restoreConn, 17)
+				assertDataRestored(restoreConn, schema2TupleCounts)
+
+				_ = os.Remove("/tmp/exclude-schemas.txt")
+			})
 			It("runs gpbackup and gprestore with exclude-table backup flag", func() {
 				skipIfOldBackupVersionBefore("1.4.0")
 				timestamp := gpbackup(gpbackupPath, backupHelperPath, "--exclude-table", "schema2.foo2", "--exclude-table", "schema2.returns", "--exclude-table", "public.myseq2", "--exclude-table", "public.myview2")
-				gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb")
+				gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb", "--allow-filtered-restore")
 
 				assertRelationsCreated(restoreConn, TOTAL_RELATIONS_AFTER_EXCLUDE)
 				assertDataRestored(restoreConn, map[string]int{"schema2.foo3": 100, "public.foo": 40000, "public.holds": 50000, "public.sales": 13})
@@ -461,7 +494,7 @@ var _ = Describe("backup end to end integration tests", func() {
 				excludeFile := iohelper.MustOpenFileForWriting("/tmp/exclude-tables.txt")
 				utils.MustPrintln(excludeFile, "schema2.foo2\nschema2.returns\npublic.sales\npublic.myseq2\npublic.myview2")
 				timestamp := gpbackup(gpbackupPath, backupHelperPath, "--exclude-table-file", "/tmp/exclude-tables.txt")
-				gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb")
+				gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb", "--allow-filtered-restore")
 
 				assertRelationsCreated(restoreConn, 8)
 				assertDataRestored(restoreConn, map[string]int{"schema2.foo3": 100, "public.foo": 40000, "public.holds": 50000})
@@ -477,6 +510,17 @@ var _ = Describe("backup end to end integration tests", func() {
 				assertRelationsCreated(restoreConn, 17)
 				assertDataRestored(restoreConn, schema2TupleCounts)
 			})
+			It("runs gpbackup and gprestore with exclude-schema-file restore flag", func() {
+				excludeFile := iohelper.MustOpenFileForWriting("/tmp/exclude-schemas.txt")
+				utils.MustPrintln(excludeFile, "public")
+				timestamp := gpbackup(gpbackupPath, backupHelperPath, "--backup-dir", backupDir)
+				gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb", "--backup-dir", backupDir, "--exclude-schema-file", "/tmp/exclude-schemas.txt")
+
+				assertRelationsCreated(restoreConn, 17)
+				assertDataRestored(restoreConn, schema2TupleCounts)
+
+				_ = os.Remove("/tmp/exclude-schemas.txt")
+			})
 			It("runs gpbackup and gprestore with exclude-table restore flag", func() {
 				timestamp := gpbackup(gpbackupPath, backupHelperPath)
 				gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb", "--exclude-table", "schema2.foo2", "--exclude-table", "schema2.returns", "--exclude-table", "public.myseq2", "--exclude-table", "public.myview2")
@@ -736,7 +780,7 @@ var _ = Describe("backup end to end integration tests", func() {
 				incremental2Timestamp := gpbackup(gpbackupPath, backupHelperPath,
 					"--incremental", "--leaf-partition-data", "--include-table=public.sales")
 
-				gprestore(gprestorePath, restoreHelperPath, incremental2Timestamp, "--redirect-db", "restoredb")
+				gprestore(gprestorePath, restoreHelperPath, incremental2Timestamp, "--redirect-db", "restoredb", "--allow-filtered-restore")
 
 				localTupleCounts := map[string]int{
 					"public.sales": 15,
@@ -780,7 +824,7 @@ var _ = Describe("backup end to end integration tests", func() {
 					incremental2Timestamp := gpbackup(gpbackupPath, backupHelperPath,
 						"--incremental", "--leaf-partition-data", "--include-table", "public.sales")
 
-					gprestore(gprestorePath, restoreHelperPath, incremental2Timestamp, "--redirect-db", "restoredb")
+					gprestore(gprestorePath, restoreHelperPath, incremental2Timestamp, "--redirect-db", "restoredb", "--allow-filtered-restore")
 
 					assertDataRestored(restoreConn, map[string]int{
 						"public.sales":             15,
@@ -1177,7 +1221,7 @@ var _ = Describe("backup end to end integration tests", func() {
 		It("runs gpbackup with --include-table flag with CAPS special characters", func() {
 			skipIfOldBackupVersionBefore("1.9.1")
 			timestamp := gpbackup(gpbackupPath, backupHelperPath, "--backup-dir", backupDir, "--include-table", `public.FOObar`)
-			gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb", "--backup-dir", backupDir)
+			gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb", "--backup-dir", backupDir, "--allow-filtered-restore")
 
 			assertRelationsCreated(restoreConn, 1)
 
@@ -1202,7 +1246,7 @@ PARTITION BY LIST (gender)
 			testhelper.AssertQueryRuns(backupConn, `insert into public.testparent values (0,0,0,'F',1)`)
 
 			timestamp := gpbackup(gpbackupPath, backupHelperPath, "--backup-dir", backupDir, "--include-table", `public.testparent_1_prt_girls`, "--leaf-partition-data")
-			gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb", "--backup-dir", backupDir)
+			gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb", "--backup-dir", backupDir, "--allow-filtered-restore")
 
 			assertRelationsCreated(restoreConn, 4)
 
@@ -1229,7 +1273,7 @@ PARTITION BY LIST (gender)
 			testhelper.AssertQueryRuns(backupConn, `insert into public."CAPparent" values (0,0,0,'F',1)`)
 
 			timestamp := gpbackup(gpbackupPath, backupHelperPath, "--backup-dir", backupDir, "--include-table", `public.CAPparent_1_prt_girls`, "--leaf-partition-data")
-			gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb", "--backup-dir", backupDir)
+			gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb", "--backup-dir", backupDir, "--allow-filtered-restore")
 
 			assertRelationsCreated(restoreConn, 4)
 
@@ -1268,7 +1312,7 @@ PARTITION BY LIST (gender)
 			defer testhelper.AssertQueryRuns(backupConn, fmt.Sprintf(`DROP TABLE %s`, tableName))
 			testhelper.AssertQueryRuns(backupConn, fmt.Sprintf(`INSERT INTO %s VALUES (0.100001216)`, tableName))
 			timestamp := gpbackup(gpbackupPath, backupHelperPath, "--backup-dir", backupDir, "--dbname", "testdb", "--include-table", fmt.Sprintf("%s", tableName))
-			gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb", "--backup-dir", backupDir)
+			gprestore(gprestorePath, restoreHelperPath, timestamp, "--redirect-db", "restoredb", "--backup-dir", backupDir, "--allow-filtered-restore")
 			tableCount := dbconn.MustSelectString(restoreConn, fmt.Sprintf("SELECT count(*) FROM %s WHERE val = 0.100001216::real", tableName))
 			Expect(tableCount).To(Equal(strconv.Itoa(1)))
 		})
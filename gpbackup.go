@@ -18,14 +18,23 @@ func main() {
 		Args:    cobra.NoArgs,
 		Version: GetVersion(),
 		Run: func(cmd *cobra.Command, args []string) {
-			defer DoTeardown()
-			DoFlagValidation(cmd)
-			DoSetup()
-			DoBackup()
+			os.Exit(RunBackupCommand(cmd))
 		}}
+	rootCmd.AddCommand(NewBackupCommand())
+	rootCmd.AddCommand(NewRestoreCommand())
+	rootCmd.AddCommand(NewListCommand())
+	rootCmd.AddCommand(NewHistoryCommand())
+	rootCmd.AddCommand(NewVerifyCommand())
+	rootCmd.AddCommand(NewDeleteCommand())
+	rootCmd.AddCommand(NewDoctorCommand())
+	rootCmd.AddCommand(NewDiffCommand())
+	rootCmd.AddCommand(NewImportCommand())
+	rootCmd.AddCommand(NewCleanupCommand())
+	rootCmd.AddCommand(NewPluginTestCommand())
+	rootCmd.AddCommand(NewCompletionCommand())
 	rootCmd.SetArgs(utils.HandleSingleDashes(os.Args[1:]))
 	DoInit(rootCmd)
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(2)
+		os.Exit(utils.EXIT_FATAL)
 	}
 }
@@ -26,6 +26,6 @@ func main() {
 	rootCmd.SetArgs(utils.HandleSingleDashes(os.Args[1:]))
 	DoInit(rootCmd)
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(2)
+		os.Exit(utils.EXIT_FATAL)
 	}
 }
@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/greenplum-db/gpbackup/utils"
 	"github.com/pkg/errors"
@@ -25,6 +26,7 @@ func doBackupAgent() error {
 		bufIoWriter *bufio.Writer
 		writeHandle io.WriteCloser
 		writeCmd    *exec.Cmd
+		level       int
 	)
 	toc := &utils.SegmentTOC{}
 	toc.DataEntries = make(map[uint]utils.SegmentDataEntry)
@@ -63,6 +65,16 @@ func doBackupAgent() error {
 			if err != nil {
 				return err
 			}
+			level = *compressionLevel
+		}
+		recordedLevel := 0
+		if *adaptiveCompression && gzipWriter != nil {
+			gzipWriter, level, err = adaptCompressionLevel(reader, gzipWriter, bufIoWriter, level)
+			if err != nil {
+				return err
+			}
+			finalWriter = gzipWriter
+			recordedLevel = level
 		}
 
 		log(fmt.Sprintf("Backing up table with oid %d\n", oid))
@@ -73,7 +85,7 @@ func doBackupAgent() error {
 		log(fmt.Sprintf("Read %d bytes\n", numBytes))
 
 		lastProcessed := lastRead + uint64(numBytes)
-		toc.AddSegmentDataEntry(uint(oid), lastRead, lastProcessed)
+		toc.AddSegmentDataEntry(uint(oid), lastRead, lastProcessed, recordedLevel)
 		lastRead = lastProcessed
 
 		lastPipe = currentPipe
@@ -88,12 +100,25 @@ func doBackupAgent() error {
 	/*
 	 * The order for flushing and closing the writers below is very specific
 	 * to ensure all data is written to the file and file handles are not leaked.
+	 * Errors are returned rather than ignored, as a failure here means the end
+	 * of the compressed stream or buffered data never reached the data file.
 	 */
 	if gzipWriter != nil {
-		_ = gzipWriter.Close()
+		err = gzipWriter.Close()
+		if err != nil {
+			_ = writeHandle.Close()
+			return err
+		}
+	}
+	err = bufIoWriter.Flush()
+	if err != nil {
+		_ = writeHandle.Close()
+		return err
+	}
+	err = writeHandle.Close()
+	if err != nil {
+		return err
 	}
-	_ = bufIoWriter.Flush()
-	_ = writeHandle.Close()
 	if *pluginConfigFile != "" {
 		/*
 		 * When using a plugin, the agent may take longer to finish than the
@@ -116,7 +141,7 @@ func doBackupAgent() error {
 	return nil
 }
 
-func getBackupPipeReader(currentPipe string) (io.Reader, io.ReadCloser, error) {
+func getBackupPipeReader(currentPipe string) (*bufio.Reader, io.ReadCloser, error) {
 	readHandle, err := os.OpenFile(currentPipe, os.O_RDONLY, os.ModeNamedPipe)
 	if err != nil {
 		return nil, nil, err
@@ -124,10 +149,67 @@ func getBackupPipeReader(currentPipe string) (io.Reader, io.ReadCloser, error) {
 	// This is a workaround for https://github.com/golang/go/issues/24164.
 	// Once this bug is fixed, the call to Fd() can be removed
 	readHandle.Fd()
-	reader := bufio.NewReader(readHandle)
+	var reader *bufio.Reader
+	if *adaptiveCompression && *copyBufferSize < utils.ADAPTIVE_COMPRESSION_SAMPLE_SIZE {
+		// The buffer must be large enough to hold the sample used to choose a compression level
+		reader = bufio.NewReaderSize(readHandle, utils.ADAPTIVE_COMPRESSION_SAMPLE_SIZE)
+	} else {
+		reader = newBufferedReader(readHandle)
+	}
 	return reader, readHandle, nil
 }
 
+/*
+ * Peeking at the start of the table's data leaves it in the reader to be
+ * copied as usual.  The current gzip member is only ended, and a new one
+ * started, when the chosen level differs from the current one.
+ */
+func adaptCompressionLevel(reader *bufio.Reader, gzipWriter *gzip.Writer, bufIoWriter *bufio.Writer, currentLevel int) (*gzip.Writer, int, error) {
+	sample, _ := reader.Peek(utils.ADAPTIVE_COMPRESSION_SAMPLE_SIZE)
+	ratio, compressRate := utils.MeasureCompressibility(sample, *compressionLevel)
+	level := utils.ChooseCompressionLevel(*compressionLevel, ratio, compressRate, outputWriter.BytesPerSecond())
+	if level == currentLevel {
+		return gzipWriter, currentLevel, nil
+	}
+	log(fmt.Sprintf("Changing compression level from %d to %d\n", currentLevel, level))
+	err := gzipWriter.Close()
+	if err != nil {
+		return nil, 0, err
+	}
+	gzipWriter, err = gzip.NewWriterLevel(bufIoWriter, level)
+	if err != nil {
+		return nil, 0, err
+	}
+	return gzipWriter, level, nil
+}
+
+/*
+ * Measures the rate at which compressed data is written to the data file or
+ * plugin, for comparison with the rate at which data can be compressed.
+ */
+type throughputWriter struct {
+	writer       io.Writer
+	bytesWritten int64
+	timeWriting  time.Duration
+}
+
+var outputWriter = &throughputWriter{}
+
+func (w *throughputWriter) Write(p []byte) (int, error) {
+	start := time.Now()
+	n, err := w.writer.Write(p)
+	w.timeWriting += time.Since(start)
+	w.bytesWritten += int64(n)
+	return n, err
+}
+
+func (w *throughputWriter) BytesPerSecond() float64 {
+	if w.timeWriting <= 0 {
+		return 0
+	}
+	return float64(w.bytesWritten) / w.timeWriting.Seconds()
+}
+
 func getBackupPipeWriter(compressLevel int) (io.Writer, *gzip.Writer, *bufio.Writer, io.WriteCloser, *exec.Cmd, error) {
 	var writeHandle io.WriteCloser
 	var err error
@@ -143,7 +225,8 @@ func getBackupPipeWriter(compressLevel int) (io.Writer, *gzip.Writer, *bufio.Wri
 
 	var finalWriter io.Writer
 	var gzipWriter *gzip.Writer
-	bufIoWriter := bufio.NewWriter(writeHandle)
+	outputWriter = &throughputWriter{writer: writeHandle}
+	bufIoWriter := newBufferedWriter(outputWriter)
 	finalWriter = bufIoWriter
 	if compressLevel > 0 {
 		gzipWriter, err = gzip.NewWriterLevel(bufIoWriter, compressLevel)
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"runtime/debug"
@@ -39,17 +40,24 @@ var (
  * Command-line flags
  */
 var (
-	backupAgent      *bool
-	compressionLevel *int
-	content          *int
-	dataFile         *string
-	oidFile          *string
-	onErrorContinue  *bool
-	pipeFile         *string
-	pluginConfigFile *string
-	printVersion     *bool
-	restoreAgent     *bool
-	tocFile          *string
+	adaptiveCompression *bool
+	backupAgent         *bool
+	compressionLevel    *int
+	content             *int
+	copyBufferSize      *int
+	copyFormat          *string
+	copyHeader          *bool
+	dataFile            *string
+	oidFile             *string
+	onErrorContinue     *bool
+	parquetAgent        *bool
+	parquetSchema       *string
+	pipeFile            *string
+	pluginConfigFile    *string
+	printVersion        *bool
+	restoreAgent        *bool
+	sampleRows          *int
+	tocFile             *string
 )
 
 func DoHelper() {
@@ -81,11 +89,18 @@ func DoHelper() {
 		err = doBackupAgent()
 	} else if *restoreAgent {
 		err = doRestoreAgent()
+	} else if *parquetAgent {
+		err = doParquetWriter()
+	} else if *sampleRows > 0 {
+		err = doSampleRows()
 	}
 	if err != nil {
 		gplog.Error(fmt.Sprintf("%v: %s", err, debug.Stack()))
-		handle, _ := iohelper.OpenFileForWriting(fmt.Sprintf("%s_error", *pipeFile))
-		_ = handle.Close()
+		// The Parquet writer and the sampler have no pipe, and report errors through their exit codes to the COPY running them
+		if *pipeFile != "" {
+			handle, _ := iohelper.OpenFileForWriting(fmt.Sprintf("%s_error", *pipeFile))
+			_ = handle.Close()
+		}
 	}
 }
 
@@ -94,28 +109,34 @@ func InitializeGlobals() {
 	CleanupGroup.Add(1)
 	gplog.InitializeLogging("gpbackup_helper", "")
 
+	adaptiveCompression = flag.Bool("adaptive-compression", false, "Choose the compression level of each table's data, starting from --compression-level")
 	backupAgent = flag.Bool("backup-agent", false, "Use gpbackup_helper as an agent for backup")
 	content = flag.Int("content", -2, "Content ID of the corresponding segment")
 	compressionLevel = flag.Int("compression-level", 0, "The level of compression to use with gzip. O indicates no compression.")
+	copyBufferSize = flag.Int("copy-buffer-size", 0, "The size in bytes of the buffers used to stream table data. 0 indicates the default size.")
+	copyFormat = flag.String("copy-format", "csv", "The format of the data read with --sample-rows")
+	copyHeader = flag.Bool("copy-header", false, "Whether the data read with --sample-rows begins with a header")
 	dataFile = flag.String("data-file", "", "Absolute path to the data file")
 	oidFile = flag.String("oid-file", "", "Absolute path to the file containing a list of oids to restore")
 	onErrorContinue = flag.Bool("on-error-continue", false, "Continue restore even when encountering an error")
+	parquetAgent = flag.Bool("parquet-writer", false, "Use gpbackup_helper to convert the CSV data of a table read from stdin into the Parquet file --data-file")
+	parquetSchema = flag.String("parquet-schema", "", "The encoded columns of the table to be converted with --parquet-writer")
 	pipeFile = flag.String("pipe-file", "", "Absolute path to the pipe file")
 	pluginConfigFile = flag.String("plugin-config", "", "The configuration file to use for a plugin")
 	printVersion = flag.Bool("version", false, "Print version number and exit")
 	restoreAgent = flag.Bool("restore-agent", false, "Use gpbackup_helper as an agent for restore")
+	sampleRows = flag.Int("sample-rows", 0, "Copy a random sample of this many rows of the data read from stdin to stdout")
 	tocFile = flag.String("toc-file", "", "Absolute path to the table of contents file")
 
-	if *onErrorContinue && !*restoreAgent {
-		fmt.Printf("--on-error-continue flag can only be used with --restore-agent flag")
-		os.Exit(1)
-	}
-
 	flag.Parse()
 	if *printVersion {
 		fmt.Printf("gpbackup_helper version %s\n", version)
 		os.Exit(0)
 	}
+	if *onErrorContinue && !*restoreAgent {
+		fmt.Printf("--on-error-continue flag can only be used with --restore-agent flag")
+		os.Exit(1)
+	}
 	operating.InitializeSystemFunctions()
 }
 
@@ -161,6 +182,24 @@ func flushAndCloseRestoreWriter() error {
 	return nil
 }
 
+/*
+ * Table data is streamed through buffers of --copy-buffer-size bytes, so that
+ * the memory used per pipe can be tuned for tables with very large rows.
+ */
+func newBufferedReader(reader io.Reader) *bufio.Reader {
+	if *copyBufferSize > 0 {
+		return bufio.NewReaderSize(reader, *copyBufferSize)
+	}
+	return bufio.NewReader(reader)
+}
+
+func newBufferedWriter(writer io.Writer) *bufio.Writer {
+	if *copyBufferSize > 0 {
+		return bufio.NewWriterSize(writer, *copyBufferSize)
+	}
+	return bufio.NewWriter(writer)
+}
+
 func fileExists(filename string) bool {
 	_, err := operating.System.Stat(filename)
 	return err == nil
@@ -182,7 +221,7 @@ func removeFileIfExists(filename string) error {
 
 func DoCleanup() {
 	defer CleanupGroup.Done()
-	if wasTerminated {
+	if wasTerminated && *pipeFile != "" {
 		/*
 		 * If the agent dies during the last table copy, it can still report
 		 * success, so we create an error file and check for its presence in
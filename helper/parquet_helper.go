@@ -0,0 +1,43 @@
+package helper
+
+import (
+	"bufio"
+	"os"
+
+	"github.com/greenplum-db/gp-common-go-libs/iohelper"
+	"github.com/greenplum-db/gpbackup/utils"
+)
+
+/*
+ * Parquet export specific functions
+ */
+
+/*
+ * Runs as the PROGRAM of a COPY TO for a single table on a single segment,
+ * converting the CSV read from stdin into the Parquet file --data-file.
+ */
+func doParquetWriter() error {
+	columns, err := utils.DecodeParquetSchema(*parquetSchema)
+	if err != nil {
+		return err
+	}
+	fileHandle, err := iohelper.OpenFileForWriting(*dataFile)
+	if err != nil {
+		return err
+	}
+	bufIoWriter := bufio.NewWriter(fileHandle)
+	parquetWriter, err := utils.NewParquetWriter(bufIoWriter, columns, *compressionLevel)
+	if err == nil {
+		var numRows int64
+		numRows, err = utils.ConvertCopyCSVToParquet(os.Stdin, parquetWriter)
+		log("Converted %d rows to Parquet file %s", numRows, *dataFile)
+	}
+	if err == nil {
+		err = bufIoWriter.Flush()
+	}
+	closeErr := fileHandle.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
@@ -137,9 +137,9 @@ func getRestoreDataReader() (*bufio.Reader, error) {
 		if err != nil {
 			return nil, err
 		}
-		bufIoReader = bufio.NewReader(gzipReader)
+		bufIoReader = newBufferedReader(gzipReader)
 	} else {
-		bufIoReader = bufio.NewReader(readHandle)
+		bufIoReader = newBufferedReader(readHandle)
 	}
 	// Check that no error has occurred in plugin command
 	errMsg := strings.Trim(errBuf.String(), "\x00")
@@ -155,7 +155,7 @@ func getRestorePipeWriter(currentPipe string) (*bufio.Writer, *os.File, error) {
 	if err != nil {
 		return nil, nil, err
 	}
-	pipeWriter := bufio.NewWriter(fileHandle)
+	pipeWriter := newBufferedWriter(fileHandle)
 	return pipeWriter, fileHandle, nil
 }
 
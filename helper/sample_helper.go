@@ -0,0 +1,30 @@
+package helper
+
+import (
+	"bufio"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/greenplum-db/gpbackup/utils"
+)
+
+/*
+ * Data sample check specific functions
+ */
+
+/*
+ * Runs in the PROGRAM of a COPY FROM for a single table on a single segment,
+ * copying a random sample of the rows of the table's decompressed data file
+ * read from stdin to stdout.
+ */
+func doSampleRows() error {
+	writer := bufio.NewWriter(os.Stdout)
+	format := utils.CopyFormat{Format: *copyFormat, Header: *copyHeader}
+	numRows, err := utils.SampleCopyRecords(os.Stdin, writer, format, *sampleRows, rand.New(rand.NewSource(time.Now().UnixNano())))
+	if err != nil {
+		return err
+	}
+	log("Sampled %d rows", numRows)
+	return writer.Flush()
+}
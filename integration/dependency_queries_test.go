@@ -56,8 +56,8 @@ var _ = Describe("backup integration tests", func() {
 
 			deps := backup.GetDependencies(connectionPool, backupSet)
 			if connectionPool.Version.Is("4") {
-				tableRelations := backup.GetIncludedUserTableRelations(connectionPool, []string{})
-				tables := backup.ConstructDefinitionsForTables(connectionPool, tableRelations)
+				tableRelations := testBackup.GetIncludedUserTableRelations(connectionPool, []string{})
+				tables := testBackup.ConstructDefinitionsForTables(connectionPool, tableRelations)
 				protocols := backup.GetExternalProtocols(connectionPool)
 				backup.AddProtocolDependenciesForGPDB4(deps, tables, protocols)
 			}
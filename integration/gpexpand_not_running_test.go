@@ -77,7 +77,7 @@ var _ = Describe("gpexpand_sensor", func() {
 		}()
 
 		defer testhelper.ShouldPanicWithMessage(`[CRITICAL]:-Greenplum expansion currently in process, please re-run gpbackup when the expansion has completed`)
-		backup.DoSetup()
+		testBackup.DoSetup()
 	})
 	It("should prevent gpbackup from starting when gpexpand is in phase 2", func() {
 		postgresConn := dbconn.NewDBConnFromEnvironment("postgres")
@@ -90,6 +90,6 @@ var _ = Describe("gpexpand_sensor", func() {
 		testhelper.AssertQueryRuns(postgresConn, "INSERT INTO gpexpand.status VALUES ('IN PROGRESS', now())")
 
 		defer testhelper.ShouldPanicWithMessage(`[CRITICAL]:-Greenplum expansion currently in process, please re-run gpbackup when the expansion has completed`)
-		backup.DoSetup()
+		testBackup.DoSetup()
 	})
 })
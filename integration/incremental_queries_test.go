@@ -46,7 +46,7 @@ var _ = Describe("backup integration tests", func() {
 		Context("AO, AO_CO and AO partition tables are only just created", func() {
 			var aoIncrementalMetadata map[string]utils.AOEntry
 			BeforeEach(func() {
-				aoIncrementalMetadata = backup.GetAOIncrementalMetadata(connectionPool)
+				aoIncrementalMetadata = testBackup.GetAOIncrementalMetadata(connectionPool)
 			})
 			It("should have a modcount of 0", func() {
 				Expect(aoIncrementalMetadata[aoTableFQN].Modcount).To(Equal(int64(0)))
@@ -66,13 +66,13 @@ var _ = Describe("backup integration tests", func() {
 				var initialAOIncrementalMetadata map[string]utils.AOEntry
 				var aoIncrementalMetadata map[string]utils.AOEntry
 				BeforeEach(func() {
-					initialAOIncrementalMetadata = backup.GetAOIncrementalMetadata(connectionPool)
+					initialAOIncrementalMetadata = testBackup.GetAOIncrementalMetadata(connectionPool)
 
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf(insertSQL, aoTableFQN))
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf(insertSQL, aoCOTableFQN))
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf(insertSQL, aoPartParentTableFQN))
 
-					aoIncrementalMetadata = backup.GetAOIncrementalMetadata(connectionPool)
+					aoIncrementalMetadata = testBackup.GetAOIncrementalMetadata(connectionPool)
 				})
 				AfterEach(func() {
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf(deleteSQL, aoTableFQN))
@@ -108,13 +108,13 @@ var _ = Describe("backup integration tests", func() {
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf(insertSQL, aoCOTableFQN))
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf(insertSQL, aoPartParentTableFQN))
 
-					initialAOIncrementalMetadata = backup.GetAOIncrementalMetadata(connectionPool)
+					initialAOIncrementalMetadata = testBackup.GetAOIncrementalMetadata(connectionPool)
 
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf(deleteSQL, aoTableFQN))
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf(deleteSQL, aoCOTableFQN))
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf(deleteSQL, aoPartParentTableFQN))
 
-					aoIncrementalMetadata = backup.GetAOIncrementalMetadata(connectionPool)
+					aoIncrementalMetadata = testBackup.GetAOIncrementalMetadata(connectionPool)
 				})
 				It("should increase modcount for non partition tables", func() {
 					Expect(aoIncrementalMetadata[aoTableFQN].Modcount).
@@ -143,13 +143,13 @@ var _ = Describe("backup integration tests", func() {
 			var aoIncrementalMetadata map[string]utils.AOEntry
 			Context("After a column add", func() {
 				BeforeEach(func() {
-					initialAOIncrementalMetadata = backup.GetAOIncrementalMetadata(connectionPool)
+					initialAOIncrementalMetadata = testBackup.GetAOIncrementalMetadata(connectionPool)
 
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf(addColumnSQL, aoTableFQN))
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf(addColumnSQL, aoCOTableFQN))
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf(addColumnSQL, aoPartParentTableFQN))
 
-					aoIncrementalMetadata = backup.GetAOIncrementalMetadata(connectionPool)
+					aoIncrementalMetadata = testBackup.GetAOIncrementalMetadata(connectionPool)
 				})
 				It("should NOT care about modcount", func() {})
 				It("should have a changed last DDL timestamp", func() {
@@ -168,13 +168,13 @@ var _ = Describe("backup integration tests", func() {
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf(addColumnSQL, aoTableFQN))
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf(addColumnSQL, aoCOTableFQN))
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf(addColumnSQL, aoPartParentTableFQN))
-					initialAOIncrementalMetadata = backup.GetAOIncrementalMetadata(connectionPool)
+					initialAOIncrementalMetadata = testBackup.GetAOIncrementalMetadata(connectionPool)
 
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf(dropColumnSQL, aoTableFQN))
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf(dropColumnSQL, aoCOTableFQN))
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf(dropColumnSQL, aoPartParentTableFQN))
 
-					aoIncrementalMetadata = backup.GetAOIncrementalMetadata(connectionPool)
+					aoIncrementalMetadata = testBackup.GetAOIncrementalMetadata(connectionPool)
 				})
 				It("should NOT care about modcount", func() {})
 				It("should have a changed last DDL timestamp", func() {
@@ -190,11 +190,11 @@ var _ = Describe("backup integration tests", func() {
 			})
 			Context("After truncating a child partition", func() {
 				BeforeEach(func() {
-					initialAOIncrementalMetadata = backup.GetAOIncrementalMetadata(connectionPool)
+					initialAOIncrementalMetadata = testBackup.GetAOIncrementalMetadata(connectionPool)
 
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf("TRUNCATE TABLE %s", aoPartChildTableFQN))
 
-					aoIncrementalMetadata = backup.GetAOIncrementalMetadata(connectionPool)
+					aoIncrementalMetadata = testBackup.GetAOIncrementalMetadata(connectionPool)
 				})
 				It("should NOT care about modcount", func() {})
 				It("should have a changed last DDL timestamp for the child", func() {
@@ -212,7 +212,7 @@ var _ = Describe("backup integration tests", func() {
 			var aoIncrementalMetadata map[string]utils.AOEntry
 			Context("After an insert followed by an ALTER table", func() {
 				BeforeEach(func() {
-					initialAOIncrementalMetadata = backup.GetAOIncrementalMetadata(connectionPool)
+					initialAOIncrementalMetadata = testBackup.GetAOIncrementalMetadata(connectionPool)
 
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf(insertSQL, aoTableFQN))
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf(addColumnSQL, aoTableFQN))
@@ -221,7 +221,7 @@ var _ = Describe("backup integration tests", func() {
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf(insertSQL, aoPartParentTableFQN))
 					testhelper.AssertQueryRuns(connectionPool, fmt.Sprintf(addColumnSQL, aoPartParentTableFQN))
 
-					aoIncrementalMetadata = backup.GetAOIncrementalMetadata(connectionPool)
+					aoIncrementalMetadata = testBackup.GetAOIncrementalMetadata(connectionPool)
 				})
 				It("should NOT care about modcount", func() {
 					//We don't care about modcount because DDL operations can reset the modcount value
@@ -244,7 +244,7 @@ var _ = Describe("backup integration tests", func() {
 				It("only retrieves ao metadata for specific tables", func() {
 					backupCmdFlags.Set(utils.INCLUDE_RELATION, aoTableFQN)
 
-					aoIncrementalMetadata = backup.GetAOIncrementalMetadata(connectionPool)
+					aoIncrementalMetadata = testBackup.GetAOIncrementalMetadata(connectionPool)
 					Expect(aoIncrementalMetadata).To(HaveLen(1))
 				})
 			})
@@ -256,7 +256,7 @@ var _ = Describe("backup integration tests", func() {
 
 					backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
 
-					aoIncrementalMetadata = backup.GetAOIncrementalMetadata(connectionPool)
+					aoIncrementalMetadata = testBackup.GetAOIncrementalMetadata(connectionPool)
 					Expect(aoIncrementalMetadata).To(HaveLen(1))
 				})
 			})
@@ -30,6 +30,7 @@ var (
 	testCluster             *cluster.Cluster
 	gpbackupHelperPath      string
 	stdout, stderr, logFile *gbytes.Buffer
+	testBackup              *backup.Backup
 
 	// GUC defaults. Initially set to GPDB4 values
 	concurrencyDefault    = "20"
@@ -57,8 +58,6 @@ var _ = BeforeSuite(func() {
 	// We can't use AssertQueryRuns since if a role already exists it will error
 	_, _ = connectionPool.Exec("CREATE ROLE testrole SUPERUSER")
 	_, _ = connectionPool.Exec("CREATE ROLE anothertestrole SUPERUSER")
-	backup.InitializeMetadataParams(connectionPool)
-	backup.SetConnection(connectionPool)
 	segConfig := cluster.MustGetSegmentConfiguration(connectionPool)
 	testCluster = cluster.NewCluster(segConfig)
 	testhelper.AssertQueryRuns(connectionPool, "SET ROLE testrole")
@@ -104,8 +103,7 @@ var _ = BeforeEach(func() {
 	backupCmdFlags = pflag.NewFlagSet("gpbackup", pflag.ExitOnError)
 
 	backup.SetFlagDefaults(backupCmdFlags)
-	backup.SetCmdFlags(backupCmdFlags)
-	backup.SetFilterRelationClause("")
+	testBackup = backup.NewBackup(backupCmdFlags, connectionPool, nil)
 
 	restoreCmdFlags = pflag.NewFlagSet("gprestore", pflag.ExitOnError)
 
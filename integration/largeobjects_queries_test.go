@@ -0,0 +1,33 @@
+package integration
+
+import (
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"github.com/greenplum-db/gpbackup/backup"
+	"github.com/greenplum-db/gpbackup/testutils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backup integration tests", func() {
+	BeforeEach(func() {
+		testutils.SkipIfBefore6(connectionPool)
+		testhelper.AssertQueryRuns(connectionPool, "SELECT pg_catalog.lo_from_bytea(98765, 'large object data'::bytea)")
+	})
+	AfterEach(func() {
+		testhelper.AssertQueryRuns(connectionPool, "SELECT pg_catalog.lo_unlink(98765)")
+	})
+	Describe("GetLargeObjects", func() {
+		It("returns a slice of large objects", func() {
+			results := backup.GetLargeObjects(connectionPool)
+
+			Expect(results).To(ContainElement(backup.LargeObject{Oid: 98765}))
+		})
+	})
+	Describe("GetLargeObjectChunk", func() {
+		It("returns the data of a large object from the given offset", func() {
+			Expect(backup.GetLargeObjectChunk(connectionPool, 98765, 0)).To(Equal([]byte("large object data")))
+			Expect(backup.GetLargeObjectChunk(connectionPool, 98765, 6)).To(Equal([]byte("object data")))
+		})
+	})
+})
@@ -28,7 +28,7 @@ var _ = Describe("backup integration create statement tests", func() {
 		})
 		It("creates a basic database", func() {
 			db := backup.Database{Oid: 1, Name: "create_test_db", Tablespace: "pg_default", Encoding: "UTF8"}
-			backup.PrintCreateDatabaseStatement(backupfile, toc, emptyDB, db, emptyMetadataMap)
+			testBackup.PrintCreateDatabaseStatement(backupfile, toc, emptyDB, db, emptyMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP DATABASE create_test_db")
@@ -41,7 +41,7 @@ var _ = Describe("backup integration create statement tests", func() {
 			var db backup.Database
 			db = backup.Database{Oid: 1, Name: "create_test_db", Tablespace: "pg_default", Encoding: "UTF8", Collate: defaultDB.Collate, CType: defaultDB.Collate}
 
-			backup.PrintCreateDatabaseStatement(backupfile, toc, defaultDB, db, emptyMetadataMap)
+			testBackup.PrintCreateDatabaseStatement(backupfile, toc, defaultDB, db, emptyMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP DATABASE create_test_db")
@@ -57,7 +57,7 @@ var _ = Describe("backup integration create statement tests", func() {
 				db = backup.Database{Oid: 1, Name: "create_test_db", Tablespace: "pg_default", Encoding: "UTF8", Collate: "en_US.utf-8", CType: "en_US.utf-8"}
 			}
 
-			backup.PrintCreateDatabaseStatement(backupfile, toc, emptyDB, db, emptyMetadataMap)
+			testBackup.PrintCreateDatabaseStatement(backupfile, toc, emptyDB, db, emptyMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP DATABASE create_test_db")
@@ -88,7 +88,7 @@ var _ = Describe("backup integration create statement tests", func() {
 			resQueueMetadataMap := testutils.DefaultMetadataMap("RESOURCE QUEUE", false, false, true, false)
 			resQueueMetadata := resQueueMetadataMap[basicQueue.GetUniqueID()]
 
-			backup.PrintCreateResourceQueueStatements(backupfile, toc, []backup.ResourceQueue{basicQueue}, resQueueMetadataMap)
+			testBackup.PrintCreateResourceQueueStatements(backupfile, toc, []backup.ResourceQueue{basicQueue}, resQueueMetadataMap)
 
 			// CREATE RESOURCE QUEUE statements can not be part of a multi-command statement, so
 			// feed the CREATE RESOURCE QUEUE and COMMENT ON statements separately.
@@ -99,7 +99,7 @@ var _ = Describe("backup integration create statement tests", func() {
 
 			resultResourceQueues := backup.GetResourceQueues(connectionPool)
 			resQueueUniqueID := testutils.UniqueIDFromObjectName(connectionPool, "", "basicQueue", backup.TYPE_RESOURCEQUEUE)
-			resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, backup.TYPE_RESOURCEQUEUE)
+			resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_RESOURCEQUEUE)
 			resultMetadata := resultMetadataMap[resQueueUniqueID]
 			structmatcher.ExpectStructsToMatch(&resultMetadata, &resQueueMetadata)
 
@@ -114,7 +114,7 @@ var _ = Describe("backup integration create statement tests", func() {
 			everythingQueue := backup.ResourceQueue{Oid: 1, Name: `"everythingQueue"`, ActiveStatements: 7, MaxCost: "32.80", CostOvercommit: true, MinCost: "22.80", Priority: "low", MemoryLimit: "2GB"}
 			emptyMetadataMap := map[backup.UniqueID]backup.ObjectMetadata{}
 
-			backup.PrintCreateResourceQueueStatements(backupfile, toc, []backup.ResourceQueue{everythingQueue}, emptyMetadataMap)
+			testBackup.PrintCreateResourceQueueStatements(backupfile, toc, []backup.ResourceQueue{everythingQueue}, emptyMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, `DROP RESOURCE QUEUE "everythingQueue"`)
@@ -138,7 +138,7 @@ var _ = Describe("backup integration create statement tests", func() {
 			someGroup := backup.ResourceGroup{Oid: 1, Name: "some_group", CPURateLimit: "10", MemoryLimit: "20", Concurrency: "15", MemorySharedQuota: "25", MemorySpillRatio: "30", MemoryAuditor: "0", Cpuset: "-1"}
 			emptyMetadataMap := map[backup.UniqueID]backup.ObjectMetadata{}
 
-			backup.PrintCreateResourceGroupStatements(backupfile, toc, []backup.ResourceGroup{someGroup}, emptyMetadataMap)
+			testBackup.PrintCreateResourceGroupStatements(backupfile, toc, []backup.ResourceGroup{someGroup}, emptyMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, `DROP RESOURCE GROUP some_group`)
@@ -193,7 +193,7 @@ var _ = Describe("backup integration create statement tests", func() {
 			defaultGroup := backup.ResourceGroup{Oid: 1, Name: "default_group", CPURateLimit: "10", MemoryLimit: "20", Concurrency: "15", MemorySharedQuota: "25", MemorySpillRatio: "30", MemoryAuditor: "0", Cpuset: "-1"}
 			emptyMetadataMap := map[backup.UniqueID]backup.ObjectMetadata{}
 
-			backup.PrintCreateResourceGroupStatements(backupfile, toc, []backup.ResourceGroup{defaultGroup}, emptyMetadataMap)
+			testBackup.PrintCreateResourceGroupStatements(backupfile, toc, []backup.ResourceGroup{defaultGroup}, emptyMetadataMap)
 
 			hunks := regexp.MustCompile(";\n\n").Split(buffer.String(), 5)
 			for i := 0; i < 5; i++ {
@@ -240,7 +240,7 @@ var _ = Describe("backup integration create statement tests", func() {
 				role1.ResGroup = ""
 			}
 
-			backup.PrintCreateRoleStatements(backupfile, toc, []backup.Role{role1}, emptyMetadataMap)
+			testBackup.PrintCreateRoleStatements(backupfile, toc, []backup.Role{role1}, emptyMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, `DROP ROLE "role1"`)
@@ -299,7 +299,7 @@ var _ = Describe("backup integration create statement tests", func() {
 			}
 			metadataMap := testutils.DefaultMetadataMap("ROLE", false, false, true, includeSecurityLabels)
 
-			backup.PrintCreateRoleStatements(backupfile, toc, []backup.Role{role1}, metadataMap)
+			testBackup.PrintCreateRoleStatements(backupfile, toc, []backup.Role{role1}, metadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, `DROP ROLE "role1"`)
@@ -318,7 +318,7 @@ var _ = Describe("backup integration create statement tests", func() {
 			testutils.SkipIfBefore6(connectionPool)
 
 			role1.Replication = true
-			backup.PrintCreateRoleStatements(backupfile, toc, []backup.Role{role1}, emptyMetadataMap)
+			testBackup.PrintCreateRoleStatements(backupfile, toc, []backup.Role{role1}, emptyMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, `DROP ROLE "role1"`)
@@ -426,14 +426,14 @@ var _ = Describe("backup integration create statement tests", func() {
 			}
 		})
 		It("creates a basic tablespace", func() {
-			numTablespaces := len(backup.GetTablespaces(connectionPool))
+			numTablespaces := len(testBackup.GetTablespaces(connectionPool))
 			emptyMetadataMap := backup.MetadataMap{}
-			backup.PrintCreateTablespaceStatements(backupfile, toc, []backup.Tablespace{expectedTablespace}, emptyMetadataMap)
+			testBackup.PrintCreateTablespaceStatements(backupfile, toc, []backup.Tablespace{expectedTablespace}, emptyMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLESPACE test_tablespace")
 
-			resultTablespaces := backup.GetTablespaces(connectionPool)
+			resultTablespaces := testBackup.GetTablespaces(connectionPool)
 			Expect(resultTablespaces).To(HaveLen(numTablespaces + 1))
 			for _, tablespace := range resultTablespaces {
 				if tablespace.Tablespace == "test_tablespace" {
@@ -451,9 +451,9 @@ var _ = Describe("backup integration create statement tests", func() {
 				SegmentLocations: []string{"content0='/tmp/test_dir1'", "content1='/tmp/test_dir2'"},
 				Options:          "seq_page_cost=123",
 			}
-			numTablespaces := len(backup.GetTablespaces(connectionPool))
+			numTablespaces := len(testBackup.GetTablespaces(connectionPool))
 			emptyMetadataMap := backup.MetadataMap{}
-			backup.PrintCreateTablespaceStatements(backupfile, toc, []backup.Tablespace{expectedTablespace}, emptyMetadataMap)
+			testBackup.PrintCreateTablespaceStatements(backupfile, toc, []backup.Tablespace{expectedTablespace}, emptyMetadataMap)
 
 			gbuffer := gbytes.BufferWithBytes([]byte(buffer.String()))
 			entries, _ := testutils.SliceBufferByEntries(toc.GlobalEntries, gbuffer)
@@ -462,7 +462,7 @@ var _ = Describe("backup integration create statement tests", func() {
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLESPACE test_tablespace")
 			testhelper.AssertQueryRuns(connectionPool, setOptions)
 
-			resultTablespaces := backup.GetTablespaces(connectionPool)
+			resultTablespaces := testBackup.GetTablespaces(connectionPool)
 			Expect(resultTablespaces).To(HaveLen(numTablespaces + 1))
 			for _, tablespace := range resultTablespaces {
 				if tablespace.Tablespace == "test_tablespace" {
@@ -473,10 +473,10 @@ var _ = Describe("backup integration create statement tests", func() {
 			Fail("Tablespace 'test_tablespace' was not created")
 		})
 		It("creates a tablespace with permissions, an owner, security label, and a comment", func() {
-			numTablespaces := len(backup.GetTablespaces(connectionPool))
+			numTablespaces := len(testBackup.GetTablespaces(connectionPool))
 			tablespaceMetadataMap := testutils.DefaultMetadataMap("TABLESPACE", true, true, true, includeSecurityLabels)
 			tablespaceMetadata := tablespaceMetadataMap[expectedTablespace.GetUniqueID()]
-			backup.PrintCreateTablespaceStatements(backupfile, toc, []backup.Tablespace{expectedTablespace}, tablespaceMetadataMap)
+			testBackup.PrintCreateTablespaceStatements(backupfile, toc, []backup.Tablespace{expectedTablespace}, tablespaceMetadataMap)
 
 			if connectionPool.Version.AtLeast("6") {
 				/*
@@ -494,8 +494,8 @@ var _ = Describe("backup integration create statement tests", func() {
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLESPACE test_tablespace")
 			}
 
-			resultTablespaces := backup.GetTablespaces(connectionPool)
-			resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_TABLESPACE)
+			resultTablespaces := testBackup.GetTablespaces(connectionPool)
+			resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TABLESPACE)
 			Expect(resultTablespaces).To(HaveLen(numTablespaces + 1))
 			for _, tablespace := range resultTablespaces {
 				if tablespace.Tablespace == "test_tablespace" {
@@ -17,7 +17,7 @@ var _ = Describe("backup integration tests", func() {
 			 * We shouldn't need to run any setup queries, because we're using
 			 * the default values of these GUCs.
 			 */
-			results := backup.GetSessionGUCs(connectionPool)
+			results := testBackup.GetSessionGUCs(connectionPool)
 			Expect(results.ClientEncoding).To(Equal("UTF8"))
 		})
 	})
@@ -544,7 +544,7 @@ CREATEEXTTABLE (protocol='gphdfs', type='writable')`
 			}
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLESPACE test_tablespace")
 
-			resultTablespaces := backup.GetTablespaces(connectionPool)
+			resultTablespaces := testBackup.GetTablespaces(connectionPool)
 
 			for _, tablespace := range resultTablespaces {
 				if tablespace.Tablespace == "test_tablespace" {
@@ -566,7 +566,7 @@ CREATEEXTTABLE (protocol='gphdfs', type='writable')`
 
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLESPACE test_tablespace")
 
-			resultTablespaces := backup.GetTablespaces(connectionPool)
+			resultTablespaces := testBackup.GetTablespaces(connectionPool)
 
 			for _, tablespace := range resultTablespaces {
 				if tablespace.Tablespace == "test_tablespace" {
@@ -119,7 +119,7 @@ bar";
 				"public.newline\nbar", // important to use double quotes to allow \n to become newline
 			}
 
-			backup.DBValidate(connectionPool, tableList, false)
+			testBackup.DBValidate(connectionPool, tableList, false)
 		})
 	})
 	Describe("ExpandIncludesForPartitions", func() {
@@ -31,7 +31,7 @@ var _ = Describe("backup integration create statement tests", func() {
 		})
 		It("creates a basic index", func() {
 			indexes := []backup.IndexDefinition{{Oid: 0, Name: "index1", OwningSchema: "public", OwningTable: "testtable", Def: "CREATE INDEX index1 ON public.testtable USING btree (i)"}}
-			backup.PrintCreateIndexStatements(backupfile, toc, indexes, indexMetadataMap)
+			testBackup.PrintCreateIndexStatements(backupfile, toc, indexes, indexMetadataMap)
 
 			//Create table whose columns we can index
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.testtable(i int)")
@@ -39,13 +39,13 @@ var _ = Describe("backup integration create statement tests", func() {
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
-			resultIndexes := backup.GetIndexes(connectionPool)
+			resultIndexes := testBackup.GetIndexes(connectionPool)
 			Expect(resultIndexes).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&resultIndexes[0], &indexes[0], "Oid")
 		})
 		It("creates an index used for clustering", func() {
 			indexes := []backup.IndexDefinition{{Oid: 0, Name: "index1", OwningSchema: "public", OwningTable: "testtable", Def: "CREATE INDEX index1 ON public.testtable USING btree (i)", IsClustered: true}}
-			backup.PrintCreateIndexStatements(backupfile, toc, indexes, indexMetadataMap)
+			testBackup.PrintCreateIndexStatements(backupfile, toc, indexes, indexMetadataMap)
 
 			//Create table whose columns we can index
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.testtable(i int)")
@@ -53,7 +53,7 @@ var _ = Describe("backup integration create statement tests", func() {
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
-			resultIndexes := backup.GetIndexes(connectionPool)
+			resultIndexes := testBackup.GetIndexes(connectionPool)
 			Expect(resultIndexes).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&resultIndexes[0], &indexes[0], "Oid")
 		})
@@ -61,7 +61,7 @@ var _ = Describe("backup integration create statement tests", func() {
 			indexes := []backup.IndexDefinition{{Oid: 1, Name: "index1", OwningSchema: "public", OwningTable: "testtable", Def: "CREATE INDEX index1 ON public.testtable USING btree (i)"}}
 			indexMetadataMap = testutils.DefaultMetadataMap("INDEX", false, false, true, false)
 			indexMetadata := indexMetadataMap[indexes[0].GetUniqueID()]
-			backup.PrintCreateIndexStatements(backupfile, toc, indexes, indexMetadataMap)
+			testBackup.PrintCreateIndexStatements(backupfile, toc, indexes, indexMetadataMap)
 
 			//Create table whose columns we can index
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.testtable(i int)")
@@ -69,8 +69,8 @@ var _ = Describe("backup integration create statement tests", func() {
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
-			resultIndexes := backup.GetIndexes(connectionPool)
-			resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, backup.TYPE_INDEX)
+			resultIndexes := testBackup.GetIndexes(connectionPool)
+			resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_INDEX)
 			resultMetadata := resultMetadataMap[resultIndexes[0].GetUniqueID()]
 			Expect(resultIndexes).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&resultIndexes[0], &indexes[0], "Oid")
@@ -84,7 +84,7 @@ var _ = Describe("backup integration create statement tests", func() {
 			}
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLESPACE test_tablespace")
 			indexes := []backup.IndexDefinition{{Oid: 0, Name: "index1", OwningSchema: "public", OwningTable: "testtable", Tablespace: "test_tablespace", Def: "CREATE INDEX index1 ON public.testtable USING btree (i)"}}
-			backup.PrintCreateIndexStatements(backupfile, toc, indexes, indexMetadataMap)
+			testBackup.PrintCreateIndexStatements(backupfile, toc, indexes, indexMetadataMap)
 
 			//Create table whose columns we can index
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.testtable(i int)")
@@ -92,7 +92,7 @@ var _ = Describe("backup integration create statement tests", func() {
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
-			resultIndexes := backup.GetIndexes(connectionPool)
+			resultIndexes := testBackup.GetIndexes(connectionPool)
 			Expect(resultIndexes).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&resultIndexes[0], &indexes[0], "Oid")
 		})
@@ -101,14 +101,14 @@ var _ = Describe("backup integration create statement tests", func() {
 			index.Def = "CREATE UNIQUE INDEX index1 ON public.testtable USING btree (i)"
 			index.IsReplicaIdentity = true
 			indexes := []backup.IndexDefinition{index}
-			backup.PrintCreateIndexStatements(backupfile, toc, indexes, indexMetadataMap)
+			testBackup.PrintCreateIndexStatements(backupfile, toc, indexes, indexMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.testtable(i int NOT NULL)")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.testtable")
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
-			resultIndexes := backup.GetIndexes(connectionPool)
+			resultIndexes := testBackup.GetIndexes(connectionPool)
 			Expect(resultIndexes).To(HaveLen(1))
 			resultIndex := resultIndexes[0]
 			structmatcher.ExpectStructsToMatchExcluding(&resultIndex, &index, "Oid")
@@ -129,14 +129,14 @@ var _ = Describe("backup integration create statement tests", func() {
 		})
 		It("creates a basic rule", func() {
 			rules := []backup.RuleDefinition{{Oid: 0, Name: "update_notify", OwningSchema: "public", OwningTable: "testtable", Def: ruleDef}}
-			backup.PrintCreateRuleStatements(backupfile, toc, rules, ruleMetadataMap)
+			testBackup.PrintCreateRuleStatements(backupfile, toc, rules, ruleMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.testtable(i int)")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.testtable")
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
-			resultRules := backup.GetRules(connectionPool)
+			resultRules := testBackup.GetRules(connectionPool)
 			Expect(resultRules).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&resultRules[0], &rules[0], "Oid")
 		})
@@ -144,7 +144,7 @@ var _ = Describe("backup integration create statement tests", func() {
 			rules := []backup.RuleDefinition{{Oid: 1, Name: "update_notify", OwningSchema: "public", OwningTable: "testtable", Def: ruleDef}}
 			ruleMetadataMap = testutils.DefaultMetadataMap("RULE", false, false, true, false)
 			ruleMetadata := ruleMetadataMap[rules[0].GetUniqueID()]
-			backup.PrintCreateRuleStatements(backupfile, toc, rules, ruleMetadataMap)
+			testBackup.PrintCreateRuleStatements(backupfile, toc, rules, ruleMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.testtable(i int)")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.testtable")
@@ -152,8 +152,8 @@ var _ = Describe("backup integration create statement tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
 			rules[0].Oid = testutils.OidFromObjectName(connectionPool, "", "update_notify", backup.TYPE_RULE)
-			resultRules := backup.GetRules(connectionPool)
-			resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, backup.TYPE_RULE)
+			resultRules := testBackup.GetRules(connectionPool)
+			resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_RULE)
 			resultMetadata := resultMetadataMap[resultRules[0].GetUniqueID()]
 			Expect(resultRules).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&resultRules[0], &rules[0], "Oid")
@@ -169,14 +169,14 @@ var _ = Describe("backup integration create statement tests", func() {
 		})
 		It("creates a basic trigger", func() {
 			triggers := []backup.TriggerDefinition{{Oid: 0, Name: "sync_testtable", OwningSchema: "public", OwningTable: "testtable", Def: `CREATE TRIGGER sync_testtable AFTER INSERT OR DELETE OR UPDATE ON public.testtable FOR EACH STATEMENT EXECUTE PROCEDURE "RI_FKey_check_ins"()`}}
-			backup.PrintCreateTriggerStatements(backupfile, toc, triggers, triggerMetadataMap)
+			testBackup.PrintCreateTriggerStatements(backupfile, toc, triggers, triggerMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.testtable(i int)")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.testtable")
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
-			resultTriggers := backup.GetTriggers(connectionPool)
+			resultTriggers := testBackup.GetTriggers(connectionPool)
 			Expect(resultTriggers).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&resultTriggers[0], &triggers[0], "Oid")
 		})
@@ -184,7 +184,7 @@ var _ = Describe("backup integration create statement tests", func() {
 			triggers := []backup.TriggerDefinition{{Oid: 1, Name: "sync_testtable", OwningSchema: "public", OwningTable: "testtable", Def: `CREATE TRIGGER sync_testtable AFTER INSERT OR DELETE OR UPDATE ON public.testtable FOR EACH STATEMENT EXECUTE PROCEDURE "RI_FKey_check_ins"()`}}
 			triggerMetadataMap = testutils.DefaultMetadataMap("RULE", false, false, true, false)
 			triggerMetadata := triggerMetadataMap[triggers[0].GetUniqueID()]
-			backup.PrintCreateTriggerStatements(backupfile, toc, triggers, triggerMetadataMap)
+			testBackup.PrintCreateTriggerStatements(backupfile, toc, triggers, triggerMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.testtable(i int)")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.testtable")
@@ -192,8 +192,8 @@ var _ = Describe("backup integration create statement tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
 			triggers[0].Oid = testutils.OidFromObjectName(connectionPool, "", "sync_testtable", backup.TYPE_TRIGGER)
-			resultTriggers := backup.GetTriggers(connectionPool)
-			resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, backup.TYPE_TRIGGER)
+			resultTriggers := testBackup.GetTriggers(connectionPool)
+			resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TRIGGER)
 			resultMetadata := resultMetadataMap[resultTriggers[0].GetUniqueID()]
 			Expect(resultTriggers).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&resultTriggers[0], &triggers[0], "Oid")
@@ -213,7 +213,7 @@ AS $$ BEGIN RAISE EXCEPTION 'exception'; END; $$;`)
 		It("creates a basic event trigger", func() {
 			eventTriggers := []backup.EventTrigger{{Oid: 1, Name: "testeventtrigger1", Event: "ddl_command_start", FunctionName: "abort_any_command", Enabled: "O"}}
 			eventTriggerMetadataMap := backup.MetadataMap{}
-			backup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, eventTriggerMetadataMap)
+			testBackup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, eventTriggerMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP EVENT TRIGGER testeventtrigger1")
@@ -226,7 +226,7 @@ AS $$ BEGIN RAISE EXCEPTION 'exception'; END; $$;`)
 		It("creates an event trigger with multiple filter tags", func() {
 			eventTriggers := []backup.EventTrigger{{Oid: 1, Name: "testeventtrigger1", Event: "ddl_command_start", FunctionName: "abort_any_command", Enabled: "O", EventTags: `'DROP FUNCTION', 'DROP TABLE'`}}
 			eventTriggerMetadataMap := backup.MetadataMap{}
-			backup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, eventTriggerMetadataMap)
+			testBackup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, eventTriggerMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP EVENT TRIGGER testeventtrigger1")
@@ -239,7 +239,7 @@ AS $$ BEGIN RAISE EXCEPTION 'exception'; END; $$;`)
 		It("creates an event trigger with a single filter tag and enable option", func() {
 			eventTriggers := []backup.EventTrigger{{Oid: 1, Name: "testeventtrigger1", Event: "ddl_command_start", FunctionName: "abort_any_command", Enabled: "R", EventTags: `'DROP FUNCTION'`}}
 			eventTriggerMetadataMap := backup.MetadataMap{}
-			backup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, eventTriggerMetadataMap)
+			testBackup.PrintCreateEventTriggerStatements(backupfile, toc, eventTriggers, eventTriggerMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP EVENT TRIGGER testeventtrigger1")
@@ -254,13 +254,13 @@ AS $$ BEGIN RAISE EXCEPTION 'exception'; END; $$;`)
 			eventTriggerMetadataMap := testutils.DefaultMetadataMap("EVENT TRIGGER", false, true, true, includeSecurityLabels)
 			eventTriggerMetadata := eventTriggerMetadataMap[eventTriggers[0].GetUniqueID()]
 
-			backup.PrintCreateEventTriggerStatements(backupfile, toc, []backup.EventTrigger{eventTriggers[0]}, eventTriggerMetadataMap)
+			testBackup.PrintCreateEventTriggerStatements(backupfile, toc, []backup.EventTrigger{eventTriggers[0]}, eventTriggerMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP EVENT TRIGGER test_event_trigger")
 
 			resultEventTriggers := backup.GetEventTriggers(connectionPool)
-			resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_EVENTTRIGGER)
+			resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_EVENTTRIGGER)
 
 			Expect(resultEventTriggers).To(HaveLen(1))
 			uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "", "test_event_trigger", backup.TYPE_EVENTTRIGGER)
@@ -35,7 +35,7 @@ var _ = Describe("backup integration tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.simple_table(i int)")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.simple_table")
 
-			results := backup.GetIndexes(connectionPool)
+			results := testBackup.GetIndexes(connectionPool)
 
 			Expect(results).To(BeEmpty())
 		})
@@ -50,7 +50,7 @@ var _ = Describe("backup integration tests", func() {
 			index1 := backup.IndexDefinition{Oid: 0, Name: "simple_table_idx1", OwningSchema: "public", OwningTable: "simple_table", Def: "CREATE INDEX simple_table_idx1 ON public.simple_table USING btree (i)"}
 			index2 := backup.IndexDefinition{Oid: 1, Name: "simple_table_idx2", OwningSchema: "public", OwningTable: "simple_table", Def: "CREATE INDEX simple_table_idx2 ON public.simple_table USING btree (j)"}
 
-			results := backup.GetIndexes(connectionPool)
+			results := testBackup.GetIndexes(connectionPool)
 
 			Expect(results).To(HaveLen(2))
 			results[0].Oid = testutils.OidFromObjectName(connectionPool, "", "simple_table_idx1", backup.TYPE_INDEX)
@@ -69,7 +69,7 @@ var _ = Describe("backup integration tests", func() {
 			index1 := backup.IndexDefinition{Oid: 0, Name: "simple_table_idx1", OwningSchema: "public", OwningTable: "simple_table", Def: "CREATE UNIQUE INDEX simple_table_idx1 ON public.simple_table USING btree (i)"}
 			index2 := backup.IndexDefinition{Oid: 1, Name: "simple_table_idx2", OwningSchema: "public", OwningTable: "simple_table", Def: "CREATE INDEX simple_table_idx2 ON public.simple_table USING btree (j)"}
 
-			results := backup.GetIndexes(connectionPool)
+			results := testBackup.GetIndexes(connectionPool)
 			supportsConstraint := make([]backup.IndexDefinition, 0)
 			userIndex := make([]backup.IndexDefinition, 0)
 			for _, indexDef := range results {
@@ -99,7 +99,7 @@ PARTITION BY RANGE (date)
 
 			index1 := backup.IndexDefinition{Oid: 0, Name: "part_idx", OwningSchema: "public", OwningTable: "part", Def: "CREATE INDEX part_idx ON public.part USING btree (id)"}
 
-			results := backup.GetIndexes(connectionPool)
+			results := testBackup.GetIndexes(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&index1, &results[0], "Oid")
@@ -118,7 +118,7 @@ PARTITION BY RANGE (date)
 
 			index1 := backup.IndexDefinition{Oid: 0, Name: "simple_table_idx", OwningSchema: "public", OwningTable: "simple_table", Tablespace: "test_tablespace", Def: "CREATE INDEX simple_table_idx ON public.simple_table USING btree (i)"}
 
-			results := backup.GetIndexes(connectionPool)
+			results := testBackup.GetIndexes(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			results[0].Oid = testutils.OidFromObjectName(connectionPool, "", "simple_table_idx", backup.TYPE_INDEX)
@@ -140,7 +140,7 @@ PARTITION BY RANGE (date)
 
 			index1 := backup.IndexDefinition{Oid: 0, Name: "simple_table_idx1", OwningSchema: "testschema", OwningTable: "simple_table", Def: "CREATE INDEX simple_table_idx1 ON testschema.simple_table USING btree (i)"}
 
-			results := backup.GetIndexes(connectionPool)
+			results := testBackup.GetIndexes(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			results[0].Oid = testutils.OidFromObjectName(connectionPool, "", "simple_table_idx1", backup.TYPE_INDEX)
@@ -162,7 +162,7 @@ PARTITION BY RANGE (date)
 
 			index1 := backup.IndexDefinition{Oid: 0, Name: "simple_table_idx1", OwningSchema: "testschema", OwningTable: "simple_table", Def: "CREATE INDEX simple_table_idx1 ON testschema.simple_table USING btree (i)"}
 
-			results := backup.GetIndexes(connectionPool)
+			results := testBackup.GetIndexes(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			results[0].Oid = testutils.OidFromObjectName(connectionPool, "", "simple_table_idx1", backup.TYPE_INDEX)
@@ -178,7 +178,7 @@ PARTITION BY RANGE (date)
 
 			index1 := backup.IndexDefinition{Oid: 0, Name: "simple_table_idx1", OwningSchema: "public", OwningTable: "simple_table", Def: "CREATE INDEX simple_table_idx1 ON public.simple_table USING btree (i)", IsClustered: true}
 
-			results := backup.GetIndexes(connectionPool)
+			results := testBackup.GetIndexes(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			results[0].Oid = testutils.OidFromObjectName(connectionPool, "", "simple_table_idx1", backup.TYPE_INDEX)
@@ -203,7 +203,7 @@ PARTITION BY RANGE (date)
 			rule1 = backup.RuleDefinition{Oid: 0, Name: "double_insert", OwningSchema: "public", OwningTable: "rule_table1", Def: ruleDef1}
 		})
 		It("returns no slice when no rule exists", func() {
-			results := backup.GetRules(connectionPool)
+			results := testBackup.GetRules(connectionPool)
 
 			Expect(results).To(BeEmpty())
 		})
@@ -219,7 +219,7 @@ PARTITION BY RANGE (date)
 
 			rule2 := backup.RuleDefinition{Oid: 1, Name: "update_notify", OwningSchema: "public", OwningTable: "rule_table1", Def: ruleDef2}
 
-			results := backup.GetRules(connectionPool)
+			results := testBackup.GetRules(connectionPool)
 
 			Expect(results).To(HaveLen(2))
 			structmatcher.ExpectStructsToMatchExcluding(&rule1, &results[0], "Oid")
@@ -238,7 +238,7 @@ PARTITION BY RANGE (date)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP RULE double_insert ON testschema.rule_table1")
 			backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "public")
 
-			results := backup.GetRules(connectionPool)
+			results := testBackup.GetRules(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&rule1, &results[0], "Oid")
@@ -256,15 +256,40 @@ PARTITION BY RANGE (date)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP RULE double_insert ON testschema.rule_table1")
 			backupCmdFlags.Set(utils.INCLUDE_RELATION, "public.rule_table1")
 
-			results := backup.GetRules(connectionPool)
+			results := testBackup.GetRules(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&rule1, &results[0], "Oid")
 		})
+		It("returns rules on views but not the rules that define the views", func() {
+			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.rule_table1(i int)")
+			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.rule_table1")
+			testhelper.AssertQueryRuns(connectionPool, "CREATE VIEW public.rule_view AS SELECT i FROM public.rule_table1")
+			defer testhelper.AssertQueryRuns(connectionPool, "DROP VIEW public.rule_view")
+			testhelper.AssertQueryRuns(connectionPool, "CREATE RULE view_insert AS ON INSERT TO public.rule_view DO INSTEAD INSERT INTO public.rule_table1 (i) VALUES (new.i)")
+			defer testhelper.AssertQueryRuns(connectionPool, "DROP RULE view_insert ON public.rule_view")
+
+			results := testBackup.GetRules(connectionPool)
+
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].Name).To(Equal("view_insert"))
+			Expect(results[0].OwningTable).To(Equal("rule_view"))
+		})
+		It("returns rules whose names end in RETURN", func() {
+			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.rule_table1(i int)")
+			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.rule_table1")
+			testhelper.AssertQueryRuns(connectionPool, "CREATE RULE \"LOG_RETURN\" AS ON INSERT TO public.rule_table1 DO NOTIFY rule_table1")
+			defer testhelper.AssertQueryRuns(connectionPool, "DROP RULE \"LOG_RETURN\" ON public.rule_table1")
+
+			results := testBackup.GetRules(connectionPool)
+
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].Name).To(Equal(`"LOG_RETURN"`))
+		})
 	})
 	Describe("GetTriggers", func() {
 		It("returns no slice when no trigger exists", func() {
-			results := backup.GetTriggers(connectionPool)
+			results := testBackup.GetTriggers(connectionPool)
 
 			Expect(results).To(BeEmpty())
 		})
@@ -281,7 +306,7 @@ PARTITION BY RANGE (date)
 			trigger1 := backup.TriggerDefinition{Oid: 0, Name: "sync_trigger_table1", OwningSchema: "public", OwningTable: "trigger_table1", Def: `CREATE TRIGGER sync_trigger_table1 AFTER INSERT OR DELETE OR UPDATE ON public.trigger_table1 FOR EACH STATEMENT EXECUTE PROCEDURE "RI_FKey_check_ins"()`}
 			trigger2 := backup.TriggerDefinition{Oid: 1, Name: "sync_trigger_table2", OwningSchema: "public", OwningTable: "trigger_table2", Def: `CREATE TRIGGER sync_trigger_table2 AFTER INSERT OR DELETE OR UPDATE ON public.trigger_table2 FOR EACH STATEMENT EXECUTE PROCEDURE "RI_FKey_check_ins"()`}
 
-			results := backup.GetTriggers(connectionPool)
+			results := testBackup.GetTriggers(connectionPool)
 
 			Expect(results).To(HaveLen(2))
 			structmatcher.ExpectStructsToMatchExcluding(&trigger1, &results[0], "Oid")
@@ -294,7 +319,7 @@ PARTITION BY RANGE (date)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.trigger_table2")
 			testhelper.AssertQueryRuns(connectionPool, "ALTER TABLE public.trigger_table2 ADD CONSTRAINT fkc FOREIGN KEY (j) REFERENCES public.trigger_table1 (i) ON UPDATE RESTRICT ON DELETE RESTRICT")
 
-			results := backup.GetTriggers(connectionPool)
+			results := testBackup.GetTriggers(connectionPool)
 
 			Expect(results).To(BeEmpty())
 		})
@@ -313,7 +338,7 @@ PARTITION BY RANGE (date)
 
 			trigger1 := backup.TriggerDefinition{Oid: 0, Name: "sync_trigger_table1", OwningSchema: "testschema", OwningTable: "trigger_table1", Def: `CREATE TRIGGER sync_trigger_table1 AFTER INSERT OR DELETE OR UPDATE ON testschema.trigger_table1 FOR EACH STATEMENT EXECUTE PROCEDURE "RI_FKey_check_ins"()`}
 
-			results := backup.GetTriggers(connectionPool)
+			results := testBackup.GetTriggers(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&trigger1, &results[0], "Oid")
@@ -333,7 +358,7 @@ PARTITION BY RANGE (date)
 
 			trigger1 := backup.TriggerDefinition{Oid: 0, Name: "sync_trigger_table1", OwningSchema: "testschema", OwningTable: "trigger_table1", Def: `CREATE TRIGGER sync_trigger_table1 AFTER INSERT OR DELETE OR UPDATE ON testschema.trigger_table1 FOR EACH STATEMENT EXECUTE PROCEDURE "RI_FKey_check_ins"()`}
 
-			results := backup.GetTriggers(connectionPool)
+			results := testBackup.GetTriggers(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&trigger1, &results[0], "Oid")
@@ -396,4 +421,35 @@ AS $$ BEGIN RAISE EXCEPTION 'exception'; END; $$;`)
 
 		})
 	})
+	Describe("GetExtendedStatistics", func() {
+		BeforeEach(func() {
+			testutils.SkipIfBefore7(connectionPool)
+			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.stats_table(i int, j int) DISTRIBUTED BY (i)")
+		})
+		AfterEach(func() {
+			testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.stats_table")
+		})
+		It("returns a slice of extended statistics objects", func() {
+			testhelper.AssertQueryRuns(connectionPool, "CREATE STATISTICS public.teststats (ndistinct, dependencies) ON i, j FROM public.stats_table")
+
+			results := testBackup.GetExtendedStatistics(connectionPool)
+
+			statistic := backup.ExtendedStatistic{Oid: 1, Schema: "public", Name: "teststats", OwningSchema: "public", OwningTable: "stats_table", Def: "CREATE STATISTICS public.teststats (ndistinct, dependencies) ON i, j FROM public.stats_table"}
+			Expect(results).To(HaveLen(1))
+			structmatcher.ExpectStructsToMatchExcluding(&statistic, &results[0], "Oid")
+		})
+		It("returns a slice of extended statistics objects in a filtered schema", func() {
+			testhelper.AssertQueryRuns(connectionPool, "CREATE STATISTICS public.teststats ON i, j FROM public.stats_table")
+			testhelper.AssertQueryRuns(connectionPool, "CREATE SCHEMA testschema")
+			defer testhelper.AssertQueryRuns(connectionPool, "DROP SCHEMA testschema CASCADE")
+			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE testschema.stats_table(i int, j int) DISTRIBUTED BY (i)")
+			testhelper.AssertQueryRuns(connectionPool, "CREATE STATISTICS testschema.teststats ON i, j FROM testschema.stats_table")
+			backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
+
+			results := testBackup.GetExtendedStatistics(connectionPool)
+
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].Schema).To(Equal("testschema"))
+		})
+	})
 })
@@ -25,7 +25,7 @@ var _ = Describe("backup integration create statement tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "ALTER DEFAULT PRIVILEGES FOR ROLE testrole REVOKE ALL ON TABLES FROM PUBLIC;")
 
-			resultPrivileges := backup.GetDefaultPrivileges(connectionPool)
+			resultPrivileges := testBackup.GetDefaultPrivileges(connectionPool)
 
 			Expect(resultPrivileges).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&defaultPrivileges[0], &resultPrivileges[0], "Oid")
@@ -41,7 +41,7 @@ var _ = Describe("backup integration create statement tests", func() {
 			defer testhelper.AssertQueryRuns(connectionPool, "ALTER DEFAULT PRIVILEGES FOR ROLE testrole GRANT ALL ON SEQUENCES TO testrole;")
 			defer testhelper.AssertQueryRuns(connectionPool, "ALTER DEFAULT PRIVILEGES FOR ROLE testrole REVOKE ALL ON SEQUENCES FROM testrole;")
 
-			resultPrivileges := backup.GetDefaultPrivileges(connectionPool)
+			resultPrivileges := testBackup.GetDefaultPrivileges(connectionPool)
 
 			Expect(resultPrivileges).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&defaultPrivileges[0], &resultPrivileges[0], "Oid")
@@ -25,7 +25,7 @@ var _ = Describe("backup integration tests", func() {
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.baz")
 				testhelper.AssertQueryRuns(connectionPool, "GRANT ALL ON TABLE public.baz TO anothertestrole")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_RELATION)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_RELATION)
 
 				fooUniqueID := testutils.UniqueIDFromObjectName(connectionPool, "public", "foo", backup.TYPE_RELATION)
 				barUniqueID := testutils.UniqueIDFromObjectName(connectionPool, "public", "bar", backup.TYPE_RELATION)
@@ -55,14 +55,14 @@ var _ = Describe("backup integration tests", func() {
 					expectedMetadata.SecurityLabel = "unclassified"
 				}
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_DATABASE)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_DATABASE)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "", "testdb", backup.TYPE_DATABASE)
 				resultMetadata := resultMetadataMap[uniqueID]
 				structmatcher.ExpectStructsToMatchExcluding(&expectedMetadata, &resultMetadata, "Oid")
 			})
 			It("returns a slice of default metadata for a role", func() {
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_ROLE)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_ROLE)
 				numRoles := len(resultMetadataMap)
 
 				testhelper.AssertQueryRuns(connectionPool, `CREATE ROLE testuser`)
@@ -70,7 +70,7 @@ var _ = Describe("backup integration tests", func() {
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON ROLE testuser IS 'This is a role comment.'")
 				testutils.CreateSecurityLabelIfGPDB6(connectionPool, "ROLE", "testuser")
 
-				resultMetadataMap = backup.GetMetadataForObjectType(connectionPool, backup.TYPE_ROLE)
+				resultMetadataMap = testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_ROLE)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "", "testuser", backup.TYPE_ROLE)
 				expectedMetadata := testutils.DefaultMetadata("ROLE", false, false, true, includeSecurityLabels)
@@ -86,7 +86,7 @@ var _ = Describe("backup integration tests", func() {
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.testtable")
 				testhelper.AssertQueryRuns(connectionPool, `ALTER TABLE public.testtable OWNER TO "Role1"`)
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_RELATION)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_RELATION)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "public", "testtable", backup.TYPE_RELATION)
 				expectedMetadata := backup.ObjectMetadata{Privileges: []backup.ACL{}, Owner: `"Role1"`}
@@ -101,7 +101,7 @@ var _ = Describe("backup integration tests", func() {
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON TABLE public.testtable IS 'This is a table comment.'")
 				testutils.CreateSecurityLabelIfGPDB6(connectionPool, "TABLE", "public.testtable")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_RELATION)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_RELATION)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "public", "testtable", backup.TYPE_RELATION)
 				expectedMetadata := testutils.DefaultMetadata("TABLE", true, true, true, includeSecurityLabels)
@@ -116,7 +116,7 @@ var _ = Describe("backup integration tests", func() {
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON SEQUENCE public.testsequence IS 'This is a sequence comment.'")
 				testutils.CreateSecurityLabelIfGPDB6(connectionPool, "SEQUENCE", "public.testsequence")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_RELATION)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_RELATION)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "public", "testsequence", backup.TYPE_RELATION)
 				expectedMetadata := testutils.DefaultMetadata("SEQUENCE", true, true, true, includeSecurityLabels)
@@ -130,7 +130,7 @@ var _ = Describe("backup integration tests", func() {
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON LANGUAGE plpythonu IS 'This is a language comment.'")
 				testutils.CreateSecurityLabelIfGPDB6(connectionPool, "LANGUAGE", "plpythonu")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_PROCLANGUAGE)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_PROCLANGUAGE)
 
 				var expectedMetadata backup.ObjectMetadata
 				if connectionPool.Version.Before("5") {
@@ -152,7 +152,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "REVOKE ALL ON FUNCTION public.add(integer, integer) FROM testrole")
 				testutils.CreateSecurityLabelIfGPDB6(connectionPool, "FUNCTION", "public.add(integer, integer)")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_FUNCTION)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_FUNCTION)
 				Expect(resultMetadataMap).To(HaveLen(1))
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "public", "add", backup.TYPE_FUNCTION)
@@ -184,7 +184,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON FUNCTION public.add(integer, integer) IS 'This is a function comment.'")
 				testutils.CreateSecurityLabelIfGPDB6(connectionPool, "FUNCTION", "public.add(integer, integer)")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_FUNCTION)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_FUNCTION)
 				Expect(resultMetadataMap).To(HaveLen(1))
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "public", "add", backup.TYPE_FUNCTION)
@@ -201,7 +201,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON VIEW public.testview IS 'This is a view comment.'")
 				testutils.CreateSecurityLabelIfGPDB6(connectionPool, "VIEW", "public.testview")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_RELATION)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_RELATION)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "public", "testview", backup.TYPE_RELATION)
 				expectedMetadata := testutils.DefaultMetadata("VIEW", true, true, true, includeSecurityLabels)
@@ -217,7 +217,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON MATERIALIZED VIEW public.testmview IS 'This is a materialized view comment.'")
 				testutils.CreateSecurityLabelIfGPDB6(connectionPool, "MATERIALIZED VIEW", "public.testmview")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_RELATION)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_RELATION)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "public", "testmview", backup.TYPE_RELATION)
 				expectedMetadata := testutils.DefaultMetadata("MATERIALIZED VIEW", true, true, true, includeSecurityLabels)
@@ -232,7 +232,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON SCHEMA testschema IS 'This is a schema comment.'")
 				testutils.CreateSecurityLabelIfGPDB6(connectionPool, "SCHEMA", "testschema")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_SCHEMA)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_SCHEMA)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "", "testschema", backup.TYPE_SCHEMA)
 				expectedMetadata := testutils.DefaultMetadata("SCHEMA", true, true, true, includeSecurityLabels)
@@ -274,7 +274,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, `
 				REVOKE ALL ON FUNCTION public.agg_prefunc(numeric, numeric) FROM public`)
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_AGGREGATE)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_AGGREGATE)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "", "agg_prefunc", backup.TYPE_AGGREGATE)
 				expectedMetadata := testutils.DefaultMetadata("AGGREGATE", true, true, true, includeSecurityLabels)
@@ -287,7 +287,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON TYPE public.testtype IS 'This is a type comment.'")
 				testutils.CreateSecurityLabelIfGPDB6(connectionPool, "TYPE", "public.testtype")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_TYPE)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TYPE)
 
 				typeUniqueID := testutils.UniqueIDFromObjectName(connectionPool, "", "testtype", backup.TYPE_TYPE)
 				expectedMetadata := testutils.DefaultMetadata("TYPE", false, true, true, includeSecurityLabels)
@@ -300,7 +300,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON DOMAIN public.domain_type IS 'This is a domain comment.'")
 				testutils.CreateSecurityLabelIfGPDB6(connectionPool, "DOMAIN", "public.domain_type")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_TYPE)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TYPE)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "", "domain_type", backup.TYPE_TYPE)
 				expectedMetadata := testutils.DefaultMetadata("DOMAIN", false, true, true, includeSecurityLabels)
@@ -314,7 +314,7 @@ LANGUAGE SQL`)
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP PROTOCOL s3_read")
 				testhelper.AssertQueryRuns(connectionPool, "GRANT ALL ON PROTOCOL s3_read TO testrole")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_PROTOCOL)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_PROTOCOL)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "", "s3_read", backup.TYPE_PROTOCOL)
 				expectedMetadata := testutils.DefaultMetadata("PROTOCOL", true, true, false, false)
@@ -332,7 +332,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "GRANT ALL ON TABLESPACE test_tablespace TO testrole")
 				testutils.CreateSecurityLabelIfGPDB6(connectionPool, "TABLESPACE", "test_tablespace")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_TABLESPACE)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TABLESPACE)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "", "test_tablespace", backup.TYPE_TABLESPACE)
 				expectedMetadata := testutils.DefaultMetadata("TABLESPACE", true, true, true, includeSecurityLabels)
@@ -345,7 +345,7 @@ LANGUAGE SQL`)
 
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON OPERATOR public.#### (bigint, NONE) IS 'This is an operator comment.'")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_OPERATOR)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_OPERATOR)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "", "####", backup.TYPE_OPERATOR)
 				expectedMetadata := testutils.DefaultMetadata("OPERATOR", false, true, true, false)
@@ -359,7 +359,7 @@ LANGUAGE SQL`)
 
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON OPERATOR FAMILY public.testfam USING hash IS 'This is an operator family comment.'")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_OPERATORFAMILY)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_OPERATORFAMILY)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "public", "testfam", backup.TYPE_OPERATORFAMILY)
 				expectedMetadata := testutils.DefaultMetadata("OPERATOR FAMILY", false, true, true, false)
@@ -375,7 +375,7 @@ LANGUAGE SQL`)
 				}
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON OPERATOR CLASS public.testclass USING hash IS 'This is an operator class comment.'")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_OPERATORCLASS)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_OPERATORCLASS)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "public", "testclass", backup.TYPE_OPERATORCLASS)
 				expectedMetadata := testutils.DefaultMetadata("OPERATOR CLASS", false, true, true, false)
@@ -388,7 +388,7 @@ LANGUAGE SQL`)
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP TEXT SEARCH DICTIONARY public.testdictionary")
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON TEXT SEARCH DICTIONARY public.testdictionary IS 'This is a text search dictionary comment.'")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_TSDICTIONARY)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TSDICTIONARY)
 
 				Expect(resultMetadataMap).To(HaveLen(1))
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "public", "testdictionary", backup.TYPE_TSDICTIONARY)
@@ -398,7 +398,7 @@ LANGUAGE SQL`)
 			})
 			It("returns a slice of default metadata for a text search configuration", func() {
 				testutils.SkipIfBefore5(connectionPool)
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_TSCONFIGURATION)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TSCONFIGURATION)
 				configurationMetadata := testutils.DefaultMetadata("TEXT SEARCH CONFIGURATION", false, true, true, false)
 
 				testhelper.AssertQueryRuns(connectionPool, `CREATE TEXT SEARCH CONFIGURATION public.testconfiguration (PARSER = pg_catalog."default");`)
@@ -406,7 +406,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON TEXT SEARCH CONFIGURATION public.testconfiguration IS 'This is a text search configuration comment.'")
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "public", "testconfiguration", backup.TYPE_TSCONFIGURATION)
-				resultMetadataMap = backup.GetMetadataForObjectType(connectionPool, backup.TYPE_TSCONFIGURATION)
+				resultMetadataMap = testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TSCONFIGURATION)
 
 				Expect(resultMetadataMap).To(HaveLen(1))
 				resultMetadata := resultMetadataMap[uniqueID]
@@ -419,7 +419,7 @@ LANGUAGE SQL`)
 
 				testhelper.AssertQueryRuns(connectionPool, "GRANT ALL ON FOREIGN DATA WRAPPER foreignwrapper TO testrole")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_FOREIGNDATAWRAPPER)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_FOREIGNDATAWRAPPER)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "", "foreignwrapper", backup.TYPE_FOREIGNDATAWRAPPER)
 				expectedMetadata := testutils.DefaultMetadata("FOREIGN DATA WRAPPER", true, true, false, false)
@@ -434,7 +434,7 @@ LANGUAGE SQL`)
 
 				testhelper.AssertQueryRuns(connectionPool, "GRANT ALL ON FOREIGN SERVER foreignserver TO testrole")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_FOREIGNSERVER)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_FOREIGNSERVER)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "", "foreignserver", backup.TYPE_FOREIGNSERVER)
 				expectedMetadata := testutils.DefaultMetadata("FOREIGN SERVER", true, true, false, false)
@@ -447,7 +447,7 @@ LANGUAGE SQL`)
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP COLLATION public.some_coll")
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON COLLATION public.some_coll IS 'This is a collation comment.'")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_COLLATION)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_COLLATION)
 
 				Expect(resultMetadataMap).To(HaveLen(1))
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "public", "some_coll", backup.TYPE_COLLATION)
@@ -467,7 +467,7 @@ AS $$ BEGIN RAISE EXCEPTION 'exception'; END; $$;`)
 
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON EVENT TRIGGER test_event_trigger IS 'This is an event trigger comment.'")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_EVENTTRIGGER)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_EVENTTRIGGER)
 
 				Expect(resultMetadataMap).To(HaveLen(1))
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "", "test_event_trigger", backup.TYPE_EVENTTRIGGER)
@@ -484,7 +484,7 @@ AS $$ BEGIN RAISE EXCEPTION 'exception'; END; $$;`)
 				// public usage creates an entry in the ACL with GRANTEE="" . We revoke this for ease of testing.
 				testhelper.AssertQueryRuns(connectionPool, "REVOKE usage on type public.my_type from public")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_TYPE)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TYPE)
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "public", "my_type", backup.TYPE_TYPE)
 				resultMetadata := resultMetadataMap[uniqueID]
 
@@ -508,7 +508,7 @@ AS $$ BEGIN RAISE EXCEPTION 'exception'; END; $$;`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON TABLE testschema.testtable IS 'This is a table comment.'")
 				backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_RELATION)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_RELATION)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "testschema", "testtable", backup.TYPE_RELATION)
 				expectedMetadata := testutils.DefaultMetadata("TABLE", true, true, true, false)
@@ -527,7 +527,7 @@ AS $$ BEGIN RAISE EXCEPTION 'exception'; END; $$;`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON TABLE testschema.testtable IS 'This is a table comment.'")
 				backupCmdFlags.Set(utils.EXCLUDE_SCHEMA, "public")
 
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_RELATION)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_RELATION)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "testschema", "testtable", backup.TYPE_RELATION)
 				expectedMetadata := testutils.DefaultMetadata("TABLE", true, true, true, false)
@@ -551,7 +551,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON FUNCTION testschema.add(integer, integer) IS 'This is a function comment.'")
 
 				backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_FUNCTION)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_FUNCTION)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "testschema", "add", backup.TYPE_FUNCTION)
 				expectedMetadata := testutils.DefaultMetadata("FUNCTION", true, true, true, false)
@@ -570,7 +570,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON VIEW testschema.testview IS 'This is a view comment.'")
 
 				backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_RELATION)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_RELATION)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "testschema", "testview", backup.TYPE_RELATION)
 				expectedMetadata := testutils.DefaultMetadata("VIEW", true, true, true, false)
@@ -590,7 +590,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON MATERIALIZED VIEW testschema.testmview IS 'This is a materialized view comment.'")
 
 				backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_RELATION)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_RELATION)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "testschema", "testmview", backup.TYPE_RELATION)
 				expectedMetadata := testutils.DefaultMetadata("MATERIALIZED VIEW", true, true, true, false)
@@ -637,7 +637,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON AGGREGATE testschema.agg_prefunc(numeric, numeric) IS 'This is an aggregate comment.'")
 
 				backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_AGGREGATE)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_AGGREGATE)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "testschema", "agg_prefunc", backup.TYPE_AGGREGATE)
 				expectedMetadata := testutils.DefaultMetadata("AGGREGATE", false, true, true, false)
@@ -655,7 +655,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON TYPE testschema.testtype IS 'This is a type comment.'")
 
 				backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_TYPE)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TYPE)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "testschema", "testtype", backup.TYPE_TYPE)
 				expectedMetadata := testutils.DefaultMetadata("TYPE", false, true, true, false)
@@ -679,7 +679,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON OPERATOR testschema.#### (bigint, NONE) IS 'This is an operator comment.'")
 
 				backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_OPERATOR)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_OPERATOR)
 
 				Expect(resultMetadataMap).To(HaveLen(1))
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "testschema", "####", backup.TYPE_OPERATOR)
@@ -698,7 +698,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON OPERATOR FAMILY testschema.testfam USING hash IS 'This is an operator family comment.'")
 
 				backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_OPERATORFAMILY)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_OPERATORFAMILY)
 
 				Expect(resultMetadataMap).To(HaveLen(1))
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "testschema", "testfam", backup.TYPE_OPERATORFAMILY)
@@ -724,7 +724,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON OPERATOR CLASS testschema.testclass USING hash IS 'This is an operator class comment.'")
 
 				backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_OPERATORCLASS)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_OPERATORCLASS)
 
 				Expect(resultMetadataMap).To(HaveLen(1))
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "testschema", "testclass", backup.TYPE_OPERATORCLASS)
@@ -743,7 +743,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON TEXT SEARCH DICTIONARY testschema.testdictionary IS 'This is a text search dictionary comment.'")
 
 				backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_TSDICTIONARY)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TSDICTIONARY)
 
 				Expect(resultMetadataMap).To(HaveLen(1))
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "testschema", "testdictionary", backup.TYPE_TSDICTIONARY)
@@ -753,7 +753,7 @@ LANGUAGE SQL`)
 			})
 			It("returns a slice of default metadata for a text search configuration in a specific schema", func() {
 				testutils.SkipIfBefore5(connectionPool)
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_TSCONFIGURATION)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TSCONFIGURATION)
 				configurationMetadata := testutils.DefaultMetadata("TEXT SEARCH CONFIGURATION", false, true, true, false)
 
 				testhelper.AssertQueryRuns(connectionPool, `CREATE TEXT SEARCH CONFIGURATION public.testconfiguration (PARSER = pg_catalog."default");`)
@@ -765,7 +765,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON TEXT SEARCH CONFIGURATION testschema.testconfiguration IS 'This is a text search configuration comment.'")
 
 				backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
-				resultMetadataMap = backup.GetMetadataForObjectType(connectionPool, backup.TYPE_TSCONFIGURATION)
+				resultMetadataMap = testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TSCONFIGURATION)
 
 				Expect(resultMetadataMap).To(HaveLen(1))
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "testschema", "testconfiguration", backup.TYPE_TSCONFIGURATION)
@@ -783,7 +783,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON COLLATION testschema.some_coll IS 'This is a collation comment.'")
 
 				backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
-				resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_COLLATION)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_COLLATION)
 
 				Expect(resultMetadataMap).To(HaveLen(1))
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "testschema", "some_coll", backup.TYPE_COLLATION)
@@ -801,7 +801,7 @@ LANGUAGE SQL`)
 			testhelper.AssertQueryRuns(connectionPool, "ALTER DEFAULT PRIVILEGES REVOKE USAGE ON SEQUENCES FROM testrole;")
 			defer testhelper.AssertQueryRuns(connectionPool, "ALTER DEFAULT PRIVILEGES GRANT USAGE ON SEQUENCES TO testrole;")
 
-			resultDefaultPrivileges := backup.GetDefaultPrivileges(connectionPool)
+			resultDefaultPrivileges := testBackup.GetDefaultPrivileges(connectionPool)
 
 			privs := []backup.ACL{{Grantee: "testrole", Update: true, Select: true}}
 			expectedDefaultPrivileges := backup.DefaultPrivileges{Schema: "", Privileges: privs, ObjectType: "S", Owner: "testrole"}
@@ -812,7 +812,7 @@ LANGUAGE SQL`)
 			testhelper.AssertQueryRuns(connectionPool, "ALTER DEFAULT PRIVILEGES GRANT SELECT ON TABLES TO PUBLIC;")
 			defer testhelper.AssertQueryRuns(connectionPool, "ALTER DEFAULT PRIVILEGES REVOKE SELECT ON TABLES FROM PUBLIC;")
 
-			resultDefaultPrivileges := backup.GetDefaultPrivileges(connectionPool)
+			resultDefaultPrivileges := testBackup.GetDefaultPrivileges(connectionPool)
 
 			privs := []backup.ACL{{Grantee: "", Select: true}, testutils.DefaultACLForType("testrole", "TABLE")}
 			expectedDefaultPrivileges := backup.DefaultPrivileges{Schema: "", Privileges: privs, ObjectType: "r", Owner: "testrole"}
@@ -823,7 +823,7 @@ LANGUAGE SQL`)
 			testhelper.AssertQueryRuns(connectionPool, "ALTER DEFAULT PRIVILEGES FOR ROLE anothertestrole GRANT USAGE ON SEQUENCES TO testrole;")
 			defer testhelper.AssertQueryRuns(connectionPool, "ALTER DEFAULT PRIVILEGES FOR ROLE anothertestrole REVOKE USAGE ON SEQUENCES FROM testrole;")
 
-			resultDefaultPrivileges := backup.GetDefaultPrivileges(connectionPool)
+			resultDefaultPrivileges := testBackup.GetDefaultPrivileges(connectionPool)
 
 			privs := []backup.ACL{{Grantee: "anothertestrole", Select: true, Update: true, Usage: true}, {Grantee: "testrole", Usage: true}}
 			expectedDefaultPrivileges := backup.DefaultPrivileges{Schema: "", Privileges: privs, ObjectType: "S", Owner: "anothertestrole"}
@@ -834,19 +834,36 @@ LANGUAGE SQL`)
 			testhelper.AssertQueryRuns(connectionPool, "ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE ON SEQUENCES TO testrole;")
 			defer testhelper.AssertQueryRuns(connectionPool, "ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE USAGE ON SEQUENCES FROM testrole;")
 
-			resultDefaultPrivileges := backup.GetDefaultPrivileges(connectionPool)
+			resultDefaultPrivileges := testBackup.GetDefaultPrivileges(connectionPool)
 
 			privs := []backup.ACL{{Grantee: "testrole", Usage: true}}
 			expectedDefaultPrivileges := backup.DefaultPrivileges{Schema: "public", Privileges: privs, ObjectType: "S", Owner: "testrole"}
 			Expect(resultDefaultPrivileges).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&expectedDefaultPrivileges, &resultDefaultPrivileges[0], "Oid")
 		})
+		It("returns only database-wide default privileges and those in included schemas", func() {
+			testhelper.AssertQueryRuns(connectionPool, "CREATE SCHEMA testschema")
+			defer testhelper.AssertQueryRuns(connectionPool, "DROP SCHEMA testschema")
+			testhelper.AssertQueryRuns(connectionPool, "ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE ON SEQUENCES TO testrole;")
+			defer testhelper.AssertQueryRuns(connectionPool, "ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE USAGE ON SEQUENCES FROM testrole;")
+			testhelper.AssertQueryRuns(connectionPool, "ALTER DEFAULT PRIVILEGES IN SCHEMA testschema GRANT USAGE ON SEQUENCES TO testrole;")
+			defer testhelper.AssertQueryRuns(connectionPool, "ALTER DEFAULT PRIVILEGES IN SCHEMA testschema REVOKE USAGE ON SEQUENCES FROM testrole;")
+			testhelper.AssertQueryRuns(connectionPool, "ALTER DEFAULT PRIVILEGES REVOKE USAGE ON SEQUENCES FROM testrole;")
+			defer testhelper.AssertQueryRuns(connectionPool, "ALTER DEFAULT PRIVILEGES GRANT USAGE ON SEQUENCES TO testrole;")
+			backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
+
+			resultDefaultPrivileges := testBackup.GetDefaultPrivileges(connectionPool)
+
+			Expect(resultDefaultPrivileges).To(HaveLen(2))
+			Expect(resultDefaultPrivileges[0].Schema).To(Equal("testschema"))
+			Expect(resultDefaultPrivileges[1].Schema).To(Equal(""))
+		})
 
 	})
-	Describe("GetCommentsForObjectType", func() {
+	Describe("GetMetadataForObjectType for objects with only comments", func() {
 		Context("comments for all objects of one type", func() {
 			It("returns a slice of default metadata for an index", func() {
-				resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, backup.TYPE_INDEX)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_INDEX)
 				numIndexes := len(resultMetadataMap)
 
 				testhelper.AssertQueryRuns(connectionPool, `CREATE TABLE public.testtable(i int)`)
@@ -854,7 +871,7 @@ LANGUAGE SQL`)
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.testtable")
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON INDEX public.testindex IS 'This is an index comment.'")
 
-				resultMetadataMap = backup.GetCommentsForObjectType(connectionPool, backup.TYPE_INDEX)
+				resultMetadataMap = testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_INDEX)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "", "testindex", backup.TYPE_INDEX)
 				expectedMetadata := testutils.DefaultMetadata("INDEX", false, false, true, false)
@@ -864,7 +881,7 @@ LANGUAGE SQL`)
 				structmatcher.ExpectStructsToMatchExcluding(&expectedMetadata, &resultMetadata, "Oid")
 			})
 			It("returns a slice of default metadata for a rule", func() {
-				resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, backup.TYPE_RULE)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_RULE)
 				numRules := len(resultMetadataMap)
 
 				testhelper.AssertQueryRuns(connectionPool, `CREATE TABLE public.testtable(i int)`)
@@ -872,7 +889,7 @@ LANGUAGE SQL`)
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.testtable")
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON RULE update_notify IS 'This is a rule comment.'")
 
-				resultMetadataMap = backup.GetCommentsForObjectType(connectionPool, backup.TYPE_RULE)
+				resultMetadataMap = testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_RULE)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "", "update_notify", backup.TYPE_RULE)
 				expectedMetadata := testutils.DefaultMetadata("RULE", false, false, true, false)
@@ -882,7 +899,7 @@ LANGUAGE SQL`)
 				structmatcher.ExpectStructsToMatchExcluding(&expectedMetadata, &resultMetadata, "Oid")
 			})
 			It("returns a slice of default metadata for a trigger", func() {
-				resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, backup.TYPE_TRIGGER)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TRIGGER)
 				numTriggers := len(resultMetadataMap)
 
 				testhelper.AssertQueryRuns(connectionPool, `CREATE TABLE public.testtable(i int)`)
@@ -890,7 +907,7 @@ LANGUAGE SQL`)
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.testtable")
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON TRIGGER sync_testtable ON public.testtable IS 'This is a trigger comment.'")
 
-				resultMetadataMap = backup.GetCommentsForObjectType(connectionPool, backup.TYPE_TRIGGER)
+				resultMetadataMap = testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TRIGGER)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "", "sync_testtable", backup.TYPE_TRIGGER)
 				expectedMetadata := testutils.DefaultMetadata("TRIGGER", false, false, true, false)
@@ -901,7 +918,7 @@ LANGUAGE SQL`)
 			})
 			It("returns a slice of default metadata for a cast in 4.3", func() {
 				testutils.SkipIfNot4(connectionPool)
-				resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, backup.TYPE_CAST)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_CAST)
 				numCasts := len(resultMetadataMap)
 
 				testhelper.AssertQueryRuns(connectionPool, "CREATE FUNCTION public.casttotext(bool) RETURNS text STRICT IMMUTABLE LANGUAGE PLPGSQL AS $$ BEGIN IF $1 IS TRUE THEN RETURN 'true'; ELSE RETURN 'false'; END IF; END; $$;")
@@ -909,7 +926,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "CREATE CAST (bool AS text) WITH FUNCTION public.casttotext(bool) AS ASSIGNMENT")
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON CAST (bool AS text) IS 'This is a cast comment.'")
 
-				resultMetadataMap = backup.GetCommentsForObjectType(connectionPool, backup.TYPE_CAST)
+				resultMetadataMap = testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_CAST)
 
 				boolOid := testutils.OidFromObjectName(connectionPool, "", "bool", backup.TYPE_TYPE)
 				textOid := testutils.OidFromObjectName(connectionPool, "", "text", backup.TYPE_TYPE)
@@ -922,7 +939,7 @@ LANGUAGE SQL`)
 			})
 			It("returns a slice of default metadata for a cast in 5", func() {
 				testutils.SkipIfBefore5(connectionPool)
-				resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, backup.TYPE_CAST)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_CAST)
 				numCasts := len(resultMetadataMap)
 
 				testhelper.AssertQueryRuns(connectionPool, `CREATE FUNCTION public.casttoint(text) RETURNS integer STRICT IMMUTABLE LANGUAGE SQL AS 'SELECT cast($1 as integer);'`)
@@ -930,7 +947,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "CREATE CAST (text AS int) WITH FUNCTION public.casttoint(text) AS ASSIGNMENT;")
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON CAST (text AS int) IS 'This is a cast comment.'")
 
-				resultMetadataMap = backup.GetCommentsForObjectType(connectionPool, backup.TYPE_CAST)
+				resultMetadataMap = testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_CAST)
 
 				textOid := testutils.OidFromObjectName(connectionPool, "", "text", backup.TYPE_TYPE)
 				intOid := testutils.OidFromObjectName(connectionPool, "", "int4", backup.TYPE_TYPE)
@@ -942,14 +959,14 @@ LANGUAGE SQL`)
 				structmatcher.ExpectStructsToMatchExcluding(&expectedMetadata, &resultMetadata, "Oid")
 			})
 			It("returns a slice of default metadata for a resource queue", func() {
-				resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, backup.TYPE_RESOURCEQUEUE)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_RESOURCEQUEUE)
 				numResQueues := len(resultMetadataMap)
 
 				testhelper.AssertQueryRuns(connectionPool, `CREATE RESOURCE QUEUE res_queue WITH (MAX_COST=32.8);`)
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP RESOURCE QUEUE res_queue")
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON RESOURCE QUEUE res_queue IS 'This is a resource queue comment.'")
 
-				resultMetadataMap = backup.GetCommentsForObjectType(connectionPool, backup.TYPE_RESOURCEQUEUE)
+				resultMetadataMap = testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_RESOURCEQUEUE)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "", "res_queue", backup.TYPE_RESOURCEQUEUE)
 				expectedMetadata := testutils.DefaultMetadata("RESOURCE QUEUE", false, false, true, false)
@@ -967,7 +984,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON TEXT SEARCH PARSER public.testparser IS 'This is a text search parser comment.'")
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "public", "testparser", backup.TYPE_TSPARSER)
-				resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, backup.TYPE_TSPARSER)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TSPARSER)
 
 				Expect(resultMetadataMap).To(HaveLen(1))
 				resultMetadata := resultMetadataMap[uniqueID]
@@ -982,7 +999,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON TEXT SEARCH TEMPLATE public.testtemplate IS 'This is a text search template comment.'")
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "public", "testtemplate", backup.TYPE_TSTEMPLATE)
-				resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, backup.TYPE_TSTEMPLATE)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TSTEMPLATE)
 
 				Expect(resultMetadataMap).To(HaveLen(1))
 				resultMetadata := resultMetadataMap[uniqueID]
@@ -997,7 +1014,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON EXTENSION plperl IS 'This is an extension comment.'")
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "", "plperl", backup.TYPE_EXTENSION)
-				resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, backup.TYPE_EXTENSION)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_EXTENSION)
 
 				Expect(resultMetadataMap).To(HaveLen(1))
 				resultMetadata := resultMetadataMap[uniqueID]
@@ -1018,7 +1035,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON INDEX testschema.testindex1 IS 'This is an index comment.'")
 
 				backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
-				resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, backup.TYPE_INDEX)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_INDEX)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "", "testindex1", backup.TYPE_INDEX)
 				expectedMetadata := testutils.DefaultMetadata("INDEX", false, false, true, false)
@@ -1038,7 +1055,7 @@ LANGUAGE SQL`)
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON CONSTRAINT testtable_i_key ON testschema.testtable IS 'This is a constraint comment.'")
 				backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
 
-				resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, backup.TYPE_CONSTRAINT)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_CONSTRAINT)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "testschema", "testtable_i_key", backup.TYPE_CONSTRAINT)
 				expectedMetadata := testutils.DefaultMetadata("CONSTRAINT", false, false, true, false)
@@ -1061,7 +1078,7 @@ LANGUAGE SQL`)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "testschema", "testparser", backup.TYPE_TSPARSER)
 				backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
-				resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, backup.TYPE_TSPARSER)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TSPARSER)
 
 				Expect(resultMetadataMap).To(HaveLen(1))
 				resultMetadata := resultMetadataMap[uniqueID]
@@ -1081,7 +1098,7 @@ LANGUAGE SQL`)
 
 				uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "testschema", "testtemplate", backup.TYPE_TSTEMPLATE)
 				backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
-				resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, backup.TYPE_TSTEMPLATE)
+				resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TSTEMPLATE)
 
 				Expect(resultMetadataMap).To(HaveLen(1))
 				resultMetadata := resultMetadataMap[uniqueID]
@@ -186,7 +186,7 @@ var _ = Describe("backup integration create statement tests", func() {
 		It("creates a trusted protocol with a read function, privileges, and an owner", func() {
 			protoMetadata := backup.ObjectMetadata{Privileges: []backup.ACL{{Grantee: "testrole", Select: true, Insert: true}}, Owner: "testrole"}
 
-			backup.PrintCreateExternalProtocolStatement(backupfile, toc, protocolReadOnly, funcInfoMap, protoMetadata)
+			testBackup.PrintCreateExternalProtocolStatement(backupfile, toc, protocolReadOnly, funcInfoMap, protoMetadata)
 
 			testhelper.AssertQueryRuns(connectionPool, "CREATE OR REPLACE FUNCTION public.read_from_s3() RETURNS integer AS '$libdir/gps3ext.so', 's3_import' LANGUAGE C STABLE;")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP FUNCTION public.read_from_s3()")
@@ -200,7 +200,7 @@ var _ = Describe("backup integration create statement tests", func() {
 			structmatcher.ExpectStructsToMatchExcluding(&protocolReadOnly, &resultExternalProtocols[0], "Oid", "ReadFunction", "FuncMap")
 		})
 		It("creates a protocol with a write function", func() {
-			backup.PrintCreateExternalProtocolStatement(backupfile, toc, protocolWriteOnly, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateExternalProtocolStatement(backupfile, toc, protocolWriteOnly, funcInfoMap, emptyMetadata)
 
 			testhelper.AssertQueryRuns(connectionPool, "CREATE OR REPLACE FUNCTION public.write_to_s3() RETURNS integer AS '$libdir/gps3ext.so', 's3_export' LANGUAGE C STABLE;")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP FUNCTION public.write_to_s3()")
@@ -214,7 +214,7 @@ var _ = Describe("backup integration create statement tests", func() {
 			structmatcher.ExpectStructsToMatchExcluding(&protocolWriteOnly, &resultExternalProtocols[0], "Oid", "WriteFunction", "FuncMap")
 		})
 		It("creates a protocol with a read and write function", func() {
-			backup.PrintCreateExternalProtocolStatement(backupfile, toc, protocolReadWrite, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateExternalProtocolStatement(backupfile, toc, protocolReadWrite, funcInfoMap, emptyMetadata)
 
 			testhelper.AssertQueryRuns(connectionPool, "CREATE OR REPLACE FUNCTION public.read_from_s3() RETURNS integer AS '$libdir/gps3ext.so', 's3_import' LANGUAGE C STABLE;")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP FUNCTION public.read_from_s3()")
@@ -28,12 +28,12 @@ var _ = Describe("backup integration create statement tests", func() {
 				}
 
 				metadata := testutils.DefaultMetadata("FUNCTION", true, true, true, false)
-				backup.PrintCreateFunctionStatement(backupfile, toc, addFunction, metadata)
+				testBackup.PrintCreateFunctionStatement(backupfile, toc, addFunction, metadata)
 
 				testhelper.AssertQueryRuns(connectionPool, buffer.String())
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP FUNCTION public.add(integer, integer)")
 
-				resultFunctions := backup.GetFunctionsAllVersions(connectionPool)
+				resultFunctions := testBackup.GetFunctionsAllVersions(connectionPool)
 
 				Expect(resultFunctions).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchExcluding(&addFunction, &resultFunctions[0], "Oid")
@@ -45,12 +45,12 @@ var _ = Describe("backup integration create statement tests", func() {
 					Volatility: "s", IsStrict: true, IsSecurityDefiner: true, Language: "sql", ExecLocation: "a",
 				}
 
-				backup.PrintCreateFunctionStatement(backupfile, toc, appendFunction, funcMetadata)
+				testBackup.PrintCreateFunctionStatement(backupfile, toc, appendFunction, funcMetadata)
 
 				testhelper.AssertQueryRuns(connectionPool, buffer.String())
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP FUNCTION public.append(integer, integer)")
 
-				resultFunctions := backup.GetFunctionsAllVersions(connectionPool)
+				resultFunctions := testBackup.GetFunctionsAllVersions(connectionPool)
 
 				Expect(resultFunctions).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchExcluding(&appendFunction, &resultFunctions[0], "Oid")
@@ -62,12 +62,12 @@ var _ = Describe("backup integration create statement tests", func() {
 					Volatility: "v", IsStrict: false, IsSecurityDefiner: false, Language: "sql", ExecLocation: "a",
 				}
 
-				backup.PrintCreateFunctionStatement(backupfile, toc, dupFunction, funcMetadata)
+				testBackup.PrintCreateFunctionStatement(backupfile, toc, dupFunction, funcMetadata)
 
 				testhelper.AssertQueryRuns(connectionPool, buffer.String())
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP FUNCTION public.dup(integer)")
 
-				resultFunctions := backup.GetFunctionsAllVersions(connectionPool)
+				resultFunctions := testBackup.GetFunctionsAllVersions(connectionPool)
 
 				Expect(resultFunctions).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchExcluding(&dupFunction, &resultFunctions[0], "Oid")
@@ -87,12 +87,12 @@ var _ = Describe("backup integration create statement tests", func() {
 				}
 
 				metadata := testutils.DefaultMetadata("FUNCTION", true, true, true, includeSecurityLabels)
-				backup.PrintCreateFunctionStatement(backupfile, toc, addFunction, metadata)
+				testBackup.PrintCreateFunctionStatement(backupfile, toc, addFunction, metadata)
 
 				testhelper.AssertQueryRuns(connectionPool, buffer.String())
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP FUNCTION public.add(integer, integer)")
 
-				resultFunctions := backup.GetFunctionsAllVersions(connectionPool)
+				resultFunctions := testBackup.GetFunctionsAllVersions(connectionPool)
 
 				Expect(resultFunctions).To(HaveLen(1))
 
@@ -106,12 +106,12 @@ var _ = Describe("backup integration create statement tests", func() {
 					NumRows: 200, DataAccess: "m", Language: "sql", ExecLocation: "a",
 				}
 
-				backup.PrintCreateFunctionStatement(backupfile, toc, appendFunction, funcMetadata)
+				testBackup.PrintCreateFunctionStatement(backupfile, toc, appendFunction, funcMetadata)
 
 				testhelper.AssertQueryRuns(connectionPool, buffer.String())
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP FUNCTION public.append(integer, integer)")
 
-				resultFunctions := backup.GetFunctionsAllVersions(connectionPool)
+				resultFunctions := testBackup.GetFunctionsAllVersions(connectionPool)
 
 				Expect(resultFunctions).To(HaveLen(1))
 
@@ -125,12 +125,12 @@ var _ = Describe("backup integration create statement tests", func() {
 					Language: "sql", ExecLocation: "a",
 				}
 
-				backup.PrintCreateFunctionStatement(backupfile, toc, dupFunction, funcMetadata)
+				testBackup.PrintCreateFunctionStatement(backupfile, toc, dupFunction, funcMetadata)
 
 				testhelper.AssertQueryRuns(connectionPool, buffer.String())
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP FUNCTION public.dup(integer)")
 
-				resultFunctions := backup.GetFunctionsAllVersions(connectionPool)
+				resultFunctions := testBackup.GetFunctionsAllVersions(connectionPool)
 
 				Expect(resultFunctions).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchExcluding(&dupFunction, &resultFunctions[0], "Oid")
@@ -149,12 +149,12 @@ var _ = Describe("backup integration create statement tests", func() {
 					Language: "sql", IsWindow: true, ExecLocation: "m",
 				}
 
-				backup.PrintCreateFunctionStatement(backupfile, toc, windowFunction, funcMetadata)
+				testBackup.PrintCreateFunctionStatement(backupfile, toc, windowFunction, funcMetadata)
 
 				testhelper.AssertQueryRuns(connectionPool, buffer.String())
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP FUNCTION public.add(integer, integer)")
 
-				resultFunctions := backup.GetFunctionsAllVersions(connectionPool)
+				resultFunctions := testBackup.GetFunctionsAllVersions(connectionPool)
 
 				Expect(resultFunctions).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchExcluding(&windowFunction, &resultFunctions[0], "Oid")
@@ -167,12 +167,12 @@ var _ = Describe("backup integration create statement tests", func() {
 					Language: "sql", IsWindow: false, ExecLocation: "s",
 				}
 
-				backup.PrintCreateFunctionStatement(backupfile, toc, segmentFunction, funcMetadata)
+				testBackup.PrintCreateFunctionStatement(backupfile, toc, segmentFunction, funcMetadata)
 
 				testhelper.AssertQueryRuns(connectionPool, buffer.String())
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP FUNCTION public.add(integer, integer)")
 
-				resultFunctions := backup.GetFunctionsAllVersions(connectionPool)
+				resultFunctions := testBackup.GetFunctionsAllVersions(connectionPool)
 
 				Expect(resultFunctions).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchExcluding(&segmentFunction, &resultFunctions[0], "Oid")
@@ -185,12 +185,12 @@ var _ = Describe("backup integration create statement tests", func() {
 					Language: "sql", IsWindow: false, ExecLocation: "a",
 				}
 
-				backup.PrintCreateFunctionStatement(backupfile, toc, leakProofFunction, funcMetadata)
+				testBackup.PrintCreateFunctionStatement(backupfile, toc, leakProofFunction, funcMetadata)
 
 				testhelper.AssertQueryRuns(connectionPool, buffer.String())
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP FUNCTION public.add(integer, integer)")
 
-				resultFunctions := backup.GetFunctionsAllVersions(connectionPool)
+				resultFunctions := testBackup.GetFunctionsAllVersions(connectionPool)
 
 				Expect(resultFunctions).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchExcluding(&leakProofFunction, &resultFunctions[0], "Oid")
@@ -239,25 +239,25 @@ var _ = Describe("backup integration create statement tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, "DROP FUNCTION public.mypre_accum(numeric, numeric)")
 		})
 		It("creates a basic aggregate", func() {
-			backup.PrintCreateAggregateStatement(backupfile, toc, basicAggregateDef, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, basicAggregateDef, funcInfoMap, emptyMetadata)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP AGGREGATE public.agg_prefunc(numeric, numeric)")
 
-			resultAggregates := backup.GetAggregates(connectionPool)
+			resultAggregates := testBackup.GetAggregates(connectionPool)
 			Expect(resultAggregates).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&basicAggregateDef, &resultAggregates[0], "Oid", "TransitionFunction", "PreliminaryFunction", "CombineFunction")
 		})
 		It("creates an aggregate with an owner, security label, and a comment", func() {
 			aggMetadata := testutils.DefaultMetadata("AGGREGATE", false, true, true, includeSecurityLabels)
-			backup.PrintCreateAggregateStatement(backupfile, toc, basicAggregateDef, funcInfoMap, aggMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, basicAggregateDef, funcInfoMap, aggMetadata)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP AGGREGATE public.agg_prefunc(numeric, numeric)")
 
-			resultAggregates := backup.GetAggregates(connectionPool)
+			resultAggregates := testBackup.GetAggregates(connectionPool)
 			Expect(resultAggregates).To(HaveLen(1))
-			resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_AGGREGATE)
+			resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_AGGREGATE)
 			resultMetadata := resultMetadataMap[resultAggregates[0].GetUniqueID()]
 			structmatcher.ExpectStructsToMatchExcluding(&basicAggregateDef, &resultAggregates[0], "Oid", "TransitionFunction", "PreliminaryFunction", "CombineFunction")
 			structmatcher.ExpectStructsToMatch(&aggMetadata, &resultMetadata)
@@ -270,11 +270,11 @@ var _ = Describe("backup integration create statement tests", func() {
 				TransitionDataType: "internal", InitValIsNull: true, FinalFuncExtra: true, Hypothetical: true, MInitValIsNull: true,
 			}
 
-			backup.PrintCreateAggregateStatement(backupfile, toc, complexAggregateDef, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, complexAggregateDef, funcInfoMap, emptyMetadata)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, `DROP AGGREGATE public.agg_hypo_ord(VARIADIC "any" ORDER BY VARIADIC "any")`)
-			resultAggregates := backup.GetAggregates(connectionPool)
+			resultAggregates := testBackup.GetAggregates(connectionPool)
 
 			Expect(resultAggregates).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&complexAggregateDef, &resultAggregates[0], "Oid", "TransitionFunction", "FinalFunction")
@@ -285,11 +285,11 @@ var _ = Describe("backup integration create statement tests", func() {
 				IdentArgs: "numeric", TransitionFunction: 9, FinalFunction: 0, SortOperator: "+", SortOperatorSchema: "pg_catalog", TransitionDataType: "numeric",
 				InitialValue: "0", IsOrdered: false, MInitValIsNull: true,
 			}
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggregateDef, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggregateDef, funcInfoMap, emptyMetadata)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, `DROP AGGREGATE public.agg_sort(numeric)`)
-			resultAggregates := backup.GetAggregates(connectionPool)
+			resultAggregates := testBackup.GetAggregates(connectionPool)
 
 			Expect(resultAggregates).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&aggregateDef, &resultAggregates[0], "Oid", "TransitionFunction", "FinalFunction", "CombineFunction")
@@ -302,11 +302,11 @@ var _ = Describe("backup integration create statement tests", func() {
 				FinalFunction: 0, SortOperator: "", TransitionDataType: "numeric", TransitionDataSize: 1000,
 				InitialValue: "0", IsOrdered: false, MInitValIsNull: true,
 			}
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggregateDef, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggregateDef, funcInfoMap, emptyMetadata)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, `DROP AGGREGATE public.agg_6_features(numeric, numeric)`)
-			resultAggregates := backup.GetAggregates(connectionPool)
+			resultAggregates := testBackup.GetAggregates(connectionPool)
 
 			Expect(resultAggregates).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&aggregateDef, &resultAggregates[0], "Oid", "TransitionFunction", "FinalFunction", "CombineFunction")
@@ -320,11 +320,11 @@ var _ = Describe("backup integration create statement tests", func() {
 				IsOrdered: false, InitValIsNull: true, MInitValIsNull: true,
 			}
 
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggregateDef, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggregateDef, funcInfoMap, emptyMetadata)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, `DROP AGGREGATE public.myavg(numeric)`)
-			resultAggregates := backup.GetAggregates(connectionPool)
+			resultAggregates := testBackup.GetAggregates(connectionPool)
 
 			Expect(resultAggregates).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&aggregateDef, &resultAggregates[0], "Oid", "TransitionFunction", "FinalFunction", "SerialFunction", "DeserialFunction")
@@ -339,11 +339,11 @@ var _ = Describe("backup integration create statement tests", func() {
 				MFinalFuncExtra: true, MInitialValue: "0", MInitValIsNull: false,
 			}
 
-			backup.PrintCreateAggregateStatement(backupfile, toc, aggregateDef, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateAggregateStatement(backupfile, toc, aggregateDef, funcInfoMap, emptyMetadata)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, `DROP AGGREGATE public.moving_agg(numeric, numeric)`)
-			resultAggregates := backup.GetAggregates(connectionPool)
+			resultAggregates := testBackup.GetAggregates(connectionPool)
 
 			Expect(resultAggregates).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&aggregateDef, &resultAggregates[0], "Oid", "TransitionFunction", "MTransitionFunction", "MInverseTransitionFunction", "MFinalFunction")
@@ -362,12 +362,12 @@ var _ = Describe("backup integration create statement tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE FUNCTION public.money_to_text(money) RETURNS TEXT AS $$ SELECT textin(cash_out($1)) $$ LANGUAGE SQL;")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP FUNCTION public.money_to_text(money)")
 
-			backup.PrintCreateCastStatement(backupfile, toc, castDef, castMetadata)
+			testBackup.PrintCreateCastStatement(backupfile, toc, castDef, castMetadata)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP CAST (money AS text)")
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
-			resultCasts := backup.GetCasts(connectionPool)
+			resultCasts := testBackup.GetCasts(connectionPool)
 			Expect(resultCasts).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&castDef, &resultCasts[0], "Oid", "FunctionOid")
 		})
@@ -379,12 +379,12 @@ var _ = Describe("backup integration create statement tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TYPE public.casttesttype (INTERNALLENGTH = variable, INPUT = public.cast_in, OUTPUT = public.cast_out)")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TYPE public.casttesttype CASCADE")
 
-			backup.PrintCreateCastStatement(backupfile, toc, castDef, castMetadata)
+			testBackup.PrintCreateCastStatement(backupfile, toc, castDef, castMetadata)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP CAST (text AS public.casttesttype)")
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
-			resultCasts := backup.GetCasts(connectionPool)
+			resultCasts := testBackup.GetCasts(connectionPool)
 			Expect(resultCasts).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&castDef, &resultCasts[0], "Oid")
 		})
@@ -395,14 +395,14 @@ var _ = Describe("backup integration create statement tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE FUNCTION public.money_to_text(money) RETURNS TEXT AS $$ SELECT textin(cash_out($1)) $$ LANGUAGE SQL;")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP FUNCTION public.money_to_text(money)")
 
-			backup.PrintCreateCastStatement(backupfile, toc, castDef, castMetadata)
+			testBackup.PrintCreateCastStatement(backupfile, toc, castDef, castMetadata)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP CAST (money AS text)")
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
-			resultCasts := backup.GetCasts(connectionPool)
+			resultCasts := testBackup.GetCasts(connectionPool)
 			Expect(resultCasts).To(HaveLen(1))
-			resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, backup.TYPE_CAST)
+			resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_CAST)
 			resultMetadata := resultMetadataMap[resultCasts[0].GetUniqueID()]
 			structmatcher.ExpectStructsToMatchExcluding(&castDef, &resultCasts[0], "Oid", "FunctionOid")
 			structmatcher.ExpectStructsToMatchExcluding(&resultMetadata, &castMetadata, "Oid")
@@ -413,12 +413,12 @@ var _ = Describe("backup integration create statement tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TYPE public.custom_numeric AS (i numeric)")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TYPE public.custom_numeric")
 
-			backup.PrintCreateCastStatement(backupfile, toc, castDef, castMetadata)
+			testBackup.PrintCreateCastStatement(backupfile, toc, castDef, castMetadata)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP CAST (varchar AS public.custom_numeric)")
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
-			resultCasts := backup.GetCasts(connectionPool)
+			resultCasts := testBackup.GetCasts(connectionPool)
 			Expect(resultCasts).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&castDef, &resultCasts[0], "Oid")
 		})
@@ -446,13 +446,13 @@ var _ = Describe("backup integration create statement tests", func() {
 			}
 			procLangs := []backup.ProceduralLanguage{plpythonInfo}
 
-			backup.PrintCreateLanguageStatements(backupfile, toc, procLangs, funcInfoMap, langMetadataMap)
+			testBackup.PrintCreateLanguageStatements(backupfile, toc, procLangs, funcInfoMap, langMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP LANGUAGE plpythonu")
 
 			resultProcLangs := backup.GetProceduralLanguages(connectionPool)
-			resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_PROCLANGUAGE)
+			resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_PROCLANGUAGE)
 
 			plpythonInfo.Oid = testutils.OidFromObjectName(connectionPool, "", "plpythonu", backup.TYPE_PROCLANGUAGE)
 			Expect(resultProcLangs).To(HaveLen(1))
@@ -468,11 +468,11 @@ var _ = Describe("backup integration create statement tests", func() {
 			extensions := []backup.Extension{plperlExtension}
 			extensionMetadataMap := testutils.DefaultMetadataMap("EXTENSION", false, false, true, false)
 			extensionMetadata := extensionMetadataMap[plperlExtension.GetUniqueID()]
-			backup.PrintCreateExtensionStatements(backupfile, toc, extensions, extensionMetadataMap)
+			testBackup.PrintCreateExtensionStatements(backupfile, toc, extensions, extensionMetadataMap)
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP EXTENSION plperl; SET search_path=pg_catalog")
 			resultExtensions := backup.GetExtensions(connectionPool)
-			resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, backup.TYPE_EXTENSION)
+			resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_EXTENSION)
 			plperlExtension.Oid = testutils.OidFromObjectName(connectionPool, "", "plperl", backup.TYPE_EXTENSION)
 			Expect(resultExtensions).To(HaveLen(1))
 			plperlMetadata := resultMetadataMap[plperlExtension.GetUniqueID()]
@@ -488,14 +488,14 @@ var _ = Describe("backup integration create statement tests", func() {
 			convMetadataMap := testutils.DefaultMetadataMap("CONVERSION", false, true, true, false)
 			convMetadata := convMetadataMap[convOne.GetUniqueID()]
 
-			backup.PrintCreateConversionStatements(backupfile, toc, conversions, convMetadataMap)
+			testBackup.PrintCreateConversionStatements(backupfile, toc, conversions, convMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP CONVERSION public.conv_one")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP CONVERSION public.conv_two")
 
-			resultConversions := backup.GetConversions(connectionPool)
-			resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_CONVERSION)
+			resultConversions := testBackup.GetConversions(connectionPool)
+			resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_CONVERSION)
 
 			convOne.Oid = testutils.OidFromObjectName(connectionPool, "public", "conv_one", backup.TYPE_CONVERSION)
 			convTwo.Oid = testutils.OidFromObjectName(connectionPool, "public", "conv_two", backup.TYPE_CONVERSION)
@@ -516,8 +516,8 @@ var _ = Describe("backup integration create statement tests", func() {
 			foreignDataWrapperValidator := backup.ForeignDataWrapper{Name: "foreigndata1", Validator: 1}
 			foreignDataWrapperOptions := backup.ForeignDataWrapper{Name: "foreigndata2", Options: "dbname 'testdb'"}
 
-			backup.PrintCreateForeignDataWrapperStatement(backupfile, toc, foreignDataWrapperValidator, funcInfoMap, emptyMetadata)
-			backup.PrintCreateForeignDataWrapperStatement(backupfile, toc, foreignDataWrapperOptions, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateForeignDataWrapperStatement(backupfile, toc, foreignDataWrapperValidator, funcInfoMap, emptyMetadata)
+			testBackup.PrintCreateForeignDataWrapperStatement(backupfile, toc, foreignDataWrapperOptions, funcInfoMap, emptyMetadata)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP FOREIGN DATA WRAPPER foreigndata1")
@@ -536,7 +536,7 @@ var _ = Describe("backup integration create statement tests", func() {
 			testutils.SkipIfBefore6(connectionPool)
 			foreignServer := backup.ForeignServer{Name: "foreignserver", Type: "mytype", Version: "myversion", ForeignDataWrapper: "foreigndatawrapper", Options: "dbname 'testdb', host 'localhost'"}
 
-			backup.PrintCreateServerStatement(backupfile, toc, foreignServer, emptyMetadata)
+			testBackup.PrintCreateServerStatement(backupfile, toc, foreignServer, emptyMetadata)
 
 			testhelper.AssertQueryRuns(connectionPool, "CREATE FOREIGN DATA WRAPPER foreigndatawrapper")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP FOREIGN DATA WRAPPER foreigndatawrapper CASCADE")
@@ -36,7 +36,7 @@ MODIFIES SQL DATA
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP FUNCTION public.append(integer, integer)")
 			testhelper.AssertQueryRuns(connectionPool, "COMMENT ON FUNCTION public.append(integer, integer) IS 'this is a function comment'")
 
-			results := backup.GetFunctions(connectionPool)
+			results := testBackup.GetFunctions(connectionPool)
 
 			addFunction := backup.Function{
 				Schema: "public", Name: "add", ReturnsSet: false, FunctionBody: "SELECT $1 + $2",
@@ -71,7 +71,7 @@ LANGUAGE SQL`)
 				Volatility: "v", IsStrict: false, IsSecurityDefiner: false, Config: "", Cost: 100, NumRows: 0, DataAccess: "c",
 				Language: "sql", ExecLocation: "a"}
 			backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
-			results := backup.GetFunctions(connectionPool)
+			results := testBackup.GetFunctions(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&results[0], &addFunction, "Oid")
@@ -83,7 +83,7 @@ AS 'SELECT $1 + $2'
 LANGUAGE SQL WINDOW`)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP FUNCTION public.add(integer, integer)")
 
-			results := backup.GetFunctions(connectionPool)
+			results := testBackup.GetFunctions(connectionPool)
 
 			windowFunction := backup.Function{
 				Schema: "public", Name: "add", ReturnsSet: false, FunctionBody: "SELECT $1 + $2",
@@ -107,7 +107,7 @@ LANGUAGE SQL WINDOW
 EXECUTE ON ALL SEGMENTS;`)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP FUNCTION public.srf_on_all_segments(integer, integer)")
 
-			results := backup.GetFunctions(connectionPool)
+			results := testBackup.GetFunctions(connectionPool)
 
 			srfOnMasterFunction := backup.Function{
 				Schema: "public", Name: "srf_on_master", ReturnsSet: false, FunctionBody: "SELECT $1 + $2",
@@ -141,7 +141,7 @@ MODIFIES SQL DATA
 `)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP FUNCTION public.append(integer, integer)")
 
-			results := backup.GetFunctions(connectionPool)
+			results := testBackup.GetFunctions(connectionPool)
 
 			appendFunction := backup.Function{
 				Schema: "public", Name: "append", ReturnsSet: true, FunctionBody: "SELECT ($1, $2)",
@@ -157,7 +157,7 @@ MODIFIES SQL DATA
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TYPE public.textrange AS RANGE (SUBTYPE = pg_catalog.text)")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TYPE public.textrange")
 
-			results := backup.GetFunctions(connectionPool)
+			results := testBackup.GetFunctions(connectionPool)
 
 			Expect(results).To(HaveLen(0))
 		})
@@ -175,7 +175,7 @@ MODIFIES SQL DATA
 `)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP FUNCTION public.myfunc(integer)")
 
-			results := backup.GetFunctions(connectionPool)
+			results := testBackup.GetFunctions(connectionPool)
 
 			appendFunction := backup.Function{
 				Schema: "public", Name: "myfunc", ReturnsSet: false, FunctionBody: `
@@ -208,7 +208,7 @@ MODIFIES SQL DATA
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP FUNCTION public.myfunc(integer)")
 			defer testhelper.AssertQueryRuns(connectionPool, `DROP SCHEMA "abc""def"`)
 
-			results := backup.GetFunctions(connectionPool)
+			results := testBackup.GetFunctions(connectionPool)
 
 			appendFunction := backup.Function{
 				Schema: "public", Name: "myfunc", ReturnsSet: false, FunctionBody: `
@@ -247,7 +247,7 @@ STABLE
 			testhelper.AssertQueryRuns(connectionPool, `CREATE FUNCTION public."specChar"(t text, "precision" double precision) RETURNS double precision AS $$BEGIN RETURN precision + 1; END;$$ LANGUAGE PLPGSQL;`)
 			defer testhelper.AssertQueryRuns(connectionPool, `DROP FUNCTION public."specChar"(text, double precision)`)
 
-			results := backup.GetFunctions4(connectionPool)
+			results := testBackup.GetFunctions4(connectionPool)
 
 			addFunction := backup.Function{
 				Schema: "public", Name: "add", ReturnsSet: false, FunctionBody: "SELECT $1 + $2",
@@ -284,7 +284,7 @@ LANGUAGE SQL`)
 				BinaryPath: "", Arguments: "", IdentArgs: "", ResultType: "",
 				Volatility: "v", IsStrict: false, IsSecurityDefiner: false, Language: "sql", ExecLocation: "a"}
 			backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
-			results := backup.GetFunctions4(connectionPool)
+			results := testBackup.GetFunctions4(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&results[0], &addFunction, "Oid")
@@ -328,7 +328,7 @@ STYPE = character,
 SORTOP = ~>~ );`)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP AGGREGATE public.ascii_max(character)")
 
-			resultAggregates := backup.GetAggregates(connectionPool)
+			resultAggregates := testBackup.GetAggregates(connectionPool)
 			aggregateDef := backup.Aggregate{
 				Schema: "public", Name: "ascii_max", Arguments: "character", IdentArgs: "character",
 				TransitionFunction: transitionOid, FinalFunction: 0, SortOperator: "~>~", SortOperatorSchema: "pg_catalog", TransitionDataType: "character",
@@ -350,7 +350,7 @@ CREATE AGGREGATE public.agg_prefunc(numeric, numeric) (
 			transitionOid := testutils.OidFromObjectName(connectionPool, "public", "mysfunc_accum", backup.TYPE_FUNCTION)
 			prelimOid := testutils.OidFromObjectName(connectionPool, "public", "mypre_accum", backup.TYPE_FUNCTION)
 
-			result := backup.GetAggregates(connectionPool)
+			result := testBackup.GetAggregates(connectionPool)
 
 			aggregateDef := backup.Aggregate{
 				Schema: "public", Name: "agg_prefunc", Arguments: "numeric, numeric",
@@ -398,7 +398,7 @@ CREATE AGGREGATE testschema.agg_prefunc(numeric, numeric) (
 			}
 			backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
 
-			result := backup.GetAggregates(connectionPool)
+			result := testBackup.GetAggregates(connectionPool)
 
 			Expect(result).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&result[0], &aggregateDef, "Oid")
@@ -420,7 +420,7 @@ CREATE AGGREGATE public.agg_hypo_ord (VARIADIC "any" ORDER BY VARIADIC "any")
 			transitionOid := testutils.OidFromObjectName(connectionPool, "pg_catalog", "ordered_set_transition_multi", backup.TYPE_FUNCTION)
 			finalOid := testutils.OidFromObjectName(connectionPool, "pg_catalog", "rank_final", backup.TYPE_FUNCTION)
 
-			result := backup.GetAggregates(connectionPool)
+			result := testBackup.GetAggregates(connectionPool)
 
 			aggregateDef := backup.Aggregate{
 				Schema: "public", Name: "agg_hypo_ord", Arguments: `VARIADIC "any" ORDER BY VARIADIC "any"`,
@@ -446,7 +446,7 @@ CREATE AGGREGATE public.agg_combinefunc(numeric, numeric) (
 			transitionOid := testutils.OidFromObjectName(connectionPool, "public", "mysfunc_accum", backup.TYPE_FUNCTION)
 			combineOid := testutils.OidFromObjectName(connectionPool, "public", "mypre_accum", backup.TYPE_FUNCTION)
 
-			result := backup.GetAggregates(connectionPool)
+			result := testBackup.GetAggregates(connectionPool)
 
 			aggregateDef := backup.Aggregate{
 				Schema: "public", Name: "agg_combinefunc", Arguments: "numeric, numeric",
@@ -473,7 +473,7 @@ CREATE AGGREGATE public.myavg (numeric) (
 			serialOid := testutils.OidFromObjectName(connectionPool, "pg_catalog", "numeric_avg_serialize", backup.TYPE_FUNCTION)
 			deserialOid := testutils.OidFromObjectName(connectionPool, "pg_catalog", "numeric_avg_deserialize", backup.TYPE_FUNCTION)
 
-			result := backup.GetAggregates(connectionPool)
+			result := testBackup.GetAggregates(connectionPool)
 
 			aggregateDef := backup.Aggregate{
 				Schema: "public", Name: "myavg", Arguments: "numeric",
@@ -504,7 +504,7 @@ CREATE AGGREGATE public.moving_agg(numeric,numeric) (
 
 			sfuncOid := testutils.OidFromObjectName(connectionPool, "public", "mysfunc_accum", backup.TYPE_FUNCTION)
 
-			result := backup.GetAggregates(connectionPool)
+			result := testBackup.GetAggregates(connectionPool)
 
 			aggregateDef := backup.Aggregate{
 				Schema: "public", Name: "moving_agg", Arguments: "numeric, numeric",
@@ -520,14 +520,14 @@ CREATE AGGREGATE public.moving_agg(numeric,numeric) (
 	})
 	Describe("GetFunctionOidToInfoMap", func() {
 		It("returns map containing function information", func() {
-			result := backup.GetFunctionOidToInfoMap(connectionPool)
+			result := testBackup.GetFunctionOidToInfoMap(connectionPool)
 			initialLength := len(result)
 			testhelper.AssertQueryRuns(connectionPool, `CREATE FUNCTION public.add(integer, integer) RETURNS integer
 AS 'SELECT $1 + $2'
 LANGUAGE SQL`)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP FUNCTION public.add(integer, integer)")
 
-			result = backup.GetFunctionOidToInfoMap(connectionPool)
+			result = testBackup.GetFunctionOidToInfoMap(connectionPool)
 			oid := testutils.OidFromObjectName(connectionPool, "public", "add", backup.TYPE_FUNCTION)
 			Expect(result).To(HaveLen(initialLength + 1))
 			Expect(result[oid].QualifiedName).To(Equal("public.add"))
@@ -535,7 +535,7 @@ LANGUAGE SQL`)
 			Expect(result[oid].IsInternal).To(BeFalse())
 		})
 		It("returns a map containing an internal function", func() {
-			result := backup.GetFunctionOidToInfoMap(connectionPool)
+			result := testBackup.GetFunctionOidToInfoMap(connectionPool)
 
 			oid := testutils.OidFromObjectName(connectionPool, "pg_catalog", "boolin", backup.TYPE_FUNCTION)
 			Expect(result[oid].QualifiedName).To(Equal("pg_catalog.boolin"))
@@ -550,7 +550,7 @@ LANGUAGE SQL`)
 			testhelper.AssertQueryRuns(connectionPool, "CREATE CAST (bool AS text) WITH FUNCTION public.casttotext(bool) AS ASSIGNMENT")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP CAST (bool AS text)")
 
-			results := backup.GetCasts(connectionPool)
+			results := testBackup.GetCasts(connectionPool)
 
 			castDef := backup.Cast{Oid: 0, SourceTypeFQN: "pg_catalog.bool", TargetTypeFQN: "pg_catalog.text", FunctionSchema: "public", FunctionName: "casttotext", FunctionArgs: "boolean", CastContext: "a", CastMethod: "f"}
 
@@ -564,7 +564,7 @@ LANGUAGE SQL`)
 			testhelper.AssertQueryRuns(connectionPool, "CREATE CAST (text AS integer) WITH FUNCTION public.casttoint(text) AS ASSIGNMENT")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP CAST (text AS int4)")
 
-			results := backup.GetCasts(connectionPool)
+			results := testBackup.GetCasts(connectionPool)
 
 			castDef := backup.Cast{Oid: 0, SourceTypeFQN: "pg_catalog.text", TargetTypeFQN: "pg_catalog.int4", FunctionSchema: "public", FunctionName: "casttoint", FunctionArgs: "text", CastContext: "a", CastMethod: "f"}
 
@@ -579,7 +579,7 @@ LANGUAGE SQL`)
 			testhelper.AssertQueryRuns(connectionPool, "CREATE CAST (text AS public.casttesttype) WITHOUT FUNCTION AS IMPLICIT")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP CAST (text AS public.casttesttype)")
 
-			results := backup.GetCasts(connectionPool)
+			results := testBackup.GetCasts(connectionPool)
 
 			castDef := backup.Cast{Oid: 0, SourceTypeFQN: "pg_catalog.text", TargetTypeFQN: "public.casttesttype", FunctionSchema: "", FunctionName: "", FunctionArgs: "", CastContext: "i", CastMethod: "b"}
 
@@ -599,7 +599,7 @@ LANGUAGE SQL`)
 			testhelper.AssertQueryRuns(connectionPool, "CREATE CAST (testschema1.casttesttype AS text) WITHOUT FUNCTION AS IMPLICIT")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP CAST (testschema1.casttesttype AS text)")
 
-			results := backup.GetCasts(connectionPool)
+			results := testBackup.GetCasts(connectionPool)
 
 			castDefTarget := backup.Cast{Oid: 0, SourceTypeFQN: "pg_catalog.text", TargetTypeFQN: "testschema1.casttesttype", FunctionSchema: "", FunctionName: "", FunctionArgs: "", CastContext: "i", CastMethod: "b"}
 			castDefSource := backup.Cast{Oid: 0, SourceTypeFQN: "testschema1.casttesttype", TargetTypeFQN: "pg_catalog.text", FunctionSchema: "", FunctionName: "", FunctionArgs: "", CastContext: "i", CastMethod: "b"}
@@ -615,7 +615,7 @@ LANGUAGE SQL`)
 			testhelper.AssertQueryRuns(connectionPool, "CREATE CAST (varchar AS public.custom_numeric) WITH INOUT")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP CAST (varchar AS public.custom_numeric)")
 
-			results := backup.GetCasts(connectionPool)
+			results := testBackup.GetCasts(connectionPool)
 
 			castDef := backup.Cast{Oid: 0, SourceTypeFQN: `pg_catalog."varchar"`, TargetTypeFQN: "public.custom_numeric", FunctionSchema: "", FunctionName: "", FunctionArgs: "", CastContext: "e", CastMethod: "i"}
 
@@ -634,7 +634,8 @@ LANGUAGE SQL`)
 			Expect(results).To(HaveLen(1))
 
 			plperlDef := backup.Extension{Oid: 0, Name: "plperl", Schema: "pg_catalog"}
-			structmatcher.ExpectStructsToMatchExcluding(&plperlDef, &results[0], "Oid")
+			structmatcher.ExpectStructsToMatchExcluding(&plperlDef, &results[0], "Oid", "Version")
+			Expect(results[0].Version).ToNot(BeEmpty())
 		})
 	})
 	Describe("GetProceduralLanguages", func() {
@@ -666,7 +667,7 @@ LANGUAGE SQL`)
 
 			expectedConversion := backup.Conversion{Oid: 0, Schema: "public", Name: "testconv", ForEncoding: "LATIN1", ToEncoding: "MULE_INTERNAL", ConversionFunction: "pg_catalog.latin1_to_mic", IsDefault: false}
 
-			resultConversions := backup.GetConversions(connectionPool)
+			resultConversions := testBackup.GetConversions(connectionPool)
 
 			Expect(resultConversions).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&expectedConversion, &resultConversions[0], "Oid")
@@ -682,7 +683,7 @@ LANGUAGE SQL`)
 			expectedConversion := backup.Conversion{Oid: 0, Schema: "testschema", Name: "testconv", ForEncoding: "LATIN1", ToEncoding: "MULE_INTERNAL", ConversionFunction: "pg_catalog.latin1_to_mic", IsDefault: false}
 
 			backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
-			resultConversions := backup.GetConversions(connectionPool)
+			resultConversions := testBackup.GetConversions(connectionPool)
 
 			Expect(resultConversions).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&expectedConversion, &resultConversions[0], "Oid")
@@ -24,12 +24,12 @@ var _ = Describe("backup integration create statement tests", func() {
 
 			operator := backup.Operator{Oid: 0, Schema: "testschema", Name: "##", Procedure: "testschema.\"testFunc\"", LeftArgType: "path", RightArgType: "path", CommutatorOp: "0", NegatorOp: "0", RestrictFunction: "-", JoinFunction: "-", CanHash: false, CanMerge: false}
 
-			backup.PrintCreateOperatorStatement(backupfile, toc, operator, backup.ObjectMetadata{})
+			testBackup.PrintCreateOperatorStatement(backupfile, toc, operator, backup.ObjectMetadata{})
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP OPERATOR testschema.##(path, path)")
 
-			resultOperators := backup.GetOperators(connectionPool)
+			resultOperators := testBackup.GetOperators(connectionPool)
 			Expect(resultOperators).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&operator, &resultOperators[0], "Oid")
 		})
@@ -43,16 +43,16 @@ var _ = Describe("backup integration create statement tests", func() {
 			operatorMetadata := testutils.DefaultMetadata("OPERATOR", false, false, true, false)
 			operator := backup.Operator{Oid: 1, Schema: "testschema", Name: "##", Procedure: "testschema.\"testFunc\"", LeftArgType: "path", RightArgType: "path", CommutatorOp: "0", NegatorOp: "0", RestrictFunction: "-", JoinFunction: "-", CanHash: false, CanMerge: false}
 
-			backup.PrintCreateOperatorStatement(backupfile, toc, operator, operatorMetadata)
+			testBackup.PrintCreateOperatorStatement(backupfile, toc, operator, operatorMetadata)
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP OPERATOR testschema.##(path, path)")
 
-			resultOperators := backup.GetOperators(connectionPool)
+			resultOperators := testBackup.GetOperators(connectionPool)
 			Expect(resultOperators).To(HaveLen(1))
-			resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, backup.TYPE_OPERATOR)
+			resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_OPERATOR)
 			resultMetadata := resultMetadataMap[resultOperators[0].GetUniqueID()]
 			structmatcher.ExpectStructsToMatchExcluding(&operator, &resultOperators[0], "Oid")
-			structmatcher.ExpectStructsToMatchExcluding(&resultMetadata, &operatorMetadata, "Oid")
+			structmatcher.ExpectStructsToMatchExcluding(&resultMetadata, &operatorMetadata, "Oid", "Owner")
 		})
 	})
 	Describe("PrintCreateOperatorFamilyStatements", func() {
@@ -63,12 +63,12 @@ var _ = Describe("backup integration create statement tests", func() {
 			operatorFamily := backup.OperatorFamily{Oid: 1, Schema: "public", Name: "testfam", IndexMethod: "hash"}
 			operatorFamilies := []backup.OperatorFamily{operatorFamily}
 
-			backup.PrintCreateOperatorFamilyStatements(backupfile, toc, operatorFamilies, backup.MetadataMap{})
+			testBackup.PrintCreateOperatorFamilyStatements(backupfile, toc, operatorFamilies, backup.MetadataMap{})
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP OPERATOR FAMILY public.testfam USING hash")
 
-			resultOperatorFamilies := backup.GetOperatorFamilies(connectionPool)
+			resultOperatorFamilies := testBackup.GetOperatorFamilies(connectionPool)
 			Expect(resultOperatorFamilies).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&operatorFamily, &resultOperatorFamilies[0], "Oid")
 		})
@@ -78,14 +78,14 @@ var _ = Describe("backup integration create statement tests", func() {
 			operatorFamilyMetadataMap := testutils.DefaultMetadataMap("OPERATOR FAMILY", false, true, true, false)
 			operatorFamilyMetadata := operatorFamilyMetadataMap[operatorFamily.GetUniqueID()]
 
-			backup.PrintCreateOperatorFamilyStatements(backupfile, toc, operatorFamilies, operatorFamilyMetadataMap)
+			testBackup.PrintCreateOperatorFamilyStatements(backupfile, toc, operatorFamilies, operatorFamilyMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP OPERATOR FAMILY public.testfam USING hash")
 
-			resultOperatorFamilies := backup.GetOperatorFamilies(connectionPool)
+			resultOperatorFamilies := testBackup.GetOperatorFamilies(connectionPool)
 			Expect(resultOperatorFamilies).To(HaveLen(1))
-			resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_OPERATORFAMILY)
+			resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_OPERATORFAMILY)
 			resultMetadata := resultMetadataMap[resultOperatorFamilies[0].GetUniqueID()]
 			structmatcher.ExpectStructsToMatchExcluding(&operatorFamily, &resultOperatorFamilies[0], "Oid")
 			structmatcher.ExpectStructsToMatchExcluding(&resultMetadata, &operatorFamilyMetadata, "Oid")
@@ -100,7 +100,7 @@ var _ = Describe("backup integration create statement tests", func() {
 				operatorClass.FamilyName = ""
 			}
 
-			backup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, emptyMetadata)
+			testBackup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, emptyMetadata)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			if connectionPool.Version.Before("5") {
@@ -109,7 +109,7 @@ var _ = Describe("backup integration create statement tests", func() {
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP OPERATOR FAMILY public.testclass USING hash CASCADE")
 			}
 
-			resultOperatorClasses := backup.GetOperatorClasses(connectionPool)
+			resultOperatorClasses := testBackup.GetOperatorClasses(connectionPool)
 			Expect(resultOperatorClasses).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&operatorClass, &resultOperatorClasses[0], "Oid")
 		})
@@ -132,11 +132,11 @@ var _ = Describe("backup integration create statement tests", func() {
 
 			testhelper.AssertQueryRuns(connectionPool, "CREATE OPERATOR FAMILY public.testfam USING gist")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP OPERATOR FAMILY public.testfam USING gist CASCADE")
-			backup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, emptyMetadata)
+			testBackup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, emptyMetadata)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
-			resultOperatorClasses := backup.GetOperatorClasses(connectionPool)
+			resultOperatorClasses := testBackup.GetOperatorClasses(connectionPool)
 			Expect(resultOperatorClasses).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&operatorClass, &resultOperatorClasses[0], "Oid", "Operators.ClassOid", "Functions.ClassOid")
 		})
@@ -148,12 +148,12 @@ var _ = Describe("backup integration create statement tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE OPERATOR FAMILY public.sort_family_name USING btree")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP OPERATOR FAMILY public.sort_family_name USING btree")
 
-			backup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, emptyMetadata)
+			testBackup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, emptyMetadata)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP OPERATOR FAMILY public.testclass USING gist CASCADE")
 
-			resultOperatorClasses := backup.GetOperatorClasses(connectionPool)
+			resultOperatorClasses := testBackup.GetOperatorClasses(connectionPool)
 			Expect(resultOperatorClasses).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&operatorClass, &resultOperatorClasses[0], "Oid", "Operators.ClassOid", "Functions.ClassOid")
 		})
@@ -165,7 +165,7 @@ var _ = Describe("backup integration create statement tests", func() {
 				operatorClass.FamilyName = ""
 			}
 
-			backup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, operatorClassMetadata)
+			testBackup.PrintCreateOperatorClassStatement(backupfile, toc, operatorClass, operatorClassMetadata)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			if connectionPool.Version.Before("5") {
@@ -174,11 +174,11 @@ var _ = Describe("backup integration create statement tests", func() {
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP OPERATOR FAMILY public.testclass USING hash CASCADE")
 			}
 
-			resultOperatorClasses := backup.GetOperatorClasses(connectionPool)
+			resultOperatorClasses := testBackup.GetOperatorClasses(connectionPool)
 			Expect(resultOperatorClasses).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&operatorClass, &resultOperatorClasses[0], "Oid")
 
-			resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_OPERATORCLASS)
+			resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_OPERATORCLASS)
 			resultMetadata := resultMetadataMap[resultOperatorClasses[0].GetUniqueID()]
 			structmatcher.ExpectStructsToMatchExcluding(&resultMetadata, &operatorClassMetadata, "Oid")
 
@@ -19,7 +19,7 @@ var _ = Describe("backup integration tests", func() {
 
 			expectedOperator := backup.Operator{Oid: 0, Schema: "public", Name: "##", Procedure: "numeric_fac", LeftArgType: "bigint", RightArgType: "-", CommutatorOp: "0", NegatorOp: "0", RestrictFunction: "-", JoinFunction: "-", CanHash: false, CanMerge: false}
 
-			results := backup.GetOperators(connectionPool)
+			results := testBackup.GetOperators(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&expectedOperator, &results[0], "Oid")
@@ -49,7 +49,7 @@ var _ = Describe("backup integration tests", func() {
 			version4expectedOperator := backup.Operator{Oid: 0, Schema: "testschema", Name: "##", Procedure: `testschema."testFunc"`, LeftArgType: "path", RightArgType: "path", CommutatorOp: "testschema.##", NegatorOp: "public.###", RestrictFunction: "eqsel", JoinFunction: "eqjoinsel", CanHash: true, CanMerge: false}
 			expectedOperator := backup.Operator{Oid: 0, Schema: "testschema", Name: "##", Procedure: `testschema."testFunc"`, LeftArgType: "path", RightArgType: "path", CommutatorOp: "testschema.##", NegatorOp: "public.###", RestrictFunction: "eqsel", JoinFunction: "eqjoinsel", CanHash: true, CanMerge: true}
 
-			results := backup.GetOperators(connectionPool)
+			results := testBackup.GetOperators(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			if connectionPool.Version.Before("5") {
@@ -69,7 +69,7 @@ var _ = Describe("backup integration tests", func() {
 
 			expectedOperator := backup.Operator{Oid: 0, Schema: "testschema", Name: "##", Procedure: "numeric_fac", LeftArgType: "bigint", RightArgType: "-", CommutatorOp: "0", NegatorOp: "0", RestrictFunction: "-", JoinFunction: "-", CanHash: false, CanMerge: false}
 
-			results := backup.GetOperators(connectionPool)
+			results := testBackup.GetOperators(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&expectedOperator, &results[0], "Oid")
@@ -85,7 +85,7 @@ var _ = Describe("backup integration tests", func() {
 
 			expectedOperator := backup.OperatorFamily{Oid: 0, Schema: "public", Name: "testfam", IndexMethod: "hash"}
 
-			results := backup.GetOperatorFamilies(connectionPool)
+			results := testBackup.GetOperatorFamilies(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&expectedOperator, &results[0], "Oid")
@@ -101,12 +101,39 @@ var _ = Describe("backup integration tests", func() {
 
 			expectedOperator := backup.OperatorFamily{Oid: 0, Schema: "testschema", Name: "testfam", IndexMethod: "hash"}
 
-			results := backup.GetOperatorFamilies(connectionPool)
+			results := testBackup.GetOperatorFamilies(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&expectedOperator, &results[0], "Oid")
 		})
 	})
+	Describe("GetOperatorFamilyMembers", func() {
+		BeforeEach(func() {
+			testutils.SkipIfBefore5(connectionPool)
+		})
+		It("returns operators and functions added to an operator family outside of an operator class", func() {
+			testhelper.AssertQueryRuns(connectionPool, "CREATE OPERATOR FAMILY public.testfam USING hash;")
+			defer testhelper.AssertQueryRuns(connectionPool, "DROP OPERATOR FAMILY public.testfam USING hash")
+			testhelper.AssertQueryRuns(connectionPool, "ALTER OPERATOR FAMILY public.testfam USING hash ADD OPERATOR 1 =(integer,bigint), FUNCTION 1 (integer, integer) hashint4(integer)")
+
+			expectedOperator := backup.OperatorFamilyMember{FamilySchema: "public", FamilyName: "testfam", IndexMethod: "hash", MemberType: "OPERATOR", Number: 1, Member: "=(integer,bigint)", LeftType: "integer", RightType: "bigint"}
+			expectedFunction := backup.OperatorFamilyMember{FamilySchema: "public", FamilyName: "testfam", IndexMethod: "hash", MemberType: "FUNCTION", Number: 1, Member: "hashint4(integer)", LeftType: "integer", RightType: "integer"}
+
+			results := testBackup.GetOperatorFamilyMembers(connectionPool)
+
+			Expect(results).To(HaveLen(2))
+			structmatcher.ExpectStructsToMatch(&expectedOperator, &results[0])
+			structmatcher.ExpectStructsToMatch(&expectedFunction, &results[1])
+		})
+		It("does not return members of an operator class", func() {
+			testhelper.AssertQueryRuns(connectionPool, "CREATE OPERATOR CLASS public.testclass FOR TYPE int USING hash AS OPERATOR 1 =(integer,integer), FUNCTION 1 hashint4(integer)")
+			defer testhelper.AssertQueryRuns(connectionPool, "DROP OPERATOR FAMILY public.testclass USING hash")
+
+			results := testBackup.GetOperatorFamilyMembers(connectionPool)
+
+			Expect(results).To(BeEmpty())
+		})
+	})
 	Describe("GetOperatorClasses", func() {
 		It("returns a slice of operator classes", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE OPERATOR CLASS public.testclass FOR TYPE int USING hash AS STORAGE int")
@@ -119,7 +146,7 @@ var _ = Describe("backup integration tests", func() {
 			version4expected := backup.OperatorClass{Oid: 0, Schema: "public", Name: "testclass", FamilySchema: "", FamilyName: "", IndexMethod: "hash", Type: "integer", Default: false, StorageType: "-", Operators: nil, Functions: nil}
 			expected := backup.OperatorClass{Oid: 0, Schema: "public", Name: "testclass", FamilySchema: "public", FamilyName: "testclass", IndexMethod: "hash", Type: "integer", Default: false, StorageType: "-", Operators: nil, Functions: nil}
 
-			results := backup.GetOperatorClasses(connectionPool)
+			results := testBackup.GetOperatorClasses(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			if connectionPool.Version.Before("5") {
@@ -139,7 +166,7 @@ var _ = Describe("backup integration tests", func() {
 
 			expected := backup.OperatorClass{Oid: 0, Schema: "public", Name: "testclass", FamilySchema: "testschema", FamilyName: "testfam", IndexMethod: "gist", Type: "integer", Default: false, StorageType: "-", Operators: nil, Functions: nil}
 
-			results := backup.GetOperatorClasses(connectionPool)
+			results := testBackup.GetOperatorClasses(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&expected, &results[0], "Oid")
@@ -155,7 +182,7 @@ var _ = Describe("backup integration tests", func() {
 			version4expected := backup.OperatorClass{Oid: 0, Schema: "public", Name: "testclass", FamilySchema: "", FamilyName: "", IndexMethod: "gist", Type: "integer", Default: true, StorageType: "text", Operators: nil, Functions: nil}
 			expected := backup.OperatorClass{Oid: 0, Schema: "public", Name: "testclass", FamilySchema: "public", FamilyName: "testclass", IndexMethod: "gist", Type: "integer", Default: true, StorageType: "text", Operators: nil, Functions: nil}
 
-			results := backup.GetOperatorClasses(connectionPool)
+			results := testBackup.GetOperatorClasses(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			if connectionPool.Version.Before("5") {
@@ -188,7 +215,7 @@ var _ = Describe("backup integration tests", func() {
 			version4expected := backup.OperatorClass{Oid: 0, Schema: "public", Name: "testclass", FamilySchema: "", FamilyName: "", IndexMethod: "gist", Type: "integer", Default: false, StorageType: "-", Operators: expectedOperators, Functions: expected4Functions}
 			expected := backup.OperatorClass{Oid: 0, Schema: "public", Name: "testclass", FamilySchema: "public", FamilyName: "testclass", IndexMethod: "gist", Type: "integer", Default: false, StorageType: "-", Operators: expectedOperators, Functions: expectedFunctions}
 
-			results := backup.GetOperatorClasses(connectionPool)
+			results := testBackup.GetOperatorClasses(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			if connectionPool.Version.Before("5") {
@@ -217,7 +244,7 @@ var _ = Describe("backup integration tests", func() {
 			version4expected := backup.OperatorClass{Oid: 0, Schema: "testschema", Name: "testclass", FamilySchema: "", FamilyName: "", IndexMethod: "hash", Type: "integer", Default: false, StorageType: "-", Operators: nil, Functions: nil}
 			expected := backup.OperatorClass{Oid: 0, Schema: "testschema", Name: "testclass", FamilySchema: "testschema", FamilyName: "testclass", IndexMethod: "hash", Type: "integer", Default: false, StorageType: "-", Operators: nil, Functions: nil}
 
-			results := backup.GetOperatorClasses(connectionPool)
+			results := testBackup.GetOperatorClasses(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			if connectionPool.Version.Before("5") {
@@ -238,7 +265,7 @@ var _ = Describe("backup integration tests", func() {
 			expectedOperators := []backup.OperatorClassOperator{{ClassOid: 0, StrategyNumber: 1, Operator: "=(integer,integer)", Recheck: false, OrderByFamily: "public.sort_family"}}
 			expected := backup.OperatorClass{Oid: 0, Schema: "public", Name: "testclass", FamilySchema: "public", FamilyName: "testclass", IndexMethod: "gist", Type: "integer", Default: false, StorageType: "-", Operators: expectedOperators, Functions: nil}
 
-			results := backup.GetOperatorClasses(connectionPool)
+			results := testBackup.GetOperatorClasses(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&expected, &results[0], "Oid", "Operators.ClassOid", "Functions.ClassOid")
@@ -23,10 +23,10 @@ var _ = Describe("backup integration tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE SCHEMA testschema")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP SCHEMA testschema CASCADE")
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE testschema.testtable(t text)")
-			includeRelationsQuoted, err := options.QuoteTableNames(connectionPool, backup.MustGetFlagStringArray(utils.INCLUDE_RELATION))
+			includeRelationsQuoted, err := options.QuoteTableNames(connectionPool, testBackup.MustGetFlagStringArray(utils.INCLUDE_RELATION))
 			Expect(err).NotTo(HaveOccurred())
 
-			tables := backup.GetIncludedUserTableRelations(connectionPool, includeRelationsQuoted)
+			tables := testBackup.GetIncludedUserTableRelations(connectionPool, includeRelationsQuoted)
 
 			tableFoo := backup.Relation{Schema: "public", Name: "foo"}
 
@@ -48,7 +48,7 @@ PARTITION BY LIST (gender)
 				testhelper.AssertQueryRuns(connectionPool, createStmt)
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.rank")
 
-				tables := backup.GetIncludedUserTableRelations(connectionPool, []string{})
+				tables := testBackup.GetIncludedUserTableRelations(connectionPool, []string{})
 
 				tableRank := backup.Relation{Schema: "public", Name: "rank"}
 
@@ -67,7 +67,7 @@ PARTITION BY LIST (gender)
 				testhelper.AssertQueryRuns(connectionPool, createStmt)
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.rank")
 
-				tables := backup.GetIncludedUserTableRelations(connectionPool, []string{})
+				tables := testBackup.GetIncludedUserTableRelations(connectionPool, []string{})
 
 				expectedTableNames := []string{"public.rank", "public.rank_1_prt_boys", "public.rank_1_prt_girls", "public.rank_1_prt_other"}
 				tableNames := make([]string, 0)
@@ -89,7 +89,7 @@ PARTITION BY LIST (gender)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE testschema.foo")
 
 			_ = backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
-			tables := backup.GetIncludedUserTableRelations(connectionPool, []string{})
+			tables := testBackup.GetIncludedUserTableRelations(connectionPool, []string{})
 
 			tableFoo := backup.Relation{Schema: "testschema", Name: "foo"}
 
@@ -105,9 +105,9 @@ PARTITION BY LIST (gender)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE testschema.foo")
 
 			_ = backupCmdFlags.Set(utils.INCLUDE_RELATION, "testschema.foo")
-			includeRelationsQuoted, err := options.QuoteTableNames(connectionPool, backup.MustGetFlagStringArray(utils.INCLUDE_RELATION))
+			includeRelationsQuoted, err := options.QuoteTableNames(connectionPool, testBackup.MustGetFlagStringArray(utils.INCLUDE_RELATION))
 			Expect(err).NotTo(HaveOccurred())
-			tables := backup.GetIncludedUserTableRelations(connectionPool, includeRelationsQuoted)
+			tables := testBackup.GetIncludedUserTableRelations(connectionPool, includeRelationsQuoted)
 
 			tableFoo := backup.Relation{Schema: "testschema", Name: "foo"}
 
@@ -123,7 +123,7 @@ PARTITION BY LIST (gender)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE testschema.foo")
 
 			_ = backupCmdFlags.Set(utils.EXCLUDE_RELATION, "testschema.foo")
-			tables := backup.GetIncludedUserTableRelations(connectionPool, []string{})
+			tables := testBackup.GetIncludedUserTableRelations(connectionPool, []string{})
 
 			tableFoo := backup.Relation{Schema: "public", Name: "foo"}
 
@@ -142,7 +142,7 @@ PARTITION BY LIST (gender)
 
 			_ = backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
 			_ = backupCmdFlags.Set(utils.EXCLUDE_RELATION, "testschema.foo")
-			tables := backup.GetIncludedUserTableRelations(connectionPool, []string{})
+			tables := testBackup.GetIncludedUserTableRelations(connectionPool, []string{})
 
 			tableFoo := backup.Relation{Schema: "testschema", Name: "bar"}
 			Expect(tables).To(HaveLen(1))
@@ -153,7 +153,7 @@ PARTITION BY LIST (gender)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.foo")
 
 			_ = backupCmdFlags.Set(utils.EXCLUDE_RELATION, "testschema.nonexistant")
-			tables := backup.GetIncludedUserTableRelations(connectionPool, []string{})
+			tables := testBackup.GetIncludedUserTableRelations(connectionPool, []string{})
 
 			tableFoo := backup.Relation{Schema: "public", Name: "foo"}
 
@@ -171,7 +171,7 @@ PARTITION BY LIST (gender)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP SCHEMA testschema CASCADE")
 			testhelper.AssertQueryRuns(connectionPool, "CREATE SEQUENCE testschema.my_sequence2")
 
-			sequences := backup.GetAllSequenceRelations(connectionPool)
+			sequences := testBackup.GetAllSequenceRelations(connectionPool)
 
 			mySequence := backup.Relation{Schema: "public", Name: "my_sequence"}
 			mySequence2 := backup.Relation{Schema: "testschema", Name: "my_sequence2"}
@@ -190,7 +190,7 @@ PARTITION BY LIST (gender)
 			mySequence := backup.Relation{Schema: "testschema", Name: "my_sequence"}
 
 			_ = backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
-			sequences := backup.GetAllSequenceRelations(connectionPool)
+			sequences := testBackup.GetAllSequenceRelations(connectionPool)
 
 			Expect(sequences).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&mySequence, &sequences[0], "SchemaOid", "Oid")
@@ -203,7 +203,7 @@ PARTITION BY LIST (gender)
 			testhelper.AssertQueryRuns(connectionPool, "ALTER SEQUENCE public.my_sequence OWNED BY public.seq_table.i")
 			_ = backupCmdFlags.Set(utils.INCLUDE_RELATION, "public.seq_table")
 
-			sequences := backup.GetAllSequenceRelations(connectionPool)
+			sequences := testBackup.GetAllSequenceRelations(connectionPool)
 
 			Expect(sequences).To(BeEmpty())
 		})
@@ -216,7 +216,7 @@ PARTITION BY LIST (gender)
 			mySequence := backup.Relation{Schema: "public", Name: "my_sequence"}
 
 			_ = backupCmdFlags.Set(utils.EXCLUDE_RELATION, "public.seq_table")
-			sequences := backup.GetAllSequenceRelations(connectionPool)
+			sequences := testBackup.GetAllSequenceRelations(connectionPool)
 
 			Expect(sequences).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&mySequence, &sequences[0], "SchemaOid", "Oid")
@@ -230,7 +230,7 @@ PARTITION BY LIST (gender)
 			sequence2 := backup.Relation{Schema: "public", Name: "sequence2"}
 
 			_ = backupCmdFlags.Set(utils.EXCLUDE_RELATION, "public.sequence1")
-			sequences := backup.GetAllSequenceRelations(connectionPool)
+			sequences := testBackup.GetAllSequenceRelations(connectionPool)
 
 			Expect(sequences).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&sequence2, &sequences[0], "SchemaOid", "Oid")
@@ -244,7 +244,7 @@ PARTITION BY LIST (gender)
 			sequence1 := backup.Relation{Schema: "public", Name: "sequence1"}
 			_ = backupCmdFlags.Set(utils.INCLUDE_RELATION, "public.sequence1")
 
-			sequences := backup.GetAllSequenceRelations(connectionPool)
+			sequences := testBackup.GetAllSequenceRelations(connectionPool)
 
 			Expect(sequences).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&sequence1, &sequences[0], "SchemaOid", "Oid")
@@ -264,6 +264,9 @@ PARTITION BY LIST (gender)
 			if connectionPool.Version.AtLeast("6") {
 				expectedSequence.StartVal = 1
 			}
+			if connectionPool.Version.AtLeast("7") {
+				expectedSequence.DataType = "bigint"
+			}
 
 			structmatcher.ExpectStructsToMatch(&expectedSequence, &resultSequenceDef)
 		})
@@ -287,7 +290,20 @@ PARTITION BY LIST (gender)
 			if connectionPool.Version.AtLeast("6") {
 				expectedSequence.StartVal = 100
 			}
+			if connectionPool.Version.AtLeast("7") {
+				expectedSequence.DataType = "bigint"
+			}
+
+			structmatcher.ExpectStructsToMatch(&expectedSequence, &resultSequenceDef)
+		})
+		It("returns sequence information for a sequence backing an identity column", func() {
+			testutils.SkipIfBefore7(connectionPool)
+			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.with_identity(a int GENERATED ALWAYS AS IDENTITY (START 10), b text)")
+			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.with_identity")
+
+			resultSequenceDef := backup.GetSequenceDefinition(connectionPool, "public.with_identity_a_seq")
 
+			expectedSequence := backup.SequenceDefinition{LastVal: 10, StartVal: 10, Increment: 1, MaxVal: math.MaxInt32, MinVal: 1, CacheVal: 1, DataType: "integer", IdentityType: "a"}
 			structmatcher.ExpectStructsToMatch(&expectedSequence, &resultSequenceDef)
 		})
 	})
@@ -300,13 +316,38 @@ PARTITION BY LIST (gender)
 			testhelper.AssertQueryRuns(connectionPool, "CREATE SEQUENCE public.my_sequence OWNED BY public.with_sequence.a;")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP SEQUENCE public.my_sequence")
 
-			sequenceOwnerTables, sequenceOwnerColumns := backup.GetSequenceColumnOwnerMap(connectionPool)
+			sequenceOwnerTables, sequenceOwnerColumns := testBackup.GetSequenceColumnOwnerMap(connectionPool)
 
 			Expect(sequenceOwnerTables).To(HaveLen(1))
 			Expect(sequenceOwnerColumns).To(HaveLen(1))
 			Expect(sequenceOwnerTables["public.my_sequence"]).To(Equal("public.with_sequence"))
 			Expect(sequenceOwnerColumns["public.my_sequence"]).To(Equal("public.with_sequence.a"))
 		})
+		It("returns the schema of the owning table for a sequence owned by a table in another schema", func() {
+			testhelper.AssertQueryRuns(connectionPool, "CREATE SCHEMA testschema")
+			defer testhelper.AssertQueryRuns(connectionPool, "DROP SCHEMA testschema")
+			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE testschema.with_sequence(a int, b char(20));")
+			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE testschema.with_sequence")
+			testhelper.AssertQueryRuns(connectionPool, "CREATE SEQUENCE public.my_sequence OWNED BY testschema.with_sequence.a;")
+			defer testhelper.AssertQueryRuns(connectionPool, "DROP SEQUENCE public.my_sequence")
+
+			sequenceOwnerTables, sequenceOwnerColumns := testBackup.GetSequenceColumnOwnerMap(connectionPool)
+
+			Expect(sequenceOwnerTables).To(HaveLen(1))
+			Expect(sequenceOwnerColumns).To(HaveLen(1))
+			Expect(sequenceOwnerTables["public.my_sequence"]).To(Equal("testschema.with_sequence"))
+			Expect(sequenceOwnerColumns["public.my_sequence"]).To(Equal("testschema.with_sequence.a"))
+		})
+		It("returns the owning column of a sequence backing an identity column", func() {
+			testutils.SkipIfBefore7(connectionPool)
+			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.with_identity(a int GENERATED BY DEFAULT AS IDENTITY, b text)")
+			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.with_identity")
+
+			sequenceOwnerTables, sequenceOwnerColumns := testBackup.GetSequenceColumnOwnerMap(connectionPool)
+
+			Expect(sequenceOwnerTables["public.with_identity_a_seq"]).To(Equal("public.with_identity"))
+			Expect(sequenceOwnerColumns["public.with_identity_a_seq"]).To(Equal("public.with_identity.a"))
+		})
 		It("does not return sequence owner columns if the owning table is not backed up", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.my_table(a int, b char(20));")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.my_table")
@@ -314,7 +355,7 @@ PARTITION BY LIST (gender)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP SEQUENCE public.my_sequence")
 
 			_ = backupCmdFlags.Set(utils.EXCLUDE_RELATION, "public.my_table")
-			sequenceOwnerTables, sequenceOwnerColumns := backup.GetSequenceColumnOwnerMap(connectionPool)
+			sequenceOwnerTables, sequenceOwnerColumns := testBackup.GetSequenceColumnOwnerMap(connectionPool)
 
 			Expect(sequenceOwnerTables).To(BeEmpty())
 			Expect(sequenceOwnerColumns).To(BeEmpty())
@@ -328,7 +369,7 @@ PARTITION BY LIST (gender)
 
 			_ = backupCmdFlags.Set(utils.INCLUDE_RELATION, "public.my_sequence")
 			_ = backupCmdFlags.Set(utils.INCLUDE_RELATION, "public.my_table")
-			sequenceOwnerTables, sequenceOwnerColumns := backup.GetSequenceColumnOwnerMap(connectionPool)
+			sequenceOwnerTables, sequenceOwnerColumns := testBackup.GetSequenceColumnOwnerMap(connectionPool)
 			Expect(sequenceOwnerTables).To(HaveLen(1))
 			Expect(sequenceOwnerColumns).To(HaveLen(1))
 		})
@@ -339,7 +380,7 @@ PARTITION BY LIST (gender)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP SEQUENCE public.my_sequence")
 
 			_ = backupCmdFlags.Set(utils.INCLUDE_RELATION, "public.my_table")
-			sequenceOwnerTables, sequenceOwnerColumns := backup.GetSequenceColumnOwnerMap(connectionPool)
+			sequenceOwnerTables, sequenceOwnerColumns := testBackup.GetSequenceColumnOwnerMap(connectionPool)
 			Expect(sequenceOwnerTables).To(HaveLen(1))
 			Expect(sequenceOwnerColumns).To(HaveLen(1))
 		})
@@ -369,7 +410,7 @@ PARTITION BY LIST (gender)
 				seqTwoDef.LogCnt = 1
 			}
 
-			results := backup.GetAllSequences(connectionPool, map[string]string{})
+			results := testBackup.GetAllSequences(connectionPool, map[string]string{})
 
 			structmatcher.ExpectStructsToMatchExcluding(&seqOneRelation, &results[0].Relation, "SchemaOid", "Oid")
 			structmatcher.ExpectStructsToMatchExcluding(&seqOneDef, &results[0].SequenceDefinition)
@@ -390,7 +431,7 @@ PARTITION BY LIST (gender)
 			testhelper.AssertQueryRuns(connectionPool, "CREATE VIEW public.simpleview AS SELECT 1")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP VIEW public.simpleview")
 
-			results, _ := backup.GetAllViews(connectionPool)
+			results, _ := testBackup.GetAllViews(connectionPool)
 
 			view := backup.View{Oid: 1, Schema: "public", Name: "simpleview", Definition: viewDef}
 
@@ -406,7 +447,7 @@ PARTITION BY LIST (gender)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP VIEW testschema.simpleview")
 			_ = backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
 
-			results, _ := backup.GetAllViews(connectionPool)
+			results, _ := testBackup.GetAllViews(connectionPool)
 
 			view := backup.View{Oid: 1, Schema: "testschema", Name: "simpleview", Definition: viewDef}
 
@@ -418,7 +459,7 @@ PARTITION BY LIST (gender)
 			testhelper.AssertQueryRuns(connectionPool, "CREATE VIEW public.simpleview WITH (security_barrier=true) AS SELECT 1")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP VIEW public.simpleview")
 
-			results, _ := backup.GetAllViews(connectionPool)
+			results, _ := testBackup.GetAllViews(connectionPool)
 
 			view := backup.View{Oid: 1, Schema: "public", Name: "simpleview", Definition: viewDef, Options: " WITH (security_barrier=true)"}
 
@@ -430,7 +471,7 @@ PARTITION BY LIST (gender)
 			testhelper.AssertQueryRuns(connectionPool, "CREATE MATERIALIZED VIEW public.simplematerialview AS SELECT 1")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP MATERIALIZED VIEW public.simplematerialview")
 
-			_, results := backup.GetAllViews(connectionPool)
+			_, results := testBackup.GetAllViews(connectionPool)
 
 			materialView := backup.MaterializedView{Oid: 1, Schema: "public", Name: "simplematerialview", Definition: viewDef}
 
@@ -442,7 +483,7 @@ PARTITION BY LIST (gender)
 			testhelper.AssertQueryRuns(connectionPool, "CREATE MATERIALIZED VIEW public.simplematerialview WITH (fillfactor=50, autovacuum_enabled=false) AS SELECT 1")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP MATERIALIZED VIEW public.simplematerialview")
 
-			_, results := backup.GetAllViews(connectionPool)
+			_, results := testBackup.GetAllViews(connectionPool)
 
 			materialView := backup.MaterializedView{Oid: 1, Schema: "public", Name: "simplematerialview", Definition: viewDef, Options: " WITH (fillfactor=50, autovacuum_enabled=false)"}
 
@@ -456,7 +497,7 @@ PARTITION BY LIST (gender)
 			testhelper.AssertQueryRuns(connectionPool, "CREATE MATERIALIZED VIEW public.simplematerialview TABLESPACE test_tablespace AS SELECT 1")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP MATERIALIZED VIEW public.simplematerialview")
 
-			_, results := backup.GetAllViews(connectionPool)
+			_, results := testBackup.GetAllViews(connectionPool)
 
 			materialView := backup.MaterializedView{Oid: 1, Schema: "public", Name: "simplematerialview", Definition: viewDef, Tablespace: "test_tablespace"}
 
@@ -19,12 +19,12 @@ var _ = Describe("backup integration create statement tests", func() {
 			schemas := []backup.Schema{{Oid: 0, Name: "test_schema"}}
 			schemaMetadata := testutils.DefaultMetadataMap("SCHEMA", true, true, true, includeSecurityLabels)
 
-			backup.PrintCreateSchemaStatements(backupfile, toc, schemas, schemaMetadata)
+			testBackup.PrintCreateSchemaStatements(backupfile, toc, schemas, schemaMetadata)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP SCHEMA test_schema")
 
-			resultSchemas := backup.GetAllUserSchemas(connectionPool)
+			resultSchemas := testBackup.GetAllUserSchemas(connectionPool)
 
 			Expect(resultSchemas).To(HaveLen(2))
 			Expect(resultSchemas[0].Name).To(Equal("public"))
@@ -36,13 +36,13 @@ var _ = Describe("backup integration create statement tests", func() {
 			schemas := []backup.Schema{{Oid: 2200, Name: "public"}}
 			schemaMetadata := testutils.DefaultMetadataMap("SCHEMA", true, true, true, includeSecurityLabels)
 
-			backup.PrintCreateSchemaStatements(backupfile, toc, schemas, schemaMetadata)
+			testBackup.PrintCreateSchemaStatements(backupfile, toc, schemas, schemaMetadata)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "ALTER SCHEMA public OWNER TO anothertestrole")
 			defer testhelper.AssertQueryRuns(connectionPool, "COMMENT ON SCHEMA public IS 'standard public schema'")
 
-			resultSchemas := backup.GetAllUserSchemas(connectionPool)
+			resultSchemas := testBackup.GetAllUserSchemas(connectionPool)
 
 			Expect(resultSchemas).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&schemas[0], &resultSchemas[0])
@@ -79,37 +79,37 @@ var _ = Describe("backup integration create statement tests", func() {
 		})
 		It("creates a unique constraint", func() {
 			constraints := []backup.Constraint{uniqueConstraint}
-			backup.PrintConstraintStatements(backupfile, toc, constraints, conMetadataMap)
+			testBackup.PrintConstraintStatements(backupfile, toc, constraints, conMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
-			resultConstraints := backup.GetConstraints(connectionPool)
+			resultConstraints := testBackup.GetConstraints(connectionPool)
 
 			Expect(resultConstraints).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&uniqueConstraint, &resultConstraints[0], "Oid")
 		})
 		It("creates a primary key constraint", func() {
 			constraints := []backup.Constraint{pkConstraint}
-			backup.PrintConstraintStatements(backupfile, toc, constraints, conMetadataMap)
+			testBackup.PrintConstraintStatements(backupfile, toc, constraints, conMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.constraints_other_table(b text)")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.constraints_other_table CASCADE")
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
-			resultConstraints := backup.GetConstraints(connectionPool)
+			resultConstraints := testBackup.GetConstraints(connectionPool)
 
 			Expect(resultConstraints).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&pkConstraint, &resultConstraints[0], "Oid")
 		})
 		It("creates a foreign key constraint", func() {
 			constraints := []backup.Constraint{fkConstraint}
-			backup.PrintConstraintStatements(backupfile, toc, constraints, conMetadataMap)
+			testBackup.PrintConstraintStatements(backupfile, toc, constraints, conMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.constraints_other_table(b text PRIMARY KEY)")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.constraints_other_table CASCADE")
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
-			resultConstraints := backup.GetConstraints(connectionPool)
+			resultConstraints := testBackup.GetConstraints(connectionPool)
 
 			Expect(resultConstraints).To(HaveLen(2))
 			structmatcher.ExpectStructsToMatchExcluding(&pkConstraint, &resultConstraints[0], "Oid")
@@ -117,24 +117,24 @@ var _ = Describe("backup integration create statement tests", func() {
 		})
 		It("creates a check constraint", func() {
 			constraints := []backup.Constraint{checkConstraint}
-			backup.PrintConstraintStatements(backupfile, toc, constraints, conMetadataMap)
+			testBackup.PrintConstraintStatements(backupfile, toc, constraints, conMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
-			resultConstraints := backup.GetConstraints(connectionPool)
+			resultConstraints := testBackup.GetConstraints(connectionPool)
 
 			Expect(resultConstraints).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&checkConstraint, &resultConstraints[0], "Oid")
 		})
 		It("creates multiple constraints on one table", func() {
 			constraints := []backup.Constraint{checkConstraint, uniqueConstraint, fkConstraint}
-			backup.PrintConstraintStatements(backupfile, toc, constraints, conMetadataMap)
+			testBackup.PrintConstraintStatements(backupfile, toc, constraints, conMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.constraints_other_table(b text PRIMARY KEY)")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.constraints_other_table CASCADE")
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
-			resultConstraints := backup.GetConstraints(connectionPool)
+			resultConstraints := testBackup.GetConstraints(connectionPool)
 
 			Expect(resultConstraints).To(HaveLen(4))
 			structmatcher.ExpectStructsToMatchExcluding(&checkConstraint, &resultConstraints[0], "Oid")
@@ -147,13 +147,13 @@ var _ = Describe("backup integration create statement tests", func() {
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP DOMAIN public.domain1")
 			domainCheckConstraint := backup.Constraint{Oid: 0, Name: "check1", ConType: "c", ConDef: "CHECK (VALUE <> 42::numeric)", OwningObject: "public.domain1", IsDomainConstraint: true, IsPartitionParent: false}
 			constraints := []backup.Constraint{domainCheckConstraint}
-			backup.PrintConstraintStatements(backupfile, toc, constraints, conMetadataMap)
+			testBackup.PrintConstraintStatements(backupfile, toc, constraints, conMetadataMap)
 
 			Expect(buffer.String()).To(Equal(""))
 		})
 		It("creates a check constraint on a parent partition table", func() {
 			constraints := []backup.Constraint{partitionCheckConstraint}
-			backup.PrintConstraintStatements(backupfile, toc, constraints, conMetadataMap)
+			testBackup.PrintConstraintStatements(backupfile, toc, constraints, conMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, `CREATE TABLE public.part (id int, year int)
 DISTRIBUTED BY (id)
@@ -163,7 +163,7 @@ PARTITION BY RANGE (year)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.part CASCADE")
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
-			resultConstraints := backup.GetConstraints(connectionPool)
+			resultConstraints := testBackup.GetConstraints(connectionPool)
 
 			Expect(resultConstraints).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&partitionCheckConstraint, &resultConstraints[0], "Oid")
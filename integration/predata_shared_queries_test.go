@@ -16,7 +16,7 @@ var _ = Describe("backup integration tests", func() {
 		It("returns user schema information", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE SCHEMA bar")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP SCHEMA bar")
-			schemas := backup.GetAllUserSchemas(connectionPool)
+			schemas := testBackup.GetAllUserSchemas(connectionPool)
 
 			schemaBar := backup.Schema{Oid: 0, Name: "bar"}
 			schemaPublic := backup.Schema{Oid: 2200, Name: "public"}
@@ -31,7 +31,7 @@ var _ = Describe("backup integration tests", func() {
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP SCHEMA bar")
 			_ = backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "bar")
 
-			schemas := backup.GetAllUserSchemas(connectionPool)
+			schemas := testBackup.GetAllUserSchemas(connectionPool)
 
 			schemaBar := backup.Schema{Oid: 0, Name: "bar"}
 
@@ -44,7 +44,7 @@ var _ = Describe("backup integration tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE SCHEMA bar")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP SCHEMA bar")
 			_ = backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "bar,public")
-			schemas := backup.GetAllUserSchemas(connectionPool)
+			schemas := testBackup.GetAllUserSchemas(connectionPool)
 
 			schemaBar := backup.Schema{Oid: 0, Name: "bar"}
 			schemaPublic := backup.Schema{Oid: 2200, Name: "public"}
@@ -90,7 +90,7 @@ var _ = Describe("backup integration tests", func() {
 				testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.no_constraints_table(a int, b text)")
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.no_constraints_table")
 
-				constraints := backup.GetConstraints(connectionPool)
+				constraints := testBackup.GetConstraints(connectionPool)
 
 				Expect(constraints).To(BeEmpty())
 			})
@@ -102,7 +102,7 @@ var _ = Describe("backup integration tests", func() {
 				testhelper.AssertQueryRuns(connectionPool, "ALTER TABLE ONLY public.constraints_table ADD CONSTRAINT uniq2 UNIQUE (a, b)")
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON CONSTRAINT uniq2 ON public.constraints_table IS 'this is a constraint comment'")
 
-				constraints := backup.GetConstraints(connectionPool)
+				constraints := testBackup.GetConstraints(connectionPool)
 
 				Expect(constraints).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchExcluding(&constraints[0], &uniqueConstraint, "Oid")
@@ -113,7 +113,7 @@ var _ = Describe("backup integration tests", func() {
 				testhelper.AssertQueryRuns(connectionPool, "ALTER TABLE ONLY public.constraints_table ADD CONSTRAINT pk1 PRIMARY KEY (b)")
 				testhelper.AssertQueryRuns(connectionPool, "COMMENT ON CONSTRAINT pk1 ON public.constraints_table IS 'this is a constraint comment'")
 
-				constraints := backup.GetConstraints(connectionPool)
+				constraints := testBackup.GetConstraints(connectionPool)
 
 				Expect(constraints).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchExcluding(&constraints[0], &pkConstraint, "Oid")
@@ -126,7 +126,7 @@ var _ = Describe("backup integration tests", func() {
 				testhelper.AssertQueryRuns(connectionPool, "ALTER TABLE ONLY public.constraints_table ADD CONSTRAINT pk1 PRIMARY KEY (b)")
 				testhelper.AssertQueryRuns(connectionPool, "ALTER TABLE ONLY public.constraints_other_table ADD CONSTRAINT fk1 FOREIGN KEY (b) REFERENCES public.constraints_table(b)")
 
-				constraints := backup.GetConstraints(connectionPool)
+				constraints := testBackup.GetConstraints(connectionPool)
 
 				Expect(constraints).To(HaveLen(2))
 				structmatcher.ExpectStructsToMatchExcluding(&constraints[0], &fkConstraint, "Oid")
@@ -137,7 +137,7 @@ var _ = Describe("backup integration tests", func() {
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.constraints_table")
 				testhelper.AssertQueryRuns(connectionPool, "ALTER TABLE ONLY public.constraints_table ADD CONSTRAINT check1 CHECK (a <> 42)")
 
-				constraints := backup.GetConstraints(connectionPool)
+				constraints := testBackup.GetConstraints(connectionPool)
 
 				Expect(constraints).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchExcluding(&constraints[0], &checkConstraint, "Oid")
@@ -152,7 +152,7 @@ PARTITION BY RANGE (date)
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.part")
 				testhelper.AssertQueryRuns(connectionPool, "ALTER TABLE public.part ADD CONSTRAINT check1 CHECK (id <> 0)")
 
-				constraints := backup.GetConstraints(connectionPool)
+				constraints := testBackup.GetConstraints(connectionPool)
 
 				Expect(constraints).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchExcluding(&constraints[0], &partitionCheckConstraint, "Oid")
@@ -162,7 +162,7 @@ PARTITION BY RANGE (date)
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP DOMAIN public.constraint_domain")
 				testhelper.AssertQueryRuns(connectionPool, "ALTER DOMAIN public.constraint_domain ADD CONSTRAINT check1 CHECK (VALUE <> 42)")
 
-				constraints := backup.GetConstraints(connectionPool)
+				constraints := testBackup.GetConstraints(connectionPool)
 
 				Expect(constraints).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchExcluding(&constraints[0], &domainConstraint, "Oid")
@@ -175,7 +175,7 @@ PARTITION BY RANGE (date)
 				testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.constraints_child_table(a int, b text, c float) INHERITS (public.constraints_table)")
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.constraints_child_table")
 
-				constraints := backup.GetConstraints(connectionPool)
+				constraints := testBackup.GetConstraints(connectionPool)
 
 				Expect(constraints).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchExcluding(&constraints[0], &checkConstraint, "Oid")
@@ -189,7 +189,7 @@ PARTITION BY RANGE (date)
 
 				testhelper.AssertQueryRuns(connectionPool, "ALTER TABLE ONLY public.constraints_table ADD CONSTRAINT check1 CHECK (a <> 42)")
 
-				constraints := backup.GetConstraints(connectionPool)
+				constraints := testBackup.GetConstraints(connectionPool)
 
 				Expect(constraints).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchExcluding(&constraints[0], &checkConstraint, "Oid")
@@ -205,7 +205,7 @@ PARTITION BY RANGE (date)
 				testhelper.AssertQueryRuns(connectionPool, "ALTER TABLE ONLY testschema.constraints_table ADD CONSTRAINT uniq2 UNIQUE (a, b)")
 				_ = backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
 
-				constraints := backup.GetConstraints(connectionPool)
+				constraints := testBackup.GetConstraints(connectionPool)
 
 				Expect(constraints).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchExcluding(&constraints[0], &constraintInSchema, "Oid")
@@ -221,12 +221,12 @@ PARTITION BY RANGE (date)
 				constraintsOid := testutils.OidFromObjectName(connectionPool, "public", "constraints_table", backup.TYPE_RELATION)
 				otherOid := testutils.OidFromObjectName(connectionPool, "public", "other_table", backup.TYPE_RELATION)
 				tables := []backup.Relation{{Oid: constraintsOid, Schema: "public", Name: "constraints_table"}}
-				constraints := backup.GetConstraints(connectionPool, tables...)
+				constraints := testBackup.GetConstraints(connectionPool, tables...)
 				Expect(constraints).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchExcluding(&constraints[0], &uniqueConstraint, "Oid")
 
 				tables = []backup.Relation{{Oid: otherOid, Schema: "public", Name: "other_table"}}
-				constraints = backup.GetConstraints(connectionPool, tables...)
+				constraints = testBackup.GetConstraints(connectionPool, tables...)
 				Expect(constraints).To(BeEmpty())
 			})
 			It("returns a constraint array without contraints on tables in the exclude set", func() {
@@ -239,13 +239,13 @@ PARTITION BY RANGE (date)
 
 				_ = backupCmdFlags.Set(utils.EXCLUDE_RELATION, "public.other_table")
 				defer backupCmdFlags.Set(utils.EXCLUDE_RELATION, "")
-				constraints := backup.GetConstraints(connectionPool)
+				constraints := testBackup.GetConstraints(connectionPool)
 				Expect(constraints).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchExcluding(&constraints[0], &uniqueConstraint, "Oid")
 
 				_ = backupCmdFlags.Set(utils.EXCLUDE_RELATION, "public.constraints_table")
-				backup.SetFilterRelationClause("")
-				constraints = backup.GetConstraints(connectionPool)
+				testBackup.SetFilterRelationClause("")
+				constraints = testBackup.GetConstraints(connectionPool)
 				Expect(constraints).To(BeEmpty())
 			})
 		})
@@ -263,7 +263,7 @@ PARTITION BY RANGE (date)
 				testhelper.AssertQueryRuns(connectionPool, "ALTER TABLE ONLY public.constraints_other_table ADD CONSTRAINT fk1 FOREIGN KEY (b) REFERENCES public.constraints_table(b)")
 				testhelper.AssertQueryRuns(connectionPool, "ALTER TABLE ONLY public.constraints_table ADD CONSTRAINT check1 CHECK (a <> 42)")
 
-				constraints := backup.GetConstraints(connectionPool)
+				constraints := testBackup.GetConstraints(connectionPool)
 
 				Expect(constraints).To(HaveLen(4))
 				structmatcher.ExpectStructsToMatchExcluding(&constraints[0], &checkConstraint, "Oid")
@@ -286,7 +286,7 @@ PARTITION BY RANGE (date)
 				testhelper.AssertQueryRuns(connectionPool, "ALTER TABLE ONLY public.constraints_other_table ADD CONSTRAINT fk1 FOREIGN KEY (a,b) REFERENCES public.constraints_table(a,b)")
 				testhelper.AssertQueryRuns(connectionPool, "ALTER TABLE ONLY public.constraints_table ADD CONSTRAINT check1 CHECK (a <> 42)")
 
-				constraints := backup.GetConstraints(connectionPool)
+				constraints := testBackup.GetConstraints(connectionPool)
 
 				Expect(constraints).To(HaveLen(4))
 				structmatcher.ExpectStructsToMatchExcluding(&constraints[0], &checkConstraint, "Oid")
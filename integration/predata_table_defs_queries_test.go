@@ -43,7 +43,7 @@ PARTITION BY RANGE (year)
 			leaf31 := testutils.OidFromObjectName(connectionPool, "public", "summer_sales_1_prt_3_2_prt_2", backup.TYPE_RELATION)
 			leaf32 := testutils.OidFromObjectName(connectionPool, "public", "summer_sales_1_prt_3_2_prt_3", backup.TYPE_RELATION)
 			leaf33 := testutils.OidFromObjectName(connectionPool, "public", "summer_sales_1_prt_3_2_prt_other_months", backup.TYPE_RELATION)
-			partTableMap := backup.GetPartitionTableMap(connectionPool)
+			partTableMap := testBackup.GetPartitionTableMap(connectionPool)
 
 			Expect(partTableMap).To(HaveLen(13))
 			structmatcher.ExpectStructsToMatch(partTableMap[parent], &backup.PartitionLevelInfo{Oid: parent, Level: "p", RootName: ""})
@@ -74,7 +74,7 @@ PARTITION BY RANGE (year)
 				testhelper.AssertQueryRuns(connectionPool, "GRANT SELECT (c, d) ON TABLE public.atttable TO testrole")
 				privileges = sql.NullString{String: "testrole=r/testrole", Valid: true}
 			}
-			tableAtts := backup.GetColumnDefinitions(connectionPool)[oid]
+			tableAtts := testBackup.GetColumnDefinitions(connectionPool)[oid]
 
 			columnA := backup.ColumnDefinition{Oid: 0, Num: 1, Name: "a", NotNull: false, HasDefault: false, Type: "double precision", Encoding: "", StatTarget: -1, StorageType: "", DefaultVal: "", Comment: "att comment"}
 			columnC := backup.ColumnDefinition{Oid: 0, Num: 3, Name: "c", NotNull: true, HasDefault: false, Type: "text", Encoding: "", StatTarget: -1, StorageType: "", DefaultVal: "", Comment: "", Privileges: privileges}
@@ -92,7 +92,7 @@ PARTITION BY RANGE (year)
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.co_atttable(a float, b text ENCODING(blocksize=65536)) WITH (appendonly=true, orientation=column)")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.co_atttable")
 			oid := testutils.OidFromObjectName(connectionPool, "public", "co_atttable", backup.TYPE_RELATION)
-			tableAtts := backup.GetColumnDefinitions(connectionPool)[oid]
+			tableAtts := testBackup.GetColumnDefinitions(connectionPool)[oid]
 
 			columnA := backup.ColumnDefinition{Oid: 0, Num: 1, Name: "a", NotNull: false, HasDefault: false, Type: "double precision", Encoding: "compresstype=none,blocksize=32768,compresslevel=0", StatTarget: -1, StorageType: "", DefaultVal: "", Comment: ""}
 			columnB := backup.ColumnDefinition{Oid: 0, Num: 2, Name: "b", NotNull: false, HasDefault: false, Type: "text", Encoding: "blocksize=65536,compresstype=none,compresslevel=0", StatTarget: -1, StorageType: "", DefaultVal: "", Comment: ""}
@@ -107,7 +107,7 @@ PARTITION BY RANGE (year)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.nocol_atttable")
 			oid := testutils.OidFromObjectName(connectionPool, "public", "nocol_atttable", backup.TYPE_RELATION)
 
-			tableAtts := backup.GetColumnDefinitions(connectionPool)[oid]
+			tableAtts := testBackup.GetColumnDefinitions(connectionPool)[oid]
 
 			Expect(tableAtts).To(BeEmpty())
 		})
@@ -120,7 +120,7 @@ PARTITION BY RANGE (year)
 			testhelper.AssertQueryRuns(connectionPool, "ALTER TABLE ONLY public.atttable ALTER COLUMN i SET (n_distinct=1);")
 			testhelper.AssertQueryRuns(connectionPool, "SECURITY LABEL FOR dummy ON COLUMN public.atttable.i IS 'unclassified';")
 			oid := testutils.OidFromObjectName(connectionPool, "public", "atttable", backup.TYPE_RELATION)
-			tableAtts := backup.GetColumnDefinitions(connectionPool)[oid]
+			tableAtts := testBackup.GetColumnDefinitions(connectionPool)[oid]
 
 			columnA := backup.ColumnDefinition{Oid: 0, Num: 1, Name: "i", NotNull: false, HasDefault: false, Type: "character(8)", Encoding: "", StatTarget: -1, StorageType: "", DefaultVal: "", Comment: "", Options: "n_distinct=1", Collation: "public.some_coll", SecurityLabelProvider: "dummy", SecurityLabel: "unclassified"}
 
@@ -140,7 +140,7 @@ PARTITION BY RANGE (year)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP FOREIGN TABLE public.ft1")
 
 			oid := testutils.OidFromObjectName(connectionPool, "public", "ft1", backup.TYPE_RELATION)
-			tableAtts := backup.GetColumnDefinitions(connectionPool)[oid]
+			tableAtts := testBackup.GetColumnDefinitions(connectionPool)[oid]
 
 			Expect(tableAtts).To(HaveLen(1))
 			column1 := backup.ColumnDefinition{Oid: 0, Num: 1, Name: "c1", NotNull: true, HasDefault: false, Type: "integer", StatTarget: -1, FdwOptions: "param1 'val1', param2 'val2'"}
@@ -160,7 +160,7 @@ CREATE TABLE public.test_tsvector (
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP table public.test_tsvector cascade")
 
 			oid := testutils.OidFromObjectName(connectionPool, "public", "test_tsvector", backup.TYPE_RELATION)
-			tableAtts := backup.GetColumnDefinitions(connectionPool)[oid]
+			tableAtts := testBackup.GetColumnDefinitions(connectionPool)[oid]
 
 			Expect(tableAtts).To(HaveLen(3))
 		})
@@ -175,7 +175,7 @@ CREATE TABLE public.test_tsvector (
 			defer testhelper.AssertQueryRuns(connectionPool, `DROP TABLE public.foo`)
 
 			oid := testutils.OidFromObjectName(connectionPool, "public", "foo", backup.TYPE_RELATION)
-			tableAtts := backup.GetColumnDefinitions(connectionPool)[oid]
+			tableAtts := testBackup.GetColumnDefinitions(connectionPool)[oid]
 
 			Expect(tableAtts).To(HaveLen(1))
 			Expect(tableAtts[0].Collation).To(Equal("myschema.mycoll"))
@@ -306,7 +306,7 @@ CREATE TABLE public.test_tsvector (
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.simple_table")
 			oid := testutils.OidFromObjectName(connectionPool, "public", "simple_table", backup.TYPE_RELATION)
 
-			result, _ := backup.GetPartitionDetails(connectionPool)
+			result, _ := testBackup.GetPartitionDetails(connectionPool)
 
 			Expect(result[oid]).To(Equal(""))
 		})
@@ -322,7 +322,7 @@ PARTITION BY LIST (gender)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.part_table")
 			oid := testutils.OidFromObjectName(connectionPool, "public", "part_table", backup.TYPE_RELATION)
 
-			result, _ := backup.GetPartitionDetails(connectionPool)
+			result, _ := testBackup.GetPartitionDetails(connectionPool)
 
 			// The spacing is very specific here and is output from the postgres function
 			expectedResult := fmt.Sprintf(`PARTITION BY LIST(gender) `+`
@@ -356,7 +356,7 @@ PARTITION BY LIST (gender)
 
 			_ = backupCmdFlags.Set(utils.INCLUDE_RELATION, "public.part_table")
 
-			results, _ := backup.GetPartitionDetails(connectionPool)
+			results, _ := testBackup.GetPartitionDetails(connectionPool)
 			Expect(results).To(HaveLen(1))
 			result := results[oid]
 
@@ -393,7 +393,7 @@ PARTITION BY LIST (gender)
 
 			_ = backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
 
-			results, _ := backup.GetPartitionDetails(connectionPool)
+			results, _ := testBackup.GetPartitionDetails(connectionPool)
 			Expect(results).To(HaveLen(1))
 			result := results[oid]
 
@@ -413,7 +413,7 @@ PARTITION BY LIST (gender)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.simple_table")
 			oid := testutils.OidFromObjectName(connectionPool, "public", "simple_table", backup.TYPE_RELATION)
 
-			_, result := backup.GetPartitionDetails(connectionPool)
+			_, result := testBackup.GetPartitionDetails(connectionPool)
 
 			Expect(result[oid]).To(Equal(""))
 		})
@@ -433,7 +433,7 @@ PARTITION BY LIST (gender)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.part_table")
 			oid := testutils.OidFromObjectName(connectionPool, "public", "part_table", backup.TYPE_RELATION)
 
-			_, result := backup.GetPartitionDetails(connectionPool)
+			_, result := testBackup.GetPartitionDetails(connectionPool)
 
 			/*
 			 * The spacing is very specific here and is output from the postgres function
@@ -495,7 +495,7 @@ SET SUBPARTITION TEMPLATE
 
 			_ = backupCmdFlags.Set(utils.INCLUDE_RELATION, "public.part_table")
 
-			_, results := backup.GetPartitionDetails(connectionPool)
+			_, results := testBackup.GetPartitionDetails(connectionPool)
 			Expect(results).To(HaveLen(1))
 			result := results[oid]
 
@@ -560,7 +560,7 @@ SET SUBPARTITION TEMPLATE
 
 			_ = backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
 
-			_, results := backup.GetPartitionDetails(connectionPool)
+			_, results := testBackup.GetPartitionDetails(connectionPool)
 			Expect(results).To(HaveLen(1))
 			result := results[oid]
 
@@ -677,7 +677,7 @@ SET SUBPARTITION TEMPLATE
 	c3 date
 ) SERVER sc OPTIONS (delimiter ',', quote '"');`)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP FOREIGN TABLE public.ft1")
-			result := backup.GetForeignTableRelations(connectionPool)
+			result := testBackup.GetForeignTableRelations(connectionPool)
 			Expect(result).To(HaveLen(1))
 			Expect(result[0].Schema).To(Equal("public"))
 			Expect(result[0].Name).To(Equal("ft1"))
@@ -686,7 +686,7 @@ SET SUBPARTITION TEMPLATE
 			testutils.SkipIfBefore6(connectionPool)
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.some_table (i int, j int)")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.some_table")
-			result := backup.GetForeignTableRelations(connectionPool)
+			result := testBackup.GetForeignTableRelations(connectionPool)
 			Expect(result).To(HaveLen(0))
 		})
 	})
@@ -696,7 +696,7 @@ SET SUBPARTITION TEMPLATE
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.simple_table")
 			oid := testutils.OidFromObjectName(connectionPool, "public", "simple_table", backup.TYPE_RELATION)
 
-			_, result := backup.GetTableStorage(connectionPool)
+			_, result := testBackup.GetTableStorage(connectionPool)
 
 			Expect(result[oid]).To(Equal(""))
 		})
@@ -705,7 +705,7 @@ SET SUBPARTITION TEMPLATE
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.ao_table")
 			oid := testutils.OidFromObjectName(connectionPool, "public", "ao_table", backup.TYPE_RELATION)
 
-			_, result := backup.GetTableStorage(connectionPool)
+			_, result := testBackup.GetTableStorage(connectionPool)
 
 			Expect(result[oid]).To(Equal("appendonly=true"))
 		})
@@ -727,7 +727,7 @@ SET SUBPARTITION TEMPLATE
 			child.Oid = testutils.OidFromObjectName(connectionPool, "public", "child", backup.TYPE_RELATION)
 			tables := []backup.Relation{child, parent}
 
-			inheritanceMap := backup.GetTableInheritance(connectionPool, tables)
+			inheritanceMap := testBackup.GetTableInheritance(connectionPool, tables)
 
 			Expect(inheritanceMap).To(HaveLen(1))
 			Expect(inheritanceMap[child.Oid]).To(ConsistOf("public.parent"))
@@ -744,7 +744,7 @@ SET SUBPARTITION TEMPLATE
 			childTwo.Oid = testutils.OidFromObjectName(connectionPool, "public", "child_two", backup.TYPE_RELATION)
 			tables := []backup.Relation{parent, childOne, childTwo}
 
-			inheritanceMap := backup.GetTableInheritance(connectionPool, tables)
+			inheritanceMap := testBackup.GetTableInheritance(connectionPool, tables)
 
 			Expect(inheritanceMap).To(HaveLen(2))
 			Expect(inheritanceMap[childOne.Oid]).To(ConsistOf("public.parent"))
@@ -761,7 +761,7 @@ SET SUBPARTITION TEMPLATE
 			child.Oid = testutils.OidFromObjectName(connectionPool, "public", "child", backup.TYPE_RELATION)
 			tables := []backup.Relation{parentOne, parentTwo, child}
 
-			inheritanceMap := backup.GetTableInheritance(connectionPool, tables)
+			inheritanceMap := testBackup.GetTableInheritance(connectionPool, tables)
 
 			Expect(inheritanceMap).To(HaveLen(1))
 			Expect(inheritanceMap[child.Oid]).To(Equal([]string{"public.parent_one", "public.parent_two"}))
@@ -771,7 +771,7 @@ SET SUBPARTITION TEMPLATE
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.parent")
 			tables := make([]backup.Relation, 0)
 
-			inheritanceMap := backup.GetTableInheritance(connectionPool, tables)
+			inheritanceMap := testBackup.GetTableInheritance(connectionPool, tables)
 
 			Expect(inheritanceMap).To(BeEmpty())
 		})
@@ -782,7 +782,7 @@ SET SUBPARTITION TEMPLATE
 			_ = backupCmdFlags.Set(utils.INCLUDE_RELATION, "public.parent")
 			tables := make([]backup.Relation, 0)
 
-			inheritanceMap := backup.GetTableInheritance(connectionPool, tables)
+			inheritanceMap := testBackup.GetTableInheritance(connectionPool, tables)
 
 			Expect(inheritanceMap).To(BeEmpty())
 		})
@@ -798,7 +798,7 @@ SET SUBPARTITION TEMPLATE
 			childOne.Oid = testutils.OidFromObjectName(connectionPool, "public", "child_one", backup.TYPE_RELATION)
 			tables := []backup.Relation{childOne}
 
-			inheritanceMap := backup.GetTableInheritance(connectionPool, tables)
+			inheritanceMap := testBackup.GetTableInheritance(connectionPool, tables)
 
 			Expect(inheritanceMap).To(HaveLen(1))
 			Expect(inheritanceMap[childOne.Oid]).To(ConsistOf("public.parent"))
@@ -822,7 +822,7 @@ SET SUBPARTITION TEMPLATE
 			partition.Oid = testutils.OidFromObjectName(connectionPool, "public", "partition_table_ext_part_", backup.TYPE_RELATION)
 			tables := []backup.Relation{partition}
 
-			inheritanceMap := backup.GetTableInheritance(connectionPool, tables)
+			inheritanceMap := testBackup.GetTableInheritance(connectionPool, tables)
 
 			Expect(inheritanceMap).To(Not(HaveKey(partition.Oid)))
 		})
@@ -858,4 +858,38 @@ SET SUBPARTITION TEMPLATE
 			Expect(result[oid]).To(Equal("n"))
 		})
 	})
+	Describe("GetTableDataSizes", func() {
+		It("returns a larger size for a table with more data", func() {
+			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.small_table (i int) DISTRIBUTED BY (i)")
+			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.small_table")
+			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.large_table (i int) DISTRIBUTED BY (i)")
+			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.large_table")
+			testhelper.AssertQueryRuns(connectionPool, "INSERT INTO public.large_table SELECT generate_series(1, 10000)")
+			smallOid := testutils.OidFromObjectName(connectionPool, "public", "small_table", backup.TYPE_RELATION)
+			largeOid := testutils.OidFromObjectName(connectionPool, "public", "large_table", backup.TYPE_RELATION)
+			tables := []backup.Table{
+				{Relation: backup.Relation{Oid: smallOid, Schema: "public", Name: "small_table"}},
+				{Relation: backup.Relation{Oid: largeOid, Schema: "public", Name: "large_table"}},
+			}
+
+			result := testBackup.GetTableDataSizes(connectionPool, tables)
+
+			Expect(result).To(HaveLen(2))
+			Expect(result[smallOid]).To(Equal(int64(0)))
+			Expect(result[largeOid]).To(BeNumerically(">", 0))
+		})
+		It("includes the size of all partitions in the size of a partition root", func() {
+			testhelper.AssertQueryRuns(connectionPool, `CREATE TABLE public.part_table (id int, year int)
+DISTRIBUTED BY (id)
+PARTITION BY RANGE (year) (START (2015) END (2017) EVERY (1))`)
+			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.part_table")
+			testhelper.AssertQueryRuns(connectionPool, "INSERT INTO public.part_table SELECT i, 2015 + i % 2 FROM generate_series(1, 10000) i")
+			rootOid := testutils.OidFromObjectName(connectionPool, "public", "part_table", backup.TYPE_RELATION)
+			tables := []backup.Table{{Relation: backup.Relation{Oid: rootOid, Schema: "public", Name: "part_table"}}}
+
+			result := testBackup.GetTableDataSizes(connectionPool, tables)
+
+			Expect(result[rootOid]).To(BeNumerically(">", 0))
+		})
+	})
 })
@@ -18,12 +18,12 @@ var _ = Describe("backup integration create statement tests", func() {
 	Describe("PrintCreateTextSearchParserStatements", func() {
 		parser := backup.TextSearchParser{Oid: 0, Schema: "public", Name: "testparser", StartFunc: "prsd_start", TokenFunc: "prsd_nexttoken", EndFunc: "prsd_end", LexTypesFunc: "prsd_lextype", HeadlineFunc: "prsd_headline"}
 		It("creates a basic text search parser", func() {
-			backup.PrintCreateTextSearchParserStatement(backupfile, toc, parser, backup.ObjectMetadata{})
+			testBackup.PrintCreateTextSearchParserStatement(backupfile, toc, parser, backup.ObjectMetadata{})
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TEXT SEARCH PARSER public.testparser")
 
-			resultParsers := backup.GetTextSearchParsers(connectionPool)
+			resultParsers := testBackup.GetTextSearchParsers(connectionPool)
 
 			Expect(resultParsers).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&parser, &resultParsers[0], "Oid")
@@ -31,13 +31,13 @@ var _ = Describe("backup integration create statement tests", func() {
 		It("creates a basic text search parser with a comment", func() {
 			parserMetadata := testutils.DefaultMetadata("TEXT SEARCH PARSER", false, false, true, false)
 
-			backup.PrintCreateTextSearchParserStatement(backupfile, toc, parser, parserMetadata)
+			testBackup.PrintCreateTextSearchParserStatement(backupfile, toc, parser, parserMetadata)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TEXT SEARCH PARSER public.testparser")
 
-			resultParsers := backup.GetTextSearchParsers(connectionPool)
-			resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, backup.TYPE_TSPARSER)
+			resultParsers := testBackup.GetTextSearchParsers(connectionPool)
+			resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TSPARSER)
 
 			Expect(resultParsers).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&parser, &resultParsers[0], "Oid")
@@ -48,12 +48,12 @@ var _ = Describe("backup integration create statement tests", func() {
 	Describe("PrintCreateTextSearchTemplateStatement", func() {
 		template := backup.TextSearchTemplate{Oid: 1, Schema: "public", Name: "testtemplate", InitFunc: "dsimple_init", LexizeFunc: "dsimple_lexize"}
 		It("creates a basic text search template", func() {
-			backup.PrintCreateTextSearchTemplateStatement(backupfile, toc, template, backup.ObjectMetadata{})
+			testBackup.PrintCreateTextSearchTemplateStatement(backupfile, toc, template, backup.ObjectMetadata{})
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TEXT SEARCH TEMPLATE public.testtemplate")
 
-			resultTemplates := backup.GetTextSearchTemplates(connectionPool)
+			resultTemplates := testBackup.GetTextSearchTemplates(connectionPool)
 
 			Expect(resultTemplates).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&template, &resultTemplates[0], "Oid")
@@ -61,13 +61,13 @@ var _ = Describe("backup integration create statement tests", func() {
 		It("creates a basic text search template with a comment", func() {
 			templateMetadata := testutils.DefaultMetadata("TEXT SEARCH TEMPLATE", false, false, true, false)
 
-			backup.PrintCreateTextSearchTemplateStatement(backupfile, toc, template, templateMetadata)
+			testBackup.PrintCreateTextSearchTemplateStatement(backupfile, toc, template, templateMetadata)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TEXT SEARCH TEMPLATE public.testtemplate")
 
-			resultTemplates := backup.GetTextSearchTemplates(connectionPool)
-			resultMetadataMap := backup.GetCommentsForObjectType(connectionPool, backup.TYPE_TSTEMPLATE)
+			resultTemplates := testBackup.GetTextSearchTemplates(connectionPool)
+			resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TSTEMPLATE)
 
 			Expect(resultTemplates).To(HaveLen(1))
 			uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "public", "testtemplate", backup.TYPE_TSTEMPLATE)
@@ -80,12 +80,12 @@ var _ = Describe("backup integration create statement tests", func() {
 		dictionary := backup.TextSearchDictionary{Oid: 1, Schema: "public", Name: "testdictionary", Template: "pg_catalog.snowball", InitOption: "language = 'russian', stopwords = 'russian'"}
 		It("creates a basic text search dictionary", func() {
 
-			backup.PrintCreateTextSearchDictionaryStatement(backupfile, toc, dictionary, backup.ObjectMetadata{})
+			testBackup.PrintCreateTextSearchDictionaryStatement(backupfile, toc, dictionary, backup.ObjectMetadata{})
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TEXT SEARCH DICTIONARY public.testdictionary")
 
-			resultDictionaries := backup.GetTextSearchDictionaries(connectionPool)
+			resultDictionaries := testBackup.GetTextSearchDictionaries(connectionPool)
 
 			Expect(resultDictionaries).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&dictionary, &resultDictionaries[0], "Oid")
@@ -93,13 +93,13 @@ var _ = Describe("backup integration create statement tests", func() {
 		It("creates a basic text search dictionary with a comment and owner", func() {
 			dictionaryMetadata := testutils.DefaultMetadata("TEXT SEARCH DICTIONARY", false, true, true, false)
 
-			backup.PrintCreateTextSearchDictionaryStatement(backupfile, toc, dictionary, dictionaryMetadata)
+			testBackup.PrintCreateTextSearchDictionaryStatement(backupfile, toc, dictionary, dictionaryMetadata)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TEXT SEARCH DICTIONARY public.testdictionary")
 
-			resultDictionaries := backup.GetTextSearchDictionaries(connectionPool)
-			resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_TSDICTIONARY)
+			resultDictionaries := testBackup.GetTextSearchDictionaries(connectionPool)
+			resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TSDICTIONARY)
 
 			Expect(resultDictionaries).To(HaveLen(1))
 			uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "public", "testdictionary", backup.TYPE_TSDICTIONARY)
@@ -111,12 +111,12 @@ var _ = Describe("backup integration create statement tests", func() {
 	Describe("PrintCreateTextSearchConfigurationStatement", func() {
 		configuration := backup.TextSearchConfiguration{Oid: 1, Schema: "public", Name: "testconfiguration", Parser: `pg_catalog."default"`, TokenToDicts: map[string][]string{}}
 		It("creates a basic text search configuration", func() {
-			backup.PrintCreateTextSearchConfigurationStatement(backupfile, toc, configuration, backup.ObjectMetadata{})
+			testBackup.PrintCreateTextSearchConfigurationStatement(backupfile, toc, configuration, backup.ObjectMetadata{})
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TEXT SEARCH CONFIGURATION public.testconfiguration")
 
-			resultConfigurations := backup.GetTextSearchConfigurations(connectionPool)
+			resultConfigurations := testBackup.GetTextSearchConfigurations(connectionPool)
 
 			Expect(resultConfigurations).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&configuration, &resultConfigurations[0], "Oid")
@@ -124,13 +124,13 @@ var _ = Describe("backup integration create statement tests", func() {
 		It("creates a basic text search configuration with a comment and owner", func() {
 			configurationMetadata := testutils.DefaultMetadata("TEXT SEARCH CONFIGURATION", false, true, true, false)
 
-			backup.PrintCreateTextSearchConfigurationStatement(backupfile, toc, configuration, configurationMetadata)
+			testBackup.PrintCreateTextSearchConfigurationStatement(backupfile, toc, configuration, configurationMetadata)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TEXT SEARCH CONFIGURATION public.testconfiguration")
 
-			resultConfigurations := backup.GetTextSearchConfigurations(connectionPool)
-			resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_TSCONFIGURATION)
+			resultConfigurations := testBackup.GetTextSearchConfigurations(connectionPool)
+			resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_TSCONFIGURATION)
 
 			Expect(resultConfigurations).To(HaveLen(1))
 			uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "public", "testconfiguration", backup.TYPE_TSCONFIGURATION)
@@ -19,7 +19,7 @@ var _ = Describe("backup integration tests", func() {
 		It("returns a text search parser without a headline", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TEXT SEARCH PARSER public.testparser(START = prsd_start, GETTOKEN = prsd_nexttoken, END = prsd_end, LEXTYPES = prsd_lextype);")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TEXT SEARCH PARSER public.testparser")
-			parsers := backup.GetTextSearchParsers(connectionPool)
+			parsers := testBackup.GetTextSearchParsers(connectionPool)
 
 			expectedParser := backup.TextSearchParser{Oid: 1, Schema: "public", Name: "testparser", StartFunc: "prsd_start", TokenFunc: "prsd_nexttoken", EndFunc: "prsd_end", LexTypesFunc: "prsd_lextype", HeadlineFunc: ""}
 
@@ -29,7 +29,7 @@ var _ = Describe("backup integration tests", func() {
 		It("returns a text search parser with a headline", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TEXT SEARCH PARSER public.testparser(START = prsd_start, GETTOKEN = prsd_nexttoken, END = prsd_end, LEXTYPES = prsd_lextype, HEADLINE = prsd_headline);")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TEXT SEARCH PARSER public.testparser")
-			parsers := backup.GetTextSearchParsers(connectionPool)
+			parsers := testBackup.GetTextSearchParsers(connectionPool)
 
 			expectedParser := backup.TextSearchParser{Oid: 1, Schema: "public", Name: "testparser", StartFunc: "prsd_start", TokenFunc: "prsd_nexttoken", EndFunc: "prsd_end", LexTypesFunc: "prsd_lextype", HeadlineFunc: "prsd_headline"}
 
@@ -45,7 +45,7 @@ var _ = Describe("backup integration tests", func() {
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TEXT SEARCH PARSER testschema.testparser")
 			backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
 
-			parsers := backup.GetTextSearchParsers(connectionPool)
+			parsers := testBackup.GetTextSearchParsers(connectionPool)
 
 			expectedParser := backup.TextSearchParser{Oid: 1, Schema: "testschema", Name: "testparser", StartFunc: "prsd_start", TokenFunc: "prsd_nexttoken", EndFunc: "prsd_end", LexTypesFunc: "prsd_lextype", HeadlineFunc: ""}
 
@@ -60,7 +60,7 @@ var _ = Describe("backup integration tests", func() {
 		It("returns a text search template without an init function", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TEXT SEARCH TEMPLATE public.testtemplate(LEXIZE = dsimple_lexize);")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TEXT SEARCH TEMPLATE public.testtemplate")
-			templates := backup.GetTextSearchTemplates(connectionPool)
+			templates := testBackup.GetTextSearchTemplates(connectionPool)
 
 			expectedTemplate := backup.TextSearchTemplate{Oid: 1, Schema: "public", Name: "testtemplate", InitFunc: "", LexizeFunc: "dsimple_lexize"}
 
@@ -70,7 +70,7 @@ var _ = Describe("backup integration tests", func() {
 		It("returns a text search template with an init function", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TEXT SEARCH TEMPLATE public.testtemplate(INIT = dsimple_init, LEXIZE = dsimple_lexize);")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TEXT SEARCH TEMPLATE public.testtemplate")
-			templates := backup.GetTextSearchTemplates(connectionPool)
+			templates := testBackup.GetTextSearchTemplates(connectionPool)
 
 			expectedTemplate := backup.TextSearchTemplate{Oid: 1, Schema: "public", Name: "testtemplate", InitFunc: "dsimple_init", LexizeFunc: "dsimple_lexize"}
 
@@ -86,7 +86,7 @@ var _ = Describe("backup integration tests", func() {
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TEXT SEARCH TEMPLATE testschema.testtemplate")
 
 			backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
-			templates := backup.GetTextSearchTemplates(connectionPool)
+			templates := testBackup.GetTextSearchTemplates(connectionPool)
 
 			expectedTemplate := backup.TextSearchTemplate{Oid: 1, Schema: "testschema", Name: "testtemplate", InitFunc: "", LexizeFunc: "dsimple_lexize"}
 
@@ -101,7 +101,7 @@ var _ = Describe("backup integration tests", func() {
 		It("returns a text search dictionary with init options", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TEXT SEARCH DICTIONARY public.testdictionary(TEMPLATE = snowball, LANGUAGE = 'russian', STOPWORDS = 'russian');")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TEXT SEARCH DICTIONARY public.testdictionary")
-			dictionaries := backup.GetTextSearchDictionaries(connectionPool)
+			dictionaries := testBackup.GetTextSearchDictionaries(connectionPool)
 
 			expectedDictionary := backup.TextSearchDictionary{Oid: 1, Schema: "public", Name: "testdictionary", Template: "pg_catalog.snowball", InitOption: "language = 'russian', stopwords = 'russian'"}
 			Expect(dictionaries).To(HaveLen(1))
@@ -110,7 +110,7 @@ var _ = Describe("backup integration tests", func() {
 		It("returns a text search dictionary without init options", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TEXT SEARCH DICTIONARY public.testdictionary (TEMPLATE = 'simple');")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TEXT SEARCH DICTIONARY public.testdictionary")
-			dictionaries := backup.GetTextSearchDictionaries(connectionPool)
+			dictionaries := testBackup.GetTextSearchDictionaries(connectionPool)
 
 			expectedDictionary := backup.TextSearchDictionary{Oid: 1, Schema: "public", Name: "testdictionary", Template: "pg_catalog.simple", InitOption: ""}
 			Expect(dictionaries).To(HaveLen(1))
@@ -125,7 +125,7 @@ var _ = Describe("backup integration tests", func() {
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TEXT SEARCH DICTIONARY testschema.testdictionary")
 
 			backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
-			dictionaries := backup.GetTextSearchDictionaries(connectionPool)
+			dictionaries := testBackup.GetTextSearchDictionaries(connectionPool)
 
 			expectedDictionary := backup.TextSearchDictionary{Oid: 1, Schema: "testschema", Name: "testdictionary", Template: "pg_catalog.simple", InitOption: ""}
 			Expect(dictionaries).To(HaveLen(1))
@@ -139,7 +139,7 @@ var _ = Describe("backup integration tests", func() {
 		It("returns a text search configuration without an init function", func() {
 			testhelper.AssertQueryRuns(connectionPool, `CREATE TEXT SEARCH CONFIGURATION public.testconfiguration (PARSER = pg_catalog."default");`)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TEXT SEARCH CONFIGURATION public.testconfiguration")
-			configurations := backup.GetTextSearchConfigurations(connectionPool)
+			configurations := testBackup.GetTextSearchConfigurations(connectionPool)
 
 			expectedConfiguration := backup.TextSearchConfiguration{Oid: 1, Schema: "public", Name: "testconfiguration", Parser: `pg_catalog."default"`, TokenToDicts: map[string][]string{}}
 
@@ -149,7 +149,7 @@ var _ = Describe("backup integration tests", func() {
 		It("returns a text search configuration with an init function", func() {
 			testhelper.AssertQueryRuns(connectionPool, `CREATE TEXT SEARCH CONFIGURATION public.testconfiguration ( PARSER = pg_catalog."default");`)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TEXT SEARCH CONFIGURATION public.testconfiguration")
-			configurations := backup.GetTextSearchConfigurations(connectionPool)
+			configurations := testBackup.GetTextSearchConfigurations(connectionPool)
 
 			expectedConfiguration := backup.TextSearchConfiguration{Oid: 1, Schema: "public", Name: "testconfiguration", Parser: `pg_catalog."default"`, TokenToDicts: map[string][]string{}}
 
@@ -163,7 +163,7 @@ var _ = Describe("backup integration tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, "ALTER TEXT SEARCH CONFIGURATION public.testconfiguration ADD MAPPING FOR uint WITH simple;")
 			testhelper.AssertQueryRuns(connectionPool, "ALTER TEXT SEARCH CONFIGURATION public.testconfiguration ADD MAPPING FOR asciiword WITH danish_stem;")
 
-			configurations := backup.GetTextSearchConfigurations(connectionPool)
+			configurations := testBackup.GetTextSearchConfigurations(connectionPool)
 
 			expectedConfiguration := backup.TextSearchConfiguration{Oid: 1, Schema: "public", Name: "testconfiguration", Parser: `pg_catalog."default"`, TokenToDicts: map[string][]string{}}
 			expectedConfiguration.TokenToDicts = map[string][]string{"uint": {"simple"}, "asciiword": {"danish_stem"}}
@@ -180,7 +180,7 @@ var _ = Describe("backup integration tests", func() {
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TEXT SEARCH CONFIGURATION testschema.testconfiguration")
 
 			backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
-			configurations := backup.GetTextSearchConfigurations(connectionPool)
+			configurations := testBackup.GetTextSearchConfigurations(connectionPool)
 
 			expectedConfiguration := backup.TextSearchConfiguration{Oid: 1, Schema: "testschema", Name: "testconfiguration", Parser: `pg_catalog."default"`, TokenToDicts: map[string][]string{}}
 
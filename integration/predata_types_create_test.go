@@ -51,7 +51,7 @@ var _ = Describe("backup integration create statement tests", func() {
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP TYPE public.base_type")
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP TYPE public.textrange")
 
-				shells := backup.GetShellTypes(connectionPool)
+				shells := testBackup.GetShellTypes(connectionPool)
 				Expect(shells).To(HaveLen(3))
 				Expect(shells[0].Name).To(Equal("base_type"))
 				Expect(shells[1].Name).To(Equal("shell_type"))
@@ -68,12 +68,12 @@ var _ = Describe("backup integration create statement tests", func() {
 				}
 			})
 			It("creates composite types", func() {
-				backup.PrintCreateCompositeTypeStatement(backupfile, toc, compositeType, emptyMetadata)
+				testBackup.PrintCreateCompositeTypeStatement(backupfile, toc, compositeType, emptyMetadata)
 
 				testhelper.AssertQueryRuns(connectionPool, buffer.String())
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP TYPE public.composite_type")
 
-				resultTypes := backup.GetCompositeTypes(connectionPool)
+				resultTypes := testBackup.GetCompositeTypes(connectionPool)
 
 				Expect(resultTypes).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchExcluding(&compositeType, &resultTypes[0], "Oid", "Attributes.CompositeTypeOid")
@@ -83,24 +83,24 @@ var _ = Describe("backup integration create statement tests", func() {
 				testhelper.AssertQueryRuns(connectionPool, `CREATE COLLATION public.some_coll (lc_collate = 'POSIX', lc_ctype = 'POSIX');`)
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP COLLATION public.some_coll")
 				compositeType.Attributes[0].Collation = "public.some_coll"
-				backup.PrintCreateCompositeTypeStatement(backupfile, toc, compositeType, emptyMetadata)
+				testBackup.PrintCreateCompositeTypeStatement(backupfile, toc, compositeType, emptyMetadata)
 
 				testhelper.AssertQueryRuns(connectionPool, buffer.String())
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP TYPE public.composite_type")
 
-				resultTypes := backup.GetCompositeTypes(connectionPool)
+				resultTypes := testBackup.GetCompositeTypes(connectionPool)
 
 				Expect(resultTypes).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchExcluding(&compositeType, &resultTypes[0], "Oid", "Attributes.CompositeTypeOid")
 			})
 			It("creates composite types with attribute comments", func() {
 				compositeType.Attributes[0].Comment = "'comment for att1'"
-				backup.PrintCreateCompositeTypeStatement(backupfile, toc, compositeType, emptyMetadata)
+				testBackup.PrintCreateCompositeTypeStatement(backupfile, toc, compositeType, emptyMetadata)
 
 				testhelper.AssertQueryRuns(connectionPool, buffer.String())
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP TYPE public.composite_type")
 
-				resultTypes := backup.GetCompositeTypes(connectionPool)
+				resultTypes := testBackup.GetCompositeTypes(connectionPool)
 
 				Expect(resultTypes).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchExcluding(&compositeType, &resultTypes[0], "Oid", "Attributes.CompositeTypeOid")
@@ -114,7 +114,7 @@ var _ = Describe("backup integration create statement tests", func() {
 					baseType.Collatable = true
 				}
 				metadata := testutils.DefaultMetadata("TYPE", false, true, true, includeSecurityLabels)
-				backup.PrintCreateBaseTypeStatement(backupfile, toc, baseType, metadata)
+				testBackup.PrintCreateBaseTypeStatement(backupfile, toc, baseType, metadata)
 
 				//Run queries to set up the database state so we can successfully create base types
 				testhelper.AssertQueryRuns(connectionPool, "CREATE TYPE public.base_type")
@@ -124,7 +124,7 @@ var _ = Describe("backup integration create statement tests", func() {
 
 				testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
-				resultTypes := backup.GetBaseTypes(connectionPool)
+				resultTypes := testBackup.GetBaseTypes(connectionPool)
 
 				Expect(resultTypes).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchExcluding(&baseType, &resultTypes[0], "Oid")
@@ -135,12 +135,12 @@ var _ = Describe("backup integration create statement tests", func() {
 				testutils.SkipIfBefore5(connectionPool)
 				enumType := backup.EnumType{Schema: "public", Name: "enum_type", EnumLabels: "'enum_labels'"}
 				enums := []backup.EnumType{enumType}
-				backup.PrintCreateEnumTypeStatements(backupfile, toc, enums, emptyMetadataMap)
+				testBackup.PrintCreateEnumTypeStatements(backupfile, toc, enums, emptyMetadataMap)
 
 				testhelper.AssertQueryRuns(connectionPool, buffer.String())
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP TYPE public.enum_type")
 
-				resultTypes := backup.GetEnumTypes(connectionPool)
+				resultTypes := testBackup.GetEnumTypes(connectionPool)
 
 				Expect(resultTypes).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchExcluding(&resultTypes[0], &enumType, "Oid")
@@ -157,12 +157,12 @@ var _ = Describe("backup integration create statement tests", func() {
 					domainType.Collation = "public.some_coll"
 				}
 				metadata := testutils.DefaultMetadata("DOMAIN", false, true, true, includeSecurityLabels)
-				backup.PrintCreateDomainStatement(backupfile, toc, domainType, metadata, constraints)
+				testBackup.PrintCreateDomainStatement(backupfile, toc, domainType, metadata, constraints)
 
 				testhelper.AssertQueryRuns(connectionPool, buffer.String())
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP TYPE public.domain_type")
 
-				resultTypes := backup.GetDomainTypes(connectionPool)
+				resultTypes := testBackup.GetDomainTypes(connectionPool)
 
 				Expect(resultTypes).To(HaveLen(1))
 				structmatcher.ExpectStructsToMatchIncluding(&domainType, &resultTypes[0], "Schema", "Name", "Type", "DefaultVal", "BaseType", "NotNull", "Collation")
@@ -175,12 +175,12 @@ var _ = Describe("backup integration create statement tests", func() {
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP COLLATION public.some_coll")
 
 				metadata := testutils.DefaultMetadata("TYPE", false, true, true, includeSecurityLabels)
-				backup.PrintCreateRangeTypeStatement(backupfile, toc, rangeType, metadata)
+				testBackup.PrintCreateRangeTypeStatement(backupfile, toc, rangeType, metadata)
 
 				testhelper.AssertQueryRuns(connectionPool, buffer.String())
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP TYPE public.textrange")
 
-				resultTypes := backup.GetRangeTypes(connectionPool)
+				resultTypes := testBackup.GetRangeTypes(connectionPool)
 
 				Expect(len(resultTypes)).To(Equal(1))
 				structmatcher.ExpectStructsToMatchExcluding(&rangeType, &resultTypes[0], "Oid")
@@ -199,11 +199,11 @@ var _ = Describe("backup integration create statement tests", func() {
 				defer testhelper.AssertQueryRuns(connectionPool, "DROP SCHEMA testschema CASCADE;")
 				testhelper.AssertQueryRuns(connectionPool, "CREATE FUNCTION testschema.time_subtype_diff(x time, y time) RETURNS float8 AS 'SELECT EXTRACT(EPOCH FROM (x - y))' LANGUAGE sql STRICT IMMUTABLE;")
 
-				backup.PrintCreateRangeTypeStatement(backupfile, toc, rangeType, emptyMetadata)
+				testBackup.PrintCreateRangeTypeStatement(backupfile, toc, rangeType, emptyMetadata)
 
 				testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
-				resultTypes := backup.GetRangeTypes(connectionPool)
+				resultTypes := testBackup.GetRangeTypes(connectionPool)
 
 				Expect(len(resultTypes)).To(Equal(1))
 				structmatcher.ExpectStructsToMatchExcluding(&rangeType, &resultTypes[0], "Oid")
@@ -211,16 +211,16 @@ var _ = Describe("backup integration create statement tests", func() {
 		})
 	})
 	Describe("PrintCreateCollationStatement", func() {
-		collation := backup.Collation{Oid: 1, Schema: "public", Name: "testcollation", Collate: "POSIX", Ctype: "POSIX"}
+		collation := backup.Collation{Oid: 1, Schema: "public", Name: "testcollation", Collate: "POSIX", Ctype: "POSIX", IsDeterministic: true}
 		It("creates a basic collation", func() {
 			testutils.SkipIfBefore6(connectionPool)
 
-			backup.PrintCreateCollationStatements(backupfile, toc, []backup.Collation{collation}, backup.MetadataMap{})
+			testBackup.PrintCreateCollationStatements(backupfile, toc, []backup.Collation{collation}, backup.MetadataMap{})
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP COLLATION public.testcollation")
 
-			resultCollations := backup.GetCollations(connectionPool)
+			resultCollations := testBackup.GetCollations(connectionPool)
 
 			Expect(resultCollations).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&collation, &resultCollations[0], "Oid")
@@ -230,13 +230,13 @@ var _ = Describe("backup integration create statement tests", func() {
 			collationMetadataMap := testutils.DefaultMetadataMap("COLLATION", false, true, true, false)
 			collationMetadata := collationMetadataMap[collation.GetUniqueID()]
 
-			backup.PrintCreateCollationStatements(backupfile, toc, []backup.Collation{collation}, collationMetadataMap)
+			testBackup.PrintCreateCollationStatements(backupfile, toc, []backup.Collation{collation}, collationMetadataMap)
 
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP COLLATION public.testcollation")
 
-			resultCollations := backup.GetCollations(connectionPool)
-			resultMetadataMap := backup.GetMetadataForObjectType(connectionPool, backup.TYPE_COLLATION)
+			resultCollations := testBackup.GetCollations(connectionPool)
+			resultMetadataMap := testBackup.GetMetadataForObjectType(connectionPool, backup.TYPE_COLLATION)
 
 			Expect(resultCollations).To(HaveLen(1))
 			uniqueID := testutils.UniqueIDFromObjectName(connectionPool, "public", "testcollation", backup.TYPE_COLLATION)
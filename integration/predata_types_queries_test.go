@@ -52,7 +52,7 @@ var _ = Describe("backup integration tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TYPE public.shell_type")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TYPE public.shell_type")
 
-			results := backup.GetShellTypes(connectionPool)
+			results := testBackup.GetShellTypes(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchIncluding(&shellType, &results[0], "Schema", "Name", "Type")
@@ -61,7 +61,7 @@ var _ = Describe("backup integration tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TYPE public.composite_type AS (name int4, name2 numeric(8,2), name1 character(8));")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TYPE public.composite_type")
 
-			results := backup.GetCompositeTypes(connectionPool)
+			results := testBackup.GetCompositeTypes(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&compositeType, &results[0], "Oid", "Attributes.CompositeTypeOid")
@@ -71,7 +71,7 @@ var _ = Describe("backup integration tests", func() {
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TYPE public.composite_type")
 			testhelper.AssertQueryRuns(connectionPool, "COMMENT ON COLUMN public.composite_type.name IS 'name comment';")
 
-			results := backup.GetCompositeTypes(connectionPool)
+			results := testBackup.GetCompositeTypes(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			compositeType.Attributes[0].Comment = "'name comment'"
@@ -84,7 +84,7 @@ var _ = Describe("backup integration tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TYPE public.composite_type AS (name int4, name2 numeric(8,2), name1 character(8) COLLATE public.some_coll);")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TYPE public.composite_type")
 
-			results := backup.GetCompositeTypes(connectionPool)
+			results := testBackup.GetCompositeTypes(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			compositeType.Attributes = []backup.Attribute{
@@ -101,7 +101,7 @@ var _ = Describe("backup integration tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE FUNCTION public.base_fn_out(public.base_type) RETURNS cstring AS 'boolout' LANGUAGE internal")
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TYPE public.base_type(INPUT=public.base_fn_in, OUTPUT=public.base_fn_out)")
 
-			results := backup.GetBaseTypes(connectionPool)
+			results := testBackup.GetBaseTypes(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			if connectionPool.Version.Before("5") {
@@ -122,7 +122,7 @@ var _ = Describe("backup integration tests", func() {
 			}
 			testhelper.AssertQueryRuns(connectionPool, "ALTER TYPE public.base_type SET DEFAULT ENCODING (compresstype=zlib)")
 
-			results := backup.GetBaseTypes(connectionPool)
+			results := testBackup.GetBaseTypes(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			if connectionPool.Version.Before("5") {
@@ -149,7 +149,7 @@ var _ = Describe("backup integration tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE FUNCTION public.base_array_fn_out(public.base_array_type) RETURNS cstring AS 'boolout' LANGUAGE internal")
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TYPE public.base_array_type(INPUT=public.base_array_fn_in, OUTPUT=public.base_array_fn_out, ELEMENT=text)")
 
-			results := backup.GetBaseTypes(connectionPool)
+			results := testBackup.GetBaseTypes(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			if connectionPool.Version.Before("5") {
@@ -163,7 +163,7 @@ var _ = Describe("backup integration tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TYPE public.enum_type AS ENUM ('label1','label2','label3')")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TYPE public.enum_type")
 
-			results := backup.GetEnumTypes(connectionPool)
+			results := testBackup.GetEnumTypes(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&enumType, &results[0], "Oid")
@@ -181,7 +181,7 @@ var _ = Describe("backup integration tests", func() {
 			}
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TYPE public.enum_type2")
 
-			results := backup.GetEnumTypes(connectionPool)
+			results := testBackup.GetEnumTypes(connectionPool)
 
 			Expect(results).To(HaveLen(2))
 			structmatcher.ExpectStructsToMatchExcluding(&enumType, &results[0], "Oid")
@@ -193,8 +193,8 @@ var _ = Describe("backup integration tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE VIEW public.simpleview AS SELECT rolname FROM pg_roles")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP VIEW public.simpleview")
 
-			bases := backup.GetBaseTypes(connectionPool)
-			composites := backup.GetCompositeTypes(connectionPool)
+			bases := testBackup.GetBaseTypes(connectionPool)
+			composites := testBackup.GetCompositeTypes(connectionPool)
 
 			Expect(bases).To(BeEmpty())
 			Expect(composites).To(BeEmpty())
@@ -208,7 +208,7 @@ var _ = Describe("backup integration tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE FOREIGN TABLE public.ft1 (c1 integer) SERVER foreignserver;")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP FOREIGN TABLE public.ft1")
 
-			results := backup.GetCompositeTypes(connectionPool)
+			results := testBackup.GetCompositeTypes(connectionPool)
 
 			Expect(results).To(BeEmpty())
 		})
@@ -217,8 +217,8 @@ var _ = Describe("backup integration tests", func() {
 			// The table's name will be truncated to 63 characters upon creation, as will the names of its implicit types
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TABLE public.loooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooo;")
 
-			bases := backup.GetBaseTypes(connectionPool)
-			composites := backup.GetCompositeTypes(connectionPool)
+			bases := testBackup.GetBaseTypes(connectionPool)
+			composites := testBackup.GetCompositeTypes(connectionPool)
 
 			Expect(bases).To(BeEmpty())
 			Expect(composites).To(BeEmpty())
@@ -230,7 +230,7 @@ var _ = Describe("backup integration tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE DOMAIN public.domain1 AS character(8) DEFAULT 'abc'")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP DOMAIN public.domain1")
 
-			results := backup.GetDomainTypes(connectionPool)
+			results := testBackup.GetDomainTypes(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&domainType, &results[0], "Oid")
@@ -245,7 +245,7 @@ var _ = Describe("backup integration tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE DOMAIN public.domain1 AS character(8) DEFAULT 'abc' COLLATE public.some_coll")
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP DOMAIN public.domain1")
 
-			results := backup.GetDomainTypes(connectionPool)
+			results := testBackup.GetDomainTypes(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 			structmatcher.ExpectStructsToMatchExcluding(&domainType, &results[0], "Oid")
@@ -259,7 +259,7 @@ var _ = Describe("backup integration tests", func() {
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TYPE testschema.shell_type")
 			backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
 
-			results := backup.GetShellTypes(connectionPool)
+			results := testBackup.GetShellTypes(connectionPool)
 			shellTypeOtherSchema := backup.ShellType{Schema: "testschema", Name: "shell_type"}
 
 			Expect(results).To(HaveLen(1))
@@ -276,7 +276,7 @@ var _ = Describe("backup integration tests", func() {
 	COLLATION = public.some_coll
 );`)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP TYPE public.textrange")
-			results := backup.GetRangeTypes(connectionPool)
+			results := testBackup.GetRangeTypes(connectionPool)
 
 			Expect(len(results)).To(Equal(1))
 
@@ -300,7 +300,7 @@ var _ = Describe("backup integration tests", func() {
 	SUBTYPE_DIFF = testschema.time_subtype_diff
 );`)
 
-			results := backup.GetRangeTypes(connectionPool)
+			results := testBackup.GetRangeTypes(connectionPool)
 
 			Expect(len(results)).To(Equal(1))
 
@@ -321,11 +321,14 @@ var _ = Describe("backup integration tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, `CREATE COLLATION public.some_coll (lc_collate = 'POSIX', lc_ctype = 'POSIX');`)
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP COLLATION public.some_coll")
 
-			results := backup.GetCollations(connectionPool)
+			results := testBackup.GetCollations(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 
-			collationDef := backup.Collation{Oid: 0, Schema: "public", Name: "some_coll", Collate: "POSIX", Ctype: "POSIX"}
+			collationDef := backup.Collation{Oid: 0, Schema: "public", Name: "some_coll", Collate: "POSIX", Ctype: "POSIX", IsDeterministic: true}
+			if connectionPool.Version.AtLeast("7") {
+				collationDef.Provider = "libc"
+			}
 			structmatcher.ExpectStructsToMatchExcluding(&collationDef, &results[0], "Oid")
 
 		})
@@ -339,11 +342,14 @@ var _ = Describe("backup integration tests", func() {
 			defer testhelper.AssertQueryRuns(connectionPool, "DROP COLLATION testschema.some_coll")
 			backupCmdFlags.Set(utils.INCLUDE_SCHEMA, "testschema")
 
-			results := backup.GetCollations(connectionPool)
+			results := testBackup.GetCollations(connectionPool)
 
 			Expect(results).To(HaveLen(1))
 
-			collationDef := backup.Collation{Oid: 0, Schema: "testschema", Name: "some_coll", Collate: "POSIX", Ctype: "POSIX"}
+			collationDef := backup.Collation{Oid: 0, Schema: "testschema", Name: "some_coll", Collate: "POSIX", Ctype: "POSIX", IsDeterministic: true}
+			if connectionPool.Version.AtLeast("7") {
+				collationDef.Provider = "libc"
+			}
 			structmatcher.ExpectStructsToMatchExcluding(&collationDef, &results[0], "Oid")
 
 		})
@@ -30,8 +30,8 @@ var _ = Describe("backup integration tests", func() {
 			oldTableOid := testutils.OidFromObjectName(connectionPool, "public", "foo", backup.TYPE_RELATION)
 			tables[0].Oid = oldTableOid
 
-			beforeAttStats := backup.GetAttributeStatistics(connectionPool, tables)
-			beforeTupleStats := backup.GetTupleStatistics(connectionPool, tables)
+			beforeAttStats := testBackup.GetAttributeStatistics(connectionPool, tables)
+			beforeTupleStats := testBackup.GetTupleStatistics(connectionPool, tables)
 			beforeTupleStat := beforeTupleStats[oldTableOid]
 
 			// Drop and recreate the table to clear the statistics
@@ -39,13 +39,13 @@ var _ = Describe("backup integration tests", func() {
 			testhelper.AssertQueryRuns(connectionPool, "CREATE TABLE public.foo(i int, j text, k bool)")
 
 			// Reload the retrieved statistics into the new table
-			backup.PrintStatisticsStatements(backupfile, toc, tables, beforeAttStats, beforeTupleStats)
+			testBackup.PrintStatisticsStatements(backupfile, toc, tables, beforeAttStats, beforeTupleStats)
 			testhelper.AssertQueryRuns(connectionPool, buffer.String())
 
 			newTableOid := testutils.OidFromObjectName(connectionPool, "public", "foo", backup.TYPE_RELATION)
 			tables[0].Oid = newTableOid
-			afterAttStats := backup.GetAttributeStatistics(connectionPool, tables)
-			afterTupleStats := backup.GetTupleStatistics(connectionPool, tables)
+			afterAttStats := testBackup.GetAttributeStatistics(connectionPool, tables)
+			afterTupleStats := testBackup.GetTupleStatistics(connectionPool, tables)
 			afterTupleStat := afterTupleStats[newTableOid]
 
 			oldAtts := beforeAttStats[oldTableOid]
@@ -29,7 +29,7 @@ var _ = Describe("backup integration tests", func() {
 	})
 	Describe("GetAttributeStatistics", func() {
 		It("returns attribute statistics for a table", func() {
-			attStats := backup.GetAttributeStatistics(connectionPool, tables)
+			attStats := testBackup.GetAttributeStatistics(connectionPool, tables)
 			Expect(attStats).To(HaveLen(1))
 			Expect(attStats[tableOid]).To(HaveLen(3))
 			tableAttStatsI := attStats[tableOid][0]
@@ -77,7 +77,7 @@ var _ = Describe("backup integration tests", func() {
 	})
 	Describe("GetTupleStatistics", func() {
 		It("returns tuple statistics for a table", func() {
-			tupleStats := backup.GetTupleStatistics(connectionPool, tables)
+			tupleStats := testBackup.GetTupleStatistics(connectionPool, tables)
 			Expect(tupleStats).To(HaveLen(1))
 			tableTupleStats := tupleStats[tableOid]
 
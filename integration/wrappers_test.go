@@ -3,9 +3,7 @@ package integration
 import (
 	"github.com/greenplum-db/gp-common-go-libs/gplog"
 	"github.com/greenplum-db/gp-common-go-libs/testhelper"
-	"github.com/greenplum-db/gpbackup/backup"
 	"github.com/greenplum-db/gpbackup/utils"
-	"github.com/spf13/cobra"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -15,9 +13,6 @@ var _ = Describe("Wrappers Integration", func() {
 	Describe("RetrieveAndProcessTables", func() {
 		BeforeEach(func() {
 			gplog.SetVerbosity(gplog.LOGERROR) // turn off progress bar in the lock-table routine
-			var rootCmd = &cobra.Command{}
-			backup.DoInit(rootCmd) // initialize the ObjectCount
-			backup.SetCmdFlags(backupCmdFlags)
 		})
 		It("returns the data tables that have names with special characters", func() {
 			_ = backupCmdFlags.Set(utils.INCLUDE_RELATION, "public.foo")
@@ -32,7 +27,7 @@ var _ = Describe("Wrappers Integration", func() {
 			connectionPool.MustBegin(0)
 			defer connectionPool.MustCommit(0)
 
-			_, dataTables := backup.RetrieveAndProcessTables()
+			_, dataTables := testBackup.RetrieveAndProcessTables()
 			Expect(len(dataTables)).To(Equal(2))
 			Expect(dataTables[0].Name).To(Equal("foo"))
 			Expect(dataTables[1].Name).To(Equal(`"BAR"`))
@@ -234,6 +234,9 @@ func (o Options) getUserTableRelationsWithIncludeFiltering(connectionPool *dbcon
 	}
 
 	oidStr := strings.Join(includeOids, ", ")
+	if connectionPool.Version.AtLeast("7") {
+		return o.getGPDB7UserTableRelationsWithIncludeFiltering(connectionPool, oidStr)
+	}
 	childPartitionFilter := ""
 	if o.isLeafPartitionData {
 		//Get all leaf partition tables whose parents are in the include list
@@ -304,6 +307,46 @@ ORDER BY c.oid;`, o.schemaFilterClause("n"), oidStr, oidStr, oidStr, childPartit
 	return results, err
 }
 
+/*
+ * In GPDB 7 every partition has its own CREATE TABLE and ATTACH PARTITION
+ * statements, so an included table needs all of the partitioned tables above
+ * it to be attached to and all of the partitions below it to hold its data.
+ */
+func (o Options) getGPDB7UserTableRelationsWithIncludeFiltering(connectionPool *dbconn.DBConn, oidStr string) ([]FqnStruct, error) {
+	query := fmt.Sprintf(`
+SELECT
+	n.nspname AS schemaname,
+	c.relname AS tablename
+FROM pg_class c
+JOIN pg_namespace n
+	ON c.relnamespace = n.oid
+WHERE %s
+AND (
+	-- Get tables in the include list and the partitioned tables above them
+	c.oid IN (
+		SELECT
+			a.relid
+		FROM pg_class i, pg_partition_ancestors(i.oid) a
+		WHERE i.oid IN (%s)
+	)
+	-- Get partitions below partitioned tables in the include list
+	OR c.oid IN (
+		SELECT
+			t.relid
+		FROM pg_class i, pg_partition_tree(i.oid) t
+		WHERE i.oid IN (%s)
+	)
+)
+AND relkind IN ('r', 'p')
+AND %s
+ORDER BY c.oid;`, o.schemaFilterClause("n"), oidStr, oidStr, ExtensionFilterClause("c"))
+
+	results := make([]FqnStruct, 0)
+	err := connectionPool.Select(&results, query)
+
+	return results, err
+}
+
 func getOidsFromRelationList(connectionPool *dbconn.DBConn, quotedRelationNames []string) ([]string, error) {
 	relList := utils.SliceToQuotedString(quotedRelationNames)
 	query := fmt.Sprintf(`
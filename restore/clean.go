@@ -0,0 +1,139 @@
+package restore
+
+/*
+ * This file contains functions for dropping objects that already exist in the
+ * restore database before they are restored, so that a database holding a
+ * partial or earlier restore of the same backup can be restored over.
+ */
+
+import (
+	"fmt"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/jackc/pgx"
+)
+
+// The SQLSTATE of an error dropping an object that other objects depend on
+const DEPENDENT_OBJECTS_STILL_EXIST = "2BP01"
+
+/*
+ * Maps the TOC object types of the pre-data objects that are dropped to the
+ * keyword used to drop them.  Objects of other types, such as operators and
+ * operator classes, cannot be named from their TOC entries alone, so they
+ * keep the functions and types they depend on from being dropped unless the
+ * drops cascade.  Schemas are never dropped, as RestoreSchemas already allows
+ * them to exist.
+ */
+var dropKeywords = map[string]string{
+	"AGGREGATE":                 "AGGREGATE",
+	"CAST":                      "CAST",
+	"COLLATION":                 "COLLATION",
+	"CONVERSION":                "CONVERSION",
+	"DOMAIN":                    "DOMAIN",
+	"EXTENSION":                 "EXTENSION",
+	"FOREIGN DATA WRAPPER":      "FOREIGN DATA WRAPPER",
+	"FOREIGN SERVER":            "SERVER",
+	"FOREIGN TABLE":             "FOREIGN TABLE",
+	"FUNCTION":                  "FUNCTION",
+	"LANGUAGE":                  "LANGUAGE",
+	"MATERIALIZED VIEW":         "MATERIALIZED VIEW",
+	"PROCEDURE":                 "PROCEDURE",
+	"SEQUENCE":                  "SEQUENCE",
+	"TABLE":                     "TABLE",
+	"TEXT SEARCH CONFIGURATION": "TEXT SEARCH CONFIGURATION",
+	"TEXT SEARCH DICTIONARY":    "TEXT SEARCH DICTIONARY",
+	"TEXT SEARCH PARSER":        "TEXT SEARCH PARSER",
+	"TEXT SEARCH TEMPLATE":      "TEXT SEARCH TEMPLATE",
+	"TRANSFORM":                 "TRANSFORM",
+	"TYPE":                      "TYPE",
+	"VIEW":                      "VIEW",
+}
+
+/*
+ * Returns a DROP statement for each object created by the given pre-data
+ * statements, in the reverse of the order the objects are created in, so that
+ * objects are dropped before the objects they depend on.  The drops only
+ * cascade if cascade is set, as objects in the restore database that were not
+ * backed up may depend on the dropped objects and would be silently dropped
+ * along with them.
+ */
+func GetDropStatements(statements []utils.StatementWithType, ifExists bool, cascade bool) []utils.StatementWithType {
+	ifExistsStr := ""
+	if ifExists {
+		ifExistsStr = " IF EXISTS"
+	}
+	cascadeStr := ""
+	if cascade {
+		cascadeStr = " CASCADE"
+	}
+	dropStatements := make([]utils.StatementWithType, 0)
+	isDropped := make(map[string]bool)
+	for i := len(statements) - 1; i >= 0; i-- {
+		statement := statements[i]
+		keyword, ok := dropKeywords[statement.ObjectType]
+		if !ok {
+			continue
+		}
+		// Casts and transforms are named by their types rather than a schema
+		name := statement.Name
+		if statement.Schema != "" && statement.ObjectType != "CAST" && statement.ObjectType != "TRANSFORM" {
+			name = utils.MakeFQN(statement.Schema, statement.Name)
+		}
+		key := fmt.Sprintf("%s %s", keyword, name)
+		if isDropped[key] {
+			continue
+		}
+		isDropped[key] = true
+		dropStatements = append(dropStatements, utils.StatementWithType{Schema: statement.Schema, Name: statement.Name,
+			ObjectType: statement.ObjectType, Statement: fmt.Sprintf("DROP %s%s %s%s;", keyword, ifExistsStr, name, cascadeStr)})
+	}
+	return dropStatements
+}
+
+/*
+ * An object that cannot be dropped, usually because it does not exist, only
+ * causes a warning, as the statement creating it reports any real problem.
+ * Objects that other objects depend on are reported individually, since
+ * restoring over them requires either dropping the dependent objects by hand
+ * or passing --cascade.
+ */
+func dropExistingObjects(metadataFilename string) {
+	if wasTerminated {
+		return
+	}
+	gplog.Info("Dropping existing objects")
+	statements := GetRestoreMetadataStatements("predata", metadataFilename, []string{}, []string{"SCHEMA"}, true, true)
+	if redirectSchema != "" {
+		statements = utils.SubstituteRedirectSchemaInStatements(statements, redirectSchema)
+	}
+	dropStatements := GetDropStatements(statements, MustGetFlagBool(utils.IF_EXISTS), MustGetFlagBool(utils.CASCADE))
+	useSavepoint := MustGetFlagBool(utils.SINGLE_TRANSACTION)
+	numErrors := 0
+	numDependencyErrors := 0
+	for _, statement := range dropStatements {
+		if useSavepoint {
+			connectionPool.MustExec("SAVEPOINT gprestore_drop", 0)
+		}
+		_, err := connectionPool.Exec(statement.Statement, 0)
+		if err != nil {
+			if useSavepoint {
+				connectionPool.MustExec("ROLLBACK TO SAVEPOINT gprestore_drop", 0)
+			}
+			if pgErr, ok := err.(pgx.PgError); ok && pgErr.Code == DEPENDENT_OBJECTS_STILL_EXIST {
+				gplog.Error("Unable to drop %s %s because other objects depend on it: %s", statement.ObjectType, statement.Name, pgErr.Detail)
+				numDependencyErrors++
+				continue
+			}
+			gplog.Verbose("Error encountered when executing statement: %s Error was: %s", statement.Statement, err.Error())
+			numErrors++
+		}
+	}
+	if numDependencyErrors > 0 {
+		gplog.Warn("Unable to drop %d objects that other objects depend on; use --%s to drop the dependent objects as well.", numDependencyErrors, utils.CASCADE)
+	}
+	if numErrors > 0 {
+		gplog.Warn("Unable to drop %d objects; see log file %s for details.", numErrors, gplog.GetLogFilePath())
+	}
+	gplog.Info("Existing objects dropped")
+}
@@ -0,0 +1,55 @@
+package restore_test
+
+import (
+	"github.com/greenplum-db/gpbackup/restore"
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("restore/clean tests", func() {
+	Describe("GetDropStatements", func() {
+		table := utils.StatementWithType{Schema: "public", Name: "foo", ObjectType: "TABLE", Statement: "CREATE TABLE public.foo (i integer);"}
+		tableComment := utils.StatementWithType{Schema: "public", Name: "foo", ObjectType: "TABLE", Statement: "COMMENT ON TABLE public.foo IS 'This is a table comment.';"}
+		function := utils.StatementWithType{Schema: "public", Name: "add(integer, integer)", ObjectType: "FUNCTION", Statement: "CREATE FUNCTION public.add(integer, integer) RETURNS integer AS $$SELECT $1 + $2$$ LANGUAGE sql;"}
+		cast := utils.StatementWithType{Schema: "public", Name: "(public.mytype AS integer)", ObjectType: "CAST", Statement: "CREATE CAST (public.mytype AS integer) WITH FUNCTION public.casttoint(public.mytype);"}
+		extension := utils.StatementWithType{Schema: "", Name: "plperl", ObjectType: "EXTENSION", Statement: "CREATE EXTENSION IF NOT EXISTS plperl WITH SCHEMA pg_catalog;"}
+		operator := utils.StatementWithType{Schema: "public", Name: "##", ObjectType: "OPERATOR", Statement: "CREATE OPERATOR public.## (PROCEDURE = public.add, LEFTARG = integer, RIGHTARG = integer);"}
+		It("drops objects in the reverse of the order they are created in", func() {
+			dropStatements := restore.GetDropStatements([]utils.StatementWithType{extension, function, table}, false, false)
+
+			Expect(dropStatements).To(Equal([]utils.StatementWithType{
+				{Schema: "public", Name: "foo", ObjectType: "TABLE", Statement: "DROP TABLE public.foo;"},
+				{Schema: "public", Name: "add(integer, integer)", ObjectType: "FUNCTION", Statement: "DROP FUNCTION public.add(integer, integer);"},
+				{Schema: "", Name: "plperl", ObjectType: "EXTENSION", Statement: "DROP EXTENSION plperl;"},
+			}))
+		})
+		It("drops objects with IF EXISTS", func() {
+			dropStatements := restore.GetDropStatements([]utils.StatementWithType{table, cast}, true, false)
+
+			Expect(dropStatements).To(Equal([]utils.StatementWithType{
+				{Schema: "public", Name: "(public.mytype AS integer)", ObjectType: "CAST", Statement: "DROP CAST IF EXISTS (public.mytype AS integer);"},
+				{Schema: "public", Name: "foo", ObjectType: "TABLE", Statement: "DROP TABLE IF EXISTS public.foo;"},
+			}))
+		})
+		It("drops objects with CASCADE", func() {
+			dropStatements := restore.GetDropStatements([]utils.StatementWithType{function, table}, true, true)
+
+			Expect(dropStatements).To(Equal([]utils.StatementWithType{
+				{Schema: "public", Name: "foo", ObjectType: "TABLE", Statement: "DROP TABLE IF EXISTS public.foo CASCADE;"},
+				{Schema: "public", Name: "add(integer, integer)", ObjectType: "FUNCTION", Statement: "DROP FUNCTION IF EXISTS public.add(integer, integer) CASCADE;"},
+			}))
+		})
+		It("drops each object once", func() {
+			dropStatements := restore.GetDropStatements([]utils.StatementWithType{table, tableComment}, true, false)
+
+			Expect(dropStatements).To(HaveLen(1))
+		})
+		It("does not drop objects that cannot be named from their TOC entries", func() {
+			dropStatements := restore.GetDropStatements([]utils.StatementWithType{operator}, true, false)
+
+			Expect(dropStatements).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,158 @@
+package restore
+
+/*
+ * This file contains functions for composing a restore from two backups, in
+ * which metadata is restored from the backup given by --timestamp and table
+ * data from the backup given by --data-timestamp, e.g. to restore the DDL of
+ * a recent metadata-only backup with the data of an older full backup.
+ */
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpbackup/backup_filepath"
+	"github.com/greenplum-db/gpbackup/backup_history"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/pkg/errors"
+)
+
+/*
+ * The data-related parts of the backup configuration are taken from the data
+ * backup, so that the rest of the restore reads its data files as if they
+ * belonged to the metadata backup.
+ */
+func ComposeDataBackup(dataTimestamp string) {
+	gplog.Info("Table data will be restored from backup with timestamp = %s", dataTimestamp)
+	segPrefix := backup_filepath.ParseSegPrefix(MustGetFlagString(utils.BACKUP_DIR), dataTimestamp)
+	dataFPInfo := backup_filepath.NewFilePathInfo(globalCluster, MustGetFlagString(utils.BACKUP_DIR), dataTimestamp, segPrefix)
+	dataBackupConfig := backup_history.ReadConfigFile(dataFPInfo.GetConfigFilePath())
+	if dataBackupConfig.MetadataOnly {
+		gplog.Fatal(errors.Errorf("Backup %s is a metadata-only backup and contains no data to restore", dataTimestamp), "")
+	}
+	utils.EnsureBackupVersionCompatibility(dataBackupConfig.BackupVersion, version)
+	if dataBackupConfig.RestorePlan == nil {
+		SetRestorePlanForLegacyBackup(utils.NewTOC(dataFPInfo.GetTOCFilePath()), dataTimestamp, dataBackupConfig)
+	}
+
+	backupConfig.MetadataOnly = false
+	backupConfig.Compressed = dataBackupConfig.Compressed
+	backupConfig.SingleDataFile = dataBackupConfig.SingleDataFile
+	backupConfig.RestorePlan = dataBackupConfig.RestorePlan
+	utils.InitializePipeThroughParameters(backupConfig.Compressed, 0)
+
+	if !backupConfig.DataOnly {
+		missingTables := GetTablesMissingFromMetadata(globalTOC, backupConfig.RestorePlan)
+		if len(missingTables) > 0 {
+			gplog.Warn("The following tables have data in backup %s but are not in backup %s, and their data will not be restored: %s",
+				dataTimestamp, globalFPInfo.Timestamp, strings.Join(missingTables, ", "))
+		}
+	}
+}
+
+func GetTablesMissingFromMetadata(toc *utils.TOC, restorePlan []backup_history.RestorePlanEntry) []string {
+	metadataTables := make(map[string]bool)
+	for _, entry := range toc.PredataEntries {
+		if entry.ObjectType == "TABLE" {
+			metadataTables[utils.MakeFQN(entry.Schema, entry.Name)] = true
+		}
+	}
+	missingTables := make([]string, 0)
+	for _, planEntry := range restorePlan {
+		for _, tableFQN := range planEntry.TableFQNs {
+			if !metadataTables[tableFQN] {
+				missingTables = append(missingTables, tableFQN)
+			}
+		}
+	}
+	sort.Strings(missingTables)
+	return missingTables
+}
+
+/*
+ * Returns the quoted column names of every table in the restore database,
+ * keyed by the table's quoted FQN.
+ */
+func GetRestoredTableColumns(connectionPool *dbconn.DBConn) map[string]map[string]bool {
+	query := `
+	SELECT quote_ident(n.nspname) || '.' || quote_ident(c.relname) AS tablefqn,
+		quote_ident(a.attname) AS attname
+	FROM pg_attribute a
+		JOIN pg_class c ON a.attrelid = c.oid
+		JOIN pg_namespace n ON c.relnamespace = n.oid
+	WHERE c.relkind = 'r'
+		AND a.attnum > 0
+		AND NOT a.attisdropped`
+	results := make([]struct {
+		TableFQN string
+		AttName  string
+	}, 0)
+	err := connectionPool.Select(&results, query)
+	gplog.FatalOnError(err)
+
+	tableColumns := make(map[string]map[string]bool)
+	for _, result := range results {
+		if _, ok := tableColumns[result.TableFQN]; !ok {
+			tableColumns[result.TableFQN] = make(map[string]bool)
+		}
+		tableColumns[result.TableFQN][result.AttName] = true
+	}
+	return tableColumns
+}
+
+/*
+ * Data can be restored into a table created from the other backup as long as
+ * the table has every column whose data was backed up; columns that exist only
+ * in the restored table are filled with their defaults by COPY.
+ */
+func RemoveUncomposableDataEntries(entries []utils.MasterDataEntry, tableColumns map[string]map[string]bool) []utils.MasterDataEntry {
+	composable := make([]utils.MasterDataEntry, 0, len(entries))
+	for _, entry := range entries {
+		tableName := utils.MakeFQN(entry.Schema, entry.Name)
+		if redirectSchema != "" {
+			tableName = utils.MakeFQN(redirectSchema, entry.Name)
+		}
+		columns, ok := tableColumns[tableName]
+		if !ok {
+			gplog.Warn("Cannot restore data for table %s: the table does not exist in the restore database", tableName)
+			continue
+		}
+		missingColumns := make([]string, 0)
+		for _, column := range SplitAttributeString(entry.AttributeString) {
+			if !columns[column] {
+				missingColumns = append(missingColumns, column)
+			}
+		}
+		if len(missingColumns) > 0 {
+			gplog.Warn("Cannot restore data for table %s: the restored table has no column(s) %s", tableName, strings.Join(missingColumns, ", "))
+			continue
+		}
+		composable = append(composable, entry)
+	}
+	return composable
+}
+
+/*
+ * Splits an attribute string such as (a,"b,c") into its quoted column names,
+ * ignoring commas within quoted names.
+ */
+func SplitAttributeString(attributeString string) []string {
+	attributeString = strings.TrimSuffix(strings.TrimPrefix(attributeString, "("), ")")
+	columns := make([]string, 0)
+	if attributeString == "" {
+		return columns
+	}
+	inQuotes := false
+	start := 0
+	for i, char := range attributeString {
+		if char == '"' {
+			inQuotes = !inQuotes
+		} else if char == ',' && !inQuotes {
+			columns = append(columns, attributeString[start:i])
+			start = i + 1
+		}
+	}
+	return append(columns, attributeString[start:])
+}
@@ -0,0 +1,65 @@
+package restore_test
+
+import (
+	"github.com/greenplum-db/gpbackup/backup_history"
+	"github.com/greenplum-db/gpbackup/restore"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/onsi/gomega/gbytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("restore/compose tests", func() {
+	Describe("GetTablesMissingFromMetadata", func() {
+		It("returns tables with data that are not created by the metadata backup", func() {
+			toc := &utils.TOC{PredataEntries: []utils.MetadataEntry{
+				{Schema: "public", Name: "foo", ObjectType: "TABLE"},
+				{Schema: "public", Name: "bar", ObjectType: "VIEW"},
+			}}
+			restorePlan := []backup_history.RestorePlanEntry{
+				{Timestamp: "20170101010101", TableFQNs: []string{"public.foo", "public.baz"}},
+				{Timestamp: "20170102010101", TableFQNs: []string{"public.bar"}},
+			}
+
+			Expect(restore.GetTablesMissingFromMetadata(toc, restorePlan)).To(Equal([]string{"public.bar", "public.baz"}))
+		})
+	})
+	Describe("SplitAttributeString", func() {
+		It("splits an attribute string into column names", func() {
+			Expect(restore.SplitAttributeString("(a,b,c)")).To(Equal([]string{"a", "b", "c"}))
+		})
+		It("does not split on commas in quoted column names", func() {
+			Expect(restore.SplitAttributeString(`(a,"b,c")`)).To(Equal([]string{"a", `"b,c"`}))
+		})
+		It("returns no columns for an empty attribute string", func() {
+			Expect(restore.SplitAttributeString("")).To(BeEmpty())
+		})
+	})
+	Describe("RemoveUncomposableDataEntries", func() {
+		tableColumns := map[string]map[string]bool{
+			"public.foo": {"a": true, "b": true, "c": true},
+			"public.bar": {"a": true},
+		}
+		It("keeps tables that have every backed up column", func() {
+			entries := []utils.MasterDataEntry{{Schema: "public", Name: "foo", AttributeString: "(a,b)"}}
+
+			Expect(restore.RemoveUncomposableDataEntries(entries, tableColumns)).To(Equal(entries))
+		})
+		It("removes and reports tables that are missing a backed up column", func() {
+			entries := []utils.MasterDataEntry{
+				{Schema: "public", Name: "foo", AttributeString: "(a,b,c)"},
+				{Schema: "public", Name: "bar", AttributeString: "(a,b)"},
+			}
+
+			Expect(restore.RemoveUncomposableDataEntries(entries, tableColumns)).To(Equal(entries[:1]))
+			Expect(stdout).To(gbytes.Say("Cannot restore data for table public.bar: the restored table has no column\\(s\\) b"))
+		})
+		It("removes and reports tables that do not exist", func() {
+			entries := []utils.MasterDataEntry{{Schema: "public", Name: "baz", AttributeString: "(a)"}}
+
+			Expect(restore.RemoveUncomposableDataEntries(entries, tableColumns)).To(BeEmpty())
+			Expect(stdout).To(gbytes.Say("Cannot restore data for table public.baz: the table does not exist in the restore database"))
+		})
+	})
+})
@@ -6,6 +6,7 @@ package restore
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 
@@ -18,11 +19,7 @@ import (
 	"gopkg.in/cheggaaa/pb.v1"
 )
 
-var (
-	tableDelim = ","
-)
-
-func CopyTableIn(connectionPool *dbconn.DBConn, tableName string, tableAttributes string, destinationToRead string, singleDataFile bool, whichConn int) (int64, error) {
+func CopyTableIn(connectionPool *dbconn.DBConn, tableName string, tableAttributes string, destinationToRead string, singleDataFile bool, freeze bool, whichConn int) (int64, error) {
 	whichConn = connectionPool.ValidateConnNum(whichConn)
 	copyCommand := ""
 	readFromDestinationCommand := "cat"
@@ -37,14 +34,18 @@ func CopyTableIn(connectionPool *dbconn.DBConn, tableName string, tableAttribute
 
 	copyCommand = fmt.Sprintf("PROGRAM '%s %s | %s'", readFromDestinationCommand, destinationToRead, customPipeThroughCommand)
 
-	query := fmt.Sprintf("COPY %s%s FROM %s WITH CSV DELIMITER '%s' ON SEGMENT;", tableName, tableAttributes, copyCommand, tableDelim)
+	freezeStr := ""
+	if freeze {
+		freezeStr = " FREEZE"
+	}
+	query := fmt.Sprintf("COPY %s%s FROM %s WITH %s%s ON SEGMENT;", tableName, tableAttributes, copyCommand, copyFormat.Options(), freezeStr)
 	result, err := connectionPool.Exec(query, whichConn)
 	if err != nil {
 		errStr := fmt.Sprintf("Error loading data into table %s", tableName)
 
 		// The COPY ON SEGMENT error might contain useful CONTEXT output
-		if err.(pgx.PgError).Where != "" {
-			errStr = fmt.Sprintf("%s: %s", errStr, err.(pgx.PgError).Where)
+		if pgErr, ok := err.(pgx.PgError); ok && pgErr.Where != "" {
+			errStr = fmt.Sprintf("%s: %s", errStr, pgErr.Where)
 		}
 
 		return 0, errors.Wrap(err, errStr)
@@ -53,14 +54,169 @@ func CopyTableIn(connectionPool *dbconn.DBConn, tableName string, tableAttribute
 	return numRows, err
 }
 
-func restoreSingleTableData(fpInfo *backup_filepath.FilePathInfo, entry utils.MasterDataEntry, tableName string, whichConn int) error {
+/*
+ * COPY FREEZE requires the table to have been created or truncated in the same
+ * transaction, so the table, which is still empty as it was created by this
+ * restore, is truncated in the transaction loading it.  The transaction is not
+ * started with connectionPool.Begin, as COPY FREEZE is not allowed in the
+ * serializable transactions it starts.
+ */
+func CopyTableInWithFreeze(connectionPool *dbconn.DBConn, tableName string, tableAttributes string, destinationToRead string, singleDataFile bool, whichConn int) (int64, error) {
+	_, err := connectionPool.Exec("BEGIN", whichConn)
+	if err != nil {
+		return 0, err
+	}
+	numRows := int64(0)
+	_, err = connectionPool.Exec(fmt.Sprintf("TRUNCATE %s;", tableName), whichConn)
+	if err == nil {
+		numRows, err = CopyTableIn(connectionPool, tableName, tableAttributes, destinationToRead, singleDataFile, true, whichConn)
+	}
+	if err != nil {
+		_, _ = connectionPool.Exec("ROLLBACK", whichConn)
+		return 0, err
+	}
+	_, err = connectionPool.Exec("COMMIT", whichConn)
+	return numRows, err
+}
+
+/*
+ * Returns the FQNs of the tables in the restore database that can be loaded
+ * with COPY FREEZE, which is only supported for heap tables that are not
+ * partitioned.
+ */
+func GetFreezableTables(connectionPool *dbconn.DBConn) map[string]bool {
+	whereClause := `c.relkind = 'r' AND c.relstorage = 'h' AND c.oid NOT IN (SELECT parrelid FROM pg_partition)`
+	if connectionPool.Version.AtLeast("7") {
+		whereClause = `c.relkind = 'r' AND c.relam = (SELECT oid FROM pg_am WHERE amname = 'heap')`
+	}
+	query := fmt.Sprintf(`
+	SELECT quote_ident(n.nspname) || '.' || quote_ident(c.relname) AS tablefqn
+	FROM pg_class c
+		JOIN pg_namespace n ON c.relnamespace = n.oid
+	WHERE %s`, whereClause)
+	results := make([]struct {
+		TableFQN string
+	}, 0)
+	err := connectionPool.Select(&results, query)
+	gplog.FatalOnError(err)
+
+	freezableTables := make(map[string]bool, len(results))
+	for _, result := range results {
+		freezableTables[result.TableFQN] = true
+	}
+	return freezableTables
+}
+
+/*
+ * The tables are truncated in a single statement, so that tables referencing
+ * one another through foreign keys can be truncated.  Tables referenced by a
+ * table that is not being restored are not truncated with CASCADE, as that
+ * would silently remove data that is not restored again.
+ */
+func TruncateTables(connectionPool *dbconn.DBConn, tableNames []string) {
+	if len(tableNames) == 0 {
+		return
+	}
+	gplog.Info("Truncating %d tables before restoring data", len(tableNames))
+	query := fmt.Sprintf("TRUNCATE %s;", strings.Join(tableNames, ", "))
+	_, err := connectionPool.Exec(query, 0)
+	if err != nil {
+		gplog.Fatal(err, "Unable to truncate tables before restoring data; tables referencing them through foreign keys must also be restored")
+	}
+}
+
+/*
+ * As COPY cannot filter the rows it loads, the data of a filtered table is
+ * loaded into a temporary staging table with the same columns and
+ * distribution policy, and only the matching rows are inserted into the table.
+ * The number of rows loaded into the staging table is returned, so that it can
+ * still be checked against the number of rows backed up.
+ */
+func CopyTableInWithFilter(connectionPool *dbconn.DBConn, tableName string, tableAttributes string, destinationToRead string, singleDataFile bool, condition string, whichConn int) (int64, error) {
+	stagingTable := "gprestore_filter_staging"
+	_, err := connectionPool.Exec(fmt.Sprintf("CREATE TEMP TABLE %s (LIKE %s);", stagingTable, tableName), whichConn)
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("Error creating staging table for table %s", tableName))
+	}
+	defer func() {
+		_, _ = connectionPool.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s;", stagingTable), whichConn)
+	}()
+
+	numRows, err := CopyTableIn(connectionPool, stagingTable, tableAttributes, destinationToRead, singleDataFile, false, whichConn)
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("Error loading data for table %s", tableName))
+	}
+	columns := "*"
+	if tableAttributes != "" {
+		columns = strings.TrimSuffix(strings.TrimPrefix(tableAttributes, "("), ")")
+	}
+	query := fmt.Sprintf("INSERT INTO %s%s SELECT %s FROM %s WHERE %s;", tableName, tableAttributes, columns, stagingTable, condition)
+	result, err := connectionPool.Exec(query, whichConn)
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("Error filtering data for table %s", tableName))
+	}
+	numRowsInserted, _ := result.RowsAffected()
+	gplog.Verbose("Restored %d of %d rows to table %s matching %s", numRowsInserted, numRows, tableName, condition)
+	return numRows, nil
+}
+
+/*
+ * The data of an external table backed up with --with-external-data is not
+ * laid out across the segments by the table's distribution policy, so COPY ON
+ * SEGMENT would reject the rows of a hash-distributed table.  It is instead
+ * loaded into a randomly distributed temporary staging table, which COPY does
+ * not check the rows of, and inserted into the table from there so that the
+ * rows are redistributed.  Rows not matching the condition, if one is given,
+ * are not inserted.
+ */
+func CopyTableInWithRedistribution(connectionPool *dbconn.DBConn, tableName string, tableAttributes string, destinationToRead string, singleDataFile bool, condition string, whichConn int) (int64, error) {
+	stagingTable := "gprestore_redistribute_staging"
+	_, err := connectionPool.Exec(fmt.Sprintf("CREATE TEMP TABLE %s (LIKE %s) DISTRIBUTED RANDOMLY;", stagingTable, tableName), whichConn)
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("Error creating staging table for table %s", tableName))
+	}
+	defer func() {
+		_, _ = connectionPool.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s;", stagingTable), whichConn)
+	}()
+
+	numRows, err := CopyTableIn(connectionPool, stagingTable, tableAttributes, destinationToRead, singleDataFile, false, whichConn)
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("Error loading data for table %s", tableName))
+	}
+	columns := "*"
+	if tableAttributes != "" {
+		columns = strings.TrimSuffix(strings.TrimPrefix(tableAttributes, "("), ")")
+	}
+	whereClause := ""
+	if condition != "" {
+		whereClause = fmt.Sprintf(" WHERE %s", condition)
+	}
+	query := fmt.Sprintf("INSERT INTO %s%s SELECT %s FROM %s%s;", tableName, tableAttributes, columns, stagingTable, whereClause)
+	_, err = connectionPool.Exec(query, whichConn)
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("Error redistributing data for table %s", tableName))
+	}
+	return numRows, nil
+}
+
+func restoreSingleTableData(fpInfo *backup_filepath.FilePathInfo, entry utils.MasterDataEntry, tableName string, freeze bool, condition string, whichConn int) error {
 	destinationToRead := ""
 	if backupConfig.SingleDataFile {
 		destinationToRead = fmt.Sprintf("%s_%d", fpInfo.GetSegmentPipePathForCopyCommand(), entry.Oid)
 	} else {
 		destinationToRead = fpInfo.GetTableBackupFilePathForCopyCommand(entry.Oid, utils.GetPipeThroughProgram().Extension, backupConfig.SingleDataFile)
 	}
-	numRowsRestored, err := CopyTableIn(connectionPool, tableName, entry.AttributeString, destinationToRead, backupConfig.SingleDataFile, whichConn)
+	var numRowsRestored int64
+	var err error
+	if entry.IsExternal {
+		numRowsRestored, err = CopyTableInWithRedistribution(connectionPool, tableName, entry.AttributeString, destinationToRead, backupConfig.SingleDataFile, condition, whichConn)
+	} else if condition != "" {
+		numRowsRestored, err = CopyTableInWithFilter(connectionPool, tableName, entry.AttributeString, destinationToRead, backupConfig.SingleDataFile, condition, whichConn)
+	} else if freeze {
+		numRowsRestored, err = CopyTableInWithFreeze(connectionPool, tableName, entry.AttributeString, destinationToRead, backupConfig.SingleDataFile, whichConn)
+	} else {
+		numRowsRestored, err = CopyTableIn(connectionPool, tableName, entry.AttributeString, destinationToRead, backupConfig.SingleDataFile, false, whichConn)
+	}
 	if err != nil {
 		return err
 	}
@@ -80,8 +236,81 @@ func CheckRowsRestored(rowsRestored int64, rowsBackedUp int64, tableName string)
 	return nil
 }
 
+/*
+ * Returns the quoted FQNs of the external tables in the restore database,
+ * which COPY cannot load data into.
+ */
+func GetExternalTableNames(connectionPool *dbconn.DBConn) map[string]bool {
+	query := `
+	SELECT quote_ident(n.nspname) || '.' || quote_ident(c.relname) AS string
+	FROM pg_exttable e
+		JOIN pg_class c ON e.reloid = c.oid
+		JOIN pg_namespace n ON c.relnamespace = n.oid`
+	externalTables := make(map[string]bool)
+	for _, tableName := range dbconn.MustSelectStringSlice(connectionPool, query) {
+		externalTables[tableName] = true
+	}
+	return externalTables
+}
+
+/*
+ * Data backed up with --with-external-data is restored only into regular
+ * tables, so the data of tables that are still external tables in the restore
+ * database is left in the backup.
+ */
+func RemoveExternalTableDataEntries(entries []utils.MasterDataEntry, externalTables map[string]bool) []utils.MasterDataEntry {
+	remaining := make([]utils.MasterDataEntry, 0, len(entries))
+	for _, entry := range entries {
+		tableName := utils.MakeFQN(entry.Schema, entry.Name)
+		if redirectSchema != "" {
+			tableName = utils.MakeFQN(redirectSchema, entry.Name)
+		}
+		if externalTables[tableName] {
+			gplog.Verbose("Skipping data restore of table %s because it is an external table", tableName)
+			continue
+		}
+		remaining = append(remaining, entry)
+	}
+	return remaining
+}
+
+const SMALL_TABLE_ROWS = 10000
+
+/*
+ * Groups consecutive data entries with fewer than SMALL_TABLE_ROWS backed up
+ * rows into batches of up to batchSize entries, which are each restored one
+ * after another on a single connection.  Every table in a batch still gets its
+ * own COPY statement, so batching only changes how tables are scheduled across
+ * the connections, not the cost of copying each table.  Larger tables are
+ * always restored on their own, and the entries keep their order so that a
+ * single data file restore still reads the data in the order it was written.
+ */
+func BatchSmallDataEntries(entries []utils.MasterDataEntry, batchSize int) [][]utils.MasterDataEntry {
+	batches := make([][]utils.MasterDataEntry, 0, len(entries))
+	var smallBatch []utils.MasterDataEntry
+	for _, entry := range entries {
+		if batchSize <= 1 || entry.RowsCopied >= SMALL_TABLE_ROWS {
+			if len(smallBatch) > 0 {
+				batches = append(batches, smallBatch)
+				smallBatch = nil
+			}
+			batches = append(batches, []utils.MasterDataEntry{entry})
+			continue
+		}
+		smallBatch = append(smallBatch, entry)
+		if len(smallBatch) == batchSize {
+			batches = append(batches, smallBatch)
+			smallBatch = nil
+		}
+	}
+	if len(smallBatch) > 0 {
+		batches = append(batches, smallBatch)
+	}
+	return batches
+}
+
 func restoreDataFromTimestamp(fpInfo backup_filepath.FilePathInfo, dataEntries []utils.MasterDataEntry,
-	gucStatements []utils.StatementWithType, dataProgressBar utils.ProgressBar) {
+	gucStatements []utils.StatementWithType, freezableTables map[string]bool, dataProgressBar utils.ProgressBar) {
 	totalTables := len(dataEntries)
 	if totalTables == 0 {
 		gplog.Verbose("No data to restore for timestamp = %s", fpInfo.Timestamp)
@@ -101,7 +330,7 @@ func restoreDataFromTimestamp(fpInfo backup_filepath.FilePathInfo, dataEntries [
 		if wasTerminated {
 			return
 		}
-		utils.StartGpbackupHelpers(globalCluster, fpInfo, "--restore-agent", MustGetFlagString(utils.PLUGIN_CONFIG), "", MustGetFlagBool(utils.ON_ERROR_CONTINUE))
+		utils.StartGpbackupHelpers(globalCluster, fpInfo, "--restore-agent", MustGetFlagString(utils.PLUGIN_CONFIG), "", MustGetFlagBool(utils.ON_ERROR_CONTINUE), MustGetFlagInt(utils.COPY_BUFFER_SIZE)*1024)
 	}
 	/*
 	 * We break when an interrupt is received and rely on
@@ -109,7 +338,8 @@ func restoreDataFromTimestamp(fpInfo backup_filepath.FilePathInfo, dataEntries [
 	 * statements in progress if they don't finish on their own.
 	 */
 	var tableNum int64 = 0
-	tasks := make(chan utils.MasterDataEntry, totalTables)
+	batches := BatchSmallDataEntries(dataEntries, MustGetFlagInt(utils.SMALL_TABLE_BATCH_SIZE))
+	tasks := make(chan []utils.MasterDataEntry, len(batches))
 	var workerPool sync.WaitGroup
 	var numErrors int32
 	var mutex = &sync.Mutex{}
@@ -119,48 +349,59 @@ func restoreDataFromTimestamp(fpInfo backup_filepath.FilePathInfo, dataEntries [
 		go func(whichConn int) {
 			defer workerPool.Done()
 			setGUCsForConnection(gucStatements, whichConn)
-			for entry := range tasks {
-				if wasTerminated {
-					dataProgressBar.(*pb.ProgressBar).NotPrint = true
-					return
-				}
-				tableName := utils.MakeFQN(entry.Schema, entry.Name)
-				err := restoreSingleTableData(&fpInfo, entry, tableName, whichConn)
-
-				atomic.AddInt64(&tableNum, 1)
-				if gplog.GetVerbosity() > gplog.LOGINFO {
-					// No progress bar at this log level, so we note table count here
-					gplog.Verbose("Restored data to table %s from file (table %d of %d)", tableName, tableNum, totalTables)
-				} else {
-					gplog.Verbose("Restored data to table %s from file", tableName)
-				}
-
-				if err != nil {
-					gplog.Error(err.Error())
-					atomic.AddInt32(&numErrors, 1)
-					if !MustGetFlagBool(utils.ON_ERROR_CONTINUE) {
+			setRestoreGUCsForConnection(PHASE_DATA, whichConn)
+			for batch := range tasks {
+				for _, entry := range batch {
+					if wasTerminated {
 						dataProgressBar.(*pb.ProgressBar).NotPrint = true
 						return
 					}
-					mutex.Lock()
-					errorTablesData[tableName] = Empty{}
-					mutex.Unlock()
-				}
+					tableName := utils.MakeFQN(entry.Schema, entry.Name)
+					if redirectSchema != "" {
+						tableName = utils.MakeFQN(redirectSchema, entry.Name)
+					}
+					condition := dataFilters[utils.MakeFQN(entry.Schema, entry.Name)]
+					err := restoreSingleTableData(&fpInfo, entry, tableName, freezableTables[tableName], condition, whichConn)
 
-				if backupConfig.SingleDataFile {
-					agentErr := utils.CheckAgentErrorsOnSegments(globalCluster, globalFPInfo)
-					if agentErr != nil {
-						gplog.Error(agentErr.Error())
-						return
+					atomic.AddInt64(&tableNum, 1)
+					if gplog.GetVerbosity() > gplog.LOGINFO {
+						// No progress bar at this log level, so we note table count here
+						gplog.Verbose("Restored data to table %s from file (table %d of %d)", tableName, tableNum, totalTables)
+					} else {
+						gplog.Verbose("Restored data to table %s from file", tableName)
+					}
+
+					if err != nil {
+						gplog.Error(err.Error())
+						atomic.AddInt32(&numErrors, 1)
+						if !MustGetFlagBool(utils.ON_ERROR_CONTINUE) {
+							dataProgressBar.(*pb.ProgressBar).NotPrint = true
+							return
+						}
+						mutex.Lock()
+						errorTablesData[tableName] = Empty{}
+						mutex.Unlock()
 					}
-				}
 
-				dataProgressBar.Increment()
+					if err == nil {
+						recordCompleted(dataEntryKey(fpInfo.Timestamp, entry))
+					}
+
+					if backupConfig.SingleDataFile {
+						agentErr := utils.CheckAgentErrorsOnSegments(globalCluster, globalFPInfo)
+						if agentErr != nil {
+							gplog.Error(agentErr.Error())
+							return
+						}
+					}
+
+					dataProgressBar.Increment()
+				}
 			}
 		}(i)
 	}
-	for _, entry := range dataEntries {
-		tasks <- entry
+	for _, batch := range batches {
+		tasks <- batch
 	}
 	close(tasks)
 	workerPool.Wait()
@@ -4,10 +4,11 @@ import (
 	"regexp"
 
 	"github.com/DATA-DOG/go-sqlmock"
-	"github.com/greenplum-db/gpbackup/backup"
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
 	"github.com/greenplum-db/gpbackup/restore"
 	"github.com/greenplum-db/gpbackup/utils"
 	"github.com/jackc/pgx"
+	"github.com/pkg/errors"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -17,7 +18,6 @@ var _ = Describe("restore/data tests", func() {
 	Describe("CopyTableIn", func() {
 		BeforeEach(func() {
 			utils.SetPipeThroughProgram(utils.PipeThroughProgram{Name: "cat", OutputCommand: "cat -", InputCommand: "cat -", Extension: ""})
-			backup.SetPluginConfig(nil)
 			cmdFlags.Set(utils.PLUGIN_CONFIG, "")
 		})
 		It("will restore a table from its own file with compression", func() {
@@ -25,7 +25,17 @@ var _ = Describe("restore/data tests", func() {
 			execStr := regexp.QuoteMeta("COPY public.foo(i,j) FROM PROGRAM 'cat <SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456.gz | gzip -d -c' WITH CSV DELIMITER ',' ON SEGMENT;")
 			mock.ExpectExec(execStr).WillReturnResult(sqlmock.NewResult(10, 0))
 			filename := "<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456.gz"
-			_, err := restore.CopyTableIn(connectionPool, "public.foo", "(i,j)", filename, false, 0)
+			_, err := restore.CopyTableIn(connectionPool, "public.foo", "(i,j)", filename, false, false, 0)
+
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+		It("will restore a table using the format of the backup's data files", func() {
+			restore.SetCopyFormat(utils.CopyFormat{Format: utils.COPY_FORMAT_CSV, Delimiter: "|", NullString: "NULL", Header: true})
+			defer restore.SetCopyFormat(utils.NewCopyFormat(utils.COPY_FORMAT_CSV, "", nil, false))
+			execStr := regexp.QuoteMeta("COPY public.foo(i,j) FROM PROGRAM 'cat <SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456 | cat -' WITH CSV HEADER DELIMITER '|' NULL 'NULL' ON SEGMENT;")
+			mock.ExpectExec(execStr).WillReturnResult(sqlmock.NewResult(10, 0))
+			filename := "<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456"
+			_, err := restore.CopyTableIn(connectionPool, "public.foo", "(i,j)", filename, false, false, 0)
 
 			Expect(err).ShouldNot(HaveOccurred())
 		})
@@ -33,7 +43,7 @@ var _ = Describe("restore/data tests", func() {
 			execStr := regexp.QuoteMeta("COPY public.foo(i,j) FROM PROGRAM 'cat <SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456 | cat -' WITH CSV DELIMITER ',' ON SEGMENT;")
 			mock.ExpectExec(execStr).WillReturnResult(sqlmock.NewResult(10, 0))
 			filename := "<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456"
-			_, err := restore.CopyTableIn(connectionPool, "public.foo", "(i,j)", filename, false, 0)
+			_, err := restore.CopyTableIn(connectionPool, "public.foo", "(i,j)", filename, false, false, 0)
 
 			Expect(err).ShouldNot(HaveOccurred())
 		})
@@ -41,7 +51,7 @@ var _ = Describe("restore/data tests", func() {
 			execStr := regexp.QuoteMeta("COPY public.foo(i,j) FROM PROGRAM 'cat <SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_pipe_3456 | cat -' WITH CSV DELIMITER ',' ON SEGMENT;")
 			mock.ExpectExec(execStr).WillReturnResult(sqlmock.NewResult(10, 0))
 			filename := "<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_pipe_3456"
-			_, err := restore.CopyTableIn(connectionPool, "public.foo", "(i,j)", filename, true, 0)
+			_, err := restore.CopyTableIn(connectionPool, "public.foo", "(i,j)", filename, true, false, 0)
 
 			Expect(err).ShouldNot(HaveOccurred())
 		})
@@ -54,7 +64,7 @@ var _ = Describe("restore/data tests", func() {
 			mock.ExpectExec(execStr).WillReturnResult(sqlmock.NewResult(10, 0))
 
 			filename := "<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_pipe_3456.gz"
-			_, err := restore.CopyTableIn(connectionPool, "public.foo", "(i,j)", filename, false, 0)
+			_, err := restore.CopyTableIn(connectionPool, "public.foo", "(i,j)", filename, false, false, 0)
 
 			Expect(err).ShouldNot(HaveOccurred())
 		})
@@ -66,7 +76,7 @@ var _ = Describe("restore/data tests", func() {
 			mock.ExpectExec(execStr).WillReturnResult(sqlmock.NewResult(10, 0))
 
 			filename := "<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_pipe_3456.gz"
-			_, err := restore.CopyTableIn(connectionPool, "public.foo", "(i,j)", filename, false, 0)
+			_, err := restore.CopyTableIn(connectionPool, "public.foo", "(i,j)", filename, false, false, 0)
 
 			Expect(err).ShouldNot(HaveOccurred())
 		})
@@ -80,12 +90,175 @@ var _ = Describe("restore/data tests", func() {
 			}
 			mock.ExpectExec(execStr).WillReturnError(pgErr)
 			filename := "<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456"
-			_, err := restore.CopyTableIn(connectionPool, "public.foo", "(i,j)", filename, false, 0)
+			_, err := restore.CopyTableIn(connectionPool, "public.foo", "(i,j)", filename, false, false, 0)
 
 			Expect(err.Error()).To(Equal("Error loading data into table public.foo: " +
 				"COPY foo, line 1: \"5\": " +
 				"ERROR: value of distribution key doesn't belong to segment with ID 0, it belongs to segment with ID 1 (SQLSTATE 22P04)"))
 		})
+		It("returns an error without CONTEXT output when the error is not a database error", func() {
+			execStr := regexp.QuoteMeta("COPY public.foo(i,j) FROM PROGRAM 'cat <SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456 | cat -' WITH CSV DELIMITER ',' ON SEGMENT;")
+			mock.ExpectExec(execStr).WillReturnError(errors.New("connection reset by peer"))
+			filename := "<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456"
+			_, err := restore.CopyTableIn(connectionPool, "public.foo", "(i,j)", filename, false, false, 0)
+
+			Expect(err.Error()).To(Equal("Error loading data into table public.foo: connection reset by peer"))
+		})
+	})
+	Describe("CopyTableInWithFreeze", func() {
+		BeforeEach(func() {
+			utils.SetPipeThroughProgram(utils.PipeThroughProgram{Name: "cat", OutputCommand: "cat -", InputCommand: "cat -", Extension: ""})
+			cmdFlags.Set(utils.PLUGIN_CONFIG, "")
+		})
+		filename := "<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456"
+		copyStr := regexp.QuoteMeta("COPY public.foo(i,j) FROM PROGRAM 'cat <SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456 | cat -' WITH CSV DELIMITER ',' FREEZE ON SEGMENT;")
+		It("truncates the table and loads it with COPY FREEZE in a single transaction", func() {
+			mock.ExpectExec("BEGIN").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta("TRUNCATE public.foo;")).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(copyStr).WillReturnResult(sqlmock.NewResult(0, 10))
+			mock.ExpectExec("COMMIT").WillReturnResult(sqlmock.NewResult(0, 0))
+
+			numRows, err := restore.CopyTableInWithFreeze(connectionPool, "public.foo", "(i,j)", filename, false, 0)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(numRows).To(Equal(int64(10)))
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+		It("rolls back the transaction when the COPY fails", func() {
+			mock.ExpectExec("BEGIN").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta("TRUNCATE public.foo;")).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(copyStr).WillReturnError(errors.New("connection reset by peer"))
+			mock.ExpectExec("ROLLBACK").WillReturnResult(sqlmock.NewResult(0, 0))
+
+			_, err := restore.CopyTableInWithFreeze(connectionPool, "public.foo", "(i,j)", filename, false, 0)
+
+			Expect(err.Error()).To(Equal("Error loading data into table public.foo: connection reset by peer"))
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+	Describe("CopyTableInWithFilter", func() {
+		BeforeEach(func() {
+			utils.SetPipeThroughProgram(utils.PipeThroughProgram{Name: "cat", OutputCommand: "cat -", InputCommand: "cat -", Extension: ""})
+			cmdFlags.Set(utils.PLUGIN_CONFIG, "")
+		})
+		filename := "<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456"
+		copyStr := regexp.QuoteMeta("COPY gprestore_filter_staging(i,j) FROM PROGRAM 'cat <SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456 | cat -' WITH CSV DELIMITER ',' ON SEGMENT;")
+		It("loads the data into a staging table and inserts the matching rows into the table", func() {
+			mock.ExpectExec(regexp.QuoteMeta("CREATE TEMP TABLE gprestore_filter_staging (LIKE public.foo);")).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(copyStr).WillReturnResult(sqlmock.NewResult(0, 10))
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO public.foo(i,j) SELECT i,j FROM gprestore_filter_staging WHERE tenant_id = 7;")).WillReturnResult(sqlmock.NewResult(0, 3))
+			mock.ExpectExec(regexp.QuoteMeta("DROP TABLE IF EXISTS gprestore_filter_staging;")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+			numRows, err := restore.CopyTableInWithFilter(connectionPool, "public.foo", "(i,j)", filename, false, "tenant_id = 7", 0)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(numRows).To(Equal(int64(10)))
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+		It("drops the staging table when the rows cannot be inserted", func() {
+			mock.ExpectExec(regexp.QuoteMeta("CREATE TEMP TABLE gprestore_filter_staging (LIKE public.foo);")).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(copyStr).WillReturnResult(sqlmock.NewResult(0, 10))
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO public.foo(i,j) SELECT i,j FROM gprestore_filter_staging WHERE k = 7;")).WillReturnError(errors.New(`column "k" does not exist`))
+			mock.ExpectExec(regexp.QuoteMeta("DROP TABLE IF EXISTS gprestore_filter_staging;")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+			_, err := restore.CopyTableInWithFilter(connectionPool, "public.foo", "(i,j)", filename, false, "k = 7", 0)
+
+			Expect(err.Error()).To(Equal(`Error filtering data for table public.foo: column "k" does not exist`))
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+	Describe("CopyTableInWithRedistribution", func() {
+		BeforeEach(func() {
+			utils.SetPipeThroughProgram(utils.PipeThroughProgram{Name: "cat", OutputCommand: "cat -", InputCommand: "cat -", Extension: ""})
+			cmdFlags.Set(utils.PLUGIN_CONFIG, "")
+		})
+		filename := "<SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456"
+		createStr := regexp.QuoteMeta("CREATE TEMP TABLE gprestore_redistribute_staging (LIKE public.foo) DISTRIBUTED RANDOMLY;")
+		copyStr := regexp.QuoteMeta("COPY gprestore_redistribute_staging(i,j) FROM PROGRAM 'cat <SEG_DATA_DIR>/backups/20170101/20170101010101/gpbackup_<SEGID>_20170101010101_3456 | cat -' WITH CSV DELIMITER ',' ON SEGMENT;")
+		dropStr := regexp.QuoteMeta("DROP TABLE IF EXISTS gprestore_redistribute_staging;")
+		It("loads the data into a randomly distributed staging table and inserts it into the table", func() {
+			mock.ExpectExec(createStr).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(copyStr).WillReturnResult(sqlmock.NewResult(0, 10))
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO public.foo(i,j) SELECT i,j FROM gprestore_redistribute_staging;")).WillReturnResult(sqlmock.NewResult(0, 10))
+			mock.ExpectExec(dropStr).WillReturnResult(sqlmock.NewResult(0, 0))
+
+			numRows, err := restore.CopyTableInWithRedistribution(connectionPool, "public.foo", "(i,j)", filename, false, "", 0)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(numRows).To(Equal(int64(10)))
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+		It("only inserts the rows matching the condition", func() {
+			mock.ExpectExec(createStr).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(copyStr).WillReturnResult(sqlmock.NewResult(0, 10))
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO public.foo(i,j) SELECT i,j FROM gprestore_redistribute_staging WHERE i = 7;")).WillReturnResult(sqlmock.NewResult(0, 3))
+			mock.ExpectExec(dropStr).WillReturnResult(sqlmock.NewResult(0, 0))
+
+			numRows, err := restore.CopyTableInWithRedistribution(connectionPool, "public.foo", "(i,j)", filename, false, "i = 7", 0)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(numRows).To(Equal(int64(10)))
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+		It("drops the staging table when the rows cannot be inserted", func() {
+			mock.ExpectExec(createStr).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(copyStr).WillReturnResult(sqlmock.NewResult(0, 10))
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO public.foo(i,j) SELECT i,j FROM gprestore_redistribute_staging;")).WillReturnError(errors.New("permission denied for relation foo"))
+			mock.ExpectExec(dropStr).WillReturnResult(sqlmock.NewResult(0, 0))
+
+			_, err := restore.CopyTableInWithRedistribution(connectionPool, "public.foo", "(i,j)", filename, false, "", 0)
+
+			Expect(err.Error()).To(Equal("Error redistributing data for table public.foo: permission denied for relation foo"))
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+	Describe("TruncateTables", func() {
+		It("truncates all tables in a single statement", func() {
+			mock.ExpectExec(regexp.QuoteMeta("TRUNCATE public.foo, public.bar;")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+			restore.TruncateTables(connectionPool, []string{"public.foo", "public.bar"})
+
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+		It("does nothing when there are no tables", func() {
+			restore.TruncateTables(connectionPool, []string{})
+
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+		It("panics if the tables cannot be truncated", func() {
+			mock.ExpectExec(regexp.QuoteMeta("TRUNCATE public.foo;")).WillReturnError(errors.New(`cannot truncate a table referenced in a foreign key constraint`))
+			defer testhelper.ShouldPanicWithMessage("cannot truncate a table referenced in a foreign key constraint: Unable to truncate tables before restoring data")
+
+			restore.TruncateTables(connectionPool, []string{"public.foo"})
+		})
+	})
+	Describe("RemoveExternalTableDataEntries", func() {
+		It("removes the entries of tables that are external tables in the restore database", func() {
+			regularEntry := utils.MasterDataEntry{Schema: "public", Name: "foo"}
+			externalEntry := utils.MasterDataEntry{Schema: "public", Name: "ext"}
+
+			entries := restore.RemoveExternalTableDataEntries([]utils.MasterDataEntry{regularEntry, externalEntry}, map[string]bool{"public.ext": true})
+
+			Expect(entries).To(Equal([]utils.MasterDataEntry{regularEntry}))
+		})
+	})
+	Describe("BatchSmallDataEntries", func() {
+		entryOne := utils.MasterDataEntry{Schema: "public", Name: "table_one", RowsCopied: 10}
+		entryTwo := utils.MasterDataEntry{Schema: "public", Name: "table_two", RowsCopied: restore.SMALL_TABLE_ROWS}
+		entryThree := utils.MasterDataEntry{Schema: "public", Name: "table_three", RowsCopied: 10}
+		entryFour := utils.MasterDataEntry{Schema: "public", Name: "table_four", RowsCopied: 10}
+		entries := []utils.MasterDataEntry{entryOne, entryTwo, entryThree, entryFour}
+
+		It("puts each entry in its own batch when the batch size is 1", func() {
+			batches := restore.BatchSmallDataEntries(entries, 1)
+
+			Expect(batches).To(Equal([][]utils.MasterDataEntry{{entryOne}, {entryTwo}, {entryThree}, {entryFour}}))
+		})
+		It("restores large tables on their own and batches small tables in order", func() {
+			batches := restore.BatchSmallDataEntries(entries, 2)
+
+			Expect(batches).To(Equal([][]utils.MasterDataEntry{{entryOne}, {entryTwo}, {entryThree, entryFour}}))
+		})
 	})
 	Describe("CheckRowsRestored", func() {
 		var (
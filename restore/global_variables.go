@@ -28,11 +28,20 @@ type Empty struct{}
 var (
 	backupConfig        *backup_history.BackupConfig
 	connectionPool      *dbconn.DBConn
+	copyFormat          = utils.NewCopyFormat(utils.COPY_FORMAT_CSV, "", nil, false)
+	dataFilters         map[string]string
 	globalCluster       *cluster.Cluster
 	globalFPInfo        backup_filepath.FilePathInfo
 	globalTOC           *utils.TOC
+	locationMap         map[string]string
+	notifications       *utils.Notifications
+	originalGUCValues   map[string]string
+	ownerMap            map[string]string
 	pluginConfig        *utils.PluginConfig
+	redirectSchema      string
+	restoreGUCs         []RestoreGUC
 	restoreStartTime    string
+	tablespaceMap       map[string]string
 	version             string
 	wasTerminated       bool
 	errorTablesMetadata map[string]Empty
@@ -68,6 +77,10 @@ func SetBackupConfig(config *backup_history.BackupConfig) {
 	backupConfig = config
 }
 
+func SetCopyFormat(format utils.CopyFormat) {
+	copyFormat = format
+}
+
 func SetConnection(conn *dbconn.DBConn) {
 	connectionPool = conn
 }
@@ -88,6 +101,10 @@ func SetTOC(toc *utils.TOC) {
 	globalTOC = toc
 }
 
+func SetRedirectSchema(schema string) {
+	redirectSchema = schema
+}
+
 // Util functions to enable ease of access to global flag values
 
 func MustGetFlagString(flagName string) string {
@@ -106,6 +123,10 @@ func MustGetFlagStringSlice(flagName string) []string {
 	return utils.MustGetFlagStringSlice(cmdFlags, flagName)
 }
 
+func MustGetFlagStringArray(flagName string) []string {
+	return utils.MustGetFlagStringArray(cmdFlags, flagName)
+}
+
 func GetVersion() string {
 	return version
 }
@@ -0,0 +1,47 @@
+package restore
+
+/*
+ * This file contains functions for leaving index creation out of a restore,
+ * as building indexes often takes longer than restoring the data they cover.
+ */
+
+import (
+	"strings"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpbackup/utils"
+)
+
+/*
+ * Splits post-data statements into those that create indexes, along with the
+ * statements setting their tablespace, comment, and so on, and all others.
+ */
+func SeparateIndexStatements(statements []utils.StatementWithType) ([]utils.StatementWithType, []utils.StatementWithType) {
+	indexStatements := make([]utils.StatementWithType, 0)
+	otherStatements := make([]utils.StatementWithType, 0)
+	for _, statement := range statements {
+		if statement.ObjectType == "INDEX" {
+			indexStatements = append(indexStatements, statement)
+		} else {
+			otherStatements = append(otherStatements, statement)
+		}
+	}
+	return indexStatements, otherStatements
+}
+
+/*
+ * Index statements are written in the order they were backed up, one per line,
+ * so the file can be run with psql or split up and run in parallel.
+ */
+func PrintDeferredIndexStatements(indexFile *utils.FileWithByteCount, statements []utils.StatementWithType) {
+	for _, statement := range statements {
+		indexFile.MustPrintf("%s\n", strings.TrimSpace(statement.Statement))
+	}
+}
+
+func writeDeferredIndexFile(filename string, statements []utils.StatementWithType) {
+	gplog.Info("Writing %d deferred index statements to %s", len(statements), filename)
+	indexFile := utils.NewFileWithByteCountFromFile(filename)
+	PrintDeferredIndexStatements(indexFile, statements)
+	indexFile.Close()
+}
@@ -0,0 +1,37 @@
+package restore_test
+
+import (
+	"github.com/greenplum-db/gpbackup/restore"
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("restore/indexes tests", func() {
+	index1 := utils.StatementWithType{ObjectType: "INDEX", ReferenceObject: "public.table1", Statement: "\n\nCREATE INDEX index1 ON public.table1 USING btree(i);\n"}
+	index2 := utils.StatementWithType{ObjectType: "INDEX", ReferenceObject: "public.table2", Statement: "\n\nCREATE INDEX index2 ON public.table2 USING btree(i);\nALTER TABLE public.table2 CLUSTER ON index2;\n"}
+	trigger := utils.StatementWithType{ObjectType: "TRIGGER", ReferenceObject: "public.table1", Statement: "\n\nCREATE TRIGGER trigger1 AFTER INSERT ON public.table1 FOR EACH STATEMENT EXECUTE PROCEDURE public.func1();\n"}
+	Describe("SeparateIndexStatements", func() {
+		It("separates index statements from other post-data statements", func() {
+			indexStatements, otherStatements := restore.SeparateIndexStatements([]utils.StatementWithType{index1, trigger, index2})
+			Expect(indexStatements).To(Equal([]utils.StatementWithType{index1, index2}))
+			Expect(otherStatements).To(Equal([]utils.StatementWithType{trigger}))
+		})
+		It("returns no index statements when there are no indexes", func() {
+			indexStatements, otherStatements := restore.SeparateIndexStatements([]utils.StatementWithType{trigger})
+			Expect(indexStatements).To(BeEmpty())
+			Expect(otherStatements).To(Equal([]utils.StatementWithType{trigger}))
+		})
+	})
+	Describe("PrintDeferredIndexStatements", func() {
+		It("prints each index statement without surrounding blank lines", func() {
+			indexFile := utils.NewFileWithByteCount(buffer)
+			restore.PrintDeferredIndexStatements(indexFile, []utils.StatementWithType{index1, index2})
+			Expect(string(buffer.Contents())).To(Equal(`CREATE INDEX index1 ON public.table1 USING btree(i);
+CREATE INDEX index2 ON public.table2 USING btree(i);
+ALTER TABLE public.table2 CLUSTER ON index2;
+`))
+		})
+	})
+})
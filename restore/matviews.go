@@ -0,0 +1,134 @@
+package restore
+
+/*
+ * This file contains functions for refreshing materialized views once their
+ * underlying data has been restored, as they are created WITH NO DATA.
+ */
+
+import (
+	"fmt"
+
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpbackup/utils"
+)
+
+func refreshMaterializedViews(metadataFilename string) {
+	if wasTerminated {
+		return
+	}
+	statements := GetRestoreMetadataStatements("predata", metadataFilename, []string{"MATERIALIZED VIEW"}, []string{}, true, true)
+	if redirectSchema != "" {
+		statements = utils.SubstituteRedirectSchemaInStatements(statements, redirectSchema)
+	}
+	matviews := make([]string, 0)
+	matviewEntries := make(map[string]utils.StatementWithType)
+	for _, statement := range statements {
+		fqn := utils.MakeFQN(statement.Schema, statement.Name)
+		if _, ok := matviewEntries[fqn]; !ok {
+			matviewEntries[fqn] = statement
+			matviews = append(matviews, fqn)
+		}
+	}
+	if len(matviews) == 0 {
+		return
+	}
+
+	gplog.Info("Refreshing materialized views")
+	batches := GetMaterializedViewRefreshBatches(matviews, GetViewDependencies(connectionPool))
+	progressBar := utils.NewProgressBar(len(matviews), "Materialized views refreshed: ", utils.PB_VERBOSE)
+	progressBar.Start()
+	for _, batch := range batches {
+		refreshStatements := make([]utils.StatementWithType, 0, len(batch))
+		for _, matview := range batch {
+			entry := matviewEntries[matview]
+			refreshStatements = append(refreshStatements, utils.StatementWithType{Schema: entry.Schema, Name: entry.Name,
+				ObjectType: "REFRESH MATERIALIZED VIEW", Statement: fmt.Sprintf("REFRESH MATERIALIZED VIEW %s;", matview)})
+		}
+		ExecuteStatements(refreshStatements, progressBar, connectionPool.NumConns > 1)
+	}
+	progressBar.Finish()
+	if wasTerminated {
+		gplog.Info("Materialized view refresh incomplete")
+	} else {
+		gplog.Info("Materialized view refresh complete")
+	}
+}
+
+/*
+ * Returns the views and materialized views that each view or materialized
+ * view in the restore database selects from, keyed by fully-qualified name.
+ */
+func GetViewDependencies(connectionPool *dbconn.DBConn) map[string][]string {
+	query := `
+	SELECT DISTINCT quote_ident(dn.nspname) || '.' || quote_ident(dc.relname) AS dependent,
+		quote_ident(rn.nspname) || '.' || quote_ident(rc.relname) AS referenced
+	FROM pg_depend d
+		JOIN pg_rewrite r ON d.classid = 'pg_rewrite'::regclass AND d.objid = r.oid
+		JOIN pg_class dc ON r.ev_class = dc.oid
+		JOIN pg_namespace dn ON dc.relnamespace = dn.oid
+		JOIN pg_class rc ON d.refclassid = 'pg_class'::regclass AND d.refobjid = rc.oid
+		JOIN pg_namespace rn ON rc.relnamespace = rn.oid
+	WHERE dc.relkind IN ('v', 'm')
+		AND rc.relkind IN ('v', 'm')
+		AND dc.oid != rc.oid`
+
+	var results []struct {
+		Dependent  string
+		Referenced string
+	}
+	err := connectionPool.Select(&results, query)
+	gplog.FatalOnError(err)
+	dependencies := make(map[string][]string)
+	for _, result := range results {
+		dependencies[result.Dependent] = append(dependencies[result.Dependent], result.Referenced)
+	}
+	return dependencies
+}
+
+/*
+ * Groups materialized views into batches such that every materialized view
+ * comes after all of the materialized views it depends on, directly or through
+ * regular views.  The materialized views in a batch are independent of one
+ * another, so each batch can be refreshed in parallel.
+ */
+func GetMaterializedViewRefreshBatches(matviews []string, dependencies map[string][]string) [][]string {
+	isMatview := make(map[string]bool, len(matviews))
+	for _, matview := range matviews {
+		isMatview[matview] = true
+	}
+	depths := make(map[string]int)
+	visiting := make(map[string]bool)
+	var getDepth func(relation string) int
+	getDepth = func(relation string) int {
+		if depth, ok := depths[relation]; ok {
+			return depth
+		}
+		if visiting[relation] {
+			return 0
+		}
+		visiting[relation] = true
+		depth := 0
+		for _, referenced := range dependencies[relation] {
+			if referencedDepth := getDepth(referenced); referencedDepth > depth {
+				depth = referencedDepth
+			}
+		}
+		if isMatview[relation] {
+			depth++
+		}
+		visiting[relation] = false
+		depths[relation] = depth
+		return depth
+	}
+
+	batches := make([][]string, 0)
+	for _, matview := range matviews {
+		batchNum := getDepth(matview) - 1
+		for len(batches) <= batchNum {
+			batches = append(batches, make([]string, 0))
+		}
+		batches[batchNum] = append(batches[batchNum], matview)
+	}
+	return batches
+}
@@ -0,0 +1,39 @@
+package restore_test
+
+import (
+	"github.com/greenplum-db/gpbackup/restore"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("restore/matviews tests", func() {
+	Describe("GetMaterializedViewRefreshBatches", func() {
+		It("puts independent materialized views in the same batch", func() {
+			batches := restore.GetMaterializedViewRefreshBatches([]string{"public.mv1", "public.mv2"}, map[string][]string{})
+
+			Expect(batches).To(Equal([][]string{{"public.mv1", "public.mv2"}}))
+		})
+		It("refreshes materialized views after the materialized views they depend on", func() {
+			dependencies := map[string][]string{"public.mv2": {"public.mv1"}, "public.mv3": {"public.mv2"}}
+
+			batches := restore.GetMaterializedViewRefreshBatches([]string{"public.mv3", "public.mv1", "public.mv2", "public.mv4"}, dependencies)
+
+			Expect(batches).To(Equal([][]string{{"public.mv1", "public.mv4"}, {"public.mv2"}, {"public.mv3"}}))
+		})
+		It("follows dependencies through regular views", func() {
+			dependencies := map[string][]string{"public.mv2": {"public.view1"}, "public.view1": {"public.mv1"}}
+
+			batches := restore.GetMaterializedViewRefreshBatches([]string{"public.mv2", "public.mv1"}, dependencies)
+
+			Expect(batches).To(Equal([][]string{{"public.mv1"}, {"public.mv2"}}))
+		})
+		It("ignores materialized views that are not being refreshed", func() {
+			dependencies := map[string][]string{"public.mv2": {"other.mv1"}}
+
+			batches := restore.GetMaterializedViewRefreshBatches([]string{"public.mv2"}, dependencies)
+
+			Expect(batches).To(Equal([][]string{{"public.mv2"}}))
+		})
+	})
+})
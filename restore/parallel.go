@@ -39,6 +39,8 @@ func executeStatementsForConn(statements chan utils.StatementWithType, fatalErr
 			} else {
 				*fatalErr = err
 			}
+		} else {
+			recordCompleted(statementKey(statement))
 		}
 		progressBar.Increment()
 	}
@@ -52,6 +54,10 @@ func ExecuteStatements(statements []utils.StatementWithType, progressBar utils.P
 	var workerPool sync.WaitGroup
 	var fatalErr error
 	var numErrors int32
+	statements, numSkipped := RemoveCompletedStatements(statements)
+	for i := 0; i < numSkipped; i++ {
+		progressBar.Increment()
+	}
 	tasks := make(chan utils.StatementWithType, len(statements))
 	for _, statement := range statements {
 		tasks <- statement
@@ -72,6 +78,10 @@ func ExecuteStatements(statements []utils.StatementWithType, progressBar utils.P
 		}
 		workerPool.Wait()
 	}
+	reportStatementErrors(fatalErr, numErrors)
+}
+
+func reportStatementErrors(fatalErr error, numErrors int32) {
 	if fatalErr != nil {
 		fmt.Println("")
 		gplog.Fatal(fatalErr, "")
@@ -89,28 +99,74 @@ func ExecuteStatementsAndCreateProgressBar(statements []utils.StatementWithType,
 }
 
 /*
- *   There is an existing bug in Greenplum where creating indexes in parallel
- *   on an AO table that didn't have any indexes previously can cause
- *   deadlock.
+ * Post-data statements are grouped by the relation they apply to.  Each group
+ * is restored in order on a single connection, and groups for different
+ * relations are restored in parallel, so an index is always created before
+ * the statements that use it.  This also avoids a bug in Greenplum where
+ * creating indexes in parallel on an AO table that didn't have any indexes
+ * previously can cause deadlock.
  *
- *   We work around this issue by restoring post data objects in
- *   two batches. The first batch takes one index from each table and
- *   restores them in parallel (which has no possibility of deadlock) and
- *   then the second restores all other postdata objects in parallel. After
- *   each table has at least one index, there is no more risk of deadlock.
+ * FOREIGN KEY constraints lock the referenced relation as well as their own,
+ * and need the referenced relation's unique index, so they are returned along
+ * with statements not tied to any relation to be restored after all groups.
  */
-func BatchPostdataStatements(statements []utils.StatementWithType) ([]utils.StatementWithType, []utils.StatementWithType) {
-	indexMap := make(map[string]bool)
-	firstBatch := make([]utils.StatementWithType, 0)
-	secondBatch := make([]utils.StatementWithType, 0)
+func GroupPostdataStatements(statements []utils.StatementWithType) ([][]utils.StatementWithType, []utils.StatementWithType) {
+	groups := make([][]utils.StatementWithType, 0)
+	groupIndexes := make(map[string]int)
+	remaining := make([]utils.StatementWithType, 0)
 	for _, statement := range statements {
-		_, tableIndexPresent := indexMap[statement.ReferenceObject]
-		if statement.ObjectType == "INDEX" && !tableIndexPresent {
-			indexMap[statement.ReferenceObject] = true
-			firstBatch = append(firstBatch, statement)
-		} else {
-			secondBatch = append(secondBatch, statement)
+		if statement.ReferenceObject == "" || isForeignKeyStatement(statement) {
+			remaining = append(remaining, statement)
+			continue
 		}
+		groupIndex, ok := groupIndexes[statement.ReferenceObject]
+		if !ok {
+			groupIndex = len(groups)
+			groupIndexes[statement.ReferenceObject] = groupIndex
+			groups = append(groups, make([]utils.StatementWithType, 0))
+		}
+		groups[groupIndex] = append(groups[groupIndex], statement)
+	}
+	return groups, remaining
+}
+
+func isForeignKeyStatement(statement utils.StatementWithType) bool {
+	return statement.ObjectType == "CONSTRAINT" && strings.Contains(statement.Statement, " FOREIGN KEY ")
+}
+
+func ExecutePostdataStatements(statements []utils.StatementWithType, progressBar utils.ProgressBar) {
+	groups, remaining := GroupPostdataStatements(statements)
+	var workerPool sync.WaitGroup
+	var fatalErr error
+	var numErrors int32
+	tasks := make(chan []utils.StatementWithType, len(groups))
+	for _, group := range groups {
+		tasks <- group
 	}
-	return firstBatch, secondBatch
+	close(tasks)
+
+	executeInParallel := connectionPool.NumConns > 1
+	for i := 0; i < connectionPool.NumConns; i++ {
+		workerPool.Add(1)
+		go func(connNum int) {
+			defer workerPool.Done()
+			connNum = connectionPool.ValidateConnNum(connNum)
+			for group := range tasks {
+				remainingGroup, numSkipped := RemoveCompletedStatements(group)
+				for i := 0; i < numSkipped; i++ {
+					progressBar.Increment()
+				}
+				groupStatements := make(chan utils.StatementWithType, len(remainingGroup))
+				for _, statement := range remainingGroup {
+					groupStatements <- statement
+				}
+				close(groupStatements)
+				executeStatementsForConn(groupStatements, &fatalErr, &numErrors, progressBar, connNum, executeInParallel)
+			}
+		}(i)
+	}
+	workerPool.Wait()
+	reportStatementErrors(fatalErr, numErrors)
+
+	ExecuteStatements(remaining, progressBar, false)
 }
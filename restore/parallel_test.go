@@ -9,29 +9,31 @@ import (
 )
 
 var _ = Describe("restore/parallel tests", func() {
-	Describe("BatchPostdataStatements", func() {
-		index1 := utils.StatementWithType{ObjectType: "INDEX", ReferenceObject: "public.table1", Statement: `CREATE INDEX testindex ON public.testtable USING btree(i);`}
-		index2 := utils.StatementWithType{ObjectType: "INDEX", ReferenceObject: "public.table2", Statement: `CREATE INDEX testindex ON public.testtable USING btree(i);`}
-		index3 := utils.StatementWithType{ObjectType: "INDEX", ReferenceObject: "public.table3", Statement: `CREATE INDEX testindex ON public.testtable USING btree(i);`}
-		trigger := utils.StatementWithType{ObjectType: "TRIGGER", ReferenceObject: "public.table3", Statement: `CREATE INDEX testindex ON public.testtable USING btree(i);`}
-		It("places all indexes in first batch when all are on different tables", func() {
-			statements := []utils.StatementWithType{index1, index2, index3}
-			firstBatch, secondBatch := restore.BatchPostdataStatements(statements)
-			Expect(firstBatch).To(Equal([]utils.StatementWithType{index1, index2, index3}))
-			Expect(secondBatch).To(Equal([]utils.StatementWithType{}))
+	Describe("GroupPostdataStatements", func() {
+		index1 := utils.StatementWithType{ObjectType: "INDEX", ReferenceObject: "public.table1", Statement: `CREATE INDEX index1 ON public.table1 USING btree(i);`}
+		index2 := utils.StatementWithType{ObjectType: "INDEX", ReferenceObject: "public.table2", Statement: `CREATE INDEX index2 ON public.table2 USING btree(i);`}
+		index3 := utils.StatementWithType{ObjectType: "INDEX", ReferenceObject: "public.table1", Statement: `CREATE INDEX index3 ON public.table1 USING btree(j);`}
+		trigger := utils.StatementWithType{ObjectType: "TRIGGER", ReferenceObject: "public.table2", Statement: `CREATE TRIGGER trigger1 AFTER INSERT ON public.table2 FOR EACH STATEMENT EXECUTE PROCEDURE public.func1();`}
+		primaryKey := utils.StatementWithType{ObjectType: "CONSTRAINT", ReferenceObject: "public.table2", Statement: `ALTER TABLE ONLY public.table2 ADD CONSTRAINT table2_pkey PRIMARY KEY (i);`}
+		foreignKey := utils.StatementWithType{ObjectType: "CONSTRAINT", ReferenceObject: "public.table1", Statement: `ALTER TABLE ONLY public.table1 ADD CONSTRAINT table1_i_fkey FOREIGN KEY (i) REFERENCES public.table2(i);`}
+		eventTrigger := utils.StatementWithType{ObjectType: "EVENT TRIGGER", Statement: `CREATE EVENT TRIGGER event_trigger1 ON ddl_command_start EXECUTE PROCEDURE public.func2();`}
+		It("groups statements by the relation they apply to, in order", func() {
+			statements := []utils.StatementWithType{index1, index2, index3, trigger}
+			groups, remaining := restore.GroupPostdataStatements(statements)
+			Expect(groups).To(Equal([][]utils.StatementWithType{{index1, index3}, {index2, trigger}}))
+			Expect(remaining).To(BeEmpty())
 		})
-		It("places first index for a table in first batch, and other indexes for that table in second", func() {
-			statements := []utils.StatementWithType{index1, index2, index2, index2, index3, index3}
-			firstBatch, secondBatch := restore.BatchPostdataStatements(statements)
-			Expect(firstBatch).To(Equal([]utils.StatementWithType{index1, index2, index3}))
-			Expect(secondBatch).To(Equal([]utils.StatementWithType{index2, index2, index3}))
+		It("restores foreign key constraints after all groups", func() {
+			statements := []utils.StatementWithType{foreignKey, index1, primaryKey}
+			groups, remaining := restore.GroupPostdataStatements(statements)
+			Expect(groups).To(Equal([][]utils.StatementWithType{{index1}, {primaryKey}}))
+			Expect(remaining).To(Equal([]utils.StatementWithType{foreignKey}))
 		})
-		It("places non-index objects in second batch", func() {
-			statements := []utils.StatementWithType{index1, index1, trigger}
-			firstBatch, secondBatch := restore.BatchPostdataStatements(statements)
-			Expect(firstBatch).To(Equal([]utils.StatementWithType{index1}))
-			Expect(secondBatch).To(Equal([]utils.StatementWithType{index1, trigger}))
+		It("restores statements not tied to a relation after all groups", func() {
+			statements := []utils.StatementWithType{eventTrigger, index1, foreignKey}
+			groups, remaining := restore.GroupPostdataStatements(statements)
+			Expect(groups).To(Equal([][]utils.StatementWithType{{index1}}))
+			Expect(remaining).To(Equal([]utils.StatementWithType{eventTrigger, foreignKey}))
 		})
-
 	})
 })
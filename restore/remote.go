@@ -8,6 +8,7 @@ import (
 	"github.com/greenplum-db/gp-common-go-libs/cluster"
 	"github.com/greenplum-db/gp-common-go-libs/gplog"
 	"github.com/greenplum-db/gp-common-go-libs/iohelper"
+	"github.com/greenplum-db/gpbackup/backup_filepath"
 	"github.com/greenplum-db/gpbackup/utils"
 	"github.com/pkg/errors"
 )
@@ -29,9 +30,11 @@ func VerifyBackupDirectoriesExistOnAllHosts() {
 	}
 }
 
+// The in-progress and completion markers are not data files, so they are not counted
 func VerifyBackupFileCountOnSegments(fileCount int) {
 	remoteOutput := globalCluster.GenerateAndExecuteCommand("Verifying backup file count", func(contentID int) string {
-		return fmt.Sprintf("find %s -type f | wc -l", globalFPInfo.GetDirForContent(contentID))
+		return fmt.Sprintf("find %s -type f ! -name 'gpbackup_*_%s' ! -name 'gpbackup_*_%s' | wc -l", globalFPInfo.GetDirForContent(contentID),
+			backup_filepath.MARKER_COMPLETE, backup_filepath.MARKER_IN_PROGRESS)
 	}, cluster.ON_SEGMENTS)
 	globalCluster.CheckClusterError(remoteOutput, "Could not verify backup file count", func(contentID int) string {
 		return "Could not verify backup file count"
@@ -50,7 +53,7 @@ func VerifyBackupFileCountOnSegments(fileCount int) {
 	}
 }
 
-func VerifyMetadataFilePaths(withStats bool) {
+func VerifyMetadataFilePaths(withStats bool, withLargeObjects bool) {
 	filetypes := []string{"config", "table of contents", "metadata"}
 	missing := false
 	for _, filetype := range filetypes {
@@ -68,6 +71,14 @@ func VerifyMetadataFilePaths(withStats bool) {
 			gplog.Error(`Note that the "-with-stats" flag must be passed to gpbackup to generate a statistics file.`)
 		}
 	}
+	if withLargeObjects {
+		filepath := globalFPInfo.GetLargeObjectsFilePath()
+		if !iohelper.FileExistsAndIsReadable(filepath) {
+			missing = true
+			gplog.Error("Cannot access large objects file %s", filepath)
+			gplog.Error(`Note that the "--with-large-objects" flag must be passed to gpbackup to generate a large objects file.`)
+		}
+	}
 	if missing {
 		gplog.Fatal(errors.Errorf("One or more metadata files do not exist or are not readable."), "Cannot proceed with restore")
 	}
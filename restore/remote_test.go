@@ -2,6 +2,7 @@ package restore_test
 
 import (
 	"os/user"
+	"strings"
 
 	"github.com/greenplum-db/gp-common-go-libs/cluster"
 	"github.com/greenplum-db/gp-common-go-libs/operating"
@@ -43,6 +44,21 @@ var _ = Describe("restore/remote tests", func() {
 			restore.VerifyBackupFileCountOnSegments(2)
 			Expect((*testExecutor).NumExecutions).To(Equal(1))
 		})
+		It("does not count the backup markers as backup files", func() {
+			testExecutor.ClusterOutput = &cluster.RemoteOutput{
+				Stdouts: map[int]string{
+					0: "2",
+					1: "2",
+				},
+			}
+			testCluster.Executor = testExecutor
+			restore.SetCluster(testCluster)
+			restore.VerifyBackupFileCountOnSegments(2)
+
+			Expect(testExecutor.NumExecutions).To(Equal(1))
+			command := strings.Join(testExecutor.ClusterCommands[0][0], " ")
+			Expect(command).To(ContainSubstring("find /data/gpseg0/backups/20170101/20170101010101 -type f ! -name 'gpbackup_*_complete' ! -name 'gpbackup_*_in_progress' | wc -l"))
+		})
 		It("panics if backup file counts do not match on all segments", func() {
 			testExecutor.ClusterOutput = &cluster.RemoteOutput{
 				Stdouts: map[int]string{
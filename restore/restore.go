@@ -9,6 +9,7 @@ import (
 
 	"github.com/greenplum-db/gp-common-go-libs/cluster"
 	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/iohelper"
 	"github.com/greenplum-db/gp-common-go-libs/operating"
 	"github.com/greenplum-db/gpbackup/backup_filepath"
 	"github.com/greenplum-db/gpbackup/backup_history"
@@ -30,27 +31,63 @@ func initializeFlags(cmd *cobra.Command) {
 	cmdFlags = cmd.Flags()
 }
 func SetFlagDefaults(flagSet *pflag.FlagSet) {
+	flagSet.Bool(utils.ALLOW_FILTERED_RESTORE, false, "Allow restoring a backup taken with filters, which does not contain the whole database, into an empty database")
 	flagSet.String(utils.BACKUP_DIR, "", "The absolute path of the directory in which the backup files to be restored are located")
+	flagSet.Int(utils.COPY_BUFFER_SIZE, 0, "The size in kilobytes of the buffers gpbackup_helper uses to stream table data from a single data file backup. 0 uses the default size.")
 	flagSet.Bool(utils.CREATE_DB, false, "Create the database before metadata restore")
 	flagSet.Bool(utils.DATA_ONLY, false, "Only restore data, do not restore metadata")
+	flagSet.String(utils.DATA_TIMESTAMP, "", "Restore table data from the backup with this timestamp instead, while restoring metadata from the backup given by --timestamp")
 	flagSet.Bool(utils.DEBUG, false, "Print verbose and debug log messages")
 	flagSet.StringSlice(utils.EXCLUDE_SCHEMA, []string{}, "Restore all metadata except objects in the specified schema(s). --exclude-schema can be specified multiple times.")
+	flagSet.String(utils.EXCLUDE_SCHEMA_FILE, "", "A file containing a list of schemas that will not be restored")
 	flagSet.StringSlice(utils.EXCLUDE_RELATION, []string{}, "Restore all metadata except the specified relation(s). --exclude-table can be specified multiple times.")
 	flagSet.String(utils.EXCLUDE_RELATION_FILE, "", "A file containing a list of fully-qualified relation(s) that will not be restored")
+	flagSet.String(utils.CONFIG, "", "A YAML configuration file whose notifiers are sent a restore_success or restore_failure event")
+	flagSet.Bool(utils.CASCADE, false, "With --clean, also drop any objects depending on the objects being restored")
+	flagSet.Bool(utils.CLEAN, false, "Drop the objects being restored from the database before restoring them")
+	flagSet.String(utils.DATA_FILTER_FILE, "", "A file containing a list of fully-qualified tables and the conditions their restored rows must satisfy, in the format schema.table:condition, one per line.  Rows of those tables not satisfying the condition are not restored.")
+	flagSet.String(utils.DEFERRED_INDEX_FILE, "", "Do not restore indexes, instead writing their statements to the specified file so they can be run after the restore")
+	flagSet.Bool(utils.IF_EXISTS, false, "With --clean, use DROP ... IF EXISTS so that objects missing from the database are skipped")
+	flagSet.Bool(utils.FAST_LOAD, false, "Load table data faster by using COPY FREEZE for heap tables created by the restore, raising maintenance_work_mem, and disabling automatic statistics collection while data is restored")
 	flagSet.Bool("help", false, "Help for gprestore")
 	flagSet.StringSlice(utils.INCLUDE_SCHEMA, []string{}, "Restore only the specified schema(s). --include-schema can be specified multiple times.")
+	flagSet.String(utils.INCLUDE_SCHEMA_FILE, "", "A file containing a list of schemas that will be restored")
 	flagSet.StringSlice(utils.INCLUDE_RELATION, []string{}, "Restore only the specified relation(s). --include-table can be specified multiple times.")
 	flagSet.String(utils.INCLUDE_RELATION_FILE, "", "A file containing a list of fully-qualified relation(s) that will be restored")
+	flagSet.StringSlice(utils.INCLUDE_RESOURCE_QUEUE, []string{}, "Restore only the specified resource queue(s) and resource group(s) from global metadata. --include-resource-queue can be specified multiple times.")
+	flagSet.StringSlice(utils.INCLUDE_ROLE, []string{}, "Restore only the specified role(s) from global metadata. --include-role can be specified multiple times.")
+	flagSet.StringSlice(utils.INCLUDE_TABLESPACE, []string{}, "Restore only the specified tablespace(s) from global metadata. --include-tablespace can be specified multiple times.")
+	flagSet.String(utils.METADATA_ENCRYPTION_KEY_FILE, "", "A file containing the passphrase with which the backup's metadata was encrypted")
 	flagSet.Bool(utils.METADATA_ONLY, false, "Only restore metadata, do not restore data")
 	flagSet.Int(utils.JOBS, 1, "Number of parallel connections to use when restoring table data and post-data")
+	flagSet.StringArray(utils.LOCATION_MAP, []string{}, "Restore external tables with LOCATION URIs beginning with old to begin with new instead, in the format old=new.  If old does not contain ://, it is matched against the host, or host and port, of each URI.  --location-map can be specified multiple times.")
+	flagSet.String(utils.LOCATION_MAP_FILE, "", "A file containing a list of external table location mappings in the format old=new, one per line")
+	flagSet.Bool(utils.NO_MATVIEW_REFRESH, false, "Do not refresh materialized views after restoring data, leaving them unpopulated")
+	flagSet.Bool(utils.NO_OWNER, false, "Do not restore ALTER ... OWNER TO statements, so that objects are owned by the restoring role")
+	flagSet.Bool(utils.NO_PRIVILEGES, false, "Do not restore GRANT and REVOKE statements for object privileges")
 	flagSet.Bool(utils.ON_ERROR_CONTINUE, false, "Log errors and continue restore, instead of exiting on first error")
+	flagSet.StringSlice(utils.OWNER_MAP, []string{}, "Restore objects owned by or granted to role old as role new instead, in the format old:new. --owner-map can be specified multiple times.")
 	flagSet.String(utils.PLUGIN_CONFIG, "", "The configuration file to use for a plugin")
+	flagSet.StringSlice(utils.POSTDATA_OBJECT_TYPE, []string{}, "With --postdata-only, restore only post-data objects of the specified type(s): constraint, default privileges, event trigger, index, policy, row level security, rule, statistics, or trigger. --postdata-object-type can be specified multiple times.")
+	flagSet.Bool(utils.POSTDATA_ONLY, false, "Only restore post-data metadata (indexes, constraints, rules, and triggers) into a database whose tables and data are already loaded")
 	flagSet.Bool("version", false, "Print version number and exit")
 	flagSet.Bool(utils.QUIET, false, "Suppress non-warning, non-error log messages")
 	flagSet.String(utils.REDIRECT_DB, "", "Restore to the specified database instead of the database that was backed up")
+	flagSet.Bool(utils.RESUME, false, "Resume a failed restore of the same backup, skipping metadata and table data that were already restored")
+	flagSet.String(utils.REDIRECT_SCHEMA, "", "Restore to the specified schema instead of the schema that was backed up")
+	flagSet.StringArray(utils.RESTORE_GUC, []string{}, "Set a configuration parameter on each restore connection, in the format [metadata:|data:]name=value.  A parameter prefixed with metadata: or data: is only set while restoring metadata or table data, respectively.  --restore-guc can be specified multiple times.")
+	flagSet.Bool(utils.SINGLE_TRANSACTION, false, "Restore metadata in a single transaction, so that a failed restore leaves the database unchanged.  Every restored object stays locked until the transaction commits, and each lock uses shared lock table memory, so max_locks_per_transaction may need to be raised for large schemas.  Requires --metadata-only or --postdata-only.")
+	flagSet.Bool(utils.SKIP_INDEXES, false, "Do not restore indexes, so that restored tables can be used before any index is built")
+	flagSet.Int(utils.SMALL_TABLE_BATCH_SIZE, 1, "The number of tables with fewer than 10000 backed up rows to schedule together on one connection, each still copied with its own COPY statement")
+	flagSet.StringArray(utils.TABLESPACE_MAP, []string{}, "Restore objects in tablespace old into tablespace new instead, in the format old:new. --tablespace-map can be specified multiple times.")
+	flagSet.String(utils.TABLESPACE_MAP_FILE, "", "A file containing a list of tablespace mappings in the format old:new, one per line")
 	flagSet.Bool(utils.WITH_GLOBALS, false, "Restore global metadata")
 	flagSet.String(utils.TIMESTAMP, "", "The timestamp to be restored, in the format YYYYMMDDHHMMSS")
+	flagSet.Bool(utils.TRUNCATE_TARGET, false, "With a data-only restore, truncate each table before restoring its data instead of adding to the rows already in it")
 	flagSet.Bool(utils.VERBOSE, false, "Print verbose log messages")
+	flagSet.Bool(utils.VERIFY_ROW_COUNTS, false, "After restoring, check that each restored table has as many rows as were backed up")
+	flagSet.Int(utils.VERIFY_SAMPLE_SIZE, 0, "Check the row counts of only this many randomly chosen restored tables.  0 checks every restored table.")
+	flagSet.Bool(utils.WITH_LARGE_OBJECTS, false, "Restore large objects, if they were backed up with --with-large-objects")
 	flagSet.Bool(utils.WITH_STATS, false, "Restore query plan statistics")
 }
 
@@ -71,25 +108,43 @@ func DoValidation(cmd *cobra.Command) {
 	ValidateFlagCombinations(cmd.Flags())
 	err := utils.ValidateFullPath(MustGetFlagString(utils.BACKUP_DIR))
 	gplog.FatalOnError(err)
+	err = utils.ValidateFullPath(MustGetFlagString(utils.CONFIG))
+	gplog.FatalOnError(err)
 	err = utils.ValidateFullPath(MustGetFlagString(utils.PLUGIN_CONFIG))
 	gplog.FatalOnError(err)
+	err = utils.ValidateFullPath(MustGetFlagString(utils.DEFERRED_INDEX_FILE))
+	gplog.FatalOnError(err)
+	err = utils.ValidateFullPath(MustGetFlagString(utils.DATA_FILTER_FILE))
+	gplog.FatalOnError(err)
 	if !backup_filepath.IsValidTimestamp(MustGetFlagString(utils.TIMESTAMP)) {
 		gplog.Fatal(errors.Errorf("Timestamp %s is invalid.  Timestamps must be in the format YYYYMMDDHHMMSS.", MustGetFlagString(utils.TIMESTAMP)), "")
 	}
+	if dataTimestamp := MustGetFlagString(utils.DATA_TIMESTAMP); dataTimestamp != "" && !backup_filepath.IsValidTimestamp(dataTimestamp) {
+		gplog.Fatal(errors.Errorf("Timestamp %s is invalid.  Timestamps must be in the format YYYYMMDDHHMMSS.", dataTimestamp), "")
+	}
 }
 
 // This function handles setup that must be done after parsing flags.
 func DoSetup() {
 	SetLoggerVerbosity()
 	gplog.Verbose("Restore Command: %s", os.Args)
+	var err error
+	notifications, err = utils.ReadNotifications(MustGetFlagString(utils.CONFIG))
+	gplog.FatalOnError(err)
 
 	utils.CheckGpexpandRunning(utils.RestorePreventedByGpexpandMessage)
 	restoreStartTime = backup_history.CurrentTimestamp()
 	gplog.Info("Restore Key = %s", MustGetFlagString(utils.TIMESTAMP))
+	if keyFile := MustGetFlagString(utils.METADATA_ENCRYPTION_KEY_FILE); keyFile != "" {
+		key, err := utils.ReadMetadataEncryptionKeyFile(keyFile)
+		gplog.FatalOnError(err)
+		utils.SetMetadataEncryptionKey(key)
+	}
 
 	CreateConnectionPool("postgres")
 	segConfig := cluster.MustGetSegmentConfiguration(connectionPool)
 	globalCluster = cluster.NewCluster(segConfig)
+	notifications.AddNotifier(utils.ReportEmailNotifier{Cluster: globalCluster}, utils.EVENT_RESTORE_SUCCESS, utils.EVENT_RESTORE_FAILURE)
 	segPrefix := backup_filepath.ParseSegPrefix(MustGetFlagString(utils.BACKUP_DIR), MustGetFlagString(utils.TIMESTAMP))
 	globalFPInfo = backup_filepath.NewFilePathInfo(globalCluster, MustGetFlagString(utils.BACKUP_DIR), MustGetFlagString(utils.TIMESTAMP), segPrefix)
 
@@ -99,8 +154,12 @@ func DoSetup() {
 	} else {
 		InitializeBackupConfig()
 	}
+	if backupConfig.MetadataEncrypted && utils.GetMetadataEncryptionKey() == nil {
+		gplog.Fatal(errors.Errorf("Backup %s has encrypted metadata; specify its key with --%s", MustGetFlagString(utils.TIMESTAMP), utils.METADATA_ENCRYPTION_KEY_FILE), "")
+	}
 
 	BackupConfigurationValidation()
+	InitializeRestoreState(globalFPInfo.GetRestoreStateFilePath(), MustGetFlagBool(utils.RESUME))
 	metadataFilename := globalFPInfo.GetMetadataFilePath()
 	if !backupConfig.DataOnly {
 		gplog.Verbose("Metadata will be restored from %s", metadataFilename)
@@ -109,7 +168,21 @@ func DoSetup() {
 	if MustGetFlagString(utils.REDIRECT_DB) != "" {
 		unquotedRestoreDatabase = MustGetFlagString(utils.REDIRECT_DB)
 	}
-	ValidateDatabaseExistence(unquotedRestoreDatabase, MustGetFlagBool(utils.CREATE_DB), backupConfig.IncludeTableFiltered || backupConfig.DataOnly)
+	ValidateDatabaseExistence(unquotedRestoreDatabase, MustGetFlagBool(utils.CREATE_DB), backupConfig.IncludeTableFiltered || backupConfig.DataOnly || MustGetFlagBool(utils.POSTDATA_ONLY))
+	if MustGetFlagBool(utils.CREATE_DB) {
+		ValidateFilteredBackupRestore(true)
+	}
+	ownerMap = GetNameMap(MustGetFlagStringSlice(utils.OWNER_MAP), "owner")
+	tablespaceMap = GetNameMap(MustGetFlagStringArray(utils.TABLESPACE_MAP), "tablespace")
+	locationMap = GetLocationMap(MustGetFlagStringArray(utils.LOCATION_MAP))
+	restoreGUCs = ParseRestoreGUCs(MustGetFlagStringArray(utils.RESTORE_GUC))
+	if MustGetFlagBool(utils.FAST_LOAD) {
+		restoreGUCs = AddFastLoadGUCs(restoreGUCs)
+	}
+	if dataFilterFile := MustGetFlagString(utils.DATA_FILTER_FILE); dataFilterFile != "" {
+		dataFilters = utils.ParseDataFilters(iohelper.MustReadLinesFromFile(dataFilterFile))
+	}
+	ValidateRestoreTarget(metadataFilename, backupConfig.DataOnly || MustGetFlagBool(utils.DATA_ONLY), backupConfig.MetadataOnly || MustGetFlagBool(utils.METADATA_ONLY) || MustGetFlagBool(utils.POSTDATA_ONLY))
 	if MustGetFlagBool(utils.WITH_GLOBALS) {
 		restoreGlobal(metadataFilename)
 	} else if MustGetFlagBool(utils.CREATE_DB) {
@@ -119,14 +192,23 @@ func DoSetup() {
 		connectionPool.Close()
 	}
 	InitializeConnectionPool(unquotedRestoreDatabase)
+	if !MustGetFlagBool(utils.CREATE_DB) {
+		ValidateFilteredBackupRestore(IsDatabaseEmpty(connectionPool))
+	}
+
+	if MustGetFlagString(utils.REDIRECT_SCHEMA) != "" {
+		ValidateRedirectSchema(connectionPool, MustGetFlagString(utils.REDIRECT_SCHEMA))
+		redirectSchema = utils.QuoteIdent(connectionPool, MustGetFlagString(utils.REDIRECT_SCHEMA))
+	}
 
 	/*
 	 * We don't need to validate anything if we're creating the database; we
 	 * should not error out for validation reasons once the restore database exists.
 	 * For on-error-continue, we will see the same errors later when we try to run SQL,
 	 * but since they will not stop the restore, it is not necessary to log them twice.
+	 * When resuming or cleaning, some of the relations are expected to exist already.
 	 */
-	if !MustGetFlagBool(utils.CREATE_DB) && !MustGetFlagBool(utils.ON_ERROR_CONTINUE) {
+	if !MustGetFlagBool(utils.CREATE_DB) && !MustGetFlagBool(utils.ON_ERROR_CONTINUE) && !MustGetFlagBool(utils.RESUME) && !MustGetFlagBool(utils.CLEAN) {
 		relationsToRestore := GenerateRestoreRelationList()
 		ValidateRelationsInRestoreDatabase(connectionPool, relationsToRestore)
 	}
@@ -134,31 +216,70 @@ func DoSetup() {
 
 func DoRestore() {
 	gucStatements := setGUCsForConnection(nil, 0)
+	saveOriginalGUCValues()
 	metadataFilename := globalFPInfo.GetMetadataFilePath()
 	isDataOnly := backupConfig.DataOnly || MustGetFlagBool(utils.DATA_ONLY)
-	isMetadataOnly := backupConfig.MetadataOnly || MustGetFlagBool(utils.METADATA_ONLY)
-	if !isDataOnly {
+	isPostdataOnly := MustGetFlagBool(utils.POSTDATA_ONLY)
+	isMetadataOnly := backupConfig.MetadataOnly || MustGetFlagBool(utils.METADATA_ONLY) || isPostdataOnly
+	/*
+	 * A single transaction is only used when no table data is restored, as
+	 * data is restored over several connections, and all statements are run
+	 * on the first connection, which --single-transaction limits the pool to.
+	 */
+	isSingleTransaction := MustGetFlagBool(utils.SINGLE_TRANSACTION)
+	if isSingleTransaction {
+		gplog.Info("Restoring metadata in a single transaction")
+		connectionPool.MustBegin(0)
+	}
+	if !isDataOnly && !isPostdataOnly {
+		setRestoreGUCsForPhase(PHASE_METADATA)
+		if MustGetFlagBool(utils.CLEAN) {
+			dropExistingObjects(metadataFilename)
+		}
 		restorePredata(metadataFilename)
 	}
 
+	var fpInfoList []backup_filepath.FilePathInfo
+	var restoredDataEntries [][]utils.MasterDataEntry
+
 	if !isMetadataOnly {
-		if MustGetFlagString(utils.PLUGIN_CONFIG) == "" {
+		if MustGetFlagString(utils.PLUGIN_CONFIG) == "" && MustGetFlagString(utils.DATA_TIMESTAMP) == "" {
 			backupFileCount := 2 // 1 for the actual data file, 1 for the segment TOC file
 			if !backupConfig.SingleDataFile {
 				backupFileCount = len(globalTOC.DataEntries)
 			}
 			VerifyBackupFileCountOnSegments(backupFileCount)
 		}
-		restoreData(GetBackupFPInfoListFromRestorePlan(), gucStatements)
+		fpInfoList = GetBackupFPInfoListFromRestorePlan()
+		restoredDataEntries = restoreData(fpInfoList, gucStatements)
 	}
 
 	if !isDataOnly {
+		setRestoreGUCsForPhase(PHASE_METADATA)
 		restorePostdata(metadataFilename)
 	}
 
+	if isSingleTransaction && !wasTerminated {
+		connectionPool.MustCommit(0)
+	}
+
+	if !isMetadataOnly && !MustGetFlagBool(utils.NO_MATVIEW_REFRESH) {
+		refreshMaterializedViews(metadataFilename)
+	}
+
+	if MustGetFlagBool(utils.WITH_LARGE_OBJECTS) {
+		restoreLargeObjects()
+	}
+
 	if MustGetFlagBool(utils.WITH_STATS) && backupConfig.WithStatistics {
-		restoreStatistics()
+		restoreStatistics(isMetadataOnly)
+	}
+
+	if MustGetFlagBool(utils.VERIFY_ROW_COUNTS) && !isMetadataOnly {
+		VerifyRestoredRowCounts(fpInfoList, restoredDataEntries)
 	}
+
+	resetRestoreGUCs()
 }
 
 func createDatabase(metadataFilename string) {
@@ -171,6 +292,7 @@ func createDatabase(metadataFilename string) {
 		dbName = quotedDBName
 		statements = utils.SubstituteRedirectDatabaseInStatements(statements, backupConfig.DatabaseName, quotedDBName)
 	}
+	statements = utils.SubstituteTablespacesInStatements(statements, tablespaceMap)
 	ExecuteRestoreMetadataStatements(statements, "", nil, utils.PB_NONE, false)
 	gplog.Info("Database creation complete for: %s", dbName)
 }
@@ -187,10 +309,25 @@ func restoreGlobal(metadataFilename string) {
 		statements = utils.SubstituteRedirectDatabaseInStatements(statements, backupConfig.DatabaseName, quotedDBName)
 	}
 	statements = utils.RemoveActiveRole(connectionPool.User, statements)
+	statements = utils.SubstituteTablespacesInStatements(statements, tablespaceMap)
+	statements = filterGlobalStatements(statements, utils.INCLUDE_ROLE, "ROLE", "ROLE GUCS", "ROLE GRANT")
+	statements = filterGlobalStatements(statements, utils.INCLUDE_RESOURCE_QUEUE, "RESOURCE QUEUE", "RESOURCE GROUP")
+	statements = filterGlobalStatements(statements, utils.INCLUDE_TABLESPACE, "TABLESPACE")
 	ExecuteRestoreMetadataStatements(statements, "Global objects", nil, utils.PB_VERBOSE, false)
 	gplog.Info("Global database metadata restore complete")
 }
 
+func filterGlobalStatements(statements []utils.StatementWithType, flagName string, objectTypes ...string) []utils.StatementWithType {
+	if !cmdFlags.Changed(flagName) {
+		return statements
+	}
+	quotedNames := make([]string, 0)
+	for _, name := range MustGetFlagStringSlice(flagName) {
+		quotedNames = append(quotedNames, utils.QuoteIdent(connectionPool, name))
+	}
+	return utils.FilterGlobalStatements(statements, objectTypes, quotedNames)
+}
+
 func restorePredata(metadataFilename string) {
 	if wasTerminated {
 		return
@@ -200,6 +337,16 @@ func restorePredata(metadataFilename string) {
 	schemaStatements := GetRestoreMetadataStatements("predata", metadataFilename, []string{"SCHEMA"}, []string{}, true, true)
 	statements := GetRestoreMetadataStatements("predata", metadataFilename, []string{}, []string{"SCHEMA"}, true, true)
 
+	/*
+	 * When redirecting to another schema, that schema must already exist, so
+	 * the original schemas are not created.
+	 */
+	if redirectSchema != "" {
+		schemaStatements = []utils.StatementWithType{}
+		statements = utils.SubstituteRedirectSchemaInStatements(statements, redirectSchema)
+	}
+	statements = transformMetadataStatements(statements)
+
 	progressBar := utils.NewProgressBar(len(schemaStatements)+len(statements), "Pre-data objects restored: ", utils.PB_VERBOSE)
 	progressBar.Start()
 
@@ -214,11 +361,41 @@ func restorePredata(metadataFilename string) {
 	}
 }
 
-func restoreData(fpInfoList []backup_filepath.FilePathInfo, gucStatements []utils.StatementWithType) {
+/*
+ * Applies the tablespace, owner, and location mappings and removes ownership and
+ * privilege statements if requested, before predata or postdata statements
+ * are executed or validated.
+ */
+func transformMetadataStatements(statements []utils.StatementWithType) []utils.StatementWithType {
+	statements = utils.SubstituteTablespacesInStatements(statements, tablespaceMap)
+	statements = utils.SubstituteRolesInStatements(statements, ownerMap)
+	statements = utils.SubstituteLocationsInStatements(statements, locationMap)
+	if MustGetFlagBool(utils.NO_OWNER) {
+		statements = utils.RemoveOwnerStatements(statements)
+	}
+	if MustGetFlagBool(utils.NO_PRIVILEGES) {
+		statements = utils.RemovePrivilegeStatements(statements)
+	}
+	return statements
+}
+
+/*
+ * Returns the data entries restored from each backup in fpInfoList, so that
+ * the row counts of the restored tables can be verified.
+ */
+func restoreData(fpInfoList []backup_filepath.FilePathInfo, gucStatements []utils.StatementWithType) [][]utils.MasterDataEntry {
 	if wasTerminated {
-		return
+		return nil
 	}
 	latestRestorePlan := backupConfig.RestorePlan
+	var tableColumns map[string]map[string]bool
+	if MustGetFlagString(utils.DATA_TIMESTAMP) != "" {
+		tableColumns = GetRestoredTableColumns(connectionPool)
+	}
+	var externalTables map[string]bool
+	if backupConfig.WithExternalData {
+		externalTables = GetExternalTableNames(connectionPool)
+	}
 
 	totalTables := 0
 	filteredDataEntries := make([][]utils.MasterDataEntry, 0)
@@ -229,16 +406,45 @@ func restoreData(fpInfoList []backup_filepath.FilePathInfo, gucStatements []util
 		filteredDataEntriesForTimestamp := toc.GetDataEntriesMatching(MustGetFlagStringSlice(utils.INCLUDE_SCHEMA),
 			MustGetFlagStringSlice(utils.EXCLUDE_SCHEMA), MustGetFlagStringSlice(utils.INCLUDE_RELATION),
 			MustGetFlagStringSlice(utils.EXCLUDE_RELATION), restorePlanTableFQNs)
+		filteredDataEntriesForTimestamp = RemoveCompletedDataEntries(fpInfo.Timestamp, filteredDataEntriesForTimestamp)
+		if tableColumns != nil {
+			filteredDataEntriesForTimestamp = RemoveUncomposableDataEntries(filteredDataEntriesForTimestamp, tableColumns)
+		}
+		if externalTables != nil {
+			filteredDataEntriesForTimestamp = RemoveExternalTableDataEntries(filteredDataEntriesForTimestamp, externalTables)
+		}
 		filteredDataEntries = append(filteredDataEntries, filteredDataEntriesForTimestamp)
 
 		totalTables += len(filteredDataEntriesForTimestamp)
 	}
+	/*
+	 * Tables are only truncated for COPY FREEZE when they were created by this
+	 * restore, as a data-only restore loads data into existing tables.
+	 */
+	var freezableTables map[string]bool
+	isDataOnly := backupConfig.DataOnly || MustGetFlagBool(utils.DATA_ONLY)
+	if MustGetFlagBool(utils.FAST_LOAD) && !isDataOnly && connectionPool.Version.AtLeast("6") {
+		freezableTables = GetFreezableTables(connectionPool)
+	}
+	if MustGetFlagBool(utils.TRUNCATE_TARGET) {
+		tableNames := make([]string, 0, totalTables)
+		for _, dataEntries := range filteredDataEntries {
+			for _, entry := range dataEntries {
+				tableName := utils.MakeFQN(entry.Schema, entry.Name)
+				if redirectSchema != "" {
+					tableName = utils.MakeFQN(redirectSchema, entry.Name)
+				}
+				tableNames = append(tableNames, tableName)
+			}
+		}
+		TruncateTables(connectionPool, tableNames)
+	}
 	dataProgressBar := utils.NewProgressBar(totalTables, "Tables restored: ", utils.PB_INFO)
 	dataProgressBar.Start()
 
 	for i, fpInfo := range fpInfoList {
 		gplog.Verbose("Restoring data from backup with timestamp: %s", fpInfo.Timestamp)
-		restoreDataFromTimestamp(fpInfo, filteredDataEntries[i], gucStatements, dataProgressBar)
+		restoreDataFromTimestamp(fpInfo, filteredDataEntries[i], gucStatements, freezableTables, dataProgressBar)
 	}
 
 	dataProgressBar.Finish()
@@ -247,6 +453,7 @@ func restoreData(fpInfoList []backup_filepath.FilePathInfo, gucStatements []util
 	} else {
 		gplog.Info("Data restore complete")
 	}
+	return filteredDataEntries
 }
 
 func restorePostdata(metadataFilename string) {
@@ -254,12 +461,22 @@ func restorePostdata(metadataFilename string) {
 		return
 	}
 	gplog.Info("Restoring post-data metadata")
-	statements := GetRestoreMetadataStatements("postdata", metadataFilename, []string{}, []string{}, true, true)
-	firstBatch, secondBatch := BatchPostdataStatements(statements)
+	objectTypes := GetPostdataObjectTypes(MustGetFlagStringSlice(utils.POSTDATA_OBJECT_TYPE))
+	statements := GetRestoreMetadataStatements("postdata", metadataFilename, objectTypes, []string{}, true, true)
+	if redirectSchema != "" {
+		statements = utils.SubstituteRedirectSchemaInStatements(statements, redirectSchema)
+	}
+	statements = transformMetadataStatements(statements)
+	if deferredIndexFile := MustGetFlagString(utils.DEFERRED_INDEX_FILE); deferredIndexFile != "" {
+		var indexStatements []utils.StatementWithType
+		indexStatements, statements = SeparateIndexStatements(statements)
+		writeDeferredIndexFile(deferredIndexFile, indexStatements)
+	} else if MustGetFlagBool(utils.SKIP_INDEXES) {
+		_, statements = SeparateIndexStatements(statements)
+	}
 	progressBar := utils.NewProgressBar(len(statements), "Post-data objects restored: ", utils.PB_VERBOSE)
 	progressBar.Start()
-	ExecuteRestoreMetadataStatements(firstBatch, "", progressBar, utils.PB_VERBOSE, connectionPool.NumConns > 1)
-	ExecuteRestoreMetadataStatements(secondBatch, "", progressBar, utils.PB_VERBOSE, connectionPool.NumConns > 1)
+	ExecutePostdataStatements(statements, progressBar)
 	progressBar.Finish()
 	if wasTerminated {
 		gplog.Info("Post-data metadata restore incomplete")
@@ -268,26 +485,61 @@ func restorePostdata(metadataFilename string) {
 	}
 }
 
-func restoreStatistics() {
+/*
+ * Extended statistics are rebuilt by analyzing their tables, which is only
+ * done when the data of those tables has been restored.
+ */
+func restoreStatistics(isMetadataOnly bool) {
 	if wasTerminated {
 		return
 	}
 	statisticsFilename := globalFPInfo.GetStatisticsFilePath()
 	gplog.Info("Restoring query planner statistics from %s", statisticsFilename)
-	statements := GetRestoreMetadataStatements("statistics", statisticsFilename, []string{}, []string{}, true, true)
+	excludeObjectTypes := []string{}
+	if isMetadataOnly {
+		excludeObjectTypes = append(excludeObjectTypes, "EXTENDED STATISTICS")
+	}
+	statements := GetRestoreMetadataStatements("statistics", statisticsFilename, []string{}, excludeObjectTypes, true, true)
+	if redirectSchema != "" {
+		statements = utils.SubstituteRedirectSchemaInStatements(statements, redirectSchema)
+	}
 	ExecuteRestoreMetadataStatements(statements, "Table statistics", nil, utils.PB_VERBOSE, false)
 	gplog.Info("Query planner statistics restore complete")
 }
 
+/*
+ * Large objects are restored in the order they were backed up, on a single
+ * connection, as each one must be created before its data is written.
+ */
+func restoreLargeObjects() {
+	if wasTerminated {
+		return
+	}
+	largeObjectsFilename := globalFPInfo.GetLargeObjectsFilePath()
+	gplog.Info("Restoring large objects from %s", largeObjectsFilename)
+	statements := GetRestoreMetadataStatements("largeobjects", largeObjectsFilename, []string{}, []string{}, false, false)
+	ExecuteRestoreMetadataStatements(statements, "Large objects", nil, utils.PB_VERBOSE, false)
+	if wasTerminated {
+		gplog.Info("Large object restore incomplete")
+	} else {
+		gplog.Info("Large object restore complete")
+	}
+}
+
 func DoTeardown() {
 	restoreFailed := false
+	errMsg, reportFile := "", ""
 	defer func() {
+		if !wasTerminated {
+			notifyRestoreCompletion(errMsg, reportFile, restoreFailed, false)
+		}
 		DoCleanup(restoreFailed)
 
-		errorCode := gplog.GetErrorCode()
+		errorCode := utils.ExitCodeFromErrorCode(gplog.GetErrorCode())
 		if errorCode == 0 {
 			gplog.Info("Restore completed successfully")
 		}
+		gplog.Info("Restore exit code %d: %s", errorCode, utils.ExitCodeDescription(errorCode))
 		os.Exit(errorCode)
 
 	}()
@@ -300,6 +552,10 @@ func DoTeardown() {
 			gplog.SetErrorCode(2)
 		} else {
 			errStr = fmt.Sprintf("%v", err)
+			// gplog keeps its own code for a fatal error that has no more specific cause
+			if exitCode := utils.ClassifyFatalError(errStr); exitCode != utils.EXIT_FATAL {
+				gplog.SetErrorCode(exitCode)
+			}
 		}
 		restoreFailed = true
 	}
@@ -317,7 +573,7 @@ func DoTeardown() {
 	if errStr != "" {
 		fmt.Println(errStr)
 	}
-	errMsg := utils.ParseErrorMessage(errStr)
+	errMsg = utils.ParseErrorMessage(errStr)
 
 	if globalFPInfo.Timestamp != "" {
 		_, statErr := os.Stat(globalFPInfo.GetDirForContent(-1))
@@ -326,7 +582,7 @@ func DoTeardown() {
 		}
 		reportFilename := globalFPInfo.GetRestoreReportFilePath(restoreStartTime)
 		utils.WriteRestoreReportFile(reportFilename, globalFPInfo.Timestamp, restoreStartTime, connectionPool, version, errMsg)
-		utils.EmailReport(globalCluster, globalFPInfo.Timestamp, reportFilename, "gprestore")
+		reportFile = reportFilename
 		if pluginConfig != nil {
 			pluginConfig.CleanupPluginForRestore(globalCluster, globalFPInfo)
 			pluginConfig.DeletePluginConfigWhenEncrypting(globalCluster)
@@ -342,6 +598,36 @@ func DoTeardown() {
 	}
 }
 
+/*
+ * As with gpbackup, a restore that ends in a panic without a fatal error has
+ * no error message, so the event is chosen by whether the restore failed and
+ * by its exit code.
+ */
+func notifyRestoreCompletion(errMsg string, reportFile string, failed bool, aborted bool) {
+	event, status := utils.EVENT_RESTORE_SUCCESS, "Success"
+	errorCode := utils.ExitCodeFromErrorCode(gplog.GetErrorCode())
+	if aborted {
+		event, status = utils.EVENT_RESTORE_FAILURE, "Aborted"
+	} else if failed || errorCode != 0 {
+		event, status = utils.EVENT_RESTORE_FAILURE, "Failure"
+		if errMsg == "" {
+			errMsg = fmt.Sprintf("Restore exit code %d: %s", errorCode, utils.ExitCodeDescription(errorCode))
+		}
+	}
+	database := MustGetFlagString(utils.REDIRECT_DB)
+	if database == "" && backupConfig != nil {
+		database = utils.UnquoteIdent(backupConfig.DatabaseName)
+	}
+	notifications.Notify(event, utils.NotificationPayload{
+		Utility:    "gprestore",
+		Timestamp:  MustGetFlagString(utils.TIMESTAMP),
+		Database:   database,
+		Status:     status,
+		Message:    errMsg,
+		ReportFile: reportFile,
+	})
+}
+
 func writeErrorTables(isMetadata bool) {
 	var errorTables *map[string]Empty
 	var errorFilename string
@@ -398,6 +684,13 @@ func DoCleanup(restoreFailed bool) {
 		}
 	}
 
+	if globalFPInfo.Timestamp != "" {
+		FinalizeRestoreState(globalFPInfo.GetRestoreStateFilePath(), !restoreFailed && len(errorTablesMetadata) == 0 && len(errorTablesData) == 0)
+	}
+	if wasTerminated {
+		notifyRestoreCompletion("Restore was terminated before it completed", "", true, true)
+	}
+
 	if connectionPool != nil {
 		connectionPool.Close()
 	}
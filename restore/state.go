@@ -0,0 +1,113 @@
+package restore
+
+/*
+ * This file contains functions for tracking which metadata statements and
+ * tables of data have been restored in a state file, so that a failed restore
+ * can be resumed with --resume without repeating work that already succeeded.
+ */
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/iohelper"
+	"github.com/greenplum-db/gpbackup/utils"
+)
+
+var (
+	completedItems  map[string]bool
+	restoreState    io.WriteCloser
+	restoreStateMux = &sync.Mutex{}
+)
+
+func InitializeRestoreState(filename string, resume bool) {
+	completedItems = make(map[string]bool)
+	if resume && iohelper.FileExistsAndIsReadable(filename) {
+		for _, key := range iohelper.MustReadLinesFromFile(filename) {
+			completedItems[key] = true
+		}
+		gplog.Info("Resuming restore; %d previously restored items will be skipped", len(completedItems))
+	} else if resume {
+		gplog.Warn("No restore state file found at %s; restoring everything", filename)
+	} else {
+		_ = os.Remove(filename)
+	}
+	restoreState = iohelper.MustOpenFileForAppending(filename)
+}
+
+/*
+ * The state file is kept after a failed restore or a restore with errors, so
+ * that it can be resumed, and removed once everything has been restored.
+ */
+func FinalizeRestoreState(filename string, restoreSucceeded bool) {
+	if restoreState == nil {
+		return
+	}
+	_ = restoreState.Close()
+	restoreState = nil
+	if restoreSucceeded {
+		_ = os.Remove(filename)
+	} else {
+		gplog.Info("Restore progress was saved to %s; run gprestore again with --%s to resume", filename, utils.RESUME)
+	}
+}
+
+/*
+ * Statements are identified by their contents rather than their position, so
+ * that a resumed restore skips the same statements even if their order
+ * differs.  Session GUCs are never skipped, as they must be set on every
+ * connection.
+ */
+func statementKey(statement utils.StatementWithType) string {
+	if statement.ObjectType == "SESSION GUCS" {
+		return ""
+	}
+	return fmt.Sprintf("METADATA %s %x", statement.ObjectType, md5.Sum([]byte(statement.Statement)))
+}
+
+func dataEntryKey(timestamp string, entry utils.MasterDataEntry) string {
+	return fmt.Sprintf("DATA %s %s", timestamp, utils.MakeFQN(entry.Schema, entry.Name))
+}
+
+func isCompleted(key string) bool {
+	return key != "" && completedItems[key]
+}
+
+func recordCompleted(key string) {
+	if key == "" || restoreState == nil {
+		return
+	}
+	restoreStateMux.Lock()
+	defer restoreStateMux.Unlock()
+	_, err := fmt.Fprintln(restoreState, key)
+	if err != nil {
+		gplog.Warn("Unable to record restore progress: %v", err)
+	}
+}
+
+func RemoveCompletedStatements(statements []utils.StatementWithType) ([]utils.StatementWithType, int) {
+	remaining := make([]utils.StatementWithType, 0, len(statements))
+	for _, statement := range statements {
+		if !isCompleted(statementKey(statement)) {
+			remaining = append(remaining, statement)
+		}
+	}
+	return remaining, len(statements) - len(remaining)
+}
+
+func RemoveCompletedDataEntries(timestamp string, entries []utils.MasterDataEntry) []utils.MasterDataEntry {
+	remaining := make([]utils.MasterDataEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !isCompleted(dataEntryKey(timestamp, entry)) {
+			remaining = append(remaining, entry)
+		}
+	}
+	if numSkipped := len(entries) - len(remaining); numSkipped > 0 {
+		gplog.Verbose("Skipping data for %d tables restored from backup %s by a previous restore", numSkipped, timestamp)
+	}
+	return remaining
+}
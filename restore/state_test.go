@@ -0,0 +1,72 @@
+package restore_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/greenplum-db/gpbackup/restore"
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("restore/state tests", func() {
+	var stateDir, stateFile string
+	table1 := utils.StatementWithType{Schema: "public", Name: "foo", ObjectType: "TABLE", Statement: "CREATE TABLE public.foo (i int);"}
+	table2 := utils.StatementWithType{Schema: "public", Name: "bar", ObjectType: "TABLE", Statement: "CREATE TABLE public.bar (i int);"}
+	guc := utils.StatementWithType{ObjectType: "SESSION GUCS", Statement: "SET client_encoding = 'UTF8';"}
+	entry1 := utils.MasterDataEntry{Schema: "public", Name: "foo"}
+	entry2 := utils.MasterDataEntry{Schema: "public", Name: "bar"}
+	BeforeEach(func() {
+		var err error
+		stateDir, err = ioutil.TempDir("", "restore_state")
+		Expect(err).ToNot(HaveOccurred())
+		stateFile = filepath.Join(stateDir, "gprestore_20170101010101_restore_state")
+	})
+	AfterEach(func() {
+		restore.FinalizeRestoreState(stateFile, true)
+		_ = os.RemoveAll(stateDir)
+	})
+	It("skips nothing when not resuming", func() {
+		Expect(ioutil.WriteFile(stateFile, []byte("DATA 20170101010101 public.foo\n"), 0644)).To(Succeed())
+		restore.InitializeRestoreState(stateFile, false)
+
+		statements, numSkipped := restore.RemoveCompletedStatements([]utils.StatementWithType{table1, table2})
+		Expect(statements).To(Equal([]utils.StatementWithType{table1, table2}))
+		Expect(numSkipped).To(Equal(0))
+		Expect(restore.RemoveCompletedDataEntries("20170101010101", []utils.MasterDataEntry{entry1, entry2})).To(Equal([]utils.MasterDataEntry{entry1, entry2}))
+	})
+	It("skips statements and data recorded by a previous restore when resuming", func() {
+		restore.InitializeRestoreState(stateFile, false)
+		mock.ExpectExec("CREATE TABLE public.foo").WillReturnResult(sqlmock.NewResult(0, 0))
+		restore.ExecuteStatementsAndCreateProgressBar([]utils.StatementWithType{table1}, "", utils.PB_NONE, false)
+		restore.FinalizeRestoreState(stateFile, false)
+		Expect(ioutil.WriteFile(stateFile, append(readFile(stateFile), []byte("DATA 20170101010101 public.foo\n")...), 0644)).To(Succeed())
+
+		restore.InitializeRestoreState(stateFile, true)
+
+		statements, numSkipped := restore.RemoveCompletedStatements([]utils.StatementWithType{guc, table1, table2})
+		Expect(statements).To(Equal([]utils.StatementWithType{guc, table2}))
+		Expect(numSkipped).To(Equal(1))
+		Expect(restore.RemoveCompletedDataEntries("20170101010101", []utils.MasterDataEntry{entry1, entry2})).To(Equal([]utils.MasterDataEntry{entry2}))
+		Expect(restore.RemoveCompletedDataEntries("20180101010101", []utils.MasterDataEntry{entry1, entry2})).To(Equal([]utils.MasterDataEntry{entry1, entry2}))
+	})
+	It("removes the state file only if the restore succeeded", func() {
+		restore.InitializeRestoreState(stateFile, false)
+		restore.FinalizeRestoreState(stateFile, false)
+		Expect(stateFile).To(BeAnExistingFile())
+
+		restore.InitializeRestoreState(stateFile, true)
+		restore.FinalizeRestoreState(stateFile, true)
+		Expect(stateFile).ToNot(BeAnExistingFile())
+	})
+})
+
+func readFile(filename string) []byte {
+	contents, err := ioutil.ReadFile(filename)
+	Expect(err).ToNot(HaveOccurred())
+	return contents
+}
@@ -2,6 +2,8 @@ package restore
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -79,7 +81,7 @@ func getFilterSchemasInBackupSet(schemaList []string) []string {
 func GenerateRestoreRelationList() []string {
 	includeRelations := MustGetFlagStringSlice(utils.INCLUDE_RELATION)
 	if len(includeRelations) > 0 {
-		return includeRelations
+		return redirectRelationList(includeRelations)
 	}
 
 	relationList := make([]string, 0)
@@ -99,7 +101,27 @@ func GenerateRestoreRelationList() []string {
 			relationList = append(relationList, fqn)
 		}
 	}
-	return relationList
+	return redirectRelationList(relationList)
+}
+
+// When redirecting to a new schema, relations are validated against the schema they will be restored into
+func redirectRelationList(relationList []string) []string {
+	if redirectSchema == "" {
+		return relationList
+	}
+	redirectedList := make([]string, 0, len(relationList))
+	for _, fqn := range relationList {
+		redirectedList = append(redirectedList, utils.MakeFQN(redirectSchema, fqn[strings.Index(fqn, ".")+1:]))
+	}
+	return redirectedList
+}
+
+func ValidateRedirectSchema(connectionPool *dbconn.DBConn, schema string) {
+	query := fmt.Sprintf("SELECT nspname AS string FROM pg_namespace WHERE nspname = '%s'", utils.EscapeSingleQuotes(schema))
+	resultSchemas := dbconn.MustSelectStringSlice(connectionPool, query)
+	if len(resultSchemas) == 0 {
+		gplog.Fatal(errors.Errorf("Schema %s to redirect into does not exist", schema), "")
+	}
 }
 func ValidateRelationsInRestoreDatabase(connectionPool *dbconn.DBConn, relationList []string) {
 	if len(relationList) == 0 {
@@ -119,17 +141,25 @@ WHERE quote_ident(n.nspname) || '.' || quote_ident(c.relname) IN (%s)`, quotedTa
 	 * For data-only we check that the relations we are planning to restore
 	 * are already defined in the database so we have somewhere to put the data.
 	 *
+	 * Postdata-only restores apply indexes and the like to relations loaded by
+	 * other means, so those relations must also already be defined.
+	 *
 	 * For non-data-only we check that the relations we are planning to restore
 	 * are not already in the database so we don't get duplicate data.
 	 */
 	var errMsg string
-	if backupConfig.DataOnly || MustGetFlagBool(utils.DATA_ONLY) {
+	isDataOnly := backupConfig.DataOnly || MustGetFlagBool(utils.DATA_ONLY)
+	if isDataOnly || MustGetFlagBool(utils.POSTDATA_ONLY) {
+		restoreType := "data-only"
+		if !isDataOnly {
+			restoreType = "postdata-only"
+		}
 		if len(relationsInDB) < len(relationList) {
 			dbRelationsSet := utils.NewSet(relationsInDB)
 			for _, restoreRelation := range relationList {
 				matches := dbRelationsSet.MatchesFilter(restoreRelation)
 				if !matches {
-					errMsg = fmt.Sprintf("Relation %s must exist for data-only restore", restoreRelation)
+					errMsg = fmt.Sprintf("Relation %s must exist for %s restore", restoreRelation, restoreType)
 				}
 			}
 		}
@@ -215,6 +245,168 @@ END AS string;`, utils.EscapeSingleQuotes(unquotedDBName))
 	}
 }
 
+func IsDatabaseEmpty(connectionPool *dbconn.DBConn) bool {
+	query := `
+SELECT CASE
+	WHEN EXISTS (SELECT 1 FROM pg_class c JOIN pg_namespace n ON c.relnamespace = n.oid
+		WHERE n.nspname NOT LIKE 'pg_%' AND n.nspname NOT IN ('information_schema', 'gp_toolkit')) THEN 'false'
+	ELSE 'true'
+END AS string;`
+	isEmpty, err := strconv.ParseBool(dbconn.MustSelectString(connectionPool, query))
+	gplog.FatalOnError(err)
+	return isEmpty
+}
+
+/*
+ * Restoring a filtered backup into an empty database produces a database that
+ * looks complete but is missing everything the filters left out, so doing so
+ * must be acknowledged.  Restoring into a database that already has other
+ * objects is assumed to be deliberate.
+ */
+func ValidateFilteredBackupRestore(isEmptyDatabase bool) {
+	if !isEmptyDatabase || !backupConfig.IsFiltered() || MustGetFlagBool(utils.ALLOW_FILTERED_RESTORE) {
+		return
+	}
+	gplog.Fatal(errors.Errorf("Backup %s was taken with the filters %s and does not contain the whole database.  Use --%s to restore it into an empty database.",
+		backupConfig.Timestamp, strings.Join(backupConfig.GetFilters(), ", "), utils.ALLOW_FILTERED_RESTORE), "")
+}
+
+var (
+	ownerPattern      = regexp.MustCompile(`(?m)^ALTER .+ OWNER TO ([^;]+);$`)
+	granteePattern    = regexp.MustCompile(`(?m)^(?:ALTER DEFAULT PRIVILEGES .+ )?GRANT .+ TO ([^;]+?)(?: WITH GRANT OPTION)?;$`)
+	tablespacePattern = regexp.MustCompile(`\bTABLESPACE ([^\s;]+)`)
+	extVersionPattern = regexp.MustCompile(`(?m)^CREATE EXTENSION .+ (VERSION '(?:[^']|'')*');$`)
+)
+
+/*
+ * Checks that the target cluster has every role, tablespace, and extension
+ * referenced by the metadata being restored, and the same number of segments
+ * as the backup when restoring data, so that everything missing can be
+ * reported together before any statements are executed.
+ */
+func ValidateRestoreTarget(metadataFilename string, isDataOnly bool, isMetadataOnly bool) {
+	problems := make([]string, 0)
+	if !isDataOnly {
+		statements := make([]utils.StatementWithType, 0)
+		if !MustGetFlagBool(utils.POSTDATA_ONLY) {
+			statements = GetRestoreMetadataStatements("predata", metadataFilename, []string{}, []string{}, true, true)
+		}
+		objectTypes := GetPostdataObjectTypes(MustGetFlagStringSlice(utils.POSTDATA_OBJECT_TYPE))
+		statements = append(statements, GetRestoreMetadataStatements("postdata", metadataFilename, objectTypes, []string{}, true, true)...)
+
+		statements = transformMetadataStatements(statements)
+
+		existingRoles := dbconn.MustSelectStringSlice(connectionPool, "SELECT quote_ident(rolname) AS string FROM pg_roles")
+		existingTablespaces := dbconn.MustSelectStringSlice(connectionPool, "SELECT quote_ident(spcname) AS string FROM pg_tablespace")
+		if MustGetFlagBool(utils.WITH_GLOBALS) {
+			globalStatements := GetRestoreMetadataStatements("global", metadataFilename, []string{"ROLE", "TABLESPACE"}, []string{}, false, false)
+			globalStatements = filterGlobalStatements(globalStatements, utils.INCLUDE_ROLE, "ROLE")
+			globalStatements = filterGlobalStatements(globalStatements, utils.INCLUDE_TABLESPACE, "TABLESPACE")
+			for _, statement := range globalStatements {
+				if statement.ObjectType == "ROLE" {
+					existingRoles = append(existingRoles, statement.Name)
+				} else {
+					existingTablespaces = append(existingTablespaces, statement.Name)
+				}
+			}
+		}
+		if missingRoles := GetMissingNames(GetReferencedRoles(statements), existingRoles); len(missingRoles) > 0 {
+			problems = append(problems, fmt.Sprintf("Roles: %s", strings.Join(missingRoles, ", ")))
+		}
+		if missingTablespaces := GetMissingNames(GetReferencedTablespaces(statements), existingTablespaces); len(missingTablespaces) > 0 {
+			problems = append(problems, fmt.Sprintf("Tablespaces: %s", strings.Join(missingTablespaces, ", ")))
+		}
+		if connectionPool.Version.AtLeast("5") {
+			existingExtensions := dbconn.MustSelectStringSlice(connectionPool, "SELECT quote_ident(name) AS string FROM pg_available_extensions")
+			existingExtensions = append(existingExtensions, dbconn.MustSelectStringSlice(connectionPool, "SELECT quote_ident(name) || ' VERSION ' || quote_literal(version) AS string FROM pg_available_extension_versions")...)
+			if missingExtensions := GetMissingNames(GetReferencedExtensions(statements), existingExtensions); len(missingExtensions) > 0 {
+				problems = append(problems, fmt.Sprintf("Extensions: %s", strings.Join(missingExtensions, ", ")))
+			}
+		}
+	}
+	numSegments := len(globalCluster.ContentIDs) - 1
+	if !isMetadataOnly && backupConfig.SegmentCount > 0 && backupConfig.SegmentCount != numSegments {
+		problems = append(problems, fmt.Sprintf("Segments: backup was taken on %d segments, but the target cluster has %d", backupConfig.SegmentCount, numSegments))
+	}
+
+	if len(problems) == 0 {
+		return
+	}
+	message := fmt.Sprintf("The target cluster is missing objects required by the backup:\n\t%s", strings.Join(problems, "\n\t"))
+	if MustGetFlagBool(utils.ON_ERROR_CONTINUE) {
+		gplog.Warn(message)
+	} else {
+		gplog.Fatal(errors.New(message), "")
+	}
+}
+
+func GetReferencedRoles(statements []utils.StatementWithType) []string {
+	roles := make([]string, 0)
+	for _, statement := range statements {
+		for _, match := range ownerPattern.FindAllStringSubmatch(statement.Statement, -1) {
+			roles = append(roles, match[1])
+		}
+		for _, match := range granteePattern.FindAllStringSubmatch(statement.Statement, -1) {
+			for _, grantee := range strings.Split(match[1], ", ") {
+				if grantee != "PUBLIC" {
+					roles = append(roles, grantee)
+				}
+			}
+		}
+	}
+	return roles
+}
+
+func GetReferencedTablespaces(statements []utils.StatementWithType) []string {
+	tablespaces := make([]string, 0)
+	for _, statement := range statements {
+		if statement.ObjectType != "TABLE" && statement.ObjectType != "MATERIALIZED VIEW" && statement.ObjectType != "INDEX" {
+			continue
+		}
+		for _, match := range tablespacePattern.FindAllStringSubmatch(statement.Statement, -1) {
+			tablespaces = append(tablespaces, match[1])
+		}
+	}
+	return tablespaces
+}
+
+/*
+ * Extensions backed up with their version are returned as "<name> VERSION
+ * '<version>'", so that the version itself must be available on the target.
+ */
+func GetReferencedExtensions(statements []utils.StatementWithType) []string {
+	extensions := make([]string, 0)
+	for _, statement := range statements {
+		if statement.ObjectType != "EXTENSION" {
+			continue
+		}
+		if match := extVersionPattern.FindStringSubmatch(statement.Statement); match != nil {
+			extensions = append(extensions, fmt.Sprintf("%s %s", statement.Name, match[1]))
+		} else {
+			extensions = append(extensions, statement.Name)
+		}
+	}
+	return extensions
+}
+
+/*
+ * Returns the sorted, de-duplicated names in requiredNames that are not in
+ * existingNames.
+ */
+func GetMissingNames(requiredNames []string, existingNames []string) []string {
+	existingSet := utils.NewSet(existingNames)
+	missingSet := make(map[string]bool)
+	missingNames := make([]string, 0)
+	for _, name := range requiredNames {
+		if !existingSet.MatchesFilter(name) && !missingSet[name] {
+			missingSet[name] = true
+			missingNames = append(missingNames, name)
+		}
+	}
+	sort.Strings(missingNames)
+	return missingNames
+}
+
 func ValidateBackupFlagCombinations() {
 	if backupConfig.SingleDataFile && MustGetFlagInt(utils.JOBS) != 1 {
 		gplog.Fatal(errors.Errorf("Cannot use jobs flag when restoring backups with a single data file per segment."), "")
@@ -228,6 +420,18 @@ func ValidateBackupFlagCombinations() {
 	if backupConfig.DataOnly && MustGetFlagBool(utils.METADATA_ONLY) {
 		gplog.Fatal(errors.Errorf("Cannot use metadata-only flag when restoring data-only backup"), "")
 	}
+	if backupConfig.DataOnly && MustGetFlagBool(utils.POSTDATA_ONLY) {
+		gplog.Fatal(errors.Errorf("Cannot use postdata-only flag when restoring data-only backup"), "")
+	}
+	if MustGetFlagBool(utils.TRUNCATE_TARGET) && !(backupConfig.DataOnly || MustGetFlagBool(utils.DATA_ONLY)) {
+		gplog.Fatal(errors.Errorf("Cannot use truncate-target flag unless restoring data only; use it with --data-only or with a data-only backup"), "")
+	}
+	if MustGetFlagBool(utils.SINGLE_TRANSACTION) && !(backupConfig.MetadataOnly || MustGetFlagBool(utils.METADATA_ONLY) || MustGetFlagBool(utils.POSTDATA_ONLY)) {
+		gplog.Fatal(errors.Errorf("Cannot use single-transaction flag when restoring table data; use it with --metadata-only or --postdata-only"), "")
+	}
+	if backupConfig.ParquetExport && !(MustGetFlagBool(utils.METADATA_ONLY) || MustGetFlagBool(utils.POSTDATA_ONLY)) {
+		gplog.Fatal(errors.Errorf("Backup exported its table data as Parquet files, which cannot be restored; use --metadata-only to restore its metadata"), "")
+	}
 	validateBackupFlagPluginCombinations()
 }
 
@@ -243,9 +447,79 @@ func ValidateFlagCombinations(flags *pflag.FlagSet) {
 	utils.CheckExclusiveFlags(flags, utils.DATA_ONLY, utils.WITH_GLOBALS)
 	utils.CheckExclusiveFlags(flags, utils.DATA_ONLY, utils.CREATE_DB)
 	utils.CheckExclusiveFlags(flags, utils.DEBUG, utils.QUIET, utils.VERBOSE)
-	utils.CheckExclusiveFlags(flags, utils.INCLUDE_SCHEMA, utils.INCLUDE_RELATION, utils.INCLUDE_RELATION_FILE)
-	utils.CheckExclusiveFlags(flags, utils.EXCLUDE_SCHEMA, utils.INCLUDE_SCHEMA)
-	utils.CheckExclusiveFlags(flags, utils.EXCLUDE_SCHEMA, utils.EXCLUDE_RELATION, utils.INCLUDE_RELATION, utils.EXCLUDE_RELATION_FILE, utils.INCLUDE_RELATION_FILE)
+	utils.CheckExclusiveFlags(flags, utils.INCLUDE_SCHEMA, utils.INCLUDE_SCHEMA_FILE, utils.INCLUDE_RELATION, utils.INCLUDE_RELATION_FILE)
+	utils.CheckExclusiveFlags(flags, utils.EXCLUDE_SCHEMA, utils.EXCLUDE_SCHEMA_FILE, utils.INCLUDE_SCHEMA, utils.INCLUDE_SCHEMA_FILE)
+	utils.CheckExclusiveFlags(flags, utils.EXCLUDE_SCHEMA, utils.EXCLUDE_SCHEMA_FILE, utils.EXCLUDE_RELATION, utils.INCLUDE_RELATION, utils.EXCLUDE_RELATION_FILE, utils.INCLUDE_RELATION_FILE)
 	utils.CheckExclusiveFlags(flags, utils.METADATA_ONLY, utils.DATA_ONLY)
 	utils.CheckExclusiveFlags(flags, utils.PLUGIN_CONFIG, utils.BACKUP_DIR)
+	utils.CheckExclusiveFlags(flags, utils.DATA_TIMESTAMP, utils.METADATA_ONLY, utils.PLUGIN_CONFIG)
+	utils.CheckExclusiveFlags(flags, utils.REDIRECT_SCHEMA, utils.WITH_GLOBALS)
+	utils.CheckExclusiveFlags(flags, utils.TABLESPACE_MAP, utils.TABLESPACE_MAP_FILE, utils.DATA_ONLY)
+	utils.CheckExclusiveFlags(flags, utils.OWNER_MAP, utils.DATA_ONLY)
+	utils.CheckExclusiveFlags(flags, utils.LOCATION_MAP, utils.LOCATION_MAP_FILE, utils.DATA_ONLY)
+	utils.CheckExclusiveFlags(flags, utils.NO_OWNER, utils.DATA_ONLY)
+	utils.CheckExclusiveFlags(flags, utils.NO_PRIVILEGES, utils.DATA_ONLY)
+	utils.CheckExclusiveFlags(flags, utils.REDIRECT_SCHEMA, utils.CREATE_DB)
+	for _, globalFilterFlag := range []string{utils.INCLUDE_ROLE, utils.INCLUDE_RESOURCE_QUEUE, utils.INCLUDE_TABLESPACE} {
+		if flags.Changed(globalFilterFlag) && !flags.Changed(utils.WITH_GLOBALS) {
+			gplog.Fatal(errors.Errorf("Cannot use --%s without --with-globals", globalFilterFlag), "")
+		}
+	}
+	utils.CheckExclusiveFlags(flags, utils.VERIFY_ROW_COUNTS, utils.METADATA_ONLY)
+	utils.CheckExclusiveFlags(flags, utils.POSTDATA_ONLY, utils.DATA_ONLY, utils.METADATA_ONLY, utils.VERIFY_ROW_COUNTS)
+	utils.CheckExclusiveFlags(flags, utils.POSTDATA_ONLY, utils.CREATE_DB)
+	utils.CheckExclusiveFlags(flags, utils.POSTDATA_ONLY, utils.WITH_GLOBALS)
+	utils.CheckExclusiveFlags(flags, utils.WITH_LARGE_OBJECTS, utils.METADATA_ONLY, utils.POSTDATA_ONLY)
+	utils.CheckExclusiveFlags(flags, utils.SKIP_INDEXES, utils.DEFERRED_INDEX_FILE, utils.DATA_ONLY)
+	utils.CheckExclusiveFlags(flags, utils.FAST_LOAD, utils.METADATA_ONLY, utils.POSTDATA_ONLY)
+	utils.CheckExclusiveFlags(flags, utils.DATA_FILTER_FILE, utils.METADATA_ONLY, utils.POSTDATA_ONLY, utils.VERIFY_ROW_COUNTS)
+	utils.CheckExclusiveFlags(flags, utils.CLEAN, utils.DATA_ONLY, utils.POSTDATA_ONLY, utils.CREATE_DB, utils.RESUME)
+	for _, cleanFlag := range []string{utils.IF_EXISTS, utils.CASCADE} {
+		if flags.Changed(cleanFlag) && !flags.Changed(utils.CLEAN) {
+			gplog.Fatal(errors.Errorf("Cannot use --%s without --%s", cleanFlag, utils.CLEAN), "")
+		}
+	}
+	for _, singleTransactionFlag := range []string{utils.JOBS, utils.ON_ERROR_CONTINUE, utils.RESUME, utils.WITH_GLOBALS, utils.CREATE_DB} {
+		utils.CheckExclusiveFlags(flags, utils.SINGLE_TRANSACTION, singleTransactionFlag)
+	}
+	if flags.Changed(utils.POSTDATA_OBJECT_TYPE) {
+		if !flags.Changed(utils.POSTDATA_ONLY) {
+			gplog.Fatal(errors.Errorf("Cannot use --%s without --%s", utils.POSTDATA_OBJECT_TYPE, utils.POSTDATA_ONLY), "")
+		}
+		GetPostdataObjectTypes(MustGetFlagStringSlice(utils.POSTDATA_OBJECT_TYPE))
+	}
+	if flags.Changed(utils.VERIFY_SAMPLE_SIZE) && !flags.Changed(utils.VERIFY_ROW_COUNTS) {
+		gplog.Fatal(errors.Errorf("Cannot use --%s without --%s", utils.VERIFY_SAMPLE_SIZE, utils.VERIFY_ROW_COUNTS), "")
+	}
+	if MustGetFlagInt(utils.VERIFY_SAMPLE_SIZE) < 0 {
+		gplog.Fatal(errors.Errorf("--verify-sample-size must not be negative"), "")
+	}
+	if MustGetFlagInt(utils.SMALL_TABLE_BATCH_SIZE) < 1 {
+		gplog.Fatal(errors.Errorf("--small-table-batch-size must be at least 1"), "")
+	}
+	if MustGetFlagInt(utils.COPY_BUFFER_SIZE) < 0 {
+		gplog.Fatal(errors.Errorf("--copy-buffer-size must not be negative"), "")
+	}
+	if flags.Changed(utils.REDIRECT_SCHEMA) && !(flags.Changed(utils.INCLUDE_SCHEMA) || flags.Changed(utils.INCLUDE_SCHEMA_FILE) || flags.Changed(utils.INCLUDE_RELATION) || flags.Changed(utils.INCLUDE_RELATION_FILE)) {
+		gplog.Fatal(errors.Errorf("Cannot use --redirect-schema without --include-schema, --include-schema-file, --include-table, or --include-table-file"), "")
+	}
+}
+
+var postdataObjectTypes = []string{"CONSTRAINT", "DEFAULT PRIVILEGES", "EVENT TRIGGER", "INDEX", "POLICY", "ROW LEVEL SECURITY", "RULE", "STATISTICS", "TRIGGER"}
+
+/*
+ * Converts the object types passed to --postdata-object-type into the object
+ * types used in the postdata section of the TOC.  An empty list means that
+ * objects of every type are restored.
+ */
+func GetPostdataObjectTypes(typeNames []string) []string {
+	objectTypes := make([]string, 0)
+	for _, typeName := range typeNames {
+		objectType := strings.ToUpper(strings.TrimSpace(typeName))
+		if !utils.Exists(postdataObjectTypes, objectType) {
+			gplog.Fatal(errors.Errorf("Invalid post-data object type %s.  Valid types are: %s", typeName, strings.ToLower(strings.Join(postdataObjectTypes, ", "))), "")
+		}
+		objectTypes = append(objectTypes, objectType)
+	}
+	return objectTypes
 }
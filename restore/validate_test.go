@@ -129,6 +129,44 @@ var _ = Describe("restore/validate tests", func() {
 
 			Expect(resultRelations).To(ConsistOf(expectedRelations))
 		})
+		Context("redirect schema", func() {
+			BeforeEach(func() {
+				restore.SetRedirectSchema("s3")
+			})
+			AfterEach(func() {
+				restore.SetRedirectSchema("")
+			})
+			It("redirects included schema relations to the new schema", func() {
+				cmdFlags.Set(utils.INCLUDE_SCHEMA, "s1")
+				expectedRelations := []string{"s3.table1", "s3.table2"}
+
+				resultRelations := restore.GenerateRestoreRelationList()
+
+				Expect(resultRelations).To(ConsistOf(expectedRelations))
+			})
+			It("redirects include relations to the new schema", func() {
+				cmdFlags.Set(utils.INCLUDE_RELATION, "s1.table1,s2.table2")
+				expectedRelations := []string{"s3.table1", "s3.table2"}
+
+				resultRelations := restore.GenerateRestoreRelationList()
+
+				Expect(resultRelations).To(ConsistOf(expectedRelations))
+			})
+		})
+	})
+	Describe("ValidateRedirectSchema", func() {
+		It("passes if the schema exists in the restore database", func() {
+			schemaRows := sqlmock.NewRows([]string{"string"}).AddRow("s3")
+			mock.ExpectQuery("SELECT (.*)").WillReturnRows(schemaRows)
+
+			restore.ValidateRedirectSchema(connectionPool, "s3")
+		})
+		It("panics if the schema does not exist in the restore database", func() {
+			mock.ExpectQuery("SELECT (.*)").WillReturnRows(sqlmock.NewRows([]string{"string"}))
+
+			defer testhelper.ShouldPanicWithMessage("Schema s3 to redirect into does not exist")
+			restore.ValidateRedirectSchema(connectionPool, "s3")
+		})
 	})
 	Describe("ValidateRelationsInRestoreDatabase", func() {
 		BeforeEach(func() {
@@ -162,6 +200,26 @@ var _ = Describe("restore/validate tests", func() {
 				restore.ValidateRelationsInRestoreDatabase(connectionPool, filterList)
 			})
 		})
+		Context("postdata-only restore", func() {
+			BeforeEach(func() {
+				cmdFlags.Set(utils.POSTDATA_ONLY, "true")
+			})
+			It("panics if a table is missing from database", func() {
+				single_table_row := sqlmock.NewRows([]string{"string"}).
+					AddRow("public.table1")
+				mock.ExpectQuery("SELECT (.*)").WillReturnRows(single_table_row)
+				filterList = []string{"public.table1", "public.table2"}
+				defer testhelper.ShouldPanicWithMessage("Relation public.table2 must exist for postdata-only restore")
+				restore.ValidateRelationsInRestoreDatabase(connectionPool, filterList)
+			})
+			It("passes if all tables are present in database", func() {
+				two_table_rows := sqlmock.NewRows([]string{"string"}).
+					AddRow("public.table1").AddRow("public.table2")
+				mock.ExpectQuery("SELECT (.*)").WillReturnRows(two_table_rows)
+				filterList = []string{"public.table1", "public.table2"}
+				restore.ValidateRelationsInRestoreDatabase(connectionPool, filterList)
+			})
+		})
 		Context("restore includes metadata", func() {
 			It("passes if table is not present in database", func() {
 				no_table_rows := sqlmock.NewRows([]string{"string"})
@@ -187,6 +245,18 @@ var _ = Describe("restore/validate tests", func() {
 			})
 		})
 	})
+	Describe("GetPostdataObjectTypes", func() {
+		It("returns an empty list when no types are given", func() {
+			Expect(restore.GetPostdataObjectTypes([]string{})).To(BeEmpty())
+		})
+		It("converts type names to TOC object types", func() {
+			Expect(restore.GetPostdataObjectTypes([]string{"index", "Event Trigger"})).To(Equal([]string{"INDEX", "EVENT TRIGGER"}))
+		})
+		It("panics on an unknown type", func() {
+			defer testhelper.ShouldPanicWithMessage("Invalid post-data object type table.  Valid types are: constraint, default privileges, event trigger, index, policy, row level security, rule, statistics, trigger")
+			restore.GetPostdataObjectTypes([]string{"table"})
+		})
+	})
 	Describe("ValidateRelationsInBackupSet", func() {
 		var toc *utils.TOC
 		var backupfile *utils.FileWithByteCount
@@ -289,4 +359,76 @@ var _ = Describe("restore/validate tests", func() {
 			restore.ValidateDatabaseExistence("testdb", false, false)
 		})
 	})
+	Describe("ValidateFilteredBackupRestore", func() {
+		filteredConfig := backup_history.BackupConfig{Timestamp: "20190102030405", IncludeSchemas: []string{"public"}, IncludeSchemaFiltered: true}
+		AfterEach(func() {
+			cmdFlags.Set(utils.ALLOW_FILTERED_RESTORE, "false")
+		})
+		It("panics when restoring a filtered backup into an empty database", func() {
+			restore.SetBackupConfig(&filteredConfig)
+			defer testhelper.ShouldPanicWithMessage("Backup 20190102030405 was taken with the filters --include-schema public and does not contain the whole database.  Use --allow-filtered-restore to restore it into an empty database.")
+			restore.ValidateFilteredBackupRestore(true)
+		})
+		It("passes when restoring a filtered backup into an empty database with --allow-filtered-restore", func() {
+			restore.SetBackupConfig(&filteredConfig)
+			cmdFlags.Set(utils.ALLOW_FILTERED_RESTORE, "true")
+			restore.ValidateFilteredBackupRestore(true)
+		})
+		It("passes when restoring a filtered backup into a database that has other objects", func() {
+			restore.SetBackupConfig(&filteredConfig)
+			restore.ValidateFilteredBackupRestore(false)
+		})
+		It("passes when restoring a backup that only excluded internal artifacts", func() {
+			restore.SetBackupConfig(&backup_history.BackupConfig{ExcludeSchemas: []string{"gpexpand"}, ExcludedArtifacts: []string{"gpexpand"}})
+			restore.ValidateFilteredBackupRestore(true)
+		})
+	})
+	Describe("IsDatabaseEmpty", func() {
+		It("returns true when the database has no user relations", func() {
+			mock.ExpectQuery("SELECT CASE (.*) FROM pg_class").WillReturnRows(sqlmock.NewRows([]string{"string"}).AddRow("true"))
+			Expect(restore.IsDatabaseEmpty(connectionPool)).To(BeTrue())
+		})
+		It("returns false when the database has user relations", func() {
+			mock.ExpectQuery("SELECT CASE (.*) FROM pg_class").WillReturnRows(sqlmock.NewRows([]string{"string"}).AddRow("false"))
+			Expect(restore.IsDatabaseEmpty(connectionPool)).To(BeFalse())
+		})
+	})
+	Describe("Restore target validation helpers", func() {
+		tableStatement := utils.StatementWithType{ObjectType: "TABLE", Statement: `
+CREATE TABLE public.foo (
+	i integer
+) TABLESPACE ts1 DISTRIBUTED BY (i);
+
+ALTER TABLE public.foo OWNER TO owner1;
+
+REVOKE ALL ON TABLE public.foo FROM PUBLIC;
+GRANT SELECT ON TABLE public.foo TO PUBLIC;
+GRANT ALL ON TABLE public.foo TO grantee1 WITH GRANT OPTION;`}
+		indexStatement := utils.StatementWithType{ObjectType: "INDEX", Statement: `
+CREATE INDEX foo_idx ON public.foo USING btree (i);
+ALTER INDEX public.foo_idx SET TABLESPACE "Ts2";`}
+		functionStatement := utils.StatementWithType{ObjectType: "FUNCTION", Statement: `
+CREATE FUNCTION public.f() RETURNS void AS $$ SELECT 1 $$ LANGUAGE sql;
+
+ALTER FUNCTION public.f() OWNER TO owner1;
+
+ALTER DEFAULT PRIVILEGES FOR ROLE owner1 GRANT EXECUTE ON FUNCTIONS TO grantee2, grantee3;`}
+		extensionStatement := utils.StatementWithType{Name: "hstore", ObjectType: "EXTENSION", Statement: `
+CREATE EXTENSION IF NOT EXISTS hstore WITH SCHEMA public;`}
+		versionedExtensionStatement := utils.StatementWithType{Name: "postgis", ObjectType: "EXTENSION", Statement: `
+CREATE EXTENSION IF NOT EXISTS postgis WITH SCHEMA public VERSION '2.5.1';`}
+		statements := []utils.StatementWithType{tableStatement, indexStatement, functionStatement, extensionStatement, versionedExtensionStatement}
+		It("finds roles referenced as owners and grantees", func() {
+			Expect(restore.GetReferencedRoles(statements)).To(Equal([]string{"owner1", "grantee1", "owner1", "grantee2", "grantee3"}))
+		})
+		It("finds tablespaces referenced by tables and indexes", func() {
+			Expect(restore.GetReferencedTablespaces(statements)).To(Equal([]string{"ts1", `"Ts2"`}))
+		})
+		It("finds extensions to be created", func() {
+			Expect(restore.GetReferencedExtensions(statements)).To(Equal([]string{"hstore", "postgis VERSION '2.5.1'"}))
+		})
+		It("returns sorted, de-duplicated names that do not exist", func() {
+			Expect(restore.GetMissingNames([]string{"owner1", "grantee2", "grantee1", "owner1"}, []string{"grantee1"})).To(Equal([]string{"grantee2", "owner1"}))
+		})
+	})
 })
@@ -0,0 +1,132 @@
+package restore
+
+/*
+ * This file contains functions for checking, once a restore has finished,
+ * that each restored table has as many rows as were backed up.
+ */
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/iohelper"
+	"github.com/greenplum-db/gpbackup/backup_filepath"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/pkg/errors"
+)
+
+type RestoredTable struct {
+	Name         string
+	RowsBackedUp int64
+}
+
+/*
+ * Only the tables whose data was restored by this run are checked, so tables
+ * restored by an earlier run that this one resumed and tables that failed to
+ * restore with --on-error-continue, which are already reported, are skipped.
+ */
+func VerifyRestoredRowCounts(fpInfoList []backup_filepath.FilePathInfo, dataEntries [][]utils.MasterDataEntry) {
+	if wasTerminated {
+		return
+	}
+	tables := make([]RestoredTable, 0)
+	for i, fpInfo := range fpInfoList {
+		if i >= len(dataEntries) {
+			break
+		}
+		tables = append(tables, GetRestoredTables(fpInfo, dataEntries[i])...)
+	}
+	tables = SampleRestoredTables(tables, MustGetFlagInt(utils.VERIFY_SAMPLE_SIZE), rand.New(rand.NewSource(time.Now().UnixNano())))
+	gplog.Info("Verifying row counts of %d restored tables", len(tables))
+	mismatches := GetRowCountMismatches(tables)
+	for _, mismatch := range mismatches {
+		gplog.Error(mismatch)
+	}
+	if len(mismatches) > 0 {
+		gplog.Fatal(errors.Errorf("Row counts of %d restored tables do not match the backup", len(mismatches)), "")
+	}
+	gplog.Info("Row counts of restored tables match the backup")
+}
+
+/*
+ * The expected row counts are read from the backup's manifest, falling back
+ * to the table of contents for backups taken before row counts were recorded
+ * in the manifest.
+ */
+func GetRestoredTables(fpInfo backup_filepath.FilePathInfo, dataEntries []utils.MasterDataEntry) []RestoredTable {
+	var rowCounts map[string]int64
+	if manifestFilename := fpInfo.GetManifestFilePath(); iohelper.FileExistsAndIsReadable(manifestFilename) {
+		rowCounts = utils.NewManifest(manifestFilename).RowCounts
+	}
+	tables := make([]RestoredTable, 0, len(dataEntries))
+	for _, entry := range dataEntries {
+		tableName := utils.MakeFQN(entry.Schema, entry.Name)
+		rowsBackedUp, ok := rowCounts[tableName]
+		if !ok {
+			rowsBackedUp = entry.RowsCopied
+		}
+		if redirectSchema != "" {
+			tableName = utils.MakeFQN(redirectSchema, entry.Name)
+		}
+		if _, failed := errorTablesData[tableName]; failed {
+			continue
+		}
+		tables = append(tables, RestoredTable{Name: tableName, RowsBackedUp: rowsBackedUp})
+	}
+	return tables
+}
+
+// A sample size of 0, or one at least the number of tables, checks every table
+func SampleRestoredTables(tables []RestoredTable, sampleSize int, random *rand.Rand) []RestoredTable {
+	if sampleSize <= 0 || sampleSize >= len(tables) {
+		return tables
+	}
+	sample := make([]RestoredTable, 0, sampleSize)
+	for _, i := range random.Perm(len(tables))[:sampleSize] {
+		sample = append(sample, tables[i])
+	}
+	return sample
+}
+
+func GetRowCountMismatches(tables []RestoredTable) []string {
+	tasks := make(chan RestoredTable, len(tables))
+	for _, table := range tables {
+		tasks <- table
+	}
+	close(tasks)
+
+	mismatches := make([]string, 0)
+	var mutex sync.Mutex
+	var workerPool sync.WaitGroup
+	for i := 0; i < connectionPool.NumConns; i++ {
+		workerPool.Add(1)
+		go func(whichConn int) {
+			defer workerPool.Done()
+			for table := range tasks {
+				if wasTerminated {
+					return
+				}
+				var rowsRestored int64
+				err := connectionPool.Get(&rowsRestored, fmt.Sprintf("SELECT count(*) FROM %s", table.Name), whichConn)
+				mismatch := ""
+				if err != nil {
+					mismatch = fmt.Sprintf("Unable to count rows of table %s: %v", table.Name, err)
+				} else if rowsRestored != table.RowsBackedUp {
+					mismatch = fmt.Sprintf("Table %s has %d rows, but %d rows were backed up", table.Name, rowsRestored, table.RowsBackedUp)
+				}
+				if mismatch != "" {
+					mutex.Lock()
+					mismatches = append(mismatches, mismatch)
+					mutex.Unlock()
+				}
+			}
+		}(i)
+	}
+	workerPool.Wait()
+	sort.Strings(mismatches)
+	return mismatches
+}
@@ -0,0 +1,67 @@
+package restore_test
+
+import (
+	"math/rand"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/greenplum-db/gpbackup/backup_filepath"
+	"github.com/greenplum-db/gpbackup/restore"
+	"github.com/greenplum-db/gpbackup/testutils"
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("restore/verify tests", func() {
+	Describe("GetRestoredTables", func() {
+		fpInfo := backup_filepath.NewFilePathInfo(testutils.SetDefaultSegmentConfiguration(), "/tmp/nonexistent_backup_dir", "20170101010101", "gpseg")
+		entries := []utils.MasterDataEntry{
+			{Schema: "public", Name: "foo", Oid: 1, RowsCopied: 10},
+			{Schema: "public", Name: "bar", Oid: 2, RowsCopied: 20},
+		}
+		AfterEach(func() {
+			restore.SetRedirectSchema("")
+		})
+		It("uses the row counts in the table of contents when the backup has no manifest", func() {
+			Expect(restore.GetRestoredTables(fpInfo, entries)).To(Equal([]restore.RestoredTable{
+				{Name: "public.foo", RowsBackedUp: 10},
+				{Name: "public.bar", RowsBackedUp: 20},
+			}))
+		})
+		It("counts the rows of tables restored to a redirected schema", func() {
+			restore.SetRedirectSchema("other")
+
+			Expect(restore.GetRestoredTables(fpInfo, entries)).To(Equal([]restore.RestoredTable{
+				{Name: "other.foo", RowsBackedUp: 10},
+				{Name: "other.bar", RowsBackedUp: 20},
+			}))
+		})
+	})
+	Describe("SampleRestoredTables", func() {
+		tables := []restore.RestoredTable{{Name: "public.foo"}, {Name: "public.bar"}, {Name: "public.baz"}}
+		It("returns every table when the sample size is 0", func() {
+			Expect(restore.SampleRestoredTables(tables, 0, rand.New(rand.NewSource(1)))).To(Equal(tables))
+		})
+		It("returns every table when the sample size is larger than the number of tables", func() {
+			Expect(restore.SampleRestoredTables(tables, 5, rand.New(rand.NewSource(1)))).To(Equal(tables))
+		})
+		It("returns the given number of distinct tables", func() {
+			sample := restore.SampleRestoredTables(tables, 2, rand.New(rand.NewSource(1)))
+
+			Expect(sample).To(HaveLen(2))
+			Expect(sample[0]).ToNot(Equal(sample[1]))
+			Expect(tables).To(ContainElement(sample[0]))
+			Expect(tables).To(ContainElement(sample[1]))
+		})
+	})
+	Describe("GetRowCountMismatches", func() {
+		It("reports tables whose row counts differ from the backup", func() {
+			mock.ExpectQuery(`SELECT count\(\*\) FROM public.foo`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(10))
+			mock.ExpectQuery(`SELECT count\(\*\) FROM public.bar`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(15))
+			tables := []restore.RestoredTable{{Name: "public.foo", RowsBackedUp: 10}, {Name: "public.bar", RowsBackedUp: 20}}
+
+			Expect(restore.GetRowCountMismatches(tables)).To(Equal([]string{"Table public.bar has 15 rows, but 20 rows were backed up"}))
+		})
+	})
+})
@@ -3,6 +3,8 @@ package restore
 import (
 	"fmt"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/greenplum-db/gpbackup/backup_filepath"
 	"github.com/greenplum-db/gpbackup/backup_history"
 	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/pkg/errors"
 )
 
 /*
@@ -104,12 +107,29 @@ func SetMaxCsvLineLengthQuery(connectionPool *dbconn.DBConn) string {
 
 func InitializeBackupConfig() {
 	backupConfig = backup_history.ReadConfigFile(globalFPInfo.GetConfigFilePath())
+	copyFormat = utils.GetCopyFormat(backupConfig)
+	if !(backupConfig.MetadataOnly || MustGetFlagBool(utils.METADATA_ONLY) || MustGetFlagBool(utils.POSTDATA_ONLY)) {
+		utils.EnsureCopyFormatCompatibility(copyFormat, backupConfig.DatabaseVersion, connectionPool.Version)
+	}
 	utils.InitializePipeThroughParameters(backupConfig.Compressed, 0)
 	utils.EnsureBackupVersionCompatibility(backupConfig.BackupVersion, version)
 	utils.EnsureDatabaseVersionCompatibility(backupConfig.DatabaseVersion, connectionPool.Version)
+	if backupConfig.Target == "postgres" {
+		gplog.Warn("This backup's metadata was written for PostgreSQL, so its tables will be created with the default distribution policy and without append-optimized storage.")
+	}
 }
 
 func InitializeFilterLists() {
+	if MustGetFlagString(utils.INCLUDE_SCHEMA_FILE) != "" {
+		includeSchemas := strings.Join(iohelper.MustReadLinesFromFile(MustGetFlagString(utils.INCLUDE_SCHEMA_FILE)), ",")
+		err := cmdFlags.Set(utils.INCLUDE_SCHEMA, includeSchemas)
+		gplog.FatalOnError(err)
+	}
+	if MustGetFlagString(utils.EXCLUDE_SCHEMA_FILE) != "" {
+		excludeSchemas := strings.Join(iohelper.MustReadLinesFromFile(MustGetFlagString(utils.EXCLUDE_SCHEMA_FILE)), ",")
+		err := cmdFlags.Set(utils.EXCLUDE_SCHEMA, excludeSchemas)
+		gplog.FatalOnError(err)
+	}
 	if MustGetFlagString(utils.INCLUDE_RELATION_FILE) != "" {
 		includeRelations := strings.Join(iohelper.MustReadLinesFromFile(MustGetFlagString(utils.INCLUDE_RELATION_FILE)), ",")
 		err := cmdFlags.Set(utils.INCLUDE_RELATION, includeRelations)
@@ -120,6 +140,58 @@ func InitializeFilterLists() {
 		err := cmdFlags.Set(utils.EXCLUDE_RELATION, excludeRelations)
 		gplog.FatalOnError(err)
 	}
+	// Tablespace names may contain commas and quotes, so the lines are not joined and parsed again as CSV
+	if MustGetFlagString(utils.TABLESPACE_MAP_FILE) != "" {
+		for _, mapping := range iohelper.MustReadLinesFromFile(MustGetFlagString(utils.TABLESPACE_MAP_FILE)) {
+			if strings.TrimSpace(mapping) == "" {
+				continue
+			}
+			err := cmdFlags.Set(utils.TABLESPACE_MAP, strings.TrimSpace(mapping))
+			gplog.FatalOnError(err)
+		}
+	}
+	if MustGetFlagString(utils.LOCATION_MAP_FILE) != "" {
+		for _, mapping := range iohelper.MustReadLinesFromFile(MustGetFlagString(utils.LOCATION_MAP_FILE)) {
+			if strings.TrimSpace(mapping) == "" {
+				continue
+			}
+			err := cmdFlags.Set(utils.LOCATION_MAP, strings.TrimSpace(mapping))
+			gplog.FatalOnError(err)
+		}
+	}
+}
+
+/*
+ * Parses tablespace or role mappings in the format old:new into a map from the
+ * quoted old name to the quoted new one.
+ */
+func GetNameMap(mappings []string, objectType string) map[string]string {
+	nameMap := make(map[string]string, len(mappings))
+	for _, mapping := range mappings {
+		names := strings.Split(mapping, ":")
+		if len(names) != 2 || names[0] == "" || names[1] == "" {
+			gplog.Fatal(errors.Errorf("Invalid %s mapping %s.  Mappings must be in the format old:new.", objectType, mapping), "")
+		}
+		nameMap[utils.QuoteIdent(connectionPool, names[0])] = utils.QuoteIdent(connectionPool, names[1])
+	}
+	return nameMap
+}
+
+/*
+ * Location mappings are in the format old=new rather than old:new, as URIs
+ * contain colons.  The new location may itself contain an equals sign, such
+ * as in the config option of an S3 URI.
+ */
+func GetLocationMap(mappings []string) map[string]string {
+	locationMap := make(map[string]string, len(mappings))
+	for _, mapping := range mappings {
+		locations := strings.SplitN(mapping, "=", 2)
+		if len(locations) != 2 || locations[0] == "" || locations[1] == "" {
+			gplog.Fatal(errors.Errorf("Invalid location mapping %s.  Mappings must be in the format old=new.", mapping), "")
+		}
+		locationMap[locations[0]] = locations[1]
+	}
+	return locationMap
 }
 
 func BackupConfigurationValidation() {
@@ -130,7 +202,7 @@ func BackupConfigurationValidation() {
 		VerifyBackupDirectoriesExistOnAllHosts()
 	}
 
-	VerifyMetadataFilePaths(MustGetFlagBool(utils.WITH_STATS))
+	VerifyMetadataFilePaths(MustGetFlagBool(utils.WITH_STATS), MustGetFlagBool(utils.WITH_LARGE_OBJECTS))
 
 	tocFilename := globalFPInfo.GetTOCFilePath()
 	globalTOC = utils.NewTOC(tocFilename)
@@ -140,6 +212,9 @@ func BackupConfigurationValidation() {
 	if isLegacyBackup := backupConfig.RestorePlan == nil; isLegacyBackup {
 		SetRestorePlanForLegacyBackup(globalTOC, globalFPInfo.Timestamp, backupConfig)
 	}
+	if dataTimestamp := MustGetFlagString(utils.DATA_TIMESTAMP); dataTimestamp != "" {
+		ComposeDataBackup(dataTimestamp)
+	}
 
 	ValidateBackupFlagCombinations()
 
@@ -181,6 +256,9 @@ func RecoverMetadataFilesUsingPlugin() {
 	if MustGetFlagBool(utils.WITH_STATS) {
 		metadataFiles = append(metadataFiles, globalFPInfo.GetStatisticsFilePath())
 	}
+	if MustGetFlagBool(utils.WITH_LARGE_OBJECTS) {
+		metadataFiles = append(metadataFiles, globalFPInfo.GetLargeObjectsFilePath())
+	}
 	for _, filename := range metadataFiles {
 		pluginConfig.MustRestoreFile(filename)
 	}
@@ -196,6 +274,12 @@ func RecoverMetadataFilesUsingPlugin() {
 
 	for _, fpInfo := range fpInfoList {
 		pluginConfig.MustRestoreFile(fpInfo.GetTOCFilePath())
+		if MustGetFlagBool(utils.VERIFY_ROW_COUNTS) && !backupConfig.MetadataOnly {
+			// Backups taken before row counts were recorded have no manifest
+			if err := pluginConfig.RestoreFile(fpInfo.GetManifestFilePath()); err != nil {
+				gplog.Verbose("Unable to restore manifest of backup %s: %v", fpInfo.Timestamp, err)
+			}
+		}
 		if backupConfig.SingleDataFile {
 			pluginConfig.RestoreSegmentTOCs(globalCluster, fpInfo)
 		}
@@ -212,9 +296,15 @@ func FindHistoricalPluginVersion(timestamp string) string {
 	if iohelper.FileExistsAndIsReadable(globalFPInfo.GetBackupHistoryFilePath()) {
 		history, err := backup_history.NewHistory(globalFPInfo.GetBackupHistoryFilePath())
 		gplog.FatalOnError(err)
-		foundBackupConfig := history.FindBackupConfig(timestamp)
-		if foundBackupConfig != nil {
-			historicalPluginVersion = foundBackupConfig.PluginVersion
+		/*
+		 * The backups of --all-databases share a timestamp, but cannot use a
+		 * plugin, so the backup with a plugin version is the one wanted.
+		 */
+		for _, backupConfig := range history.FindBackupConfigs(timestamp) {
+			if backupConfig.PluginVersion != "" {
+				historicalPluginVersion = backupConfig.PluginVersion
+				break
+			}
 		}
 	}
 	return historicalPluginVersion
@@ -225,7 +315,7 @@ func FindHistoricalPluginVersion(timestamp string) string {
  */
 
 func GetRestoreMetadataStatements(section string, filename string, includeObjectTypes []string, excludeObjectTypes []string, filterSchemas bool, filterRelations bool) []utils.StatementWithType {
-	metadataFile := iohelper.MustOpenFileForReading(filename)
+	metadataFile := utils.MustOpenMetadataFileForReading(filename)
 	var statements []utils.StatementWithType
 	var inSchemas, exSchemas, inRelations, exRelations []string
 	if len(includeObjectTypes) > 0 || len(excludeObjectTypes) > 0 || filterSchemas || filterRelations {
@@ -294,10 +384,150 @@ func setGUCsForConnection(gucStatements []utils.StatementWithType, whichConn int
 	return gucStatements
 }
 
+const (
+	PHASE_METADATA = "metadata"
+	PHASE_DATA     = "data"
+)
+
+type RestoreGUC struct {
+	Phase string
+	Name  string
+	Value string
+}
+
+/*
+ * Parses --restore-guc values of the form [phase:]name=value.  A GUC with no
+ * phase is set for both phases.
+ */
+func ParseRestoreGUCs(values []string) []RestoreGUC {
+	gucRegex := regexp.MustCompile(`^(?:(metadata|data):)?([A-Za-z_][A-Za-z0-9_.]*)=(.*)$`)
+	gucs := make([]RestoreGUC, 0, len(values))
+	for _, value := range values {
+		match := gucRegex.FindStringSubmatch(value)
+		if match == nil {
+			gplog.Fatal(errors.Errorf("Invalid restore GUC %s.  GUCs must be in the format [metadata:|data:]name=value.", value), "")
+		}
+		gucs = append(gucs, RestoreGUC{Phase: match[1], Name: strings.ToLower(match[2]), Value: match[3]})
+	}
+	return gucs
+}
+
+/*
+ * Returns the value each GUC should have during the given phase.  A GUC given
+ * for that phase specifically takes precedence over one given for both, and
+ * GUCs given only for another phase are returned with their original values.
+ */
+func GetGUCValuesForPhase(gucs []RestoreGUC, phase string, originalValues map[string]string) map[string]string {
+	values := make(map[string]string, len(gucs))
+	isPhaseSpecific := make(map[string]bool, len(gucs))
+	for _, guc := range gucs {
+		if _, ok := values[guc.Name]; !ok {
+			values[guc.Name] = originalValues[guc.Name]
+		}
+		if guc.Phase == phase {
+			values[guc.Name] = guc.Value
+			isPhaseSpecific[guc.Name] = true
+		} else if guc.Phase == "" && !isPhaseSpecific[guc.Name] {
+			values[guc.Name] = guc.Value
+		}
+	}
+	return values
+}
+
+/*
+ * The values the GUCs had before any --restore-guc values were set are saved,
+ * so that they can be set back when moving to a phase that does not use them.
+ */
+func saveOriginalGUCValues() {
+	originalGUCValues = make(map[string]string, len(restoreGUCs))
+	for _, guc := range restoreGUCs {
+		if _, ok := originalGUCValues[guc.Name]; !ok {
+			query := fmt.Sprintf("SELECT current_setting('%s')", guc.Name)
+			originalGUCValues[guc.Name] = dbconn.MustSelectString(connectionPool, query)
+		}
+	}
+}
+
+func setRestoreGUCsForConnection(phase string, whichConn int) {
+	if len(restoreGUCs) == 0 {
+		return
+	}
+	values := GetGUCValuesForPhase(restoreGUCs, phase, originalGUCValues)
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		gplog.Verbose("Setting %s to %s on connection %d for %s restore", name, values[name], whichConn, phase)
+		query := fmt.Sprintf("SELECT set_config('%s', '%s', false)", name, utils.EscapeSingleQuotes(values[name]))
+		connectionPool.MustExec(query, whichConn)
+	}
+}
+
+func setRestoreGUCsForPhase(phase string) {
+	for i := 0; i < connectionPool.NumConns; i++ {
+		setRestoreGUCsForConnection(phase, i)
+	}
+}
+
+/*
+ * Sets every GUC set with --restore-guc or --fast-load back to the value it
+ * had before the restore.
+ */
+func resetRestoreGUCs() {
+	names := make([]string, 0, len(originalGUCValues))
+	for name := range originalGUCValues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i := 0; i < connectionPool.NumConns; i++ {
+		for _, name := range names {
+			query := fmt.Sprintf("SELECT set_config('%s', '%s', false)", name, utils.EscapeSingleQuotes(originalGUCValues[name]))
+			connectionPool.MustExec(query, i)
+		}
+	}
+}
+
+/*
+ * --fast-load raises maintenance_work_mem for the whole restore, as it speeds
+ * up index creation as well, and disables automatic statistics collection only
+ * while table data is restored.  GUCs the user set with --restore-guc are not
+ * changed.
+ */
+var fastLoadGUCs = []RestoreGUC{
+	{Phase: "", Name: "maintenance_work_mem", Value: "512MB"},
+	{Phase: PHASE_DATA, Name: "gp_autostats_mode", Value: "none"},
+}
+
+func AddFastLoadGUCs(gucs []RestoreGUC) []RestoreGUC {
+	isUserGUC := make(map[string]bool, len(gucs))
+	for _, guc := range gucs {
+		isUserGUC[guc.Name] = true
+	}
+	for _, guc := range fastLoadGUCs {
+		if !isUserGUC[guc.Name] {
+			gucs = append(gucs, guc)
+		}
+	}
+	return gucs
+}
+
+/*
+ * In a single transaction restore, each schema is created under a savepoint,
+ * so that a schema that already exists does not abort the whole transaction.
+ */
 func RestoreSchemas(schemaStatements []utils.StatementWithType, progressBar utils.ProgressBar) {
 	numErrors := 0
+	useSavepoint := MustGetFlagBool(utils.SINGLE_TRANSACTION)
 	for _, schema := range schemaStatements {
+		if useSavepoint {
+			connectionPool.MustExec("SAVEPOINT gprestore_schema", 0)
+		}
 		_, err := connectionPool.Exec(schema.Statement, 0)
+		if err != nil && useSavepoint {
+			connectionPool.MustExec("ROLLBACK TO SAVEPOINT gprestore_schema", 0)
+		}
 		if err != nil {
 			if strings.Contains(err.Error(), "already exists") {
 				gplog.Warn("Schema %s already exists", schema.Name)
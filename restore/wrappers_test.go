@@ -88,6 +88,19 @@ var _ = Describe("wrapper tests", func() {
 			expectedErrMsg := "[ERROR]:-Encountered 1 errors during schema restore; see log file gbytes.Buffer for a list of errors."
 			testhelper.ExpectRegexp(logfile, expectedErrMsg)
 		})
+		It("rolls back to a savepoint if schema already exists in a single transaction restore", func() {
+			cmdFlags.Set(utils.SINGLE_TRANSACTION, "true")
+			defer cmdFlags.Set(utils.SINGLE_TRANSACTION, "false")
+			expectedErr := errors.New(`schema "foo" already exists`)
+			mock.ExpectExec("SAVEPOINT gprestore_schema").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("create schema foo").WillReturnError(expectedErr)
+			mock.ExpectExec("ROLLBACK TO SAVEPOINT gprestore_schema").WillReturnResult(sqlmock.NewResult(0, 0))
+
+			restore.RestoreSchemas(schemaArray, ignoredProgressBar)
+
+			testhelper.ExpectRegexp(logfile, "[WARNING]:-Schema foo already exists")
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
 		It("panics if create schema statement fails", func() {
 			expectedErr := errors.New("some other schema error")
 			mock.ExpectExec("create schema foo").WillReturnError(expectedErr)
@@ -312,6 +325,120 @@ withstatistics: false
 				resultPluginVersion := restore.FindHistoricalPluginVersion("20180415154238")
 				Expect(resultPluginVersion).To(Equal("99.99.9999"))
 			})
+			It("finds the plugin version of the backup taken with a plugin among those with the same timestamp", func() {
+				history := `
+backupconfigs:
+- databasename: db1
+  timestamp: "20180415154238"
+- databasename: db2
+  plugin: /usr/local/bin/gpbackup_s3_plugin
+  pluginversion: 1.2.3
+  timestamp: "20180415154238"
+`
+				err := ioutil.WriteFile(filepath.Join(mdd, "gpbackup_history.yaml"), []byte(history), 0777)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(restore.FindHistoricalPluginVersion("20180415154238")).To(Equal("1.2.3"))
+			})
+		})
+	})
+	Describe("InitializeFilterLists", func() {
+		It("reads each line of the tablespace map file as one mapping", func() {
+			mapFile, err := ioutil.TempFile("", "tablespace_map")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.Remove(mapFile.Name())
+			_, err = mapFile.WriteString("ts,1:new_ts\n\n\"ts2\":new,ts\n")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mapFile.Close()).To(Succeed())
+			_ = cmdFlags.Set(utils.TABLESPACE_MAP_FILE, mapFile.Name())
+
+			restore.InitializeFilterLists()
+
+			Expect(restore.MustGetFlagStringArray(utils.TABLESPACE_MAP)).To(Equal([]string{"ts,1:new_ts", `"ts2":new,ts`}))
+		})
+	})
+	Describe("GetNameMap", func() {
+		It("returns a map of quoted old tablespace names to quoted new ones", func() {
+			mock.ExpectQuery("SELECT quote_ident").WillReturnRows(sqlmock.NewRows([]string{"quote_ident"}).AddRow("ts1"))
+			mock.ExpectQuery("SELECT quote_ident").WillReturnRows(sqlmock.NewRows([]string{"quote_ident"}).AddRow(`"New_TS"`))
+
+			Expect(restore.GetNameMap([]string{"ts1:New_TS"}, "tablespace")).To(Equal(map[string]string{"ts1": `"New_TS"`}))
+		})
+		It("returns an empty map if there are no mappings", func() {
+			Expect(restore.GetNameMap([]string{}, "tablespace")).To(BeEmpty())
+		})
+		It("panics if a mapping is not in the format old:new", func() {
+			defer testhelper.ShouldPanicWithMessage("Invalid tablespace mapping ts1.  Mappings must be in the format old:new.")
+			restore.GetNameMap([]string{"ts1"}, "tablespace")
+		})
+	})
+	Describe("GetLocationMap", func() {
+		It("returns a map of old locations to new ones", func() {
+			Expect(restore.GetLocationMap([]string{"etl1:8080=etl2:8081", "s3://old/bucket=s3://new/bucket config=/tmp/s3.conf"})).To(Equal(map[string]string{
+				"etl1:8080":       "etl2:8081",
+				"s3://old/bucket": "s3://new/bucket config=/tmp/s3.conf",
+			}))
+		})
+		It("panics if a mapping is not in the format old=new", func() {
+			defer testhelper.ShouldPanicWithMessage("Invalid location mapping etl1.  Mappings must be in the format old=new.")
+			restore.GetLocationMap([]string{"etl1"})
+		})
+	})
+	Describe("ParseRestoreGUCs", func() {
+		It("parses GUCs with and without a phase", func() {
+			gucs := restore.ParseRestoreGUCs([]string{"gp_autostats_mode=none", "metadata:maintenance_work_mem=2GB", "data:search_path=a, b"})
+
+			Expect(gucs).To(Equal([]restore.RestoreGUC{
+				{Phase: "", Name: "gp_autostats_mode", Value: "none"},
+				{Phase: "metadata", Name: "maintenance_work_mem", Value: "2GB"},
+				{Phase: "data", Name: "search_path", Value: "a, b"},
+			}))
+		})
+		It("panics if a GUC is not in the format name=value", func() {
+			defer testhelper.ShouldPanicWithMessage("Invalid restore GUC postdata:work_mem=1GB.  GUCs must be in the format [metadata:|data:]name=value.")
+			restore.ParseRestoreGUCs([]string{"postdata:work_mem=1GB"})
+		})
+		It("panics if a GUC name is not a valid identifier", func() {
+			defer testhelper.ShouldPanicWithMessage("Invalid restore GUC work_mem';=1GB.  GUCs must be in the format [metadata:|data:]name=value.")
+			restore.ParseRestoreGUCs([]string{"work_mem';=1GB"})
+		})
+	})
+	Describe("AddFastLoadGUCs", func() {
+		It("adds the fast load GUCs", func() {
+			gucs := restore.AddFastLoadGUCs([]restore.RestoreGUC{{Phase: "", Name: "work_mem", Value: "1GB"}})
+
+			Expect(gucs).To(Equal([]restore.RestoreGUC{
+				{Phase: "", Name: "work_mem", Value: "1GB"},
+				{Phase: "", Name: "maintenance_work_mem", Value: "512MB"},
+				{Phase: "data", Name: "gp_autostats_mode", Value: "none"},
+			}))
+		})
+		It("does not add fast load GUCs set by the user", func() {
+			gucs := restore.AddFastLoadGUCs([]restore.RestoreGUC{{Phase: "metadata", Name: "maintenance_work_mem", Value: "2GB"}})
+
+			Expect(gucs).To(Equal([]restore.RestoreGUC{
+				{Phase: "metadata", Name: "maintenance_work_mem", Value: "2GB"},
+				{Phase: "data", Name: "gp_autostats_mode", Value: "none"},
+			}))
+		})
+	})
+	Describe("GetGUCValuesForPhase", func() {
+		originalValues := map[string]string{"gp_autostats_mode": "on_no_stats", "maintenance_work_mem": "64MB", "work_mem": "32MB"}
+		gucs := []restore.RestoreGUC{
+			{Phase: "metadata", Name: "maintenance_work_mem", Value: "2GB"},
+			{Phase: "data", Name: "gp_autostats_mode", Value: "none"},
+			{Phase: "data", Name: "work_mem", Value: "1GB"},
+			{Phase: "", Name: "work_mem", Value: "128MB"},
+		}
+		It("returns the values for the metadata phase", func() {
+			values := restore.GetGUCValuesForPhase(gucs, restore.PHASE_METADATA, originalValues)
+
+			Expect(values).To(Equal(map[string]string{"gp_autostats_mode": "on_no_stats", "maintenance_work_mem": "2GB", "work_mem": "128MB"}))
+		})
+		It("returns the values for the data phase, preferring phase-specific values", func() {
+			values := restore.GetGUCValuesForPhase(gucs, restore.PHASE_DATA, originalValues)
+
+			Expect(values).To(Equal(map[string]string{"gp_autostats_mode": "none", "maintenance_work_mem": "64MB", "work_mem": "1GB"}))
 		})
 	})
 })
@@ -17,6 +17,7 @@ import (
 	"github.com/greenplum-db/gpbackup/utils"
 	"github.com/onsi/gomega/gbytes"
 	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/spf13/pflag"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -35,14 +36,20 @@ func SetupTestEnvironment() (*dbconn.DBConn, sqlmock.Sqlmock, *gbytes.Buffer, *g
 
 func SetupTestCluster() *cluster.Cluster {
 	testCluster := SetDefaultSegmentConfiguration()
-	backup.SetCluster(testCluster)
 	restore.SetCluster(testCluster)
 	testFPInfo := backup_filepath.NewFilePathInfo(testCluster, "", "20170101010101", "gpseg")
-	backup.SetFPInfo(testFPInfo)
 	restore.SetFPInfo(testFPInfo)
 	return testCluster
 }
 
+func SetupTestBackup(flags *pflag.FlagSet, connectionPool *dbconn.DBConn) *backup.Backup {
+	testBackup := backup.NewBackup(flags, connectionPool, nil)
+	testCluster := SetDefaultSegmentConfiguration()
+	testBackup.SetCluster(testCluster)
+	testBackup.SetFPInfo(backup_filepath.NewFilePathInfo(testCluster, "", "20170101010101", "gpseg"))
+	return testBackup
+}
+
 func SetupTestDbConn(dbname string) *dbconn.DBConn {
 	conn := dbconn.NewDBConnFromEnvironment(dbname)
 	conn.MustConnect(1)
@@ -147,9 +154,12 @@ var objNameToClassID = map[string]uint32{
 	"FUNCTION":                  1255,
 	"INDEX":                     2610,
 	"LANGUAGE":                  2612,
+	"LARGE OBJECT":              2995,
 	"OPERATOR CLASS":            2616,
 	"OPERATOR FAMILY":           2753,
 	"OPERATOR":                  2617,
+	"POLICY":                    3256,
+	"PROCEDURE":                 1255,
 	"PROTOCOL":                  7175,
 	"RESOURCE GROUP":            6436,
 	"RESOURCE QUEUE":            6026,
@@ -157,12 +167,14 @@ var objNameToClassID = map[string]uint32{
 	"RULE":                      2618,
 	"SCHEMA":                    2615,
 	"SEQUENCE":                  1259,
+	"STATISTICS":                3381,
 	"TABLE":                     1259,
 	"TABLESPACE":                1213,
 	"TEXT SEARCH CONFIGURATION": 3602,
 	"TEXT SEARCH DICTIONARY":    3600,
 	"TEXT SEARCH PARSER":        3601,
 	"TEXT SEARCH TEMPLATE":      3764,
+	"TRANSFORM":                 3576,
 	"TRIGGER":                   2620,
 	"TYPE":                      1247,
 	"USER MAPPING":              1418,
@@ -177,15 +189,15 @@ func ClassIDFromObjectName(objName string) uint32 {
 func DefaultACLForType(grantee string, objType string) backup.ACL {
 	return backup.ACL{
 		Grantee:    grantee,
-		Select:     objType == "PROTOCOL" || objType == "SEQUENCE" || objType == "TABLE" || objType == "VIEW" || objType == "FOREIGN TABLE" || objType == "MATERIALIZED VIEW",
+		Select:     objType == "LARGE OBJECT" || objType == "PROTOCOL" || objType == "SEQUENCE" || objType == "TABLE" || objType == "VIEW" || objType == "FOREIGN TABLE" || objType == "MATERIALIZED VIEW",
 		Insert:     objType == "PROTOCOL" || objType == "TABLE" || objType == "VIEW" || objType == "FOREIGN TABLE" || objType == "MATERIALIZED VIEW",
-		Update:     objType == "SEQUENCE" || objType == "TABLE" || objType == "VIEW" || objType == "FOREIGN TABLE" || objType == "MATERIALIZED VIEW",
+		Update:     objType == "LARGE OBJECT" || objType == "SEQUENCE" || objType == "TABLE" || objType == "VIEW" || objType == "FOREIGN TABLE" || objType == "MATERIALIZED VIEW",
 		Delete:     objType == "TABLE" || objType == "VIEW" || objType == "FOREIGN TABLE" || objType == "MATERIALIZED VIEW",
 		Truncate:   objType == "TABLE" || objType == "VIEW" || objType == "MATERIALIZED VIEW",
 		References: objType == "TABLE" || objType == "VIEW" || objType == "FOREIGN TABLE" || objType == "MATERIALIZED VIEW",
 		Trigger:    objType == "TABLE" || objType == "VIEW" || objType == "FOREIGN TABLE" || objType == "MATERIALIZED VIEW",
 		Usage:      objType == "LANGUAGE" || objType == "SCHEMA" || objType == "SEQUENCE" || objType == "FOREIGN DATA WRAPPER" || objType == "FOREIGN SERVER",
-		Execute:    objType == "FUNCTION" || objType == "AGGREGATE",
+		Execute:    objType == "FUNCTION" || objType == "PROCEDURE" || objType == "AGGREGATE",
 		Create:     objType == "DATABASE" || objType == "SCHEMA" || objType == "TABLESPACE",
 		Temporary:  objType == "DATABASE",
 		Connect:    objType == "DATABASE",
@@ -195,15 +207,15 @@ func DefaultACLForType(grantee string, objType string) backup.ACL {
 func DefaultACLForTypeWithGrant(grantee string, objType string) backup.ACL {
 	return backup.ACL{
 		Grantee:             grantee,
-		SelectWithGrant:     objType == "PROTOCOL" || objType == "SEQUENCE" || objType == "TABLE" || objType == "VIEW" || objType == "MATERIALIZED VIEW",
+		SelectWithGrant:     objType == "LARGE OBJECT" || objType == "PROTOCOL" || objType == "SEQUENCE" || objType == "TABLE" || objType == "VIEW" || objType == "MATERIALIZED VIEW",
 		InsertWithGrant:     objType == "PROTOCOL" || objType == "TABLE" || objType == "VIEW" || objType == "MATERIALIZED VIEW",
-		UpdateWithGrant:     objType == "SEQUENCE" || objType == "TABLE" || objType == "VIEW" || objType == "MATERIALIZED VIEW",
+		UpdateWithGrant:     objType == "LARGE OBJECT" || objType == "SEQUENCE" || objType == "TABLE" || objType == "VIEW" || objType == "MATERIALIZED VIEW",
 		DeleteWithGrant:     objType == "TABLE" || objType == "VIEW" || objType == "MATERIALIZED VIEW",
 		TruncateWithGrant:   objType == "TABLE" || objType == "VIEW" || objType == "MATERIALIZED VIEW",
 		ReferencesWithGrant: objType == "TABLE" || objType == "VIEW" || objType == "MATERIALIZED VIEW",
 		TriggerWithGrant:    objType == "TABLE" || objType == "VIEW" || objType == "MATERIALIZED VIEW",
 		UsageWithGrant:      objType == "LANGUAGE" || objType == "SCHEMA" || objType == "SEQUENCE" || objType == "FOREIGN DATA WRAPPER" || objType == "FOREIGN SERVER",
-		ExecuteWithGrant:    objType == "FUNCTION",
+		ExecuteWithGrant:    objType == "FUNCTION" || objType == "PROCEDURE",
 		CreateWithGrant:     objType == "DATABASE" || objType == "SCHEMA" || objType == "TABLESPACE",
 		TemporaryWithGrant:  objType == "DATABASE",
 		ConnectWithGrant:    objType == "DATABASE",
@@ -123,10 +123,17 @@ var _ = Describe("agent remote", func() {
 	})
 	Describe("StartGpbackupHelpers()", func() {
 		It("Correctly propagates --on-error-continue flag to gpbackup_helper", func() {
-			utils.StartGpbackupHelpers(testCluster, fpInfo, "operation", "/tmp/pluginConfigFile.yml", " compressStr", true)
+			utils.StartGpbackupHelpers(testCluster, fpInfo, "operation", "/tmp/pluginConfigFile.yml", " compressStr", true, 0)
 
 			cc := testExecutor.ClusterCommands[0]
 			Expect(cc[0][4]).To(ContainSubstring(" --on-error-continue"))
+			Expect(cc[0][4]).ToNot(ContainSubstring("--copy-buffer-size"))
+		})
+		It("Correctly propagates --copy-buffer-size flag to gpbackup_helper", func() {
+			utils.StartGpbackupHelpers(testCluster, fpInfo, "operation", "", "", false, 1048576)
+
+			cc := testExecutor.ClusterCommands[0]
+			Expect(cc[0][4]).To(ContainSubstring(" --copy-buffer-size 1048576"))
 		})
 	})
 	Describe("CheckAgentErrorsOnSegments", func() {
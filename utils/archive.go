@@ -0,0 +1,261 @@
+package utils
+
+/*
+ * This file contains functions for writing the files of a backup, which are
+ * spread across the hosts of the cluster, to a single tar archive stream.
+ */
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/greenplum-db/gp-common-go-libs/cluster"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/pkg/errors"
+)
+
+const ARCHIVE_TO_STDOUT = "-"
+
+/*
+ * The files in Directory on the local host are written to the archive under
+ * Name, except for those matching one of the Exclude patterns.
+ */
+type ArchiveSource struct {
+	Directory string
+	Name      string
+	Exclude   []string
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+func OpenArchiveFile(filename string) (io.WriteCloser, error) {
+	if filename == ARCHIVE_TO_STDOUT {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+	file, err := operating.System.OpenFileWrite(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to open archive file %s", filename)
+	}
+	return file, nil
+}
+
+/*
+ * Replaces the gplog logger with one that writes to stderr instead of stdout,
+ * so that stdout is left to the archive.  As with progress logging, the log
+ * file is opened again by name, in append mode.
+ */
+func InitializeArchiveLogging(program string) error {
+	logFilePath := gplog.GetLogFilePath()
+	logFile, err := operating.System.OpenFileWrite(logFilePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to open log file %s", logFilePath)
+	}
+	gplog.SetLogger(gplog.NewLogger(os.Stderr, os.Stderr, logFile, logFilePath, gplog.GetVerbosity(), program, gplog.GetLogFileVerbosity()))
+	return nil
+}
+
+/*
+ * Entries may be added to the archive from several goroutines at once, as the
+ * data of several tables is backed up at once with --jobs.
+ */
+type ArchiveWriter struct {
+	writer    io.WriteCloser
+	tarWriter *tar.Writer
+	lock      sync.Mutex
+}
+
+func NewArchiveWriter(writer io.WriteCloser) *ArchiveWriter {
+	return &ArchiveWriter{writer: writer, tarWriter: tar.NewWriter(writer)}
+}
+
+func (archive *ArchiveWriter) AddSpool(name string, spool *ArchiveSpool) error {
+	reader, err := spool.reader()
+	if err != nil {
+		return err
+	}
+	archive.lock.Lock()
+	defer archive.lock.Unlock()
+	header := &tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: spool.size, ModTime: operating.System.Now()}
+	err = archive.tarWriter.WriteHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(archive.tarWriter, reader)
+	return err
+}
+
+/*
+ * The directory is read with tar and its entries are copied into the archive
+ * as they are read.
+ */
+func (archive *ArchiveWriter) AddDirectory(source ArchiveSource) error {
+	gplog.Verbose("Archiving %s as %s", source.Directory, source.Name)
+	archive.lock.Lock()
+	defer archive.lock.Unlock()
+	tarCommand := fmt.Sprintf(`tar -C "%s"`, source.Directory)
+	for _, pattern := range source.Exclude {
+		tarCommand += fmt.Sprintf(` --exclude="%s"`, pattern)
+	}
+	tarCommand += " -cf - ."
+	cmd := exec.Command("bash", "-c", tarCommand)
+	stderr := bytes.Buffer{}
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	err = cmd.Start()
+	if err != nil {
+		return errors.Wrapf(err, "Unable to archive %s", source.Directory)
+	}
+	err = AppendTarEntries(archive.tarWriter, stdout, source.Name)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return errors.Wrapf(err, "Unable to archive %s", source.Directory)
+	}
+	err = cmd.Wait()
+	if err != nil {
+		return errors.Wrapf(err, "Unable to archive %s: %s", source.Directory, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// Writes the end of the archive and closes the file or stream it is written to
+func (archive *ArchiveWriter) Close() error {
+	err := archive.tarWriter.Close()
+	closeErr := archive.writer.Close()
+	if err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+/*
+ * Ends the archive of a failed backup with a block that is not a valid tar
+ * header instead of the end of the archive, so that tar fails on the archive
+ * rather than reading it as complete, and closes the file or stream.
+ */
+func (archive *ArchiveWriter) Abort() {
+	archive.lock.Lock()
+	defer archive.lock.Unlock()
+	_ = archive.tarWriter.Flush()
+	block := make([]byte, 512)
+	copy(block, "gpbackup: this archive is incomplete, as the backup failed\n")
+	_, _ = archive.writer.Write(block)
+	_ = archive.writer.Close()
+}
+
+const archiveSpoolMemoryLimit = 1024 * 1024
+
+/*
+ * The size of a tar entry is written before its contents, and the entries of
+ * the data files of the segments cannot be interleaved, so each data file is
+ * held until the segment has finished writing it: in memory, or in a temporary
+ * file on the local host once it is larger than archiveSpoolMemoryLimit.
+ */
+type ArchiveSpool struct {
+	buffer bytes.Buffer
+	file   *os.File
+	size   int64
+}
+
+func (spool *ArchiveSpool) Write(p []byte) (int, error) {
+	if spool.file == nil && spool.buffer.Len()+len(p) > archiveSpoolMemoryLimit {
+		file, err := ioutil.TempFile("", "gpbackup_archive_spool_")
+		if err != nil {
+			return 0, err
+		}
+		spool.file = file
+		_, err = spool.buffer.WriteTo(file)
+		if err != nil {
+			return 0, err
+		}
+	}
+	var n int
+	var err error
+	if spool.file != nil {
+		n, err = spool.file.Write(p)
+	} else {
+		n, err = spool.buffer.Write(p)
+	}
+	spool.size += int64(n)
+	return n, err
+}
+
+func (spool *ArchiveSpool) reader() (io.Reader, error) {
+	if spool.file == nil {
+		return &spool.buffer, nil
+	}
+	_, err := spool.file.Seek(0, io.SeekStart)
+	return spool.file, err
+}
+
+func (spool *ArchiveSpool) Remove() {
+	if spool.file != nil {
+		_ = spool.file.Close()
+		_ = os.Remove(spool.file.Name())
+	}
+}
+
+/*
+ * Reads the file, usually a named pipe, at filePath on the given host over ssh
+ * until the writer closes it.
+ */
+func SpoolRemoteFile(host string, filePath string) (*ArchiveSpool, error) {
+	spool := &ArchiveSpool{}
+	args := cluster.ConstructSSHCommand(host, fmt.Sprintf(`cat "%s"`, filePath))
+	cmd := exec.Command(args[0], args[1:]...)
+	stderr := bytes.Buffer{}
+	cmd.Stdout = spool
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		spool.Remove()
+		return nil, errors.Wrapf(err, "Unable to read %s on host %s: %s", filePath, host, strings.TrimSpace(stderr.String()))
+	}
+	return spool, nil
+}
+
+/*
+ * Copies the entries of the tar read from the reader into the archive, with
+ * their names under the given directory name.
+ */
+func AppendTarEntries(tarWriter *tar.Writer, reader io.Reader, name string) error {
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		header.Name = path.Join(name, header.Name)
+		if header.Typeflag == tar.TypeDir {
+			header.Name += "/"
+		}
+		err = tarWriter.WriteHeader(header)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(tarWriter, tarReader)
+		if err != nil {
+			return err
+		}
+	}
+}
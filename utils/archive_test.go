@@ -0,0 +1,132 @@
+package utils_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("utils/archive tests", func() {
+	Describe("AppendTarEntries", func() {
+		It("copies the entries of a tar under the given name", func() {
+			source := bytes.Buffer{}
+			sourceWriter := tar.NewWriter(&source)
+			Expect(sourceWriter.WriteHeader(&tar.Header{Name: "./", Typeflag: tar.TypeDir, Mode: 0755})).To(Succeed())
+			Expect(sourceWriter.WriteHeader(&tar.Header{Name: "./gpbackup_0_20170101010101_3456.gz", Typeflag: tar.TypeReg, Mode: 0644, Size: 4})).To(Succeed())
+			_, err := sourceWriter.Write([]byte("data"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sourceWriter.Close()).To(Succeed())
+
+			archive := bytes.Buffer{}
+			archiveWriter := tar.NewWriter(&archive)
+			err = utils.AppendTarEntries(archiveWriter, &source, "gpseg0/backups/20170101/20170101010101")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(archiveWriter.Close()).To(Succeed())
+
+			archiveReader := tar.NewReader(&archive)
+			header, err := archiveReader.Next()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(header.Name).To(Equal("gpseg0/backups/20170101/20170101010101/"))
+			header, err = archiveReader.Next()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(header.Name).To(Equal("gpseg0/backups/20170101/20170101010101/gpbackup_0_20170101010101_3456.gz"))
+			Expect(ioutil.ReadAll(archiveReader)).To(Equal([]byte("data")))
+		})
+	})
+	Describe("ArchiveWriter", func() {
+		var archive bytes.Buffer
+		var archiveWriter *utils.ArchiveWriter
+		BeforeEach(func() {
+			archive = bytes.Buffer{}
+			archiveWriter = utils.NewArchiveWriter(nopCloser{&archive})
+		})
+		readEntries := func() map[string]string {
+			entries := make(map[string]string)
+			archiveReader := tar.NewReader(&archive)
+			for {
+				header, err := archiveReader.Next()
+				if err != nil {
+					Expect(err.Error()).To(Equal("EOF"))
+					return entries
+				}
+				contents, err := ioutil.ReadAll(archiveReader)
+				Expect(err).ToNot(HaveOccurred())
+				entries[header.Name] = string(contents)
+			}
+		}
+		It("adds a spooled stream as a file", func() {
+			spool := &utils.ArchiveSpool{}
+			_, err := spool.Write([]byte("data"))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(archiveWriter.AddSpool("gpseg0/backups/20170101/20170101010101/gpbackup_0_20170101010101_3456.gz", spool)).To(Succeed())
+			Expect(archiveWriter.Close()).To(Succeed())
+
+			Expect(readEntries()).To(Equal(map[string]string{"gpseg0/backups/20170101/20170101010101/gpbackup_0_20170101010101_3456.gz": "data"}))
+		})
+		It("adds a stream that was spooled to a temporary file", func() {
+			data := strings.Repeat("0123456789abcdef", 128*1024)
+			spool := &utils.ArchiveSpool{}
+			for i := 0; i < len(data); i += 1000 {
+				end := i + 1000
+				if end > len(data) {
+					end = len(data)
+				}
+				_, err := spool.Write([]byte(data[i:end]))
+				Expect(err).ToNot(HaveOccurred())
+			}
+			defer spool.Remove()
+
+			Expect(archiveWriter.AddSpool("gpseg0/data", spool)).To(Succeed())
+			Expect(archiveWriter.Close()).To(Succeed())
+
+			Expect(readEntries()).To(Equal(map[string]string{"gpseg0/data": data}))
+		})
+		It("adds a local directory, leaving out excluded files", func() {
+			tempDir, err := ioutil.TempDir("", "archive dir")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(tempDir)
+			Expect(ioutil.WriteFile(filepath.Join(tempDir, "gpbackup_20170101010101_metadata.sql"), []byte("metadata"), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(tempDir, "gpbackup_20170101010101_in_progress"), []byte("marker"), 0644)).To(Succeed())
+
+			source := utils.ArchiveSource{Directory: tempDir, Name: "gpseg-1/backups/20170101/20170101010101", Exclude: []string{"*_in_progress"}}
+			Expect(archiveWriter.AddDirectory(source)).To(Succeed())
+			Expect(archiveWriter.Close()).To(Succeed())
+
+			Expect(readEntries()).To(Equal(map[string]string{
+				"gpseg-1/backups/20170101/20170101010101/":                                     "",
+				"gpseg-1/backups/20170101/20170101010101/gpbackup_20170101010101_metadata.sql": "metadata",
+			}))
+		})
+		It("ends an aborted archive with an invalid header", func() {
+			spool := &utils.ArchiveSpool{}
+			_, err := spool.Write([]byte("data"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(archiveWriter.AddSpool("gpseg0/data", spool)).To(Succeed())
+
+			archiveWriter.Abort()
+
+			archiveReader := tar.NewReader(&archive)
+			_, err = archiveReader.Next()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = archiveReader.Next()
+			Expect(err).To(Equal(tar.ErrHeader))
+		})
+	})
+})
+
+type nopCloser struct {
+	*bytes.Buffer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,39 @@
+package utils
+
+/*
+ * This file contains the contents of the marker files that show whether a
+ * backup is in progress or complete.
+ */
+
+import (
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"gopkg.in/yaml.v2"
+)
+
+/*
+ * The completion marker records the checksum of the manifest, so that a
+ * manifest that was changed after the backup completed can be detected.
+ * Backups without a manifest, such as plugin and metadata-only backups, have
+ * no manifest checksum.
+ */
+type BackupMarker struct {
+	Timestamp        string
+	EndTime          string `yaml:",omitempty"`
+	ManifestChecksum string `yaml:",omitempty"`
+}
+
+func ReadBackupMarker(filename string) *BackupMarker {
+	marker := &BackupMarker{}
+	contents, err := operating.System.ReadFile(filename)
+	gplog.FatalOnError(err)
+	err = yaml.Unmarshal(contents, marker)
+	gplog.FatalOnError(err)
+	return marker
+}
+
+func (marker BackupMarker) Contents() string {
+	contents, err := yaml.Marshal(marker)
+	gplog.FatalOnError(err)
+	return string(contents)
+}
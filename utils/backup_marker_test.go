@@ -0,0 +1,37 @@
+package utils_test
+
+import (
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("utils/backup_marker tests", func() {
+	AfterEach(func() {
+		operating.System = operating.InitializeSystemFunctions()
+	})
+	Describe("Contents", func() {
+		It("omits the end time and manifest checksum of an in-progress marker", func() {
+			marker := utils.BackupMarker{Timestamp: "20190101000000"}
+
+			Expect(marker.Contents()).To(Equal("timestamp: \"20190101000000\"\n"))
+		})
+		It("includes the end time and manifest checksum of a completion marker", func() {
+			marker := utils.BackupMarker{Timestamp: "20190101000000", EndTime: "20190101000500", ManifestChecksum: "0a1b2c3d"}
+
+			Expect(marker.Contents()).To(Equal("timestamp: \"20190101000000\"\nendtime: \"20190101000500\"\nmanifestchecksum: 0a1b2c3d\n"))
+		})
+	})
+	Describe("ReadBackupMarker", func() {
+		It("reads a marker written with Contents", func() {
+			expected := utils.BackupMarker{Timestamp: "20190101000000", EndTime: "20190101000500", ManifestChecksum: "0a1b2c3d"}
+			operating.System.ReadFile = func(filename string) ([]byte, error) {
+				return []byte(expected.Contents()), nil
+			}
+
+			Expect(*utils.ReadBackupMarker("marker")).To(Equal(expected))
+		})
+	})
+})
@@ -1,6 +1,18 @@
 package utils
 
-import "fmt"
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/iohelper"
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/pkg/errors"
+)
 
 var (
 	pipeThroughProgram PipeThroughProgram
@@ -21,6 +33,80 @@ func InitializePipeThroughParameters(compress bool, compressionLevel int) {
 	}
 }
 
+/*
+ * Parquet exports are written by gpbackup_helper instead of being piped
+ * through a program, and their pages are compressed with gzip internally.
+ */
+func InitializeParquetPipeThroughParameters(compress bool) {
+	if compress {
+		pipeThroughProgram = PipeThroughProgram{Name: "gzip", OutputCommand: "", InputCommand: "", Extension: ".parquet"}
+	} else {
+		pipeThroughProgram = PipeThroughProgram{Name: "cat", OutputCommand: "", InputCommand: "", Extension: ".parquet"}
+	}
+}
+
+/*
+ * With --adaptive-compression, gpbackup_helper samples the start of each
+ * table's data to choose a compression level for that table.  A change in
+ * level starts a new gzip member in the data file, which gzip readers treat
+ * as a continuation of the same stream, so restore needs no changes.
+ */
+const (
+	ADAPTIVE_COMPRESSION_SAMPLE_SIZE = 1024 * 1024
+	incompressibleRatio              = 0.9
+	adaptiveLevelStep                = 2
+)
+
+/*
+ * Returns the compressed size of the sample as a fraction of its original
+ * size, and the rate in bytes per second at which it was compressed.
+ */
+func MeasureCompressibility(sample []byte, compressionLevel int) (float64, float64) {
+	if len(sample) == 0 {
+		return 1, 0
+	}
+	start := time.Now()
+	compressed, err := CompressBytes(sample, compressionLevel)
+	elapsed := time.Since(start).Seconds()
+	if err != nil {
+		return 1, 0
+	}
+	ratio := float64(len(compressed)) / float64(len(sample))
+	if elapsed <= 0 {
+		return ratio, 0
+	}
+	return ratio, float64(len(sample)) / elapsed
+}
+
+/*
+ * Data that barely compresses is written at the fastest level.  Otherwise the
+ * level is lowered if compressing the data takes longer than writing the
+ * compressed result, and raised if writing is the bottleneck and there is CPU
+ * time to spare.  Without a write rate yet to compare against, the base level
+ * is used.
+ */
+func ChooseCompressionLevel(baseLevel int, ratio float64, compressRate float64, writeRate float64) int {
+	if ratio >= incompressibleRatio {
+		return gzip.BestSpeed
+	}
+	if compressRate <= 0 || writeRate <= 0 {
+		return baseLevel
+	}
+	// Seconds spent per byte of table data compressing it and writing the result
+	compressTime := 1 / compressRate
+	writeTime := ratio / writeRate
+	if compressTime > writeTime {
+		if baseLevel-adaptiveLevelStep < gzip.BestSpeed {
+			return gzip.BestSpeed
+		}
+		return baseLevel - adaptiveLevelStep
+	}
+	if baseLevel+adaptiveLevelStep > gzip.BestCompression {
+		return gzip.BestCompression
+	}
+	return baseLevel + adaptiveLevelStep
+}
+
 func GetPipeThroughProgram() PipeThroughProgram {
 	return pipeThroughProgram
 }
@@ -28,3 +114,76 @@ func GetPipeThroughProgram() PipeThroughProgram {
 func SetPipeThroughProgram(compression PipeThroughProgram) {
 	pipeThroughProgram = compression
 }
+
+/*
+ * Metadata, statistics, and TOC files keep their usual names when they are
+ * compressed with --compress-metadata, so readers detect compression from the
+ * gzip header instead of the file extension.  This also lets a restore or an
+ * incremental backup read files from a mix of compressed and uncompressed
+ * backups.
+ */
+func IsGzipCompressed(contents []byte) bool {
+	return len(contents) >= 2 && contents[0] == 0x1f && contents[1] == 0x8b
+}
+
+func CompressBytes(contents []byte, compressionLevel int) ([]byte, error) {
+	var buffer bytes.Buffer
+	gzipWriter, err := gzip.NewWriterLevel(&buffer, compressionLevel)
+	if err != nil {
+		return nil, err
+	}
+	_, err = gzipWriter.Write(contents)
+	if err != nil {
+		return nil, err
+	}
+	err = gzipWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func DecompressBytes(contents []byte) ([]byte, error) {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(contents))
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+	return ioutil.ReadAll(gzipReader)
+}
+
+// Encrypted files are decrypted first, as they are compressed before being encrypted
+func ReadFileDecompressingIfNeeded(filename string) ([]byte, error) {
+	contents, err := operating.System.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if IsEncrypted(contents) {
+		contents, err = DecryptBytes(contents, metadataEncryptionKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to read %s", filename)
+		}
+	}
+	if IsGzipCompressed(contents) {
+		return DecompressBytes(contents)
+	}
+	return contents, nil
+}
+
+/*
+ * Statements are read out of the metadata file by byte offset, and those
+ * offsets refer to the uncompressed, unencrypted contents, so a compressed or
+ * encrypted metadata file is read into memory before it is handed back.
+ */
+func MustOpenMetadataFileForReading(filename string) io.ReaderAt {
+	metadataFile := iohelper.MustOpenFileForReading(filename)
+	header := make([]byte, len(encryptedFileHeader))
+	numRead, _ := metadataFile.ReadAt(header, 0)
+	if !IsGzipCompressed(header[:numRead]) && !IsEncrypted(header[:numRead]) {
+		return metadataFile
+	}
+	_ = metadataFile.Close()
+	contents, err := ReadFileDecompressingIfNeeded(filename)
+	gplog.FatalOnError(err, fmt.Sprintf("Unable to read metadata file %s", filename))
+	return bytes.NewReader(contents)
+}
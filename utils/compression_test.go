@@ -2,14 +2,17 @@ package utils_test
 
 import (
 	"os/user"
+	"strings"
 
 	"github.com/greenplum-db/gp-common-go-libs/cluster"
 	"github.com/greenplum-db/gp-common-go-libs/operating"
 	"github.com/greenplum-db/gp-common-go-libs/structmatcher"
 	"github.com/greenplum-db/gp-common-go-libs/testhelper"
 	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/pkg/errors"
 
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
 )
 
 var _ = Describe("utils/compression tests", func() {
@@ -57,4 +60,70 @@ var _ = Describe("utils/compression tests", func() {
 			structmatcher.ExpectStructsToMatch(&expectedProgram, &resultProgram)
 		})
 	})
+	Describe("MeasureCompressibility", func() {
+		It("measures how well a sample compresses", func() {
+			sample := []byte(strings.Repeat("1,foo,2018-01-01\n", 1000))
+
+			ratio, rate := utils.MeasureCompressibility(sample, 1)
+
+			Expect(ratio).To(BeNumerically("<", 0.1))
+			Expect(rate).To(BeNumerically(">", 0))
+		})
+		It("treats an empty sample as incompressible", func() {
+			ratio, rate := utils.MeasureCompressibility([]byte{}, 1)
+
+			Expect(ratio).To(Equal(float64(1)))
+			Expect(rate).To(Equal(float64(0)))
+		})
+	})
+	Describe("ChooseCompressionLevel", func() {
+		It("uses the fastest level for data that barely compresses", func() {
+			Expect(utils.ChooseCompressionLevel(6, 0.95, 1000, 1000)).To(Equal(1))
+		})
+		It("uses the base level before a write rate has been measured", func() {
+			Expect(utils.ChooseCompressionLevel(6, 0.5, 1000, 0)).To(Equal(6))
+		})
+		It("lowers the level when compression is the bottleneck", func() {
+			Expect(utils.ChooseCompressionLevel(6, 0.5, 100, 1000)).To(Equal(4))
+			Expect(utils.ChooseCompressionLevel(2, 0.5, 100, 1000)).To(Equal(1))
+		})
+		It("raises the level when writing is the bottleneck", func() {
+			Expect(utils.ChooseCompressionLevel(6, 0.5, 1000, 100)).To(Equal(8))
+			Expect(utils.ChooseCompressionLevel(8, 0.5, 1000, 100)).To(Equal(9))
+		})
+	})
+	Describe("CompressBytes", func() {
+		It("produces gzip contents that decompress to the original contents", func() {
+			compressed, err := utils.CompressBytes([]byte("SET client_encoding = 'UTF8';\n"), 6)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(utils.IsGzipCompressed(compressed)).To(BeTrue())
+
+			decompressed, err := utils.DecompressBytes(compressed)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(decompressed)).To(Equal("SET client_encoding = 'UTF8';\n"))
+		})
+	})
+	Describe("ReadFileDecompressingIfNeeded", func() {
+		AfterEach(func() {
+			operating.System = operating.InitializeSystemFunctions()
+		})
+		It("returns the contents of an uncompressed file unchanged", func() {
+			operating.System.ReadFile = func(filename string) ([]byte, error) { return []byte("dataentries: []\n"), nil }
+			contents, err := utils.ReadFileDecompressingIfNeeded("toc.yaml")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal("dataentries: []\n"))
+		})
+		It("decompresses the contents of a gzip-compressed file", func() {
+			compressed, _ := utils.CompressBytes([]byte("dataentries: []\n"), 1)
+			operating.System.ReadFile = func(filename string) ([]byte, error) { return compressed, nil }
+			contents, err := utils.ReadFileDecompressingIfNeeded("toc.yaml")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal("dataentries: []\n"))
+		})
+		It("returns an error if the file cannot be read", func() {
+			operating.System.ReadFile = func(filename string) ([]byte, error) { return nil, errors.New("permission denied") }
+			_, err := utils.ReadFileDecompressingIfNeeded("toc.yaml")
+			Expect(err).To(MatchError("permission denied"))
+		})
+	})
 })
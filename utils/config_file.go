@@ -0,0 +1,137 @@
+package utils
+
+/*
+ * This file contains functions for setting flags from a YAML configuration
+ * file and from environment variables, so that scheduled runs do not need to
+ * pass every flag on the command line.
+ */
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+)
+
+type configProfiles struct {
+	Profiles map[string]map[string]interface{} `yaml:"profiles"`
+}
+
+/*
+ * Flags that control how flags are set, or that only print information and
+ * exit, cannot themselves be set from a configuration file or environment.
+ */
+var unconfigurableFlags = map[string]bool{CONFIG: true, PROFILE: true, "help": true, "version": true}
+
+/*
+ * Values are taken, in order of precedence, from the command line, then from
+ * environment variables named <envPrefix><FLAG_NAME>, then from the given
+ * profile in the configuration file, then from the top level of that file.
+ */
+func SetFlagsFromConfiguration(flags *pflag.FlagSet, envPrefix string) error {
+	err := SetFlagsFromEnvironment(flags, envPrefix)
+	if err != nil {
+		return err
+	}
+	configFile := MustGetFlagString(flags, CONFIG)
+	profile := MustGetFlagString(flags, PROFILE)
+	if configFile == "" {
+		if profile != "" {
+			return errors.Errorf("--%s must be specified with --%s", CONFIG, PROFILE)
+		}
+		return nil
+	}
+	contents, err := operating.System.ReadFile(configFile)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to read configuration file %s", configFile)
+	}
+	return errors.Wrapf(SetFlagsFromConfigFile(flags, contents, profile), "Invalid configuration file %s", configFile)
+}
+
+func SetFlagsFromEnvironment(flags *pflag.FlagSet, envPrefix string) error {
+	var err error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed || unconfigurableFlags[flag.Name] {
+			return
+		}
+		envName := envPrefix + strings.ToUpper(strings.Replace(flag.Name, "-", "_", -1))
+		if value, ok := os.LookupEnv(envName); ok {
+			if setErr := flags.Set(flag.Name, value); setErr != nil {
+				err = errors.Errorf("Invalid value %s for environment variable %s: %v", value, envName, setErr)
+			}
+		}
+	})
+	return err
+}
+
+func SetFlagsFromConfigFile(flags *pflag.FlagSet, contents []byte, profile string) error {
+	values := make(map[string]interface{})
+	err := yaml.Unmarshal(contents, &values)
+	if err != nil {
+		return err
+	}
+	// Notifiers are not flags, and are read by ReadNotifications
+	delete(values, "profiles")
+	delete(values, "notifications")
+	if profile != "" {
+		profiles := configProfiles{}
+		err = yaml.Unmarshal(contents, &profiles)
+		if err != nil {
+			return err
+		}
+		profileValues, ok := profiles.Profiles[profile]
+		if !ok {
+			return errors.Errorf("Profile %s not found", profile)
+		}
+		for name, value := range profileValues {
+			values[name] = value
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		flag := flags.Lookup(name)
+		if flag == nil || unconfigurableFlags[name] {
+			return errors.Errorf("Unknown flag %s", name)
+		}
+		if flag.Changed {
+			continue
+		}
+		err = setFlagFromConfigValue(flags, name, values[name])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+ * Lists set each of their elements in turn, which appends them to the flag's
+ * value as repeating the flag on the command line would.
+ */
+func setFlagFromConfigValue(flags *pflag.FlagSet, name string, value interface{}) error {
+	elements, isList := value.([]interface{})
+	if !isList {
+		elements = []interface{}{value}
+	}
+	for _, element := range elements {
+		switch element.(type) {
+		case []interface{}, map[interface{}]interface{}:
+			return errors.Errorf("Invalid value for flag %s: values must be scalars or lists of scalars", name)
+		}
+		err := flags.Set(name, fmt.Sprintf("%v", element))
+		if err != nil {
+			return errors.Errorf("Invalid value %v for flag %s: %v", element, name, err)
+		}
+	}
+	return nil
+}
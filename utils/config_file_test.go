@@ -0,0 +1,119 @@
+package utils_test
+
+import (
+	"os"
+
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/spf13/pflag"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("utils/config_file tests", func() {
+	var flagSet *pflag.FlagSet
+	BeforeEach(func() {
+		flagSet = pflag.NewFlagSet("testFlags", pflag.ContinueOnError)
+		_ = flagSet.String(utils.DBNAME, "", "")
+		_ = flagSet.Int(utils.JOBS, 1, "")
+		_ = flagSet.Bool(utils.SINGLE_DATA_FILE, false, "")
+		_ = flagSet.StringSlice(utils.INCLUDE_SCHEMA, []string{}, "")
+		_ = flagSet.String(utils.CONFIG, "", "")
+		_ = flagSet.String(utils.PROFILE, "", "")
+	})
+	Describe("SetFlagsFromConfigFile", func() {
+		contents := []byte(`
+dbname: testdb
+jobs: 4
+include-schema:
+- public
+- sales
+profiles:
+  nightly:
+    jobs: 8
+    single-data-file: true
+`)
+		It("sets flags from the top level of the file", func() {
+			Expect(utils.SetFlagsFromConfigFile(flagSet, contents, "")).To(Succeed())
+
+			Expect(utils.MustGetFlagString(flagSet, utils.DBNAME)).To(Equal("testdb"))
+			Expect(utils.MustGetFlagInt(flagSet, utils.JOBS)).To(Equal(4))
+			Expect(utils.MustGetFlagBool(flagSet, utils.SINGLE_DATA_FILE)).To(BeFalse())
+			Expect(utils.MustGetFlagStringSlice(flagSet, utils.INCLUDE_SCHEMA)).To(Equal([]string{"public", "sales"}))
+		})
+		It("overrides top-level values with those of the given profile", func() {
+			Expect(utils.SetFlagsFromConfigFile(flagSet, contents, "nightly")).To(Succeed())
+
+			Expect(utils.MustGetFlagString(flagSet, utils.DBNAME)).To(Equal("testdb"))
+			Expect(utils.MustGetFlagInt(flagSet, utils.JOBS)).To(Equal(8))
+			Expect(utils.MustGetFlagBool(flagSet, utils.SINGLE_DATA_FILE)).To(BeTrue())
+		})
+		It("does not override flags given on the command line", func() {
+			Expect(flagSet.Parse([]string{"--jobs", "2", "--include-schema", "hr"})).To(Succeed())
+
+			Expect(utils.SetFlagsFromConfigFile(flagSet, contents, "nightly")).To(Succeed())
+
+			Expect(utils.MustGetFlagInt(flagSet, utils.JOBS)).To(Equal(2))
+			Expect(utils.MustGetFlagStringSlice(flagSet, utils.INCLUDE_SCHEMA)).To(Equal([]string{"hr"}))
+		})
+		It("returns an error if the profile does not exist", func() {
+			err := utils.SetFlagsFromConfigFile(flagSet, contents, "weekly")
+
+			Expect(err).To(MatchError("Profile weekly not found"))
+		})
+		It("returns an error for an unknown flag", func() {
+			err := utils.SetFlagsFromConfigFile(flagSet, []byte("no-such-flag: true\n"), "")
+
+			Expect(err).To(MatchError("Unknown flag no-such-flag"))
+		})
+		It("returns an error if the file sets the configuration file itself", func() {
+			err := utils.SetFlagsFromConfigFile(flagSet, []byte("config: other.yaml\n"), "")
+
+			Expect(err).To(MatchError("Unknown flag config"))
+		})
+		It("ignores the notifications section", func() {
+			contents := []byte("dbname: testdb\nnotifications:\n- type: command\n  command: /bin/true\n")
+
+			Expect(utils.SetFlagsFromConfigFile(flagSet, contents, "")).To(Succeed())
+
+			Expect(utils.MustGetFlagString(flagSet, utils.DBNAME)).To(Equal("testdb"))
+		})
+		It("returns an error for an invalid value", func() {
+			err := utils.SetFlagsFromConfigFile(flagSet, []byte("jobs: many\n"), "")
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("Invalid value many for flag jobs"))
+		})
+	})
+	Describe("SetFlagsFromEnvironment", func() {
+		AfterEach(func() {
+			_ = os.Unsetenv("GPBACKUP_DBNAME")
+			_ = os.Unsetenv("GPBACKUP_SINGLE_DATA_FILE")
+		})
+		It("sets flags from environment variables", func() {
+			_ = os.Setenv("GPBACKUP_DBNAME", "envdb")
+			_ = os.Setenv("GPBACKUP_SINGLE_DATA_FILE", "true")
+
+			Expect(utils.SetFlagsFromEnvironment(flagSet, "GPBACKUP_")).To(Succeed())
+
+			Expect(utils.MustGetFlagString(flagSet, utils.DBNAME)).To(Equal("envdb"))
+			Expect(utils.MustGetFlagBool(flagSet, utils.SINGLE_DATA_FILE)).To(BeTrue())
+		})
+		It("does not override flags given on the command line", func() {
+			_ = os.Setenv("GPBACKUP_DBNAME", "envdb")
+			Expect(flagSet.Parse([]string{"--dbname", "clidb"})).To(Succeed())
+
+			Expect(utils.SetFlagsFromEnvironment(flagSet, "GPBACKUP_")).To(Succeed())
+
+			Expect(utils.MustGetFlagString(flagSet, utils.DBNAME)).To(Equal("clidb"))
+		})
+		It("takes precedence over the configuration file", func() {
+			_ = os.Setenv("GPBACKUP_DBNAME", "envdb")
+
+			Expect(utils.SetFlagsFromEnvironment(flagSet, "GPBACKUP_")).To(Succeed())
+			Expect(utils.SetFlagsFromConfigFile(flagSet, []byte("dbname: filedb\n"), "")).To(Succeed())
+
+			Expect(utils.MustGetFlagString(flagSet, utils.DBNAME)).To(Equal("envdb"))
+		})
+	})
+})
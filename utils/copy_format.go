@@ -0,0 +1,234 @@
+package utils
+
+/*
+ * This file contains the format of a backup's data files, which are written
+ * with COPY TO and must be read back with COPY FROM using the same options.
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpbackup/backup_history"
+	"github.com/pkg/errors"
+)
+
+const (
+	COPY_FORMAT_BINARY = "binary"
+	COPY_FORMAT_CSV    = "csv"
+	COPY_FORMAT_TEXT   = "text"
+)
+
+type CopyFormat struct {
+	Format     string
+	Delimiter  string
+	NullString string
+	Header     bool
+}
+
+/*
+ * An empty delimiter and a nil null string are replaced with the defaults of
+ * COPY for the format, so that the format recorded for a backup is complete.
+ * The binary format has neither.
+ */
+func NewCopyFormat(format string, delimiter string, nullString *string, header bool) CopyFormat {
+	copyFormat := CopyFormat{Format: format, Delimiter: delimiter, Header: header}
+	if format == COPY_FORMAT_BINARY {
+		if nullString != nil {
+			copyFormat.NullString = *nullString
+		}
+		return copyFormat
+	}
+	if copyFormat.Delimiter == "" {
+		copyFormat.Delimiter = ","
+		if format == COPY_FORMAT_TEXT {
+			copyFormat.Delimiter = "\t"
+		}
+	}
+	if nullString != nil {
+		copyFormat.NullString = *nullString
+	} else {
+		copyFormat.NullString = copyFormat.defaultNullString()
+	}
+	return copyFormat
+}
+
+/*
+ * Backups taken before the format of data files could be chosen record no
+ * format, and their data files are CSV delimited by commas.
+ */
+func GetCopyFormat(config *backup_history.BackupConfig) CopyFormat {
+	if config.CopyFormat == "" {
+		return NewCopyFormat(COPY_FORMAT_CSV, "", nil, false)
+	}
+	return CopyFormat{Format: config.CopyFormat, Delimiter: config.CopyDelimiter, NullString: config.CopyNullString, Header: config.CopyHeader}
+}
+
+func (copyFormat CopyFormat) defaultNullString() string {
+	if copyFormat.Format == COPY_FORMAT_TEXT {
+		return `\N`
+	}
+	return ""
+}
+
+func (copyFormat CopyFormat) Validate() error {
+	switch copyFormat.Format {
+	case COPY_FORMAT_CSV, COPY_FORMAT_TEXT:
+	case COPY_FORMAT_BINARY:
+		if copyFormat.Delimiter != "" || copyFormat.NullString != "" || copyFormat.Header {
+			return errors.Errorf("--copy-delimiter, --copy-null-string, and --copy-header cannot be used with --copy-format %s", COPY_FORMAT_BINARY)
+		}
+		return nil
+	default:
+		return errors.Errorf("--copy-format must be one of %s, %s, or %s", COPY_FORMAT_CSV, COPY_FORMAT_TEXT, COPY_FORMAT_BINARY)
+	}
+	if len(copyFormat.Delimiter) != 1 || strings.ContainsAny(copyFormat.Delimiter, "\r\n\\\"") {
+		return errors.Errorf("--copy-delimiter must be a single character other than a newline, carriage return, backslash, or double quote")
+	}
+	if strings.Contains(copyFormat.NullString, copyFormat.Delimiter) {
+		return errors.Errorf("--copy-null-string must not contain the delimiter")
+	}
+	if copyFormat.Header && copyFormat.Format != COPY_FORMAT_CSV {
+		return errors.Errorf("--copy-header requires --copy-format %s", COPY_FORMAT_CSV)
+	}
+	return nil
+}
+
+/*
+ * Returns the options following WITH in COPY commands.  The null string is
+ * only given when it is not the default, so that backups in the default format
+ * use the same COPY commands as backups taken before the format could be
+ * chosen.
+ */
+func (copyFormat CopyFormat) Options() string {
+	if copyFormat.Format == COPY_FORMAT_BINARY {
+		return "BINARY"
+	}
+	options := ""
+	if copyFormat.Format == COPY_FORMAT_CSV {
+		options = "CSV "
+		if copyFormat.Header {
+			options += "HEADER "
+		}
+	}
+	options += fmt.Sprintf("DELIMITER '%s'", escapeCopyOption(copyFormat.Delimiter))
+	if copyFormat.NullString != copyFormat.defaultNullString() {
+		options += fmt.Sprintf(" NULL '%s'", escapeCopyOption(copyFormat.NullString))
+	}
+	return options
+}
+
+/*
+ * Binary data files hold the send and receive representations of values,
+ * which can change between major versions, so they are only loaded into a
+ * database of the same major version as the one that was backed up.
+ */
+func EnsureCopyFormatCompatibility(copyFormat CopyFormat, backupGPDBVersion string, restoreGPDBVersion dbconn.GPDBVersion) {
+	if copyFormat.Format != COPY_FORMAT_BINARY {
+		return
+	}
+	threeDigitVersion := regexp.MustCompile(`\d+\.\d+\.\d+`).FindString(backupGPDBVersion)
+	backupGPDBSemVer, err := semver.Make(threeDigitVersion)
+	gplog.FatalOnError(err)
+	if backupGPDBSemVer.Major != restoreGPDBVersion.SemVer.Major {
+		gplog.Fatal(errors.Errorf("Cannot restore data backed up with --copy-format %s from GPDB version %s to %s, because the binary representations of values may differ between major versions.", COPY_FORMAT_BINARY, backupGPDBVersion, restoreGPDBVersion.VersionString), "")
+	}
+}
+
+func escapeCopyOption(value string) string {
+	return strings.Replace(value, "'", "''", -1)
+}
+
+/*
+ * Reads the raw bytes of one record of a text or CSV data file, including its
+ * terminating newline.  Text data escapes newlines within values, but CSV data
+ * quotes them, so a newline only ends a CSV record outside of quotes.  Returns
+ * io.EOF once there are no more records.
+ */
+func ReadCopyRecord(reader *bufio.Reader, format string) ([]byte, error) {
+	record := make([]byte, 0)
+	inQuotes := false
+	for {
+		line, err := reader.ReadBytes('\n')
+		record = append(record, line...)
+		if err == io.EOF {
+			if len(record) == 0 {
+				return nil, io.EOF
+			}
+			if inQuotes != (format == COPY_FORMAT_CSV && bytes.Count(line, []byte{'"'})%2 == 1) {
+				return nil, errors.New("Unterminated quoted field at end of CSV data")
+			}
+			return append(record, '\n'), nil
+		} else if err != nil {
+			return nil, err
+		}
+		if format == COPY_FORMAT_CSV && bytes.Count(line, []byte{'"'})%2 == 1 {
+			inQuotes = !inQuotes
+		}
+		if !inQuotes {
+			return record, nil
+		}
+	}
+}
+
+/*
+ * Copies the header of the data read from the reader, if it has one, and a
+ * random sample of up to numRows of its records to the writer, in the order
+ * in which they were read.  Returns the number of records in the sample.
+ */
+func SampleCopyRecords(reader io.Reader, writer io.Writer, copyFormat CopyFormat, numRows int, random *rand.Rand) (int64, error) {
+	bufferedReader := bufio.NewReaderSize(reader, 1024*1024)
+	var header []byte
+	if copyFormat.Header {
+		var err error
+		header, err = ReadCopyRecord(bufferedReader, copyFormat.Format)
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+	}
+	// Reservoir sampling keeps each record with equal probability in one pass
+	sample := make([][]byte, 0, numRows)
+	positions := make([]int, 0, numRows)
+	for numRead := 0; ; numRead++ {
+		record, err := ReadCopyRecord(bufferedReader, copyFormat.Format)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return 0, err
+		}
+		if len(sample) < numRows {
+			sample = append(sample, record)
+			positions = append(positions, numRead)
+		} else if replace := random.Intn(numRead + 1); replace < numRows {
+			sample[replace] = record
+			positions[replace] = numRead
+		}
+	}
+	sort.Sort(sampledRecords{sample, positions})
+	_, err := writer.Write(header)
+	for i := 0; i < len(sample) && err == nil; i++ {
+		_, err = writer.Write(sample[i])
+	}
+	return int64(len(sample)), err
+}
+
+type sampledRecords struct {
+	records   [][]byte
+	positions []int
+}
+
+func (s sampledRecords) Len() int           { return len(s.records) }
+func (s sampledRecords) Less(i, j int) bool { return s.positions[i] < s.positions[j] }
+func (s sampledRecords) Swap(i, j int) {
+	s.records[i], s.records[j] = s.records[j], s.records[i]
+	s.positions[i], s.positions[j] = s.positions[j], s.positions[i]
+}
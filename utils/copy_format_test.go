@@ -0,0 +1,148 @@
+package utils_test
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"math/rand"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"github.com/greenplum-db/gpbackup/backup_history"
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("utils/copy_format tests", func() {
+	Describe("NewCopyFormat", func() {
+		It("uses the defaults of csv", func() {
+			Expect(utils.NewCopyFormat(utils.COPY_FORMAT_CSV, "", nil, false)).To(Equal(utils.CopyFormat{Format: "csv", Delimiter: ",", NullString: ""}))
+		})
+		It("uses the defaults of text", func() {
+			Expect(utils.NewCopyFormat(utils.COPY_FORMAT_TEXT, "", nil, false)).To(Equal(utils.CopyFormat{Format: "text", Delimiter: "\t", NullString: `\N`}))
+		})
+		It("has no delimiter or null string for binary", func() {
+			Expect(utils.NewCopyFormat(utils.COPY_FORMAT_BINARY, "", nil, false)).To(Equal(utils.CopyFormat{Format: "binary"}))
+		})
+		It("keeps a given empty null string", func() {
+			nullString := ""
+			Expect(utils.NewCopyFormat(utils.COPY_FORMAT_TEXT, "|", &nullString, false)).To(Equal(utils.CopyFormat{Format: "text", Delimiter: "|", NullString: ""}))
+		})
+	})
+	Describe("GetCopyFormat", func() {
+		It("uses csv delimited by commas for backups without a recorded format", func() {
+			Expect(utils.GetCopyFormat(&backup_history.BackupConfig{})).To(Equal(utils.CopyFormat{Format: "csv", Delimiter: ","}))
+		})
+		It("uses the recorded format", func() {
+			config := backup_history.BackupConfig{CopyFormat: "csv", CopyDelimiter: "|", CopyNullString: "NULL", CopyHeader: true}
+			Expect(utils.GetCopyFormat(&config)).To(Equal(utils.CopyFormat{Format: "csv", Delimiter: "|", NullString: "NULL", Header: true}))
+		})
+	})
+	Describe("Validate", func() {
+		It("accepts a valid format", func() {
+			Expect(utils.NewCopyFormat(utils.COPY_FORMAT_CSV, "|", nil, true).Validate()).To(Succeed())
+		})
+		It("rejects an unknown format", func() {
+			Expect(utils.NewCopyFormat("binary", "", nil, false).Validate()).To(MatchError("--copy-format must be one of csv, text, or binary"))
+		})
+		It("rejects a delimiter of more than one character", func() {
+			Expect(utils.NewCopyFormat(utils.COPY_FORMAT_CSV, "||", nil, false).Validate()).To(MatchError(ContainSubstring("--copy-delimiter must be a single character")))
+		})
+		It("rejects a null string containing the delimiter", func() {
+			nullString := "a,b"
+			Expect(utils.NewCopyFormat(utils.COPY_FORMAT_CSV, "", &nullString, false).Validate()).To(MatchError("--copy-null-string must not contain the delimiter"))
+		})
+		It("rejects text options for binary", func() {
+			Expect(utils.NewCopyFormat(utils.COPY_FORMAT_BINARY, "|", nil, false).Validate()).To(MatchError("--copy-delimiter, --copy-null-string, and --copy-header cannot be used with --copy-format binary"))
+		})
+		It("rejects a header for text", func() {
+			Expect(utils.NewCopyFormat(utils.COPY_FORMAT_TEXT, "", nil, true).Validate()).To(MatchError("--copy-header requires --copy-format csv"))
+		})
+	})
+	Describe("Options", func() {
+		It("gives only the delimiter for the default csv format", func() {
+			Expect(utils.NewCopyFormat(utils.COPY_FORMAT_CSV, "", nil, false).Options()).To(Equal("CSV DELIMITER ','"))
+		})
+		It("gives the header and a null string other than the default", func() {
+			nullString := "NULL"
+			Expect(utils.NewCopyFormat(utils.COPY_FORMAT_CSV, "|", &nullString, true).Options()).To(Equal("CSV HEADER DELIMITER '|' NULL 'NULL'"))
+		})
+		It("gives only BINARY for binary", func() {
+			Expect(utils.NewCopyFormat(utils.COPY_FORMAT_BINARY, "", nil, false).Options()).To(Equal("BINARY"))
+		})
+		It("gives text options without CSV", func() {
+			nullString := "it's null"
+			Expect(utils.NewCopyFormat(utils.COPY_FORMAT_TEXT, "", &nullString, false).Options()).To(Equal("DELIMITER '\t' NULL 'it''s null'"))
+		})
+	})
+	Describe("EnsureCopyFormatCompatibility", func() {
+		var restoreVersion dbconn.GPDBVersion
+		BeforeEach(func() {
+			semver, _ := semver.Make("6.0.0")
+			restoreVersion = dbconn.GPDBVersion{VersionString: "6.0.0 build dev", SemVer: semver}
+		})
+		It("panics when binary data is restored to another major version", func() {
+			defer testhelper.ShouldPanicWithMessage("Cannot restore data backed up with --copy-format binary from GPDB version 5.28.0 build dev to 6.0.0 build dev")
+			utils.EnsureCopyFormatCompatibility(utils.NewCopyFormat(utils.COPY_FORMAT_BINARY, "", nil, false), "5.28.0 build dev", restoreVersion)
+		})
+		It("does not panic when binary data is restored to the same major version", func() {
+			utils.EnsureCopyFormatCompatibility(utils.NewCopyFormat(utils.COPY_FORMAT_BINARY, "", nil, false), "6.20.3 build dev", restoreVersion)
+		})
+		It("does not panic when csv data is restored to another major version", func() {
+			utils.EnsureCopyFormatCompatibility(utils.NewCopyFormat(utils.COPY_FORMAT_CSV, "", nil, false), "5.28.0 build dev", restoreVersion)
+		})
+	})
+	Describe("ReadCopyRecord", func() {
+		It("reads text records one line at a time", func() {
+			reader := bufio.NewReader(strings.NewReader("1\ta\\nb\n2\t\"c\n"))
+			Expect(utils.ReadCopyRecord(reader, utils.COPY_FORMAT_TEXT)).To(Equal([]byte("1\ta\\nb\n")))
+			Expect(utils.ReadCopyRecord(reader, utils.COPY_FORMAT_TEXT)).To(Equal([]byte("2\t\"c\n")))
+			_, err := utils.ReadCopyRecord(reader, utils.COPY_FORMAT_TEXT)
+			Expect(err).To(Equal(io.EOF))
+		})
+		It("reads CSV records with quoted newlines and quotes", func() {
+			reader := bufio.NewReader(strings.NewReader("1,\"a\nb\"\n2,\"c\"\"\n\"\n3,d"))
+			Expect(utils.ReadCopyRecord(reader, utils.COPY_FORMAT_CSV)).To(Equal([]byte("1,\"a\nb\"\n")))
+			Expect(utils.ReadCopyRecord(reader, utils.COPY_FORMAT_CSV)).To(Equal([]byte("2,\"c\"\"\n\"\n")))
+			Expect(utils.ReadCopyRecord(reader, utils.COPY_FORMAT_CSV)).To(Equal([]byte("3,d\n")))
+			_, err := utils.ReadCopyRecord(reader, utils.COPY_FORMAT_CSV)
+			Expect(err).To(Equal(io.EOF))
+		})
+		It("returns an error for an unterminated quoted field", func() {
+			reader := bufio.NewReader(strings.NewReader("1,\"a\nb"))
+			_, err := utils.ReadCopyRecord(reader, utils.COPY_FORMAT_CSV)
+			Expect(err).To(MatchError("Unterminated quoted field at end of CSV data"))
+		})
+	})
+	Describe("SampleCopyRecords", func() {
+		csvFormat := utils.NewCopyFormat(utils.COPY_FORMAT_CSV, "", nil, false)
+		It("copies every record when there are fewer than the sample size", func() {
+			buffer := bytes.Buffer{}
+			numRows, err := utils.SampleCopyRecords(strings.NewReader("1,a\n2,\"b\nc\"\n"), &buffer, csvFormat, 5, rand.New(rand.NewSource(0)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(numRows).To(Equal(int64(2)))
+			Expect(buffer.String()).To(Equal("1,a\n2,\"b\nc\"\n"))
+		})
+		It("copies a sample of the records in the order they were read", func() {
+			buffer := bytes.Buffer{}
+			numRows, err := utils.SampleCopyRecords(strings.NewReader("1\n2\n3\n4\n5\n6\n7\n8\n"), &buffer, csvFormat, 3, rand.New(rand.NewSource(0)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(numRows).To(Equal(int64(3)))
+			records := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+			Expect(records).To(HaveLen(3))
+			Expect(records[0] < records[1] && records[1] < records[2]).To(BeTrue())
+		})
+		It("copies the header before the sample", func() {
+			headerFormat := utils.NewCopyFormat(utils.COPY_FORMAT_CSV, "", nil, true)
+			buffer := bytes.Buffer{}
+			numRows, err := utils.SampleCopyRecords(strings.NewReader("i,j\n1,a\n"), &buffer, headerFormat, 1, rand.New(rand.NewSource(0)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(numRows).To(Equal(int64(1)))
+			Expect(buffer.String()).To(Equal("i,j\n1,a\n"))
+		})
+	})
+})
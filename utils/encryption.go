@@ -0,0 +1,129 @@
+package utils
+
+/*
+ * This file contains functions for encrypting metadata, statistics, and TOC
+ * files with --metadata-encryption-key-file.
+ */
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"io"
+	"strings"
+
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/pkg/errors"
+)
+
+var (
+	metadataEncryptionKey []byte
+)
+
+/*
+ * An encrypted file is this header, followed by a random nonce and the file's
+ * contents encrypted with AES-256-GCM.  Like compressed files, encrypted files
+ * keep their usual names, so readers detect encryption from the header.
+ */
+const encryptedFileHeader = "GPBACKUP_AES256_GCM\n"
+
+/*
+ * The key is the SHA-256 hash of the passphrase in the key file, so any
+ * passphrase can be used, and leading and trailing whitespace such as a final
+ * newline does not change the key.
+ */
+func ReadMetadataEncryptionKeyFile(filename string) ([]byte, error) {
+	contents, err := operating.System.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	passphrase := strings.TrimSpace(string(contents))
+	if passphrase == "" {
+		return nil, errors.Errorf("Encryption key file %s is empty", filename)
+	}
+	key := sha256.Sum256([]byte(passphrase))
+	return key[:], nil
+}
+
+func SetMetadataEncryptionKey(key []byte) {
+	metadataEncryptionKey = key
+}
+
+func GetMetadataEncryptionKey() []byte {
+	return metadataEncryptionKey
+}
+
+func IsEncrypted(contents []byte) bool {
+	return bytes.HasPrefix(contents, []byte(encryptedFileHeader))
+}
+
+func EncryptBytes(contents []byte, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, err
+	}
+	encrypted := append([]byte(encryptedFileHeader), nonce...)
+	return gcm.Seal(encrypted, nonce, contents, nil), nil
+}
+
+func DecryptBytes(contents []byte, key []byte) ([]byte, error) {
+	if key == nil {
+		return nil, errors.New("File is encrypted; specify the key with --metadata-encryption-key-file")
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	encrypted := contents[len(encryptedFileHeader):]
+	if len(encrypted) < gcm.NonceSize() {
+		return nil, errors.New("Encrypted file is truncated")
+	}
+	decrypted, err := gcm.Open(nil, encrypted[:gcm.NonceSize()], encrypted[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("Unable to decrypt file; the encryption key may be incorrect or the file may be corrupt")
+	}
+	return decrypted, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+/*
+ * AES-GCM authenticates the whole file at once, so the contents of an
+ * encrypted file are collected in memory, compressed along the way if
+ * gzipWriter is set, and encrypted and written out when the file is closed.
+ */
+type encryptingFileCloser struct {
+	plaintext  bytes.Buffer
+	gzipWriter io.WriteCloser
+	file       io.WriteCloser
+	key        []byte
+}
+
+func (closer *encryptingFileCloser) Close() error {
+	defer closer.file.Close()
+	if closer.gzipWriter != nil {
+		err := closer.gzipWriter.Close()
+		if err != nil {
+			return err
+		}
+	}
+	encrypted, err := EncryptBytes(closer.plaintext.Bytes(), closer.key)
+	if err != nil {
+		return err
+	}
+	_, err = closer.file.Write(encrypted)
+	return err
+}
@@ -0,0 +1,79 @@
+package utils_test
+
+import (
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("utils/encryption tests", func() {
+	key := make([]byte, 32)
+	AfterEach(func() {
+		operating.System = operating.InitializeSystemFunctions()
+		utils.SetMetadataEncryptionKey(nil)
+	})
+	Describe("ReadMetadataEncryptionKeyFile", func() {
+		It("derives the same key from a passphrase regardless of surrounding whitespace", func() {
+			operating.System.ReadFile = func(filename string) ([]byte, error) { return []byte("secret\n"), nil }
+			withNewline, err := utils.ReadMetadataEncryptionKeyFile("keyfile")
+			Expect(err).ToNot(HaveOccurred())
+			operating.System.ReadFile = func(filename string) ([]byte, error) { return []byte("secret"), nil }
+			withoutNewline, _ := utils.ReadMetadataEncryptionKeyFile("keyfile")
+
+			Expect(withNewline).To(HaveLen(32))
+			Expect(withNewline).To(Equal(withoutNewline))
+		})
+		It("returns an error for an empty key file", func() {
+			operating.System.ReadFile = func(filename string) ([]byte, error) { return []byte("\n"), nil }
+			_, err := utils.ReadMetadataEncryptionKeyFile("keyfile")
+			Expect(err).To(MatchError("Encryption key file keyfile is empty"))
+		})
+	})
+	Describe("EncryptBytes", func() {
+		It("encrypts contents that can be decrypted with the same key", func() {
+			encrypted, err := utils.EncryptBytes([]byte("CREATE ROLE testrole;"), key)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(utils.IsEncrypted(encrypted)).To(BeTrue())
+			Expect(string(encrypted)).ToNot(ContainSubstring("testrole"))
+
+			decrypted, err := utils.DecryptBytes(encrypted, key)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(decrypted)).To(Equal("CREATE ROLE testrole;"))
+		})
+		It("fails to decrypt contents with another key", func() {
+			encrypted, _ := utils.EncryptBytes([]byte("CREATE ROLE testrole;"), key)
+			otherKey := make([]byte, 32)
+			otherKey[0] = 1
+			_, err := utils.DecryptBytes(encrypted, otherKey)
+			Expect(err).To(MatchError("Unable to decrypt file; the encryption key may be incorrect or the file may be corrupt"))
+		})
+		It("fails to decrypt contents without a key", func() {
+			encrypted, _ := utils.EncryptBytes([]byte("CREATE ROLE testrole;"), key)
+			_, err := utils.DecryptBytes(encrypted, nil)
+			Expect(err).To(MatchError("File is encrypted; specify the key with --metadata-encryption-key-file"))
+		})
+	})
+	Describe("ReadFileDecompressingIfNeeded", func() {
+		It("decrypts and decompresses a compressed, encrypted file", func() {
+			compressed, _ := utils.CompressBytes([]byte("dataentries: []\n"), 1)
+			encrypted, _ := utils.EncryptBytes(compressed, key)
+			operating.System.ReadFile = func(filename string) ([]byte, error) { return encrypted, nil }
+			utils.SetMetadataEncryptionKey(key)
+
+			contents, err := utils.ReadFileDecompressingIfNeeded("toc.yaml")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal("dataentries: []\n"))
+		})
+		It("returns an error for an encrypted file when no key is set", func() {
+			encrypted, _ := utils.EncryptBytes([]byte("dataentries: []\n"), key)
+			operating.System.ReadFile = func(filename string) ([]byte, error) { return encrypted, nil }
+
+			_, err := utils.ReadFileDecompressingIfNeeded("toc.yaml")
+
+			Expect(err).To(MatchError("Unable to read toc.yaml: File is encrypted; specify the key with --metadata-encryption-key-file"))
+		})
+	})
+})
@@ -0,0 +1,94 @@
+package utils
+
+/*
+ * This file contains the exit codes shared by gpbackup and gprestore, and
+ * functions for classifying a fatal error into one of them so that scripts
+ * running either utility can tell why it failed.
+ */
+
+import (
+	"regexp"
+)
+
+/*
+ * A fatal error exits with 1 and a run that completed despite logging errors
+ * exits with 2.  This is the reverse of the error codes gplog sets, 1 when an
+ * error is logged and 2 on a fatal error, so gplog's code is converted with
+ * ExitCodeFromErrorCode when gpbackup or gprestore exits.
+ */
+const (
+	EXIT_SUCCESS               = 0
+	EXIT_FATAL                 = 1
+	EXIT_COMPLETED_WITH_ERRORS = 2
+	EXIT_LOCK_TIMEOUT          = 3
+	EXIT_DISK_FULL             = 4
+	EXIT_CONNECTION_LOST       = 5
+	EXIT_TERMINATED            = 6
+)
+
+const (
+	gplogErrorCode = 1
+	gplogFatalCode = 2
+)
+
+var (
+	lockTimeoutRegex    = regexp.MustCompile(`(?i)lock timeout|could not obtain lock|SQLSTATE 55P03`)
+	diskFullRegex       = regexp.MustCompile(`(?i)no space left on device|could not extend file|disk quota exceeded|SQLSTATE 53100`)
+	connectionLostRegex = regexp.MustCompile(`(?i)connection reset by peer|broken pipe|server closed the connection|terminating connection|connection refused|conn closed|unexpected EOF|SQLSTATE (08|57P0[1-3])`)
+)
+
+/*
+ * Returns the exit code for the message of a fatal error, or EXIT_FATAL if
+ * the error does not match a more specific cause.
+ */
+func ClassifyFatalError(errStr string) int {
+	switch {
+	case lockTimeoutRegex.MatchString(errStr):
+		return EXIT_LOCK_TIMEOUT
+	case diskFullRegex.MatchString(errStr):
+		return EXIT_DISK_FULL
+	case connectionLostRegex.MatchString(errStr):
+		return EXIT_CONNECTION_LOST
+	default:
+		return EXIT_FATAL
+	}
+}
+
+/*
+ * Converts the error code set by gplog into the exit code of the utility.  The
+ * codes for specific causes, which are set on gplog directly, are unchanged.
+ */
+func ExitCodeFromErrorCode(errorCode int) int {
+	switch errorCode {
+	case gplogErrorCode:
+		return EXIT_COMPLETED_WITH_ERRORS
+	case gplogFatalCode:
+		return EXIT_FATAL
+	default:
+		return errorCode
+	}
+}
+
+// Whether the exit code is that of a run that did not complete
+func IsFailureExitCode(exitCode int) bool {
+	return exitCode != EXIT_SUCCESS && exitCode != EXIT_COMPLETED_WITH_ERRORS
+}
+
+func ExitCodeDescription(exitCode int) string {
+	switch exitCode {
+	case EXIT_SUCCESS:
+		return "success"
+	case EXIT_COMPLETED_WITH_ERRORS:
+		return "completed with non-fatal errors"
+	case EXIT_LOCK_TIMEOUT:
+		return "failed to acquire a lock"
+	case EXIT_DISK_FULL:
+		return "ran out of disk space"
+	case EXIT_CONNECTION_LOST:
+		return "lost connection to the database"
+	case EXIT_TERMINATED:
+		return "terminated by a signal"
+	default:
+		return "fatal error"
+	}
+}
@@ -0,0 +1,57 @@
+package utils_test
+
+import (
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("utils/exit_code tests", func() {
+	Describe("ClassifyFatalError", func() {
+		It("classifies a lock timeout", func() {
+			Expect(utils.ClassifyFatalError("ERROR: canceling statement due to lock timeout (SQLSTATE 55P03)")).To(Equal(utils.EXIT_LOCK_TIMEOUT))
+		})
+		It("classifies a full disk", func() {
+			Expect(utils.ClassifyFatalError("write /data/backups/gpbackup_20180101010101_metadata.sql: no space left on device")).To(Equal(utils.EXIT_DISK_FULL))
+			Expect(utils.ClassifyFatalError(`ERROR: could not extend file "base/16384/16385": wrote only 4096 of 8192 bytes (SQLSTATE 53100)`)).To(Equal(utils.EXIT_DISK_FULL))
+		})
+		It("classifies a lost connection", func() {
+			Expect(utils.ClassifyFatalError("read tcp 127.0.0.1:5432: read: connection reset by peer")).To(Equal(utils.EXIT_CONNECTION_LOST))
+			Expect(utils.ClassifyFatalError("FATAL: terminating connection due to administrator command (SQLSTATE 57P01)")).To(Equal(utils.EXIT_CONNECTION_LOST))
+		})
+		It("classifies any other error as fatal", func() {
+			Expect(utils.ClassifyFatalError(`ERROR: relation "public.foo" does not exist (SQLSTATE 42P01)`)).To(Equal(utils.EXIT_FATAL))
+		})
+	})
+	Describe("ExitCodeFromErrorCode", func() {
+		It("exits with 1 on a fatal error and 2 when errors were logged", func() {
+			Expect(utils.ExitCodeFromErrorCode(0)).To(Equal(utils.EXIT_SUCCESS))
+			Expect(utils.ExitCodeFromErrorCode(1)).To(Equal(utils.EXIT_COMPLETED_WITH_ERRORS))
+			Expect(utils.ExitCodeFromErrorCode(2)).To(Equal(utils.EXIT_FATAL))
+			Expect(utils.EXIT_FATAL).To(Equal(1))
+			Expect(utils.EXIT_COMPLETED_WITH_ERRORS).To(Equal(2))
+		})
+		It("keeps the codes for specific causes", func() {
+			Expect(utils.ExitCodeFromErrorCode(utils.EXIT_LOCK_TIMEOUT)).To(Equal(utils.EXIT_LOCK_TIMEOUT))
+			Expect(utils.ExitCodeFromErrorCode(utils.EXIT_TERMINATED)).To(Equal(utils.EXIT_TERMINATED))
+		})
+	})
+	Describe("IsFailureExitCode", func() {
+		It("does not treat a run that completed with errors as failed", func() {
+			Expect(utils.IsFailureExitCode(utils.EXIT_SUCCESS)).To(BeFalse())
+			Expect(utils.IsFailureExitCode(utils.EXIT_COMPLETED_WITH_ERRORS)).To(BeFalse())
+			Expect(utils.IsFailureExitCode(utils.EXIT_FATAL)).To(BeTrue())
+			Expect(utils.IsFailureExitCode(utils.EXIT_DISK_FULL)).To(BeTrue())
+		})
+	})
+	Describe("ExitCodeDescription", func() {
+		It("describes each exit code", func() {
+			Expect(utils.ExitCodeDescription(utils.EXIT_SUCCESS)).To(Equal("success"))
+			Expect(utils.ExitCodeDescription(utils.EXIT_COMPLETED_WITH_ERRORS)).To(Equal("completed with non-fatal errors"))
+			Expect(utils.ExitCodeDescription(utils.EXIT_FATAL)).To(Equal("fatal error"))
+			Expect(utils.ExitCodeDescription(utils.EXIT_CONNECTION_LOST)).To(Equal("lost connection to the database"))
+			Expect(utils.ExitCodeDescription(utils.EXIT_TERMINATED)).To(Equal("terminated by a signal"))
+		})
+	})
+})
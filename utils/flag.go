@@ -14,33 +14,115 @@ import (
 )
 
 const (
-	BACKUP_DIR            = "backup-dir"
-	COMPRESSION_LEVEL     = "compression-level"
-	DATA_ONLY             = "data-only"
-	DBNAME                = "dbname"
-	DEBUG                 = "debug"
-	EXCLUDE_RELATION      = "exclude-table"
-	EXCLUDE_RELATION_FILE = "exclude-table-file"
-	EXCLUDE_SCHEMA        = "exclude-schema"
-	FROM_TIMESTAMP        = "from-timestamp"
-	INCLUDE_RELATION      = "include-table"
-	INCLUDE_RELATION_FILE = "include-table-file"
-	INCLUDE_SCHEMA        = "include-schema"
-	INCREMENTAL           = "incremental"
-	JOBS                  = "jobs"
-	LEAF_PARTITION_DATA   = "leaf-partition-data"
-	METADATA_ONLY         = "metadata-only"
-	NO_COMPRESSION        = "no-compression"
-	PLUGIN_CONFIG         = "plugin-config"
-	QUIET                 = "quiet"
-	SINGLE_DATA_FILE      = "single-data-file"
-	VERBOSE               = "verbose"
-	WITH_STATS            = "with-stats"
-	CREATE_DB             = "create-db"
-	ON_ERROR_CONTINUE     = "on-error-continue"
-	REDIRECT_DB           = "redirect-db"
-	TIMESTAMP             = "timestamp"
-	WITH_GLOBALS          = "with-globals"
+	ADAPTIVE_COMPRESSION         = "adaptive-compression"
+	ALL_DATABASES                = "all-databases"
+	ARCHIVE_FILE                 = "archive-file"
+	BACKUP_DIR                   = "backup-dir"
+	BACKUP_GUC                   = "backup-guc"
+	CHECK_CATALOG                = "check-catalog"
+	COMPRESSION_LEVEL            = "compression-level"
+	COMPRESS_METADATA            = "compress-metadata"
+	CONFIG                       = "config"
+	CONNECTION_OPTIONS           = "connection-options"
+	COPY_BUFFER_SIZE             = "copy-buffer-size"
+	COPY_DELIMITER               = "copy-delimiter"
+	COPY_FORMAT                  = "copy-format"
+	COPY_HEADER                  = "copy-header"
+	COPY_NULL_STRING             = "copy-null-string"
+	COPY_RETRIES                 = "copy-retries"
+	DATA_ONLY                    = "data-only"
+	DBNAME                       = "dbname"
+	DEBUG                        = "debug"
+	DETERMINISTIC                = "deterministic"
+	DRY_RUN                      = "dry-run"
+	EXCLUDE_COLUMN               = "exclude-column"
+	EXCLUDE_COLUMN_DDL           = "exclude-column-ddl"
+	EXCLUDE_LEAF_PARTITION       = "exclude-leaf-partition"
+	EXCLUDE_MONITORING_SCHEMAS   = "exclude-monitoring-schemas"
+	EXCLUDE_RELATION             = "exclude-table"
+	EXCLUDE_RELATION_FILE        = "exclude-table-file"
+	EXCLUDE_SCHEMA               = "exclude-schema"
+	EXCLUDE_SCHEMA_FILE          = "exclude-schema-file"
+	FROM_TIMESTAMP               = "from-timestamp"
+	INCLUDE_INTERNAL_ARTIFACTS   = "include-internal-artifacts"
+	INCLUDE_LEAF_PARTITION       = "include-leaf-partition"
+	INCLUDE_RELATION             = "include-table"
+	INCLUDE_RESOURCE_QUEUE       = "include-resource-queue"
+	INCLUDE_ROLE                 = "include-role"
+	INCLUDE_RELATION_FILE        = "include-table-file"
+	INCLUDE_SCHEMA               = "include-schema"
+	INCLUDE_SCHEMA_FILE          = "include-schema-file"
+	INCLUDE_TABLESPACE           = "include-tablespace"
+	INCREMENTAL                  = "incremental"
+	JOBS                         = "jobs"
+	LARGE_ROW_THRESHOLD          = "large-row-threshold"
+	LEAF_PARTITION_DATA          = "leaf-partition-data"
+	LEAF_PARTITION_KEY_END       = "leaf-partition-key-end"
+	LEAF_PARTITION_KEY_START     = "leaf-partition-key-start"
+	LINK_UNCHANGED_DATA          = "link-unchanged-data"
+	LOCATION_MAP                 = "location-map"
+	LOCATION_MAP_FILE            = "location-map-file"
+	LOCK_DATA_TABLES_ONLY        = "lock-data-tables-only"
+	MASKING_RULES_FILE           = "masking-rules-file"
+	METADATA_ENCRYPTION_KEY_FILE = "metadata-encryption-key-file"
+	METADATA_ONLY                = "metadata-only"
+	METRICS_ADDRESS              = "metrics-address"
+	MONITORING_SCHEMA_FILE       = "monitoring-schema-file"
+	NO_COMPRESSION               = "no-compression"
+	NO_MANIFEST_CHECKSUMS        = "no-manifest-checksums"
+	NO_OWNER                     = "no-owner"
+	NO_PRIVILEGES                = "no-privileges"
+	NO_TABLESPACES               = "no-tablespaces"
+	OWNER_MAP                    = "owner-map"
+	PARQUET_EXPORT               = "parquet-export"
+	PLUGIN_CONFIG                = "plugin-config"
+	PROFILE                      = "profile"
+	PROGRESS_FILE                = "progress-file"
+	PROGRESS_FORMAT              = "progress-format"
+	QUERY_TIMEOUT                = "query-timeout"
+	QUIET                        = "quiet"
+	REMOVE_ORPHANED_BACKUPS      = "remove-orphaned-backups"
+	SAMPLE_PERCENT               = "sample-percent"
+	SEGMENT_COUNT                = "segment-count"
+	SINGLE_DATA_FILE             = "single-data-file"
+	SMALL_TABLE_BATCH_SIZE       = "small-table-batch-size"
+	SPLIT_METADATA               = "split-metadata"
+	STRICT                       = "strict"
+	TABLESPACE_MAP               = "tablespace-map"
+	TABLESPACE_MAP_FILE          = "tablespace-map-file"
+	TARGET                       = "target"
+	TERMINATE_LEAKED_SESSIONS    = "terminate-leaked-sessions"
+	UTILITY_MODE                 = "utility-mode"
+	VERBOSE                      = "verbose"
+	VERIFICATION_QUERIES         = "verification-queries"
+	VERIFY_DATA_SAMPLE           = "verify-data-sample"
+	WITH_EXTERNAL_DATA           = "with-external-data"
+	WITH_LARGE_OBJECTS           = "with-large-objects"
+	WITH_STATS                   = "with-stats"
+	ALLOW_FILTERED_RESTORE       = "allow-filtered-restore"
+	CASCADE                      = "cascade"
+	CLEAN                        = "clean"
+	CREATE_DB                    = "create-db"
+	DATA_FILTER_FILE             = "data-filter-file"
+	DATA_TIMESTAMP               = "data-timestamp"
+	DEFERRED_INDEX_FILE          = "deferred-index-file"
+	FAST_LOAD                    = "fast-load"
+	IF_EXISTS                    = "if-exists"
+	NO_MATVIEW_REFRESH           = "no-matview-refresh"
+	ON_ERROR_CONTINUE            = "on-error-continue"
+	POSTDATA_OBJECT_TYPE         = "postdata-object-type"
+	POSTDATA_ONLY                = "postdata-only"
+	REDIRECT_DB                  = "redirect-db"
+	REDIRECT_SCHEMA              = "redirect-schema"
+	RESTORE_GUC                  = "restore-guc"
+	RESUME                       = "resume"
+	SINGLE_TRANSACTION           = "single-transaction"
+	SKIP_INDEXES                 = "skip-indexes"
+	TIMESTAMP                    = "timestamp"
+	TRUNCATE_TARGET              = "truncate-target"
+	VERIFY_ROW_COUNTS            = "verify-row-counts"
+	VERIFY_SAMPLE_SIZE           = "verify-sample-size"
+	WITH_GLOBALS                 = "with-globals"
 )
 
 /*
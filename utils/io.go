@@ -6,9 +6,14 @@ package utils
  */
 
 import (
+	"bufio"
+	"compress/gzip"
 	"fmt"
+	"hash"
+	"hash/crc32"
 	"io"
 	"io/ioutil"
+	"os"
 	"strings"
 
 	"github.com/greenplum-db/gp-common-go-libs/dbconn"
@@ -87,30 +92,112 @@ func MustPrintBytes(file io.Writer, bytes []byte) uint64 {
  * Structs and functions for file readers/writers that track bytes read/written
  */
 
+/*
+ * Metadata is written as many small statements, so writes to a file are
+ * buffered and reach it in large batches.  ByteCount and the checksum both
+ * cover the uncompressed bytes written, as the TOC stores offsets into the
+ * uncompressed metadata.
+ */
 type FileWithByteCount struct {
 	Filename  string
 	writer    io.Writer
-	closer    io.WriteCloser
+	buffer    *bufio.Writer
+	closer    io.Closer
+	checksum  hash.Hash32
 	ByteCount uint64
 }
 
+const fileWriteBufferSize = 64 * 1024
+
 func NewFileWithByteCount(writer io.Writer) *FileWithByteCount {
-	return &FileWithByteCount{"", writer, nil, 0}
+	checksum := crc32.NewIEEE()
+	return &FileWithByteCount{writer: io.MultiWriter(writer, checksum), checksum: checksum}
 }
 
+/*
+ * A file is written under a temporary name and only given its own name once
+ * it has been closed successfully, so a file left behind by a backup that
+ * failed partway through is never mistaken for a complete one.
+ */
 func NewFileWithByteCountFromFile(filename string) *FileWithByteCount {
-	file := iohelper.MustOpenFileForWriting(filename)
-	return &FileWithByteCount{filename, file, file, 0}
+	file := iohelper.MustOpenFileForWriting(GetPartialFilename(filename))
+	return newBufferedFileWithByteCount(filename, file, file)
+}
+
+func NewCompressedFileWithByteCountFromFile(filename string, compressionLevel int) *FileWithByteCount {
+	file := iohelper.MustOpenFileForWriting(GetPartialFilename(filename))
+	gzipWriter, err := gzip.NewWriterLevel(file, compressionLevel)
+	gplog.FatalOnError(err)
+	return newBufferedFileWithByteCount(filename, gzipWriter, &gzipFileCloser{gzipWriter, file})
+}
+
+/*
+ * The contents are compressed before they are encrypted, unless
+ * compressionLevel is 0.
+ */
+func NewEncryptedFileWithByteCountFromFile(filename string, compressionLevel int, key []byte) *FileWithByteCount {
+	file := iohelper.MustOpenFileForWriting(GetPartialFilename(filename))
+	closer := &encryptingFileCloser{file: file, key: key}
+	var writer io.Writer = &closer.plaintext
+	if compressionLevel > 0 {
+		gzipWriter, err := gzip.NewWriterLevel(&closer.plaintext, compressionLevel)
+		gplog.FatalOnError(err)
+		closer.gzipWriter = gzipWriter
+		writer = gzipWriter
+	}
+	return newBufferedFileWithByteCount(filename, writer, closer)
+}
+
+func newBufferedFileWithByteCount(filename string, writer io.Writer, closer io.Closer) *FileWithByteCount {
+	buffer := bufio.NewWriterSize(writer, fileWriteBufferSize)
+	checksum := crc32.NewIEEE()
+	return &FileWithByteCount{Filename: filename, writer: io.MultiWriter(buffer, checksum), buffer: buffer, closer: closer, checksum: checksum}
+}
+
+func GetPartialFilename(filename string) string {
+	if filename == "" {
+		return ""
+	}
+	return filename + ".partial"
 }
 
+type gzipFileCloser struct {
+	gzipWriter *gzip.Writer
+	file       io.WriteCloser
+}
+
+func (closer *gzipFileCloser) Close() error {
+	err := closer.gzipWriter.Close()
+	fileErr := closer.file.Close()
+	if err != nil {
+		return err
+	}
+	return fileErr
+}
+
+/*
+ * Closing a file a second time does nothing, and writing to a file after it
+ * has been closed panics.
+ */
 func (file *FileWithByteCount) Close() {
-	if file.closer != nil {
-		_ = file.closer.Close()
-		if file.Filename != "" {
-			err := operating.System.Chmod(file.Filename, 0444)
-			gplog.FatalOnError(err)
-		}
+	if file.closer == nil {
+		return
 	}
+	err := file.buffer.Flush()
+	gplog.FatalOnError(err, "Unable to write to file")
+	_ = file.closer.Close()
+	if file.Filename != "" {
+		err = os.Rename(GetPartialFilename(file.Filename), file.Filename)
+		gplog.FatalOnError(err)
+		err = operating.System.Chmod(file.Filename, 0444)
+		gplog.FatalOnError(err)
+	}
+	file.writer, file.buffer, file.closer = nil, nil, nil
+}
+
+// The CRC-32 of everything written to the file, before any compression
+func (file *FileWithByteCount) Checksum() string {
+	return fmt.Sprintf("%08x", file.checksum.Sum32())
 }
 
 func (file *FileWithByteCount) MustPrintln(v ...interface{}) {
@@ -131,6 +218,16 @@ func (file *FileWithByteCount) MustPrint(s string) {
 	file.ByteCount += uint64(bytesWritten)
 }
 
+/*
+ * Prints a statement and adds a TOC entry covering exactly the bytes written
+ * for it, so that the caller need not track byte offsets itself.
+ */
+func (file *FileWithByteCount) MustPrintEntry(toc *TOC, section string, entry MetadataEntry, s string, v ...interface{}) {
+	start := file.ByteCount
+	file.MustPrintf(s, v...)
+	toc.AddMetadataEntry(section, entry, start, file.ByteCount)
+}
+
 func CopyFile(src, dest string) error {
 	info, err := operating.System.Stat(src)
 	if err == nil {
@@ -1,6 +1,8 @@
 package utils_test
 
 import (
+	"fmt"
+	"hash/crc32"
 	"io"
 	"io/ioutil"
 	"os"
@@ -107,13 +109,75 @@ var _ = Describe("utils/io tests", func() {
 			defer testhelper.ShouldPanicWithMessage("invalid memory address or nil pointer dereference")
 			file.MustPrintf("message")
 		})
-		It("closes the FileWithByteCount and makes it read-only if it has a filename", func() {
-			file = utils.NewFileWithByteCountFromFile("testfile")
+	})
+	Describe("FileWithByteCount written to a file", func() {
+		filename := "/tmp/gpbackup_io_test_file"
+		BeforeEach(func() {
+			_ = os.Remove(filename)
+		})
+		AfterEach(func() {
+			_ = os.Remove(filename)
+			_ = os.Remove(utils.GetPartialFilename(filename))
+		})
+		It("writes to a partial file that is renamed and made read-only when it is closed", func() {
+			file := utils.NewFileWithByteCountFromFile(filename)
+			file.MustPrintf("message")
+			Expect(filename).ToNot(BeAnExistingFile())
+			Expect(utils.GetPartialFilename(filename)).To(BeAnExistingFile())
+
+			file.Close()
+
+			Expect(utils.GetPartialFilename(filename)).ToNot(BeAnExistingFile())
+			contents, _ := ioutil.ReadFile(filename)
+			Expect(string(contents)).To(Equal("message"))
+			info, _ := os.Stat(filename)
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0444)))
 			file.Close()
-			Expect(wasCalled).To(BeTrue())
 			defer testhelper.ShouldPanicWithMessage("invalid memory address or nil pointer dereference")
 			file.MustPrintf("message")
 		})
+		It("counts and checksums the uncompressed bytes of a compressed file", func() {
+			file := utils.NewCompressedFileWithByteCountFromFile(filename, 1)
+			file.MustPrintf("message")
+			file.Close()
+
+			contents, err := utils.ReadFileDecompressingIfNeeded(filename)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal("message"))
+			Expect(file.ByteCount).To(Equal(uint64(7)))
+			Expect(file.Checksum()).To(Equal(fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte("message")))))
+		})
+		It("counts and checksums the unencrypted bytes of an encrypted file", func() {
+			key := make([]byte, 32)
+			utils.SetMetadataEncryptionKey(key)
+			defer utils.SetMetadataEncryptionKey(nil)
+			file := utils.NewEncryptedFileWithByteCountFromFile(filename, 1, key)
+			file.MustPrintf("message")
+			file.Close()
+
+			raw, _ := ioutil.ReadFile(filename)
+			Expect(utils.IsEncrypted(raw)).To(BeTrue())
+			contents, err := utils.ReadFileDecompressingIfNeeded(filename)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal("message"))
+			Expect(file.ByteCount).To(Equal(uint64(7)))
+			Expect(file.Checksum()).To(Equal(fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte("message")))))
+		})
+	})
+	Describe("MustPrintEntry", func() {
+		It("adds a TOC entry covering the statement it prints", func() {
+			toc := &utils.TOC{}
+			toc.InitializeMetadataEntryMap()
+			file := utils.NewFileWithByteCount(buffer)
+			file.MustPrint("SET client_encoding = 'UTF8';\n")
+
+			file.MustPrintEntry(toc, "predata", utils.MetadataEntry{Schema: "public", Name: "foo", ObjectType: "TABLE"}, "\n\nCREATE TABLE %s ();", "public.foo")
+
+			Expect(toc.PredataEntries).To(Equal([]utils.MetadataEntry{
+				{Schema: "public", Name: "foo", ObjectType: "TABLE", StartByte: 30, EndByte: 59},
+			}))
+			Expect(string(buffer.Contents())).To(Equal("SET client_encoding = 'UTF8';\n\n\nCREATE TABLE public.foo ();"))
+		})
 	})
 	Describe("CopyFile", func() {
 		var sourceFilePath = "/tmp/test_file.txt"
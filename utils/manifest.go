@@ -0,0 +1,126 @@
+package utils
+
+/*
+ * This file contains the manifest of a backup's data files, which records the
+ * size and checksum of every file written to the segments so that a backup
+ * can later be checked for missing, truncated, or corrupted files, and the
+ * number of rows backed up from each table so that a restore can be checked.
+ */
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+/*
+ * Checksums are the CRCs computed by the POSIX cksum utility, which is present
+ * on every segment host, so that they can be computed where the files are
+ * rather than by copying the files to the master.
+ */
+type Manifest struct {
+	Segments  map[int][]ManifestEntry
+	RowCounts map[string]int64 `yaml:",omitempty"`
+}
+
+type ManifestEntry struct {
+	Path     string
+	Size     int64
+	Checksum uint32
+}
+
+func NewManifest(filename string) *Manifest {
+	manifest := &Manifest{}
+	contents, err := operating.System.ReadFile(filename)
+	gplog.FatalOnError(err)
+	err = yaml.Unmarshal(contents, manifest)
+	gplog.FatalOnError(err)
+	return manifest
+}
+
+// Row counts are keyed by the fully-qualified name of the table
+func GetRowCountsFromTOC(toc *TOC) map[string]int64 {
+	rowCounts := make(map[string]int64, len(toc.DataEntries))
+	for _, entry := range toc.DataEntries {
+		rowCounts[MakeFQN(entry.Schema, entry.Name)] = entry.RowsCopied
+	}
+	return rowCounts
+}
+
+func (manifest *Manifest) WriteToFileAndMakeReadOnly(filename string) {
+	manifestContents, err := yaml.Marshal(manifest)
+	gplog.FatalOnError(err)
+	writeTOCContentsAndMakeReadOnly(filename, manifestContents)
+}
+
+/*
+ * Parses the output of cksum run on one or more files, which has one line per
+ * file of the form "<checksum> <size> <path>".
+ */
+func ParseCksumOutput(output string) ([]ManifestEntry, error) {
+	entries := make([]ManifestEntry, 0)
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 3)
+		if len(fields) != 3 {
+			return nil, errors.Errorf("Unable to parse checksum output: %s", line)
+		}
+		checksum, err := strconv.ParseUint(fields[0], 10, 32)
+		if err != nil {
+			return nil, errors.Errorf("Unable to parse checksum output: %s", line)
+		}
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("Unable to parse checksum output: %s", line)
+		}
+		entries = append(entries, ManifestEntry{Path: fields[2], Size: size, Checksum: uint32(checksum)})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+	return entries, nil
+}
+
+/*
+ * Returns a description of each problem with the files found on a segment:
+ * expected files that are missing, and files whose size or checksum differs
+ * from that recorded when the backup was taken.  Files with no recorded entry,
+ * such as those of backups taken before manifests were written, are only
+ * checked for presence.
+ */
+func CompareToManifest(expectedFiles []string, found []ManifestEntry, recorded []ManifestEntry) []string {
+	foundMap := make(map[string]ManifestEntry, len(found))
+	for _, entry := range found {
+		foundMap[entry.Path] = entry
+	}
+	recordedMap := make(map[string]ManifestEntry, len(recorded))
+	for _, entry := range recorded {
+		recordedMap[entry.Path] = entry
+	}
+	problems := make([]string, 0)
+	for _, path := range expectedFiles {
+		foundEntry, ok := foundMap[path]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s is missing", path))
+			continue
+		}
+		recordedEntry, ok := recordedMap[path]
+		if !ok {
+			continue
+		}
+		if foundEntry.Size != recordedEntry.Size {
+			problems = append(problems, fmt.Sprintf("%s is %d bytes, but was %d bytes when backed up", path, foundEntry.Size, recordedEntry.Size))
+		} else if foundEntry.Checksum != recordedEntry.Checksum {
+			problems = append(problems, fmt.Sprintf("%s has checksum %d, but had checksum %d when backed up", path, foundEntry.Checksum, recordedEntry.Checksum))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,67 @@
+package utils_test
+
+import (
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("utils/manifest tests", func() {
+	Describe("ParseCksumOutput", func() {
+		It("parses one entry per line, sorted by path", func() {
+			output := "3015617425 6 /data/gpseg0/gpbackup_0_20190101000000_2\n4294967295 0 /data/gpseg0/gpbackup_0_20190101000000_1\n"
+
+			entries, err := utils.ParseCksumOutput(output)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(Equal([]utils.ManifestEntry{
+				{Path: "/data/gpseg0/gpbackup_0_20190101000000_1", Size: 0, Checksum: 4294967295},
+				{Path: "/data/gpseg0/gpbackup_0_20190101000000_2", Size: 6, Checksum: 3015617425},
+			}))
+		})
+		It("returns no entries for empty output", func() {
+			entries, err := utils.ParseCksumOutput("")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+		It("returns an error for a line that is not cksum output", func() {
+			_, err := utils.ParseCksumOutput("cksum: /data/gpseg0/file: No such file or directory")
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Describe("GetRowCountsFromTOC", func() {
+		It("returns the rows backed up from each table by name", func() {
+			toc := &utils.TOC{}
+			toc.AddMasterDataEntry("public", "foo", 1, "(i)", 10, "")
+			toc.AddMasterDataEntry("public", "bar", 2, "(i)", 0, "")
+
+			Expect(utils.GetRowCountsFromTOC(toc)).To(Equal(map[string]int64{"public.foo": 10, "public.bar": 0}))
+		})
+	})
+	Describe("CompareToManifest", func() {
+		recorded := []utils.ManifestEntry{
+			{Path: "file1", Size: 10, Checksum: 1},
+			{Path: "file2", Size: 20, Checksum: 2},
+			{Path: "file3", Size: 30, Checksum: 3},
+		}
+		It("finds no problems when the files match the manifest", func() {
+			Expect(utils.CompareToManifest([]string{"file1", "file2", "file3"}, recorded, recorded)).To(BeEmpty())
+		})
+		It("reports missing files and files whose size or checksum has changed", func() {
+			found := []utils.ManifestEntry{
+				{Path: "file1", Size: 10, Checksum: 4},
+				{Path: "file3", Size: 5, Checksum: 3},
+				{Path: "file4", Size: 40, Checksum: 4},
+			}
+
+			Expect(utils.CompareToManifest([]string{"file1", "file2", "file3", "file4"}, found, recorded)).To(Equal([]string{
+				"file1 has checksum 4, but had checksum 1 when backed up",
+				"file2 is missing",
+				"file3 is 5 bytes, but was 30 bytes when backed up",
+			}))
+		})
+	})
+})
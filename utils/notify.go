@@ -0,0 +1,288 @@
+package utils
+
+/*
+ * This file contains the notifiers to which backup and restore events are
+ * sent, such as a backup or restore completing or failing or a backup being
+ * deleted, and the parsing of their configuration from the notifications
+ * section of a configuration file.
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/cluster"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	EVENT_BACKUP_SUCCESS  = "backup_success"
+	EVENT_BACKUP_FAILURE  = "backup_failure"
+	EVENT_BACKUP_DELETED  = "backup_deleted"
+	EVENT_RESTORE_SUCCESS = "restore_success"
+	EVENT_RESTORE_FAILURE = "restore_failure"
+)
+
+var notificationEvents = map[string]bool{EVENT_BACKUP_SUCCESS: true, EVENT_BACKUP_FAILURE: true, EVENT_BACKUP_DELETED: true,
+	EVENT_RESTORE_SUCCESS: true, EVENT_RESTORE_FAILURE: true}
+
+type NotificationPayload struct {
+	Utility    string `json:"utility"`
+	Timestamp  string `json:"timestamp"`
+	Database   string `json:"database"`
+	Status     string `json:"status"`
+	Message    string `json:"message"`
+	ReportFile string `json:"report_file"`
+}
+
+type Notifier interface {
+	Notify(event string, payload NotificationPayload) error
+}
+
+type notification struct {
+	Event    string `json:"event"`
+	Hostname string `json:"hostname"`
+	NotificationPayload
+}
+
+func newNotification(event string, payload NotificationPayload) notification {
+	hostname, _ := operating.System.Hostname()
+	return notification{Event: event, Hostname: hostname, NotificationPayload: payload}
+}
+
+type EmailNotifier struct {
+	Addresses []string
+}
+
+func (notifier EmailNotifier) Notify(event string, payload NotificationPayload) error {
+	n := newNotification(event, payload)
+	message := fmt.Sprintf(`To: %s
+Subject: %s %s on %s: %s
+
+Event: %s
+Host: %s
+Database: %s
+Timestamp: %s
+Status: %s
+`, strings.Join(notifier.Addresses, ", "), n.Utility, n.Event, n.Hostname, n.Timestamp, n.Event, n.Hostname, n.Database, n.Timestamp, n.Status)
+	if n.Message != "" {
+		message += fmt.Sprintf("Message: %s\n", n.Message)
+	}
+	if n.ReportFile != "" {
+		message += fmt.Sprintf("Report file: %s\n", n.ReportFile)
+	}
+	command := exec.Command("sendmail", "-t")
+	command.Stdin = strings.NewReader(message)
+	output, err := command.CombinedOutput()
+	if err != nil {
+		return errors.Errorf("sendmail failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+/*
+ * Emails the report file to the contacts listed for the utility in
+ * gp_email_contacts.yaml, as gpbackup and gprestore did before notifiers could
+ * be configured.  Notifications without a report file are not emailed.
+ */
+type ReportEmailNotifier struct {
+	Cluster *cluster.Cluster
+}
+
+func (notifier ReportEmailNotifier) Notify(event string, payload NotificationPayload) error {
+	if payload.ReportFile == "" {
+		return nil
+	}
+	EmailReport(notifier.Cluster, payload.Timestamp, payload.ReportFile, payload.Utility)
+	return nil
+}
+
+/*
+ * The notification is sent as a JSON object in the body of a POST request,
+ * and any response other than a 2xx status is an error.
+ */
+type WebhookNotifier struct {
+	URL     string
+	Headers map[string]string
+	Timeout time.Duration
+}
+
+func (notifier WebhookNotifier) Notify(event string, payload NotificationPayload) error {
+	body, err := json.Marshal(newNotification(event, payload))
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest("POST", notifier.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range notifier.Headers {
+		request.Header.Set(name, value)
+	}
+	client := &http.Client{Timeout: notifier.Timeout}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return errors.Errorf("Webhook %s returned status %s", notifier.URL, response.Status)
+	}
+	return nil
+}
+
+/*
+ * The command is run by bash with the notification as a JSON object on its
+ * standard input and its fields in GPBACKUP_NOTIFY_* environment variables.
+ */
+type CommandNotifier struct {
+	Command string
+}
+
+func (notifier CommandNotifier) Notify(event string, payload NotificationPayload) error {
+	n := newNotification(event, payload)
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	command := exec.Command("bash", "-c", notifier.Command)
+	command.Stdin = bytes.NewReader(body)
+	command.Env = append(os.Environ(),
+		"GPBACKUP_NOTIFY_EVENT="+n.Event,
+		"GPBACKUP_NOTIFY_UTILITY="+n.Utility,
+		"GPBACKUP_NOTIFY_TIMESTAMP="+n.Timestamp,
+		"GPBACKUP_NOTIFY_DATABASE="+n.Database,
+		"GPBACKUP_NOTIFY_STATUS="+n.Status,
+		"GPBACKUP_NOTIFY_MESSAGE="+n.Message,
+		"GPBACKUP_NOTIFY_REPORT_FILE="+n.ReportFile)
+	output, err := command.CombinedOutput()
+	if err != nil {
+		return errors.Errorf("Notification command failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+type NotifierConfig struct {
+	Type      string            `yaml:"type"`
+	Events    []string          `yaml:"events"`
+	Addresses []string          `yaml:"addresses"`
+	URL       string            `yaml:"url"`
+	Headers   map[string]string `yaml:"headers"`
+	Timeout   int               `yaml:"timeout"`
+	Command   string            `yaml:"command"`
+}
+
+type notificationsConfig struct {
+	Notifications []NotifierConfig `yaml:"notifications"`
+}
+
+func NewNotifier(config NotifierConfig) (Notifier, error) {
+	switch config.Type {
+	case "email":
+		if len(config.Addresses) == 0 {
+			return nil, errors.New("Email notifiers require addresses")
+		}
+		return EmailNotifier{Addresses: config.Addresses}, nil
+	case "webhook":
+		if config.URL == "" {
+			return nil, errors.New("Webhook notifiers require a url")
+		}
+		timeout := 30
+		if config.Timeout > 0 {
+			timeout = config.Timeout
+		}
+		return WebhookNotifier{URL: config.URL, Headers: config.Headers, Timeout: time.Duration(timeout) * time.Second}, nil
+	case "command":
+		if config.Command == "" {
+			return nil, errors.New("Command notifiers require a command")
+		}
+		return CommandNotifier{Command: config.Command}, nil
+	}
+	return nil, errors.Errorf("Unknown notifier type %s.  Notifiers must be of type email, webhook, or command.", config.Type)
+}
+
+type filteredNotifier struct {
+	notifier Notifier
+	events   map[string]bool
+}
+
+/*
+ * Notifications sends each event to the notifiers configured for it.  A nil
+ * Notifications has no notifiers, so it can be used before any are configured.
+ */
+type Notifications struct {
+	notifiers []filteredNotifier
+}
+
+// If no events are given, the notifier receives every event
+func (notifications *Notifications) AddNotifier(notifier Notifier, events ...string) {
+	filter := make(map[string]bool)
+	for _, event := range events {
+		filter[event] = true
+	}
+	notifications.notifiers = append(notifications.notifiers, filteredNotifier{notifier: notifier, events: filter})
+}
+
+/*
+ * A failed notification is logged rather than returned, as it must not change
+ * the outcome of the operation it reports.
+ */
+func (notifications *Notifications) Notify(event string, payload NotificationPayload) {
+	if notifications == nil {
+		return
+	}
+	for _, filtered := range notifications.notifiers {
+		if len(filtered.events) > 0 && !filtered.events[event] {
+			continue
+		}
+		gplog.Verbose("Sending %s notification to %T", event, filtered.notifier)
+		if err := filtered.notifier.Notify(event, payload); err != nil {
+			gplog.Warn("Unable to send %s notification: %v", event, err)
+		}
+	}
+}
+
+func ParseNotifications(contents []byte) (*Notifications, error) {
+	config := notificationsConfig{}
+	err := yaml.Unmarshal(contents, &config)
+	if err != nil {
+		return nil, err
+	}
+	notifications := &Notifications{}
+	for _, notifierConfig := range config.Notifications {
+		for _, event := range notifierConfig.Events {
+			if !notificationEvents[event] {
+				return nil, errors.Errorf("Unknown notification event %s", event)
+			}
+		}
+		notifier, err := NewNotifier(notifierConfig)
+		if err != nil {
+			return nil, err
+		}
+		notifications.AddNotifier(notifier, notifierConfig.Events...)
+	}
+	return notifications, nil
+}
+
+// Without a configuration file there are no notifiers
+func ReadNotifications(configFile string) (*Notifications, error) {
+	if configFile == "" {
+		return &Notifications{}, nil
+	}
+	contents, err := operating.System.ReadFile(configFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to read configuration file %s", configFile)
+	}
+	notifications, err := ParseNotifications(contents)
+	return notifications, errors.Wrapf(err, "Invalid notifications in configuration file %s", configFile)
+}
@@ -0,0 +1,162 @@
+package utils_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/pkg/errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+type recordingNotifier struct {
+	events []string
+	err    error
+}
+
+func (notifier *recordingNotifier) Notify(event string, payload utils.NotificationPayload) error {
+	notifier.events = append(notifier.events, event)
+	return notifier.err
+}
+
+var _ = Describe("utils/notify tests", func() {
+	payload := utils.NotificationPayload{Utility: "gpbackup", Timestamp: "20190102030405", Database: "testdb", Status: "Failure", Message: "oops"}
+	Describe("ParseNotifications", func() {
+		It("creates a notifier for each entry", func() {
+			contents := []byte(`
+dbname: testdb
+notifications:
+- type: email
+  events: [backup_failure]
+  addresses: [dba@example.com]
+- type: webhook
+  url: https://example.com/hook
+- type: command
+  command: /bin/true
+`)
+			_, err := utils.ParseNotifications(contents)
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("returns no error when there are no notifications", func() {
+			_, err := utils.ParseNotifications([]byte("dbname: testdb\n"))
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("returns an error for an unknown notifier type", func() {
+			_, err := utils.ParseNotifications([]byte("notifications:\n- type: pager\n"))
+
+			Expect(err).To(MatchError("Unknown notifier type pager.  Notifiers must be of type email, webhook, or command."))
+		})
+		It("accepts restore events", func() {
+			_, err := utils.ParseNotifications([]byte("notifications:\n- type: command\n  command: /bin/true\n  events: [restore_success, restore_failure]\n"))
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("returns an error for an unknown event", func() {
+			_, err := utils.ParseNotifications([]byte("notifications:\n- type: command\n  command: /bin/true\n  events: [backup_started]\n"))
+
+			Expect(err).To(MatchError("Unknown notification event backup_started"))
+		})
+		It("returns an error for a webhook without a url", func() {
+			_, err := utils.ParseNotifications([]byte("notifications:\n- type: webhook\n"))
+
+			Expect(err).To(MatchError("Webhook notifiers require a url"))
+		})
+	})
+	Describe("Notifications.Notify", func() {
+		It("sends events only to the notifiers configured for them", func() {
+			all := &recordingNotifier{}
+			failures := &recordingNotifier{}
+			notifications := &utils.Notifications{}
+			notifications.AddNotifier(all)
+			notifications.AddNotifier(failures, utils.EVENT_BACKUP_FAILURE)
+
+			notifications.Notify(utils.EVENT_BACKUP_SUCCESS, payload)
+			notifications.Notify(utils.EVENT_BACKUP_FAILURE, payload)
+
+			Expect(all.events).To(Equal([]string{utils.EVENT_BACKUP_SUCCESS, utils.EVENT_BACKUP_FAILURE}))
+			Expect(failures.events).To(Equal([]string{utils.EVENT_BACKUP_FAILURE}))
+		})
+		It("logs a warning and continues when a notifier fails", func() {
+			failing := &recordingNotifier{err: errors.New("unreachable")}
+			other := &recordingNotifier{}
+			notifications := &utils.Notifications{}
+			notifications.AddNotifier(failing)
+			notifications.AddNotifier(other)
+
+			notifications.Notify(utils.EVENT_BACKUP_DELETED, payload)
+
+			Expect(stdout).To(Say("Unable to send backup_deleted notification: unreachable"))
+			Expect(other.events).To(Equal([]string{utils.EVENT_BACKUP_DELETED}))
+		})
+		It("does nothing when there are no notifications", func() {
+			var notifications *utils.Notifications
+
+			notifications.Notify(utils.EVENT_BACKUP_SUCCESS, payload)
+		})
+	})
+	Describe("WebhookNotifier", func() {
+		It("posts the notification as JSON", func() {
+			var received map[string]string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Header.Get("Authorization")).To(Equal("Bearer token"))
+				_ = json.NewDecoder(r.Body).Decode(&received)
+			}))
+			defer server.Close()
+			notifier := utils.WebhookNotifier{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}, Timeout: time.Second}
+
+			Expect(notifier.Notify(utils.EVENT_BACKUP_FAILURE, payload)).To(Succeed())
+
+			Expect(received["event"]).To(Equal("backup_failure"))
+			Expect(received["timestamp"]).To(Equal("20190102030405"))
+			Expect(received["message"]).To(Equal("oops"))
+		})
+		It("returns an error if the webhook does not succeed", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer server.Close()
+			notifier := utils.WebhookNotifier{URL: server.URL, Timeout: time.Second}
+
+			err := notifier.Notify(utils.EVENT_BACKUP_FAILURE, payload)
+
+			Expect(err).To(MatchError("Webhook " + server.URL + " returned status 500 Internal Server Error"))
+		})
+	})
+	Describe("CommandNotifier", func() {
+		It("passes the notification to the command", func() {
+			tempDir, err := ioutil.TempDir("", "notify")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(tempDir)
+			outputFile := filepath.Join(tempDir, "output")
+			notifier := utils.CommandNotifier{Command: `echo "$GPBACKUP_NOTIFY_EVENT $GPBACKUP_NOTIFY_STATUS" > ` + outputFile}
+
+			Expect(notifier.Notify(utils.EVENT_BACKUP_FAILURE, payload)).To(Succeed())
+
+			output, err := ioutil.ReadFile(outputFile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(output)).To(Equal("backup_failure Failure\n"))
+		})
+		It("returns an error if the command fails", func() {
+			notifier := utils.CommandNotifier{Command: "exit 1"}
+
+			Expect(notifier.Notify(utils.EVENT_BACKUP_FAILURE, payload)).ToNot(Succeed())
+		})
+	})
+	Describe("ReportEmailNotifier", func() {
+		It("does not email a notification without a report file", func() {
+			notifier := utils.ReportEmailNotifier{}
+
+			Expect(notifier.Notify(utils.EVENT_BACKUP_FAILURE, payload)).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,70 @@
+package utils
+
+/*
+ * This file contains structs and functions for recording the options a backup
+ * was taken with, so that it can be reproduced or audited later.
+ */
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+)
+
+/*
+ * Options holds the effective value of every flag, whether it was set on the
+ * command line, in the environment, or in a configuration file, or was left
+ * at its default.  SetOptions lists the flags that were not left at their
+ * defaults.
+ */
+type OptionsSnapshot struct {
+	Utility            string            `yaml:"utility"`
+	UtilityVersion     string            `yaml:"utilityversion"`
+	DatabaseVersion    string            `yaml:"databaseversion"`
+	PluginConfigFile   string            `yaml:"pluginconfigfile,omitempty"`
+	PluginConfigSHA256 string            `yaml:"pluginconfigsha256,omitempty"`
+	Options            map[string]string `yaml:"options"`
+	SetOptions         []string          `yaml:"setoptions"`
+}
+
+/*
+ * The plugin configuration is recorded as a hash of its contents instead of
+ * the contents themselves, as it may hold credentials for the storage.
+ */
+func NewOptionsSnapshot(flags *pflag.FlagSet, utility string, utilityVersion string, databaseVersion string, pluginConfigFile string) (*OptionsSnapshot, error) {
+	snapshot := &OptionsSnapshot{
+		Utility:         utility,
+		UtilityVersion:  utilityVersion,
+		DatabaseVersion: databaseVersion,
+		Options:         make(map[string]string),
+		SetOptions:      make([]string, 0),
+	}
+	flags.VisitAll(func(flag *pflag.Flag) {
+		snapshot.Options[flag.Name] = flag.Value.String()
+		if flag.Changed {
+			snapshot.SetOptions = append(snapshot.SetOptions, flag.Name)
+		}
+	})
+	sort.Strings(snapshot.SetOptions)
+	if pluginConfigFile != "" {
+		contents, err := operating.System.ReadFile(pluginConfigFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to read plugin configuration file %s", pluginConfigFile)
+		}
+		snapshot.PluginConfigFile = pluginConfigFile
+		snapshot.PluginConfigSHA256 = fmt.Sprintf("%x", sha256.Sum256(contents))
+	}
+	return snapshot, nil
+}
+
+func (snapshot *OptionsSnapshot) WriteToFileAndMakeReadOnly(filename string) {
+	snapshotContents, err := yaml.Marshal(snapshot)
+	gplog.FatalOnError(err)
+	writeTOCContentsAndMakeReadOnly(filename, snapshotContents)
+}
@@ -0,0 +1,62 @@
+package utils_test
+
+import (
+	"os"
+
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/spf13/pflag"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("utils/options_snapshot tests", func() {
+	var flagSet *pflag.FlagSet
+	BeforeEach(func() {
+		flagSet = pflag.NewFlagSet("testFlags", pflag.ContinueOnError)
+		_ = flagSet.String(utils.DBNAME, "", "")
+		_ = flagSet.Int(utils.JOBS, 1, "")
+		_ = flagSet.StringSlice(utils.INCLUDE_SCHEMA, []string{}, "")
+	})
+	AfterEach(func() {
+		operating.System = operating.InitializeSystemFunctions()
+	})
+	Describe("NewOptionsSnapshot", func() {
+		It("records the value of every flag and which flags were set", func() {
+			_ = flagSet.Set(utils.DBNAME, "testdb")
+			_ = flagSet.Set(utils.INCLUDE_SCHEMA, "public")
+
+			snapshot, err := utils.NewOptionsSnapshot(flagSet, "gpbackup", "1.0.0", "6.0.0", "")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(snapshot.Utility).To(Equal("gpbackup"))
+			Expect(snapshot.UtilityVersion).To(Equal("1.0.0"))
+			Expect(snapshot.DatabaseVersion).To(Equal("6.0.0"))
+			Expect(snapshot.Options).To(Equal(map[string]string{utils.DBNAME: "testdb", utils.JOBS: "1", utils.INCLUDE_SCHEMA: "[public]"}))
+			Expect(snapshot.SetOptions).To(Equal([]string{utils.DBNAME, utils.INCLUDE_SCHEMA}))
+			Expect(snapshot.PluginConfigSHA256).To(Equal(""))
+		})
+		It("records a hash of the plugin configuration file", func() {
+			operating.System.ReadFile = func(filename string) ([]byte, error) {
+				return []byte("abc"), nil
+			}
+
+			snapshot, err := utils.NewOptionsSnapshot(flagSet, "gpbackup", "1.0.0", "6.0.0", "/tmp/plugin_config.yaml")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(snapshot.PluginConfigFile).To(Equal("/tmp/plugin_config.yaml"))
+			Expect(snapshot.PluginConfigSHA256).To(Equal("ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"))
+		})
+		It("returns an error if the plugin configuration file cannot be read", func() {
+			operating.System.ReadFile = func(filename string) ([]byte, error) {
+				return nil, os.ErrNotExist
+			}
+
+			_, err := utils.NewOptionsSnapshot(flagSet, "gpbackup", "1.0.0", "6.0.0", "/tmp/plugin_config.yaml")
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("Unable to read plugin configuration file /tmp/plugin_config.yaml"))
+		})
+	})
+})
@@ -0,0 +1,573 @@
+package utils
+
+/*
+ * This file contains a writer of Parquet files, which gpbackup_helper uses to
+ * convert the CSV output of COPY into files that external engines can query
+ * directly.  It writes only the subset of the format that such a file needs:
+ * a flat schema of optional columns, with the PLAIN-encoded values of each
+ * column in a row group in a single data page, optionally gzip-compressed.
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+/*
+ * Type is the name of the column's type as given by format_type, from which
+ * the type of the column in the Parquet file is derived.
+ */
+type ParquetColumn struct {
+	Name string
+	Type string
+}
+
+const PARQUET_ROW_GROUP_SIZE = 64 * 1024 * 1024
+
+const (
+	parquetMagic = "PAR1"
+
+	parquetTypeBoolean   int32 = 0
+	parquetTypeInt32     int32 = 1
+	parquetTypeInt64     int32 = 2
+	parquetTypeFloat     int32 = 4
+	parquetTypeDouble    int32 = 5
+	parquetTypeByteArray int32 = 6
+
+	parquetConvertedTypeNone  int32 = -1
+	parquetConvertedTypeUTF8  int32 = 0
+	parquetConvertedTypeInt16 int32 = 16
+
+	parquetCodecUncompressed int32 = 0
+	parquetCodecGzip         int32 = 2
+
+	parquetEncodingPlain int32 = 0
+	parquetEncodingRLE   int32 = 3
+
+	parquetPageTypeData       int32 = 0
+	parquetRepetitionOptional int32 = 1
+)
+
+/*
+ * Types without a matching Parquet type, such as numeric and timestamp, are
+ * written as strings in the same text representation that COPY gives them.
+ */
+func getParquetType(gpdbType string) (int32, int32) {
+	switch gpdbType {
+	case "boolean":
+		return parquetTypeBoolean, parquetConvertedTypeNone
+	case "smallint":
+		return parquetTypeInt32, parquetConvertedTypeInt16
+	case "integer":
+		return parquetTypeInt32, parquetConvertedTypeNone
+	case "bigint":
+		return parquetTypeInt64, parquetConvertedTypeNone
+	case "real":
+		return parquetTypeFloat, parquetConvertedTypeNone
+	case "double precision":
+		return parquetTypeDouble, parquetConvertedTypeNone
+	default:
+		return parquetTypeByteArray, parquetConvertedTypeUTF8
+	}
+}
+
+/*
+ * The schema is passed to gpbackup_helper on its command line, inside the
+ * single-quoted PROGRAM of a COPY command, so it is encoded with characters
+ * that need no quoting in SQL or the shell.
+ */
+func EncodeParquetSchema(columns []ParquetColumn) string {
+	schema, _ := json.Marshal(columns)
+	return base64.RawURLEncoding.EncodeToString(schema)
+}
+
+func DecodeParquetSchema(encodedSchema string) ([]ParquetColumn, error) {
+	schema, err := base64.RawURLEncoding.DecodeString(encodedSchema)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to decode Parquet schema")
+	}
+	columns := make([]ParquetColumn, 0)
+	err = json.Unmarshal(schema, &columns)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to decode Parquet schema")
+	}
+	return columns, nil
+}
+
+type parquetColumnChunk struct {
+	column        ParquetColumn
+	physicalType  int32
+	convertedType int32
+	notNull       []bool
+	booleans      []bool
+	values        bytes.Buffer
+}
+
+type ParquetWriter struct {
+	writer           io.Writer
+	offset           int64
+	compressionLevel int
+	chunks           []*parquetColumnChunk
+	rowGroups        [][]byte
+	numRows          int64
+	rowGroupRows     int64
+	bufferedBytes    int
+}
+
+/*
+ * A compression level of 0 leaves pages uncompressed.
+ */
+func NewParquetWriter(writer io.Writer, columns []ParquetColumn, compressionLevel int) (*ParquetWriter, error) {
+	parquetWriter := &ParquetWriter{writer: writer, compressionLevel: compressionLevel}
+	for _, column := range columns {
+		physicalType, convertedType := getParquetType(column.Type)
+		parquetWriter.chunks = append(parquetWriter.chunks, &parquetColumnChunk{column: column, physicalType: physicalType, convertedType: convertedType})
+	}
+	err := parquetWriter.write([]byte(parquetMagic))
+	return parquetWriter, err
+}
+
+func (parquetWriter *ParquetWriter) write(data []byte) error {
+	n, err := parquetWriter.writer.Write(data)
+	parquetWriter.offset += int64(n)
+	return err
+}
+
+/*
+ * Values are the text representations given by COPY, with nil for NULL.
+ */
+func (parquetWriter *ParquetWriter) WriteRow(values []*string) error {
+	// COPY writes an empty line for each row of a table without columns
+	if len(parquetWriter.chunks) == 0 && len(values) == 1 && values[0] == nil {
+		values = nil
+	}
+	if len(values) != len(parquetWriter.chunks) {
+		return errors.Errorf("Row has %d values, but the Parquet schema has %d columns", len(values), len(parquetWriter.chunks))
+	}
+	for i, value := range values {
+		chunk := parquetWriter.chunks[i]
+		chunk.notNull = append(chunk.notNull, value != nil)
+		if value == nil {
+			continue
+		}
+		err := chunk.appendValue(*value)
+		if err != nil {
+			return errors.Wrapf(err, "Unable to convert value of column %s", chunk.column.Name)
+		}
+		parquetWriter.bufferedBytes += len(*value)
+	}
+	parquetWriter.rowGroupRows++
+	if parquetWriter.bufferedBytes >= PARQUET_ROW_GROUP_SIZE {
+		return parquetWriter.flushRowGroup()
+	}
+	return nil
+}
+
+func (chunk *parquetColumnChunk) appendValue(value string) error {
+	switch chunk.physicalType {
+	case parquetTypeBoolean:
+		chunk.booleans = append(chunk.booleans, value == "t" || value == "true")
+	case parquetTypeInt32:
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return err
+		}
+		_ = binary.Write(&chunk.values, binary.LittleEndian, int32(parsed))
+	case parquetTypeInt64:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		_ = binary.Write(&chunk.values, binary.LittleEndian, parsed)
+	case parquetTypeFloat:
+		parsed, err := strconv.ParseFloat(value, 32)
+		if err != nil {
+			return err
+		}
+		_ = binary.Write(&chunk.values, binary.LittleEndian, math.Float32bits(float32(parsed)))
+	case parquetTypeDouble:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		_ = binary.Write(&chunk.values, binary.LittleEndian, math.Float64bits(parsed))
+	default:
+		_ = binary.Write(&chunk.values, binary.LittleEndian, uint32(len(value)))
+		chunk.values.WriteString(value)
+	}
+	return nil
+}
+
+/*
+ * Definition levels of optional columns in a flat schema are 1 bit wide, so
+ * they are written as a single bit-packed run of the RLE/bit-packing hybrid
+ * encoding, padded to a multiple of 8 values.
+ */
+func encodeDefinitionLevels(notNull []bool) []byte {
+	numGroups := (len(notNull) + 7) / 8
+	levels := bytes.Buffer{}
+	levels.Write(appendUvarint(nil, uint64(numGroups<<1|1)))
+	levels.Write(packBits(notNull))
+	encoded := make([]byte, 4, 4+levels.Len())
+	binary.LittleEndian.PutUint32(encoded, uint32(levels.Len()))
+	return append(encoded, levels.Bytes()...)
+}
+
+func packBits(bits []bool) []byte {
+	packed := make([]byte, (len(bits)+7)/8)
+	for i, bit := range bits {
+		if bit {
+			packed[i/8] |= 1 << uint(i%8)
+		}
+	}
+	return packed
+}
+
+func (parquetWriter *ParquetWriter) compress(page []byte) ([]byte, error) {
+	if parquetWriter.compressionLevel == 0 {
+		return page, nil
+	}
+	compressed := bytes.Buffer{}
+	gzipWriter, err := gzip.NewWriterLevel(&compressed, parquetWriter.compressionLevel)
+	if err != nil {
+		return nil, err
+	}
+	_, err = gzipWriter.Write(page)
+	if err != nil {
+		return nil, err
+	}
+	err = gzipWriter.Close()
+	return compressed.Bytes(), err
+}
+
+func (parquetWriter *ParquetWriter) flushRowGroup() error {
+	if parquetWriter.rowGroupRows == 0 {
+		return nil
+	}
+	codec := parquetCodecUncompressed
+	if parquetWriter.compressionLevel != 0 {
+		codec = parquetCodecGzip
+	}
+	rowGroup := newThriftCompactWriter()
+	rowGroup.writeFieldHeader(thriftTypeList, 1)
+	rowGroup.writeListHeader(thriftTypeStruct, len(parquetWriter.chunks))
+	var totalByteSize int64
+	for _, chunk := range parquetWriter.chunks {
+		page := encodeDefinitionLevels(chunk.notNull)
+		if chunk.physicalType == parquetTypeBoolean {
+			page = append(page, packBits(chunk.booleans)...)
+		} else {
+			page = append(page, chunk.values.Bytes()...)
+		}
+		compressedPage, err := parquetWriter.compress(page)
+		if err != nil {
+			return err
+		}
+
+		header := newThriftCompactWriter()
+		header.writeI32Field(1, parquetPageTypeData)
+		header.writeI32Field(2, int32(len(page)))
+		header.writeI32Field(3, int32(len(compressedPage)))
+		header.beginStructField(5)
+		header.writeI32Field(1, int32(len(chunk.notNull)))
+		header.writeI32Field(2, parquetEncodingPlain)
+		header.writeI32Field(3, parquetEncodingRLE)
+		header.writeI32Field(4, parquetEncodingRLE)
+		header.endStruct()
+		header.endStruct()
+
+		dataPageOffset := parquetWriter.offset
+		err = parquetWriter.write(header.bytes())
+		if err != nil {
+			return err
+		}
+		err = parquetWriter.write(compressedPage)
+		if err != nil {
+			return err
+		}
+		uncompressedSize := int64(header.len() + len(page))
+		totalByteSize += uncompressedSize
+
+		rowGroup.beginListStruct()
+		rowGroup.writeI64Field(2, dataPageOffset)
+		rowGroup.beginStructField(3)
+		rowGroup.writeI32Field(1, chunk.physicalType)
+		rowGroup.writeFieldHeader(thriftTypeList, 2)
+		rowGroup.writeListHeader(thriftTypeI32, 2)
+		rowGroup.writeVarint(int64(parquetEncodingPlain))
+		rowGroup.writeVarint(int64(parquetEncodingRLE))
+		rowGroup.writeFieldHeader(thriftTypeList, 3)
+		rowGroup.writeListHeader(thriftTypeBinary, 1)
+		rowGroup.writeBinary(chunk.column.Name)
+		rowGroup.writeI32Field(4, codec)
+		rowGroup.writeI64Field(5, int64(len(chunk.notNull)))
+		rowGroup.writeI64Field(6, uncompressedSize)
+		rowGroup.writeI64Field(7, int64(header.len()+len(compressedPage)))
+		rowGroup.writeI64Field(9, dataPageOffset)
+		rowGroup.endStruct()
+		rowGroup.endStruct()
+
+		chunk.notNull = chunk.notNull[:0]
+		chunk.booleans = chunk.booleans[:0]
+		chunk.values.Reset()
+	}
+	rowGroup.writeI64Field(2, totalByteSize)
+	rowGroup.writeI64Field(3, parquetWriter.rowGroupRows)
+	rowGroup.endStruct()
+	parquetWriter.rowGroups = append(parquetWriter.rowGroups, rowGroup.bytes())
+
+	parquetWriter.numRows += parquetWriter.rowGroupRows
+	parquetWriter.rowGroupRows = 0
+	parquetWriter.bufferedBytes = 0
+	return nil
+}
+
+/*
+ * Writes any buffered rows and the file footer.  The underlying writer is
+ * not closed.
+ */
+func (parquetWriter *ParquetWriter) Close() error {
+	err := parquetWriter.flushRowGroup()
+	if err != nil {
+		return err
+	}
+	footer := newThriftCompactWriter()
+	footer.writeI32Field(1, 1)
+	footer.writeFieldHeader(thriftTypeList, 2)
+	footer.writeListHeader(thriftTypeStruct, len(parquetWriter.chunks)+1)
+	footer.beginListStruct()
+	footer.writeStringField(4, "schema")
+	footer.writeI32Field(5, int32(len(parquetWriter.chunks)))
+	footer.endStruct()
+	for _, chunk := range parquetWriter.chunks {
+		footer.beginListStruct()
+		footer.writeI32Field(1, chunk.physicalType)
+		footer.writeI32Field(3, parquetRepetitionOptional)
+		footer.writeStringField(4, chunk.column.Name)
+		if chunk.convertedType != parquetConvertedTypeNone {
+			footer.writeI32Field(6, chunk.convertedType)
+		}
+		footer.endStruct()
+	}
+	footer.writeI64Field(3, parquetWriter.numRows)
+	footer.writeFieldHeader(thriftTypeList, 4)
+	footer.writeListHeader(thriftTypeStruct, len(parquetWriter.rowGroups))
+	for _, rowGroup := range parquetWriter.rowGroups {
+		// Each row group was encoded as a complete struct of the list
+		footer.buf.Write(rowGroup)
+	}
+	footer.writeStringField(6, "gpbackup")
+	footer.endStruct()
+
+	err = parquetWriter.write(footer.bytes())
+	if err != nil {
+		return err
+	}
+	footerLength := make([]byte, 4)
+	binary.LittleEndian.PutUint32(footerLength, uint32(footer.len()))
+	err = parquetWriter.write(footerLength)
+	if err != nil {
+		return err
+	}
+	return parquetWriter.write([]byte(parquetMagic))
+}
+
+/*
+ * Reads one record of the CSV written by COPY with the default CSV options,
+ * in which an unquoted empty field is NULL and a quoted one is an empty
+ * string.  Returns io.EOF once there are no more records.
+ */
+func ReadCopyCSVRecord(reader *bufio.Reader) ([]*string, error) {
+	record := make([]*string, 0)
+	field := bytes.Buffer{}
+	inQuotes, quoted, readAny := false, false, false
+	endField := func() {
+		if !quoted && field.Len() == 0 {
+			record = append(record, nil)
+		} else {
+			value := field.String()
+			record = append(record, &value)
+		}
+		field.Reset()
+		quoted = false
+	}
+	for {
+		b, err := reader.ReadByte()
+		if err == io.EOF {
+			if !readAny {
+				return nil, io.EOF
+			}
+			if inQuotes {
+				return nil, errors.New("Unterminated quoted field at end of CSV data")
+			}
+			endField()
+			return record, nil
+		} else if err != nil {
+			return nil, err
+		}
+		readAny = true
+		if inQuotes {
+			if b == '"' {
+				if next, err := reader.Peek(1); err == nil && next[0] == '"' {
+					_, _ = reader.ReadByte()
+					field.WriteByte('"')
+				} else {
+					inQuotes = false
+				}
+			} else {
+				field.WriteByte(b)
+			}
+			continue
+		}
+		switch b {
+		case '"':
+			inQuotes, quoted = true, true
+		case ',':
+			endField()
+		case '\r':
+			if next, err := reader.Peek(1); err == nil && next[0] == '\n' {
+				_, _ = reader.ReadByte()
+			}
+			endField()
+			return record, nil
+		case '\n':
+			endField()
+			return record, nil
+		default:
+			field.WriteByte(b)
+		}
+	}
+}
+
+/*
+ * Converts all of the CSV read from the reader and closes the Parquet writer.
+ * Returns the number of rows converted.
+ */
+func ConvertCopyCSVToParquet(reader io.Reader, parquetWriter *ParquetWriter) (int64, error) {
+	bufferedReader := bufio.NewReaderSize(reader, 1024*1024)
+	var numRows int64
+	for {
+		record, err := ReadCopyCSVRecord(bufferedReader)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return numRows, err
+		}
+		err = parquetWriter.WriteRow(record)
+		if err != nil {
+			return numRows, errors.Wrapf(err, "Unable to convert row %d", numRows+1)
+		}
+		numRows++
+	}
+	return numRows, parquetWriter.Close()
+}
+
+/*
+ * Parquet metadata is serialized with the Thrift compact protocol.
+ */
+const (
+	thriftTypeI32    byte = 5
+	thriftTypeI64    byte = 6
+	thriftTypeBinary byte = 8
+	thriftTypeList   byte = 9
+	thriftTypeStruct byte = 12
+)
+
+type thriftCompactWriter struct {
+	buf          bytes.Buffer
+	lastFieldID  int16
+	lastFieldIDs []int16
+}
+
+func newThriftCompactWriter() *thriftCompactWriter {
+	return &thriftCompactWriter{}
+}
+
+func (writer *thriftCompactWriter) bytes() []byte {
+	return writer.buf.Bytes()
+}
+
+func (writer *thriftCompactWriter) len() int {
+	return writer.buf.Len()
+}
+
+func appendUvarint(buf []byte, value uint64) []byte {
+	for value >= 0x80 {
+		buf = append(buf, byte(value)|0x80)
+		value >>= 7
+	}
+	return append(buf, byte(value))
+}
+
+func (writer *thriftCompactWriter) writeVarint(value int64) {
+	zigzag := uint64((value << 1) ^ (value >> 63))
+	writer.buf.Write(appendUvarint(nil, zigzag))
+}
+
+func (writer *thriftCompactWriter) writeFieldHeader(fieldType byte, fieldID int16) {
+	delta := fieldID - writer.lastFieldID
+	if delta > 0 && delta <= 15 {
+		writer.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		writer.buf.WriteByte(fieldType)
+		writer.writeVarint(int64(fieldID))
+	}
+	writer.lastFieldID = fieldID
+}
+
+func (writer *thriftCompactWriter) writeI32Field(fieldID int16, value int32) {
+	writer.writeFieldHeader(thriftTypeI32, fieldID)
+	writer.writeVarint(int64(value))
+}
+
+func (writer *thriftCompactWriter) writeI64Field(fieldID int16, value int64) {
+	writer.writeFieldHeader(thriftTypeI64, fieldID)
+	writer.writeVarint(value)
+}
+
+func (writer *thriftCompactWriter) writeBinary(value string) {
+	writer.buf.Write(appendUvarint(nil, uint64(len(value))))
+	writer.buf.WriteString(value)
+}
+
+func (writer *thriftCompactWriter) writeStringField(fieldID int16, value string) {
+	writer.writeFieldHeader(thriftTypeBinary, fieldID)
+	writer.writeBinary(value)
+}
+
+func (writer *thriftCompactWriter) writeListHeader(elementType byte, size int) {
+	if size < 15 {
+		writer.buf.WriteByte(byte(size)<<4 | elementType)
+	} else {
+		writer.buf.WriteByte(0xf0 | elementType)
+		writer.buf.Write(appendUvarint(nil, uint64(size)))
+	}
+}
+
+func (writer *thriftCompactWriter) beginStructField(fieldID int16) {
+	writer.writeFieldHeader(thriftTypeStruct, fieldID)
+	writer.beginListStruct()
+}
+
+// Structs that are elements of lists have no field header
+func (writer *thriftCompactWriter) beginListStruct() {
+	writer.lastFieldIDs = append(writer.lastFieldIDs, writer.lastFieldID)
+	writer.lastFieldID = 0
+}
+
+func (writer *thriftCompactWriter) endStruct() {
+	writer.buf.WriteByte(0)
+	if len(writer.lastFieldIDs) > 0 {
+		writer.lastFieldID = writer.lastFieldIDs[len(writer.lastFieldIDs)-1]
+		writer.lastFieldIDs = writer.lastFieldIDs[:len(writer.lastFieldIDs)-1]
+	}
+}
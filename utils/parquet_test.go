@@ -0,0 +1,88 @@
+package utils_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func stringPointer(value string) *string {
+	return &value
+}
+
+var _ = Describe("utils/parquet tests", func() {
+	columns := []utils.ParquetColumn{{Name: "id", Type: "integer"}, {Name: "name", Type: "text"}}
+	Describe("EncodeParquetSchema", func() {
+		It("encodes a schema that can be decoded", func() {
+			encoded := utils.EncodeParquetSchema([]utils.ParquetColumn{{Name: `it's "quoted"`, Type: "character varying(20)"}})
+
+			Expect(encoded).To(MatchRegexp(`^[A-Za-z0-9_-]+$`))
+			decoded, err := utils.DecodeParquetSchema(encoded)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded).To(Equal([]utils.ParquetColumn{{Name: `it's "quoted"`, Type: "character varying(20)"}}))
+		})
+		It("returns an error for a schema that cannot be decoded", func() {
+			_, err := utils.DecodeParquetSchema("not a schema")
+			Expect(err).To(MatchError(ContainSubstring("Unable to decode Parquet schema")))
+		})
+	})
+	Describe("ReadCopyCSVRecord", func() {
+		It("distinguishes NULL from empty strings and unquotes quoted fields", func() {
+			reader := bufio.NewReader(strings.NewReader("1,,\"\",\"a,\"\"b\"\"\nc\"\n2,x,y,z"))
+
+			record, err := utils.ReadCopyCSVRecord(reader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(record).To(Equal([]*string{stringPointer("1"), nil, stringPointer(""), stringPointer("a,\"b\"\nc")}))
+			record, err = utils.ReadCopyCSVRecord(reader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(record).To(Equal([]*string{stringPointer("2"), stringPointer("x"), stringPointer("y"), stringPointer("z")}))
+			_, err = utils.ReadCopyCSVRecord(reader)
+			Expect(err).To(Equal(io.EOF))
+		})
+		It("returns an error for an unterminated quoted field", func() {
+			_, err := utils.ReadCopyCSVRecord(bufio.NewReader(strings.NewReader("1,\"abc")))
+			Expect(err).To(MatchError("Unterminated quoted field at end of CSV data"))
+		})
+	})
+	Describe("ConvertCopyCSVToParquet", func() {
+		It("writes a Parquet file with a footer", func() {
+			buffer := bytes.Buffer{}
+			parquetWriter, err := utils.NewParquetWriter(&buffer, columns, 1)
+			Expect(err).ToNot(HaveOccurred())
+
+			numRows, err := utils.ConvertCopyCSVToParquet(strings.NewReader("1,one\n2,\n,three\n"), parquetWriter)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(numRows).To(Equal(int64(3)))
+			contents := buffer.Bytes()
+			Expect(string(contents[:4])).To(Equal("PAR1"))
+			Expect(string(contents[len(contents)-4:])).To(Equal("PAR1"))
+			footerLength := binary.LittleEndian.Uint32(contents[len(contents)-8 : len(contents)-4])
+			footer := string(contents[len(contents)-8-int(footerLength) : len(contents)-8])
+			Expect(footer).To(ContainSubstring("schema"))
+			Expect(footer).To(ContainSubstring("name"))
+			Expect(footer).To(ContainSubstring("gpbackup"))
+		})
+		It("returns an error for a value that does not match its column's type", func() {
+			parquetWriter, _ := utils.NewParquetWriter(&bytes.Buffer{}, columns, 0)
+
+			_, err := utils.ConvertCopyCSVToParquet(strings.NewReader("1,one\nx,two\n"), parquetWriter)
+
+			Expect(err).To(MatchError(ContainSubstring("Unable to convert row 2: Unable to convert value of column id")))
+		})
+		It("returns an error for a row with the wrong number of values", func() {
+			parquetWriter, _ := utils.NewParquetWriter(&bytes.Buffer{}, columns, 0)
+
+			_, err := utils.ConvertCopyCSVToParquet(strings.NewReader("1,one,extra\n"), parquetWriter)
+
+			Expect(err).To(MatchError(ContainSubstring("Row has 3 values, but the Parquet schema has 2 columns")))
+		})
+	})
+})
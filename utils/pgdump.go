@@ -0,0 +1,202 @@
+package utils
+
+/*
+ * This file contains a reader for plain-format pg_dump and pg_dumpall files,
+ * which splits a dump into its SQL statements and the COPY data of its
+ * tables so that it can be converted into a backup.
+ */
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	pgDumpHeaderRegex  = regexp.MustCompile(`^-- (?:Data for )?Name: (.*); Type: ([^;]*); Schema: ([^;]*); Owner: ([^;]*)(?:; Tablespace: [^;]*)?$`)
+	pgDumpVersionRegex = regexp.MustCompile(`^-- Dumped from database version (\S+)`)
+	pgDumpCopyRegex    = regexp.MustCompile(`(?s)^COPY (.+?) (?:\((.*)\) )?FROM stdin;$`)
+	pgDumpConnectRegex = regexp.MustCompile(`^\\connect\s+(?:-reuse-previous=on\s+)?(.*)$`)
+	unquotedIdentRegex = regexp.MustCompile(`^[a-z_][a-z0-9_$]*$`)
+)
+
+/*
+ * A statement from a dump, along with the object described by the comment
+ * that pg_dump writes before each object and the database that pg_dumpall
+ * had connected to.  Statements that are not preceded by such a comment, such
+ * as the roles in a pg_dumpall file, have no object type.  CopyTable is set
+ * only for the COPY statement of a table's data, which is followed by the
+ * data itself.
+ */
+type PgDumpStatement struct {
+	Statement   string
+	ObjectType  string
+	Schema      string
+	Name        string
+	Database    string
+	CopyTable   string
+	CopyColumns string
+}
+
+type PgDumpReader struct {
+	reader          *bufio.Reader
+	header          PgDumpStatement
+	database        string
+	inCopy          bool
+	DatabaseVersion string
+}
+
+func NewPgDumpReader(reader io.Reader) *PgDumpReader {
+	return &PgDumpReader{reader: bufio.NewReaderSize(reader, fileWriteBufferSize)}
+}
+
+func (dumpReader *PgDumpReader) readLine() (string, error) {
+	line, err := dumpReader.reader.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), err
+}
+
+/*
+ * Returns the next statement of the dump, or io.EOF once there are none.
+ * A statement ends at a line ending with a semicolon that is not within a
+ * quoted string, dollar-quoted body, or comment.  Any COPY data that was not
+ * read with ReadCopyData is skipped.  psql meta-commands other than \connect
+ * are ignored.
+ */
+func (dumpReader *PgDumpReader) Next() (PgDumpStatement, error) {
+	if dumpReader.inCopy {
+		if err := dumpReader.ReadCopyData(func(string) error { return nil }); err != nil {
+			return PgDumpStatement{}, err
+		}
+	}
+	lines := make([]string, 0)
+	for {
+		line, err := dumpReader.readLine()
+		if err == io.EOF {
+			if len(lines) > 0 {
+				return PgDumpStatement{}, errors.Errorf("Incomplete statement at end of dump file: %s", strings.Join(lines, "\n"))
+			}
+			return PgDumpStatement{}, io.EOF
+		} else if err != nil {
+			return PgDumpStatement{}, err
+		}
+
+		if len(lines) == 0 {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" {
+				continue
+			} else if strings.HasPrefix(trimmed, "--") {
+				dumpReader.parseComment(trimmed)
+				continue
+			} else if strings.HasPrefix(trimmed, `\`) {
+				if matches := pgDumpConnectRegex.FindStringSubmatch(trimmed); matches != nil {
+					dumpReader.database = parseConnectDatabase(matches[1])
+					dumpReader.header = PgDumpStatement{}
+				}
+				continue
+			}
+		}
+
+		lines = append(lines, line)
+		if !strings.HasSuffix(strings.TrimSpace(line), ";") {
+			continue
+		}
+		statement := strings.Join(lines, "\n")
+		if CheckStatementSyntax(statement) != nil {
+			continue
+		}
+		return dumpReader.makeStatement(statement), nil
+	}
+}
+
+func (dumpReader *PgDumpReader) parseComment(comment string) {
+	if matches := pgDumpHeaderRegex.FindStringSubmatch(comment); matches != nil {
+		schema := matches[3]
+		if schema == "-" {
+			schema = ""
+		}
+		dumpReader.header = PgDumpStatement{ObjectType: matches[2], Schema: schema, Name: matches[1]}
+	} else if matches := pgDumpVersionRegex.FindStringSubmatch(comment); matches != nil && dumpReader.DatabaseVersion == "" {
+		dumpReader.DatabaseVersion = matches[1]
+	}
+}
+
+func (dumpReader *PgDumpReader) makeStatement(statement string) PgDumpStatement {
+	dumpStatement := dumpReader.header
+	dumpStatement.Statement = statement
+	dumpStatement.Database = dumpReader.database
+	if matches := pgDumpCopyRegex.FindStringSubmatch(statement); matches != nil {
+		dumpStatement.CopyTable = matches[1]
+		dumpStatement.CopyColumns = matches[2]
+		dumpReader.inCopy = true
+	}
+	return dumpStatement
+}
+
+/*
+ * The database of a \connect is either an identifier, quoted if necessary,
+ * or a connection string of the form "dbname='name'" in newer versions.
+ */
+func parseConnectDatabase(argument string) string {
+	argument = strings.TrimSpace(argument)
+	if strings.HasPrefix(argument, `"dbname='`) && strings.HasSuffix(argument, `'"`) {
+		name := argument[len(`"dbname='`) : len(argument)-len(`'"`)]
+		name = strings.Replace(name, `""`, `"`, -1)
+		return strings.NewReplacer(`\'`, `'`, `\\`, `\`).Replace(name)
+	}
+	return UnquoteIdent(argument)
+}
+
+/*
+ * Passes each row of the data following a COPY statement, including its
+ * terminating newline, to handleRow, stopping at the \. that ends the data.
+ */
+func (dumpReader *PgDumpReader) ReadCopyData(handleRow func(row string) error) error {
+	dumpReader.inCopy = false
+	for {
+		line, err := dumpReader.reader.ReadString('\n')
+		if err == io.EOF {
+			return errors.New("Unterminated COPY data at end of dump file")
+		} else if err != nil {
+			return err
+		}
+		if strings.TrimRight(line, "\r\n") == `\.` {
+			return nil
+		}
+		if err = handleRow(line); err != nil {
+			return err
+		}
+	}
+}
+
+/*
+ * Splits a list such as a qualified name or a column list on the separator,
+ * ignoring separators within quoted identifiers, and trims each element.
+ */
+func SplitQuotedList(list string, separator rune) []string {
+	elements := make([]string, 0)
+	inQuotes := false
+	start := 0
+	for i, char := range list {
+		if char == '"' {
+			inQuotes = !inQuotes
+		} else if char == separator && !inQuotes {
+			elements = append(elements, strings.TrimSpace(list[start:i]))
+			start = i + 1
+		}
+	}
+	return append(elements, strings.TrimSpace(list[start:]))
+}
+
+// Quotes an identifier unless it is a lowercase name that needs no quotes
+func QuoteIdentIfNeeded(ident string) string {
+	if unquotedIdentRegex.MatchString(ident) {
+		return ident
+	}
+	return `"` + strings.Replace(ident, `"`, `""`, -1) + `"`
+}
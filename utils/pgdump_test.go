@@ -0,0 +1,158 @@
+package utils_test
+
+import (
+	"io"
+	"strings"
+
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("utils/pgdump tests", func() {
+	Describe("PgDumpReader", func() {
+		readAll := func(dump string) ([]utils.PgDumpStatement, map[string][]string, *utils.PgDumpReader) {
+			dumpReader := utils.NewPgDumpReader(strings.NewReader(dump))
+			statements := make([]utils.PgDumpStatement, 0)
+			rows := make(map[string][]string)
+			for {
+				statement, err := dumpReader.Next()
+				if err == io.EOF {
+					break
+				}
+				Expect(err).ToNot(HaveOccurred())
+				statements = append(statements, statement)
+				if statement.CopyTable != "" {
+					err = dumpReader.ReadCopyData(func(row string) error {
+						rows[statement.CopyTable] = append(rows[statement.CopyTable], row)
+						return nil
+					})
+					Expect(err).ToNot(HaveOccurred())
+				}
+			}
+			return statements, rows, dumpReader
+		}
+
+		It("splits a dump into statements described by their headers", func() {
+			dump := `--
+-- PostgreSQL database dump
+--
+
+-- Dumped from database version 12.4
+-- Dumped by pg_dump version 12.4
+
+SET statement_timeout = 0;
+
+--
+-- Name: foo; Type: TABLE; Schema: public; Owner: gpadmin
+--
+
+CREATE TABLE public.foo (
+    i integer,
+    t text
+);
+
+
+ALTER TABLE public.foo OWNER TO gpadmin;
+
+--
+-- Name: f(); Type: FUNCTION; Schema: public; Owner: gpadmin
+--
+
+CREATE FUNCTION public.f() RETURNS integer
+    LANGUAGE sql
+    AS $$SELECT 1;
+SELECT 2;$$;
+`
+			statements, _, dumpReader := readAll(dump)
+			Expect(dumpReader.DatabaseVersion).To(Equal("12.4"))
+			Expect(statements).To(Equal([]utils.PgDumpStatement{
+				{Statement: "SET statement_timeout = 0;"},
+				{Statement: "CREATE TABLE public.foo (\n    i integer,\n    t text\n);", ObjectType: "TABLE", Schema: "public", Name: "foo"},
+				{Statement: "ALTER TABLE public.foo OWNER TO gpadmin;", ObjectType: "TABLE", Schema: "public", Name: "foo"},
+				{Statement: "CREATE FUNCTION public.f() RETURNS integer\n    LANGUAGE sql\n    AS $$SELECT 1;\nSELECT 2;$$;", ObjectType: "FUNCTION", Schema: "public", Name: "f()"},
+			}))
+		})
+		It("reads the COPY data of tables", func() {
+			dump := `--
+-- Data for Name: foo; Type: TABLE DATA; Schema: public; Owner: gpadmin
+--
+
+COPY public.foo (i, t) FROM stdin;
+1	one
+2	\N
+\.
+
+
+--
+-- Name: bar; Type: TABLE; Schema: public; Owner: gpadmin
+--
+
+COPY public.bar FROM stdin;
+3
+\.
+`
+			statements, rows, _ := readAll(dump)
+			Expect(statements).To(HaveLen(2))
+			Expect(statements[0].ObjectType).To(Equal("TABLE DATA"))
+			Expect(statements[0].CopyTable).To(Equal("public.foo"))
+			Expect(statements[0].CopyColumns).To(Equal("i, t"))
+			Expect(statements[1].CopyTable).To(Equal("public.bar"))
+			Expect(statements[1].CopyColumns).To(Equal(""))
+			Expect(rows).To(Equal(map[string][]string{"public.foo": {"1\tone\n", "2\t\\N\n"}, "public.bar": {"3\n"}}))
+		})
+		It("skips COPY data that is not read", func() {
+			dumpReader := utils.NewPgDumpReader(strings.NewReader("COPY public.foo (i) FROM stdin;\n1;\n\\.\nSELECT 1;\n"))
+			statement, err := dumpReader.Next()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(statement.CopyTable).To(Equal("public.foo"))
+			statement, err = dumpReader.Next()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(statement.Statement).To(Equal("SELECT 1;"))
+		})
+		It("records the database of each statement in a pg_dumpall file", func() {
+			dump := `CREATE ROLE gpadmin;
+\connect template1
+\connect db1
+CREATE SCHEMA s1;
+\connect -reuse-previous=on "dbname='it\'s a db'"
+CREATE SCHEMA s2;
+\connect "Other DB"
+\restrict abc
+CREATE SCHEMA s3;
+`
+			statements, _, _ := readAll(dump)
+			Expect(statements).To(HaveLen(4))
+			Expect(statements[0].Database).To(Equal(""))
+			Expect(statements[1].Database).To(Equal("db1"))
+			Expect(statements[2].Database).To(Equal("it's a db"))
+			Expect(statements[3].Database).To(Equal("Other DB"))
+		})
+		It("returns an error for a dump that ends within a statement", func() {
+			dumpReader := utils.NewPgDumpReader(strings.NewReader("CREATE TABLE public.foo (\n    i integer\n"))
+			_, err := dumpReader.Next()
+			Expect(err).To(MatchError(ContainSubstring("Incomplete statement at end of dump file")))
+		})
+		It("returns an error for COPY data that is not terminated", func() {
+			dumpReader := utils.NewPgDumpReader(strings.NewReader("COPY public.foo (i) FROM stdin;\n1\n"))
+			_, err := dumpReader.Next()
+			Expect(err).ToNot(HaveOccurred())
+			err = dumpReader.ReadCopyData(func(string) error { return nil })
+			Expect(err).To(MatchError("Unterminated COPY data at end of dump file"))
+		})
+	})
+	Describe("SplitQuotedList", func() {
+		It("splits on separators outside of quoted identifiers", func() {
+			Expect(utils.SplitQuotedList(`public."a.b"`, '.')).To(Equal([]string{"public", `"a.b"`}))
+			Expect(utils.SplitQuotedList(`i, "j, k",l`, ',')).To(Equal([]string{"i", `"j, k"`, "l"}))
+		})
+	})
+	Describe("QuoteIdentIfNeeded", func() {
+		It("quotes identifiers only when needed", func() {
+			Expect(utils.QuoteIdentIfNeeded("foo_1")).To(Equal("foo_1"))
+			Expect(utils.QuoteIdentIfNeeded("Foo")).To(Equal(`"Foo"`))
+			Expect(utils.QuoteIdentIfNeeded(`a"b`)).To(Equal(`"a""b"`))
+		})
+	})
+})
@@ -74,13 +74,23 @@ func (plugin *PluginConfig) MustBackupFile(filenamePath string) {
 	gplog.FatalOnError(err)
 }
 
-func (plugin *PluginConfig) MustRestoreFile(filenamePath string) {
+func (plugin *PluginConfig) RestoreFile(filenamePath string) error {
 	directory, _ := filepath.Split(filenamePath)
 	err := operating.System.MkdirAll(directory, 0755)
-	gplog.FatalOnError(err)
+	if err != nil {
+		return err
+	}
 	command := fmt.Sprintf("%s restore_file %s %s", plugin.ExecutablePath, plugin.ConfigPath, filenamePath)
 	output, err := exec.Command("bash", "-c", command).CombinedOutput()
-	gplog.FatalOnError(err, string(output))
+	if err != nil {
+		return fmt.Errorf("Plugin failed to restore %s. %s", filenamePath, string(output))
+	}
+	return nil
+}
+
+func (plugin *PluginConfig) MustRestoreFile(filenamePath string) {
+	err := plugin.RestoreFile(filenamePath)
+	gplog.FatalOnError(err)
 }
 
 func (plugin *PluginConfig) CheckPluginExistsOnAllHosts(c *cluster.Cluster) string {
@@ -34,12 +34,19 @@ const (
 	INCR_PERCENT = 10
 )
 
+// Progress bars are not printed while progress is reported as JSON events
+var printProgressBars = true
+
+func SetPrintProgressBars(print bool) {
+	printProgressBars = print
+}
+
 func NewProgressBar(count int, prefix string, showProgressBar int) ProgressBar {
 	progressBar := pb.New(count).Prefix(prefix)
 	progressBar.ShowTimeLeft = false
 	progressBar.SetMaxWidth(100)
 	progressBar.SetRefreshRate(time.Millisecond * 200)
-	progressBar.NotPrint = !(showProgressBar >= PB_INFO && count > 0 && gplog.GetVerbosity() == gplog.LOGINFO)
+	progressBar.NotPrint = !(printProgressBars && showProgressBar >= PB_INFO && count > 0 && gplog.GetVerbosity() == gplog.LOGINFO)
 	if showProgressBar == PB_VERBOSE {
 		verboseProgressBar := NewVerboseProgressBar(count, prefix)
 		verboseProgressBar.ProgressBar = progressBar
@@ -0,0 +1,143 @@
+package utils
+
+/*
+ * This file contains the stream of progress events written as newline-delimited
+ * JSON when --progress-format json is given, so that orchestration tools can
+ * follow a backup without parsing its log messages or progress bar.
+ */
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/pkg/errors"
+)
+
+const (
+	PROGRESS_FORMAT_TEXT = "text"
+	PROGRESS_FORMAT_JSON = "json"
+
+	EVENT_PHASE_STARTED   = "phase_started"
+	EVENT_TABLE_COMPLETED = "table_completed"
+	EVENT_WARNING         = "warning"
+	EVENT_FINISHED        = "finished"
+)
+
+type ProgressEvent struct {
+	Time     string `json:"time"`
+	Event    string `json:"event"`
+	Phase    string `json:"phase,omitempty"`
+	Table    string `json:"table,omitempty"`
+	Rows     *int64 `json:"rows,omitempty"`
+	Bytes    *int64 `json:"bytes,omitempty"`
+	Message  string `json:"message,omitempty"`
+	Status   string `json:"status,omitempty"`
+	ExitCode *int   `json:"exit_code,omitempty"`
+}
+
+/*
+ * Events are written whole, one per line, so that events sent from several
+ * goroutines are never interleaved.  A nil ProgressStream discards events, so
+ * it can be used whether or not --progress-format json was given.
+ */
+type ProgressStream struct {
+	mutex  sync.Mutex
+	writer io.Writer
+	closer io.Closer
+}
+
+func NewProgressStream(writer io.Writer) *ProgressStream {
+	return &ProgressStream{writer: writer}
+}
+
+// With no filename, events are written to stdout
+func OpenProgressStream(filename string) (*ProgressStream, error) {
+	if filename == "" {
+		return NewProgressStream(os.Stdout), nil
+	}
+	file, err := operating.System.OpenFileWrite(filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to open progress file %s", filename)
+	}
+	stream := NewProgressStream(file)
+	stream.closer = file
+	return stream, nil
+}
+
+func (stream *ProgressStream) Send(event ProgressEvent) {
+	if stream == nil {
+		return
+	}
+	event.Time = operating.System.Now().Format("2006-01-02T15:04:05.000Z07:00")
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+	_, _ = stream.writer.Write(append(line, '\n'))
+}
+
+func (stream *ProgressStream) PhaseStarted(phase string) {
+	stream.Send(ProgressEvent{Event: EVENT_PHASE_STARTED, Phase: phase})
+}
+
+func (stream *ProgressStream) TableCompleted(table string, rows int64, bytes int64) {
+	stream.Send(ProgressEvent{Event: EVENT_TABLE_COMPLETED, Table: table, Rows: &rows, Bytes: &bytes})
+}
+
+func (stream *ProgressStream) Warning(message string) {
+	stream.Send(ProgressEvent{Event: EVENT_WARNING, Message: message})
+}
+
+func (stream *ProgressStream) Finished(status string, exitCode int) {
+	stream.Send(ProgressEvent{Event: EVENT_FINISHED, Status: status, ExitCode: &exitCode})
+}
+
+func (stream *ProgressStream) Close() {
+	if stream == nil || stream.closer == nil {
+		return
+	}
+	_ = stream.closer.Close()
+}
+
+/*
+ * gplog has no way to observe the messages it logs, so warnings are taken
+ * from its console output as it is written.  Each line is passed through to
+ * the given writer, which is stderr when events are written to stdout so that
+ * stdout contains nothing but events.
+ */
+type progressLogWriter struct {
+	stream      *ProgressStream
+	passthrough io.Writer
+}
+
+func (writer progressLogWriter) Write(output []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+		if index := strings.Index(line, "[WARNING]:-"); index >= 0 {
+			writer.stream.Warning(line[index+len("[WARNING]:-"):])
+		}
+	}
+	return writer.passthrough.Write(output)
+}
+
+/*
+ * Replaces the gplog logger with one whose console output is scanned for
+ * warnings to send to the stream.  The log file is opened again by name, in
+ * append mode, as the current logger does not expose it.
+ */
+func InitializeProgressLogging(stream *ProgressStream, program string, passthrough io.Writer) error {
+	logFilePath := gplog.GetLogFilePath()
+	logFile, err := operating.System.OpenFileWrite(logFilePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to open log file %s", logFilePath)
+	}
+	gplog.SetLogger(gplog.NewLogger(progressLogWriter{stream: stream, passthrough: passthrough}, os.Stderr, logFile,
+		logFilePath, gplog.GetVerbosity(), program, gplog.GetLogFileVerbosity()))
+	return nil
+}
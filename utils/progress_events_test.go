@@ -0,0 +1,72 @@
+package utils_test
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/greenplum-db/gpbackup/utils"
+	"github.com/onsi/gomega/gbytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("utils/progress_events tests", func() {
+	var events *gbytes.Buffer
+	var stream *utils.ProgressStream
+	BeforeEach(func() {
+		operating.System.Now = func() time.Time { return time.Date(2019, time.October, 10, 12, 0, 0, 0, time.UTC) }
+		events = gbytes.NewBuffer()
+		stream = utils.NewProgressStream(events)
+	})
+	AfterEach(func() {
+		operating.System = operating.InitializeSystemFunctions()
+	})
+	Describe("ProgressStream", func() {
+		It("writes each event as a line of JSON", func() {
+			stream.PhaseStarted("data")
+			stream.TableCompleted("public.foo", 0, 8192)
+			stream.Finished("Success", 0)
+
+			Expect(string(events.Contents())).To(Equal(`{"time":"2019-10-10T12:00:00.000Z","event":"phase_started","phase":"data"}
+{"time":"2019-10-10T12:00:00.000Z","event":"table_completed","table":"public.foo","rows":0,"bytes":8192}
+{"time":"2019-10-10T12:00:00.000Z","event":"finished","status":"Success","exit_code":0}
+`))
+		})
+		It("discards events when there is no stream", func() {
+			var nilStream *utils.ProgressStream
+
+			nilStream.PhaseStarted("data")
+			nilStream.Warning("warning")
+			nilStream.Close()
+		})
+	})
+	Describe("InitializeProgressLogging", func() {
+		var previousLogger *gplog.GpLogger
+		BeforeEach(func() {
+			previousLogger = gplog.GetLogger()
+			gplog.SetLogger(gplog.NewLogger(stdout, stderr, logfile, "/tmp/gpbackup_test.log", gplog.LOGINFO, "testProgram"))
+			operating.System.OpenFileWrite = func(name string, flag int, perm os.FileMode) (io.WriteCloser, error) { return logfile, nil }
+		})
+		AfterEach(func() {
+			gplog.SetLogger(previousLogger)
+		})
+		It("sends warnings as events and passes log messages through", func() {
+			passthrough := gbytes.NewBuffer()
+			err := utils.InitializeProgressLogging(stream, "testProgram", passthrough)
+			Expect(err).ToNot(HaveOccurred())
+
+			gplog.Info("an info message")
+			gplog.Warn("a warning message")
+
+			Expect(string(events.Contents())).To(Equal(`{"time":"2019-10-10T12:00:00.000Z","event":"warning","message":"a warning message"}
+`))
+			Expect(passthrough).To(gbytes.Say("an info message"))
+			Expect(passthrough).To(gbytes.Say("a warning message"))
+			Expect(logfile).To(gbytes.Say("a warning message"))
+		})
+	})
+})
@@ -26,11 +26,57 @@ import (
  * file that we will want to read in for a restore.
  */
 type Report struct {
-	BackupParamsString string
-	DatabaseSize       string
+	Aborted              bool
+	BackupParamsString   string
+	DataSampleChecks     []DataSampleCheck
+	DatabaseSize         string
+	LockDuration         time.Duration
+	NumLeafPartitions    int
+	NumPartitionedTables int
+	TableClassifications []TableClassification
+	TableTimings         []TableTiming
+	VerificationQueries  []VerificationQuery
 	backup_history.BackupConfig
 }
 
+type TableClassification struct {
+	StorageType string
+	Compression string
+	NumTables   int
+	Size        string
+}
+
+/*
+ * Bytes is the on-disk size of the table rather than the size of its backup
+ * file, so throughput is an estimate of how quickly the table was read.
+ * Retries is the number of times the table's COPY failed before it succeeded.
+ */
+type TableTiming struct {
+	Table     string
+	StartTime time.Time
+	Duration  time.Duration
+	Rows      int64
+	Bytes     int64
+	Retries   int
+}
+
+func (timing TableTiming) MBPerSecond() float64 {
+	if timing.Duration <= 0 {
+		return 0
+	}
+	return float64(timing.Bytes) / (1024 * 1024) / timing.Duration.Seconds()
+}
+
+/*
+ * Rows is the number of rows of the table's data files that were loaded, and
+ * Error is set if any of them could not be.
+ */
+type DataSampleCheck struct {
+	Table string
+	Rows  int64
+	Error string
+}
+
 type LineInfo struct {
 	Key   string
 	Value string
@@ -89,15 +135,20 @@ func (report *Report) ConstructBackupParamsString() {
 	if report.WithStatistics {
 		statsStr = "Yes"
 	}
+	largeObjectsStr := "No"
+	if report.WithLargeObjects {
+		largeObjectsStr = "Yes"
+	}
 	backupParamsTemplate := `compression: %s
 plugin executable: %s
 backup section: %s
 object filtering: %s
 includes statistics: %s
+includes large objects: %s
 data file format: %s
 %s`
 	report.BackupParamsString = fmt.Sprintf(backupParamsTemplate, compressStr, pluginStr, sectionStr, filterStr,
-		statsStr, filesStr, report.constructIncrementalSection())
+		statsStr, largeObjectsStr, filesStr, report.constructIncrementalSection())
 }
 
 func (report *Report) constructIncrementalSection() string {
@@ -139,8 +190,17 @@ func (report *Report) WriteBackupReportFile(reportFilename string, timestamp str
 		LineInfo{Key: "start time:", Value: start},
 		LineInfo{Key: "end time:", Value: end},
 		LineInfo{Key: "duration:", Value: duration})
+	if report.LockDuration > 0 {
+		reportInfo = append(reportInfo,
+			LineInfo{Key: "table lock duration:", Value: reformatDuration(report.LockDuration)})
+	}
 
-	if errMsg != "" {
+	if report.Aborted {
+		reportInfo = append(reportInfo,
+			LineInfo{},
+			LineInfo{Key: "backup status:", Value: "Aborted"},
+			LineInfo{Key: "backup error:", Value: errMsg})
+	} else if errMsg != "" {
 		reportInfo = append(reportInfo,
 			LineInfo{},
 			LineInfo{Key: "backup status:", Value: "Failure"},
@@ -164,6 +224,11 @@ func (report *Report) WriteBackupReportFile(reportFilename string, timestamp str
 
 	logOutputReport(reportFile, reportInfo)
 
+	report.PrintTableClassification(reportFile)
+	report.PrintSlowestTables(reportFile, 10)
+	report.PrintRetriedTables(reportFile)
+	report.PrintVerificationQueries(reportFile)
+	report.PrintDataSampleChecks(reportFile)
 	PrintObjectCounts(reportFile, objectCounts)
 
 	err = reportFile.Close()
@@ -256,6 +321,127 @@ func reformatDuration(duration time.Duration) string {
 	return fmt.Sprintf("%d:%02d:%02d", hour, min, sec)
 }
 
+func (report *Report) PrintTableClassification(reportFile io.WriteCloser) {
+	if len(report.TableClassifications) == 0 {
+		return
+	}
+	storageWidth, compressionWidth := len("storage type"), len("compression")
+	for _, class := range report.TableClassifications {
+		if len(class.StorageType) > storageWidth {
+			storageWidth = len(class.StorageType)
+		}
+		if len(class.Compression) > compressionWidth {
+			compressionWidth = len(class.Compression)
+		}
+	}
+	classificationStr := "\ntable storage classification:\n"
+	classificationStr += strings.TrimSpace(fmt.Sprintf("%-*s%-*s%-9s%s", storageWidth+3, "storage type", compressionWidth+3, "compression", "tables", "size")) + "\n"
+	for _, class := range report.TableClassifications {
+		classificationStr += strings.TrimSpace(fmt.Sprintf("%-*s%-*s%-9d%s", storageWidth+3, class.StorageType, compressionWidth+3, class.Compression, class.NumTables, class.Size)) + "\n"
+	}
+	if report.NumPartitionedTables > 0 || report.NumLeafPartitions > 0 {
+		classificationStr += fmt.Sprintf("partitioned tables: %d\n", report.NumPartitionedTables)
+	}
+	if report.NumLeafPartitions > 0 {
+		classificationStr += fmt.Sprintf("leaf partitions: %d\n", report.NumLeafPartitions)
+	}
+	MustPrintf(reportFile, classificationStr)
+}
+
+func (report *Report) PrintSlowestTables(reportFile io.WriteCloser, numTables int) {
+	if len(report.TableTimings) == 0 {
+		return
+	}
+	timings := make([]TableTiming, len(report.TableTimings))
+	copy(timings, report.TableTimings)
+	sort.SliceStable(timings, func(i, j int) bool {
+		return timings[i].Duration > timings[j].Duration
+	})
+	if len(timings) > numTables {
+		timings = timings[:numTables]
+	}
+	tableWidth := len("table")
+	for _, timing := range timings {
+		if len(timing.Table) > tableWidth {
+			tableWidth = len(timing.Table)
+		}
+	}
+	timingStr := "\nslowest tables:\n"
+	timingStr += fmt.Sprintf("%-*s%-11s%-13s%s\n", tableWidth+3, "table", "duration", "rows", "MB/s")
+	for _, timing := range timings {
+		timingStr += fmt.Sprintf("%-*s%-11s%-13d%.2f\n", tableWidth+3, timing.Table, reformatDuration(timing.Duration), timing.Rows, timing.MBPerSecond())
+	}
+	MustPrintf(reportFile, timingStr)
+}
+
+func (report *Report) PrintRetriedTables(reportFile io.WriteCloser) {
+	retried := make([]TableTiming, 0)
+	tableWidth := len("table")
+	for _, timing := range report.TableTimings {
+		if timing.Retries > 0 {
+			retried = append(retried, timing)
+			if len(timing.Table) > tableWidth {
+				tableWidth = len(timing.Table)
+			}
+		}
+	}
+	if len(retried) == 0 {
+		return
+	}
+	sort.SliceStable(retried, func(i, j int) bool {
+		return retried[i].Table < retried[j].Table
+	})
+	retryStr := "\nretried tables:\n"
+	retryStr += fmt.Sprintf("%-*s%s\n", tableWidth+3, "table", "retries")
+	for _, timing := range retried {
+		retryStr += fmt.Sprintf("%-*s%d\n", tableWidth+3, timing.Table, timing.Retries)
+	}
+	MustPrintf(reportFile, retryStr)
+}
+
+func (report *Report) PrintVerificationQueries(reportFile io.WriteCloser) {
+	if len(report.VerificationQueries) == 0 {
+		return
+	}
+	nameWidth := 0
+	for _, query := range report.VerificationQueries {
+		if len(query.Name) > nameWidth {
+			nameWidth = len(query.Name)
+		}
+	}
+	verificationStr := "\nverification queries:\n"
+	for _, query := range report.VerificationQueries {
+		result := query.Result
+		if query.Error != "" {
+			result = fmt.Sprintf("ERROR: %s", query.Error)
+		}
+		verificationStr += fmt.Sprintf("%-*s%s\n", nameWidth+4, query.Name+":", result)
+	}
+	MustPrintf(reportFile, verificationStr)
+}
+
+func (report *Report) PrintDataSampleChecks(reportFile io.WriteCloser) {
+	if len(report.DataSampleChecks) == 0 {
+		return
+	}
+	tableWidth := len("table")
+	for _, check := range report.DataSampleChecks {
+		if len(check.Table) > tableWidth {
+			tableWidth = len(check.Table)
+		}
+	}
+	sampleStr := "\ndata sample checks:\n"
+	sampleStr += fmt.Sprintf("%-*s%-15s%s\n", tableWidth+3, "table", "sampled rows", "result")
+	for _, check := range report.DataSampleChecks {
+		result := "OK"
+		if check.Error != "" {
+			result = fmt.Sprintf("ERROR: %s", check.Error)
+		}
+		sampleStr += fmt.Sprintf("%-*s%-15d%s\n", tableWidth+3, check.Table, check.Rows, result)
+	}
+	MustPrintf(reportFile, sampleStr)
+}
+
 func PrintObjectCounts(reportFile io.WriteCloser, objectCounts map[string]int) {
 	objectStr := "\ncount of database objects in backup:\n"
 	objectSlice := make([]string, 0)
@@ -332,7 +518,7 @@ func GetContacts(filename string, utility string) string {
 	exitStatus := "success"
 	if errorCode == 1 {
 		exitStatus = "success_with_errors"
-	} else if errorCode == 2 {
+	} else if errorCode >= 2 {
 		exitStatus = "failure"
 	}
 
@@ -132,6 +132,89 @@ count of database objects in backup:
 sequences   1
 tables      42
 types       1000`))
+		})
+		It("writes a table storage classification section", func() {
+			backupReport.TableClassifications = []utils.TableClassification{
+				{StorageType: "append-optimized", Compression: "zlib level 5", NumTables: 2, Size: "1.0 GB"},
+				{StorageType: "heap", Compression: "none", NumTables: 40, Size: "3.5 GB"},
+			}
+			backupReport.NumPartitionedTables = 3
+			backupReport.WriteBackupReportFile("filename", timestamp, endtime, objectCounts, "")
+			Expect(buffer).To(gbytes.Say(`table storage classification:
+storage type       compression    tables   size
+append-optimized   zlib level 5   2        1.0 GB
+heap               none           40       3.5 GB
+partitioned tables: 3
+
+count of database objects in backup:`))
+		})
+		It("writes a section listing the slowest tables", func() {
+			backupReport.TableTimings = []utils.TableTiming{
+				{Table: "public.fast", Duration: 2 * time.Second, Rows: 10, Bytes: 1024 * 1024},
+				{Table: "public.slow_table", Duration: 90 * time.Second, Rows: 1000000, Bytes: 450 * 1024 * 1024},
+				{Table: "public.medium", Duration: 10 * time.Second, Rows: 500, Bytes: 0},
+			}
+			backupReport.WriteBackupReportFile("filename", timestamp, endtime, objectCounts, "")
+			Expect(buffer).To(gbytes.Say(`slowest tables:
+table               duration   rows         MB/s
+public.slow_table   0:01:30    1000000      5.00
+public.medium       0:00:10    500          0.00
+public.fast         0:00:02    10           0.50
+
+count of database objects in backup:`))
+		})
+		It("writes a section listing the tables whose data backup was retried", func() {
+			backupReport.TableTimings = []utils.TableTiming{
+				{Table: "public.retried_table", Duration: 2 * time.Second, Retries: 2},
+				{Table: "public.other", Duration: 1 * time.Second},
+			}
+			backupReport.WriteBackupReportFile("filename", timestamp, endtime, objectCounts, "")
+			Expect(buffer).To(gbytes.Say(`retried tables:
+table                  retries
+public.retried_table   2
+
+count of database objects in backup:`))
+		})
+		It("writes a section listing the results of verification queries", func() {
+			backupReport.VerificationQueries = []utils.VerificationQuery{
+				{Name: "orders row count", Query: "SELECT count(*) FROM sales.orders", Result: "1000"},
+				{Name: "invoices", Query: "SELECT count(*) FROM sales.invoices", Error: "relation does not exist"},
+			}
+			backupReport.WriteBackupReportFile("filename", timestamp, endtime, objectCounts, "")
+			Expect(buffer).To(gbytes.Say(`verification queries:
+orders row count:   1000
+invoices:           ERROR: relation does not exist
+
+count of database objects in backup:`))
+		})
+		It("writes a section listing the results of data sample checks", func() {
+			backupReport.DataSampleChecks = []utils.DataSampleCheck{
+				{Table: "public.orders", Rows: 200},
+				{Table: "public.invoices", Error: "invalid byte sequence for encoding"},
+			}
+			backupReport.WriteBackupReportFile("filename", timestamp, endtime, objectCounts, "")
+			Expect(buffer).To(gbytes.Say(`data sample checks:
+table             sampled rows   result
+public.orders     200            OK
+public.invoices   0              ERROR: invalid byte sequence for encoding
+
+count of database objects in backup:`))
+		})
+		It("writes a report for an aborted backup", func() {
+			backupReport.Aborted = true
+			backupReport.WriteBackupReportFile("filename", timestamp, endtime, objectCounts, "Backup was terminated before it completed; its files are incomplete")
+			Expect(buffer).To(gbytes.Say(`backup status:         Aborted
+backup error:          Backup was terminated before it completed; its files are incomplete`))
+		})
+		It("writes a report including the time spent acquiring table locks", func() {
+			backupReport.LockDuration = 90 * time.Second
+			backupReport.WriteBackupReportFile("filename", timestamp, endtime, objectCounts, "")
+			Expect(buffer).To(gbytes.Say(`start time:            Sun Jan 01 2017 01:01:01
+end time:              Sun Jan 01 2017 05:04:03
+duration:              4:03:02
+table lock duration:   0:01:30
+
+backup status:         Success`))
 		})
 		It("writes a report without database size information", func() {
 			backupReport.DatabaseSize = ""
@@ -284,7 +367,6 @@ restore status:      Success but non-fatal errors occurred. See log file .+ for
 			utils.InitializePipeThroughParameters(true, 0)
 			backupCmdFlags := pflag.NewFlagSet("gpbackup", pflag.ExitOnError)
 			backup.SetFlagDefaults(backupCmdFlags)
-			backup.SetCmdFlags(backupCmdFlags)
 			err := backupCmdFlags.Set(utils.INCLUDE_RELATION, "public.foobar")
 			Expect(err).ToNot(HaveOccurred())
 			opts, err := options.NewOptions(backupCmdFlags)
@@ -293,7 +375,7 @@ restore status:      Success but non-fatal errors occurred. See log file .+ for
 			err = backupCmdFlags.Set(utils.INCLUDE_RELATION, "public.baz")
 			Expect(err).ToNot(HaveOccurred())
 
-			backupConfig := backup.NewBackupConfig("testdb",
+			backupConfig := backup.NewBackup(backupCmdFlags, nil, nil).NewBackupConfig("testdb",
 				"5.0.0 build test", "0.1.0",
 				"/tmp/plugin.sh", "timestamp1", *opts)
 			structmatcher.ExpectStructsToMatch(backup_history.BackupConfig{
@@ -306,6 +388,7 @@ restore status:      Success but non-fatal errors occurred. See log file .+ for
 				ExcludeSchemas:       []string{},
 				ExcludeRelations:     []string{},
 				Plugin:               "/tmp/plugin.sh",
+				Target:               "greenplum",
 				Timestamp:            "timestamp1",
 				IncludeTableFiltered: true,
 			}, backupConfig)
@@ -483,6 +566,14 @@ Timestamp Key: 20170101010101`)
 				contacts := utils.GetContacts(contactsFilename, "gpbackup")
 				Expect(contacts).To(Equal("contact2@example.org"))
 			})
+			It("Gets a list of gpbackup contacts on a failure with a specific exit code", func() {
+				gplog.SetErrorCode(utils.EXIT_DISK_FULL)
+				_, _ = w.Write(contactsFileContents)
+				_ = w.Close()
+
+				contacts := utils.GetContacts(contactsFilename, "gpbackup")
+				Expect(contacts).To(Equal("contact2@example.org"))
+			})
 			It("Gets a list of gprestore contacts and doesn't fail when no status specified", func() {
 				gplog.SetErrorCode(0)
 				_, _ = w.Write(contactsFileContents)
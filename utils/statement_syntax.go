@@ -0,0 +1,154 @@
+package utils
+
+/*
+ * This file contains a lexical check of the metadata statements in a backup,
+ * which finds statements that were truncated or corrupted without needing a
+ * database to parse them.
+ */
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var dollarQuoteTag = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)?\$`)
+
+func isIdentifierChar(char byte) bool {
+	return char == '_' || char == '$' || (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9')
+}
+
+/*
+ * Checks that every quoted string, quoted identifier, dollar-quoted body, and
+ * comment in the statement is closed, that its parentheses are balanced, and
+ * that it ends with a semicolon.  This does not check the statement against
+ * the SQL grammar, but it does catch the ways a statement is damaged when the
+ * metadata file is truncated or its table of contents is wrong.
+ */
+func CheckStatementSyntax(statement string) error {
+	depth := 0
+	var last byte
+	for i := 0; i < len(statement); {
+		char := statement[i]
+		switch {
+		case strings.HasPrefix(statement[i:], "--"):
+			end := strings.IndexByte(statement[i:], '\n')
+			if end == -1 {
+				i = len(statement)
+			} else {
+				i += end + 1
+			}
+			continue
+		case strings.HasPrefix(statement[i:], "/*"):
+			end, err := findCommentEnd(statement, i)
+			if err != nil {
+				return err
+			}
+			i = end
+			continue
+		case char == '\'':
+			backslashEscapes := i > 0 && (statement[i-1] == 'E' || statement[i-1] == 'e') && (i == 1 || !isIdentifierChar(statement[i-2]))
+			end, err := findQuoteEnd(statement, i, '\'', backslashEscapes)
+			if err != nil {
+				return errors.New("unterminated quoted string")
+			}
+			i = end
+		case char == '"':
+			end, err := findQuoteEnd(statement, i, '"', false)
+			if err != nil {
+				return errors.New("unterminated quoted identifier")
+			}
+			i = end
+		case char == '$' && (i == 0 || !isIdentifierChar(statement[i-1])) && dollarQuoteTag.MatchString(statement[i:]):
+			tag := dollarQuoteTag.FindString(statement[i:])
+			end := strings.Index(statement[i+len(tag):], tag)
+			if end == -1 {
+				return errors.Errorf("unterminated dollar-quoted string %s", tag)
+			}
+			i += len(tag) + end + len(tag)
+		case char == '(':
+			depth++
+			i++
+		case char == ')':
+			depth--
+			if depth < 0 {
+				return errors.New("unbalanced parentheses")
+			}
+			i++
+		default:
+			i++
+		}
+		if char != ' ' && char != '\t' && char != '\n' && char != '\r' {
+			last = char
+		}
+	}
+	if last == 0 {
+		return errors.New("empty statement")
+	}
+	if depth != 0 {
+		return errors.New("unbalanced parentheses")
+	}
+	if last != ';' {
+		return errors.New("statement does not end with a semicolon")
+	}
+	return nil
+}
+
+// Block comments nest in PostgreSQL, unlike in the SQL standard
+func findCommentEnd(statement string, start int) (int, error) {
+	depth := 0
+	for i := start; i < len(statement)-1; i++ {
+		if statement[i] == '/' && statement[i+1] == '*' {
+			depth++
+			i++
+		} else if statement[i] == '*' && statement[i+1] == '/' {
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1, nil
+			}
+		}
+	}
+	return 0, errors.New("unterminated comment")
+}
+
+// A doubled quote character is an escaped quote rather than the closing quote
+func findQuoteEnd(statement string, start int, quote byte, backslashEscapes bool) (int, error) {
+	for i := start + 1; i < len(statement); i++ {
+		if backslashEscapes && statement[i] == '\\' {
+			i++
+		} else if statement[i] == quote {
+			if i+1 < len(statement) && statement[i+1] == quote {
+				i++
+			} else {
+				return i + 1, nil
+			}
+		}
+	}
+	return 0, errors.New("unterminated quote")
+}
+
+/*
+ * Returns a description of each statement in the given section of the table
+ * of contents that lies outside the metadata file or fails the check above.
+ * The contents must be those of the uncompressed metadata file.
+ */
+func (toc *TOC) FindInvalidStatements(section string, contents []byte) []string {
+	problems := make([]string, 0)
+	for _, entry := range *toc.metadataEntryMap[section] {
+		name := entry.Name
+		if entry.Schema != "" {
+			name = MakeFQN(entry.Schema, entry.Name)
+		}
+		if entry.StartByte > entry.EndByte || entry.EndByte > uint64(len(contents)) {
+			problems = append(problems, fmt.Sprintf("%s %s is outside the bounds of the metadata file", entry.ObjectType, name))
+			continue
+		}
+		if err := CheckStatementSyntax(string(contents[entry.StartByte:entry.EndByte])); err != nil {
+			problems = append(problems, fmt.Sprintf("%s %s: %s", entry.ObjectType, name, err.Error()))
+		}
+	}
+	return problems
+}
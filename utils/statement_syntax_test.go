@@ -0,0 +1,56 @@
+package utils_test
+
+import (
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("utils/statement_syntax tests", func() {
+	Describe("CheckStatementSyntax", func() {
+		It("accepts complete statements", func() {
+			for _, statement := range []string{
+				"\n\nCREATE TABLE public.foo (\n\ti integer\n) DISTRIBUTED BY (i);",
+				"\n\nCOMMENT ON TABLE public.foo IS 'it''s a (table';",
+				"\n\nCOMMENT ON TABLE public.foo IS E'it\\'s a table';",
+				`CREATE SCHEMA "weird;""(schema";`,
+				"CREATE FUNCTION public.f() RETURNS integer AS $_$SELECT 1; -- ($_$ LANGUAGE sql;",
+				"CREATE FUNCTION public.f() RETURNS integer AS $$SELECT $1$$ LANGUAGE sql;",
+				"/* a /* nested */ comment ( */ SELECT 1; -- trailing (",
+			} {
+				Expect(utils.CheckStatementSyntax(statement)).To(Succeed(), statement)
+			}
+		})
+		It("rejects statements that have been cut short", func() {
+			for statement, message := range map[string]string{
+				"COMMENT ON TABLE public.foo IS 'it''s a":                   "unterminated quoted string",
+				`CREATE SCHEMA "weird`:                                      "unterminated quoted identifier",
+				"CREATE FUNCTION public.f() RETURNS integer AS $_$SELECT 1": "unterminated dollar-quoted string $_$",
+				"/* a /* nested */ comment":                                 "unterminated comment",
+				"CREATE TABLE public.foo (\n\ti integer":                    "unbalanced parentheses",
+				"CREATE TABLE public.foo ()) DISTRIBUTED RANDOMLY;":         "unbalanced parentheses",
+				"CREATE TABLE public.foo (i integer) DISTRIBUTED":           "statement does not end with a semicolon",
+				"\n\n": "empty statement",
+			} {
+				Expect(utils.CheckStatementSyntax(statement)).To(MatchError(message), statement)
+			}
+		})
+	})
+	Describe("FindInvalidStatements", func() {
+		It("reports statements that are damaged or outside the metadata file", func() {
+			contents := []byte("CREATE SCHEMA foo;\nCREATE TABLE foo.bar (i int\nCREATE ROLE r;")
+			toc := &utils.TOC{PredataEntries: []utils.MetadataEntry{
+				{Schema: "", Name: "foo", ObjectType: "SCHEMA", StartByte: 0, EndByte: 18},
+				{Schema: "foo", Name: "bar", ObjectType: "TABLE", StartByte: 18, EndByte: 46},
+				{Schema: "", Name: "r", ObjectType: "ROLE", StartByte: 46, EndByte: 100},
+			}}
+			toc.InitializeMetadataEntryMap()
+
+			Expect(toc.FindInvalidStatements("predata", contents)).To(Equal([]string{
+				"TABLE foo.bar: unbalanced parentheses",
+				"ROLE r is outside the bounds of the metadata file",
+			}))
+		})
+	})
+})
@@ -5,9 +5,12 @@ import (
 	"io"
 	"os"
 	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/greenplum-db/gp-common-go-libs/gplog"
 	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
 )
 
@@ -17,8 +20,10 @@ type TOC struct {
 	PredataEntries      []MetadataEntry
 	PostdataEntries     []MetadataEntry
 	StatisticsEntries   []MetadataEntry
+	LargeObjectEntries  []MetadataEntry `yaml:",omitempty"`
 	DataEntries         []MasterDataEntry
 	IncrementalMetadata IncrementalEntries
+	MetadataChecksum    string `yaml:",omitempty"`
 }
 
 type SegmentTOC struct {
@@ -41,11 +46,13 @@ type MasterDataEntry struct {
 	AttributeString string
 	RowsCopied      int64
 	PartitionRoot   string
+	IsExternal      bool `yaml:",omitempty"`
 }
 
 type SegmentDataEntry struct {
-	StartByte uint64
-	EndByte   uint64
+	StartByte        uint64
+	EndByte          uint64
+	CompressionLevel int `yaml:",omitempty"`
 }
 
 type IncrementalEntries struct {
@@ -59,7 +66,7 @@ type AOEntry struct {
 
 func NewTOC(filename string) *TOC {
 	toc := &TOC{}
-	contents, err := operating.System.ReadFile(filename)
+	contents, err := ReadFileDecompressingIfNeeded(filename)
 	gplog.FatalOnError(err)
 	err = yaml.Unmarshal(contents, toc)
 	gplog.FatalOnError(err)
@@ -78,7 +85,30 @@ func NewSegmentTOC(filename string) *SegmentTOC {
 func (toc *TOC) WriteToFileAndMakeReadOnly(filename string) {
 	tocContents, err := yaml.Marshal(toc)
 	gplog.FatalOnError(err)
+	writeTOCContentsAndMakeReadOnly(filename, tocContents)
+}
+
+func (toc *TOC) WriteToCompressedFileAndMakeReadOnly(filename string, compressionLevel int) {
+	tocContents, err := yaml.Marshal(toc)
+	gplog.FatalOnError(err)
+	tocContents, err = CompressBytes(tocContents, compressionLevel)
+	gplog.FatalOnError(err)
+	writeTOCContentsAndMakeReadOnly(filename, tocContents)
+}
+
+func (toc *TOC) WriteToEncryptedFileAndMakeReadOnly(filename string, compressionLevel int, key []byte) {
+	tocContents, err := yaml.Marshal(toc)
+	gplog.FatalOnError(err)
+	if compressionLevel > 0 {
+		tocContents, err = CompressBytes(tocContents, compressionLevel)
+		gplog.FatalOnError(err)
+	}
+	tocContents, err = EncryptBytes(tocContents, key)
+	gplog.FatalOnError(err)
+	writeTOCContentsAndMakeReadOnly(filename, tocContents)
+}
 
+func writeTOCContentsAndMakeReadOnly(filename string, tocContents []byte) {
 	tocFile, err := os.OpenFile(filename, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
 	gplog.FatalOnError(err)
 
@@ -172,6 +202,45 @@ func (toc *TOC) GetSQLStatementForObjectTypes(section string, metadataFile io.Re
 	return statements
 }
 
+const (
+	SPLIT_BY_OBJECT_TYPE = "object-type"
+	SPLIT_BY_SCHEMA      = "schema"
+)
+
+var unsafeFilenamePattern = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+/*
+ * Group the global, predata, and postdata entries by the file they belong in
+ * when metadata is split by object type or by schema, keeping the entries in
+ * each file in TOC order so each file can be run on its own.  Objects outside
+ * any schema are grouped by section when splitting by schema.  Session GUCs
+ * are not assigned a file, as they belong at the top of every file.
+ */
+func (toc *TOC) GetSplitMetadataEntries(splitBy string) ([]string, map[string][]MetadataEntry) {
+	filenames := make([]string, 0)
+	entriesByFile := make(map[string][]MetadataEntry)
+	for _, section := range []string{"global", "predata", "postdata"} {
+		for _, entry := range *toc.metadataEntryMap[section] {
+			if entry.ObjectType == "SESSION GUCS" {
+				continue
+			}
+			filename := ""
+			if splitBy == SPLIT_BY_SCHEMA && entry.Schema != "" {
+				filename = "schema_" + unsafeFilenamePattern.ReplaceAllString(strings.Trim(entry.Schema, `"`), "_") + ".sql"
+			} else if splitBy == SPLIT_BY_SCHEMA {
+				filename = section + ".sql"
+			} else {
+				filename = strings.ToLower(unsafeFilenamePattern.ReplaceAllString(entry.ObjectType, "_")) + ".sql"
+			}
+			if _, ok := entriesByFile[filename]; !ok {
+				filenames = append(filenames, filename)
+			}
+			entriesByFile[filename] = append(entriesByFile[filename], entry)
+		}
+	}
+	return filenames, entriesByFile
+}
+
 func constructFilterSets(includeObjectTypes []string, excludeObjectTypes []string, includeSchemas []string, excludeSchemas []string, includeRelations []string, excludeRelations []string) (*FilterSet, *FilterSet, *FilterSet) {
 	var objectSet, schemaSet, relationSet *FilterSet
 	if len(includeObjectTypes) > 0 {
@@ -267,6 +336,227 @@ func SubstituteRedirectDatabaseInStatements(statements []StatementWithType, oldQ
 	return statements
 }
 
+/*
+ * Rewrite the schema qualifier of every object in the given statements to
+ * point at newQuotedSchema.  Only qualifiers that appear at the start of an
+ * identifier are replaced, so that a schema name that happens to be a suffix
+ * of another identifier is left alone.
+ */
+func SubstituteRedirectSchemaInStatements(statements []StatementWithType, newQuotedSchema string) []StatementWithType {
+	escapedNewSchema := strings.Replace(newQuotedSchema, "$", "$$", -1)
+	for i := range statements {
+		oldQuotedSchema := statements[i].Schema
+		if oldQuotedSchema == "" || oldQuotedSchema == newQuotedSchema {
+			continue
+		}
+		pattern := regexp.MustCompile(fmt.Sprintf(`(^|[\s(,'])%s\.`, regexp.QuoteMeta(oldQuotedSchema)))
+		statements[i].Statement = pattern.ReplaceAllString(statements[i].Statement, fmt.Sprintf("${1}%s.", escapedNewSchema))
+		if strings.HasPrefix(statements[i].ReferenceObject, oldQuotedSchema+".") {
+			statements[i].ReferenceObject = newQuotedSchema + strings.TrimPrefix(statements[i].ReferenceObject, oldQuotedSchema)
+		}
+		statements[i].Schema = newQuotedSchema
+	}
+	return statements
+}
+
+var (
+	tablespaceClausePattern  = regexp.MustCompile(`\bTABLESPACE ([^\s;]+)`)
+	defaultTablespacePattern = regexp.MustCompile(`^(ALTER DATABASE \S+ SET default_tablespace TO ')((?:[^']|'')*)(';)$`)
+)
+
+/*
+ * Replaces the tablespaces named in the TABLESPACE clauses of database, table,
+ * index, and materialized view statements, and in a database's
+ * default_tablespace setting, according to tablespaceMap, which maps quoted
+ * old tablespace names to quoted new ones.
+ */
+func SubstituteTablespacesInStatements(statements []StatementWithType, tablespaceMap map[string]string) []StatementWithType {
+	if len(tablespaceMap) == 0 {
+		return statements
+	}
+	for i := range statements {
+		switch statements[i].ObjectType {
+		case "DATABASE", "TABLE", "INDEX", "MATERIALIZED VIEW":
+			statements[i].Statement = tablespaceClausePattern.ReplaceAllStringFunc(statements[i].Statement, func(clause string) string {
+				if newTablespace, ok := tablespaceMap[strings.TrimPrefix(clause, "TABLESPACE ")]; ok {
+					return "TABLESPACE " + newTablespace
+				}
+				return clause
+			})
+		case "DATABASE GUC":
+			statements[i].Statement = substituteDefaultTablespace(statements[i].Statement, tablespaceMap)
+		}
+	}
+	return statements
+}
+
+/*
+ * The setting holds the tablespace name itself rather than a quoted
+ * identifier, so the name is looked up both as it is and in double quotes.
+ */
+func substituteDefaultTablespace(statement string, tablespaceMap map[string]string) string {
+	matches := defaultTablespacePattern.FindStringSubmatch(strings.TrimSpace(statement))
+	if matches == nil {
+		return statement
+	}
+	name := strings.Replace(matches[2], "''", "'", -1)
+	newTablespace, ok := tablespaceMap[name]
+	if !ok {
+		newTablespace, ok = tablespaceMap[`"`+strings.Replace(name, `"`, `""`, -1)+`"`]
+	}
+	if !ok {
+		return statement
+	}
+	if strings.HasPrefix(newTablespace, `"`) && strings.HasSuffix(newTablespace, `"`) && len(newTablespace) > 1 {
+		newTablespace = strings.Replace(newTablespace[1:len(newTablespace)-1], `""`, `"`, -1)
+	}
+	return strings.Replace(statement, matches[0], matches[1]+EscapeSingleQuotes(newTablespace)+matches[3], 1)
+}
+
+var (
+	locationClausePattern = regexp.MustCompile(`(?s)\bLOCATION \(\n(.*?)\n\)`)
+	locationURIPattern    = regexp.MustCompile(`'([^']*)'`)
+)
+
+/*
+ * Replaces the LOCATION URIs of external tables according to locationMap, so
+ * that tables restored to another environment read from and write to that
+ * environment's hosts.
+ */
+func SubstituteLocationsInStatements(statements []StatementWithType, locationMap map[string]string) []StatementWithType {
+	if len(locationMap) == 0 {
+		return statements
+	}
+	for i := range statements {
+		if statements[i].ObjectType != "TABLE" {
+			continue
+		}
+		statements[i].Statement = locationClausePattern.ReplaceAllStringFunc(statements[i].Statement, func(clause string) string {
+			return locationURIPattern.ReplaceAllStringFunc(clause, func(quotedURI string) string {
+				return "'" + SubstituteLocation(quotedURI[1:len(quotedURI)-1], locationMap) + "'"
+			})
+		})
+	}
+	return statements
+}
+
+/*
+ * A mapping whose old location contains "://" replaces that prefix of a URI,
+ * with the longest matching prefix taking precedence.  Any other mapping
+ * replaces the host or the host and port of a URI, so that a gpfdist host or
+ * an S3 endpoint can be mapped without listing each of its URIs.
+ */
+func SubstituteLocation(uri string, locationMap map[string]string) string {
+	longestPrefix := ""
+	for oldLocation := range locationMap {
+		if strings.Contains(oldLocation, "://") && strings.HasPrefix(uri, oldLocation) && len(oldLocation) > len(longestPrefix) {
+			longestPrefix = oldLocation
+		}
+	}
+	if longestPrefix != "" {
+		return locationMap[longestPrefix] + uri[len(longestPrefix):]
+	}
+
+	schemeEnd := strings.Index(uri, "://")
+	if schemeEnd == -1 {
+		return uri
+	}
+	authorityStart := schemeEnd + len("://")
+	authorityEnd := len(uri)
+	if index := strings.IndexAny(uri[authorityStart:], "/?# "); index != -1 {
+		authorityEnd = authorityStart + index
+	}
+	authority := uri[authorityStart:authorityEnd]
+	if newAuthority, ok := locationMap[authority]; ok {
+		return uri[:authorityStart] + newAuthority + uri[authorityEnd:]
+	}
+	if portStart := strings.LastIndex(authority, ":"); portStart != -1 {
+		if newHost, ok := locationMap[authority[:portStart]]; ok {
+			return uri[:authorityStart] + newHost + authority[portStart:] + uri[authorityEnd:]
+		}
+	}
+	return uri
+}
+
+var (
+	ownerClausePattern       = regexp.MustCompile(`(?m)^(ALTER .+ OWNER TO )([^;]+)(;)$`)
+	defaultPrivsOwnerPattern = regexp.MustCompile(`(?m)^(ALTER DEFAULT PRIVILEGES FOR ROLE )(\S+)( .+)$`)
+	granteeClausePattern     = regexp.MustCompile(`(?m)^((?:ALTER DEFAULT PRIVILEGES .+ )?(?:GRANT .+ TO|REVOKE .+ FROM) )([^;]+?)((?: WITH GRANT OPTION)?;)$`)
+)
+
+/*
+ * Replaces the roles named in OWNER TO clauses and in GRANT and REVOKE
+ * statements according to roleMap, which maps quoted old role names to quoted
+ * new ones.
+ */
+func SubstituteRolesInStatements(statements []StatementWithType, roleMap map[string]string) []StatementWithType {
+	if len(roleMap) == 0 {
+		return statements
+	}
+	for i := range statements {
+		for _, pattern := range []*regexp.Regexp{ownerClausePattern, defaultPrivsOwnerPattern, granteeClausePattern} {
+			statements[i].Statement = substituteRolesInClauses(pattern, statements[i].Statement, roleMap)
+		}
+	}
+	return statements
+}
+
+/*
+ * The pattern must have three groups: the text before the list of roles, the
+ * comma-separated list of roles, and the text after it.
+ */
+func substituteRolesInClauses(pattern *regexp.Regexp, statement string, roleMap map[string]string) string {
+	return pattern.ReplaceAllStringFunc(statement, func(clause string) string {
+		groups := pattern.FindStringSubmatch(clause)
+		roles := strings.Split(groups[2], ", ")
+		for i, role := range roles {
+			if newRole, ok := roleMap[role]; ok {
+				roles[i] = newRole
+			}
+		}
+		return groups[1] + strings.Join(roles, ", ") + groups[3]
+	})
+}
+
+/*
+ * Removes ALTER ... OWNER TO statements, so that restored objects are owned by
+ * the role performing the restore.
+ */
+func RemoveOwnerStatements(statements []StatementWithType) []StatementWithType {
+	return removeMatchingLines(statements, ownerClausePattern)
+}
+
+/*
+ * Removes GRANT and REVOKE statements, including ALTER DEFAULT PRIVILEGES
+ * statements, so that restored objects keep their default privileges.
+ */
+func RemovePrivilegeStatements(statements []StatementWithType) []StatementWithType {
+	return removeMatchingLines(statements, granteeClausePattern)
+}
+
+/*
+ * Several statements may share one TOC entry, one per line, so matching lines
+ * are removed individually and entries left with no statements are dropped.
+ */
+func removeMatchingLines(statements []StatementWithType, pattern *regexp.Regexp) []StatementWithType {
+	newStatements := make([]StatementWithType, 0)
+	for _, statement := range statements {
+		lines := strings.Split(statement.Statement, "\n")
+		keptLines := make([]string, 0, len(lines))
+		for _, line := range lines {
+			if !pattern.MatchString(line) {
+				keptLines = append(keptLines, line)
+			}
+		}
+		statement.Statement = strings.Join(keptLines, "\n")
+		if strings.TrimSpace(statement.Statement) == "" {
+			continue
+		}
+		newStatements = append(newStatements, statement)
+	}
+	return newStatements
+}
+
 func RemoveActiveRole(activeUser string, statements []StatementWithType) []StatementWithType {
 	newStatements := make([]StatementWithType, 0)
 	for _, statement := range statements {
@@ -278,12 +568,31 @@ func RemoveActiveRole(activeUser string, statements []StatementWithType) []State
 	return newStatements
 }
 
+/*
+ * Keeps only those statements of the given object types whose names are in
+ * includeNames, so that a subset of roles, resource queues, or tablespaces can
+ * be restored from the global metadata.  Statements of other types are kept.
+ */
+func FilterGlobalStatements(statements []StatementWithType, objectTypes []string, includeNames []string) []StatementWithType {
+	typeSet := NewSet(objectTypes)
+	nameSet := NewIncludeSet(includeNames)
+	newStatements := make([]StatementWithType, 0)
+	for _, statement := range statements {
+		if typeSet.MatchesFilter(statement.ObjectType) && !nameSet.MatchesFilter(statement.Name) {
+			continue
+		}
+		newStatements = append(newStatements, statement)
+	}
+	return newStatements
+}
+
 func (toc *TOC) InitializeMetadataEntryMap() {
-	toc.metadataEntryMap = make(map[string]*[]MetadataEntry, 4)
+	toc.metadataEntryMap = make(map[string]*[]MetadataEntry, 5)
 	toc.metadataEntryMap["global"] = &toc.GlobalEntries
 	toc.metadataEntryMap["predata"] = &toc.PredataEntries
 	toc.metadataEntryMap["postdata"] = &toc.PostdataEntries
 	toc.metadataEntryMap["statistics"] = &toc.StatisticsEntries
+	toc.metadataEntryMap["largeobjects"] = &toc.LargeObjectEntries
 }
 
 type TOCObject interface {
@@ -302,10 +611,69 @@ func (toc *TOC) AddMetadataEntry(section string, entry MetadataEntry, start, end
 }
 
 func (toc *TOC) AddMasterDataEntry(schema string, name string, oid uint32, attributeString string, rowsCopied int64, PartitionRoot string) {
-	toc.DataEntries = append(toc.DataEntries, MasterDataEntry{schema, name, oid, attributeString, rowsCopied, PartitionRoot})
+	toc.DataEntries = append(toc.DataEntries, MasterDataEntry{schema, name, oid, attributeString, rowsCopied, PartitionRoot, false})
 }
 
-func (toc *SegmentTOC) AddSegmentDataEntry(oid uint, startByte uint64, endByte uint64) {
+/*
+ * The data of an external table backed up with --with-external-data is
+ * written by whichever segment read each row rather than the segment the row
+ * belongs to, so its entry is marked for restore to redistribute the rows.
+ */
+func (toc *TOC) AddExternalMasterDataEntry(schema string, name string, oid uint32, attributeString string, rowsCopied int64, PartitionRoot string) {
+	toc.DataEntries = append(toc.DataEntries, MasterDataEntry{schema, name, oid, attributeString, rowsCopied, PartitionRoot, true})
+}
+
+func (toc *SegmentTOC) AddSegmentDataEntry(oid uint, startByte uint64, endByte uint64, compressionLevel int) {
 	// We use uint for oid since the flags package does not have a uint32 flag
-	toc.DataEntries[oid] = SegmentDataEntry{startByte, endByte}
+	toc.DataEntries[oid] = SegmentDataEntry{StartByte: startByte, EndByte: endByte, CompressionLevel: compressionLevel}
+}
+
+/*
+ * A StatementMiddleware is passed each metadata statement, along with the TOC
+ * entry that describes it, before the statement is written to the backup, and
+ * returns the statement to write in its place.  Returning an empty string
+ * drops the statement from the backup.
+ *
+ * Middleware is applied in the order in which it is given.
+ */
+type StatementMiddleware func(entry MetadataEntry, statement string) string
+
+func ApplyStatementMiddleware(middleware []StatementMiddleware, entry MetadataEntry, statement string) string {
+	for _, rewrite := range middleware {
+		statement = rewrite(entry, statement)
+	}
+	return statement
+}
+
+/*
+ * Pass every statement in the given sections through the given statement
+ * middleware.  The entries' byte offsets must refer to contents; the rewritten
+ * statements are written to metadataFile and the offsets are updated to refer
+ * to it instead.  Any bytes not covered by an entry are copied unchanged.
+ */
+func (toc *TOC) RewriteStatementsWithMiddleware(middleware []StatementMiddleware, contents []byte, metadataFile *FileWithByteCount, sections ...string) {
+	entries := make([]*MetadataEntry, 0)
+	for _, section := range sections {
+		sectionEntries := *toc.metadataEntryMap[section]
+		for i := range sectionEntries {
+			entries = append(entries, &sectionEntries[i])
+		}
+	}
+	sort.SliceStable(entries, func(i int, j int) bool {
+		return entries[i].StartByte < entries[j].StartByte
+	})
+
+	position := uint64(0)
+	for _, entry := range entries {
+		if entry.StartByte < position {
+			gplog.Fatal(errors.Errorf("Metadata entry for %s %s overlaps the previous entry", entry.ObjectType, entry.Name), "")
+		}
+		metadataFile.MustPrint(string(contents[position:entry.StartByte]))
+		statement := ApplyStatementMiddleware(middleware, *entry, string(contents[entry.StartByte:entry.EndByte]))
+		position = entry.EndByte
+		entry.StartByte = metadataFile.ByteCount
+		metadataFile.MustPrint(statement)
+		entry.EndByte = metadataFile.ByteCount
+	}
+	metadataFile.MustPrint(string(contents[position:]))
 }
@@ -2,6 +2,7 @@ package utils_test
 
 import (
 	"bytes"
+	"strings"
 
 	"github.com/greenplum-db/gpbackup/testutils"
 	"github.com/greenplum-db/gpbackup/utils"
@@ -355,12 +356,199 @@ COMMENT ON DATABASE "db-special-chär$" IS 'this is a database comment';`}
 			statements := utils.SubstituteRedirectDatabaseInStatements([]utils.StatementWithType{oldSpecial}, `"db-special-chär$"`, "newdatabase")
 			Expect(statements[0].Statement).To(Equal("CREATE DATABASE newdatabase TEMPLATE template0 TABLESPACE test_tablespace;\n\nCOMMENT ON DATABASE newdatabase IS 'this is a database comment';"))
 		})
+		It("can substitute a quoted database name in a database GUC statement", func() {
+			quotedGucs := utils.StatementWithType{ObjectType: "DATABASE GUC", Statement: `ALTER DATABASE "db-special-chär$" SET fsync TO off;` + "\n"}
+			statements := utils.SubstituteRedirectDatabaseInStatements([]utils.StatementWithType{quotedGucs}, `"db-special-chär$"`, "newdatabase")
+			Expect(statements[0].Statement).To(Equal("ALTER DATABASE newdatabase SET fsync TO off;\n"))
+		})
 		It("can substitute a database name if the new name contained special characters", func() {
 			statements := utils.SubstituteRedirectDatabaseInStatements([]utils.StatementWithType{create}, "somedatabase", `"db-special-chär$"`)
 			Expect(statements[0].Statement).To(Equal(`CREATE DATABASE "db-special-chär$" TEMPLATE template0;
 `))
 		})
 	})
+	Describe("SubstituteRedirectSchemaInStatements", func() {
+		table := utils.StatementWithType{Schema: "schema1", Name: "table1", ObjectType: "TABLE", Statement: "\n\nCREATE TABLE schema1.table1 (\n\ti integer\n) DISTRIBUTED BY (i);\n\nALTER TABLE schema1.table1 OWNER TO testrole;\n"}
+		index := utils.StatementWithType{Schema: "schema1", Name: "idx1", ObjectType: "INDEX", ReferenceObject: "schema1.table1", Statement: "\n\nCREATE INDEX idx1 ON schema1.table1 USING btree (i);\n"}
+		suffixMatch := utils.StatementWithType{Schema: "schema1", Name: "view1", ObjectType: "VIEW", Statement: "\n\nCREATE VIEW schema1.view1 AS SELECT * FROM otherschema1.table1;\n"}
+		quoted := utils.StatementWithType{Schema: `"Schema$1"`, Name: "seq1", ObjectType: "SEQUENCE", Statement: `CREATE SEQUENCE "Schema$1".seq1;`}
+		It("substitutes the schema of a relation", func() {
+			statements := utils.SubstituteRedirectSchemaInStatements([]utils.StatementWithType{table}, "newschema")
+			Expect(statements[0].Schema).To(Equal("newschema"))
+			Expect(statements[0].Statement).To(Equal("\n\nCREATE TABLE newschema.table1 (\n\ti integer\n) DISTRIBUTED BY (i);\n\nALTER TABLE newschema.table1 OWNER TO testrole;\n"))
+		})
+		It("substitutes the schema of the reference object", func() {
+			statements := utils.SubstituteRedirectSchemaInStatements([]utils.StatementWithType{index}, "newschema")
+			Expect(statements[0].ReferenceObject).To(Equal("newschema.table1"))
+			Expect(statements[0].Statement).To(Equal("\n\nCREATE INDEX idx1 ON newschema.table1 USING btree (i);\n"))
+		})
+		It("does not substitute a schema name that is a suffix of another identifier", func() {
+			statements := utils.SubstituteRedirectSchemaInStatements([]utils.StatementWithType{suffixMatch}, "newschema")
+			Expect(statements[0].Statement).To(Equal("\n\nCREATE VIEW newschema.view1 AS SELECT * FROM otherschema1.table1;\n"))
+		})
+		It("substitutes schema names containing special characters", func() {
+			statements := utils.SubstituteRedirectSchemaInStatements([]utils.StatementWithType{quoted}, `"New$Schema"`)
+			Expect(statements[0].Statement).To(Equal(`CREATE SEQUENCE "New$Schema".seq1;`))
+		})
+	})
+	Describe("SubstituteTablespacesInStatements", func() {
+		tablespaceMap := map[string]string{"ts1": `"New_TS"`}
+		It("replaces mapped tablespaces in table, index, and materialized view statements", func() {
+			statements := []utils.StatementWithType{
+				{ObjectType: "TABLE", Statement: "CREATE TABLE public.foo (i int) TABLESPACE ts1 DISTRIBUTED BY (i);"},
+				{ObjectType: "INDEX", Statement: "CREATE INDEX foo_idx ON public.foo USING btree (i);\nALTER INDEX public.foo_idx SET TABLESPACE ts1;"},
+				{ObjectType: "MATERIALIZED VIEW", Statement: "CREATE MATERIALIZED VIEW public.mv TABLESPACE ts1 AS SELECT 1;"},
+			}
+
+			statements = utils.SubstituteTablespacesInStatements(statements, tablespaceMap)
+
+			Expect(statements[0].Statement).To(Equal(`CREATE TABLE public.foo (i int) TABLESPACE "New_TS" DISTRIBUTED BY (i);`))
+			Expect(statements[1].Statement).To(Equal("CREATE INDEX foo_idx ON public.foo USING btree (i);\nALTER INDEX public.foo_idx SET TABLESPACE \"New_TS\";"))
+			Expect(statements[2].Statement).To(Equal(`CREATE MATERIALIZED VIEW public.mv TABLESPACE "New_TS" AS SELECT 1;`))
+		})
+		It("replaces mapped tablespaces in database statements and default_tablespace settings", func() {
+			statements := []utils.StatementWithType{
+				{ObjectType: "DATABASE", Statement: "\n\nCREATE DATABASE testdb TEMPLATE template0 TABLESPACE ts1;"},
+				{ObjectType: "DATABASE GUC", Statement: "\nALTER DATABASE testdb SET default_tablespace TO 'ts1';"},
+				{ObjectType: "DATABASE GUC", Statement: "\nALTER DATABASE testdb SET default_tablespace TO 'ts10';"},
+				{ObjectType: "DATABASE GUC", Statement: "\nALTER DATABASE testdb SET search_path TO ts1;"},
+			}
+
+			statements = utils.SubstituteTablespacesInStatements(statements, tablespaceMap)
+
+			Expect(statements[0].Statement).To(Equal("\n\nCREATE DATABASE testdb TEMPLATE template0 TABLESPACE \"New_TS\";"))
+			Expect(statements[1].Statement).To(Equal("\nALTER DATABASE testdb SET default_tablespace TO 'New_TS';"))
+			Expect(statements[2].Statement).To(Equal("\nALTER DATABASE testdb SET default_tablespace TO 'ts10';"))
+			Expect(statements[3].Statement).To(Equal("\nALTER DATABASE testdb SET search_path TO ts1;"))
+		})
+		It("matches default_tablespace settings against quoted tablespace names", func() {
+			statements := []utils.StatementWithType{{ObjectType: "DATABASE GUC", Statement: "\nALTER DATABASE testdb SET default_tablespace TO 'Old TS';"}}
+
+			statements = utils.SubstituteTablespacesInStatements(statements, map[string]string{`"Old TS"`: "ts2"})
+
+			Expect(statements[0].Statement).To(Equal("\nALTER DATABASE testdb SET default_tablespace TO 'ts2';"))
+		})
+		It("does not replace unmapped tablespaces or tablespaces in other statements", func() {
+			statements := []utils.StatementWithType{
+				{ObjectType: "TABLE", Statement: "CREATE TABLE public.foo (i int) TABLESPACE ts10 DISTRIBUTED BY (i);"},
+				{ObjectType: "FUNCTION", Statement: "CREATE FUNCTION public.f() RETURNS text AS $$SELECT 'TABLESPACE ts1'$$ LANGUAGE sql;"},
+			}
+
+			statements = utils.SubstituteTablespacesInStatements(statements, tablespaceMap)
+
+			Expect(statements[0].Statement).To(Equal("CREATE TABLE public.foo (i int) TABLESPACE ts10 DISTRIBUTED BY (i);"))
+			Expect(statements[1].Statement).To(Equal("CREATE FUNCTION public.f() RETURNS text AS $$SELECT 'TABLESPACE ts1'$$ LANGUAGE sql;"))
+		})
+	})
+	Describe("SubstituteRolesInStatements", func() {
+		roleMap := map[string]string{"olduser": `"NewUser"`, "group1": "group2"}
+		It("replaces mapped roles in owner, grant, and revoke statements", func() {
+			statements := []utils.StatementWithType{{ObjectType: "TABLE", Statement: `CREATE TABLE public.foo (i int);
+
+ALTER TABLE public.foo OWNER TO olduser;
+
+REVOKE ALL ON TABLE public.foo FROM PUBLIC;
+REVOKE ALL ON TABLE public.foo FROM olduser;
+GRANT ALL ON TABLE public.foo TO olduser;
+GRANT SELECT ON TABLE public.foo TO group1, otheruser WITH GRANT OPTION;`}}
+
+			statements = utils.SubstituteRolesInStatements(statements, roleMap)
+
+			Expect(statements[0].Statement).To(Equal(`CREATE TABLE public.foo (i int);
+
+ALTER TABLE public.foo OWNER TO "NewUser";
+
+REVOKE ALL ON TABLE public.foo FROM PUBLIC;
+REVOKE ALL ON TABLE public.foo FROM "NewUser";
+GRANT ALL ON TABLE public.foo TO "NewUser";
+GRANT SELECT ON TABLE public.foo TO group2, otheruser WITH GRANT OPTION;`))
+		})
+		It("replaces mapped roles in default privileges statements", func() {
+			statements := []utils.StatementWithType{{ObjectType: "DEFAULT PRIVILEGES", Statement: `ALTER DEFAULT PRIVILEGES FOR ROLE olduser IN SCHEMA schema1 REVOKE ALL ON TABLES FROM olduser;
+ALTER DEFAULT PRIVILEGES FOR ROLE olduser GRANT SELECT ON TABLES TO group1;`}}
+
+			statements = utils.SubstituteRolesInStatements(statements, roleMap)
+
+			Expect(statements[0].Statement).To(Equal(`ALTER DEFAULT PRIVILEGES FOR ROLE "NewUser" IN SCHEMA schema1 REVOKE ALL ON TABLES FROM "NewUser";
+ALTER DEFAULT PRIVILEGES FOR ROLE "NewUser" GRANT SELECT ON TABLES TO group2;`))
+		})
+		It("does not replace roles that are not mapped", func() {
+			statements := []utils.StatementWithType{{ObjectType: "SCHEMA", Statement: "CREATE SCHEMA schema1;\n\nALTER SCHEMA schema1 OWNER TO olduser2;"}}
+
+			statements = utils.SubstituteRolesInStatements(statements, roleMap)
+
+			Expect(statements[0].Statement).To(Equal("CREATE SCHEMA schema1;\n\nALTER SCHEMA schema1 OWNER TO olduser2;"))
+		})
+	})
+	Describe("SubstituteLocationsInStatements", func() {
+		externalTable := "\n\nCREATE READABLE EXTERNAL TABLE public.ext (\n\ti integer\n) LOCATION (\n\t'gpfdist://etl1:8080/data/a.txt',\n\t'gpfdist://etl2/data/b.txt'\n) FORMAT 'TEXT' (delimiter '|');"
+		It("replaces the hosts of external table locations", func() {
+			statements := []utils.StatementWithType{{ObjectType: "TABLE", Statement: externalTable}}
+
+			statements = utils.SubstituteLocationsInStatements(statements, map[string]string{"etl1": "newetl1", "etl2": "newetl2:9000"})
+
+			Expect(statements[0].Statement).To(Equal("\n\nCREATE READABLE EXTERNAL TABLE public.ext (\n\ti integer\n) LOCATION (\n\t'gpfdist://newetl1:8080/data/a.txt',\n\t'gpfdist://newetl2:9000/data/b.txt'\n) FORMAT 'TEXT' (delimiter '|');"))
+		})
+		It("replaces the longest matching location prefix", func() {
+			statements := []utils.StatementWithType{{ObjectType: "TABLE", Statement: externalTable}}
+
+			statements = utils.SubstituteLocationsInStatements(statements, map[string]string{
+				"gpfdist://etl1:8080/":      "gpfdist://other:8080/",
+				"gpfdist://etl1:8080/data/": "gpfdist://etl3:8081/new/",
+				"etl1:8080":                 "unused",
+			})
+
+			Expect(statements[0].Statement).To(ContainSubstring("'gpfdist://etl3:8081/new/a.txt'"))
+			Expect(statements[0].Statement).To(ContainSubstring("'gpfdist://etl2/data/b.txt'"))
+		})
+		It("replaces S3 endpoints without changing their options", func() {
+			statements := []utils.StatementWithType{{ObjectType: "TABLE", Statement: "\n\nCREATE WRITABLE EXTERNAL TABLE public.ext (\n\ti integer\n) LOCATION (\n\t's3://s3-us-west-2.amazonaws.com/bucket/prefix config=/home/gpadmin/s3.conf'\n) FORMAT 'CSV';"}}
+
+			statements = utils.SubstituteLocationsInStatements(statements, map[string]string{"s3-us-west-2.amazonaws.com": "s3.example.com"})
+
+			Expect(statements[0].Statement).To(ContainSubstring("'s3://s3.example.com/bucket/prefix config=/home/gpadmin/s3.conf'"))
+		})
+		It("does not change other statements", func() {
+			statements := []utils.StatementWithType{
+				{ObjectType: "TABLE", Statement: "\n\nCREATE TABLE public.foo (\n\tt text DEFAULT 'gpfdist://etl1/a'\n) DISTRIBUTED RANDOMLY;"},
+				{ObjectType: "COMMENT", Statement: "\n\nCOMMENT ON TABLE public.ext IS 'gpfdist://etl1/a';"},
+			}
+
+			result := utils.SubstituteLocationsInStatements(statements, map[string]string{"etl1": "newetl1"})
+
+			Expect(result[0].Statement).To(ContainSubstring("'gpfdist://etl1/a'"))
+			Expect(result[1].Statement).To(ContainSubstring("'gpfdist://etl1/a'"))
+		})
+	})
+	Describe("RemoveOwnerStatements", func() {
+		It("removes owner statements and keeps the rest of the entry", func() {
+			language := utils.StatementWithType{ObjectType: "LANGUAGE", Statement: "\n\nCREATE PROCEDURAL LANGUAGE plpythonu;\nALTER FUNCTION pg_catalog.plpython_call_handler() OWNER TO testrole;\n"}
+
+			statements := utils.RemoveOwnerStatements([]utils.StatementWithType{language})
+
+			Expect(statements).To(HaveLen(1))
+			Expect(statements[0].Statement).To(Equal("\n\nCREATE PROCEDURAL LANGUAGE plpythonu;\n"))
+		})
+		It("removes entries that only contain owner statements", func() {
+			owner := utils.StatementWithType{ObjectType: "TABLE", Statement: "\n\nALTER TABLE public.foo OWNER TO testrole;\n"}
+			comment := utils.StatementWithType{ObjectType: "TABLE", Statement: "\n\nCOMMENT ON TABLE public.foo IS 'This is a comment.';\n"}
+
+			statements := utils.RemoveOwnerStatements([]utils.StatementWithType{owner, comment})
+
+			Expect(statements).To(Equal([]utils.StatementWithType{comment}))
+		})
+	})
+	Describe("RemovePrivilegeStatements", func() {
+		It("removes grant, revoke, and default privileges statements", func() {
+			privileges := utils.StatementWithType{ObjectType: "TABLE", Statement: "\n\nREVOKE ALL ON TABLE public.foo FROM PUBLIC;\nGRANT SELECT ON TABLE public.foo TO testrole WITH GRANT OPTION;\n"}
+			defaultPrivileges := utils.StatementWithType{ObjectType: "DEFAULT PRIVILEGES", Statement: "\n\nALTER DEFAULT PRIVILEGES FOR ROLE testrole REVOKE ALL ON TABLES FROM PUBLIC;\n"}
+			owner := utils.StatementWithType{ObjectType: "TABLE", Statement: "\n\nALTER TABLE public.foo OWNER TO testrole;\n"}
+
+			statements := utils.RemovePrivilegeStatements([]utils.StatementWithType{privileges, defaultPrivileges, owner})
+
+			Expect(statements).To(Equal([]utils.StatementWithType{owner}))
+		})
+	})
 	Describe("RemoveActiveRoles", func() {
 		user1 := utils.StatementWithType{Name: "user1", ObjectType: "ROLE", Statement: "CREATE ROLE user1 SUPERUSER;\n"}
 		user2 := utils.StatementWithType{Name: "user2", ObjectType: "ROLE", Statement: "CREATE ROLE user2;\n"}
@@ -375,6 +563,93 @@ COMMENT ON DATABASE "db-special-chär$" IS 'this is a database comment';`}
 			Expect(resultStatements).To(Equal([]utils.StatementWithType{user1, user2}))
 		})
 	})
+	Describe("FilterGlobalStatements", func() {
+		user1 := utils.StatementWithType{Name: "user1", ObjectType: "ROLE", Statement: "CREATE ROLE user1;\n"}
+		user1Gucs := utils.StatementWithType{Name: "user1", ObjectType: "ROLE GUCS", Statement: "ALTER ROLE user1 SET search_path TO public;\n"}
+		user2 := utils.StatementWithType{Name: "user2", ObjectType: "ROLE", Statement: "CREATE ROLE user2;\n"}
+		user2Grant := utils.StatementWithType{Name: "user2", ObjectType: "ROLE GRANT", Statement: "GRANT user1 TO user2;\n"}
+		tablespace := utils.StatementWithType{Name: "ts1", ObjectType: "TABLESPACE", Statement: "CREATE TABLESPACE ts1 LOCATION '/tmp';\n"}
+		statements := []utils.StatementWithType{user1, user1Gucs, user2, user2Grant, tablespace}
+		It("keeps only the statements for the included names of the given object types", func() {
+			resultStatements := utils.FilterGlobalStatements(statements, []string{"ROLE", "ROLE GUCS", "ROLE GRANT"}, []string{"user1"})
+
+			Expect(resultStatements).To(Equal([]utils.StatementWithType{user1, user1Gucs, tablespace}))
+		})
+		It("removes all statements of the given object types if no names match", func() {
+			resultStatements := utils.FilterGlobalStatements(statements, []string{"TABLESPACE"}, []string{"ts2"})
+
+			Expect(resultStatements).To(Equal([]utils.StatementWithType{user1, user1Gucs, user2, user2Grant}))
+		})
+	})
+	Describe("GetSplitMetadataEntries", func() {
+		BeforeEach(func() {
+			toc.AddMetadataEntry("global", utils.MetadataEntry{Name: "", ObjectType: "SESSION GUCS"}, 0, 1)
+			toc.AddMetadataEntry("global", utils.MetadataEntry{Name: "role1", ObjectType: "ROLE"}, 1, 2)
+			toc.AddMetadataEntry("predata", utils.MetadataEntry{Schema: "schema", Name: "schema", ObjectType: "SCHEMA"}, 2, 3)
+			toc.AddMetadataEntry("predata", utils.MetadataEntry{Schema: "schema", Name: "table1", ObjectType: "TABLE"}, 3, 4)
+			toc.AddMetadataEntry("predata", utils.MetadataEntry{Schema: `"Schema 2"`, Name: "table2", ObjectType: "TABLE"}, 4, 5)
+			toc.AddMetadataEntry("postdata", utils.MetadataEntry{Schema: "schema", Name: "someindex", ObjectType: "INDEX"}, 5, 6)
+		})
+		It("groups entries by object type in TOC order", func() {
+			filenames, entriesByFile := toc.GetSplitMetadataEntries(utils.SPLIT_BY_OBJECT_TYPE)
+
+			Expect(filenames).To(Equal([]string{"role.sql", "schema.sql", "table.sql", "index.sql"}))
+			Expect(entriesByFile["table.sql"]).To(HaveLen(2))
+			Expect(entriesByFile["table.sql"][0].Name).To(Equal("table1"))
+			Expect(entriesByFile["table.sql"][1].Name).To(Equal("table2"))
+		})
+		It("groups entries by schema, grouping objects outside a schema by section", func() {
+			filenames, entriesByFile := toc.GetSplitMetadataEntries(utils.SPLIT_BY_SCHEMA)
+
+			Expect(filenames).To(Equal([]string{"global.sql", "schema_schema.sql", "schema_Schema_2.sql"}))
+			Expect(entriesByFile["global.sql"]).To(HaveLen(1))
+			Expect(entriesByFile["schema_schema.sql"]).To(HaveLen(3))
+			Expect(entriesByFile["schema_schema.sql"][2].Name).To(Equal("someindex"))
+			Expect(entriesByFile["schema_Schema_2.sql"]).To(HaveLen(1))
+		})
+	})
+	Describe("RewriteStatementsWithMiddleware", func() {
+		It("rewrites each statement and updates the entry offsets", func() {
+			contents := "SET search_path=pg_catalog;\nCREATE SCHEMA schema;\nCREATE TABLE schema.table1 (i int);\nCREATE INDEX someindex ON schema.table1(i);\n"
+			toc.AddMetadataEntry("predata", utils.MetadataEntry{Schema: "schema", Name: "schema", ObjectType: "SCHEMA"}, 28, 50)
+			toc.AddMetadataEntry("predata", utils.MetadataEntry{Schema: "schema", Name: "table1", ObjectType: "TABLE"}, 50, 86)
+			toc.AddMetadataEntry("postdata", utils.MetadataEntry{Schema: "schema", Name: "someindex", ObjectType: "INDEX"}, 86, 130)
+			middleware := []utils.StatementMiddleware{
+				func(entry utils.MetadataEntry, statement string) string {
+					if entry.ObjectType == "SCHEMA" {
+						return ""
+					}
+					return statement
+				},
+				func(entry utils.MetadataEntry, statement string) string {
+					return strings.Replace(statement, "schema.", "newschema.", -1)
+				},
+			}
+			outputBuffer := bytes.NewBuffer([]byte(""))
+			outputFile := utils.NewFileWithByteCount(outputBuffer)
+
+			toc.RewriteStatementsWithMiddleware(middleware, []byte(contents), outputFile, "predata", "postdata")
+
+			Expect(outputBuffer.String()).To(Equal("SET search_path=pg_catalog;\nCREATE TABLE newschema.table1 (i int);\nCREATE INDEX someindex ON newschema.table1(i);\n"))
+			Expect(toc.PredataEntries[0].StartByte).To(Equal(uint64(28)))
+			Expect(toc.PredataEntries[0].EndByte).To(Equal(uint64(28)))
+			Expect(toc.PredataEntries[1].StartByte).To(Equal(uint64(28)))
+			Expect(toc.PredataEntries[1].EndByte).To(Equal(uint64(67)))
+			Expect(toc.PostdataEntries[0].StartByte).To(Equal(uint64(67)))
+			Expect(toc.PostdataEntries[0].EndByte).To(Equal(uint64(114)))
+		})
+		It("copies the contents unchanged when there is no middleware", func() {
+			contents := "CREATE SCHEMA schema;\n"
+			toc.AddMetadataEntry("predata", utils.MetadataEntry{Schema: "schema", Name: "schema", ObjectType: "SCHEMA"}, 0, 22)
+			outputBuffer := bytes.NewBuffer([]byte(""))
+			outputFile := utils.NewFileWithByteCount(outputBuffer)
+
+			toc.RewriteStatementsWithMiddleware(nil, []byte(contents), outputFile, "predata")
+
+			Expect(outputBuffer.String()).To(Equal(contents))
+			Expect(toc.PredataEntries[0].EndByte).To(Equal(uint64(22)))
+		})
+	})
 	Describe("GetIncludedPartitionRoots", func() {
 		It("does not return anything if relations are not leaf partitions", func() {
 			toc.AddMasterDataEntry("schema0", "name0", 0, "attribute0", 1, "")
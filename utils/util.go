@@ -8,15 +8,19 @@ package utils
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"os/signal"
 	"regexp"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/blang/semver"
 	"github.com/greenplum-db/gp-common-go-libs/dbconn"
 	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gp-common-go-libs/operating"
 	"github.com/greenplum-db/gpbackup/backup_filepath"
+	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 )
 
@@ -58,6 +62,47 @@ func ValidateFQNs(fqns []string) {
 	}
 }
 
+/*
+ * Parses --data-filter-file lines of the form schema.table:condition into a
+ * map from the table's FQN to the condition.  A condition may itself contain
+ * colons, so only the first colon separates it from the table.
+ */
+func ParseDataFilters(lines []string) map[string]string {
+	filters := make(map[string]string, len(lines))
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[1]) == "" {
+			gplog.Fatal(errors.Errorf("Invalid data filter %s.  Data filters must be in the format schema.table:condition.", line), "")
+		}
+		tableFQN := strings.TrimSpace(parts[0])
+		ValidateFQNs([]string{tableFQN})
+		filters[tableFQN] = strings.TrimSpace(parts[1])
+	}
+	return filters
+}
+
+/*
+ * Catalogs with hundreds of thousands of objects repeat the same schema and
+ * role names on every row, and each string scanned from a row is a separate
+ * copy.  Interning them keeps one copy of each distinct string instead.
+ */
+type StringInterner map[string]string
+
+func NewStringInterner() StringInterner {
+	return make(StringInterner)
+}
+
+func (interner StringInterner) Intern(str string) string {
+	if interned, ok := interner[str]; ok {
+		return interned
+	}
+	interner[str] = str
+	return str
+}
+
 func ValidateFullPath(path string) error {
 	if len(path) > 0 && !(strings.HasPrefix(path, "/") || strings.HasPrefix(path, "~")) {
 		return errors.Errorf("%s is not an absolute path.", path)
@@ -65,6 +110,153 @@ func ValidateFullPath(path string) error {
 	return nil
 }
 
+type ConnectionOption struct {
+	Name  string
+	Value string
+}
+
+/*
+ * Parses options in the format of the PGOPTIONS environment variable, such as
+ * "-c optimizer=off --statement_mem=256MB", into GUC names and values.  As in
+ * PGOPTIONS, a backslash escapes the following character, so that values may
+ * contain spaces.
+ */
+func ParseConnectionOptions(optionStr string) ([]ConnectionOption, error) {
+	words := make([]string, 0)
+	var word strings.Builder
+	inWord, escaped := false, false
+	for _, char := range optionStr {
+		switch {
+		case escaped:
+			word.WriteRune(char)
+			escaped = false
+		case char == '\\':
+			inWord, escaped = true, true
+		case char == ' ' || char == '\t' || char == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(char)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	validName := regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+	options := make([]ConnectionOption, 0)
+	for i := 0; i < len(words); i++ {
+		setting := ""
+		switch {
+		case words[i] == "-c" && i+1 < len(words):
+			i++
+			setting = words[i]
+		case strings.HasPrefix(words[i], "-c"):
+			setting = strings.TrimPrefix(words[i], "-c")
+		case strings.HasPrefix(words[i], "--"):
+			setting = strings.TrimPrefix(words[i], "--")
+		default:
+			return nil, errors.Errorf("Invalid connection option %s.  Options must be in the format -c name=value or --name=value.", words[i])
+		}
+		equalsIndex := strings.Index(setting, "=")
+		if equalsIndex <= 0 || !validName.MatchString(setting[:equalsIndex]) {
+			return nil, errors.Errorf("Invalid connection option %s.  Options must be in the format -c name=value or --name=value.", setting)
+		}
+		name := strings.Replace(setting[:equalsIndex], "-", "_", -1)
+		if name == "gp_role" || name == "gp_session_role" {
+			return nil, errors.Errorf("Cannot set %s for backup connections, as they must be dispatched from the master.", name)
+		}
+		options = append(options, ConnectionOption{Name: name, Value: setting[equalsIndex+1:]})
+	}
+	return options, nil
+}
+
+/*
+ * Parses --backup-guc values of the form name=value.  Unlike connection
+ * options, each value is taken as given, so it needs no escaping.  Values are
+ * read as they would be in postgresql.conf, so a list parameter such as
+ * search_path takes a comma-separated list, as in search_path=public,"my schema".
+ */
+func ParseBackupGUCs(settings []string) ([]ConnectionOption, error) {
+	gucRegex := regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_.]*)=(.*)$`)
+	gucs := make([]ConnectionOption, 0, len(settings))
+	for _, setting := range settings {
+		match := gucRegex.FindStringSubmatch(setting)
+		if match == nil {
+			return nil, errors.Errorf("Invalid backup GUC %s.  GUCs must be in the format name=value.", setting)
+		}
+		name := strings.ToLower(match[1])
+		if name == "gp_role" || name == "gp_session_role" {
+			return nil, errors.Errorf("Cannot set %s for backup connections, as they must be dispatched from the master.", name)
+		}
+		gucs = append(gucs, ConnectionOption{Name: name, Value: match[2]})
+	}
+	return gucs, nil
+}
+
+/*
+ * The option is set with set_config rather than SET ... TO '<value>', as SET
+ * would take the quoted value of a list parameter as a single element.
+ */
+func ConnectionOptionStatement(option ConnectionOption) string {
+	return fmt.Sprintf("SELECT set_config('%s', '%s', false)", EscapeSingleQuotes(option.Name), EscapeSingleQuotes(option.Value))
+}
+
+/*
+ * Connects to the master in utility mode, in which queries are not dispatched
+ * to the segments, so that the catalog can still be read while the segments
+ * are down.  The driver is given the connection string as a URL that already
+ * has a query string.
+ */
+type UtilityModeDriver struct {
+	dbconn.GPDBDriver
+	Version dbconn.GPDBVersion
+}
+
+func (driver UtilityModeDriver) Connect(driverName string, dataSourceName string) (*sqlx.DB, error) {
+	return driver.GPDBDriver.Connect(driverName, UtilityModeConnectionString(dataSourceName, driver.Version))
+}
+
+// GPDB 7 replaced the gp_session_role startup parameter with gp_role
+func UtilityModeConnectionString(dataSourceName string, version dbconn.GPDBVersion) string {
+	if version.AtLeast("7") {
+		return dataSourceName + "&gp_role=utility"
+	}
+	return dataSourceName + "&gp_session_role=utility"
+}
+
+var gpVersionRegex = regexp.MustCompile(`\(Greenplum Database\) (\d+\.\d+\.\d+)(.*)`)
+
+/*
+ * The utility mode startup parameter must be chosen before connecting, so the
+ * version is read from the installed postgres binary, which is the same as the
+ * master's when gpbackup is run on the master host as required.
+ */
+func GetInstalledGPDBVersion() (dbconn.GPDBVersion, error) {
+	postgresPath := fmt.Sprintf("%s/bin/postgres", operating.System.Getenv("GPHOME"))
+	output, err := exec.Command(postgresPath, "--gp-version").CombinedOutput()
+	if err != nil {
+		return dbconn.GPDBVersion{}, errors.Wrapf(err, "Unable to determine the installed GPDB version with %s --gp-version: %s", postgresPath, strings.TrimSpace(string(output)))
+	}
+	return ParseGPVersionOutput(string(output))
+}
+
+func ParseGPVersionOutput(output string) (dbconn.GPDBVersion, error) {
+	matches := gpVersionRegex.FindStringSubmatch(output)
+	if matches == nil {
+		return dbconn.GPDBVersion{}, errors.Errorf("Unable to parse a GPDB version from %q", strings.TrimSpace(output))
+	}
+	semVer, err := semver.Make(matches[1])
+	if err != nil {
+		return dbconn.GPDBVersion{}, errors.Wrapf(err, "Unable to parse a GPDB version from %q", strings.TrimSpace(output))
+	}
+	return dbconn.GPDBVersion{VersionString: strings.TrimSpace(matches[1] + matches[2]), SemVer: semVer}, nil
+}
+
 func InitializeSignalHandler(cleanupFunc func(bool), procDesc string, termFlag *bool) {
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
@@ -73,8 +265,9 @@ func InitializeSignalHandler(cleanupFunc func(bool), procDesc string, termFlag *
 			fmt.Println() // Add newline after "^C" is printed
 			gplog.Warn("Received a termination signal, aborting %s", procDesc)
 			*termFlag = true
+			gplog.SetErrorCode(EXIT_TERMINATED)
 			cleanupFunc(true)
-			os.Exit(2)
+			os.Exit(EXIT_TERMINATED)
 		}
 	}()
 }
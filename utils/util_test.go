@@ -1,6 +1,10 @@
 package utils_test
 
 import (
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
 	"github.com/greenplum-db/gp-common-go-libs/testhelper"
 	"github.com/greenplum-db/gpbackup/utils"
 
@@ -35,6 +39,18 @@ var _ = Describe("utils/util tests", func() {
 			Expect(actual).To(Equal(expected))
 		})
 	})
+	Describe("ParseDataFilters", func() {
+		It("parses tables and their conditions", func() {
+			filters := utils.ParseDataFilters([]string{"public.foo:tenant_id = 7", "", `public."Bar": created::date > '2020-01-01'`})
+
+			Expect(filters).To(Equal(map[string]string{"public.foo": "tenant_id = 7", `public."Bar"`: "created::date > '2020-01-01'"}))
+		})
+		It("panics if a filter has no condition", func() {
+			defer testhelper.ShouldPanicWithMessage("Invalid data filter public.foo.  Data filters must be in the format schema.table:condition.")
+
+			utils.ParseDataFilters([]string{"public.foo"})
+		})
+	})
 	Describe("ValidateFQNs", func() {
 		It("validates an unquoted string", func() {
 			testStrings := []string{`schemaname.tablename`}
@@ -113,4 +129,121 @@ var _ = Describe("utils/util tests", func() {
 			utils.ValidateGPDBVersionCompatibility(connectionPool)
 		})
 	})
+	Describe("ParseConnectionOptions", func() {
+		It("returns no options for an empty string", func() {
+			options, err := utils.ParseConnectionOptions("")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(options).To(BeEmpty())
+		})
+		It("parses options in each PGOPTIONS format", func() {
+			options, err := utils.ParseConnectionOptions("-c optimizer=off  -cstatement_mem=256MB --gp-interconnect-type=tcp")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(options).To(Equal([]utils.ConnectionOption{
+				{Name: "optimizer", Value: "off"},
+				{Name: "statement_mem", Value: "256MB"},
+				{Name: "gp_interconnect_type", Value: "tcp"},
+			}))
+		})
+		It("unescapes backslash-escaped characters in values", func() {
+			options, err := utils.ParseConnectionOptions(`-c search_path=public,\ my\ schema`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(options).To(Equal([]utils.ConnectionOption{{Name: "search_path", Value: "public, my schema"}}))
+		})
+		It("returns an error for an option without a value", func() {
+			_, err := utils.ParseConnectionOptions("-c optimizer")
+			Expect(err).To(MatchError("Invalid connection option optimizer.  Options must be in the format -c name=value or --name=value."))
+		})
+		It("returns an error for an option that is not a setting", func() {
+			_, err := utils.ParseConnectionOptions("-o optimizer=off")
+			Expect(err).To(MatchError("Invalid connection option -o.  Options must be in the format -c name=value or --name=value."))
+		})
+		It("returns an error for an invalid setting name", func() {
+			_, err := utils.ParseConnectionOptions("-c optimizer;drop=off")
+			Expect(err).To(HaveOccurred())
+		})
+		It("returns an error when setting gp_role", func() {
+			_, err := utils.ParseConnectionOptions("-c gp_role=utility")
+			Expect(err).To(MatchError("Cannot set gp_role for backup connections, as they must be dispatched from the master."))
+		})
+	})
+	Describe("ConnectionOptionStatement", func() {
+		It("sets a list parameter from a comma-separated list", func() {
+			statement := utils.ConnectionOptionStatement(utils.ConnectionOption{Name: "search_path", Value: `public, "my schema"`})
+			Expect(statement).To(Equal(`SELECT set_config('search_path', 'public, "my schema"', false)`))
+		})
+		It("escapes single quotes in the value", func() {
+			option := utils.ConnectionOption{Name: "application_name", Value: "it's"}
+			Expect(utils.ConnectionOptionStatement(option)).To(Equal("SELECT set_config('application_name', 'it''s', false)"))
+		})
+	})
+	Describe("StringInterner", func() {
+		It("keeps one copy of each distinct string", func() {
+			interner := utils.NewStringInterner()
+
+			Expect(interner.Intern("public")).To(Equal("public"))
+			Expect(interner.Intern(strings.ToLower("PUBLIC"))).To(Equal("public"))
+			Expect(interner.Intern("schema")).To(Equal("schema"))
+			Expect(interner).To(HaveLen(2))
+		})
+	})
+	Describe("ParseBackupGUCs", func() {
+		It("parses GUCs in the format name=value", func() {
+			gucs, err := utils.ParseBackupGUCs([]string{"lock_timeout=30s", "Statement_Timeout=0", `search_path=public, "my schema"`})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gucs).To(Equal([]utils.ConnectionOption{
+				{Name: "lock_timeout", Value: "30s"},
+				{Name: "statement_timeout", Value: "0"},
+				{Name: "search_path", Value: `public, "my schema"`},
+			}))
+		})
+		It("returns an error if a GUC is not in the format name=value", func() {
+			_, err := utils.ParseBackupGUCs([]string{"lock_timeout"})
+			Expect(err).To(MatchError("Invalid backup GUC lock_timeout.  GUCs must be in the format name=value."))
+		})
+		It("returns an error if a GUC name is not a valid identifier", func() {
+			_, err := utils.ParseBackupGUCs([]string{"lock_timeout';=30s"})
+			Expect(err).To(MatchError("Invalid backup GUC lock_timeout';=30s.  GUCs must be in the format name=value."))
+		})
+		It("returns an error for GUCs that control dispatch", func() {
+			_, err := utils.ParseBackupGUCs([]string{"gp_role=utility"})
+			Expect(err).To(MatchError("Cannot set gp_role for backup connections, as they must be dispatched from the master."))
+		})
+	})
+	Describe("ConnectionOptionStatement", func() {
+		It("sets a list parameter from a comma-separated list", func() {
+			statement := utils.ConnectionOptionStatement(utils.ConnectionOption{Name: "search_path", Value: `public, "my schema"`})
+			Expect(statement).To(Equal(`SELECT set_config('search_path', 'public, "my schema"', false)`))
+		})
+		It("escapes single quotes in the value", func() {
+			option := utils.ConnectionOption{Name: "application_name", Value: "it's"}
+			Expect(utils.ConnectionOptionStatement(option)).To(Equal("SELECT set_config('application_name', 'it''s', false)"))
+		})
+	})
+	Describe("UtilityModeConnectionString", func() {
+		const dataSourceName = "postgres://testrole@testhost:5432/testdb?sslmode=disable&statement_cache_capacity=0"
+		It("sets gp_session_role before GPDB 7", func() {
+			version := dbconn.GPDBVersion{VersionString: "6.20.0 build dev", SemVer: semver.MustParse("6.20.0")}
+			Expect(utils.UtilityModeConnectionString(dataSourceName, version)).To(Equal(dataSourceName + "&gp_session_role=utility"))
+		})
+		It("sets gp_session_role for GPDB 5", func() {
+			version := dbconn.GPDBVersion{VersionString: "5.28.0 build dev", SemVer: semver.MustParse("5.28.0")}
+			Expect(utils.UtilityModeConnectionString(dataSourceName, version)).To(Equal(dataSourceName + "&gp_session_role=utility"))
+		})
+		It("sets gp_role for GPDB 7", func() {
+			version := dbconn.GPDBVersion{VersionString: "7.0.0 build dev", SemVer: semver.MustParse("7.0.0")}
+			Expect(utils.UtilityModeConnectionString(dataSourceName, version)).To(Equal(dataSourceName + "&gp_role=utility"))
+		})
+	})
+	Describe("ParseGPVersionOutput", func() {
+		It("parses the version printed by postgres --gp-version", func() {
+			version, err := utils.ParseGPVersionOutput("postgres (Greenplum Database) 6.20.0 build commit:abcdef\n")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(version.VersionString).To(Equal("6.20.0 build commit:abcdef"))
+			Expect(version.SemVer).To(Equal(semver.MustParse("6.20.0")))
+		})
+		It("returns an error if the output contains no version", func() {
+			_, err := utils.ParseGPVersionOutput("postgres: command not found")
+			Expect(err).To(MatchError(`Unable to parse a GPDB version from "postgres: command not found"`))
+		})
+	})
 })
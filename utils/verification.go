@@ -0,0 +1,56 @@
+package utils
+
+/*
+ * This file contains structs and functions for the queries a user can have
+ * run at the backup snapshot, so that each backup records business-level
+ * evidence of its consistency, such as the row counts of critical tables.
+ */
+
+import (
+	"strings"
+
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+/*
+ * Each query must return a single value.  Result and Error are filled in
+ * once the query has been run.
+ */
+type VerificationQuery struct {
+	Name   string `yaml:"name"`
+	Query  string `yaml:"query"`
+	Result string `yaml:"-"`
+	Error  string `yaml:"-"`
+}
+
+/*
+ * The file holds a list of queries, each with a name under which its result
+ * is recorded in the backup report:
+ *
+ * - name: orders row count
+ *   query: SELECT count(*) FROM sales.orders
+ */
+func ReadVerificationQueries(filename string) ([]VerificationQuery, error) {
+	contents, err := operating.System.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to read verification query file %s", filename)
+	}
+	queries := make([]VerificationQuery, 0)
+	err = yaml.Unmarshal(contents, &queries)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid verification query file %s", filename)
+	}
+	names := make(map[string]bool, len(queries))
+	for i, query := range queries {
+		if strings.TrimSpace(query.Name) == "" || strings.TrimSpace(query.Query) == "" {
+			return nil, errors.Errorf("Verification query %d in %s must have both a name and a query", i+1, filename)
+		}
+		if names[query.Name] {
+			return nil, errors.Errorf("Verification query name %s is used more than once in %s", query.Name, filename)
+		}
+		names[query.Name] = true
+	}
+	return queries, nil
+}
@@ -0,0 +1,52 @@
+package utils_test
+
+import (
+	"github.com/greenplum-db/gp-common-go-libs/operating"
+	"github.com/greenplum-db/gpbackup/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("utils/verification tests", func() {
+	AfterEach(func() {
+		operating.System = operating.InitializeSystemFunctions()
+	})
+	Describe("ReadVerificationQueries", func() {
+		readFile := func(contents string) {
+			operating.System.ReadFile = func(filename string) ([]byte, error) {
+				return []byte(contents), nil
+			}
+		}
+		It("reads a list of named queries", func() {
+			readFile(`
+- name: orders row count
+  query: SELECT count(*) FROM sales.orders
+- name: unpaid invoices
+  query: SELECT count(*) FROM sales.invoices WHERE NOT paid
+`)
+
+			queries, err := utils.ReadVerificationQueries("queries.yaml")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(queries).To(Equal([]utils.VerificationQuery{
+				{Name: "orders row count", Query: "SELECT count(*) FROM sales.orders"},
+				{Name: "unpaid invoices", Query: "SELECT count(*) FROM sales.invoices WHERE NOT paid"},
+			}))
+		})
+		It("returns an error if a query has no name", func() {
+			readFile("- query: SELECT 1\n")
+
+			_, err := utils.ReadVerificationQueries("queries.yaml")
+
+			Expect(err).To(MatchError("Verification query 1 in queries.yaml must have both a name and a query"))
+		})
+		It("returns an error if a name is used more than once", func() {
+			readFile("- name: one\n  query: SELECT 1\n- name: one\n  query: SELECT 2\n")
+
+			_, err := utils.ReadVerificationQueries("queries.yaml")
+
+			Expect(err).To(MatchError("Verification query name one is used more than once in queries.yaml"))
+		})
+	})
+})